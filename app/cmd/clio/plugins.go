@@ -0,0 +1,7 @@
+package main
+
+// Third-party exporters are compiled in by blank-importing their packages
+// here. Each package registers itself with pkg/export from an init function
+// and becomes available to `clio export --format`.
+//
+//	import _ "example.com/clio-exporters/csv"
@@ -0,0 +1,292 @@
+// Package alerts watches newly captured messages and commit diffs for keywords
+// or regular expressions configured in the alerts block, such as TODO, FIXME,
+// or password. Each scan picks up where the previous one stopped, so a row is
+// matched at most once.
+package alerts
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/blobs"
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+const (
+	// SourceMessages watches conversation messages
+	SourceMessages = "messages"
+	// SourceDiffs watches the lines added by captured commits
+	SourceDiffs = "diffs"
+
+	// scanBatchSize caps the rows read from a source per scan
+	scanBatchSize = 500
+	// excerptLength caps the matching line included in a match, in runes
+	excerptLength = 200
+)
+
+// Rule is a compiled alert
+type Rule struct {
+	Name    string
+	Pattern *regexp.Regexp
+	Sources map[string]bool
+}
+
+// Compile compiles configured alerts into rules. Keywords match
+// case-insensitively; patterns with regex set are used as given.
+func Compile(alerts []config.AlertConfig) ([]Rule, error) {
+	rules := make([]Rule, 0, len(alerts))
+	for _, alert := range alerts {
+		pattern := "(?i)" + regexp.QuoteMeta(alert.Pattern)
+		if alert.Regex {
+			pattern = alert.Pattern
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("alert %q: invalid regex: %w", alert.Name, err)
+		}
+
+		sources := map[string]bool{SourceMessages: true, SourceDiffs: true}
+		if len(alert.Sources) > 0 {
+			sources = make(map[string]bool, len(alert.Sources))
+			for _, source := range alert.Sources {
+				sources[source] = true
+			}
+		}
+		rules = append(rules, Rule{Name: alert.Name, Pattern: re, Sources: sources})
+	}
+	return rules, nil
+}
+
+// Match is a rule matching a newly captured message or diff
+type Match struct {
+	Rule    string
+	Source  string
+	Excerpt string // The first matching line, trimmed
+	Time    time.Time
+
+	SessionID string // Empty for commits not correlated to a session
+	Project   string
+
+	// Set for messages
+	ComposerID string
+	Role       string
+
+	// Set for diffs
+	CommitHash string
+	Repository string
+	File       string
+}
+
+// Scanner finds rule matches in content captured since the previous scan
+type Scanner interface {
+	// Scan returns the matches in rows captured since the previous scan. The
+	// first scan of a database only records where to start from.
+	Scan() ([]Match, error)
+}
+
+// scanner implements Scanner on top of the clio database
+type scanner struct {
+	db     *sql.DB
+	blobs  blobs.Store
+	rules  []Rule
+	logger logging.Logger
+}
+
+// NewScanner creates a scanner for the rules. blobStore may be nil, in which
+// case content moved to the blob store is matched against its preview.
+func NewScanner(db *sql.DB, blobStore blobs.Store, rules []Rule, logger logging.Logger) (Scanner, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database cannot be nil")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	return &scanner{
+		db:     db,
+		blobs:  blobStore,
+		rules:  rules,
+		logger: logger.With("component", "alerts"),
+	}, nil
+}
+
+// row is captured content read from a source
+type row struct {
+	rowid int64
+	match Match // Everything but the rule and excerpt
+	text  string
+}
+
+// sourceQueries select rows after a rowid, oldest first, for each source
+var sourceQueries = map[string]string{
+	SourceMessages: `
+		SELECT m.rowid, m.content, m.content_blob, m.created_at, m.role,
+			c.composer_id, c.session_id, COALESCE(s.project, '')
+		FROM messages m
+		JOIN conversations c ON c.id = m.conversation_id
+		LEFT JOIN sessions s ON s.id = c.session_id
+		WHERE m.rowid > ?
+		ORDER BY m.rowid
+		LIMIT ?`,
+	SourceDiffs: `
+		SELECT f.rowid, COALESCE(f.diff, ''), f.diff_blob, c.timestamp, c.hash,
+			c.repository_name, f.file_path, COALESCE(c.session_id, ''), COALESCE(s.project, '')
+		FROM commit_files f
+		JOIN commits c ON c.id = f.commit_id
+		LEFT JOIN sessions s ON s.id = c.session_id
+		WHERE f.rowid > ?
+		ORDER BY f.rowid
+		LIMIT ?`,
+}
+
+// sourceTables are the tables behind each source, for finding the starting rowid
+var sourceTables = map[string]string{
+	SourceMessages: "messages",
+	SourceDiffs:    "commit_files",
+}
+
+// Scan matches the rules against each watched source
+func (s *scanner) Scan() ([]Match, error) {
+	var matches []Match
+	for _, source := range []string{SourceMessages, SourceDiffs} {
+		if !s.watches(source) {
+			continue
+		}
+		found, err := s.scanSource(source)
+		if err != nil {
+			return matches, err
+		}
+		matches = append(matches, found...)
+	}
+	return matches, nil
+}
+
+// watches reports whether any rule watches the source
+func (s *scanner) watches(source string) bool {
+	for _, rule := range s.rules {
+		if rule.Sources[source] {
+			return true
+		}
+	}
+	return false
+}
+
+// scanSource matches the rules against rows of one source captured since its cursor
+func (s *scanner) scanSource(source string) ([]Match, error) {
+	var cursor int64
+	err := s.db.QueryRow("SELECT last_rowid FROM alert_cursors WHERE source = ?", source).Scan(&cursor)
+	if err == sql.ErrNoRows {
+		// Content captured before alerts were configured doesn't raise them
+		if err := s.db.QueryRow("SELECT COALESCE(MAX(rowid), 0) FROM " + sourceTables[source]).Scan(&cursor); err != nil {
+			return nil, fmt.Errorf("failed to find where to start scanning %s: %w", source, err)
+		}
+		return nil, s.saveCursor(source, cursor)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s alert cursor: %w", source, err)
+	}
+
+	rows, err := s.readRows(source, cursor)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	var matches []Match
+	for _, r := range rows {
+		for _, rule := range s.rules {
+			if !rule.Sources[source] {
+				continue
+			}
+			if excerpt, ok := findExcerpt(rule.Pattern, r.text, source == SourceDiffs); ok {
+				match := r.match
+				match.Rule, match.Source, match.Excerpt = rule.Name, source, excerpt
+				matches = append(matches, match)
+			}
+		}
+	}
+
+	if err := s.saveCursor(source, rows[len(rows)-1].rowid); err != nil {
+		return nil, err
+	}
+	s.logger.Debug("scanned for alerts", "source", source, "rows", len(rows), "matches", len(matches))
+	return matches, nil
+}
+
+// readRows reads a batch of rows of a source after the cursor, resolving blob references
+func (s *scanner) readRows(source string, cursor int64) ([]row, error) {
+	result, err := s.db.Query(sourceQueries[source], cursor, scanBatchSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", source, err)
+	}
+	defer result.Close()
+
+	var rows []row
+	for result.Next() {
+		var r row
+		var ref sql.NullString
+		if source == SourceMessages {
+			err = result.Scan(&r.rowid, &r.text, &ref, &r.match.Time, &r.match.Role,
+				&r.match.ComposerID, &r.match.SessionID, &r.match.Project)
+		} else {
+			err = result.Scan(&r.rowid, &r.text, &ref, &r.match.Time, &r.match.CommitHash,
+				&r.match.Repository, &r.match.File, &r.match.SessionID, &r.match.Project)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan %s row: %w", source, err)
+		}
+		if r.text, err = blobs.Resolve(s.blobs, r.text, ref); err != nil {
+			// Match the preview kept in the row rather than stalling the scan
+			s.logger.Warn("failed to load blob, matching the stored preview", "source", source, "error", err)
+		}
+		rows = append(rows, r)
+	}
+	if err := result.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating %s: %w", source, err)
+	}
+	return rows, nil
+}
+
+// saveCursor records the last rowid scanned in a source
+func (s *scanner) saveCursor(source string, rowid int64) error {
+	_, err := s.db.Exec(`
+		INSERT INTO alert_cursors (source, last_rowid, updated_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT(source) DO UPDATE SET
+			last_rowid = excluded.last_rowid,
+			updated_at = excluded.updated_at
+	`, source, rowid, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to save %s alert cursor: %w", source, err)
+	}
+	return nil
+}
+
+// findExcerpt returns the first line of text the pattern matches, trimmed and
+// shortened to excerptLength. For diffs only added lines are considered, so
+// removing a TODO doesn't raise an alert.
+func findExcerpt(pattern *regexp.Regexp, text string, addedOnly bool) (string, bool) {
+	for _, line := range strings.Split(text, "\n") {
+		if addedOnly {
+			if !strings.HasPrefix(line, "+") || strings.HasPrefix(line, "+++") {
+				continue
+			}
+			line = line[1:]
+		}
+		if !pattern.MatchString(line) {
+			continue
+		}
+		line = strings.TrimSpace(line)
+		if runes := []rune(line); len(runes) > excerptLength {
+			line = string(runes[:excerptLength]) + "…"
+		}
+		return line, true
+	}
+	return "", false
+}
@@ -0,0 +1,122 @@
+package alerts
+
+import (
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/logging"
+	_ "modernc.org/sqlite"
+)
+
+func setupTestDB(t *testing.T) *sql.DB {
+	database, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "clio.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if err := db.RunMigrations(database); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	now := time.Date(2024, 5, 1, 9, 0, 0, 0, time.Local)
+	mustExec(t, database, `
+		INSERT INTO sessions (id, project, start_time, last_activity, created_at, updated_at)
+		VALUES ('s1', 'clio', ?, ?, ?, ?)
+	`, now, now, now, now)
+	mustExec(t, database, `
+		INSERT INTO conversations (id, session_id, composer_id, created_at, updated_at)
+		VALUES ('c1', 's1', 'composer-1', ?, ?)
+	`, now, now)
+	mustExec(t, database, `
+		INSERT INTO commits (id, session_id, repository_path, repository_name, hash, message,
+			author_name, author_email, timestamp, branch, created_at, updated_at)
+		VALUES ('k1', 's1', '/src/clio', 'clio', 'abc123', 'Fix', 'Dev', 'dev@example.com', ?, 'main', ?, ?)
+	`, now, now, now)
+	return database
+}
+
+func mustExec(t *testing.T, database *sql.DB, query string, args ...any) {
+	t.Helper()
+	if _, err := database.Exec(query, args...); err != nil {
+		t.Fatalf("failed to exec %q: %v", query, err)
+	}
+}
+
+func addMessage(t *testing.T, database *sql.DB, id, content string) {
+	mustExec(t, database, `
+		INSERT INTO messages (id, conversation_id, bubble_id, type, role, content, created_at)
+		VALUES (?, 'c1', ?, 1, 'user', ?, ?)
+	`, id, id, content, time.Now())
+}
+
+func addDiff(t *testing.T, database *sql.DB, id, file, diff string) {
+	mustExec(t, database, `
+		INSERT INTO commit_files (id, commit_id, file_path, diff, created_at)
+		VALUES (?, 'k1', ?, ?, ?)
+	`, id, file, diff, time.Now())
+}
+
+func TestScanner_Scan(t *testing.T) {
+	database := setupTestDB(t)
+	addMessage(t, database, "m0", "an old TODO from before alerts")
+
+	rules, err := Compile([]config.AlertConfig{
+		{Name: "todo", Pattern: "todo"},
+		{Name: "secret", Pattern: `(?i)password\s*=`, Regex: true, Sources: []string{SourceDiffs}},
+	})
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	scanner, err := NewScanner(database, nil, rules, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewScanner() error = %v", err)
+	}
+
+	// The first scan only records where to start
+	if matches, err := scanner.Scan(); err != nil || len(matches) != 0 {
+		t.Fatalf("first Scan() = %+v, %v; want no matches", matches, err)
+	}
+
+	addMessage(t, database, "m1", "Looks good.\n  Leave a TODO for the retry logic  ")
+	addMessage(t, database, "m2", "password = hunter2 in a message")
+	addDiff(t, database, "f1", "config.go", "--- a/config.go\n+++ b/config.go\n-// TODO: remove\n+password = \"hunter2\"")
+
+	matches, err := scanner.Scan()
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if len(matches) != 2 {
+		t.Fatalf("Scan() = %+v, want the message TODO and the added password", matches)
+	}
+	if got := matches[0]; got.Rule != "todo" || got.Source != SourceMessages || got.Excerpt != "Leave a TODO for the retry logic" ||
+		got.SessionID != "s1" || got.Project != "clio" || got.ComposerID != "composer-1" || got.Role != "user" {
+		t.Errorf("message match = %+v", got)
+	}
+	if got := matches[1]; got.Rule != "secret" || got.Source != SourceDiffs || got.Excerpt != `password = "hunter2"` ||
+		got.CommitHash != "abc123" || got.File != "config.go" || got.Repository != "clio" {
+		t.Errorf("diff match = %+v", got)
+	}
+
+	// Rows are scanned once, even when updated later
+	mustExec(t, database, "UPDATE messages SET content = 'another TODO' WHERE id = 'm2'")
+	if matches, err := scanner.Scan(); err != nil || len(matches) != 0 {
+		t.Errorf("rescan = %+v, %v; want no matches", matches, err)
+	}
+}
+
+func TestFindExcerpt_Truncates(t *testing.T) {
+	rules, err := Compile([]config.AlertConfig{{Name: "fixme", Pattern: "FIXME"}})
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	long := "fixme " + strings.Repeat("x", 300)
+	excerpt, ok := findExcerpt(rules[0].Pattern, long, false)
+	if !ok || len([]rune(excerpt)) != excerptLength+1 {
+		t.Errorf("findExcerpt() = %d runes, %v; want %d", len([]rune(excerpt)), ok, excerptLength+1)
+	}
+}
@@ -0,0 +1,796 @@
+// Package archive writes selected sessions to a self-contained, compressed bundle
+// and imports bundles into another clio database. A bundle is a gzip-compressed
+// tar holding a JSON manifest, a SQLite database with clio's schema containing
+// only the archived sessions' rows, and the files attached to them.
+package archive
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/blobs"
+	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/dedup"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+const (
+	// FormatVersion is the bundle layout written by Create; Import refuses newer layouts
+	FormatVersion = 1
+	// Extension is the conventional file extension of bundles
+	Extension = ".clio"
+
+	// manifestName is the tar entry holding the manifest; it is written first
+	manifestName = "manifest.json"
+	// databaseName is the tar entry holding the sessions database
+	databaseName = "sessions.db"
+	// assetsPrefix prefixes the tar entries of attached files
+	assetsPrefix = "assets/"
+	// bundleSchema is the name the bundle database is attached under
+	bundleSchema = "bundle"
+	// assetDirPerm and assetFilePerm match the permissions used by the assets store
+	assetDirPerm  = 0755
+	assetFilePerm = 0644
+)
+
+// Manifest describes a bundle's contents
+type Manifest struct {
+	FormatVersion int              `json:"format_version"`
+	SchemaVersion int              `json:"schema_version"` // Migration version of the bundled database
+	CreatedAt     time.Time        `json:"created_at"`
+	Sessions      []SessionSummary `json:"sessions"`
+	Attachments   int              `json:"attachments"`
+}
+
+// SessionSummary is an archived session as listed in the manifest
+type SessionSummary struct {
+	ID            string     `json:"id"`
+	Project       string     `json:"project"`
+	StartTime     time.Time  `json:"start_time"`
+	EndTime       *time.Time `json:"end_time,omitempty"`
+	Conversations int        `json:"conversations"`
+	Commits       int        `json:"commits"`
+}
+
+// Options selects the sessions to archive; zero values don't filter
+type Options struct {
+	SessionIDs []string
+	Project    string
+	Since      time.Time // Sessions starting at or after
+	Until      time.Time // Sessions starting before
+}
+
+// ImportResult summarizes an import
+type ImportResult struct {
+	Manifest    *Manifest
+	Imported    int // Sessions added to the database
+	Skipped     int // Sessions the database already had
+	Attachments int // Attached files copied into the assets directory
+}
+
+// Archiver defines the interface for creating and importing bundles
+type Archiver interface {
+	// Create writes the selected sessions to a bundle. It fails when no session matches.
+	Create(w io.Writer, opts Options) (*Manifest, error)
+	// Import adds a bundle's sessions to the database. Rows the database already has
+	// are kept, so importing a bundle twice is a no-op.
+	Import(r io.Reader) (*ImportResult, error)
+}
+
+// table is a database table copied into bundles
+type table struct {
+	name string
+	// selectWhere picks the rows belonging to the bundle's sessions, once parent tables are copied
+	selectWhere string
+	// importWhere skips rows of the bundle (aliased src) that the database already has
+	// under another ID; duplicate IDs are ignored regardless
+	importWhere string
+	// generatedID marks an autoincrement id that is reassigned on import
+	generatedID bool
+}
+
+// tables lists the session data in a bundle, parents first. Derived data such as
+// conversation metrics and the code provenance index is rebuilt on the importing
+// machine instead.
+var tables = []table{
+	{name: "conversations", selectWhere: "session_id IN (SELECT id FROM bundle.sessions)"},
+	{name: "messages", selectWhere: "conversation_id IN (SELECT id FROM bundle.conversations)"},
+	{name: "message_revisions", selectWhere: "conversation_id IN (SELECT id FROM bundle.conversations)", generatedID: true},
+	{name: "commits", selectWhere: "session_id IN (SELECT id FROM bundle.sessions)",
+		importWhere: "NOT EXISTS (SELECT 1 FROM main.commits c WHERE c.hash = src.hash)"},
+	{name: "commit_files", selectWhere: "commit_id IN (SELECT id FROM bundle.commits)",
+		importWhere: "commit_id IN (SELECT id FROM main.commits)"},
+	// Cases are imported before their runs so cases of runs the database already has are skipped
+	{name: "test_cases", selectWhere: "run_id IN (SELECT id FROM main.test_runs WHERE session_id IN (SELECT id FROM bundle.sessions))",
+		importWhere: "run_id NOT IN (SELECT id FROM main.test_runs)", generatedID: true},
+	{name: "test_runs", selectWhere: "session_id IN (SELECT id FROM bundle.sessions)"},
+	{name: "attachments", selectWhere: "session_id IN (SELECT id FROM bundle.sessions)"},
+	{name: "journal_entries", selectWhere: "session_id IN (SELECT id FROM bundle.sessions)"},
+	{name: "heartbeats", selectWhere: "session_id IN (SELECT id FROM bundle.sessions)", generatedID: true},
+}
+
+// archiver implements Archiver
+type archiver struct {
+	db        *sql.DB
+	assetsDir string
+	blobs     blobs.Store
+	logger    logging.Logger
+}
+
+// NewArchiver creates an archiver over db; attached files are read from and
+// imported into assetsDir. Values moved to the blob store are written to bundles
+// in full, so bundles don't depend on this machine's blobs.
+func NewArchiver(database *sql.DB, assetsDir string, store blobs.Store, logger logging.Logger) (Archiver, error) {
+	if database == nil {
+		return nil, fmt.Errorf("database cannot be nil")
+	}
+	if assetsDir == "" {
+		return nil, fmt.Errorf("assets directory cannot be empty")
+	}
+	if store == nil {
+		return nil, fmt.Errorf("blob store cannot be nil")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	return &archiver{
+		db:        database,
+		assetsDir: assetsDir,
+		blobs:     store,
+		logger:    logger.With("component", "archive"),
+	}, nil
+}
+
+// Create copies the selected sessions into a temporary database and writes the bundle
+func (a *archiver) Create(w io.Writer, opts Options) (*Manifest, error) {
+	ids, err := a.selectSessions(opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no sessions match")
+	}
+
+	tmpDir, err := os.MkdirTemp("", "clio-archive-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	bundlePath := filepath.Join(tmpDir, databaseName)
+	schemaVersion, err := createBundleDatabase(bundlePath)
+	if err != nil {
+		return nil, err
+	}
+
+	attachments, err := a.copySessions(bundlePath, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := summarize(bundlePath)
+	if err != nil {
+		return nil, err
+	}
+	manifest.SchemaVersion = schemaVersion
+	manifest.Attachments = len(attachments)
+
+	if err := writeBundle(w, manifest, bundlePath, attachments); err != nil {
+		return nil, err
+	}
+
+	a.logger.Debug("created archive", "sessions", len(manifest.Sessions), "attachments", len(attachments))
+	return manifest, nil
+}
+
+// Import extracts a bundle, upgrades its schema when it is older, and copies its rows
+func (a *archiver) Import(r io.Reader) (*ImportResult, error) {
+	current, err := db.SchemaVersion(a.db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	tmpDir, err := os.MkdirTemp("", "clio-archive-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	manifest, err := extractBundle(r, tmpDir)
+	if err != nil {
+		return nil, err
+	}
+	if manifest.SchemaVersion > current {
+		return nil, fmt.Errorf("archive schema version %d is newer than this database's (%d); upgrade clio to import it",
+			manifest.SchemaVersion, current)
+	}
+
+	bundlePath := filepath.Join(tmpDir, databaseName)
+	if err := upgradeBundleDatabase(bundlePath); err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{Manifest: manifest}
+	if err := a.importRows(bundlePath, result); err != nil {
+		return nil, err
+	}
+	if err := a.importAssets(bundlePath, filepath.Join(tmpDir, "assets"), result); err != nil {
+		return nil, err
+	}
+
+	a.logger.Debug("imported archive", "imported", result.Imported, "skipped", result.Skipped)
+	return result, nil
+}
+
+// ReadManifest reads a bundle's manifest without importing it
+func ReadManifest(r io.Reader) (*Manifest, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("not a clio archive: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	header, err := tr.Next()
+	if err != nil {
+		return nil, fmt.Errorf("not a clio archive: %w", err)
+	}
+	if header.Name != manifestName {
+		return nil, fmt.Errorf("not a clio archive: %s is not first", manifestName)
+	}
+	return decodeManifest(tr)
+}
+
+// selectSessions returns the IDs of the sessions matching opts, oldest first
+func (a *archiver) selectSessions(opts Options) ([]string, error) {
+	query := "SELECT id, project, start_time FROM sessions"
+	var args []interface{}
+	if opts.Project != "" {
+		query += " WHERE LOWER(project) = LOWER(?)"
+		args = append(args, opts.Project)
+	}
+	rows, err := a.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sessions: %w", err)
+	}
+	defer rows.Close()
+
+	wanted := make(map[string]bool, len(opts.SessionIDs))
+	for _, id := range opts.SessionIDs {
+		wanted[id] = true
+	}
+
+	type candidate struct {
+		id    string
+		start time.Time
+	}
+	var matches []candidate
+	for rows.Next() {
+		var c candidate
+		var project sql.NullString
+		if err := rows.Scan(&c.id, &project, &c.start); err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		if len(wanted) > 0 && !wanted[c.id] {
+			continue
+		}
+		if !db.InRange(c.start, opts.Since, opts.Until) {
+			continue
+		}
+		matches = append(matches, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate sessions: %w", err)
+	}
+
+	db.SortOldestFirst(matches, func(c candidate) time.Time { return c.start })
+	ids := make([]string, len(matches))
+	for i, c := range matches {
+		ids[i] = c.id
+	}
+	return ids, nil
+}
+
+// copySessions copies the sessions and their rows into the bundle database and
+// returns the stored paths of their attached files
+func (a *archiver) copySessions(bundlePath string, ids []string) ([]string, error) {
+	ctx := context.Background()
+	conn, err := attachBundle(ctx, a.db, bundlePath)
+	if err != nil {
+		return nil, err
+	}
+	defer detachBundle(ctx, conn)
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	columns, err := tableColumns(ctx, tx, "sessions", false)
+	if err != nil {
+		return nil, err
+	}
+	insertSession := fmt.Sprintf("INSERT INTO bundle.sessions (%[1]s) SELECT %[1]s FROM main.sessions WHERE id = ?", columns)
+	for _, id := range ids {
+		if _, err := tx.ExecContext(ctx, insertSession, id); err != nil {
+			return nil, fmt.Errorf("failed to archive session %s: %w", id, err)
+		}
+	}
+
+	for _, t := range tables {
+		columns, err := tableColumns(ctx, tx, t.name, false)
+		if err != nil {
+			return nil, err
+		}
+		query := fmt.Sprintf("INSERT INTO bundle.%[1]s (%[2]s) SELECT %[2]s FROM main.%[1]s WHERE %[3]s", t.name, columns, t.selectWhere)
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return nil, fmt.Errorf("failed to archive %s: %w", t.name, err)
+		}
+	}
+
+	if err := blobs.Inline(tx, bundleSchema, a.blobs); err != nil {
+		return nil, err
+	}
+	if err := dedup.Inline(tx, bundleSchema); err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, "SELECT DISTINCT path FROM bundle.attachments")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query attachments: %w", err)
+	}
+	var paths []string
+	for rows.Next() {
+		var p string
+		if err := rows.Scan(&p); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan attachment: %w", err)
+		}
+		paths = append(paths, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate attachments: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit archive: %w", err)
+	}
+	return paths, nil
+}
+
+// importRows copies the bundle's rows into the database in one transaction
+func (a *archiver) importRows(bundlePath string, result *ImportResult) error {
+	ctx := context.Background()
+	conn, err := attachBundle(ctx, a.db, bundlePath)
+	if err != nil {
+		return err
+	}
+	defer detachBundle(ctx, conn)
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	columns, err := tableColumns(ctx, tx, "sessions", false)
+	if err != nil {
+		return err
+	}
+	res, err := tx.ExecContext(ctx, fmt.Sprintf("INSERT OR IGNORE INTO main.sessions (%[1]s) SELECT %[1]s FROM bundle.sessions", columns))
+	if err != nil {
+		return fmt.Errorf("failed to import sessions: %w", err)
+	}
+	imported, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to count imported sessions: %w", err)
+	}
+	result.Imported = int(imported)
+	result.Skipped = len(result.Manifest.Sessions) - result.Imported
+
+	for _, t := range tables {
+		columns, err := tableColumns(ctx, tx, t.name, t.generatedID)
+		if err != nil {
+			return err
+		}
+		query := fmt.Sprintf("INSERT OR IGNORE INTO main.%[1]s (%[2]s) SELECT %[2]s FROM bundle.%[1]s AS src", t.name, columns)
+		if t.importWhere != "" {
+			query += " WHERE " + t.importWhere
+		}
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("failed to import %s: %w", t.name, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit import: %w", err)
+	}
+	return nil
+}
+
+// importAssets moves the bundle's attached files into the assets directory, where
+// they're stored by SHA-256 as the assets store does, and points the imported
+// attachments at them
+func (a *archiver) importAssets(bundlePath, extractedDir string, result *ImportResult) error {
+	bundleDB, err := sql.Open("sqlite", bundlePath)
+	if err != nil {
+		return fmt.Errorf("failed to open archive database: %w", err)
+	}
+	defer bundleDB.Close()
+
+	rows, err := bundleDB.Query("SELECT id, sha256, path FROM attachments")
+	if err != nil {
+		return fmt.Errorf("failed to query archived attachments: %w", err)
+	}
+	defer rows.Close()
+
+	copied := make(map[string]bool)
+	for rows.Next() {
+		var id, sum, stored string
+		if err := rows.Scan(&id, &sum, &stored); err != nil {
+			return fmt.Errorf("failed to scan archived attachment: %w", err)
+		}
+		name := filepath.Base(filepath.FromSlash(stored))
+		src := filepath.Join(extractedDir, name)
+		if _, err := os.Stat(src); err != nil {
+			a.logger.Warn("archived attachment has no content", "id", id, "name", name)
+			continue
+		}
+		if len(sum) < 2 {
+			continue
+		}
+
+		dst := filepath.Join(a.assetsDir, sum[:2], name)
+		if !copied[dst] {
+			if err := moveAsset(src, dst); err != nil {
+				return err
+			}
+			copied[dst] = true
+			result.Attachments++
+		}
+		if _, err := a.db.Exec("UPDATE attachments SET path = ? WHERE id = ? AND path = ?", dst, id, stored); err != nil {
+			return fmt.Errorf("failed to update attachment path: %w", err)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate archived attachments: %w", err)
+	}
+	return nil
+}
+
+// createBundleDatabase creates an empty database with clio's schema and returns its version
+func createBundleDatabase(path string) (int, error) {
+	bundleDB, err := sql.Open("sqlite", path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create archive database: %w", err)
+	}
+	defer bundleDB.Close()
+
+	if err := db.RunMigrations(bundleDB); err != nil {
+		return 0, fmt.Errorf("failed to create archive schema: %w", err)
+	}
+	version, err := db.SchemaVersion(bundleDB)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read archive schema version: %w", err)
+	}
+	return version, nil
+}
+
+// upgradeBundleDatabase migrates an extracted bundle database written by an older clio
+func upgradeBundleDatabase(path string) error {
+	bundleDB, err := sql.Open("sqlite", path)
+	if err != nil {
+		return fmt.Errorf("failed to open archive database: %w", err)
+	}
+	defer bundleDB.Close()
+
+	if err := db.RunMigrations(bundleDB); err != nil {
+		return fmt.Errorf("failed to upgrade archive schema: %w", err)
+	}
+	return nil
+}
+
+// attachBundle attaches the bundle database to a dedicated connection, since
+// attached databases are per connection
+func attachBundle(ctx context.Context, database *sql.DB, path string) (*sql.Conn, error) {
+	conn, err := database.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database connection: %w", err)
+	}
+	if _, err := conn.ExecContext(ctx, "ATTACH DATABASE ? AS "+bundleSchema, path); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to attach archive database: %w", err)
+	}
+	return conn, nil
+}
+
+// detachBundle detaches the bundle database and releases the connection
+func detachBundle(ctx context.Context, conn *sql.Conn) {
+	conn.ExecContext(ctx, "DETACH DATABASE "+bundleSchema)
+	conn.Close()
+}
+
+// tableColumns returns the quoted, comma-separated columns of a table in the main
+// database, leaving out id when skipID is set
+func tableColumns(ctx context.Context, tx *sql.Tx, name string, skipID bool) (string, error) {
+	rows, err := tx.QueryContext(ctx, "SELECT name FROM pragma_table_info(?, 'main')", name)
+	if err != nil {
+		return "", fmt.Errorf("failed to list columns of %s: %w", name, err)
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return "", fmt.Errorf("failed to scan column of %s: %w", name, err)
+		}
+		if skipID && column == "id" {
+			continue
+		}
+		columns = append(columns, `"`+column+`"`)
+	}
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("failed to iterate columns of %s: %w", name, err)
+	}
+	if len(columns) == 0 {
+		return "", fmt.Errorf("table %s not found", name)
+	}
+	return strings.Join(columns, ", "), nil
+}
+
+// summarize builds the manifest's session list from the bundle database
+func summarize(bundlePath string) (*Manifest, error) {
+	bundleDB, err := sql.Open("sqlite", bundlePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive database: %w", err)
+	}
+	defer bundleDB.Close()
+
+	rows, err := bundleDB.Query(`
+		SELECT s.id, s.project, s.start_time, s.end_time,
+			(SELECT COUNT(*) FROM conversations c WHERE c.session_id = s.id),
+			(SELECT COUNT(*) FROM commits c WHERE c.session_id = s.id)
+		FROM sessions s
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize archive: %w", err)
+	}
+	defer rows.Close()
+
+	manifest := &Manifest{FormatVersion: FormatVersion, CreatedAt: time.Now()}
+	for rows.Next() {
+		var s SessionSummary
+		var project sql.NullString
+		var end sql.NullTime
+		if err := rows.Scan(&s.ID, &project, &s.StartTime, &end, &s.Conversations, &s.Commits); err != nil {
+			return nil, fmt.Errorf("failed to scan archived session: %w", err)
+		}
+		s.Project = project.String
+		if end.Valid {
+			s.EndTime = &end.Time
+		}
+		manifest.Sessions = append(manifest.Sessions, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate archived sessions: %w", err)
+	}
+
+	sort.Slice(manifest.Sessions, func(i, j int) bool {
+		return manifest.Sessions[i].StartTime.Before(manifest.Sessions[j].StartTime)
+	})
+	return manifest, nil
+}
+
+// writeBundle writes the manifest, database, and attached files as a gzip-compressed tar
+func writeBundle(w io.Writer, manifest *Manifest, bundlePath string, attachments []string) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := writeEntry(tw, manifestName, manifest.CreatedAt, int64(len(manifestJSON)), strings.NewReader(string(manifestJSON))); err != nil {
+		return err
+	}
+	if err := writeFileEntry(tw, databaseName, bundlePath, manifest.CreatedAt); err != nil {
+		return err
+	}
+
+	written := make(map[string]bool)
+	for _, p := range attachments {
+		name := assetsPrefix + filepath.Base(p)
+		if written[name] {
+			continue
+		}
+		if err := writeFileEntry(tw, name, p, manifest.CreatedAt); err != nil {
+			return err
+		}
+		written[name] = true
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to finish archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to finish archive: %w", err)
+	}
+	return nil
+}
+
+// writeFileEntry adds the file at path to the tar under name
+func writeFileEntry(tw *tar.Writer, name, path string, modTime time.Time) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	return writeEntry(tw, name, modTime, info.Size(), file)
+}
+
+// writeEntry adds a regular file entry to the tar
+func writeEntry(tw *tar.Writer, name string, modTime time.Time, size int64, r io.Reader) error {
+	header := &tar.Header{Name: name, Mode: assetFilePerm, Size: size, ModTime: modTime, Typeflag: tar.TypeReg}
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if _, err := io.Copy(tw, r); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// extractBundle extracts the database and attached files into dir and returns the manifest
+func extractBundle(r io.Reader, dir string) (*Manifest, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("not a clio archive: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	var manifest *Manifest
+	hasDatabase := false
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		switch {
+		case header.Name == manifestName:
+			if manifest, err = decodeManifest(tr); err != nil {
+				return nil, err
+			}
+		case manifest == nil:
+			return nil, fmt.Errorf("not a clio archive: %s is not first", manifestName)
+		case header.Name == databaseName:
+			if err := extractFile(tr, filepath.Join(dir, databaseName)); err != nil {
+				return nil, err
+			}
+			hasDatabase = true
+		case strings.HasPrefix(header.Name, assetsPrefix):
+			// Only plain file names are accepted, so entries can't escape dir
+			name := strings.TrimPrefix(header.Name, assetsPrefix)
+			if name == "" || name != path.Base(name) || name == ".." {
+				return nil, fmt.Errorf("invalid archive entry %q", header.Name)
+			}
+			if err := extractFile(tr, filepath.Join(dir, "assets", name)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if manifest == nil || !hasDatabase {
+		return nil, fmt.Errorf("not a clio archive: missing %s or %s", manifestName, databaseName)
+	}
+	return manifest, nil
+}
+
+// decodeManifest decodes a manifest and checks its format version
+func decodeManifest(r io.Reader) (*Manifest, error) {
+	var manifest Manifest
+	if err := json.NewDecoder(r).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("failed to decode manifest: %w", err)
+	}
+	if manifest.FormatVersion < 1 || manifest.FormatVersion > FormatVersion {
+		return nil, fmt.Errorf("unsupported archive format version %d; upgrade clio to read it", manifest.FormatVersion)
+	}
+	return &manifest, nil
+}
+
+// extractFile writes a tar entry's content to path
+func extractFile(r io.Reader, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), assetDirPerm); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, assetFilePerm)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	if _, err := io.Copy(file, r); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to extract %s: %w", path, err)
+	}
+	return file.Close()
+}
+
+// moveAsset places an extracted file at dst unless a copy is already there. The
+// content must hash to the SHA-256 its name starts with.
+func moveAsset(src, dst string) error {
+	if _, err := os.Stat(dst); err == nil {
+		return nil
+	}
+
+	file, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open archived attachment: %w", err)
+	}
+	hash := sha256.New()
+	_, err = io.Copy(hash, file)
+	file.Close()
+	if err != nil {
+		return fmt.Errorf("failed to hash archived attachment: %w", err)
+	}
+	name := filepath.Base(dst)
+	if sum := hex.EncodeToString(hash.Sum(nil)); !strings.HasPrefix(name, sum) {
+		return fmt.Errorf("archived attachment %s is corrupt", name)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), assetDirPerm); err != nil {
+		return fmt.Errorf("failed to create assets directory: %w", err)
+	}
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+	// Fall back to copying when the temporary directory is on another filesystem
+	return copyFile(src, dst)
+}
+
+// copyFile copies src to dst through a temporary file so dst is never partial
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open archived attachment: %w", err)
+	}
+	defer in.Close()
+
+	tmp := dst + ".tmp"
+	if err := extractFile(in, tmp); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to store attachment: %w", err)
+	}
+	return nil
+}
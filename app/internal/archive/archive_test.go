@@ -0,0 +1,215 @@
+package archive
+
+import (
+	"bytes"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/assets"
+	"github.com/stwalsh4118/clio/internal/blobs"
+	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/logging"
+	_ "modernc.org/sqlite"
+)
+
+func setupTestDB(t *testing.T) (*sql.DB, string) {
+	dir := t.TempDir()
+	database, err := sql.Open("sqlite", filepath.Join(dir, "clio.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+	if err := db.RunMigrations(database); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	return database, filepath.Join(dir, "assets")
+}
+
+func mustExec(t *testing.T, database *sql.DB, query string, args ...interface{}) {
+	t.Helper()
+	if _, err := database.Exec(query, args...); err != nil {
+		t.Fatalf("failed to exec %q: %v", query, err)
+	}
+}
+
+func countRows(t *testing.T, database *sql.DB, table string) int {
+	t.Helper()
+	var n int
+	if err := database.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&n); err != nil {
+		t.Fatalf("failed to count %s: %v", table, err)
+	}
+	return n
+}
+
+func TestArchiver_CreateAndImport(t *testing.T) {
+	source, sourceAssets := setupTestDB(t)
+	base := time.Date(2024, 5, 1, 9, 0, 0, 0, time.Local)
+	for i, id := range []string{"s1", "s2", "s3"} {
+		start := base.Add(time.Duration(i) * 24 * time.Hour)
+		project := "clio"
+		if id == "s3" {
+			project = "other"
+		}
+		mustExec(t, source, `
+			INSERT INTO sessions (id, project, start_time, end_time, last_activity, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, id, project, start, start.Add(time.Hour), start.Add(time.Hour), start, start)
+		mustExec(t, source, `
+			INSERT INTO conversations (id, session_id, composer_id, name, message_count, created_at, updated_at)
+			VALUES (?, ?, ?, 'Chat', 1, ?, ?)
+		`, "conv-"+id, id, "composer-"+id, start, start)
+		mustExec(t, source, `
+			INSERT INTO messages (id, conversation_id, bubble_id, type, role, content, created_at)
+			VALUES (?, ?, 'b1', 1, 'user', 'hello', ?)
+		`, "msg-"+id, "conv-"+id, start)
+		mustExec(t, source, `
+			INSERT INTO commits (id, session_id, repository_path, repository_name, hash, message,
+				author_name, author_email, timestamp, branch, created_at, updated_at)
+			VALUES (?, ?, '/src/clio', 'clio', ?, 'Work', 'Dev', 'dev@example.com', ?, 'main', ?, ?)
+		`, "commit-"+id, id, "hash-"+id, start, start, start)
+		mustExec(t, source, `
+			INSERT INTO commit_files (id, commit_id, file_path, lines_added, created_at) VALUES (?, ?, 'main.go', 3, ?)
+		`, "file-"+id, "commit-"+id, start)
+	}
+	mustExec(t, source, `
+		INSERT INTO test_runs (id, session_id, format, source_path, run_time, total, failed, created_at)
+		VALUES ('run-1', 's1', 'gotest', 'out.json', ?, 2, 1, ?)
+	`, base, base)
+	mustExec(t, source, "INSERT INTO test_cases (run_id, name, status) VALUES ('run-1', 'TestA', 'fail'), ('run-1', 'TestB', 'pass')")
+	mustExec(t, source, "INSERT INTO journal_entries (id, session_id, text, created_at) VALUES ('j1', 's1', 'note', ?)", base)
+
+	store, err := assets.NewStore(source, sourceAssets, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	screenshot := filepath.Join(t.TempDir(), "shot.png")
+	if err := os.WriteFile(screenshot, []byte("\x89PNG\r\n\x1a\nimage"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	attachment, err := store.Attach("s2", screenshot, "")
+	if err != nil {
+		t.Fatalf("Attach() error = %v", err)
+	}
+
+	// s1's message was moved to the blob store, which the target doesn't have
+	blobStore, err := blobs.NewStore(filepath.Join(t.TempDir(), "blobs"), logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("blobs.NewStore() error = %v", err)
+	}
+	long := strings.Repeat("long message ", 1000)
+	ref, err := blobStore.Put([]byte(long))
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	mustExec(t, source, "UPDATE messages SET content = ?, content_blob = ? WHERE id = 'msg-s1'", blobs.Preview(long), ref)
+
+	archiver, err := NewArchiver(source, sourceAssets, blobStore, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewArchiver() error = %v", err)
+	}
+	if _, err := archiver.Create(&bytes.Buffer{}, Options{Project: "missing"}); err == nil {
+		t.Error("Create() with no matching sessions should fail")
+	}
+
+	var bundle bytes.Buffer
+	manifest, err := archiver.Create(&bundle, Options{Project: "CLIO"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if len(manifest.Sessions) != 2 || manifest.Sessions[0].ID != "s1" || manifest.Sessions[1].Commits != 1 || manifest.Attachments != 1 {
+		t.Fatalf("manifest = %+v, want s1 and s2 with one attachment", manifest)
+	}
+
+	read, err := ReadManifest(bytes.NewReader(bundle.Bytes()))
+	if err != nil {
+		t.Fatalf("ReadManifest() error = %v", err)
+	}
+	if read.SchemaVersion != manifest.SchemaVersion || len(read.Sessions) != 2 {
+		t.Errorf("ReadManifest() = %+v, want the written manifest", read)
+	}
+
+	target, targetAssets := setupTestDB(t)
+	// The target already captured s1's commit under its own ID
+	mustExec(t, target, `
+		INSERT INTO commits (id, repository_path, repository_name, hash, message,
+			author_name, author_email, timestamp, branch, created_at, updated_at)
+		VALUES ('local', '/home/me/clio', 'clio', 'hash-s1', 'Work', 'Dev', 'dev@example.com', ?, 'main', ?, ?)
+	`, base, base, base)
+	targetBlobs, err := blobs.NewStore(filepath.Join(t.TempDir(), "blobs"), logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("blobs.NewStore() error = %v", err)
+	}
+	importer, err := NewArchiver(target, targetAssets, targetBlobs, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewArchiver() error = %v", err)
+	}
+	result, err := importer.Import(bytes.NewReader(bundle.Bytes()))
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if result.Imported != 2 || result.Skipped != 0 || result.Attachments != 1 {
+		t.Errorf("result = %+v, want 2 sessions and 1 attachment imported", result)
+	}
+
+	for table, want := range map[string]int{
+		"sessions": 2, "conversations": 2, "messages": 2, "commits": 2, "commit_files": 1,
+		"test_runs": 1, "test_cases": 2, "journal_entries": 1, "attachments": 1,
+	} {
+		if got := countRows(t, target, table); got != want {
+			t.Errorf("%s rows = %d, want %d", table, got, want)
+		}
+	}
+
+	var content string
+	var contentBlob sql.NullString
+	if err := target.QueryRow("SELECT content, content_blob FROM messages WHERE id = 'msg-s1'").Scan(&content, &contentBlob); err != nil {
+		t.Fatalf("failed to query message: %v", err)
+	}
+	if content != long || contentBlob.Valid {
+		t.Errorf("imported message has %d bytes and blob %v, want the full message inline", len(content), contentBlob)
+	}
+
+	var stored string
+	if err := target.QueryRow("SELECT path FROM attachments").Scan(&stored); err != nil {
+		t.Fatalf("failed to query attachment: %v", err)
+	}
+	if want := filepath.Join(targetAssets, attachment.SHA256[:2], filepath.Base(attachment.Path)); stored != want {
+		t.Errorf("attachment path = %s, want %s", stored, want)
+	}
+	if _, err := os.Stat(stored); err != nil {
+		t.Errorf("imported attachment content missing: %v", err)
+	}
+
+	again, err := importer.Import(bytes.NewReader(bundle.Bytes()))
+	if err != nil {
+		t.Fatalf("second Import() error = %v", err)
+	}
+	if again.Imported != 0 || again.Skipped != 2 {
+		t.Errorf("second import = %+v, want both sessions skipped", again)
+	}
+	if got := countRows(t, target, "test_cases"); got != 2 {
+		t.Errorf("test_cases after reimport = %d, want 2", got)
+	}
+}
+
+func TestArchiver_ImportRejectsInvalidBundles(t *testing.T) {
+	database, assetsDir := setupTestDB(t)
+	blobStore, err := blobs.NewStore(filepath.Join(t.TempDir(), "blobs"), logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("blobs.NewStore() error = %v", err)
+	}
+	archiver, err := NewArchiver(database, assetsDir, blobStore, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewArchiver() error = %v", err)
+	}
+	if _, err := archiver.Import(bytes.NewReader([]byte("not an archive"))); err == nil {
+		t.Error("Import() of garbage should fail")
+	}
+	if _, err := ReadManifest(bytes.NewReader(nil)); err == nil {
+		t.Error("ReadManifest() of an empty file should fail")
+	}
+}
@@ -0,0 +1,239 @@
+// Package assets stores files attached to sessions, such as screenshots of UI work,
+// so exporters can embed them alongside the session's conversations and commits.
+package assets
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+const (
+	// sniffLength is how much of a file is read to detect its media type
+	sniffLength = 512
+	// assetDirPerm is the permission for the assets directory and its shards
+	assetDirPerm = 0755
+	// assetFilePerm is the permission for stored files
+	assetFilePerm = 0644
+)
+
+// Attachment is a file attached to a session
+type Attachment struct {
+	ID         string
+	SessionID  string
+	SHA256     string
+	FileName   string // Base name of the attached file
+	MediaType  string // e.g. "image/png"
+	Size       int64
+	Path       string // Location of the stored copy
+	Caption    string
+	AttachedAt time.Time
+	Duplicate  bool // The session already had this content; nothing new was stored
+}
+
+// IsImage reports whether the attachment is an image
+func (a *Attachment) IsImage() bool {
+	return strings.HasPrefix(a.MediaType, "image/")
+}
+
+// Store defines the interface for attaching files to sessions
+type Store interface {
+	// Attach copies the file at path into the store and links it to a session.
+	// Content is stored once by SHA-256, however many sessions it's attached to.
+	Attach(sessionID, path, caption string) (*Attachment, error)
+	// List returns a session's attachments, oldest first
+	List(sessionID string) ([]Attachment, error)
+}
+
+// store implements Store with content-addressed files under a directory
+type store struct {
+	db     *sql.DB
+	dir    string
+	logger logging.Logger
+}
+
+// NewStore creates a store that keeps files under dir, which is created on first use
+func NewStore(db *sql.DB, dir string, logger logging.Logger) (Store, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database cannot be nil")
+	}
+	if dir == "" {
+		return nil, fmt.Errorf("assets directory cannot be empty")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	return &store{
+		db:     db,
+		dir:    dir,
+		logger: logger.With("component", "assets"),
+	}, nil
+}
+
+// Attach stores a file and records the attachment
+func (s *store) Attach(sessionID, path, caption string) (*Attachment, error) {
+	var exists bool
+	if err := s.db.QueryRow("SELECT EXISTS(SELECT 1 FROM sessions WHERE id = ?)", sessionID).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to look up session: %w", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("session %s not found", sessionID)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("%s is not a regular file", path)
+	}
+
+	sum, mediaType, err := hashFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	attachment := &Attachment{
+		ID:         uuid.New().String(),
+		SessionID:  sessionID,
+		SHA256:     sum,
+		FileName:   filepath.Base(path),
+		MediaType:  mediaType,
+		Size:       info.Size(),
+		Path:       filepath.Join(s.dir, sum[:2], sum+strings.ToLower(filepath.Ext(path))),
+		Caption:    caption,
+		AttachedAt: time.Now(),
+	}
+
+	if err := s.copyContent(path, attachment.Path); err != nil {
+		return nil, err
+	}
+
+	res, err := s.db.Exec(`
+		INSERT OR IGNORE INTO attachments (id, session_id, sha256, file_name, media_type, size, path, caption, attached_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, attachment.ID, sessionID, sum, attachment.FileName, mediaType, attachment.Size, attachment.Path,
+		sql.NullString{String: caption, Valid: caption != ""}, attachment.AttachedAt, attachment.AttachedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record attachment: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		attachment.Duplicate = true
+	}
+
+	s.logger.Debug("attached file", "session_id", sessionID, "sha256", sum, "duplicate", attachment.Duplicate)
+	return attachment, nil
+}
+
+// List returns a session's attachments
+func (s *store) List(sessionID string) ([]Attachment, error) {
+	rows, err := s.db.Query(`
+		SELECT id, session_id, sha256, file_name, media_type, size, path, caption, attached_at
+		FROM attachments
+		WHERE session_id = ?
+	`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query attachments: %w", err)
+	}
+	defer rows.Close()
+
+	var attachments []Attachment
+	for rows.Next() {
+		var a Attachment
+		var caption sql.NullString
+		if err := rows.Scan(&a.ID, &a.SessionID, &a.SHA256, &a.FileName, &a.MediaType, &a.Size, &a.Path, &caption, &a.AttachedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan attachment: %w", err)
+		}
+		a.Caption = caption.String
+		attachments = append(attachments, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating attachments: %w", err)
+	}
+
+	db.SortOldestFirst(attachments, func(a Attachment) time.Time { return a.AttachedAt })
+	return attachments, nil
+}
+
+// copyContent copies src to dst unless dst already holds the content
+func (s *store) copyContent(src, dst string) error {
+	if _, err := os.Stat(dst); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), assetDirPerm); err != nil {
+		return fmt.Errorf("failed to create assets directory: %w", err)
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer in.Close()
+
+	// Written to a temporary file first so a failed copy never leaves a partial asset
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".attach-*")
+	if err != nil {
+		return fmt.Errorf("failed to create asset: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, in); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to copy file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write asset: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), assetFilePerm); err != nil {
+		return fmt.Errorf("failed to set asset permissions: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), dst); err != nil {
+		return fmt.Errorf("failed to store asset: %w", err)
+	}
+	return nil
+}
+
+// hashFile returns the file's SHA-256 and media type. The type comes from the
+// extension, falling back to sniffing the content.
+func hashFile(path string) (string, string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", "", fmt.Errorf("failed to read file: %w", err)
+	}
+	head = head[:n]
+
+	hash := sha256.New()
+	hash.Write(head)
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", "", fmt.Errorf("failed to read file: %w", err)
+	}
+
+	mediaType := mime.TypeByExtension(strings.ToLower(filepath.Ext(path)))
+	if mediaType == "" {
+		mediaType = http.DetectContentType(head)
+	}
+	if parsed, _, err := mime.ParseMediaType(mediaType); err == nil {
+		mediaType = parsed
+	}
+	return hex.EncodeToString(hash.Sum(nil)), mediaType, nil
+}
@@ -0,0 +1,119 @@
+package assets
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/logging"
+	_ "modernc.org/sqlite"
+)
+
+func setupTestStore(t *testing.T) (Store, *sql.DB, string) {
+	dir := t.TempDir()
+	database, err := sql.Open("sqlite", filepath.Join(dir, "clio.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+	if err := db.RunMigrations(database); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	now := time.Now()
+	for _, id := range []string{"session-1", "session-2"} {
+		if _, err := database.Exec(`
+			INSERT INTO sessions (id, project, start_time, last_activity, created_at, updated_at)
+			VALUES (?, 'clio', ?, ?, ?, ?)
+		`, id, now, now, now, now); err != nil {
+			t.Fatalf("failed to create session: %v", err)
+		}
+	}
+
+	assetsDir := filepath.Join(dir, "assets")
+	store, err := NewStore(database, assetsDir, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	return store, database, assetsDir
+}
+
+func TestStore_Attach(t *testing.T) {
+	store, _, assetsDir := setupTestStore(t)
+
+	src := filepath.Join(t.TempDir(), "Screenshot.PNG")
+	content := []byte("\x89PNG\r\n\x1a\nfake image")
+	if err := os.WriteFile(src, content, 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	first, err := store.Attach("session-1", src, "New settings page")
+	if err != nil {
+		t.Fatalf("Attach() error = %v", err)
+	}
+	if !first.IsImage() || first.FileName != "Screenshot.PNG" || first.Size != int64(len(content)) || first.Duplicate {
+		t.Errorf("attachment = %+v, want a new PNG image", first)
+	}
+	if want := filepath.Join(assetsDir, first.SHA256[:2], first.SHA256+".png"); first.Path != want {
+		t.Errorf("stored at %q, want content-addressed path %q", first.Path, want)
+	}
+	stored, err := os.ReadFile(first.Path)
+	if err != nil || string(stored) != string(content) {
+		t.Fatalf("stored copy = %q (%v), want the original content", stored, err)
+	}
+
+	again, err := store.Attach("session-1", src, "")
+	if err != nil {
+		t.Fatalf("second Attach() error = %v", err)
+	}
+	if !again.Duplicate {
+		t.Error("attaching the same content to the same session should be a duplicate")
+	}
+
+	other, err := store.Attach("session-2", src, "")
+	if err != nil {
+		t.Fatalf("Attach() to another session error = %v", err)
+	}
+	if other.Duplicate || other.Path != first.Path {
+		t.Errorf("attachment = %+v, want a new attachment sharing the stored content", other)
+	}
+
+	attachments, err := store.List("session-1")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(attachments) != 1 || attachments[0].Caption != "New settings page" {
+		t.Errorf("attachments = %+v, want the captioned screenshot", attachments)
+	}
+}
+
+func TestStore_AttachErrors(t *testing.T) {
+	store, _, _ := setupTestStore(t)
+
+	src := filepath.Join(t.TempDir(), "notes")
+	if err := os.WriteFile(src, []byte("plain text notes"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	if _, err := store.Attach("missing", src, ""); err == nil {
+		t.Error("Attach() to an unknown session should fail")
+	}
+	if _, err := store.Attach("session-1", filepath.Join(t.TempDir(), "missing.png"), ""); err == nil {
+		t.Error("Attach() of a missing file should fail")
+	}
+	if _, err := store.Attach("session-1", t.TempDir(), ""); err == nil {
+		t.Error("Attach() of a directory should fail")
+	}
+
+	// Files without a known extension are sniffed
+	attachment, err := store.Attach("session-1", src, "")
+	if err != nil {
+		t.Fatalf("Attach() error = %v", err)
+	}
+	if attachment.MediaType != "text/plain" || attachment.IsImage() {
+		t.Errorf("media type = %q, want text/plain", attachment.MediaType)
+	}
+}
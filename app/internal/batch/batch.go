@@ -0,0 +1,264 @@
+// Package batch exports sessions to a directory, one file per session, with a
+// JSON manifest and a Markdown index page. Sessions whose content hasn't changed
+// since the last export to the directory are skipped, so re-running an export
+// only rewrites what changed.
+package batch
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stwalsh4118/clio/pkg/export"
+)
+
+const (
+	// FormatVersion is the manifest layout written by Export; Export refuses newer layouts
+	FormatVersion = 1
+	// ManifestName is the manifest file written to the export directory
+	ManifestName = "manifest.json"
+	// IndexName is the Markdown index page written to the export directory
+	IndexName = "index.md"
+
+	// timeLayout is the timestamp format used in the index page
+	timeLayout = "2006-01-02 15:04"
+	// idPrefixLength is how much of a session ID goes into its file name
+	idPrefixLength = 8
+	// dirPerm and filePerm are the permissions of the directory and the files written
+	dirPerm  = 0755
+	filePerm = 0644
+)
+
+// extensions maps built-in format names to file extensions; other formats use .txt
+var extensions = map[string]string{
+	"json":     ".json",
+	"markdown": ".md",
+	"mermaid":  ".mmd",
+	"dot":      ".dot",
+}
+
+// slugSeparators matches the runs of characters replaced by '-' in file names
+var slugSeparators = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Manifest lists the sessions exported to a directory
+type Manifest struct {
+	FormatVersion int       `json:"format_version"`
+	Format        string    `json:"format"` // Exporter every session file was written with
+	UpdatedAt     time.Time `json:"updated_at"`
+	Sessions      []Entry   `json:"sessions"` // Oldest first
+}
+
+// Entry is an exported session as listed in the manifest
+type Entry struct {
+	ID            string     `json:"id"`
+	Project       string     `json:"project"`
+	StartTime     time.Time  `json:"start_time"`
+	EndTime       *time.Time `json:"end_time,omitempty"`
+	Conversations int        `json:"conversations"`
+	Commits       int        `json:"commits"`
+	File          string     `json:"file"`         // Relative to the export directory
+	ContentHash   string     `json:"content_hash"` // SHA-256 of the format and the session's data
+	ExportedAt    time.Time  `json:"exported_at"`
+}
+
+// Result reports what an export wrote
+type Result struct {
+	Manifest  *Manifest
+	Written   int // Sessions written because they were new or changed
+	Unchanged int // Sessions skipped because their content hash matched
+}
+
+// Export writes each session in data to dir with exporter, then rewrites the
+// manifest and index page. Sessions already in the manifest with the same
+// content hash and an existing file are skipped; entries for sessions not in
+// data are kept, so repeated exports of different ranges build up one index.
+// A directory exported in another format is an error.
+func Export(dir string, exporter export.Exporter, data *export.Data) (*Result, error) {
+	if err := os.MkdirAll(dir, dirPerm); err != nil {
+		return nil, fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	manifest, err := ReadManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+	if manifest == nil {
+		manifest = &Manifest{FormatVersion: FormatVersion, Format: exporter.Name(), Sessions: []Entry{}}
+	}
+	if manifest.Format != exporter.Name() {
+		return nil, fmt.Errorf("%s holds a %s export; use --format %s or another directory", dir, manifest.Format, manifest.Format)
+	}
+
+	entries := make(map[string]int, len(manifest.Sessions))
+	for i, entry := range manifest.Sessions {
+		entries[entry.ID] = i
+	}
+
+	result := &Result{Manifest: manifest}
+	for _, session := range data.Sessions {
+		hash, err := contentHash(exporter.Name(), session)
+		if err != nil {
+			return nil, err
+		}
+
+		i, known := entries[session.ID]
+		if known && manifest.Sessions[i].ContentHash == hash && fileExists(filepath.Join(dir, manifest.Sessions[i].File)) {
+			result.Unchanged++
+			continue
+		}
+
+		entry := Entry{
+			ID:            session.ID,
+			Project:       session.Project,
+			StartTime:     session.StartTime,
+			EndTime:       session.EndTime,
+			Conversations: len(session.Conversations),
+			Commits:       len(session.Commits),
+			File:          FileName(session, exporter.Name()),
+			ContentHash:   hash,
+			ExportedAt:    data.GeneratedAt,
+		}
+		if err := writeSession(filepath.Join(dir, entry.File), exporter, data.GeneratedAt, session); err != nil {
+			return nil, err
+		}
+		if known {
+			// The file name changes with the session's project
+			if previous := manifest.Sessions[i].File; previous != entry.File {
+				if err := os.Remove(filepath.Join(dir, previous)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+					return nil, fmt.Errorf("failed to remove previous export of session %s: %w", session.ID, err)
+				}
+			}
+			manifest.Sessions[i] = entry
+		} else {
+			entries[session.ID] = len(manifest.Sessions)
+			manifest.Sessions = append(manifest.Sessions, entry)
+		}
+		result.Written++
+	}
+
+	sort.SliceStable(manifest.Sessions, func(i, j int) bool {
+		return manifest.Sessions[i].StartTime.Before(manifest.Sessions[j].StartTime)
+	})
+	manifest.UpdatedAt = data.GeneratedAt
+
+	if err := writeManifest(dir, manifest); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, IndexName), []byte(Index(manifest)), filePerm); err != nil {
+		return nil, fmt.Errorf("failed to write index: %w", err)
+	}
+	return result, nil
+}
+
+// ReadManifest reads the manifest of an export directory, returning nil when
+// the directory has none
+func ReadManifest(dir string) (*Manifest, error) {
+	content, err := os.ReadFile(filepath.Join(dir, ManifestName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to decode manifest: %w", err)
+	}
+	if manifest.FormatVersion > FormatVersion {
+		return nil, fmt.Errorf("manifest format version %d is newer than this clio supports (%d); upgrade clio", manifest.FormatVersion, FormatVersion)
+	}
+	return &manifest, nil
+}
+
+// FileName returns the file a session is exported to:
+// <start date>-<project>-<session ID prefix><extension>
+func FileName(session export.Session, format string) string {
+	extension, ok := extensions[format]
+	if !ok {
+		extension = ".txt"
+	}
+	id := session.ID
+	if len(id) > idPrefixLength {
+		id = id[:idPrefixLength]
+	}
+	project := strings.Trim(slugSeparators.ReplaceAllString(strings.ToLower(session.Project), "-"), "-")
+	if project == "" {
+		project = "session"
+	}
+	return fmt.Sprintf("%s-%s-%s%s", session.StartTime.Local().Format("2006-01-02"), project, id, extension)
+}
+
+// Index renders the manifest as a Markdown page linking each session's file,
+// newest first
+func Index(manifest *Manifest) string {
+	var b strings.Builder
+	b.WriteString("# Clio Export\n\n")
+	fmt.Fprintf(&b, "Updated %s, %d session(s) in %s format. See %s for details.\n\n",
+		manifest.UpdatedAt.Local().Format(timeLayout), len(manifest.Sessions), manifest.Format, ManifestName)
+	b.WriteString("| Started | Project | Session | Conversations | Commits |\n")
+	b.WriteString("| --- | --- | --- | --- | --- |\n")
+	for i := len(manifest.Sessions) - 1; i >= 0; i-- {
+		entry := manifest.Sessions[i]
+		id := entry.ID
+		if len(id) > idPrefixLength {
+			id = id[:idPrefixLength]
+		}
+		fmt.Fprintf(&b, "| %s | %s | [%s](<%s>) | %d | %d |\n", entry.StartTime.Local().Format(timeLayout),
+			strings.ReplaceAll(entry.Project, "|", `\|`), id, entry.File, entry.Conversations, entry.Commits)
+	}
+	return b.String()
+}
+
+// contentHash hashes the format with the session's data, so a session is
+// rewritten when anything exported about it changes
+func contentHash(format string, session export.Session) (string, error) {
+	encoded, err := json.Marshal(session)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode session %s: %w", session.ID, err)
+	}
+	hash := sha256.New()
+	hash.Write([]byte(format + "\n"))
+	hash.Write(encoded)
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// writeSession exports a single session to path
+func writeSession(path string, exporter export.Exporter, generatedAt time.Time, session export.Session) error {
+	var buf bytes.Buffer
+	if err := exporter.Export(&buf, &export.Data{GeneratedAt: generatedAt, Sessions: []export.Session{session}}); err != nil {
+		return fmt.Errorf("failed to export session %s as %s: %w", session.ID, exporter.Name(), err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), filePerm); err != nil {
+		return fmt.Errorf("failed to write session %s: %w", session.ID, err)
+	}
+	return nil
+}
+
+// writeManifest writes the manifest to the export directory
+func writeManifest(dir string, manifest *Manifest) error {
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ManifestName), append(content, '\n'), filePerm); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
+
+// fileExists reports whether path exists
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
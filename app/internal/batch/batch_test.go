@@ -0,0 +1,134 @@
+package batch
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/pkg/export"
+)
+
+func testData(generatedAt time.Time) *export.Data {
+	base := time.Date(2024, 5, 1, 9, 0, 0, 0, time.Local)
+	return &export.Data{
+		GeneratedAt: generatedAt,
+		Sessions: []export.Session{
+			{ID: "session-one-id", Project: "My App", StartTime: base,
+				Conversations: []export.Conversation{{ComposerID: "c1", Name: "Refactor"}}},
+			{ID: "s2", Project: "clio", StartTime: base.Add(24 * time.Hour),
+				Commits: []export.Commit{{Hash: "abc", Message: "Fix"}}},
+		},
+	}
+}
+
+func mustLookup(t *testing.T, format string) export.Exporter {
+	t.Helper()
+	exporter, ok := export.Lookup(format)
+	if !ok {
+		t.Fatalf("exporter %q not registered", format)
+	}
+	return exporter
+}
+
+func TestExport(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "out")
+	markdown := mustLookup(t, "markdown")
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.Local)
+
+	result, err := Export(dir, markdown, testData(now))
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if result.Written != 2 || result.Unchanged != 0 {
+		t.Fatalf("first export wrote %d, skipped %d; want 2 written", result.Written, result.Unchanged)
+	}
+
+	manifest, err := ReadManifest(dir)
+	if err != nil || manifest == nil {
+		t.Fatalf("ReadManifest() = %v, %v", manifest, err)
+	}
+	if len(manifest.Sessions) != 2 || manifest.Format != "markdown" {
+		t.Fatalf("manifest = %+v, want two markdown sessions", manifest)
+	}
+	if got, want := manifest.Sessions[0].File, "2024-05-01-my-app-session-.md"; got != want {
+		t.Errorf("file name = %q, want %q", got, want)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, manifest.Sessions[0].File))
+	if err != nil || !strings.Contains(string(content), "### Refactor") || strings.Contains(string(content), "Fix") {
+		t.Errorf("session file = %q, %v; want only the first session", content, err)
+	}
+
+	index, err := os.ReadFile(filepath.Join(dir, IndexName))
+	if err != nil {
+		t.Fatalf("failed to read index: %v", err)
+	}
+	first := strings.Index(string(index), "2024-05-02-clio-s2.md")
+	second := strings.Index(string(index), "2024-05-01-my-app-session-.md")
+	if first < 0 || second < 0 || first > second {
+		t.Errorf("index = %q, want both sessions linked newest first", index)
+	}
+
+	// Unchanged sessions are skipped even though the export time differs
+	data := testData(now.Add(time.Hour))
+	data.Sessions[1].Commits = append(data.Sessions[1].Commits, export.Commit{Hash: "def", Message: "Follow-up"})
+	result, err = Export(dir, markdown, data)
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if result.Written != 1 || result.Unchanged != 1 {
+		t.Errorf("second export wrote %d, skipped %d; want the changed session only", result.Written, result.Unchanged)
+	}
+	if result.Manifest.Sessions[0].ExportedAt.Equal(now.Add(time.Hour)) || !result.Manifest.Sessions[1].ExportedAt.Equal(now.Add(time.Hour)) {
+		t.Errorf("exported times = %+v, want only the changed session updated", result.Manifest.Sessions)
+	}
+
+	// Sessions outside a later export stay in the manifest
+	data.Sessions = data.Sessions[1:]
+	if result, err = Export(dir, markdown, data); err != nil || len(result.Manifest.Sessions) != 2 {
+		t.Errorf("partial export manifest = %+v, %v; want both sessions kept", result, err)
+	}
+
+	// A removed file is rewritten
+	if err := os.Remove(filepath.Join(dir, "2024-05-02-clio-s2.md")); err != nil {
+		t.Fatalf("failed to remove file: %v", err)
+	}
+	if result, err = Export(dir, markdown, data); err != nil || result.Written != 1 {
+		t.Errorf("export after removal = %+v, %v; want the file rewritten", result, err)
+	}
+
+	if _, err := Export(dir, mustLookup(t, "json"), data); err == nil {
+		t.Error("Export() in another format succeeded, want an error")
+	}
+}
+
+func TestExport_RenamedProject(t *testing.T) {
+	dir := t.TempDir()
+	exporter := mustLookup(t, "json")
+	data := testData(time.Now())
+	if _, err := Export(dir, exporter, data); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	data.Sessions[1].Project = "renamed"
+	if _, err := Export(dir, exporter, data); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "2024-05-02-clio-s2.json")); !os.IsNotExist(err) {
+		t.Errorf("previous file still exists (err = %v), want it removed", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "2024-05-02-renamed-s2.json")); err != nil {
+		t.Errorf("renamed file missing: %v", err)
+	}
+}
+
+func TestReadManifest_NewerVersion(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ManifestName), []byte(`{"format_version": 99}`), 0644); err != nil {
+		t.Fatalf("failed to write manifest: %v", err)
+	}
+	if _, err := ReadManifest(dir); err == nil {
+		t.Error("ReadManifest() succeeded for a newer format version, want an error")
+	}
+}
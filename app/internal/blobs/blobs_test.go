@@ -0,0 +1,169 @@
+package blobs
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/logging"
+	_ "modernc.org/sqlite"
+)
+
+func newTestStore(t *testing.T) (Store, string) {
+	dir := filepath.Join(t.TempDir(), "blobs")
+	store, err := NewStore(dir, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	return store, dir
+}
+
+func TestStore_PutGet(t *testing.T) {
+	store, dir := newTestStore(t)
+	content := []byte(strings.Repeat("diff --git a/main.go b/main.go\n", 100))
+
+	ref, err := store.Put(content)
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	again, err := store.Put(content)
+	if err != nil || again != ref {
+		t.Fatalf("second Put() = %s, %v, want the same reference", again, err)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, ref[:2], "*"))
+	if len(files) != 1 {
+		t.Errorf("stored files = %v, want one blob", files)
+	}
+
+	got, err := store.Get(ref)
+	if err != nil || string(got) != string(content) {
+		t.Fatalf("Get() = %d bytes, %v, want the stored content", len(got), err)
+	}
+
+	if _, err := store.Get(strings.Repeat("0", 64)); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() of a missing blob error = %v, want ErrNotFound", err)
+	}
+	if _, err := store.Get("../../etc/passwd"); err == nil {
+		t.Error("Get() should reject invalid references")
+	}
+
+	// A blob whose content no longer matches its name is reported, not returned
+	other, err := store.Put([]byte("other"))
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := os.Rename(filepath.Join(dir, other[:2], other+blobExtension), filepath.Join(dir, ref[:2], ref+blobExtension)); err != nil {
+		t.Fatalf("failed to swap blobs: %v", err)
+	}
+	if _, err := store.Get(ref); err == nil {
+		t.Error("Get() should fail for corrupt blobs")
+	}
+}
+
+func TestPreview(t *testing.T) {
+	short := "short message"
+	if got := Preview(short); got != short {
+		t.Errorf("Preview(%q) = %q", short, got)
+	}
+
+	// A three-byte rune straddles the preview length
+	long := strings.Repeat("a", PreviewLength-1) + "€" + strings.Repeat("b", 10)
+	got := Preview(long)
+	if len(got) != PreviewLength-1 || !strings.HasSuffix(got, "a") {
+		t.Errorf("Preview() = %d bytes ending %q, want the rune left out", len(got), got[len(got)-1:])
+	}
+}
+
+func TestCompactor_Compact(t *testing.T) {
+	database, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "clio.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+	if err := db.RunMigrations(database); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	store, _ := newTestStore(t)
+
+	now := time.Now()
+	long := strings.Repeat("x", 2*PreviewLength)
+	for id, content := range map[string]string{"small": "hello", "big": long} {
+		if _, err := database.Exec(`
+			INSERT INTO messages (id, conversation_id, bubble_id, type, role, content, created_at)
+			VALUES (?, 'c1', ?, 1, 'agent', ?, ?)
+		`, id, id, content, now); err != nil {
+			t.Fatalf("failed to insert message: %v", err)
+		}
+	}
+
+	if _, err := NewCompactor(database, store, PreviewLength-1, logging.NewNoopLogger()); err == nil {
+		t.Error("NewCompactor() should reject thresholds below the preview length")
+	}
+	compactor, err := NewCompactor(database, store, PreviewLength, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewCompactor() error = %v", err)
+	}
+
+	result, err := compactor.Compact()
+	if err != nil {
+		t.Fatalf("Compact() error = %v", err)
+	}
+	if result.Values != 1 || result.BytesSaved != int64(len(long)-PreviewLength) {
+		t.Errorf("result = %+v, want one value moved", result)
+	}
+
+	var content string
+	var ref sql.NullString
+	if err := database.QueryRow("SELECT content, content_blob FROM messages WHERE id = 'big'").Scan(&content, &ref); err != nil {
+		t.Fatalf("failed to query message: %v", err)
+	}
+	if len(content) != PreviewLength || !ref.Valid {
+		t.Fatalf("compacted row has %d bytes and blob %v, want a preview and a reference", len(content), ref)
+	}
+	full, err := Resolve(store, content, ref)
+	if err != nil || full != long {
+		t.Errorf("Resolve() = %d bytes, %v, want the full message", len(full), err)
+	}
+	if got, _ := Resolve(nil, content, ref); got != content {
+		t.Error("Resolve() without a store should return the preview")
+	}
+
+	if again, err := compactor.Compact(); err != nil || again.Values != 0 {
+		t.Errorf("second Compact() = %+v, %v, want nothing moved", again, err)
+	}
+
+	// Inlining restores the full value for copies that leave the machine
+	if _, err := database.Exec("ATTACH DATABASE ? AS copy", filepath.Join(t.TempDir(), "copy.db")); err != nil {
+		t.Fatalf("failed to attach database: %v", err)
+	}
+	if _, err := database.Exec("CREATE TABLE copy.messages AS SELECT * FROM main.messages"); err != nil {
+		t.Fatalf("failed to copy messages: %v", err)
+	}
+	for _, col := range columns[1:] {
+		if _, err := database.Exec("CREATE TABLE copy." + col.table + " AS SELECT * FROM main." + col.table); err != nil {
+			t.Fatalf("failed to copy %s: %v", col.table, err)
+		}
+	}
+	tx, err := database.Begin()
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	if err := Inline(tx, "copy", store); err != nil {
+		tx.Rollback()
+		t.Fatalf("Inline() error = %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+	if err := database.QueryRow("SELECT content, content_blob FROM copy.messages WHERE id = 'big'").Scan(&content, &ref); err != nil {
+		t.Fatalf("failed to query copied message: %v", err)
+	}
+	if content != long || ref.Valid {
+		t.Errorf("inlined row has %d bytes and blob %v, want the full message", len(content), ref)
+	}
+}
@@ -0,0 +1,174 @@
+package blobs
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+// column is a text column whose oversized values are moved to the store; ref is
+// the column holding the blob reference
+type column struct {
+	table string
+	value string
+	ref   string
+}
+
+// columns lists the columns compacted into the store
+var columns = []column{
+	{table: "messages", value: "content", ref: "content_blob"},
+	{table: "message_revisions", value: "content", ref: "content_blob"},
+	{table: "commits", value: "full_diff", ref: "full_diff_blob"},
+	{table: "commit_files", value: "diff", ref: "diff_blob"},
+}
+
+// CompactResult summarizes a compaction
+type CompactResult struct {
+	Values     int   // Values moved to the store
+	BytesSaved int64 // Bytes removed from the database
+}
+
+// Compactor defines the interface for moving oversized values out of the database
+type Compactor interface {
+	// Compact moves every value over the threshold that isn't in the store yet,
+	// including rows written before the store existed
+	Compact() (*CompactResult, error)
+}
+
+// compactor implements Compactor
+type compactor struct {
+	db        *sql.DB
+	store     Store
+	threshold int
+	logger    logging.Logger
+}
+
+// NewCompactor creates a compactor moving values over threshold bytes to store
+func NewCompactor(db *sql.DB, store Store, threshold int, logger logging.Logger) (Compactor, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database cannot be nil")
+	}
+	if store == nil {
+		return nil, fmt.Errorf("blob store cannot be nil")
+	}
+	if threshold < PreviewLength {
+		return nil, fmt.Errorf("threshold must be at least %d bytes, got: %d", PreviewLength, threshold)
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	return &compactor{
+		db:        db,
+		store:     store,
+		threshold: threshold,
+		logger:    logger.With("component", "blob_compactor"),
+	}, nil
+}
+
+// Compact compacts each column in turn
+func (c *compactor) Compact() (*CompactResult, error) {
+	result := &CompactResult{}
+	for _, col := range columns {
+		if err := c.compactColumn(col, result); err != nil {
+			return result, err
+		}
+	}
+	if result.Values > 0 {
+		c.logger.Info("moved oversized values to blob store", "values", result.Values, "bytes_saved", result.BytesSaved)
+	}
+	return result, nil
+}
+
+// compactColumn moves a column's oversized values one row at a time, so capture
+// writing the same tables is never blocked for long
+func (c *compactor) compactColumn(col column, result *CompactResult) error {
+	// IDs are collected first so a single connection database isn't holding a
+	// result set while rows are updated
+	rows, err := c.db.Query(fmt.Sprintf(
+		"SELECT id FROM %s WHERE %s IS NULL AND LENGTH(CAST(%s AS BLOB)) > ?", col.table, col.ref, col.value), c.threshold)
+	if err != nil {
+		return fmt.Errorf("failed to query oversized %s.%s: %w", col.table, col.value, err)
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan %s id: %w", col.table, err)
+		}
+		ids = append(ids, id)
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return fmt.Errorf("failed to iterate oversized %s.%s: %w", col.table, col.value, err)
+	}
+
+	for _, id := range ids {
+		var value string
+		err := c.db.QueryRow(fmt.Sprintf("SELECT %s FROM %s WHERE id = ?", col.value, col.table), id).Scan(&value)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s %s: %w", col.table, id, err)
+		}
+
+		ref, err := c.store.Put([]byte(value))
+		if err != nil {
+			return err
+		}
+		// The value must be unchanged, in case capture rewrote the row meanwhile
+		preview := Preview(value)
+		res, err := c.db.Exec(fmt.Sprintf("UPDATE %s SET %s = ?, %s = ? WHERE id = ? AND %s = ?", col.table, col.value, col.ref, col.value),
+			preview, ref, id, value)
+		if err != nil {
+			return fmt.Errorf("failed to update %s %s: %w", col.table, id, err)
+		}
+		if n, err := res.RowsAffected(); err == nil && n > 0 {
+			result.Values++
+			result.BytesSaved += int64(len(value) - len(preview))
+		}
+	}
+	return nil
+}
+
+// Inline puts the full values back into the rows of a database attached as schema
+// and clears their blob references, for copies of rows leaving this machine such
+// as archive bundles. Rows whose blob is missing from the store keep their previews.
+func Inline(tx *sql.Tx, schema string, store Store) error {
+	for _, col := range columns {
+		rows, err := tx.Query(fmt.Sprintf("SELECT id, %s FROM %s.%s WHERE %s IS NOT NULL", col.ref, schema, col.table, col.ref))
+		if err != nil {
+			return fmt.Errorf("failed to query %s blobs: %w", col.table, err)
+		}
+		refs := make(map[string]string)
+		for rows.Next() {
+			var id, ref string
+			if err := rows.Scan(&id, &ref); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan %s blob: %w", col.table, err)
+			}
+			refs[id] = ref
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return fmt.Errorf("failed to iterate %s blobs: %w", col.table, err)
+		}
+
+		update := fmt.Sprintf("UPDATE %s.%s SET %s = COALESCE(?, %s), %s = NULL WHERE id = ?", schema, col.table, col.value, col.value, col.ref)
+		for id, ref := range refs {
+			var value sql.NullString
+			if content, err := store.Get(ref); err == nil {
+				value = sql.NullString{String: string(content), Valid: true}
+			}
+			if _, err := tx.Exec(update, value, id); err != nil {
+				return fmt.Errorf("failed to inline %s %s: %w", col.table, id, err)
+			}
+		}
+	}
+	return nil
+}
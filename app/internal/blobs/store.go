@@ -0,0 +1,175 @@
+// Package blobs keeps oversized message content and diffs out of SQLite. Values
+// over a size threshold are moved to gzip-compressed, content-addressed files;
+// their rows keep a short preview and the blob's SHA-256, and readers that need
+// the full value load it from the store.
+package blobs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"unicode/utf8"
+
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+const (
+	// DefaultThreshold is the size in bytes above which values are moved to the store
+	DefaultThreshold = 256 * 1024
+	// PreviewLength is the size in bytes of the preview kept in a row
+	PreviewLength = 4096
+	// blobExtension marks stored blobs as gzip-compressed
+	blobExtension = ".gz"
+	// blobDirPerm is the permission for the blobs directory and its shards
+	blobDirPerm = 0755
+	// blobFilePerm is the permission for stored blobs
+	blobFilePerm = 0644
+)
+
+// ErrNotFound is returned when the store has no blob for a reference
+var ErrNotFound = errors.New("blob not found")
+
+// refPattern matches blob references: lowercase SHA-256 hex digests
+var refPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// Store defines the interface for content-addressed blob storage
+type Store interface {
+	// Put stores content and returns its reference, the SHA-256 of the content.
+	// Storing the same content twice keeps a single copy.
+	Put(content []byte) (string, error)
+	// Get returns the content stored under ref, verified against its hash
+	Get(ref string) ([]byte, error)
+}
+
+// store implements Store with gzip-compressed files sharded by hash prefix
+type store struct {
+	dir    string
+	logger logging.Logger
+}
+
+// NewStore creates a store that keeps blobs under dir, which is created on first use
+func NewStore(dir string, logger logging.Logger) (Store, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("blobs directory cannot be empty")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	return &store{
+		dir:    dir,
+		logger: logger.With("component", "blobs"),
+	}, nil
+}
+
+// Put writes content through a temporary file so a blob is never partial
+func (s *store) Put(content []byte) (string, error) {
+	sum := sha256.Sum256(content)
+	ref := hex.EncodeToString(sum[:])
+	path := s.path(ref)
+	if _, err := os.Stat(path); err == nil {
+		return ref, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), blobDirPerm); err != nil {
+		return "", fmt.Errorf("failed to create blobs directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ref+".*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("failed to create blob: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	gz := gzip.NewWriter(tmp)
+	if _, err := gz.Write(content); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("failed to write blob: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("failed to write blob: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("failed to write blob: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), blobFilePerm); err != nil {
+		return "", fmt.Errorf("failed to set blob permissions: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("failed to store blob: %w", err)
+	}
+
+	s.logger.Debug("stored blob", "ref", ref, "size", len(content))
+	return ref, nil
+}
+
+// Get reads and decompresses a blob
+func (s *store) Get(ref string) ([]byte, error) {
+	if !refPattern.MatchString(ref) {
+		return nil, fmt.Errorf("invalid blob reference %q", ref)
+	}
+
+	file, err := os.Open(s.path(ref))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, ref)
+		}
+		return nil, fmt.Errorf("failed to open blob: %w", err)
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blob %s: %w", ref, err)
+	}
+	defer gz.Close()
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, gz); err != nil {
+		return nil, fmt.Errorf("failed to read blob %s: %w", ref, err)
+	}
+	if sum := sha256.Sum256(buf.Bytes()); hex.EncodeToString(sum[:]) != ref {
+		return nil, fmt.Errorf("blob %s is corrupt", ref)
+	}
+	return buf.Bytes(), nil
+}
+
+// path returns where a blob is stored
+func (s *store) path(ref string) string {
+	return filepath.Join(s.dir, ref[:2], ref+blobExtension)
+}
+
+// Preview returns the start of content, at most PreviewLength bytes, cut on a
+// UTF-8 boundary
+func Preview(content string) string {
+	if len(content) <= PreviewLength {
+		return content
+	}
+	cut := PreviewLength
+	for cut > 0 && !utf8.RuneStart(content[cut]) {
+		cut--
+	}
+	return content[:cut]
+}
+
+// Resolve returns a column's full value: the blob when ref is set and the store
+// can load it, otherwise the value stored in the row. A nil store always returns
+// the row's value, so callers that only need previews don't have to open one.
+func Resolve(store Store, value string, ref sql.NullString) (string, error) {
+	if store == nil || !ref.Valid || ref.String == "" {
+		return value, nil
+	}
+	content, err := store.Get(ref.String)
+	if err != nil {
+		return value, err
+	}
+	return string(content), nil
+}
@@ -0,0 +1,37 @@
+package blog
+
+import "testing"
+
+func TestUnifiedDiff(t *testing.T) {
+	from := "title\none\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\n"
+	to := "title\none\n2\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\neleven\n"
+
+	want := `--- a
++++ b
+@@ -1,6 +1,6 @@
+ title
+ one
+-two
++2
+ three
+ four
+ five
+@@ -9,3 +9,4 @@
+ eight
+ nine
+ ten
++eleven
+`
+	if got := UnifiedDiff("a", "b", from, to); got != want {
+		t.Errorf("UnifiedDiff() =\n%s\nwant\n%s", got, want)
+	}
+
+	if got := UnifiedDiff("a", "b", from, from); got != "" {
+		t.Errorf("UnifiedDiff() of equal text = %q, want empty", got)
+	}
+
+	want = "--- a\n+++ b\n@@ -0,0 +1,1 @@\n+new\n"
+	if got := UnifiedDiff("a", "b", "", "new\n"); got != want {
+		t.Errorf("UnifiedDiff() from empty = %q, want %q", got, want)
+	}
+}
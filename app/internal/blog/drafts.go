@@ -0,0 +1,308 @@
+package blog
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+const (
+	// StatusDraft marks a draft whose file still holds the generated text
+	StatusDraft = "draft"
+	// StatusEdited marks a draft the author has changed since it was generated
+	StatusEdited = "edited"
+	// StatusPublished marks a draft the author has published
+	StatusPublished = "published"
+)
+
+// Draft is a generated post tracked by clio
+type Draft struct {
+	ID            int64
+	TopicID       int64 // Zero when the draft isn't from a plan, or its topic was deleted
+	Path          string
+	Title         string
+	Status        string // StatusDraft, StatusEdited, or StatusPublished
+	Generated     string // The latest generated text
+	GeneratedHash string // SHA-256 of Generated
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	PublishedAt   time.Time // Zero until published
+}
+
+// WriteResult is the outcome of writing a generated draft
+type WriteResult struct {
+	Draft   *Draft
+	Written bool   // The file was written
+	Diff    string // Unified diff from the file to the generated text when it wasn't written
+}
+
+// Drafter defines the interface for tracking generated drafts
+type Drafter interface {
+	// Write stores generated text as the draft at path. A new draft, or one the
+	// author hasn't edited, is written to the file; an edited or published draft
+	// is left alone and the diff from it to the generated text is returned.
+	Write(topicID int64, title, path, generated string) (*WriteResult, error)
+	// Get returns the draft with id, its status checked against its file
+	Get(id int64) (*Draft, error)
+	// List returns the drafts, newest first, their statuses checked against their files
+	List() ([]Draft, error)
+	// Publish marks a draft as published
+	Publish(id int64, at time.Time) error
+}
+
+// drafter implements Drafter on top of the clio database
+type drafter struct {
+	db     *sql.DB
+	logger logging.Logger
+}
+
+// NewDrafter creates a draft tracker backed by the database
+func NewDrafter(db *sql.DB, logger logging.Logger) (Drafter, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database cannot be nil")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	return &drafter{
+		db:     db,
+		logger: logger.With("component", "blog_drafts"),
+	}, nil
+}
+
+// Write writes or diffs a generated draft
+func (d *drafter) Write(topicID int64, title, path, generated string) (*WriteResult, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve draft path: %w", err)
+	}
+	now := time.Now()
+
+	draft, err := d.byPath(path)
+	if err != nil {
+		return nil, err
+	}
+	current, exists, err := readDraftFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// A file that was never generated, or was changed since, is the author's
+	edited := exists && (draft == nil || hashText(current) != draft.GeneratedHash)
+	published := draft != nil && draft.Status == StatusPublished
+	result := &WriteResult{}
+	if exists && (edited || published) {
+		result.Diff = UnifiedDiff(path, path+" (regenerated)", current, generated)
+	} else {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create draft directory: %w", err)
+		}
+		if err := os.WriteFile(path, []byte(generated), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write draft: %w", err)
+		}
+		result.Written = true
+	}
+
+	status := StatusDraft
+	switch {
+	case published:
+		status = StatusPublished
+	case edited && current != generated:
+		status = StatusEdited
+	}
+
+	if draft == nil {
+		draft = &Draft{Path: path, CreatedAt: now}
+	}
+	draft.TopicID = topicID
+	draft.Title = title
+	draft.Status = status
+	draft.Generated = generated
+	draft.GeneratedHash = hashText(generated)
+	draft.UpdatedAt = now
+	if err := d.save(draft); err != nil {
+		return nil, err
+	}
+
+	d.logger.Debug("wrote blog draft", "path", path, "written", result.Written, "status", status)
+	result.Draft = draft
+	return result, nil
+}
+
+// Get loads a draft and refreshes its status
+func (d *drafter) Get(id int64) (*Draft, error) {
+	draft, err := scanDraft(d.db.QueryRow(`
+		SELECT id, topic_id, path, title, status, generated, generated_hash, created_at, updated_at, published_at
+		FROM drafts WHERE id = ?
+	`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("draft %d not found", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up draft: %w", err)
+	}
+	if err := d.refresh(draft); err != nil {
+		return nil, err
+	}
+	return draft, nil
+}
+
+// List loads the drafts and refreshes their statuses
+func (d *drafter) List() ([]Draft, error) {
+	rows, err := d.db.Query(`
+		SELECT id, topic_id, path, title, status, generated, generated_hash, created_at, updated_at, published_at
+		FROM drafts
+		ORDER BY id DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query drafts: %w", err)
+	}
+	defer rows.Close()
+
+	var drafts []Draft
+	for rows.Next() {
+		draft, err := scanDraft(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan draft: %w", err)
+		}
+		drafts = append(drafts, *draft)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating drafts: %w", err)
+	}
+	rows.Close()
+
+	for i := range drafts {
+		if err := d.refresh(&drafts[i]); err != nil {
+			return nil, err
+		}
+	}
+	return drafts, nil
+}
+
+// Publish records when a draft was published
+func (d *drafter) Publish(id int64, at time.Time) error {
+	draft, err := d.Get(id)
+	if err != nil {
+		return err
+	}
+	if _, err := d.db.Exec("UPDATE drafts SET status = ?, published_at = ?, updated_at = ? WHERE id = ?",
+		StatusPublished, at, at, draft.ID); err != nil {
+		return fmt.Errorf("failed to publish draft: %w", err)
+	}
+	return nil
+}
+
+// refresh marks an unpublished draft edited once its file stops matching the
+// generated text, and a draft again when it matches
+func (d *drafter) refresh(draft *Draft) error {
+	if draft.Status == StatusPublished {
+		return nil
+	}
+	current, exists, err := readDraftFile(draft.Path)
+	if err != nil {
+		return err
+	}
+	// A deleted file is regenerated as a fresh draft
+	status := StatusDraft
+	if exists && hashText(current) != draft.GeneratedHash {
+		status = StatusEdited
+	}
+	if status == draft.Status {
+		return nil
+	}
+
+	if _, err := d.db.Exec("UPDATE drafts SET status = ? WHERE id = ?", status, draft.ID); err != nil {
+		return fmt.Errorf("failed to update draft status: %w", err)
+	}
+	draft.Status = status
+	return nil
+}
+
+// byPath returns the draft tracked at path, or nil when there is none
+func (d *drafter) byPath(path string) (*Draft, error) {
+	draft, err := scanDraft(d.db.QueryRow(`
+		SELECT id, topic_id, path, title, status, generated, generated_hash, created_at, updated_at, published_at
+		FROM drafts WHERE path = ?
+	`, path))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up draft: %w", err)
+	}
+	return draft, nil
+}
+
+// save inserts a new draft or updates a tracked one
+func (d *drafter) save(draft *Draft) error {
+	topicID := sql.NullInt64{Int64: draft.TopicID, Valid: draft.TopicID != 0}
+	if draft.ID != 0 {
+		if _, err := d.db.Exec(`
+			UPDATE drafts
+			SET topic_id = ?, title = ?, status = ?, generated = ?, generated_hash = ?, updated_at = ?
+			WHERE id = ?
+		`, topicID, draft.Title, draft.Status, draft.Generated, draft.GeneratedHash, draft.UpdatedAt, draft.ID); err != nil {
+			return fmt.Errorf("failed to update draft: %w", err)
+		}
+		return nil
+	}
+
+	result, err := d.db.Exec(`
+		INSERT INTO drafts (topic_id, path, title, status, generated, generated_hash, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, topicID, draft.Path, draft.Title, draft.Status, draft.Generated, draft.GeneratedHash, draft.CreatedAt, draft.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to store draft: %w", err)
+	}
+	if draft.ID, err = result.LastInsertId(); err != nil {
+		return fmt.Errorf("failed to read draft ID: %w", err)
+	}
+	return nil
+}
+
+// scanDraft reads a draft row
+func scanDraft(row scanner) (*Draft, error) {
+	var draft Draft
+	var topicID sql.NullInt64
+	var published sql.NullTime
+	if err := row.Scan(&draft.ID, &topicID, &draft.Path, &draft.Title, &draft.Status, &draft.Generated,
+		&draft.GeneratedHash, &draft.CreatedAt, &draft.UpdatedAt, &published); err != nil {
+		return nil, err
+	}
+	draft.TopicID = topicID.Int64
+	draft.PublishedAt = published.Time
+	return &draft, nil
+}
+
+// scanner is implemented by *sql.Row and *sql.Rows
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// readDraftFile returns a draft file's content and whether it exists
+func readDraftFile(path string) (string, bool, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read draft: %w", err)
+	}
+	return string(content), true, nil
+}
+
+// hashText returns the hex SHA-256 of text
+func hashText(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,103 @@
+package blog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+func TestDrafter_Write(t *testing.T) {
+	database, _ := setupTestPlanner(t)
+	drafter, err := NewDrafter(database, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewDrafter() error = %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "drafts", "lexer.md")
+
+	// New drafts are written
+	result, err := drafter.Write(0, "Lexer", path, "# Lexer\n\nv1\n")
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if !result.Written || result.Draft.Status != StatusDraft || result.Draft.ID == 0 {
+		t.Fatalf("Write() = %+v, want a written draft", result)
+	}
+
+	// Unedited drafts are regenerated in place
+	result, err = drafter.Write(0, "Lexer", path, "# Lexer\n\nv2\n")
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if content, _ := os.ReadFile(path); !result.Written || string(content) != "# Lexer\n\nv2\n" {
+		t.Fatalf("Write() = %+v, file %q, want the unedited draft overwritten", result, content)
+	}
+
+	// Edited drafts are diffed, not overwritten
+	if err := os.WriteFile(path, []byte("# Lexer\n\nMy intro\n"), 0644); err != nil {
+		t.Fatalf("failed to edit draft: %v", err)
+	}
+	draft, err := drafter.Get(result.Draft.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if draft.Status != StatusEdited {
+		t.Errorf("status = %q, want %q after editing", draft.Status, StatusEdited)
+	}
+	result, err = drafter.Write(0, "Lexer", path, "# Lexer\n\nv3\n")
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if content, _ := os.ReadFile(path); result.Written || string(content) != "# Lexer\n\nMy intro\n" {
+		t.Fatalf("Write() overwrote the edited draft: %q", content)
+	}
+	if !strings.Contains(result.Diff, "-My intro\n+v3\n") || result.Draft.Status != StatusEdited {
+		t.Errorf("Write() = %+v, want a diff to the regenerated draft", result)
+	}
+
+	// Published drafts are never overwritten, even when they match
+	if err := os.WriteFile(path, []byte("# Lexer\n\nv3\n"), 0644); err != nil {
+		t.Fatalf("failed to edit draft: %v", err)
+	}
+	if err := drafter.Publish(draft.ID, time.Now()); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	result, err = drafter.Write(0, "Lexer", path, "# Lexer\n\nv4\n")
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if result.Written || result.Draft.Status != StatusPublished || !strings.Contains(result.Diff, "+v4") {
+		t.Errorf("Write() = %+v, want the published draft diffed", result)
+	}
+
+	drafts, err := drafter.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(drafts) != 1 || drafts[0].Status != StatusPublished || drafts[0].PublishedAt.IsZero() {
+		t.Errorf("List() = %+v, want the published draft", drafts)
+	}
+}
+
+func TestDrafter_WriteOverUntrackedFile(t *testing.T) {
+	database, _ := setupTestPlanner(t)
+	drafter, err := NewDrafter(database, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewDrafter() error = %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "post.md")
+	if err := os.WriteFile(path, []byte("Written by hand\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	result, err := drafter.Write(0, "Post", path, "Generated\n")
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if result.Written || result.Diff == "" || result.Draft.Status != StatusEdited {
+		t.Errorf("Write() = %+v, want the existing file kept and diffed", result)
+	}
+}
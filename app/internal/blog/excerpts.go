@@ -0,0 +1,202 @@
+package blog
+
+import (
+	"database/sql"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/blobs"
+)
+
+const (
+	// maxExcerptLines caps the lines shown per excerpt
+	maxExcerptLines = 20
+	// minExcerptLines skips added blocks too short to be worth showing
+	minExcerptLines = 3
+	// maxExcerptsPerSession bounds the excerpts shown per session
+	maxExcerptsPerSession = 3
+)
+
+var (
+	// hunkHeader matches a unified diff hunk header, capturing the new file's first line
+	hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+	// secretAssignment matches string literals assigned to names that look like
+	// credentials, capturing everything but the value
+	secretAssignment = regexp.MustCompile(`(?i)((?:password|passwd|secret|token|api[_-]?key|access[_-]?key|private[_-]?key)\w*["']?\s*(?::=|=|:)\s*)(["'` + "`" + `])[^"'` + "`" + `]+(["'` + "`" + `])`)
+)
+
+// languages maps the extensions of source files worth excerpting to their
+// Markdown fence language
+var languages = map[string]string{
+	".go": "go", ".py": "python", ".js": "javascript", ".jsx": "jsx", ".ts": "typescript",
+	".tsx": "tsx", ".rs": "rust", ".java": "java", ".kt": "kotlin", ".rb": "ruby", ".c": "c",
+	".h": "c", ".cc": "cpp", ".cpp": "cpp", ".hpp": "cpp", ".cs": "csharp", ".swift": "swift",
+	".sh": "bash", ".sql": "sql", ".php": "php", ".lua": "lua", ".ex": "elixir", ".exs": "elixir",
+	".hs": "haskell", ".scala": "scala", ".vue": "vue", ".svelte": "svelte", ".css": "css",
+	".scss": "scss", ".html": "html", ".yaml": "yaml", ".yml": "yaml", ".toml": "toml",
+}
+
+// generatedFiles matches vendored and generated files, whose code isn't the author's
+var generatedFiles = regexp.MustCompile(`(^|/)(vendor|node_modules|dist|build)/|\.pb\.go$|_generated\.go$|\.gen\.go$|\.min\.(js|css)$`)
+
+// Excerpt is a block of code a commit added, as it reads after the commit
+type Excerpt struct {
+	Path       string
+	CommitHash string
+	Timestamp  time.Time // Commit time
+	StartLine  int       // First shown line in the file after the commit
+	EndLine    int       // Last shown line
+	Language   string    // Markdown fence language
+	Code       string    // Shown lines, with credentials redacted
+	Added      int       // Lines in the added block, shown or not
+}
+
+// Truncated reports whether the excerpt shows only part of its block
+func (e Excerpt) Truncated() bool {
+	return e.EndLine-e.StartLine+1 < e.Added
+}
+
+// ExcerptsFromDiff returns the blocks of consecutive lines a commit's unified
+// diff adds to source files, capped at 20 lines each. Blocks shorter than 3
+// lines, and changes to vendored, generated, or non-source files, are skipped.
+func ExcerptsFromDiff(hash string, timestamp time.Time, diff string) []Excerpt {
+	var excerpts []Excerpt
+	var file, language string
+	var block []string
+	blockStart, line := 0, 0
+	// Header lines run from "diff --git" to the first hunk; inside hunks a
+	// removed "-- comment" line reads like a header
+	inHeader := false
+
+	flush := func() {
+		if language != "" && len(block) >= minExcerptLines {
+			shown := block
+			if len(shown) > maxExcerptLines {
+				shown = shown[:maxExcerptLines]
+			}
+			excerpts = append(excerpts, Excerpt{
+				Path:       file,
+				CommitHash: hash,
+				Timestamp:  timestamp,
+				StartLine:  blockStart,
+				EndLine:    blockStart + len(shown) - 1,
+				Language:   language,
+				Code:       sanitize(shown),
+				Added:      len(block),
+			})
+		}
+		block = nil
+	}
+
+	for _, text := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(text, "diff --git "):
+			flush()
+			file, language = "", ""
+			inHeader = true
+		case strings.HasPrefix(text, "@@"):
+			flush()
+			inHeader = false
+			if match := hunkHeader.FindStringSubmatch(text); match != nil {
+				line, _ = strconv.Atoi(match[1])
+			}
+		case inHeader:
+			if strings.HasPrefix(text, "+++ ") {
+				file = strings.TrimPrefix(strings.TrimPrefix(text, "+++ "), "b/")
+				language = ""
+				if file != "/dev/null" && !generatedFiles.MatchString(file) {
+					language = languages[strings.ToLower(path.Ext(file))]
+				}
+			}
+		case strings.HasPrefix(text, "+"):
+			if len(block) == 0 {
+				blockStart = line
+			}
+			block = append(block, text[1:])
+			line++
+		case strings.HasPrefix(text, "-"):
+			flush()
+		case strings.HasPrefix(text, "\\"):
+			// "\ No newline at end of file" isn't a line of the file
+		default:
+			// Context lines end a block and advance the new file
+			flush()
+			line++
+		}
+	}
+	flush()
+	return excerpts
+}
+
+// sanitize joins an excerpt's lines, trimming trailing whitespace and redacting
+// string literals assigned to credential-like names
+func sanitize(lines []string) string {
+	cleaned := make([]string, len(lines))
+	for i, line := range lines {
+		line = strings.TrimRight(line, " \t\r")
+		cleaned[i] = secretAssignment.ReplaceAllString(line, "${1}${2}REDACTED${3}")
+	}
+	return strings.Join(cleaned, "\n")
+}
+
+// LoadExcerpts picks a session's code excerpts from its commits' stored diffs:
+// the largest added block per file, at most 3, in commit order. Merge commits are
+// skipped, since their changes were made in other commits.
+func LoadExcerpts(db *sql.DB, store blobs.Store, sessionID string) ([]Excerpt, error) {
+	rows, err := db.Query(`
+		SELECT hash, timestamp, full_diff, full_diff_blob
+		FROM commits
+		WHERE session_id = ? AND is_merge = 0
+	`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query commits: %w", err)
+	}
+	defer rows.Close()
+
+	best := make(map[string]Excerpt)
+	for rows.Next() {
+		var hash string
+		var timestamp time.Time
+		var diff, diffBlob sql.NullString
+		if err := rows.Scan(&hash, &timestamp, &diff, &diffBlob); err != nil {
+			return nil, fmt.Errorf("failed to scan commit: %w", err)
+		}
+		// A missing blob only leaves the diff's preview to excerpt from
+		content, _ := blobs.Resolve(store, diff.String, diffBlob)
+		for _, excerpt := range ExcerptsFromDiff(hash, timestamp, content) {
+			current, ok := best[excerpt.Path]
+			if !ok || excerpt.Added > current.Added || (excerpt.Added == current.Added && excerpt.Timestamp.Before(current.Timestamp)) {
+				best[excerpt.Path] = excerpt
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating commits: %w", err)
+	}
+
+	excerpts := make([]Excerpt, 0, len(best))
+	for _, excerpt := range best {
+		excerpts = append(excerpts, excerpt)
+	}
+	sort.Slice(excerpts, func(i, j int) bool {
+		if excerpts[i].Added != excerpts[j].Added {
+			return excerpts[i].Added > excerpts[j].Added
+		}
+		return excerpts[i].Path < excerpts[j].Path
+	})
+	if len(excerpts) > maxExcerptsPerSession {
+		excerpts = excerpts[:maxExcerptsPerSession]
+	}
+	sort.SliceStable(excerpts, func(i, j int) bool {
+		if !excerpts[i].Timestamp.Equal(excerpts[j].Timestamp) {
+			return excerpts[i].Timestamp.Before(excerpts[j].Timestamp)
+		}
+		return excerpts[i].Path < excerpts[j].Path
+	})
+	return excerpts, nil
+}
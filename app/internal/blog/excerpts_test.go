@@ -0,0 +1,110 @@
+package blog
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testDiff is a two-file diff as the git poller stores it
+const testDiff = `diff --git a/lexer/scan.go b/lexer/scan.go
+index 1111111..2222222 100644
+--- a/lexer/scan.go
++++ b/lexer/scan.go
+@@ -10,4 +10,8 @@ func scan(s string) {
+ 	for i := 0; i < len(s); {
+-		c := s[i]
++		r, size := utf8.DecodeRuneInString(s[i:])
++		if unicode.IsLetter(r) {
++			ident(s, i)
++		}
++		i += size   
+ 	}
+ }
+-- 
+diff --git a/schema.sql b/schema.sql
+--- a/schema.sql
++++ b/schema.sql
+@@ -1,2 +1,5 @@
+--- old comment
++-- tokens
++CREATE TABLE tokens (id INTEGER);
++INSERT INTO config VALUES ('api_key', 'x');
++const apiKey = "sk-live-123"
+ SELECT 1;
+diff --git a/vendor/lib/lib.go b/vendor/lib/lib.go
+--- /dev/null
++++ b/vendor/lib/lib.go
+@@ -0,0 +1,3 @@
++package lib
++
++func Lib() {}
+`
+
+func TestExcerptsFromDiff(t *testing.T) {
+	at := time.Date(2026, 9, 1, 9, 0, 0, 0, time.UTC)
+	excerpts := ExcerptsFromDiff("abcdef123456", at, testDiff)
+	if len(excerpts) != 2 {
+		t.Fatalf("ExcerptsFromDiff() = %+v, want 2 excerpts", excerpts)
+	}
+
+	scan := excerpts[0]
+	if scan.Path != "lexer/scan.go" || scan.Language != "go" || scan.StartLine != 11 || scan.EndLine != 15 || scan.Added != 5 {
+		t.Errorf("excerpt = %+v, want lexer/scan.go lines 11-15", scan)
+	}
+	if !strings.HasSuffix(scan.Code, "\t\ti += size") {
+		t.Errorf("code = %q, want trailing whitespace trimmed", scan.Code)
+	}
+
+	schema := excerpts[1]
+	if schema.Path != "schema.sql" || schema.StartLine != 1 || schema.Added != 4 {
+		t.Errorf("excerpt = %+v, want schema.sql from line 1", schema)
+	}
+	if strings.Contains(schema.Code, "sk-live-123") || !strings.Contains(schema.Code, `apiKey = "REDACTED"`) {
+		t.Errorf("code = %q, want the credential redacted", schema.Code)
+	}
+}
+
+func TestExcerptsFromDiff_Truncates(t *testing.T) {
+	var diff strings.Builder
+	diff.WriteString("diff --git a/big.py b/big.py\n--- a/big.py\n+++ b/big.py\n@@ -0,0 +1,30 @@\n")
+	for i := 1; i <= 30; i++ {
+		fmt.Fprintf(&diff, "+x%d = %d\n", i, i)
+	}
+
+	excerpts := ExcerptsFromDiff("abc", time.Now(), diff.String())
+	if len(excerpts) != 1 {
+		t.Fatalf("ExcerptsFromDiff() = %+v, want 1 excerpt", excerpts)
+	}
+	excerpt := excerpts[0]
+	if !excerpt.Truncated() || excerpt.EndLine != maxExcerptLines || strings.Count(excerpt.Code, "\n") != maxExcerptLines-1 {
+		t.Errorf("excerpt = %+v, want the first %d of 30 lines", excerpt, maxExcerptLines)
+	}
+}
+
+func TestLoadExcerpts(t *testing.T) {
+	database, _ := setupTestPlanner(t)
+	at := time.Date(2026, 9, 1, 9, 0, 0, 0, time.UTC)
+
+	insert := func(id, hash string, timestamp time.Time, isMerge bool, diff string) {
+		t.Helper()
+		if _, err := database.Exec(`
+			INSERT INTO commits (id, session_id, repository_path, repository_name, hash, message,
+				author_name, author_email, timestamp, branch, is_merge, full_diff, created_at, updated_at)
+			VALUES (?, 's1', '/repo', 'repo', ?, 'msg', 'a', 'a@example.com', ?, 'main', ?, ?, ?, ?)
+		`, id, hash, timestamp, isMerge, diff, timestamp, timestamp); err != nil {
+			t.Fatalf("failed to insert commit: %v", err)
+		}
+	}
+	insert("c1", "1111111aaaa", at, false, testDiff)
+	insert("c2", "2222222bbbb", at.Add(time.Hour), true, "diff --git a/m.go b/m.go\n--- a/m.go\n+++ b/m.go\n@@ -0,0 +1,9 @@\n+a\n+b\n+c\n+d\n+e\n+f\n+g\n+h\n+i\n")
+
+	excerpts, err := LoadExcerpts(database, nil, "s1")
+	if err != nil {
+		t.Fatalf("LoadExcerpts() error = %v", err)
+	}
+	if len(excerpts) != 2 || excerpts[0].Path != "lexer/scan.go" || excerpts[1].Path != "schema.sql" {
+		t.Errorf("LoadExcerpts() = %+v, want one excerpt per file without the merge commit", excerpts)
+	}
+}
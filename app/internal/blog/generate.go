@@ -0,0 +1,125 @@
+package blog
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/stwalsh4118/clio/internal/summaries"
+	"github.com/stwalsh4118/clio/pkg/export"
+	"gopkg.in/yaml.v3"
+)
+
+// draftDateLayout is the date format used in generated posts
+const draftDateLayout = "2006-01-02"
+
+// frontMatter is the YAML front matter of a generated post
+type frontMatter struct {
+	Title string   `yaml:"title"`
+	Date  string   `yaml:"date"`
+	Draft bool     `yaml:"draft"`
+	Tags  []string `yaml:"tags,omitempty"`
+}
+
+// Generate drafts a post skeleton for a topic from its sessions: front matter,
+// then a section per session with its key points, commits, and the code
+// excerpts keyed by session ID, for the author to write up. It works offline and
+// gives the same draft for the same sessions.
+func Generate(topic Topic, sessions []export.Session, excerpts map[string][]Excerpt) (string, error) {
+	sessions = append([]export.Session(nil), sessions...)
+	sort.SliceStable(sessions, func(i, j int) bool { return sessions[i].StartTime.Before(sessions[j].StartTime) })
+
+	date := topic.Start
+	if len(sessions) > 0 {
+		date = sessions[0].StartTime
+	}
+
+	var b strings.Builder
+	b.WriteString("---\n")
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(frontMatter{
+		Title: topic.Title,
+		Date:  date.Local().Format(draftDateLayout),
+		Draft: true,
+		Tags:  topic.Keywords,
+	}); err != nil {
+		return "", fmt.Errorf("failed to encode front matter: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return "", fmt.Errorf("failed to encode front matter: %w", err)
+	}
+	b.WriteString("---\n\n")
+	fmt.Fprintf(&b, "<!-- Drafted by clio from %d session(s). Edit freely: regenerating shows a diff instead of overwriting your changes. -->\n\n", len(sessions))
+	b.WriteString("## Background\n\n")
+
+	for _, session := range sessions {
+		input := sessionInput(session)
+		fmt.Fprintf(&b, "## %s: %s\n\n", session.StartTime.Local().Format(draftDateLayout), summaries.Title(input))
+
+		// Commits are listed below with their hashes rather than rolled up
+		input.Commits = nil
+		points, err := summaries.Extractive{}.Summarize(context.Background(), input)
+		if err != nil {
+			return "", fmt.Errorf("failed to summarize session %s: %w", session.ID, err)
+		}
+		if points != "" {
+			b.WriteString(points + "\n\n")
+		}
+
+		if len(session.Commits) > 0 {
+			b.WriteString("Commits:\n\n")
+			for _, commit := range session.Commits {
+				subject, _, _ := strings.Cut(commit.Message, "\n")
+				fmt.Fprintf(&b, "- `%s` %s\n", shortHash(commit.Hash), strings.TrimSpace(subject))
+			}
+			b.WriteString("\n")
+		}
+
+		for _, excerpt := range excerpts[session.ID] {
+			writeExcerpt(&b, excerpt)
+		}
+	}
+
+	b.WriteString("## What I learned\n")
+	return b.String(), nil
+}
+
+// writeExcerpt writes a code excerpt with the file lines and commit it's from
+func writeExcerpt(b *strings.Builder, excerpt Excerpt) {
+	fmt.Fprintf(b, "`%s` lines %d-%d at `%s`", excerpt.Path, excerpt.StartLine, excerpt.EndLine, shortHash(excerpt.CommitHash))
+	if excerpt.Truncated() {
+		fmt.Fprintf(b, " (first %d of %d added lines)", excerpt.EndLine-excerpt.StartLine+1, excerpt.Added)
+	}
+	b.WriteString(":\n\n")
+
+	// A longer fence keeps code containing fences intact
+	fence := "```"
+	for strings.Contains(excerpt.Code, fence) {
+		fence += "`"
+	}
+	fmt.Fprintf(b, "%s%s\n%s\n%s\n\n", fence, excerpt.Language, excerpt.Code, fence)
+}
+
+// sessionInput converts an exported session for the summarizers
+func sessionInput(session export.Session) *summaries.Input {
+	input := &summaries.Input{Subject: summaries.SubjectSession, ID: session.ID, Title: session.Project}
+	for _, conversation := range session.Conversations {
+		for _, message := range conversation.Messages {
+			input.Messages = append(input.Messages, summaries.Message{Role: message.Role, Text: message.Text, CreatedAt: message.CreatedAt})
+		}
+	}
+	for _, commit := range session.Commits {
+		input.Commits = append(input.Commits, commit.Message)
+	}
+	return input
+}
+
+// shortHash abbreviates a commit hash
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
@@ -0,0 +1,60 @@
+package blog
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/pkg/export"
+)
+
+func TestGenerate(t *testing.T) {
+	start := time.Date(2026, 9, 1, 9, 0, 0, 0, time.Local)
+	sessions := []export.Session{
+		{
+			ID:        "s2",
+			StartTime: start.AddDate(0, 0, 1),
+			Commits:   []export.Commit{{Hash: "0123456789abcdef", Message: "Decode runes in the lexer\n\nBody"}},
+		},
+		{
+			ID:        "s1",
+			StartTime: start,
+			Conversations: []export.Conversation{{Messages: []export.Message{
+				{Role: "user", Text: "The lexer crashes on unicode identifiers. Can you find out why?"},
+				{Role: "agent", Text: "The scanner advances by bytes instead of runes, splitting multibyte identifiers."},
+			}}},
+		},
+	}
+	topic := Topic{Title: `Fixing "unicode" in the lexer`, Keywords: []string{"lexer", "unicode"}}
+
+	excerpts := map[string][]Excerpt{"s2": {{
+		Path: "lexer/scan.go", CommitHash: "0123456789abcdef", StartLine: 11, EndLine: 12, Added: 4,
+		Language: "go", Code: "r, size := utf8.DecodeRuneInString(s)\n// ```",
+	}}}
+
+	draft, err := Generate(topic, sessions, excerpts)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	for _, want := range []string{
+		"---\ntitle: Fixing \"unicode\" in the lexer\ndate: \"2026-09-01\"\ndraft: true\ntags:\n  - lexer\n  - unicode\n---\n",
+		"## 2026-09-01: The scanner advances by bytes instead of runes",
+		"- The lexer crashes on unicode identifiers.\n",
+		"## 2026-09-02: Decode runes in the lexer",
+		"- `0123456` Decode runes in the lexer\n",
+		"`lexer/scan.go` lines 11-12 at `0123456` (first 2 of 4 added lines):\n\n````go\nr, size := utf8.DecodeRuneInString(s)\n// ```\n````\n",
+		"## What I learned\n",
+	} {
+		if !strings.Contains(draft, want) {
+			t.Errorf("Generate() = %s\nwant it to contain %q", draft, want)
+		}
+	}
+	if strings.Index(draft, "2026-09-01:") > strings.Index(draft, "2026-09-02:") {
+		t.Error("Generate() should order sessions oldest first")
+	}
+
+	again, _ := Generate(topic, sessions, excerpts)
+	if again != draft {
+		t.Error("Generate() is not deterministic")
+	}
+}
@@ -0,0 +1,505 @@
+// Package blog plans blog series from captured sessions, grouping related
+// sessions into proposed posts, and tracks the drafts written for them.
+package blog
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/logging"
+	"github.com/stwalsh4118/clio/internal/summaries"
+	"github.com/stwalsh4118/clio/pkg/export"
+)
+
+const (
+	// minSimilarity is the share of their terms two sessions must have in common
+	// to be proposed as one post
+	minSimilarity = 0.2
+	// commonTermShare drops terms found in more than this share of the sessions,
+	// such as the project's name, since they don't tell topics apart
+	commonTermShare = 0.5
+	// minSessionsForCommonTerms is how many sessions a plan needs before common
+	// terms are dropped
+	minSessionsForCommonTerms = 4
+	// maxKeywords bounds the keywords listed for a topic
+	maxKeywords = 5
+	// maxTitleLength truncates suggested titles
+	maxTitleLength = 80
+)
+
+// Topic is a proposed post and the sessions it would be written from
+type Topic struct {
+	ID         int64 // Zero until the plan is saved
+	Position   int   // 1-based place in the series
+	Title      string
+	Keywords   []string  // Terms most shared by the topic's sessions
+	SessionIDs []string  // Oldest first
+	Start      time.Time // Start of the first session
+	End        time.Time // End, or last activity, of the last session
+}
+
+// Plan is a proposed blog series
+type Plan struct {
+	ID        int64 // Zero until the plan is saved
+	Project   string
+	Since     time.Time // Zero when the plan had no lower bound
+	Until     time.Time // Zero when the plan had no upper bound
+	CreatedAt time.Time
+	Topics    []Topic // In series order, oldest work first
+}
+
+// Planner defines the interface for storing blog plans
+type Planner interface {
+	// Save stores a plan and its topics, setting their IDs
+	Save(plan *Plan) error
+	// Get returns the plan with id
+	Get(id int64) (*Plan, error)
+	// Latest returns the most recently created plan
+	Latest() (*Plan, error)
+	// Topic returns the topic with id, from any plan
+	Topic(id int64) (*Topic, error)
+}
+
+// planner implements Planner on top of the clio database
+type planner struct {
+	db     *sql.DB
+	logger logging.Logger
+}
+
+// NewPlanner creates a blog planner backed by the database
+func NewPlanner(db *sql.DB, logger logging.Logger) (Planner, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database cannot be nil")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	return &planner{
+		db:     db,
+		logger: logger.With("component", "blog"),
+	}, nil
+}
+
+// sessionTerms is a session with the terms describing its work
+type sessionTerms struct {
+	session    export.Session
+	terms      map[string]int
+	candidates []string // Possible titles: conversation names or opening prompts, then commit subjects
+}
+
+// Propose groups related sessions into topics. Sessions are related when they
+// share enough of the terms in their conversation names, opening prompts, and
+// commit subjects; a topic is every session linked to another through such
+// pairs. Each topic's title is the conversation name or commit subject covering
+// most of its keywords. Sessions without conversations or commits are left out.
+func Propose(sessions []export.Session) []Topic {
+	var described []sessionTerms
+	for _, session := range sessions {
+		if s := describe(session); len(s.terms) > 0 {
+			described = append(described, s)
+		}
+	}
+	sort.SliceStable(described, func(i, j int) bool {
+		return described[i].session.StartTime.Before(described[j].session.StartTime)
+	})
+	dropCommonTerms(described)
+
+	// Union-find over sessions similar enough to share a post
+	parent := make([]int, len(described))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	for i := range described {
+		for j := i + 1; j < len(described); j++ {
+			if similarity(described[i].terms, described[j].terms) >= minSimilarity {
+				parent[find(j)] = find(i)
+			}
+		}
+	}
+
+	// Sessions are sorted, so groups come out ordered by their first session
+	var order []int
+	groups := make(map[int][]sessionTerms)
+	for i, s := range described {
+		root := find(i)
+		if _, ok := groups[root]; !ok {
+			order = append(order, root)
+		}
+		groups[root] = append(groups[root], s)
+	}
+
+	topics := make([]Topic, 0, len(order))
+	for _, root := range order {
+		topic := newTopic(groups[root])
+		topic.Position = len(topics) + 1
+		topics = append(topics, topic)
+	}
+	return topics
+}
+
+// describe collects the terms and possible titles of a session's work
+func describe(session export.Session) sessionTerms {
+	s := sessionTerms{session: session, terms: make(map[string]int)}
+	for _, conversation := range session.Conversations {
+		candidate := strings.TrimSpace(conversation.Name)
+		if candidate == "" {
+			candidate = openingPrompt(conversation)
+		}
+		if candidate != "" {
+			s.candidates = append(s.candidates, candidate)
+		}
+	}
+	for _, commit := range session.Commits {
+		subject, _, _ := strings.Cut(commit.Message, "\n")
+		if subject = strings.TrimSpace(subject); subject != "" {
+			s.candidates = append(s.candidates, subject)
+		}
+	}
+	for _, candidate := range s.candidates {
+		for _, term := range summaries.Terms(candidate) {
+			s.terms[term]++
+		}
+	}
+	return s
+}
+
+// openingPrompt returns a conversation's first user message on one line
+func openingPrompt(conversation export.Conversation) string {
+	for _, message := range conversation.Messages {
+		if message.Role == "user" {
+			return strings.Join(strings.Fields(message.Text), " ")
+		}
+	}
+	return ""
+}
+
+// dropCommonTerms removes terms most sessions share, once there are enough
+// sessions to tell
+func dropCommonTerms(described []sessionTerms) {
+	if len(described) < minSessionsForCommonTerms {
+		return
+	}
+	frequency := make(map[string]int)
+	for _, s := range described {
+		for term := range s.terms {
+			frequency[term]++
+		}
+	}
+	for _, s := range described {
+		for term := range s.terms {
+			if float64(frequency[term]) > commonTermShare*float64(len(described)) {
+				delete(s.terms, term)
+			}
+		}
+	}
+}
+
+// similarity is the Jaccard index of two sessions' terms
+func similarity(a, b map[string]int) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for term := range a {
+		if _, ok := b[term]; ok {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// newTopic describes a group of related sessions, oldest first
+func newTopic(group []sessionTerms) Topic {
+	var topic Topic
+	sessionCounts := make(map[string]int)
+	totals := make(map[string]int)
+	for _, s := range group {
+		topic.SessionIDs = append(topic.SessionIDs, s.session.ID)
+		for term, count := range s.terms {
+			sessionCounts[term]++
+			totals[term] += count
+		}
+	}
+	topic.Start = group[0].session.StartTime
+	last := group[len(group)-1].session
+	topic.End = last.StartTime
+	if last.EndTime != nil {
+		topic.End = *last.EndTime
+	}
+
+	// Keywords are the terms shared by the most sessions, then used most often
+	terms := make([]string, 0, len(totals))
+	for term := range totals {
+		terms = append(terms, term)
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		a, b := terms[i], terms[j]
+		if sessionCounts[a] != sessionCounts[b] {
+			return sessionCounts[a] > sessionCounts[b]
+		}
+		if totals[a] != totals[b] {
+			return totals[a] > totals[b]
+		}
+		return a < b
+	})
+	if len(terms) > maxKeywords {
+		terms = terms[:maxKeywords]
+	}
+	topic.Keywords = terms
+
+	topic.Title = suggestTitle(group, terms)
+	return topic
+}
+
+// suggestTitle picks the candidate title covering most keywords, preferring
+// earlier candidates
+func suggestTitle(group []sessionTerms, keywords []string) string {
+	best, bestScore := "", -1
+	for _, s := range group {
+		for _, candidate := range s.candidates {
+			terms := make(map[string]bool)
+			for _, term := range summaries.Terms(candidate) {
+				terms[term] = true
+			}
+			score := 0
+			for _, keyword := range keywords {
+				if terms[keyword] {
+					score++
+				}
+			}
+			if score > bestScore {
+				best, bestScore = candidate, score
+			}
+		}
+	}
+	if len(best) > maxTitleLength {
+		cut := strings.LastIndex(best[:maxTitleLength], " ")
+		if cut <= 0 {
+			cut = maxTitleLength
+		}
+		best = strings.TrimSpace(best[:cut]) + "..."
+	}
+	return best
+}
+
+// Save stores a plan in one transaction
+func (p *planner) Save(plan *Plan) error {
+	if plan.CreatedAt.IsZero() {
+		plan.CreatedAt = time.Now()
+	}
+
+	tx, err := p.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		INSERT INTO blog_plans (project, since, until, created_at)
+		VALUES (?, ?, ?, ?)
+	`, nullString(plan.Project), nullTime(plan.Since), nullTime(plan.Until), plan.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to store blog plan: %w", err)
+	}
+	planID, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to read blog plan ID: %w", err)
+	}
+
+	topicIDs := make([]int64, len(plan.Topics))
+	for i, topic := range plan.Topics {
+		result, err := tx.Exec(`
+			INSERT INTO blog_topics (plan_id, position, title, keywords)
+			VALUES (?, ?, ?, ?)
+		`, planID, topic.Position, topic.Title, strings.Join(topic.Keywords, ","))
+		if err != nil {
+			return fmt.Errorf("failed to store blog topic: %w", err)
+		}
+		if topicIDs[i], err = result.LastInsertId(); err != nil {
+			return fmt.Errorf("failed to read blog topic ID: %w", err)
+		}
+		for _, sessionID := range topic.SessionIDs {
+			if _, err := tx.Exec(`
+				INSERT OR IGNORE INTO blog_topic_sessions (topic_id, session_id)
+				VALUES (?, ?)
+			`, topicIDs[i], sessionID); err != nil {
+				return fmt.Errorf("failed to store blog topic session: %w", err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit blog plan: %w", err)
+	}
+
+	plan.ID = planID
+	for i := range plan.Topics {
+		plan.Topics[i].ID = topicIDs[i]
+	}
+	p.logger.Debug("saved blog plan", "plan_id", planID, "topics", len(plan.Topics))
+	return nil
+}
+
+// Get loads a plan and its topics
+func (p *planner) Get(id int64) (*Plan, error) {
+	plan := &Plan{ID: id}
+	var project sql.NullString
+	var since, until sql.NullTime
+	err := p.db.QueryRow("SELECT project, since, until, created_at FROM blog_plans WHERE id = ?", id).
+		Scan(&project, &since, &until, &plan.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("blog plan %d not found", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up blog plan: %w", err)
+	}
+	plan.Project = project.String
+	plan.Since = since.Time
+	plan.Until = until.Time
+
+	if plan.Topics, err = p.topics(id); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// Latest loads the most recently created plan
+func (p *planner) Latest() (*Plan, error) {
+	var id int64
+	err := p.db.QueryRow("SELECT id FROM blog_plans ORDER BY id DESC LIMIT 1").Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("no blog plans yet")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up blog plans: %w", err)
+	}
+	return p.Get(id)
+}
+
+// Topic loads a topic and its sessions
+func (p *planner) Topic(id int64) (*Topic, error) {
+	topic := &Topic{ID: id}
+	var keywords string
+	err := p.db.QueryRow("SELECT position, title, keywords FROM blog_topics WHERE id = ?", id).
+		Scan(&topic.Position, &topic.Title, &keywords)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("blog topic %d not found", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up blog topic: %w", err)
+	}
+	if keywords != "" {
+		topic.Keywords = strings.Split(keywords, ",")
+	}
+	if err := p.topicSessions(topic); err != nil {
+		return nil, err
+	}
+	return topic, nil
+}
+
+// topics loads a plan's topics in series order
+func (p *planner) topics(planID int64) ([]Topic, error) {
+	rows, err := p.db.Query(`
+		SELECT id, position, title, keywords
+		FROM blog_topics
+		WHERE plan_id = ?
+		ORDER BY position ASC
+	`, planID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query blog topics: %w", err)
+	}
+	defer rows.Close()
+
+	var topics []Topic
+	for rows.Next() {
+		var topic Topic
+		var keywords string
+		if err := rows.Scan(&topic.ID, &topic.Position, &topic.Title, &keywords); err != nil {
+			return nil, fmt.Errorf("failed to scan blog topic: %w", err)
+		}
+		if keywords != "" {
+			topic.Keywords = strings.Split(keywords, ",")
+		}
+		topics = append(topics, topic)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating blog topics: %w", err)
+	}
+
+	for i := range topics {
+		if err := p.topicSessions(&topics[i]); err != nil {
+			return nil, err
+		}
+	}
+	return topics, nil
+}
+
+// topicSessions loads a topic's sessions and the span they cover
+func (p *planner) topicSessions(topic *Topic) error {
+	rows, err := p.db.Query(`
+		SELECT ts.session_id, s.start_time, s.end_time, s.last_activity
+		FROM blog_topic_sessions ts
+		LEFT JOIN sessions s ON s.id = ts.session_id
+		WHERE ts.topic_id = ?
+	`, topic.ID)
+	if err != nil {
+		return fmt.Errorf("failed to query blog topic sessions: %w", err)
+	}
+	defer rows.Close()
+
+	type topicSession struct {
+		id         string
+		start, end time.Time
+	}
+	var sessions []topicSession
+	for rows.Next() {
+		var s topicSession
+		var start, end, lastActivity sql.NullTime
+		if err := rows.Scan(&s.id, &start, &end, &lastActivity); err != nil {
+			return fmt.Errorf("failed to scan blog topic session: %w", err)
+		}
+		s.start = start.Time
+		s.end = lastActivity.Time
+		if end.Valid {
+			s.end = end.Time
+		}
+		sessions = append(sessions, s)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating blog topic sessions: %w", err)
+	}
+
+	db.SortOldestFirst(sessions, func(t topicSession) time.Time { return t.start })
+	for _, s := range sessions {
+		topic.SessionIDs = append(topic.SessionIDs, s.id)
+		if topic.Start.IsZero() || (!s.start.IsZero() && s.start.Before(topic.Start)) {
+			topic.Start = s.start
+		}
+		if s.end.After(topic.End) {
+			topic.End = s.end
+		}
+	}
+	return nil
+}
+
+// nullString stores empty strings as NULL
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// nullTime stores zero times as NULL
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
@@ -0,0 +1,144 @@
+package blog
+
+import (
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/logging"
+	"github.com/stwalsh4118/clio/pkg/export"
+	_ "modernc.org/sqlite"
+)
+
+func setupTestPlanner(t *testing.T) (*sql.DB, Planner) {
+	database, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "clio.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if err := db.RunMigrations(database); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	planner, err := NewPlanner(database, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewPlanner() error = %v", err)
+	}
+	return database, planner
+}
+
+// testSession builds a session with one named conversation and commit subjects
+func testSession(id string, start time.Time, name string, commits ...string) export.Session {
+	session := export.Session{ID: id, Project: "clio", StartTime: start}
+	if name != "" {
+		session.Conversations = []export.Conversation{{Name: name}}
+	}
+	for _, message := range commits {
+		session.Commits = append(session.Commits, export.Commit{Message: message})
+	}
+	return session
+}
+
+func TestPropose(t *testing.T) {
+	day := time.Date(2026, 9, 1, 9, 0, 0, 0, time.UTC)
+	sessions := []export.Session{
+		testSession("s3", day.AddDate(0, 0, 2), "Lexer unicode identifiers", "Decode runes in the clio lexer"),
+		testSession("s1", day, "Fix lexer crash on unicode", "Add clio lexer regression tests"),
+		testSession("s2", day.AddDate(0, 0, 1), "Dark mode theme toggle", "Add clio theme settings"),
+		testSession("s4", day.AddDate(0, 0, 3), "", "Persist the dark mode theme choice\n\nStore it in local storage"),
+		{ID: "empty", StartTime: day},
+	}
+
+	topics := Propose(sessions)
+	if len(topics) != 2 {
+		t.Fatalf("Propose() = %d topics, want 2: %+v", len(topics), topics)
+	}
+
+	lexer, theme := topics[0], topics[1]
+	if !reflect.DeepEqual(lexer.SessionIDs, []string{"s1", "s3"}) || !reflect.DeepEqual(theme.SessionIDs, []string{"s2", "s4"}) {
+		t.Errorf("sessions = %v and %v, want [s1 s3] and [s2 s4]", lexer.SessionIDs, theme.SessionIDs)
+	}
+	if lexer.Position != 1 || theme.Position != 2 {
+		t.Errorf("positions = %d, %d, want 1, 2", lexer.Position, theme.Position)
+	}
+	if lexer.Title != "Fix lexer crash on unicode" {
+		t.Errorf("title = %q, want the conversation name covering most keywords", lexer.Title)
+	}
+	if len(lexer.Keywords) < 2 || lexer.Keywords[0] != "lexer" || lexer.Keywords[1] != "unicode" {
+		t.Errorf("keywords = %v, want lexer and unicode first", lexer.Keywords)
+	}
+	for _, keyword := range append(lexer.Keywords, theme.Keywords...) {
+		if keyword == "clio" {
+			t.Errorf("keywords include %q, which every session shares", keyword)
+		}
+	}
+	if !lexer.Start.Equal(day) || !lexer.End.Equal(day.AddDate(0, 0, 2)) {
+		t.Errorf("span = %v - %v, want the first and last session", lexer.Start, lexer.End)
+	}
+}
+
+func TestPlanner_SaveGetLatest(t *testing.T) {
+	database, planner := setupTestPlanner(t)
+
+	start := time.Date(2026, 9, 1, 9, 0, 0, 0, time.UTC)
+	end := start.Add(2 * time.Hour)
+	for i, id := range []string{"s1", "s2"} {
+		sessionStart := start.AddDate(0, 0, i)
+		if _, err := database.Exec(`
+			INSERT INTO sessions (id, project, start_time, end_time, last_activity, created_at, updated_at)
+			VALUES (?, 'clio', ?, ?, ?, ?, ?)
+		`, id, sessionStart, sessionStart.Add(2*time.Hour), sessionStart, sessionStart, sessionStart); err != nil {
+			t.Fatalf("failed to insert session: %v", err)
+		}
+	}
+
+	if _, err := planner.Latest(); err == nil {
+		t.Error("Latest() without plans should fail")
+	}
+
+	plan := &Plan{
+		Project: "clio",
+		Since:   start,
+		Topics: []Topic{
+			{Position: 1, Title: "Fixing the lexer", Keywords: []string{"lexer", "unicode"}, SessionIDs: []string{"s2", "s1"}},
+			{Position: 2, Title: "Dark mode", SessionIDs: []string{"gone"}},
+		},
+	}
+	if err := planner.Save(plan); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if plan.ID == 0 || plan.Topics[0].ID == 0 || plan.Topics[1].ID == 0 {
+		t.Fatalf("Save() didn't set IDs: %+v", plan)
+	}
+
+	got, err := planner.Latest()
+	if err != nil {
+		t.Fatalf("Latest() error = %v", err)
+	}
+	if got.ID != plan.ID || got.Project != "clio" || !got.Since.Equal(start) || !got.Until.IsZero() {
+		t.Errorf("Latest() = %+v, want the saved plan", got)
+	}
+	if len(got.Topics) != 2 {
+		t.Fatalf("topics = %+v, want 2", got.Topics)
+	}
+	lexer := got.Topics[0]
+	if lexer.Title != "Fixing the lexer" || !reflect.DeepEqual(lexer.Keywords, []string{"lexer", "unicode"}) {
+		t.Errorf("topic = %+v, want the saved title and keywords", lexer)
+	}
+	if !reflect.DeepEqual(lexer.SessionIDs, []string{"s1", "s2"}) {
+		t.Errorf("sessions = %v, want oldest first", lexer.SessionIDs)
+	}
+	if !lexer.Start.Equal(start) || !lexer.End.Equal(end.AddDate(0, 0, 1)) {
+		t.Errorf("span = %v - %v, want the sessions' span", lexer.Start, lexer.End)
+	}
+	if !reflect.DeepEqual(got.Topics[1].SessionIDs, []string{"gone"}) {
+		t.Errorf("sessions = %v, want deleted sessions kept", got.Topics[1].SessionIDs)
+	}
+
+	if _, err := planner.Get(plan.ID + 1); err == nil {
+		t.Error("Get() of a missing plan should fail")
+	}
+}
@@ -0,0 +1,243 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stwalsh4118/clio/internal/archive"
+	"github.com/stwalsh4118/clio/internal/filters"
+	"github.com/stwalsh4118/clio/internal/logging"
+	"github.com/stwalsh4118/clio/internal/remote"
+	"github.com/stwalsh4118/clio/internal/report"
+)
+
+// newArchiveCmd creates the archive command with create, import, and info subcommands
+func newArchiveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "archive",
+		Short: "Bundle sessions into a portable archive file",
+		Long: `Bundle selected sessions into a self-contained, compressed archive file for
+sharing or backup, and import archives on another machine.
+
+An archive holds the sessions' conversations, commits, test runs, journal notes,
+heartbeats, and attached files, with the database schema version it was written
+with. Archives are read-only snapshots: importing adds their sessions to the
+database and skips sessions it already has, so importing twice is harmless.
+
+create also uploads to cloud storage when given an s3:// or gs:// URL, using
+the credentials in the remote_storage configuration block. An object already
+at the URL is replaced.
+
+Examples:
+  clio archive create week.clio --project clio --since 7d
+  clio archive create bug.clio --session latest
+  clio archive create gs://team-backups/clio/week.clio --since 7d
+  clio archive info week.clio
+  clio archive import week.clio`,
+	}
+
+	var sessions []string
+	var project, since, until string
+	var force bool
+	create := &cobra.Command{
+		Use:   "create <file.clio>",
+		Short: "Write selected sessions to an archive",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			now := time.Now()
+			opts := archive.Options{Project: project}
+			var err error
+			if opts.Since, err = filters.ParseTime(since, now); err != nil {
+				return usageErrorf("invalid --since: %w", err)
+			}
+			if opts.Until, err = filters.ParseTime(until, now); err != nil {
+				return usageErrorf("invalid --until: %w", err)
+			}
+			if !opts.Since.IsZero() && !opts.Until.IsZero() && !opts.Since.Before(opts.Until) {
+				return usageErrorf("--since must be before --until")
+			}
+			return handleArchiveCreate(args[0], sessions, opts, force)
+		},
+	}
+	create.Flags().StringArrayVar(&sessions, "session", nil, "Archive this session (ID, 'latest', or 'active'); repeatable")
+	create.Flags().StringVar(&project, "project", "", "Only archive this project")
+	create.Flags().StringVar(&since, "since", "", "Only archive sessions starting at or after this time (date, timestamp, or duration like 7d)")
+	create.Flags().StringVar(&until, "until", "", "Only archive sessions starting before this time (date, timestamp, or duration like 7d)")
+	create.Flags().BoolVar(&force, "force", false, "Overwrite an existing file")
+	cmd.AddCommand(create)
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "import <file.clio>",
+		Short: "Add an archive's sessions to the database",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handleArchiveImport(args[0])
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "info <file.clio>",
+		Short: "List an archive's sessions without importing them",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handleArchiveInfo(args[0])
+		},
+	})
+
+	return cmd
+}
+
+// handleArchiveCreate implements archive create
+func handleArchiveCreate(path string, sessionRefs []string, opts archive.Options, force bool) error {
+	if filepath.Ext(path) == "" {
+		path += archive.Extension
+	}
+	upload := remote.IsRemote(path)
+	if upload {
+		if _, err := remote.Parse(path); err != nil {
+			return usageErrorf("%v", err)
+		}
+	} else if _, err := os.Stat(path); err == nil && !force {
+		return usageErrorf("%s already exists; use --force to overwrite it", path)
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	database, err := openDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	if len(sessionRefs) > 0 {
+		reporter, err := report.NewReporter(database, logging.NewNoopLogger())
+		if err != nil {
+			return fmt.Errorf("failed to create reporter: %w", err)
+		}
+		for _, ref := range sessionRefs {
+			id, err := reporter.ResolveSession(ref)
+			if err != nil {
+				return usageErrorf("%v", err)
+			}
+			opts.SessionIDs = append(opts.SessionIDs, id)
+		}
+	}
+
+	store, err := openBlobStore(cfg)
+	if err != nil {
+		return err
+	}
+	archiver, err := archive.NewArchiver(database, cfg.Storage.AssetsPath, store, logging.NewNoopLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create archiver: %w", err)
+	}
+
+	// Write next to the destination and rename, so a failed run leaves no partial archive;
+	// uploads are written to a temporary file first
+	tmpDir := filepath.Dir(path)
+	if upload {
+		tmpDir = ""
+	}
+	tmp, err := os.CreateTemp(tmpDir, "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create archive file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	manifest, err := archiver.Create(tmp, opts)
+	if err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	if upload {
+		body, err := os.ReadFile(tmp.Name())
+		if err != nil {
+			return fmt.Errorf("failed to read archive: %w", err)
+		}
+		if err := uploadToRemote(cfg, path, body); err != nil {
+			return err
+		}
+	} else if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+
+	fmt.Printf("Archived %d session(s) and %d attached file(s) to %s\n", len(manifest.Sessions), manifest.Attachments, path)
+	return nil
+}
+
+// handleArchiveImport implements archive import
+func handleArchiveImport(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return usageErrorf("failed to open archive: %v", err)
+	}
+	defer file.Close()
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	database, err := openDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	store, err := openBlobStore(cfg)
+	if err != nil {
+		return err
+	}
+	archiver, err := archive.NewArchiver(database, cfg.Storage.AssetsPath, store, logging.NewNoopLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create archiver: %w", err)
+	}
+
+	result, err := archiver.Import(file)
+	if err != nil {
+		return fmt.Errorf("failed to import archive: %w", err)
+	}
+
+	fmt.Printf("Imported %d session(s) and %d attached file(s) from %s", result.Imported, result.Attachments, path)
+	if result.Skipped > 0 {
+		fmt.Printf("; %d session(s) were already present", result.Skipped)
+	}
+	fmt.Println()
+	return nil
+}
+
+// handleArchiveInfo implements archive info
+func handleArchiveInfo(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return usageErrorf("failed to open archive: %v", err)
+	}
+	defer file.Close()
+
+	manifest, err := archive.ReadManifest(file)
+	if err != nil {
+		return usageErrorf("%v", err)
+	}
+
+	fmt.Printf("Archive:  %s\n", path)
+	fmt.Printf("Created:  %s\n", formatTime(manifest.CreatedAt))
+	fmt.Printf("Schema:   version %d (format %d)\n", manifest.SchemaVersion, manifest.FormatVersion)
+	fmt.Printf("Sessions: %d, attached files: %d\n\n", len(manifest.Sessions), manifest.Attachments)
+	for _, session := range manifest.Sessions {
+		end := "active"
+		if session.EndTime != nil {
+			end = formatTime(*session.EndTime)
+		}
+		fmt.Printf("  %s  %-16s %s - %s  %d conversation(s), %d commit(s)\n", session.ID, session.Project,
+			formatTime(session.StartTime), end, session.Conversations, session.Commits)
+	}
+	return nil
+}
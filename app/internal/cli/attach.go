@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/stwalsh4118/clio/internal/assets"
+	"github.com/stwalsh4118/clio/internal/logging"
+	"github.com/stwalsh4118/clio/internal/report"
+)
+
+// newAttachCmd creates the attach command
+func newAttachCmd() *cobra.Command {
+	var caption string
+
+	cmd := &cobra.Command{
+		Use:   "attach <session> <file>...",
+		Short: "Attach screenshots or other files to a session",
+		Long: `Attach images or other files to a session so exports can embed them, for
+example screenshots of the UI a session built. Files are copied into the assets
+directory (storage.assets_path) by content hash, so attaching the same file
+again stores nothing new.
+
+The session is a full session ID, a unique ID prefix, or "latest" for the most
+recently started session.
+
+Examples:
+  clio attach latest before.png after.png
+  clio attach 3f2a9c settings.png --caption "Settings page after the redesign"`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handleAttach(args[0], args[1:], caption)
+		},
+	}
+
+	cmd.Flags().StringVarP(&caption, "caption", "c", "", "Caption shown with the attached files in exports")
+
+	return cmd
+}
+
+// handleAttach implements the attach command
+func handleAttach(sessionRef string, paths []string, caption string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	database, err := openDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	reporter, err := report.NewReporter(database, logging.NewNoopLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create reporter: %w", err)
+	}
+	sessionID, err := reporter.ResolveSession(sessionRef)
+	if err != nil {
+		return usageErrorf("%v", err)
+	}
+
+	store, err := assets.NewStore(database, cfg.Storage.AssetsPath, logging.NewNoopLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create asset store: %w", err)
+	}
+
+	for _, path := range paths {
+		attachment, err := store.Attach(sessionID, path, caption)
+		if err != nil {
+			return fmt.Errorf("failed to attach %s: %w", path, err)
+		}
+		if attachment.Duplicate {
+			fmt.Printf("%s: already attached to session %s\n", path, sessionID)
+			continue
+		}
+		fmt.Printf("%s: attached to session %s (%s, %d bytes)\n", path, sessionID, attachment.MediaType, attachment.Size)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/stwalsh4118/clio/internal/git"
+	"github.com/stwalsh4118/clio/internal/report"
+)
+
+// newBisectContextCmd creates the bisect-context command
+func newBisectContextCmd() *cobra.Command {
+	var good []string
+	var repository string
+	var conversations int
+
+	cmd := &cobra.Command{
+		Use:   "bisect-context <bad-commit>",
+		Short: "Show the sessions and conversations behind the commits in a bisect range",
+		Long: `List the commits git bisect chooses between, the ones reachable from the bad
+commit but not from any good commit, with the session each was correlated
+with and the conversations worked in before it. Each conversation is shown
+with its latest prompt before the commit, so it's clear what the commit was
+trying to do while deciding whether it's good or bad.
+
+Good commits default to those marked in the repository's current git bisect
+session; outside one, pass them with --good. Commits clio hasn't captured are
+listed without context.
+
+Examples:
+  clio bisect-context HEAD
+  clio bisect-context HEAD --good v1.4.0
+  clio bisect-context a1b2c3d --good main~20 --conversations 1`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if conversations <= 0 {
+				return usageErrorf("--conversations must be positive")
+			}
+			return handleBisectContext(repository, args[0], good, conversations)
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&good, "good", nil, "Known good commit (repeatable; defaults to the current git bisect's good commits)")
+	cmd.Flags().StringVar(&repository, "repo", "", "Repository path (defaults to the one containing the current directory)")
+	cmd.Flags().IntVar(&conversations, "conversations", report.DefaultBisectConversations, "Conversations to show per commit")
+
+	return cmd
+}
+
+// handleBisectContext implements the bisect-context command
+func handleBisectContext(repository, bad string, good []string, conversations int) error {
+	if repository == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get working directory: %w", err)
+		}
+		repository = cwd
+	}
+	repo, _, err := git.OpenContaining(repository)
+	if err != nil {
+		return usageErrorf("not in a git repository; pass --repo")
+	}
+
+	badHash, err := git.ResolveCommit(repo, bad)
+	if err != nil {
+		return usageErrorf("%v", err)
+	}
+	var goodHashes []string
+	for _, revision := range good {
+		hash, err := git.ResolveCommit(repo, revision)
+		if err != nil {
+			return usageErrorf("%v", err)
+		}
+		goodHashes = append(goodHashes, hash)
+	}
+	if len(goodHashes) == 0 {
+		if goodHashes, err = git.BisectGood(repo); err != nil {
+			return err
+		}
+		if len(goodHashes) == 0 {
+			return usageErrorf("no good commits are marked in a git bisect; pass --good")
+		}
+	}
+
+	commits, err := git.CommitRange(repo, badHash, goodHashes)
+	if err != nil {
+		return err
+	}
+	if len(commits) == 0 {
+		fmt.Printf("%s is reachable from a good commit; there's nothing to bisect\n", shortHash(badHash))
+		return nil
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	database, err := openReadOnlyDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	reporter, err := newBlobReporter(cfg, database)
+	if err != nil {
+		return err
+	}
+	hashes := make([]string, len(commits))
+	for i, commit := range commits {
+		hashes[i] = commit.Hash
+	}
+	captured, err := reporter.BisectContext(report.BisectOptions{Hashes: hashes, Conversations: conversations})
+	if err != nil {
+		return fmt.Errorf("failed to get bisect context: %w", err)
+	}
+	context := make(map[string]report.BisectCommit, len(captured))
+	withSession := 0
+	for _, commit := range captured {
+		context[commit.Hash] = commit
+		if commit.SessionID != "" {
+			withSession++
+		}
+	}
+
+	fmt.Printf("%d commit(s) in range, %d captured, %d with a session\n", len(commits), len(captured), withSession)
+	for _, commit := range commits {
+		fmt.Printf("\n%s  %s  %s", shortHash(commit.Hash), formatTime(commit.When), commitSubject(commit.Message))
+		if commit.Merge {
+			fmt.Print("  (merge)")
+		}
+		fmt.Println()
+
+		info, ok := context[commit.Hash]
+		if !ok {
+			fmt.Println("  Not captured by clio")
+			continue
+		}
+		if info.SessionID == "" {
+			fmt.Println("  No correlated session")
+			continue
+		}
+		fmt.Printf("  Session %s (%s)\n", shortHash(info.SessionID), info.Project)
+		if len(info.Conversations) == 0 {
+			fmt.Println("  No conversations before the commit")
+		}
+		for _, conversation := range info.Conversations {
+			fmt.Printf("  [%s] %q", formatTime(conversation.LastActive), conversation.Name)
+			if conversation.Prompt != "" {
+				fmt.Printf(": %s", conversation.Prompt)
+			}
+			fmt.Println()
+		}
+	}
+	return nil
+}
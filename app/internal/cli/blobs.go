@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/stwalsh4118/clio/internal/blobs"
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/logging"
+	"github.com/stwalsh4118/clio/internal/report"
+)
+
+// newBlobsCmd creates the blobs command with its compact subcommand
+func newBlobsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "blobs",
+		Short: "Manage the store for oversized messages and diffs",
+		Long: `Manage the blob store that keeps oversized agent messages and diffs out of the
+database.
+
+Values larger than storage.blob_threshold_bytes are moved to compressed files
+under storage.blobs_path, named by the SHA-256 of their content. The database
+keeps a short preview of each value; exports, replays, and attribution stats
+load the full value from the store. The daemon compacts new values hourly.
+
+Examples:
+  clio blobs compact`,
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "compact",
+		Short: "Move oversized values, including existing ones, to the blob store",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handleBlobsCompact()
+		},
+	})
+
+	return cmd
+}
+
+// handleBlobsCompact implements blobs compact
+func handleBlobsCompact() error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	database, err := openDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	store, err := openBlobStore(cfg)
+	if err != nil {
+		return err
+	}
+	compactor, err := blobs.NewCompactor(database, store, cfg.Storage.BlobThresholdBytes, logging.NewNoopLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create blob compactor: %w", err)
+	}
+
+	result, err := compactor.Compact()
+	if err != nil {
+		return fmt.Errorf("failed to compact oversized values: %w", err)
+	}
+	if result.Values == 0 {
+		fmt.Println("No values over the threshold to move")
+		return nil
+	}
+	fmt.Printf("Moved %d value(s) to %s, saving %d bytes in the database\n", result.Values, cfg.Storage.BlobsPath, result.BytesSaved)
+	return nil
+}
+
+// openBlobStore opens the configured blob store
+func openBlobStore(cfg *config.Config) (blobs.Store, error) {
+	store, err := blobs.NewStore(cfg.Storage.BlobsPath, logging.NewNoopLogger())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open blob store: %w", err)
+	}
+	return store, nil
+}
+
+// newBlobReporter creates a reporter that loads full values from the blob store
+func newBlobReporter(cfg *config.Config, database *sql.DB) (report.Reporter, error) {
+	store, err := openBlobStore(cfg)
+	if err != nil {
+		return nil, err
+	}
+	reporter, err := report.NewReporterWithBlobs(database, store, logging.NewNoopLogger())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create reporter: %w", err)
+	}
+	return reporter, nil
+}
@@ -0,0 +1,409 @@
+package cli
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stwalsh4118/clio/internal/blog"
+	"github.com/stwalsh4118/clio/internal/filters"
+	"github.com/stwalsh4118/clio/internal/goals"
+	"github.com/stwalsh4118/clio/internal/logging"
+	"github.com/stwalsh4118/clio/internal/report"
+	"github.com/stwalsh4118/clio/internal/scrub"
+	"github.com/stwalsh4118/clio/pkg/export"
+)
+
+// newBlogCmd creates the blog command with plan, show, draft, drafts, and
+// publish subcommands
+func newBlogCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "blog",
+		Short: "Plan and draft blog posts from captured sessions",
+		Long: `Plan a blog series from captured work. 'clio blog plan' groups related
+sessions into proposed posts, each with a suggested title and the sessions it
+would be written from, and stores the plan so posts can be written from it
+over time. 'clio blog show' prints a stored plan again.
+
+'clio blog draft' writes a post skeleton for one of the plan's topics into the
+blog repository. Drafts are tracked as draft, edited, or published: once you
+edit or publish a draft, drafting it again prints a diff from your version to
+the regenerated one instead of overwriting it.
+
+Examples:
+  clio blog plan --project clio --since 2026-09-01
+  clio blog show
+  clio blog draft 12
+  clio blog drafts
+  clio blog publish 3`,
+	}
+
+	var project, since, until string
+	plan := &cobra.Command{
+		Use:   "plan",
+		Short: "Propose blog posts from related sessions",
+		Long: `Group related sessions into proposed blog posts. Sessions are related when
+their conversation names, opening prompts, and commit subjects share enough
+terms. Each proposed post gets a suggested title, its keywords, and its source
+sessions. The plan is stored; see it again with 'clio blog show'.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			now := time.Now()
+			sinceTime, err := filters.ParseTime(since, now)
+			if err != nil {
+				return usageErrorf("invalid --since: %w", err)
+			}
+			untilTime, err := filters.ParseTime(until, now)
+			if err != nil {
+				return usageErrorf("invalid --until: %w", err)
+			}
+			if !sinceTime.IsZero() && !untilTime.IsZero() && !sinceTime.Before(untilTime) {
+				return usageErrorf("--since must be before --until")
+			}
+			return handleBlogPlan(report.ExportOptions{Project: project, Since: sinceTime, Until: untilTime})
+		},
+	}
+	plan.Flags().StringVar(&project, "project", "", "Only plan from this project")
+	plan.Flags().StringVar(&since, "since", "", "Only plan from sessions starting at or after this time (date, timestamp, or duration like 30d)")
+	plan.Flags().StringVar(&until, "until", "", "Only plan from sessions starting before this time (date, timestamp, or duration like 30d)")
+	cmd.AddCommand(plan)
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "show [plan]",
+		Short: "Print a stored blog plan (default: the latest)",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var id int64
+			if len(args) == 1 {
+				parsed, err := strconv.ParseInt(args[0], 10, 64)
+				if err != nil || parsed <= 0 {
+					return usageErrorf("invalid plan ID %q", args[0])
+				}
+				id = parsed
+			}
+			return handleBlogShow(id)
+		},
+	})
+
+	var output string
+	var translateMessages bool
+	draft := &cobra.Command{
+		Use:   "draft <topic>",
+		Short: "Draft a post for a planned topic",
+		Long: `Draft a post skeleton for a topic of a blog plan: front matter, then each
+session's key points, commits, and code excerpts. Excerpts come from the
+commits' diffs rather than code pasted in conversations, so they match what was
+committed; each names its file, lines, and commit. The draft is written to
+<blog_repository>/drafts/<title>.md unless --output is set.
+
+When the draft was edited or published since it was last generated, it is left
+alone and the diff from it to the regenerated draft is printed instead.
+
+Email addresses, names listed in scrub.names, internal hostnames, and
+profanity are scrubbed from the draft, even when they were captured and
+stored (see the scrub configuration block).
+
+--translate drafts from messages translated into translation.target_language
+(default: en) with translation.command, for a post in one language about
+conversations held in another (see clio export --help).`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			topicID, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil || topicID <= 0 {
+				return usageErrorf("invalid topic ID %q", args[0])
+			}
+			return handleBlogDraft(topicID, output, translateMessages)
+		},
+	}
+	draft.Flags().StringVarP(&output, "output", "o", "", "Write the draft to this file")
+	draft.Flags().BoolVar(&translateMessages, "translate", false, "Translate messages in other languages with translation.command")
+	cmd.AddCommand(draft)
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "drafts",
+		Short: "List drafts with their status",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handleBlogDrafts()
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "publish <draft>",
+		Short: "Mark a draft as published",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil || id <= 0 {
+				return usageErrorf("invalid draft ID %q", args[0])
+			}
+			return handleBlogPublish(id)
+		},
+	})
+
+	return cmd
+}
+
+// handleBlogPlan implements blog plan
+func handleBlogPlan(opts report.ExportOptions) error {
+	database, planner, err := openBlogPlanner()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	reporter, err := report.NewReporter(database, logging.NewNoopLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create reporter: %w", err)
+	}
+	data, err := reporter.ExportData(opts)
+	if err != nil {
+		return fmt.Errorf("failed to load sessions: %w", err)
+	}
+
+	topics := blog.Propose(data.Sessions)
+	if len(topics) == 0 {
+		fmt.Println("No sessions with conversations or commits to plan from")
+		return nil
+	}
+
+	plan := &blog.Plan{Project: opts.Project, Since: opts.Since, Until: opts.Until, Topics: topics}
+	if err := planner.Save(plan); err != nil {
+		return err
+	}
+	printBlogPlan(plan)
+	return nil
+}
+
+// handleBlogShow implements blog show; a zero id shows the latest plan
+func handleBlogShow(id int64) error {
+	database, planner, err := openBlogPlanner()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	var plan *blog.Plan
+	if id == 0 {
+		plan, err = planner.Latest()
+	} else {
+		plan, err = planner.Get(id)
+	}
+	if err != nil {
+		return usageErrorf("%v", err)
+	}
+	printBlogPlan(plan)
+	return nil
+}
+
+// handleBlogDraft implements blog draft
+func handleBlogDraft(topicID int64, output string, translateMessages bool) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if output == "" && cfg.BlogRepository == "" {
+		return usageErrorf("no blog repository configured; set one with 'clio config --set-blog-repo' or use --output")
+	}
+	translator, err := newExportTranslator(cfg, translateMessages)
+	if err != nil {
+		return err
+	}
+
+	database, err := openDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	planner, err := blog.NewPlanner(database, logging.NewNoopLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create blog planner: %w", err)
+	}
+	topic, err := planner.Topic(topicID)
+	if err != nil {
+		return usageErrorf("%v", err)
+	}
+
+	store, err := openBlobStore(cfg)
+	if err != nil {
+		return err
+	}
+	reporter, err := report.NewReporterWithBlobs(database, store, logging.NewNoopLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create reporter: %w", err)
+	}
+	var sessions []export.Session
+	excerpts := make(map[string][]blog.Excerpt)
+	for _, sessionID := range topic.SessionIDs {
+		data, err := reporter.ExportData(report.ExportOptions{SessionID: sessionID})
+		if err != nil {
+			return fmt.Errorf("failed to load session %s: %w", sessionID, err)
+		}
+		sessions = append(sessions, data.Sessions...)
+		if excerpts[sessionID], err = blog.LoadExcerpts(database, store, sessionID); err != nil {
+			return fmt.Errorf("failed to load code excerpts for session %s: %w", sessionID, err)
+		}
+	}
+
+	if err := translateExport(cfg, translator, sessions); err != nil {
+		return err
+	}
+
+	generated, err := blog.Generate(*topic, sessions, excerpts)
+	if err != nil {
+		return err
+	}
+	scrubber, err := scrub.New(cfg.Scrub)
+	if err != nil {
+		return fmt.Errorf("failed to create scrubber: %w", err)
+	}
+	generated = scrubber.Text(generated)
+
+	path := output
+	if path == "" {
+		slug := goals.Slug(topic.Title)
+		if slug == "" {
+			slug = fmt.Sprintf("topic-%d", topic.ID)
+		}
+		path = filepath.Join(cfg.BlogRepository, "drafts", slug+".md")
+	}
+
+	drafter, err := blog.NewDrafter(database, logging.NewNoopLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create drafter: %w", err)
+	}
+	result, err := drafter.Write(topic.ID, topic.Title, path, generated)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case result.Written:
+		fmt.Printf("Wrote draft %d to %s\n", result.Draft.ID, result.Draft.Path)
+	case result.Diff == "":
+		fmt.Printf("Draft %d at %s already matches the regenerated draft\n", result.Draft.ID, result.Draft.Path)
+	default:
+		fmt.Fprintf(os.Stderr, "Draft %d at %s is %s; not overwriting it. Changes from it to the regenerated draft:\n", result.Draft.ID, result.Draft.Path, result.Draft.Status)
+		fmt.Print(result.Diff)
+	}
+	return nil
+}
+
+// handleBlogDrafts implements blog drafts
+func handleBlogDrafts() error {
+	database, drafter, err := openBlogDrafter()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	drafts, err := drafter.List()
+	if err != nil {
+		return err
+	}
+	if len(drafts) == 0 {
+		fmt.Println("No drafts. Plan posts with 'clio blog plan', then draft one with 'clio blog draft'.")
+		return nil
+	}
+
+	for _, draft := range drafts {
+		fmt.Printf("%d  %-9s  %s\n", draft.ID, draft.Status, draft.Title)
+		updated := draft.UpdatedAt
+		if draft.Status == blog.StatusPublished {
+			updated = draft.PublishedAt
+		}
+		fmt.Printf("   %s (%s)\n", draft.Path, formatTime(updated))
+	}
+	return nil
+}
+
+// handleBlogPublish implements blog publish
+func handleBlogPublish(id int64) error {
+	database, drafter, err := openBlogDrafter()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	if err := drafter.Publish(id, time.Now()); err != nil {
+		return usageErrorf("%v", err)
+	}
+	fmt.Printf("Marked draft %d as published; drafting it again will show a diff instead of overwriting it\n", id)
+	return nil
+}
+
+// printBlogPlan prints a plan's proposed posts in series order
+func printBlogPlan(plan *blog.Plan) {
+	var scope []string
+	if plan.Project != "" {
+		scope = append(scope, plan.Project)
+	}
+	if !plan.Since.IsZero() {
+		scope = append(scope, "since "+plan.Since.Local().Format(reportDateLayout))
+	}
+	if !plan.Until.IsZero() {
+		scope = append(scope, "until "+plan.Until.Local().Format(reportDateLayout))
+	}
+	header := fmt.Sprintf("Blog plan %d", plan.ID)
+	if len(scope) > 0 {
+		header += " (" + strings.Join(scope, ", ") + ")"
+	}
+	fmt.Printf("%s: %d proposed post(s)\n", header, len(plan.Topics))
+
+	for _, topic := range plan.Topics {
+		fmt.Printf("\n%d. %s  [topic %d]\n", topic.Position, topic.Title, topic.ID)
+		fmt.Printf("   %s - %s, %d session(s)\n", topic.Start.Local().Format(reportDateLayout), topic.End.Local().Format(reportDateLayout), len(topic.SessionIDs))
+		if len(topic.Keywords) > 0 {
+			fmt.Printf("   Keywords: %s\n", strings.Join(topic.Keywords, ", "))
+		}
+		for _, sessionID := range topic.SessionIDs {
+			fmt.Printf("   - %s\n", sessionID)
+		}
+	}
+	fmt.Println("\nDraft a post with 'clio blog draft <topic>'")
+}
+
+// openBlogPlanner opens the database with a blog planner
+func openBlogPlanner() (*sql.DB, blog.Planner, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	database, err := openDatabase(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	planner, err := blog.NewPlanner(database, logging.NewNoopLogger())
+	if err != nil {
+		database.Close()
+		return nil, nil, fmt.Errorf("failed to create blog planner: %w", err)
+	}
+	return database, planner, nil
+}
+
+// openBlogDrafter opens the database with a draft tracker
+func openBlogDrafter() (*sql.DB, blog.Drafter, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	database, err := openDatabase(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	drafter, err := blog.NewDrafter(database, logging.NewNoopLogger())
+	if err != nil {
+		database.Close()
+		return nil, nil, fmt.Errorf("failed to create drafter: %w", err)
+	}
+	return database, drafter, nil
+}
@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/stwalsh4118/clio/internal/clipboard"
+)
+
+// copyFlagUsage describes the --copy flag of commands with shareable output
+const copyFlagUsage = "Also copy the output to the system clipboard"
+
+// clipboardTee returns w, or with copy set a writer that also keeps what is
+// written to w, and a function to call once the output is complete that puts it
+// on the clipboard. The clipboard command is looked up first so a missing one
+// fails before any output is written.
+func clipboardTee(w io.Writer, copy bool) (io.Writer, func() error, error) {
+	if !copy {
+		return w, func() error { return nil }, nil
+	}
+
+	board, err := clipboard.New()
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot --copy: %w", err)
+	}
+	var buf bytes.Buffer
+	flush := func() error {
+		if err := board.Copy(buf.String()); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Copied %d bytes to the clipboard\n", buf.Len())
+		return nil
+	}
+	return io.MultiWriter(w, &buf), flush, nil
+}
@@ -42,13 +42,13 @@ to the watch list, or --set-blog-repo to set the blog repository path.`,
 
 			// Ensure only one flag is used at a time
 			if flagCount > 1 {
-				return fmt.Errorf("only one flag can be used at a time")
+				return usageErrorf("only one flag can be used at a time")
 			}
 
 			// Load current configuration
-			cfg, err := config.Load()
+			cfg, err := loadConfig()
 			if err != nil {
-				return fmt.Errorf("failed to load configuration: %w", err)
+				return err
 			}
 
 			// Handle --show flag
@@ -85,6 +85,9 @@ func handleShow(cfg *config.Config) error {
 		return fmt.Errorf("failed to marshal configuration: %w", err)
 	}
 
+	if cfg.Profile != "" {
+		fmt.Printf("# Active profile: %s\n", cfg.Profile)
+	}
 	fmt.Print(string(data))
 	return nil
 }
@@ -93,12 +96,12 @@ func handleShow(cfg *config.Config) error {
 func handleAddWatch(cfg *config.Config, path string) error {
 	// Validate path
 	if err := config.ValidatePath(path); err != nil {
-		return fmt.Errorf("invalid path: %w", err)
+		return usageErrorf("invalid path: %w", err)
 	}
 
 	// Check for duplicates
 	if config.IsDuplicate(path, cfg.WatchedDirectories) {
-		return fmt.Errorf("directory already in watch list: %s", path)
+		return usageErrorf("directory already in watch list: %s", path)
 	}
 
 	// Add to watched directories
@@ -106,7 +109,7 @@ func handleAddWatch(cfg *config.Config, path string) error {
 
 	// Validate entire configuration before saving
 	if err := config.ValidateConfig(cfg); err != nil {
-		return fmt.Errorf("configuration validation failed: %w", err)
+		return newError(CategoryConfig, fmt.Errorf("configuration validation failed: %w", err))
 	}
 
 	// Save configuration
@@ -122,7 +125,7 @@ func handleAddWatch(cfg *config.Config, path string) error {
 func handleSetBlogRepo(cfg *config.Config, path string) error {
 	// Validate path
 	if err := config.ValidatePath(path); err != nil {
-		return fmt.Errorf("invalid path: %w", err)
+		return usageErrorf("invalid path: %w", err)
 	}
 
 	// Set blog repository
@@ -130,7 +133,7 @@ func handleSetBlogRepo(cfg *config.Config, path string) error {
 
 	// Validate entire configuration before saving
 	if err := config.ValidateConfig(cfg); err != nil {
-		return fmt.Errorf("configuration validation failed: %w", err)
+		return newError(CategoryConfig, fmt.Errorf("configuration validation failed: %w", err))
 	}
 
 	// Save configuration
@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/stwalsh4118/clio/internal/transcript"
+	"github.com/stwalsh4118/clio/pkg/export"
+)
+
+// newConversationsCmd creates the conversations command
+func newConversationsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "conversations",
+		Short: "Work with single conversations",
+		Long:  `Work with single conversations, independent of the sessions they were captured in.`,
+	}
+
+	cmd.AddCommand(newConversationsExportCmd())
+	return cmd
+}
+
+// newConversationsExportCmd creates the conversations export subcommand
+func newConversationsExportCmd() *cobra.Command {
+	var output string
+	var copyOutput bool
+	var redaction redactionFlags
+
+	cmd := &cobra.Command{
+		Use:   "export <composer-id>",
+		Short: "Export one conversation as a Markdown transcript",
+		Long: `Export one conversation as a clean Markdown transcript for sharing: each
+message under its role and time, code blocks labelled with their language, agent
+reasoning collapsed, and the tools the agent ran. Messages from every session
+the conversation spans are included.
+
+The conversation is a composer ID or a unique prefix of one. Redaction rules
+from the redaction configuration block apply; the --strip-* flags override them.
+
+Examples:
+  clio conversations export 3f2a9c
+  clio conversations export 3f2a9c --strip-thinking -o lexer-fix.md
+  clio conversations export 3f2a9c --copy`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handleConversationsExport(args[0], output, copyOutput, redaction)
+		},
+	}
+	redaction.cmd = cmd
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Write to this file instead of stdout")
+	cmd.Flags().BoolVar(&copyOutput, "copy", false, copyFlagUsage)
+	cmd.Flags().BoolVar(&redaction.stripThinking, "strip-thinking", false, "Leave out agent reasoning text")
+	cmd.Flags().BoolVar(&redaction.stripToolCalls, "strip-tool-calls", false, "Leave out the tools agents ran")
+	cmd.Flags().BoolVar(&redaction.stripPaths, "strip-paths", false, "Shorten absolute paths to their last element")
+
+	return cmd
+}
+
+// handleConversationsExport implements the conversations export command
+func handleConversationsExport(composerRef, output string, copyOutput bool, redaction redactionFlags) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	database, err := openReadOnlyDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	reporter, err := newBlobReporter(cfg, database)
+	if err != nil {
+		return err
+	}
+	conversation, err := reporter.ExportConversation(composerRef)
+	if err != nil {
+		return usageErrorf("%v", err)
+	}
+
+	// Redaction works on export data, so the conversation is wrapped in a session
+	data := &export.Data{Sessions: []export.Session{{Conversations: []export.Conversation{*conversation}}}}
+	redaction.resolve(cfg.Redaction).Apply(data)
+
+	var w io.Writer = os.Stdout
+	if output != "" {
+		file, err := os.Create(output)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	w, flush, err := clipboardTee(w, copyOutput)
+	if err != nil {
+		return err
+	}
+	if err := transcript.Write(w, data.Sessions[0].Conversations[0]); err != nil {
+		return err
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+
+	if output != "" {
+		fmt.Fprintf(os.Stderr, "Exported %d message(s) to %s\n", len(conversation.Messages), output)
+	}
+	return nil
+}
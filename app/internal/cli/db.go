@@ -0,0 +1,188 @@
+package cli
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stwalsh4118/clio/internal/integrity"
+	"github.com/stwalsh4118/clio/internal/logging"
+	"github.com/stwalsh4118/clio/internal/search"
+)
+
+const (
+	// dbVerifyMaxIssues is the number of issues listed per check before the rest are counted
+	dbVerifyMaxIssues = 10
+)
+
+// newDBCmd creates the db command with its verify and reindex subcommands
+func newDBCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "db",
+		Short: "Inspect and maintain the clio database",
+	}
+
+	var repair bool
+	verifyCmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Check the database for broken references, bad JSON, and implausible timestamps",
+		Long: `Check the clio database for rows that drop out of reports because something
+they depend on is broken:
+
+  - messages whose conversation doesn't exist
+  - conversations whose session doesn't exist
+  - commits correlated with a session that doesn't exist
+  - JSON columns (code blocks, tool calls, metadata) that don't parse
+  - timestamps that don't parse, lie before 2000 or more than a day in the
+    future, or sessions and conversations that end before they start
+
+--repair fixes what can be fixed without guessing, in one transaction: orphaned
+messages are deleted, commits are uncorrelated from missing sessions, JSON that
+doesn't parse is cleared, and sessions ending before they start are ended at
+their last activity. Everything else is only listed; the daemon reconstructs
+the sessions of orphaned conversations when it starts. Back up the database
+first; stop the daemon so it doesn't write while repairing.
+
+Examples:
+  clio db verify
+  clio db verify --repair`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handleDBVerify(repair)
+		},
+	}
+	verifyCmd.Flags().BoolVar(&repair, "repair", false, "Fix the issues that can be fixed safely")
+	cmd.AddCommand(verifyCmd)
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "reindex",
+		Short: "Rebuild the search index with the configured tokenizer and stop words",
+		Long: `Rebuild the index 'clio search' matches messages against, applying the search
+section of the configuration:
+
+  search:
+    tokenizer: porter                 # unicode61 (default), or porter for English stemming
+    keep_diacritics: false            # true tells "café" and "cafe" apart
+    stop_word_languages: [german]     # built-in lists of words left out of the index
+    stop_words: [todo]                # extra words left out of the index
+
+The daemon and 'clio search' apply changed options when they start; reindex
+applies them too and also renormalizes every message.
+
+Examples:
+  clio db reindex`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handleDBReindex()
+		},
+	})
+
+	return cmd
+}
+
+// handleDBReindex implements db reindex
+func handleDBReindex() error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	database, err := openDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	blobStore, err := openBlobStore(cfg)
+	if err != nil {
+		return err
+	}
+	index, err := search.NewIndex(database, blobStore, logging.NewNoopLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create search index: %w", err)
+	}
+	if _, err := index.Configure(cfg.Search); err != nil {
+		return fmt.Errorf("failed to apply search options: %w", err)
+	}
+	indexed, err := index.Rebuild()
+	if err != nil {
+		return fmt.Errorf("failed to rebuild search index: %w", err)
+	}
+
+	fmt.Printf("Reindexed %d message(s) for search (tokenizer %s", indexed, cfg.Search.Tokenizer)
+	if len(cfg.Search.Languages) > 0 {
+		fmt.Printf(", %s stop words", strings.Join(cfg.Search.Languages, ", "))
+	}
+	fmt.Println(")")
+	return nil
+}
+
+// handleDBVerify implements db verify
+func handleDBVerify(repair bool) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	var database *sql.DB
+	if repair {
+		database, err = openDatabase(cfg)
+	} else {
+		database, err = openReadOnlyDatabase(cfg)
+	}
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	verifier, err := integrity.NewVerifier(database, logging.NewNoopLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create verifier: %w", err)
+	}
+	report, err := verifier.Verify(time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to verify database: %w", err)
+	}
+
+	for _, check := range integrity.Checks {
+		issues := report.ByCheck(check)
+		if len(issues) == 0 {
+			fmt.Printf("[%s] %s\n", doctorStatusOK, check.Title())
+			continue
+		}
+		fmt.Printf("[%s] %s: %d issue(s)\n", doctorStatusFail, check.Title(), len(issues))
+		for i, issue := range issues {
+			if i == dbVerifyMaxIssues {
+				fmt.Printf("       ... and %d more\n", len(issues)-i)
+				break
+			}
+			note := ""
+			if !issue.Repairable() {
+				note = " (not fixed by --repair)"
+			}
+			fmt.Printf("       %s %s: %s%s\n", issue.Table, issue.RowID, issue.Detail, note)
+		}
+	}
+
+	remaining := len(report.Issues)
+	if remaining == 0 {
+		return nil
+	}
+	if repair && report.Repairable() > 0 {
+		repaired, err := verifier.Repair(report)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("\nRepaired %d issue(s)\n", repaired)
+		remaining -= repaired
+	} else if report.Repairable() > 0 {
+		fmt.Printf("\n%d issue(s) can be fixed with --repair\n", report.Repairable())
+	}
+
+	if remaining > 0 {
+		return fmt.Errorf("%d issue(s) need attention", remaining)
+	}
+	return nil
+}
@@ -0,0 +1,310 @@
+package cli
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/cursor"
+	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/git"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+const (
+	// doctorStatusOK marks a passing check
+	doctorStatusOK = "OK"
+	// doctorStatusWarn marks a check that found something worth attention
+	doctorStatusWarn = "WARN"
+	// doctorStatusFail marks a check that prevents clio from working
+	doctorStatusFail = "FAIL"
+)
+
+// doctorResult is the outcome of a single diagnostic check
+type doctorResult struct {
+	name    string
+	status  string
+	details []string
+}
+
+// doctorEnv holds shared state for diagnostic checks
+type doctorEnv struct {
+	cfg      *config.Config
+	database *sql.DB
+}
+
+// doctorCheck runs a single diagnostic against the environment
+type doctorCheck func(env *doctorEnv) doctorResult
+
+// newDoctorCmd creates the doctor command
+func newDoctorCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose configuration and capture problems",
+		Long: `Run diagnostic checks against the clio configuration, database, and
+Cursor data source, and report anything that needs attention.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handleDoctor()
+		},
+	}
+}
+
+// handleDoctor implements the doctor command logic
+func handleDoctor() error {
+	env := &doctorEnv{}
+
+	// Configuration and database are prerequisites for the remaining checks
+	results := []doctorResult{checkDoctorConfig(env)}
+	if env.cfg != nil {
+		results = append(results, checkDoctorDatabase(env))
+	}
+	if env.database != nil {
+		defer env.database.Close()
+	}
+
+	for _, check := range doctorChecks() {
+		if env.cfg == nil {
+			break
+		}
+		results = append(results, check(env))
+	}
+
+	failed := 0
+	for _, result := range results {
+		fmt.Printf("[%s] %s\n", result.status, result.name)
+		for _, detail := range result.details {
+			fmt.Printf("       %s\n", detail)
+		}
+		if result.status == doctorStatusFail {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+	return nil
+}
+
+// doctorChecks returns the checks that run once configuration has been loaded
+func doctorChecks() []doctorCheck {
+	return []doctorCheck{
+		checkDoctorCursorDatabase,
+		checkDoctorQuarantine,
+		checkDoctorBackfills,
+		checkDoctorRepositories,
+	}
+}
+
+// checkDoctorConfig loads and validates the configuration
+func checkDoctorConfig(env *doctorEnv) doctorResult {
+	result := doctorResult{name: "Configuration"}
+
+	cfg, err := config.Load()
+	if err != nil {
+		result.status = doctorStatusFail
+		result.details = []string{err.Error()}
+		return result
+	}
+	env.cfg = cfg
+
+	if err := config.ValidateConfig(cfg); err != nil {
+		result.status = doctorStatusWarn
+		result.details = []string{err.Error()}
+		return result
+	}
+
+	result.status = doctorStatusOK
+	return result
+}
+
+// checkDoctorDatabase opens the clio database read-only, so diagnosing it
+// neither creates nor migrates it
+func checkDoctorDatabase(env *doctorEnv) doctorResult {
+	result := doctorResult{name: "Database"}
+
+	database, err := db.ConnectReadOnly(env.cfg)
+	if errors.Is(err, os.ErrNotExist) {
+		result.status = doctorStatusWarn
+		result.details = []string{
+			env.cfg.Storage.DatabasePath,
+			"not created yet; it is created when the daemon starts or a command next opens it",
+		}
+		return result
+	}
+	if err != nil {
+		result.status = doctorStatusFail
+		result.details = []string{err.Error()}
+		return result
+	}
+
+	// The remaining checks would read tables an unmigrated database lacks
+	pending, err := db.MigrationsPending(database)
+	if err != nil || pending {
+		database.Close()
+	}
+	if err != nil {
+		result.status = doctorStatusFail
+		result.details = []string{err.Error()}
+		return result
+	}
+	if pending {
+		result.status = doctorStatusWarn
+		result.details = []string{
+			env.cfg.Storage.DatabasePath,
+			"Pending migrations run automatically when the daemon starts or a command next opens the database",
+		}
+		return result
+	}
+	env.database = database
+
+	result.status = doctorStatusOK
+	result.details = []string{env.cfg.Storage.DatabasePath}
+	return result
+}
+
+// checkDoctorCursorDatabase checks that Cursor's conversation database can be read
+func checkDoctorCursorDatabase(env *doctorEnv) doctorResult {
+	result := doctorResult{name: "Cursor database"}
+
+	if env.cfg.Cursor.LogPath == "" {
+		result.status = doctorStatusWarn
+		result.details = []string{"cursor.log_path is not configured; conversation capture is disabled"}
+		return result
+	}
+
+	dbPath := filepath.Join(env.cfg.Cursor.LogPath, "globalStorage", "state.vscdb")
+	if _, err := os.Stat(dbPath); err != nil {
+		result.status = doctorStatusWarn
+		result.details = []string{fmt.Sprintf("%s: %v", dbPath, err)}
+		return result
+	}
+
+	cursorDB, err := cursor.OpenCursorDatabase(env.cfg)
+	if err != nil {
+		result.status = doctorStatusFail
+		result.details = []string{err.Error()}
+		return result
+	}
+	cursorDB.Close()
+
+	result.status = doctorStatusOK
+	result.details = []string{dbPath}
+	return result
+}
+
+// checkDoctorQuarantine reports Cursor payloads that didn't match the expected schema
+func checkDoctorQuarantine(env *doctorEnv) doctorResult {
+	result := doctorResult{name: "Cursor schema"}
+
+	if env.database == nil {
+		result.status = doctorStatusWarn
+		result.details = []string{"skipped: database unavailable"}
+		return result
+	}
+
+	counts, err := cursor.CountQuarantinedPayloads(env.database)
+	if err != nil {
+		result.status = doctorStatusFail
+		result.details = []string{err.Error()}
+		return result
+	}
+
+	if len(counts) == 0 {
+		result.status = doctorStatusOK
+		return result
+	}
+
+	// Report counts in a stable order
+	sources := make([]string, 0, len(counts))
+	for source := range counts {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	result.status = doctorStatusWarn
+	for _, source := range sources {
+		result.details = append(result.details, fmt.Sprintf("%d %s payload(s) quarantined with an unrecognised schema", counts[source], source))
+	}
+	result.details = append(result.details, "Cursor may have changed its data format; raw payloads are kept in the quarantined_payloads table")
+	return result
+}
+
+// checkDoctorBackfills reports derived-field backfills that haven't been applied yet
+func checkDoctorBackfills(env *doctorEnv) doctorResult {
+	result := doctorResult{name: "Derived field backfills"}
+
+	if env.database == nil {
+		result.status = doctorStatusWarn
+		result.details = []string{"skipped: database unavailable"}
+		return result
+	}
+
+	// Pending backfills are only listed, so no messages are loaded
+	runner, err := cursor.NewBackfillRunner(env.database, nil, logging.NewNoopLogger())
+	if err != nil {
+		result.status = doctorStatusFail
+		result.details = []string{err.Error()}
+		return result
+	}
+
+	pending, err := runner.Pending()
+	if err != nil {
+		result.status = doctorStatusFail
+		result.details = []string{err.Error()}
+		return result
+	}
+
+	if len(pending) == 0 {
+		result.status = doctorStatusOK
+		return result
+	}
+
+	result.status = doctorStatusWarn
+	result.details = append(result.details, fmt.Sprintf("%d pending: %v", len(pending), pending))
+	result.details = append(result.details, "Pending backfills run automatically when the daemon starts or a command next opens the database")
+	return result
+}
+
+// checkDoctorRepositories reports watched repositories the daemon has marked unhealthy
+func checkDoctorRepositories(env *doctorEnv) doctorResult {
+	result := doctorResult{name: "Watched repositories"}
+
+	if env.database == nil {
+		result.status = doctorStatusWarn
+		result.details = []string{"skipped: database unavailable"}
+		return result
+	}
+
+	records, err := git.ListRepositoryHealth(env.database)
+	if err != nil {
+		result.status = doctorStatusFail
+		result.details = []string{err.Error()}
+		return result
+	}
+
+	for _, record := range records {
+		if record.Status != git.RepositoryUnhealthy {
+			continue
+		}
+		since := ""
+		if record.UnhealthySince != nil {
+			since = fmt.Sprintf(" since %s", formatTime(*record.UnhealthySince))
+		}
+		result.details = append(result.details, fmt.Sprintf("%s: %s (%d failed poll(s)%s)", record.Path, record.LastError, record.ConsecutiveFailures, since))
+	}
+
+	if len(result.details) == 0 {
+		result.status = doctorStatusOK
+		return result
+	}
+
+	result.status = doctorStatusWarn
+	result.details = append(result.details, "Update watched_directories if these repositories were moved or removed; they are retried with backoff until then")
+	return result
+}
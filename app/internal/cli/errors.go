@@ -0,0 +1,256 @@
+package cli
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/lease"
+	"github.com/stwalsh4118/clio/internal/logging"
+	"github.com/stwalsh4118/clio/internal/upgrade"
+	"github.com/stwalsh4118/clio/internal/version"
+	"github.com/stwalsh4118/clio/pkg/clioclient"
+)
+
+// Exit codes returned by clio commands. Scripts can branch on these; they are
+// stable across releases.
+const (
+	ExitOK               = 0
+	ExitFailure          = 1 // Uncategorised error
+	ExitUsage            = 2 // Invalid flags or arguments
+	ExitConfig           = 3 // Configuration missing, unreadable, or invalid
+	ExitDaemonNotRunning = 4 // The command needs a running daemon
+	ExitDatabaseLocked   = 5 // Another process holds a lock on the database
+	ExitPartialFailure   = 6 // Some items succeeded and some failed
+	ExitVersionMismatch  = 7 // The database or daemon belongs to another clio version
+)
+
+// ErrorCategory classifies a command failure
+type ErrorCategory string
+
+// Error categories, one per exit code
+const (
+	CategoryFailure          ErrorCategory = "failure"
+	CategoryUsage            ErrorCategory = "usage"
+	CategoryConfig           ErrorCategory = "config"
+	CategoryDaemonNotRunning ErrorCategory = "daemon_not_running"
+	CategoryDatabaseLocked   ErrorCategory = "database_locked"
+	CategoryPartialFailure   ErrorCategory = "partial_failure"
+	CategoryVersionMismatch  ErrorCategory = "version_mismatch"
+)
+
+// categoryExitCodes maps each category to its exit code
+var categoryExitCodes = map[ErrorCategory]int{
+	CategoryFailure:          ExitFailure,
+	CategoryUsage:            ExitUsage,
+	CategoryConfig:           ExitConfig,
+	CategoryDaemonNotRunning: ExitDaemonNotRunning,
+	CategoryDatabaseLocked:   ExitDatabaseLocked,
+	CategoryPartialFailure:   ExitPartialFailure,
+	CategoryVersionMismatch:  ExitVersionMismatch,
+}
+
+const (
+	// errorFormatText prints "Error: <message>" to stderr
+	errorFormatText = "text"
+	// errorFormatJSON prints a JSON object with the category and exit code to stderr
+	errorFormatJSON = "json"
+)
+
+// Error is a categorised command failure
+type Error struct {
+	Category ErrorCategory
+	Err      error
+}
+
+// Error implements error
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the process exit code for the error's category
+func (e *Error) ExitCode() int {
+	if code, ok := categoryExitCodes[e.Category]; ok {
+		return code
+	}
+	return ExitFailure
+}
+
+// newError wraps err in a categorised error
+func newError(category ErrorCategory, err error) *Error {
+	return &Error{Category: category, Err: err}
+}
+
+// usageErrorf returns an invalid-arguments error
+func usageErrorf(format string, args ...any) error {
+	return newError(CategoryUsage, fmt.Errorf(format, args...))
+}
+
+// daemonNotRunningErrorf returns an error for commands that need the daemon
+func daemonNotRunningErrorf(format string, args ...any) error {
+	return newError(CategoryDaemonNotRunning, fmt.Errorf(format, args...))
+}
+
+// partialFailureErrorf returns an error for commands where only some items failed
+func partialFailureErrorf(format string, args ...any) error {
+	return newError(CategoryPartialFailure, fmt.Errorf(format, args...))
+}
+
+// ClassifyError returns the categorised form of err. Errors that weren't
+// categorised where they were created are recognised by their cause.
+func ClassifyError(err error) *Error {
+	var cliErr *Error
+	if errors.As(err, &cliErr) {
+		return cliErr
+	}
+
+	switch {
+	case db.IsLocked(err), errors.Is(err, lease.ErrHeld):
+		return newError(CategoryDatabaseLocked, err)
+	case errors.Is(err, clioclient.ErrDaemonNotRunning):
+		return newError(CategoryDaemonNotRunning, err)
+	case errors.Is(err, db.ErrNewerSchema), errors.Is(err, upgrade.ErrNewerData):
+		return newError(CategoryVersionMismatch, err)
+	default:
+		return newError(CategoryFailure, err)
+	}
+}
+
+// ExitCode returns the exit code for err, ExitOK when err is nil
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	return ClassifyError(err).ExitCode()
+}
+
+// errorReport is the JSON form of a command failure
+type errorReport struct {
+	Error    string        `json:"error"`
+	Category ErrorCategory `json:"category"`
+	ExitCode int           `json:"exit_code"`
+}
+
+// writeError prints err to w in the given format
+func writeError(w io.Writer, err error, format string) {
+	classified := ClassifyError(err)
+	if format == errorFormatJSON {
+		report := errorReport{Error: err.Error(), Category: classified.Category, ExitCode: classified.ExitCode()}
+		if encodeErr := json.NewEncoder(w).Encode(report); encodeErr == nil {
+			return
+		}
+	}
+	fmt.Fprintf(w, "Error: %v\n", err)
+}
+
+// loadConfig loads the configuration, categorising failures as config errors
+func loadConfig() (*config.Config, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, newError(CategoryConfig, fmt.Errorf("failed to load configuration: %w", err))
+	}
+	return cfg, nil
+}
+
+// openDatabase opens the clio database, categorising lock contention. Versions are
+// checked before migrations change anything, and data left behind by an older
+// clio is upgraded when no daemon is doing it.
+func openDatabase(cfg *config.Config) (*sql.DB, error) {
+	database, err := db.Connect(cfg)
+	if err != nil {
+		return nil, openDatabaseError(err)
+	}
+	if err := checkVersions(database); err != nil {
+		database.Close()
+		return nil, err
+	}
+	if err := db.RunMigrations(database); err != nil {
+		database.Close()
+		return nil, openDatabaseError(fmt.Errorf("failed to run migrations: %w", err))
+	}
+	if err := upgradeData(cfg, database); err != nil {
+		database.Close()
+		return nil, openDatabaseError(err)
+	}
+	return database, nil
+}
+
+// openReadOnlyDatabase opens the clio database read-only for commands that only
+// read, so they wait out the daemon's writes instead of failing and can't change
+// the data. A database that is missing or needs migrating or upgrading is first
+// prepared through openDatabase.
+func openReadOnlyDatabase(cfg *config.Config) (*sql.DB, error) {
+	database, err := db.ConnectReadOnly(cfg)
+	if errors.Is(err, os.ErrNotExist) {
+		return prepareReadOnlyDatabase(cfg)
+	}
+	if err != nil {
+		return nil, openDatabaseError(err)
+	}
+	if err := checkVersions(database); err != nil {
+		database.Close()
+		return nil, err
+	}
+
+	ready, err := readOnlyDatabaseReady(database)
+	if err != nil {
+		database.Close()
+		return nil, openDatabaseError(err)
+	}
+	if !ready {
+		database.Close()
+		return prepareReadOnlyDatabase(cfg)
+	}
+	return database, nil
+}
+
+// readOnlyDatabaseReady reports whether the database needs no migrations or data upgrade
+func readOnlyDatabaseReady(database *sql.DB) (bool, error) {
+	pending, err := db.MigrationsPending(database)
+	if err != nil || pending {
+		return false, err
+	}
+	// Only checked, so no messages are loaded
+	upgrader, err := upgrade.NewUpgrader(database, nil, version.Version, logging.NewNoopLogger())
+	if err != nil {
+		return false, err
+	}
+	needed, err := upgrader.Needed()
+	return !needed, err
+}
+
+// prepareReadOnlyDatabase creates, migrates, and upgrades the database, then
+// reopens it read-only
+func prepareReadOnlyDatabase(cfg *config.Config) (*sql.DB, error) {
+	database, err := openDatabase(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := database.Close(); err != nil {
+		return nil, openDatabaseError(err)
+	}
+
+	database, err = db.ConnectReadOnly(cfg)
+	if err != nil {
+		return nil, openDatabaseError(err)
+	}
+	return database, nil
+}
+
+// openDatabaseError categorises a failure opening the database
+func openDatabaseError(err error) error {
+	if db.IsLocked(err) {
+		return newError(CategoryDatabaseLocked, fmt.Errorf("database is locked by another process: %w", err))
+	}
+	return fmt.Errorf("failed to open database: %w", err)
+}
@@ -0,0 +1,425 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stwalsh4118/clio/internal/batch"
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/filters"
+	"github.com/stwalsh4118/clio/internal/meta"
+	"github.com/stwalsh4118/clio/internal/remote"
+	"github.com/stwalsh4118/clio/internal/report"
+	"github.com/stwalsh4118/clio/internal/translate"
+	"github.com/stwalsh4118/clio/pkg/export"
+)
+
+const (
+	// defaultExportFormat is used when --format isn't given
+	defaultExportFormat = "markdown"
+	// defaultExportWatchInterval is how often --watch checks for new or changed sessions
+	defaultExportWatchInterval = 30 * time.Second
+)
+
+// newExportCmd creates the export command
+func newExportCmd() *cobra.Command {
+	var format string
+	var output string
+	var copyOutput bool
+	var all bool
+	var outDir string
+	var watch bool
+	var interval time.Duration
+	var project string
+	var since string
+	var until string
+	var filter string
+	var metadata string
+	var listFormats bool
+	var translateMessages bool
+	var redaction redactionFlags
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export captured sessions in a chosen format",
+		Long: `Export captured sessions with their conversations and correlated commits.
+
+--format selects a registered exporter. Built-in formats are json, markdown,
+and the mermaid and dot graphs of sessions, conversations, and commits for
+embedding in docs or posts; custom builds can add exporters through the
+pkg/export API. Use --list-formats to see the formats available in this build.
+
+Time ranges accept a date (2006-01-02), an RFC 3339 timestamp, or a relative
+duration such as 7d or 12h. --meta keeps sessions whose metadata, or whose
+commits' metadata, has key=value (see clio meta); exporters receive that
+metadata with each session and commit. --filter applies a named filter (see
+clio filters); flags given alongside it override its terms.
+
+Redaction rules from the redaction configuration block apply to every format;
+the --strip-* and --allow-ext flags override them for this export.
+
+--translate translates messages detected as written in another language into
+translation.target_language (default: en) with translation.command, for
+example a script asking an LLM; it reads a message on stdin and prints the
+translation. Identical messages are only translated once per run.
+
+--output writes to a file, or uploads to cloud storage when given an s3:// or
+gs:// URL, using the credentials in the remote_storage configuration block.
+
+--copy also puts the output on the system clipboard (pbcopy, clip, wl-copy,
+xclip, or xsel) for pasting into chat or a pull request.
+
+--all --out <dir> writes one file per session to the directory instead, with a
+manifest.json and an index.md page linking them. Sessions exported there
+before whose content hasn't changed are skipped, so re-running the same export
+only rewrites what changed. --watch keeps doing so every --interval until
+interrupted, so the directory stays an up-to-date journal of sessions as they
+gain content and end. A relative --since or --until moves with each pass.
+
+Examples:
+  clio export --project clio --since 7d > week.md
+  clio export --meta customer=acme --format json
+  clio export --project clio --since 7d --translate > week-en.md
+  clio export --since 7d --output s3://team-logs/clio/week.md
+  clio export --all --since 2026-10-01 --out exports/
+  clio export --watch --out ~/journal --since 30d`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if listFormats {
+				return handleListExportFormats()
+			}
+
+			if watch {
+				if interval <= 0 {
+					return usageErrorf("--interval must be positive")
+				}
+				all = true
+			}
+			if all != (outDir != "") {
+				return usageErrorf("--all and --watch need --out, and --out needs --all or --watch")
+			}
+			if all && (output != "" || copyOutput) {
+				return usageErrorf("--output and --copy don't apply to --all or --watch")
+			}
+			if remote.IsRemote(output) {
+				if _, err := remote.Parse(output); err != nil {
+					return usageErrorf("invalid --output: %v", err)
+				}
+			}
+
+			tag, filterMeta, err := applyFilter(cmd, filter, &project, &since, &until)
+			if err != nil {
+				return err
+			}
+			if !cmd.Flags().Changed("meta") {
+				metadata = filterMeta
+			}
+
+			now := time.Now()
+			opts := report.ExportOptions{Project: project, Tag: tag}
+			if metadata != "" {
+				if opts.MetaKey, opts.MetaValue, err = meta.ParsePair(metadata); err != nil {
+					return usageErrorf("invalid --meta: %v", err)
+				}
+			}
+			if opts.Since, err = filters.ParseTime(since, now); err != nil {
+				return usageErrorf("invalid --since: %w", err)
+			}
+			if opts.Until, err = filters.ParseTime(until, now); err != nil {
+				return usageErrorf("invalid --until: %w", err)
+			}
+			if !opts.Since.IsZero() && !opts.Until.IsZero() && !opts.Since.Before(opts.Until) {
+				return usageErrorf("--since must be before --until")
+			}
+
+			if err := config.ValidateRedactionConfig(config.RedactionConfig{AllowExtensions: redaction.allowExtensions}); err != nil {
+				return usageErrorf("invalid --allow-ext: %v", err)
+			}
+
+			if all {
+				return handleExportAll(format, outDir, watch, interval, opts, since, until, redaction, translateMessages)
+			}
+			return handleExport(format, output, copyOutput, opts, redaction, translateMessages)
+		},
+	}
+	redaction.cmd = cmd
+
+	cmd.Flags().StringVarP(&format, "format", "f", defaultExportFormat, "Export format ("+strings.Join(export.Names(), ", ")+")")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Write to this file or s3:// or gs:// URL instead of stdout")
+	cmd.Flags().BoolVar(&copyOutput, "copy", false, copyFlagUsage)
+	cmd.Flags().BoolVar(&all, "all", false, "Export each session to its own file in --out, with a manifest and index")
+	cmd.Flags().StringVar(&outDir, "out", "", "Directory for --all and --watch")
+	cmd.Flags().BoolVar(&watch, "watch", false, "Keep the --out directory up to date as sessions change, until interrupted (implies --all)")
+	cmd.Flags().DurationVar(&interval, "interval", defaultExportWatchInterval, "How often --watch checks for changed sessions")
+	cmd.Flags().StringVar(&project, "project", "", "Only include this project")
+	cmd.Flags().StringVar(&since, "since", "", "Only include sessions starting at or after this time (date, timestamp, or duration like 7d)")
+	cmd.Flags().StringVar(&until, "until", "", "Only include sessions starting before this time (date, timestamp, or duration like 7d)")
+	cmd.Flags().StringVar(&metadata, "meta", "", "Only include sessions whose metadata or commits' metadata has this key=value")
+	cmd.Flags().StringVar(&filter, "filter", "", filterFlagUsage)
+	cmd.Flags().BoolVar(&listFormats, "list-formats", false, "List available export formats")
+	cmd.Flags().BoolVar(&redaction.stripThinking, "strip-thinking", false, "Leave out agent reasoning text")
+	cmd.Flags().BoolVar(&redaction.stripToolCalls, "strip-tool-calls", false, "Leave out the tools agents ran")
+	cmd.Flags().BoolVar(&redaction.stripPaths, "strip-paths", false, "Shorten absolute paths to their last element")
+	cmd.Flags().StringSliceVar(&redaction.allowExtensions, "allow-ext", nil, "Only export attachments with these extensions, e.g. png,jpg")
+	cmd.Flags().BoolVar(&translateMessages, "translate", false, "Translate messages in other languages with translation.command")
+
+	return cmd
+}
+
+// handleListExportFormats prints the registered exporters
+func handleListExportFormats() error {
+	for _, exporter := range export.Exporters() {
+		fmt.Printf("%-12s %s\n", exporter.Name(), exporter.Description())
+	}
+	return nil
+}
+
+// handleExport implements the export command logic
+func handleExport(format, output string, copyOutput bool, opts report.ExportOptions, redaction redactionFlags, translateMessages bool) error {
+	exporter, ok := export.Lookup(format)
+	if !ok {
+		return usageErrorf("unknown export format %q (available: %s)", format, strings.Join(export.Names(), ", "))
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	opts.MinConfidence = cfg.Git.MinCorrelationConfidence
+	translator, err := newExportTranslator(cfg, translateMessages)
+	if err != nil {
+		return err
+	}
+
+	database, err := openReadOnlyDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	reporter, err := newBlobReporter(cfg, database)
+	if err != nil {
+		return err
+	}
+
+	data, err := reporter.ExportData(opts)
+	if err != nil {
+		return fmt.Errorf("failed to load export data: %w", err)
+	}
+	if err := translateExport(cfg, translator, data.Sessions); err != nil {
+		return err
+	}
+	redaction.resolve(cfg.Redaction).Apply(data)
+
+	var w io.Writer = os.Stdout
+	var upload bytes.Buffer
+	if remote.IsRemote(output) {
+		w = &upload
+	} else if output != "" {
+		file, err := os.Create(output)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	w, flush, err := clipboardTee(w, copyOutput)
+	if err != nil {
+		return err
+	}
+	if err := exporter.Export(w, data); err != nil {
+		return fmt.Errorf("failed to export as %s: %w", format, err)
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+	if remote.IsRemote(output) {
+		if err := uploadToRemote(cfg, output, upload.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	if output != "" {
+		fmt.Fprintf(os.Stderr, "Exported %d session(s) to %s\n", len(data.Sessions), output)
+	}
+	return nil
+}
+
+// handleExportAll implements export --all, writing each session to its own file
+// in dir; with watch set it repeats every interval until interrupted, resolving
+// since and until again each time
+func handleExportAll(format, dir string, watch bool, interval time.Duration, opts report.ExportOptions, since, until string, redaction redactionFlags, translateMessages bool) error {
+	exporter, ok := export.Lookup(format)
+	if !ok {
+		return usageErrorf("unknown export format %q (available: %s)", format, strings.Join(export.Names(), ", "))
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	opts.MinConfidence = cfg.Git.MinCorrelationConfidence
+	translator, err := newExportTranslator(cfg, translateMessages)
+	if err != nil {
+		return err
+	}
+
+	database, err := openReadOnlyDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	reporter, err := newBlobReporter(cfg, database)
+	if err != nil {
+		return err
+	}
+
+	exportAll := func(opts report.ExportOptions) (*batch.Result, error) {
+		data, err := reporter.ExportData(opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load export data: %w", err)
+		}
+		if err := translateExport(cfg, translator, data.Sessions); err != nil {
+			return nil, err
+		}
+		redaction.resolve(cfg.Redaction).Apply(data)
+
+		result, err := batch.Export(dir, exporter, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export to %s: %w", dir, err)
+		}
+		return result, nil
+	}
+
+	result, err := exportAll(opts)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Exported %d session(s) to %s, %d unchanged; %d listed in %s\n",
+		result.Written, dir, result.Unchanged, len(result.Manifest.Sessions), filepath.Join(dir, batch.IndexName))
+	if !watch {
+		return nil
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Println("Watching for session changes (Ctrl+C to stop)")
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	return watchExport(ctx, ticker.C, opts, since, until, exportAll, os.Stdout, os.Stderr)
+}
+
+// watchExport runs exportAll on every tick until ctx is done. since and until
+// are resolved against each tick's time, so a relative window like 7d keeps
+// moving instead of staying where it was when the watch started.
+func watchExport(ctx context.Context, ticks <-chan time.Time, opts report.ExportOptions, since, until string,
+	exportAll func(report.ExportOptions) (*batch.Result, error), stdout, stderr io.Writer) error {
+	for {
+		var now time.Time
+		select {
+		case <-ctx.Done():
+			return nil
+		case now = <-ticks:
+		}
+
+		var err error
+		if opts.Since, err = filters.ParseTime(since, now); err != nil {
+			return usageErrorf("invalid --since: %w", err)
+		}
+		if opts.Until, err = filters.ParseTime(until, now); err != nil {
+			return usageErrorf("invalid --until: %w", err)
+		}
+		result, err := exportAll(opts)
+		if err != nil {
+			// The daemon may be mid-write; the next tick retries
+			fmt.Fprintf(stderr, "Export failed: %v\n", err)
+			continue
+		}
+		if result.Written > 0 {
+			fmt.Fprintf(stdout, "%s Updated %d session(s)\n", now.Format(reportTimeLayout), result.Written)
+		}
+	}
+}
+
+// newExportTranslator returns the configured translation command, remembering
+// its translations for the run, or nil when enabled isn't set
+func newExportTranslator(cfg *config.Config, enabled bool) (translate.Translator, error) {
+	if !enabled {
+		return nil, nil
+	}
+	if cfg.Translation.Command == "" {
+		return nil, usageErrorf("--translate needs translation.command in the configuration")
+	}
+	translator, err := translate.NewCommandTranslator(cfg.Translation.Command)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create translator: %w", err)
+	}
+	return translate.NewCachedTranslator(translator), nil
+}
+
+// translateExport translates the sessions' messages into the target language
+// when translator is set
+func translateExport(cfg *config.Config, translator translate.Translator, sessions []export.Session) error {
+	if translator == nil {
+		return nil
+	}
+	timeout := time.Duration(cfg.Translation.TimeoutSeconds) * time.Second
+	translated, err := translate.Sessions(context.Background(), translator, sessions, cfg.Translation.TargetLanguage, timeout)
+	if err != nil {
+		return err
+	}
+	if translated > 0 {
+		fmt.Fprintf(os.Stderr, "Translated %d message(s) into %s\n", translated, cfg.Translation.TargetLanguage)
+	}
+	return nil
+}
+
+// redactionFlags holds the export redaction flags; flags given on the command line
+// override the redaction configuration
+type redactionFlags struct {
+	cmd             *cobra.Command
+	stripThinking   bool
+	stripToolCalls  bool
+	stripPaths      bool
+	allowExtensions []string
+}
+
+// resolve combines the configured redaction with the flags that were given
+func (f redactionFlags) resolve(cfg config.RedactionConfig) export.Redaction {
+	redaction := export.Redaction{
+		StripThinking:   cfg.StripThinking,
+		StripToolCalls:  cfg.StripToolCalls,
+		StripPaths:      cfg.StripPaths,
+		AllowExtensions: cfg.AllowExtensions,
+	}
+	if f.cmd == nil {
+		return redaction
+	}
+
+	flags := f.cmd.Flags()
+	if flags.Changed("strip-thinking") {
+		redaction.StripThinking = f.stripThinking
+	}
+	if flags.Changed("strip-tool-calls") {
+		redaction.StripToolCalls = f.stripToolCalls
+	}
+	if flags.Changed("strip-paths") {
+		redaction.StripPaths = f.stripPaths
+	}
+	if flags.Changed("allow-ext") {
+		redaction.AllowExtensions = f.allowExtensions
+	}
+	return redaction
+}
@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/batch"
+	"github.com/stwalsh4118/clio/internal/report"
+)
+
+func TestWatchExport(t *testing.T) {
+	start := time.Date(2024, 1, 10, 12, 0, 0, 0, time.Local)
+	ticks := make(chan time.Time)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls []report.ExportOptions
+	exportAll := func(opts report.ExportOptions) (*batch.Result, error) {
+		calls = append(calls, opts)
+		switch len(calls) {
+		case 1:
+			return nil, errors.New("database is locked")
+		case 2:
+			return &batch.Result{Written: 2}, nil
+		}
+		return &batch.Result{Unchanged: 2}, nil
+	}
+
+	var stdout, stderr bytes.Buffer
+	done := make(chan error)
+	go func() {
+		done <- watchExport(ctx, ticks, report.ExportOptions{Project: "clio"}, "7d", "2024-02-01", exportAll, &stdout, &stderr)
+	}()
+	for i := 0; i < 3; i++ {
+		ticks <- start.Add(time.Duration(i) * time.Hour)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("watchExport() error = %v", err)
+	}
+
+	if len(calls) != 3 {
+		t.Fatalf("exportAll called %d times, want 3", len(calls))
+	}
+	until := time.Date(2024, 2, 1, 0, 0, 0, 0, time.Local)
+	for i, opts := range calls {
+		// The relative --since moves with each tick; the date in --until stays put
+		tick := start.Add(time.Duration(i) * time.Hour)
+		if want := tick.AddDate(0, 0, -7); !opts.Since.Equal(want) {
+			t.Errorf("tick %d Since = %v, want %v", i, opts.Since, want)
+		}
+		if !opts.Until.Equal(until) {
+			t.Errorf("tick %d Until = %v, want %v", i, opts.Until, until)
+		}
+		if opts.Project != "clio" {
+			t.Errorf("tick %d Project = %q, want clio", i, opts.Project)
+		}
+	}
+
+	// A failed export is reported and retried on the next tick; unchanged ticks stay quiet
+	if got := stderr.String(); got != "Export failed: database is locked\n" {
+		t.Errorf("stderr = %q, want the failed export", got)
+	}
+	if got := stdout.String(); strings.Count(got, "\n") != 1 || !strings.Contains(got, "Updated 2 session(s)") {
+		t.Errorf("stdout = %q, want one update line", got)
+	}
+}
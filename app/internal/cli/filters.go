@@ -0,0 +1,195 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/filters"
+)
+
+// filterFlagUsage is the help text of --filter on the commands that take it
+const filterFlagUsage = "Apply a named filter from the configuration (see clio filters)"
+
+// newFiltersCmd creates the filters command with list, add, and rm subcommands
+func newFiltersCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "filters",
+		Short: "Manage named filters for --filter",
+		Long: `Manage named filters: saved queries kept in the filters configuration block
+and applied with --filter on export, report, and stats.
+
+A filter is key:value terms combined with AND. Keys are project, since, and
+until, which work like the flags of the same name; tag, which keeps
+sessions tagged to a goal or behind commits mentioning #<tag>; and meta, which
+keeps sessions whose metadata or commits' metadata has key=value (see clio
+meta). tag and meta apply to export only.
+Flags given alongside --filter override the filter's terms.
+
+Examples:
+  clio filters add bugfixes tag:bugfix AND project:clio
+  clio filters add this-week since:7d
+  clio filters add acme meta:customer=acme
+  clio export --filter bugfixes --format markdown
+  clio report --files --filter this-week --project api
+  clio filters rm this-week`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handleFiltersList()
+		},
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List named filters",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handleFiltersList()
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "add <name> <expression>",
+		Short: "Add or replace a named filter",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handleFiltersAdd(args[0], strings.Join(args[1:], " "))
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:     "rm <name>",
+		Aliases: []string{"delete"},
+		Short:   "Delete a named filter",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handleFiltersRm(args[0])
+		},
+	})
+
+	return cmd
+}
+
+// handleFiltersList implements filters list
+func handleFiltersList() error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	names := filters.Names(cfg.Filters)
+	if len(names) == 0 {
+		fmt.Println("No filters. Add one with 'clio filters add'.")
+		return nil
+	}
+	for _, name := range names {
+		fmt.Printf("%-16s %s\n", name, cfg.Filters[name])
+	}
+	return nil
+}
+
+// handleFiltersAdd implements filters add
+func handleFiltersAdd(name, expr string) error {
+	name = strings.ToLower(name)
+	if err := filters.ValidateName(name); err != nil {
+		return usageErrorf("%v", err)
+	}
+	filter, err := filters.Parse(expr)
+	if err != nil {
+		return usageErrorf("invalid filter: %v", err)
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	_, replaced := cfg.Filters[name]
+	if cfg.Filters == nil {
+		cfg.Filters = make(map[string]string)
+	}
+	cfg.Filters[name] = filter.String()
+
+	if err := saveFilters(cfg); err != nil {
+		return err
+	}
+	if replaced {
+		fmt.Printf("Replaced filter %s: %s\n", name, filter)
+	} else {
+		fmt.Printf("Added filter %s: %s\n", name, filter)
+	}
+	return nil
+}
+
+// handleFiltersRm implements filters rm
+func handleFiltersRm(name string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	name = strings.ToLower(name)
+	if _, ok := cfg.Filters[name]; !ok {
+		return usageErrorf("filter %q not found", name)
+	}
+	delete(cfg.Filters, name)
+
+	if err := saveFilters(cfg); err != nil {
+		return err
+	}
+	fmt.Printf("Deleted filter %s\n", name)
+	return nil
+}
+
+// saveFilters validates and saves the configuration after a filter change
+func saveFilters(cfg *config.Config) error {
+	if err := config.ValidateConfig(cfg); err != nil {
+		return newError(CategoryConfig, fmt.Errorf("configuration validation failed: %w", err))
+	}
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+	return nil
+}
+
+// applyFilter fills project, since, and until from the named filter, keeping
+// the values of flags given on the command line, and returns the filter's tag
+// and meta terms. An empty name leaves everything as is.
+func applyFilter(cmd *cobra.Command, name string, project, since, until *string) (string, string, error) {
+	if name == "" {
+		return "", "", nil
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return "", "", err
+	}
+	filter, err := filters.Lookup(cfg.Filters, name)
+	if err != nil {
+		return "", "", usageErrorf("invalid --filter: %v", err)
+	}
+
+	flags := cmd.Flags()
+	if !flags.Changed("project") {
+		*project = filter.Project
+	}
+	if !flags.Changed("since") {
+		*since = filter.Since
+	}
+	if !flags.Changed("until") {
+		*until = filter.Until
+	}
+	return filter.Tag, filter.Meta, nil
+}
+
+// applyUntaggedFilter is applyFilter for commands whose activity isn't
+// session-scoped, which can't apply a tag: or meta: term
+func applyUntaggedFilter(cmd *cobra.Command, name string, project, since, until *string) error {
+	tag, metadata, err := applyFilter(cmd, name, project, since, until)
+	if err != nil {
+		return err
+	}
+	if tag != "" || metadata != "" {
+		return usageErrorf("filter %q has a tag: or meta: term, which only export supports", name)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/stwalsh4118/clio/internal/logging"
+	"github.com/stwalsh4118/clio/internal/provenance"
+)
+
+const (
+	// maxCodePreviewLines is how many lines of a matched block find-code prints
+	maxCodePreviewLines = 6
+)
+
+// newFindCodeCmd creates the find-code command
+func newFindCodeCmd() *cobra.Command {
+	var opts provenance.FindOptions
+	var reindex bool
+
+	cmd := &cobra.Command{
+		Use:   "find-code <snippet|file|->",
+		Short: "Find the conversation a piece of code came from",
+		Long: `Find which captured conversation a piece of code originated from. The argument
+is a file to read, - to read stdin, or the code itself.
+
+Code blocks from agent messages are indexed by normalized content and by hashed
+windows of tokens (shingles), so reformatted or lightly edited code still
+matches. A match's score is the share of the searched code found in the block;
+ties go to the earliest conversation. The index is updated before each search.
+
+Examples:
+  clio find-code internal/parser/lexer.go
+  pbpaste | clio find-code - --min-score 0.3
+  clio find-code 'for attempt := 0; attempt < maxAttempts; attempt++ {'`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.Limit <= 0 {
+				return usageErrorf("--limit must be positive")
+			}
+			if opts.MinScore <= 0 || opts.MinScore > 1 {
+				return usageErrorf("--min-score must be in (0, 1]")
+			}
+			code, err := readCodeArg(args[0])
+			if err != nil {
+				return err
+			}
+			return handleFindCode(code, opts, reindex)
+		},
+	}
+
+	cmd.Flags().IntVarP(&opts.Limit, "limit", "n", provenance.DefaultFindLimit, "Maximum number of matches")
+	cmd.Flags().Float64Var(&opts.MinScore, "min-score", provenance.DefaultMinScore, "Minimum share of the code a block must contain")
+	cmd.Flags().BoolVar(&reindex, "reindex", false, "Rebuild the code index before searching")
+
+	return cmd
+}
+
+// readCodeArg returns the code named by a find-code argument
+func readCodeArg(arg string) (string, error) {
+	if arg == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", fmt.Errorf("failed to read code from stdin: %w", err)
+		}
+		return string(data), nil
+	}
+	if info, err := os.Stat(arg); err == nil && info.Mode().IsRegular() {
+		data, err := os.ReadFile(arg)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", arg, err)
+		}
+		return string(data), nil
+	}
+	return arg, nil
+}
+
+// handleFindCode implements the find-code command
+func handleFindCode(code string, opts provenance.FindOptions, reindex bool) error {
+	if strings.TrimSpace(code) == "" {
+		return usageErrorf("code to find cannot be empty")
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	database, err := openDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	index, err := provenance.NewIndex(database, logging.NewNoopLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create code index: %w", err)
+	}
+	if reindex {
+		_, err = index.Rebuild()
+	} else {
+		_, err = index.Sync()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update code index: %w", err)
+	}
+
+	matches, err := index.Find(code, opts)
+	if err != nil {
+		return fmt.Errorf("failed to find code: %w", err)
+	}
+	if len(matches) == 0 {
+		fmt.Println("No captured conversation contains this code")
+		return nil
+	}
+
+	for i, match := range matches {
+		if i > 0 {
+			fmt.Println()
+		}
+		kind := fmt.Sprintf("%.0f%% match", match.Score*100)
+		if match.Exact {
+			kind = "exact match"
+		}
+		fmt.Printf("%s  %q (%s, session %s) - %s\n", formatTime(match.MessageCreatedAt),
+			match.ConversationName, match.Project, shortHash(match.SessionID), kind)
+
+		lines := strings.Split(strings.TrimRight(match.Code, "\n"), "\n")
+		for _, line := range lines[:min(len(lines), maxCodePreviewLines)] {
+			fmt.Printf("    %s\n", line)
+		}
+		if len(lines) > maxCodePreviewLines {
+			fmt.Printf("    ... %d more lines\n", len(lines)-maxCodePreviewLines)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,248 @@
+package cli
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stwalsh4118/clio/internal/goals"
+	"github.com/stwalsh4118/clio/internal/logging"
+	"github.com/stwalsh4118/clio/internal/recap"
+	"github.com/stwalsh4118/clio/internal/report"
+)
+
+// newGoalCmd creates the goal command with add, list, tag, done, and rm subcommands
+func newGoalCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "goal",
+		Short: "Track milestones with progress inferred from tagged work",
+		Long: `Track milestones such as "ship v1 importer". Progress is inferred from
+tagged work: commits whose message mentions #<tag>, and sessions tagged with
+'clio goal tag'. A goal's tag is derived from its title unless --tag sets it.
+
+Open goals and their progress are also shown by 'clio status'.
+
+Examples:
+  clio goal add "Ship v1 importer" --project clio --due 2026-11-30 --target 20
+  git commit -m "Parse Zed threads #ship-v1-importer"
+  clio goal tag ship-v1-importer
+  clio goal list
+  clio goal done ship-v1-importer`,
+	}
+
+	var project, due, tag string
+	var target int
+	add := &cobra.Command{
+		Use:   "add <title>",
+		Short: "Add a goal",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			goal := goals.Goal{Title: strings.Join(args, " "), Project: project, Tag: tag, TargetCommits: target}
+			if due != "" {
+				dueDate, err := time.ParseInLocation(reportDateLayout, due, time.Local)
+				if err != nil {
+					return usageErrorf("invalid --due %q: use a date like %s", due, reportDateLayout)
+				}
+				goal.Due = dueDate
+			}
+			if target < 0 {
+				return usageErrorf("--target cannot be negative")
+			}
+			return handleGoalAdd(goal)
+		},
+	}
+	add.Flags().StringVar(&project, "project", "", "Only count work in this project")
+	add.Flags().StringVar(&due, "due", "", "Due date (2006-01-02)")
+	add.Flags().StringVar(&tag, "tag", "", "Tag mentioned as #<tag> in commits (default: derived from the title)")
+	add.Flags().IntVar(&target, "target", 0, "Tagged commits expected to finish the goal, for a progress percentage")
+	cmd.AddCommand(add)
+
+	var all bool
+	list := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List goals with their progress",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handleGoalList(all)
+		},
+	}
+	list.Flags().BoolVarP(&all, "all", "a", false, "Include completed goals")
+	cmd.AddCommand(list)
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "tag <goal> [session]",
+		Short: "Count a session's work towards a goal (default: the active session)",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sessionRef := report.ActiveSession
+			if len(args) == 2 {
+				sessionRef = args[1]
+			}
+			return handleGoalTag(args[0], sessionRef)
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "done <goal>",
+		Short: "Mark a goal as completed",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handleGoalDone(args[0])
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:     "rm <goal>",
+		Aliases: []string{"delete"},
+		Short:   "Delete a goal",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handleGoalRm(args[0])
+		},
+	})
+
+	return cmd
+}
+
+// handleGoalAdd implements goal add
+func handleGoalAdd(goal goals.Goal) error {
+	database, tracker, err := openGoalTracker()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	added, err := tracker.Add(goal)
+	if err != nil {
+		return usageErrorf("%v", err)
+	}
+	fmt.Printf("Added goal %q; mention #%s in commit messages to track progress\n", added.Title, added.Tag)
+	return nil
+}
+
+// handleGoalList implements goal list
+func handleGoalList(all bool) error {
+	database, tracker, err := openGoalTracker()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	list, err := tracker.List(all)
+	if err != nil {
+		return fmt.Errorf("failed to list goals: %w", err)
+	}
+	if len(list) == 0 {
+		fmt.Println("No goals. Add one with 'clio goal add'.")
+		return nil
+	}
+
+	now := time.Now()
+	for _, goal := range list {
+		progress, err := tracker.Progress(goal, now)
+		if err != nil {
+			return fmt.Errorf("failed to compute goal progress: %w", err)
+		}
+		printGoalProgress(progress, "")
+	}
+	return nil
+}
+
+// handleGoalTag implements goal tag
+func handleGoalTag(tag, sessionRef string) error {
+	database, tracker, err := openGoalTracker()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	reporter, err := report.NewReporter(database, logging.NewNoopLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create reporter: %w", err)
+	}
+	sessionID, err := reporter.ResolveSession(sessionRef)
+	if err != nil {
+		return usageErrorf("%v", err)
+	}
+
+	if err := tracker.TagSession(tag, sessionID, time.Now()); err != nil {
+		return usageErrorf("%v", err)
+	}
+	fmt.Printf("Tagged session %s with goal %s\n", sessionID, strings.ToLower(tag))
+	return nil
+}
+
+// handleGoalDone implements goal done
+func handleGoalDone(tag string) error {
+	database, tracker, err := openGoalTracker()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	if err := tracker.Complete(tag, time.Now()); err != nil {
+		return usageErrorf("%v", err)
+	}
+	fmt.Printf("Completed goal %s\n", strings.ToLower(tag))
+	return nil
+}
+
+// handleGoalRm implements goal rm
+func handleGoalRm(tag string) error {
+	database, tracker, err := openGoalTracker()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	if err := tracker.Remove(tag); err != nil {
+		return usageErrorf("%v", err)
+	}
+	fmt.Printf("Deleted goal %s\n", strings.ToLower(tag))
+	return nil
+}
+
+// openGoalTracker opens the database and creates a goal tracker over it
+func openGoalTracker() (*sql.DB, goals.Tracker, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	database, err := openDatabase(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tracker, err := goals.NewTracker(database, logging.NewNoopLogger())
+	if err != nil {
+		database.Close()
+		return nil, nil, fmt.Errorf("failed to create goal tracker: %w", err)
+	}
+	return database, tracker, nil
+}
+
+// printGoalProgress prints a goal's progress in two lines, indented by indent
+func printGoalProgress(progress *goals.Progress, indent string) {
+	percent := ""
+	if progress.Percent >= 0 {
+		percent = fmt.Sprintf(" %3.0f%%", progress.Percent*100)
+	}
+	due := ""
+	if !progress.Due.IsZero() {
+		due = ", due " + progress.Due.Format(reportDateLayout)
+	}
+	project := ""
+	if progress.Project != "" {
+		project = " [" + progress.Project + "]"
+	}
+	fmt.Printf("%s%-8s%s  %s%s  #%s%s\n", indent, progress.Status, percent, progress.Title, project, progress.Tag, due)
+
+	last := "no tagged work yet"
+	if !progress.LastActivity.IsZero() {
+		last = "last activity " + formatTime(progress.LastActivity)
+	}
+	fmt.Printf("%s  %d commit(s), %d session(s), %s; %s\n", indent, progress.Commits, progress.Sessions,
+		recap.FormatDuration(progress.TimeSpent), last)
+}
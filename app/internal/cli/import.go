@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/stwalsh4118/clio/internal/importer"
+	"github.com/stwalsh4118/clio/internal/logging"
+	"github.com/stwalsh4118/clio/internal/sensitive"
+)
+
+// newImportCmd creates the import command with a subcommand per export format
+func newImportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import conversations from AI assistant data exports",
+		Long: `Import conversations from the official data exports of other AI assistants,
+attributed to a project, so earlier AI work lives in the same history as
+captured Cursor conversations.
+
+Each export may be given as the downloaded .zip archive, the extracted
+directory, or its conversations.json file. Importing the same export again
+updates the existing conversations instead of duplicating them.`,
+	}
+
+	cmd.AddCommand(newImportFormatCmd(importer.FormatChatGPT, "ChatGPT", "chatgpt <export.zip>"))
+	cmd.AddCommand(newImportFormatCmd(importer.FormatClaude, "Claude", "claude <export>"))
+
+	return cmd
+}
+
+// newImportFormatCmd creates the import subcommand for one export format
+func newImportFormatCmd(format, displayName, use string) *cobra.Command {
+	var project string
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: fmt.Sprintf("Import conversations from a %s data export", displayName),
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if strings.TrimSpace(project) == "" {
+				return usageErrorf("--project is required")
+			}
+			return handleImport(format, args[0], project, dryRun)
+		},
+	}
+
+	cmd.Flags().StringVarP(&project, "project", "p", "", "Project to attribute the imported conversations to (required)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be imported without writing")
+
+	return cmd
+}
+
+// handleImport implements the import subcommands
+func handleImport(format, path, project string, dryRun bool) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	database, err := openDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	// Imported messages pass the sensitive gate like captured ones
+	logger := logging.NewNoopLogger()
+	gate, err := sensitive.NewGate(cfg.Sensitive, logger)
+	if err != nil {
+		return newError(CategoryConfig, fmt.Errorf("invalid sensitive configuration: %w", err))
+	}
+	imp, err := importer.NewImporterWithGate(database, gate, logger)
+	if err != nil {
+		return fmt.Errorf("failed to create importer: %w", err)
+	}
+
+	result, err := imp.Import(format, path, importer.Options{Project: project, DryRun: dryRun})
+	if err != nil {
+		return fmt.Errorf("failed to import %s export: %w", format, err)
+	}
+
+	verb := "Imported"
+	if dryRun {
+		verb = "Would import"
+	}
+	fmt.Printf("%s %d conversations (%d messages) into project %q\n", verb, result.Conversations, result.Messages, project)
+	if result.Skipped > 0 {
+		fmt.Printf("Skipped %d conversations with no messages\n", result.Skipped)
+	}
+
+	return nil
+}
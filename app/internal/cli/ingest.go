@@ -0,0 +1,217 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stwalsh4118/clio/internal/logging"
+	"github.com/stwalsh4118/clio/internal/testresults"
+)
+
+const (
+	// defaultIngestWatchInterval is how often --watch rescans the results directory
+	defaultIngestWatchInterval = 5 * time.Second
+)
+
+// resultFileExtensions are the file types --watch ingests
+var resultFileExtensions = []string{".xml", ".json", ".jsonl"}
+
+// newIngestCmd creates the ingest command with a subcommand per data type
+func newIngestCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ingest",
+		Short: "Ingest development activity produced outside clio",
+	}
+
+	cmd.AddCommand(newIngestTestResultsCmd())
+
+	return cmd
+}
+
+// newIngestTestResultsCmd creates the ingest test-results subcommand
+func newIngestTestResultsCmd() *cobra.Command {
+	var format string
+	var project string
+	var watchDir string
+	var interval time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "test-results [file...]",
+		Short: "Store test run outcomes linked to the active session and nearest commit",
+		Long: `Store test run outcomes from JUnit XML or go test -json output. Each run is
+linked to the session active when it finished and the latest commit before it,
+so exports can show when tests went red and which conversations fixed them.
+
+Ingesting the same file twice stores it once. With --watch, the directory is
+polled for new or changed result files until interrupted.
+
+Examples:
+  go test -json ./... > results.json; clio ingest test-results results.json
+  clio ingest test-results --watch build/test-results --project clio`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if watchDir == "" && len(args) == 0 {
+				return usageErrorf("provide a results file or --watch <dir>")
+			}
+			if watchDir != "" && len(args) > 0 {
+				return usageErrorf("--watch cannot be combined with result files")
+			}
+			if !isTestResultFormat(format) {
+				return usageErrorf("invalid --format %q (supported: %s)", format, strings.Join(testresults.Formats(), ", "))
+			}
+			if interval <= 0 {
+				return usageErrorf("--interval must be positive")
+			}
+			return handleIngestTestResults(args, watchDir, interval, testresults.Options{Format: format, Project: project})
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", testresults.FormatAuto, "Result format ("+strings.Join(testresults.Formats(), ", ")+")")
+	cmd.Flags().StringVarP(&project, "project", "p", "", "Only link to sessions and commits of this project")
+	cmd.Flags().StringVar(&watchDir, "watch", "", "Watch a directory and ingest result files as they appear")
+	cmd.Flags().DurationVar(&interval, "interval", defaultIngestWatchInterval, "How often --watch rescans the directory")
+
+	return cmd
+}
+
+// isTestResultFormat reports whether format is a supported result format
+func isTestResultFormat(format string) bool {
+	for _, f := range testresults.Formats() {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// handleIngestTestResults implements the ingest test-results command
+func handleIngestTestResults(paths []string, watchDir string, interval time.Duration, opts testresults.Options) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	database, err := openDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	ingester, err := testresults.NewIngester(database, logging.NewNoopLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create test results ingester: %w", err)
+	}
+
+	if watchDir != "" {
+		return watchTestResults(ingester, watchDir, interval, opts)
+	}
+
+	for _, path := range paths {
+		result, err := ingester.Ingest(path, opts)
+		if err != nil {
+			return fmt.Errorf("failed to ingest %s: %w", path, err)
+		}
+		printTestRunResult(path, result)
+	}
+	return nil
+}
+
+// watchTestResults polls dir for new or changed result files until interrupted
+func watchTestResults(ingester testresults.Ingester, dir string, interval time.Duration, opts testresults.Options) error {
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return usageErrorf("--watch %s is not a directory", dir)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Printf("Watching %s for test results (Ctrl+C to stop)\n", dir)
+	modTimes := make(map[string]time.Time)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for _, path := range changedResultFiles(dir, modTimes) {
+			result, err := ingester.Ingest(path, opts)
+			if err != nil {
+				// Runners may still be writing the file; it's retried when it changes again
+				fmt.Fprintf(os.Stderr, "Skipping %s: %v\n", path, err)
+				continue
+			}
+			printTestRunResult(path, result)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// changedResultFiles returns result files in dir modified since they were last seen, in name order
+func changedResultFiles(dir string, modTimes map[string]time.Time) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to read %s: %v\n", dir, err)
+		return nil
+	}
+
+	var changed []string
+	for _, entry := range entries {
+		if entry.IsDir() || !hasResultFileExtension(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		if seen, ok := modTimes[path]; ok && seen.Equal(info.ModTime()) {
+			continue
+		}
+		modTimes[path] = info.ModTime()
+		changed = append(changed, path)
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// hasResultFileExtension reports whether name looks like a result file
+func hasResultFileExtension(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, e := range resultFileExtensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// printTestRunResult prints a one-line summary of an ingested run
+func printTestRunResult(path string, result *testresults.Result) {
+	if result.Duplicate {
+		fmt.Printf("%s: already ingested\n", path)
+		return
+	}
+
+	status := "PASS"
+	if result.Failed > 0 {
+		status = "FAIL"
+	}
+	fmt.Printf("%s: %s %d passed, %d failed, %d skipped (%s)", path, status, result.Passed, result.Failed, result.Skipped, result.Format)
+	if result.SessionID != "" {
+		fmt.Printf(", session %s", result.SessionID)
+	}
+	if result.CommitHash != "" {
+		fmt.Printf(", commit %s", shortHash(result.CommitHash))
+	}
+	fmt.Println()
+}
@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/stwalsh4118/clio/internal/issues"
+	"github.com/stwalsh4118/clio/pkg/export"
+)
+
+// newIssueCmd creates the issue command
+func newIssueCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "issue",
+		Short: "Open issues from captured conversations",
+		Long:  `Open GitHub or GitLab issues drafted from captured conversations.`,
+	}
+
+	cmd.AddCommand(newIssueCreateCmd())
+	return cmd
+}
+
+// newIssueCreateCmd creates the issue create subcommand
+func newIssueCreateCmd() *cobra.Command {
+	var from string
+	var repo string
+	var provider string
+	var title string
+	var labels []string
+	var excerpts int
+	var codeBlocks int
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Draft an issue from a conversation and open it",
+		Long: `Draft an issue from a conversation where a bug or TODO was discussed and open
+it through the GitHub or GitLab API. The title is the conversation's name (or
+its first request); the body quotes the request, the lines that mention the
+problem (bugs, errors, failures, TODOs), and code blocks from the messages
+containing them, and notes which conversation it came from. Redaction rules
+from the redaction configuration block apply.
+
+--dry-run prints the draft without creating anything; review it before
+opening the issue. Creating needs an API token in issues.github.token or
+issues.gitlab.token, as a secret reference (see 'clio secrets');
+issues.<provider>.api_url points at GitHub Enterprise or a self-managed GitLab.
+
+The conversation is a composer ID or a unique prefix of one. --repo is
+owner/name; GitLab repositories may be nested in groups.
+
+Examples:
+  clio issue create --from 3f2a9c --repo stwalsh4118/clio --dry-run
+  clio issue create --from 3f2a9c --repo stwalsh4118/clio --label bug
+  clio issue create --from 3f2a9c --repo group/sub/clio --provider gitlab --title "Lexer panics on empty input"`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if provider != issues.ProviderGitHub && provider != issues.ProviderGitLab {
+				return usageErrorf("invalid --provider %q (valid: %s, %s)", provider, issues.ProviderGitHub, issues.ProviderGitLab)
+			}
+			if err := issues.ValidateRepo(provider, repo); err != nil {
+				return usageErrorf("invalid --repo: %v", err)
+			}
+			if excerpts < 1 || codeBlocks < 0 {
+				return usageErrorf("--excerpts must be at least 1 and --code-blocks at least 0")
+			}
+			opts := issues.Options{Title: title, Labels: labels, Excerpts: excerpts, CodeBlocks: codeBlocks}
+			return handleIssueCreate(from, repo, provider, opts, dryRun)
+		},
+	}
+
+	cmd.Flags().StringVar(&from, "from", "", "Conversation to draft the issue from (composer ID or prefix)")
+	cmd.Flags().StringVar(&repo, "repo", "", "Repository to open the issue in, as owner/name")
+	cmd.Flags().StringVar(&provider, "provider", issues.ProviderGitHub, "Issue tracker: github or gitlab")
+	cmd.Flags().StringVar(&title, "title", "", "Issue title (defaults to the conversation's name)")
+	cmd.Flags().StringArrayVar(&labels, "label", nil, "Label to apply (repeatable)")
+	cmd.Flags().IntVar(&excerpts, "excerpts", issues.DefaultExcerpts, "Lines mentioning the problem to quote")
+	cmd.Flags().IntVar(&codeBlocks, "code-blocks", issues.DefaultCodeBlocks, "Code blocks to include")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the draft without creating the issue")
+	_ = cmd.MarkFlagRequired("from")
+	_ = cmd.MarkFlagRequired("repo")
+
+	return cmd
+}
+
+// handleIssueCreate implements the issue create command
+func handleIssueCreate(from, repo, provider string, opts issues.Options, dryRun bool) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	// The tracker is checked first, so a missing token doesn't surface after drafting
+	var tracker issues.Tracker
+	if !dryRun {
+		if tracker, err = issues.NewTracker(cfg.Issues, provider); err != nil {
+			return err
+		}
+	}
+
+	database, err := openReadOnlyDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	reporter, err := newBlobReporter(cfg, database)
+	if err != nil {
+		return err
+	}
+	conversation, err := reporter.ExportConversation(from)
+	if err != nil {
+		return usageErrorf("%v", err)
+	}
+
+	// Redaction works on export data, so the conversation is wrapped in a session
+	data := &export.Data{Sessions: []export.Session{{Conversations: []export.Conversation{*conversation}}}}
+	redactionFlags{}.resolve(cfg.Redaction).Apply(data)
+	draft := issues.BuildDraft(data.Sessions[0].Conversations[0], opts)
+
+	if dryRun {
+		fmt.Printf("Repository: %s (%s)\n", repo, provider)
+		fmt.Printf("Title: %s\n", draft.Title)
+		if len(draft.Labels) > 0 {
+			fmt.Printf("Labels: %s\n", strings.Join(draft.Labels, ", "))
+		}
+		fmt.Printf("\n%s", draft.Body)
+		return nil
+	}
+
+	issue, err := tracker.Create(context.Background(), repo, draft)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Created issue #%d: %s\n", issue.Number, issue.URL)
+	return nil
+}
@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stwalsh4118/clio/internal/journal"
+	"github.com/stwalsh4118/clio/internal/logging"
+	"github.com/stwalsh4118/clio/internal/report"
+)
+
+// newJournalCmd creates the journal command
+func newJournalCmd() *cobra.Command {
+	var sessionRef string
+	var list bool
+
+	cmd := &cobra.Command{
+		Use:   "journal [text...]",
+		Short: "Write a note into a session's journal",
+		Long: `Write a timestamped note into a session, recording intent, decisions, and
+dead ends the AI conversations don't capture. Notes are interleaved with the
+session's conversations in exports.
+
+Notes go to the active session unless --session names another: a full session
+ID, a unique ID prefix, or "latest". Pass "-" to read the note from stdin, for
+example a voice memo transcript.
+
+Examples:
+  clio journal "Switching to a trie; the regex approach can't handle prefixes"
+  clio journal --session latest "Abandoned: the API doesn't expose timestamps"
+  pbpaste | clio journal -
+  clio journal --list`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if list {
+				if len(args) > 0 {
+					return usageErrorf("--list cannot be combined with a note")
+				}
+				return handleJournalList(sessionRef)
+			}
+			if len(args) == 0 {
+				return usageErrorf("provide the note text, or - to read it from stdin")
+			}
+
+			text := strings.Join(args, " ")
+			if text == "-" {
+				data, err := io.ReadAll(os.Stdin)
+				if err != nil {
+					return fmt.Errorf("failed to read note from stdin: %w", err)
+				}
+				text = string(data)
+			}
+			if strings.TrimSpace(text) == "" {
+				return usageErrorf("journal note cannot be empty")
+			}
+			return handleJournal(sessionRef, text)
+		},
+	}
+
+	cmd.Flags().StringVarP(&sessionRef, "session", "s", report.ActiveSession, "Session to write to (ID, ID prefix, \"latest\", or \"active\")")
+	cmd.Flags().BoolVarP(&list, "list", "l", false, "List the session's notes instead of writing one")
+
+	return cmd
+}
+
+// handleJournal implements the journal command
+func handleJournal(sessionRef, text string) error {
+	database, sessionID, err := openJournalSession(sessionRef)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	j, err := journal.NewJournal(database, logging.NewNoopLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create journal: %w", err)
+	}
+	entry, err := j.Add(sessionID, text, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to write journal entry: %w", err)
+	}
+
+	fmt.Printf("Noted in session %s at %s\n", sessionID, formatTime(entry.CreatedAt))
+	return nil
+}
+
+// handleJournalList implements journal --list
+func handleJournalList(sessionRef string) error {
+	database, sessionID, err := openJournalSession(sessionRef)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	j, err := journal.NewJournal(database, logging.NewNoopLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create journal: %w", err)
+	}
+	entries, err := j.List(sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to list journal entries: %w", err)
+	}
+
+	if len(entries) == 0 {
+		fmt.Printf("No journal entries in session %s\n", sessionID)
+		return nil
+	}
+	fmt.Printf("Journal for session %s:\n", sessionID)
+	for _, entry := range entries {
+		fmt.Printf("  %s  %s\n", formatTime(entry.CreatedAt), strings.ReplaceAll(entry.Text, "\n", "\n                    "))
+	}
+	return nil
+}
+
+// openJournalSession opens the database and resolves the session a journal command targets
+func openJournalSession(sessionRef string) (*sql.DB, string, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, "", err
+	}
+
+	database, err := openDatabase(cfg)
+	if err != nil {
+		return nil, "", err
+	}
+
+	reporter, err := report.NewReporter(database, logging.NewNoopLogger())
+	if err != nil {
+		database.Close()
+		return nil, "", fmt.Errorf("failed to create reporter: %w", err)
+	}
+	sessionID, err := reporter.ResolveSession(sessionRef)
+	if err != nil {
+		database.Close()
+		if sessionRef == report.ActiveSession {
+			return nil, "", usageErrorf("%v; use --session to pick one", err)
+		}
+		return nil, "", usageErrorf("%v", err)
+	}
+	return database, sessionID, nil
+}
@@ -0,0 +1,258 @@
+package cli
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stwalsh4118/clio/internal/logging"
+	"github.com/stwalsh4118/clio/internal/meta"
+	"github.com/stwalsh4118/clio/internal/report"
+)
+
+// newMetaCmd creates the meta command with set, get, unset, and list subcommands
+func newMetaCmd() *cobra.Command {
+	var kind string
+
+	cmd := &cobra.Command{
+		Use:   "meta",
+		Short: "Attach key=value metadata to sessions and commits",
+		Long: `Attach arbitrary key=value context to sessions and commits, such as the
+ticket, customer, or experiment they were for.
+
+The entity is a session ID, a unique session ID prefix, "latest", or "active",
+or a commit hash or a unique prefix of one. When it matches both a session and
+a commit, --kind picks one. Keys are lowercase letters, digits, '.', '-' and
+'_'; values are kept as written.
+
+Metadata is included with each session and commit in exports, where custom
+exporters and templates can read it, and 'clio export --meta key=value' or a
+meta:key=value filter term (see clio filters) keeps only the sessions whose
+metadata, or whose commits' metadata, has the value.
+
+Examples:
+  clio meta set latest ticket=CLI-42 customer=acme
+  clio meta set 3f2a9c1 reviewed-by=sam --kind commit
+  clio meta get latest
+  clio meta list customer
+  clio meta unset latest ticket`,
+	}
+	cmd.PersistentFlags().StringVar(&kind, "kind", "", "Treat the entity as a session or a commit (default: whichever it matches)")
+
+	checkKind := func() error {
+		switch meta.EntityType(kind) {
+		case "", meta.EntitySession, meta.EntityCommit:
+			return nil
+		default:
+			return usageErrorf("invalid --kind %q: use session or commit", kind)
+		}
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "set <session|commit> <key=value>...",
+		Short: "Set metadata keys, replacing their values",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkKind(); err != nil {
+				return err
+			}
+			values := make(map[string]string)
+			for _, pair := range args[1:] {
+				key, value, err := meta.ParsePair(pair)
+				if err != nil {
+					return usageErrorf("%v", err)
+				}
+				values[key] = value
+			}
+			return handleMetaSet(args[0], meta.EntityType(kind), values)
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "get <session|commit>",
+		Short: "Show an entity's metadata",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkKind(); err != nil {
+				return err
+			}
+			return handleMetaGet(args[0], meta.EntityType(kind))
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:     "unset <session|commit> <key>...",
+		Aliases: []string{"rm"},
+		Short:   "Remove metadata keys",
+		Args:    cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkKind(); err != nil {
+				return err
+			}
+			return handleMetaUnset(args[0], meta.EntityType(kind), args[1:])
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:     "list [key]",
+		Aliases: []string{"ls"},
+		Short:   "List metadata, or the entities that have a key",
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key := ""
+			if len(args) == 1 {
+				key = args[0]
+			}
+			return handleMetaList(key)
+		},
+	})
+
+	return cmd
+}
+
+// handleMetaSet implements meta set
+func handleMetaSet(ref string, kind meta.EntityType, values map[string]string) error {
+	database, store, err := openMetaStore()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	entityType, entityID, err := resolveMetaEntity(database, ref, kind)
+	if err != nil {
+		return err
+	}
+	if err := store.Set(entityType, entityID, values, time.Now()); err != nil {
+		return usageErrorf("%v", err)
+	}
+	fmt.Printf("Set %d key(s) on %s %s\n", len(values), entityType, entityID)
+	return nil
+}
+
+// handleMetaGet implements meta get
+func handleMetaGet(ref string, kind meta.EntityType) error {
+	database, store, err := openMetaStore()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	entityType, entityID, err := resolveMetaEntity(database, ref, kind)
+	if err != nil {
+		return err
+	}
+	values, err := store.Get(entityType, entityID)
+	if err != nil {
+		return fmt.Errorf("failed to read metadata: %w", err)
+	}
+	if len(values) == 0 {
+		fmt.Printf("No metadata on %s %s. Add some with 'clio meta set'.\n", entityType, entityID)
+		return nil
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Printf("%s=%s\n", key, values[key])
+	}
+	return nil
+}
+
+// handleMetaUnset implements meta unset
+func handleMetaUnset(ref string, kind meta.EntityType, keys []string) error {
+	database, store, err := openMetaStore()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	entityType, entityID, err := resolveMetaEntity(database, ref, kind)
+	if err != nil {
+		return err
+	}
+	removed, err := store.Unset(entityType, entityID, keys)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Removed %d key(s) from %s %s\n", removed, entityType, entityID)
+	return nil
+}
+
+// handleMetaList implements meta list
+func handleMetaList(key string) error {
+	database, store, err := openMetaStore()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	entries, err := store.List(key)
+	if err != nil {
+		return fmt.Errorf("failed to list metadata: %w", err)
+	}
+	if len(entries) == 0 {
+		fmt.Println("No metadata. Add some with 'clio meta set'.")
+		return nil
+	}
+	for _, entry := range entries {
+		fmt.Printf("%-8s %-40s %s=%s\n", entry.EntityType, entry.EntityID, entry.Key, entry.Value)
+	}
+	return nil
+}
+
+// openMetaStore opens the database and a metadata store on it
+func openMetaStore() (*sql.DB, meta.Store, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	database, err := openDatabase(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	store, err := meta.NewStore(database, logging.NewNoopLogger())
+	if err != nil {
+		database.Close()
+		return nil, nil, fmt.Errorf("failed to create metadata store: %w", err)
+	}
+	return database, store, nil
+}
+
+// resolveMetaEntity resolves a session or commit reference, limited to kind when it's set
+func resolveMetaEntity(database *sql.DB, ref string, kind meta.EntityType) (meta.EntityType, string, error) {
+	reporter, err := report.NewReporter(database, logging.NewNoopLogger())
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create reporter: %w", err)
+	}
+
+	var sessionID, hash string
+	var sessionErr, commitErr error
+	if kind != meta.EntityCommit {
+		sessionID, sessionErr = reporter.ResolveSession(ref)
+	}
+	if kind != meta.EntitySession {
+		hash, commitErr = reporter.ResolveCommit(ref)
+	}
+
+	switch {
+	case sessionID != "" && hash != "":
+		return "", "", usageErrorf("%q matches session %s and commit %s; pick one with --kind", ref, sessionID, hash)
+	case sessionID != "":
+		return meta.EntitySession, sessionID, nil
+	case hash != "":
+		return meta.EntityCommit, hash, nil
+	case kind == meta.EntityCommit:
+		return "", "", usageErrorf("%v", commitErr)
+	case kind == meta.EntitySession:
+		return "", "", usageErrorf("%v", sessionErr)
+	default:
+		return "", "", usageErrorf("%v; %v", sessionErr, commitErr)
+	}
+}
@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/stwalsh4118/clio/internal/opener"
+	"github.com/stwalsh4118/clio/internal/report"
+	"github.com/stwalsh4118/clio/internal/transcript"
+	"github.com/stwalsh4118/clio/pkg/export"
+)
+
+// openDirName is the directory under the system temp directory holding renderings
+const openDirName = "clio-open"
+
+// newOpenCmd creates the open command
+func newOpenCmd() *cobra.Command {
+	var viewer bool
+
+	cmd := &cobra.Command{
+		Use:   "open <session|conversation>",
+		Short: "Open a session or conversation as Markdown in an editor or viewer",
+		Long: `Render a session or a single conversation as Markdown and open it in $VISUAL or
+$EDITOR, or the platform's default Markdown viewer when neither is set or
+--viewer is given, for reading transcripts outside the terminal.
+
+The reference is tried as a session first (a full ID, a unique ID prefix,
+"latest", or "active"), then as a conversation's composer ID or unique prefix.
+Renderings are written under the system temp directory, one file per session or
+conversation, and replaced on the next open.
+
+Examples:
+  clio open latest
+  clio open 3f2a9c --viewer`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handleOpen(args[0], viewer)
+		},
+	}
+
+	cmd.Flags().BoolVar(&viewer, "viewer", false, "Open in the default viewer even when $EDITOR is set")
+
+	return cmd
+}
+
+// handleOpen implements the open command
+func handleOpen(ref string, viewer bool) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	database, err := openReadOnlyDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	reporter, err := newBlobReporter(cfg, database)
+	if err != nil {
+		return err
+	}
+
+	name, rendered, err := renderForOpen(reporter, ref)
+	if err != nil {
+		return err
+	}
+
+	return openRendering(name, rendered, 0, viewer)
+}
+
+// openRendering writes a rendering under the temp directory and opens it at a
+// 1-based line, or the top when line is zero
+func openRendering(name string, rendered []byte, line int, viewer bool) error {
+	dir := filepath.Join(os.TempDir(), openDirName)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, rendered, 0600); err != nil {
+		return fmt.Errorf("failed to write rendering: %w", err)
+	}
+
+	command, err := opener.ResolveAt(path, line, viewer)
+	if err != nil {
+		return usageErrorf("%v", err)
+	}
+	fmt.Fprintf(os.Stderr, "Opening %s with %s\n", path, command.Name)
+	return command.Run()
+}
+
+// renderForOpen renders the session or conversation ref refers to as Markdown,
+// returning a file name for it
+func renderForOpen(reporter report.Reporter, ref string) (string, []byte, error) {
+	var buf bytes.Buffer
+	sessionID, sessionErr := reporter.ResolveSession(ref)
+	if sessionErr == nil {
+		data, err := reporter.ExportData(report.ExportOptions{SessionID: sessionID})
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to load session: %w", err)
+		}
+		markdown, _ := export.Lookup("markdown")
+		if err := markdown.Export(&buf, data); err != nil {
+			return "", nil, fmt.Errorf("failed to render session: %w", err)
+		}
+		return "session-" + sessionID + ".md", buf.Bytes(), nil
+	}
+
+	conversation, err := reporter.ExportConversation(ref)
+	if err != nil {
+		return "", nil, usageErrorf("no session or conversation matches %q: %v; %v", ref, sessionErr, err)
+	}
+	if err := transcript.Write(&buf, *conversation); err != nil {
+		return "", nil, err
+	}
+	return conversationFileName(conversation.ComposerID), buf.Bytes(), nil
+}
+
+// conversationFileName names the rendering of a conversation
+func conversationFileName(composerID string) string {
+	return "conversation-" + filepath.Base(composerID) + ".md"
+}
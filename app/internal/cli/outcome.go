@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stwalsh4118/clio/internal/filters"
+	"github.com/stwalsh4118/clio/internal/logging"
+	"github.com/stwalsh4118/clio/internal/outcomes"
+	"github.com/stwalsh4118/clio/internal/report"
+)
+
+// outcomeClear is the outcome argument that clears a manual outcome
+const outcomeClear = "clear"
+
+// newOutcomeCmd creates the outcome command with a list subcommand
+func newOutcomeCmd() *cobra.Command {
+	var note string
+
+	cmd := &cobra.Command{
+		Use:   "outcome <session> <shipped|abandoned|blocked|clear>",
+		Short: "Record how a session ended",
+		Long: `Record how a session ended: shipped, abandoned, or blocked.
+
+Sessions without a recorded outcome have one inferred once they end. A session
+shipped when it produced commits that no later commit reverted, or, without
+commits of its own, when its project got a commit within a day of it ending; it
+was abandoned when its commits were all reverted or no commit followed. Blocked
+is only ever recorded by hand. "clear" removes a recorded outcome so it's
+inferred again.
+
+The session is a session ID, a unique session ID prefix, "latest", or "active".
+Completion rates per week and project are shown by 'clio stats --outcomes'.
+
+Examples:
+  clio outcome latest blocked --note "waiting on API access"
+  clio outcome 3f2a9c shipped
+  clio outcome list --since 7d`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if args[1] == outcomeClear {
+				return handleOutcome(args[0], "", note)
+			}
+			outcome, err := outcomes.Parse(args[1])
+			if err != nil {
+				return usageErrorf("%v", err)
+			}
+			return handleOutcome(args[0], outcome, note)
+		},
+	}
+	cmd.Flags().StringVar(&note, "note", "", "Why the session ended this way, such as what blocked it")
+
+	var project string
+	var since string
+	var until string
+	list := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List sessions with their outcomes, newest first",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			now := time.Now()
+			sinceTime, err := filters.ParseTime(since, now)
+			if err != nil {
+				return usageErrorf("invalid --since: %w", err)
+			}
+			untilTime, err := filters.ParseTime(until, now)
+			if err != nil {
+				return usageErrorf("invalid --until: %w", err)
+			}
+			return handleOutcomeList(outcomes.Options{Project: project, Since: sinceTime, Until: untilTime})
+		},
+	}
+	list.Flags().StringVar(&project, "project", "", "Only include this project")
+	list.Flags().StringVar(&since, "since", "7d", "Only include sessions started at or after this time (date, timestamp, or duration like 7d)")
+	list.Flags().StringVar(&until, "until", "", "Only include sessions started before this time (date, timestamp, or duration like 7d)")
+	cmd.AddCommand(list)
+
+	return cmd
+}
+
+// handleOutcome implements the outcome command; an empty outcome clears it
+func handleOutcome(ref string, outcome outcomes.Outcome, note string) error {
+	database, store, err := openOutcomeStore()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	reporter, err := report.NewReporter(database, logging.NewNoopLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create reporter: %w", err)
+	}
+	sessionID, err := reporter.ResolveSession(ref)
+	if err != nil {
+		return usageErrorf("%v", err)
+	}
+
+	if outcome == "" {
+		if err := store.Clear(sessionID); err != nil {
+			return fmt.Errorf("failed to clear outcome: %w", err)
+		}
+		fmt.Printf("Cleared the outcome of session %s; it will be inferred\n", sessionID)
+		return nil
+	}
+	if err := store.Set(sessionID, outcome, note, time.Now()); err != nil {
+		return fmt.Errorf("failed to set outcome: %w", err)
+	}
+	fmt.Printf("Session %s: %s\n", sessionID, outcome)
+	return nil
+}
+
+// handleOutcomeList implements outcome list
+func handleOutcomeList(opts outcomes.Options) error {
+	database, store, err := openOutcomeStore()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	sessions, err := store.Sessions(opts, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to list outcomes: %w", err)
+	}
+	if len(sessions) == 0 {
+		fmt.Println("No sessions in this range")
+		return nil
+	}
+
+	for _, session := range sessions {
+		outcome := string(session.Outcome)
+		switch {
+		case outcome == "":
+			outcome = "unknown"
+		case session.Source == outcomes.SourceInferred:
+			outcome += "?"
+		}
+		fmt.Printf("%-10s  %s  %-16s  %s", outcome, formatTime(session.Start), session.Project, session.SessionID)
+		if session.Note != "" {
+			fmt.Printf("  (%s)", session.Note)
+		}
+		fmt.Println()
+	}
+	fmt.Println("\nOutcomes marked ? were inferred; record one with 'clio outcome <session> <outcome>'.")
+	return nil
+}
+
+// openOutcomeStore opens the database and an outcome store on it
+func openOutcomeStore() (*sql.DB, outcomes.Store, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	database, err := openDatabase(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	store, err := outcomes.NewStore(database, logging.NewNoopLogger())
+	if err != nil {
+		database.Close()
+		return nil, nil, fmt.Errorf("failed to create outcome store: %w", err)
+	}
+	return database, store, nil
+}
@@ -0,0 +1,233 @@
+package cli
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stwalsh4118/clio/internal/logging"
+	"github.com/stwalsh4118/clio/internal/pins"
+	"github.com/stwalsh4118/clio/internal/report"
+)
+
+// newPinCmd creates the pin command with list and remove subcommands
+func newPinCmd() *cobra.Command {
+	var note string
+	var kind string
+
+	cmd := &cobra.Command{
+		Use:   "pin <conversation|session>",
+		Short: "Pin a conversation or session as a favorite",
+		Long: `Pin a conversation or session worth finding again, such as a great debugging
+session. Pinned sessions sort to the top of 'clio report --orphans', and what
+was pinned during the period gets its own section in 'clio standup'.
+
+The reference is a session ID, a unique session ID prefix, "latest", or
+"active", or a composer ID or a unique prefix of one. When it matches both a
+session and a conversation, --kind picks one. Pinning again keeps the pin and
+replaces its note when --note is given.
+
+Examples:
+  clio pin latest --note "great debugging session"
+  clio pin 3f2a9c --kind conversation
+  clio pin list
+  clio pin remove 2`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch pins.Kind(kind) {
+			case "", pins.KindSession, pins.KindConversation:
+			default:
+				return usageErrorf("invalid --kind %q: use session or conversation", kind)
+			}
+			return handlePin(args[0], pins.Kind(kind), note)
+		},
+	}
+	cmd.Flags().StringVar(&note, "note", "", "Why the conversation or session is worth finding again")
+	cmd.Flags().StringVar(&kind, "kind", "", "Treat the reference as a session or a conversation (default: whichever it matches)")
+
+	cmd.AddCommand(&cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List pins, most recently pinned first",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handlePinList()
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:     "remove <id>",
+		Aliases: []string{"rm"},
+		Short:   "Remove a pin",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.ParseInt(strings.TrimPrefix(args[0], "#"), 10, 64)
+			if err != nil {
+				return usageErrorf("invalid pin ID %q", args[0])
+			}
+			return handlePinRemove(id)
+		},
+	})
+
+	return cmd
+}
+
+// handlePin implements the pin command
+func handlePin(ref string, kind pins.Kind, note string) error {
+	database, store, err := openPinStore()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	reporter, err := report.NewReporter(database, logging.NewNoopLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create reporter: %w", err)
+	}
+
+	var sessionID, composerID string
+	var sessionErr, conversationErr error
+	if kind != pins.KindConversation {
+		sessionID, sessionErr = reporter.ResolveSession(ref)
+	}
+	if kind != pins.KindSession {
+		composerID, conversationErr = reporter.ResolveConversation(ref)
+	}
+
+	targetID := sessionID
+	switch {
+	case sessionID != "" && composerID != "":
+		return usageErrorf("%q matches session %s and conversation %s; pick one with --kind", ref, sessionID, composerID)
+	case sessionID != "":
+		kind = pins.KindSession
+	case composerID != "":
+		kind, targetID = pins.KindConversation, composerID
+	case kind == pins.KindConversation:
+		return usageErrorf("%v", conversationErr)
+	case kind == pins.KindSession:
+		return usageErrorf("%v", sessionErr)
+	default:
+		return usageErrorf("%v; %v", sessionErr, conversationErr)
+	}
+
+	pin, err := store.Pin(kind, targetID, note, time.Now())
+	if err != nil {
+		return usageErrorf("%v", err)
+	}
+	fmt.Printf("Pinned #%d: %s\n", pin.ID, pinLabel(*pin))
+	return nil
+}
+
+// handlePinList implements pin list
+func handlePinList() error {
+	database, store, err := openPinStore()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	list, err := store.List()
+	if err != nil {
+		return fmt.Errorf("failed to list pins: %w", err)
+	}
+	if len(list) == 0 {
+		fmt.Println("No pins. Pin a conversation or session with 'clio pin'.")
+		return nil
+	}
+
+	for _, pin := range list {
+		fmt.Printf("#%-3d %-12s %s\n", pin.ID, pin.Kind, pinLabel(pin))
+		switch {
+		case pin.Missing:
+			fmt.Println("      no longer in the database")
+		case pin.Kind == pins.KindSession:
+			fmt.Printf("      see clio replay %s\n", pin.TargetID)
+		default:
+			fmt.Printf("      see clio conversations export %s\n", pin.TargetID)
+		}
+	}
+	return nil
+}
+
+// handlePinRemove implements pin remove
+func handlePinRemove(id int64) error {
+	database, store, err := openPinStore()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	if err := store.Unpin(id); err != nil {
+		return usageErrorf("%v", err)
+	}
+	fmt.Printf("Removed pin #%d\n", id)
+	return nil
+}
+
+// openPinStore opens the database and a pin store on it
+func openPinStore() (*sql.DB, pins.Store, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	database, err := openDatabase(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	store, err := pins.NewStore(database, logging.NewNoopLogger())
+	if err != nil {
+		database.Close()
+		return nil, nil, fmt.Errorf("failed to create pin store: %w", err)
+	}
+	return database, store, nil
+}
+
+// pinLabel describes a pin by its conversation's name or session ID, with its
+// project, start, and note
+func pinLabel(pin pins.Pin) string {
+	label := pin.TargetID
+	if pin.Kind == pins.KindConversation && pin.Name != "" {
+		label = fmt.Sprintf("%q", pin.Name)
+	}
+	if !pin.Missing {
+		label += fmt.Sprintf(" (%s, %s)", pin.Project, formatTime(pin.Start))
+	}
+	if pin.Note != "" {
+		label += ": " + pin.Note
+	}
+	return label
+}
+
+// listPins returns every pin in the database
+func listPins(database *sql.DB) ([]pins.Pin, error) {
+	store, err := pins.NewStore(database, logging.NewNoopLogger())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pin store: %w", err)
+	}
+	list, err := store.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pins: %w", err)
+	}
+	return list, nil
+}
+
+// sortPinnedFirst moves pinned sessions to the top, keeping the order otherwise,
+// and returns the pinned session IDs
+func sortPinnedFirst(sessions []report.OrphanSession, list []pins.Pin) map[string]bool {
+	pinned := make(map[string]bool)
+	for _, pin := range list {
+		if pin.Kind == pins.KindSession {
+			pinned[pin.TargetID] = true
+		}
+	}
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return pinned[sessions[i].ID] && !pinned[sessions[j].ID]
+	})
+	return pinned
+}
@@ -0,0 +1,175 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stwalsh4118/clio/internal/git"
+	"github.com/stwalsh4118/clio/internal/logging"
+	"github.com/stwalsh4118/clio/internal/releasenotes"
+	"github.com/stwalsh4118/clio/internal/summaries"
+)
+
+// newReleaseNotesCmd creates the release-notes command
+func newReleaseNotesCmd() *cobra.Command {
+	var repository string
+	var from string
+	var to string
+	var heuristic bool
+	var noSummaries bool
+	var changelog bool
+
+	cmd := &cobra.Command{
+		Use:   "release-notes",
+		Short: "Compile Markdown release notes for the commits between two tags",
+		Long: `Compile release notes for the commits reachable from --to but not from --from,
+grouped by Conventional Commits type (features, bug fixes, ...) with breaking
+changes repeated first. Commits that don't follow the convention are listed
+under other changes; merge, fixup!, and squash! commits are left out.
+
+Each commit clio captured in a session is annotated with a one-line summary of
+the conversation it came out of, generated as 'clio summarize' does: with
+summaries.command (for example a script asking an LLM) when it's configured,
+otherwise with the built-in extractive summarizer. Summaries are cached, so
+compiling the notes again is cheap.
+
+The Markdown is printed to stdout, ready for a GitHub release. With
+--changelog the entries are written to CHANGELOG.md at the repository's root
+instead, in Keep a Changelog format: features under Added, fixes under Fixed,
+and other notable changes under Changed. Compiling a version already in the
+changelog replaces its section rather than adding another; --to HEAD writes
+the Unreleased section.
+
+Examples:
+  clio release-notes --from v1.3.0 --to v1.4.0
+  clio release-notes --repo ~/src/clio --from v1.3.0 --heuristic
+  clio release-notes --from v1.3.0 --to v1.4.0 | gh release create v1.4.0 --notes-file -
+  clio release-notes --from v1.3.0 --to v1.4.0 --changelog`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handleReleaseNotes(repository, from, to, heuristic, noSummaries, changelog)
+		},
+	}
+
+	cmd.Flags().StringVar(&repository, "repo", "", "Repository path (defaults to the one containing the current directory)")
+	cmd.Flags().StringVar(&from, "from", "", "Tag (or other revision) of the previous release")
+	cmd.Flags().StringVar(&to, "to", "HEAD", "Tag (or other revision) being released")
+	cmd.Flags().BoolVar(&heuristic, "heuristic", false, "Use the built-in extractive summarizer even when summaries.command is set")
+	cmd.Flags().BoolVar(&noSummaries, "no-summaries", false, "Leave out conversation summaries")
+	cmd.Flags().BoolVar(&changelog, "changelog", false, "Write the entries to "+releasenotes.ChangelogName+" in the repository instead of printing them")
+	cmd.MarkFlagsMutuallyExclusive("heuristic", "no-summaries")
+	_ = cmd.MarkFlagRequired("from")
+
+	return cmd
+}
+
+// handleReleaseNotes implements the release-notes command
+func handleReleaseNotes(repository, from, to string, heuristic, noSummaries, changelog bool) error {
+	if repository == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get working directory: %w", err)
+		}
+		repository = cwd
+	}
+	repo, root, err := git.OpenContaining(repository)
+	if err != nil {
+		return usageErrorf("not in a git repository; pass --repo")
+	}
+
+	fromHash, err := git.ResolveCommit(repo, from)
+	if err != nil {
+		return usageErrorf("%v", err)
+	}
+	toHash, err := git.ResolveCommit(repo, to)
+	if err != nil {
+		return usageErrorf("%v", err)
+	}
+	rangeCommits, err := git.CommitRange(repo, toHash, []string{fromHash})
+	if err != nil {
+		return err
+	}
+
+	commits := make([]releasenotes.Commit, len(rangeCommits))
+	for i, commit := range rangeCommits {
+		commits[i] = releasenotes.Commit{Hash: commit.Hash, Message: commit.Message, Merge: commit.Merge}
+	}
+	if !noSummaries && len(commits) > 0 {
+		if err := summarizeReleaseCommits(commits, heuristic); err != nil {
+			return err
+		}
+	}
+
+	date := time.Now()
+	if len(rangeCommits) > 0 {
+		date = rangeCommits[len(rangeCommits)-1].When
+	}
+	notes := releasenotes.Build(from, to, date, commits)
+	if !changelog {
+		fmt.Print(notes.Markdown())
+		return nil
+	}
+
+	path := filepath.Join(root, releasenotes.ChangelogName)
+	change, err := releasenotes.WriteChangelog(path, notes)
+	if err != nil {
+		return err
+	}
+	switch change {
+	case releasenotes.ChangelogCreated:
+		fmt.Printf("Created %s with %s\n", path, to)
+	case releasenotes.ChangelogAdded:
+		fmt.Printf("Added %s to %s\n", to, path)
+	case releasenotes.ChangelogReplaced:
+		fmt.Printf("Updated %s in %s\n", to, path)
+	default:
+		fmt.Printf("%s is already up to date in %s\n", to, path)
+	}
+	return nil
+}
+
+// summarizeReleaseCommits sets the summary of each commit's conversation
+func summarizeReleaseCommits(commits []releasenotes.Commit, heuristic bool) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	database, err := openDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	store, err := openBlobStore(cfg)
+	if err != nil {
+		return err
+	}
+	cache, err := summaries.NewCache(database, store, logging.NewNoopLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create summary cache: %w", err)
+	}
+	summarizer, err := newSummarizer(cfg.Summaries, heuristic)
+	if err != nil {
+		return err
+	}
+	enricher, err := releasenotes.NewEnricher(database, cache, summarizer, logging.NewNoopLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create release notes enricher: %w", err)
+	}
+	timeout := time.Duration(cfg.Summaries.TimeoutSeconds) * time.Second
+
+	for i := range commits {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		commits[i].Summary, err = enricher.Summary(ctx, commits[i].Hash)
+		cancel()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
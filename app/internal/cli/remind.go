@@ -0,0 +1,240 @@
+package cli
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stwalsh4118/clio/internal/logging"
+	"github.com/stwalsh4118/clio/internal/reminders"
+	"github.com/stwalsh4118/clio/internal/report"
+)
+
+// newRemindCmd creates the remind command with list and done subcommands
+func newRemindCmd() *cobra.Command {
+	var in string
+	var at string
+	var sessionRef string
+
+	cmd := &cobra.Command{
+		Use:   "remind <text>",
+		Short: "Set a note-to-self reminder tied to a session",
+		Long: `Set a reminder that comes due later, such as revisiting a flaky test. The
+reminder is tied to the active session, or the one --session names, so the
+context it was written in can be found again.
+
+Due reminders are listed by 'clio status', and the daemon announces each one
+once through the reminder.due webhook event and the on_reminder_due hook.
+
+--in takes a duration such as 3d, 2w, or 90m; --at takes a date (2006-01-02,
+due at the start of the day), a local time (2006-01-02 15:04), or an RFC 3339
+timestamp.
+
+Examples:
+  clio remind "revisit flaky test" --in 3d
+  clio remind "check the nightly build" --at "2026-10-20 09:00" --session latest
+  clio remind list
+  clio remind done 3`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if (in == "") == (at == "") {
+				return usageErrorf("give one of --in or --at")
+			}
+			now := time.Now()
+			var due time.Time
+			var err error
+			if in != "" {
+				if due, err = parseReminderIn(in, now); err != nil {
+					return usageErrorf("invalid --in: %v", err)
+				}
+			} else if due, err = parseReminderAt(at); err != nil {
+				return usageErrorf("invalid --at: %v", err)
+			}
+			return handleRemind(strings.Join(args, " "), due, sessionRef, now)
+		},
+	}
+	cmd.Flags().StringVar(&in, "in", "", "Due after this long, e.g. 3d, 2w, or 90m")
+	cmd.Flags().StringVar(&at, "at", "", "Due at this date or time")
+	cmd.Flags().StringVarP(&sessionRef, "session", "s", "", "Session to tie the reminder to (ID, ID prefix, \"latest\", or \"active\"; default: the active session, if any)")
+
+	var all bool
+	list := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List open reminders, soonest due first",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handleRemindList(all)
+		},
+	}
+	list.Flags().BoolVarP(&all, "all", "a", false, "Include dismissed reminders")
+	cmd.AddCommand(list)
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "done <id>",
+		Short: "Dismiss a reminder",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.ParseInt(strings.TrimPrefix(args[0], "#"), 10, 64)
+			if err != nil {
+				return usageErrorf("invalid reminder ID %q", args[0])
+			}
+			return handleRemindDone(id)
+		},
+	})
+
+	return cmd
+}
+
+// handleRemind implements the remind command
+func handleRemind(text string, due time.Time, sessionRef string, now time.Time) error {
+	database, store, err := openReminderStore()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	reporter, err := report.NewReporter(database, logging.NewNoopLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create reporter: %w", err)
+	}
+	var sessionID string
+	if sessionRef == "" {
+		// Reminders set outside a session aren't tied to one
+		sessionID, _ = reporter.ResolveSession(report.ActiveSession)
+	} else if sessionID, err = reporter.ResolveSession(sessionRef); err != nil {
+		return usageErrorf("%v", err)
+	}
+
+	reminder, err := store.Add(reminders.Reminder{Text: text, DueAt: due, SessionID: sessionID, CreatedAt: now})
+	if err != nil {
+		return usageErrorf("%v", err)
+	}
+
+	fmt.Printf("Reminder #%d due %s", reminder.ID, formatTime(reminder.DueAt))
+	if reminder.SessionID != "" {
+		fmt.Printf(", tied to session %s", reminder.SessionID)
+	}
+	fmt.Println()
+	return nil
+}
+
+// handleRemindList implements remind list
+func handleRemindList(all bool) error {
+	database, store, err := openReminderStore()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	list, err := store.List(all)
+	if err != nil {
+		return fmt.Errorf("failed to list reminders: %w", err)
+	}
+	if len(list) == 0 {
+		fmt.Println("No reminders. Set one with 'clio remind'.")
+		return nil
+	}
+
+	now := time.Now()
+	for _, reminder := range list {
+		printReminder(reminder, now, "")
+	}
+	return nil
+}
+
+// handleRemindDone implements remind done
+func handleRemindDone(id int64) error {
+	database, store, err := openReminderStore()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	if err := store.Done(id, time.Now()); err != nil {
+		return usageErrorf("%v", err)
+	}
+	fmt.Printf("Dismissed reminder #%d\n", id)
+	return nil
+}
+
+// openReminderStore opens the database and a reminder store on it
+func openReminderStore() (*sql.DB, reminders.Store, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	database, err := openDatabase(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	store, err := reminders.NewStore(database, logging.NewNoopLogger())
+	if err != nil {
+		database.Close()
+		return nil, nil, fmt.Errorf("failed to create reminder store: %w", err)
+	}
+	return database, store, nil
+}
+
+// printReminder prints a reminder with when it's due and the session it came from
+func printReminder(reminder reminders.Reminder, now time.Time, indent string) {
+	state := "in " + formatReminderWait(reminder.DueAt.Sub(now))
+	switch {
+	case reminder.DoneAt != nil:
+		state = "done"
+	case reminder.IsDue(now):
+		state = "DUE"
+	}
+	fmt.Printf("%s#%-3d %-8s %s  %s\n", indent, reminder.ID, state, formatTime(reminder.DueAt), reminder.Text)
+	if reminder.SessionID != "" {
+		fmt.Printf("%s      from session %s (%s, started %s); see clio replay %s\n", indent, reminder.SessionID,
+			reminder.Project, formatTime(reminder.SessionStart), reminder.SessionID)
+	}
+}
+
+// formatReminderWait formats the time until a reminder is due, e.g. 3d, 5h, or 20m
+func formatReminderWait(d time.Duration) string {
+	d = d.Round(time.Minute)
+	switch {
+	case d >= 48*time.Hour:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dm", max(int(d.Minutes()), 1))
+	}
+}
+
+// parseReminderIn parses --in: a positive duration with d (days) and w (weeks)
+// units added to time.ParseDuration's
+func parseReminderIn(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for suffix, days := range map[string]int{"d": 1, "w": 7} {
+		if n, err := strconv.Atoi(strings.TrimSuffix(value, suffix)); strings.HasSuffix(value, suffix) && err == nil && n > 0 {
+			return now.AddDate(0, 0, n*days), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return now.Add(d), nil
+	}
+	return time.Time{}, fmt.Errorf("%q is not a positive duration (e.g. 3d, 2w, 90m)", value)
+}
+
+// parseReminderAt parses --at: a date, a local date and time, or an RFC 3339 timestamp
+func parseReminderAt(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{reportDateLayout, reportTimeLayout} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%q is not a date (%s), time (%s), or RFC 3339 timestamp", value, reportDateLayout, reportTimeLayout)
+}
@@ -0,0 +1,21 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/remote"
+)
+
+// uploadToRemote uploads body to an s3:// or gs:// URL with the configured credentials
+func uploadToRemote(cfg *config.Config, dest string, body []byte) error {
+	location, err := remote.Parse(dest)
+	if err != nil {
+		return usageErrorf("%v", err)
+	}
+	uploader, err := remote.NewUploader(cfg.RemoteStorage, location.Scheme)
+	if err != nil {
+		return newError(CategoryConfig, err)
+	}
+	return uploader.Upload(context.Background(), location, body)
+}
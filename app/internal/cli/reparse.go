@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/stwalsh4118/clio/internal/cursor"
+)
+
+// newReparseCmd creates the reparse command
+func newReparseCmd() *cobra.Command {
+	var all bool
+	var source string
+	var force bool
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "reparse [composer-id...]",
+		Short: "Re-extract stored conversations with the latest parser",
+		Long: `Re-run message extraction for captured conversations and upgrade the
+stored messages in place, so parser improvements reach historical data.
+
+Archived raw payloads are used when available (see cursor.archive_raw_payloads),
+otherwise the live Cursor database is read. Only messages extracted by an older
+parser version are rewritten unless --force is given.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if all && len(args) > 0 {
+				return usageErrorf("cannot combine --all with composer IDs")
+			}
+			if !all && len(args) == 0 {
+				return cmd.Help()
+			}
+			return handleReparse(args, all, source, force, dryRun)
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "Reparse every captured conversation")
+	cmd.Flags().StringVar(&source, "source", cursor.ReparseSourceAuto, "Payload source: auto, archive, or cursor")
+	cmd.Flags().BoolVar(&force, "force", false, "Rewrite messages even if already extracted by the current parser")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be upgraded without writing")
+
+	return cmd
+}
+
+// handleReparse implements the reparse command logic
+func handleReparse(composerIDs []string, all bool, source string, force bool, dryRun bool) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	database, err := openDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	reparser, err := cursor.NewReparser(cfg, database)
+	if err != nil {
+		return fmt.Errorf("failed to create reparser: %w", err)
+	}
+	defer reparser.Close()
+
+	if all {
+		composerIDs, err = reparser.GetStoredComposerIDs()
+		if err != nil {
+			return fmt.Errorf("failed to list conversations: %w", err)
+		}
+	}
+
+	opts := cursor.ReparseOptions{
+		Source: source,
+		Force:  force,
+		DryRun: dryRun,
+	}
+
+	var upgraded, failed int
+	for _, composerID := range composerIDs {
+		result, err := reparser.Reparse(composerID, opts)
+		if err != nil {
+			fmt.Printf("Failed %s: %v\n", composerID, err)
+			failed++
+			continue
+		}
+
+		if result.MessagesUpgraded == 0 {
+			fmt.Printf("Up to date %s (%s)\n", composerID, result.Source)
+			continue
+		}
+
+		verb := "Upgraded"
+		if dryRun {
+			verb = "Would upgrade"
+		}
+		fmt.Printf("%s %s (%s): %d of %d message(s)\n", verb, composerID, result.Source, result.MessagesUpgraded, result.MessagesParsed)
+		upgraded += result.MessagesUpgraded
+	}
+
+	summaryVerb := "upgraded"
+	if dryRun {
+		summaryVerb = "would be upgraded"
+	}
+	fmt.Printf("\nReparsed %d conversation(s): %d message(s) %s, %d failed (parser version %d)\n", len(composerIDs)-failed, upgraded, summaryVerb, failed, cursor.ParserVersion)
+	if failed > 0 && failed < len(composerIDs) {
+		return partialFailureErrorf("%d of %d conversation(s) failed to reparse", failed, len(composerIDs))
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d conversation(s) failed to reparse", failed)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"github.com/stwalsh4118/clio/internal/replay"
+	"github.com/stwalsh4118/clio/internal/report"
+)
+
+// newReplayCmd creates the replay command
+func newReplayCmd() *cobra.Command {
+	var opts replay.Options
+
+	cmd := &cobra.Command{
+		Use:   "replay <session>",
+		Short: "Play a session back in the terminal",
+		Long: `Play a session back chronologically: messages with the tools the agent ran,
+journal notes, commits, test runs, and attachments. Useful for reviewing how a
+solution evolved or recording a screencast.
+
+The wait between frames follows the real gaps scaled by --speed, capped at
+--max-pause so idle stretches don't stall playback. While playing, press Enter
+to pause or resume, type + or - then Enter to double or halve the speed, and q
+then Enter to stop.
+
+The session is a full session ID, a unique ID prefix, "latest", or "active".
+
+Examples:
+  clio replay latest
+  clio replay 3f2a9c --speed 10 --max-pause 1s
+  clio replay latest --instant | less`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.Speed <= 0 {
+				return usageErrorf("--speed must be positive")
+			}
+			if opts.MaxPause <= 0 {
+				return usageErrorf("--max-pause must be positive")
+			}
+			return handleReplay(args[0], opts)
+		},
+	}
+
+	cmd.Flags().Float64Var(&opts.Speed, "speed", replay.DefaultSpeed, "Playback speed as a multiple of real time")
+	cmd.Flags().DurationVar(&opts.MaxPause, "max-pause", replay.DefaultMaxPause, "Longest wait between frames")
+	cmd.Flags().BoolVar(&opts.Instant, "instant", false, "Print the whole session without waiting")
+
+	return cmd
+}
+
+// handleReplay implements the replay command
+func handleReplay(sessionRef string, opts replay.Options) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	database, err := openReadOnlyDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	reporter, err := newBlobReporter(cfg, database)
+	if err != nil {
+		return err
+	}
+	sessionID, err := reporter.ResolveSession(sessionRef)
+	if err != nil {
+		return usageErrorf("%v", err)
+	}
+	data, err := reporter.ExportData(report.ExportOptions{SessionID: sessionID})
+	if err != nil {
+		return fmt.Errorf("failed to load session: %w", err)
+	}
+	if len(data.Sessions) == 0 {
+		return usageErrorf("session %s not found", sessionID)
+	}
+	session := data.Sessions[0]
+
+	player, err := replay.NewPlayer(opts)
+	if err != nil {
+		return usageErrorf("%v", err)
+	}
+
+	frames := replay.Frames(session)
+	if len(frames) == 0 {
+		fmt.Printf("Session %s has no activity to replay\n", sessionID)
+		return nil
+	}
+
+	end := "active"
+	if session.EndTime != nil {
+		end = formatTime(*session.EndTime)
+	}
+	fmt.Printf("Replaying %s: %s - %s (%d frames)\n\n", session.Project, formatTime(session.StartTime), end, len(frames))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var controls <-chan replay.Control
+	if !opts.Instant && isTerminal(os.Stdin) {
+		controls = readReplayControls(ctx)
+	}
+	if err := player.Play(ctx, frames, os.Stdout, controls); err != nil && err != context.Canceled {
+		return err
+	}
+	return nil
+}
+
+// readReplayControls turns lines typed on stdin into playback controls. The reader
+// goroutine blocks on stdin and exits with the process.
+func readReplayControls(ctx context.Context) <-chan replay.Control {
+	controls := make(chan replay.Control)
+	go func() {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			var control replay.Control
+			switch strings.TrimSpace(scanner.Text()) {
+			case "":
+				control = replay.TogglePause
+			case "+":
+				control = replay.Faster
+			case "-":
+				control = replay.Slower
+			case "q", "Q":
+				control = replay.Quit
+			default:
+				continue
+			}
+			select {
+			case controls <- control:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return controls
+}
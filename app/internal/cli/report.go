@@ -0,0 +1,537 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stwalsh4118/clio/internal/commitmsg"
+	"github.com/stwalsh4118/clio/internal/filters"
+	"github.com/stwalsh4118/clio/internal/git"
+	"github.com/stwalsh4118/clio/internal/logging"
+	"github.com/stwalsh4118/clio/internal/recap"
+	"github.com/stwalsh4118/clio/internal/report"
+)
+
+const (
+	// reportDateLayout is the date format accepted by time range flags
+	reportDateLayout = "2006-01-02"
+	// reportTimeLayout is the timestamp format used in report output
+	reportTimeLayout = "2006-01-02 15:04"
+	// maxCommitSubjectLength truncates commit subjects in report output
+	maxCommitSubjectLength = 60
+	// defaultFileReportLimit is how many files --files lists by default
+	defaultFileReportLimit = 20
+	// maxCompareCommitsShown is how many of each branch's latest commits --compare lists
+	maxCompareCommitsShown = 10
+)
+
+// newReportCmd creates the report command
+func newReportCmd() *cobra.Command {
+	var orphans bool
+	var files bool
+	var commitStyle bool
+	var reverts bool
+	var convention string
+	var pattern string
+	var compare []string
+	var repository string
+	var limit int
+	var project string
+	var since string
+	var until string
+	var filter string
+
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Report on captured development activity",
+		Long: `Generate reports over captured sessions and commits.
+
+--orphans lists commits with no correlated session and sessions with no
+commits, which usually point at capture gaps or repositories that aren't in
+watched_directories.
+
+--files lists the time spent on each file, derived from editor heartbeats
+(see heartbeats in the configuration). Gaps between heartbeats longer than
+heartbeats.timeout_minutes aren't counted.
+
+--commit-style checks commit messages against the convention in the
+commit_style configuration block, Conventional Commits by default, and
+summarizes how many follow it per project and week, with the most common
+problems and the latest commits that don't. --convention and --pattern
+override the configured convention, e.g. --convention regex --pattern
+'^[A-Z]+-[0-9]+: ' for subjects starting with an issue key. Merge commits
+aren't checked.
+
+--reverts lists revert commits and fixup!, squash!, and amend! commits, each
+linked to the commit it reverts or fixes up, that commit's session, and the
+conversation it most likely came out of (the session's conversation active
+last before the commit), with the share of its lines that came from AI
+suggestions. The conversations behind reverted changes are summarized at the
+end, so AI changes that were later undone can be studied.
+
+--compare compares branches of a repository, such as alternative attempts at
+the same change: each branch's commits, the sessions behind them, the time
+spent in those sessions, and what their conversations set out to do. Commits
+belong to the branch checked out when they were captured. The repository is
+--repo (a name or path), or the one containing the current directory.
+
+Time ranges accept a date (2006-01-02), an RFC 3339 timestamp, or a relative
+duration such as 7d or 12h. --filter applies a named filter (see clio filters)
+without a tag: term; flags given alongside it override its terms.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			modes := 0
+			for _, mode := range []bool{orphans, files, commitStyle, reverts, len(compare) > 0} {
+				if mode {
+					modes++
+				}
+			}
+			if modes == 0 {
+				return cmd.Help()
+			}
+			if modes > 1 {
+				return usageErrorf("--orphans, --files, --commit-style, --reverts, and --compare cannot be combined")
+			}
+			if len(compare) > 0 {
+				if len(compare) < 2 {
+					return usageErrorf("--compare needs at least two branches, e.g. --compare exp/a,exp/b")
+				}
+				return handleReportCompare(repository, compare)
+			}
+			if limit < 0 {
+				return usageErrorf("--limit cannot be negative")
+			}
+
+			if err := applyUntaggedFilter(cmd, filter, &project, &since, &until); err != nil {
+				return err
+			}
+
+			now := time.Now()
+			sinceTime, err := filters.ParseTime(since, now)
+			if err != nil {
+				return usageErrorf("invalid --since: %w", err)
+			}
+			untilTime, err := filters.ParseTime(until, now)
+			if err != nil {
+				return usageErrorf("invalid --until: %w", err)
+			}
+			if !sinceTime.IsZero() && !untilTime.IsZero() && !sinceTime.Before(untilTime) {
+				return usageErrorf("--since must be before --until")
+			}
+
+			if files {
+				return handleReportFiles(report.FileActivityOptions{Project: project, Since: sinceTime, Until: untilTime}, limit)
+			}
+			if reverts {
+				return handleReportReverts(report.FollowUpOptions{Project: project, Since: sinceTime, Until: untilTime})
+			}
+			if commitStyle {
+				return handleReportCommitStyle(cmd, report.CommitStyleOptions{Project: project, Since: sinceTime, Until: untilTime}, convention, pattern, limit)
+			}
+			return handleReportOrphans(report.OrphanOptions{Project: project, Since: sinceTime, Until: untilTime})
+		},
+	}
+
+	cmd.Flags().BoolVar(&orphans, "orphans", false, "List commits without sessions and sessions without commits")
+	cmd.Flags().BoolVar(&files, "files", false, "List time spent per file from editor heartbeats")
+	cmd.Flags().BoolVar(&commitStyle, "commit-style", false, "Summarize how many commit messages follow the configured convention")
+	cmd.Flags().BoolVar(&reverts, "reverts", false, "List revert and fixup commits linked to the commits, sessions, and conversations they follow up on")
+	cmd.Flags().StringVar(&convention, "convention", "", "Convention for --commit-style: conventional or regex (default: commit_style.convention)")
+	cmd.Flags().StringVar(&pattern, "pattern", "", "Regular expression subjects must match for --commit-style (implies --convention regex)")
+	cmd.Flags().StringSliceVar(&compare, "compare", nil, "Compare the work behind these branches (comma-separated)")
+	cmd.Flags().StringVar(&repository, "repo", "", "Repository for --compare (name or path; default: the current directory's)")
+	cmd.Flags().IntVar(&limit, "limit", defaultFileReportLimit, "Maximum files listed by --files or commits by --commit-style (0 for all)")
+	cmd.Flags().StringVar(&project, "project", "", "Only include this project")
+	cmd.Flags().StringVar(&since, "since", "", "Only include activity at or after this time (date, timestamp, or duration like 7d)")
+	cmd.Flags().StringVar(&until, "until", "", "Only include activity before this time (date, timestamp, or duration like 7d)")
+	cmd.Flags().StringVar(&filter, "filter", "", filterFlagUsage)
+
+	return cmd
+}
+
+// handleReportOrphans implements the report --orphans command logic
+func handleReportOrphans(opts report.OrphanOptions) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	database, err := openReadOnlyDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	reporter, err := report.NewReporter(database, logging.NewNoopLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create reporter: %w", err)
+	}
+
+	orphans, err := reporter.Orphans(opts)
+	if err != nil {
+		return fmt.Errorf("failed to generate report: %w", err)
+	}
+
+	if len(orphans.Commits) == 0 && len(orphans.Sessions) == 0 {
+		fmt.Println("No uncorrelated work found.")
+		return nil
+	}
+	pinList, err := listPins(database)
+	if err != nil {
+		return err
+	}
+
+	for _, group := range orphans.ByProject() {
+		fmt.Printf("Project: %s\n", group.Project)
+
+		if len(group.Commits) > 0 {
+			fmt.Printf("  Commits without a session (%d):\n", len(group.Commits))
+			for _, commit := range group.Commits {
+				fmt.Printf("    %s  %s  %s\n", shortHash(commit.Hash), formatTime(commit.Timestamp), commitSubject(commit.Message))
+			}
+		}
+
+		if len(group.Sessions) > 0 {
+			fmt.Printf("  Sessions without commits (%d):\n", len(group.Sessions))
+			pinned := sortPinnedFirst(group.Sessions, pinList)
+			for _, session := range group.Sessions {
+				end := "active"
+				if session.EndTime != nil {
+					end = formatTime(*session.EndTime)
+				}
+				note := ""
+				if pinned[session.ID] {
+					note = ", pinned"
+				}
+				fmt.Printf("    %s  %s - %s  (%d conversation(s)%s)\n", session.ID, formatTime(session.StartTime), end, session.ConversationCount, note)
+			}
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("%d commit(s) without a session, %d session(s) without commits\n", len(orphans.Commits), len(orphans.Sessions))
+	if len(orphans.Commits) > 0 {
+		fmt.Println("Commits without a session may mean Cursor capture wasn't running or cursor.log_path is wrong.")
+	}
+	if len(orphans.Sessions) > 0 {
+		fmt.Println("Sessions without commits may be in repositories missing from watched_directories.")
+	}
+	return nil
+}
+
+// handleReportReverts implements the report --reverts command logic
+func handleReportReverts(opts report.FollowUpOptions) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	database, err := openReadOnlyDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	reporter, err := newBlobReporter(cfg, database)
+	if err != nil {
+		return err
+	}
+
+	followUps, err := reporter.FollowUps(opts)
+	if err != nil {
+		return fmt.Errorf("failed to generate report: %w", err)
+	}
+	if len(followUps) == 0 {
+		fmt.Println("No revert or fixup commits found.")
+		return nil
+	}
+
+	type conversationReverts struct {
+		composerID, name, project string
+		reverts, fixups           int
+	}
+	var conversations []*conversationReverts
+	byComposer := make(map[string]*conversationReverts)
+	counts := make(map[string]int)
+	for _, followUp := range followUps {
+		counts[followUp.Kind]++
+		fmt.Printf("%-6s  %s  %s  %-12s  %s\n", followUp.Kind, shortHash(followUp.Hash), formatTime(followUp.Timestamp), followUp.Project, commitSubject(followUp.Message))
+
+		original := followUp.Original
+		if original == nil {
+			fmt.Println("        of a commit that wasn't captured")
+			continue
+		}
+		fmt.Printf("    of  %s  %s  %s\n", shortHash(original.Hash), formatTime(original.Timestamp), commitSubject(original.Message))
+		if original.SessionID == "" {
+			fmt.Println("        made outside a captured session")
+			continue
+		}
+		source := fmt.Sprintf("session %s", original.SessionID)
+		if original.ComposerID != "" {
+			name := original.Conversation
+			if name == "" {
+				name = original.ComposerID
+			}
+			source += fmt.Sprintf(", conversation %q", name)
+		}
+		if total := original.AILines + original.HumanLines; total > 0 {
+			source += fmt.Sprintf(", %.0f%% AI (%d/%d lines)", original.AIShare()*100, original.AILines, total)
+		}
+		fmt.Printf("        %s\n", source)
+
+		if original.ComposerID == "" {
+			continue
+		}
+		conversation := byComposer[original.ComposerID]
+		if conversation == nil {
+			conversation = &conversationReverts{composerID: original.ComposerID, name: original.Conversation, project: followUp.Project}
+			byComposer[original.ComposerID] = conversation
+			conversations = append(conversations, conversation)
+		}
+		if followUp.Kind == commitmsg.FollowUpRevert {
+			conversation.reverts++
+		} else {
+			conversation.fixups++
+		}
+	}
+
+	fmt.Printf("\n%d revert(s), %d fixup(s)\n", counts[commitmsg.FollowUpRevert], counts[commitmsg.FollowUpFixup])
+	if len(conversations) > 0 {
+		fmt.Println("\nConversations behind reverted or fixed-up commits:")
+		for _, conversation := range conversations {
+			name := conversation.name
+			if name == "" {
+				name = "(unnamed)"
+			}
+			fmt.Printf("  %q (%s)  %d revert(s), %d fixup(s)\n", name, conversation.project, conversation.reverts, conversation.fixups)
+			fmt.Printf("    see clio conversations export %s\n", conversation.composerID)
+		}
+	}
+	return nil
+}
+
+// handleReportFiles implements the report --files command logic
+func handleReportFiles(opts report.FileActivityOptions, limit int) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	opts.Timeout = time.Duration(cfg.Heartbeats.TimeoutMinutes) * time.Minute
+
+	database, err := openReadOnlyDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	reporter, err := report.NewReporter(database, logging.NewNoopLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create reporter: %w", err)
+	}
+
+	files, err := reporter.FileActivity(opts)
+	if err != nil {
+		return fmt.Errorf("failed to generate report: %w", err)
+	}
+
+	if len(files) == 0 {
+		fmt.Println("No editor heartbeats found.")
+		fmt.Println("Send WakaTime-style heartbeats to the daemon API or set heartbeats.log_path.")
+		return nil
+	}
+
+	var total time.Duration
+	for _, file := range files {
+		total += file.Duration
+	}
+	shown := files
+	if limit > 0 && len(shown) > limit {
+		shown = shown[:limit]
+	}
+
+	for _, file := range shown {
+		fmt.Printf("%8s  %-12s  %s", recap.FormatDuration(file.Duration), file.Project, file.Entity)
+		if file.Writes > 0 {
+			fmt.Printf("  (%d saves)", file.Writes)
+		}
+		fmt.Println()
+	}
+	if len(shown) < len(files) {
+		fmt.Printf("... %d more file(s)\n", len(files)-len(shown))
+	}
+	fmt.Printf("\n%s across %d file(s)\n", recap.FormatDuration(total), len(files))
+	return nil
+}
+
+// handleReportCommitStyle implements the report --commit-style command logic
+func handleReportCommitStyle(cmd *cobra.Command, opts report.CommitStyleOptions, convention, pattern string, limit int) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	style := cfg.CommitStyle
+	if cmd.Flags().Changed("pattern") {
+		style.Convention, style.Pattern = commitmsg.ConventionRegex, pattern
+	}
+	if cmd.Flags().Changed("convention") {
+		style.Convention = convention
+	}
+	if opts.Convention, err = commitmsg.NewConvention(style); err != nil {
+		return usageErrorf("%v", err)
+	}
+
+	database, err := openReadOnlyDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	reporter, err := report.NewReporter(database, logging.NewNoopLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create reporter: %w", err)
+	}
+
+	result, err := reporter.CommitStyle(opts)
+	if err != nil {
+		return fmt.Errorf("failed to generate report: %w", err)
+	}
+	if result.Commits == 0 {
+		fmt.Println("No commits captured in this range")
+		return nil
+	}
+
+	fmt.Printf("Checking %s\n\n", opts.Convention.Describe())
+	for _, project := range result.Projects {
+		fmt.Printf("Project: %s\n", project.Project)
+		fmt.Println("  Week of       Commits   Compliant   Rate")
+		for _, week := range project.Weeks {
+			fmt.Printf("  %-12s  %7d   %9d   %3.0f%%\n", week.Start.Format(statsWeekLayout), week.Commits, week.Compliant, week.Rate()*100)
+		}
+		fmt.Printf("  %-12s  %7d   %9d   %3.0f%%\n", "Total", project.Commits, project.Compliant, project.Rate()*100)
+		for _, problem := range project.Problems {
+			fmt.Printf("  %4d × %s\n", problem.Commits, problem.Problem)
+		}
+		fmt.Println()
+	}
+
+	shown := result.NonCompliant
+	if limit > 0 && len(shown) > limit {
+		shown = shown[:limit]
+	}
+	if len(shown) > 0 {
+		fmt.Println("Latest commits not following the convention:")
+		for _, commit := range shown {
+			fmt.Printf("  %s  %s  %-12s  %s  (%s)\n", shortHash(commit.Hash), formatTime(commit.Timestamp), commit.Project,
+				commitSubject(commit.Message), strings.Join(commit.Problems, ", "))
+		}
+		if len(shown) < len(result.NonCompliant) {
+			fmt.Printf("  ... %d more commit(s)\n", len(result.NonCompliant)-len(shown))
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("%d of %d commit(s) follow the convention (%.0f%%)\n", result.Compliant, result.Commits, result.Rate()*100)
+	return nil
+}
+
+// handleReportCompare implements the report --compare command logic
+func handleReportCompare(repository string, branches []string) error {
+	if repository == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get working directory: %w", err)
+		}
+		_, root, err := git.OpenContaining(cwd)
+		if err != nil {
+			return usageErrorf("not in a git repository; pass --repo")
+		}
+		repository = root
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	database, err := openReadOnlyDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	reporter, err := newBlobReporter(cfg, database)
+	if err != nil {
+		return err
+	}
+
+	summaries, err := reporter.CompareBranches(report.BranchCompareOptions{Repository: repository, Branches: branches})
+	if err != nil {
+		return fmt.Errorf("failed to generate report: %w", err)
+	}
+
+	fmt.Printf("%-24s  %7s  %8s  %10s  %5s  %s\n", "Branch", "Commits", "Sessions", "Time", "Files", "Lines")
+	for _, summary := range summaries {
+		fmt.Printf("%-24s  %7d  %8d  %10s  %5d  +%d -%d\n", summary.Branch, len(summary.Commits), len(summary.Sessions),
+			recap.FormatDuration(summary.TimeSpent), summary.FilesChanged, summary.LinesAdded, summary.LinesRemoved)
+	}
+
+	for _, summary := range summaries {
+		fmt.Printf("\n%s\n", summary.Branch)
+		if len(summary.Commits) == 0 {
+			fmt.Println("  No captured commits on this branch.")
+			continue
+		}
+
+		fmt.Printf("  Commits (%d):\n", len(summary.Commits))
+		commits := summary.Commits
+		if len(commits) > maxCompareCommitsShown {
+			fmt.Printf("    ... %d earlier commit(s)\n", len(commits)-maxCompareCommitsShown)
+			commits = commits[len(commits)-maxCompareCommitsShown:]
+		}
+		for _, commit := range commits {
+			fmt.Printf("    %s  %s  %s\n", shortHash(commit.Hash), formatTime(commit.Timestamp), commitSubject(commit.Message))
+		}
+
+		if len(summary.Sessions) == 0 {
+			fmt.Println("  No correlated sessions.")
+			continue
+		}
+		fmt.Printf("  Sessions (%d, %s):\n", len(summary.Sessions), recap.FormatDuration(summary.TimeSpent))
+		for _, session := range summary.Sessions {
+			shared := ""
+			if session.Shared {
+				shared = "  (also on another compared branch)"
+			}
+			fmt.Printf("    %s  %s  %s%s\n", session.ID, formatTime(session.StartTime), recap.FormatDuration(session.Duration), shared)
+			for _, conversation := range session.Conversations {
+				fmt.Printf("      - %s (%d messages)", conversation.Name, conversation.Messages)
+				if conversation.Summary != "" {
+					fmt.Printf(": %s", conversation.Summary)
+				}
+				fmt.Println()
+			}
+		}
+	}
+	return nil
+}
+
+// shortHash abbreviates a commit hash for display
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
+
+// commitSubject returns the first line of a commit message, truncated for display
+func commitSubject(message string) string {
+	subject, _, _ := strings.Cut(message, "\n")
+	if len(subject) > maxCommitSubjectLength {
+		return subject[:maxCommitSubjectLength-3] + "..."
+	}
+	return subject
+}
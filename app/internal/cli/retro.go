@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stwalsh4118/clio/internal/logging"
+	"github.com/stwalsh4118/clio/internal/outcomes"
+	"github.com/stwalsh4118/clio/internal/report"
+	"github.com/stwalsh4118/clio/internal/retro"
+)
+
+// newRetroCmd creates the retro command
+func newRetroCmd() *cobra.Command {
+	var month string
+	var project string
+	var polish bool
+	var copyOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "retro",
+		Short: "Write a monthly retrospective from captured work",
+		Long: `Write a retrospective of a month's sessions as Markdown: what shipped (each
+project's commits), where the time went (active time per project), pain points
+(conversations that kept reporting errors or asking for retries), and notable
+sessions (the longest, the one with the most commits, and the one with the
+most conversations). Session outcomes, recorded with 'clio outcome' or
+inferred, give the month's completion rate.
+
+--month is YYYY-MM, "last" (the default, the previous month), or "current" for
+the month so far.
+--polish pipes the retrospective through retro.polish_command, for example a
+script asking an LLM to turn it into prose; if the command fails the
+unpolished retrospective is shown. --copy also puts it on the system clipboard.
+
+Examples:
+  clio retro
+  clio retro --month 2026-09 --project clio
+  clio retro --month current --polish`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			now := time.Now()
+			start, err := retro.ParseMonth(month, now)
+			if err != nil {
+				return usageErrorf("invalid --month: %v", err)
+			}
+			if start.After(now) {
+				return usageErrorf("--month %s hasn't started yet", start.Format("2006-01"))
+			}
+			return handleRetro(start, project, polish, copyOutput, now)
+		},
+	}
+
+	cmd.Flags().StringVar(&month, "month", "last", "Month to look back on: YYYY-MM, last, or current")
+	cmd.Flags().StringVar(&project, "project", "", "Only include this project")
+	cmd.Flags().BoolVar(&polish, "polish", false, "Rewrite the retrospective with retro.polish_command")
+	cmd.Flags().BoolVar(&copyOutput, "copy", false, copyFlagUsage)
+
+	return cmd
+}
+
+// handleRetro implements the retro command
+func handleRetro(month time.Time, project string, polish, copyOutput bool, now time.Time) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if polish && cfg.Retro.PolishCommand == "" {
+		return usageErrorf("--polish needs retro.polish_command in the configuration")
+	}
+
+	database, err := openReadOnlyDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	until := month.AddDate(0, 1, 0)
+	reporter, err := report.NewReporter(database, logging.NewNoopLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create reporter: %w", err)
+	}
+	data, err := reporter.ExportData(report.ExportOptions{Project: project, Since: month, Until: until})
+	if err != nil {
+		return fmt.Errorf("failed to load the month's work: %w", err)
+	}
+
+	store, err := outcomes.NewStore(database, logging.NewNoopLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create outcome store: %w", err)
+	}
+	outcomeReport, err := store.Report(outcomes.Options{Project: project, Since: month, Until: until}, now)
+	if err != nil {
+		return fmt.Errorf("failed to load session outcomes: %w", err)
+	}
+
+	text := retro.Build(data, month, outcomeReport.Total, now).Markdown()
+	if polish {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Retro.PolishTimeoutSeconds)*time.Second)
+		defer cancel()
+		polished, err := retro.Polish(ctx, cfg.Retro.PolishCommand, text)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v; showing the unpolished retrospective\n", err)
+		} else {
+			text = polished
+		}
+	}
+
+	w, flush, err := clipboardTee(os.Stdout, copyOutput)
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(w, text)
+	return flush()
+}
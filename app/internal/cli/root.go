@@ -1,11 +1,11 @@
 package cli
 
 import (
-	"github.com/spf13/cobra"
-)
+	"os"
 
-const (
-	version = "0.1.0"
+	"github.com/spf13/cobra"
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/version"
 )
 
 // NewRootCmd creates and returns the root command for clio
@@ -18,19 +18,91 @@ development insights from Cursor conversations and git activity.
 
 It monitors your development workflow and stores captured data in a
 queryable format for analysis and blog content generation.`,
-		Version: version,
+		Version: version.Version,
+		// Errors are printed by Execute so they can be formatted for scripts
+		SilenceErrors: true,
 	}
 
+	rootCmd.PersistentFlags().String("error-format", errorFormatText, "Error output format: text or json")
+	rootCmd.PersistentFlags().String("profile", "", "Configuration profile to use (overrides "+config.ProfileEnvVar+")")
+	rootCmd.PersistentFlags().String("time-format", timeFormatLocal, "Timestamp format in output: local, relative, or iso")
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		// Profiles are selected through the environment so the daemon started by 'clio start' inherits them
+		if profile, _ := cmd.Flags().GetString("profile"); profile != "" {
+			if err := config.SetActiveProfile(profile); err != nil {
+				return newError(CategoryUsage, err)
+			}
+		}
+		if format, _ := cmd.Flags().GetString("time-format"); format != "" {
+			if err := setTimeFormat(format); err != nil {
+				return newError(CategoryUsage, err)
+			}
+		}
+		return nil
+	}
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return newError(CategoryUsage, err)
+	})
+
 	// Add subcommands
 	rootCmd.AddCommand(newStartCmd())
 	rootCmd.AddCommand(newStopCmd())
 	rootCmd.AddCommand(newStatusCmd())
 	rootCmd.AddCommand(newConfigCmd())
+	rootCmd.AddCommand(newDoctorCmd())
+	rootCmd.AddCommand(newReparseCmd())
+	rootCmd.AddCommand(newReportCmd())
+	rootCmd.AddCommand(newExportCmd())
+	rootCmd.AddCommand(newConversationsCmd())
+	rootCmd.AddCommand(newIssueCmd())
+	rootCmd.AddCommand(newOpenCmd())
+	rootCmd.AddCommand(newArchiveCmd())
+	rootCmd.AddCommand(newBlobsCmd())
+	rootCmd.AddCommand(newDBCmd())
+	rootCmd.AddCommand(newImportCmd())
+	rootCmd.AddCommand(newIngestCmd())
+	rootCmd.AddCommand(newAttachCmd())
+	rootCmd.AddCommand(newJournalCmd())
+	rootCmd.AddCommand(newRemindCmd())
+	rootCmd.AddCommand(newPinCmd())
+	rootCmd.AddCommand(newOutcomeCmd())
+	rootCmd.AddCommand(newMetaCmd())
+	rootCmd.AddCommand(newShareCmd())
+	rootCmd.AddCommand(newReplayCmd())
+	rootCmd.AddCommand(newTimelineCmd())
+	rootCmd.AddCommand(newWhyCmd())
+	rootCmd.AddCommand(newBisectContextCmd())
+	rootCmd.AddCommand(newReleaseNotesCmd())
+	rootCmd.AddCommand(newFindCodeCmd())
+	rootCmd.AddCommand(newSearchCmd())
+	rootCmd.AddCommand(newSubscriptionsCmd())
+	rootCmd.AddCommand(newStatsCmd())
+	rootCmd.AddCommand(newGoalCmd())
+	rootCmd.AddCommand(newFiltersCmd())
+	rootCmd.AddCommand(newStandupCmd())
+	rootCmd.AddCommand(newRetroCmd())
+	rootCmd.AddCommand(newSummarizeCmd())
+	rootCmd.AddCommand(newBlogCmd())
+	rootCmd.AddCommand(newSecretsCmd())
 	rootCmd.AddCommand(newDaemonCmd())
 
 	return rootCmd
 }
 
+// Execute runs the root command and returns the process exit code.
+// Failures are printed to stderr; see the Exit* constants for the codes.
+func Execute() int {
+	rootCmd := NewRootCmd()
+	err := rootCmd.Execute()
+	if err == nil {
+		return ExitOK
+	}
+
+	format, _ := rootCmd.PersistentFlags().GetString("error-format")
+	writeError(os.Stderr, err, format)
+	return ExitCode(err)
+}
+
 // newStartCmd creates the start command
 func newStartCmd() *cobra.Command {
 	return &cobra.Command{
@@ -57,14 +129,45 @@ func newStopCmd() *cobra.Command {
 
 // newStatusCmd creates the status command
 func newStatusCmd() *cobra.Command {
-	return &cobra.Command{
+	var showErrors bool
+	var clearErrors bool
+	var limit int
+
+	cmd := &cobra.Command{
 		Use:   "status",
 		Short: "Check daemon status",
-		Long:  "Check if the monitoring daemon is running",
+		Long: `Check if the monitoring daemon is running.
+
+--errors lists errors the daemon's subsystems keep hitting (e.g. a repository
+that fails to poll), one line per subsystem, subject, and cause with how often
+it occurred and when it last did. The daemon logs each recurring error at most
+every 10 minutes and stores the rest here. --clear-errors forgets them.
+
+Examples:
+  clio status
+  clio status --errors
+  clio status --errors --limit 5
+  clio status --errors --time-format relative`,
+		Args: cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if limit < 0 {
+				return usageErrorf("--limit must not be negative")
+			}
+			if clearErrors {
+				return handleStatusClearErrors()
+			}
+			if showErrors {
+				return handleStatusErrors(limit)
+			}
 			return handleStatus()
 		},
 	}
+
+	cmd.Flags().BoolVar(&showErrors, "errors", false, "List recurring errors collected by the daemon")
+	cmd.Flags().IntVar(&limit, "limit", 20, "Maximum errors to list with --errors (0 for all)")
+	cmd.Flags().BoolVar(&clearErrors, "clear-errors", false, "Forget the collected errors")
+	cmd.MarkFlagsMutuallyExclusive("errors", "clear-errors")
+	return cmd
 }
 
 // newDaemonCmd creates the daemon command (hidden, used internally)
@@ -0,0 +1,272 @@
+package cli
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/filters"
+	"github.com/stwalsh4118/clio/internal/report"
+	"github.com/stwalsh4118/clio/internal/search"
+	"github.com/stwalsh4118/clio/internal/transcript"
+)
+
+// Color modes for search highlights
+const (
+	colorAuto   = "auto"
+	colorAlways = "always"
+	colorNever  = "never"
+)
+
+const (
+	// ansiHighlight starts a highlighted match: bold yellow
+	ansiHighlight = "\x1b[1;33m"
+	// ansiReset ends a highlighted match
+	ansiReset = "\x1b[0m"
+	// defaultSearchContext is the number of lines shown either side of a match
+	defaultSearchContext = 2
+)
+
+// searchOutput controls how search matches are printed and opened
+type searchOutput struct {
+	terms []string // Query terms to highlight
+	color bool
+	open  int // 1-based match to open, or zero
+}
+
+// searchSyntaxHelp documents the search query language for --help-syntax
+const searchSyntaxHelp = `Search queries combine terms with operators:
+
+  retry backoff            both words (AND is implied between terms)
+  retry AND backoff        the same
+  retry OR backoff         either word
+  NOT flaky                messages without the word
+  (retry OR backoff) NOT test
+                           parentheses group terms
+  "connection refused"     words next to each other, as a phrase
+
+Operators are uppercase; lowercase and, or, and not are searched as words.
+Case, punctuation, markdown syntax, and configured stop words are ignored.
+
+Fields narrow a term to part of a message or match its metadata:
+
+  code:"ctx.Done()"        words in code blocks
+  prose:retry              words outside code blocks
+  project:clio             the session's project, ignoring case
+  role:user                messages from the user (or role:agent)
+  session:3f2a             sessions whose ID starts with 3f2a
+  conversation:deploy      conversations whose name contains deploy
+
+Quote values with spaces: project:"my app". Words with a colon before
+anything other than a field name, like panic: or http://host, are text.
+
+Time terms bound the whole query, so they can't appear inside OR or NOT:
+
+  since:7d                 at or after a date, timestamp, or duration ago
+  until:2026-03-01         before a date, timestamp, or duration ago
+  date:2026-03-01          on that day
+  date:2026-03-01..2026-03-07
+                           from the first day through the last; either end
+                           may be left out or be a timestamp or duration
+
+Examples:
+  clio search 'code:"ctx.Done()" NOT project:scratch'
+  clio search '(timeout OR deadline) role:agent since:7d'
+  clio search '"rate limit" date:2026-03-01..2026-03-07'
+`
+
+// newSearchCmd creates the search command
+func newSearchCmd() *cobra.Command {
+	var opts report.SearchOptions
+	var since string
+	var until string
+	var filter string
+	var helpSyntax bool
+	var color string
+	var out searchOutput
+
+	cmd := &cobra.Command{
+		Use:   "search <query>",
+		Short: "Search captured messages",
+		Long: `Search the text of captured conversation messages, newest first.
+
+Messages are indexed with markdown syntax stripped and code blocks kept apart
+from the prose around them, so "retry the fetch call" matches
+"**retry** the ` + "`fetch`" + ` call". Every word of the query must match,
+ignoring case and punctuation. Queries can also use "quoted phrases", fields
+like project:clio or code:retry, date ranges, and OR and NOT; see
+--help-syntax. --code-only matches only code blocks. The index is updated
+before each search; see 'clio db reindex' for stemming and stop words.
+
+Each match shows the lines of the message around its first matching line
+(--context, 0 for a one-line snippet) with matched words highlighted when
+output is a terminal (--color). --open opens the conversation of the first
+match, or of the match numbered N with --open=N, in $VISUAL or $EDITOR at the
+matched message, as 'clio open' renders it. To be notified of new matches as
+they are captured, save the query with 'clio subscriptions add'.
+
+Time ranges accept a date (2006-01-02), an RFC 3339 timestamp, or a relative
+duration such as 7d or 12h.
+
+Examples:
+  clio search "connection refused"
+  clio search --code-only "ctx.Done()"
+  clio search retry --project clio --since 7d
+  clio search '(timeout OR deadline) role:agent NOT project:scratch'
+  clio search "rate limit" -C 5 --open=2`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if helpSyntax {
+				fmt.Print(searchSyntaxHelp)
+				return nil
+			}
+			opts.Query = strings.Join(args, " ")
+			if strings.TrimSpace(opts.Query) == "" {
+				return usageErrorf("search query cannot be empty")
+			}
+			if opts.Limit <= 0 {
+				return usageErrorf("--limit must be positive")
+			}
+			if opts.Context < 0 {
+				return usageErrorf("--context cannot be negative")
+			}
+			if out.open < 0 {
+				return usageErrorf("--open must be positive")
+			}
+			switch color {
+			case colorAuto:
+				out.color = isTerminal(os.Stdout) && os.Getenv("NO_COLOR") == ""
+			case colorAlways, colorNever:
+				out.color = color == colorAlways
+			default:
+				return usageErrorf("--color must be %s, %s, or %s", colorAuto, colorAlways, colorNever)
+			}
+			if err := applyUntaggedFilter(cmd, filter, &opts.Project, &since, &until); err != nil {
+				return err
+			}
+
+			now := time.Now()
+			var err error
+			if opts.Since, err = filters.ParseTime(since, now); err != nil {
+				return usageErrorf("invalid --since: %w", err)
+			}
+			if opts.Until, err = filters.ParseTime(until, now); err != nil {
+				return usageErrorf("invalid --until: %w", err)
+			}
+			query, err := search.ParseQuery(opts.Query, now)
+			if err != nil {
+				return usageErrorf("invalid query: %w", err)
+			}
+			out.terms = query.Terms()
+			return handleSearch(opts, out)
+		},
+	}
+
+	cmd.Flags().IntVarP(&opts.Limit, "limit", "n", 20, "Maximum number of matches")
+	cmd.Flags().BoolVar(&opts.CodeOnly, "code-only", false, "Only match code blocks")
+	cmd.Flags().StringVar(&opts.Project, "project", "", "Only include this project")
+	cmd.Flags().StringVar(&since, "since", "", "Only include messages at or after this time (date, timestamp, or duration like 7d)")
+	cmd.Flags().StringVar(&until, "until", "", "Only include messages before this time (date, timestamp, or duration like 7d)")
+	cmd.Flags().StringVar(&filter, "filter", "", filterFlagUsage)
+	cmd.Flags().IntVarP(&opts.Context, "context", "C", defaultSearchContext, "Lines shown either side of the first match; 0 shows a one-line snippet")
+	cmd.Flags().StringVar(&color, "color", colorAuto, "Highlight matched words: auto (when output is a terminal), always, or never")
+	cmd.Flags().IntVar(&out.open, "open", 0, "Open the conversation of the first match (or the Nth with --open=N) at the matched message")
+	cmd.Flags().Lookup("open").NoOptDefVal = "1"
+	cmd.Flags().BoolVar(&helpSyntax, "help-syntax", false, "Show the query syntax and exit")
+
+	return cmd
+}
+
+// handleSearch implements the search command logic
+func handleSearch(opts report.SearchOptions, out searchOutput) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	// The daemon keeps the search index up to date, so searching only reads
+	database, err := openReadOnlyDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	reporter, err := newBlobReporter(cfg, database)
+	if err != nil {
+		return err
+	}
+
+	hits, err := reporter.Search(opts)
+	if errors.Is(err, search.ErrNotBuilt) {
+		return fmt.Errorf("%w; start the daemon or run 'clio db reindex' to build it", err)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to search messages: %w", err)
+	}
+	if len(hits) == 0 {
+		fmt.Println("No captured messages match")
+		return nil
+	}
+
+	highlight := func(text string) string {
+		if !out.color {
+			return text
+		}
+		return search.Highlight(text, out.terms, ansiHighlight, ansiReset)
+	}
+	for i, hit := range hits {
+		if i > 0 {
+			fmt.Println()
+		}
+		name := hit.ConversationName
+		if name == "" {
+			name = hit.ComposerID
+		}
+		fmt.Printf("[%d] %s  %q (%s, session %s) - %s\n", i+1, formatTime(hit.CreatedAt), name, hit.Project, shortHash(hit.SessionID), hit.Role)
+		if len(hit.Context) == 0 {
+			fmt.Printf("    %s\n", highlight(hit.Snippet))
+		}
+		for _, line := range hit.Context {
+			fmt.Printf("    %s\n", highlight(line))
+		}
+	}
+
+	if out.open == 0 {
+		return nil
+	}
+	if out.open > len(hits) {
+		return usageErrorf("--open=%d is past the last of %d match(es)", out.open, len(hits))
+	}
+	return openSearchHit(cfg, database, hits[out.open-1])
+}
+
+// openSearchHit opens the conversation of a search match at the matched message
+func openSearchHit(cfg *config.Config, database *sql.DB, hit report.SearchHit) error {
+	reporter, err := newBlobReporter(cfg, database)
+	if err != nil {
+		return err
+	}
+	conversation, err := reporter.ExportConversation(hit.ComposerID)
+	if err != nil {
+		return fmt.Errorf("failed to load conversation: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := transcript.Write(&buf, *conversation); err != nil {
+		return err
+	}
+	// Transcript messages carry no IDs, so the message is found by role and time
+	line := 0
+	for i, message := range conversation.Messages {
+		if message.Role == hit.Role && message.CreatedAt.Equal(hit.CreatedAt) {
+			line = transcript.MessageLines(*conversation)[i]
+			break
+		}
+	}
+	return openRendering(conversationFileName(conversation.ComposerID), buf.Bytes(), line, false)
+}
@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/stwalsh4118/clio/internal/secrets"
+)
+
+// newSecretsCmd creates the secrets command with set, get, and rm subcommands
+func newSecretsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "secrets",
+		Short: "Manage API keys and tokens in the system keychain",
+		Long: `Store API keys, tokens, and webhook secrets in the operating system
+keychain (macOS Keychain, or the Secret Service via libsecret on Linux) instead
+of plaintext configuration.
+
+Reference a stored secret from config.yaml as "secret:<name>", for example:
+
+  webhooks:
+    - url: secret:slack-webhook-url
+      events: [session.ended]
+      secret: secret:webhook-signing-key
+
+CLIO_SECRET_<NAME> environment variables override stored secrets, with '.'
+and '-' in the name replaced by '_'.`,
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "set <name>",
+		Short: "Store a secret read from stdin",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handleSecretsSet(args[0], os.Stdin)
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "get <name>",
+		Short: "Print a stored secret",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handleSecretsGet(args[0])
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:     "rm <name>",
+		Aliases: []string{"delete"},
+		Short:   "Delete a stored secret",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handleSecretsRm(args[0])
+		},
+	})
+
+	return cmd
+}
+
+// handleSecretsSet stores the first line of input under name
+func handleSecretsSet(name string, input io.Reader) error {
+	if err := secrets.ValidateName(name); err != nil {
+		return newError(CategoryUsage, err)
+	}
+
+	store, err := secrets.NewStore()
+	if err != nil {
+		return newError(CategoryConfig, err)
+	}
+
+	if file, ok := input.(*os.File); ok && isTerminal(file) {
+		fmt.Fprintf(os.Stderr, "Enter value for %s (input is visible; pipe the value to hide it): ", name)
+	}
+	value, err := bufio.NewReader(input).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to read secret value: %w", err)
+	}
+	value = strings.TrimRight(value, "\r\n")
+	if value == "" {
+		return usageErrorf("secret value cannot be empty")
+	}
+
+	if err := store.Set(name, value); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Stored %s in %s; reference it as %s%s\n", name, store.Backend(), secrets.ReferencePrefix, name)
+	return nil
+}
+
+// handleSecretsGet prints a stored secret to stdout
+func handleSecretsGet(name string) error {
+	value, err := secrets.Resolve(secrets.ReferencePrefix + name)
+	if err != nil {
+		if errors.Is(err, secrets.ErrNotFound) {
+			return usageErrorf("no secret named %q", name)
+		}
+		return err
+	}
+	fmt.Println(value)
+	return nil
+}
+
+// handleSecretsRm deletes a stored secret
+func handleSecretsRm(name string) error {
+	if err := secrets.ValidateName(name); err != nil {
+		return newError(CategoryUsage, err)
+	}
+
+	store, err := secrets.NewStore()
+	if err != nil {
+		return newError(CategoryConfig, err)
+	}
+
+	if err := store.Delete(name); err != nil {
+		if errors.Is(err, secrets.ErrNotFound) {
+			return usageErrorf("no secret named %q", name)
+		}
+		return err
+	}
+	fmt.Printf("Deleted secret %s\n", name)
+	return nil
+}
+
+// isTerminal reports whether file is an interactive terminal rather than a pipe or file
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,188 @@
+package cli
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/logging"
+	"github.com/stwalsh4118/clio/internal/report"
+	"github.com/stwalsh4118/clio/internal/share"
+)
+
+// defaultShareExpiry is how long share links last without --expires
+const defaultShareExpiry = "24h"
+
+// newShareCmd creates the share command with list and revoke subcommands
+func newShareCmd() *cobra.Command {
+	var expires string
+
+	cmd := &cobra.Command{
+		Use:   "share <session>",
+		Short: "Mint an expiring read-only link to one session",
+		Long: `Mint a link a teammate on the LAN can open in a browser to read one session,
+without access to anything else clio captured. The link's token is its only
+credential, so send it privately; it stops working when it expires or is
+revoked with 'clio share revoke'.
+
+The daemon serves links on share.listen, e.g. ":7070", which must be set in the
+configuration (restart the daemon after setting it). Printed links use
+share.url when set, and otherwise the listen address with this machine's
+hostname. Sessions are rendered as with 'clio export --format markdown', with
+the redaction settings applied.
+
+--expires takes a duration such as 2h, 3d, or 1w (default: 24h).
+
+Examples:
+  clio share latest
+  clio share 3f2a9c --expires 3d
+  clio share list
+  clio share revoke 2`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			now := time.Now()
+			expiresAt, err := parseReminderIn(expires, now)
+			if err != nil {
+				return usageErrorf("invalid --expires: %v", err)
+			}
+			return handleShare(args[0], expiresAt, now)
+		},
+	}
+	cmd.Flags().StringVar(&expires, "expires", defaultShareExpiry, "Expire the link after this long, e.g. 2h, 3d, or 1w")
+
+	var all bool
+	list := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List active share links, newest first",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handleShareList(all)
+		},
+	}
+	list.Flags().BoolVarP(&all, "all", "a", false, "Include expired and revoked links")
+	cmd.AddCommand(list)
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "revoke <id>",
+		Short: "Stop a share link from working before it expires",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.ParseInt(strings.TrimPrefix(args[0], "#"), 10, 64)
+			if err != nil {
+				return usageErrorf("invalid share link ID %q", args[0])
+			}
+			return handleShareRevoke(id)
+		},
+	})
+
+	return cmd
+}
+
+// handleShare implements the share command
+func handleShare(sessionRef string, expiresAt, now time.Time) error {
+	cfg, database, store, err := openShareStore()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	// Checked before minting so no link is made that nothing serves
+	if _, err := share.URL(cfg.Share, ""); err != nil {
+		return newError(CategoryConfig, err)
+	}
+
+	reporter, err := report.NewReporter(database, logging.NewNoopLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create reporter: %w", err)
+	}
+	sessionID, err := reporter.ResolveSession(sessionRef)
+	if err != nil {
+		return usageErrorf("%v", err)
+	}
+
+	link, err := store.Create(sessionID, expiresAt, now)
+	if err != nil {
+		return usageErrorf("%v", err)
+	}
+	url, err := share.URL(cfg.Share, link.Token)
+	if err != nil {
+		return newError(CategoryConfig, err)
+	}
+
+	fmt.Printf("Share link #%d to session %s (%s), expires %s:\n", link.ID, link.SessionID, link.Project, formatTime(link.ExpiresAt))
+	fmt.Printf("  %s\n", url)
+	fmt.Println("The link isn't shown again; revoke it with 'clio share revoke' if it leaks.")
+	return nil
+}
+
+// handleShareList implements share list
+func handleShareList(all bool) error {
+	_, database, store, err := openShareStore()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	now := time.Now()
+	links, err := store.List(all, now)
+	if err != nil {
+		return fmt.Errorf("failed to list share links: %w", err)
+	}
+	if len(links) == 0 {
+		fmt.Println("No active share links. Mint one with 'clio share'.")
+		return nil
+	}
+
+	for _, link := range links {
+		state := "expires " + formatTime(link.ExpiresAt)
+		switch {
+		case link.RevokedAt != nil:
+			state = "revoked " + formatTime(*link.RevokedAt)
+		case !link.IsActive(now):
+			state = "expired " + formatTime(link.ExpiresAt)
+		}
+		fmt.Printf("#%-3d session %s (%s, started %s)  %s\n", link.ID, link.SessionID, link.Project,
+			formatTime(link.SessionStart), state)
+	}
+	return nil
+}
+
+// handleShareRevoke implements share revoke
+func handleShareRevoke(id int64) error {
+	_, database, store, err := openShareStore()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	if err := store.Revoke(id, time.Now()); err != nil {
+		return usageErrorf("%v", err)
+	}
+	fmt.Printf("Revoked share link #%d\n", id)
+	return nil
+}
+
+// openShareStore loads the configuration and opens the database and a share link store on it
+func openShareStore() (*config.Config, *sql.DB, share.Store, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	database, err := openDatabase(cfg)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	store, err := share.NewStore(database, logging.NewNoopLogger())
+	if err != nil {
+		database.Close()
+		return nil, nil, nil, fmt.Errorf("failed to create share link store: %w", err)
+	}
+	return cfg, database, store, nil
+}
@@ -0,0 +1,171 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/filters"
+	"github.com/stwalsh4118/clio/internal/goals"
+	"github.com/stwalsh4118/clio/internal/logging"
+	"github.com/stwalsh4118/clio/internal/pins"
+	"github.com/stwalsh4118/clio/internal/report"
+	"github.com/stwalsh4118/clio/internal/standup"
+)
+
+// newStandupCmd creates the standup command
+func newStandupCmd() *cobra.Command {
+	var team string
+	var project string
+	var since string
+	var phrase bool
+	var copyOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "standup",
+		Short: "Draft a standup message from recent work",
+		Long: `Draft a yesterday/today/blockers standup message from the work captured since
+the previous working day (Friday on Mondays), formatted for pasting into Slack.
+
+Yesterday lists each project's commits and conversations. Today lists
+conversations left unresolved and goals due within a week or worked on.
+Blockers are failing test runs, journal notes mentioning a blocker ("blocked",
+"stuck", "waiting on"), and conversations abandoned after an error.
+
+Teams can use their own Go text/template via standup.templates in the
+configuration, selected with --team or standup.team. --phrase pipes the draft
+through standup.phrase_command, for example a script asking an LLM to reword it.
+--copy also puts the draft on the system clipboard.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			now := time.Now()
+			sinceTime := standup.PreviousWorkday(now)
+			if since != "" {
+				parsed, err := filters.ParseTime(since, now)
+				if err != nil {
+					return usageErrorf("invalid --since: %w", err)
+				}
+				sinceTime = parsed
+			}
+			return handleStandup(strings.ToLower(team), project, sinceTime, phrase, copyOutput, now)
+		},
+	}
+
+	cmd.Flags().StringVar(&team, "team", "", "Render with this team's template from standup.templates")
+	cmd.Flags().StringVar(&project, "project", "", "Only include this project")
+	cmd.Flags().StringVar(&since, "since", "", "Cover work since this time (default: the previous working day)")
+	cmd.Flags().BoolVar(&phrase, "phrase", false, "Reword the draft with standup.phrase_command")
+	cmd.Flags().BoolVar(&copyOutput, "copy", false, copyFlagUsage)
+
+	return cmd
+}
+
+// handleStandup implements the standup command
+func handleStandup(team, project string, since time.Time, phrase, copyOutput bool, now time.Time) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	templateText, err := standupTemplate(cfg.Standup, team)
+	if err != nil {
+		return err
+	}
+	if phrase && cfg.Standup.PhraseCommand == "" {
+		return usageErrorf("--phrase needs standup.phrase_command in the configuration")
+	}
+
+	database, err := openReadOnlyDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	reporter, err := report.NewReporter(database, logging.NewNoopLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create reporter: %w", err)
+	}
+	data, err := reporter.ExportData(report.ExportOptions{Project: project, Since: since})
+	if err != nil {
+		return fmt.Errorf("failed to load recent work: %w", err)
+	}
+
+	tracker, err := goals.NewTracker(database, logging.NewNoopLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create goal tracker: %w", err)
+	}
+	openGoals, err := tracker.List(false)
+	if err != nil {
+		return fmt.Errorf("failed to list goals: %w", err)
+	}
+	var progress []goals.Progress
+	for _, goal := range openGoals {
+		if project != "" && goal.Project != "" && !strings.EqualFold(project, goal.Project) {
+			continue
+		}
+		p, err := tracker.Progress(goal, now)
+		if err != nil {
+			return fmt.Errorf("failed to compute goal progress: %w", err)
+		}
+		progress = append(progress, *p)
+	}
+
+	var pinned []pins.Pin
+	pinList, err := listPins(database)
+	if err != nil {
+		return err
+	}
+	for _, pin := range pinList {
+		if project == "" || strings.EqualFold(project, pin.Project) {
+			pinned = append(pinned, pin)
+		}
+	}
+
+	text, err := standup.Render(standup.Build(data, progress, pinned, since, now), templateText)
+	if err != nil {
+		return usageErrorf("%v", err)
+	}
+
+	if phrase {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Standup.PhraseTimeoutSeconds)*time.Second)
+		defer cancel()
+		phrased, err := standup.Phrase(ctx, cfg.Standup.PhraseCommand, text)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v; showing the unphrased draft\n", err)
+		} else {
+			text = phrased
+		}
+	}
+
+	w, flush, err := clipboardTee(os.Stdout, copyOutput)
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(w, text)
+	return flush()
+}
+
+// standupTemplate returns the template for team, falling back to standup.team and
+// then the built-in template
+func standupTemplate(cfg config.StandupConfig, team string) (string, error) {
+	if team == "" {
+		team = cfg.Team
+	}
+	if team == "" {
+		return "", nil
+	}
+
+	path, ok := cfg.Templates[team]
+	if !ok {
+		return "", usageErrorf("no standup template for team %q; add one under standup.templates", team)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read standup template: %w", err)
+	}
+	return string(data), nil
+}
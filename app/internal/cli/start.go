@@ -16,9 +16,8 @@ import (
 func handleStart() error {
 	// Load and validate configuration before starting daemon
 	// Load() validates configuration automatically, so if it succeeds, config is valid
-	_, err := config.Load()
-	if err != nil {
-		return fmt.Errorf("failed to load configuration: %w", err)
+	if _, err := loadConfig(); err != nil {
+		return err
 	}
 
 	// Check if daemon is already running
@@ -69,6 +68,9 @@ func handleStart() error {
 		"USER=" + os.Getenv("USER"),
 		"CLIO_DAEMON=true",
 	}
+	if profile := config.ActiveProfile(); profile != "" {
+		env = append(env, config.ProfileEnvVar+"="+profile)
+	}
 
 	// In dev mode, enable console logging
 	if isDevMode {
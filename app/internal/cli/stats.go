@@ -0,0 +1,506 @@
+package cli
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/filters"
+	"github.com/stwalsh4118/clio/internal/logging"
+	"github.com/stwalsh4118/clio/internal/outcomes"
+	"github.com/stwalsh4118/clio/internal/quality"
+	"github.com/stwalsh4118/clio/internal/report"
+)
+
+const (
+	// statsWeekLayout is the date format for week rows in stats output
+	statsWeekLayout = "2006-01-02"
+)
+
+// newStatsCmd creates the stats command
+func newStatsCmd() *cobra.Command {
+	var attribution bool
+	var qualityStats bool
+	var team bool
+	var hotspots bool
+	var rework bool
+	var outcomeStats bool
+	var commits bool
+	var window int
+	var limit int
+	var exclude []string
+	var project string
+	var since string
+	var until string
+	var filter string
+	var alsoDBs []string
+
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show statistics derived from captured activity",
+		Long: `Show statistics derived from captured sessions, conversations, and commits.
+
+--attribution estimates what share of each week's added lines originated from
+AI suggestions rather than manual edits. A line counts as AI-originated when it
+appeared (ignoring whitespace) in a code block the agent suggested earlier in
+the commit's session. Lines with fewer than three letters or digits, such as
+closing braces, aren't attributed either way. Use --commits to list each commit.
+
+--quality shows how conversations went, week by week and per project: how many
+resolved or were abandoned, the user turns it took to resolve them, retries
+(requests to redo or fix the last attempt), and user turns reporting errors.
+Metrics are derived from messages and stored, and refreshed on every run.
+
+--team aggregates captured commits per team member: commits, how many were
+made during a captured session, and the AI share of their added lines. Commit
+authors are mapped to members by email or name through team.members in the
+configuration; authors no member lists are shown under their own name. Only
+aggregates are shown per member, never their messages or transcripts.
+
+--hotspots lists the files changed in the most commits, with their churn (lines
+added and removed) and how many conversations in the repository's sessions
+mention them by name. Files that change often and keep coming up in
+conversations are refactor candidates. --exclude drops files matching a glob,
+tried against the path and the base name, such as go.sum; it can be given
+several times.
+
+--rework shows churn and rework per project and for the sessions with the most
+rework. An added line counts as reworked when a later commit changes or removes
+it within --window days (default 14); the rework is counted against the commit
+and session that added the line, and split between AI-originated and manual
+lines as --attribution estimates them, so sessions whose code needed heavy
+follow-up fixes stand out.
+
+--outcomes shows how sessions ended, week by week and per project: shipped,
+abandoned, blocked, or not known yet, and the completion rate (the share of
+sessions with an outcome that shipped). Outcomes are recorded with clio outcome
+or inferred from each session's commits and their reverts.
+
+--also-db includes another clio database, such as a backup or the database of
+a previous machine, read-only, so a report spans a machine migration without
+merging the data. Rows already in an earlier database are counted once. It can
+be given several times.
+
+Time ranges accept a date (2006-01-02), an RFC 3339 timestamp, or a relative
+duration such as 7d or 12h. --filter applies a named filter (see clio filters)
+without a tag: term; flags given alongside it override its terms.
+
+Examples:
+  clio stats --attribution --since 30d
+  clio stats --quality --also-db ~/old-laptop/clio.db
+  clio stats --hotspots --project clio --since 90d --exclude go.sum
+  clio stats --rework --since 30d --window 7
+  clio stats --outcomes --since 56d`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			modes := 0
+			for _, set := range []bool{attribution, qualityStats, team, hotspots, rework, outcomeStats} {
+				if set {
+					modes++
+				}
+			}
+			if modes == 0 {
+				return cmd.Help()
+			}
+			if modes > 1 {
+				return usageErrorf("only one of --attribution, --quality, --team, --hotspots, --rework, and --outcomes can be given")
+			}
+
+			if err := applyUntaggedFilter(cmd, filter, &project, &since, &until); err != nil {
+				return err
+			}
+
+			now := time.Now()
+			sinceTime, err := filters.ParseTime(since, now)
+			if err != nil {
+				return usageErrorf("invalid --since: %w", err)
+			}
+			untilTime, err := filters.ParseTime(until, now)
+			if err != nil {
+				return usageErrorf("invalid --until: %w", err)
+			}
+			if !sinceTime.IsZero() && !untilTime.IsZero() && !sinceTime.Before(untilTime) {
+				return usageErrorf("--since must be before --until")
+			}
+
+			if limit < 1 {
+				return usageErrorf("--limit must be at least 1")
+			}
+			if window < 1 {
+				return usageErrorf("--window must be at least 1 day")
+			}
+
+			for _, pattern := range exclude {
+				if _, err := path.Match(pattern, ""); err != nil {
+					return usageErrorf("invalid --exclude %q: %v", pattern, err)
+				}
+			}
+
+			if hotspots {
+				return handleStatsHotspots(report.HotspotOptions{Project: project, Since: sinceTime, Until: untilTime, Limit: limit, Exclude: exclude}, alsoDBs)
+			}
+			if outcomeStats {
+				return handleStatsOutcomes(outcomes.Options{Project: project, Since: sinceTime, Until: untilTime}, alsoDBs)
+			}
+			if rework {
+				opts := report.ReworkOptions{Project: project, Since: sinceTime, Until: untilTime,
+					Window: time.Duration(window) * 24 * time.Hour, Sessions: limit}
+				return handleStatsRework(opts, alsoDBs)
+			}
+			if team {
+				return handleStatsTeam(report.TeamOptions{Project: project, Since: sinceTime, Until: untilTime}, alsoDBs)
+			}
+			if qualityStats {
+				return handleStatsQuality(quality.ReportOptions{Project: project, Since: sinceTime, Until: untilTime}, alsoDBs)
+			}
+			return handleStatsAttribution(report.AttributionOptions{Project: project, Since: sinceTime, Until: untilTime}, commits, alsoDBs)
+		},
+	}
+
+	cmd.Flags().BoolVar(&attribution, "attribution", false, "Estimate the share of added lines that came from AI suggestions")
+	cmd.Flags().BoolVar(&qualityStats, "quality", false, "Show conversation quality metrics and their trend over time")
+	cmd.Flags().BoolVar(&team, "team", false, "Show per-member commit aggregates, mapping authors to members with team.members")
+	cmd.Flags().BoolVar(&hotspots, "hotspots", false, "Show the most frequently changed files and how often conversations mention them")
+	cmd.Flags().BoolVar(&rework, "rework", false, "Show churn and how many added lines were changed again within --window days")
+	cmd.Flags().BoolVar(&outcomeStats, "outcomes", false, "Show session outcomes and completion rates by week and by project")
+	cmd.Flags().BoolVar(&commits, "commits", false, "List each commit's attribution with --attribution")
+	cmd.Flags().IntVar(&limit, "limit", report.DefaultHotspots, "Files listed with --hotspots, or sessions with --rework")
+	cmd.Flags().IntVar(&window, "window", report.DefaultReworkDays, "Days within which a changed line counts as rework with --rework")
+	cmd.Flags().StringArrayVar(&exclude, "exclude", nil, "Skip files matching this glob with --hotspots; repeatable")
+	cmd.Flags().StringVar(&project, "project", "", "Only include this project")
+	cmd.Flags().StringVar(&since, "since", "", "Only include activity at or after this time (date, timestamp, or duration like 7d)")
+	cmd.Flags().StringVar(&until, "until", "", "Only include activity before this time (date, timestamp, or duration like 7d)")
+	cmd.Flags().StringVar(&filter, "filter", "", filterFlagUsage)
+	cmd.Flags().StringArrayVar(&alsoDBs, "also-db", nil, "Also include this clio database, read-only; repeatable")
+
+	return cmd
+}
+
+// openStatsDatabase opens the database read-only with the --also-db databases attached
+func openStatsDatabase(cfg *config.Config, alsoDBs []string) (*sql.DB, error) {
+	database, err := openReadOnlyDatabase(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if len(alsoDBs) == 0 {
+		return database, nil
+	}
+
+	if err := db.AttachReadOnly(database, alsoDBs); err != nil {
+		database.Close()
+		return nil, usageErrorf("invalid --also-db: %v", err)
+	}
+	fmt.Fprintf(os.Stderr, "Including %s\n", strings.Join(alsoDBs, ", "))
+	return database, nil
+}
+
+// handleStatsAttribution implements the stats --attribution command
+func handleStatsAttribution(opts report.AttributionOptions, listCommits bool, alsoDBs []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	database, err := openStatsDatabase(cfg, alsoDBs)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	reporter, err := newBlobReporter(cfg, database)
+	if err != nil {
+		return err
+	}
+
+	result, err := reporter.Attribution(opts)
+	if err != nil {
+		return fmt.Errorf("failed to generate attribution stats: %w", err)
+	}
+	if len(result.Commits) == 0 {
+		fmt.Println("No commits captured in this range")
+		return nil
+	}
+
+	fmt.Println("Week of       Commits   AI lines   Manual lines   AI share")
+	var ai, manual int
+	for _, week := range result.Weeks {
+		fmt.Printf("%-12s  %7d   %8d   %12d   %7.0f%%\n", week.Start.Format(statsWeekLayout), week.Commits, week.AILines, week.HumanLines, week.AIShare()*100)
+		ai += week.AILines
+		manual += week.HumanLines
+	}
+	total := report.WeekAttribution{AILines: ai, HumanLines: manual}
+	fmt.Printf("%-12s  %7d   %8d   %12d   %7.0f%%\n", "Total", len(result.Commits), ai, manual, total.AIShare()*100)
+
+	if listCommits {
+		fmt.Println()
+		for _, commit := range result.Commits {
+			note := ""
+			if commit.SessionID == "" {
+				note = "  (no session)"
+			} else if commit.Truncated {
+				note = "  (diff truncated)"
+			}
+			fmt.Printf("%s  %s  %3.0f%% AI (%d/%d)  %s%s\n", shortHash(commit.Hash), formatTime(commit.Timestamp),
+				commit.AIShare()*100, commit.AILines, commit.AILines+commit.HumanLines, commitSubject(commit.Message), note)
+		}
+	}
+	return nil
+}
+
+// handleStatsTeam implements the stats --team command
+func handleStatsTeam(opts report.TeamOptions, alsoDBs []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	opts.Members = cfg.Team.Members
+
+	database, err := openStatsDatabase(cfg, alsoDBs)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	reporter, err := newBlobReporter(cfg, database)
+	if err != nil {
+		return err
+	}
+
+	members, err := reporter.Team(opts)
+	if err != nil {
+		return fmt.Errorf("failed to generate team stats: %w", err)
+	}
+	if len(members) == 0 {
+		fmt.Println("No commits captured in this range")
+		return nil
+	}
+
+	fmt.Println("Member                 Commits   In sessions   Projects   AI share   Last commit")
+	for _, member := range members {
+		fmt.Printf("%-20s  %8d   %11d   %8d   %7.0f%%   %s\n", member.Member, member.Commits, member.SessionCommits,
+			member.Projects, member.AIShare()*100, formatTime(member.LastCommit))
+	}
+
+	var unmapped []string
+	for _, member := range members {
+		if _, ok := cfg.Team.Members[member.Member]; !ok {
+			unmapped = append(unmapped, strings.Join(member.Identities, ", "))
+		}
+	}
+	if len(unmapped) > 0 && len(cfg.Team.Members) > 0 {
+		fmt.Printf("\nAuthors not in team.members: %s\n", strings.Join(unmapped, "; "))
+	}
+	return nil
+}
+
+// handleStatsHotspots implements the stats --hotspots command
+func handleStatsHotspots(opts report.HotspotOptions, alsoDBs []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	database, err := openStatsDatabase(cfg, alsoDBs)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	reporter, err := newBlobReporter(cfg, database)
+	if err != nil {
+		return err
+	}
+
+	hotspots, err := reporter.Hotspots(opts)
+	if err != nil {
+		return fmt.Errorf("failed to generate hotspot stats: %w", err)
+	}
+	if len(hotspots) == 0 {
+		fmt.Println("No changed files captured in this range")
+		return nil
+	}
+
+	fmt.Println("Project       Commits   Added   Removed   Sessions   Conversations   Mentions   Last changed       File")
+	for _, h := range hotspots {
+		fmt.Printf("%-12s  %7d   %5d   %7d   %8d   %13d   %8d   %s  %s\n", h.Project, h.Commits, h.LinesAdded, h.LinesRemoved,
+			h.Sessions, h.Conversations, h.Mentions, formatTime(h.LastChanged), h.File)
+	}
+	return nil
+}
+
+// handleStatsRework implements the stats --rework command
+func handleStatsRework(opts report.ReworkOptions, alsoDBs []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	database, err := openStatsDatabase(cfg, alsoDBs)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	reporter, err := newBlobReporter(cfg, database)
+	if err != nil {
+		return err
+	}
+
+	result, err := reporter.Rework(opts)
+	if err != nil {
+		return fmt.Errorf("failed to generate rework stats: %w", err)
+	}
+	if result.Commits == 0 {
+		fmt.Println("No commits captured in this range")
+		return nil
+	}
+
+	fmt.Printf("Lines changed again within %d days\n\n", int(result.Window.Hours()/24))
+	fmt.Println("Project       Commits     Added   Removed   Reworked   Rework   AI rework   Manual rework")
+	for _, project := range result.Projects {
+		printReworkRow(project.Project, project.ReworkCounts)
+	}
+	if len(result.Projects) > 1 {
+		printReworkRow("Total", result.ReworkCounts)
+	}
+
+	if len(result.Sessions) > 0 {
+		fmt.Println()
+		fmt.Println("Sessions with the most rework:")
+		for _, session := range result.Sessions {
+			fmt.Printf("  %s  %-12s  %s  %d commit(s), %d/%d lines reworked (%.0f%%, AI %.0f%%)\n", session.SessionID, session.Project,
+				formatTime(session.FirstCommit), session.Commits, session.Reworked, session.Added, session.ReworkRate()*100, session.AIReworkRate()*100)
+		}
+	}
+	if result.Truncated > 0 {
+		fmt.Printf("\n%d commits had truncated diffs; only their stored lines were counted\n", result.Truncated)
+	}
+	return nil
+}
+
+// printReworkRow prints one row of the rework stats project table
+func printReworkRow(label string, c report.ReworkCounts) {
+	fmt.Printf("%-12s  %7d   %7d   %7d   %8d   %5.0f%%   %8.0f%%   %12.0f%%\n", label, c.Commits, c.Added, c.Removed, c.Reworked,
+		c.ReworkRate()*100, c.AIReworkRate()*100, c.ManualReworkRate()*100)
+}
+
+// handleStatsOutcomes implements the stats --outcomes command
+func handleStatsOutcomes(opts outcomes.Options, alsoDBs []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	database, err := openStatsDatabase(cfg, alsoDBs)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	store, err := outcomes.NewStore(database, logging.NewNoopLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create outcome store: %w", err)
+	}
+	result, err := store.Report(opts, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to generate outcome stats: %w", err)
+	}
+	if result.Total.Sessions == 0 {
+		fmt.Println("No sessions captured in this range")
+		return nil
+	}
+
+	fmt.Println("Week of       Sessions   Shipped   Abandoned   Blocked   Unknown   Completion")
+	for _, week := range result.Weeks {
+		printOutcomeRow(week.Start.Format(statsWeekLayout), week.Counts)
+	}
+	printOutcomeRow("Total", result.Total)
+
+	if len(result.Projects) > 1 {
+		fmt.Println()
+		fmt.Println("Project       Sessions   Shipped   Abandoned   Blocked   Unknown   Completion")
+		for _, project := range result.Projects {
+			printOutcomeRow(project.Project, project.Counts)
+		}
+	}
+	return nil
+}
+
+// printOutcomeRow prints one row of the outcome stats tables
+func printOutcomeRow(label string, c outcomes.Counts) {
+	fmt.Printf("%-12s  %8d   %7d   %9d   %7d   %7d   %9.0f%%\n", label, c.Sessions, c.Shipped, c.Abandoned, c.Blocked, c.Unknown, c.CompletionRate()*100)
+}
+
+// handleStatsQuality implements the stats --quality command
+func handleStatsQuality(opts quality.ReportOptions, alsoDBs []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	database, err := openDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	blobStore, err := openBlobStore(cfg)
+	if err != nil {
+		return err
+	}
+	store, err := quality.NewStore(database, blobStore, logging.NewNoopLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create quality store: %w", err)
+	}
+	if _, err := store.Sync(time.Now()); err != nil {
+		return fmt.Errorf("failed to compute conversation metrics: %w", err)
+	}
+
+	// Other databases are read-only, so they report the metrics they stored themselves
+	if len(alsoDBs) > 0 {
+		combined, err := openStatsDatabase(cfg, alsoDBs)
+		if err != nil {
+			return err
+		}
+		defer combined.Close()
+		if store, err = quality.NewStore(combined, nil, logging.NewNoopLogger()); err != nil {
+			return fmt.Errorf("failed to create quality store: %w", err)
+		}
+	}
+
+	result, err := store.Report(opts)
+	if err != nil {
+		return fmt.Errorf("failed to generate quality stats: %w", err)
+	}
+	if result.Total.Conversations == 0 {
+		fmt.Println("No conversations captured in this range")
+		return nil
+	}
+
+	fmt.Println("Week of       Conversations   Resolved   Abandoned   Open   Turns to resolve   Retries/conv   Errors/conv")
+	for _, week := range result.Weeks {
+		printQualityRow(week.Start.Format(statsWeekLayout), week.Summary)
+	}
+	printQualityRow("Total", result.Total)
+
+	if len(result.Projects) > 1 {
+		fmt.Println()
+		fmt.Println("Project       Conversations   Resolved   Abandoned   Open   Turns to resolve   Retries/conv   Errors/conv")
+		for _, project := range result.Projects {
+			printQualityRow(project.Project, project.Summary)
+		}
+	}
+	return nil
+}
+
+// printQualityRow prints one row of the quality stats tables; the resolved share
+// is of conversations that have finished
+func printQualityRow(label string, s quality.Summary) {
+	fmt.Printf("%-12s  %13d   %4d %3.0f%%   %9d   %4d   %16.1f   %12.1f   %11.1f\n", label, s.Conversations, s.Resolved,
+		s.ResolutionRate()*100, s.Abandoned, s.Open, s.AvgTurnsToResolution(), s.RetriesPerConversation(), s.ErrorsPerConversation())
+}
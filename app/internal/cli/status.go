@@ -1,14 +1,91 @@
 package cli
 
 import (
+	"database/sql"
 	"fmt"
 	"os"
+	"time"
 
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/cursor"
 	"github.com/stwalsh4118/clio/internal/daemon"
+	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/errorlog"
+	"github.com/stwalsh4118/clio/internal/git"
+	"github.com/stwalsh4118/clio/internal/goals"
+	"github.com/stwalsh4118/clio/internal/logging"
+	"github.com/stwalsh4118/clio/internal/reminders"
+	"github.com/stwalsh4118/clio/internal/sensitive"
 )
 
 // handleStatus implements the status command logic
 func handleStatus() error {
+	if profile := config.ActiveProfile(); profile != "" {
+		fmt.Printf("Profile: %s\n", profile)
+	}
+	if err := printDaemonStatus(); err != nil {
+		return err
+	}
+
+	printCaptureSummaries()
+	return nil
+}
+
+// handleStatusErrors implements status --errors
+func handleStatusErrors(limit int) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	database, err := openReadOnlyDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	records, err := errorlog.List(database, limit)
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		fmt.Println("No recurring errors recorded")
+		return nil
+	}
+
+	fmt.Printf("Recurring errors (most recent first):\n")
+	for _, record := range records {
+		subject := record.Subsystem
+		if record.Subject != "" {
+			subject += " " + record.Subject
+		}
+		fmt.Printf("  [%s] %s: %s\n", formatTime(record.LastSeen), subject, record.Message)
+		fmt.Printf("      %d occurrence(s) since %s\n", record.Count, formatTime(record.FirstSeen))
+	}
+	return nil
+}
+
+// handleStatusClearErrors implements status --clear-errors
+func handleStatusClearErrors() error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	database, err := openDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	cleared, err := errorlog.Clear(database)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Cleared %d recurring error(s)\n", cleared)
+	return nil
+}
+
+// printDaemonStatus prints whether the daemon is running
+func printDaemonStatus() error {
 	// Check if daemon is running
 	running, stale, err := daemon.VerifyDaemonRunning()
 	if err != nil {
@@ -38,3 +115,146 @@ func handleStatus() error {
 	fmt.Printf("Status: running (PID: %d)\n", pid)
 	return nil
 }
+
+// printCaptureSummaries prints capture problems recorded in the database.
+// Best effort: any problem reading the database is left for 'clio doctor' to report.
+func printCaptureSummaries() {
+	cfg, err := config.Load()
+	if err != nil {
+		return
+	}
+
+	// Read-only, so a missing database isn't created or an old one migrated just to report on it
+	database, err := db.ConnectReadOnly(cfg)
+	if err != nil {
+		return
+	}
+	defer database.Close()
+
+	printQuarantineSummary(database)
+	printRepositoryHealthSummary(database)
+	printErrorSummary(database)
+	printGoalSummary(database)
+	printReminderSummary(database)
+	printSensitiveSummary(database)
+}
+
+// printErrorSummary counts recurring errors seen in the last day
+func printErrorSummary(database *sql.DB) {
+	records, err := errorlog.List(database, 0)
+	if err != nil {
+		return
+	}
+
+	cutoff := time.Now().Add(-24 * time.Hour)
+	recent := 0
+	for _, record := range records {
+		if record.LastSeen.After(cutoff) {
+			recent++
+		}
+	}
+	if recent > 0 {
+		fmt.Printf("Recurring errors in the last day: %d (run 'clio status --errors' for details)\n", recent)
+	}
+}
+
+// printQuarantineSummary prints a warning when Cursor payloads have been quarantined
+func printQuarantineSummary(database *sql.DB) {
+	counts, err := cursor.CountQuarantinedPayloads(database)
+	if err != nil {
+		return
+	}
+
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+	if total > 0 {
+		fmt.Printf("Quarantined payloads: %d (run 'clio doctor' for details)\n", total)
+	}
+}
+
+// printRepositoryHealthSummary lists watched repositories the daemon can't poll or that are offline
+func printRepositoryHealthSummary(database *sql.DB) {
+	records, err := git.ListRepositoryHealth(database)
+	if err != nil {
+		return
+	}
+
+	var unhealthy, offline []git.RepositoryHealth
+	for _, record := range records {
+		switch record.Status {
+		case git.RepositoryUnhealthy:
+			unhealthy = append(unhealthy, record)
+		case git.RepositoryOffline:
+			offline = append(offline, record)
+		}
+	}
+
+	if len(unhealthy) > 0 {
+		fmt.Printf("Unhealthy repositories: %d\n", len(unhealthy))
+		for _, record := range unhealthy {
+			fmt.Printf("  %s: %s (next retry %s)\n", record.Path, record.LastError, formatTime(record.NextRetryAt))
+		}
+	}
+	if len(offline) > 0 {
+		fmt.Printf("Offline repositories: %d (polling resumes when their drive or share returns)\n", len(offline))
+		for _, record := range offline {
+			since := ""
+			if record.UnhealthySince != nil {
+				since = " since " + formatTime(*record.UnhealthySince)
+			}
+			fmt.Printf("  %s: offline%s\n", record.Path, since)
+		}
+	}
+}
+
+// printGoalSummary lists open goals with their progress
+func printGoalSummary(database *sql.DB) {
+	tracker, err := goals.NewTracker(database, logging.NewNoopLogger())
+	if err != nil {
+		return
+	}
+	open, err := tracker.List(false)
+	if err != nil || len(open) == 0 {
+		return
+	}
+
+	fmt.Printf("Goals: %d open\n", len(open))
+	now := time.Now()
+	for _, goal := range open {
+		progress, err := tracker.Progress(goal, now)
+		if err != nil {
+			return
+		}
+		printGoalProgress(progress, "  ")
+	}
+}
+
+// printReminderSummary lists reminders that are due
+func printReminderSummary(database *sql.DB) {
+	store, err := reminders.NewStore(database, logging.NewNoopLogger())
+	if err != nil {
+		return
+	}
+	now := time.Now()
+	due, err := store.Due(now)
+	if err != nil || len(due) == 0 {
+		return
+	}
+
+	fmt.Printf("Reminders due: %d (dismiss with 'clio remind done <id>')\n", len(due))
+	for _, reminder := range due {
+		printReminder(reminder, now, "  ")
+	}
+}
+
+// printSensitiveSummary counts the messages the sensitive gate flagged, redacted, or dropped
+func printSensitiveSummary(database *sql.DB) {
+	counts, err := sensitive.CountFlags(database)
+	if err != nil || len(counts) == 0 {
+		return
+	}
+	fmt.Printf("Sensitive messages: %d flagged, %d redacted, %d dropped\n",
+		counts[sensitive.ActionStore], counts[sensitive.ActionRedact], counts[sensitive.ActionDrop])
+}
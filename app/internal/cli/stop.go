@@ -22,7 +22,7 @@ func handleStop() error {
 	}
 
 	if !exists {
-		return fmt.Errorf("daemon is not running (PID file not found)")
+		return daemonNotRunningErrorf("daemon is not running (PID file not found)")
 	}
 
 	// Read PID from file
@@ -42,7 +42,7 @@ func handleStop() error {
 		if err := daemon.RemovePIDFile(); err != nil {
 			return fmt.Errorf("daemon is not running, but failed to remove stale PID file: %w", err)
 		}
-		return fmt.Errorf("daemon is not running (stale PID file removed)")
+		return daemonNotRunningErrorf("daemon is not running (stale PID file removed)")
 	}
 
 	// Verify it's actually the clio daemon
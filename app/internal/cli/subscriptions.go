@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/logging"
+	"github.com/stwalsh4118/clio/internal/subscriptions"
+)
+
+// newSubscriptionsCmd creates the subscriptions command with list, add, and rm subcommands
+func newSubscriptionsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "subscriptions",
+		Aliases: []string{"subs"},
+		Short:   "Manage saved searches that notify on new matches",
+		Long: `Manage subscriptions: saved searches the daemon runs against newly captured
+messages every 30 seconds. Each new match is announced once through the
+search.matched webhook event and the on_search_matched hook, so the daemon only
+checks subscriptions when a webhook or hook is configured.
+
+Queries use the syntax of 'clio search' (see clio search --help-syntax). Only
+messages captured after a subscription is added can match it, and at most 20
+matches per subscription are announced per check.
+
+Examples:
+  clio subscriptions add panics 'panic: role:agent'
+  clio subscriptions add migrations --code-only "ALTER TABLE" project:clio
+  clio subscriptions
+  clio subscriptions rm panics`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handleSubscriptionsList()
+		},
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List subscriptions with their match counts",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handleSubscriptionsList()
+		},
+	})
+
+	var codeOnly bool
+	add := &cobra.Command{
+		Use:   "add <name> <query>",
+		Short: "Subscribe to new matches of a search",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handleSubscriptionsAdd(subscriptions.Subscription{
+				Name:     args[0],
+				Query:    strings.Join(args[1:], " "),
+				CodeOnly: codeOnly,
+			})
+		},
+	}
+	add.Flags().BoolVar(&codeOnly, "code-only", false, "Only match code blocks")
+	cmd.AddCommand(add)
+
+	cmd.AddCommand(&cobra.Command{
+		Use:     "rm <name>",
+		Aliases: []string{"delete"},
+		Short:   "Delete a subscription",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handleSubscriptionsRm(args[0])
+		},
+	})
+
+	return cmd
+}
+
+// handleSubscriptionsList implements subscriptions list
+func handleSubscriptionsList() error {
+	_, database, store, err := openSubscriptionStore()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	list, err := store.List()
+	if err != nil {
+		return fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+	if len(list) == 0 {
+		fmt.Println("No subscriptions. Add one with 'clio subscriptions add'.")
+		return nil
+	}
+	for _, sub := range list {
+		query := sub.Query
+		if sub.CodeOnly {
+			query += " (code only)"
+		}
+		fmt.Printf("%-16s %s\n", sub.Name, query)
+		last := "never"
+		if sub.LastMatchedAt != nil {
+			last = formatTime(*sub.LastMatchedAt)
+		}
+		fmt.Printf("%-16s %d match(es), last %s, added %s\n", "", sub.MatchCount, last, formatTime(sub.CreatedAt))
+	}
+	return nil
+}
+
+// handleSubscriptionsAdd implements subscriptions add
+func handleSubscriptionsAdd(sub subscriptions.Subscription) error {
+	cfg, database, store, err := openSubscriptionStore()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	if _, err := store.Add(sub); err != nil {
+		return usageErrorf("%v", err)
+	}
+	fmt.Printf("Subscribed %s to new matches of %s\n", sub.Name, sub.Query)
+	if len(cfg.Webhooks) == 0 && cfg.Hooks.OnSearchMatched == "" {
+		fmt.Println("Configure a webhook or the on_search_matched hook to be notified of matches.")
+	}
+	return nil
+}
+
+// handleSubscriptionsRm implements subscriptions rm
+func handleSubscriptionsRm(name string) error {
+	_, database, store, err := openSubscriptionStore()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	if err := store.Remove(name); err != nil {
+		return usageErrorf("%v", err)
+	}
+	fmt.Printf("Deleted subscription %s\n", name)
+	return nil
+}
+
+// openSubscriptionStore loads the configuration and opens the database and a subscription store on it
+func openSubscriptionStore() (*config.Config, *sql.DB, subscriptions.Store, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	database, err := openDatabase(cfg)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// Only the daemon checks subscriptions, so no blob store is needed here
+	store, err := subscriptions.NewStore(database, nil, logging.NewNoopLogger())
+	if err != nil {
+		database.Close()
+		return nil, nil, nil, fmt.Errorf("failed to create subscription store: %w", err)
+	}
+	return cfg, database, store, nil
+}
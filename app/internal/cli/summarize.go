@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/logging"
+	"github.com/stwalsh4118/clio/internal/report"
+	"github.com/stwalsh4118/clio/internal/summaries"
+)
+
+// newSummarizeCmd creates the summarize command
+func newSummarizeCmd() *cobra.Command {
+	var conversations bool
+	var refresh bool
+	var heuristic bool
+
+	cmd := &cobra.Command{
+		Use:   "summarize <session>",
+		Short: "Summarize a session and its conversations",
+		Long: `Summarize a session from its conversations and commits, and with
+--conversations each of its conversations.
+
+Summaries are cached with a hash of the messages and commits they were made
+from, so they're only generated again once those change. --refresh discards the
+cached summaries first.
+
+summaries.command in the configuration summarizes with an external command,
+for example a script asking an LLM; it reads a transcript on stdin and prints
+the summary. Without it, or with --heuristic, the built-in extractive
+summarizer picks the key sentences and lists the session's commits, without
+any network access.
+
+The session is a full session ID, a unique ID prefix, "latest", or "active".
+
+Examples:
+  clio summarize latest
+  clio summarize 3f2a9c --conversations --refresh`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handleSummarize(args[0], conversations, refresh, heuristic)
+		},
+	}
+
+	cmd.Flags().BoolVar(&conversations, "conversations", false, "Also summarize each conversation")
+	cmd.Flags().BoolVar(&refresh, "refresh", false, "Regenerate cached summaries")
+	cmd.Flags().BoolVar(&heuristic, "heuristic", false, "Use the built-in extractive summarizer even when summaries.command is set")
+
+	return cmd
+}
+
+// handleSummarize implements the summarize command
+func handleSummarize(sessionRef string, conversations, refresh, heuristic bool) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	database, err := openDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	reporter, err := report.NewReporter(database, logging.NewNoopLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create reporter: %w", err)
+	}
+	sessionID, err := reporter.ResolveSession(sessionRef)
+	if err != nil {
+		return usageErrorf("%v", err)
+	}
+
+	store, err := openBlobStore(cfg)
+	if err != nil {
+		return err
+	}
+	cache, err := summaries.NewCache(database, store, logging.NewNoopLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create summary cache: %w", err)
+	}
+	summarizer, err := newSummarizer(cfg.Summaries, heuristic)
+	if err != nil {
+		return err
+	}
+	timeout := time.Duration(cfg.Summaries.TimeoutSeconds) * time.Second
+
+	var refs []summaries.ConversationRef
+	if conversations {
+		if refs, err = summaries.Conversations(database, sessionID); err != nil {
+			return err
+		}
+	}
+	if refresh {
+		if err := cache.Forget(summaries.SubjectSession, sessionID); err != nil {
+			return err
+		}
+		for _, ref := range refs {
+			if err := cache.Forget(summaries.SubjectConversation, ref.ID); err != nil {
+				return err
+			}
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	summary, err := cache.Session(ctx, sessionID, summarizer)
+	cancel()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Session %s\n", shortHash(sessionID))
+	printSummaryText(summary.Text, "  ")
+
+	for _, ref := range refs {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		summary, err := cache.Conversation(ctx, ref.ID, summarizer)
+		cancel()
+		if err != nil {
+			return err
+		}
+		name := ref.Name
+		if name == "" {
+			name = ref.ID
+		}
+		fmt.Printf("\n%q\n", name)
+		printSummaryText(summary.Text, "  ")
+	}
+	return nil
+}
+
+// newSummarizer returns the configured summary command, or the built-in
+// extractive summarizer when there is none or heuristic is set
+func newSummarizer(cfg config.SummariesConfig, heuristic bool) (summaries.Summarizer, error) {
+	if heuristic || cfg.Command == "" {
+		return summaries.Extractive{}, nil
+	}
+	summarizer, err := summaries.NewCommandSummarizer(cfg.Command)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create summarizer: %w", err)
+	}
+	return summarizer, nil
+}
+
+// printSummaryText prints a summary indented, or a placeholder when it's empty
+func printSummaryText(text, indent string) {
+	if text == "" {
+		fmt.Printf("%s(nothing to summarize)\n", indent)
+		return
+	}
+	for _, line := range strings.Split(text, "\n") {
+		fmt.Printf("%s%s\n", indent, line)
+	}
+}
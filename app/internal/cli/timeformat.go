@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// timeFormatLocal prints timestamps in local time, e.g. 2026-03-08 14:05
+	timeFormatLocal = "local"
+	// timeFormatRelative prints timestamps relative to now, e.g. 2h ago
+	timeFormatRelative = "relative"
+	// timeFormatISO prints RFC 3339 timestamps with the local offset
+	timeFormatISO = "iso"
+
+	// relativeTimeLimit is how far from now relative timestamps fall back to the local date
+	relativeTimeLimit = 7 * 24 * time.Hour
+)
+
+// timeFormat is how command output renders timestamps, set from --time-format
+var timeFormat = timeFormatLocal
+
+// setTimeFormat selects how timestamps are rendered
+func setTimeFormat(format string) error {
+	switch format = strings.ToLower(strings.TrimSpace(format)); format {
+	case timeFormatLocal, timeFormatRelative, timeFormatISO:
+		timeFormat = format
+		return nil
+	default:
+		return fmt.Errorf("invalid --time-format %q: use local, relative, or iso", format)
+	}
+}
+
+// formatTime renders a timestamp in the selected time format
+func formatTime(t time.Time) string {
+	switch timeFormat {
+	case timeFormatRelative:
+		return relativeTime(t, time.Now())
+	case timeFormatISO:
+		return t.Local().Format(time.RFC3339)
+	default:
+		return t.Local().Format(reportTimeLayout)
+	}
+}
+
+// relativeTime renders t relative to now ("5m ago", "in 2h"), or as the local
+// date when it is a week or more away
+func relativeTime(t, now time.Time) string {
+	d := now.Sub(t)
+	future := d < 0
+	if future {
+		d = -d
+	}
+	if d >= relativeTimeLimit {
+		return t.Local().Format(reportDateLayout)
+	}
+
+	var amount string
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		amount = fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		amount = fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		amount = fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+	if future {
+		return "in " + amount
+	}
+	return amount + " ago"
+}
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRelativeTime(t *testing.T) {
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want string
+	}{
+		{name: "now", at: now, want: "just now"},
+		{name: "seconds ago", at: now.Add(-59 * time.Second), want: "just now"},
+		{name: "seconds ahead", at: now.Add(30 * time.Second), want: "just now"},
+		{name: "minutes", at: now.Add(-5 * time.Minute), want: "5m ago"},
+		{name: "minutes round down", at: now.Add(-(59*time.Minute + 59*time.Second)), want: "59m ago"},
+		{name: "hours", at: now.Add(-2 * time.Hour), want: "2h ago"},
+		{name: "hours round down", at: now.Add(-(23*time.Hour + 59*time.Minute)), want: "23h ago"},
+		{name: "days", at: now.Add(-3 * 24 * time.Hour), want: "3d ago"},
+		{name: "just under a week", at: now.Add(-(7*24*time.Hour - time.Second)), want: "6d ago"},
+		{name: "a week falls back to the date", at: now.Add(-7 * 24 * time.Hour), want: "2024-01-03"},
+		{name: "older falls back to the date", at: time.Date(2023, 12, 25, 8, 0, 0, 0, time.Local), want: "2023-12-25"},
+		{name: "future minutes", at: now.Add(10 * time.Minute), want: "in 10m"},
+		{name: "future hours", at: now.Add(5 * time.Hour), want: "in 5h"},
+		{name: "future days", at: now.Add(2 * 24 * time.Hour), want: "in 2d"},
+		{name: "far future falls back to the date", at: now.Add(8 * 24 * time.Hour), want: "2024-01-18"},
+		{name: "other zone", at: now.Add(-90 * time.Minute).In(time.FixedZone("UTC+9", 9*3600)), want: "1h ago"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := relativeTime(tt.at, now); got != tt.want {
+				t.Errorf("relativeTime(%v) = %q, want %q", tt.at, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetTimeFormat(t *testing.T) {
+	t.Cleanup(func() { timeFormat = timeFormatLocal })
+
+	tests := []struct {
+		format  string
+		want    string
+		wantErr bool
+	}{
+		{format: "local", want: timeFormatLocal},
+		{format: "relative", want: timeFormatRelative},
+		{format: "iso", want: timeFormatISO},
+		{format: " ISO ", want: timeFormatISO},
+		{format: "Relative", want: timeFormatRelative},
+		{format: "utc", wantErr: true},
+		{format: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			timeFormat = timeFormatLocal
+			err := setTimeFormat(tt.format)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "use local, relative, or iso") {
+					t.Errorf("setTimeFormat(%q) error = %v, want the valid formats listed", tt.format, err)
+				}
+				if timeFormat != timeFormatLocal {
+					t.Errorf("setTimeFormat(%q) changed the format to %q", tt.format, timeFormat)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("setTimeFormat(%q) error = %v", tt.format, err)
+			}
+			if timeFormat != tt.want {
+				t.Errorf("setTimeFormat(%q) format = %q, want %q", tt.format, timeFormat, tt.want)
+			}
+		})
+	}
+}
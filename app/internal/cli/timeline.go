@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stwalsh4118/clio/internal/report"
+	"github.com/stwalsh4118/clio/internal/timeline"
+	"github.com/stwalsh4118/clio/pkg/export"
+)
+
+// newTimelineCmd creates the timeline command
+func newTimelineCmd() *cobra.Command {
+	var day string
+	var project string
+	var width int
+
+	cmd := &cobra.Command{
+		Use:   "timeline [session]",
+		Short: "Show a day or session's activity on a time axis",
+		Long: `Plot messages, tool calls, and commits on a time axis in the terminal, with a
+density bar per project, to see how a day or work session unfolded.
+
+Without a session the timeline covers a day, today unless --day is given,
+trimmed to the hours with activity. The session is a full session ID, a unique
+ID prefix, "latest", or "active".
+
+Examples:
+  clio timeline
+  clio timeline --day 2026-10-15 --project clio
+  clio timeline latest --width 100`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if width < timeline.MinWidth {
+				return usageErrorf("--width must be at least %d", timeline.MinWidth)
+			}
+			if len(args) == 1 {
+				if day != "" || project != "" {
+					return usageErrorf("--day and --project don't apply to a session")
+				}
+				return handleTimelineSession(args[0], width)
+			}
+
+			start := time.Now()
+			if day != "" {
+				parsed, err := time.ParseInLocation(reportDateLayout, day, time.Local)
+				if err != nil {
+					return usageErrorf("invalid --day %q: use a date like %s", day, reportDateLayout)
+				}
+				start = parsed
+			}
+			start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.Local)
+			return handleTimelineDay(start, project, width)
+		},
+	}
+
+	cmd.Flags().StringVar(&day, "day", "", "Show this day (default: today)")
+	cmd.Flags().StringVar(&project, "project", "", "Only include this project")
+	cmd.Flags().IntVar(&width, "width", timeline.DefaultWidth, "Columns of the time axis")
+
+	return cmd
+}
+
+// handleTimelineDay implements the timeline command for a day starting at start
+func handleTimelineDay(start time.Time, project string, width int) error {
+	end := start.AddDate(0, 0, 1)
+	// Sessions started the day before can run past midnight
+	sessions, _, err := loadTimelineSessions("", report.ExportOptions{Project: project, Since: start.AddDate(0, 0, -1), Until: end})
+	if err != nil {
+		return err
+	}
+
+	first, last, ok := timeline.Bounds(sessions, start, end)
+	if !ok {
+		fmt.Printf("No activity captured on %s\n", start.Format(reportDateLayout))
+		return nil
+	}
+	return timeline.Render(os.Stdout, timeline.Build(sessions, first, last.Add(time.Second), width))
+}
+
+// handleTimelineSession implements the timeline command for a session
+func handleTimelineSession(sessionRef string, width int) error {
+	sessions, sessionID, err := loadTimelineSessions(sessionRef, report.ExportOptions{})
+	if err != nil {
+		return err
+	}
+	if len(sessions) == 0 {
+		return usageErrorf("session %s not found", sessionID)
+	}
+
+	// Activity can be captured after a session is marked ended
+	first, last, ok := timeline.Bounds(sessions, time.Time{}, time.Now().Add(time.Minute))
+	if !ok {
+		fmt.Printf("Session %s has no activity to show\n", sessionID)
+		return nil
+	}
+	return timeline.Render(os.Stdout, timeline.Build(sessions, first, last.Add(time.Second), width))
+}
+
+// loadTimelineSessions loads the sessions to plot: the referenced session when
+// sessionRef is set, otherwise those matching opts. It returns the resolved
+// session ID alongside.
+func loadTimelineSessions(sessionRef string, opts report.ExportOptions) ([]export.Session, string, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, "", err
+	}
+
+	database, err := openReadOnlyDatabase(cfg)
+	if err != nil {
+		return nil, "", err
+	}
+	defer database.Close()
+
+	reporter, err := newBlobReporter(cfg, database)
+	if err != nil {
+		return nil, "", err
+	}
+	if sessionRef != "" {
+		if opts.SessionID, err = reporter.ResolveSession(sessionRef); err != nil {
+			return nil, "", usageErrorf("%v", err)
+		}
+	}
+
+	data, err := reporter.ExportData(opts)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load sessions: %w", err)
+	}
+	return data.Sessions, opts.SessionID, nil
+}
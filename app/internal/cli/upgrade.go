@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/daemon"
+	"github.com/stwalsh4118/clio/internal/logging"
+	"github.com/stwalsh4118/clio/internal/upgrade"
+	"github.com/stwalsh4118/clio/internal/version"
+)
+
+// checkVersions refuses a database upgraded by a newer clio, and a database in use
+// by a running daemon of another version, whose data this binary would mix with its own
+func checkVersions(database *sql.DB) error {
+	state, err := upgrade.ReadState(database)
+	if err != nil {
+		return err
+	}
+	if err := upgrade.Check(state, version.Version); err != nil {
+		return newError(CategoryVersionMismatch, err)
+	}
+
+	if state.DaemonPID == 0 || state.DaemonVersion == version.Version {
+		return nil
+	}
+	// The record may outlive a daemon that didn't stop cleanly
+	running, _, err := daemon.VerifyDaemonRunning()
+	if err != nil || !running {
+		return nil
+	}
+	pid, err := daemon.ReadPID()
+	if err != nil || pid != state.DaemonPID {
+		return nil
+	}
+	return newError(CategoryVersionMismatch, fmt.Errorf(
+		"the running daemon is clio %s but this is clio %s; restart it with 'clio stop' and 'clio start' so both use the same version",
+		state.DaemonVersion, version.Version))
+}
+
+// upgradeData runs the data upgrade a new clio needs, printing progress to stderr.
+// The daemon upgrades at startup, so this only does work when the CLI is the
+// first of the new version to open the database.
+func upgradeData(cfg *config.Config, database *sql.DB) error {
+	blobStore, err := openBlobStore(cfg)
+	if err != nil {
+		return err
+	}
+	upgrader, err := upgrade.NewUpgrader(database, blobStore, version.Version, logging.NewNoopLogger())
+	if err != nil {
+		return err
+	}
+	needed, err := upgrader.Needed()
+	if err != nil {
+		return err
+	}
+	if !needed {
+		return nil
+	}
+
+	// Stay quiet when there is nothing stored to upgrade, e.g. on first use
+	reported := false
+	lastPercent := -1
+	result, err := upgrader.Run(func(backfill string, done, total int) {
+		if !reported {
+			fmt.Fprintf(os.Stderr, "Upgrading data for clio %s...\n", version.Version)
+			reported = true
+		}
+		percent := done * 100 / total
+		if percent/10 == lastPercent/10 && done < total {
+			return
+		}
+		lastPercent = percent
+		fmt.Fprintf(os.Stderr, "  %s: %d/%d conversations (%d%%)\n", backfill, done, total, percent)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upgrade data: %w", err)
+	}
+	if reported {
+		fmt.Fprintf(os.Stderr, "Data upgraded to version %d (%d backfill(s) applied)\n", result.ToVersion, len(result.Backfills))
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/stwalsh4118/clio/internal/git"
+	"github.com/stwalsh4118/clio/internal/report"
+)
+
+// newWhyCmd creates the why command
+func newWhyCmd() *cobra.Command {
+	var limit int
+	var excerpts int
+
+	cmd := &cobra.Command{
+		Use:   "why <file>[:line]",
+		Short: "Explain code with the conversations behind the commits that changed it",
+		Long: `Answer "why does this code look like this?" from captured history. For a file,
+the latest captured commits that touched it are shown with excerpts from the
+conversations in their correlated sessions. With :line, the line is blamed at
+HEAD and only the commit that last changed it is explained.
+
+Excerpts are the messages before the commit that mention the file name, topped
+up with the messages just before the commit.
+
+Examples:
+  clio why internal/parser/lexer.go
+  clio why internal/parser/lexer.go:42 --excerpts 5`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if limit <= 0 {
+				return usageErrorf("--limit must be positive")
+			}
+			if excerpts <= 0 {
+				return usageErrorf("--excerpts must be positive")
+			}
+			file, line := parseFileLine(args[0])
+			return handleWhy(file, line, report.WhyOptions{Limit: limit, Excerpts: excerpts})
+		},
+	}
+
+	cmd.Flags().IntVarP(&limit, "limit", "n", report.DefaultWhyCommits, "Number of commits to explain")
+	cmd.Flags().IntVar(&excerpts, "excerpts", report.DefaultWhyExcerpts, "Conversation excerpts per commit")
+
+	return cmd
+}
+
+// parseFileLine splits "path:line" into its parts; line is 0 when absent
+func parseFileLine(arg string) (string, int) {
+	if i := strings.LastIndex(arg, ":"); i > 0 {
+		if line, err := strconv.Atoi(arg[i+1:]); err == nil && line > 0 {
+			return arg[:i], line
+		}
+	}
+	return arg, 0
+}
+
+// handleWhy implements the why command
+func handleWhy(file string, line int, opts report.WhyOptions) error {
+	absFile, err := filepath.Abs(file)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", file, err)
+	}
+	if resolved, err := filepath.EvalSymlinks(absFile); err == nil {
+		absFile = resolved
+	}
+
+	repo, root, err := git.OpenContaining(filepath.Dir(absFile))
+	if err != nil {
+		return usageErrorf("%s is not in a git repository", file)
+	}
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+	rel, err := filepath.Rel(root, absFile)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return usageErrorf("%s is outside repository %s", file, root)
+	}
+	opts.RepositoryPath = root
+	opts.File = rel
+
+	if line > 0 {
+		if opts.CommitHash, err = git.BlameLine(repo, rel, line); err != nil {
+			return usageErrorf("%v", err)
+		}
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	database, err := openReadOnlyDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	reporter, err := newBlobReporter(cfg, database)
+	if err != nil {
+		return err
+	}
+	commits, err := reporter.Why(opts)
+	if err != nil {
+		return fmt.Errorf("failed to explain %s: %w", file, err)
+	}
+
+	if len(commits) == 0 {
+		if line > 0 {
+			fmt.Printf("Line %d of %s was last changed in %s, which clio hasn't captured\n", line, rel, shortHash(opts.CommitHash))
+		} else {
+			fmt.Printf("No captured commits touched %s\n", rel)
+		}
+		return nil
+	}
+
+	if line > 0 {
+		fmt.Printf("%s:%d\n", rel, line)
+	} else {
+		fmt.Printf("%s\n", rel)
+	}
+	for _, commit := range commits {
+		fmt.Printf("\n%s  %s  %s", shortHash(commit.Hash), formatTime(commit.Timestamp), commitSubject(commit.Message))
+		if commit.LinesAdded > 0 || commit.LinesRemoved > 0 {
+			fmt.Printf("  (+%d -%d)", commit.LinesAdded, commit.LinesRemoved)
+		}
+		fmt.Println()
+
+		if commit.SessionID == "" {
+			fmt.Println("  No correlated session")
+			continue
+		}
+		fmt.Printf("  Session %s (%s)\n", shortHash(commit.SessionID), commit.Project)
+		if len(commit.Excerpts) == 0 {
+			fmt.Println("  No conversation messages before the commit")
+		}
+		for _, excerpt := range commit.Excerpts {
+			fmt.Printf("  [%s] %s in %q: %s\n", formatTime(excerpt.CreatedAt), excerpt.Role, excerpt.Conversation, excerpt.Text)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+// Package clipboard puts text on the system clipboard through the platform's
+// clipboard command, so rendered output can be pasted elsewhere.
+package clipboard
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// commandTimeout bounds a single clipboard command
+const commandTimeout = 10 * time.Second
+
+// tool is a clipboard command and its arguments
+type tool struct {
+	name string
+	args []string
+}
+
+// Clipboard copies text to the system clipboard
+type Clipboard interface {
+	Copy(text string) error
+	// Tool names the clipboard command in use, for display
+	Tool() string
+}
+
+// runFunc runs a command with stdin
+type runFunc func(ctx context.Context, stdin string, name string, args ...string) error
+
+// commandClipboard copies through a clipboard command that reads stdin
+type commandClipboard struct {
+	tool tool
+	run  runFunc
+}
+
+// New returns the clipboard for this platform: pbcopy on macOS, clip on
+// Windows, and on Linux and the BSDs wl-copy under Wayland, then xclip or xsel
+func New() (Clipboard, error) {
+	return newClipboard(runtime.GOOS, os.Getenv, exec.LookPath, runCommand)
+}
+
+// newClipboard picks the first clipboard command for goos that lookPath finds
+func newClipboard(goos string, getenv func(string) string, lookPath func(string) (string, error), run runFunc) (Clipboard, error) {
+	var candidates []tool
+	switch goos {
+	case "darwin":
+		candidates = []tool{{name: "pbcopy"}}
+	case "windows":
+		candidates = []tool{{name: "clip.exe"}}
+	case "linux", "freebsd", "openbsd", "netbsd":
+		if getenv("WAYLAND_DISPLAY") != "" {
+			candidates = append(candidates, tool{name: "wl-copy"})
+		}
+		candidates = append(candidates,
+			tool{name: "xclip", args: []string{"-selection", "clipboard"}},
+			tool{name: "xsel", args: []string{"--clipboard", "--input"}},
+		)
+	default:
+		return nil, fmt.Errorf("no supported clipboard on %s", goos)
+	}
+
+	names := make([]string, len(candidates))
+	for i, candidate := range candidates {
+		if _, err := lookPath(candidate.name); err == nil {
+			return &commandClipboard{tool: candidate, run: run}, nil
+		}
+		names[i] = candidate.name
+	}
+	return nil, fmt.Errorf("no clipboard command found; install %s", strings.Join(names, " or "))
+}
+
+// Tool implements Clipboard
+func (c *commandClipboard) Tool() string {
+	return c.tool.name
+}
+
+// Copy implements Clipboard
+func (c *commandClipboard) Copy(text string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	if err := c.run(ctx, text, c.tool.name, c.tool.args...); err != nil {
+		return fmt.Errorf("failed to copy to the clipboard: %w", err)
+	}
+	return nil
+}
+
+// runCommand runs a clipboard command with text on stdin, including stderr in errors
+func runCommand(ctx context.Context, stdin string, name string, args ...string) error {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%s: %w: %s", name, err, msg)
+		}
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
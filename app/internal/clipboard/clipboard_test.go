@@ -0,0 +1,82 @@
+package clipboard
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestNewClipboard(t *testing.T) {
+	tests := []struct {
+		name      string
+		goos      string
+		wayland   string
+		installed []string
+		want      string
+		wantErr   bool
+	}{
+		{name: "macOS", goos: "darwin", installed: []string{"pbcopy"}, want: "pbcopy"},
+		{name: "Windows", goos: "windows", installed: []string{"clip.exe"}, want: "clip.exe"},
+		{name: "Wayland", goos: "linux", wayland: "wayland-0", installed: []string{"wl-copy", "xclip"}, want: "wl-copy"},
+		{name: "X11 ignores wl-copy", goos: "linux", installed: []string{"wl-copy", "xclip"}, want: "xclip"},
+		{name: "xsel fallback", goos: "linux", wayland: "wayland-0", installed: []string{"xsel"}, want: "xsel"},
+		{name: "nothing installed", goos: "linux", wantErr: true},
+		{name: "unsupported", goos: "plan9", installed: []string{"pbcopy"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(key string) string {
+				if key == "WAYLAND_DISPLAY" {
+					return tt.wayland
+				}
+				return ""
+			}
+			lookPath := func(name string) (string, error) {
+				for _, installed := range tt.installed {
+					if installed == name {
+						return "/usr/bin/" + name, nil
+					}
+				}
+				return "", exec.ErrNotFound
+			}
+
+			clipboard, err := newClipboard(tt.goos, getenv, lookPath, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newClipboard() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && clipboard.Tool() != tt.want {
+				t.Errorf("Tool() = %q, want %q", clipboard.Tool(), tt.want)
+			}
+		})
+	}
+}
+
+func TestCommandClipboard_Copy(t *testing.T) {
+	var got []string
+	run := func(ctx context.Context, stdin string, name string, args ...string) error {
+		got = append(got, name+" "+strings.Join(args, " ")+": "+stdin)
+		return nil
+	}
+	lookPath := func(name string) (string, error) { return name, nil }
+
+	clipboard, err := newClipboard("linux", func(string) string { return "" }, lookPath, run)
+	if err != nil {
+		t.Fatalf("newClipboard() error = %v", err)
+	}
+	if err := clipboard.Copy("hello\nworld"); err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	if len(got) != 1 || got[0] != "xclip -selection clipboard: hello\nworld" {
+		t.Errorf("calls = %q, want the text on xclip's stdin", got)
+	}
+
+	failing := &commandClipboard{tool: tool{name: "pbcopy"}, run: func(context.Context, string, string, ...string) error {
+		return errors.New("boom")
+	}}
+	if err := failing.Copy("x"); err == nil {
+		t.Error("Copy() should fail when the command fails")
+	}
+}
@@ -0,0 +1,205 @@
+// Package commitmsg parses commit messages and checks them against a message
+// convention: Conventional Commits ("feat(parser): add dates") or a regular
+// expression the subject line must match.
+package commitmsg
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/stwalsh4118/clio/internal/config"
+)
+
+// Message conventions
+const (
+	// ConventionConventional is Conventional Commits: type(scope)!: description
+	ConventionConventional = "conventional"
+	// ConventionRegex requires the subject line to match a regular expression
+	ConventionRegex = "regex"
+)
+
+// DefaultTypes are the Conventional Commits types allowed unless configured
+var DefaultTypes = []string{"build", "chore", "ci", "docs", "feat", "fix", "perf", "refactor", "revert", "style", "test"}
+
+// conventionalPattern matches a Conventional Commits subject line
+var conventionalPattern = regexp.MustCompile(`^([A-Za-z]+)(?:\(([^()\s][^()]*)\))?(!)?: (\S.*)$`)
+
+// breakingFooter marks a breaking change in a message's body
+var breakingFooter = regexp.MustCompile(`(?m)^BREAKING[ -]CHANGE: `)
+
+// revertBody is the line git revert writes into the message body
+var revertBody = regexp.MustCompile(`(?m)^This reverts commit ([0-9a-f]{7,40})\b`)
+
+// revertSubject is the subject line git revert writes
+var revertSubject = regexp.MustCompile(`^Revert "(.+)"$`)
+
+// fixupPrefixes start the subjects git commit --fixup and --squash write
+var fixupPrefixes = []string{"fixup! ", "squash! ", "amend! "}
+
+// Follow-up kinds
+const (
+	// FollowUpRevert is a commit undoing an earlier one
+	FollowUpRevert = "revert"
+	// FollowUpFixup is a fixup!, squash!, or amend! commit meant to be folded into an earlier one
+	FollowUpFixup = "fixup"
+)
+
+// FollowUp is a commit that reverts or fixes up an earlier commit
+type FollowUp struct {
+	Kind    string // FollowUpRevert or FollowUpFixup
+	Hash    string // The earlier commit's hash, possibly abbreviated; empty when the message doesn't name it
+	Subject string // The earlier commit's subject; empty when the message doesn't quote it
+}
+
+// Conventional is a commit message following Conventional Commits
+type Conventional struct {
+	Type        string // Lowercased, e.g. "feat"
+	Scope       string // Empty when the subject has none
+	Breaking    bool   // Marked with ! or a BREAKING CHANGE footer
+	Description string
+}
+
+// Subject returns the first line of message, trimmed
+func Subject(message string) string {
+	subject, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	return strings.TrimSpace(subject)
+}
+
+// ParseConventional parses message as a Conventional Commit, reporting false
+// when its subject line doesn't follow the format. The type isn't checked
+// against a list.
+func ParseConventional(message string) (Conventional, bool) {
+	groups := conventionalPattern.FindStringSubmatch(Subject(message))
+	if groups == nil {
+		return Conventional{}, false
+	}
+	return Conventional{
+		Type:        strings.ToLower(groups[1]),
+		Scope:       strings.TrimSpace(groups[2]),
+		Breaking:    groups[3] == "!" || breakingFooter.MatchString(message),
+		Description: groups[4],
+	}, true
+}
+
+// RevertedHash returns the hash of the commit that message's commit reverts,
+// read from the "This reverts commit <hash>." line git revert writes, or ""
+// when it doesn't revert one. The hash may be abbreviated.
+func RevertedHash(message string) string {
+	groups := revertBody.FindStringSubmatch(message)
+	if groups == nil {
+		return ""
+	}
+	return groups[1]
+}
+
+// ParseFollowUp reports whether message's commit reverts or fixes up an
+// earlier commit, and which. Reverts are recognized by the message git revert
+// writes, fixups by the fixup!, squash!, or amend! subject prefixes git commit
+// writes; a revert of a revert is a revert of the revert.
+func ParseFollowUp(message string) (FollowUp, bool) {
+	subject := Subject(message)
+	if hash := RevertedHash(message); hash != "" {
+		followUp := FollowUp{Kind: FollowUpRevert, Hash: hash}
+		if groups := revertSubject.FindStringSubmatch(subject); groups != nil {
+			followUp.Subject = groups[1]
+		}
+		return followUp, true
+	}
+	if groups := revertSubject.FindStringSubmatch(subject); groups != nil {
+		return FollowUp{Kind: FollowUpRevert, Subject: groups[1]}, true
+	}
+
+	fixup := false
+	for trimmed := true; trimmed; {
+		trimmed = false
+		for _, prefix := range fixupPrefixes {
+			if strings.HasPrefix(subject, prefix) {
+				subject = strings.TrimSpace(subject[len(prefix):])
+				fixup, trimmed = true, true
+			}
+		}
+	}
+	if fixup && subject != "" {
+		return FollowUp{Kind: FollowUpFixup, Subject: subject}, true
+	}
+	return FollowUp{}, false
+}
+
+// Convention checks commit messages against a configured convention
+type Convention struct {
+	kind             string
+	pattern          *regexp.Regexp // Set for ConventionRegex
+	types            map[string]bool
+	maxSubjectLength int
+}
+
+// NewConvention creates a convention from the configuration
+func NewConvention(cfg config.CommitStyleConfig) (*Convention, error) {
+	c := &Convention{kind: cfg.Convention, maxSubjectLength: cfg.MaxSubjectLength}
+	switch cfg.Convention {
+	case ConventionConventional, "":
+		c.kind = ConventionConventional
+		types := cfg.Types
+		if len(types) == 0 {
+			types = DefaultTypes
+		}
+		c.types = make(map[string]bool, len(types))
+		for _, t := range types {
+			c.types[strings.ToLower(t)] = true
+		}
+	case ConventionRegex:
+		if cfg.Pattern == "" {
+			return nil, fmt.Errorf("the regex convention needs a pattern")
+		}
+		pattern, err := regexp.Compile(cfg.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern: %w", err)
+		}
+		c.pattern = pattern
+	default:
+		return nil, fmt.Errorf("unknown convention %q (use %s or %s)", cfg.Convention, ConventionConventional, ConventionRegex)
+	}
+	return c, nil
+}
+
+// Describe summarizes the convention, e.g. "conventional commits"
+func (c *Convention) Describe() string {
+	description := "conventional commits"
+	if c.kind == ConventionRegex {
+		description = fmt.Sprintf("subjects matching %s", c.pattern)
+	}
+	if c.maxSubjectLength > 0 {
+		description += fmt.Sprintf(", at most %d characters", c.maxSubjectLength)
+	}
+	return description
+}
+
+// Check returns the ways message breaks the convention, or nil when it
+// follows it. Problems are short and stable, e.g. "unknown type", so they can
+// be counted.
+func (c *Convention) Check(message string) []string {
+	subject := Subject(message)
+	if subject == "" {
+		return []string{"empty message"}
+	}
+
+	var problems []string
+	switch c.kind {
+	case ConventionRegex:
+		if !c.pattern.MatchString(subject) {
+			problems = append(problems, "subject doesn't match the pattern")
+		}
+	default:
+		if parsed, ok := ParseConventional(message); !ok {
+			problems = append(problems, "not type(scope): description")
+		} else if !c.types[parsed.Type] {
+			problems = append(problems, "unknown type")
+		}
+	}
+	if c.maxSubjectLength > 0 && utf8.RuneCountInString(subject) > c.maxSubjectLength {
+		problems = append(problems, "subject too long")
+	}
+	return problems
+}
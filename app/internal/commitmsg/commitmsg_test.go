@@ -0,0 +1,109 @@
+package commitmsg
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stwalsh4118/clio/internal/config"
+)
+
+func TestParseConventional(t *testing.T) {
+	tests := []struct {
+		message string
+		want    Conventional
+		ok      bool
+	}{
+		{"feat(parser): add date ranges", Conventional{Type: "feat", Scope: "parser", Description: "add date ranges"}, true},
+		{"Fix: handle empty input\n\nDetails", Conventional{Type: "fix", Description: "handle empty input"}, true},
+		{"refactor!: drop the v1 API", Conventional{Type: "refactor", Breaking: true, Description: "drop the v1 API"}, true},
+		{"feat: new config\n\nBREAKING CHANGE: the file moved", Conventional{Type: "feat", Breaking: true, Description: "new config"}, true},
+		{"fixed stuff", Conventional{}, false},
+		{"feat:missing space", Conventional{}, false},
+		{"feat(): empty scope", Conventional{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseConventional(tt.message)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("ParseConventional(%q) = %+v, %v, want %+v, %v", tt.message, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestConvention_Check(t *testing.T) {
+	conventional, err := NewConvention(config.CommitStyleConfig{Convention: ConventionConventional, MaxSubjectLength: 30})
+	if err != nil {
+		t.Fatalf("NewConvention() error = %v", err)
+	}
+	issueKey, err := NewConvention(config.CommitStyleConfig{Convention: ConventionRegex, Pattern: `^[A-Z]+-[0-9]+: `})
+	if err != nil {
+		t.Fatalf("NewConvention() error = %v", err)
+	}
+
+	tests := []struct {
+		convention *Convention
+		message    string
+		want       []string
+	}{
+		{conventional, "fix(cli): handle empty input", nil},
+		{conventional, "feture: add search", []string{"unknown type"}},
+		{conventional, "Updated the parser to handle every edge case", []string{"not type(scope): description", "subject too long"}},
+		{conventional, "  \n", []string{"empty message"}},
+		{issueKey, "CLIO-12: add search", nil},
+		{issueKey, "add search", []string{"subject doesn't match the pattern"}},
+	}
+	for _, tt := range tests {
+		if got := tt.convention.Check(tt.message); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Check(%q) = %q, want %q", tt.message, got, tt.want)
+		}
+	}
+
+	for _, cfg := range []config.CommitStyleConfig{
+		{Convention: ConventionRegex},
+		{Convention: ConventionRegex, Pattern: "("},
+		{Convention: "gitmoji"},
+	} {
+		if _, err := NewConvention(cfg); err == nil {
+			t.Errorf("NewConvention(%+v) expected error, got nil", cfg)
+		}
+	}
+}
+
+func TestRevertedHash(t *testing.T) {
+	tests := []struct {
+		message string
+		want    string
+	}{
+		{"Revert \"feat: add dates\"\n\nThis reverts commit 3f2a9c41d0e8b7a6c5d4e3f2a1b0c9d8e7f6a5b4.\n", "3f2a9c41d0e8b7a6c5d4e3f2a1b0c9d8e7f6a5b4"},
+		{"Revert the parser change\n\nThis reverts commit 3f2a9c4, which broke quoting.", "3f2a9c4"},
+		{"fix: this reverts commit 3f2a9c4 in spirit", ""},
+		{"feat: add dates", ""},
+	}
+	for _, tt := range tests {
+		if got := RevertedHash(tt.message); got != tt.want {
+			t.Errorf("RevertedHash(%q) = %q, want %q", tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestParseFollowUp(t *testing.T) {
+	tests := []struct {
+		message string
+		want    FollowUp
+		ok      bool
+	}{
+		{"Revert \"feat: add dates\"\n\nThis reverts commit 3f2a9c4.", FollowUp{Kind: FollowUpRevert, Hash: "3f2a9c4", Subject: "feat: add dates"}, true},
+		{"Undo the parser change\n\nThis reverts commit 3f2a9c4.", FollowUp{Kind: FollowUpRevert, Hash: "3f2a9c4"}, true},
+		{"Revert \"Revert \"feat: add dates\"\"", FollowUp{Kind: FollowUpRevert, Subject: "Revert \"feat: add dates\""}, true},
+		{"fixup! feat: add dates", FollowUp{Kind: FollowUpFixup, Subject: "feat: add dates"}, true},
+		{"fixup! squash! feat: add dates\n\nForgot a test", FollowUp{Kind: FollowUpFixup, Subject: "feat: add dates"}, true},
+		{"amend! feat: add dates", FollowUp{Kind: FollowUpFixup, Subject: "feat: add dates"}, true},
+		{"fixup! ", FollowUp{}, false},
+		{"fix: revert handling", FollowUp{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseFollowUp(tt.message)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("ParseFollowUp(%q) = %+v, %v, want %+v, %v", tt.message, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -2,13 +2,48 @@ package config
 
 // Config represents the root configuration structure for clio
 type Config struct {
-	WatchedDirectories []string      `mapstructure:"watched_directories" yaml:"watched_directories"`
-	BlogRepository     string        `mapstructure:"blog_repository" yaml:"blog_repository"`
-	Storage            StorageConfig `mapstructure:"storage" yaml:"storage"`
-	Cursor             CursorConfig  `mapstructure:"cursor" yaml:"cursor"`
-	Session            SessionConfig `mapstructure:"session" yaml:"session"`
-	Logging            LoggingConfig `mapstructure:"logging" yaml:"logging"`
-	Git                GitConfig     `mapstructure:"git" yaml:"git"`
+	WatchedDirectories []string                 `mapstructure:"watched_directories" yaml:"watched_directories"`
+	BlogRepository     string                   `mapstructure:"blog_repository" yaml:"blog_repository"`
+	Timezone           string                   `mapstructure:"timezone" yaml:"timezone,omitempty"` // IANA zone reports bucket days in, e.g. Europe/Berlin; empty uses the system zone
+	Storage            StorageConfig            `mapstructure:"storage" yaml:"storage"`
+	Cursor             CursorConfig             `mapstructure:"cursor" yaml:"cursor"`
+	Zed                ZedConfig                `mapstructure:"zed" yaml:"zed"`
+	JetBrains          JetBrainsConfig          `mapstructure:"jetbrains" yaml:"jetbrains"`
+	Heartbeats         HeartbeatsConfig         `mapstructure:"heartbeats" yaml:"heartbeats"`
+	Session            SessionConfig            `mapstructure:"session" yaml:"session"`
+	Logging            LoggingConfig            `mapstructure:"logging" yaml:"logging"`
+	Git                GitConfig                `mapstructure:"git" yaml:"git"`
+	Webhooks           []WebhookConfig          `mapstructure:"webhooks" yaml:"webhooks"`
+	Hooks              HooksConfig              `mapstructure:"hooks" yaml:"hooks"`
+	Standup            StandupConfig            `mapstructure:"standup" yaml:"standup"`
+	Summaries          SummariesConfig          `mapstructure:"summaries" yaml:"summaries"`
+	Retro              RetroConfig              `mapstructure:"retro" yaml:"retro"`
+	Nudges             NudgesConfig             `mapstructure:"nudges" yaml:"nudges"`
+	Translation        TranslationConfig        `mapstructure:"translation" yaml:"translation"`
+	Redaction          RedactionConfig          `mapstructure:"redaction" yaml:"redaction"`
+	Scrub              ScrubConfig              `mapstructure:"scrub" yaml:"scrub,omitempty"`
+	CommitStyle        CommitStyleConfig        `mapstructure:"commit_style" yaml:"commit_style"`
+	Sensitive          SensitiveConfig          `mapstructure:"sensitive" yaml:"sensitive"`
+	Team               TeamConfig               `mapstructure:"team" yaml:"team,omitempty"`
+	Share              ShareConfig              `mapstructure:"share" yaml:"share,omitempty"`
+	Search             SearchConfig             `mapstructure:"search" yaml:"search"`
+	RemoteStorage      RemoteStorageConfig      `mapstructure:"remote_storage" yaml:"remote_storage,omitempty"`
+	Issues             IssuesConfig             `mapstructure:"issues" yaml:"issues,omitempty"`
+	Filters            map[string]string        `mapstructure:"filters" yaml:"filters,omitempty"`     // Named filters, e.g. bugfixes: "tag:bugfix AND project:clio"
+	Alerts             []AlertConfig            `mapstructure:"alerts" yaml:"alerts,omitempty"`       // Keyword and regex watches over newly captured messages and diffs
+	TagRules           []TagRuleConfig          `mapstructure:"tag_rules" yaml:"tag_rules,omitempty"` // Goal tags applied to sessions automatically when they end
+	Profiles           map[string]ProfileConfig `mapstructure:"profiles" yaml:"profiles,omitempty"`   // Named overrides selected with --profile or CLIO_PROFILE
+
+	Profile     string       `mapstructure:"-" yaml:"-"` // Active profile name, empty for the default configuration
+	profileBase *profileBase // Top-level values replaced by the active profile
+}
+
+// ProfileConfig overrides top-level settings while the profile is active
+type ProfileConfig struct {
+	WatchedDirectories []string      `mapstructure:"watched_directories" yaml:"watched_directories,omitempty"` // Replaces the top-level list when set
+	BlogRepository     string        `mapstructure:"blog_repository" yaml:"blog_repository,omitempty"`         // Replaces the top-level blog repository when set
+	Storage            StorageConfig `mapstructure:"storage" yaml:"storage,omitempty"`                         // Unset paths default to ~/.clio/profiles/<name>/
+	LogFilePath        string        `mapstructure:"log_file_path" yaml:"log_file_path,omitempty"`             // Defaults to clio.log in the profile's storage base path
 }
 
 // StorageConfig contains storage-related configuration
@@ -16,12 +51,43 @@ type StorageConfig struct {
 	BasePath     string `mapstructure:"base_path" yaml:"base_path"`
 	SessionsPath string `mapstructure:"sessions_path" yaml:"sessions_path"`
 	DatabasePath string `mapstructure:"database_path" yaml:"database_path"`
+	AssetsPath   string `mapstructure:"assets_path" yaml:"assets_path"` // Content-addressed files attached to sessions
+	BlobsPath    string `mapstructure:"blobs_path" yaml:"blobs_path"`   // Oversized message content and diffs moved out of the database
+	// Values larger than this many bytes are moved to the blob store (default: 262144, 256 KiB)
+	BlobThresholdBytes int `mapstructure:"blob_threshold_bytes" yaml:"blob_threshold_bytes,omitempty"`
 }
 
 // CursorConfig contains Cursor-related configuration
 type CursorConfig struct {
-	LogPath            string `mapstructure:"log_path" yaml:"log_path"`
-	PollIntervalSeconds int  `mapstructure:"poll_interval_seconds" yaml:"poll_interval_seconds"`
+	LogPath                   string `mapstructure:"log_path" yaml:"log_path"`
+	PollIntervalSeconds       int    `mapstructure:"poll_interval_seconds" yaml:"poll_interval_seconds"`
+	DebounceSeconds           int    `mapstructure:"debounce_seconds" yaml:"debounce_seconds"`                         // Seconds data must be unchanged before capture runs (default: 2, 0 disables)
+	QueueSize                 int    `mapstructure:"queue_size" yaml:"queue_size"`                                     // Max conversations waiting to be processed (default: 100)
+	MaxConcurrency            int    `mapstructure:"max_concurrency" yaml:"max_concurrency"`                           // Conversations processed in parallel (default: 1)
+	MaxConversationsPerSecond int    `mapstructure:"max_conversations_per_second" yaml:"max_conversations_per_second"` // Processing rate limit (default: 5, 0 disables)
+	ArchiveRawPayloads        bool   `mapstructure:"archive_raw_payloads" yaml:"archive_raw_payloads"`                 // Store compressed raw Cursor JSON for re-parsing (default: false)
+}
+
+// ZedConfig contains configuration for capturing Zed assistant conversations
+type ZedConfig struct {
+	Enabled             bool   `mapstructure:"enabled" yaml:"enabled"`                             // Capture Zed assistant conversations (default: false)
+	ConversationsPath   string `mapstructure:"conversations_path" yaml:"conversations_path"`       // Directory of saved *.zed.json contexts (default: ~/.config/zed/conversations)
+	DatabasePath        string `mapstructure:"database_path" yaml:"database_path"`                 // Zed's state database holding inline assist history (default: platform data dir)
+	PollIntervalSeconds int    `mapstructure:"poll_interval_seconds" yaml:"poll_interval_seconds"` // How often to check for changes (default: 30)
+}
+
+// JetBrainsConfig contains configuration for capturing JetBrains AI Assistant chats
+type JetBrainsConfig struct {
+	Enabled             bool   `mapstructure:"enabled" yaml:"enabled"`                             // Capture AI Assistant chat history (default: false)
+	ConfigPath          string `mapstructure:"config_path" yaml:"config_path"`                     // JetBrains config root holding one directory per IDE version (default: platform config dir)
+	PollIntervalSeconds int    `mapstructure:"poll_interval_seconds" yaml:"poll_interval_seconds"` // How often to check for changes (default: 60)
+}
+
+// HeartbeatsConfig contains configuration for WakaTime-style editor activity heartbeats
+type HeartbeatsConfig struct {
+	LogPath             string `mapstructure:"log_path" yaml:"log_path"`                           // JSON-lines file of heartbeats to tail (default: "", disabled)
+	PollIntervalSeconds int    `mapstructure:"poll_interval_seconds" yaml:"poll_interval_seconds"` // How often to check the log for new heartbeats (default: 10)
+	TimeoutMinutes      int    `mapstructure:"timeout_minutes" yaml:"timeout_minutes"`             // Longer gaps between heartbeats aren't counted as file time (default: 15)
 }
 
 // SessionConfig contains session-related configuration
@@ -31,14 +97,197 @@ type SessionConfig struct {
 
 // LoggingConfig contains logging-related configuration
 type LoggingConfig struct {
-	Level      string `mapstructure:"level" yaml:"level"`           // "debug", "info", "warn", "error" (default: "info")
-	FilePath   string `mapstructure:"file_path" yaml:"file_path"`   // Path to log file (default: ~/.clio/clio.log)
-	Console    bool   `mapstructure:"console" yaml:"console"`       // Also log to console (default: false for daemon, true for CLI)
-	MaxSize    int    `mapstructure:"max_size" yaml:"max_size"`     // Max log file size in MB before rotation (default: 10)
+	Level      string `mapstructure:"level" yaml:"level"`             // "debug", "info", "warn", "error" (default: "info")
+	FilePath   string `mapstructure:"file_path" yaml:"file_path"`     // Path to log file (default: ~/.clio/clio.log)
+	Console    bool   `mapstructure:"console" yaml:"console"`         // Also log to console (default: false for daemon, true for CLI)
+	MaxSize    int    `mapstructure:"max_size" yaml:"max_size"`       // Max log file size in MB before rotation (default: 10)
 	MaxBackups int    `mapstructure:"max_backups" yaml:"max_backups"` // Number of rotated log files to keep (default: 3)
 }
 
 // GitConfig contains git-related configuration
 type GitConfig struct {
-	PollIntervalSeconds int `mapstructure:"poll_interval_seconds" yaml:"poll_interval_seconds"` // Polling interval in seconds (default: 30, minimum: 1)
+	PollIntervalSeconds      int     `mapstructure:"poll_interval_seconds" yaml:"poll_interval_seconds"`             // Polling interval in seconds (default: 30, minimum: 1)
+	MinCorrelationConfidence float64 `mapstructure:"min_correlation_confidence" yaml:"min_correlation_confidence"`   // Commit-session links below this confidence are excluded from exports (default: 0.5, range: 0-1)
+	ErrorBudget              int     `mapstructure:"error_budget" yaml:"error_budget"`                               // Failures per repository within the budget window before polling is suspended (default: 5)
+	ErrorBudgetWindowSeconds int     `mapstructure:"error_budget_window_seconds" yaml:"error_budget_window_seconds"` // Window in which failures count against the error budget (default: 600)
+	CircuitCooldownSeconds   int     `mapstructure:"circuit_cooldown_seconds" yaml:"circuit_cooldown_seconds"`       // Seconds polling stays suspended once the budget is exhausted (default: 900)
+	AdaptivePolling          bool    `mapstructure:"adaptive_polling" yaml:"adaptive_polling"`                       // Shorten intervals for active repositories and lengthen them when quiet (default: false)
+	MinPollIntervalSeconds   int     `mapstructure:"min_poll_interval_seconds" yaml:"min_poll_interval_seconds"`     // Adaptive polling lower bound (default: 5)
+	MaxPollIntervalSeconds   int     `mapstructure:"max_poll_interval_seconds" yaml:"max_poll_interval_seconds"`     // Adaptive polling upper bound (default: 300)
+	BatchPollResults         bool    `mapstructure:"batch_poll_results" yaml:"batch_poll_results"`                   // Emit one batch per polling cycle instead of per-repository results (default: false)
+	MaxGapFillCommits        int     `mapstructure:"max_gap_fill_commits" yaml:"max_gap_fill_commits"`               // Most recent commits ingested per repository on startup after downtime (default: 500)
+	PostSessionWindowMinutes int     `mapstructure:"post_session_window_minutes" yaml:"post_session_window_minutes"` // Gap-filled commits may match sessions that ended up to this long before (default: 30)
+	ExtractWorkers           int     `mapstructure:"extract_workers" yaml:"extract_workers"`                         // Commits extracted concurrently when many arrive at once, e.g. gap-filling (default: 4)
+}
+
+// WebhookConfig registers an outbound webhook called when subscribed events occur
+type WebhookConfig struct {
+	URL    string   `mapstructure:"url" yaml:"url"`       // http(s) endpoint that receives event JSON via POST
+	Events []string `mapstructure:"events" yaml:"events"` // Event types: "session.ended", "commit.captured", "reminder.due", "alert.matched", "search.matched", "habit.nudge"
+	Secret string   `mapstructure:"secret" yaml:"secret"` // Optional HMAC-SHA256 key; signature sent in X-Clio-Signature
+}
+
+// StandupConfig configures clio standup
+type StandupConfig struct {
+	Team                 string            `mapstructure:"team" yaml:"team,omitempty"`                           // Template used when --team isn't given (default: "", the built-in template)
+	Templates            map[string]string `mapstructure:"templates" yaml:"templates,omitempty"`                 // Team name to Go text/template file
+	PhraseCommand        string            `mapstructure:"phrase_command" yaml:"phrase_command,omitempty"`       // Executable that rewrites the standup read from stdin, e.g. with an LLM
+	PhraseTimeoutSeconds int               `mapstructure:"phrase_timeout_seconds" yaml:"phrase_timeout_seconds"` // The phrase command is killed after this long (default: 60)
+}
+
+// RetroConfig configures clio retro
+type RetroConfig struct {
+	PolishCommand        string `mapstructure:"polish_command" yaml:"polish_command,omitempty"`       // Executable that rewrites the retrospective read from stdin, e.g. with an LLM
+	PolishTimeoutSeconds int    `mapstructure:"polish_timeout_seconds" yaml:"polish_timeout_seconds"` // The polish command is killed after this long (default: 120)
+}
+
+// NudgesConfig configures the habit nudges the daemon raises as habit.nudge
+// events; a threshold of 0 turns its nudge off
+type NudgesConfig struct {
+	Enabled             bool `mapstructure:"enabled" yaml:"enabled"`                           // Raise nudges (default: false)
+	UncommittedSessions int  `mapstructure:"uncommitted_sessions" yaml:"uncommitted_sessions"` // Nudge when this many of a project's latest sessions have no correlated commits (default: 3)
+	LongSessionMinutes  int  `mapstructure:"long_session_minutes" yaml:"long_session_minutes"` // Nudge when an active session goes this long without a commit (default: 180)
+	CooldownHours       int  `mapstructure:"cooldown_hours" yaml:"cooldown_hours"`             // Minimum time between nudges of one kind in one project (default: 24)
+}
+
+// SummariesConfig configures how conversations and sessions are summarized
+type SummariesConfig struct {
+	Command        string `mapstructure:"command" yaml:"command,omitempty"`       // Executable that summarizes the transcript read from stdin, e.g. with an LLM (default: built-in heuristic)
+	TimeoutSeconds int    `mapstructure:"timeout_seconds" yaml:"timeout_seconds"` // The command is killed after this long (default: 120)
+}
+
+// TranslationConfig configures how exports translate messages with --translate
+type TranslationConfig struct {
+	Command        string `mapstructure:"command" yaml:"command,omitempty"`       // Executable that translates the text read from stdin, e.g. with an LLM (default: none, --translate fails)
+	TargetLanguage string `mapstructure:"target_language" yaml:"target_language"` // ISO 639-1 code messages are translated into (default: "en")
+	TimeoutSeconds int    `mapstructure:"timeout_seconds" yaml:"timeout_seconds"` // The command is killed after this long, per message (default: 60)
+}
+
+// RedactionConfig configures what exports leave out; export flags override it
+type RedactionConfig struct {
+	StripThinking   bool     `mapstructure:"strip_thinking" yaml:"strip_thinking"`               // Drop agent reasoning text
+	StripToolCalls  bool     `mapstructure:"strip_tool_calls" yaml:"strip_tool_calls"`           // Drop the tools agents ran
+	StripPaths      bool     `mapstructure:"strip_paths" yaml:"strip_paths"`                     // Shorten absolute paths to their last element
+	AllowExtensions []string `mapstructure:"allow_extensions" yaml:"allow_extensions,omitempty"` // Only export attachments with these extensions (default: all)
+}
+
+// ScrubConfig configures the last pass over published artifacts, blog drafts and
+// share links, which removes personal and internal details even when they were
+// stored. Email addresses and profanity are scrubbed unless kept.
+type ScrubConfig struct {
+	KeepEmails    bool     `mapstructure:"keep_emails" yaml:"keep_emails,omitempty"`       // Leave email addresses in (default: false, replaced with [email])
+	KeepProfanity bool     `mapstructure:"keep_profanity" yaml:"keep_profanity,omitempty"` // Leave profanity unmasked (default: false, masked like d***)
+	Names         []string `mapstructure:"names" yaml:"names,omitempty"`                   // People's names replaced with [name], matched as whole words ignoring case
+	NamesFile     string   `mapstructure:"names_file" yaml:"names_file,omitempty"`         // File of more names, one per line; lines starting with # are skipped
+	Hostnames     []string `mapstructure:"hostnames" yaml:"hostnames,omitempty"`           // Internal hostnames replaced with [host]; a leading dot matches a domain and its subdomains
+}
+
+// CommitStyleConfig configures the commit message convention clio report
+// --commit-style checks stored commits against
+type CommitStyleConfig struct {
+	Convention       string   `mapstructure:"convention" yaml:"convention"`                           // "conventional" or "regex" (default: "conventional")
+	Pattern          string   `mapstructure:"pattern" yaml:"pattern,omitempty"`                       // Regular expression subject lines must match with the regex convention
+	Types            []string `mapstructure:"types" yaml:"types,omitempty"`                           // Conventional commit types allowed (default: build, chore, ci, docs, feat, fix, perf, refactor, revert, style, test)
+	MaxSubjectLength int      `mapstructure:"max_subject_length" yaml:"max_subject_length,omitempty"` // Longest subject line allowed, in characters (default: 0, no limit)
+}
+
+// SensitiveConfig configures the gate captured messages pass before they're
+// stored: content in a sensitive category is stored and flagged, redacted, or
+// dropped according to the category's action
+type SensitiveConfig struct {
+	Categories               []SensitiveCategory `mapstructure:"categories" yaml:"categories,omitempty"`
+	ClassifierCommand        string              `mapstructure:"classifier_command" yaml:"classifier_command,omitempty"`       // Executable reading a message on stdin and printing the categories it contains, e.g. with a local model
+	ClassifierTimeoutSeconds int                 `mapstructure:"classifier_timeout_seconds" yaml:"classifier_timeout_seconds"` // The command is killed after this long (default: 10)
+}
+
+// SensitiveCategory is a kind of content the sensitive gate looks for, such as PII or client names
+type SensitiveCategory struct {
+	Name     string   `mapstructure:"name" yaml:"name"`
+	Patterns []string `mapstructure:"patterns" yaml:"patterns,omitempty"` // Go regular expressions; may be empty when only the classifier command reports the category
+	Action   string   `mapstructure:"action" yaml:"action"`               // "store" (flag only), "redact", or "drop"
+}
+
+// TeamConfig maps commit author identities to team members for 'clio stats --team'
+type TeamConfig struct {
+	Members map[string][]string `mapstructure:"members" yaml:"members,omitempty"` // Member name to the author emails and names they commit as
+}
+
+// ShareConfig configures the LAN listener serving session share links minted
+// with 'clio share'
+type ShareConfig struct {
+	Listen string `mapstructure:"listen" yaml:"listen,omitempty"` // TCP address the daemon serves share links on, e.g. ":7070" (default: "", disabled)
+	URL    string `mapstructure:"url" yaml:"url,omitempty"`       // Base URL printed for links, e.g. "http://devbox.lan:7070" (default: from listen and the hostname)
+}
+
+// Search index tokenizers
+const (
+	// SearchTokenizerUnicode61 splits words on Unicode letters and digits
+	SearchTokenizerUnicode61 = "unicode61"
+	// SearchTokenizerPorter is unicode61 with English stemming, so "retries" matches "retry"
+	SearchTokenizerPorter = "porter"
+)
+
+// SearchConfig configures how 'clio search' indexes messages. Changes are
+// applied by the daemon when it starts, by 'clio search', and by 'clio db reindex'.
+type SearchConfig struct {
+	Tokenizer      string   `mapstructure:"tokenizer" yaml:"tokenizer"`                               // unicode61 (default) or porter
+	KeepDiacritics bool     `mapstructure:"keep_diacritics" yaml:"keep_diacritics,omitempty"`         // Match "café" and "cafe" as different words
+	Languages      []string `mapstructure:"stop_word_languages" yaml:"stop_word_languages,omitempty"` // Built-in stop word lists to leave out of the index, e.g. english, german
+	StopWords      []string `mapstructure:"stop_words" yaml:"stop_words,omitempty"`                   // Extra words to leave out of the index
+}
+
+// IssuesConfig holds the credentials clio issue create uses to open issues
+type IssuesConfig struct {
+	GitHub IssueTrackerConfig `mapstructure:"github" yaml:"github,omitempty"` // GitHub or GitHub Enterprise
+	GitLab IssueTrackerConfig `mapstructure:"gitlab" yaml:"gitlab,omitempty"` // GitLab.com or a self-managed GitLab
+}
+
+// IssueTrackerConfig configures one issue tracker's API
+type IssueTrackerConfig struct {
+	Token  string `mapstructure:"token" yaml:"token,omitempty"`     // API token, as a secret reference such as "secret:github-token"
+	APIURL string `mapstructure:"api_url" yaml:"api_url,omitempty"` // API base URL (default: https://api.github.com or https://gitlab.com/api/v4)
+}
+
+// RemoteStorageConfig holds the credentials exports and archives use when
+// written to s3:// and gs:// URLs
+type RemoteStorageConfig struct {
+	S3  RemoteBucketConfig `mapstructure:"s3" yaml:"s3,omitempty"`   // Amazon S3 or an S3-compatible service such as MinIO
+	GCS RemoteBucketConfig `mapstructure:"gcs" yaml:"gcs,omitempty"` // Google Cloud Storage, through HMAC keys for its S3-compatible API
+}
+
+// RemoteBucketConfig configures one cloud storage service
+type RemoteBucketConfig struct {
+	Region          string `mapstructure:"region" yaml:"region,omitempty"`                       // S3 region (default: us-east-1); ignored for GCS
+	Endpoint        string `mapstructure:"endpoint" yaml:"endpoint,omitempty"`                   // S3-compatible endpoint URL, addressed path-style (default: AWS)
+	AccessKeyID     string `mapstructure:"access_key_id" yaml:"access_key_id,omitempty"`         // Access key ID, or a secret reference such as "secret:aws-key-id"
+	SecretAccessKey string `mapstructure:"secret_access_key" yaml:"secret_access_key,omitempty"` // Secret reference such as "secret:aws-secret-key"; plaintext isn't accepted
+}
+
+// AlertConfig raises an alert.matched event when a newly captured message or
+// diff matches a keyword or regular expression
+type AlertConfig struct {
+	Name    string   `mapstructure:"name" yaml:"name"`                 // Identifies the alert in events
+	Pattern string   `mapstructure:"pattern" yaml:"pattern"`           // Keyword matched case-insensitively, or a Go regular expression with regex
+	Regex   bool     `mapstructure:"regex" yaml:"regex,omitempty"`     // Treat pattern as a regular expression
+	Sources []string `mapstructure:"sources" yaml:"sources,omitempty"` // "messages", "diffs", or both (default: both)
+}
+
+// TagRuleConfig tags a session with a goal tag when it ends, if its project
+// matches and its messages mention one of the keywords
+type TagRuleConfig struct {
+	Tag      string   `mapstructure:"tag" yaml:"tag"`                     // Goal tag to apply; the goal is created when it doesn't exist
+	Project  string   `mapstructure:"project" yaml:"project,omitempty"`   // Glob matched case-insensitively against the session's project, e.g. "api-*" (default: any project)
+	Contains []string `mapstructure:"contains" yaml:"contains,omitempty"` // Keywords matched case-insensitively; a message must mention one (default: no message condition)
+}
+
+// HooksConfig configures executables run on daemon events; each receives the event JSON on stdin
+type HooksConfig struct {
+	OnSessionEnd     string `mapstructure:"on_session_end" yaml:"on_session_end"`         // Run when a session ends
+	OnCommitCaptured string `mapstructure:"on_commit_captured" yaml:"on_commit_captured"` // Run when a commit is stored
+	OnReminderDue    string `mapstructure:"on_reminder_due" yaml:"on_reminder_due"`       // Run when a reminder comes due
+	OnAlertMatched   string `mapstructure:"on_alert_matched" yaml:"on_alert_matched"`     // Run when an alert matches captured content
+	OnSearchMatched  string `mapstructure:"on_search_matched" yaml:"on_search_matched"`   // Run when a search subscription matches a captured message
+	OnHabitNudge     string `mapstructure:"on_habit_nudge" yaml:"on_habit_nudge"`         // Run when a habit nudge is raised
+	TimeoutSeconds   int    `mapstructure:"timeout_seconds" yaml:"timeout_seconds"`       // Hooks running longer are killed (default: 30)
+	MaxConcurrency   int    `mapstructure:"max_concurrency" yaml:"max_concurrency"`       // Hooks running at once (default: 2)
 }
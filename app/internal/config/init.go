@@ -216,14 +216,70 @@ func CreateDefaultConfig() error {
 			BasePath:     "~/" + configDirName,
 			SessionsPath: "~/" + configDirName + "/sessions",
 			DatabasePath: "~/" + configDirName + "/clio.db",
+			AssetsPath:   "~/" + configDirName + "/assets",
+			BlobsPath:    "~/" + configDirName + "/blobs",
+
+			BlobThresholdBytes: defaultBlobThresholdBytes,
 		},
 		Cursor: CursorConfig{
-			LogPath:            "", // User must configure this explicitly
-			PollIntervalSeconds: 7, // Default polling interval: 7 seconds
+			LogPath:                   "",  // User must configure this explicitly
+			PollIntervalSeconds:       7,   // Default polling interval: 7 seconds
+			DebounceSeconds:           2,   // Wait for writes to settle for 2 seconds
+			QueueSize:                 100, // Up to 100 conversations waiting
+			MaxConcurrency:            1,   // Process one conversation at a time
+			MaxConversationsPerSecond: 5,   // Rate limit processing
+		},
+		Zed: ZedConfig{
+			Enabled:             false, // Opt-in
+			PollIntervalSeconds: 30,    // Check for changes every 30 seconds
+		},
+		JetBrains: JetBrainsConfig{
+			Enabled:             false, // Opt-in
+			PollIntervalSeconds: 60,    // Check for changes every minute
+		},
+		Heartbeats: HeartbeatsConfig{
+			PollIntervalSeconds: 10, // Check the heartbeat log every 10 seconds
+			TimeoutMinutes:      15, // WakaTime's default keystroke timeout
 		},
 		Session: SessionConfig{
 			InactivityTimeoutMinutes: 30,
 		},
+		Git: GitConfig{
+			PollIntervalSeconds:      30,  // Default polling interval: 30 seconds
+			MinCorrelationConfidence: 0.5, // Exclude weak commit-session links from exports
+			ErrorBudget:              5,   // Suspend polling after 5 failures...
+			ErrorBudgetWindowSeconds: 600, // ...within 10 minutes
+			CircuitCooldownSeconds:   900, // Suspend for 15 minutes
+			MinPollIntervalSeconds:   5,   // Adaptive polling bounds (when enabled)
+			MaxPollIntervalSeconds:   300,
+			MaxGapFillCommits:        500, // Ingest up to 500 commits per repository made while stopped
+			PostSessionWindowMinutes: 30,  // Attribute gap-filled commits to sessions ended up to 30 minutes before
+			ExtractWorkers:           4,   // Extract diffs of large commit backlogs 4 at a time
+		},
+		Standup: StandupConfig{
+			PhraseTimeoutSeconds: 60,
+		},
+		Summaries: SummariesConfig{
+			TimeoutSeconds: 120,
+		},
+		Retro: RetroConfig{
+			PolishTimeoutSeconds: 120,
+		},
+		Nudges: NudgesConfig{
+			UncommittedSessions: 3,
+			LongSessionMinutes:  180,
+			CooldownHours:       24,
+		},
+		CommitStyle: CommitStyleConfig{
+			Convention: "conventional",
+		},
+		Translation: TranslationConfig{
+			TargetLanguage: "en",
+			TimeoutSeconds: 60,
+		},
+		Search: SearchConfig{
+			Tokenizer: SearchTokenizerUnicode61,
+		},
 		Logging: LoggingConfig{
 			Level:      "info",
 			FilePath:   "~/" + configDirName + "/clio.log",
@@ -231,6 +287,10 @@ func CreateDefaultConfig() error {
 			MaxSize:    10,    // 10 MB
 			MaxBackups: 3,     // Keep 3 rotated files
 		},
+		Hooks: HooksConfig{
+			TimeoutSeconds: 30, // Kill hooks after 30 seconds
+			MaxConcurrency: 2,  // Run up to 2 hooks at once
+		},
 	}
 
 	// Ensure storage base path directory exists (we created ~/.clio/ but validation
@@ -6,6 +6,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
+	_ "time/tzdata" // Time zone names resolve on systems without a zoneinfo database
 
 	"github.com/spf13/viper"
 )
@@ -15,13 +17,17 @@ const (
 	configFileName = "config"
 	configFileType = "yaml"
 	envPrefix      = "CLIO"
+
+	// defaultBlobThresholdBytes is the default for storage.blob_threshold_bytes
+	defaultBlobThresholdBytes = 256 * 1024
 )
 
 // Load loads the configuration from file, environment variables, and defaults.
 // It returns a Config struct populated with values from these sources in order of precedence:
 // 1. Environment variables (CLIO_ prefix)
-// 2. Configuration file (~/.clio/config.yaml)
-// 3. Default values
+// 2. The active profile (CLIO_PROFILE) from the configuration file
+// 3. Configuration file (~/.clio/config.yaml)
+// 4. Default values
 // If the configuration file doesn't exist, it will be created automatically with default values.
 func Load() (*Config, error) {
 	// Ensure config file exists before loading (creates it with defaults if missing)
@@ -44,6 +50,13 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	// Overlay the active profile before defaults and path expansion apply to its values
+	if profile := ActiveProfile(); profile != "" {
+		if err := applyProfile(&cfg, profile); err != nil {
+			return nil, err
+		}
+	}
+
 	// Apply defaults for empty string values (Viper treats empty strings as set values)
 	applyDefaultsForEmptyValues(&cfg)
 
@@ -63,9 +76,24 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("configuration validation failed: %w", err)
 	}
 
+	// Local time is the reporting time zone, so days, weeks, and timestamps
+	// everywhere follow the configured one
+	applyTimezone(&cfg)
+
 	return &cfg, nil
 }
 
+// applyTimezone makes the configured time zone the local one. The zone carries
+// its DST rules, so local midnight stays the day boundary all year.
+func applyTimezone(cfg *Config) {
+	if cfg.Timezone == "" {
+		return
+	}
+	if loc, err := time.LoadLocation(cfg.Timezone); err == nil {
+		time.Local = loc
+	}
+}
+
 // initViper initializes Viper with configuration file path, environment variable prefix, and settings
 func initViper() error {
 	homeDir, err := os.UserHomeDir()
@@ -114,21 +142,62 @@ func setDefaults() {
 	// Blog repository - empty string by default
 	viper.SetDefault("blog_repository", "")
 
+	// Reporting time zone - the system zone by default
+	viper.SetDefault("timezone", "")
+
 	// Storage paths
 	viper.SetDefault("storage.base_path", filepath.Join(homeDir, configDirName))
 	viper.SetDefault("storage.sessions_path", filepath.Join(homeDir, configDirName, "sessions"))
 	viper.SetDefault("storage.database_path", filepath.Join(homeDir, configDirName, "clio.db"))
+	viper.SetDefault("storage.assets_path", filepath.Join(homeDir, configDirName, "assets"))
+	viper.SetDefault("storage.blobs_path", filepath.Join(homeDir, configDirName, "blobs"))
+	viper.SetDefault("storage.blob_threshold_bytes", defaultBlobThresholdBytes)
 
 	// Cursor log path - user must configure this explicitly
 	viper.SetDefault("cursor.log_path", "")
 	// Cursor polling interval - default 7 seconds
 	viper.SetDefault("cursor.poll_interval_seconds", 7)
+	// Cursor change debounce - default 2 seconds (0 disables)
+	viper.SetDefault("cursor.debounce_seconds", 2)
+	// Capture work queue limits
+	viper.SetDefault("cursor.queue_size", 100)
+	viper.SetDefault("cursor.max_concurrency", 1)
+	viper.SetDefault("cursor.max_conversations_per_second", 5)
+	// Raw payload archiving is opt-in (uses extra disk space)
+	viper.SetDefault("cursor.archive_raw_payloads", false)
+
+	// Zed capture is opt-in; empty paths are detected per platform
+	viper.SetDefault("zed.enabled", false)
+	viper.SetDefault("zed.conversations_path", "")
+	viper.SetDefault("zed.database_path", "")
+	viper.SetDefault("zed.poll_interval_seconds", 30)
+
+	// JetBrains capture is opt-in; an empty config path is detected per platform
+	viper.SetDefault("jetbrains.enabled", false)
+	viper.SetDefault("jetbrains.config_path", "")
+	viper.SetDefault("jetbrains.poll_interval_seconds", 60)
+
+	// Heartbeats are always accepted on the daemon API; tailing a log is opt-in
+	viper.SetDefault("heartbeats.log_path", "")
+	viper.SetDefault("heartbeats.poll_interval_seconds", 10)
+	viper.SetDefault("heartbeats.timeout_minutes", 15)
 
 	// Session configuration
 	viper.SetDefault("session.inactivity_timeout_minutes", 30)
 
 	// Git configuration
-	viper.SetDefault("git.poll_interval_seconds", 30) // Default 30 seconds
+	viper.SetDefault("git.poll_interval_seconds", 30)        // Default 30 seconds
+	viper.SetDefault("git.min_correlation_confidence", 0.5)  // Exclude weak commit-session links from exports
+	viper.SetDefault("git.error_budget", 5)                  // Failures tolerated per repository
+	viper.SetDefault("git.error_budget_window_seconds", 600) // Within 10 minutes
+	viper.SetDefault("git.circuit_cooldown_seconds", 900)    // Suspend polling for 15 minutes
+	viper.SetDefault("git.adaptive_polling", false)          // Fixed interval unless enabled
+	viper.SetDefault("git.min_poll_interval_seconds", 5)     // Fastest adaptive interval
+	viper.SetDefault("git.max_poll_interval_seconds", 300)   // Slowest adaptive interval
+	viper.SetDefault("git.batch_poll_results", false)        // Per-repository poll results
+	viper.SetDefault("git.max_gap_fill_commits", 500)        // Startup gap-fill bound per repository
+	viper.SetDefault("git.post_session_window_minutes", 30)  // post_session correlation window
+	viper.SetDefault("git.extract_workers", 4)               // Concurrent diff extraction for large backlogs
 
 	// Logging configuration
 	viper.SetDefault("logging.level", "info")
@@ -136,6 +205,38 @@ func setDefaults() {
 	viper.SetDefault("logging.console", false) // Default to false (daemon mode), CLI commands can override
 	viper.SetDefault("logging.max_size", 10)   // 10 MB
 	viper.SetDefault("logging.max_backups", 3) // Keep 3 rotated files
+
+	// Hooks configuration
+	viper.SetDefault("hooks.timeout_seconds", 30) // Kill hooks after 30 seconds
+	viper.SetDefault("hooks.max_concurrency", 2)  // Run up to 2 hooks at once
+
+	// Standup configuration
+	viper.SetDefault("standup.phrase_timeout_seconds", 60)
+
+	// Summaries configuration
+	viper.SetDefault("summaries.timeout_seconds", 120)
+
+	// Retro configuration
+	viper.SetDefault("retro.polish_timeout_seconds", 120)
+
+	// Habit nudges
+	viper.SetDefault("nudges.enabled", false)
+	viper.SetDefault("nudges.uncommitted_sessions", 3)
+	viper.SetDefault("nudges.long_session_minutes", 180)
+	viper.SetDefault("nudges.cooldown_hours", 24)
+
+	// Commit message convention
+	viper.SetDefault("commit_style.convention", "conventional")
+
+	// Translation configuration
+	viper.SetDefault("translation.target_language", "en")
+	viper.SetDefault("translation.timeout_seconds", 60)
+
+	// Search index configuration
+	viper.SetDefault("search.tokenizer", SearchTokenizerUnicode61)
+
+	// Sensitive content gate; no categories means messages are stored as captured
+	viper.SetDefault("sensitive.classifier_timeout_seconds", 10)
 }
 
 // loadConfig performs any additional loading logic after Viper is initialized
@@ -264,15 +365,122 @@ func applyDefaultsForEmptyValues(cfg *Config) {
 	}
 	// Console defaults to false, so we don't need to set it
 
+	// Apply blob store defaults if not set
+	if cfg.Storage.BlobsPath == "" {
+		cfg.Storage.BlobsPath = filepath.Join(homeDir, configDirName, "blobs")
+	}
+	if cfg.Storage.BlobThresholdBytes == 0 {
+		cfg.Storage.BlobThresholdBytes = defaultBlobThresholdBytes
+	}
+
 	// Apply cursor defaults if not set
 	if cfg.Cursor.PollIntervalSeconds == 0 {
 		cfg.Cursor.PollIntervalSeconds = 7
 	}
+	if cfg.Cursor.QueueSize == 0 {
+		cfg.Cursor.QueueSize = 100
+	}
+	if cfg.Cursor.MaxConcurrency == 0 {
+		cfg.Cursor.MaxConcurrency = 1
+	}
+
+	// Apply zed defaults if not set
+	if cfg.Zed.PollIntervalSeconds == 0 {
+		cfg.Zed.PollIntervalSeconds = 30
+	}
+
+	// Apply jetbrains defaults if not set
+	if cfg.JetBrains.PollIntervalSeconds == 0 {
+		cfg.JetBrains.PollIntervalSeconds = 60
+	}
+
+	// Apply heartbeats defaults if not set
+	if cfg.Heartbeats.PollIntervalSeconds == 0 {
+		cfg.Heartbeats.PollIntervalSeconds = 10
+	}
+	if cfg.Heartbeats.TimeoutMinutes == 0 {
+		cfg.Heartbeats.TimeoutMinutes = 15
+	}
 
 	// Apply git defaults if not set
 	if cfg.Git.PollIntervalSeconds == 0 {
 		cfg.Git.PollIntervalSeconds = 30
 	}
+	if cfg.Git.ErrorBudget == 0 {
+		cfg.Git.ErrorBudget = 5
+	}
+	if cfg.Git.ErrorBudgetWindowSeconds == 0 {
+		cfg.Git.ErrorBudgetWindowSeconds = 600
+	}
+	if cfg.Git.CircuitCooldownSeconds == 0 {
+		cfg.Git.CircuitCooldownSeconds = 900
+	}
+	if cfg.Git.MinPollIntervalSeconds == 0 {
+		cfg.Git.MinPollIntervalSeconds = 5
+	}
+	if cfg.Git.MaxPollIntervalSeconds == 0 {
+		cfg.Git.MaxPollIntervalSeconds = 300
+	}
+	if cfg.Git.MaxGapFillCommits == 0 {
+		cfg.Git.MaxGapFillCommits = 500
+	}
+	if cfg.Git.PostSessionWindowMinutes == 0 {
+		cfg.Git.PostSessionWindowMinutes = 30
+	}
+	if cfg.Git.ExtractWorkers == 0 {
+		cfg.Git.ExtractWorkers = 4
+	}
+
+	// Hooks defaults
+	if cfg.Hooks.TimeoutSeconds == 0 {
+		cfg.Hooks.TimeoutSeconds = 30
+	}
+	if cfg.Hooks.MaxConcurrency == 0 {
+		cfg.Hooks.MaxConcurrency = 2
+	}
+
+	// Standup defaults
+	if cfg.Standup.PhraseTimeoutSeconds == 0 {
+		cfg.Standup.PhraseTimeoutSeconds = 60
+	}
+
+	// Summaries defaults
+	if cfg.Summaries.TimeoutSeconds == 0 {
+		cfg.Summaries.TimeoutSeconds = 120
+	}
+
+	// Retro defaults
+	if cfg.Retro.PolishTimeoutSeconds == 0 {
+		cfg.Retro.PolishTimeoutSeconds = 120
+	}
+
+	// Nudge defaults; the thresholds are left alone since 0 turns a nudge off
+	if cfg.Nudges.CooldownHours == 0 {
+		cfg.Nudges.CooldownHours = 24
+	}
+
+	// Commit style defaults
+	if cfg.CommitStyle.Convention == "" {
+		cfg.CommitStyle.Convention = "conventional"
+	}
+
+	// Translation defaults
+	if cfg.Translation.TargetLanguage == "" {
+		cfg.Translation.TargetLanguage = "en"
+	}
+	if cfg.Translation.TimeoutSeconds == 0 {
+		cfg.Translation.TimeoutSeconds = 60
+	}
+
+	// Search defaults
+	if cfg.Search.Tokenizer == "" {
+		cfg.Search.Tokenizer = SearchTokenizerUnicode61
+	}
+
+	// Sensitive gate defaults
+	if cfg.Sensitive.ClassifierTimeoutSeconds == 0 {
+		cfg.Sensitive.ClassifierTimeoutSeconds = 10
+	}
 }
 
 // expandConfigPaths expands all ~ paths in the configuration struct
@@ -284,13 +492,46 @@ func expandConfigPaths(cfg *Config) {
 	cfg.Storage.BasePath = expandHomeDir(cfg.Storage.BasePath)
 	cfg.Storage.SessionsPath = expandHomeDir(cfg.Storage.SessionsPath)
 	cfg.Storage.DatabasePath = expandHomeDir(cfg.Storage.DatabasePath)
+	cfg.Storage.AssetsPath = expandHomeDir(cfg.Storage.AssetsPath)
+	cfg.Storage.BlobsPath = expandHomeDir(cfg.Storage.BlobsPath)
 
 	// Expand cursor log path
 	cfg.Cursor.LogPath = expandHomeDir(cfg.Cursor.LogPath)
 
+	// Expand zed paths
+	cfg.Zed.ConversationsPath = expandHomeDir(cfg.Zed.ConversationsPath)
+	cfg.Zed.DatabasePath = expandHomeDir(cfg.Zed.DatabasePath)
+
+	// Expand jetbrains config path
+	cfg.JetBrains.ConfigPath = expandHomeDir(cfg.JetBrains.ConfigPath)
+
+	// Expand heartbeats log path
+	cfg.Heartbeats.LogPath = expandHomeDir(cfg.Heartbeats.LogPath)
+
 	// Expand logging file path
 	cfg.Logging.FilePath = expandHomeDir(cfg.Logging.FilePath)
 
+	// Expand hook executable paths
+	cfg.Hooks.OnSessionEnd = expandHomeDir(cfg.Hooks.OnSessionEnd)
+	cfg.Hooks.OnCommitCaptured = expandHomeDir(cfg.Hooks.OnCommitCaptured)
+	cfg.Hooks.OnReminderDue = expandHomeDir(cfg.Hooks.OnReminderDue)
+	cfg.Hooks.OnAlertMatched = expandHomeDir(cfg.Hooks.OnAlertMatched)
+	cfg.Hooks.OnSearchMatched = expandHomeDir(cfg.Hooks.OnSearchMatched)
+	cfg.Hooks.OnHabitNudge = expandHomeDir(cfg.Hooks.OnHabitNudge)
+
+	// Expand standup template and phrase command paths
+	for team, path := range cfg.Standup.Templates {
+		cfg.Standup.Templates[team] = expandHomeDir(path)
+	}
+	cfg.Standup.PhraseCommand = expandHomeDir(cfg.Standup.PhraseCommand)
+
+	// Expand summary command path
+	cfg.Summaries.Command = expandHomeDir(cfg.Summaries.Command)
+	cfg.Retro.PolishCommand = expandHomeDir(cfg.Retro.PolishCommand)
+	cfg.Translation.Command = expandHomeDir(cfg.Translation.Command)
+	cfg.Scrub.NamesFile = expandHomeDir(cfg.Scrub.NamesFile)
+	cfg.Sensitive.ClassifierCommand = expandHomeDir(cfg.Sensitive.ClassifierCommand)
+
 	// Expand watched directories paths
 	for i, dir := range cfg.WatchedDirectories {
 		cfg.WatchedDirectories[i] = expandHomeDir(dir)
@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -193,3 +194,113 @@ func TestExpandHomeDir(t *testing.T) {
 		})
 	}
 }
+
+func TestLoad_WithProfile(t *testing.T) {
+	resetViper()
+	defer resetViper()
+
+	homeDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+	t.Setenv("CLIO_CURSOR_LOG_PATH", t.TempDir())
+
+	workBlog := filepath.Join(homeDir, "work-blog")
+	personalBlog := filepath.Join(homeDir, "personal-blog")
+	for _, dir := range []string{workBlog, personalBlog, filepath.Join(homeDir, configDirName)} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("failed to create %s: %v", dir, err)
+		}
+	}
+	// Watched directories under /tmp are rejected, so the profile overlay is checked through the blog repository
+	configYAML := `blog_repository: ~/personal-blog
+profiles:
+  work:
+    blog_repository: ~/work-blog
+`
+	configPath := filepath.Join(homeDir, configDirName, configFileName+"."+configFileType)
+	if err := os.WriteFile(configPath, []byte(configYAML), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	t.Setenv(ProfileEnvVar, "Work")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.Profile != "work" {
+		t.Errorf("Profile = %q, want work", cfg.Profile)
+	}
+	if cfg.BlogRepository != workBlog {
+		t.Errorf("BlogRepository = %q, want %q", cfg.BlogRepository, workBlog)
+	}
+	wantDB := filepath.Join(homeDir, configDirName, profilesDirName, "work", "clio.db")
+	if cfg.Storage.DatabasePath != wantDB {
+		t.Errorf("DatabasePath = %q, want %q", cfg.Storage.DatabasePath, wantDB)
+	}
+	if want := filepath.Join(homeDir, configDirName, profilesDirName, "work", "clio.log"); cfg.Logging.FilePath != want {
+		t.Errorf("Logging.FilePath = %q, want %q", cfg.Logging.FilePath, want)
+	}
+
+	// Edits under a profile are saved to the profile, not the top level
+	cfg.BlogRepository = homeDir
+	if err := Save(cfg); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	resetViper()
+	t.Setenv(ProfileEnvVar, "")
+	base, err := Load()
+	if err != nil {
+		t.Fatalf("Load() without profile failed: %v", err)
+	}
+	if base.BlogRepository != personalBlog {
+		t.Errorf("top-level BlogRepository = %q, want %q", base.BlogRepository, personalBlog)
+	}
+	if got := base.Profiles["work"].BlogRepository; got != "~" {
+		t.Errorf("work profile BlogRepository = %q, want the edited value", got)
+	}
+	if base.Storage.DatabasePath != filepath.Join(homeDir, configDirName, "clio.db") {
+		t.Errorf("top-level DatabasePath = %q, want the default", base.Storage.DatabasePath)
+	}
+
+	resetViper()
+	t.Setenv(ProfileEnvVar, "missing")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "available: work") {
+		t.Errorf("Load() with unknown profile error = %v, want list of profiles", err)
+	}
+}
+
+func TestLoad_WithTimezone(t *testing.T) {
+	resetViper()
+	local := time.Local
+	defer func() {
+		time.Local = local
+		resetViper()
+	}()
+
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("CLIO_CURSOR_LOG_PATH", t.TempDir())
+	t.Setenv("CLIO_TIMEZONE", "America/New_York")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.Timezone != "America/New_York" {
+		t.Errorf("Timezone = %q, want America/New_York", cfg.Timezone)
+	}
+	if time.Local.String() != "America/New_York" {
+		t.Errorf("time.Local = %s, want America/New_York", time.Local)
+	}
+
+	// Days still start at local midnight across the DST change
+	day := time.Date(2026, 3, 8, 0, 0, 0, 0, time.Local)
+	if next := day.AddDate(0, 0, 1); next.Hour() != 0 || next.Sub(day) != 23*time.Hour {
+		t.Errorf("day after %s = %s, want local midnight 23 hours later", day, next)
+	}
+
+	resetViper()
+	t.Setenv("CLIO_TIMEZONE", "Mars/Olympus")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "timezone") {
+		t.Errorf("Load() with an unknown time zone error = %v, want a timezone error", err)
+	}
+}
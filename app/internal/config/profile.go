@@ -0,0 +1,165 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// ProfileEnvVar selects the active profile; the --profile flag sets it so daemons inherit the choice
+	ProfileEnvVar = "CLIO_PROFILE"
+	// profilesDirName holds per-profile data under the config directory
+	profilesDirName = "profiles"
+)
+
+// profileNamePattern restricts profile names to characters safe in file names
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// profileBase holds the top-level values a profile replaced, so Save writes
+// profile edits back to the profile instead of the top level
+type profileBase struct {
+	watchedDirectories []string
+	blogRepository     string
+	storage            StorageConfig
+	logFilePath        string
+}
+
+// ActiveProfile returns the profile selected by CLIO_PROFILE, or "" for the default configuration
+func ActiveProfile() string {
+	return strings.ToLower(strings.TrimSpace(os.Getenv(ProfileEnvVar)))
+}
+
+// SetActiveProfile selects a profile for this process and any daemon it starts
+func SetActiveProfile(name string) error {
+	if err := ValidateProfileName(name); err != nil {
+		return err
+	}
+	return os.Setenv(ProfileEnvVar, name)
+}
+
+// ValidateProfileName checks that a profile name is safe to use in file names
+func ValidateProfileName(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q (use letters, digits, '-' and '_')", name)
+	}
+	return nil
+}
+
+// ProfileDir returns the default data directory for a profile, ~/.clio/profiles/<name>
+func ProfileDir(name string) (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(homeDir, configDirName, profilesDirName, name), nil
+}
+
+// ProfileNames returns the configured profile names in sorted order
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// applyProfile overlays the named profile onto the top-level configuration.
+// Storage paths and the log file the profile leaves unset default to its own
+// directory so profiles never share a database.
+func applyProfile(cfg *Config, name string) error {
+	if err := ValidateProfileName(name); err != nil {
+		return err
+	}
+	// Viper lowercases map keys, so profile names are case-insensitive
+	name = strings.ToLower(name)
+	profile, ok := cfg.Profiles[name]
+	if !ok {
+		available := "none defined"
+		if names := cfg.ProfileNames(); len(names) > 0 {
+			available = "available: " + strings.Join(names, ", ")
+		}
+		return fmt.Errorf("profile %q not found in config (%s)", name, available)
+	}
+
+	dir, err := ProfileDir(name)
+	if err != nil {
+		return err
+	}
+
+	cfg.profileBase = &profileBase{
+		watchedDirectories: cfg.WatchedDirectories,
+		blogRepository:     cfg.BlogRepository,
+		storage:            cfg.Storage,
+		logFilePath:        cfg.Logging.FilePath,
+	}
+	cfg.Profile = name
+
+	if profile.WatchedDirectories != nil {
+		cfg.WatchedDirectories = append([]string{}, profile.WatchedDirectories...)
+	}
+	if profile.BlogRepository != "" {
+		cfg.BlogRepository = profile.BlogRepository
+	}
+
+	threshold := cfg.Storage.BlobThresholdBytes
+	cfg.Storage = profile.Storage
+	if cfg.Storage.BasePath == "" {
+		cfg.Storage.BasePath = dir
+	}
+	if cfg.Storage.SessionsPath == "" {
+		cfg.Storage.SessionsPath = filepath.Join(cfg.Storage.BasePath, "sessions")
+	}
+	if cfg.Storage.DatabasePath == "" {
+		cfg.Storage.DatabasePath = filepath.Join(cfg.Storage.BasePath, "clio.db")
+	}
+	if cfg.Storage.AssetsPath == "" {
+		cfg.Storage.AssetsPath = filepath.Join(cfg.Storage.BasePath, "assets")
+	}
+	if cfg.Storage.BlobsPath == "" {
+		cfg.Storage.BlobsPath = filepath.Join(cfg.Storage.BasePath, "blobs")
+	}
+	if cfg.Storage.BlobThresholdBytes == 0 {
+		cfg.Storage.BlobThresholdBytes = threshold
+	}
+
+	cfg.Logging.FilePath = profile.LogFilePath
+	if cfg.Logging.FilePath == "" {
+		cfg.Logging.FilePath = filepath.Join(cfg.Storage.BasePath, "clio.log")
+	}
+
+	return nil
+}
+
+// unapplyProfile returns a copy of cfg with the active profile's editable
+// settings moved back into its profile entry and the top level restored
+func unapplyProfile(cfg *Config) *Config {
+	if cfg.Profile == "" || cfg.profileBase == nil {
+		return cfg
+	}
+
+	result := *cfg
+	result.Profiles = make(map[string]ProfileConfig, len(cfg.Profiles))
+	for name, profile := range cfg.Profiles {
+		result.Profiles[name] = profile
+	}
+
+	profile := result.Profiles[cfg.Profile]
+	profile.WatchedDirectories = cfg.WatchedDirectories
+	if cfg.BlogRepository != cfg.profileBase.blogRepository {
+		profile.BlogRepository = cfg.BlogRepository
+	}
+	result.Profiles[cfg.Profile] = profile
+
+	result.WatchedDirectories = cfg.profileBase.watchedDirectories
+	result.BlogRepository = cfg.profileBase.blogRepository
+	result.Storage = cfg.profileBase.storage
+	result.Logging.FilePath = cfg.profileBase.logFilePath
+	result.Profile = ""
+	result.profileBase = nil
+	return &result
+}
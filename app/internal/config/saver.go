@@ -51,8 +51,9 @@ func Save(cfg *Config) error {
 	// Use resolved path for config file
 	configPath := filepath.Join(resolvedConfigDir, configFileName+"."+configFileType)
 
-	// Create a copy of config with paths converted to ~ format for readability
-	saveCfg := convertPathsToTilde(cfg, homeDir)
+	// Create a copy of config with paths converted to ~ format for readability.
+	// Edits made under a profile are saved to that profile.
+	saveCfg := convertPathsToTilde(unapplyProfile(cfg), homeDir)
 
 	// Marshal config to YAML
 	data, err := yaml.Marshal(saveCfg)
@@ -71,19 +72,90 @@ func Save(cfg *Config) error {
 // convertPathsToTilde creates a copy of the config with absolute paths
 // converted to ~ format if they're within the user's home directory
 func convertPathsToTilde(cfg *Config, homeDir string) *Config {
+	// Copy whole sections so non-path settings are preserved
+	cursor := cfg.Cursor
+	cursor.LogPath = convertPathToTilde(cfg.Cursor.LogPath, homeDir)
+	zed := cfg.Zed
+	zed.ConversationsPath = convertPathToTilde(cfg.Zed.ConversationsPath, homeDir)
+	zed.DatabasePath = convertPathToTilde(cfg.Zed.DatabasePath, homeDir)
+	jetBrains := cfg.JetBrains
+	jetBrains.ConfigPath = convertPathToTilde(cfg.JetBrains.ConfigPath, homeDir)
+	heartbeats := cfg.Heartbeats
+	heartbeats.LogPath = convertPathToTilde(cfg.Heartbeats.LogPath, homeDir)
+	logging := cfg.Logging
+	logging.FilePath = convertPathToTilde(cfg.Logging.FilePath, homeDir)
+	hooks := cfg.Hooks
+	hooks.OnSessionEnd = convertPathToTilde(cfg.Hooks.OnSessionEnd, homeDir)
+	hooks.OnCommitCaptured = convertPathToTilde(cfg.Hooks.OnCommitCaptured, homeDir)
+	hooks.OnReminderDue = convertPathToTilde(cfg.Hooks.OnReminderDue, homeDir)
+	hooks.OnAlertMatched = convertPathToTilde(cfg.Hooks.OnAlertMatched, homeDir)
+	hooks.OnSearchMatched = convertPathToTilde(cfg.Hooks.OnSearchMatched, homeDir)
+	hooks.OnHabitNudge = convertPathToTilde(cfg.Hooks.OnHabitNudge, homeDir)
+	standup := cfg.Standup
+	standup.PhraseCommand = convertPathToTilde(cfg.Standup.PhraseCommand, homeDir)
+	if len(cfg.Standup.Templates) > 0 {
+		standup.Templates = make(map[string]string, len(cfg.Standup.Templates))
+		for team, path := range cfg.Standup.Templates {
+			standup.Templates[team] = convertPathToTilde(path, homeDir)
+		}
+	}
+
+	summaries := cfg.Summaries
+	summaries.Command = convertPathToTilde(cfg.Summaries.Command, homeDir)
+
+	retro := cfg.Retro
+	retro.PolishCommand = convertPathToTilde(cfg.Retro.PolishCommand, homeDir)
+
+	translation := cfg.Translation
+	translation.Command = convertPathToTilde(cfg.Translation.Command, homeDir)
+
+	scrub := cfg.Scrub
+	scrub.NamesFile = convertPathToTilde(cfg.Scrub.NamesFile, homeDir)
+
+	sensitive := cfg.Sensitive
+	sensitive.ClassifierCommand = convertPathToTilde(cfg.Sensitive.ClassifierCommand, homeDir)
+
 	// Create a copy to avoid modifying the original
 	result := &Config{
 		WatchedDirectories: make([]string, len(cfg.WatchedDirectories)),
 		BlogRepository:     convertPathToTilde(cfg.BlogRepository, homeDir),
+		Timezone:           cfg.Timezone,
 		Storage: StorageConfig{
 			BasePath:     convertPathToTilde(cfg.Storage.BasePath, homeDir),
 			SessionsPath: convertPathToTilde(cfg.Storage.SessionsPath, homeDir),
 			DatabasePath: convertPathToTilde(cfg.Storage.DatabasePath, homeDir),
+			AssetsPath:   convertPathToTilde(cfg.Storage.AssetsPath, homeDir),
+			BlobsPath:    convertPathToTilde(cfg.Storage.BlobsPath, homeDir),
+
+			BlobThresholdBytes: cfg.Storage.BlobThresholdBytes,
 		},
-		Cursor: CursorConfig{
-			LogPath: convertPathToTilde(cfg.Cursor.LogPath, homeDir),
-		},
-		Session: cfg.Session,
+		Cursor:     cursor,
+		Zed:        zed,
+		JetBrains:  jetBrains,
+		Heartbeats: heartbeats,
+		Session:    cfg.Session,
+		Logging:    logging,
+		Git:        cfg.Git,
+		Webhooks:   cfg.Webhooks,
+		Hooks:      hooks,
+		Standup:    standup,
+		Summaries:  summaries,
+		Retro:      retro,
+		Nudges:     cfg.Nudges,
+		Sensitive:  sensitive,
+		Team:       cfg.Team,
+		Share:      cfg.Share,
+		Search:     cfg.Search,
+		Redaction:  cfg.Redaction,
+		Filters:    cfg.Filters,
+		Alerts:     cfg.Alerts,
+		TagRules:   cfg.TagRules,
+
+		RemoteStorage: cfg.RemoteStorage,
+		Issues:        cfg.Issues,
+		Translation:   translation,
+		Scrub:         scrub,
+		CommitStyle:   cfg.CommitStyle,
 	}
 
 	// Convert watched directories paths
@@ -91,6 +163,33 @@ func convertPathsToTilde(cfg *Config, homeDir string) *Config {
 		result.WatchedDirectories[i] = convertPathToTilde(dir, homeDir)
 	}
 
+	// Convert profile paths
+	if len(cfg.Profiles) > 0 {
+		result.Profiles = make(map[string]ProfileConfig, len(cfg.Profiles))
+		for name, profile := range cfg.Profiles {
+			converted := ProfileConfig{
+				BlogRepository: convertPathToTilde(profile.BlogRepository, homeDir),
+				Storage: StorageConfig{
+					BasePath:     convertPathToTilde(profile.Storage.BasePath, homeDir),
+					SessionsPath: convertPathToTilde(profile.Storage.SessionsPath, homeDir),
+					DatabasePath: convertPathToTilde(profile.Storage.DatabasePath, homeDir),
+					AssetsPath:   convertPathToTilde(profile.Storage.AssetsPath, homeDir),
+					BlobsPath:    convertPathToTilde(profile.Storage.BlobsPath, homeDir),
+
+					BlobThresholdBytes: profile.Storage.BlobThresholdBytes,
+				},
+				LogFilePath: convertPathToTilde(profile.LogFilePath, homeDir),
+			}
+			if profile.WatchedDirectories != nil {
+				converted.WatchedDirectories = make([]string, len(profile.WatchedDirectories))
+				for i, dir := range profile.WatchedDirectories {
+					converted.WatchedDirectories[i] = convertPathToTilde(dir, homeDir)
+				}
+			}
+			result.Profiles[name] = converted
+		}
+	}
+
 	return result
 }
 
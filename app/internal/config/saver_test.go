@@ -0,0 +1,54 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestConvertPathsToTilde_KeepsSectionSettings(t *testing.T) {
+	homeDir := filepath.Join(t.TempDir(), "home")
+	cfg := &Config{
+		Cursor: CursorConfig{
+			LogPath:             filepath.Join(homeDir, ".cursor", "logs"),
+			PollIntervalSeconds: 7,
+			DebounceSeconds:     3,
+			QueueSize:           50,
+			MaxConcurrency:      2,
+			ArchiveRawPayloads:  true,
+		},
+		Logging: LoggingConfig{
+			Level:      "debug",
+			FilePath:   filepath.Join(homeDir, ".clio", "clio.log"),
+			Console:    true,
+			MaxSize:    20,
+			MaxBackups: 5,
+		},
+		Git: GitConfig{
+			PollIntervalSeconds:      45,
+			MinCorrelationConfidence: 0.7,
+			AdaptivePolling:          true,
+		},
+	}
+
+	saved := convertPathsToTilde(cfg, homeDir)
+
+	// Paths are shortened; every other setting in the sections survives the save
+	wantCursor := cfg.Cursor
+	wantCursor.LogPath = "~/.cursor/logs"
+	if saved.Cursor != wantCursor {
+		t.Errorf("Cursor = %+v, want %+v", saved.Cursor, wantCursor)
+	}
+	wantLogging := cfg.Logging
+	wantLogging.FilePath = "~/.clio/clio.log"
+	if saved.Logging != wantLogging {
+		t.Errorf("Logging = %+v, want %+v", saved.Logging, wantLogging)
+	}
+	if saved.Git != cfg.Git {
+		t.Errorf("Git = %+v, want %+v", saved.Git, cfg.Git)
+	}
+
+	// The original config is left untouched
+	if cfg.Cursor.LogPath != filepath.Join(homeDir, ".cursor", "logs") {
+		t.Errorf("original Cursor.LogPath changed to %q", cfg.Cursor.LogPath)
+	}
+}
@@ -2,13 +2,50 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 	"time"
 	"unicode"
+
+	"github.com/stwalsh4118/clio/internal/filters"
+	"github.com/stwalsh4118/clio/internal/search/stopwords"
+	"github.com/stwalsh4118/clio/internal/secrets"
+)
+
+const (
+	// maxCaptureConcurrency is the upper bound for cursor.max_concurrency
+	maxCaptureConcurrency = 8
+	// maxHookConcurrency is the upper bound for hooks.max_concurrency
+	maxHookConcurrency = 8
+	// minBlobThresholdBytes is the lower bound for storage.blob_threshold_bytes,
+	// matching the preview length kept in rows (blobs.PreviewLength)
+	minBlobThresholdBytes = 4096
 )
 
+// webhookEventTypes are the event types webhooks can subscribe to
+var webhookEventTypes = map[string]bool{
+	"session.ended":   true,
+	"commit.captured": true,
+	"reminder.due":    true,
+	"alert.matched":   true,
+	"search.matched":  true,
+	"habit.nudge":     true,
+}
+
+// alertSources are the captured content alerts can watch
+var alertSources = map[string]bool{
+	"messages": true,
+	"diffs":    true,
+}
+
+// tagRulePattern matches valid goal tags, as goals.Add accepts them
+var tagRulePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
 // ValidatePath validates that a path exists and is a directory.
 // It expands home directory paths (~) before validation and checks for security issues.
 // Returns an error with a helpful message if validation fails.
@@ -272,6 +309,24 @@ func ValidateStoragePaths(storage StorageConfig) error {
 		}
 	}
 
+	// Validate assets path (created when the first file is attached)
+	if storage.AssetsPath != "" {
+		if err := validatePathStructure(expandHomeDir(storage.AssetsPath)); err != nil {
+			return fmt.Errorf("storage assets path is invalid: %w", err)
+		}
+	}
+
+	// Validate blobs path (created when the first value is moved)
+	if storage.BlobsPath != "" {
+		if err := validatePathStructure(expandHomeDir(storage.BlobsPath)); err != nil {
+			return fmt.Errorf("storage blobs path is invalid: %w", err)
+		}
+	}
+	// The threshold can't be below the preview kept in rows, or previews would be moved again
+	if storage.BlobThresholdBytes != 0 && storage.BlobThresholdBytes < minBlobThresholdBytes {
+		return fmt.Errorf("blob threshold must be >= %d bytes, got: %d", minBlobThresholdBytes, storage.BlobThresholdBytes)
+	}
+
 	// Validate database path (must be valid if provided)
 	if storage.DatabasePath != "" {
 		expandedDatabasePath := expandHomeDir(storage.DatabasePath)
@@ -351,6 +406,636 @@ func ValidateCursorConfig(cursor CursorConfig) error {
 		return fmt.Errorf("poll interval must be >= 1 second, got: %d", cursor.PollIntervalSeconds)
 	}
 
+	// Validate debounce window (0 disables debouncing)
+	if cursor.DebounceSeconds < 0 {
+		return fmt.Errorf("debounce must be >= 0 seconds, got: %d", cursor.DebounceSeconds)
+	}
+
+	// Validate work queue limits
+	if cursor.QueueSize < 1 {
+		return fmt.Errorf("queue size must be >= 1, got: %d", cursor.QueueSize)
+	}
+	if cursor.MaxConcurrency < 1 || cursor.MaxConcurrency > maxCaptureConcurrency {
+		return fmt.Errorf("max concurrency must be between 1 and %d, got: %d", maxCaptureConcurrency, cursor.MaxConcurrency)
+	}
+	if cursor.MaxConversationsPerSecond < 0 {
+		return fmt.Errorf("max conversations per second must be >= 0, got: %d", cursor.MaxConversationsPerSecond)
+	}
+
+	return nil
+}
+
+// ValidateGitConfig validates git configuration including correlation thresholds.
+func ValidateGitConfig(git GitConfig) error {
+	if git.MinCorrelationConfidence < 0 || git.MinCorrelationConfidence > 1 {
+		return fmt.Errorf("min correlation confidence must be between 0 and 1, got: %v", git.MinCorrelationConfidence)
+	}
+
+	// Validate circuit breaker limits
+	if git.ErrorBudget < 1 {
+		return fmt.Errorf("error budget must be >= 1, got: %d", git.ErrorBudget)
+	}
+	if git.ErrorBudgetWindowSeconds < 1 {
+		return fmt.Errorf("error budget window must be >= 1 second, got: %d", git.ErrorBudgetWindowSeconds)
+	}
+	if git.CircuitCooldownSeconds < 1 {
+		return fmt.Errorf("circuit cooldown must be >= 1 second, got: %d", git.CircuitCooldownSeconds)
+	}
+
+	if git.MaxGapFillCommits < 1 {
+		return fmt.Errorf("max gap fill commits must be >= 1, got: %d", git.MaxGapFillCommits)
+	}
+	if git.PostSessionWindowMinutes < 1 {
+		return fmt.Errorf("post session window must be >= 1 minute, got: %d", git.PostSessionWindowMinutes)
+	}
+	if git.ExtractWorkers < 1 {
+		return fmt.Errorf("extract workers must be >= 1, got: %d", git.ExtractWorkers)
+	}
+
+	// Validate adaptive polling bounds
+	if git.AdaptivePolling {
+		if git.MinPollIntervalSeconds < 1 {
+			return fmt.Errorf("min poll interval must be >= 1 second, got: %d", git.MinPollIntervalSeconds)
+		}
+		if git.MaxPollIntervalSeconds < git.MinPollIntervalSeconds {
+			return fmt.Errorf("max poll interval (%d) must be >= min poll interval (%d)", git.MaxPollIntervalSeconds, git.MinPollIntervalSeconds)
+		}
+	}
+
+	return nil
+}
+
+// ValidateWebhooks validates that each webhook has an http(s) URL and known event types
+func ValidateWebhooks(webhooks []WebhookConfig) error {
+	for i, webhook := range webhooks {
+		// Secret references are resolved when the daemon starts; only their names can be checked here
+		if secrets.IsReference(webhook.URL) {
+			if err := secrets.ValidateName(strings.TrimPrefix(webhook.URL, secrets.ReferencePrefix)); err != nil {
+				return fmt.Errorf("webhook %d: url: %w", i+1, err)
+			}
+		} else if parsed, err := url.Parse(webhook.URL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("webhook %d: url must be an http or https URL or a secret reference, got: %q", i+1, webhook.URL)
+		}
+		if secrets.IsReference(webhook.Secret) {
+			if err := secrets.ValidateName(strings.TrimPrefix(webhook.Secret, secrets.ReferencePrefix)); err != nil {
+				return fmt.Errorf("webhook %d: secret: %w", i+1, err)
+			}
+		}
+		if len(webhook.Events) == 0 {
+			return fmt.Errorf("webhook %d: at least one event type is required", i+1)
+		}
+		for _, event := range webhook.Events {
+			if !webhookEventTypes[event] {
+				return fmt.Errorf("webhook %d: unknown event type %q (valid: session.ended, commit.captured, reminder.due, alert.matched, search.matched, habit.nudge)", i+1, event)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ValidateHooksConfig validates that configured hooks are executable files and limits are in range
+func ValidateHooksConfig(hooks HooksConfig) error {
+	for name, path := range map[string]string{
+		"on_session_end":     hooks.OnSessionEnd,
+		"on_commit_captured": hooks.OnCommitCaptured,
+		"on_reminder_due":    hooks.OnReminderDue,
+		"on_alert_matched":   hooks.OnAlertMatched,
+		"on_search_matched":  hooks.OnSearchMatched,
+		"on_habit_nudge":     hooks.OnHabitNudge,
+	} {
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+		if info.IsDir() || info.Mode().Perm()&0111 == 0 {
+			return fmt.Errorf("%s: %s is not an executable file", name, path)
+		}
+	}
+
+	if hooks.TimeoutSeconds < 1 {
+		return fmt.Errorf("timeout must be >= 1 second, got: %d", hooks.TimeoutSeconds)
+	}
+	if hooks.MaxConcurrency < 1 || hooks.MaxConcurrency > maxHookConcurrency {
+		return fmt.Errorf("max concurrency must be between 1 and %d, got: %d", maxHookConcurrency, hooks.MaxConcurrency)
+	}
+
+	return nil
+}
+
+// ValidateStandupConfig validates that standup templates exist, the default team has
+// a template, and the phrase command is an executable file
+func ValidateStandupConfig(standup StandupConfig) error {
+	for team, path := range standup.Templates {
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("template for team %q: %v", team, err)
+		}
+		if info.IsDir() {
+			return fmt.Errorf("template for team %q: %s is a directory", team, path)
+		}
+	}
+	if standup.Team != "" {
+		if _, ok := standup.Templates[standup.Team]; !ok {
+			return fmt.Errorf("team %q has no template", standup.Team)
+		}
+	}
+
+	if standup.PhraseCommand != "" {
+		info, err := os.Stat(standup.PhraseCommand)
+		if err != nil {
+			return fmt.Errorf("phrase_command: %v", err)
+		}
+		if info.IsDir() || info.Mode().Perm()&0111 == 0 {
+			return fmt.Errorf("phrase_command: %s is not an executable file", standup.PhraseCommand)
+		}
+	}
+	if standup.PhraseTimeoutSeconds < 1 {
+		return fmt.Errorf("phrase timeout must be >= 1 second, got: %d", standup.PhraseTimeoutSeconds)
+	}
+
+	return nil
+}
+
+// ValidateNudgesConfig validates that nudge thresholds aren't negative and the cooldown is positive
+func ValidateNudgesConfig(nudges NudgesConfig) error {
+	if nudges.UncommittedSessions < 0 {
+		return fmt.Errorf("uncommitted sessions must be >= 0, got: %d", nudges.UncommittedSessions)
+	}
+	if nudges.LongSessionMinutes < 0 {
+		return fmt.Errorf("long session minutes must be >= 0, got: %d", nudges.LongSessionMinutes)
+	}
+	if nudges.CooldownHours < 1 {
+		return fmt.Errorf("cooldown must be >= 1 hour, got: %d", nudges.CooldownHours)
+	}
+
+	return nil
+}
+
+// ValidateRetroConfig validates that the polish command is an executable file
+func ValidateRetroConfig(retro RetroConfig) error {
+	if retro.PolishCommand != "" {
+		info, err := os.Stat(retro.PolishCommand)
+		if err != nil {
+			return fmt.Errorf("polish_command: %v", err)
+		}
+		if info.IsDir() || info.Mode().Perm()&0111 == 0 {
+			return fmt.Errorf("polish_command: %s is not an executable file", retro.PolishCommand)
+		}
+	}
+	if retro.PolishTimeoutSeconds < 1 {
+		return fmt.Errorf("polish timeout must be >= 1 second, got: %d", retro.PolishTimeoutSeconds)
+	}
+
+	return nil
+}
+
+// ValidateSummariesConfig validates that the summary command is an executable file
+func ValidateSummariesConfig(summaries SummariesConfig) error {
+	if summaries.Command != "" {
+		info, err := os.Stat(summaries.Command)
+		if err != nil {
+			return fmt.Errorf("command: %v", err)
+		}
+		if info.IsDir() || info.Mode().Perm()&0111 == 0 {
+			return fmt.Errorf("command: %s is not an executable file", summaries.Command)
+		}
+	}
+	if summaries.TimeoutSeconds < 1 {
+		return fmt.Errorf("timeout must be >= 1 second, got: %d", summaries.TimeoutSeconds)
+	}
+	return nil
+}
+
+// languageCodePattern matches ISO 639-1 language codes
+var languageCodePattern = regexp.MustCompile(`^[a-z]{2}$`)
+
+// ValidateTranslationConfig validates that the translation command is an
+// executable file and the target language is an ISO 639-1 code
+func ValidateTranslationConfig(translation TranslationConfig) error {
+	if translation.Command != "" {
+		info, err := os.Stat(translation.Command)
+		if err != nil {
+			return fmt.Errorf("command: %v", err)
+		}
+		if info.IsDir() || info.Mode().Perm()&0111 == 0 {
+			return fmt.Errorf("command: %s is not an executable file", translation.Command)
+		}
+	}
+	if !languageCodePattern.MatchString(translation.TargetLanguage) {
+		return fmt.Errorf("target_language must be a two-letter ISO 639-1 code such as en, got: %q", translation.TargetLanguage)
+	}
+	if translation.TimeoutSeconds < 1 {
+		return fmt.Errorf("timeout must be >= 1 second, got: %d", translation.TimeoutSeconds)
+	}
+	return nil
+}
+
+// sensitiveActions are what the sensitive gate can do with matching content
+var sensitiveActions = map[string]bool{
+	"store":  true,
+	"redact": true,
+	"drop":   true,
+}
+
+// ValidateSensitiveConfig validates sensitive categories and the classifier command
+func ValidateSensitiveConfig(sensitive SensitiveConfig) error {
+	names := make(map[string]bool)
+	for i, category := range sensitive.Categories {
+		if category.Name == "" {
+			return fmt.Errorf("category %d: name is required", i+1)
+		}
+		if strings.ContainsAny(category.Name, " \t\n") {
+			return fmt.Errorf("category %q: name cannot contain whitespace", category.Name)
+		}
+		if names[category.Name] {
+			return fmt.Errorf("category %d: duplicate name %q", i+1, category.Name)
+		}
+		names[category.Name] = true
+
+		if !sensitiveActions[category.Action] {
+			return fmt.Errorf("category %q: unknown action %q (valid: store, redact, drop)", category.Name, category.Action)
+		}
+		if len(category.Patterns) == 0 && sensitive.ClassifierCommand == "" {
+			return fmt.Errorf("category %q: needs patterns or a classifier command", category.Name)
+		}
+		for _, pattern := range category.Patterns {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("category %q: invalid pattern: %v", category.Name, err)
+			}
+		}
+	}
+
+	if sensitive.ClassifierCommand != "" {
+		info, err := os.Stat(sensitive.ClassifierCommand)
+		if err != nil {
+			return fmt.Errorf("classifier command: %v", err)
+		}
+		if info.IsDir() || info.Mode().Perm()&0111 == 0 {
+			return fmt.Errorf("classifier command: %s is not an executable file", sensitive.ClassifierCommand)
+		}
+		if sensitive.ClassifierTimeoutSeconds < 1 {
+			return fmt.Errorf("classifier timeout must be >= 1 second, got: %d", sensitive.ClassifierTimeoutSeconds)
+		}
+	}
+	return nil
+}
+
+// ValidateTeamConfig validates that each team member lists identities and that
+// no identity belongs to two members
+func ValidateTeamConfig(team TeamConfig) error {
+	owners := make(map[string]string)
+	members := make([]string, 0, len(team.Members))
+	for member := range team.Members {
+		members = append(members, member)
+	}
+	sort.Strings(members)
+
+	for _, member := range members {
+		if strings.TrimSpace(member) == "" {
+			return fmt.Errorf("member name cannot be empty")
+		}
+		if len(team.Members[member]) == 0 {
+			return fmt.Errorf("member %q: no author emails or names", member)
+		}
+		for _, identity := range team.Members[member] {
+			key := strings.ToLower(strings.TrimSpace(identity))
+			if key == "" {
+				return fmt.Errorf("member %q: empty author identity", member)
+			}
+			if owner, ok := owners[key]; ok && owner != member {
+				return fmt.Errorf("author %q is listed for both %q and %q", identity, owner, member)
+			}
+			owners[key] = member
+		}
+	}
+	return nil
+}
+
+// ValidateShareConfig validates the share link listener address and base URL
+func ValidateShareConfig(share ShareConfig) error {
+	if share.Listen != "" {
+		if _, port, err := net.SplitHostPort(share.Listen); err != nil {
+			return fmt.Errorf("invalid listen address %q: %w", share.Listen, err)
+		} else if port == "" {
+			return fmt.Errorf("listen address %q needs a port", share.Listen)
+		}
+	}
+	if share.URL != "" {
+		parsed, err := url.Parse(share.URL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("url must be an http(s) URL, got %q", share.URL)
+		}
+	}
+	return nil
+}
+
+// ValidateRemoteStorageConfig validates the credentials and endpoints of the
+// cloud storage services exports may be written to
+func ValidateRemoteStorageConfig(remote RemoteStorageConfig) error {
+	if err := validateRemoteBucketConfig(remote.S3); err != nil {
+		return fmt.Errorf("s3: %w", err)
+	}
+	if err := validateRemoteBucketConfig(remote.GCS); err != nil {
+		return fmt.Errorf("gcs: %w", err)
+	}
+	return nil
+}
+
+// validateRemoteBucketConfig validates one cloud storage service's configuration
+func validateRemoteBucketConfig(bucket RemoteBucketConfig) error {
+	if bucket.Endpoint != "" {
+		parsed, err := url.Parse(bucket.Endpoint)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("endpoint must be an http or https URL, got: %q", bucket.Endpoint)
+		}
+	}
+	if (bucket.AccessKeyID == "") != (bucket.SecretAccessKey == "") {
+		return fmt.Errorf("access_key_id and secret_access_key must be set together")
+	}
+	if bucket.SecretAccessKey == "" {
+		return nil
+	}
+
+	// Secret references are resolved when an export runs; only their names can be checked here
+	if !secrets.IsReference(bucket.SecretAccessKey) {
+		return fmt.Errorf("secret_access_key must be a secret reference such as \"secret:aws-secret-key\" (store it with clio secrets set)")
+	}
+	if err := secrets.ValidateName(strings.TrimPrefix(bucket.SecretAccessKey, secrets.ReferencePrefix)); err != nil {
+		return fmt.Errorf("secret_access_key: %w", err)
+	}
+	if secrets.IsReference(bucket.AccessKeyID) {
+		if err := secrets.ValidateName(strings.TrimPrefix(bucket.AccessKeyID, secrets.ReferencePrefix)); err != nil {
+			return fmt.Errorf("access_key_id: %w", err)
+		}
+	}
+	return nil
+}
+
+// ValidateIssuesConfig validates the API URLs and tokens of the issue trackers
+func ValidateIssuesConfig(issues IssuesConfig) error {
+	if err := validateIssueTrackerConfig(issues.GitHub); err != nil {
+		return fmt.Errorf("github: %w", err)
+	}
+	if err := validateIssueTrackerConfig(issues.GitLab); err != nil {
+		return fmt.Errorf("gitlab: %w", err)
+	}
+	return nil
+}
+
+// validateIssueTrackerConfig validates one issue tracker's configuration
+func validateIssueTrackerConfig(tracker IssueTrackerConfig) error {
+	if tracker.APIURL != "" {
+		parsed, err := url.Parse(tracker.APIURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("api_url must be an http or https URL, got: %q", tracker.APIURL)
+		}
+	}
+	if tracker.Token == "" {
+		return nil
+	}
+
+	// Secret references are resolved when an issue is created; only their names can be checked here
+	if !secrets.IsReference(tracker.Token) {
+		return fmt.Errorf("token must be a secret reference such as \"secret:github-token\" (store it with clio secrets set)")
+	}
+	if err := secrets.ValidateName(strings.TrimPrefix(tracker.Token, secrets.ReferencePrefix)); err != nil {
+		return fmt.Errorf("token: %w", err)
+	}
+	return nil
+}
+
+// ValidateFilters validates the names and expressions of named filters
+func ValidateFilters(named map[string]string) error {
+	for _, name := range filters.Names(named) {
+		if err := filters.ValidateName(name); err != nil {
+			return err
+		}
+		if _, err := filters.Parse(named[name]); err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// ValidateAlerts validates that alerts are named uniquely and have a valid pattern and sources
+func ValidateAlerts(alerts []AlertConfig) error {
+	names := make(map[string]bool)
+	for i, alert := range alerts {
+		if alert.Name == "" {
+			return fmt.Errorf("alert %d: name is required", i+1)
+		}
+		if names[alert.Name] {
+			return fmt.Errorf("alert %d: duplicate name %q", i+1, alert.Name)
+		}
+		names[alert.Name] = true
+
+		if strings.TrimSpace(alert.Pattern) == "" {
+			return fmt.Errorf("alert %q: pattern is required", alert.Name)
+		}
+		if alert.Regex {
+			if _, err := regexp.Compile(alert.Pattern); err != nil {
+				return fmt.Errorf("alert %q: invalid regex: %v", alert.Name, err)
+			}
+		}
+		for _, source := range alert.Sources {
+			if !alertSources[source] {
+				return fmt.Errorf("alert %q: unknown source %q (valid: messages, diffs)", alert.Name, source)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateSearchConfig validates the tokenizer and stop word languages
+func ValidateSearchConfig(search SearchConfig) error {
+	switch search.Tokenizer {
+	case "", SearchTokenizerUnicode61, SearchTokenizerPorter:
+	default:
+		return fmt.Errorf("invalid tokenizer %q: use %s or %s", search.Tokenizer, SearchTokenizerUnicode61, SearchTokenizerPorter)
+	}
+	for _, language := range search.Languages {
+		if _, ok := stopwords.Words(language); !ok {
+			return fmt.Errorf("no stop words for %q: use one of %s", language, strings.Join(stopwords.Languages(), ", "))
+		}
+	}
+	for _, word := range search.StopWords {
+		if strings.TrimSpace(word) == "" || strings.ContainsFunc(word, unicode.IsSpace) {
+			return fmt.Errorf("invalid stop word %q: use single words", word)
+		}
+	}
+	return nil
+}
+
+// ValidateTimezone validates that a time zone is empty or a known IANA zone name
+func ValidateTimezone(name string) error {
+	if name == "" {
+		return nil
+	}
+	if _, err := time.LoadLocation(name); err != nil {
+		return fmt.Errorf("unknown time zone %q: use an IANA name like Europe/Berlin", name)
+	}
+	return nil
+}
+
+// ValidateTagRules validates that tag rules have a valid tag, a valid project
+// glob, and at least one condition
+func ValidateTagRules(rules []TagRuleConfig) error {
+	for i, rule := range rules {
+		if !tagRulePattern.MatchString(rule.Tag) {
+			return fmt.Errorf("rule %d: invalid tag %q (use lowercase letters, digits, '.', '_', and '-')", i+1, rule.Tag)
+		}
+		if _, err := filepath.Match(strings.ToLower(rule.Project), ""); err != nil {
+			return fmt.Errorf("rule %d: invalid project pattern %q: %v", i+1, rule.Project, err)
+		}
+		if rule.Project == "" && len(rule.Contains) == 0 {
+			return fmt.Errorf("rule %d: needs a project or contains condition", i+1)
+		}
+		for _, keyword := range rule.Contains {
+			if strings.TrimSpace(keyword) == "" {
+				return fmt.Errorf("rule %d: contains has an empty keyword", i+1)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateRedactionConfig validates that allowed extensions are bare extensions
+func ValidateRedactionConfig(redaction RedactionConfig) error {
+	for _, ext := range redaction.AllowExtensions {
+		trimmed := strings.TrimPrefix(ext, ".")
+		if trimmed == "" || strings.ContainsAny(trimmed, `/\. `) {
+			return fmt.Errorf("invalid extension %q in allow_extensions: use a bare extension like png or .png", ext)
+		}
+	}
+	return nil
+}
+
+// hostnamePattern matches hostnames and, with a leading dot, domains
+var hostnamePattern = regexp.MustCompile(`^\.?[A-Za-z0-9]([A-Za-z0-9-]*[A-Za-z0-9])?(\.[A-Za-z0-9]([A-Za-z0-9-]*[A-Za-z0-9])?)*$`)
+
+// ValidateScrubConfig validates that the names file exists and hostnames are well formed
+func ValidateScrubConfig(scrub ScrubConfig) error {
+	for _, name := range scrub.Names {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("names cannot contain empty names")
+		}
+	}
+	if scrub.NamesFile != "" {
+		info, err := os.Stat(scrub.NamesFile)
+		if err != nil {
+			return fmt.Errorf("names_file: %w", err)
+		}
+		if info.IsDir() {
+			return fmt.Errorf("names_file: %s is a directory", scrub.NamesFile)
+		}
+	}
+	for _, host := range scrub.Hostnames {
+		if !hostnamePattern.MatchString(host) {
+			return fmt.Errorf("invalid hostname %q: use a hostname like build01.corp.example.com or a domain like .corp.example.com", host)
+		}
+	}
+	return nil
+}
+
+// ValidateCommitStyleConfig validates the commit message convention
+func ValidateCommitStyleConfig(style CommitStyleConfig) error {
+	switch style.Convention {
+	case "conventional":
+		for _, t := range style.Types {
+			if t == "" || strings.ContainsAny(t, "():! ") {
+				return fmt.Errorf("invalid type %q: use a word like feat", t)
+			}
+		}
+	case "regex":
+		if style.Pattern == "" {
+			return fmt.Errorf("the regex convention needs a pattern")
+		}
+		if _, err := regexp.Compile(style.Pattern); err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+	default:
+		return fmt.Errorf("convention must be conventional or regex, got: %q", style.Convention)
+	}
+	if style.MaxSubjectLength < 0 {
+		return fmt.Errorf("max_subject_length cannot be negative, got: %d", style.MaxSubjectLength)
+	}
+	return nil
+}
+
+// ValidateZedConfig validates Zed capture configuration. Paths are only checked when
+// capture is enabled, since Zed may not be installed.
+func ValidateZedConfig(zed ZedConfig) error {
+	if zed.PollIntervalSeconds < 1 {
+		return fmt.Errorf("poll interval must be >= 1 second, got: %d", zed.PollIntervalSeconds)
+	}
+	if !zed.Enabled {
+		return nil
+	}
+
+	if zed.ConversationsPath != "" {
+		info, err := os.Stat(zed.ConversationsPath)
+		if err != nil {
+			return fmt.Errorf("conversations path: %w", err)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("conversations path is not a directory")
+		}
+	}
+	if zed.DatabasePath != "" {
+		info, err := os.Stat(zed.DatabasePath)
+		if err != nil {
+			return fmt.Errorf("database path: %w", err)
+		}
+		if info.IsDir() {
+			return fmt.Errorf("database path is a directory")
+		}
+	}
+
+	return nil
+}
+
+// ValidateJetBrainsConfig validates JetBrains capture configuration. The config path is
+// only checked when capture is enabled.
+func ValidateJetBrainsConfig(jetBrains JetBrainsConfig) error {
+	if jetBrains.PollIntervalSeconds < 1 {
+		return fmt.Errorf("poll interval must be >= 1 second, got: %d", jetBrains.PollIntervalSeconds)
+	}
+	if !jetBrains.Enabled || jetBrains.ConfigPath == "" {
+		return nil
+	}
+
+	info, err := os.Stat(jetBrains.ConfigPath)
+	if err != nil {
+		return fmt.Errorf("config path: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("config path is not a directory")
+	}
+
+	return nil
+}
+
+// ValidateHeartbeatsConfig validates heartbeat configuration. The log path may not exist
+// yet, since editors create it on their first heartbeat.
+func ValidateHeartbeatsConfig(heartbeats HeartbeatsConfig) error {
+	if heartbeats.PollIntervalSeconds < 1 {
+		return fmt.Errorf("poll interval must be >= 1 second, got: %d", heartbeats.PollIntervalSeconds)
+	}
+	if heartbeats.TimeoutMinutes < 1 {
+		return fmt.Errorf("timeout must be >= 1 minute, got: %d", heartbeats.TimeoutMinutes)
+	}
+	if heartbeats.LogPath == "" {
+		return nil
+	}
+
+	if info, err := os.Stat(heartbeats.LogPath); err == nil && info.IsDir() {
+		return fmt.Errorf("log path is a directory")
+	}
+
 	return nil
 }
 
@@ -393,11 +1078,138 @@ func ValidateConfig(cfg *Config) error {
 		errors = append(errors, fmt.Sprintf("cursor: %v", sanitizeError(err)))
 	}
 
+	// Validate zed config
+	if err := ValidateZedConfig(cfg.Zed); err != nil {
+		errors = append(errors, fmt.Sprintf("zed: %v", sanitizeError(err)))
+	}
+
+	// Validate jetbrains config
+	if err := ValidateJetBrainsConfig(cfg.JetBrains); err != nil {
+		errors = append(errors, fmt.Sprintf("jetbrains: %v", sanitizeError(err)))
+	}
+
+	// Validate heartbeats config
+	if err := ValidateHeartbeatsConfig(cfg.Heartbeats); err != nil {
+		errors = append(errors, fmt.Sprintf("heartbeats: %v", sanitizeError(err)))
+	}
+
+	// Validate git config
+	if err := ValidateGitConfig(cfg.Git); err != nil {
+		errors = append(errors, fmt.Sprintf("git: %v", err))
+	}
+
 	// Validate session config
 	if err := ValidateSessionConfig(cfg.Session); err != nil {
 		errors = append(errors, fmt.Sprintf("session: %v", err))
 	}
 
+	// Validate webhooks
+	if err := ValidateWebhooks(cfg.Webhooks); err != nil {
+		errors = append(errors, fmt.Sprintf("webhooks: %v", err))
+	}
+
+	// Validate hooks
+	if err := ValidateHooksConfig(cfg.Hooks); err != nil {
+		errors = append(errors, fmt.Sprintf("hooks: %v", sanitizeError(err)))
+	}
+
+	// Validate standup config
+	if err := ValidateStandupConfig(cfg.Standup); err != nil {
+		errors = append(errors, fmt.Sprintf("standup: %v", sanitizeError(err)))
+	}
+
+	// Validate summaries config
+	if err := ValidateSummariesConfig(cfg.Summaries); err != nil {
+		errors = append(errors, fmt.Sprintf("summaries: %v", sanitizeError(err)))
+	}
+
+	// Validate retro config
+	if err := ValidateRetroConfig(cfg.Retro); err != nil {
+		errors = append(errors, fmt.Sprintf("retro: %v", sanitizeError(err)))
+	}
+
+	// Validate habit nudges
+	if err := ValidateNudgesConfig(cfg.Nudges); err != nil {
+		errors = append(errors, fmt.Sprintf("nudges: %v", sanitizeError(err)))
+	}
+
+	// Validate translation config
+	if err := ValidateTranslationConfig(cfg.Translation); err != nil {
+		errors = append(errors, fmt.Sprintf("translation: %v", sanitizeError(err)))
+	}
+
+	// Validate redaction config
+	if err := ValidateRedactionConfig(cfg.Redaction); err != nil {
+		errors = append(errors, fmt.Sprintf("redaction: %v", sanitizeError(err)))
+	}
+
+	// Validate published artifact scrubbing
+	if err := ValidateScrubConfig(cfg.Scrub); err != nil {
+		errors = append(errors, fmt.Sprintf("scrub: %v", sanitizeError(err)))
+	}
+
+	// Validate commit message convention
+	if err := ValidateCommitStyleConfig(cfg.CommitStyle); err != nil {
+		errors = append(errors, fmt.Sprintf("commit_style: %v", sanitizeError(err)))
+	}
+
+	// Validate sensitive content gate
+	if err := ValidateSensitiveConfig(cfg.Sensitive); err != nil {
+		errors = append(errors, fmt.Sprintf("sensitive: %v", sanitizeError(err)))
+	}
+
+	// Validate team members
+	if err := ValidateTeamConfig(cfg.Team); err != nil {
+		errors = append(errors, fmt.Sprintf("team: %v", err))
+	}
+
+	// Validate share links
+	if err := ValidateShareConfig(cfg.Share); err != nil {
+		errors = append(errors, fmt.Sprintf("share: %v", err))
+	}
+
+	// Validate remote storage credentials
+	if err := ValidateRemoteStorageConfig(cfg.RemoteStorage); err != nil {
+		errors = append(errors, fmt.Sprintf("remote storage: %v", err))
+	}
+
+	// Validate issue tracker credentials
+	if err := ValidateIssuesConfig(cfg.Issues); err != nil {
+		errors = append(errors, fmt.Sprintf("issues: %v", err))
+	}
+
+	// Validate alerts
+	if err := ValidateAlerts(cfg.Alerts); err != nil {
+		errors = append(errors, fmt.Sprintf("alerts: %v", err))
+	}
+
+	// Validate search index options
+	if err := ValidateSearchConfig(cfg.Search); err != nil {
+		errors = append(errors, fmt.Sprintf("search: %v", err))
+	}
+
+	// Validate reporting time zone
+	if err := ValidateTimezone(cfg.Timezone); err != nil {
+		errors = append(errors, fmt.Sprintf("timezone: %v", err))
+	}
+
+	// Validate tag rules
+	if err := ValidateTagRules(cfg.TagRules); err != nil {
+		errors = append(errors, fmt.Sprintf("tag rules: %v", err))
+	}
+
+	// Validate named filters
+	if err := ValidateFilters(cfg.Filters); err != nil {
+		errors = append(errors, fmt.Sprintf("filters: %v", err))
+	}
+
+	// Validate profile names; the active profile's values were validated above
+	for _, name := range cfg.ProfileNames() {
+		if err := ValidateProfileName(name); err != nil {
+			errors = append(errors, fmt.Sprintf("profiles: %v", err))
+		}
+	}
+
 	if len(errors) > 0 {
 		return fmt.Errorf("configuration validation failed:\n  %s", strings.Join(errors, "\n  "))
 	}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateWebhooks(t *testing.T) {
+	tests := []struct {
+		name    string
+		events  []string
+		wantErr string
+	}{
+		{name: "session ended", events: []string{"session.ended"}},
+		{name: "commit captured", events: []string{"commit.captured"}},
+		{name: "reminder due", events: []string{"reminder.due"}},
+		{name: "alert matched", events: []string{"alert.matched"}},
+		{name: "search matched", events: []string{"search.matched"}},
+		{name: "habit nudge", events: []string{"habit.nudge"}},
+		{name: "no events", events: nil, wantErr: "at least one event type"},
+		{name: "unknown event", events: []string{"search.matched", "search.started"}, wantErr: `unknown event type "search.started"`},
+		{name: "digest ready", events: []string{"digest.ready"}, wantErr: `unknown event type "digest.ready"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateWebhooks([]WebhookConfig{{URL: "https://example.com/hook", Events: tt.events}})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateWebhooks() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateWebhooks() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	// The error lists every valid event type
+	err := ValidateWebhooks([]WebhookConfig{{URL: "https://example.com/hook", Events: []string{"unknown"}}})
+	for event := range webhookEventTypes {
+		if err == nil || !strings.Contains(err.Error(), event) {
+			t.Errorf("ValidateWebhooks() error = %v, want it to list %s", err, event)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package cursor
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+const (
+	// compressionGzip marks a payload compressed with gzip
+	compressionGzip = "gzip"
+)
+
+// ArchivedPayload is a raw Cursor payload restored from the archive
+type ArchivedPayload struct {
+	ComposerID    string
+	BubbleID      string // Empty for the composerData payload
+	ParserVersion int    // Parser version that was current when the payload was captured
+	Payload       []byte // Decompressed raw JSON
+	CapturedAt    time.Time
+}
+
+// RawPayloadArchive defines the interface for archiving raw composer and bubble JSON
+// so conversations can be re-parsed later without the original Cursor database
+type RawPayloadArchive interface {
+	ArchiveConversation(conversation *Conversation) error
+	GetPayloads(composerID string) ([]ArchivedPayload, error)
+	GetComposerIDs() ([]string, error)
+}
+
+// rawPayloadArchive implements RawPayloadArchive using gzip-compressed blobs in the raw_payloads table
+type rawPayloadArchive struct {
+	db     *sql.DB
+	logger logging.Logger
+}
+
+// NewRawPayloadArchive creates a new raw payload archive instance
+func NewRawPayloadArchive(db *sql.DB, logger logging.Logger) (RawPayloadArchive, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database cannot be nil")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	// Use component-specific logger
+	logger = logger.With("component", "raw_payload_archive")
+
+	return &rawPayloadArchive{
+		db:     db,
+		logger: logger,
+	}, nil
+}
+
+// ArchiveConversation stores the raw composerData payload and every bubble payload of a
+// parsed conversation. Payloads whose content hasn't changed since the last archive are skipped.
+func (a *rawPayloadArchive) ArchiveConversation(conversation *Conversation) error {
+	if conversation == nil {
+		return fmt.Errorf("conversation cannot be nil")
+	}
+
+	tx, err := a.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	archived := 0
+	if len(conversation.RawPayload) > 0 {
+		stored, err := a.archivePayloadInTx(tx, conversation.ComposerID, "", conversation.RawPayload)
+		if err != nil {
+			return err
+		}
+		if stored {
+			archived++
+		}
+	}
+	for i := range conversation.Messages {
+		message := &conversation.Messages[i]
+		if len(message.RawPayload) == 0 {
+			continue
+		}
+		stored, err := a.archivePayloadInTx(tx, conversation.ComposerID, message.BubbleID, message.RawPayload)
+		if err != nil {
+			return err
+		}
+		if stored {
+			archived++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	a.logger.Debug("archived raw payloads", "composer_id", conversation.ComposerID, "archived", archived)
+	return nil
+}
+
+// archivePayloadInTx compresses and upserts a single payload.
+// Returns false when the stored payload already has the same content.
+func (a *rawPayloadArchive) archivePayloadInTx(tx *sql.Tx, composerID, bubbleID string, payload []byte) (bool, error) {
+	hash := sha256.Sum256(payload)
+	payloadHash := hex.EncodeToString(hash[:])
+
+	// Skip unchanged payloads to avoid recompressing on every update
+	var existingHash string
+	err := tx.QueryRow("SELECT payload_hash FROM raw_payloads WHERE composer_id = ? AND bubble_id = ?", composerID, bubbleID).Scan(&existingHash)
+	if err != nil && err != sql.ErrNoRows {
+		return false, fmt.Errorf("failed to query archived payload: %w", err)
+	}
+	if existingHash == payloadHash {
+		return false, nil
+	}
+
+	compressed, err := compressPayload(payload)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO raw_payloads (composer_id, bubble_id, parser_version, compression, payload, payload_hash, captured_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(composer_id, bubble_id) DO UPDATE SET
+			parser_version = excluded.parser_version,
+			compression = excluded.compression,
+			payload = excluded.payload,
+			payload_hash = excluded.payload_hash,
+			captured_at = excluded.captured_at
+	`, composerID, bubbleID, ParserVersion, compressionGzip, compressed, payloadHash, time.Now())
+	if err != nil {
+		return false, fmt.Errorf("failed to store archived payload: %w", err)
+	}
+
+	return true, nil
+}
+
+// GetPayloads returns all archived payloads for a composer, composerData first
+func (a *rawPayloadArchive) GetPayloads(composerID string) ([]ArchivedPayload, error) {
+	rows, err := a.db.Query(`
+		SELECT composer_id, bubble_id, parser_version, compression, payload, captured_at
+		FROM raw_payloads
+		WHERE composer_id = ?
+		ORDER BY bubble_id
+	`, composerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query archived payloads: %w", err)
+	}
+	defer rows.Close()
+
+	var payloads []ArchivedPayload
+	for rows.Next() {
+		var payload ArchivedPayload
+		var compression string
+		var data []byte
+		if err := rows.Scan(&payload.ComposerID, &payload.BubbleID, &payload.ParserVersion, &compression, &data, &payload.CapturedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan archived payload: %w", err)
+		}
+
+		payload.Payload, err = decompressPayload(compression, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress payload for %s/%s: %w", payload.ComposerID, payload.BubbleID, err)
+		}
+		payloads = append(payloads, payload)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating archived payloads: %w", err)
+	}
+
+	return payloads, nil
+}
+
+// GetComposerIDs returns the IDs of all composers with an archived composerData payload
+func (a *rawPayloadArchive) GetComposerIDs() ([]string, error) {
+	rows, err := a.db.Query("SELECT composer_id FROM raw_payloads WHERE bubble_id = '' ORDER BY composer_id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query archived composer IDs: %w", err)
+	}
+	defer rows.Close()
+
+	var composerIDs []string
+	for rows.Next() {
+		var composerID string
+		if err := rows.Scan(&composerID); err != nil {
+			return nil, fmt.Errorf("failed to scan archived composer ID: %w", err)
+		}
+		composerIDs = append(composerIDs, composerID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating archived composer IDs: %w", err)
+	}
+
+	return composerIDs, nil
+}
+
+// archiveRawPayloads archives a parsed conversation's raw payloads when archiving is enabled.
+// Failures are logged rather than returned so archiving never blocks capture.
+func archiveRawPayloads(archive RawPayloadArchive, logger logging.Logger, conversation *Conversation) {
+	if archive == nil || conversation == nil {
+		return
+	}
+
+	if err := archive.ArchiveConversation(conversation); err != nil {
+		logger.Warn("failed to archive raw payloads", "composer_id", conversation.ComposerID, "error", err)
+	}
+}
+
+// compressPayload gzips a raw payload
+func compressPayload(payload []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(payload); err != nil {
+		return nil, fmt.Errorf("failed to compress payload: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress payload: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// decompressPayload reverses compressPayload for the given compression type
+func decompressPayload(compression string, data []byte) ([]byte, error) {
+	switch compression {
+	case compressionGzip:
+		reader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		return io.ReadAll(reader)
+	default:
+		return nil, fmt.Errorf("unsupported compression: %s", compression)
+	}
+}
@@ -0,0 +1,92 @@
+package cursor
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+func TestNewRawPayloadArchive(t *testing.T) {
+	cfg := createTestConfig(t)
+	database := createTestDB(t, cfg)
+	defer database.Close()
+
+	if _, err := NewRawPayloadArchive(database, logging.NewNoopLogger()); err != nil {
+		t.Fatalf("NewRawPayloadArchive() error = %v, want nil", err)
+	}
+	if _, err := NewRawPayloadArchive(nil, logging.NewNoopLogger()); err == nil {
+		t.Error("NewRawPayloadArchive(nil, ...) expected error, got nil")
+	}
+	if _, err := NewRawPayloadArchive(database, nil); err == nil {
+		t.Error("NewRawPayloadArchive(..., nil) expected error, got nil")
+	}
+}
+
+func TestRawPayloadArchive_RoundTrip(t *testing.T) {
+	cfg := createTestConfig(t)
+	database := createTestDB(t, cfg)
+	defer database.Close()
+
+	archive, err := NewRawPayloadArchive(database, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewRawPayloadArchive() error = %v", err)
+	}
+
+	composerJSON := []byte(`{"composerId":"c1","fullConversationHeadersOnly":[{"bubbleId":"b1","type":1}]}`)
+	bubbleJSON := []byte(`{"bubbleId":"b1","type":1,"text":"hello"}`)
+	conversation := createTestConversation(t, "c1", time.Now())
+	conversation.RawPayload = composerJSON
+	conversation.Messages = []Message{{BubbleID: "b1", RawPayload: bubbleJSON}}
+
+	if err := archive.ArchiveConversation(conversation); err != nil {
+		t.Fatalf("ArchiveConversation() error = %v", err)
+	}
+	// Archiving unchanged payloads again is a no-op
+	if err := archive.ArchiveConversation(conversation); err != nil {
+		t.Fatalf("ArchiveConversation() second call error = %v", err)
+	}
+
+	composerIDs, err := archive.GetComposerIDs()
+	if err != nil {
+		t.Fatalf("GetComposerIDs() error = %v", err)
+	}
+	if len(composerIDs) != 1 || composerIDs[0] != "c1" {
+		t.Errorf("GetComposerIDs() = %v, want [c1]", composerIDs)
+	}
+
+	payloads, err := archive.GetPayloads("c1")
+	if err != nil {
+		t.Fatalf("GetPayloads() error = %v", err)
+	}
+	if len(payloads) != 2 {
+		t.Fatalf("GetPayloads() returned %d payloads, want 2", len(payloads))
+	}
+
+	// composerData payload sorts first (empty bubble ID)
+	if payloads[0].BubbleID != "" || !bytes.Equal(payloads[0].Payload, composerJSON) {
+		t.Errorf("composer payload = %+v, want original JSON", payloads[0])
+	}
+	if payloads[1].BubbleID != "b1" || !bytes.Equal(payloads[1].Payload, bubbleJSON) {
+		t.Errorf("bubble payload = %+v, want original JSON", payloads[1])
+	}
+	if payloads[0].ParserVersion != ParserVersion {
+		t.Errorf("ParserVersion = %d, want %d", payloads[0].ParserVersion, ParserVersion)
+	}
+
+	// Stored payload is compressed, not the raw JSON
+	var stored []byte
+	if err := database.QueryRow("SELECT payload FROM raw_payloads WHERE composer_id = 'c1' AND bubble_id = 'b1'").Scan(&stored); err != nil {
+		t.Fatalf("Failed to read stored payload: %v", err)
+	}
+	if bytes.Equal(stored, bubbleJSON) {
+		t.Error("stored payload should be compressed")
+	}
+}
+
+func TestDecompressPayload_UnsupportedCompression(t *testing.T) {
+	if _, err := decompressPayload("lz4", []byte("data")); err == nil {
+		t.Error("decompressPayload() with unsupported compression expected error, got nil")
+	}
+}
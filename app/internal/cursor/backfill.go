@@ -0,0 +1,251 @@
+package cursor
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/blobs"
+	"github.com/stwalsh4118/clio/internal/dedup"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+// DerivedFieldBackfill recomputes a derived message field from data that is already stored.
+// Backfills are registered in derivedFieldBackfills and each runs once per database,
+// recorded in the backfill_runs table (like migrations, but in Go).
+type DerivedFieldBackfill struct {
+	Name        string // Unique name recorded in backfill_runs - never rename
+	Description string
+	// BelowParserVersion limits the backfill to messages extracted by older parsers.
+	// Zero applies it to every stored message.
+	BelowParserVersion int
+	// Apply recomputes the field on a stored message and reports whether anything changed
+	Apply func(message *Message) bool
+}
+
+// BackfillResult reports what a single backfill did
+type BackfillResult struct {
+	Name            string
+	MessagesUpdated int
+}
+
+// BackfillProgress is called as a backfill works through the stored conversations,
+// with the number done so far out of total
+type BackfillProgress func(name string, done, total int)
+
+// derivedFieldBackfills lists every registered backfill in the order they run.
+// When the parser starts deriving a new field, add a backfill here so historical
+// messages get the field without re-reading Cursor's database.
+var derivedFieldBackfills = []DerivedFieldBackfill{
+	{
+		Name:        "message_content_flags",
+		Description: "Derive has_code, has_thinking, and has_tool_calls from stored content",
+		Apply: func(message *Message) bool {
+			hasCode := len(message.CodeBlocks) > 0
+			hasThinking := message.ThinkingText != ""
+			hasToolCalls := len(message.ToolCalls) > 0
+			if message.HasCode == hasCode && message.HasThinking == hasThinking && message.HasToolCalls == hasToolCalls {
+				return false
+			}
+			message.HasCode = hasCode
+			message.HasThinking = hasThinking
+			message.HasToolCalls = hasToolCalls
+			return true
+		},
+	},
+	{
+		Name:        "message_content_source",
+		Description: "Derive content_source for messages captured before it existed",
+		Apply: func(message *Message) bool {
+			contentSource := determineContentSource(message.Text, message.ThinkingText, message.CodeBlocks, message.ToolCalls)
+			if message.ContentSource == contentSource {
+				return false
+			}
+			message.ContentSource = contentSource
+			return true
+		},
+	},
+	{
+		Name:        "message_shared_code_blocks",
+		Description: "Store large code blocks captured before deduplication once",
+		Apply: func(message *Message) bool {
+			// Rewriting the message shares its large blocks
+			for _, block := range message.CodeBlocks {
+				if len(block.Content) >= dedup.MinSize {
+					return true
+				}
+			}
+			return false
+		},
+	},
+}
+
+// BackfillRunner defines the interface for applying pending derived-field backfills
+type BackfillRunner interface {
+	Pending() ([]string, error)
+	Run() ([]BackfillResult, error)
+	RunWithProgress(progress BackfillProgress) ([]BackfillResult, error)
+}
+
+// backfillRunner implements BackfillRunner over the stored conversations
+type backfillRunner struct {
+	db        *sql.DB
+	storage   ConversationStorage
+	logger    logging.Logger
+	backfills []DerivedFieldBackfill
+}
+
+// NewBackfillRunner creates a runner for the registered backfills. blobStore loads
+// messages moved there so they're rewritten in full; nil is only safe when
+// nothing has been moved.
+func NewBackfillRunner(db *sql.DB, blobStore blobs.Store, logger logging.Logger) (BackfillRunner, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database cannot be nil")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	storage, err := newConversationStorage(db, nil, blobStore, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create conversation storage: %w", err)
+	}
+
+	return &backfillRunner{
+		db:        db,
+		storage:   storage,
+		logger:    logger.With("component", "backfill_runner"),
+		backfills: derivedFieldBackfills,
+	}, nil
+}
+
+// Pending returns the names of backfills that haven't run yet
+func (r *backfillRunner) Pending() ([]string, error) {
+	completed, err := r.completedBackfills()
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []string
+	for _, backfill := range r.backfills {
+		if !completed[backfill.Name] {
+			pending = append(pending, backfill.Name)
+		}
+	}
+	return pending, nil
+}
+
+// Run applies every pending backfill and records it as completed
+func (r *backfillRunner) Run() ([]BackfillResult, error) {
+	return r.RunWithProgress(nil)
+}
+
+// RunWithProgress applies every pending backfill like Run, reporting progress
+// after each conversation when progress isn't nil
+func (r *backfillRunner) RunWithProgress(progress BackfillProgress) ([]BackfillResult, error) {
+	completed, err := r.completedBackfills()
+	if err != nil {
+		return nil, err
+	}
+
+	var results []BackfillResult
+	for _, backfill := range r.backfills {
+		if completed[backfill.Name] {
+			continue
+		}
+
+		updated, err := r.runBackfill(backfill, progress)
+		if err != nil {
+			return results, fmt.Errorf("backfill %s failed: %w", backfill.Name, err)
+		}
+
+		if _, err := r.db.Exec(
+			"INSERT INTO backfill_runs (name, messages_updated, completed_at) VALUES (?, ?, ?)",
+			backfill.Name, updated, time.Now(),
+		); err != nil {
+			return results, fmt.Errorf("failed to record backfill %s: %w", backfill.Name, err)
+		}
+
+		r.logger.Info("backfill completed", "name", backfill.Name, "messages_updated", updated)
+		results = append(results, BackfillResult{Name: backfill.Name, MessagesUpdated: updated})
+	}
+
+	return results, nil
+}
+
+// runBackfill applies a single backfill conversation by conversation
+func (r *backfillRunner) runBackfill(backfill DerivedFieldBackfill, progress BackfillProgress) (int, error) {
+	conversationIDs, err := r.conversationIDs()
+	if err != nil {
+		return 0, err
+	}
+
+	updated := 0
+	for i, conversationID := range conversationIDs {
+		conversation, err := r.storage.GetConversation(conversationID)
+		if err != nil {
+			return updated, fmt.Errorf("failed to load conversation %s: %w", conversationID, err)
+		}
+
+		var changed []*Message
+		for i := range conversation.Messages {
+			message := &conversation.Messages[i]
+			if backfill.BelowParserVersion > 0 && message.ParserVersion >= backfill.BelowParserVersion {
+				continue
+			}
+			if backfill.Apply(message) {
+				changed = append(changed, message)
+			}
+		}
+
+		// UpgradeMessages keeps each message's parser_version as loaded
+		if err := r.storage.UpgradeMessages(conversationID, changed); err != nil {
+			return updated, err
+		}
+		updated += len(changed)
+
+		if progress != nil {
+			progress(backfill.Name, i+1, len(conversationIDs))
+		}
+	}
+
+	return updated, nil
+}
+
+// conversationIDs returns every stored conversation ID
+func (r *backfillRunner) conversationIDs() ([]string, error) {
+	rows, err := r.db.Query("SELECT id FROM conversations ORDER BY created_at ASC")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query conversations: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan conversation ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// completedBackfills returns the names recorded in backfill_runs
+func (r *backfillRunner) completedBackfills() (map[string]bool, error) {
+	rows, err := r.db.Query("SELECT name FROM backfill_runs")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query backfill runs: %w", err)
+	}
+	defer rows.Close()
+
+	completed := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan backfill run: %w", err)
+		}
+		completed[name] = true
+	}
+	return completed, rows.Err()
+}
@@ -0,0 +1,244 @@
+package cursor
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/blobs"
+	"github.com/stwalsh4118/clio/internal/dedup"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+func TestNewBackfillRunner(t *testing.T) {
+	cfg := createTestConfig(t)
+	database := createTestDB(t, cfg)
+	defer database.Close()
+
+	if _, err := NewBackfillRunner(database, nil, logging.NewNoopLogger()); err != nil {
+		t.Fatalf("NewBackfillRunner() error = %v, want nil", err)
+	}
+	if _, err := NewBackfillRunner(nil, nil, logging.NewNoopLogger()); err == nil {
+		t.Error("NewBackfillRunner(nil, nil, ...) expected error, got nil")
+	}
+	if _, err := NewBackfillRunner(database, nil, nil); err == nil {
+		t.Error("NewBackfillRunner(..., nil) expected error, got nil")
+	}
+}
+
+func TestBackfillRunner_Run(t *testing.T) {
+	cfg := createTestConfig(t)
+	database := createTestDB(t, cfg)
+	defer database.Close()
+
+	sessionID := "test-session-1"
+	if _, err := database.Exec(`
+		INSERT INTO sessions (id, project, start_time, end_time, last_activity, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, sessionID, "test-project", time.Now(), nil, time.Now(), time.Now(), time.Now()); err != nil {
+		t.Fatalf("Failed to create test session: %v", err)
+	}
+
+	logger := logging.NewNoopLogger()
+	storage, err := NewConversationStorage(database, logger)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+
+	// Messages stored before derived fields existed: has thinking text but no flags/source
+	conversation := &Conversation{
+		ComposerID: "c1",
+		CreatedAt:  time.Now(),
+		Messages: []Message{
+			{BubbleID: "b1", Type: 2, Role: "agent", ThinkingText: "considering", CreatedAt: time.Now()},
+			{BubbleID: "b2", Type: 1, Role: "user", Text: "hi", ContentSource: "text", CreatedAt: time.Now()},
+			{BubbleID: "b3", Type: 2, Role: "agent", CodeBlocks: []CodeBlock{{Content: strings.Repeat("x", dedup.MinSize)}},
+				HasCode: true, ContentSource: "code", CreatedAt: time.Now()},
+		},
+	}
+	if err := storage.StoreConversation(conversation, sessionID); err != nil {
+		t.Fatalf("Failed to store conversation: %v", err)
+	}
+
+	runner, err := NewBackfillRunner(database, nil, logger)
+	if err != nil {
+		t.Fatalf("NewBackfillRunner() error = %v", err)
+	}
+
+	pending, err := runner.Pending()
+	if err != nil {
+		t.Fatalf("Pending() error = %v", err)
+	}
+	if len(pending) != len(derivedFieldBackfills) {
+		t.Errorf("Pending() = %v, want all %d backfills", pending, len(derivedFieldBackfills))
+	}
+
+	results, err := runner.Run()
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(results) != len(derivedFieldBackfills) {
+		t.Fatalf("Run() results = %d, want %d", len(results), len(derivedFieldBackfills))
+	}
+	for _, result := range results {
+		if result.MessagesUpdated != 1 {
+			t.Errorf("backfill %s updated %d messages, want 1", result.Name, result.MessagesUpdated)
+		}
+	}
+
+	stored, err := storage.GetConversationByComposerID("c1")
+	if err != nil {
+		t.Fatalf("Failed to retrieve conversation: %v", err)
+	}
+	for _, message := range stored.Messages {
+		if message.BubbleID == "b3" && message.CodeBlocks[0].Content != strings.Repeat("x", dedup.MinSize) {
+			t.Errorf("message b3 code block = %q, want its content restored", message.CodeBlocks[0].Content)
+		}
+	}
+	var refCount int
+	if err := database.QueryRow("SELECT SUM(ref_count) FROM shared_contents").Scan(&refCount); err != nil || refCount != 1 {
+		t.Errorf("shared content references = %d, %v, want 1 after rewriting b3", refCount, err)
+	}
+	for _, message := range stored.Messages {
+		if message.BubbleID != "b1" {
+			continue
+		}
+		if !message.HasThinking || message.ContentSource != "thinking" {
+			t.Errorf("message b1 not backfilled: has_thinking=%v content_source=%q", message.HasThinking, message.ContentSource)
+		}
+		// Backfills don't claim a newer parser extracted the message
+		if message.ParserVersion != 0 {
+			t.Errorf("message b1 ParserVersion = %d, want 0", message.ParserVersion)
+		}
+	}
+
+	// Completed backfills don't run again
+	pending, err = runner.Pending()
+	if err != nil {
+		t.Fatalf("Pending() error = %v", err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("Pending() after Run = %v, want none", pending)
+	}
+	results, err = runner.Run()
+	if err != nil {
+		t.Fatalf("second Run() error = %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("second Run() results = %v, want none", results)
+	}
+}
+
+func TestBackfillRunner_RewritesBlobBackedMessagesInFull(t *testing.T) {
+	cfg := createTestConfig(t)
+	database := createTestDB(t, cfg)
+	defer database.Close()
+
+	sessionID := "test-session-1"
+	if _, err := database.Exec(`
+		INSERT INTO sessions (id, project, start_time, end_time, last_activity, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, sessionID, "test-project", time.Now(), nil, time.Now(), time.Now(), time.Now()); err != nil {
+		t.Fatalf("Failed to create test session: %v", err)
+	}
+
+	logger := logging.NewNoopLogger()
+	storage, err := NewConversationStorage(database, logger)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	long := strings.Repeat("a long answer ", blobs.PreviewLength/14+1) + "with its ending"
+	conversation := &Conversation{
+		ComposerID: "c1",
+		CreatedAt:  time.Now(),
+		Messages: []Message{
+			{BubbleID: "b1", Type: 2, Role: "agent", Text: long, ThinkingText: "considering", CreatedAt: time.Now()},
+		},
+	}
+	if err := storage.StoreConversation(conversation, sessionID); err != nil {
+		t.Fatalf("Failed to store conversation: %v", err)
+	}
+
+	// The message was moved to the blob store, leaving a preview in the row
+	blobStore, err := blobs.NewStore(filepath.Join(t.TempDir(), "blobs"), logger)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	ref, err := blobStore.Put([]byte(long))
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if _, err := database.Exec("UPDATE messages SET content = ?, content_blob = ? WHERE id = 'b1'", blobs.Preview(long), ref); err != nil {
+		t.Fatalf("failed to move message to the blob store: %v", err)
+	}
+
+	runner, err := NewBackfillRunner(database, blobStore, logger)
+	if err != nil {
+		t.Fatalf("NewBackfillRunner() error = %v", err)
+	}
+	if _, err := runner.Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	var content string
+	if err := database.QueryRow("SELECT content FROM messages WHERE id = 'b1'").Scan(&content); err != nil {
+		t.Fatalf("failed to read message: %v", err)
+	}
+	if content != long {
+		t.Errorf("rewritten content = %d bytes, want the full %d-byte message", len(content), len(long))
+	}
+}
+
+func TestStorage_ConversationParserVersion(t *testing.T) {
+	cfg := createTestConfig(t)
+	database := createTestDB(t, cfg)
+	defer database.Close()
+
+	sessionID := "test-session-1"
+	if _, err := database.Exec(`
+		INSERT INTO sessions (id, project, start_time, end_time, last_activity, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, sessionID, "test-project", time.Now(), nil, time.Now(), time.Now(), time.Now()); err != nil {
+		t.Fatalf("Failed to create test session: %v", err)
+	}
+
+	storage, err := NewConversationStorage(database, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+
+	conversation := &Conversation{
+		ComposerID: "c1",
+		CreatedAt:  time.Now(),
+		Messages: []Message{
+			{BubbleID: "b1", Type: 1, Role: "user", Text: "a", CreatedAt: time.Now(), ParserVersion: ParserVersion},
+			{BubbleID: "b2", Type: 1, Role: "user", Text: "b", CreatedAt: time.Now()},
+		},
+	}
+	if err := storage.StoreConversation(conversation, sessionID); err != nil {
+		t.Fatalf("Failed to store conversation: %v", err)
+	}
+
+	// Conversation version is the lowest among its messages
+	stored, err := storage.GetConversationByComposerID("c1")
+	if err != nil {
+		t.Fatalf("Failed to retrieve conversation: %v", err)
+	}
+	if stored.ParserVersion != 0 {
+		t.Errorf("ParserVersion = %d, want 0", stored.ParserVersion)
+	}
+
+	upgraded := conversation.Messages[1]
+	upgraded.ParserVersion = ParserVersion
+	if err := storage.UpgradeMessages("c1", []*Message{&upgraded}); err != nil {
+		t.Fatalf("UpgradeMessages() error = %v", err)
+	}
+	stored, err = storage.GetConversationByComposerID("c1")
+	if err != nil {
+		t.Fatalf("Failed to retrieve conversation: %v", err)
+	}
+	if stored.ParserVersion != ParserVersion {
+		t.Errorf("ParserVersion after upgrade = %d, want %d", stored.ParserVersion, ParserVersion)
+	}
+}
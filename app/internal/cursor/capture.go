@@ -10,6 +10,7 @@ import (
 
 	"github.com/stwalsh4118/clio/internal/config"
 	"github.com/stwalsh4118/clio/internal/logging"
+	"github.com/stwalsh4118/clio/internal/sensitive"
 )
 
 const (
@@ -23,6 +24,8 @@ const (
 type CaptureService interface {
 	Start() error
 	Stop() error
+	OnSessionEnd(handler SessionEndHandler)
+	SessionManager() SessionManager
 }
 
 // captureService orchestrates all Cursor capture components
@@ -36,6 +39,9 @@ type captureService struct {
 	sessionManager  SessionManager
 	storage         ConversationStorage
 	updater         ConversationUpdater
+	queue           WorkQueue
+	quarantine      QuarantineStorage
+	archive         RawPayloadArchive // nil unless cursor.archive_raw_payloads is enabled
 	ctx             context.Context
 	cancel          context.CancelFunc
 	wg              sync.WaitGroup
@@ -95,20 +101,54 @@ func (cs *captureService) initializeComponents() error {
 	}
 	cs.parser = parser
 
-	// Create project detector
-	projectDetector, err := NewProjectDetector(cs.config)
+	// Create project detector, naming checkouts of a repository by its remote
+	namer, err := NewProjectNamer(cs.db, cs.logger)
+	if err != nil {
+		return fmt.Errorf("failed to create project namer: %w", err)
+	}
+	projectDetector, err := NewProjectDetectorWithNamer(cs.config, namer)
 	if err != nil {
 		return fmt.Errorf("failed to create project detector: %w", err)
 	}
 	cs.projectDetector = projectDetector
 
-	// Create storage
-	storage, err := NewConversationStorage(cs.db, cs.logger)
+	// Create storage, gating sensitive content when configured
+	gate, err := sensitive.NewGate(cs.config.Sensitive, cs.logger)
+	if err != nil {
+		return fmt.Errorf("failed to create sensitive gate: %w", err)
+	}
+	storage, err := NewConversationStorageWithGate(cs.db, gate, cs.logger)
 	if err != nil {
 		return fmt.Errorf("failed to create conversation storage: %w", err)
 	}
 	cs.storage = storage
 
+	// Create quarantine storage (payloads with unrecognised schema)
+	quarantine, err := NewQuarantineStorage(cs.db, cs.logger)
+	if err != nil {
+		return fmt.Errorf("failed to create quarantine storage: %w", err)
+	}
+	cs.quarantine = quarantine
+
+	// Create raw payload archive if enabled
+	if cs.config.Cursor.ArchiveRawPayloads {
+		archive, err := NewRawPayloadArchive(cs.db, cs.logger)
+		if err != nil {
+			return fmt.Errorf("failed to create raw payload archive: %w", err)
+		}
+		cs.archive = archive
+	}
+
+	// Apply pending derived-field backfills to previously captured messages
+	backfills, err := NewBackfillRunner(cs.db, openBlobStore(cs.config, cs.logger), cs.logger)
+	if err != nil {
+		return fmt.Errorf("failed to create backfill runner: %w", err)
+	}
+	if _, err := backfills.Run(); err != nil {
+		// Don't fail initialization - the backfill is retried on next start
+		cs.logger.Warn("failed to run derived field backfills", "error", err)
+	}
+
 	// Create session manager
 	sessionManager, err := NewSessionManager(cs.config, cs.db)
 	if err != nil {
@@ -129,13 +169,24 @@ func (cs *captureService) initializeComponents() error {
 	}
 	cs.updater = updater
 
-	// Create poller (requires updater)
-	poller, err := NewPoller(cs.config, cs.updater)
+	// Create change detector and poller
+	detector, err := NewChangeDetector(cs.config)
+	if err != nil {
+		return fmt.Errorf("failed to create change detector: %w", err)
+	}
+	poller, err := NewPoller(cs.config, detector)
 	if err != nil {
 		return fmt.Errorf("failed to create poller: %w", err)
 	}
 	cs.poller = poller
 
+	// Create work queue (bounds and rate-limits composer processing)
+	queue, err := NewWorkQueue(cs.config, cs.processComposer, cs.logger)
+	if err != nil {
+		return fmt.Errorf("failed to create work queue: %w", err)
+	}
+	cs.queue = queue
+
 	cs.logger.Info("capture service components initialized")
 	return nil
 }
@@ -174,6 +225,13 @@ func (cs *captureService) Start() error {
 		return fmt.Errorf("failed to get poller channel: %w", err)
 	}
 
+	// Start work queue workers
+	if err := cs.queue.Start(cs.ctx); err != nil {
+		cs.sessionManager.Stop()
+		cs.poller.Stop()
+		return fmt.Errorf("failed to start work queue: %w", err)
+	}
+
 	// Start poll processing goroutine
 	cs.wg.Add(1)
 	go cs.processPolls(polls)
@@ -200,17 +258,15 @@ func (cs *captureService) processPolls(polls <-chan struct{}) {
 				return
 			}
 
-			// Process poll asynchronously to avoid blocking
-			cs.wg.Add(1)
-			go cs.handlePoll()
+			// Handle poll inline - enqueueing blocks while the work queue is full,
+			// so bursts of polls are coalesced by the poller's buffered channel
+			cs.handlePoll()
 		}
 	}
 }
 
-// handlePoll handles a single poll signal
+// handlePoll handles a single poll signal by queueing updated composers for processing
 func (cs *captureService) handlePoll() {
-	defer cs.wg.Done()
-
 	cs.logger.Debug("processing poll")
 
 	// Detect updated composers
@@ -227,11 +283,11 @@ func (cs *captureService) handlePoll() {
 
 	cs.logger.Info("detected updated composers", "count", len(updatedComposers))
 
-	// Process each updated composer
+	// Queue each updated composer (workers process them with bounded concurrency)
 	for _, composerID := range updatedComposers {
-		if err := cs.processComposer(composerID); err != nil {
-			cs.logger.Error("failed to process composer", "composer_id", composerID, "error", err)
-			// Continue processing other composers despite errors
+		if err := cs.queue.Enqueue(cs.ctx, composerID); err != nil {
+			cs.logger.Debug("stopped queueing composers", "error", err)
+			return
 		}
 	}
 }
@@ -270,6 +326,7 @@ func (cs *captureService) processComposer(composerID string) error {
 func (cs *captureService) processNewConversation(composerID string) error {
 	// Parse conversation
 	conversation, err := cs.parser.ParseConversation(composerID)
+	recordQuarantine(cs.quarantine, cs.logger, conversation, err)
 	if err != nil {
 		return fmt.Errorf("failed to parse conversation: %w", err)
 	}
@@ -292,6 +349,9 @@ func (cs *captureService) processNewConversation(composerID string) error {
 		return fmt.Errorf("failed to get or create session: %w", err)
 	}
 
+	// Archive raw payloads for future re-parsing
+	archiveRawPayloads(cs.archive, cs.logger, conversation)
+
 	// Mark as processed
 	messageCount := len(conversation.Messages)
 	if err := cs.updater.MarkAsProcessed(composerID, messageCount); err != nil {
@@ -455,6 +515,9 @@ func (cs *captureService) Stop() error {
 	done := make(chan struct{})
 	go func() {
 		cs.wg.Wait()
+		if cs.queue != nil {
+			cs.queue.Stop()
+		}
 		close(done)
 	}()
 
@@ -468,3 +531,13 @@ func (cs *captureService) Stop() error {
 	cs.started = false
 	return nil
 }
+
+// OnSessionEnd registers a handler called whenever a captured session ends
+func (cs *captureService) OnSessionEnd(handler SessionEndHandler) {
+	cs.sessionManager.OnSessionEnd(handler)
+}
+
+// SessionManager returns the session manager, so other capture sources can share its sessions
+func (cs *captureService) SessionManager() SessionManager {
+	return cs.sessionManager
+}
@@ -0,0 +1,139 @@
+package cursor
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"sync"
+
+	"github.com/stwalsh4118/clio/internal/config"
+)
+
+// ChangeDetector detects whether Cursor's conversation data has changed between polls
+// without re-parsing every composer. It keeps a single read-only connection open so that
+// SQLite's data_version pragma can be used as a cheap "nothing changed" check.
+type ChangeDetector interface {
+	Fingerprint() (string, error) // Returns a checksum of the conversation key range
+	Close() error
+}
+
+// changeDetector implements ChangeDetector using PRAGMA data_version and a checksum
+// over the composerData rows in cursorDiskKV
+type changeDetector struct {
+	config          *config.Config
+	db              *sql.DB
+	lastDataVersion int64
+	lastFingerprint string
+	mu              sync.Mutex
+}
+
+const (
+	// composerDataKeyPattern matches the composer rows whose contents change when messages are added
+	composerDataKeyPattern = "composerData:%"
+)
+
+// NewChangeDetector creates a new change detector instance.
+// The Cursor database is opened lazily on the first Fingerprint call.
+func NewChangeDetector(cfg *config.Config) (ChangeDetector, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	return &changeDetector{
+		config:          cfg,
+		lastDataVersion: -1,
+	}, nil
+}
+
+// Fingerprint returns a checksum of the composerData key range.
+// When SQLite reports that no other connection has written to the database since the
+// previous call, the cached fingerprint is returned without scanning any rows.
+func (cd *changeDetector) Fingerprint() (string, error) {
+	cd.mu.Lock()
+	defer cd.mu.Unlock()
+
+	// Open persistent connection on first use (data_version is per-connection)
+	if cd.db == nil {
+		db, err := OpenCursorDatabase(cd.config)
+		if err != nil {
+			return "", err
+		}
+		cd.db = db
+	}
+
+	// Cheap check: data_version only changes when another connection commits
+	var dataVersion int64
+	if err := cd.db.QueryRow("PRAGMA data_version").Scan(&dataVersion); err != nil {
+		cd.resetLocked()
+		return "", fmt.Errorf("failed to read data_version: %w", err)
+	}
+	if dataVersion == cd.lastDataVersion && cd.lastFingerprint != "" {
+		return cd.lastFingerprint, nil
+	}
+
+	// Database was written to - checksum the relevant rows to see if conversations changed
+	fingerprint, err := cd.checksumComposerData()
+	if err != nil {
+		cd.resetLocked()
+		return "", err
+	}
+
+	cd.lastDataVersion = dataVersion
+	cd.lastFingerprint = fingerprint
+	return fingerprint, nil
+}
+
+// checksumComposerData hashes every composerData key and value in key order
+func (cd *changeDetector) checksumComposerData() (string, error) {
+	rows, err := cd.db.Query("SELECT key, value FROM cursorDiskKV WHERE key LIKE ? ORDER BY key", composerDataKeyPattern)
+	if err != nil {
+		return "", fmt.Errorf("failed to query composer data: %w", err)
+	}
+	defer rows.Close()
+
+	hasher := sha256.New()
+	for rows.Next() {
+		var key string
+		var value []byte
+		if err := rows.Scan(&key, &value); err != nil {
+			return "", fmt.Errorf("failed to scan composer data row: %w", err)
+		}
+		// Separate fields so that key/value boundaries can't collide
+		hasher.Write([]byte(key))
+		hasher.Write([]byte{0})
+		hasher.Write(value)
+		hasher.Write([]byte{0})
+	}
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("error iterating composer data: %w", err)
+	}
+
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// resetLocked drops the connection and cached state so the next call starts fresh.
+// Caller must hold cd.mu.
+func (cd *changeDetector) resetLocked() {
+	if cd.db != nil {
+		cd.db.Close()
+		cd.db = nil
+	}
+	cd.lastDataVersion = -1
+	cd.lastFingerprint = ""
+}
+
+// Close closes the persistent database connection
+func (cd *changeDetector) Close() error {
+	cd.mu.Lock()
+	defer cd.mu.Unlock()
+
+	if cd.db == nil {
+		return nil
+	}
+	err := cd.db.Close()
+	cd.db = nil
+	cd.lastDataVersion = -1
+	cd.lastFingerprint = ""
+	return err
+}
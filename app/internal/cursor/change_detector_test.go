@@ -0,0 +1,108 @@
+package cursor
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/stwalsh4118/clio/internal/config"
+)
+
+func TestNewChangeDetector(t *testing.T) {
+	detector, err := NewChangeDetector(&config.Config{})
+	if err != nil {
+		t.Fatalf("NewChangeDetector() error = %v, want nil", err)
+	}
+	if detector == nil {
+		t.Fatal("NewChangeDetector() returned nil detector")
+	}
+
+	// Test nil config
+	_, err = NewChangeDetector(nil)
+	if err == nil {
+		t.Error("NewChangeDetector(nil) expected error, got nil")
+	}
+}
+
+func TestChangeDetector_Fingerprint(t *testing.T) {
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "globalStorage", "state.vscdb")
+	createTestDatabase(t, dbPath)
+
+	cfg := &config.Config{
+		Cursor: config.CursorConfig{
+			LogPath: tmpDir,
+		},
+	}
+
+	detector, err := NewChangeDetector(cfg)
+	if err != nil {
+		t.Fatalf("NewChangeDetector() error = %v", err)
+	}
+	defer detector.Close()
+
+	first, err := detector.Fingerprint()
+	if err != nil {
+		t.Fatalf("Fingerprint() error = %v", err)
+	}
+	if first == "" {
+		t.Fatal("Fingerprint() returned empty fingerprint")
+	}
+
+	// Unchanged database returns the same fingerprint
+	second, err := detector.Fingerprint()
+	if err != nil {
+		t.Fatalf("Fingerprint() error = %v", err)
+	}
+	if second != first {
+		t.Errorf("Fingerprint() changed without writes: %q != %q", second, first)
+	}
+
+	writer, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("Failed to open test database for writing: %v", err)
+	}
+	defer writer.Close()
+
+	// Writes outside the composerData range don't change the fingerprint
+	if _, err := writer.Exec("INSERT INTO cursorDiskKV (key, value) VALUES (?, ?)", "bubbleId:test-composer-id-123:bubble-4", []byte(`{"text":"partial"}`)); err != nil {
+		t.Fatalf("Failed to insert bubble: %v", err)
+	}
+	third, err := detector.Fingerprint()
+	if err != nil {
+		t.Fatalf("Fingerprint() error = %v", err)
+	}
+	if third != first {
+		t.Errorf("Fingerprint() changed after unrelated write: %q != %q", third, first)
+	}
+
+	// Updating composer data changes the fingerprint
+	if _, err := writer.Exec("INSERT INTO cursorDiskKV (key, value) VALUES (?, ?)", "composerData:test-composer-id-123", []byte(`{"composerId":"test-composer-id-123","fullConversationHeadersOnly":[]}`)); err != nil {
+		t.Fatalf("Failed to update composer data: %v", err)
+	}
+	fourth, err := detector.Fingerprint()
+	if err != nil {
+		t.Fatalf("Fingerprint() error = %v", err)
+	}
+	if fourth == first {
+		t.Error("Fingerprint() did not change after composer data update")
+	}
+}
+
+func TestChangeDetector_MissingDatabase(t *testing.T) {
+	cfg := &config.Config{
+		Cursor: config.CursorConfig{
+			LogPath: t.TempDir(),
+		},
+	}
+
+	detector, err := NewChangeDetector(cfg)
+	if err != nil {
+		t.Fatalf("NewChangeDetector() error = %v", err)
+	}
+	defer detector.Close()
+
+	if _, err := detector.Fingerprint(); err == nil {
+		t.Error("Fingerprint() with missing database expected error, got nil")
+	}
+}
@@ -0,0 +1,179 @@
+package cursor
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// orphanedSession is a session that conversations refer to but that didn't load,
+// because its row is missing or couldn't be read, with the time span of those
+// conversations
+type orphanedSession struct {
+	id    string
+	start time.Time
+	end   time.Time
+}
+
+// reconstructOrphanedSessions gives conversations whose session didn't load a
+// session again, so they show up in reports instead of silently dropping out.
+// Each missing session is recreated under its own ID, so commits and other rows
+// referring to it stay linked, spanning its conversations and ended. Its project
+// is inferred by inferOrphanProject. Must be called with sm.mu held, after the
+// stored sessions were loaded.
+func (sm *sessionManager) reconstructOrphanedSessions() (int, error) {
+	rows, err := sm.db.Query(`
+		SELECT id, session_id, first_message_time, last_message_time, created_at
+		FROM conversations
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query conversations: %w", err)
+	}
+
+	orphans := make(map[string]*orphanedSession)
+	for rows.Next() {
+		var conversationID, sessionID string
+		var first, last sql.NullTime
+		var createdAt time.Time
+		if err := rows.Scan(&conversationID, &sessionID, &first, &last, &createdAt); err != nil {
+			sm.logger.Warn("skipping unreadable conversation", "error", err, "conversation_id", conversationID)
+			continue
+		}
+		if _, loaded := sm.sessions[sessionID]; loaded {
+			continue
+		}
+
+		start := createdAt
+		if first.Valid {
+			start = first.Time
+		}
+		end := start
+		if last.Valid && last.Time.After(start) {
+			end = last.Time
+		}
+
+		orphan := orphans[sessionID]
+		if orphan == nil {
+			orphans[sessionID] = &orphanedSession{id: sessionID, start: start, end: end}
+			continue
+		}
+		if start.Before(orphan.start) {
+			orphan.start = start
+		}
+		if end.After(orphan.end) {
+			orphan.end = end
+		}
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return 0, fmt.Errorf("error iterating conversations: %w", err)
+	}
+
+	ids := make([]string, 0, len(orphans))
+	for id := range orphans {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	now := time.Now()
+	for _, id := range ids {
+		orphan := orphans[id]
+		project, err := sm.inferOrphanProject(orphan)
+		if err != nil {
+			return 0, err
+		}
+
+		end := orphan.end
+		session := &Session{
+			ID:           orphan.id,
+			Project:      project,
+			StartTime:    orphan.start,
+			EndTime:      &end,
+			LastActivity: end,
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		}
+		// The upsert also replaces a stored row that couldn't be read
+		if err := sm.saveSessionToDB(session); err != nil {
+			return 0, err
+		}
+
+		conversations, err := sm.storage.GetConversationsBySession(session.ID)
+		if err != nil {
+			conversations = []*Conversation{}
+		}
+		session.Conversations = conversations
+		sm.sessions[session.ID] = session
+
+		sm.logger.Warn("reconstructed session for orphaned conversations", "session_id", session.ID,
+			"project", project, "conversations", len(conversations))
+	}
+
+	return len(ids), nil
+}
+
+// inferOrphanProject picks the project of a reconstructed session from, in
+// order: the project stored in its unreadable row, the repository most of the
+// commits correlated with it were made in, and the project of the loaded
+// sessions its time span overlaps, when they all share one. Otherwise the
+// session is filed under the unknown project.
+func (sm *sessionManager) inferOrphanProject(orphan *orphanedSession) (string, error) {
+	var stored sql.NullString
+	err := sm.db.QueryRow("SELECT project FROM sessions WHERE id = ?", orphan.id).Scan(&stored)
+	if err != nil && err != sql.ErrNoRows {
+		return "", fmt.Errorf("failed to query stored session project: %w", err)
+	}
+	if strings.TrimSpace(stored.String) != "" {
+		return stored.String, nil
+	}
+
+	rows, err := sm.db.Query("SELECT repository_name FROM commits WHERE session_id = ?", orphan.id)
+	if err != nil {
+		return "", fmt.Errorf("failed to query orphaned session commits: %w", err)
+	}
+	counts := make(map[string]int)
+	for rows.Next() {
+		var repository string
+		if err := rows.Scan(&repository); err != nil {
+			rows.Close()
+			return "", fmt.Errorf("failed to scan commit repository: %w", err)
+		}
+		counts[repository]++
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return "", fmt.Errorf("error iterating orphaned session commits: %w", err)
+	}
+	best := ""
+	for repository, count := range counts {
+		if count > counts[best] || (count == counts[best] && repository < best) {
+			best = repository
+		}
+	}
+	if best != "" {
+		return best, nil
+	}
+
+	overlapping := ""
+	for _, session := range sm.sessions {
+		end := session.LastActivity
+		if session.EndTime != nil && session.EndTime.After(end) {
+			end = *session.EndTime
+		}
+		if session.Project == "" || session.StartTime.After(orphan.end) || end.Before(orphan.start) {
+			continue
+		}
+		if overlapping != "" && overlapping != session.Project {
+			return defaultProjectName, nil
+		}
+		overlapping = session.Project
+	}
+	if overlapping != "" {
+		return overlapping, nil
+	}
+	return defaultProjectName, nil
+}
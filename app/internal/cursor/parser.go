@@ -12,9 +12,16 @@ import (
 	_ "modernc.org/sqlite" // SQLite driver
 )
 
+const (
+	// ParserVersion identifies the parsing logic used to extract messages.
+	// Increment it when the parser starts extracting new data from Cursor payloads.
+	ParserVersion = 1
+)
+
 // ParserService defines the interface for parsing Cursor conversation data
 type ParserService interface {
 	ParseConversation(composerID string) (*Conversation, error)
+	ParsePayloads(composerID string, composerPayload []byte, bubblePayloads map[string][]byte) (*Conversation, error)
 	ParseAllConversations() ([]*Conversation, error)
 	GetComposerIDs() ([]string, error)
 	Close() error
@@ -178,12 +185,40 @@ func (p *parser) ParseConversation(composerID string) (*Conversation, error) {
 	p.logger.Debug("parsing conversation", "composer_id", composerID)
 
 	// Get composer data
-	composerData, err := p.queryComposerData(composerID)
+	composerData, composerQuarantine, err := p.queryComposerData(composerID)
 	if err != nil {
 		p.logger.Error("failed to query composer data", "composer_id", composerID, "error", err)
 		return nil, fmt.Errorf("failed to query composer data: %w", err)
 	}
 
+	return p.buildConversation(composerID, composerData, composerQuarantine, p.queryBubblePayload)
+}
+
+// ParsePayloads parses a conversation from raw payloads (e.g. restored from the archive)
+// instead of reading Cursor's database. bubblePayloads is keyed by bubble ID.
+func (p *parser) ParsePayloads(composerID string, composerPayload []byte, bubblePayloads map[string][]byte) (*Conversation, error) {
+	p.logger.Debug("parsing conversation from payloads", "composer_id", composerID, "bubble_count", len(bubblePayloads))
+
+	composerData, composerQuarantine, err := p.decodeComposerData(composerID, composerPayload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode composer data: %w", err)
+	}
+
+	lookup := func(composerID, bubbleID string) ([]byte, error) {
+		payload, ok := bubblePayloads[bubbleID]
+		if !ok {
+			return nil, sql.ErrNoRows
+		}
+		return payload, nil
+	}
+	return p.buildConversation(composerID, composerData, composerQuarantine, lookup)
+}
+
+// bubbleFetcher returns the raw JSON for a bubble, or sql.ErrNoRows if it doesn't exist
+type bubbleFetcher func(composerID, bubbleID string) ([]byte, error)
+
+// buildConversation assembles a conversation from decoded composer data and its bubbles
+func (p *parser) buildConversation(composerID string, composerData *composerDataJSON, composerQuarantine *QuarantinedPayload, fetch bubbleFetcher) (*Conversation, error) {
 	// Parse timestamp (Unix milliseconds)
 	createdAt := parseUnixMilliseconds(composerData.CreatedAt)
 
@@ -194,10 +229,15 @@ func (p *parser) ParseConversation(composerID string) (*Conversation, error) {
 		Status:     composerData.Status,
 		CreatedAt:  createdAt,
 		Messages:   []Message{},
+		RawPayload: composerData.raw,
+	}
+	if composerQuarantine != nil {
+		conversation.Quarantined = append(conversation.Quarantined, *composerQuarantine)
 	}
 
 	// Get all message bubbles
-	messages, err := p.queryMessageBubbles(composerID, composerData.FullConversationHeadersOnly)
+	messages, bubbleQuarantine, err := p.queryMessageBubbles(composerID, composerData.FullConversationHeadersOnly, fetch)
+	conversation.Quarantined = append(conversation.Quarantined, bubbleQuarantine...)
 	if err != nil {
 		// Log error but return partial conversation
 		// This allows us to get conversation metadata even if some messages fail
@@ -247,10 +287,15 @@ type composerDataJSON struct {
 		BubbleID string `json:"bubbleId"`
 		Type     int    `json:"type"`
 	} `json:"fullConversationHeadersOnly"`
+
+	raw []byte // Raw JSON the struct was decoded from
 }
 
-// queryComposerData queries and parses composer data from the database
-func (p *parser) queryComposerData(composerID string) (*composerDataJSON, error) {
+// queryComposerData queries and parses composer data from the database.
+// The payload is probed against the expected schema first: a payload that can't be decoded
+// is returned as a *SchemaError, while one with unexpected field types is still parsed and
+// returned alongside a QuarantinedPayload describing the mismatch.
+func (p *parser) queryComposerData(composerID string) (*composerDataJSON, *QuarantinedPayload, error) {
 	key := fmt.Sprintf("composerData:%s", composerID)
 	query := "SELECT value FROM cursorDiskKV WHERE key = ?"
 
@@ -263,17 +308,48 @@ func (p *parser) queryComposerData(composerID string) (*composerDataJSON, error)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			p.logger.Warn("composer data not found", "composer_id", composerID)
-			return nil, fmt.Errorf("composer data not found for ID: %s", composerID)
+			return nil, nil, fmt.Errorf("composer data not found for ID: %s", composerID)
 		}
 		p.logger.Error("failed to query composer data", "composer_id", composerID, "error", err)
-		return nil, fmt.Errorf("failed to query composer data: %w", err)
+		return nil, nil, fmt.Errorf("failed to query composer data: %w", err)
 	}
 
+	return p.decodeComposerData(composerID, valueBlob)
+}
+
+// decodeComposerData probes and decodes a raw composerData payload
+func (p *parser) decodeComposerData(composerID string, valueBlob []byte) (*composerDataJSON, *QuarantinedPayload, error) {
+	quarantined := QuarantinedPayload{
+		Source:     QuarantineSourceComposer,
+		ComposerID: composerID,
+		Payload:    valueBlob,
+	}
+
+	// Probe the payload shape before relying on it
+	var rawComposerData map[string]interface{}
+	if err := json.Unmarshal(valueBlob, &rawComposerData); err != nil {
+		p.logger.Error("failed to parse composer data JSON", "composer_id", composerID, "error", err)
+		quarantined.Reason = fmt.Sprintf("invalid JSON: %v", err)
+		return nil, nil, &SchemaError{Payload: quarantined}
+	}
+	problems := probeComposerShape(rawComposerData)
+
 	// Parse JSON
 	var composerData composerDataJSON
 	if err := json.Unmarshal(valueBlob, &composerData); err != nil {
 		p.logger.Error("failed to parse composer data JSON", "composer_id", composerID, "error", err)
-		return nil, fmt.Errorf("failed to parse composer data JSON: %w", err)
+		quarantined.Reason = joinProblems(append(problems, err.Error()))
+		return nil, nil, &SchemaError{Payload: quarantined}
+	}
+
+	composerData.raw = valueBlob
+
+	var quarantinedResult *QuarantinedPayload
+	if len(problems) > 0 {
+		// Parsed, but some fields were dropped - keep the raw payload for later re-parsing
+		p.logger.Warn("composer data has unexpected schema", "composer_id", composerID, "problems", len(problems))
+		quarantined.Reason = joinProblems(problems)
+		quarantinedResult = &quarantined
 	}
 
 	// Ensure ComposerID is set (may not be in JSON)
@@ -282,7 +358,7 @@ func (p *parser) queryComposerData(composerID string) (*composerDataJSON, error)
 	}
 
 	p.logger.Debug("queried composer data", "composer_id", composerID, "name", composerData.Name, "message_count", len(composerData.FullConversationHeadersOnly))
-	return &composerData, nil
+	return &composerData, quarantinedResult, nil
 }
 
 // bubbleDataJSON represents the JSON structure of bubbleId entries
@@ -293,25 +369,21 @@ type bubbleDataJSON struct {
 	CreatedAt string `json:"createdAt"` // ISO 8601 timestamp
 }
 
-// queryMessageBubbles queries and parses message bubbles from the database
+// queryMessageBubbles queries and parses message bubbles from the database.
+// Bubbles that are corrupted or have unexpected field types are returned as quarantined payloads.
 func (p *parser) queryMessageBubbles(composerID string, headers []struct {
 	BubbleID string `json:"bubbleId"`
 	Type     int    `json:"type"`
-}) ([]Message, error) {
+}, fetch bubbleFetcher) ([]Message, []QuarantinedPayload, error) {
 	var messages []Message
+	var quarantined []QuarantinedPayload
 	var missingCount, corruptedCount, invalidTimestampCount int
 
 	p.logger.Debug("querying message bubbles", "composer_id", composerID, "header_count", len(headers))
 
 	for _, header := range headers {
-		// Query bubble data
-		key := fmt.Sprintf("bubbleId:%s:%s", composerID, header.BubbleID)
-		query := "SELECT value FROM cursorDiskKV WHERE key = ?"
-
-		var valueBlob []byte
-		err := p.retryQueryWithBackoff(5, func() error {
-			return p.db.QueryRow(query, key).Scan(&valueBlob)
-		})
+		// Fetch bubble data
+		valueBlob, err := fetch(composerID, header.BubbleID)
 		if err != nil {
 			if err == sql.ErrNoRows {
 				// Missing bubble - log warning but continue
@@ -320,7 +392,7 @@ func (p *parser) queryMessageBubbles(composerID string, headers []struct {
 				continue
 			}
 			p.logger.Error("failed to query bubble data", "composer_id", composerID, "bubble_id", header.BubbleID, "error", err)
-			return nil, fmt.Errorf("failed to query bubble data: %w", err)
+			return nil, quarantined, fmt.Errorf("failed to query bubble data: %w", err)
 		}
 
 		// Parse JSON into a map first to capture all fields
@@ -329,9 +401,28 @@ func (p *parser) queryMessageBubbles(composerID string, headers []struct {
 			// Corrupted JSON - skip this message but continue
 			p.logger.Warn("corrupted JSON in message bubble, skipping", "composer_id", composerID, "bubble_id", header.BubbleID, "error", err)
 			corruptedCount++
+			quarantined = append(quarantined, QuarantinedPayload{
+				Source:     QuarantineSourceBubble,
+				ComposerID: composerID,
+				BubbleID:   header.BubbleID,
+				Reason:     fmt.Sprintf("invalid JSON: %v", err),
+				Payload:    valueBlob,
+			})
 			continue
 		}
 
+		// Probe the shape - unexpected field types mean data would be silently dropped
+		if problems := probeBubbleShape(rawBubbleData); len(problems) > 0 {
+			p.logger.Warn("message bubble has unexpected schema", "composer_id", composerID, "bubble_id", header.BubbleID, "problems", len(problems))
+			quarantined = append(quarantined, QuarantinedPayload{
+				Source:     QuarantineSourceBubble,
+				ComposerID: composerID,
+				BubbleID:   header.BubbleID,
+				Reason:     joinProblems(problems),
+				Payload:    valueBlob,
+			})
+		}
+
 		// Extract known fields
 		bubbleID, _ := rawBubbleData["bubbleId"].(string)
 		if bubbleID == "" {
@@ -403,19 +494,33 @@ func (p *parser) queryMessageBubbles(composerID string, headers []struct {
 			HasToolCalls:  len(toolCalls) > 0,
 			CreatedAt:     createdAt,
 			Metadata:      metadata,
+			RawPayload:    valueBlob,
+			ParserVersion: ParserVersion,
 		}
 
 		messages = append(messages, message)
 		p.logger.Debug("parsed message bubble", "composer_id", composerID, "bubble_id", header.BubbleID, "role", role)
 	}
 
-	if missingCount > 0 || corruptedCount > 0 || invalidTimestampCount > 0 {
-		p.logger.Warn("message bubble parsing completed with issues", "composer_id", composerID, "total_headers", len(headers), "successful", len(messages), "missing", missingCount, "corrupted", corruptedCount, "invalid_timestamps", invalidTimestampCount)
+	if missingCount > 0 || corruptedCount > 0 || invalidTimestampCount > 0 || len(quarantined) > 0 {
+		p.logger.Warn("message bubble parsing completed with issues", "composer_id", composerID, "total_headers", len(headers), "successful", len(messages), "missing", missingCount, "corrupted", corruptedCount, "invalid_timestamps", invalidTimestampCount, "quarantined", len(quarantined))
 	} else {
 		p.logger.Debug("message bubble parsing completed", "composer_id", composerID, "message_count", len(messages))
 	}
 
-	return messages, nil
+	return messages, quarantined, nil
+}
+
+// queryBubblePayload queries the raw JSON for a bubble from Cursor's database
+func (p *parser) queryBubblePayload(composerID, bubbleID string) ([]byte, error) {
+	key := fmt.Sprintf("bubbleId:%s:%s", composerID, bubbleID)
+	query := "SELECT value FROM cursorDiskKV WHERE key = ?"
+
+	var valueBlob []byte
+	err := p.retryQueryWithBackoff(5, func() error {
+		return p.db.QueryRow(query, key).Scan(&valueBlob)
+	})
+	return valueBlob, err
 }
 
 // parseUnixMilliseconds parses a Unix timestamp in milliseconds to time.Time
@@ -19,17 +19,19 @@ type PollerService interface {
 
 // poller implements PollerService for polling Cursor database updates
 type poller struct {
-	config    *config.Config
-	updater   ConversationUpdater
-	interval  time.Duration
-	ticker    *time.Ticker
-	done      chan struct{}
-	pollChan  chan struct{}
-	started   bool
-	mu        sync.Mutex
-	logger    logging.Logger
-	wg        sync.WaitGroup
-	pollCount int64 // Track number of polls for periodic logging
+	config          *config.Config
+	detector        ChangeDetector
+	interval        time.Duration
+	debounce        time.Duration
+	ticker          *time.Ticker
+	done            chan struct{}
+	pollChan        chan struct{}
+	started         bool
+	mu              sync.Mutex
+	logger          logging.Logger
+	wg              sync.WaitGroup
+	pollCount       int64  // Track number of polls for periodic logging
+	lastFingerprint string // Fingerprint of the data last signalled to consumers
 }
 
 const (
@@ -37,15 +39,17 @@ const (
 	defaultPollInterval = 7 * time.Second
 	// minPollInterval is the minimum allowed polling interval
 	minPollInterval = 1 * time.Second
+	// maxDebounceWait caps how long a poll waits for writes to settle before signalling anyway
+	maxDebounceWait = 30 * time.Second
 )
 
 // NewPoller creates a new poller instance
-func NewPoller(cfg *config.Config, updater ConversationUpdater) (PollerService, error) {
+func NewPoller(cfg *config.Config, detector ChangeDetector) (PollerService, error) {
 	if cfg == nil {
 		return nil, fmt.Errorf("config cannot be nil")
 	}
-	if updater == nil {
-		return nil, fmt.Errorf("updater cannot be nil")
+	if detector == nil {
+		return nil, fmt.Errorf("change detector cannot be nil")
 	}
 
 	// Create logger
@@ -70,10 +74,17 @@ func NewPoller(cfg *config.Config, updater ConversationUpdater) (PollerService,
 		logger.Warn("polling interval too small, using minimum", "requested_seconds", intervalSeconds, "minimum_seconds", int(minPollInterval.Seconds()))
 	}
 
+	// Debounce window - zero disables debouncing
+	debounce := time.Duration(cfg.Cursor.DebounceSeconds) * time.Second
+	if debounce < 0 {
+		debounce = 0
+	}
+
 	return &poller{
 		config:   cfg,
-		updater:  updater,
+		detector: detector,
 		interval: interval,
+		debounce: debounce,
 		done:     make(chan struct{}),
 		pollChan: make(chan struct{}, 1), // Buffered channel to prevent blocking
 		started:  false,
@@ -120,37 +131,85 @@ func (p *poller) pollLoop() {
 	}
 }
 
-// performPoll performs a single poll operation
+// performPoll performs a single poll operation.
+// A poll signal is only sent when the conversation data fingerprint has changed and
+// has stayed stable for the debounce window, so bursts of writes while Cursor streams
+// a response are coalesced into a single capture pass.
 func (p *poller) performPoll() {
 	pollNum := atomic.AddInt64(&p.pollCount, 1)
 	p.logger.Debug("performing poll", "poll_number", pollNum)
 
-	// Call DetectUpdatedComposers to check for updates
-	updatedComposers, err := p.updater.DetectUpdatedComposers()
+	fingerprint, err := p.detector.Fingerprint()
 	if err != nil {
-		// Log error but continue polling (graceful degradation)
-		p.logger.Error("failed to detect updated composers during poll", "error", err)
+		// Can't tell whether anything changed - fall back to a full scan (graceful degradation)
+		p.logger.Warn("failed to compute change fingerprint, signalling full scan", "error", err)
+		p.sendSignal()
 		return
 	}
 
-	// Log poll results - INFO when updates found, DEBUG otherwise
-	// Also log periodically (every 10 polls) at INFO level to show polling is active
-	if len(updatedComposers) > 0 {
-		p.logger.Info("poll completed - detected updated conversations", "updated_count", len(updatedComposers))
-	} else if pollNum%10 == 0 {
+	if fingerprint == p.lastFingerprint {
 		// Log every 10th poll at INFO level to show polling is working
-		p.logger.Info("poll completed - no updates detected", "poll_number", pollNum)
-	} else {
-		p.logger.Debug("poll completed - no updates detected")
+		if pollNum%10 == 0 {
+			p.logger.Info("poll completed - no changes detected", "poll_number", pollNum)
+		} else {
+			p.logger.Debug("poll completed - no changes detected")
+		}
+		return
+	}
+
+	// Wait for writes to settle before signalling
+	settled, ok := p.waitForStableFingerprint(fingerprint)
+	if !ok {
+		// Shutdown requested while debouncing
+		return
 	}
 
-	// Send poll signal (non-blocking due to buffered channel)
+	p.lastFingerprint = settled
+	p.logger.Info("poll completed - conversation data changed", "poll_number", pollNum)
+	p.sendSignal()
+}
+
+// waitForStableFingerprint re-checks the fingerprint every debounce interval until it stops
+// changing or maxDebounceWait elapses. Returns false if the poller is stopped meanwhile.
+func (p *poller) waitForStableFingerprint(fingerprint string) (string, bool) {
+	if p.debounce <= 0 {
+		return fingerprint, true
+	}
+
+	deadline := time.Now().Add(maxDebounceWait)
+	for {
+		select {
+		case <-p.done:
+			return "", false
+		case <-time.After(p.debounce):
+		}
+
+		next, err := p.detector.Fingerprint()
+		if err != nil {
+			// Use what we have - the capture pass will re-read the data anyway
+			p.logger.Debug("failed to re-check fingerprint during debounce", "error", err)
+			return fingerprint, true
+		}
+		if next == fingerprint {
+			return fingerprint, true
+		}
+		fingerprint = next
+
+		if time.Now().After(deadline) {
+			p.logger.Debug("debounce wait exceeded, signalling with latest fingerprint", "max_wait_seconds", int(maxDebounceWait.Seconds()))
+			return fingerprint, true
+		}
+	}
+}
+
+// sendSignal sends a poll signal (non-blocking due to buffered channel)
+func (p *poller) sendSignal() {
 	select {
 	case p.pollChan <- struct{}{}:
 		p.logger.Debug("poll signal sent")
 	default:
-		// Channel full - log warning but don't block
-		p.logger.Warn("poll channel full, dropping poll signal")
+		// Channel full - a capture pass is already pending, which will pick up these changes
+		p.logger.Debug("poll channel full, capture pass already pending")
 	}
 }
 
@@ -176,6 +235,11 @@ func (p *poller) Stop() error {
 	// Wait for polling goroutine to finish
 	p.wg.Wait()
 
+	// Release the change detector's database connection
+	if err := p.detector.Close(); err != nil {
+		p.logger.Warn("failed to close change detector", "error", err)
+	}
+
 	// Close poll channel
 	close(p.pollChan)
 
@@ -0,0 +1,152 @@
+package cursor
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+// fakeChangeDetector returns a scripted sequence of fingerprints
+type fakeChangeDetector struct {
+	mu           sync.Mutex
+	fingerprints []string
+	err          error
+	calls        int
+}
+
+func (f *fakeChangeDetector) Fingerprint() (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if f.err != nil {
+		return "", f.err
+	}
+	// Repeat the last fingerprint once the script runs out
+	idx := f.calls - 1
+	if idx >= len(f.fingerprints) {
+		idx = len(f.fingerprints) - 1
+	}
+	return f.fingerprints[idx], nil
+}
+
+func (f *fakeChangeDetector) Close() error { return nil }
+
+// newTestPoller creates a poller with the given detector without starting the ticker
+func newTestPoller(detector ChangeDetector, debounce time.Duration) *poller {
+	return &poller{
+		config:   &config.Config{},
+		detector: detector,
+		interval: time.Second,
+		debounce: debounce,
+		done:     make(chan struct{}),
+		pollChan: make(chan struct{}, 1),
+		logger:   logging.NewNoopLogger(),
+	}
+}
+
+// drainSignals returns how many poll signals are pending
+func drainSignals(p *poller) int {
+	count := 0
+	for {
+		select {
+		case <-p.pollChan:
+			count++
+		default:
+			return count
+		}
+	}
+}
+
+func TestNewPoller(t *testing.T) {
+	cfg := &config.Config{
+		Cursor: config.CursorConfig{
+			PollIntervalSeconds: 7,
+			DebounceSeconds:     2,
+		},
+	}
+
+	if _, err := NewPoller(cfg, &fakeChangeDetector{fingerprints: []string{"a"}}); err != nil {
+		t.Fatalf("NewPoller() error = %v, want nil", err)
+	}
+	if _, err := NewPoller(nil, &fakeChangeDetector{}); err == nil {
+		t.Error("NewPoller(nil, ...) expected error, got nil")
+	}
+	if _, err := NewPoller(cfg, nil); err == nil {
+		t.Error("NewPoller(..., nil) expected error, got nil")
+	}
+}
+
+func TestPoller_SignalsOnlyOnChange(t *testing.T) {
+	detector := &fakeChangeDetector{fingerprints: []string{"a", "a", "b"}}
+	p := newTestPoller(detector, 0)
+
+	// First poll always signals (nothing captured yet)
+	p.performPoll()
+	if got := drainSignals(p); got != 1 {
+		t.Errorf("first poll signals = %d, want 1", got)
+	}
+
+	// Unchanged fingerprint - no signal
+	p.performPoll()
+	if got := drainSignals(p); got != 0 {
+		t.Errorf("unchanged poll signals = %d, want 0", got)
+	}
+
+	// Changed fingerprint - signal
+	p.performPoll()
+	if got := drainSignals(p); got != 1 {
+		t.Errorf("changed poll signals = %d, want 1", got)
+	}
+}
+
+func TestPoller_DebounceWaitsForStableFingerprint(t *testing.T) {
+	// Data keeps changing for three checks and then settles
+	detector := &fakeChangeDetector{fingerprints: []string{"a", "b", "c", "c"}}
+	p := newTestPoller(detector, 10*time.Millisecond)
+
+	p.performPoll()
+	if got := drainSignals(p); got != 1 {
+		t.Errorf("signals = %d, want 1", got)
+	}
+	if detector.calls != 4 {
+		t.Errorf("fingerprint calls = %d, want 4", detector.calls)
+	}
+	if p.lastFingerprint != "c" {
+		t.Errorf("lastFingerprint = %q, want %q", p.lastFingerprint, "c")
+	}
+}
+
+func TestPoller_DetectorErrorFallsBackToSignal(t *testing.T) {
+	detector := &fakeChangeDetector{err: errors.New("database is locked")}
+	p := newTestPoller(detector, 0)
+
+	p.performPoll()
+	if got := drainSignals(p); got != 1 {
+		t.Errorf("signals = %d, want 1", got)
+	}
+}
+
+func TestPoller_DebounceStopsOnShutdown(t *testing.T) {
+	detector := &fakeChangeDetector{fingerprints: []string{"a", "b", "c", "d", "e"}}
+	p := newTestPoller(detector, time.Hour)
+
+	finished := make(chan struct{})
+	go func() {
+		p.performPoll()
+		close(finished)
+	}()
+
+	close(p.done)
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Fatal("performPoll() did not return after shutdown")
+	}
+	if got := drainSignals(p); got != 0 {
+		t.Errorf("signals after shutdown = %d, want 0", got)
+	}
+}
@@ -35,13 +35,21 @@ type projectDetector struct {
 	config                    *config.Config
 	logger                    logging.Logger
 	workspaceStoragePath      string
+	namer                     ProjectNamer // Nil names projects by directory alone
 	mu                        sync.RWMutex
 	workspaceHashToProjectPath map[string]string // workspaceHash → projectPath
 	composerIDToWorkspaceHash map[string]string // composerID → workspaceHash
 }
 
-// NewProjectDetector creates a new project detector instance
+// NewProjectDetector creates a new project detector instance that names projects
+// by their directory. Use NewProjectDetectorWithNamer to name them by git remote.
 func NewProjectDetector(cfg *config.Config) (ProjectDetector, error) {
+	return NewProjectDetectorWithNamer(cfg, nil)
+}
+
+// NewProjectDetectorWithNamer creates a project detector that names workspace
+// paths with namer, so checkouts of the same repository share a project
+func NewProjectDetectorWithNamer(cfg *config.Config, namer ProjectNamer) (ProjectDetector, error) {
 	if cfg == nil {
 		return nil, fmt.Errorf("config cannot be nil")
 	}
@@ -60,6 +68,7 @@ func NewProjectDetector(cfg *config.Config) (ProjectDetector, error) {
 		config:                    cfg,
 		logger:                    logger,
 		workspaceStoragePath:      workspaceStoragePath,
+		namer:                     namer,
 		workspaceHashToProjectPath: make(map[string]string),
 		composerIDToWorkspaceHash:  make(map[string]string),
 	}
@@ -100,14 +109,23 @@ func (pd *projectDetector) DetectProject(conv *Conversation) (string, error) {
 		return pd.NormalizeProjectName(defaultProjectName), nil
 	}
 
-	// Normalize and return project name
+	// Name the workspace, by its repository's remote when there's a namer
 	projectName := pd.NormalizeProjectName(projectPath)
+	if pd.namer != nil {
+		projectName = pd.namer.ProjectName(projectPath)
+	}
 	pd.logger.Debug("detected project for conversation", "composer_id", conv.ComposerID, "project", projectName)
 	return projectName, nil
 }
 
 // NormalizeProjectName normalizes a project path or name to a filesystem-safe project name
 func (pd *projectDetector) NormalizeProjectName(name string) string {
+	return NormalizeProjectName(name)
+}
+
+// NormalizeProjectName normalizes a project path or name to a filesystem-safe project name.
+// Capture sources other than Cursor use it so project names match across sources.
+func NormalizeProjectName(name string) string {
 	if name == "" {
 		return defaultProjectName
 	}
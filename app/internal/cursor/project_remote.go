@@ -0,0 +1,189 @@
+package cursor
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+const (
+	// maxRemoteNameSuffix bounds the numbered names tried when a remote's other names are taken
+	maxRemoteNameSuffix = 100
+)
+
+// scpRemotePattern matches scp-style remotes such as git@github.com:acme/api.git
+var scpRemotePattern = regexp.MustCompile(`^(?:[^@/]+@)?([^:/]+):(.+)$`)
+
+// ProjectNamer names projects from workspace paths. Checkouts of a repository
+// with an origin remote share one name wherever they're checked out, and
+// unrelated repositories in directories with the same name get distinct names.
+// Paths without a remote are named by NormalizeProjectName.
+type ProjectNamer interface {
+	ProjectName(path string) string
+}
+
+// projectNamer implements ProjectNamer with names claimed in the project_remotes table
+type projectNamer struct {
+	db     *sql.DB
+	logger logging.Logger
+	mu     sync.Mutex
+	names  map[string]string // Path → project name, for paths already named
+}
+
+// NewProjectNamer creates a project namer backed by the database
+func NewProjectNamer(db *sql.DB, logger logging.Logger) (ProjectNamer, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database cannot be nil")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	return &projectNamer{
+		db:     db,
+		logger: logger.With("component", "project_namer"),
+		names:  make(map[string]string),
+	}, nil
+}
+
+// ProjectName returns the project name of a workspace path or file:// URI.
+// Values that aren't absolute paths are normalized as names.
+func (pn *projectNamer) ProjectName(path string) string {
+	dir := path
+	if strings.HasPrefix(dir, "file://") {
+		if parsed, err := url.Parse(dir); err == nil {
+			dir = parsed.Path
+		}
+	}
+	if !filepath.IsAbs(dir) {
+		return NormalizeProjectName(path)
+	}
+	dir = filepath.Clean(dir)
+
+	pn.mu.Lock()
+	defer pn.mu.Unlock()
+
+	if name, ok := pn.names[dir]; ok {
+		return name
+	}
+
+	name := NormalizeProjectName(dir)
+	if remote := NormalizeRemoteURL(OriginURL(dir)); remote != "" {
+		claimed, err := pn.claim(remote)
+		if err != nil {
+			// Fall back to the directory name without caching, so the claim is retried
+			pn.logger.Warn("failed to name project by remote, using the directory name", "path", dir, "remote", remote, "error", err)
+			return name
+		}
+		name = claimed
+	}
+	pn.names[dir] = name
+	return name
+}
+
+// claim returns the project name of a remote, claiming the first free candidate
+// (repo, owner-repo, then the whole remote) the first time the remote is seen
+func (pn *projectNamer) claim(remote string) (string, error) {
+	var name string
+	err := pn.db.QueryRow("SELECT project FROM project_remotes WHERE remote = ?", remote).Scan(&name)
+	if err == nil {
+		return name, nil
+	}
+	if err != sql.ErrNoRows {
+		return "", fmt.Errorf("failed to look up remote: %w", err)
+	}
+
+	candidates := remoteProjectNames(remote)
+	base := candidates[len(candidates)-1]
+	for i := 2; i <= maxRemoteNameSuffix; i++ {
+		candidates = append(candidates, fmt.Sprintf("%s-%d", base, i))
+	}
+
+	for _, candidate := range candidates {
+		// Another namer may claim the remote or the name concurrently; the
+		// insert is ignored then and the lookup tells which
+		if _, err := pn.db.Exec(`
+			INSERT OR IGNORE INTO project_remotes (remote, project, created_at) VALUES (?, ?, ?)
+		`, remote, candidate, time.Now()); err != nil {
+			return "", fmt.Errorf("failed to claim project name: %w", err)
+		}
+		err := pn.db.QueryRow("SELECT project FROM project_remotes WHERE remote = ?", remote).Scan(&name)
+		if err == nil {
+			if name != candidates[0] {
+				pn.logger.Info("project name taken by another remote, using a longer one", "remote", remote, "project", name)
+			}
+			return name, nil
+		}
+		if err != sql.ErrNoRows {
+			return "", fmt.Errorf("failed to look up remote: %w", err)
+		}
+	}
+	return "", fmt.Errorf("no free project name for remote %s", remote)
+}
+
+// remoteProjectNames returns the names a remote can claim, shortest first
+func remoteProjectNames(remote string) []string {
+	parts := strings.Split(remote, "/")
+	names := []string{NormalizeProjectName(parts[len(parts)-1])}
+	if len(parts) >= 2 {
+		names = append(names, NormalizeProjectName(parts[len(parts)-2]+"-"+parts[len(parts)-1]))
+	}
+	if len(parts) >= 3 {
+		names = append(names, NormalizeProjectName(strings.Join(parts, "-")))
+	}
+	return names
+}
+
+// OriginURL returns the URL of the origin remote of the repository at dir, or
+// an empty string when dir isn't a repository root or has no origin. Worktrees
+// read the remote of their main repository.
+func OriginURL(dir string) string {
+	repo, err := git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{EnableDotGitCommonDir: true})
+	if err != nil {
+		return ""
+	}
+	remote, err := repo.Remote("origin")
+	if err != nil || len(remote.Config().URLs) == 0 {
+		return ""
+	}
+	return remote.Config().URLs[0]
+}
+
+// NormalizeRemoteURL reduces the forms of a remote URL to host/path, so
+// git@github.com:acme/api.git, https://github.com/acme/api, and
+// ssh://git@github.com/acme/api.git all become github.com/acme/api. Local
+// remotes keep their cleaned path.
+func NormalizeRemoteURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+
+	if strings.HasPrefix(raw, "file://") || filepath.IsAbs(raw) {
+		return strings.TrimSuffix(filepath.Clean(strings.TrimPrefix(raw, "file://")), ".git")
+	}
+
+	var host, path string
+	if parsed, err := url.Parse(raw); err == nil && parsed.Scheme != "" && parsed.Host != "" {
+		host, path = parsed.Hostname(), parsed.Path
+	} else if match := scpRemotePattern.FindStringSubmatch(raw); match != nil {
+		host, path = match[1], match[2]
+	} else {
+		return ""
+	}
+
+	path = strings.Trim(path, "/")
+	path = strings.TrimSuffix(path, ".git")
+	if path == "" {
+		return ""
+	}
+	return strings.ToLower(host) + "/" + path
+}
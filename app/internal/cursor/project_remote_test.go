@@ -0,0 +1,100 @@
+package cursor
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+func TestNormalizeRemoteURL(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{raw: "git@github.com:acme/api.git", want: "github.com/acme/api"},
+		{raw: "https://GitHub.com/acme/api", want: "github.com/acme/api"},
+		{raw: "https://user@github.com/acme/api.git/", want: "github.com/acme/api"},
+		{raw: "ssh://git@github.com:22/acme/api.git", want: "github.com/acme/api"},
+		{raw: "file:///srv/git/api.git", want: "/srv/git/api"},
+		{raw: "/srv/git/api", want: "/srv/git/api"},
+		{raw: "", want: ""},
+		{raw: "api", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			if got := NormalizeRemoteURL(tt.raw); got != tt.want {
+				t.Errorf("NormalizeRemoteURL(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProjectNamer_ProjectName(t *testing.T) {
+	tmpDir := t.TempDir()
+	testDB, err := sql.Open("sqlite", filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	defer testDB.Close()
+	if err := db.RunMigrations(testDB); err != nil {
+		t.Fatalf("Failed to run migrations: %v", err)
+	}
+
+	initRepo := func(path, remote string) string {
+		t.Helper()
+		repo, err := git.PlainInit(path, false)
+		if err != nil {
+			t.Fatalf("Failed to init repository: %v", err)
+		}
+		if remote != "" {
+			if _, err := repo.CreateRemote(&gitconfig.RemoteConfig{Name: "origin", URLs: []string{remote}}); err != nil {
+				t.Fatalf("Failed to create remote: %v", err)
+			}
+		}
+		return path
+	}
+
+	// Two checkouts of one repository, and an unrelated repository also named api
+	checkout := initRepo(filepath.Join(tmpDir, "work", "api"), "git@github.com:acme/api.git")
+	clone := initRepo(filepath.Join(tmpDir, "review", "acme-api"), "https://github.com/acme/api")
+	other := initRepo(filepath.Join(tmpDir, "side", "api"), "git@gitlab.com:beta/api.git")
+	local := initRepo(filepath.Join(tmpDir, "scratch"), "")
+
+	namer, err := NewProjectNamer(testDB, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewProjectNamer() error = %v", err)
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: checkout, want: "api"},
+		{path: clone, want: "api"},
+		{path: "file://" + clone, want: "api"},
+		{path: other, want: "beta-api"},
+		{path: local, want: "scratch"},
+		{path: "My Project", want: NormalizeProjectName("My Project")},
+		{path: "", want: defaultProjectName},
+	}
+	for _, tt := range tests {
+		if got := namer.ProjectName(tt.path); got != tt.want {
+			t.Errorf("ProjectName(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+
+	// Names are claimed in the database, so a new namer keeps them
+	namer, err = NewProjectNamer(testDB, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewProjectNamer() error = %v", err)
+	}
+	if got := namer.ProjectName(other); got != "beta-api" {
+		t.Errorf("ProjectName(%q) with a new namer = %q, want beta-api", other, got)
+	}
+}
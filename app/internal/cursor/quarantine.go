@@ -0,0 +1,132 @@
+package cursor
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+// QuarantineStorage defines the interface for storing Cursor payloads that failed schema probing
+type QuarantineStorage interface {
+	StorePayloads(payloads []QuarantinedPayload) error
+	CountBySource() (map[string]int, error)
+}
+
+// quarantineStorage implements QuarantineStorage for database persistence
+type quarantineStorage struct {
+	db     *sql.DB
+	logger logging.Logger
+}
+
+// NewQuarantineStorage creates a new quarantine storage instance
+func NewQuarantineStorage(db *sql.DB, logger logging.Logger) (QuarantineStorage, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database cannot be nil")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	// Use component-specific logger
+	logger = logger.With("component", "quarantine_storage")
+
+	return &quarantineStorage{
+		db:     db,
+		logger: logger,
+	}, nil
+}
+
+// StorePayloads upserts quarantined payloads. A payload seen again keeps its first_seen_at
+// but gets the latest reason, raw JSON, and last_seen_at.
+func (qs *quarantineStorage) StorePayloads(payloads []QuarantinedPayload) error {
+	if len(payloads) == 0 {
+		return nil
+	}
+
+	tx, err := qs.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	query := `
+		INSERT INTO quarantined_payloads (source, composer_id, bubble_id, reason, payload, first_seen_at, last_seen_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(source, composer_id, bubble_id) DO UPDATE SET
+			reason = excluded.reason,
+			payload = excluded.payload,
+			last_seen_at = excluded.last_seen_at
+	`
+	for _, payload := range payloads {
+		if _, err := tx.Exec(query, payload.Source, payload.ComposerID, payload.BubbleID, payload.Reason, payload.Payload, now, now); err != nil {
+			return fmt.Errorf("failed to store quarantined payload: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	qs.logger.Warn("quarantined payloads with unrecognised schema", "count", len(payloads))
+	return nil
+}
+
+// CountBySource returns the number of quarantined payloads grouped by source
+func (qs *quarantineStorage) CountBySource() (map[string]int, error) {
+	return CountQuarantinedPayloads(qs.db)
+}
+
+// CountQuarantinedPayloads returns the number of quarantined payloads grouped by source.
+// Exposed as a function so read-only callers (status, doctor) don't need a logger.
+func CountQuarantinedPayloads(db *sql.DB) (map[string]int, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database cannot be nil")
+	}
+
+	rows, err := db.Query("SELECT source, COUNT(*) FROM quarantined_payloads GROUP BY source")
+	if err != nil {
+		return nil, fmt.Errorf("failed to count quarantined payloads: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var source string
+		var count int
+		if err := rows.Scan(&source, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan quarantine count: %w", err)
+		}
+		counts[source] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating quarantine counts: %w", err)
+	}
+
+	return counts, nil
+}
+
+// recordQuarantine stores payloads flagged while parsing a conversation, including the
+// payload carried by a SchemaError when the conversation couldn't be parsed at all.
+// Failures are logged rather than returned so quarantine never blocks capture.
+func recordQuarantine(store QuarantineStorage, logger logging.Logger, conversation *Conversation, parseErr error) {
+	if store == nil {
+		return
+	}
+
+	var payloads []QuarantinedPayload
+	if conversation != nil {
+		payloads = append(payloads, conversation.Quarantined...)
+	}
+	var schemaErr *SchemaError
+	if errors.As(parseErr, &schemaErr) {
+		payloads = append(payloads, schemaErr.Payload)
+	}
+
+	if err := store.StorePayloads(payloads); err != nil {
+		logger.Warn("failed to store quarantined payloads", "count", len(payloads), "error", err)
+	}
+}
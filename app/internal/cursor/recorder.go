@@ -0,0 +1,117 @@
+package cursor
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/logging"
+	"github.com/stwalsh4118/clio/internal/sensitive"
+)
+
+// ConversationRecorder stores complete conversations read by capture sources other than
+// Cursor (such as Zed and JetBrains), which re-read whole conversations rather than
+// tracking processed message counts
+type ConversationRecorder interface {
+	// Record stores a conversation. New conversations join the project's active session
+	// (or start one); known conversations are updated in the session they were first
+	// captured in. Unchanged conversations are skipped. Reports whether anything was written.
+	Record(conversation *Conversation, project string) (bool, error)
+}
+
+// conversationRecorder implements ConversationRecorder on top of the session manager
+type conversationRecorder struct {
+	db             *sql.DB
+	storage        ConversationStorage
+	gate           *sensitive.Gate
+	sessionManager SessionManager
+	logger         logging.Logger
+}
+
+// NewConversationRecorder creates a recorder that attributes conversations through sessionManager
+func NewConversationRecorder(db *sql.DB, sessionManager SessionManager, logger logging.Logger) (ConversationRecorder, error) {
+	return NewConversationRecorderWithGate(db, sessionManager, nil, logger)
+}
+
+// NewConversationRecorderWithGate creates a recorder whose updates pass the
+// sensitive gate before they're stored. A nil gate stores messages as captured.
+func NewConversationRecorderWithGate(db *sql.DB, sessionManager SessionManager, gate *sensitive.Gate, logger logging.Logger) (ConversationRecorder, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database cannot be nil")
+	}
+	if sessionManager == nil {
+		return nil, fmt.Errorf("session manager cannot be nil")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	storage, err := NewConversationStorageWithGate(db, gate, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create conversation storage: %w", err)
+	}
+
+	return &conversationRecorder{
+		db:             db,
+		storage:        storage,
+		gate:           gate,
+		sessionManager: sessionManager,
+		logger:         logger,
+	}, nil
+}
+
+// Record stores a new or changed conversation
+func (r *conversationRecorder) Record(conversation *Conversation, project string) (bool, error) {
+	if conversation == nil || len(conversation.Messages) == 0 {
+		return false, nil
+	}
+
+	existing, err := r.storage.GetConversationByComposerID(conversation.ComposerID)
+	if err != nil {
+		session, err := r.sessionManager.GetOrCreateSession(project, conversation)
+		if err != nil {
+			return false, fmt.Errorf("failed to get or create session: %w", err)
+		}
+		r.logger.Info("recorded new conversation", "composer_id", conversation.ComposerID, "source", conversation.Source, "project", project, "session_id", session.ID, "message_count", len(conversation.Messages))
+		return true, nil
+	}
+
+	// Compared as stored, so conversations with redacted or dropped messages aren't rewritten each time they're read
+	if sameMessages(existing.Messages, gatedMessages(r.gate, conversation.Messages)) && existing.Name == conversation.Name {
+		return false, nil
+	}
+
+	var sessionID string
+	if err := r.db.QueryRow("SELECT session_id FROM conversations WHERE id = ?", conversation.ComposerID).Scan(&sessionID); err != nil {
+		return false, fmt.Errorf("failed to get session for conversation: %w", err)
+	}
+	if err := r.storage.StoreConversation(conversation, sessionID); err != nil {
+		return false, fmt.Errorf("failed to store conversation: %w", err)
+	}
+
+	lastActivity := conversation.Messages[len(conversation.Messages)-1].CreatedAt
+	if _, err := r.db.Exec(`
+		UPDATE sessions
+		SET last_activity = ?,
+			updated_at = ?
+		WHERE id = ? AND (last_activity IS NULL OR ? > last_activity)
+	`, lastActivity, time.Now(), sessionID, lastActivity); err != nil {
+		r.logger.Warn("failed to update session metadata", "session_id", sessionID, "error", err)
+	}
+
+	r.logger.Info("recorded conversation update", "composer_id", conversation.ComposerID, "source", conversation.Source, "session_id", sessionID, "message_count", len(conversation.Messages))
+	return true, nil
+}
+
+// sameMessages reports whether two message lists have the same IDs and text
+func sameMessages(a, b []Message) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].BubbleID != b[i].BubbleID || a[i].Text != b[i].Text {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,51 @@
+package cursor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+func TestConversationRecorder_UpdatesInOriginalSession(t *testing.T) {
+	cfg := createTestConfig(t)
+	database := createTestDB(t, cfg)
+	defer database.Close()
+
+	sm, err := NewSessionManager(cfg, database)
+	if err != nil {
+		t.Fatalf("NewSessionManager() error = %v", err)
+	}
+	recorder, err := NewConversationRecorder(database, sm, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewConversationRecorder() error = %v", err)
+	}
+
+	createdAt := time.Now().Add(-time.Minute)
+	conv := createTestConversationWithMessages(t, "zed-1", 2, createdAt)
+	conv.Source = "zed"
+	if recorded, err := recorder.Record(conv, "alpha"); err != nil || !recorded {
+		t.Fatalf("Record() new = %v, %v", recorded, err)
+	}
+	if recorded, err := recorder.Record(conv, "alpha"); err != nil || recorded {
+		t.Errorf("Record() unchanged = %v, %v; want skipped", recorded, err)
+	}
+
+	// Later messages stay with the original session even when attributed elsewhere
+	updated := createTestConversationWithMessages(t, "zed-1", 3, createdAt)
+	updated.Source = "zed"
+	if recorded, err := recorder.Record(updated, "beta"); err != nil || !recorded {
+		t.Fatalf("Record() update = %v, %v", recorded, err)
+	}
+
+	var project string
+	var messages int
+	if err := database.QueryRow(`
+		SELECT s.project, c.message_count FROM conversations c JOIN sessions s ON s.id = c.session_id WHERE c.id = 'zed-1'
+	`).Scan(&project, &messages); err != nil {
+		t.Fatalf("failed to query conversation: %v", err)
+	}
+	if project != "alpha" || messages != 3 {
+		t.Errorf("project/messages = %q/%d, want alpha/3", project, messages)
+	}
+}
@@ -0,0 +1,225 @@
+package cursor
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/logging"
+	"github.com/stwalsh4118/clio/internal/sensitive"
+)
+
+const (
+	// ReparseSourceAuto uses archived payloads when available, otherwise the live Cursor database
+	ReparseSourceAuto = "auto"
+	// ReparseSourceArchive only uses archived raw payloads
+	ReparseSourceArchive = "archive"
+	// ReparseSourceCursor only uses the live Cursor database
+	ReparseSourceCursor = "cursor"
+)
+
+// ReparseOptions controls how a conversation is re-parsed
+type ReparseOptions struct {
+	Source string // ReparseSourceAuto, ReparseSourceArchive, or ReparseSourceCursor
+	Force  bool   // Upgrade messages even if they were extracted by the current parser version
+	DryRun bool   // Report what would change without writing
+}
+
+// ReparseResult describes the outcome of re-parsing a single conversation
+type ReparseResult struct {
+	ComposerID       string
+	Source           string // Source actually used (archive or cursor)
+	MessagesParsed   int    // Messages extracted from the source
+	MessagesUpgraded int    // Stored messages rewritten (or that would be, for dry runs)
+}
+
+// Reparser defines the interface for re-extracting stored conversations with the current parser
+type Reparser interface {
+	Reparse(composerID string, opts ReparseOptions) (*ReparseResult, error)
+	GetStoredComposerIDs() ([]string, error)
+	Close() error
+}
+
+// reparser implements Reparser using the raw payload archive and the Cursor database
+type reparser struct {
+	db      *sql.DB
+	parser  ParserService
+	storage ConversationStorage
+	archive RawPayloadArchive
+	logger  logging.Logger
+}
+
+// NewReparser creates a new reparser instance
+func NewReparser(cfg *config.Config, database *sql.DB) (Reparser, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+	if database == nil {
+		return nil, fmt.Errorf("database cannot be nil")
+	}
+
+	// Create logger
+	logger, err := logging.NewLogger(cfg)
+	if err != nil {
+		logger = logging.NewNoopLogger()
+	}
+	logger = logger.With("component", "reparser")
+
+	parser, err := NewParser(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create parser: %w", err)
+	}
+	// Re-extracted messages pass the sensitive gate like captured ones
+	gate, err := sensitive.NewGate(cfg.Sensitive, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sensitive gate: %w", err)
+	}
+	storage, err := NewConversationStorageWithGate(database, gate, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create conversation storage: %w", err)
+	}
+	archive, err := NewRawPayloadArchive(database, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create raw payload archive: %w", err)
+	}
+
+	return &reparser{
+		db:      database,
+		parser:  parser,
+		storage: storage,
+		archive: archive,
+		logger:  logger,
+	}, nil
+}
+
+// Reparse re-extracts a stored conversation and upgrades its messages in place.
+// Only messages that are already stored are rewritten; new messages are left to the
+// capture service so processed counts stay consistent.
+func (r *reparser) Reparse(composerID string, opts ReparseOptions) (*ReparseResult, error) {
+	source := opts.Source
+	if source == "" {
+		source = ReparseSourceAuto
+	}
+	if source != ReparseSourceAuto && source != ReparseSourceArchive && source != ReparseSourceCursor {
+		return nil, fmt.Errorf("invalid reparse source: %s", source)
+	}
+
+	existing, err := r.storage.GetConversationByComposerID(composerID)
+	if err != nil {
+		return nil, fmt.Errorf("conversation %s has not been captured: %w", composerID, err)
+	}
+
+	conversation, usedSource, err := r.loadConversation(composerID, source)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ReparseResult{
+		ComposerID:     composerID,
+		Source:         usedSource,
+		MessagesParsed: len(conversation.Messages),
+	}
+
+	// Pick stored messages that were extracted by an older parser
+	storedVersions := make(map[string]int, len(existing.Messages))
+	for _, message := range existing.Messages {
+		storedVersions[message.BubbleID] = message.ParserVersion
+	}
+	var upgrades []*Message
+	for i := range conversation.Messages {
+		message := &conversation.Messages[i]
+		storedVersion, stored := storedVersions[message.BubbleID]
+		if !stored {
+			continue
+		}
+		if !opts.Force && storedVersion >= ParserVersion {
+			continue
+		}
+		upgrades = append(upgrades, message)
+	}
+	result.MessagesUpgraded = len(upgrades)
+
+	if opts.DryRun || len(upgrades) == 0 {
+		return result, nil
+	}
+
+	if err := r.storage.UpgradeMessages(existing.ComposerID, upgrades); err != nil {
+		return nil, fmt.Errorf("failed to upgrade messages: %w", err)
+	}
+
+	r.logger.Info("reparsed conversation", "composer_id", composerID, "source", usedSource, "upgraded", len(upgrades))
+	return result, nil
+}
+
+// loadConversation parses a conversation from the requested source.
+// Returns the source that was actually used.
+func (r *reparser) loadConversation(composerID, source string) (*Conversation, string, error) {
+	if source == ReparseSourceAuto || source == ReparseSourceArchive {
+		conversation, err := r.loadFromArchive(composerID)
+		if err == nil {
+			return conversation, ReparseSourceArchive, nil
+		}
+		if source == ReparseSourceArchive {
+			return nil, "", err
+		}
+		r.logger.Debug("archive unavailable, falling back to Cursor database", "composer_id", composerID, "error", err)
+	}
+
+	conversation, err := r.parser.ParseConversation(composerID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse conversation from Cursor database: %w", err)
+	}
+	return conversation, ReparseSourceCursor, nil
+}
+
+// loadFromArchive parses a conversation from its archived raw payloads
+func (r *reparser) loadFromArchive(composerID string) (*Conversation, error) {
+	payloads, err := r.archive.GetPayloads(composerID)
+	if err != nil {
+		return nil, err
+	}
+
+	var composerPayload []byte
+	bubblePayloads := make(map[string][]byte)
+	for _, payload := range payloads {
+		if payload.BubbleID == "" {
+			composerPayload = payload.Payload
+			continue
+		}
+		bubblePayloads[payload.BubbleID] = payload.Payload
+	}
+	if composerPayload == nil {
+		return nil, fmt.Errorf("no archived payloads for conversation %s", composerID)
+	}
+
+	return r.parser.ParsePayloads(composerID, composerPayload, bubblePayloads)
+}
+
+// GetStoredComposerIDs returns the composer IDs of every captured conversation.
+// Imported conversations have no archived payloads and are skipped.
+func (r *reparser) GetStoredComposerIDs() ([]string, error) {
+	rows, err := r.db.Query("SELECT composer_id FROM conversations WHERE source = ? ORDER BY created_at ASC", SourceCursor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query conversations: %w", err)
+	}
+	defer rows.Close()
+
+	var composerIDs []string
+	for rows.Next() {
+		var composerID string
+		if err := rows.Scan(&composerID); err != nil {
+			return nil, fmt.Errorf("failed to scan composer ID: %w", err)
+		}
+		composerIDs = append(composerIDs, composerID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating conversations: %w", err)
+	}
+
+	return composerIDs, nil
+}
+
+// Close releases the parser's Cursor database connection
+func (r *reparser) Close() error {
+	return r.parser.Close()
+}
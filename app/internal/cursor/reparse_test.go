@@ -0,0 +1,149 @@
+package cursor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+func TestNewReparser(t *testing.T) {
+	cfg := createTestConfig(t)
+	database := createTestDB(t, cfg)
+	defer database.Close()
+
+	reparser, err := NewReparser(cfg, database)
+	if err != nil {
+		t.Fatalf("NewReparser() error = %v, want nil", err)
+	}
+	reparser.Close()
+
+	if _, err := NewReparser(nil, database); err == nil {
+		t.Error("NewReparser(nil, ...) expected error, got nil")
+	}
+	if _, err := NewReparser(cfg, nil); err == nil {
+		t.Error("NewReparser(..., nil) expected error, got nil")
+	}
+}
+
+func TestReparser_ReparseFromArchive(t *testing.T) {
+	cfg := createTestConfig(t)
+	database := createTestDB(t, cfg)
+	defer database.Close()
+
+	// Store a conversation captured before parser versioning, with a stale content_source
+	sessionID := "test-session-1"
+	if _, err := database.Exec(`
+		INSERT INTO sessions (id, project, start_time, end_time, last_activity, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, sessionID, "test-project", time.Now(), nil, time.Now(), time.Now(), time.Now()); err != nil {
+		t.Fatalf("Failed to create test session: %v", err)
+	}
+
+	logger := logging.NewNoopLogger()
+	storage, err := NewConversationStorage(database, logger)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	stored := &Conversation{
+		ComposerID: "c1",
+		Name:       "Test",
+		CreatedAt:  time.Now(),
+		Messages: []Message{
+			{BubbleID: "b1", Type: 1, Role: "user", Text: "question", CreatedAt: time.Now()},
+			{BubbleID: "b2", Type: 2, Role: "agent", Text: "", CreatedAt: time.Now()},
+		},
+	}
+	if err := storage.StoreConversation(stored, sessionID); err != nil {
+		t.Fatalf("Failed to store conversation: %v", err)
+	}
+
+	// Archive the raw payloads, where b2 carries thinking text the old parser missed
+	archive, err := NewRawPayloadArchive(database, logger)
+	if err != nil {
+		t.Fatalf("Failed to create archive: %v", err)
+	}
+	archived := &Conversation{
+		ComposerID: "c1",
+		RawPayload: []byte(`{"composerId":"c1","name":"Test","createdAt":1704067200000,"fullConversationHeadersOnly":[{"bubbleId":"b1","type":1},{"bubbleId":"b2","type":2},{"bubbleId":"b3","type":1}]}`),
+		Messages: []Message{
+			{BubbleID: "b1", RawPayload: []byte(`{"bubbleId":"b1","type":1,"text":"question","createdAt":"2024-01-01T12:00:00.000Z"}`)},
+			{BubbleID: "b2", RawPayload: []byte(`{"bubbleId":"b2","type":2,"text":"","thinking":{"text":"considering"},"createdAt":"2024-01-01T12:00:10.000Z"}`)},
+			{BubbleID: "b3", RawPayload: []byte(`{"bubbleId":"b3","type":1,"text":"not stored yet","createdAt":"2024-01-01T12:00:20.000Z"}`)},
+		},
+	}
+	if err := archive.ArchiveConversation(archived); err != nil {
+		t.Fatalf("Failed to archive conversation: %v", err)
+	}
+
+	reparser, err := NewReparser(cfg, database)
+	if err != nil {
+		t.Fatalf("NewReparser() error = %v", err)
+	}
+	defer reparser.Close()
+
+	// Dry run reports without writing
+	result, err := reparser.Reparse("c1", ReparseOptions{Source: ReparseSourceArchive, DryRun: true})
+	if err != nil {
+		t.Fatalf("Reparse() dry run error = %v", err)
+	}
+	if result.MessagesUpgraded != 2 || result.MessagesParsed != 3 {
+		t.Errorf("dry run result = %+v, want 2 upgraded of 3 parsed", result)
+	}
+
+	result, err = reparser.Reparse("c1", ReparseOptions{Source: ReparseSourceAuto})
+	if err != nil {
+		t.Fatalf("Reparse() error = %v", err)
+	}
+	if result.Source != ReparseSourceArchive {
+		t.Errorf("Source = %q, want %q", result.Source, ReparseSourceArchive)
+	}
+	if result.MessagesUpgraded != 2 {
+		t.Errorf("MessagesUpgraded = %d, want 2", result.MessagesUpgraded)
+	}
+
+	upgraded, err := storage.GetConversationByComposerID("c1")
+	if err != nil {
+		t.Fatalf("Failed to retrieve conversation: %v", err)
+	}
+	// Unstored message b3 is left to the capture service
+	if len(upgraded.Messages) != 2 {
+		t.Fatalf("messages = %d, want 2", len(upgraded.Messages))
+	}
+	for _, message := range upgraded.Messages {
+		if message.ParserVersion != ParserVersion {
+			t.Errorf("message %s ParserVersion = %d, want %d", message.BubbleID, message.ParserVersion, ParserVersion)
+		}
+		if message.BubbleID == "b2" && (message.ThinkingText != "considering" || message.ContentSource != "thinking") {
+			t.Errorf("message b2 not upgraded: thinking=%q source=%q", message.ThinkingText, message.ContentSource)
+		}
+	}
+
+	// Second run has nothing left to upgrade
+	result, err = reparser.Reparse("c1", ReparseOptions{})
+	if err != nil {
+		t.Fatalf("Reparse() second run error = %v", err)
+	}
+	if result.MessagesUpgraded != 0 {
+		t.Errorf("second run MessagesUpgraded = %d, want 0", result.MessagesUpgraded)
+	}
+}
+
+func TestReparser_Errors(t *testing.T) {
+	cfg := createTestConfig(t)
+	database := createTestDB(t, cfg)
+	defer database.Close()
+
+	reparser, err := NewReparser(cfg, database)
+	if err != nil {
+		t.Fatalf("NewReparser() error = %v", err)
+	}
+	defer reparser.Close()
+
+	if _, err := reparser.Reparse("missing", ReparseOptions{}); err == nil {
+		t.Error("Reparse() of uncaptured conversation expected error, got nil")
+	}
+	if _, err := reparser.Reparse("missing", ReparseOptions{Source: "bogus"}); err == nil {
+		t.Error("Reparse() with invalid source expected error, got nil")
+	}
+}
@@ -0,0 +1,106 @@
+package cursor
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MessageRevision is an earlier version of a message that Cursor rewrote in
+// place, e.g. by regenerating an answer or editing a prompt
+type MessageRevision struct {
+	MessageID      string
+	ConversationID string
+	Revision       int            // 1 for the first version replaced
+	Content        string         // The preview when ContentBlob is set
+	ContentBlob    sql.NullString // Blob holding the full content, as in messages
+	ThinkingText   string
+	ParserVersion  int
+	CreatedAt      time.Time // When the replaced version was created
+	ReplacedAt     time.Time
+}
+
+// saveRevisionInTx keeps the stored version of message as a revision when the
+// new version rewrites its text. Text that only grows, as a streaming reply does
+// between polls, isn't a rewrite.
+func saveRevisionInTx(tx *sql.Tx, message *Message, conversationID string) error {
+	var content string
+	var contentBlob, thinking sql.NullString
+	var createdAt time.Time
+	var parserVersion int
+	err := tx.QueryRow(`
+		SELECT content, content_blob, thinking_text, created_at, parser_version
+		FROM messages
+		WHERE id = ?
+	`, message.BubbleID).Scan(&content, &contentBlob, &thinking, &createdAt, &parserVersion)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read stored message: %w", err)
+	}
+	if !rewritten(content, contentBlob, message.Text) {
+		return nil
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO message_revisions (
+			message_id, conversation_id, revision, content, content_blob,
+			thinking_text, parser_version, created_at, replaced_at
+		)
+		VALUES (?, ?, (SELECT COALESCE(MAX(revision), 0) + 1 FROM message_revisions WHERE message_id = ?), ?, ?, ?, ?, ?, ?)
+	`, message.BubbleID, conversationID, message.BubbleID, content, contentBlob, thinking, parserVersion, createdAt, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to store message revision: %w", err)
+	}
+	return nil
+}
+
+// rewritten reports whether text replaces the stored content rather than
+// repeating or extending it. Content moved to the blob store is compared by its
+// blob's SHA-256, since the row only holds a preview.
+func rewritten(stored string, blob sql.NullString, text string) bool {
+	if blob.Valid && blob.String != "" {
+		sum := sha256.Sum256([]byte(text))
+		return hex.EncodeToString(sum[:]) != blob.String
+	}
+	return stored != "" && !strings.HasPrefix(text, stored)
+}
+
+// GetMessageRevisions returns the earlier versions of a message, oldest first
+func (cs *conversationStorage) GetMessageRevisions(messageID string) ([]MessageRevision, error) {
+	if messageID == "" {
+		return nil, fmt.Errorf("message ID cannot be empty")
+	}
+
+	rows, err := cs.db.Query(`
+		SELECT message_id, conversation_id, revision, content, content_blob,
+			thinking_text, parser_version, created_at, replaced_at
+		FROM message_revisions
+		WHERE message_id = ?
+		ORDER BY revision ASC
+	`, messageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query message revisions: %w", err)
+	}
+	defer rows.Close()
+
+	var revisions []MessageRevision
+	for rows.Next() {
+		var r MessageRevision
+		var thinking sql.NullString
+		if err := rows.Scan(&r.MessageID, &r.ConversationID, &r.Revision, &r.Content, &r.ContentBlob,
+			&thinking, &r.ParserVersion, &r.CreatedAt, &r.ReplacedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan message revision: %w", err)
+		}
+		r.ThinkingText = thinking.String
+		revisions = append(revisions, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating message revisions: %w", err)
+	}
+	return revisions, nil
+}
@@ -0,0 +1,104 @@
+package cursor
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+func TestRewritten(t *testing.T) {
+	tests := []struct {
+		name   string
+		stored string
+		blob   sql.NullString
+		text   string
+		want   bool
+	}{
+		{"unchanged", "hello", sql.NullString{}, "hello", false},
+		{"streamed further", "hel", sql.NullString{}, "hello", false},
+		{"first content", "", sql.NullString{}, "hello", false},
+		{"regenerated", "hello", sql.NullString{}, "goodbye", true},
+		{"truncated", "hello", sql.NullString{}, "hel", true},
+		{"blob unchanged", "hel", sql.NullString{String: "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", Valid: true}, "hello", false},
+		{"blob rewritten", "hel", sql.NullString{String: "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", Valid: true}, "help", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rewritten(tt.stored, tt.blob, tt.text); got != tt.want {
+				t.Errorf("rewritten(%q, %v, %q) = %v, want %v", tt.stored, tt.blob, tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStoreMessage_KeepsRevisions(t *testing.T) {
+	cfg := createTestConfig(t)
+	database, err := db.Open(cfg)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer database.Close()
+
+	sessionID := "test-session-revisions"
+	_, err = database.Exec(`
+		INSERT INTO sessions (id, project, start_time, end_time, last_activity, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, sessionID, "test-project", time.Now(), nil, time.Now(), time.Now(), time.Now())
+	if err != nil {
+		t.Fatalf("Failed to create test session: %v", err)
+	}
+
+	storage, err := NewConversationStorage(database, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+
+	conv := createTestConversationWithMessages(t, "composer-revisions", 2, time.Now())
+	if err := storage.StoreConversation(conv, sessionID); err != nil {
+		t.Fatalf("Failed to store conversation: %v", err)
+	}
+	answer := conv.Messages[1]
+
+	// Streaming growth is not a revision; a regeneration and an edit are
+	for _, text := range []string{answer.Text + " continued", "Regenerated answer", "Edited answer"} {
+		answer.Text = text
+		if err := storage.UpdateConversation(conv.ComposerID, []*Message{&answer}); err != nil {
+			t.Fatalf("Failed to update conversation: %v", err)
+		}
+	}
+
+	// Re-parsing the same bubble replaces it without a revision
+	answer.Text = "Reparsed answer"
+	if err := storage.UpgradeMessages(conv.ComposerID, []*Message{&answer}); err != nil {
+		t.Fatalf("Failed to upgrade messages: %v", err)
+	}
+
+	revisions, err := storage.GetMessageRevisions(answer.BubbleID)
+	if err != nil {
+		t.Fatalf("GetMessageRevisions() error = %v", err)
+	}
+	want := []string{"Message 1 continued", "Regenerated answer"}
+	if len(revisions) != len(want) {
+		t.Fatalf("GetMessageRevisions() returned %d revisions, want %d: %+v", len(revisions), len(want), revisions)
+	}
+	for i, revision := range revisions {
+		if revision.Content != want[i] || revision.Revision != i+1 || revision.ConversationID != conv.ComposerID {
+			t.Errorf("revision %d = %+v, want content %q", i, revision, want[i])
+		}
+	}
+
+	retrieved, err := storage.GetConversationByComposerID(conv.ComposerID)
+	if err != nil {
+		t.Fatalf("Failed to retrieve conversation: %v", err)
+	}
+	if got := retrieved.Messages[1].Text; got != "Reparsed answer" {
+		t.Errorf("stored message text = %q, want the latest version", got)
+	}
+
+	if revisions, err := storage.GetMessageRevisions(conv.Messages[0].BubbleID); err != nil || len(revisions) != 0 {
+		t.Errorf("GetMessageRevisions() for an unchanged message = %v, %v; want none", revisions, err)
+	}
+}
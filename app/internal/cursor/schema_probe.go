@@ -0,0 +1,161 @@
+package cursor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	// QuarantineSourceComposer marks a quarantined composerData payload
+	QuarantineSourceComposer = "composer"
+	// QuarantineSourceBubble marks a quarantined bubbleId payload
+	QuarantineSourceBubble = "bubble"
+)
+
+// QuarantinedPayload is a raw Cursor payload whose shape didn't match what the parser expects.
+// It is kept verbatim so it can be re-parsed once the parser learns the new format.
+type QuarantinedPayload struct {
+	Source     string // QuarantineSourceComposer or QuarantineSourceBubble
+	ComposerID string
+	BubbleID   string // Empty for composer payloads
+	Reason     string // Human-readable description of the schema mismatch
+	Payload    []byte // Raw JSON as read from Cursor's database
+}
+
+// SchemaError is returned by the parser when a payload can't be parsed at all
+// because its shape doesn't match the expected Cursor schema
+type SchemaError struct {
+	Payload QuarantinedPayload
+}
+
+// Error implements the error interface
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("unexpected %s schema for %s: %s", e.Payload.Source, e.Payload.ComposerID, e.Payload.Reason)
+}
+
+// fieldKind describes the JSON type expected for a known field
+type fieldKind int
+
+const (
+	kindString fieldKind = iota
+	kindNumber
+	kindObject
+	kindArray
+)
+
+// String returns the JSON name of the kind
+func (k fieldKind) String() string {
+	switch k {
+	case kindString:
+		return "string"
+	case kindNumber:
+		return "number"
+	case kindObject:
+		return "object"
+	case kindArray:
+		return "array"
+	default:
+		return "unknown"
+	}
+}
+
+// composerFieldKinds lists the composerData fields the parser reads and their expected types
+var composerFieldKinds = map[string]fieldKind{
+	"composerId":                  kindString,
+	"name":                        kindString,
+	"status":                      kindString,
+	"createdAt":                   kindNumber,
+	"fullConversationHeadersOnly": kindArray,
+}
+
+// bubbleFieldKinds lists the bubble fields the parser reads and their expected types
+var bubbleFieldKinds = map[string]fieldKind{
+	"bubbleId":            kindString,
+	"type":                kindNumber,
+	"text":                kindString,
+	"createdAt":           kindString,
+	"thinking":            kindObject,
+	"codeBlocks":          kindArray,
+	"suggestedCodeBlocks": kindArray,
+	"toolFormerData":      kindObject,
+	"toolResults":         kindArray,
+}
+
+// probeComposerShape checks a decoded composerData payload against the expected schema.
+// Returns a list of problems; an empty list means the shape is recognised.
+func probeComposerShape(data map[string]interface{}) []string {
+	problems := probeFieldKinds(data, composerFieldKinds)
+
+	headers, ok := data["fullConversationHeadersOnly"]
+	if !ok {
+		problems = append(problems, "missing field fullConversationHeadersOnly")
+		return problems
+	}
+
+	// Every header must carry a bubble ID or messages can't be located
+	if headerList, ok := headers.([]interface{}); ok {
+		for i, header := range headerList {
+			headerMap, ok := header.(map[string]interface{})
+			if !ok {
+				problems = append(problems, fmt.Sprintf("header %d is not an object", i))
+				continue
+			}
+			if _, ok := headerMap["bubbleId"].(string); !ok {
+				problems = append(problems, fmt.Sprintf("header %d has no string bubbleId", i))
+			}
+		}
+	}
+
+	return problems
+}
+
+// probeBubbleShape checks a decoded bubble payload against the expected schema.
+// Returns a list of problems; an empty list means the shape is recognised.
+func probeBubbleShape(data map[string]interface{}) []string {
+	return probeFieldKinds(data, bubbleFieldKinds)
+}
+
+// probeFieldKinds reports known fields whose JSON type differs from the expected kind.
+// Missing fields are not reported - Cursor omits many fields when they are empty.
+func probeFieldKinds(data map[string]interface{}, kinds map[string]fieldKind) []string {
+	var problems []string
+	for field, kind := range kinds {
+		value, ok := data[field]
+		if !ok || value == nil {
+			continue
+		}
+		if !matchesKind(value, kind) {
+			problems = append(problems, fmt.Sprintf("field %s: expected %s, got %T", field, kind, value))
+		}
+	}
+	return problems
+}
+
+// matchesKind reports whether a decoded JSON value has the expected kind
+func matchesKind(value interface{}, kind fieldKind) bool {
+	switch kind {
+	case kindString:
+		_, ok := value.(string)
+		return ok
+	case kindNumber:
+		_, ok := value.(float64)
+		return ok
+	case kindObject:
+		_, ok := value.(map[string]interface{})
+		return ok
+	case kindArray:
+		_, ok := value.([]interface{})
+		return ok
+	default:
+		return false
+	}
+}
+
+// joinProblems formats probe problems as a single sorted reason string
+func joinProblems(problems []string) string {
+	sorted := make([]string, len(problems))
+	copy(sorted, problems)
+	sort.Strings(sorted)
+	return strings.Join(sorted, "; ")
+}
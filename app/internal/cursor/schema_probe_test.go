@@ -0,0 +1,211 @@
+package cursor
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+func TestProbeComposerShape(t *testing.T) {
+	tests := []struct {
+		name         string
+		data         map[string]interface{}
+		wantProblems int
+	}{
+		{
+			name: "known shape",
+			data: map[string]interface{}{
+				"composerId": "c1",
+				"name":       "Test",
+				"createdAt":  float64(1704067200000),
+				"fullConversationHeadersOnly": []interface{}{
+					map[string]interface{}{"bubbleId": "b1", "type": float64(1)},
+				},
+			},
+			wantProblems: 0,
+		},
+		{
+			name:         "missing headers",
+			data:         map[string]interface{}{"composerId": "c1"},
+			wantProblems: 1,
+		},
+		{
+			name: "createdAt changed to string",
+			data: map[string]interface{}{
+				"createdAt":                   "2024-01-01T00:00:00Z",
+				"fullConversationHeadersOnly": []interface{}{},
+			},
+			wantProblems: 1,
+		},
+		{
+			name: "header without bubbleId",
+			data: map[string]interface{}{
+				"fullConversationHeadersOnly": []interface{}{
+					map[string]interface{}{"id": "b1"},
+					"b2",
+				},
+			},
+			wantProblems: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := probeComposerShape(tt.data)
+			if len(problems) != tt.wantProblems {
+				t.Errorf("probeComposerShape() problems = %v, want %d", problems, tt.wantProblems)
+			}
+		})
+	}
+}
+
+func TestProbeBubbleShape(t *testing.T) {
+	known := map[string]interface{}{
+		"bubbleId":  "b1",
+		"type":      float64(2),
+		"text":      "hello",
+		"createdAt": "2024-01-01T12:00:00.000Z",
+		"thinking":  map[string]interface{}{"text": "hmm"},
+		"unknown":   true, // Unknown fields are fine - they end up in metadata
+	}
+	if problems := probeBubbleShape(known); len(problems) != 0 {
+		t.Errorf("probeBubbleShape() problems = %v, want none", problems)
+	}
+
+	changed := map[string]interface{}{
+		"type": "assistant",
+		"text": []interface{}{"rich", "text"},
+	}
+	problems := probeBubbleShape(changed)
+	if len(problems) != 2 {
+		t.Errorf("probeBubbleShape() problems = %v, want 2", problems)
+	}
+}
+
+func TestParser_QuarantinesUnexpectedSchema(t *testing.T) {
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "globalStorage", "state.vscdb")
+	createTestDatabase(t, dbPath)
+
+	// Simulate a Cursor format change on one bubble
+	writer, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	if _, err := writer.Exec("INSERT INTO cursorDiskKV (key, value) VALUES (?, ?)",
+		"bubbleId:test-composer-id-123:bubble-2",
+		[]byte(`{"bubbleId":"bubble-2","type":2,"text":{"richText":"To debug this"},"createdAt":"2024-01-01T12:00:15.000Z"}`)); err != nil {
+		t.Fatalf("Failed to update bubble: %v", err)
+	}
+	writer.Close()
+
+	p, err := NewParser(&config.Config{Cursor: config.CursorConfig{LogPath: tmpDir}})
+	if err != nil {
+		t.Fatalf("NewParser() error = %v", err)
+	}
+	defer p.Close()
+
+	conversation, err := p.ParseConversation("test-composer-id-123")
+	if err != nil {
+		t.Fatalf("ParseConversation() error = %v", err)
+	}
+
+	// Message is still captured, but the raw payload is quarantined
+	if len(conversation.Messages) != 3 {
+		t.Errorf("messages = %d, want 3", len(conversation.Messages))
+	}
+	if len(conversation.Quarantined) != 1 {
+		t.Fatalf("quarantined = %d, want 1", len(conversation.Quarantined))
+	}
+	q := conversation.Quarantined[0]
+	if q.Source != QuarantineSourceBubble || q.BubbleID != "bubble-2" {
+		t.Errorf("quarantined = %+v, want bubble-2 bubble payload", q)
+	}
+	if !strings.Contains(q.Reason, "field text") {
+		t.Errorf("reason = %q, want mention of field text", q.Reason)
+	}
+}
+
+func TestParser_ComposerSchemaError(t *testing.T) {
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "globalStorage", "state.vscdb")
+	createTestDatabase(t, dbPath)
+
+	writer, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	if _, err := writer.Exec("INSERT INTO cursorDiskKV (key, value) VALUES (?, ?)",
+		"composerData:test-composer-id-123",
+		[]byte(`{"composerId":"test-composer-id-123","fullConversationHeadersOnly":{"bubbles":[]}}`)); err != nil {
+		t.Fatalf("Failed to update composer: %v", err)
+	}
+	writer.Close()
+
+	p, err := NewParser(&config.Config{Cursor: config.CursorConfig{LogPath: tmpDir}})
+	if err != nil {
+		t.Fatalf("NewParser() error = %v", err)
+	}
+	defer p.Close()
+
+	_, err = p.ParseConversation("test-composer-id-123")
+	var schemaErr *SchemaError
+	if !errors.As(err, &schemaErr) {
+		t.Fatalf("ParseConversation() error = %v, want *SchemaError", err)
+	}
+	if schemaErr.Payload.Source != QuarantineSourceComposer {
+		t.Errorf("source = %q, want %q", schemaErr.Payload.Source, QuarantineSourceComposer)
+	}
+	if len(schemaErr.Payload.Payload) == 0 {
+		t.Error("expected raw payload to be preserved")
+	}
+}
+
+func TestQuarantineStorage_StoreAndCount(t *testing.T) {
+	cfg := createTestConfig(t)
+	database := createTestDB(t, cfg)
+	defer database.Close()
+
+	store, err := NewQuarantineStorage(database, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewQuarantineStorage() error = %v", err)
+	}
+
+	payloads := []QuarantinedPayload{
+		{Source: QuarantineSourceComposer, ComposerID: "c1", Reason: "missing field", Payload: []byte(`{}`)},
+		{Source: QuarantineSourceBubble, ComposerID: "c1", BubbleID: "b1", Reason: "field text", Payload: []byte(`{}`)},
+		{Source: QuarantineSourceBubble, ComposerID: "c1", BubbleID: "b2", Reason: "field text", Payload: []byte(`{}`)},
+	}
+	if err := store.StorePayloads(payloads); err != nil {
+		t.Fatalf("StorePayloads() error = %v", err)
+	}
+
+	// Storing the same payloads again updates rather than duplicates
+	if err := store.StorePayloads(payloads); err != nil {
+		t.Fatalf("StorePayloads() second call error = %v", err)
+	}
+
+	counts, err := store.CountBySource()
+	if err != nil {
+		t.Fatalf("CountBySource() error = %v", err)
+	}
+	if counts[QuarantineSourceComposer] != 1 || counts[QuarantineSourceBubble] != 2 {
+		t.Errorf("CountBySource() = %v, want composer=1 bubble=2", counts)
+	}
+
+	// recordQuarantine picks up payloads from schema errors
+	schemaErr := &SchemaError{Payload: QuarantinedPayload{Source: QuarantineSourceComposer, ComposerID: "c2", Reason: "invalid JSON"}}
+	recordQuarantine(store, logging.NewNoopLogger(), nil, schemaErr)
+	counts, err = CountQuarantinedPayloads(database)
+	if err != nil {
+		t.Fatalf("CountQuarantinedPayloads() error = %v", err)
+	}
+	if counts[QuarantineSourceComposer] != 2 {
+		t.Errorf("composer count = %d, want 2", counts[QuarantineSourceComposer])
+	}
+}
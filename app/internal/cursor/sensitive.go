@@ -0,0 +1,96 @@
+package cursor
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/dedup"
+	"github.com/stwalsh4118/clio/internal/sensitive"
+)
+
+// gateMessage returns the message as the sensitive gate lets it be stored,
+// with the gate's decision. The message is copied before being redacted, so
+// callers' messages are left as captured.
+func gateMessage(gate *sensitive.Gate, message *Message) (*Message, sensitive.Decision) {
+	fields := make([]string, 0, 2+len(message.CodeBlocks))
+	fields = append(fields, message.Text, message.ThinkingText)
+	for _, block := range message.CodeBlocks {
+		fields = append(fields, block.Content)
+	}
+
+	decision, fields := gate.Check(fields)
+	if decision.Action != sensitive.ActionRedact {
+		return message, decision
+	}
+
+	redacted := *message
+	redacted.Text, redacted.ThinkingText = fields[0], fields[1]
+	redacted.CodeBlocks = append([]CodeBlock(nil), message.CodeBlocks...)
+	for i := range redacted.CodeBlocks {
+		redacted.CodeBlocks[i].Content = fields[2+i]
+	}
+	return &redacted, decision
+}
+
+// gatedMessages returns the messages as they would be stored, without the
+// dropped ones, for comparing captured conversations with stored ones
+func gatedMessages(gate *sensitive.Gate, messages []Message) []Message {
+	if gate == nil {
+		return messages
+	}
+	gated := make([]Message, 0, len(messages))
+	for i := range messages {
+		message, decision := gateMessage(gate, &messages[i])
+		if decision.Action != sensitive.ActionDrop {
+			gated = append(gated, *message)
+		}
+	}
+	return gated
+}
+
+// gateMessageInTx runs the message through the storage's sensitive gate and
+// records the categories found. It returns the message to store, or nil when
+// the message is dropped, in which case a previously stored version is deleted.
+func (cs *conversationStorage) gateMessageInTx(tx *sql.Tx, message *Message, conversationID string) (*Message, error) {
+	gated, decision := gateMessage(cs.gate, message)
+
+	if _, err := tx.Exec("DELETE FROM sensitive_flags WHERE message_id = ?", message.BubbleID); err != nil {
+		return nil, fmt.Errorf("failed to clear sensitive flags: %w", err)
+	}
+	now := time.Now()
+	for _, category := range decision.Categories {
+		if _, err := tx.Exec(`
+			INSERT INTO sensitive_flags (message_id, conversation_id, category, action, flagged_at)
+			VALUES (?, ?, ?, ?, ?)
+		`, message.BubbleID, conversationID, category.Name, category.Action, now); err != nil {
+			return nil, fmt.Errorf("failed to flag sensitive message: %w", err)
+		}
+	}
+
+	if decision.Action != sensitive.ActionDrop {
+		if decision.Action != "" {
+			cs.logger.Debug("sensitive content in message", "conversation_id", conversationID, "bubble_id", message.BubbleID, "action", decision.Action)
+		}
+		return gated, nil
+	}
+
+	// Nothing of a dropped message is kept, including versions stored before the gate was configured
+	refs, err := storedCodeBlockRefs(tx, message.BubbleID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec("DELETE FROM messages WHERE id = ?", message.BubbleID); err != nil {
+		return nil, fmt.Errorf("failed to delete dropped message: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM message_revisions WHERE message_id = ?", message.BubbleID); err != nil {
+		return nil, fmt.Errorf("failed to delete dropped message revisions: %w", err)
+	}
+	for _, ref := range refs {
+		if err := dedup.Release(tx, ref); err != nil {
+			return nil, err
+		}
+	}
+	cs.logger.Info("dropped sensitive message", "conversation_id", conversationID, "bubble_id", message.BubbleID)
+	return nil, nil
+}
@@ -10,7 +10,9 @@ import (
 	"time"
 
 	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/idle"
 	"github.com/stwalsh4118/clio/internal/logging"
+	"github.com/stwalsh4118/clio/internal/sensitive"
 )
 
 // Session represents a continuous development session containing multiple conversations
@@ -43,15 +45,20 @@ func (s *Session) Duration() time.Duration {
 type SessionManager interface {
 	GetOrCreateSession(project string, conversation *Conversation) (*Session, error)
 	AddConversation(sessionID string, conversation *Conversation) error
+	RecordActivity(project string, at time.Time) (string, bool)
 	EndSession(sessionID string) error
 	GetActiveSessions() ([]*Session, error)
 	GetSession(sessionID string) (*Session, error)
 	LoadSessions() error
 	SaveSessions() error
 	StartInactivityMonitor(ctx context.Context) error
+	OnSessionEnd(handler SessionEndHandler)
 	Stop() error
 }
 
+// SessionEndHandler is called with a copy of each session when it ends
+type SessionEndHandler func(session Session)
+
 // sessionManager implements SessionManager for tracking development sessions
 type sessionManager struct {
 	config                  *config.Config
@@ -65,6 +72,9 @@ type sessionManager struct {
 	inactivityMonitorCancel context.CancelFunc  // Cancel function for inactivity monitor
 	monitorRunning          bool                // Whether inactivity monitor is running
 	monitorMu               sync.Mutex          // Mutex for monitor state
+	endHandlers             []SessionEndHandler // Called when a session ends (guarded by mu)
+	sleepWatch              *idle.JumpWatch     // Detects machine sleep between checks
+	sleeps                  []idle.Gap          // Recent sleeps, oldest first (guarded by mu)
 }
 
 const (
@@ -72,6 +82,8 @@ const (
 	inactivityCheckInterval = 1 * time.Minute
 	// sessionIDLength is the length of random bytes for session ID suffix
 	sessionIDLength = 8
+	// sleepRetention is how long a detected sleep is kept to end sessions that expired across it
+	sleepRetention = 48 * time.Hour
 )
 
 // NewSessionManager creates a new session manager instance
@@ -92,8 +104,12 @@ func NewSessionManager(cfg *config.Config, database *sql.DB) (SessionManager, er
 		logger = logging.NewNoopLogger()
 	}
 
-	// Create storage service with logger
-	storage, err := NewConversationStorage(database, logger)
+	// Create storage service with logger, gating sensitive content when configured
+	gate, err := sensitive.NewGate(cfg.Sensitive, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sensitive gate: %w", err)
+	}
+	storage, err := newConversationStorage(database, gate, openBlobStore(cfg, logger), logger)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create conversation storage: %w", err)
 	}
@@ -107,6 +123,7 @@ func NewSessionManager(cfg *config.Config, database *sql.DB) (SessionManager, er
 		logger:                  logger,
 		sessions:                make(map[string]*Session),
 		activeSessionsByProject: make(map[string]string),
+		sleepWatch:              idle.NewJumpWatch(idle.DefaultJumpThreshold),
 	}
 
 	return sm, nil
@@ -163,7 +180,9 @@ func (sm *sessionManager) GetOrCreateSession(project string, conversation *Conve
 			}
 			// Session expired, end it
 			now := time.Now()
-			session.EndTime = &now
+			sm.observeSleep(now)
+			endTime := sm.expiredAt(session, now)
+			session.EndTime = &endTime
 			delete(sm.activeSessionsByProject, project)
 		}
 	}
@@ -252,17 +271,52 @@ func (sm *sessionManager) AddConversation(sessionID string, conversation *Conver
 	return nil
 }
 
+// RecordActivity extends the project's active session with activity that isn't a
+// conversation, such as editor heartbeats, so editing files keeps a session from
+// timing out. It returns the session ID when the project had an active session.
+// Activity never starts a session.
+func (sm *sessionManager) RecordActivity(project string, at time.Time) (string, bool) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	sessionID, exists := sm.activeSessionsByProject[project]
+	if !exists {
+		return "", false
+	}
+	session, found := sm.sessions[sessionID]
+	if !found || !session.IsActive() || at.Before(session.StartTime) {
+		return "", false
+	}
+
+	timeout := time.Duration(sm.config.Session.InactivityTimeoutMinutes) * time.Minute
+	if time.Since(session.LastActivity) >= timeout {
+		// The inactivity monitor will end it; activity doesn't revive a timed-out session
+		return "", false
+	}
+
+	if at.After(session.LastActivity) {
+		session.LastActivity = at
+		session.UpdatedAt = time.Now()
+		if err := sm.saveSessionToDB(session); err != nil {
+			sm.logger.Error("failed to save session to database", "error", err, "session_id", sessionID)
+		}
+	}
+
+	return sessionID, true
+}
+
 // EndSession ends an active session
 func (sm *sessionManager) EndSession(sessionID string) error {
 	sm.mu.Lock()
-	defer sm.mu.Unlock()
 
 	session, exists := sm.sessions[sessionID]
 	if !exists {
+		sm.mu.Unlock()
 		return fmt.Errorf("session not found: %s", sessionID)
 	}
 
 	if !session.IsActive() {
+		sm.mu.Unlock()
 		return nil // Already ended, no error
 	}
 
@@ -273,9 +327,26 @@ func (sm *sessionManager) EndSession(sessionID string) error {
 	// Remove from active sessions map
 	delete(sm.activeSessionsByProject, session.Project)
 
+	ended := *session
+	handlers := sm.endHandlers
+	sm.mu.Unlock()
+
+	// Call handlers outside of lock so they can query the session manager
+	for _, handler := range handlers {
+		handler(ended)
+	}
+
 	return nil
 }
 
+// OnSessionEnd registers a handler called whenever a session ends
+func (sm *sessionManager) OnSessionEnd(handler SessionEndHandler) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	sm.endHandlers = append(sm.endHandlers, handler)
+}
+
 // GetActiveSessions returns all currently active sessions
 func (sm *sessionManager) GetActiveSessions() ([]*Session, error) {
 	sm.mu.RLock()
@@ -339,7 +410,9 @@ func (sm *sessionManager) LoadSessions() error {
 			&session.UpdatedAt,
 		)
 		if err != nil {
-			continue // Skip invalid rows
+			// Its conversations get a reconstructed session below
+			sm.logger.Warn("skipping unreadable session", "error", err, "session_id", session.ID)
+			continue
 		}
 
 		if endTime.Valid {
@@ -364,6 +437,11 @@ func (sm *sessionManager) LoadSessions() error {
 		return fmt.Errorf("error iterating sessions: %w", err)
 	}
 
+	// Conversations whose session is missing or unreadable would drop out of every view
+	if _, err := sm.reconstructOrphanedSessions(); err != nil {
+		sm.logger.Error("failed to reconstruct sessions for orphaned conversations", "error", err)
+	}
+
 	return nil
 }
 
@@ -499,6 +577,7 @@ func (sm *sessionManager) endInactiveSessions() {
 
 	timeout := time.Duration(sm.config.Session.InactivityTimeoutMinutes) * time.Minute
 	now := time.Now()
+	sm.observeSleep(now)
 
 	var sessionsToEnd []string
 
@@ -517,22 +596,59 @@ func (sm *sessionManager) endInactiveSessions() {
 	}
 
 	// End inactive sessions
+	var ended []Session
 	for _, sessionID := range sessionsToEnd {
 		session := sm.sessions[sessionID]
 		if session != nil && session.IsActive() {
-			session.EndTime = &now
+			endTime := sm.expiredAt(session, now)
+			session.EndTime = &endTime
 			session.UpdatedAt = now
 			delete(sm.activeSessionsByProject, session.Project)
+			ended = append(ended, *session)
 		}
 	}
 
 	shouldSave := len(sessionsToEnd) > 0
+	handlers := sm.endHandlers
 	sm.mu.Unlock()
 
 	// Save sessions if any were ended (outside of lock to avoid deadlock)
 	if shouldSave {
 		_ = sm.SaveSessions()
 	}
+
+	for _, session := range ended {
+		for _, handler := range handlers {
+			handler(session)
+		}
+	}
+}
+
+// observeSleep records a sleep of the machine since the last observation; must be called with mu held
+func (sm *sessionManager) observeSleep(now time.Time) {
+	if gap, ok := sm.sleepWatch.Observe(now); ok {
+		sm.logger.Info("machine slept, sessions idle across it will end when it began", "start", gap.Start, "end", gap.End)
+		sm.sleeps = append(sm.sleeps, gap)
+	}
+	for len(sm.sleeps) > 0 && now.Sub(sm.sleeps[0].End) > sleepRetention {
+		sm.sleeps = sm.sleeps[1:]
+	}
+}
+
+// expiredAt returns when an expired session ended: when the machine went to sleep
+// after its last activity, so an overnight sleep doesn't extend it, or else now.
+// Must be called with mu held.
+func (sm *sessionManager) expiredAt(session *Session, now time.Time) time.Time {
+	for _, gap := range sm.sleeps {
+		if !gap.End.After(session.LastActivity) {
+			continue
+		}
+		if gap.Start.Before(session.LastActivity) {
+			return session.LastActivity
+		}
+		return gap.Start
+	}
+	return now
 }
 
 // Stop stops the inactivity monitor and saves sessions
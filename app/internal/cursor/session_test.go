@@ -9,6 +9,7 @@ import (
 
 	"github.com/stwalsh4118/clio/internal/config"
 	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/idle"
 )
 
 // createTestConfig creates a test configuration with temporary directory
@@ -246,6 +247,43 @@ func TestAddConversation(t *testing.T) {
 	}
 }
 
+func TestRecordActivity(t *testing.T) {
+	cfg := createTestConfig(t)
+	database := createTestDB(t, cfg)
+	defer database.Close()
+	sm, err := NewSessionManager(cfg, database)
+	if err != nil {
+		t.Fatalf("Failed to create session manager: %v", err)
+	}
+
+	if _, ok := sm.RecordActivity("project-1", time.Now()); ok {
+		t.Error("Activity should not start a session")
+	}
+
+	now := time.Now()
+	session, err := sm.GetOrCreateSession("project-1", createTestConversation(t, "composer-1", now))
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+
+	later := now.Add(5 * time.Minute)
+	sessionID, ok := sm.RecordActivity("project-1", later)
+	if !ok || sessionID != session.ID {
+		t.Fatalf("RecordActivity() = %q, %v; want the active session", sessionID, ok)
+	}
+	if !session.LastActivity.Equal(later) {
+		t.Errorf("LastActivity = %v, want %v", session.LastActivity, later)
+	}
+
+	// Earlier activity is attributed but doesn't move LastActivity back
+	if _, ok := sm.RecordActivity("project-1", now.Add(time.Minute)); !ok || !session.LastActivity.Equal(later) {
+		t.Errorf("LastActivity = %v after earlier activity, want %v", session.LastActivity, later)
+	}
+	if _, ok := sm.RecordActivity("project-2", later); ok {
+		t.Error("Activity should only extend its own project's session")
+	}
+}
+
 func TestAddConversation_NonexistentSession(t *testing.T) {
 	cfg := createTestConfig(t)
 	database := createTestDB(t, cfg)
@@ -812,3 +850,110 @@ func TestGetOrCreateSession_ExpiredSession(t *testing.T) {
 		t.Error("Expected new session ID for expired session")
 	}
 }
+
+func TestEndInactiveSessions_EndsAtSleep(t *testing.T) {
+	cfg := createTestConfig(t)
+	database := createTestDB(t, cfg)
+	defer database.Close()
+	manager, err := NewSessionManager(cfg, database)
+	if err != nil {
+		t.Fatalf("Failed to create session manager: %v", err)
+	}
+	sm := manager.(*sessionManager)
+
+	// Work stopped in the evening and the machine slept overnight
+	lastActivity := time.Now().Add(-10 * time.Hour)
+	session, err := sm.GetOrCreateSession("project-1", createTestConversation(t, "composer-1", lastActivity))
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	sleep := idle.Gap{Kind: idle.KindSleep, Start: lastActivity.Add(20 * time.Minute), End: time.Now().Add(-time.Minute)}
+	sm.sleeps = []idle.Gap{sleep}
+
+	sm.endInactiveSessions()
+
+	ended, err := sm.GetSession(session.ID)
+	if err != nil {
+		t.Fatalf("Failed to get session: %v", err)
+	}
+	if ended.EndTime == nil || !ended.EndTime.Equal(sleep.Start) {
+		t.Errorf("EndTime = %v, want the start of the sleep %v", ended.EndTime, sleep.Start)
+	}
+}
+
+func TestLoadSessions_ReconstructsOrphanedSessions(t *testing.T) {
+	cfg := createTestConfig(t)
+	database := createTestDB(t, cfg)
+	defer database.Close()
+
+	base := time.Date(2024, 3, 4, 9, 0, 0, 0, time.Local)
+	exec := func(query string, args ...interface{}) {
+		t.Helper()
+		if _, err := database.Exec(query, args...); err != nil {
+			t.Fatalf("failed to exec %q: %v", query, err)
+		}
+	}
+	exec(`INSERT INTO sessions (id, project, start_time, end_time, last_activity, created_at, updated_at) VALUES ('s-ok', 'alpha', ?, ?, ?, ?, ?)`,
+		base, base.Add(2*time.Hour), base.Add(2*time.Hour), base, base)
+	exec(`INSERT INTO sessions (id, project, start_time, last_activity, created_at, updated_at) VALUES ('s-bad', 'gamma', 'garbage', ?, ?, ?)`,
+		base, base, base)
+
+	conversation := `
+		INSERT INTO conversations (id, session_id, composer_id, name, status, message_count, first_message_time, last_message_time, created_at, updated_at)
+		VALUES (?, ?, ?, 'Test Conversation', 'completed', 0, ?, ?, ?, ?)
+	`
+	exec(conversation, "c-ok", "s-ok", "c-ok", base, base.Add(time.Hour), base, base)
+	exec(conversation, "c-overlap", "gone-overlap", "c-overlap", base.Add(30*time.Minute), base.Add(time.Hour), base, base)
+	exec(conversation, "c-overlap-2", "gone-overlap", "c-overlap-2", base.Add(3*time.Hour), base.Add(4*time.Hour), base, base)
+	exec(conversation, "c-commit", "gone-commit", "c-commit", base.Add(5*24*time.Hour), nil, base.Add(5*24*time.Hour), base)
+	exec(conversation, "c-bad", "s-bad", "c-bad", base, base.Add(time.Hour), base, base)
+	exec(conversation, "c-lost", "gone-lost", "c-lost", base.Add(10*24*time.Hour), nil, base.Add(10*24*time.Hour), base)
+	exec(`INSERT INTO commits (id, session_id, repository_path, repository_name, hash, message, author_name, author_email, timestamp, branch, created_at, updated_at)
+		VALUES ('k1', 'gone-commit', '/src/beta', 'beta', 'abc', 'fix', 'A', 'a@b.c', ?, 'main', ?, ?)`, base, base, base)
+
+	sm, err := NewSessionManager(cfg, database)
+	if err != nil {
+		t.Fatalf("Failed to create session manager: %v", err)
+	}
+	if err := sm.LoadSessions(); err != nil {
+		t.Fatalf("LoadSessions() error = %v", err)
+	}
+
+	for id, project := range map[string]string{
+		"s-ok":         "alpha",
+		"gone-overlap": "alpha",
+		"gone-commit":  "beta",
+		"s-bad":        "gamma",
+		"gone-lost":    defaultProjectName,
+	} {
+		session, err := sm.GetSession(id)
+		if err != nil {
+			t.Errorf("GetSession(%s) error = %v", id, err)
+			continue
+		}
+		if session.Project != project {
+			t.Errorf("session %s project = %q, want %q", id, session.Project, project)
+		}
+		if len(session.Conversations) == 0 {
+			t.Errorf("session %s has no conversations", id)
+		}
+	}
+
+	overlap, _ := sm.GetSession("gone-overlap")
+	if !overlap.StartTime.Equal(base.Add(30*time.Minute)) || overlap.EndTime == nil || !overlap.EndTime.Equal(base.Add(4*time.Hour)) {
+		t.Errorf("gone-overlap spans %v to %v, want its conversations' span", overlap.StartTime, overlap.EndTime)
+	}
+	if active, _ := sm.GetActiveSessions(); len(active) != 0 {
+		t.Errorf("active sessions = %d, want reconstructed sessions ended", len(active))
+	}
+
+	// Reconstructed sessions are stored, so the next load finds them
+	var count int
+	if err := database.QueryRow("SELECT COUNT(*) FROM sessions").Scan(&count); err != nil || count != 5 {
+		t.Errorf("stored sessions = %d, %v; want 5", count, err)
+	}
+	var start time.Time
+	if err := database.QueryRow("SELECT start_time FROM sessions WHERE id = 's-bad'").Scan(&start); err != nil || !start.Equal(base) {
+		t.Errorf("s-bad start_time = %v, %v; want the unreadable row replaced", start, err)
+	}
+}
@@ -0,0 +1,76 @@
+package cursor
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/stwalsh4118/clio/internal/dedup"
+)
+
+// shareCodeBlocks returns the code blocks as stored: blocks of at least
+// dedup.MinSize bytes have their content replaced by a reference to shared content.
+// The message's own blocks are left untouched.
+func shareCodeBlocks(tx *sql.Tx, blocks []CodeBlock) ([]CodeBlock, error) {
+	stored := make([]CodeBlock, len(blocks))
+	copy(stored, blocks)
+	for i := range stored {
+		if len(stored[i].Content) < dedup.MinSize {
+			continue
+		}
+		ref, err := dedup.Share(tx, stored[i].Content)
+		if err != nil {
+			return nil, err
+		}
+		stored[i].Content = ""
+		stored[i].ContentRef = ref
+	}
+	return stored, nil
+}
+
+// storedCodeBlockRefs returns the shared content references of a stored message,
+// none when the message isn't stored yet
+func storedCodeBlockRefs(tx *sql.Tx, messageID string) ([]string, error) {
+	var blocksJSON sql.NullString
+	err := tx.QueryRow("SELECT code_blocks FROM messages WHERE id = ?", messageID).Scan(&blocksJSON)
+	if err == sql.ErrNoRows || !blocksJSON.Valid || blocksJSON.String == "" {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stored code blocks: %w", err)
+	}
+
+	var blocks []CodeBlock
+	if err := json.Unmarshal([]byte(blocksJSON.String), &blocks); err != nil {
+		// A malformed value can't hold references to release
+		return nil, nil
+	}
+	var refs []string
+	for _, block := range blocks {
+		if block.ContentRef != "" {
+			refs = append(refs, block.ContentRef)
+		}
+	}
+	return refs, nil
+}
+
+// ResolveSharedCodeBlocks restores shared content in code blocks loaded from the
+// messages table, so readers see the blocks as captured. Queries run on q, so
+// callers iterating rows must finish before resolving.
+func ResolveSharedCodeBlocks(q dedup.Querier, messages []Message) error {
+	for i := range messages {
+		for j := range messages[i].CodeBlocks {
+			block := &messages[i].CodeBlocks[j]
+			if block.ContentRef == "" {
+				continue
+			}
+			content, err := dedup.Load(q, block.ContentRef)
+			if err != nil {
+				return fmt.Errorf("failed to resolve code block of message %s: %w", messages[i].BubbleID, err)
+			}
+			block.Content = content
+			block.ContentRef = ""
+		}
+	}
+	return nil
+}
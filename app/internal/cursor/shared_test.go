@@ -0,0 +1,86 @@
+package cursor
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/dedup"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+func TestStorage_SharedCodeBlocks(t *testing.T) {
+	cfg := createTestConfig(t)
+	database := createTestDB(t, cfg)
+	defer database.Close()
+
+	if _, err := database.Exec(`
+		INSERT INTO sessions (id, project, start_time, last_activity, created_at, updated_at)
+		VALUES ('s1', 'test-project', ?, ?, ?, ?)
+	`, time.Now(), time.Now(), time.Now(), time.Now()); err != nil {
+		t.Fatalf("Failed to create test session: %v", err)
+	}
+
+	storage, err := NewConversationStorage(database, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+
+	// An agent retrying a change repeats the same large block
+	large := strings.Repeat("func retry() {}\n", dedup.MinSize/16+1)
+	blocks := func(content string) []CodeBlock {
+		return []CodeBlock{{Content: content, LanguageID: "go"}, {Content: "small", LanguageID: "go", CodeBlockIdx: 1}}
+	}
+	conversation := &Conversation{
+		ComposerID: "c1",
+		CreatedAt:  time.Now(),
+		Messages: []Message{
+			{BubbleID: "b1", Type: 2, Role: "agent", CodeBlocks: blocks(large), CreatedAt: time.Now()},
+			{BubbleID: "b2", Type: 2, Role: "agent", CodeBlocks: blocks(large), CreatedAt: time.Now()},
+		},
+	}
+	if err := storage.StoreConversation(conversation, "s1"); err != nil {
+		t.Fatalf("StoreConversation() error = %v", err)
+	}
+	if conversation.Messages[0].CodeBlocks[0].Content != large {
+		t.Error("storing should not change the caller's code blocks")
+	}
+
+	sharedRefs := func() (rows, refs int) {
+		t.Helper()
+		if err := database.QueryRow("SELECT COUNT(*), COALESCE(SUM(ref_count), 0) FROM shared_contents").Scan(&rows, &refs); err != nil {
+			t.Fatalf("failed to query shared contents: %v", err)
+		}
+		return rows, refs
+	}
+	if rows, refs := sharedRefs(); rows != 1 || refs != 2 {
+		t.Errorf("shared contents = %d rows with %d references, want 1 with 2", rows, refs)
+	}
+
+	stored, err := storage.GetConversation("c1")
+	if err != nil {
+		t.Fatalf("GetConversation() error = %v", err)
+	}
+	for _, message := range stored.Messages {
+		if message.CodeBlocks[0].Content != large || message.CodeBlocks[0].ContentRef != "" || message.CodeBlocks[1].Content != "small" {
+			t.Errorf("message %s code blocks = %+v, want the captured blocks", message.BubbleID, message.CodeBlocks)
+		}
+	}
+
+	// Rewriting a message keeps its reference; replacing its block releases it
+	if err := storage.UpgradeMessages("c1", []*Message{&stored.Messages[0]}); err != nil {
+		t.Fatalf("UpgradeMessages() error = %v", err)
+	}
+	if rows, refs := sharedRefs(); rows != 1 || refs != 2 {
+		t.Errorf("after rewrite shared contents = %d rows with %d references, want 1 with 2", rows, refs)
+	}
+	for i := range stored.Messages {
+		stored.Messages[i].CodeBlocks = blocks("short now")
+	}
+	if err := storage.UpgradeMessages("c1", []*Message{&stored.Messages[0], &stored.Messages[1]}); err != nil {
+		t.Fatalf("UpgradeMessages() error = %v", err)
+	}
+	if rows, _ := sharedRefs(); rows != 0 {
+		t.Errorf("shared contents = %d rows, want none once nothing refers to them", rows)
+	}
+}
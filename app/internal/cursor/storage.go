@@ -6,7 +6,12 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/stwalsh4118/clio/internal/blobs"
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/dedup"
+	"github.com/stwalsh4118/clio/internal/language"
 	"github.com/stwalsh4118/clio/internal/logging"
+	"github.com/stwalsh4118/clio/internal/sensitive"
 )
 
 // ConversationStorage defines the interface for storing and retrieving conversations and messages
@@ -14,19 +19,47 @@ type ConversationStorage interface {
 	StoreConversation(conversation *Conversation, sessionID string) error
 	StoreMessage(message *Message, conversationID string) error
 	UpdateConversation(conversationID string, newMessages []*Message) error
+	UpgradeMessages(conversationID string, messages []*Message) error
 	GetConversation(conversationID string) (*Conversation, error)
 	GetConversationByComposerID(composerID string) (*Conversation, error)
 	GetConversationsBySession(sessionID string) ([]*Conversation, error)
+	GetMessageRevisions(messageID string) ([]MessageRevision, error)
 }
 
 // conversationStorage implements ConversationStorage for database persistence
 type conversationStorage struct {
 	db     *sql.DB
+	gate   *sensitive.Gate // nil stores messages as captured
+	blobs  blobs.Store     // nil reads messages moved to the blob store as their previews
 	logger logging.Logger
 }
 
 // NewConversationStorage creates a new conversation storage instance
 func NewConversationStorage(db *sql.DB, logger logging.Logger) (ConversationStorage, error) {
+	return NewConversationStorageWithGate(db, nil, logger)
+}
+
+// NewConversationStorageWithGate creates a conversation storage that passes
+// messages through the sensitive gate before storing them. A nil gate stores
+// messages as captured.
+func NewConversationStorageWithGate(db *sql.DB, gate *sensitive.Gate, logger logging.Logger) (ConversationStorage, error) {
+	return newConversationStorage(db, gate, nil, logger)
+}
+
+// openBlobStore opens the configured blob store, or returns nil when it can't be
+// opened so messages moved there are read as their previews
+func openBlobStore(cfg *config.Config, logger logging.Logger) blobs.Store {
+	store, err := blobs.NewStore(cfg.Storage.BlobsPath, logger)
+	if err != nil {
+		logger.Warn("failed to open blob store, reading oversized messages as previews", "error", err)
+		return nil
+	}
+	return store
+}
+
+// newConversationStorage creates a conversation storage that also loads messages
+// moved to the blob store, for readers that rewrite or analyze full messages
+func newConversationStorage(db *sql.DB, gate *sensitive.Gate, blobStore blobs.Store, logger logging.Logger) (ConversationStorage, error) {
 	if db == nil {
 		return nil, fmt.Errorf("database cannot be nil")
 	}
@@ -39,6 +72,8 @@ func NewConversationStorage(db *sql.DB, logger logging.Logger) (ConversationStor
 
 	return &conversationStorage{
 		db:     db,
+		gate:   gate,
+		blobs:  blobStore,
 		logger: logger,
 	}, nil
 }
@@ -94,11 +129,15 @@ func (cs *conversationStorage) StoreConversation(conversation *Conversation, ses
 	}
 
 	now := time.Now()
+	source := conversation.Source
+	if source == "" {
+		source = SourceCursor
+	}
 
 	// Store conversation (use composer_id as the conversation ID)
 	_, err = tx.Exec(`
-		INSERT INTO conversations (id, session_id, composer_id, name, status, message_count, first_message_time, last_message_time, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO conversations (id, session_id, composer_id, name, status, message_count, first_message_time, last_message_time, created_at, updated_at, source)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(id) DO UPDATE SET
 			session_id = excluded.session_id,
 			name = excluded.name,
@@ -118,6 +157,7 @@ func (cs *conversationStorage) StoreConversation(conversation *Conversation, ses
 		lastMessageTime,
 		conversation.CreatedAt,
 		now,
+		source,
 	)
 	if err != nil {
 		cs.logger.Error("failed to store conversation", "composer_id", conversation.ComposerID, "session_id", sessionID, "error", err)
@@ -126,12 +166,16 @@ func (cs *conversationStorage) StoreConversation(conversation *Conversation, ses
 
 	// Store all messages
 	for i := range conversation.Messages {
-		if err := cs.storeMessageInTx(tx, &conversation.Messages[i], conversation.ComposerID); err != nil {
+		if err := cs.storeMessageInTx(tx, &conversation.Messages[i], conversation.ComposerID, true); err != nil {
 			cs.logger.Error("failed to store message", "composer_id", conversation.ComposerID, "bubble_id", conversation.Messages[i].BubbleID, "error", err)
 			return fmt.Errorf("failed to store message %s: %w", conversation.Messages[i].BubbleID, err)
 		}
 	}
 
+	if err := refreshConversationParserVersionInTx(tx, conversation.ComposerID); err != nil {
+		return err
+	}
+
 	// Commit transaction
 	if err := tx.Commit(); err != nil {
 		cs.logger.Error("failed to commit transaction", "composer_id", conversation.ComposerID, "error", err)
@@ -142,12 +186,43 @@ func (cs *conversationStorage) StoreConversation(conversation *Conversation, ses
 	return nil
 }
 
-// storeMessageInTx stores a message within an existing transaction
-func (cs *conversationStorage) storeMessageInTx(tx *sql.Tx, message *Message, conversationID string) error {
+// storeMessageInTx stores a message within an existing transaction. With keepRevision,
+// a stored version whose text Cursor rewrote is kept in message_revisions first.
+func (cs *conversationStorage) storeMessageInTx(tx *sql.Tx, message *Message, conversationID string, keepRevision bool) error {
+	// Sensitive content is redacted or dropped before anything about the message is stored
+	if cs.gate != nil {
+		gated, err := cs.gateMessageInTx(tx, message, conversationID)
+		if err != nil {
+			return err
+		}
+		if gated == nil {
+			return nil
+		}
+		message = gated
+	}
+
+	if keepRevision {
+		if err := saveRevisionInTx(tx, message, conversationID); err != nil {
+			return err
+		}
+	}
+
+	// Large code blocks are stored once however many messages repeat them. The
+	// version being replaced is released after the new one is shared, so content
+	// both versions hold never drops to zero references.
+	releasedRefs, err := storedCodeBlockRefs(tx, message.BubbleID)
+	if err != nil {
+		return err
+	}
+	codeBlocks, err := shareCodeBlocks(tx, message.CodeBlocks)
+	if err != nil {
+		return err
+	}
+
 	// Marshal code blocks to JSON
 	var codeBlocksJSON sql.NullString
-	if len(message.CodeBlocks) > 0 {
-		codeBlocksBytes, err := json.Marshal(message.CodeBlocks)
+	if len(codeBlocks) > 0 {
+		codeBlocksBytes, err := json.Marshal(codeBlocks)
 		if err != nil {
 			cs.logger.Warn("failed to marshal code blocks", "conversation_id", conversationID, "bubble_id", message.BubbleID, "error", err)
 			return fmt.Errorf("failed to marshal code blocks: %w", err)
@@ -203,20 +278,21 @@ func (cs *conversationStorage) storeMessageInTx(tx *sql.Tx, message *Message, co
 		contentSourceNull = sql.NullString{String: message.ContentSource, Valid: true}
 	}
 
-	_, err := tx.Exec(`
+	_, err = tx.Exec(`
 		INSERT INTO messages (
 			id, conversation_id, bubble_id, type, role, content, 
 			thinking_text, code_blocks, tool_calls,
 			has_code, has_thinking, has_tool_calls, content_source,
-			created_at, metadata
+			created_at, metadata, parser_version, language
 		)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(id) DO UPDATE SET
 			conversation_id = excluded.conversation_id,
 			bubble_id = excluded.bubble_id,
 			type = excluded.type,
 			role = excluded.role,
 			content = excluded.content,
+			content_blob = NULL,
 			thinking_text = excluded.thinking_text,
 			code_blocks = excluded.code_blocks,
 			tool_calls = excluded.tool_calls,
@@ -225,7 +301,9 @@ func (cs *conversationStorage) storeMessageInTx(tx *sql.Tx, message *Message, co
 			has_tool_calls = excluded.has_tool_calls,
 			content_source = excluded.content_source,
 			created_at = excluded.created_at,
-			metadata = excluded.metadata
+			metadata = excluded.metadata,
+			parser_version = excluded.parser_version,
+			language = excluded.language
 	`,
 		message.BubbleID, // id = bubble_id
 		conversationID,
@@ -242,11 +320,18 @@ func (cs *conversationStorage) storeMessageInTx(tx *sql.Tx, message *Message, co
 		contentSourceNull,
 		message.CreatedAt,
 		metadataJSON,
+		message.ParserVersion,
+		language.Detect(message.Text),
 	)
 	if err != nil {
 		cs.logger.Error("failed to insert message", "conversation_id", conversationID, "bubble_id", message.BubbleID, "error", err)
 		return fmt.Errorf("failed to insert message: %w", err)
 	}
+	for _, ref := range releasedRefs {
+		if err := dedup.Release(tx, ref); err != nil {
+			return err
+		}
+	}
 
 	cs.logger.Debug("stored message", "conversation_id", conversationID, "bubble_id", message.BubbleID, "role", message.Role, "has_code", message.HasCode, "has_thinking", message.HasThinking)
 	return nil
@@ -284,7 +369,7 @@ func (cs *conversationStorage) StoreMessage(message *Message, conversationID str
 	defer tx.Rollback()
 
 	// Store message
-	if err := cs.storeMessageInTx(tx, message, conversationID); err != nil {
+	if err := cs.storeMessageInTx(tx, message, conversationID, true); err != nil {
 		return err
 	}
 
@@ -352,7 +437,7 @@ func (cs *conversationStorage) UpdateConversation(conversationID string, newMess
 
 	// Store all new messages
 	for _, message := range newMessages {
-		if err := cs.storeMessageInTx(tx, message, conversationID); err != nil {
+		if err := cs.storeMessageInTx(tx, message, conversationID, true); err != nil {
 			cs.logger.Error("failed to store message in update", "conversation_id", conversationID, "bubble_id", message.BubbleID, "error", err)
 			return fmt.Errorf("failed to store message %s: %w", message.BubbleID, err)
 		}
@@ -409,6 +494,10 @@ func (cs *conversationStorage) UpdateConversation(conversationID string, newMess
 		return fmt.Errorf("failed to update conversation: %w", err)
 	}
 
+	if err := refreshConversationParserVersionInTx(tx, conversationID); err != nil {
+		return err
+	}
+
 	// Commit transaction
 	if err := tx.Commit(); err != nil {
 		cs.logger.Error("failed to commit transaction", "conversation_id", conversationID, "error", err)
@@ -419,6 +508,62 @@ func (cs *conversationStorage) UpdateConversation(conversationID string, newMess
 	return nil
 }
 
+// UpgradeMessages rewrites existing messages in place with freshly parsed content
+// (e.g. after a parser upgrade). Messages are matched by bubble ID; stored messages that
+// aren't in the list are left untouched, and message counts are not changed.
+func (cs *conversationStorage) UpgradeMessages(conversationID string, messages []*Message) error {
+	if conversationID == "" {
+		return fmt.Errorf("conversation ID cannot be empty")
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+
+	tx, err := cs.db.Begin()
+	if err != nil {
+		cs.logger.Error("failed to begin transaction", "conversation_id", conversationID, "error", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// A parser upgrade re-reads the same bubble, so the replaced version isn't a revision
+	for _, message := range messages {
+		if err := cs.storeMessageInTx(tx, message, conversationID, false); err != nil {
+			return fmt.Errorf("failed to upgrade message %s: %w", message.BubbleID, err)
+		}
+	}
+
+	if _, err := tx.Exec("UPDATE conversations SET updated_at = ? WHERE id = ?", time.Now(), conversationID); err != nil {
+		return fmt.Errorf("failed to update conversation: %w", err)
+	}
+
+	if err := refreshConversationParserVersionInTx(tx, conversationID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		cs.logger.Error("failed to commit transaction", "conversation_id", conversationID, "error", err)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	cs.logger.Info("upgraded messages", "conversation_id", conversationID, "message_count", len(messages))
+	return nil
+}
+
+// refreshConversationParserVersionInTx sets a conversation's parser_version to the lowest
+// version among its messages, so a conversation is only "current" when every message is
+func refreshConversationParserVersionInTx(tx *sql.Tx, conversationID string) error {
+	_, err := tx.Exec(`
+		UPDATE conversations
+		SET parser_version = (SELECT COALESCE(MIN(parser_version), 0) FROM messages WHERE conversation_id = ?)
+		WHERE id = ?
+	`, conversationID, conversationID)
+	if err != nil {
+		return fmt.Errorf("failed to update conversation parser version: %w", err)
+	}
+	return nil
+}
+
 // GetConversation retrieves a conversation by its ID (composer_id)
 func (cs *conversationStorage) GetConversation(conversationID string) (*Conversation, error) {
 	return cs.GetConversationByComposerID(conversationID)
@@ -437,7 +582,7 @@ func (cs *conversationStorage) GetConversationByComposerID(composerID string) (*
 	var firstMsgTime, lastMsgTime sql.NullTime
 	var messageCount int // We'll use actual message count from messages table
 	err := cs.db.QueryRow(`
-		SELECT id, composer_id, name, status, message_count, first_message_time, last_message_time, created_at
+		SELECT id, composer_id, name, status, message_count, first_message_time, last_message_time, created_at, parser_version, source
 		FROM conversations
 		WHERE composer_id = ?
 	`, composerID).Scan(
@@ -449,6 +594,8 @@ func (cs *conversationStorage) GetConversationByComposerID(composerID string) (*
 		&firstMsgTime,
 		&lastMsgTime,
 		&conv.CreatedAt,
+		&conv.ParserVersion,
+		&conv.Source,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -481,7 +628,7 @@ func (cs *conversationStorage) GetConversationsBySession(sessionID string) ([]*C
 
 	// Query conversations
 	rows, err := cs.db.Query(`
-		SELECT id, composer_id, name, status, message_count, first_message_time, last_message_time, created_at
+		SELECT id, composer_id, name, status, message_count, first_message_time, last_message_time, created_at, parser_version, source
 		FROM conversations
 		WHERE session_id = ?
 		ORDER BY created_at ASC
@@ -507,6 +654,8 @@ func (cs *conversationStorage) GetConversationsBySession(sessionID string) ([]*C
 			&firstMsgTime,
 			&lastMsgTime,
 			&conv.CreatedAt,
+			&conv.ParserVersion,
+			&conv.Source,
 		)
 		if err != nil {
 			cs.logger.Warn("failed to scan conversation row, skipping", "session_id", sessionID, "error", err)
@@ -542,10 +691,10 @@ func (cs *conversationStorage) GetConversationsBySession(sessionID string) ([]*C
 // getMessagesByConversationID retrieves all messages for a conversation, ordered by created_at
 func (cs *conversationStorage) getMessagesByConversationID(conversationID string) ([]Message, error) {
 	rows, err := cs.db.Query(`
-		SELECT id, bubble_id, type, role, content, 
+		SELECT id, bubble_id, type, role, content, content_blob,
 			thinking_text, code_blocks, tool_calls,
 			has_code, has_thinking, has_tool_calls, content_source,
-			created_at, metadata
+			created_at, metadata, parser_version
 		FROM messages
 		WHERE conversation_id = ?
 		ORDER BY created_at ASC
@@ -560,7 +709,7 @@ func (cs *conversationStorage) getMessagesByConversationID(conversationID string
 	var skippedCount int
 	for rows.Next() {
 		var msg Message
-		var thinkingTextNull, codeBlocksJSON, toolCallsJSON, metadataJSON, contentSourceNull sql.NullString
+		var contentBlob, thinkingTextNull, codeBlocksJSON, toolCallsJSON, metadataJSON, contentSourceNull sql.NullString
 		var hasCodeInt, hasThinkingInt, hasToolCallsInt int
 
 		err := rows.Scan(
@@ -569,6 +718,7 @@ func (cs *conversationStorage) getMessagesByConversationID(conversationID string
 			&msg.Type,
 			&msg.Role,
 			&msg.Text,
+			&contentBlob,
 			&thinkingTextNull,
 			&codeBlocksJSON,
 			&toolCallsJSON,
@@ -578,6 +728,7 @@ func (cs *conversationStorage) getMessagesByConversationID(conversationID string
 			&contentSourceNull,
 			&msg.CreatedAt,
 			&metadataJSON,
+			&msg.ParserVersion,
 		)
 		if err != nil {
 			cs.logger.Warn("failed to scan message row, skipping", "conversation_id", conversationID, "error", err)
@@ -585,6 +736,12 @@ func (cs *conversationStorage) getMessagesByConversationID(conversationID string
 			continue // Skip invalid rows
 		}
 
+		// Stored messages are rewritten from what's read, so a preview must not stand in for the message
+		if msg.Text, err = blobs.Resolve(cs.blobs, msg.Text, contentBlob); err != nil {
+			cs.logger.Error("failed to load message blob", "conversation_id", conversationID, "bubble_id", msg.BubbleID, "error", err)
+			return nil, fmt.Errorf("failed to load message %s: %w", msg.BubbleID, err)
+		}
+
 		// Parse thinking_text
 		if thinkingTextNull.Valid {
 			msg.ThinkingText = thinkingTextNull.String
@@ -636,6 +793,10 @@ func (cs *conversationStorage) getMessagesByConversationID(conversationID string
 		return nil, fmt.Errorf("error iterating messages: %w", err)
 	}
 
+	if err := ResolveSharedCodeBlocks(cs.db, messages); err != nil {
+		return nil, err
+	}
+
 	if skippedCount > 0 {
 		cs.logger.Warn("retrieved messages with skipped entries", "conversation_id", conversationID, "successful", len(messages), "skipped", skippedCount)
 	}
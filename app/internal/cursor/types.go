@@ -2,6 +2,9 @@ package cursor
 
 import "time"
 
+// SourceCursor marks conversations captured from Cursor, as opposed to imported history
+const SourceCursor = "cursor"
+
 // Conversation represents a complete conversation from Cursor's database
 type Conversation struct {
 	ComposerID string    // Unique identifier for the conversation
@@ -9,13 +12,21 @@ type Conversation struct {
 	Status     string    // Conversation status (e.g., "completed", "active", "none")
 	CreatedAt  time.Time // When the conversation was created
 	Messages   []Message // All messages in chronological order
+	Source     string    // Where the conversation came from: SourceCursor or an import format (empty means SourceCursor)
+
+	Quarantined   []QuarantinedPayload // Payloads whose shape didn't match the expected schema (not persisted with the conversation)
+	RawPayload    []byte               // Raw composerData JSON as read from Cursor (archived when enabled)
+	ParserVersion int                  // Lowest parser version among stored messages (set when loaded from storage)
 }
 
 // CodeBlock represents a code block in a message
 type CodeBlock struct {
-	Content     string `json:"content"`      // The actual code content
-	LanguageID  string `json:"languageId"`   // Language identifier (e.g., "go", "typescript", "shellscript")
-	CodeBlockIdx int   `json:"codeBlockIdx"` // Index of the code block in the message
+	Content      string `json:"content"`      // The actual code content
+	LanguageID   string `json:"languageId"`   // Language identifier (e.g., "go", "typescript", "shellscript")
+	CodeBlockIdx int    `json:"codeBlockIdx"` // Index of the code block in the message
+	// ContentRef references shared content in storage, which replaces Content for large
+	// blocks; loaded messages have it resolved back into Content
+	ContentRef string `json:"contentRef,omitempty"`
 }
 
 // ToolCall represents a tool call made by the agent
@@ -40,4 +51,6 @@ type Message struct {
 	HasToolCalls  bool                   // Derived: true if tool_calls is not empty
 	CreatedAt     time.Time              // When the message was created
 	Metadata      map[string]interface{} // Additional metadata for future extensibility
+	RawPayload    []byte                 // Raw bubble JSON as read from Cursor (archived when enabled)
+	ParserVersion int                    // Parser version that extracted this message (0 = before versioning)
 }
@@ -26,6 +26,8 @@ type conversationUpdater struct {
 	parser         ParserService
 	storage        ConversationStorage
 	sessionManager SessionManager
+	quarantine     QuarantineStorage
+	archive        RawPayloadArchive // nil unless cursor.archive_raw_payloads is enabled
 	logger         logging.Logger
 }
 
@@ -54,12 +56,29 @@ func NewConversationUpdater(cfg *config.Config, db *sql.DB, parser ParserService
 	}
 	logger = logger.With("component", "conversation_updater")
 
+	// Create quarantine storage for payloads with unrecognised schema
+	quarantine, err := NewQuarantineStorage(db, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create quarantine storage: %w", err)
+	}
+
+	// Create raw payload archive if enabled
+	var archive RawPayloadArchive
+	if cfg.Cursor.ArchiveRawPayloads {
+		archive, err = NewRawPayloadArchive(db, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create raw payload archive: %w", err)
+		}
+	}
+
 	return &conversationUpdater{
 		config:         cfg,
 		db:             db,
 		parser:         parser,
 		storage:        storage,
 		sessionManager: sessionManager,
+		quarantine:     quarantine,
+		archive:        archive,
 		logger:         logger,
 	}, nil
 }
@@ -233,6 +252,7 @@ func (u *conversationUpdater) ProcessUpdate(composerID string) error {
 
 	// Parse the full conversation
 	conversation, err := u.parser.ParseConversation(composerID)
+	recordQuarantine(u.quarantine, u.logger, conversation, err)
 	if err != nil {
 		return fmt.Errorf("failed to parse conversation: %w", err)
 	}
@@ -271,6 +291,9 @@ func (u *conversationUpdater) ProcessUpdate(composerID string) error {
 		return fmt.Errorf("failed to update conversation: %w", err)
 	}
 
+	// Archive raw payloads for future re-parsing (unchanged payloads are skipped)
+	archiveRawPayloads(u.archive, u.logger, conversation)
+
 	// Mark as processed with new message count
 	newMessageCount := len(conversation.Messages)
 	if err := u.MarkAsProcessed(composerID, newMessageCount); err != nil {
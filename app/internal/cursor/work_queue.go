@@ -0,0 +1,232 @@
+package cursor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+// WorkQueue defines the interface for a bounded, rate-limited queue of composer IDs
+// waiting to be parsed and stored
+type WorkQueue interface {
+	Start(ctx context.Context) error
+	Enqueue(ctx context.Context, composerID string) error // Blocks while the queue is full
+	Stop() error
+	Pending() int
+}
+
+// ComposerHandler processes a single composer ID taken from the work queue
+type ComposerHandler func(composerID string) error
+
+// workQueue implements WorkQueue with a buffered channel and a fixed worker pool
+type workQueue struct {
+	items       chan string
+	pending     map[string]struct{} // Composer IDs waiting in the queue (dedupe)
+	inFlight    map[string]bool     // Composer IDs being processed -> enqueued again meanwhile
+	handler     ComposerHandler
+	concurrency int
+	rateLimit   time.Duration // Minimum time between items; zero means unlimited
+	limiter     *time.Ticker
+	logger      logging.Logger
+	started     bool
+	mu          sync.Mutex
+	wg          sync.WaitGroup
+}
+
+const (
+	// defaultQueueSize is the default number of composer IDs that can wait for processing
+	defaultQueueSize = 100
+	// defaultMaxConcurrency is the default number of composers processed in parallel
+	defaultMaxConcurrency = 1
+)
+
+// NewWorkQueue creates a new work queue using the capture limits from config
+func NewWorkQueue(cfg *config.Config, handler ComposerHandler, logger logging.Logger) (WorkQueue, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+	if handler == nil {
+		return nil, fmt.Errorf("handler cannot be nil")
+	}
+	if logger == nil {
+		logger = logging.NewNoopLogger()
+	}
+
+	queueSize := cfg.Cursor.QueueSize
+	if queueSize < 1 {
+		queueSize = defaultQueueSize
+	}
+	concurrency := cfg.Cursor.MaxConcurrency
+	if concurrency < 1 {
+		concurrency = defaultMaxConcurrency
+	}
+	var rateLimit time.Duration
+	if cfg.Cursor.MaxConversationsPerSecond > 0 {
+		rateLimit = time.Second / time.Duration(cfg.Cursor.MaxConversationsPerSecond)
+	}
+
+	return &workQueue{
+		items:       make(chan string, queueSize),
+		pending:     make(map[string]struct{}),
+		inFlight:    make(map[string]bool),
+		handler:     handler,
+		concurrency: concurrency,
+		rateLimit:   rateLimit,
+		logger:      logger.With("component", "work_queue"),
+	}, nil
+}
+
+// Start launches the worker pool. Workers exit when ctx is cancelled.
+func (q *workQueue) Start(ctx context.Context) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.started {
+		return fmt.Errorf("work queue is already started")
+	}
+
+	if q.rateLimit > 0 {
+		q.limiter = time.NewTicker(q.rateLimit)
+	}
+
+	for i := 0; i < q.concurrency; i++ {
+		q.wg.Add(1)
+		go q.worker(ctx)
+	}
+
+	q.started = true
+	q.logger.Debug("work queue started", "concurrency", q.concurrency, "queue_size", cap(q.items), "rate_limit", q.rateLimit)
+	return nil
+}
+
+// Enqueue adds a composer ID to the queue. Composer IDs that are already queued are skipped;
+// one being processed is queued again once it finishes, so updates made meanwhile aren't missed.
+// When the queue is full, Enqueue blocks until space frees up or ctx is cancelled,
+// which pushes back on the poll loop instead of spawning unbounded work.
+func (q *workQueue) Enqueue(ctx context.Context, composerID string) error {
+	q.mu.Lock()
+	if _, exists := q.pending[composerID]; exists {
+		q.mu.Unlock()
+		q.logger.Debug("composer already queued, skipping", "composer_id", composerID)
+		return nil
+	}
+	if _, processing := q.inFlight[composerID]; processing {
+		q.inFlight[composerID] = true
+		q.mu.Unlock()
+		q.logger.Debug("composer being processed, will queue again when done", "composer_id", composerID)
+		return nil
+	}
+	q.pending[composerID] = struct{}{}
+	q.mu.Unlock()
+
+	select {
+	case q.items <- composerID:
+		return nil
+	case <-ctx.Done():
+		q.done(composerID)
+		return ctx.Err()
+	}
+}
+
+// worker processes items until the context is cancelled
+func (q *workQueue) worker(ctx context.Context) {
+	defer q.wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case composerID := <-q.items:
+			// Wait for a rate limit slot before processing
+			if q.limiter != nil {
+				select {
+				case <-ctx.Done():
+					q.done(composerID)
+					return
+				case <-q.limiter.C:
+				}
+			}
+
+			q.mu.Lock()
+			delete(q.pending, composerID)
+			q.inFlight[composerID] = false
+			q.mu.Unlock()
+
+			if err := q.handler(composerID); err != nil {
+				q.logger.Error("failed to process composer", "composer_id", composerID, "error", err)
+				// Continue processing other composers despite errors
+			}
+			if q.finish(composerID) {
+				q.requeue(ctx, composerID)
+			}
+		}
+	}
+}
+
+// done removes a composer ID from the pending set so it can be queued again
+func (q *workQueue) done(composerID string) {
+	q.mu.Lock()
+	delete(q.pending, composerID)
+	q.mu.Unlock()
+}
+
+// finish marks a composer ID as processed and reports whether it was enqueued
+// while in progress. If so it is pending again and must be requeued.
+func (q *workQueue) finish(composerID string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	dirty := q.inFlight[composerID]
+	delete(q.inFlight, composerID)
+	if dirty {
+		q.pending[composerID] = struct{}{}
+	}
+	return dirty
+}
+
+// requeue puts a pending composer ID back on the queue. When the queue is full it
+// waits in the background, so a worker never blocks on its own queue.
+func (q *workQueue) requeue(ctx context.Context, composerID string) {
+	select {
+	case q.items <- composerID:
+		return
+	default:
+	}
+
+	go func() {
+		select {
+		case q.items <- composerID:
+		case <-ctx.Done():
+			q.done(composerID)
+		}
+	}()
+}
+
+// Stop waits for workers to exit. The context passed to Start must be cancelled first.
+func (q *workQueue) Stop() error {
+	q.mu.Lock()
+	if !q.started {
+		q.mu.Unlock()
+		return nil
+	}
+	q.started = false
+	q.mu.Unlock()
+
+	q.wg.Wait()
+
+	if q.limiter != nil {
+		q.limiter.Stop()
+	}
+	return nil
+}
+
+// Pending returns the number of composer IDs queued or in progress
+func (q *workQueue) Pending() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.pending) + len(q.inFlight)
+}
@@ -0,0 +1,207 @@
+package cursor
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/config"
+)
+
+func TestNewWorkQueue(t *testing.T) {
+	handler := func(string) error { return nil }
+
+	if _, err := NewWorkQueue(&config.Config{}, handler, nil); err != nil {
+		t.Fatalf("NewWorkQueue() error = %v, want nil", err)
+	}
+	if _, err := NewWorkQueue(nil, handler, nil); err == nil {
+		t.Error("NewWorkQueue(nil, ...) expected error, got nil")
+	}
+	if _, err := NewWorkQueue(&config.Config{}, nil, nil); err == nil {
+		t.Error("NewWorkQueue(..., nil handler) expected error, got nil")
+	}
+}
+
+func TestWorkQueue_ProcessesItems(t *testing.T) {
+	var mu sync.Mutex
+	processed := make(map[string]int)
+	var wg sync.WaitGroup
+	wg.Add(3)
+	handler := func(composerID string) error {
+		mu.Lock()
+		processed[composerID]++
+		mu.Unlock()
+		wg.Done()
+		return nil
+	}
+
+	cfg := &config.Config{Cursor: config.CursorConfig{QueueSize: 10, MaxConcurrency: 2}}
+	queue, err := NewWorkQueue(cfg, handler, nil)
+	if err != nil {
+		t.Fatalf("NewWorkQueue() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := queue.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	for _, id := range []string{"a", "b", "c"} {
+		if err := queue.Enqueue(ctx, id); err != nil {
+			t.Fatalf("Enqueue(%q) error = %v", id, err)
+		}
+	}
+	wg.Wait()
+
+	cancel()
+	queue.Stop()
+
+	for _, id := range []string{"a", "b", "c"} {
+		if processed[id] != 1 {
+			t.Errorf("composer %q processed %d times, want 1", id, processed[id])
+		}
+	}
+	if queue.Pending() != 0 {
+		t.Errorf("Pending() = %d, want 0", queue.Pending())
+	}
+}
+
+func TestWorkQueue_DedupesPendingItems(t *testing.T) {
+	var calls int32
+	handler := func(string) error {
+		atomic.AddInt32(&calls, 1)
+		return nil
+	}
+
+	cfg := &config.Config{Cursor: config.CursorConfig{QueueSize: 10}}
+	queue, err := NewWorkQueue(cfg, handler, nil)
+	if err != nil {
+		t.Fatalf("NewWorkQueue() error = %v", err)
+	}
+
+	// Enqueue before starting so items stay pending
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		if err := queue.Enqueue(ctx, "same"); err != nil {
+			t.Fatalf("Enqueue() error = %v", err)
+		}
+	}
+	if queue.Pending() != 1 {
+		t.Errorf("Pending() = %d, want 1", queue.Pending())
+	}
+}
+
+func TestWorkQueue_RequeuesItemsEnqueuedWhileProcessing(t *testing.T) {
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	var calls int32
+	handler := func(string) error {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			started <- struct{}{}
+			<-release
+		}
+		return nil
+	}
+
+	cfg := &config.Config{Cursor: config.CursorConfig{QueueSize: 10}}
+	queue, err := NewWorkQueue(cfg, handler, nil)
+	if err != nil {
+		t.Fatalf("NewWorkQueue() error = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() {
+		cancel()
+		queue.Stop()
+	}()
+	if err := queue.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	if err := queue.Enqueue(ctx, "same"); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	<-started
+
+	// Updates arriving while the composer is processed are coalesced into one more run
+	for i := 0; i < 2; i++ {
+		if err := queue.Enqueue(ctx, "same"); err != nil {
+			t.Fatalf("Enqueue() error = %v", err)
+		}
+	}
+	if queue.Pending() != 1 {
+		t.Errorf("Pending() = %d, want 1", queue.Pending())
+	}
+	close(release)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for queue.Pending() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Pending() = %d, want the requeued composer processed", queue.Pending())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("handler called %d times, want 2", got)
+	}
+}
+
+func TestWorkQueue_EnqueueBlocksWhenFull(t *testing.T) {
+	cfg := &config.Config{Cursor: config.CursorConfig{QueueSize: 1}}
+	queue, err := NewWorkQueue(cfg, func(string) error { return nil }, nil)
+	if err != nil {
+		t.Fatalf("NewWorkQueue() error = %v", err)
+	}
+
+	// Workers not started - first item fills the queue
+	if err := queue.Enqueue(context.Background(), "first"); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := queue.Enqueue(ctx, "second"); err == nil {
+		t.Error("Enqueue() on full queue expected context error, got nil")
+	}
+
+	// Cancelled item is no longer pending so it can be retried later
+	if queue.Pending() != 1 {
+		t.Errorf("Pending() = %d, want 1", queue.Pending())
+	}
+}
+
+func TestWorkQueue_RateLimit(t *testing.T) {
+	var wg sync.WaitGroup
+	wg.Add(3)
+	handler := func(string) error {
+		wg.Done()
+		return nil
+	}
+
+	cfg := &config.Config{Cursor: config.CursorConfig{QueueSize: 10, MaxConversationsPerSecond: 20}}
+	queue, err := NewWorkQueue(cfg, handler, nil)
+	if err != nil {
+		t.Fatalf("NewWorkQueue() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := queue.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	start := time.Now()
+	for _, id := range []string{"a", "b", "c"} {
+		queue.Enqueue(ctx, id)
+	}
+	wg.Wait()
+
+	// 20/s means one item every 50ms, so three items take at least ~150ms
+	if elapsed := time.Since(start); elapsed < 140*time.Millisecond {
+		t.Errorf("processed 3 items in %v, expected rate limiting to take >= 140ms", elapsed)
+	}
+
+	cancel()
+	queue.Stop()
+}
@@ -0,0 +1,65 @@
+package daemon
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/alerts"
+	"github.com/stwalsh4118/clio/internal/blobs"
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/logging"
+	"github.com/stwalsh4118/clio/internal/notify"
+)
+
+const (
+	// alertScanInterval is how often newly captured content is checked against the alerts
+	alertScanInterval = 30 * time.Second
+)
+
+// newAlertScanner compiles the configured alerts into a scanner
+func newAlertScanner(cfg *config.Config, database *sql.DB, blobStore blobs.Store, logger logging.Logger) (alerts.Scanner, error) {
+	rules, err := alerts.Compile(cfg.Alerts)
+	if err != nil {
+		return nil, err
+	}
+	return alerts.NewScanner(database, blobStore, rules, logger)
+}
+
+// runAlerts raises alerts for newly captured content, every alertScanInterval until shutdown
+func (d *Daemon) runAlerts() {
+	ticker := time.NewTicker(alertScanInterval)
+	defer ticker.Stop()
+
+	for {
+		d.raiseAlerts()
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// raiseAlerts notifies each match found since the previous scan
+func (d *Daemon) raiseAlerts() {
+	matches, err := d.alerts.Scan()
+	// Matches found before a failure are still raised; the scan resumes after them
+	for _, match := range matches {
+		d.notifier.Notify(notify.NewEvent(notify.EventAlertMatched, notify.AlertMatched{
+			Alert:      match.Rule,
+			Source:     match.Source,
+			Excerpt:    match.Excerpt,
+			Timestamp:  match.Time,
+			SessionID:  match.SessionID,
+			Project:    match.Project,
+			ComposerID: match.ComposerID,
+			Role:       match.Role,
+			CommitHash: match.CommitHash,
+			Repository: match.Repository,
+			File:       match.File,
+		}))
+	}
+	if err != nil {
+		d.logger.Warn("failed to scan for alerts, will retry", "error", err)
+	}
+}
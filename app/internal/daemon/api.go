@@ -0,0 +1,302 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/heartbeat"
+	"github.com/stwalsh4118/clio/internal/logging"
+	"github.com/stwalsh4118/clio/internal/report"
+	"github.com/stwalsh4118/clio/internal/search"
+	"github.com/stwalsh4118/clio/pkg/clioclient"
+	"github.com/stwalsh4118/clio/pkg/export"
+)
+
+const (
+	// apiReadHeaderTimeout bounds how long a client may take to send request headers
+	apiReadHeaderTimeout = 5 * time.Second
+	// apiShutdownTimeout bounds how long in-flight API requests may run during shutdown
+	apiShutdownTimeout = 5 * time.Second
+	// maxHeartbeatBodySize bounds a heartbeat request body
+	maxHeartbeatBodySize = 1024 * 1024
+)
+
+// GetSocketPath returns the absolute path to the daemon API socket.
+// The socket is created at ~/.clio/clio.sock, or ~/.clio/clio.<profile>.sock under a profile
+func GetSocketPath() (string, error) {
+	pidPath, err := GetPIDFilePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(pidPath), clioclient.SocketFileNameForProfile(config.ActiveProfile())), nil
+}
+
+// apiServer serves the local daemon API used by pkg/clioclient
+type apiServer struct {
+	reporter      report.Reporter
+	heartbeats    heartbeat.Recorder // Nil when heartbeats can't be recorded
+	redaction     export.Redaction   // Applied to sessions and exports served
+	minConfidence float64            // Commit-session links below this are left out of exports
+	logger        logging.Logger
+	status        func() clioclient.Status
+	server        *http.Server
+	listener      net.Listener
+	socketPath    string
+}
+
+// newAPIServer creates an API server; status reports the daemon's current state
+func newAPIServer(reporter report.Reporter, heartbeats heartbeat.Recorder, redaction export.Redaction, minConfidence float64, logger logging.Logger, status func() clioclient.Status) *apiServer {
+	s := &apiServer{
+		reporter:      reporter,
+		heartbeats:    heartbeats,
+		redaction:     redaction,
+		minConfidence: minConfidence,
+		logger:        logger.With("component", "api"),
+		status:        status,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+clioclient.PathStatus, s.handleStatus)
+	mux.HandleFunc("GET "+clioclient.PathSessions, s.handleSessions)
+	mux.HandleFunc("GET "+clioclient.PathSearch, s.handleSearch)
+	mux.HandleFunc("GET "+clioclient.PathExport, s.handleExport)
+	mux.HandleFunc("POST "+clioclient.PathHeartbeats, s.handleHeartbeats)
+	s.server = &http.Server{Handler: mux, ReadHeaderTimeout: apiReadHeaderTimeout}
+
+	return s
+}
+
+// Start listens on socketPath and serves requests in the background.
+// A leftover socket from a previous daemon is removed first.
+func (s *apiServer) Start(socketPath string) error {
+	if err := os.Remove(socketPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove stale API socket: %w", err)
+	}
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return fmt.Errorf("failed to listen on API socket: %w", err)
+	}
+	// Only the daemon's user may connect
+	if err := os.Chmod(socketPath, 0600); err != nil {
+		listener.Close()
+		return fmt.Errorf("failed to restrict API socket permissions: %w", err)
+	}
+	s.listener = listener
+	s.socketPath = socketPath
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error("API server stopped", "error", err)
+		}
+	}()
+
+	s.logger.Info("API server started", "socket", socketPath)
+	return nil
+}
+
+// Stop waits for in-flight requests and removes the socket
+func (s *apiServer) Stop() error {
+	if s.listener == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), apiShutdownTimeout)
+	defer cancel()
+
+	err := s.server.Shutdown(ctx)
+	if removeErr := os.Remove(s.socketPath); removeErr != nil && !os.IsNotExist(removeErr) {
+		s.logger.Warn("failed to remove API socket", "error", removeErr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to shut down API server: %w", err)
+	}
+	return nil
+}
+
+// handleStatus returns the daemon status
+func (s *apiServer) handleStatus(w http.ResponseWriter, r *http.Request) {
+	s.writeJSON(w, s.status())
+}
+
+// handleSessions returns sessions with their conversations and commits
+func (s *apiServer) handleSessions(w http.ResponseWriter, r *http.Request) {
+	opts, err := exportOptionsFromQuery(r)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	data, err := s.reporter.ExportData(opts)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	s.redaction.Apply(data)
+	s.writeJSON(w, data.Sessions)
+}
+
+// handleSearch returns messages matching the q parameter
+func (s *apiServer) handleSearch(w http.ResponseWriter, r *http.Request) {
+	filter, err := exportOptionsFromQuery(r)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	opts := report.SearchOptions{
+		Query:   r.URL.Query().Get("q"),
+		Project: filter.Project,
+		Since:   filter.Since,
+		Until:   filter.Until,
+	}
+	if opts.Query == "" {
+		s.writeError(w, http.StatusBadRequest, fmt.Errorf("q is required"))
+		return
+	}
+	if _, err := search.ParseQuery(opts.Query, time.Now()); err != nil {
+		s.writeError(w, http.StatusBadRequest, fmt.Errorf("invalid q: %w", err))
+		return
+	}
+	if limit := r.URL.Query().Get("limit"); limit != "" {
+		if opts.Limit, err = strconv.Atoi(limit); err != nil || opts.Limit < 0 {
+			s.writeError(w, http.StatusBadRequest, fmt.Errorf("invalid limit %q", limit))
+			return
+		}
+	}
+	if codeOnly := r.URL.Query().Get("code_only"); codeOnly != "" {
+		if opts.CodeOnly, err = strconv.ParseBool(codeOnly); err != nil {
+			s.writeError(w, http.StatusBadRequest, fmt.Errorf("invalid code_only %q", codeOnly))
+			return
+		}
+	}
+
+	hits, err := s.reporter.Search(opts)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	results := make([]clioclient.SearchResult, 0, len(hits))
+	for _, hit := range hits {
+		results = append(results, clioclient.SearchResult{
+			SessionID:        hit.SessionID,
+			Project:          hit.Project,
+			ConversationName: hit.ConversationName,
+			ComposerID:       hit.ComposerID,
+			Role:             hit.Role,
+			Snippet:          hit.Snippet,
+			CreatedAt:        hit.CreatedAt,
+		})
+	}
+	s.writeJSON(w, results)
+}
+
+// handleExport runs the exporter named by the format parameter
+func (s *apiServer) handleExport(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	exporter, ok := export.Lookup(format)
+	if !ok {
+		s.writeError(w, http.StatusBadRequest, fmt.Errorf("unknown export format %q", format))
+		return
+	}
+
+	opts, err := exportOptionsFromQuery(r)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	opts.MinConfidence = s.minConfidence
+
+	data, err := s.reporter.ExportData(opts)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	s.redaction.Apply(data)
+
+	// Errors after the first write can't change the status, so log them instead
+	if err := exporter.Export(w, data); err != nil {
+		s.logger.Error("export failed", "format", format, "error", err)
+	}
+}
+
+// handleHeartbeats records a WakaTime-style heartbeat or array of heartbeats
+func (s *apiServer) handleHeartbeats(w http.ResponseWriter, r *http.Request) {
+	if s.heartbeats == nil {
+		s.writeError(w, http.StatusServiceUnavailable, fmt.Errorf("heartbeat recording is unavailable"))
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxHeartbeatBodySize))
+	if err != nil {
+		s.writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("failed to read heartbeats: %w", err))
+		return
+	}
+	heartbeats, err := heartbeat.Decode(body)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	result, err := s.heartbeats.Record(heartbeats)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(clioclient.HeartbeatResult{
+		Received: result.Received,
+		Stored:   result.Stored,
+		Invalid:  result.Invalid,
+	}); err != nil {
+		s.logger.Debug("failed to write API response", "error", err)
+	}
+}
+
+// exportOptionsFromQuery parses the project, since, and until parameters
+func exportOptionsFromQuery(r *http.Request) (report.ExportOptions, error) {
+	query := r.URL.Query()
+	opts := report.ExportOptions{Project: query.Get("project")}
+
+	for name, target := range map[string]*time.Time{"since": &opts.Since, "until": &opts.Until} {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return opts, fmt.Errorf("invalid %s: %w", name, err)
+		}
+		*target = t
+	}
+	return opts, nil
+}
+
+// writeJSON writes v as a JSON response
+func (s *apiServer) writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		s.logger.Debug("failed to write API response", "error", err)
+	}
+}
+
+// writeError writes an error response
+func (s *apiServer) writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if encodeErr := json.NewEncoder(w).Encode(clioclient.ErrorResponse{Error: err.Error()}); encodeErr != nil {
+		s.logger.Debug("failed to write API error", "error", encodeErr)
+	}
+}
@@ -7,14 +7,38 @@ import (
 	"os"
 	"time"
 
+	"github.com/stwalsh4118/clio/internal/alerts"
+	"github.com/stwalsh4118/clio/internal/blobs"
 	"github.com/stwalsh4118/clio/internal/config"
 	"github.com/stwalsh4118/clio/internal/cursor"
 	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/errorlog"
+	"github.com/stwalsh4118/clio/internal/git"
+	"github.com/stwalsh4118/clio/internal/heartbeat"
+	"github.com/stwalsh4118/clio/internal/idle"
+	"github.com/stwalsh4118/clio/internal/jetbrains"
+	"github.com/stwalsh4118/clio/internal/lease"
 	"github.com/stwalsh4118/clio/internal/logging"
+	"github.com/stwalsh4118/clio/internal/notify"
+	"github.com/stwalsh4118/clio/internal/nudges"
+	"github.com/stwalsh4118/clio/internal/reminders"
+	"github.com/stwalsh4118/clio/internal/report"
+	"github.com/stwalsh4118/clio/internal/scrub"
+	"github.com/stwalsh4118/clio/internal/search"
+	"github.com/stwalsh4118/clio/internal/share"
+	"github.com/stwalsh4118/clio/internal/subscriptions"
+	"github.com/stwalsh4118/clio/internal/tagrules"
+	"github.com/stwalsh4118/clio/internal/upgrade"
+	"github.com/stwalsh4118/clio/internal/version"
+	"github.com/stwalsh4118/clio/internal/zed"
+	"github.com/stwalsh4118/clio/pkg/clioclient"
+	"github.com/stwalsh4118/clio/pkg/export"
 )
 
 const (
 	shutdownTimeout = 10 * time.Second
+	// blobCompactInterval is how often oversized values are moved to the blob store
+	blobCompactInterval = time.Hour
 )
 
 // Daemon represents the main daemon process structure.
@@ -26,6 +50,26 @@ type Daemon struct {
 	config         *config.Config
 	logger         logging.Logger
 	captureService cursor.CaptureService
+	zedCapture     zed.CaptureService
+	jetBrains      jetbrains.CaptureService
+	heartbeatLog   heartbeat.LogWatcher
+	gitPoller      git.PollerService
+	commitPipeline git.CommitPipeline
+	notifier       notify.Notifier
+	reminders      reminders.Store
+	alerts         alerts.Scanner
+	subscriptions  subscriptions.Store
+	searchIndex    search.Index    // Kept up to date so searches only read
+	nudges         nudges.Checker  // Nil unless nudges.enabled is set
+	tagger         tagrules.Tagger // Nil without tag rules
+	idleGaps       idle.Store
+	blobCompactor  blobs.Compactor
+	errors         errorlog.Collector
+	upgrader       upgrade.Upgrader
+	lease          lease.Lease
+	api            *apiServer
+	share          *shareServer // Nil unless share.listen is set
+	startedAt      time.Time
 }
 
 // NewDaemon creates a new daemon instance.
@@ -54,6 +98,30 @@ func NewDaemon() (*Daemon, error) {
 		return nil, fmt.Errorf("failed to initialize logger: %w", err)
 	}
 
+	// Claim the database before changing it, so a second daemon exits instead of writing alongside the first
+	databaseLease, err := acquireLease(database, logger)
+	if err != nil {
+		cancel()
+		database.Close()
+		return nil, err
+	}
+
+	// Oversized messages and diffs move to the blob store; upgrades and the API load them back
+	blobStore, err := blobs.NewStore(cfg.Storage.BlobsPath, logger)
+	if err != nil {
+		logger.Warn("failed to create blob store, oversized values stay in the database", "error", err)
+		blobStore = nil
+	}
+
+	// Bring stored data up to date for this version before anything reads or captures it
+	upgrader, err := upgradeData(database, blobStore, logger)
+	if err != nil {
+		cancel()
+		_ = databaseLease.Release()
+		database.Close()
+		return nil, err
+	}
+
 	// Create capture service (may fail if Cursor log path not configured - that's OK)
 	captureService, err := cursor.NewCaptureService(cfg, database)
 	if err != nil {
@@ -62,7 +130,139 @@ func NewDaemon() (*Daemon, error) {
 		captureService = nil
 	}
 
-	return &Daemon{
+	// Other editors share Cursor's sessions so work on a project in several editors lands together
+	var sessions cursor.SessionManager
+	if captureService != nil {
+		sessions = captureService.SessionManager()
+	}
+
+	// Create Zed capture when enabled
+	var zedCapture zed.CaptureService
+	if cfg.Zed.Enabled {
+		zedCapture, err = zed.NewCaptureService(cfg, database, sessions)
+		if err != nil {
+			logger.Warn("failed to create zed capture service", "error", err)
+			zedCapture = nil
+		}
+	}
+
+	// Create JetBrains AI Assistant capture when enabled
+	var jetBrainsCapture jetbrains.CaptureService
+	if cfg.JetBrains.Enabled {
+		jetBrainsCapture, err = jetbrains.NewCaptureService(cfg, database, sessions)
+		if err != nil {
+			logger.Warn("failed to create jetbrains capture service", "error", err)
+			jetBrainsCapture = nil
+		}
+	}
+
+	// Heartbeats extend the shared sessions; without Cursor capture they're stored unlinked
+	heartbeats, err := heartbeat.NewRecorder(database, sessions, cfg.WatchedDirectories, logger)
+	if err != nil {
+		logger.Warn("failed to create heartbeat recorder", "error", err)
+		heartbeats = nil
+	}
+	var heartbeatLog heartbeat.LogWatcher
+	if heartbeats != nil && cfg.Heartbeats.LogPath != "" {
+		interval := time.Duration(cfg.Heartbeats.PollIntervalSeconds) * time.Second
+		heartbeatLog, err = heartbeat.NewLogWatcher(cfg.Heartbeats.LogPath, interval, heartbeats, logger)
+		if err != nil {
+			logger.Warn("failed to create heartbeat log watcher", "error", err)
+			heartbeatLog = nil
+		}
+	}
+
+	// Create git commit capture (poller feeding the commit pipeline); the daemon runs without it on failure
+	gitPoller, commitPipeline, err := newCommitCapture(cfg, database, blobStore, logger)
+	if err != nil {
+		logger.Warn("failed to create git commit capture", "error", err)
+		gitPoller, commitPipeline = nil, nil
+	}
+
+	// Create webhook and exec hook notifiers; each is nil when not configured
+	webhooks, err := notify.NewWebhookNotifier(cfg, logger)
+	if err != nil {
+		logger.Warn("failed to create webhook notifier", "error", err)
+		webhooks = nil
+	}
+	hooks, err := notify.NewExecNotifier(cfg, logger)
+	if err != nil {
+		logger.Warn("failed to create hook notifier", "error", err)
+		hooks = nil
+	}
+	var notifier notify.Notifier
+	if webhooks != nil || hooks != nil {
+		notifier = notify.NewMultiNotifier(webhooks, hooks)
+	}
+
+	var blobCompactor blobs.Compactor
+	if blobStore != nil {
+		if blobCompactor, err = blobs.NewCompactor(database, blobStore, cfg.Storage.BlobThresholdBytes, logger); err != nil {
+			logger.Warn("failed to create blob compactor, oversized values stay in the database", "error", err)
+			blobCompactor = nil
+		}
+	}
+
+	// Recurring errors are counted and stored for 'clio status --errors' rather than logged each time
+	errorCollector, err := errorlog.NewCollector(database, logger)
+	if err != nil {
+		logger.Warn("failed to create error collector, errors will be logged individually", "error", err)
+		errorCollector = nil
+	}
+
+	// Due reminders are announced through the notifiers, so there's nothing to do without one
+	var reminderStore reminders.Store
+	if notifier != nil {
+		if reminderStore, err = reminders.NewStore(database, logger); err != nil {
+			logger.Warn("failed to create reminder store, due reminders won't be announced", "error", err)
+			reminderStore = nil
+		}
+	}
+
+	// Alerts are raised through the notifiers too
+	var alertScanner alerts.Scanner
+	if notifier != nil && len(cfg.Alerts) > 0 {
+		if alertScanner, err = newAlertScanner(cfg, database, blobStore, logger); err != nil {
+			logger.Warn("failed to create alert scanner, alerts won't be raised", "error", err)
+			alertScanner = nil
+		}
+	}
+
+	// Saved searches are checked even when none exist yet, since they are added while the daemon runs
+	var subscriptionStore subscriptions.Store
+	if notifier != nil {
+		if subscriptionStore, err = subscriptions.NewStore(database, blobStore, logger); err != nil {
+			logger.Warn("failed to create subscription store, search matches won't be announced", "error", err)
+			subscriptionStore = nil
+		}
+	}
+
+	// Habit nudges are announced through the notifiers too
+	var nudgeChecker nudges.Checker
+	if notifier != nil && cfg.Nudges.Enabled {
+		if nudgeChecker, err = nudges.NewChecker(database, cfg.Nudges, logger); err != nil {
+			logger.Warn("failed to create nudge checker, habit nudges won't be raised", "error", err)
+			nudgeChecker = nil
+		}
+	}
+
+	// Tag rules are applied as sessions end
+	var tagger tagrules.Tagger
+	if len(cfg.TagRules) > 0 {
+		if tagger, err = newTagger(cfg, database, blobStore, logger); err != nil {
+			logger.Warn("failed to create tagger, sessions won't be tagged by rule", "error", err)
+			tagger = nil
+		}
+	}
+
+	// Sleep is recorded so reports can leave it out of time worked
+	idleGaps, err := idle.NewStore(database, logger)
+	if err != nil {
+		logger.Warn("failed to create idle gap store, sleep won't be recorded", "error", err)
+		idleGaps = nil
+	}
+
+	d := &Daemon{
 		ctx:            ctx,
 		cancel:         cancel,
 		done:           make(chan struct{}),
@@ -70,7 +270,135 @@ func NewDaemon() (*Daemon, error) {
 		config:         cfg,
 		logger:         logger,
 		captureService: captureService,
-	}, nil
+		zedCapture:     zedCapture,
+		jetBrains:      jetBrainsCapture,
+		heartbeatLog:   heartbeatLog,
+		gitPoller:      gitPoller,
+		commitPipeline: commitPipeline,
+		notifier:       notifier,
+		reminders:      reminderStore,
+		alerts:         alertScanner,
+		subscriptions:  subscriptionStore,
+		nudges:         nudgeChecker,
+		tagger:         tagger,
+		idleGaps:       idleGaps,
+		blobCompactor:  blobCompactor,
+		errors:         errorCollector,
+		upgrader:       upgrader,
+		lease:          databaseLease,
+	}
+	d.registerEventHandlers()
+	d.registerTagRules()
+	d.registerErrorReporting()
+
+	// Search options changed since the last start apply before the API serves searches
+	if index, err := search.NewIndex(database, blobStore, logger); err != nil {
+		logger.Warn("failed to create search index, searches won't find new messages", "error", err)
+	} else {
+		if _, err := index.Configure(cfg.Search); err != nil {
+			logger.Warn("failed to apply search options", "error", err)
+		}
+		d.searchIndex = index
+	}
+
+	// Create the local API server used by pkg/clioclient
+	reporter, err := report.NewReporterWithBlobs(database, blobStore, logger)
+	if err != nil {
+		logger.Warn("failed to create reporter, API server disabled", "error", err)
+	} else {
+		redaction := export.Redaction{
+			StripThinking:   cfg.Redaction.StripThinking,
+			StripToolCalls:  cfg.Redaction.StripToolCalls,
+			StripPaths:      cfg.Redaction.StripPaths,
+			AllowExtensions: cfg.Redaction.AllowExtensions,
+		}
+		d.api = newAPIServer(reporter, heartbeats, redaction, cfg.Git.MinCorrelationConfidence, logger, d.apiStatus)
+
+		// Share links are served to the LAN only when a listen address is configured
+		if cfg.Share.Listen != "" {
+			if links, err := share.NewStore(database, logger); err != nil {
+				logger.Warn("failed to create share link store, share links won't be served", "error", err)
+			} else if scrubber, err := scrub.New(cfg.Scrub); err != nil {
+				// Pages are never served unscrubbed
+				logger.Warn("failed to create scrubber, share links won't be served", "error", err)
+			} else {
+				d.share = newShareServer(links, reporter, redaction, scrubber, logger)
+			}
+		}
+	}
+
+	return d, nil
+}
+
+// newCommitCapture creates the git poller and the pipeline that extracts, correlates, and stores its commits
+func newCommitCapture(cfg *config.Config, database *sql.DB, blobStore blobs.Store, logger logging.Logger) (git.PollerService, git.CommitPipeline, error) {
+	healthStore, err := git.NewHealthStore(database, logger)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create repository health store: %w", err)
+	}
+	checkpoints, err := git.NewCheckpointStore(database, logger)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create poller checkpoint store: %w", err)
+	}
+	poller, err := git.NewPollerService(cfg, logger, healthStore, checkpoints)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create git poller: %w", err)
+	}
+	extractor, err := git.NewCommitExtractor(logger)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create commit extractor: %w", err)
+	}
+	correlation, err := git.NewCorrelationService(logger, database, blobStore)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create correlation service: %w", err)
+	}
+	storage, err := git.NewCommitStorage(database, logger)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create commit storage: %w", err)
+	}
+	// Correlation reads sessions from the database; a session manager is only needed to enable it
+	sessionManager, err := cursor.NewSessionManager(cfg, database)
+	if err != nil {
+		logger.Warn("failed to create session manager, commits will be stored uncorrelated", "error", err)
+		sessionManager = nil
+	}
+	pipeline, err := git.NewCommitPipeline(cfg, logger, extractor, correlation, storage, sessionManager)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create commit pipeline: %w", err)
+	}
+	return poller, pipeline, nil
+}
+
+// startCommitCapture discovers repositories under the watched directories and starts polling them
+func (d *Daemon) startCommitCapture() error {
+	repos, err := git.NewDiscoveryService(d.logger).DiscoverRepositories(d.config.WatchedDirectories)
+	if err != nil {
+		return fmt.Errorf("failed to discover repositories: %w", err)
+	}
+	if len(repos) == 0 {
+		d.logger.Info("no git repositories found in watched directories, commit capture disabled")
+		d.gitPoller, d.commitPipeline = nil, nil
+		return nil
+	}
+
+	// Re-bind repositories moved or renamed since they were last seen, so polling
+	// resumes from their checkpoint instead of baselining at a new path
+	if fingerprints, err := git.NewFingerprintStore(d.db, d.logger); err != nil {
+		d.logger.Warn("failed to create repository fingerprint store, moved repositories won't be re-bound", "error", err)
+	} else if _, err := fingerprints.Rebind(repos); err != nil {
+		d.logger.Warn("failed to re-bind moved repositories", "error", err)
+	}
+
+	if err := d.commitPipeline.Start(d.ctx, d.gitPoller); err != nil {
+		return fmt.Errorf("failed to start commit pipeline: %w", err)
+	}
+	if err := d.gitPoller.Start(d.ctx, repos); err != nil {
+		_ = d.commitPipeline.Stop()
+		d.gitPoller, d.commitPipeline = nil, nil
+		return fmt.Errorf("failed to start git poller: %w", err)
+	}
+	d.logger.Info("commit capture started", "repository_count", len(repos))
+	return nil
 }
 
 // Run starts the daemon main loop.
@@ -86,18 +414,91 @@ func (d *Daemon) Run() error {
 		return fmt.Errorf("failed to write PID file: %w", err)
 	}
 
-	d.logger.Info("daemon started", "pid", pid)
+	d.startedAt = time.Now()
+	d.logger.Info("daemon started", "pid", pid, "version", version.Version)
+
+	// Recorded so the CLI can refuse to run alongside a daemon of another version
+	if err := d.upgrader.RecordDaemon(pid); err != nil {
+		d.logger.Warn("failed to record daemon version", "error", err)
+	}
 
 	// Start capture service if available
 	if d.captureService != nil {
 		if err := d.captureService.Start(); err != nil {
-			// Log error but don't crash daemon - allows daemon to run without cursor capture
-			d.logger.Error("failed to start capture service", "error", err)
+			// Report error but don't crash daemon - allows daemon to run without cursor capture
+			d.reportError(errorlog.SubsystemCapture, "cursor", fmt.Errorf("failed to start capture service: %w", err))
 		} else {
 			d.logger.Info("capture service started")
 		}
 	}
 
+	// Start other editors' capture after Cursor capture, whose session manager they may share
+	if d.zedCapture != nil {
+		if err := d.zedCapture.Start(); err != nil {
+			d.reportError(errorlog.SubsystemCapture, "zed", fmt.Errorf("failed to start zed capture service: %w", err))
+		}
+	}
+	if d.jetBrains != nil {
+		if err := d.jetBrains.Start(); err != nil {
+			d.reportError(errorlog.SubsystemCapture, "jetbrains", fmt.Errorf("failed to start jetbrains capture service: %w", err))
+		}
+	}
+	if d.heartbeatLog != nil {
+		if err := d.heartbeatLog.Start(); err != nil {
+			d.logger.Error("failed to start heartbeat log watcher", "error", err)
+		}
+	}
+
+	// Start commit capture if available
+	if d.gitPoller != nil && d.commitPipeline != nil {
+		if err := d.startCommitCapture(); err != nil {
+			// Report error but don't crash daemon - allows daemon to run without commit capture
+			d.reportError(errorlog.SubsystemGit, "", fmt.Errorf("failed to start commit capture: %w", err))
+		}
+	}
+
+	// Start the API server once capture state is settled, since status reads it
+	if d.api != nil {
+		if socketPath, err := GetSocketPath(); err != nil {
+			d.logger.Error("failed to get API socket path", "error", err)
+		} else if err := d.api.Start(socketPath); err != nil {
+			// Log error but don't crash daemon - capture works without the API
+			d.logger.Error("failed to start API server", "error", err)
+		}
+	}
+	if d.share != nil {
+		if err := d.share.Start(d.config.Share.Listen); err != nil {
+			d.logger.Error("failed to start share server", "error", err)
+		}
+	}
+
+	// Compact in the background, starting with values stored before the blob store existed
+	if d.blobCompactor != nil {
+		go d.runBlobCompaction()
+	}
+	if d.errors != nil {
+		go d.runErrorFlush()
+	}
+	if d.reminders != nil {
+		go d.runReminders()
+	}
+	if d.alerts != nil {
+		go d.runAlerts()
+	}
+	if d.searchIndex != nil {
+		go d.runSearchIndex()
+	}
+	if d.subscriptions != nil {
+		go d.runSubscriptions()
+	}
+	if d.nudges != nil {
+		go d.runNudges()
+	}
+	if d.idleGaps != nil {
+		go d.runSleepDetection()
+	}
+	go d.runLeaseHeartbeat()
+
 	// Main daemon loop (placeholder)
 	// This will be replaced with actual monitoring logic in future tasks
 	ticker := time.NewTicker(1 * time.Second)
@@ -120,6 +521,35 @@ func (d *Daemon) Run() error {
 func (d *Daemon) Shutdown() {
 	d.logger.Info("daemon shutdown initiated")
 
+	// Stop serving API requests before capture shuts down
+	if d.api != nil {
+		if err := d.api.Stop(); err != nil {
+			d.logger.Error("failed to stop API server", "error", err)
+		}
+	}
+	if d.share != nil {
+		if err := d.share.Stop(); err != nil {
+			d.logger.Error("failed to stop share server", "error", err)
+		}
+	}
+
+	// Stop other editors' capture before Cursor capture stops the session manager they may share
+	if d.zedCapture != nil {
+		if err := d.zedCapture.Stop(); err != nil {
+			d.logger.Error("failed to stop zed capture service", "error", err)
+		}
+	}
+	if d.jetBrains != nil {
+		if err := d.jetBrains.Stop(); err != nil {
+			d.logger.Error("failed to stop jetbrains capture service", "error", err)
+		}
+	}
+	if d.heartbeatLog != nil {
+		if err := d.heartbeatLog.Stop(); err != nil {
+			d.logger.Error("failed to stop heartbeat log watcher", "error", err)
+		}
+	}
+
 	// Stop capture service if available
 	if d.captureService != nil {
 		if err := d.captureService.Stop(); err != nil {
@@ -129,6 +559,41 @@ func (d *Daemon) Shutdown() {
 		}
 	}
 
+	// Stop the poller before the pipeline so its results channel closes first
+	if d.gitPoller != nil {
+		if err := d.gitPoller.Stop(); err != nil {
+			d.logger.Error("failed to stop git poller", "error", err)
+		}
+	}
+	if d.commitPipeline != nil {
+		if err := d.commitPipeline.Stop(); err != nil {
+			d.logger.Error("failed to stop commit pipeline", "error", err)
+		}
+	}
+
+	// Deliver queued events once their producers have stopped
+	if d.notifier != nil {
+		if err := d.notifier.Stop(); err != nil {
+			d.logger.Error("failed to stop notifier", "error", err)
+		}
+	}
+
+	// Store errors collected since the last flush once their producers have stopped
+	if d.errors != nil {
+		if err := d.errors.Flush(); err != nil {
+			d.logger.Error("failed to store collected errors", "error", err)
+		}
+	}
+
+	if err := d.upgrader.ClearDaemon(os.Getpid()); err != nil {
+		d.logger.Error("failed to clear daemon version", "error", err)
+	}
+
+	// Release last so another daemon can't start while this one still writes
+	if err := d.lease.Release(); err != nil {
+		d.logger.Error("failed to release database lease", "error", err)
+	}
+
 	// Cancel context to signal shutdown
 	d.cancel()
 
@@ -161,6 +626,37 @@ func (d *Daemon) Shutdown() {
 	}
 }
 
+// runBlobCompaction compacts now and every blobCompactInterval until shutdown
+func (d *Daemon) runBlobCompaction() {
+	ticker := time.NewTicker(blobCompactInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := d.blobCompactor.Compact(); err != nil {
+			d.reportError(errorlog.SubsystemBlobs, "", fmt.Errorf("failed to compact oversized values: %w", err))
+		}
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// apiStatus reports the daemon state served by the status endpoint
+func (d *Daemon) apiStatus() clioclient.Status {
+	return clioclient.Status{
+		PID:              os.Getpid(),
+		Version:          version.Version,
+		StartedAt:        d.startedAt,
+		CursorCapture:    d.captureService != nil,
+		ZedCapture:       d.zedCapture != nil,
+		JetBrainsCapture: d.jetBrains != nil,
+		CommitCapture:    d.gitPoller != nil && d.commitPipeline != nil,
+		HeartbeatLog:     d.heartbeatLog != nil,
+	}
+}
+
 // Wait waits for the daemon to finish.
 func (d *Daemon) Wait() {
 	<-d.done
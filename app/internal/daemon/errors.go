@@ -0,0 +1,45 @@
+package daemon
+
+import (
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/errorlog"
+	"github.com/stwalsh4118/clio/internal/git"
+)
+
+// registerErrorReporting sends commit capture errors to the error collector
+func (d *Daemon) registerErrorReporting() {
+	if d.errors == nil || d.commitPipeline == nil {
+		return
+	}
+	d.commitPipeline.OnError(func(repository git.Repository, err error) {
+		d.errors.Report(errorlog.SubsystemGit, repository.Path, err)
+	})
+}
+
+// reportError sends a recurring error to the error collector, or logs it when
+// there is no collector
+func (d *Daemon) reportError(subsystem, subject string, err error) {
+	if d.errors == nil {
+		d.logger.Error("subsystem error", "subsystem", subsystem, "subject", subject, "error", err)
+		return
+	}
+	d.errors.Report(subsystem, subject, err)
+}
+
+// runErrorFlush writes collected errors every errorlog.FlushInterval until shutdown
+func (d *Daemon) runErrorFlush() {
+	ticker := time.NewTicker(errorlog.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := d.errors.Flush(); err != nil {
+				d.logger.Warn("failed to store collected errors, will retry", "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package daemon
+
+import (
+	"github.com/stwalsh4118/clio/internal/cursor"
+	"github.com/stwalsh4118/clio/internal/git"
+	"github.com/stwalsh4118/clio/internal/notify"
+)
+
+// registerEventHandlers forwards session and commit events to the daemon's notifier
+func (d *Daemon) registerEventHandlers() {
+	if d.notifier == nil {
+		return
+	}
+
+	notifySessionEnded := func(session cursor.Session) {
+		endTime := session.LastActivity
+		if session.EndTime != nil {
+			endTime = *session.EndTime
+		}
+		d.notifier.Notify(notify.NewEvent(notify.EventSessionEnded, notify.SessionEnded{
+			SessionID:         session.ID,
+			Project:           session.Project,
+			StartTime:         session.StartTime,
+			EndTime:           endTime,
+			ConversationCount: len(session.Conversations),
+		}))
+	}
+	if d.captureService != nil {
+		d.captureService.OnSessionEnd(notifySessionEnded)
+	}
+	// Other editors only report sessions they own; shared sessions are reported above
+	if d.zedCapture != nil {
+		d.zedCapture.OnSessionEnd(notifySessionEnded)
+	}
+	if d.jetBrains != nil {
+		d.jetBrains.OnSessionEnd(notifySessionEnded)
+	}
+
+	if d.commitPipeline != nil {
+		d.commitPipeline.OnCommitStored(func(commit git.Commit, repository git.Repository, correlation *git.CommitSessionCorrelation) {
+			data := notify.CommitCaptured{
+				Hash:           commit.Hash,
+				RepositoryPath: repository.Path,
+				RepositoryName: repository.Name,
+				Branch:         commit.Branch,
+				Message:        commit.Message,
+				Author:         commit.Author,
+				Timestamp:      commit.Timestamp,
+			}
+			if correlation != nil && correlation.SessionID != "" {
+				data.SessionID = correlation.SessionID
+				data.CorrelationType = correlation.CorrelationType
+				data.Confidence = correlation.Confidence
+			}
+			d.notifier.Notify(notify.NewEvent(notify.EventCommitCaptured, data))
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package daemon
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/lease"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+// acquireLease claims the database for this daemon, refusing to start while
+// another daemon writes to it
+func acquireLease(database *sql.DB, logger logging.Logger) (lease.Lease, error) {
+	l, err := lease.NewLease(database, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create database lease: %w", err)
+	}
+	if err := l.Acquire(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// runLeaseHeartbeat refreshes the lease every lease.HeartbeatInterval until
+// shutdown. The daemon shuts down if another daemon has taken the lease over.
+func (d *Daemon) runLeaseHeartbeat() {
+	ticker := time.NewTicker(lease.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-ticker.C:
+			err := d.lease.Heartbeat()
+			if errors.Is(err, lease.ErrLost) {
+				d.logger.Error("stopping daemon to avoid writing alongside another daemon", "error", err)
+				d.Shutdown()
+				return
+			}
+			if err != nil {
+				// A transient failure such as a busy database; the lease outlives several missed heartbeats
+				d.logger.Warn("failed to refresh database lease, will retry", "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package daemon
+
+import (
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/notify"
+)
+
+const (
+	// nudgeCheckInterval is how often recent sessions are checked for habit nudges
+	nudgeCheckInterval = 5 * time.Minute
+)
+
+// runNudges announces habit nudges, every nudgeCheckInterval until shutdown
+func (d *Daemon) runNudges() {
+	ticker := time.NewTicker(nudgeCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		d.announceNudges()
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// announceNudges notifies each nudge raised since the previous check
+func (d *Daemon) announceNudges() {
+	raised, err := d.nudges.Check(time.Now())
+	// Nudges raised before a failure are still announced; they have been recorded
+	for _, nudge := range raised {
+		d.notifier.Notify(notify.NewEvent(notify.EventHabitNudge, notify.HabitNudge{
+			Kind:      nudge.Kind,
+			Message:   nudge.Message,
+			Project:   nudge.Project,
+			SessionID: nudge.SessionID,
+		}))
+	}
+	if err != nil {
+		d.logger.Warn("failed to check habit nudges, will retry", "error", err)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+
+	"github.com/stwalsh4118/clio/internal/config"
 )
 
 const (
@@ -13,7 +15,7 @@ const (
 )
 
 // GetPIDFilePath returns the absolute path to the PID file.
-// The PID file is stored at ~/.clio/clio.pid
+// The PID file is stored at ~/.clio/clio.pid, or ~/.clio/clio.<profile>.pid under a profile
 func GetPIDFilePath() (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
@@ -21,7 +23,7 @@ func GetPIDFilePath() (string, error) {
 	}
 
 	configDir := filepath.Join(homeDir, configDirName)
-	pidPath := filepath.Join(configDir, pidFileName)
+	pidPath := filepath.Join(configDir, profilePIDFileName())
 
 	// Expand and resolve the path
 	absPath, err := filepath.Abs(pidPath)
@@ -83,7 +85,7 @@ func WritePID(pid int) error {
 	}
 
 	// Use resolved path for PID file
-	pidPath = filepath.Join(resolvedDir, pidFileName)
+	pidPath = filepath.Join(resolvedDir, profilePIDFileName())
 
 	// Write PID to file with restrictive permissions (0600 - owner read/write only)
 	pidStr := strconv.Itoa(pid)
@@ -161,6 +163,16 @@ func PIDFileExists() (bool, error) {
 	return true, nil
 }
 
+// profilePIDFileName returns the PID file name for the active profile so each
+// profile can run its own daemon
+func profilePIDFileName() string {
+	profile := config.ActiveProfile()
+	if profile == "" {
+		return pidFileName
+	}
+	return strings.TrimSuffix(pidFileName, ".pid") + "." + profile + ".pid"
+}
+
 // configDirName matches the constant from config package
 const configDirName = ".clio"
 
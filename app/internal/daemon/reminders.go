@@ -0,0 +1,47 @@
+package daemon
+
+import (
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/notify"
+)
+
+const (
+	// reminderCheckInterval is how often due reminders are announced
+	reminderCheckInterval = time.Minute
+)
+
+// runReminders announces reminders as they come due, every reminderCheckInterval until shutdown
+func (d *Daemon) runReminders() {
+	ticker := time.NewTicker(reminderCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		d.announceReminders(time.Now())
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// announceReminders notifies each due reminder not announced yet
+func (d *Daemon) announceReminders(now time.Time) {
+	due, err := d.reminders.Announce(now)
+	if err != nil {
+		d.logger.Warn("failed to check reminders, will retry", "error", err)
+		return
+	}
+	for _, reminder := range due {
+		d.notifier.Notify(notify.NewEvent(notify.EventReminderDue, notify.ReminderDue{
+			ID:           reminder.ID,
+			Text:         reminder.Text,
+			DueAt:        reminder.DueAt,
+			CreatedAt:    reminder.CreatedAt,
+			SessionID:    reminder.SessionID,
+			Project:      reminder.Project,
+			SessionStart: reminder.SessionStart,
+		}))
+	}
+}
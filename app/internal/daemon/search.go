@@ -0,0 +1,30 @@
+package daemon
+
+import (
+	"time"
+)
+
+const (
+	// searchIndexInterval is how often newly captured messages are added to the search index
+	searchIndexInterval = 30 * time.Second
+)
+
+// runSearchIndex keeps the search index up to date, every searchIndexInterval
+// until shutdown, so searches only have to read it
+func (d *Daemon) runSearchIndex() {
+	ticker := time.NewTicker(searchIndexInterval)
+	defer ticker.Stop()
+
+	for {
+		if indexed, err := d.searchIndex.Sync(); err != nil {
+			d.logger.Warn("failed to update search index, will retry", "error", err)
+		} else if indexed > 0 {
+			d.logger.Debug("updated search index", "messages", indexed)
+		}
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/logging"
+	"github.com/stwalsh4118/clio/internal/report"
+	"github.com/stwalsh4118/clio/internal/scrub"
+	"github.com/stwalsh4118/clio/internal/share"
+	"github.com/stwalsh4118/clio/pkg/export"
+)
+
+// sharePage renders a shared session's Markdown as preformatted text, which
+// needs no scripts or outside resources
+var sharePage = template.Must(template.New("share").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>{{.Title}}</title>
+<style>body{margin:2em auto;max-width:60em;padding:0 1em;font-family:sans-serif}pre{white-space:pre-wrap;word-wrap:break-word}</style>
+</head>
+<body>
+<p><small>Read-only shared session. This link expires {{.Expires}}.</small></p>
+<pre>{{.Markdown}}</pre>
+</body>
+</html>
+`))
+
+// shareServer serves share links over TCP so teammates on the LAN can read one
+// session each. Unlike the API socket, it's reachable by other machines, so
+// it serves nothing but the sessions of active links.
+type shareServer struct {
+	links     share.Store
+	reporter  report.Reporter
+	redaction export.Redaction // Applied to sessions served, as on the API
+	scrubber  *scrub.Scrubber  // The last pass over pages served
+	logger    logging.Logger
+	server    *http.Server
+	listener  net.Listener
+}
+
+// newShareServer creates a share link server
+func newShareServer(links share.Store, reporter report.Reporter, redaction export.Redaction, scrubber *scrub.Scrubber, logger logging.Logger) *shareServer {
+	s := &shareServer{
+		links:     links,
+		reporter:  reporter,
+		redaction: redaction,
+		scrubber:  scrubber,
+		logger:    logger.With("component", "share"),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+share.PathPrefix+"{token}", s.handleShare)
+	s.server = &http.Server{Handler: mux, ReadHeaderTimeout: apiReadHeaderTimeout}
+
+	return s
+}
+
+// Start listens on addr and serves links in the background
+func (s *shareServer) Start(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for share links: %w", err)
+	}
+	s.listener = listener
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error("share server stopped", "error", err)
+		}
+	}()
+
+	s.logger.Info("share server started", "address", listener.Addr().String())
+	return nil
+}
+
+// Stop waits for in-flight requests
+func (s *shareServer) Stop() error {
+	if s.listener == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), apiShutdownTimeout)
+	defer cancel()
+
+	if err := s.server.Shutdown(ctx); err != nil {
+		return fmt.Errorf("failed to shut down share server: %w", err)
+	}
+	return nil
+}
+
+// handleShare renders the session of the link the token names
+func (s *shareServer) handleShare(w http.ResponseWriter, r *http.Request) {
+	// Pages hold transcripts, so keep them out of caches and referrers
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'")
+
+	link, err := s.links.Resolve(r.PathValue("token"), time.Now())
+	if errors.Is(err, share.ErrInvalidLink) {
+		http.Error(w, "This link is invalid, has expired, or was revoked.", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		s.logger.Error("failed to resolve share link", "error", err)
+		http.Error(w, "Failed to load the shared session.", http.StatusInternalServerError)
+		return
+	}
+
+	data, err := s.reporter.ExportData(report.ExportOptions{SessionID: link.SessionID})
+	if err != nil {
+		s.logger.Error("failed to load shared session", "link_id", link.ID, "error", err)
+		http.Error(w, "Failed to load the shared session.", http.StatusInternalServerError)
+		return
+	}
+	s.redaction.Apply(data)
+
+	var markdown bytes.Buffer
+	exporter, _ := export.Lookup("markdown")
+	if err := exporter.Export(&markdown, data); err != nil {
+		s.logger.Error("failed to render shared session", "link_id", link.ID, "error", err)
+		http.Error(w, "Failed to load the shared session.", http.StatusInternalServerError)
+		return
+	}
+
+	var page bytes.Buffer
+	if err := sharePage.Execute(&page, map[string]string{
+		"Title":    s.scrubber.Text(fmt.Sprintf("%s session, %s", link.Project, link.SessionStart.Local().Format("2006-01-02 15:04"))),
+		"Expires":  link.ExpiresAt.Local().Format("2006-01-02 15:04 MST"),
+		"Markdown": s.scrubber.Text(markdown.String()),
+	}); err != nil {
+		s.logger.Error("failed to render share page", "link_id", link.ID, "error", err)
+		http.Error(w, "Failed to load the shared session.", http.StatusInternalServerError)
+		return
+	}
+
+	s.logger.Info("served share link", "link_id", link.ID, "session_id", link.SessionID, "remote", r.RemoteAddr)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if _, err := w.Write(page.Bytes()); err != nil {
+		s.logger.Debug("failed to write share page", "error", err)
+	}
+}
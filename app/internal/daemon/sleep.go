@@ -0,0 +1,34 @@
+package daemon
+
+import (
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/idle"
+)
+
+const (
+	// sleepCheckInterval is how often the clock is checked for a jump; it bounds
+	// how much awake time a recorded sleep can include
+	sleepCheckInterval = 15 * time.Second
+)
+
+// runSleepDetection records each sleep of the machine, every sleepCheckInterval until shutdown
+func (d *Daemon) runSleepDetection() {
+	ticker := time.NewTicker(sleepCheckInterval)
+	defer ticker.Stop()
+
+	watch := idle.NewJumpWatch(idle.DefaultJumpThreshold)
+	watch.Observe(time.Now())
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if gap, ok := watch.Observe(time.Now()); ok {
+			if _, err := d.idleGaps.Record(gap); err != nil {
+				d.logger.Warn("failed to record sleep", "start", gap.Start, "end", gap.End, "error", err)
+			}
+		}
+	}
+}
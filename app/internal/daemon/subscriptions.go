@@ -0,0 +1,51 @@
+package daemon
+
+import (
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/notify"
+)
+
+const (
+	// subscriptionCheckInterval is how often newly captured messages are checked against the saved searches
+	subscriptionCheckInterval = 30 * time.Second
+)
+
+// runSubscriptions announces new matches of the saved searches, every
+// subscriptionCheckInterval until shutdown
+func (d *Daemon) runSubscriptions() {
+	ticker := time.NewTicker(subscriptionCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		d.announceSearchMatches()
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// announceSearchMatches notifies each subscription match found since the previous check
+func (d *Daemon) announceSearchMatches() {
+	matches, err := d.subscriptions.Check()
+	// Matches found before a failure are still announced; their subscriptions have moved past them
+	for _, match := range matches {
+		d.notifier.Notify(notify.NewEvent(notify.EventSearchMatched, notify.SearchMatched{
+			Subscription:     match.Subscription,
+			Query:            match.Query,
+			Excerpt:          match.Excerpt,
+			Timestamp:        match.Time,
+			MessageID:        match.MessageID,
+			SessionID:        match.SessionID,
+			Project:          match.Project,
+			ConversationName: match.ConversationName,
+			ComposerID:       match.ComposerID,
+			Role:             match.Role,
+		}))
+	}
+	if err != nil {
+		d.logger.Warn("failed to check search subscriptions, will retry", "error", err)
+	}
+}
@@ -0,0 +1,44 @@
+package daemon
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/blobs"
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/cursor"
+	"github.com/stwalsh4118/clio/internal/logging"
+	"github.com/stwalsh4118/clio/internal/tagrules"
+)
+
+// newTagger compiles the configured tag rules into a tagger
+func newTagger(cfg *config.Config, database *sql.DB, blobStore blobs.Store, logger logging.Logger) (tagrules.Tagger, error) {
+	rules, err := tagrules.Compile(cfg.TagRules)
+	if err != nil {
+		return nil, err
+	}
+	return tagrules.NewTagger(database, blobStore, rules, logger)
+}
+
+// registerTagRules applies the tag rules to each session when it ends
+func (d *Daemon) registerTagRules() {
+	if d.tagger == nil {
+		return
+	}
+
+	applyTagRules := func(session cursor.Session) {
+		if _, err := d.tagger.Apply(session.ID, time.Now()); err != nil {
+			d.logger.Warn("failed to apply tag rules", "session_id", session.ID, "error", err)
+		}
+	}
+	if d.captureService != nil {
+		d.captureService.OnSessionEnd(applyTagRules)
+	}
+	// Other editors only report sessions they own; shared sessions are handled above
+	if d.zedCapture != nil {
+		d.zedCapture.OnSessionEnd(applyTagRules)
+	}
+	if d.jetBrains != nil {
+		d.jetBrains.OnSessionEnd(applyTagRules)
+	}
+}
@@ -0,0 +1,38 @@
+package daemon
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/blobs"
+	"github.com/stwalsh4118/clio/internal/logging"
+	"github.com/stwalsh4118/clio/internal/upgrade"
+	"github.com/stwalsh4118/clio/internal/version"
+)
+
+// upgradeProgressInterval is the minimum time between progress log lines during a data upgrade
+const upgradeProgressInterval = 5 * time.Second
+
+// upgradeData brings the stored data up to date for this version, logging the
+// progress of long backfills. Data upgraded by a newer clio is refused.
+func upgradeData(database *sql.DB, blobStore blobs.Store, logger logging.Logger) (upgrade.Upgrader, error) {
+	upgrader, err := upgrade.NewUpgrader(database, blobStore, version.Version, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create upgrader: %w", err)
+	}
+
+	var lastLogged time.Time
+	progress := func(backfill string, done, total int) {
+		if done < total && time.Since(lastLogged) < upgradeProgressInterval {
+			return
+		}
+		lastLogged = time.Now()
+		logger.Info("upgrading data", "backfill", backfill, "conversations_done", done, "conversations_total", total)
+	}
+
+	if _, err := upgrader.Run(progress); err != nil {
+		return nil, fmt.Errorf("failed to upgrade data: %w", err)
+	}
+	return upgrader, nil
+}
@@ -0,0 +1,273 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// attachSchemaPrefix names attached databases in SQL: also0, also1, ...
+const attachSchemaPrefix = "also"
+
+// AttachReadOnly attaches other clio databases, such as a backup or the
+// database of a previous machine, read-only to database for combined
+// reporting. Each of database's tables is shadowed by a temporary view of its
+// rows followed by the attached databases' rows, so unqualified queries cover
+// all of them without copying data. Rows whose primary key is already present
+// in an earlier database are left out, so an attached copy doesn't count
+// sessions twice; tables keyed by an integer ID are combined whole. Columns an
+// older database lacks read as NULL. Full-text search tables aren't combined.
+//
+// The views and attachments belong to one connection, so database is limited
+// to a single open connection.
+func AttachReadOnly(database *sql.DB, paths []string) error {
+	if database == nil {
+		return fmt.Errorf("database cannot be nil")
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+	database.SetMaxOpenConns(1)
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		return fmt.Errorf("failed to load migrations: %w", err)
+	}
+	latest := latestMigration(migrations)
+
+	schemas := make([]string, 0, len(paths))
+	for i, path := range paths {
+		schema := fmt.Sprintf("%s%d", attachSchemaPrefix, i)
+		if err := attachDatabase(database, schema, path, latest); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		schemas = append(schemas, schema)
+	}
+
+	// Temporary views don't change the database files, but query_only refuses them too
+	if _, err := database.Exec("PRAGMA query_only = 0"); err != nil {
+		return fmt.Errorf("failed to allow temporary views: %w", err)
+	}
+	defer database.Exec("PRAGMA query_only = 1")
+
+	tables, err := combinableTables(database)
+	if err != nil {
+		return err
+	}
+	for _, table := range tables {
+		view, err := combinedView(database, table, schemas)
+		if err != nil {
+			return err
+		}
+		if view == "" {
+			continue
+		}
+		if _, err := database.Exec(view); err != nil {
+			return fmt.Errorf("failed to combine table %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// attachDatabase attaches the clio database at path read-only as schema,
+// refusing databases written by a newer clio
+func attachDatabase(database *sql.DB, schema, path string, latest int) error {
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve database path: %w", err)
+	}
+	dsn := (&url.URL{Scheme: "file", Path: filepath.ToSlash(absPath), RawQuery: "mode=ro"}).String()
+	if _, err := database.Exec("ATTACH DATABASE ? AS "+schema, dsn); err != nil {
+		return fmt.Errorf("failed to attach database: %w", err)
+	}
+
+	var version sql.NullInt64
+	err = database.QueryRow("SELECT MAX(version) FROM " + schema + ".schema_migrations").Scan(&version)
+	if err == nil && int(version.Int64) > latest {
+		err = fmt.Errorf("database schema version %d is newer than this clio supports (%d); upgrade clio", version.Int64, latest)
+	} else if err != nil {
+		err = fmt.Errorf("not a clio database: %w", err)
+	}
+	if err != nil {
+		_, _ = database.Exec("DETACH DATABASE " + schema)
+		return err
+	}
+	return nil
+}
+
+// combinableTables returns the main database's tables apart from migration
+// bookkeeping and full-text search tables with their shadow tables
+func combinableTables(database *sql.DB) ([]string, error) {
+	rows, err := database.Query(`
+		SELECT name, COALESCE(sql, '') FROM main.sqlite_master
+		WHERE type = 'table' AND name NOT LIKE 'sqlite_%' AND name != 'schema_migrations'
+		ORDER BY name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+	defer rows.Close()
+
+	var names []string
+	var virtual []string
+	for rows.Next() {
+		var name, definition string
+		if err := rows.Scan(&name, &definition); err != nil {
+			return nil, fmt.Errorf("failed to scan table: %w", err)
+		}
+		if strings.HasPrefix(strings.ToUpper(definition), "CREATE VIRTUAL TABLE") {
+			virtual = append(virtual, name)
+			continue
+		}
+		names = append(names, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tables: %w", err)
+	}
+
+	tables := names[:0]
+	for _, name := range names {
+		shadow := false
+		for _, v := range virtual {
+			shadow = shadow || strings.HasPrefix(name, v+"_")
+		}
+		if !shadow {
+			tables = append(tables, name)
+		}
+	}
+	return tables, nil
+}
+
+// tableColumn is a column of a table as pragma table_info reports it
+type tableColumn struct {
+	name    string
+	typ     string
+	primary bool
+}
+
+// tableColumns returns a table's columns in the schema; none when it doesn't exist there
+func tableColumns(database *sql.DB, schema, table string) ([]tableColumn, error) {
+	rows, err := database.Query("SELECT name, type, pk FROM pragma_table_info(?, ?)", table, schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns of %s.%s: %w", schema, table, err)
+	}
+	defer rows.Close()
+
+	var columns []tableColumn
+	for rows.Next() {
+		var column tableColumn
+		var pk int
+		if err := rows.Scan(&column.name, &column.typ, &pk); err != nil {
+			return nil, fmt.Errorf("failed to scan column: %w", err)
+		}
+		column.primary = pk > 0
+		columns = append(columns, column)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating columns: %w", err)
+	}
+	return columns, nil
+}
+
+// combinedView returns the statement creating the temporary view that shadows
+// table, or "" when no attached database has the table
+func combinedView(database *sql.DB, table string, schemas []string) (string, error) {
+	columns, err := tableColumns(database, "main", table)
+	if err != nil {
+		return "", err
+	}
+
+	// An integer primary key is a local row ID, so equal keys don't mean equal rows
+	var keys []string
+	for _, column := range columns {
+		if column.primary {
+			keys = append(keys, column.name)
+		}
+	}
+	if len(keys) == 1 && strings.EqualFold(columnType(columns, keys[0]), "INTEGER") {
+		keys = nil
+	}
+
+	selects := []string{fmt.Sprintf("SELECT %s FROM main.%s", columnList(columns, nil, ""), quoteIdent(table))}
+	var earlier []string // Schemas whose rows take precedence
+	earlier = append(earlier, "main")
+	for _, schema := range schemas {
+		other, err := tableColumns(database, schema, table)
+		if err != nil {
+			return "", err
+		}
+		if len(other) == 0 {
+			continue
+		}
+		present := make(map[string]bool, len(other))
+		for _, column := range other {
+			present[column.name] = true
+		}
+
+		query := fmt.Sprintf("SELECT %s FROM %s.%s AS o", columnList(columns, present, "o."), schema, quoteIdent(table))
+		if dedupe := keysPresent(keys, present); dedupe {
+			var conditions []string
+			for _, prior := range earlier {
+				var matches []string
+				for _, key := range keys {
+					matches = append(matches, fmt.Sprintf("p.%s = o.%s", quoteIdent(key), quoteIdent(key)))
+				}
+				conditions = append(conditions, fmt.Sprintf("NOT EXISTS (SELECT 1 FROM %s.%s AS p WHERE %s)",
+					prior, quoteIdent(table), strings.Join(matches, " AND ")))
+			}
+			query += " WHERE " + strings.Join(conditions, " AND ")
+		}
+		selects = append(selects, query)
+		earlier = append(earlier, schema)
+	}
+	if len(selects) == 1 {
+		return "", nil
+	}
+
+	return fmt.Sprintf("CREATE TEMP VIEW %s AS %s", quoteIdent(table), strings.Join(selects, " UNION ALL ")), nil
+}
+
+// columnList lists columns for a SELECT, reading those not present as NULL;
+// a nil present map means all are
+func columnList(columns []tableColumn, present map[string]bool, prefix string) string {
+	parts := make([]string, len(columns))
+	for i, column := range columns {
+		if present != nil && !present[column.name] {
+			parts[i] = "NULL AS " + quoteIdent(column.name)
+			continue
+		}
+		parts[i] = prefix + quoteIdent(column.name)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// columnType returns the declared type of the named column
+func columnType(columns []tableColumn, name string) string {
+	for _, column := range columns {
+		if column.name == name {
+			return column.typ
+		}
+	}
+	return ""
+}
+
+// keysPresent reports whether there are key columns and all are present
+func keysPresent(keys []string, present map[string]bool) bool {
+	for _, key := range keys {
+		if !present[key] {
+			return false
+		}
+	}
+	return len(keys) > 0
+}
+
+// quoteIdent quotes an SQL identifier
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
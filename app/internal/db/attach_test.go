@@ -0,0 +1,81 @@
+package db
+
+import (
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/config"
+)
+
+// createAttachTestDB creates a migrated database holding the given sessions
+func createAttachTestDB(t *testing.T, path string, sessions ...string) {
+	t.Helper()
+	database, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer database.Close()
+	if err := RunMigrations(database); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	now := time.Now()
+	for _, id := range sessions {
+		if _, err := database.Exec(`
+			INSERT INTO sessions (id, project, start_time, last_activity, created_at, updated_at)
+			VALUES (?, 'clio', ?, ?, ?, ?)
+		`, id, now, now, now, now); err != nil {
+			t.Fatalf("failed to create session: %v", err)
+		}
+	}
+}
+
+func TestAttachReadOnly(t *testing.T) {
+	dir := t.TempDir()
+	mainPath := filepath.Join(dir, "clio.db")
+	oldPath := filepath.Join(dir, "old.db")
+	createAttachTestDB(t, mainPath, "new-1", "shared")
+	createAttachTestDB(t, oldPath, "old-1", "old-2", "shared")
+
+	database, err := ConnectReadOnly(&config.Config{Storage: config.StorageConfig{DatabasePath: mainPath}})
+	if err != nil {
+		t.Fatalf("ConnectReadOnly() error = %v", err)
+	}
+	defer database.Close()
+
+	if err := AttachReadOnly(database, []string{oldPath}); err != nil {
+		t.Fatalf("AttachReadOnly() error = %v", err)
+	}
+
+	var count int
+	if err := database.QueryRow("SELECT COUNT(*) FROM sessions").Scan(&count); err != nil || count != 4 {
+		t.Errorf("combined sessions = %d, %v; want 4 with the shared one counted once", count, err)
+	}
+	if _, err := database.Exec("DELETE FROM main.sessions"); err == nil {
+		t.Error("the main database should stay read-only")
+	}
+
+	// Databases written by a newer clio are refused
+	newer, err := sql.Open("sqlite", oldPath)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if _, err := newer.Exec("INSERT INTO schema_migrations (version, dirty) VALUES (99999, 0)"); err != nil {
+		t.Fatalf("failed to bump schema version: %v", err)
+	}
+	newer.Close()
+
+	fresh, err := ConnectReadOnly(&config.Config{Storage: config.StorageConfig{DatabasePath: mainPath}})
+	if err != nil {
+		t.Fatalf("ConnectReadOnly() error = %v", err)
+	}
+	defer fresh.Close()
+	if err := AttachReadOnly(fresh, []string{oldPath}); err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Errorf("AttachReadOnly() error = %v, want a newer schema error", err)
+	}
+	if err := AttachReadOnly(fresh, []string{filepath.Join(dir, "missing.db")}); err == nil {
+		t.Error("AttachReadOnly() with a missing database should fail")
+	}
+}
@@ -2,16 +2,41 @@ package db
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/stwalsh4118/clio/internal/config"
-	_ "modernc.org/sqlite" // SQLite driver
+	"modernc.org/sqlite" // SQLite driver
+	sqlite3 "modernc.org/sqlite/lib"
 )
 
+// ReadBusyTimeout is how long a read-only connection waits for a lock held by a
+// writer before failing with SQLITE_BUSY
+const ReadBusyTimeout = 5 * time.Second
+
 // Open opens a database connection and runs migrations
 func Open(cfg *config.Config) (*sql.DB, error) {
+	db, err := Connect(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Run migrations
+	if err := RunMigrations(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	return db, nil
+}
+
+// Connect opens a database connection without running migrations, for callers
+// that check the database before changing it
+func Connect(cfg *config.Config) (*sql.DB, error) {
 	if cfg == nil {
 		return nil, fmt.Errorf("config cannot be nil")
 	}
@@ -28,8 +53,8 @@ func Open(cfg *config.Config) (*sql.DB, error) {
 		return nil, fmt.Errorf("failed to create database directory: %w", err)
 	}
 
-	// Open database connection
-	db, err := sql.Open("sqlite", dbPath+"?_journal_mode=WAL")
+	// Open database connection. WAL lets readers run alongside the daemon's writes.
+	db, err := sql.Open("sqlite", dbPath+"?_pragma=journal_mode(WAL)")
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -40,11 +65,58 @@ func Open(cfg *config.Config) (*sql.DB, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	// Run migrations
-	if err := RunMigrations(db); err != nil {
-		db.Close()
-		return nil, fmt.Errorf("failed to run migrations: %w", err)
+	return db, nil
+}
+
+// ConnectReadOnly opens a read-only connection to an existing database, for
+// commands that only read while the daemon writes. Reads wait up to
+// ReadBusyTimeout for a writer's lock, and the connection refuses writes, so a
+// heavy read can neither fail on a momentary lock nor change the data. It
+// returns an error wrapping os.ErrNotExist when the database hasn't been created.
+func ConnectReadOnly(cfg *config.Config) (*sql.DB, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+	dbPath := cfg.Storage.DatabasePath
+	if dbPath == "" {
+		return nil, fmt.Errorf("database path not configured")
+	}
+	if _, err := os.Stat(dbPath); err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
+	absPath, err := filepath.Abs(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve database path: %w", err)
+	}
+	query := url.Values{}
+	query.Set("mode", "ro")
+	query.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", ReadBusyTimeout.Milliseconds()))
+	query.Add("_pragma", "query_only(1)")
+	dsn := (&url.URL{Scheme: "file", Path: filepath.ToSlash(absPath), RawQuery: query.Encode()}).String()
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
 	return db, nil
 }
+
+// IsLocked reports whether err was caused by another connection holding a
+// lock on the database (SQLITE_BUSY or SQLITE_LOCKED)
+func IsLocked(err error) bool {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	// Extended result codes keep the primary code in the low byte
+	switch sqliteErr.Code() & 0xff {
+	case sqlite3.SQLITE_BUSY, sqlite3.SQLITE_LOCKED:
+		return true
+	}
+	return false
+}
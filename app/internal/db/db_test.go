@@ -0,0 +1,113 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stwalsh4118/clio/internal/config"
+)
+
+func TestIsLocked(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "locked.db")
+
+	holder, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer holder.Close()
+	if _, err := holder.Exec("CREATE TABLE items (id INTEGER)"); err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+
+	tx, err := holder.Begin()
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec("INSERT INTO items (id) VALUES (1)"); err != nil {
+		t.Fatalf("failed to write in transaction: %v", err)
+	}
+
+	// A second connection with no busy timeout fails immediately on the held write lock
+	other, err := sql.Open("sqlite", dbPath+"?_pragma=busy_timeout(0)")
+	if err != nil {
+		t.Fatalf("failed to open second connection: %v", err)
+	}
+	defer other.Close()
+
+	_, err = other.Exec("INSERT INTO items (id) VALUES (2)")
+	if err == nil {
+		t.Fatal("write through second connection should fail while the lock is held")
+	}
+	if !IsLocked(err) || !IsLocked(fmt.Errorf("wrapped: %w", err)) {
+		t.Errorf("IsLocked(%v) = false, want true", err)
+	}
+
+	if IsLocked(errors.New("database is locked")) {
+		t.Error("IsLocked() should only match SQLite errors")
+	}
+	if IsLocked(nil) {
+		t.Error("IsLocked(nil) should be false")
+	}
+}
+
+func TestConnectReadOnly(t *testing.T) {
+	cfg := &config.Config{
+		Storage: config.StorageConfig{
+			DatabasePath: filepath.Join(t.TempDir(), "read only.db"),
+		},
+	}
+
+	if _, err := ConnectReadOnly(cfg); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("ConnectReadOnly() before the database exists error = %v, want os.ErrNotExist", err)
+	}
+
+	writer, err := Open(cfg)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer writer.Close()
+
+	var journalMode string
+	if err := writer.QueryRow("PRAGMA journal_mode").Scan(&journalMode); err != nil {
+		t.Fatalf("failed to read journal mode: %v", err)
+	}
+	if journalMode != "wal" {
+		t.Errorf("journal_mode = %q, want wal", journalMode)
+	}
+
+	reader, err := ConnectReadOnly(cfg)
+	if err != nil {
+		t.Fatalf("ConnectReadOnly() error = %v", err)
+	}
+	defer reader.Close()
+
+	// Reads see the writer's data while the writer holds an open write transaction
+	if _, err := writer.Exec("INSERT INTO sessions (id, project, start_time, last_activity, created_at, updated_at) VALUES ('s1', 'p', 0, 0, 0, 0)"); err != nil {
+		t.Fatalf("failed to insert session: %v", err)
+	}
+	tx, err := writer.Begin()
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec("INSERT INTO sessions (id, project, start_time, last_activity, created_at, updated_at) VALUES ('s2', 'p', 0, 0, 0, 0)"); err != nil {
+		t.Fatalf("failed to write in transaction: %v", err)
+	}
+
+	var count int
+	if err := reader.QueryRow("SELECT COUNT(*) FROM sessions").Scan(&count); err != nil {
+		t.Fatalf("read during write transaction error = %v", err)
+	}
+	if count != 1 {
+		t.Errorf("read during write transaction saw %d sessions, want 1", count)
+	}
+
+	if _, err := reader.Exec("DELETE FROM sessions"); err == nil {
+		t.Error("write through read-only connection should fail")
+	}
+}
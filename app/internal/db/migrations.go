@@ -3,17 +3,23 @@ package db
 import (
 	"database/sql"
 	"embed"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
 	"regexp"
 	"sort"
 	"strconv"
+	"strings"
 )
 
 //go:embed migrations/*.sql
 var migrationsFS embed.FS
 
+// ErrNewerSchema is returned when the database was migrated by a newer clio than
+// this one, whose schema it doesn't know
+var ErrNewerSchema = errors.New("database schema is newer than this clio supports")
+
 // migrationFile represents a migration file
 type migrationFile struct {
 	version int
@@ -42,6 +48,11 @@ func RunMigrations(db *sql.DB) error {
 		return fmt.Errorf("failed to load migrations: %w", err)
 	}
 
+	// A newer binary's schema may hold data this one would corrupt
+	if latest := latestMigration(migrations); currentVersion > latest {
+		return fmt.Errorf("%w (database version %d, latest known %d); upgrade clio", ErrNewerSchema, currentVersion, latest)
+	}
+
 	// Run pending migrations
 	for _, migration := range migrations {
 		if migration.version <= currentVersion {
@@ -75,6 +86,37 @@ func RunMigrations(db *sql.DB) error {
 	return nil
 }
 
+// MigrationsPending reports whether the database lacks migrations this binary
+// knows, without applying them. It returns ErrNewerSchema like RunMigrations.
+func MigrationsPending(db *sql.DB) (bool, error) {
+	// Read the version without creating schema_migrations, so this works on read-only connections
+	var currentVersion int
+	err := db.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&currentVersion)
+	if err != nil && !strings.Contains(err.Error(), "no such table") {
+		return false, fmt.Errorf("failed to get migration version: %w", err)
+	}
+	migrations, err := loadMigrations()
+	if err != nil {
+		return false, fmt.Errorf("failed to load migrations: %w", err)
+	}
+	latest := latestMigration(migrations)
+	if currentVersion > latest {
+		return false, fmt.Errorf("%w (database version %d, latest known %d); upgrade clio", ErrNewerSchema, currentVersion, latest)
+	}
+	return currentVersion < latest, nil
+}
+
+// latestMigration returns the highest migration version, 0 when there are none
+func latestMigration(migrations []migrationFile) int {
+	latest := 0
+	for _, migration := range migrations {
+		if migration.version > latest {
+			latest = migration.version
+		}
+	}
+	return latest
+}
+
 // loadMigrations loads all migration files from embed.FS
 // Loads both .up.sql and .down.sql files
 func loadMigrations() ([]migrationFile, error) {
@@ -292,3 +334,15 @@ func setMigrationVersion(tx *sql.Tx, version int, dirty bool) error {
 	`, version, dirty)
 	return err
 }
+
+// SchemaVersion returns the latest migration applied to the database, or 0 when none has been
+func SchemaVersion(db *sql.DB) (int, error) {
+	version, dirty, err := getMigrationVersion(db)
+	if err != nil {
+		return 0, err
+	}
+	if dirty {
+		return 0, fmt.Errorf("database is in a dirty migration state (version %d)", version)
+	}
+	return version, nil
+}
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_quarantined_payloads_composer_id;
+DROP INDEX IF EXISTS idx_quarantined_payloads_source;
+DROP TABLE IF EXISTS quarantined_payloads;
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS quarantined_payloads (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    source TEXT NOT NULL,
+    composer_id TEXT NOT NULL,
+    bubble_id TEXT NOT NULL DEFAULT '',
+    reason TEXT NOT NULL,
+    payload BLOB,
+    first_seen_at TIMESTAMP NOT NULL,
+    last_seen_at TIMESTAMP NOT NULL,
+    UNIQUE (source, composer_id, bubble_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_quarantined_payloads_source ON quarantined_payloads(source);
+CREATE INDEX IF NOT EXISTS idx_quarantined_payloads_composer_id ON quarantined_payloads(composer_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_raw_payloads_parser_version;
+DROP INDEX IF EXISTS idx_raw_payloads_composer_id;
+DROP TABLE IF EXISTS raw_payloads;
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS raw_payloads (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    composer_id TEXT NOT NULL,
+    bubble_id TEXT NOT NULL DEFAULT '',
+    parser_version INTEGER NOT NULL,
+    compression TEXT NOT NULL,
+    payload BLOB NOT NULL,
+    payload_hash TEXT NOT NULL,
+    captured_at TIMESTAMP NOT NULL,
+    UNIQUE (composer_id, bubble_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_raw_payloads_composer_id ON raw_payloads(composer_id);
+CREATE INDEX IF NOT EXISTS idx_raw_payloads_parser_version ON raw_payloads(parser_version);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_messages_parser_version;
+ALTER TABLE messages DROP COLUMN parser_version;
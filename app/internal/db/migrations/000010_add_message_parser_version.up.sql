@@ -0,0 +1,5 @@
+-- Track which parser version extracted each message so re-parsing can upgrade
+-- historical rows. Existing rows predate versioning and are recorded as 0.
+ALTER TABLE messages ADD COLUMN parser_version INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_messages_parser_version ON messages(parser_version);
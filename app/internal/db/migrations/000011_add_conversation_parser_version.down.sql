@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS backfill_runs;
+ALTER TABLE conversations DROP COLUMN parser_version;
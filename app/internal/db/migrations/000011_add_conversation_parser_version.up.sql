@@ -0,0 +1,9 @@
+-- Lowest parser version among a conversation's messages (0 = captured before versioning)
+ALTER TABLE conversations ADD COLUMN parser_version INTEGER NOT NULL DEFAULT 0;
+
+-- Derived-field backfills that have already been applied (run once, like migrations)
+CREATE TABLE IF NOT EXISTS backfill_runs (
+    name TEXT PRIMARY KEY,
+    messages_updated INTEGER NOT NULL DEFAULT 0,
+    completed_at TIMESTAMP NOT NULL
+);
@@ -0,0 +1 @@
+ALTER TABLE commits DROP COLUMN correlation_confidence;
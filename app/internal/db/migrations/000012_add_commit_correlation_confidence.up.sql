@@ -0,0 +1,3 @@
+-- Confidence score in [0, 1] for the commit-session correlation. Commits stored
+-- before scoring existed have NULL (unscored).
+ALTER TABLE commits ADD COLUMN correlation_confidence REAL;
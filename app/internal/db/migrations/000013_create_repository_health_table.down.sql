@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_repository_health_status;
+DROP TABLE IF EXISTS repository_health;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS repository_health (
+    repository_path TEXT PRIMARY KEY,
+    repository_name TEXT NOT NULL,
+    status TEXT NOT NULL,
+    consecutive_failures INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    unhealthy_since TIMESTAMP,
+    next_retry_at TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_repository_health_status ON repository_health(status);
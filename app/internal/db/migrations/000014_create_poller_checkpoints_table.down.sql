@@ -0,0 +1 @@
+DROP TABLE IF EXISTS poller_checkpoints;
@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS poller_checkpoints (
+    repository_path TEXT PRIMARY KEY,
+    last_seen_hash TEXT NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_conversations_source;
+ALTER TABLE conversations DROP COLUMN source;
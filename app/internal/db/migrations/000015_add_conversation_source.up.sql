@@ -0,0 +1,5 @@
+-- Where a conversation came from: "cursor" for captured conversations, or the
+-- import format (e.g. "chatgpt", "claude") for imported history
+ALTER TABLE conversations ADD COLUMN source TEXT NOT NULL DEFAULT 'cursor';
+
+CREATE INDEX IF NOT EXISTS idx_conversations_source ON conversations(source);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_test_cases_run_id;
+DROP TABLE IF EXISTS test_cases;
+DROP INDEX IF EXISTS idx_test_runs_run_time;
+DROP INDEX IF EXISTS idx_test_runs_session_id;
+DROP TABLE IF EXISTS test_runs;
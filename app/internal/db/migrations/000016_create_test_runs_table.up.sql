@@ -0,0 +1,33 @@
+-- Test run outcomes ingested from JUnit XML or go test -json output.
+-- id is the SHA-256 of the results file, so re-ingesting a file is a no-op.
+CREATE TABLE IF NOT EXISTS test_runs (
+    id TEXT PRIMARY KEY,
+    session_id TEXT,
+    commit_hash TEXT,
+    format TEXT NOT NULL,
+    source_path TEXT NOT NULL,
+    run_time TIMESTAMP NOT NULL,
+    duration_ms INTEGER NOT NULL DEFAULT 0,
+    total INTEGER NOT NULL DEFAULT 0,
+    passed INTEGER NOT NULL DEFAULT 0,
+    failed INTEGER NOT NULL DEFAULT 0,
+    skipped INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (session_id) REFERENCES sessions(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_test_runs_session_id ON test_runs(session_id);
+CREATE INDEX IF NOT EXISTS idx_test_runs_run_time ON test_runs(run_time);
+
+CREATE TABLE IF NOT EXISTS test_cases (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    run_id TEXT NOT NULL,
+    suite TEXT NOT NULL DEFAULT '',
+    name TEXT NOT NULL,
+    status TEXT NOT NULL,
+    duration_ms INTEGER NOT NULL DEFAULT 0,
+    message TEXT,
+    FOREIGN KEY (run_id) REFERENCES test_runs(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_test_cases_run_id ON test_cases(run_id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_heartbeats_session_id;
+DROP INDEX IF EXISTS idx_heartbeats_time;
+DROP INDEX IF EXISTS idx_heartbeats_entity_time;
+DROP TABLE IF EXISTS heartbeats;
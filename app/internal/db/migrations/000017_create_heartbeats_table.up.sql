@@ -0,0 +1,21 @@
+-- Editor activity heartbeats in WakaTime's format. A heartbeat marks activity
+-- on a file at an instant; time per file is derived from the gaps between them.
+CREATE TABLE IF NOT EXISTS heartbeats (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    session_id TEXT,
+    project TEXT NOT NULL,
+    entity TEXT NOT NULL,
+    entity_type TEXT NOT NULL DEFAULT 'file',
+    category TEXT,
+    language TEXT,
+    branch TEXT,
+    is_write INTEGER NOT NULL DEFAULT 0,
+    time TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (session_id) REFERENCES sessions(id) ON DELETE SET NULL
+);
+
+-- Editors resend heartbeats after going offline; duplicates are ignored
+CREATE UNIQUE INDEX IF NOT EXISTS idx_heartbeats_entity_time ON heartbeats(entity, time);
+CREATE INDEX IF NOT EXISTS idx_heartbeats_time ON heartbeats(time);
+CREATE INDEX IF NOT EXISTS idx_heartbeats_session_id ON heartbeats(session_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_attachments_session_id;
+DROP INDEX IF EXISTS idx_attachments_session_sha256;
+DROP TABLE IF EXISTS attachments;
@@ -0,0 +1,18 @@
+-- Images and other files attached to sessions with clio attach.
+-- Content is stored once per SHA-256 in the assets directory; path is the stored copy.
+CREATE TABLE IF NOT EXISTS attachments (
+    id TEXT PRIMARY KEY,
+    session_id TEXT NOT NULL,
+    sha256 TEXT NOT NULL,
+    file_name TEXT NOT NULL,
+    media_type TEXT NOT NULL,
+    size INTEGER NOT NULL DEFAULT 0,
+    path TEXT NOT NULL,
+    caption TEXT,
+    attached_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (session_id) REFERENCES sessions(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_attachments_session_sha256 ON attachments(session_id, sha256);
+CREATE INDEX IF NOT EXISTS idx_attachments_session_id ON attachments(session_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_journal_entries_session_id;
+DROP TABLE IF EXISTS journal_entries;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_code_shingles_block_id;
+DROP TABLE IF EXISTS code_shingles;
+DROP INDEX IF EXISTS idx_code_blocks_content_hash;
+DROP INDEX IF EXISTS idx_code_blocks_message_id;
+DROP TABLE IF EXISTS code_blocks;
@@ -0,0 +1,29 @@
+-- Provenance index over the code blocks stored in messages.code_blocks, used by
+-- clio find-code. content_hash is the SHA-256 of the normalized code; shingles
+-- are 64-bit hashes of overlapping token windows for matching edited code.
+-- source_hash is the SHA-256 of the message's code_blocks JSON, so a reparsed
+-- message is reindexed.
+CREATE TABLE IF NOT EXISTS code_blocks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    message_id TEXT NOT NULL,
+    block_index INTEGER NOT NULL,
+    language TEXT,
+    content_hash TEXT NOT NULL,
+    shingle_count INTEGER NOT NULL DEFAULT 0,
+    source_hash TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (message_id) REFERENCES messages(id) ON DELETE CASCADE,
+    UNIQUE (message_id, block_index)
+);
+
+CREATE INDEX IF NOT EXISTS idx_code_blocks_message_id ON code_blocks(message_id);
+CREATE INDEX IF NOT EXISTS idx_code_blocks_content_hash ON code_blocks(content_hash);
+
+CREATE TABLE IF NOT EXISTS code_shingles (
+    hash INTEGER NOT NULL,
+    block_id INTEGER NOT NULL,
+    PRIMARY KEY (hash, block_id),
+    FOREIGN KEY (block_id) REFERENCES code_blocks(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_code_shingles_block_id ON code_shingles(block_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_conversation_metrics_status;
+DROP INDEX IF EXISTS idx_conversation_metrics_session_id;
+DROP TABLE IF EXISTS conversation_metrics;
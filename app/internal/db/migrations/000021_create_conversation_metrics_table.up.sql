@@ -0,0 +1,22 @@
+-- Derived per-conversation quality metrics, computed from messages by
+-- internal/quality and refreshed when a conversation gains messages or is still
+-- open. status is 'resolved', 'abandoned', or 'open'; turns_to_resolution is
+-- NULL unless the conversation was resolved.
+CREATE TABLE IF NOT EXISTS conversation_metrics (
+    conversation_id TEXT PRIMARY KEY,
+    session_id TEXT NOT NULL,
+    project TEXT NOT NULL,
+    message_count INTEGER NOT NULL,
+    turns INTEGER NOT NULL,
+    turns_to_resolution INTEGER,
+    retries INTEGER NOT NULL DEFAULT 0,
+    error_mentions INTEGER NOT NULL DEFAULT 0,
+    status TEXT NOT NULL,
+    started_at TIMESTAMP NOT NULL,
+    ended_at TIMESTAMP NOT NULL,
+    computed_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (conversation_id) REFERENCES conversations(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_conversation_metrics_session_id ON conversation_metrics(session_id);
+CREATE INDEX IF NOT EXISTS idx_conversation_metrics_status ON conversation_metrics(status);
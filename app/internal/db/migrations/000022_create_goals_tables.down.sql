@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_goal_sessions_session_id;
+DROP TABLE IF EXISTS goal_sessions;
+DROP TABLE IF EXISTS goals;
@@ -0,0 +1,23 @@
+-- Milestones tracked with clio goal. Commits count towards a goal when their
+-- message contains #<tag>; sessions can also be tagged explicitly through
+-- goal_sessions. due_date is local midnight of the due day.
+CREATE TABLE IF NOT EXISTS goals (
+    tag TEXT PRIMARY KEY,
+    title TEXT NOT NULL,
+    project TEXT,
+    due_date TIMESTAMP,
+    target_commits INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL,
+    completed_at TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS goal_sessions (
+    goal_tag TEXT NOT NULL,
+    session_id TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (goal_tag, session_id),
+    FOREIGN KEY (goal_tag) REFERENCES goals(tag) ON DELETE CASCADE,
+    FOREIGN KEY (session_id) REFERENCES sessions(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_goal_sessions_session_id ON goal_sessions(session_id);
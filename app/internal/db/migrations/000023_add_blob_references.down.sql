@@ -0,0 +1,3 @@
+ALTER TABLE commit_files DROP COLUMN diff_blob;
+ALTER TABLE commits DROP COLUMN full_diff_blob;
+ALTER TABLE messages DROP COLUMN content_blob;
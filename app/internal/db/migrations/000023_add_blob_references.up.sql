@@ -0,0 +1,5 @@
+-- SHA-256 of the blob holding a value moved out of the row because it was over
+-- the blob threshold; the row keeps a preview. NULL when the row holds the full value.
+ALTER TABLE messages ADD COLUMN content_blob TEXT;
+ALTER TABLE commits ADD COLUMN full_diff_blob TEXT;
+ALTER TABLE commit_files ADD COLUMN diff_blob TEXT;
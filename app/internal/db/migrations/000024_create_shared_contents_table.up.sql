@@ -0,0 +1,10 @@
+-- Content repeated across messages, such as a code block an agent rewrote on
+-- every retry, stored once by internal/dedup. Code blocks in messages.code_blocks
+-- point at a row through their contentRef; ref_count is the number of code blocks
+-- pointing at it, and the row is deleted when it drops to zero.
+CREATE TABLE IF NOT EXISTS shared_contents (
+    hash TEXT PRIMARY KEY,
+    content TEXT NOT NULL,
+    ref_count INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL
+);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_error_reports_last_seen;
+DROP TABLE IF EXISTS error_reports;
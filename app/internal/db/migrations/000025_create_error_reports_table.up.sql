@@ -0,0 +1,16 @@
+-- Errors recurring across daemon subsystems, collected by internal/errorlog.
+-- Occurrences with the same subsystem, subject (e.g. a repository path), and
+-- normalized cause share a row holding their count and latest message, so
+-- 'clio status --errors' can report them without scanning logs.
+CREATE TABLE IF NOT EXISTS error_reports (
+    subsystem TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    cause TEXT NOT NULL,
+    message TEXT NOT NULL,
+    count INTEGER NOT NULL DEFAULT 0,
+    first_seen TIMESTAMP NOT NULL,
+    last_seen TIMESTAMP NOT NULL,
+    PRIMARY KEY (subsystem, subject, cause)
+);
+
+CREATE INDEX IF NOT EXISTS idx_error_reports_last_seen ON error_reports(last_seen);
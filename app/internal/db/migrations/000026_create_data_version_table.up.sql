@@ -0,0 +1,12 @@
+-- The stored data format and which clio versions use it, maintained by
+-- internal/upgrade. A single row records the data version the last upgrade
+-- brought the data to and the binary that ran it, and the running daemon's
+-- version and PID so the CLI can refuse to mix versions.
+CREATE TABLE IF NOT EXISTS data_version (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    data_version INTEGER NOT NULL,
+    written_by TEXT NOT NULL,
+    daemon_version TEXT NOT NULL DEFAULT '',
+    daemon_pid INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL
+);
@@ -0,0 +1,12 @@
+-- Ownership of the database by a running daemon, maintained by internal/lease.
+-- The single row names the daemon holding the lease, which refreshes
+-- heartbeat_at while it runs; a second daemon finding a fresh lease held by
+-- another owner refuses to start instead of writing alongside it.
+CREATE TABLE IF NOT EXISTS daemon_lease (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    owner TEXT NOT NULL,
+    pid INTEGER NOT NULL,
+    hostname TEXT NOT NULL,
+    acquired_at TIMESTAMP NOT NULL,
+    heartbeat_at TIMESTAMP NOT NULL
+);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_message_revisions_conversation;
+DROP TABLE IF EXISTS message_revisions;
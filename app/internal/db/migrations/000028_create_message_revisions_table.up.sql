@@ -0,0 +1,20 @@
+-- Earlier versions of messages Cursor rewrote in place, e.g. a regenerated
+-- answer or an edited prompt, kept by internal/cursor before the message row is
+-- overwritten. revision counts from 1 per message in the order versions were
+-- replaced. content_blob is set as in messages when the content was moved to the
+-- blob store.
+CREATE TABLE IF NOT EXISTS message_revisions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    message_id TEXT NOT NULL,
+    conversation_id TEXT NOT NULL,
+    revision INTEGER NOT NULL,
+    content TEXT NOT NULL,
+    content_blob TEXT,
+    thinking_text TEXT,
+    parser_version INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL,
+    replaced_at TIMESTAMP NOT NULL,
+    UNIQUE (message_id, revision)
+);
+
+CREATE INDEX IF NOT EXISTS idx_message_revisions_conversation ON message_revisions(conversation_id);
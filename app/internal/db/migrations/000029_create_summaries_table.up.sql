@@ -0,0 +1,13 @@
+-- Summaries of conversations and sessions cached by internal/summaries, one per
+-- summarizer. content_hash covers the messages (and a session's commits) the
+-- summary was generated from; a summary whose hash no longer matches is
+-- regenerated the next time it is asked for.
+CREATE TABLE IF NOT EXISTS summaries (
+    subject TEXT NOT NULL,
+    subject_id TEXT NOT NULL,
+    summarizer TEXT NOT NULL,
+    content_hash TEXT NOT NULL,
+    summary TEXT NOT NULL,
+    generated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (subject, subject_id, summarizer)
+);
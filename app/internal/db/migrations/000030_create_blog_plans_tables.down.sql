@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_blog_topic_sessions_session_id;
+DROP TABLE IF EXISTS blog_topic_sessions;
+DROP INDEX IF EXISTS idx_blog_topics_plan_id;
+DROP TABLE IF EXISTS blog_topics;
+DROP TABLE IF EXISTS blog_plans;
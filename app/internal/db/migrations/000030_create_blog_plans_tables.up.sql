@@ -0,0 +1,32 @@
+-- Blog series plans proposed by clio blog plan. Each plan groups related
+-- sessions into topics, one proposed post each, kept so posts can be drafted
+-- from the plan over time. since and until are the range the plan covered.
+CREATE TABLE IF NOT EXISTS blog_plans (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    project TEXT,
+    since TIMESTAMP,
+    until TIMESTAMP,
+    created_at TIMESTAMP NOT NULL
+);
+
+-- keywords is a comma-separated list of the topic's most shared terms
+CREATE TABLE IF NOT EXISTS blog_topics (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    plan_id INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+    title TEXT NOT NULL,
+    keywords TEXT NOT NULL DEFAULT '',
+    FOREIGN KEY (plan_id) REFERENCES blog_plans(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_blog_topics_plan_id ON blog_topics(plan_id);
+
+CREATE TABLE IF NOT EXISTS blog_topic_sessions (
+    topic_id INTEGER NOT NULL,
+    session_id TEXT NOT NULL,
+    PRIMARY KEY (topic_id, session_id),
+    FOREIGN KEY (topic_id) REFERENCES blog_topics(id) ON DELETE CASCADE,
+    FOREIGN KEY (session_id) REFERENCES sessions(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_blog_topic_sessions_session_id ON blog_topic_sessions(session_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_drafts_topic_id;
+DROP TABLE IF EXISTS drafts;
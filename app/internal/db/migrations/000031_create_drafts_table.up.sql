@@ -0,0 +1,19 @@
+-- Blog posts drafted by clio blog draft. generated is the latest text clio
+-- generated for the draft and generated_hash its SHA-256; when the file at path
+-- no longer matches it, the author has edited the draft and clio shows a diff
+-- rather than overwriting it. status is draft, edited, or published.
+CREATE TABLE IF NOT EXISTS drafts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    topic_id INTEGER,
+    path TEXT NOT NULL UNIQUE,
+    title TEXT NOT NULL,
+    status TEXT NOT NULL,
+    generated TEXT NOT NULL,
+    generated_hash TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    published_at TIMESTAMP,
+    FOREIGN KEY (topic_id) REFERENCES blog_topics(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_drafts_topic_id ON drafts(topic_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_reminders_done_at;
+DROP TABLE IF EXISTS reminders;
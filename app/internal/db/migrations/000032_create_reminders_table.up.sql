@@ -0,0 +1,15 @@
+-- Notes to self set with clio remind. session_id is the session the reminder
+-- was set in, if any. The daemon sets notified_at once it has announced a due
+-- reminder; done_at is set when it's dismissed with clio remind done.
+CREATE TABLE IF NOT EXISTS reminders (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    session_id TEXT,
+    text TEXT NOT NULL,
+    due_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    notified_at TIMESTAMP,
+    done_at TIMESTAMP,
+    FOREIGN KEY (session_id) REFERENCES sessions(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_reminders_done_at ON reminders(done_at);
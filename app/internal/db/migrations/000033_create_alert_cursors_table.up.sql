@@ -0,0 +1,8 @@
+-- How far the daemon has scanned each source for alerts (messages and commit
+-- file diffs), as the rowid of the last row scanned. Rows are scanned once, so
+-- edits made by reparsing don't raise alerts again.
+CREATE TABLE IF NOT EXISTS alert_cursors (
+    source TEXT PRIMARY KEY,
+    last_rowid INTEGER NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_sensitive_flags_conversation;
+DROP TABLE IF EXISTS sensitive_flags;
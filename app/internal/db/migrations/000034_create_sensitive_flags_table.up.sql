@@ -0,0 +1,14 @@
+-- Sensitive categories the gate in internal/cursor found in captured messages,
+-- with the action taken: store (flagged only), redact, or drop. Dropped
+-- messages are never stored, so message_id has no foreign key; flags are
+-- replaced whenever the message is stored again.
+CREATE TABLE IF NOT EXISTS sensitive_flags (
+    message_id TEXT NOT NULL,
+    conversation_id TEXT NOT NULL,
+    category TEXT NOT NULL,
+    action TEXT NOT NULL,
+    flagged_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (message_id, category)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sensitive_flags_conversation ON sensitive_flags(conversation_id);
@@ -0,0 +1,15 @@
+-- Read-only links to one session, minted with clio share and served by the
+-- daemon's share listener. Only a SHA-256 hash of each link's token is kept,
+-- so the database alone can't be used to open links. revoked_at is set by
+-- clio share revoke.
+CREATE TABLE IF NOT EXISTS share_links (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    token_hash TEXT NOT NULL UNIQUE,
+    session_id TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP,
+    FOREIGN KEY (session_id) REFERENCES sessions(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_share_links_session_id ON share_links(session_id);
@@ -0,0 +1,11 @@
+-- Favorite sessions and conversations, pinned with clio pin. target_id is a
+-- session ID for kind 'session' and a composer ID for kind 'conversation', so a
+-- pinned conversation stays pinned across the sessions it spans.
+CREATE TABLE IF NOT EXISTS pins (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    kind TEXT NOT NULL,
+    target_id TEXT NOT NULL,
+    note TEXT,
+    pinned_at TIMESTAMP NOT NULL,
+    UNIQUE (kind, target_id)
+);
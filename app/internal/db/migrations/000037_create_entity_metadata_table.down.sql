@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_entity_metadata_key;
+DROP TABLE IF EXISTS entity_metadata;
@@ -0,0 +1,14 @@
+-- Arbitrary key=value context attached to sessions and commits with clio meta,
+-- such as a ticket or customer. entity_id is a session ID for entity_type
+-- 'session' and a commit hash for entity_type 'commit', so a commit's metadata
+-- covers every worktree it was captured in.
+CREATE TABLE IF NOT EXISTS entity_metadata (
+    entity_type TEXT NOT NULL,
+    entity_id TEXT NOT NULL,
+    key TEXT NOT NULL,
+    value TEXT NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (entity_type, entity_id, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_entity_metadata_key ON entity_metadata(key, value);
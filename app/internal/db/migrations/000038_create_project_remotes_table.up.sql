@@ -0,0 +1,8 @@
+-- Project names claimed by git remotes. Checkouts of a repository with an origin
+-- remote share the name its remote claimed first, and a different repository
+-- whose directory has the same name claims a longer one (owner-repo).
+CREATE TABLE IF NOT EXISTS project_remotes (
+    remote TEXT PRIMARY KEY,
+    project TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL
+);
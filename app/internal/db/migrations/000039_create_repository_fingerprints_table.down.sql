@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_repository_fingerprints_identity;
+DROP TABLE IF EXISTS repository_fingerprints;
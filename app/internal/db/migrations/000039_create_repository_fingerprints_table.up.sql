@@ -0,0 +1,13 @@
+-- Stable identities of watched repositories: the root commit of HEAD's
+-- first-parent history and the normalized origin remote. When a repository is
+-- discovered at a new path and its old path is gone, its commits and poller
+-- checkpoint are moved to the new path.
+CREATE TABLE IF NOT EXISTS repository_fingerprints (
+    repository_path TEXT PRIMARY KEY,
+    root_commit TEXT NOT NULL,
+    remote TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_repository_fingerprints_identity ON repository_fingerprints(root_commit, remote);
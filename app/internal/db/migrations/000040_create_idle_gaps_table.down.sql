@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_idle_gaps_end_time;
+DROP TABLE IF EXISTS idle_gaps;
@@ -0,0 +1,12 @@
+-- Periods the machine was asleep or locked, recorded by the daemon. Sessions
+-- don't run across them, and reports leave them out of time worked.
+CREATE TABLE IF NOT EXISTS idle_gaps (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    kind TEXT NOT NULL,
+    start_time TIMESTAMP NOT NULL,
+    end_time TIMESTAMP NOT NULL,
+    source TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_idle_gaps_end_time ON idle_gaps(end_time);
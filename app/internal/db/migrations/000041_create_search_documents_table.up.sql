@@ -0,0 +1,10 @@
+-- Message text normalized for search: markdown syntax stripped from the prose,
+-- and code kept apart so searches can target either. Rebuilt from messages, so
+-- rows are replaced whenever a message's content or code blocks change.
+CREATE TABLE IF NOT EXISTS search_documents (
+    message_id TEXT PRIMARY KEY,
+    prose TEXT NOT NULL,
+    code TEXT NOT NULL,
+    source_hash TEXT NOT NULL,
+    indexed_at TIMESTAMP NOT NULL
+);
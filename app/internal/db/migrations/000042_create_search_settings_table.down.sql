@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS search_fts;
+DROP TABLE IF EXISTS search_settings;
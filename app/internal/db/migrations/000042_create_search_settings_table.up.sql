@@ -0,0 +1,8 @@
+-- The options the full-text search table was built with. The table itself
+-- (search_fts) is created by the search index, since its tokenizer comes from
+-- the configuration; a change of options rebuilds it from search_documents.
+CREATE TABLE IF NOT EXISTS search_settings (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    options TEXT NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
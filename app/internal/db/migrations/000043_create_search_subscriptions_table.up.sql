@@ -0,0 +1,14 @@
+-- Saved searches the daemon runs against newly captured messages, notifying
+-- search.matched for each new match. last_rowid is the rowid of the last
+-- message checked, starting at the newest message when the subscription is
+-- added, so each message is checked once.
+CREATE TABLE IF NOT EXISTS search_subscriptions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    query TEXT NOT NULL,
+    code_only BOOLEAN NOT NULL DEFAULT 0,
+    last_rowid INTEGER NOT NULL,
+    match_count INTEGER NOT NULL DEFAULT 0,
+    last_matched_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL
+);
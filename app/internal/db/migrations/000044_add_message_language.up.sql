@@ -0,0 +1,4 @@
+-- The natural language of each message's prose as an ISO 639-1 code, detected
+-- when it's stored; empty when the text is too short or mixed to tell. NULL for
+-- messages stored before detection, which readers detect on the fly.
+ALTER TABLE messages ADD COLUMN language TEXT;
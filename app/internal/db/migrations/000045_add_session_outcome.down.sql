@@ -0,0 +1,3 @@
+ALTER TABLE sessions DROP COLUMN outcome_set_at;
+ALTER TABLE sessions DROP COLUMN outcome_note;
+ALTER TABLE sessions DROP COLUMN outcome;
@@ -0,0 +1,5 @@
+-- A session's outcome set by hand: shipped, abandoned, or blocked. NULL leaves
+-- it to be inferred from the session's commits and their reverts.
+ALTER TABLE sessions ADD COLUMN outcome TEXT;
+ALTER TABLE sessions ADD COLUMN outcome_note TEXT;
+ALTER TABLE sessions ADD COLUMN outcome_set_at TIMESTAMP;
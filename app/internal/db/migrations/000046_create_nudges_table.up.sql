@@ -0,0 +1,13 @@
+-- Habit nudges the daemon has raised. trigger_key identifies what the nudge
+-- was about (e.g. the latest session of an uncommitted run), so the same
+-- condition is only nudged once; sent_at enforces the cooldown between nudges
+-- of one kind in one project.
+CREATE TABLE IF NOT EXISTS nudges (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    kind TEXT NOT NULL,
+    project TEXT NOT NULL,
+    trigger_key TEXT NOT NULL,
+    message TEXT NOT NULL,
+    sent_at TIMESTAMP NOT NULL,
+    UNIQUE (kind, project, trigger_key)
+);
@@ -1,6 +1,8 @@
 package db
 
 import (
+	"database/sql"
+	"errors"
 	"path/filepath"
 	"testing"
 
@@ -107,6 +109,57 @@ func TestMigrations_Idempotent(t *testing.T) {
 	}
 }
 
+func TestMigrations_RefusesNewerSchema(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := &config.Config{
+		Storage: config.StorageConfig{
+			DatabasePath: filepath.Join(tmpDir, "newer_schema_test.db"),
+		},
+	}
+
+	db, err := Open(cfg)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	// Simulate a migration applied by a newer clio
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatalf("Failed to load migrations: %v", err)
+	}
+	if _, err := db.Exec("INSERT INTO schema_migrations (version, dirty) VALUES (?, 0)", latestMigration(migrations)+1); err != nil {
+		t.Fatalf("Failed to record newer migration: %v", err)
+	}
+
+	err = RunMigrations(db)
+	if !errors.Is(err, ErrNewerSchema) {
+		t.Errorf("Expected ErrNewerSchema, got %v", err)
+	}
+}
+
+func TestMigrationsPending(t *testing.T) {
+	tmpDir := t.TempDir()
+	database, err := sql.Open("sqlite", filepath.Join(tmpDir, "pending_test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer database.Close()
+
+	pending, err := MigrationsPending(database)
+	if err != nil || !pending {
+		t.Fatalf("MigrationsPending() on a new database = %v, %v; want true", pending, err)
+	}
+
+	if err := RunMigrations(database); err != nil {
+		t.Fatalf("Failed to run migrations: %v", err)
+	}
+	pending, err = MigrationsPending(database)
+	if err != nil || pending {
+		t.Errorf("MigrationsPending() after migrating = %v, %v; want false", pending, err)
+	}
+}
+
 func TestRollbackMigrations(t *testing.T) {
 	// Create temporary database
 	tmpDir := t.TempDir()
@@ -141,8 +194,12 @@ func TestRollbackMigrations(t *testing.T) {
 		t.Fatal("Sessions table should exist before rollback")
 	}
 
-	// Rollback all migrations (7 migrations to get back to version 0)
-	newVersion, err := RollbackMigrations(db, 7)
+	// Rollback all migrations to get back to version 0
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatalf("Failed to load migrations: %v", err)
+	}
+	newVersion, err := RollbackMigrations(db, len(migrations))
 	if err != nil {
 		t.Fatalf("Failed to rollback migration: %v", err)
 	}
@@ -0,0 +1,33 @@
+package db
+
+import (
+	"sort"
+	"time"
+)
+
+// The SQLite driver writes time.Time values with Time.String, e.g.
+// "2024-01-10 13:30:00 +0100 CET", keeping the offset of the zone each value
+// was written in. Values written in different zones, such as local capture
+// times and UTC commit times, or across a daylight saving change, don't sort
+// or compare as text the way they do as times. Queries therefore leave
+// ordering and time ranges out of SQL: the driver parses the scanned values
+// back to time.Time, and callers order and filter them with SortOldestFirst,
+// SortNewestFirst, and InRange.
+
+// SortOldestFirst sorts items by the time at returns, oldest first, keeping
+// the order of items with equal times
+func SortOldestFirst[T any](items []T, at func(T) time.Time) {
+	sort.SliceStable(items, func(i, j int) bool { return at(items[i]).Before(at(items[j])) })
+}
+
+// SortNewestFirst sorts items by the time at returns, newest first, keeping
+// the order of items with equal times
+func SortNewestFirst[T any](items []T, at func(T) time.Time) {
+	sort.SliceStable(items, func(i, j int) bool { return at(items[i]).After(at(items[j])) })
+}
+
+// InRange reports whether t is at or after since and before until. A zero
+// bound leaves that side of the range open.
+func InRange(t, since, until time.Time) bool {
+	return (since.IsZero() || !t.Before(since)) && (until.IsZero() || t.Before(until))
+}
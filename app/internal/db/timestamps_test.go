@@ -0,0 +1,97 @@
+package db
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStoredTimestamps(t *testing.T) {
+	database, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "clio.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer database.Close()
+	if _, err := database.Exec("CREATE TABLE events (id TEXT, at TIMESTAMP)"); err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+
+	// Written oldest first, but 12:30 UTC written as 13:30 CET sorts after 13:00 UTC as text
+	cet := time.FixedZone("CET", 3600)
+	base := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	events := []struct {
+		id string
+		at time.Time
+	}{
+		{"first", base.Add(123 * time.Millisecond)},
+		{"second", base.Add(30 * time.Minute).In(cet)},
+		{"third", base.Add(time.Hour)},
+	}
+	for _, e := range events {
+		if _, err := database.Exec("INSERT INTO events (id, at) VALUES (?, ?)", e.id, e.at); err != nil {
+			t.Fatalf("failed to insert event: %v", err)
+		}
+	}
+
+	type event struct {
+		id string
+		at time.Time
+	}
+	rows, err := database.Query("SELECT id, at FROM events ORDER BY at")
+	if err != nil {
+		t.Fatalf("failed to query events: %v", err)
+	}
+	defer rows.Close()
+	var stored []event
+	for rows.Next() {
+		var e event
+		if err := rows.Scan(&e.id, &e.at); err != nil {
+			t.Fatalf("failed to scan event: %v", err)
+		}
+		stored = append(stored, e)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("failed to read events: %v", err)
+	}
+	if len(stored) != 3 || stored[1].id != "third" || stored[2].id != "second" {
+		t.Fatalf("ORDER BY = %+v, want the CET value sorted last as text", stored)
+	}
+
+	SortOldestFirst(stored, func(e event) time.Time { return e.at })
+	if stored[0].id != "first" || stored[1].id != "second" || stored[2].id != "third" {
+		t.Errorf("SortOldestFirst() = %+v, want first, second, third", stored)
+	}
+	SortNewestFirst(stored, func(e event) time.Time { return e.at })
+	if stored[0].id != "third" || stored[2].id != "first" {
+		t.Errorf("SortNewestFirst() = %+v, want third first", stored)
+	}
+}
+
+func TestInRange(t *testing.T) {
+	since := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	until := since.Add(24 * time.Hour)
+
+	tests := []struct {
+		name         string
+		at           time.Time
+		since, until time.Time
+		want         bool
+	}{
+		{name: "unbounded", at: since, want: true},
+		{name: "at since", at: since, since: since, until: until, want: true},
+		{name: "before since", at: since.Add(-time.Nanosecond), since: since, until: until, want: false},
+		{name: "at until", at: until, since: since, until: until, want: false},
+		{name: "other zone inside", at: until.Add(-time.Minute).In(time.FixedZone("CET", 3600)), since: since, until: until, want: true},
+		{name: "open start", at: since.Add(-48 * time.Hour), until: until, want: true},
+		{name: "open end", at: until.Add(48 * time.Hour), since: since, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := InRange(tt.at, tt.since, tt.until); got != tt.want {
+				t.Errorf("InRange(%v, %v, %v) = %v, want %v", tt.at, tt.since, tt.until, got, tt.want)
+			}
+		})
+	}
+}
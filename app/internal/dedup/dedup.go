@@ -0,0 +1,166 @@
+// Package dedup stores content repeated across messages once. Agents retrying a
+// change often repeat the same large code block or diff in message after message;
+// storage moves each such block to the shared_contents table under its SHA-256,
+// counting the blocks that refer to it, and readers restore it transparently.
+package dedup
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MinSize is the smallest content in bytes worth sharing; shorter content stays inline
+const MinSize = 1024
+
+// refKey is the code block field holding the reference to shared content
+const refKey = "contentRef"
+
+// ErrNotFound is returned when no shared content is stored under a reference
+var ErrNotFound = errors.New("shared content not found")
+
+// Querier is the read access dedup needs, satisfied by *sql.DB and *sql.Tx
+type Querier interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// Share stores content under its SHA-256, counting one more reference to it, and
+// returns the reference
+func Share(tx *sql.Tx, content string) (string, error) {
+	sum := sha256.Sum256([]byte(content))
+	ref := hex.EncodeToString(sum[:])
+	_, err := tx.Exec(`
+		INSERT INTO shared_contents (hash, content, ref_count, created_at)
+		VALUES (?, ?, 1, ?)
+		ON CONFLICT(hash) DO UPDATE SET ref_count = ref_count + 1
+	`, ref, content, time.Now())
+	if err != nil {
+		return "", fmt.Errorf("failed to share content: %w", err)
+	}
+	return ref, nil
+}
+
+// Release drops a reference to shared content, deleting the content once nothing
+// refers to it
+func Release(tx *sql.Tx, ref string) error {
+	if _, err := tx.Exec("UPDATE shared_contents SET ref_count = ref_count - 1 WHERE hash = ?", ref); err != nil {
+		return fmt.Errorf("failed to release shared content: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM shared_contents WHERE hash = ? AND ref_count <= 0", ref); err != nil {
+		return fmt.Errorf("failed to delete shared content: %w", err)
+	}
+	return nil
+}
+
+// Load returns the content stored under ref
+func Load(q Querier, ref string) (string, error) {
+	var content string
+	err := q.QueryRow("SELECT content FROM shared_contents WHERE hash = ?", ref).Scan(&content)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, ref)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to load shared content: %w", err)
+	}
+	return content, nil
+}
+
+// ExpandCodeBlocks restores shared content in a stored code_blocks JSON array, for
+// readers that parse code blocks themselves. Arrays without references are
+// returned unchanged. Queries run on q, so callers iterating rows must finish
+// before expanding.
+func ExpandCodeBlocks(q Querier, raw string) (string, error) {
+	if !strings.Contains(raw, `"`+refKey+`"`) {
+		return raw, nil
+	}
+
+	var blocks []map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(raw), &blocks); err != nil {
+		return raw, fmt.Errorf("failed to parse code blocks: %w", err)
+	}
+	for _, block := range blocks {
+		encoded, ok := block[refKey]
+		if !ok {
+			continue
+		}
+		var ref string
+		if err := json.Unmarshal(encoded, &ref); err != nil {
+			return raw, fmt.Errorf("failed to parse code block reference: %w", err)
+		}
+		content, err := Load(q, ref)
+		if err != nil {
+			return raw, err
+		}
+		if block["content"], err = json.Marshal(content); err != nil {
+			return raw, fmt.Errorf("failed to encode code block: %w", err)
+		}
+		delete(block, refKey)
+	}
+
+	expanded, err := json.Marshal(blocks)
+	if err != nil {
+		return raw, fmt.Errorf("failed to encode code blocks: %w", err)
+	}
+	return string(expanded), nil
+}
+
+// CodeBlockRefs returns the shared content references in a stored code_blocks
+// JSON array. A malformed array can't hold references, so it returns none.
+func CodeBlockRefs(raw string) []string {
+	if !strings.Contains(raw, `"`+refKey+`"`) {
+		return nil
+	}
+
+	var blocks []map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(raw), &blocks); err != nil {
+		return nil
+	}
+	var refs []string
+	for _, block := range blocks {
+		var ref string
+		if err := json.Unmarshal(block[refKey], &ref); err == nil && ref != "" {
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// Inline restores shared content in the code blocks of a database attached as
+// schema, for copies of messages leaving this database such as archive bundles
+func Inline(tx *sql.Tx, schema string) error {
+	rows, err := tx.Query(fmt.Sprintf("SELECT id, code_blocks FROM %s.messages WHERE code_blocks LIKE ?", schema), `%"`+refKey+`"%`)
+	if err != nil {
+		return fmt.Errorf("failed to query shared code blocks: %w", err)
+	}
+	stored := make(map[string]string)
+	for rows.Next() {
+		var id, blocks string
+		if err := rows.Scan(&id, &blocks); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan shared code blocks: %w", err)
+		}
+		stored[id] = blocks
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return fmt.Errorf("failed to iterate shared code blocks: %w", err)
+	}
+
+	update := fmt.Sprintf("UPDATE %s.messages SET code_blocks = ? WHERE id = ?", schema)
+	for id, blocks := range stored {
+		expanded, err := ExpandCodeBlocks(tx, blocks)
+		if err != nil {
+			return fmt.Errorf("failed to expand code blocks of message %s: %w", id, err)
+		}
+		if _, err := tx.Exec(update, expanded, id); err != nil {
+			return fmt.Errorf("failed to inline code blocks of message %s: %w", id, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package dedup
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stwalsh4118/clio/internal/db"
+	_ "modernc.org/sqlite"
+)
+
+func setupTestDB(t *testing.T) *sql.DB {
+	database, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "clio.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+	if err := db.RunMigrations(database); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	return database
+}
+
+func inTx(t *testing.T, database *sql.DB, fn func(tx *sql.Tx) error) {
+	t.Helper()
+	tx, err := database.Begin()
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		t.Fatalf("transaction failed: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+}
+
+func TestShareRelease(t *testing.T) {
+	database := setupTestDB(t)
+	content := strings.Repeat("line\n", 300)
+
+	var first, second string
+	inTx(t, database, func(tx *sql.Tx) (err error) {
+		if first, err = Share(tx, content); err != nil {
+			return err
+		}
+		second, err = Share(tx, content)
+		return err
+	})
+	if first != second {
+		t.Fatalf("Share() = %s and %s, want one reference for identical content", first, second)
+	}
+
+	var refCount int
+	if err := database.QueryRow("SELECT ref_count FROM shared_contents WHERE hash = ?", first).Scan(&refCount); err != nil || refCount != 2 {
+		t.Errorf("ref_count = %d, %v, want 2", refCount, err)
+	}
+	if got, err := Load(database, first); err != nil || got != content {
+		t.Errorf("Load() = %d bytes, %v, want the shared content", len(got), err)
+	}
+
+	inTx(t, database, func(tx *sql.Tx) error { return Release(tx, first) })
+	if _, err := Load(database, first); err != nil {
+		t.Errorf("Load() after one release error = %v, want the content kept", err)
+	}
+	inTx(t, database, func(tx *sql.Tx) error { return Release(tx, first) })
+	if _, err := Load(database, first); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Load() after the last release error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestExpandCodeBlocks(t *testing.T) {
+	database := setupTestDB(t)
+	content := strings.Repeat("x", MinSize)
+
+	var ref string
+	inTx(t, database, func(tx *sql.Tx) (err error) {
+		ref, err = Share(tx, content)
+		return err
+	})
+
+	plain := `[{"content":"small","languageId":"go","codeBlockIdx":0}]`
+	if got, err := ExpandCodeBlocks(database, plain); err != nil || got != plain {
+		t.Errorf("ExpandCodeBlocks() = %s, %v, want blocks without references unchanged", got, err)
+	}
+
+	stored := `[{"content":"","languageId":"go","codeBlockIdx":0,"contentRef":"` + ref + `"},{"content":"small","languageId":"go","codeBlockIdx":1}]`
+	got, err := ExpandCodeBlocks(database, stored)
+	if err != nil {
+		t.Fatalf("ExpandCodeBlocks() error = %v", err)
+	}
+	if want := `[{"codeBlockIdx":0,"content":"` + content + `","languageId":"go"},{"codeBlockIdx":1,"content":"small","languageId":"go"}]`; got != want {
+		t.Errorf("ExpandCodeBlocks() = %s, want %s", got, want)
+	}
+
+	missing := `[{"content":"","contentRef":"` + strings.Repeat("0", 64) + `"}]`
+	if _, err := ExpandCodeBlocks(database, missing); !errors.Is(err, ErrNotFound) {
+		t.Errorf("ExpandCodeBlocks() with a missing reference error = %v, want ErrNotFound", err)
+	}
+}
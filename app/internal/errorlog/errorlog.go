@@ -0,0 +1,256 @@
+// Package errorlog collects errors that recur across daemon subsystems. Occurrences
+// with the same subsystem, subject (such as a repository path), and cause are
+// counted together, logged at most once per LogInterval, and stored with their
+// latest occurrence for 'clio status --errors' instead of filling the log.
+package errorlog
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+const (
+	// LogInterval is the minimum time between log lines for the same recurring error
+	LogInterval = 10 * time.Minute
+	// FlushInterval is how often the daemon writes collected errors to the database
+	FlushInterval = time.Minute
+	// maxMessageLength bounds the stored error message
+	maxMessageLength = 1000
+)
+
+// Subsystems reporting to the collector
+const (
+	SubsystemGit     = "git"
+	SubsystemCapture = "capture"
+	SubsystemBlobs   = "blobs"
+)
+
+var (
+	// hashPattern matches commit hashes and other hex identifiers
+	hashPattern = regexp.MustCompile(`\b[0-9a-f]{7,64}\b`)
+	// numberPattern matches counts, offsets, and durations
+	numberPattern = regexp.MustCompile(`\d+`)
+)
+
+// Record is a recurring error with its latest occurrence
+type Record struct {
+	Subsystem string
+	Subject   string // What failed, e.g. a repository path; empty for the subsystem as a whole
+	Cause     string // Message with hashes and numbers normalized, identifying the error
+	Message   string // Latest message
+	Count     int
+	FirstSeen time.Time
+	LastSeen  time.Time
+}
+
+// Collector deduplicates and rate-limits errors reported by subsystems
+type Collector interface {
+	Report(subsystem, subject string, err error)
+	Flush() error
+}
+
+// entry is an error tracked in memory between flushes
+type entry struct {
+	record     Record
+	pending    int       // Occurrences not yet flushed
+	lastLogged time.Time // When the error was last logged
+	suppressed int       // Occurrences since it was last logged
+}
+
+// collector implements Collector
+type collector struct {
+	db      *sql.DB
+	logger  logging.Logger
+	mu      sync.Mutex
+	entries map[string]*entry
+}
+
+// NewCollector creates a new error collector instance
+func NewCollector(db *sql.DB, logger logging.Logger) (Collector, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database cannot be nil")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	return &collector{
+		db:      db,
+		logger:  logger.With("component", "error_collector"),
+		entries: make(map[string]*entry),
+	}, nil
+}
+
+// Report records an occurrence of err. The first occurrence is logged, and repeats
+// at most once per LogInterval with the number suppressed since.
+func (c *collector) Report(subsystem, subject string, err error) {
+	c.report(subsystem, subject, err, time.Now())
+}
+
+// report records an occurrence of err at now
+func (c *collector) report(subsystem, subject string, err error, now time.Time) {
+	if err == nil {
+		return
+	}
+	message := truncate(err.Error())
+	cause := Cause(err)
+	key := subsystem + "\x00" + subject + "\x00" + cause
+
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	if !ok {
+		e = &entry{record: Record{Subsystem: subsystem, Subject: subject, Cause: cause, FirstSeen: now}}
+		c.entries[key] = e
+	}
+	e.record.Message = message
+	e.record.Count++
+	e.record.LastSeen = now
+	e.pending++
+
+	shouldLog := !ok || now.Sub(e.lastLogged) >= LogInterval
+	suppressed := e.suppressed
+	if shouldLog {
+		e.lastLogged = now
+		e.suppressed = 0
+	} else {
+		e.suppressed++
+	}
+	c.mu.Unlock()
+
+	if !shouldLog {
+		return
+	}
+	if suppressed > 0 {
+		c.logger.Warn("recurring error", "subsystem", subsystem, "subject", subject, "error", message, "repeats_since_last_log", suppressed)
+		return
+	}
+	c.logger.Warn("error reported", "subsystem", subsystem, "subject", subject, "error", message)
+}
+
+// Flush writes occurrences collected since the last flush to the database and
+// forgets errors that haven't recurred within LogInterval
+func (c *collector) Flush() error {
+	return c.flush(time.Now())
+}
+
+// flush writes pending occurrences as of now
+func (c *collector) flush(now time.Time) error {
+	c.mu.Lock()
+	var pending []Record
+	for key, e := range c.entries {
+		if e.pending > 0 {
+			record := e.record
+			record.Count = e.pending
+			pending = append(pending, record)
+			e.pending = 0
+		}
+		if now.Sub(e.record.LastSeen) >= LogInterval {
+			delete(c.entries, key)
+		}
+	}
+	c.mu.Unlock()
+
+	for i, record := range pending {
+		if err := c.save(record); err != nil {
+			// Keep what wasn't written for the next flush
+			c.requeue(pending[i:])
+			return err
+		}
+	}
+	return nil
+}
+
+// save adds record's occurrences to its stored row
+func (c *collector) save(record Record) error {
+	_, err := c.db.Exec(`
+		INSERT INTO error_reports (subsystem, subject, cause, message, count, first_seen, last_seen)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(subsystem, subject, cause) DO UPDATE SET
+			message = excluded.message,
+			count = error_reports.count + excluded.count,
+			last_seen = excluded.last_seen
+	`, record.Subsystem, record.Subject, record.Cause, record.Message, record.Count, record.FirstSeen, record.LastSeen)
+	if err != nil {
+		return fmt.Errorf("failed to save error report: %w", err)
+	}
+	return nil
+}
+
+// requeue restores unwritten occurrences to the in-memory entries
+func (c *collector) requeue(records []Record) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, record := range records {
+		key := record.Subsystem + "\x00" + record.Subject + "\x00" + record.Cause
+		e, ok := c.entries[key]
+		if !ok {
+			e = &entry{record: record}
+			c.entries[key] = e
+		}
+		e.pending += record.Count
+	}
+}
+
+// Cause identifies an error independently of the hashes and numbers in its message,
+// so e.g. failures on different commits with the same cause are counted together
+func Cause(err error) string {
+	cause := hashPattern.ReplaceAllString(truncate(err.Error()), "<hash>")
+	return numberPattern.ReplaceAllString(cause, "N")
+}
+
+// truncate bounds a message to maxMessageLength bytes
+func truncate(message string) string {
+	if len(message) <= maxMessageLength {
+		return message
+	}
+	return message[:maxMessageLength] + "..."
+}
+
+// List returns stored errors, most recent first. A limit of 0 returns all.
+func List(db *sql.DB, limit int) ([]Record, error) {
+	query := `
+		SELECT subsystem, subject, cause, message, count, first_seen, last_seen
+		FROM error_reports
+		ORDER BY last_seen DESC, subsystem ASC, subject ASC
+	`
+	var args []interface{}
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query error reports: %w", err)
+	}
+	defer rows.Close()
+
+	var records []Record
+	for rows.Next() {
+		var record Record
+		if err := rows.Scan(&record.Subsystem, &record.Subject, &record.Cause, &record.Message,
+			&record.Count, &record.FirstSeen, &record.LastSeen); err != nil {
+			return nil, fmt.Errorf("failed to scan error report: %w", err)
+		}
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating error reports: %w", err)
+	}
+	return records, nil
+}
+
+// Clear deletes every stored error and returns how many there were
+func Clear(db *sql.DB) (int64, error) {
+	result, err := db.Exec("DELETE FROM error_reports")
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear error reports: %w", err)
+	}
+	return result.RowsAffected()
+}
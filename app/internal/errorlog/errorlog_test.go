@@ -0,0 +1,94 @@
+package errorlog
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/logging"
+	_ "modernc.org/sqlite"
+)
+
+func newTestCollector(t *testing.T) (*collector, *sql.DB) {
+	database, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "clio.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+	if err := db.RunMigrations(database); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	c, err := NewCollector(database, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewCollector() error = %v", err)
+	}
+	return c.(*collector), database
+}
+
+func TestCause(t *testing.T) {
+	a := Cause(fmt.Errorf("failed to extract commit 3f2a9c1d8e: object not found after 3 attempts"))
+	b := Cause(fmt.Errorf("failed to extract commit 77be01aa42: object not found after 4 attempts"))
+	if a != b {
+		t.Errorf("Cause() = %q and %q, want the same cause", a, b)
+	}
+	if c := Cause(errors.New("database is locked")); c == a {
+		t.Error("different errors should have different causes")
+	}
+}
+
+func TestCollector_DedupsAndRateLimits(t *testing.T) {
+	c, database := newTestCollector(t)
+	now := time.Now()
+
+	// Repeats of the same cause share an entry and are logged once per interval
+	for i := 0; i < 5; i++ {
+		c.report(SubsystemGit, "/repos/a", fmt.Errorf("failed to open repository: attempt %d", i), now.Add(time.Duration(i)*time.Second))
+	}
+	c.report(SubsystemGit, "/repos/b", errors.New("failed to open repository: attempt 1"), now)
+	if len(c.entries) != 2 {
+		t.Fatalf("entries = %d, want one per subject", len(c.entries))
+	}
+	key := SubsystemGit + "\x00/repos/a\x00" + Cause(errors.New("failed to open repository: attempt 0"))
+	if e := c.entries[key]; e == nil || e.suppressed != 4 || !e.lastLogged.Equal(now) {
+		t.Fatalf("entry = %+v, want 4 repeats suppressed since the first log", e)
+	}
+	c.report(SubsystemGit, "/repos/a", errors.New("failed to open repository: attempt 9"), now.Add(LogInterval))
+	if e := c.entries[key]; e.suppressed != 0 || !e.lastLogged.Equal(now.Add(LogInterval)) {
+		t.Errorf("entry = %+v, want logged again after the interval", e)
+	}
+
+	if err := c.flush(now.Add(LogInterval)); err != nil {
+		t.Fatalf("flush() error = %v", err)
+	}
+	c.report(SubsystemGit, "/repos/a", errors.New("failed to open repository: attempt 10"), now.Add(LogInterval+time.Second))
+	if err := c.flush(now.Add(LogInterval + time.Second)); err != nil {
+		t.Fatalf("flush() error = %v", err)
+	}
+
+	records, err := List(database, 0)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("records = %+v, want two", records)
+	}
+	latest := records[0]
+	if latest.Subject != "/repos/a" || latest.Count != 7 || latest.Message != "failed to open repository: attempt 10" {
+		t.Errorf("latest = %+v, want /repos/a with 7 occurrences and the latest message", latest)
+	}
+
+	// Errors that stopped recurring are forgotten in memory but kept in the database
+	if _, ok := c.entries[SubsystemGit+"\x00/repos/b\x00"+Cause(errors.New("failed to open repository: attempt 1"))]; ok {
+		t.Error("entry not seen within the log interval should be forgotten after a flush")
+	}
+
+	cleared, err := Clear(database)
+	if err != nil || cleared != 2 {
+		t.Errorf("Clear() = %d, %v, want 2", cleared, err)
+	}
+}
@@ -0,0 +1,204 @@
+// Package filters parses named filters: saved queries such as
+// "tag:bugfix AND project:clio" that scope commands the way their --project,
+// --since, and --until flags do.
+package filters
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Filter keys
+const (
+	KeyProject = "project"
+	KeySince   = "since"
+	KeyUntil   = "until"
+	KeyTag     = "tag"
+	KeyMeta    = "meta"
+)
+
+// DateLayout is the date format time values accept
+const DateLayout = "2006-01-02"
+
+// namePattern restricts filter names to what survives config key lowercasing
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Filter is a parsed filter expression. Times are kept as written, since
+// relative values like 7d are resolved when the filter is used.
+type Filter struct {
+	Project string
+	Since   string // A date, RFC 3339 timestamp, or duration like 7d, as --since takes
+	Until   string
+	Tag     string // Goal tag the sessions were tagged with
+	Meta    string // key=value metadata the sessions or their commits carry (see clio meta)
+}
+
+// Parse parses a filter expression: key:value terms joined by spaces or AND.
+// Values with spaces are double-quoted, e.g. project:"my app". Each key may
+// appear once; OR and NOT aren't supported.
+func Parse(expr string) (Filter, error) {
+	terms, err := split(expr)
+	if err != nil {
+		return Filter{}, err
+	}
+
+	var f Filter
+	seen := make(map[string]bool)
+	for _, term := range terms {
+		switch strings.ToUpper(term) {
+		case "AND":
+			continue
+		case "OR", "NOT":
+			return Filter{}, fmt.Errorf("%s isn't supported; terms are always combined with AND", strings.ToUpper(term))
+		}
+
+		key, value, ok := strings.Cut(term, ":")
+		key = strings.ToLower(key)
+		if !ok || value == "" {
+			return Filter{}, fmt.Errorf("term %q is not key:value", term)
+		}
+		if seen[key] {
+			return Filter{}, fmt.Errorf("%s: given more than once", key)
+		}
+		seen[key] = true
+
+		switch key {
+		case KeyProject:
+			f.Project = value
+		case KeySince:
+			f.Since = value
+		case KeyUntil:
+			f.Until = value
+		case KeyTag:
+			f.Tag = strings.ToLower(value)
+		case KeyMeta:
+			metaKey, metaValue, ok := strings.Cut(value, "=")
+			if !ok || metaKey == "" || metaValue == "" {
+				return Filter{}, fmt.Errorf("%s: %q is not key=value", KeyMeta, value)
+			}
+			f.Meta = strings.ToLower(metaKey) + "=" + metaValue
+		default:
+			return Filter{}, fmt.Errorf("unknown key %q (use %s, %s, %s, %s, or %s)", key, KeyProject, KeySince, KeyUntil, KeyTag, KeyMeta)
+		}
+	}
+	if len(seen) == 0 {
+		return Filter{}, fmt.Errorf("filter has no terms")
+	}
+	return f, nil
+}
+
+// split breaks an expression into terms on spaces outside double quotes,
+// removing the quotes
+func split(expr string) ([]string, error) {
+	var terms []string
+	var term strings.Builder
+	quoted, started := false, false
+	for _, r := range expr {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			started = true
+		case !quoted && (r == ' ' || r == '\t' || r == '\n'):
+			if started {
+				terms = append(terms, term.String())
+				term.Reset()
+				started = false
+			}
+		default:
+			term.WriteRune(r)
+			started = true
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if started {
+		terms = append(terms, term.String())
+	}
+	return terms, nil
+}
+
+// ParseTime resolves a since or until value: a date, an RFC 3339 timestamp, or
+// a relative duration before now (e.g. 7d, 12h). Empty values return the zero time.
+func ParseTime(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.ParseInLocation(DateLayout, value, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	// time.ParseDuration has no day unit, so handle it here
+	if strings.HasSuffix(value, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(value, "d"))
+		if err == nil && days >= 0 {
+			return now.AddDate(0, 0, -days), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+
+	return time.Time{}, fmt.Errorf("%q is not a date (%s), RFC 3339 timestamp, or duration (e.g. 7d, 12h)", value, DateLayout)
+}
+
+// String formats the filter as an expression Parse accepts
+func (f Filter) String() string {
+	var terms []string
+	for _, term := range []struct{ key, value string }{
+		{KeyProject, f.Project}, {KeySince, f.Since}, {KeyUntil, f.Until}, {KeyTag, f.Tag}, {KeyMeta, f.Meta},
+	} {
+		if term.value == "" {
+			continue
+		}
+		value := term.value
+		if strings.ContainsAny(value, " \t\n") {
+			value = `"` + value + `"`
+		}
+		terms = append(terms, term.key+":"+value)
+	}
+	return strings.Join(terms, " AND ")
+}
+
+// ValidateName checks that a filter name is usable as a config key
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("invalid filter name %q (use lowercase letters, digits, '-' and '_')", name)
+	}
+	return nil
+}
+
+// Names returns the names of configured filters in sorted order
+func Names(configured map[string]string) []string {
+	names := make([]string, 0, len(configured))
+	for name := range configured {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Lookup parses the configured filter called name
+func Lookup(configured map[string]string, name string) (Filter, error) {
+	expr, ok := configured[strings.ToLower(name)]
+	if !ok {
+		if len(configured) == 0 {
+			return Filter{}, fmt.Errorf("filter %q not found; add one with clio filters add", name)
+		}
+		return Filter{}, fmt.Errorf("filter %q not found (available: %s)", name, strings.Join(Names(configured), ", "))
+	}
+	f, err := Parse(expr)
+	if err != nil {
+		return Filter{}, fmt.Errorf("filter %q: %w", name, err)
+	}
+	return f, nil
+}
@@ -0,0 +1,93 @@
+package filters
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		expr    string
+		want    Filter
+		wantErr bool
+	}{
+		{expr: "tag:bugfix AND project:clio", want: Filter{Tag: "bugfix", Project: "clio"}},
+		{expr: "project:clio since:30d", want: Filter{Project: "clio", Since: "30d"}},
+		{expr: `project:"my app" and TAG:Release until:2026-01-01`, want: Filter{Project: "my app", Tag: "release", Until: "2026-01-01"}},
+		{expr: "meta:Ticket=ABC-12 project:clio", want: Filter{Meta: "ticket=ABC-12", Project: "clio"}},
+		{expr: `meta:"customer=Acme Corp"`, want: Filter{Meta: "customer=Acme Corp"}},
+		{expr: "meta:ticket", wantErr: true},
+		{expr: "meta:=ABC-12", wantErr: true},
+		{expr: "project:clio OR project:web", wantErr: true},
+		{expr: "project:clio project:web", wantErr: true},
+		{expr: "author:me", wantErr: true},
+		{expr: "clio", wantErr: true},
+		{expr: "project:", wantErr: true},
+		{expr: `project:"my app`, wantErr: true},
+		{expr: " AND ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := Parse(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse(%q) error = %v, wantErr %v", tt.expr, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilter_String(t *testing.T) {
+	f := Filter{Project: "my app", Since: "7d", Tag: "bugfix", Meta: "customer=Acme Corp"}
+	if got, want := f.String(), `project:"my app" AND since:7d AND tag:bugfix AND meta:"customer=Acme Corp"`; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if parsed, err := Parse(f.String()); err != nil || parsed != f {
+		t.Errorf("Parse(String()) = %+v, %v, want %+v", parsed, err, f)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	configured := map[string]string{"bugfixes": "tag:bugfix AND project:clio", "broken": "author:me"}
+
+	f, err := Lookup(configured, "BugFixes")
+	if err != nil || f.Tag != "bugfix" {
+		t.Errorf("Lookup(BugFixes) = %+v, %v, want the bugfixes filter", f, err)
+	}
+	if _, err := Lookup(configured, "missing"); err == nil {
+		t.Error("Lookup() of a missing filter should fail")
+	}
+	if _, err := Lookup(configured, "broken"); err == nil {
+		t.Error("Lookup() of an invalid filter should fail")
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{value: "", want: time.Time{}},
+		{value: "2026-03-01", want: time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local)},
+		{value: "2026-03-01T08:30:00Z", want: time.Date(2026, 3, 1, 8, 30, 0, 0, time.UTC)},
+		{value: "7d", want: now.AddDate(0, 0, -7)},
+		{value: "12h", want: now.Add(-12 * time.Hour)},
+		{value: "-3d", wantErr: true},
+		{value: "yesterday", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseTime(tt.value, now)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseTime(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParseTime(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
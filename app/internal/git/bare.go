@@ -0,0 +1,34 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// isBareRepository reports whether dir is a bare or mirror repository: a git
+// directory holding HEAD, objects, and refs itself, without a worktree
+func isBareRepository(dir string) bool {
+	if info, err := os.Stat(filepath.Join(dir, "HEAD")); err != nil || info.IsDir() {
+		return false
+	}
+	for _, sub := range []string{"objects", "refs"} {
+		if info, err := os.Stat(filepath.Join(dir, sub)); err != nil || !info.IsDir() {
+			return false
+		}
+	}
+	return true
+}
+
+// isShallowBoundary reports whether err is a missing object at the edge of a
+// shallow clone's history. Git treats commits there as root commits.
+func isShallowBoundary(repo *git.Repository, err error) bool {
+	if !errors.Is(err, plumbing.ErrObjectNotFound) {
+		return false
+	}
+	shallow, shallowErr := repo.Storer.Shallow()
+	return shallowErr == nil && len(shallow) > 0
+}
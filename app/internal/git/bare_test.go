@@ -0,0 +1,92 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+func TestIsBareRepository(t *testing.T) {
+	tmpDir := t.TempDir()
+	bare := filepath.Join(tmpDir, "mirror.git")
+	createTestGitRepo(t, bare, true)
+	regular := filepath.Join(tmpDir, "repo")
+	createTestGitRepo(t, regular, false)
+
+	if !isBareRepository(bare) {
+		t.Error("expected bare repository to be detected")
+	}
+	if isBareRepository(regular) {
+		t.Error("repository with a worktree should not be detected as bare")
+	}
+	if isBareRepository(tmpDir) {
+		t.Error("plain directory should not be detected as bare")
+	}
+}
+
+func TestShallowClone_TreatsBoundaryAsRoot(t *testing.T) {
+	repoPath := filepath.Join(t.TempDir(), "repo")
+	repo, err := createGitRepoWithCommits(t, repoPath, 3)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("failed to get HEAD: %v", err)
+	}
+	var hashes []plumbing.Hash
+	iter, err := repo.Log(&git.LogOptions{From: head.Hash()})
+	if err != nil {
+		t.Fatalf("failed to get log: %v", err)
+	}
+	for c, err := iter.Next(); err == nil; c, err = iter.Next() {
+		hashes = append(hashes, c.Hash)
+	}
+	iter.Close()
+	if len(hashes) != 3 {
+		t.Fatalf("expected 3 commits, got %d", len(hashes))
+	}
+
+	// Emulate a depth-2 clone: the root commit is missing and its child is shallow
+	root, boundary := hashes[2].String(), hashes[1]
+	if err := os.Remove(filepath.Join(repoPath, ".git", "objects", root[:2], root[2:])); err != nil {
+		t.Fatalf("failed to remove root commit: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repoPath, ".git", "shallow"), []byte(boundary.String()+"\n"), 0644); err != nil {
+		t.Fatalf("failed to write shallow file: %v", err)
+	}
+	repo, err = git.PlainOpen(repoPath)
+	if err != nil {
+		t.Fatalf("failed to reopen repository: %v", err)
+	}
+
+	extractor, err := NewCommitExtractor(logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("failed to create extractor: %v", err)
+	}
+	diff, err := extractor.ExtractDiff(repo, boundary)
+	if err != nil {
+		t.Fatalf("ExtractDiff() at the shallow boundary error = %v", err)
+	}
+	if len(diff.Files) != 1 || diff.Files[0].Additions != 1 {
+		t.Errorf("diff files = %+v, want the tree added as in a root commit", diff.Files)
+	}
+
+	service, err := NewPollerService(&config.Config{Git: config.GitConfig{PollIntervalSeconds: 1}}, logging.NewNoopLogger(), nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create poller: %v", err)
+	}
+	commits, err := service.(*poller).getCommitsBetween(repoPath, "", head.Hash().String())
+	if err != nil {
+		t.Fatalf("getCommitsBetween() error = %v", err)
+	}
+	if len(commits) != 2 {
+		t.Errorf("expected the 2 commits above the boundary, got %d", len(commits))
+	}
+}
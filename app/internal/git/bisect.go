@@ -0,0 +1,103 @@
+package git
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// bisectGoodPrefix names the refs git bisect records good commits under
+const bisectGoodPrefix = "refs/bisect/good-"
+
+// RangeCommit is a commit in a revision range
+type RangeCommit struct {
+	Hash    string
+	Message string
+	Author  string
+	When    time.Time // Committer time
+	Merge   bool
+}
+
+// ResolveCommit resolves a revision such as a hash, abbreviated hash, branch,
+// or HEAD~2 to a commit hash
+func ResolveCommit(repo *git.Repository, revision string) (string, error) {
+	hash, err := repo.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", revision, err)
+	}
+	return hash.String(), nil
+}
+
+// BisectGood returns the commits marked good in the repository's current git
+// bisect session, or none outside one
+func BisectGood(repo *git.Repository) ([]string, error) {
+	refs, err := repo.References()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list references: %w", err)
+	}
+	var good []string
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if strings.HasPrefix(ref.Name().String(), bisectGoodPrefix) {
+			good = append(good, ref.Hash().String())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bisect references: %w", err)
+	}
+	sort.Strings(good)
+	return good, nil
+}
+
+// CommitRange returns the commits reachable from bad but not from any of good,
+// as git rev-list bad --not good... does, oldest first. These are the commits
+// git bisect chooses between.
+func CommitRange(repo *git.Repository, bad string, good []string) ([]RangeCommit, error) {
+	if len(good) == 0 {
+		return nil, fmt.Errorf("at least one good commit is needed")
+	}
+
+	excluded := make(map[plumbing.Hash]bool)
+	for _, hash := range good {
+		start, err := repo.CommitObject(plumbing.NewHash(hash))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get good commit %s: %w", hash, err)
+		}
+		iter := object.NewCommitPreorderIter(start, excluded, nil)
+		err = iter.ForEach(func(c *object.Commit) error {
+			excluded[c.Hash] = true
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to walk history of %s: %w", hash, err)
+		}
+	}
+
+	start, err := repo.CommitObject(plumbing.NewHash(bad))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bad commit %s: %w", bad, err)
+	}
+	var commits []RangeCommit
+	iter := object.NewCommitPreorderIter(start, excluded, nil)
+	err = iter.ForEach(func(c *object.Commit) error {
+		commits = append(commits, RangeCommit{
+			Hash:    c.Hash.String(),
+			Message: c.Message,
+			Author:  c.Author.Name,
+			When:    c.Committer.When,
+			Merge:   c.NumParents() > 1,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk history of %s: %w", bad, err)
+	}
+
+	sort.SliceStable(commits, func(i, j int) bool { return commits[i].When.Before(commits[j].When) })
+	return commits, nil
+}
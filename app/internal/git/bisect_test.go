@@ -0,0 +1,74 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestCommitRange(t *testing.T) {
+	repoPath := filepath.Join(t.TempDir(), "repo")
+	repo, err := git.PlainInit(repoPath, false)
+	if err != nil {
+		t.Fatalf("failed to init repo: %v", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+
+	base := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	var hashes []string
+	for i, message := range []string{"Add a", "Add b", "Add c", "Add d"} {
+		if err := os.WriteFile(filepath.Join(repoPath, "main.go"), []byte(message), 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+		if _, err := worktree.Add("main.go"); err != nil {
+			t.Fatalf("failed to add file: %v", err)
+		}
+		signature := &object.Signature{Name: "Dev", Email: "dev@example.com", When: base.Add(time.Duration(i) * time.Hour)}
+		hash, err := worktree.Commit(message, &git.CommitOptions{Author: signature, Committer: signature})
+		if err != nil {
+			t.Fatalf("failed to commit: %v", err)
+		}
+		hashes = append(hashes, hash.String())
+	}
+
+	bad, err := ResolveCommit(repo, "HEAD")
+	if err != nil || bad != hashes[3] {
+		t.Fatalf("ResolveCommit(HEAD) = %s, %v, want %s", bad, err, hashes[3])
+	}
+	if short, err := ResolveCommit(repo, hashes[1][:8]); err != nil || short != hashes[1] {
+		t.Errorf("ResolveCommit(short hash) = %s, %v, want %s", short, err, hashes[1])
+	}
+
+	commits, err := CommitRange(repo, bad, []string{hashes[1]})
+	if err != nil {
+		t.Fatalf("CommitRange() error = %v", err)
+	}
+	if len(commits) != 2 || commits[0].Hash != hashes[2] || commits[1].Hash != hashes[3] {
+		t.Fatalf("CommitRange() = %+v, want Add c then Add d", commits)
+	}
+	if commits[0].Message != "Add c" || commits[0].Author != "Dev" || !commits[0].When.Equal(base.Add(2*time.Hour)) {
+		t.Errorf("first commit = %+v", commits[0])
+	}
+	if _, err := CommitRange(repo, bad, nil); err == nil {
+		t.Error("CommitRange() without a good commit should fail")
+	}
+
+	if good, err := BisectGood(repo); err != nil || len(good) != 0 {
+		t.Errorf("BisectGood() outside a bisect = %v, %v", good, err)
+	}
+	ref := plumbing.NewHashReference(plumbing.ReferenceName(bisectGoodPrefix+hashes[0]), plumbing.NewHash(hashes[0]))
+	if err := repo.Storer.SetReference(ref); err != nil {
+		t.Fatalf("failed to set bisect ref: %v", err)
+	}
+	if good, err := BisectGood(repo); err != nil || len(good) != 1 || good[0] != hashes[0] {
+		t.Errorf("BisectGood() = %v, %v, want %s", good, err, hashes[0])
+	}
+}
@@ -0,0 +1,48 @@
+package git
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// OpenContaining opens the repository containing path, searching parent
+// directories, and returns it with its worktree root
+func OpenContaining(path string) (*git.Repository, string, error) {
+	repo, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{DetectDotGit: true, EnableDotGitCommonDir: true})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open repository containing %s: %w", path, err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get worktree: %w", err)
+	}
+	return repo, worktree.Filesystem.Root(), nil
+}
+
+// BlameLine returns the hash of the commit that last changed a line (1-based) of
+// file, a path relative to the repository root, as of HEAD
+func BlameLine(repo *git.Repository, file string, line int) (string, error) {
+	if line < 1 {
+		return "", fmt.Errorf("line must be at least 1")
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return "", fmt.Errorf("failed to get HEAD commit: %w", err)
+	}
+
+	blame, err := git.Blame(commit, filepath.ToSlash(file))
+	if err != nil {
+		return "", fmt.Errorf("failed to blame %s: %w", file, err)
+	}
+	if line > len(blame.Lines) {
+		return "", fmt.Errorf("%s has %d lines at HEAD", file, len(blame.Lines))
+	}
+	return blame.Lines[line-1].Hash.String(), nil
+}
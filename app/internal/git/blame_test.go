@@ -0,0 +1,70 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestBlameLine(t *testing.T) {
+	repoPath := filepath.Join(t.TempDir(), "repo")
+	repo, err := git.PlainInit(repoPath, false)
+	if err != nil {
+		t.Fatalf("failed to init repo: %v", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+
+	commit := func(content, message string) string {
+		if err := os.MkdirAll(filepath.Join(repoPath, "pkg"), 0755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(repoPath, "pkg", "main.go"), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+		if _, err := worktree.Add("pkg/main.go"); err != nil {
+			t.Fatalf("failed to add file: %v", err)
+		}
+		hash, err := worktree.Commit(message, &git.CommitOptions{
+			Author: &object.Signature{Name: "Dev", Email: "dev@example.com", When: time.Now()},
+		})
+		if err != nil {
+			t.Fatalf("failed to commit: %v", err)
+		}
+		return hash.String()
+	}
+
+	first := commit("package main\nfunc a() {}\n", "Add a")
+	second := commit("package main\nfunc a() {}\nfunc b() {}\n", "Add b")
+
+	opened, root, err := OpenContaining(filepath.Join(repoPath, "pkg"))
+	if err != nil {
+		t.Fatalf("OpenContaining() error = %v", err)
+	}
+	if root != repoPath {
+		t.Errorf("root = %q, want %q", root, repoPath)
+	}
+
+	for line, want := range map[int]string{2: first, 3: second} {
+		got, err := BlameLine(opened, "pkg/main.go", line)
+		if err != nil {
+			t.Fatalf("BlameLine(%d) error = %v", line, err)
+		}
+		if got != want {
+			t.Errorf("BlameLine(%d) = %s, want %s", line, got, want)
+		}
+	}
+
+	if _, err := BlameLine(opened, "pkg/main.go", 10); err == nil {
+		t.Error("BlameLine() past the end of the file should fail")
+	}
+	if _, _, err := OpenContaining(t.TempDir()); err == nil {
+		t.Error("OpenContaining() outside a repository should fail")
+	}
+}
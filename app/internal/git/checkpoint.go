@@ -0,0 +1,120 @@
+package git
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+// CheckpointStore defines the interface for persisting the poller's last-seen
+// hash per repository, so commits made while the daemon was stopped are detected
+// after a restart
+type CheckpointStore interface {
+	Load() (map[string]string, error)
+	SaveAll(hashes map[string]string) error
+	Prune(keepPaths []string) error
+}
+
+// checkpointStore implements CheckpointStore for database persistence
+type checkpointStore struct {
+	db     *sql.DB
+	logger logging.Logger
+}
+
+// NewCheckpointStore creates a new poller checkpoint store instance
+func NewCheckpointStore(db *sql.DB, logger logging.Logger) (CheckpointStore, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database cannot be nil")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	return &checkpointStore{
+		db:     db,
+		logger: logger.With("component", "poller_checkpoints"),
+	}, nil
+}
+
+// Load returns the last-seen hash of every checkpointed repository, keyed by path
+func (cs *checkpointStore) Load() (map[string]string, error) {
+	rows, err := cs.db.Query("SELECT repository_path, last_seen_hash FROM poller_checkpoints")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query poller checkpoints: %w", err)
+	}
+	defer rows.Close()
+
+	hashes := make(map[string]string)
+	for rows.Next() {
+		var path, hash string
+		if err := rows.Scan(&path, &hash); err != nil {
+			return nil, fmt.Errorf("failed to scan poller checkpoint: %w", err)
+		}
+		hashes[path] = hash
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate poller checkpoints: %w", err)
+	}
+	return hashes, nil
+}
+
+// SaveAll upserts the given checkpoints in a single transaction, so a polling
+// cycle is either checkpointed completely or not at all
+func (cs *checkpointStore) SaveAll(hashes map[string]string) error {
+	if len(hashes) == 0 {
+		return nil
+	}
+
+	tx, err := cs.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin checkpoint transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	now := time.Now()
+	for path, hash := range hashes {
+		_, err := tx.Exec(`
+			INSERT INTO poller_checkpoints (repository_path, last_seen_hash, updated_at)
+			VALUES (?, ?, ?)
+			ON CONFLICT(repository_path) DO UPDATE SET
+				last_seen_hash = excluded.last_seen_hash,
+				updated_at = excluded.updated_at
+		`, path, hash, now)
+		if err != nil {
+			return fmt.Errorf("failed to save poller checkpoint for %s: %w", path, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit poller checkpoints: %w", err)
+	}
+	cs.logger.Debug("saved poller checkpoints", "count", len(hashes))
+	return nil
+}
+
+// Prune removes checkpoints for repositories that are no longer watched
+func (cs *checkpointStore) Prune(keepPaths []string) error {
+	keep := make(map[string]bool, len(keepPaths))
+	for _, path := range keepPaths {
+		keep[path] = true
+	}
+
+	hashes, err := cs.Load()
+	if err != nil {
+		return err
+	}
+	for path := range hashes {
+		if keep[path] {
+			continue
+		}
+		if _, err := cs.db.Exec("DELETE FROM poller_checkpoints WHERE repository_path = ?", path); err != nil {
+			return fmt.Errorf("failed to prune poller checkpoint: %w", err)
+		}
+		cs.logger.Debug("pruned checkpoint for unwatched repository", "repository", path)
+	}
+	return nil
+}
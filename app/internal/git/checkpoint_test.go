@@ -0,0 +1,296 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+func newTestCheckpointStore(t *testing.T) CheckpointStore {
+	t.Helper()
+
+	database, cleanup := setupTestCorrelationDB(t)
+	t.Cleanup(cleanup)
+	database.SetMaxOpenConns(1)
+
+	store, err := NewCheckpointStore(database, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("failed to create checkpoint store: %v", err)
+	}
+	return store
+}
+
+func TestCheckpointStore_SaveLoadPrune(t *testing.T) {
+	store := newTestCheckpointStore(t)
+
+	if err := store.SaveAll(map[string]string{"/repos/a": "aaa", "/repos/b": "bbb"}); err != nil {
+		t.Fatalf("SaveAll() error = %v", err)
+	}
+	if err := store.SaveAll(map[string]string{"/repos/a": "ccc"}); err != nil {
+		t.Fatalf("SaveAll() error = %v", err)
+	}
+	if err := store.Prune([]string{"/repos/a"}); err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+
+	hashes, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(hashes) != 1 || hashes["/repos/a"] != "ccc" {
+		t.Errorf("Load() = %v, want only /repos/a at ccc", hashes)
+	}
+}
+
+func TestPollerService_ResumesFromCheckpoint(t *testing.T) {
+	store := newTestCheckpointStore(t)
+	cfg := &config.Config{Git: config.GitConfig{PollIntervalSeconds: 60}}
+
+	repoPath := filepath.Join(t.TempDir(), "project")
+	gitRepo, err := createGitRepoWithCommits(t, repoPath, 1)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	repos := []Repository{{Path: repoPath, Name: "project"}}
+
+	// First run checkpoints the current HEAD
+	first, err := NewPollerService(cfg, logging.NewNoopLogger(), nil, store)
+	if err != nil {
+		t.Fatalf("failed to create poller: %v", err)
+	}
+	if err := first.Start(context.Background(), repos); err != nil {
+		t.Fatalf("failed to start poller: %v", err)
+	}
+	first.Stop()
+
+	// Commit while the daemon is "down"
+	worktree, _ := gitRepo.Worktree()
+	os.WriteFile(filepath.Join(repoPath, "offline.txt"), []byte("content"), 0644)
+	worktree.Add("offline.txt")
+	missed, err := worktree.Commit("Offline commit", &git.CommitOptions{
+		Author: &object.Signature{Name: "Author", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+
+	// The restarted poller resumes from the checkpoint and reports the missed commit
+	second, err := NewPollerService(cfg, logging.NewNoopLogger(), nil, store)
+	if err != nil {
+		t.Fatalf("failed to create poller: %v", err)
+	}
+	if err := second.Start(context.Background(), repos); err != nil {
+		t.Fatalf("failed to start poller: %v", err)
+	}
+
+	select {
+	case result := <-second.PollResults():
+		if len(result.NewCommits) != 1 || result.NewCommits[0].Hash != missed.String() {
+			t.Errorf("resumed poll commits = %+v, want the offline commit", result.NewCommits)
+		}
+		second.Acknowledge(repoPath, result.Range, true)
+	case <-time.After(2 * time.Second):
+		t.Fatal("restarted poller did not report the commit made while stopped")
+	}
+	second.Stop()
+
+	hashes, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if hashes[repoPath] != missed.String() {
+		t.Errorf("checkpoint = %s, want %s", hashes[repoPath], missed.String())
+	}
+}
+
+func TestPollerService_GapFillIsBounded(t *testing.T) {
+	store := newTestCheckpointStore(t)
+	cfg := &config.Config{Git: config.GitConfig{PollIntervalSeconds: 60, MaxGapFillCommits: 2}}
+
+	repoPath := filepath.Join(t.TempDir(), "project")
+	gitRepo, err := createGitRepoWithCommits(t, repoPath, 1)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	head, _ := gitRepo.Head()
+	if err := store.SaveAll(map[string]string{repoPath: head.Hash().String()}); err != nil {
+		t.Fatalf("SaveAll() error = %v", err)
+	}
+
+	worktree, _ := gitRepo.Worktree()
+	var hashes []string
+	for i := 0; i < 4; i++ {
+		os.WriteFile(filepath.Join(repoPath, "offline.txt"), []byte{byte('a' + i)}, 0644)
+		worktree.Add("offline.txt")
+		hash, err := worktree.Commit("Offline commit", &git.CommitOptions{
+			Author: &object.Signature{Name: "Author", Email: "test@example.com", When: time.Now()},
+		})
+		if err != nil {
+			t.Fatalf("failed to commit: %v", err)
+		}
+		hashes = append(hashes, hash.String())
+	}
+
+	service, err := NewPollerService(cfg, logging.NewNoopLogger(), nil, store)
+	if err != nil {
+		t.Fatalf("failed to create poller: %v", err)
+	}
+	if err := service.Start(context.Background(), []Repository{{Path: repoPath, Name: "project"}}); err != nil {
+		t.Fatalf("failed to start poller: %v", err)
+	}
+
+	select {
+	case result := <-service.PollResults():
+		if len(result.NewCommits) != 2 {
+			t.Fatalf("gap fill commits = %d, want the 2 most recent", len(result.NewCommits))
+		}
+		if result.NewCommits[0].Hash != hashes[3] || result.NewCommits[1].Hash != hashes[2] {
+			t.Errorf("gap fill kept %s, %s; want the newest commits", result.NewCommits[0].Hash, result.NewCommits[1].Hash)
+		}
+		service.Acknowledge(repoPath, result.Range, true)
+	case <-time.After(2 * time.Second):
+		t.Fatal("poller did not fill the startup gap")
+	}
+	service.Stop()
+
+	checkpoints, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if checkpoints[repoPath] != hashes[3] {
+		t.Errorf("checkpoint after gap fill = %s, want HEAD %s", checkpoints[repoPath], hashes[3])
+	}
+}
+
+func TestPollerService_CheckpointsOnlyStoredCommits(t *testing.T) {
+	store := newTestCheckpointStore(t)
+	cfg := &config.Config{Git: config.GitConfig{PollIntervalSeconds: 60}}
+
+	repoPath := filepath.Join(t.TempDir(), "project")
+	gitRepo, err := createGitRepoWithCommits(t, repoPath, 1)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	base, _ := gitRepo.Head()
+	repos := []Repository{{Path: repoPath, Name: "project"}}
+
+	service, err := NewPollerService(cfg, logging.NewNoopLogger(), nil, store)
+	if err != nil {
+		t.Fatalf("failed to create poller: %v", err)
+	}
+	p := service.(*poller)
+	p.pollAllRepositories(repos)
+
+	worktree, _ := gitRepo.Worktree()
+	os.WriteFile(filepath.Join(repoPath, "new.txt"), []byte("content"), 0644)
+	worktree.Add("new.txt")
+	hash, err := worktree.Commit("New commit", &git.CommitOptions{
+		Author: &object.Signature{Name: "Author", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+
+	checkpoint := func() string {
+		hashes, err := store.Load()
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		return hashes[repoPath]
+	}
+
+	// The commit is delivered, but the checkpoint waits until it is stored
+	p.pollAllRepositories(repos)
+	result := <-service.PollResults()
+	if len(result.NewCommits) != 1 || result.Range != (PollRange{From: base.Hash().String(), To: hash.String()}) {
+		t.Fatalf("result = %+v, want the new commit", result)
+	}
+	if got := checkpoint(); got != base.Hash().String() {
+		t.Fatalf("checkpoint before storing = %s, want %s", got, base.Hash())
+	}
+
+	// A result that isn't stored is polled again
+	service.Acknowledge(repoPath, result.Range, false)
+	p.pollAllRepositories(repos)
+	select {
+	case result = <-service.PollResults():
+		if len(result.NewCommits) != 1 || result.NewCommits[0].Hash != hash.String() {
+			t.Fatalf("re-polled commits = %+v, want the unstored commit", result.NewCommits)
+		}
+	default:
+		t.Fatal("unstored commit was not polled again")
+	}
+	if got := checkpoint(); got != base.Hash().String() {
+		t.Errorf("checkpoint after a failed store = %s, want %s", got, base.Hash())
+	}
+
+	service.Acknowledge(repoPath, result.Range, true)
+	if got := checkpoint(); got != hash.String() {
+		t.Errorf("checkpoint after storing = %s, want %s", got, hash)
+	}
+}
+
+func TestPollerService_FullChannelAppliesBackpressure(t *testing.T) {
+	cfg := &config.Config{Git: config.GitConfig{PollIntervalSeconds: 60}}
+
+	repoPath := filepath.Join(t.TempDir(), "project")
+	gitRepo, err := createGitRepoWithCommits(t, repoPath, 1)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	repos := []Repository{{Path: repoPath, Name: "project"}}
+
+	service, err := NewPollerService(cfg, logging.NewNoopLogger(), nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create poller: %v", err)
+	}
+	p := service.(*poller)
+	p.pollAllRepositories(repos)
+
+	worktree, _ := gitRepo.Worktree()
+	os.WriteFile(filepath.Join(repoPath, "new.txt"), []byte("content"), 0644)
+	worktree.Add("new.txt")
+	hash, err := worktree.Commit("New commit", &git.CommitOptions{
+		Author: &object.Signature{Name: "Author", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+
+	// Fill the channel so the next result has to wait for the consumer
+	for i := 0; i < pollResultChanBuffer; i++ {
+		p.pollResults <- PollResult{Repository: Repository{Path: "/repos/other"}}
+	}
+	polled := make(chan struct{})
+	go func() {
+		p.pollAllRepositories(repos)
+		close(polled)
+	}()
+	select {
+	case <-polled:
+		t.Fatal("poll completed with the results channel full, want it to wait")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	var got []PollResult
+	for len(got) <= pollResultChanBuffer {
+		select {
+		case result := <-service.PollResults():
+			got = append(got, result)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("received %d results, want the waiting result delivered", len(got))
+		}
+	}
+	<-polled
+	last := got[len(got)-1]
+	if len(last.NewCommits) != 1 || last.NewCommits[0].Hash != hash.String() {
+		t.Errorf("last result = %+v, want the new commit", last)
+	}
+}
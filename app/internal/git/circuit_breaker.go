@@ -0,0 +1,157 @@
+package git
+
+import (
+	"sync"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+const (
+	// defaultErrorBudget is the number of failures tolerated within the budget window
+	defaultErrorBudget = 5
+	// defaultErrorBudgetWindow is the window in which failures count against the budget
+	defaultErrorBudgetWindow = 10 * time.Minute
+	// defaultCircuitCooldown is how long polling is suspended once the circuit opens
+	defaultCircuitCooldown = 15 * time.Minute
+)
+
+// circuitState is the state of a repository's circuit breaker
+type circuitState int
+
+const (
+	// circuitClosed polls normally
+	circuitClosed circuitState = iota
+	// circuitOpen suspends polling until the cooldown expires
+	circuitOpen
+	// circuitHalfOpen allows a single trial poll after the cooldown
+	circuitHalfOpen
+)
+
+// repoCircuit tracks failures for a single repository
+type repoCircuit struct {
+	state      circuitState
+	failures   []time.Time // Failure times within the budget window
+	openedAt   time.Time
+	suppressed int // Polls skipped while open
+	lastError  error
+}
+
+// circuitBreaker suspends polling of repositories that exhaust their error budget,
+// so a corrupt or unreadable repository produces one warning instead of one per poll
+type circuitBreaker struct {
+	logger   logging.Logger
+	budget   int
+	window   time.Duration
+	cooldown time.Duration
+	mu       sync.Mutex
+	repos    map[string]*repoCircuit
+}
+
+// newCircuitBreaker creates a breaker that opens after budget failures within window
+func newCircuitBreaker(logger logging.Logger, budget int, window, cooldown time.Duration) *circuitBreaker {
+	if budget < 1 {
+		budget = defaultErrorBudget
+	}
+	if window <= 0 {
+		window = defaultErrorBudgetWindow
+	}
+	if cooldown <= 0 {
+		cooldown = defaultCircuitCooldown
+	}
+
+	return &circuitBreaker{
+		logger:   logger,
+		budget:   budget,
+		window:   window,
+		cooldown: cooldown,
+		repos:    make(map[string]*repoCircuit),
+	}
+}
+
+// allow reports whether a repository may be polled, moving an open circuit to
+// half-open once its cooldown has expired
+func (cb *circuitBreaker) allow(path string, now time.Time) bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	circuit, ok := cb.repos[path]
+	if !ok {
+		return true
+	}
+
+	switch circuit.state {
+	case circuitOpen:
+		if now.Sub(circuit.openedAt) < cb.cooldown {
+			circuit.suppressed++
+			return false
+		}
+		circuit.state = circuitHalfOpen
+		cb.logger.Debug("circuit half-open, trying repository again", "repository", path)
+		return true
+	default:
+		return true
+	}
+}
+
+// recordSuccess closes the circuit and clears the repository's failures
+func (cb *circuitBreaker) recordSuccess(path string) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	circuit, ok := cb.repos[path]
+	if !ok {
+		return
+	}
+	if circuit.state != circuitClosed {
+		cb.logger.Info("circuit closed, repository polling resumed", "repository", path, "suppressed_polls", circuit.suppressed)
+	}
+	delete(cb.repos, path)
+}
+
+// recordFailure counts a failure against the repository's error budget and
+// reports whether the circuit is now open
+func (cb *circuitBreaker) recordFailure(path string, err error, now time.Time) bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	circuit, ok := cb.repos[path]
+	if !ok {
+		circuit = &repoCircuit{}
+		cb.repos[path] = circuit
+	}
+	circuit.lastError = err
+
+	// A failed trial poll reopens the circuit without another warning
+	if circuit.state == circuitHalfOpen {
+		circuit.state = circuitOpen
+		circuit.openedAt = now
+		cb.logger.Debug("circuit reopened after failed trial poll", "repository", path, "error", err)
+		return true
+	}
+
+	// Drop failures that have aged out of the budget window
+	cutoff := now.Add(-cb.window)
+	recent := circuit.failures[:0]
+	for _, failedAt := range circuit.failures {
+		if failedAt.After(cutoff) {
+			recent = append(recent, failedAt)
+		}
+	}
+	circuit.failures = append(recent, now)
+
+	if len(circuit.failures) < cb.budget {
+		return false
+	}
+
+	circuit.state = circuitOpen
+	circuit.openedAt = now
+	cb.logger.Warn("repository exhausted its error budget, suspending polling",
+		"repository", path,
+		"failures", len(circuit.failures),
+		"window", cb.window,
+		"cooldown", cb.cooldown,
+		"last_error", err,
+	)
+	return true
+}
@@ -0,0 +1,79 @@
+package git
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+func TestCircuitBreaker_OpensAfterBudget(t *testing.T) {
+	breaker := newCircuitBreaker(logging.NewNoopLogger(), 3, time.Minute, 10*time.Minute)
+	path := "/repos/broken"
+	errCorrupt := errors.New("object not found")
+	now := time.Now()
+
+	for i := 0; i < 2; i++ {
+		if opened := breaker.recordFailure(path, errCorrupt, now.Add(time.Duration(i)*time.Second)); opened {
+			t.Fatalf("circuit opened after %d failures, budget is 3", i+1)
+		}
+	}
+	if !breaker.allow(path, now) {
+		t.Fatal("circuit should stay closed within budget")
+	}
+
+	if opened := breaker.recordFailure(path, errCorrupt, now.Add(2*time.Second)); !opened {
+		t.Fatal("circuit should open once the budget is exhausted")
+	}
+	if breaker.allow(path, now.Add(5*time.Minute)) {
+		t.Error("open circuit should suspend polling during cooldown")
+	}
+	if got := breaker.repos[path].suppressed; got != 1 {
+		t.Errorf("suppressed = %d, want 1", got)
+	}
+
+	// After cooldown a single trial poll is allowed; failing it reopens the circuit
+	trialTime := now.Add(11 * time.Minute)
+	if !breaker.allow(path, trialTime) {
+		t.Fatal("circuit should allow a trial poll after cooldown")
+	}
+	if opened := breaker.recordFailure(path, errCorrupt, trialTime); !opened {
+		t.Error("failed trial poll should reopen the circuit")
+	}
+	if breaker.allow(path, trialTime.Add(time.Minute)) {
+		t.Error("reopened circuit should suspend polling")
+	}
+
+	// A successful trial poll closes the circuit
+	recoverTime := trialTime.Add(11 * time.Minute)
+	if !breaker.allow(path, recoverTime) {
+		t.Fatal("circuit should allow a trial poll after cooldown")
+	}
+	breaker.recordSuccess(path)
+	if _, ok := breaker.repos[path]; ok {
+		t.Error("successful poll should clear the circuit")
+	}
+}
+
+func TestCircuitBreaker_FailuresOutsideWindowDontCount(t *testing.T) {
+	breaker := newCircuitBreaker(logging.NewNoopLogger(), 2, time.Minute, time.Minute)
+	path := "/repos/flaky"
+	now := time.Now()
+
+	breaker.recordFailure(path, errors.New("locked"), now)
+	if opened := breaker.recordFailure(path, errors.New("locked"), now.Add(2*time.Minute)); opened {
+		t.Error("failures spread beyond the budget window should not open the circuit")
+	}
+	if opened := breaker.recordFailure(path, errors.New("locked"), now.Add(2*time.Minute+time.Second)); !opened {
+		t.Error("two failures within the window should open the circuit")
+	}
+}
+
+func TestNewCircuitBreaker_Defaults(t *testing.T) {
+	breaker := newCircuitBreaker(logging.NewNoopLogger(), 0, 0, 0)
+	if breaker.budget != defaultErrorBudget || breaker.window != defaultErrorBudgetWindow || breaker.cooldown != defaultCircuitCooldown {
+		t.Errorf("defaults = (%d, %v, %v), want (%d, %v, %v)", breaker.budget, breaker.window, breaker.cooldown,
+			defaultErrorBudget, defaultErrorBudgetWindow, defaultCircuitCooldown)
+	}
+}
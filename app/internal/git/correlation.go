@@ -2,13 +2,16 @@ package git
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"math"
 	"net/url"
 	"path/filepath"
 	"regexp"
 	"strings"
 	"time"
 
+	"github.com/stwalsh4118/clio/internal/blobs"
 	"github.com/stwalsh4118/clio/internal/cursor"
 	"github.com/stwalsh4118/clio/internal/logging"
 )
@@ -20,23 +23,43 @@ const (
 	maxProjectNameLength = 255
 	// defaultProjectName is returned when project name cannot be determined
 	defaultProjectName = "unknown"
+
+	// timeConfidenceWeight is the share of confidence from time distance to the nearest message
+	timeConfidenceWeight = 0.5
+	// fileOverlapConfidenceWeight is the share of confidence from commit files mentioned in the session
+	fileOverlapConfidenceWeight = 0.3
+	// activityConfidenceWeight is the share of confidence from session activity around the commit
+	activityConfidenceWeight = 0.2
+	// activitySaturationMessages is the number of messages within the window that counts as fully active
+	activitySaturationMessages = 5
 )
 
 // CorrelationService defines the interface for correlating commits with sessions
 type CorrelationService interface {
 	CorrelateCommit(commit CommitMetadata, repository Repository, sessionManager cursor.SessionManager) (*CommitSessionCorrelation, error)
+	CorrelateCommitWithOptions(commit CommitMetadata, repository Repository, sessionManager cursor.SessionManager, opts CorrelationOptions) (*CommitSessionCorrelation, error)
 	CorrelateCommits(commits []CommitMetadata, repository Repository, sessionManager cursor.SessionManager) ([]CommitSessionCorrelation, error)
 	GroupCommitsBySession(correlations []CommitSessionCorrelation) (map[string][]CommitSessionCorrelation, error)
 }
 
+// CorrelationOptions adjusts how a commit is matched to sessions
+type CorrelationOptions struct {
+	// PostSessionWindow also matches sessions that ended up to this long before the
+	// commit, as "post_session" (e.g. a commit made after testing). Zero disables it.
+	PostSessionWindow time.Duration
+}
+
 // correlationService implements CorrelationService
 type correlationService struct {
-	logger logging.Logger
-	db     *sql.DB
+	logger   logging.Logger
+	db       *sql.DB
+	blobs    blobs.Store // Nil matches messages moved to the blob store by their previews
+	projects cursor.ProjectNamer
 }
 
-// NewCorrelationService creates a new correlation service instance
-func NewCorrelationService(logger logging.Logger, db *sql.DB) (CorrelationService, error) {
+// NewCorrelationService creates a new correlation service instance. blobStore
+// loads messages moved there so they're matched in full; it may be nil.
+func NewCorrelationService(logger logging.Logger, db *sql.DB, blobStore blobs.Store) (CorrelationService, error) {
 	if logger == nil {
 		return nil, fmt.Errorf("logger cannot be nil")
 	}
@@ -44,14 +67,26 @@ func NewCorrelationService(logger logging.Logger, db *sql.DB) (CorrelationServic
 		return nil, fmt.Errorf("database cannot be nil")
 	}
 
+	projects, err := cursor.NewProjectNamer(db, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create project namer: %w", err)
+	}
+
 	return &correlationService{
-		logger: logger.With("component", "git_correlation"),
-		db:     db,
+		logger:   logger.With("component", "git_correlation"),
+		db:       db,
+		blobs:    blobStore,
+		projects: projects,
 	}, nil
 }
 
 // CorrelateCommit correlates a single commit with sessions
 func (cs *correlationService) CorrelateCommit(commit CommitMetadata, repository Repository, sessionManager cursor.SessionManager) (*CommitSessionCorrelation, error) {
+	return cs.CorrelateCommitWithOptions(commit, repository, sessionManager, CorrelationOptions{})
+}
+
+// CorrelateCommitWithOptions correlates a single commit with sessions using opts
+func (cs *correlationService) CorrelateCommitWithOptions(commit CommitMetadata, repository Repository, sessionManager cursor.SessionManager, opts CorrelationOptions) (*CommitSessionCorrelation, error) {
 	cs.logger.Debug("correlating commit with sessions", "commit", commit.Hash, "repository", repository.Path)
 
 	// Validate commit timestamp
@@ -60,7 +95,7 @@ func (cs *correlationService) CorrelateCommit(commit CommitMetadata, repository
 		return &CommitSessionCorrelation{
 			CommitHash:      commit.Hash,
 			SessionID:       "",
-			Project:         cs.normalizeProjectName(repository.Path),
+			Project:         cs.projects.ProjectName(repository.Path),
 			CorrelationType: "none",
 			TimeDiff:        0,
 		}, nil
@@ -71,14 +106,14 @@ func (cs *correlationService) CorrelateCommit(commit CommitMetadata, repository
 		return &CommitSessionCorrelation{
 			CommitHash:      commit.Hash,
 			SessionID:       "",
-			Project:         cs.normalizeProjectName(repository.Path),
+			Project:         cs.projects.ProjectName(repository.Path),
 			CorrelationType: "none",
 			TimeDiff:        0,
 		}, nil
 	}
 
 	// Normalize repository path to project name
-	projectName := cs.normalizeProjectName(repository.Path)
+	projectName := cs.projects.ProjectName(repository.Path)
 	cs.logger.Debug("normalized project name", "repository_path", repository.Path, "project_name", projectName)
 
 	// Get all sessions (active + ended) from database
@@ -110,7 +145,7 @@ func (cs *correlationService) CorrelateCommit(commit CommitMetadata, repository
 	cs.logger.Debug("found matching sessions", "project", projectName, "matching_count", len(matchingSessions), "total_sessions", len(sessions))
 
 	// Find best matching session
-	bestMatch := cs.findBestMatchingSession(commit, matchingSessions)
+	bestMatch := cs.findBestMatchingSession(commit, matchingSessions, opts.PostSessionWindow)
 	if bestMatch == nil {
 		cs.logger.Debug("no matching session found for commit", "commit", commit.Hash, "project", projectName, "matching_sessions", len(matchingSessions))
 		return &CommitSessionCorrelation{
@@ -210,7 +245,18 @@ func (cs *correlationService) getAllSessions(sessionManager cursor.SessionManage
 			session.EndTime = &endTime.Time
 		}
 
-		// Load conversations for this session
+		sessions = append(sessions, &session)
+	}
+
+	if err := rows.Err(); err != nil {
+		cs.logger.Error("error iterating sessions", "error", err)
+		return nil, fmt.Errorf("error iterating sessions: %w", err)
+	}
+	// Release the connection before issuing nested queries
+	rows.Close()
+
+	// Load conversations for each session
+	for _, session := range sessions {
 		conversations, err := cs.getConversationsForSession(session.ID)
 		if err != nil {
 			cs.logger.Warn("failed to load conversations for session, using empty slice", "session_id", session.ID, "error", err)
@@ -219,13 +265,6 @@ func (cs *correlationService) getAllSessions(sessionManager cursor.SessionManage
 			session.Conversations = conversations
 			cs.logger.Debug("loaded conversations for session", "session_id", session.ID, "conversation_count", len(conversations))
 		}
-
-		sessions = append(sessions, &session)
-	}
-
-	if err := rows.Err(); err != nil {
-		cs.logger.Error("error iterating sessions", "error", err)
-		return nil, fmt.Errorf("error iterating sessions: %w", err)
 	}
 
 	cs.logger.Debug("loaded all sessions from database", "session_count", len(sessions))
@@ -256,6 +295,7 @@ func (cs *correlationService) getConversationsForSession(sessionID string) ([]*c
 	defer rows.Close()
 
 	var conversations []*cursor.Conversation
+	var conversationIDs []string
 
 	for rows.Next() {
 		var conv cursor.Conversation
@@ -280,23 +320,28 @@ func (cs *correlationService) getConversationsForSession(sessionID string) ([]*c
 			conv.CreatedAt = firstMsgTime.Time
 		}
 
-		// Load messages for this conversation (conversation_id references conversations.id)
-		messages, err := cs.getMessagesForConversation(conversationID)
-		if err != nil {
-			cs.logger.Warn("failed to load messages for conversation, using empty slice", "composer_id", conv.ComposerID, "conversation_id", conversationID, "error", err)
-			conv.Messages = []cursor.Message{}
-		} else {
-			conv.Messages = messages
-			cs.logger.Debug("loaded messages for conversation", "composer_id", conv.ComposerID, "message_count", len(messages))
-		}
-
 		conversations = append(conversations, &conv)
+		conversationIDs = append(conversationIDs, conversationID)
 	}
 
 	if err := rows.Err(); err != nil {
 		cs.logger.Error("error iterating conversations", "session_id", sessionID, "error", err)
 		return nil, fmt.Errorf("error iterating conversations: %w", err)
 	}
+	// Release the connection before issuing nested queries
+	rows.Close()
+
+	// Load messages for each conversation (conversation_id references conversations.id)
+	for i, conv := range conversations {
+		messages, err := cs.getMessagesForConversation(conversationIDs[i])
+		if err != nil {
+			cs.logger.Warn("failed to load messages for conversation, using empty slice", "composer_id", conv.ComposerID, "conversation_id", conversationIDs[i], "error", err)
+			conv.Messages = []cursor.Message{}
+		} else {
+			conv.Messages = messages
+			cs.logger.Debug("loaded messages for conversation", "composer_id", conv.ComposerID, "message_count", len(messages))
+		}
+	}
 
 	cs.logger.Debug("loaded conversations for session", "session_id", sessionID, "conversation_count", len(conversations))
 	return conversations, nil
@@ -306,7 +351,7 @@ func (cs *correlationService) getConversationsForSession(sessionID string) ([]*c
 // conversationID is the composer_id (which is also the conversation id in the conversations table)
 func (cs *correlationService) getMessagesForConversation(conversationID string) ([]cursor.Message, error) {
 	query := `
-		SELECT bubble_id, type, role, content, thinking_text, code_blocks, tool_calls,
+		SELECT bubble_id, type, role, content, content_blob, thinking_text, code_blocks, tool_calls,
 			has_code, has_thinking, has_tool_calls, content_source, created_at
 		FROM messages
 		WHERE conversation_id = ?
@@ -324,7 +369,7 @@ func (cs *correlationService) getMessagesForConversation(conversationID string)
 
 	for rows.Next() {
 		var msg cursor.Message
-		var thinkingText, codeBlocks, toolCalls sql.NullString
+		var contentBlob, thinkingText, codeBlocks, toolCalls sql.NullString
 		var hasCode, hasThinking, hasToolCalls int
 
 		err := rows.Scan(
@@ -332,6 +377,7 @@ func (cs *correlationService) getMessagesForConversation(conversationID string)
 			&msg.Type,
 			&msg.Role,
 			&msg.Text,
+			&contentBlob,
 			&thinkingText,
 			&codeBlocks,
 			&toolCalls,
@@ -345,10 +391,19 @@ func (cs *correlationService) getMessagesForConversation(conversationID string)
 			cs.logger.Warn("failed to scan message row, skipping", "conversation_id", conversationID, "error", err)
 			continue
 		}
+		if msg.Text, err = blobs.Resolve(cs.blobs, msg.Text, contentBlob); err != nil {
+			cs.logger.Debug("failed to load message blob, matching the stored preview", "conversation_id", conversationID, "bubble_id", msg.BubbleID, "error", err)
+		}
 
 		if thinkingText.Valid {
 			msg.ThinkingText = thinkingText.String
 		}
+		// Code blocks are used to match commit files; a malformed value only weakens the score
+		if codeBlocks.Valid && codeBlocks.String != "" {
+			if err := json.Unmarshal([]byte(codeBlocks.String), &msg.CodeBlocks); err != nil {
+				cs.logger.Debug("failed to unmarshal code blocks, ignoring", "conversation_id", conversationID, "bubble_id", msg.BubbleID, "error", err)
+			}
+		}
 		// Set boolean flags from integer values
 		msg.HasCode = hasCode == 1
 		msg.HasToolCalls = hasToolCalls == 1
@@ -361,6 +416,9 @@ func (cs *correlationService) getMessagesForConversation(conversationID string)
 		cs.logger.Error("error iterating messages", "conversation_id", conversationID, "error", err)
 		return nil, fmt.Errorf("error iterating messages: %w", err)
 	}
+	if err := cursor.ResolveSharedCodeBlocks(cs.db, messages); err != nil {
+		cs.logger.Debug("failed to resolve shared code blocks, ignoring", "conversation_id", conversationID, "error", err)
+	}
 
 	cs.logger.Debug("loaded messages for conversation", "conversation_id", conversationID, "message_count", len(messages))
 	return messages, nil
@@ -381,8 +439,9 @@ func (cs *correlationService) filterSessionsByProject(sessions []*cursor.Session
 	return matching
 }
 
-// findBestMatchingSession finds the best matching session for a commit
-func (cs *correlationService) findBestMatchingSession(commit CommitMetadata, sessions []*cursor.Session) *CommitSessionCorrelation {
+// findBestMatchingSession finds the best matching session for a commit.
+// postSessionWindow > 0 also matches sessions that ended shortly before the commit.
+func (cs *correlationService) findBestMatchingSession(commit CommitMetadata, sessions []*cursor.Session, postSessionWindow time.Duration) *CommitSessionCorrelation {
 	cs.logger.Debug("finding best matching session", "commit", commit.Hash, "commit_time", commit.Timestamp, "session_count", len(sessions))
 	var bestMatch *CommitSessionCorrelation
 	var bestTimeDiff time.Duration = time.Duration(1<<63 - 1) // Max duration
@@ -405,6 +464,7 @@ func (cs *correlationService) findBestMatchingSession(commit CommitMetadata, ses
 		// Find minimum time difference to any message in this session
 		minTimeDiff := time.Duration(1<<63 - 1)
 		foundWithinWindow := false
+		messagesInWindow := 0
 
 		for _, conv := range session.Conversations {
 			for _, msg := range conv.Messages {
@@ -420,6 +480,7 @@ func (cs *correlationService) findBestMatchingSession(commit CommitMetadata, ses
 				// Check if within correlation window
 				if diff <= correlationWindow {
 					foundWithinWindow = true
+					messagesInWindow++
 				}
 			}
 		}
@@ -428,30 +489,34 @@ func (cs *correlationService) findBestMatchingSession(commit CommitMetadata, ses
 		correlationType := "none"
 		isWithinSessionWindow := commitTime.After(session.StartTime) && commitTime.Before(sessionEnd.Add(time.Second))
 
+		sinceEnd := commitTime.Sub(sessionEnd)
+
 		if isWithinSessionWindow && foundWithinWindow {
 			correlationType = "active"
 		} else if foundWithinWindow {
 			correlationType = "proximate"
+		} else if postSessionWindow > 0 && sinceEnd > 0 && sinceEnd <= postSessionWindow {
+			correlationType = "post_session"
 		}
 
-		// Select best match: prefer "active" over "proximate" over "none"
+		// Select best match: prefer "active" over "proximate" over "post_session" over "none"
 		// For same type, prefer closer timestamp
-		isBetter := false
-		if correlationType == "active" && (bestType != "active" || minTimeDiff < bestTimeDiff) {
-			isBetter = true
-		} else if correlationType == "proximate" && bestType == "none" {
-			isBetter = true
-		} else if correlationType == "proximate" && bestType == "proximate" && minTimeDiff < bestTimeDiff {
-			isBetter = true
-		}
+		isBetter := correlationRank[correlationType] > correlationRank[bestType] ||
+			(correlationType != "none" && correlationType == bestType && minTimeDiff < bestTimeDiff)
 
 		if isBetter {
+			confidence := cs.scoreCorrelation(commit, session, minTimeDiff, messagesInWindow, isWithinSessionWindow, correlationWindow)
+			if correlationType == "post_session" {
+				// Score time distance from the session's end against the extension window
+				confidence = cs.scoreCorrelation(commit, session, sinceEnd, 0, false, postSessionWindow)
+			}
 			bestMatch = &CommitSessionCorrelation{
 				CommitHash:      commit.Hash,
 				SessionID:       session.ID,
 				Project:         session.Project,
 				CorrelationType: correlationType,
 				TimeDiff:        minTimeDiff,
+				Confidence:      confidence,
 			}
 			bestTimeDiff = minTimeDiff
 			bestType = correlationType
@@ -461,6 +526,65 @@ func (cs *correlationService) findBestMatchingSession(commit CommitMetadata, ses
 	return bestMatch
 }
 
+// correlationRank orders correlation types from weakest to strongest
+var correlationRank = map[string]int{
+	"none":         0,
+	"post_session": 1,
+	"proximate":    2,
+	"active":       3,
+}
+
+// scoreCorrelation combines time distance (relative to window), file-path overlap, and
+// session activity into a confidence in [0, 1]. When the commit's files are unknown, the
+// file-overlap weight is redistributed across the other signals.
+func (cs *correlationService) scoreCorrelation(commit CommitMetadata, session *cursor.Session, timeDiff time.Duration, messagesInWindow int, withinSessionWindow bool, window time.Duration) float64 {
+	timeScore := 1 - float64(timeDiff)/float64(window)
+	timeScore = math.Max(0, math.Min(1, timeScore))
+
+	activityScore := 0.5 * math.Min(1, float64(messagesInWindow)/activitySaturationMessages)
+	if withinSessionWindow {
+		activityScore += 0.5
+	}
+
+	score := timeConfidenceWeight*timeScore + activityConfidenceWeight*activityScore
+	totalWeight := timeConfidenceWeight + activityConfidenceWeight
+	if len(commit.FilePaths) > 0 {
+		score += fileOverlapConfidenceWeight * fileOverlapScore(commit.FilePaths, session)
+		totalWeight += fileOverlapConfidenceWeight
+	}
+
+	// Round to keep stored values readable
+	return math.Round(score/totalWeight*1000) / 1000
+}
+
+// fileOverlapScore returns the fraction of commit files mentioned in the session's messages
+func fileOverlapScore(filePaths []string, session *cursor.Session) float64 {
+	var corpus strings.Builder
+	for _, conv := range session.Conversations {
+		for _, msg := range conv.Messages {
+			corpus.WriteString(msg.Text)
+			corpus.WriteString("\n")
+			corpus.WriteString(msg.ThinkingText)
+			corpus.WriteString("\n")
+			for _, block := range msg.CodeBlocks {
+				corpus.WriteString(block.Content)
+				corpus.WriteString("\n")
+			}
+		}
+	}
+	text := corpus.String()
+
+	matched := 0
+	for _, path := range filePaths {
+		// Conversations usually mention files by name rather than full repository path
+		if strings.Contains(text, path) || strings.Contains(text, filepath.Base(path)) {
+			matched++
+		}
+	}
+
+	return float64(matched) / float64(len(filePaths))
+}
+
 // normalizeProjectName normalizes a project path or name to a filesystem-safe project name
 // This matches the logic from cursor.ProjectDetector.NormalizeProjectName
 func (cs *correlationService) normalizeProjectName(name string) string {
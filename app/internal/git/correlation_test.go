@@ -2,9 +2,12 @@ package git
 
 import (
 	"database/sql"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/stwalsh4118/clio/internal/blobs"
 	"github.com/stwalsh4118/clio/internal/config"
 	"github.com/stwalsh4118/clio/internal/cursor"
 	"github.com/stwalsh4118/clio/internal/db"
@@ -115,7 +118,7 @@ func TestCorrelateCommit_ProximateSession(t *testing.T) {
 	defer cleanup()
 
 	logger := logging.NewNoopLogger()
-	service, err := NewCorrelationService(logger, database)
+	service, err := NewCorrelationService(logger, database, nil)
 	if err != nil {
 		t.Fatalf("failed to create correlation service: %v", err)
 	}
@@ -187,7 +190,7 @@ func TestCorrelateCommit_NoCorrelation(t *testing.T) {
 	defer cleanup()
 
 	logger := logging.NewNoopLogger()
-	service, err := NewCorrelationService(logger, database)
+	service, err := NewCorrelationService(logger, database, nil)
 	if err != nil {
 		t.Fatalf("failed to create correlation service: %v", err)
 	}
@@ -238,7 +241,7 @@ func TestCorrelateCommit_ProjectMatching(t *testing.T) {
 	defer cleanup()
 
 	logger := logging.NewNoopLogger()
-	service, err := NewCorrelationService(logger, database)
+	service, err := NewCorrelationService(logger, database, nil)
 	if err != nil {
 		t.Fatalf("failed to create correlation service: %v", err)
 	}
@@ -303,7 +306,7 @@ func TestCorrelateCommits_MultipleCommits(t *testing.T) {
 	defer cleanup()
 
 	logger := logging.NewNoopLogger()
-	service, err := NewCorrelationService(logger, database)
+	service, err := NewCorrelationService(logger, database, nil)
 	if err != nil {
 		t.Fatalf("failed to create correlation service: %v", err)
 	}
@@ -374,7 +377,7 @@ func TestCorrelateCommits_MultipleCommits(t *testing.T) {
 func TestGroupCommitsBySession(t *testing.T) {
 	logger := logging.NewNoopLogger()
 	database, _ := setupTestCorrelationDB(t)
-	service, err := NewCorrelationService(logger, database)
+	service, err := NewCorrelationService(logger, database, nil)
 	if err != nil {
 		t.Fatalf("failed to create correlation service: %v", err)
 	}
@@ -435,7 +438,7 @@ func TestGroupCommitsBySession(t *testing.T) {
 func TestNormalizeProjectName(t *testing.T) {
 	logger := logging.NewNoopLogger()
 	database, _ := setupTestCorrelationDB(t)
-	service, err := NewCorrelationService(logger, database)
+	service, err := NewCorrelationService(logger, database, nil)
 	if err != nil {
 		t.Fatalf("failed to create correlation service: %v", err)
 	}
@@ -506,3 +509,165 @@ func createMockSessionManager(t *testing.T, database *sql.DB) cursor.SessionMana
 	return sm
 }
 
+
+func TestCorrelateCommit_Confidence(t *testing.T) {
+	database, cleanup := setupTestCorrelationDB(t)
+	defer cleanup()
+
+	logger := logging.NewNoopLogger()
+	service, err := NewCorrelationService(logger, database, nil)
+	if err != nil {
+		t.Fatalf("failed to create correlation service: %v", err)
+	}
+
+	sessionManager := createMockSessionManager(t, database)
+
+	now := time.Now()
+	session := createTestSession(t, database, "session-1", "my-project", now.Add(-1*time.Hour), now.Add(30*time.Minute))
+	messages := []cursor.Message{
+		{
+			BubbleID:  "msg-1",
+			Type:      1,
+			Role:      "user",
+			Text:      "Please fix the retry loop in poller.go",
+			CreatedAt: now.Add(-1 * time.Minute),
+		},
+	}
+	createTestConversation(t, database, "conv-1", session.ID, messages)
+
+	repository := Repository{
+		Path: "/home/user/my-project",
+		Name: "my-project",
+	}
+
+	discussed := CommitMetadata{Hash: "abc123", Timestamp: now, FilePaths: []string{"internal/git/poller.go"}}
+	unrelated := CommitMetadata{Hash: "def456", Timestamp: now, FilePaths: []string{"README.md"}}
+
+	discussedCorrelation, err := service.CorrelateCommit(discussed, repository, sessionManager)
+	if err != nil {
+		t.Fatalf("failed to correlate commit: %v", err)
+	}
+	unrelatedCorrelation, err := service.CorrelateCommit(unrelated, repository, sessionManager)
+	if err != nil {
+		t.Fatalf("failed to correlate commit: %v", err)
+	}
+
+	for _, correlation := range []*CommitSessionCorrelation{discussedCorrelation, unrelatedCorrelation} {
+		if correlation.CorrelationType != "active" {
+			t.Errorf("commit %s: expected correlation type 'active', got '%s'", correlation.CommitHash, correlation.CorrelationType)
+		}
+		if correlation.Confidence <= 0 || correlation.Confidence > 1 {
+			t.Errorf("commit %s: confidence %v out of range (0, 1]", correlation.CommitHash, correlation.Confidence)
+		}
+	}
+
+	// File overlap with the conversation raises confidence
+	if discussedCorrelation.Confidence <= unrelatedCorrelation.Confidence {
+		t.Errorf("expected discussed commit confidence %v > unrelated commit confidence %v", discussedCorrelation.Confidence, unrelatedCorrelation.Confidence)
+	}
+
+	// Uncorrelated commits have zero confidence
+	distant := CommitMetadata{Hash: "ghi789", Timestamp: now.Add(-3 * time.Hour)}
+	distantCorrelation, err := service.CorrelateCommit(distant, repository, sessionManager)
+	if err != nil {
+		t.Fatalf("failed to correlate commit: %v", err)
+	}
+	if distantCorrelation.CorrelationType != "none" || distantCorrelation.Confidence != 0 {
+		t.Errorf("expected no correlation with zero confidence, got '%s' (%v)", distantCorrelation.CorrelationType, distantCorrelation.Confidence)
+	}
+}
+
+func TestCorrelateCommit_ResolvesBlobs(t *testing.T) {
+	database, cleanup := setupTestCorrelationDB(t)
+	defer cleanup()
+
+	logger := logging.NewNoopLogger()
+	blobStore, err := blobs.NewStore(filepath.Join(t.TempDir(), "blobs"), logger)
+	if err != nil {
+		t.Fatalf("failed to create blob store: %v", err)
+	}
+	sessionManager := createMockSessionManager(t, database)
+
+	now := time.Now()
+	session := createTestSession(t, database, "session-1", "my-project", now.Add(-1*time.Hour), now.Add(30*time.Minute))
+	long := strings.Repeat("trace line\n", blobs.PreviewLength/11+1) + "The retry loop in poller.go never backs off"
+	createTestConversation(t, database, "conv-1", session.ID, []cursor.Message{
+		{BubbleID: "msg-1", Type: 1, Role: "user", Text: long, CreatedAt: now.Add(-1 * time.Minute)},
+	})
+
+	// The file is only named past the preview left in the row
+	ref, err := blobStore.Put([]byte(long))
+	if err != nil {
+		t.Fatalf("failed to store blob: %v", err)
+	}
+	if _, err := database.Exec("UPDATE messages SET content = ?, content_blob = ? WHERE id = 'msg-1'", blobs.Preview(long), ref); err != nil {
+		t.Fatalf("failed to move message to the blob store: %v", err)
+	}
+
+	repository := Repository{Path: "/home/user/my-project", Name: "my-project"}
+	commit := CommitMetadata{Hash: "abc123", Timestamp: now, FilePaths: []string{"internal/git/poller.go"}}
+	confidence := func(store blobs.Store) float64 {
+		t.Helper()
+		service, err := NewCorrelationService(logger, database, store)
+		if err != nil {
+			t.Fatalf("failed to create correlation service: %v", err)
+		}
+		correlation, err := service.CorrelateCommit(commit, repository, sessionManager)
+		if err != nil {
+			t.Fatalf("failed to correlate commit: %v", err)
+		}
+		return correlation.Confidence
+	}
+
+	if full, preview := confidence(blobStore), confidence(nil); full <= preview {
+		t.Errorf("confidence with the full message = %v, want more than %v from its preview", full, preview)
+	}
+}
+
+func TestCorrelateCommitWithOptions_PostSession(t *testing.T) {
+	database, cleanup := setupTestCorrelationDB(t)
+	defer cleanup()
+
+	service, err := NewCorrelationService(logging.NewNoopLogger(), database, nil)
+	if err != nil {
+		t.Fatalf("failed to create correlation service: %v", err)
+	}
+	sessionManager := createMockSessionManager(t, database)
+
+	now := time.Now()
+	session := createTestSession(t, database, "session-1", "my-project", now.Add(-2*time.Hour), now.Add(-30*time.Minute))
+	createTestConversation(t, database, "conv-1", session.ID, []cursor.Message{
+		{BubbleID: "msg-1", Type: 1, Role: "user", Text: "Run the tests", CreatedAt: now.Add(-35 * time.Minute)},
+	})
+
+	repository := Repository{Path: "/home/user/my-project", Name: "my-project"}
+	// Committed 20 minutes after the session ended
+	commit := CommitMetadata{Hash: "abc123", Timestamp: now.Add(-10 * time.Minute)}
+
+	correlation, err := service.CorrelateCommit(commit, repository, sessionManager)
+	if err != nil {
+		t.Fatalf("CorrelateCommit() error = %v", err)
+	}
+	if correlation.CorrelationType != "none" {
+		t.Errorf("without a post-session window type = %q, want none", correlation.CorrelationType)
+	}
+
+	correlation, err = service.CorrelateCommitWithOptions(commit, repository, sessionManager, CorrelationOptions{PostSessionWindow: 30 * time.Minute})
+	if err != nil {
+		t.Fatalf("CorrelateCommitWithOptions() error = %v", err)
+	}
+	if correlation.CorrelationType != "post_session" || correlation.SessionID != "session-1" {
+		t.Errorf("correlation = %q to %q, want post_session to session-1", correlation.CorrelationType, correlation.SessionID)
+	}
+	if correlation.Confidence <= 0 || correlation.Confidence >= 1 {
+		t.Errorf("post_session confidence = %v, want between 0 and 1", correlation.Confidence)
+	}
+
+	correlation, err = service.CorrelateCommitWithOptions(commit, repository, sessionManager, CorrelationOptions{PostSessionWindow: 10 * time.Minute})
+	if err != nil {
+		t.Fatalf("CorrelateCommitWithOptions() error = %v", err)
+	}
+	if correlation.CorrelationType != "none" {
+		t.Errorf("commit outside the window type = %q, want none", correlation.CorrelationType)
+	}
+}
@@ -0,0 +1,82 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// diffWriter keeps the first budget lines of a diff written to it and only counts
+// the rest, so diffs of any size are held in memory up to the lines shown
+type diffWriter struct {
+	budget   int
+	kept     strings.Builder
+	newlines int
+}
+
+// newDiffWriter creates a diffWriter that keeps at most budget lines
+func newDiffWriter(budget int) *diffWriter {
+	return &diffWriter{budget: budget}
+}
+
+// Write keeps p up to the line budget and counts its lines. It never fails.
+func (w *diffWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		if w.Truncated() {
+			w.newlines += bytes.Count(p, []byte{'\n'})
+			break
+		}
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			w.kept.Write(p)
+			break
+		}
+		w.newlines++
+		if w.Truncated() {
+			// The last kept line ends the content without its newline
+			w.kept.Write(p[:i])
+		} else {
+			w.kept.Write(p[:i+1])
+		}
+		p = p[i+1:]
+	}
+	return n, nil
+}
+
+// TotalLines returns the number of lines written, counting the text after the
+// last newline as a line
+func (w *diffWriter) TotalLines() int {
+	return w.newlines + 1
+}
+
+// ShownLines returns the number of lines kept
+func (w *diffWriter) ShownLines() int {
+	if w.Truncated() {
+		return w.budget
+	}
+	return w.TotalLines()
+}
+
+// Truncated reports whether more lines were written than the budget
+func (w *diffWriter) Truncated() bool {
+	return w.newlines >= w.budget
+}
+
+// Content returns the kept lines, followed by a note when the diff was truncated
+func (w *diffWriter) Content() string {
+	if !w.Truncated() {
+		return w.kept.String()
+	}
+	return w.kept.String() + fmt.Sprintf("\n\n[Diff truncated: %d lines total, showing first %d lines]", w.TotalLines(), w.budget)
+}
+
+// chunkLineCount returns the number of lines in a chunk, not counting the empty
+// text after a trailing newline
+func chunkLineCount(content string) int {
+	count := strings.Count(content, "\n")
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		count++
+	}
+	return count
+}
@@ -0,0 +1,57 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDiffWriter_MatchesSplitSemantics(t *testing.T) {
+	tests := []struct {
+		name   string
+		chunks []string
+		budget int
+	}{
+		{name: "empty", chunks: nil, budget: 3},
+		{name: "under budget", chunks: []string{"a\nb\n"}, budget: 5},
+		{name: "exactly budget", chunks: []string{"a\nb\nc"}, budget: 3},
+		{name: "trailing newline at budget", chunks: []string{"a\nb\nc\n"}, budget: 3},
+		{name: "over budget across writes", chunks: []string{"a\nb", "\nc\nd", "\ne\nf\n"}, budget: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newDiffWriter(tt.budget)
+			for _, chunk := range tt.chunks {
+				if n, err := w.Write([]byte(chunk)); err != nil || n != len(chunk) {
+					t.Fatalf("Write() = %d, %v", n, err)
+				}
+			}
+
+			full := strings.Join(tt.chunks, "")
+			lines := strings.Split(full, "\n")
+			want := full
+			if len(lines) > tt.budget {
+				want = strings.Join(lines[:tt.budget], "\n") + fmt.Sprintf("\n\n[Diff truncated: %d lines total, showing first %d lines]", len(lines), tt.budget)
+			}
+
+			if w.TotalLines() != len(lines) {
+				t.Errorf("TotalLines() = %d, want %d", w.TotalLines(), len(lines))
+			}
+			if w.Truncated() != (len(lines) > tt.budget) {
+				t.Errorf("Truncated() = %v, want %v", w.Truncated(), len(lines) > tt.budget)
+			}
+			if got := w.Content(); got != want {
+				t.Errorf("Content() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestChunkLineCount(t *testing.T) {
+	for content, want := range map[string]int{"": 0, "a": 1, "a\n": 1, "a\nb": 2, "a\nb\n": 2} {
+		if got := chunkLineCount(content); got != want {
+			t.Errorf("chunkLineCount(%q) = %d, want %d", content, got, want)
+		}
+	}
+}
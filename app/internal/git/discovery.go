@@ -89,7 +89,7 @@ func (ds *discoveryService) DiscoverRepositories(dirs []string) ([]Repository, e
 			if !seenPaths[repo.Path] {
 				seenPaths[repo.Path] = true
 				allRepos = append(allRepos, repo)
-				ds.logger.Info("discovered git repository", "path", repo.Path, "name", repo.Name, "is_worktree", repo.IsWorktree)
+				ds.logger.Info("discovered git repository", "path", repo.Path, "name", repo.Name, "is_worktree", repo.IsWorktree, "is_bare", repo.IsBare)
 			} else {
 				ds.logger.Debug("skipping duplicate repository", "path", repo.Path)
 			}
@@ -136,6 +136,26 @@ func (ds *discoveryService) FindGitRepositories(dir string) ([]Repository, error
 			return filepath.SkipDir // Don't scan into .git directory
 		}
 
+		// Check for bare or mirror repositories, whose directory is the git directory
+		if d.IsDir() && isBareRepository(path) {
+			if err := ds.validateRepository(path); err != nil {
+				ds.logger.Warn("invalid or corrupted bare repository detected, skipping", "path", path, "error", err)
+				return filepath.SkipDir
+			}
+
+			repo, err := ds.createRepository(path, path, false)
+			if err != nil {
+				ds.logger.Warn("failed to create repository from bare repository, skipping", "path", path, "error", err)
+				return filepath.SkipDir
+			}
+			// Mirrors are conventionally named <project>.git
+			repo.Name = strings.TrimSuffix(repo.Name, ".git")
+			repo.IsBare = true
+			repos = append(repos, repo)
+			ds.logger.Debug("found bare git repository", "repo_root", path)
+			return filepath.SkipDir // Don't scan into git internals
+		}
+
 		// Check for .git file (worktree)
 		if !d.IsDir() && d.Name() == ".git" {
 			repoRoot := filepath.Dir(path)
@@ -201,6 +201,24 @@ func TestDiscoveryService_FindGitRepositories(t *testing.T) {
 		}
 	})
 
+	t.Run("find bare mirror repository", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		mirror := filepath.Join(tmpDir, "mirrors", "project.git")
+		createTestGitRepo(t, mirror, true)
+
+		repos, err := ds.FindGitRepositories(tmpDir)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(repos) != 1 {
+			t.Fatalf("expected 1 repository, got %d", len(repos))
+		}
+		if !repos[0].IsBare || repos[0].Name != "project" || repos[0].GitDir != repos[0].Path {
+			t.Errorf("unexpected bare repository metadata: %+v", repos[0])
+		}
+	})
+
 	t.Run("skip scanning into .git directories", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		repo1 := filepath.Join(tmpDir, "repo1")
@@ -285,114 +285,106 @@ func (ce *commitExtractor) ExtractDiff(repo *git.Repository, hash plumbing.Hash)
 		return nil, fmt.Errorf("failed after %d attempts: %w", maxRetries+1, lastErr)
 	}
 
-	// Generate patch
-	var patch *object.Patch
+	// Diff against the first parent for merge commits, or the empty tree for initial commits
+	commitTree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit tree: %w", err)
+	}
+
+	var changes object.Changes
 	parentIter := commit.Parents()
 	defer parentIter.Close()
 
-	// Get first parent for merge commits, or use empty tree for initial commits
 	parent, err := parentIter.Next()
 	if err != nil {
 		// Check if this is an initial commit (no parent)
-		// ErrParentNotFound or io.EOF both indicate no parent
-		if err == object.ErrParentNotFound || errors.Is(err, io.EOF) {
-			// Initial commit - compare commit tree with empty tree
-			commitTree, err := commit.Tree()
-			if err != nil {
-				return nil, fmt.Errorf("failed to get commit tree: %w", err)
-			}
+		// ErrParentNotFound or io.EOF both indicate no parent; a parent missing at
+		// the edge of a shallow clone is treated as none, as git does
+		if err == object.ErrParentNotFound || errors.Is(err, io.EOF) || isShallowBoundary(repo, err) {
 			// Use DiffTree to compare with empty tree (nil = empty tree)
-			changes, err := object.DiffTree(nil, commitTree)
+			changes, err = object.DiffTree(nil, commitTree)
 			if err != nil {
 				return nil, fmt.Errorf("failed to diff trees for initial commit: %w", err)
 			}
-			patch, err = changes.Patch()
-			if err != nil {
-				return nil, fmt.Errorf("failed to generate patch for initial commit: %w", err)
-			}
 		} else {
 			return nil, fmt.Errorf("failed to get parent commit: %w", err)
 		}
-		} else {
-			// Normal commit or merge commit (use first parent)
-			patch, err = parent.Patch(commit)
-			if err != nil {
-				ce.logger.Error("failed to generate patch", "commit", commit.Hash.String(), "error", err)
-				return nil, fmt.Errorf("failed to generate patch: %w", err)
-			}
+	} else {
+		// Normal commit or merge commit (use first parent)
+		parentTree, err := parent.Tree()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get parent tree: %w", err)
 		}
+		changes, err = parentTree.Diff(commitTree)
+		if err != nil {
+			ce.logger.Error("failed to diff trees", "commit", commit.Hash.String(), "error", err)
+			return nil, fmt.Errorf("failed to diff trees: %w", err)
+		}
+	}
 
-	// Extract full diff string
-	fullDiff := patch.String()
-
-	// Extract file-level statistics
+	// Stream the diff one file at a time through a line-budgeted writer, so only the
+	// shown lines stay in memory however large the diff is (vendored deps, generated code)
+	writer := newDiffWriter(MaxDiffLines)
 	files := []FileChange{}
-	for _, filePatch := range patch.FilePatches() {
-		from, to := filePatch.Files()
-
-		// Determine file path (prefer 'to' path, fallback to 'from' path)
-		var filePath string
-		if to != nil {
-			filePath = to.Path()
-		} else if from != nil {
-			filePath = from.Path()
-		} else {
-			// Skip if both are nil (shouldn't happen, but be safe)
-			ce.logger.Debug("skipping file patch with nil files", "commit", commit.Hash.String())
-			continue
+	for _, change := range changes {
+		patch, err := change.Patch()
+		if err != nil {
+			ce.logger.Error("failed to generate patch", "commit", commit.Hash.String(), "error", err)
+			return nil, fmt.Errorf("failed to generate patch: %w", err)
+		}
+		if err := patch.Encode(writer); err != nil {
+			return nil, fmt.Errorf("failed to encode patch: %w", err)
 		}
 
-		// Count additions and deletions from chunks
-		// Chunk types: 0=Equal, 1=Add, 2=Delete
-		additions := 0
-		deletions := 0
-		for _, chunk := range filePatch.Chunks() {
-			chunkType := chunk.Type()
-			content := chunk.Content()
-			lines := strings.Split(content, "\n")
-
-			// Count non-empty lines (last line might be empty if content ends with newline)
-			lineCount := len(lines)
-			if lineCount > 0 && lines[lineCount-1] == "" {
-				lineCount--
+		// Extract file-level statistics
+		for _, filePatch := range patch.FilePatches() {
+			from, to := filePatch.Files()
+
+			// Determine file path (prefer 'to' path, fallback to 'from' path)
+			var filePath string
+			if to != nil {
+				filePath = to.Path()
+			} else if from != nil {
+				filePath = from.Path()
+			} else {
+				// Skip if both are nil (shouldn't happen, but be safe)
+				ce.logger.Debug("skipping file patch with nil files", "commit", commit.Hash.String())
+				continue
 			}
 
-			if chunkType == 1 { // Add
-				additions += lineCount
-			} else if chunkType == 2 { // Delete
-				deletions += lineCount
+			// Count additions and deletions from chunks
+			// Chunk types: 0=Equal, 1=Add, 2=Delete
+			additions := 0
+			deletions := 0
+			for _, chunk := range filePatch.Chunks() {
+				lineCount := chunkLineCount(chunk.Content())
+				if chunk.Type() == 1 { // Add
+					additions += lineCount
+				} else if chunk.Type() == 2 { // Delete
+					deletions += lineCount
+				}
 			}
-		}
 
-		files = append(files, FileChange{
-			Path:      filePath,
-			Additions: additions,
-			Deletions: deletions,
-		})
-		ce.logger.Debug("processed file diff", "commit", commit.Hash.String(), "file", filePath, "additions", additions, "deletions", deletions)
+			files = append(files, FileChange{
+				Path:      filePath,
+				Additions: additions,
+				Deletions: deletions,
+			})
+			ce.logger.Debug("processed file diff", "commit", commit.Hash.String(), "file", filePath, "additions", additions, "deletions", deletions)
+		}
 	}
 
-	// Handle large diffs - truncate if necessary
-	diffLines := strings.Split(fullDiff, "\n")
-	totalLines := len(diffLines)
-	truncated := false
-	shownLines := totalLines
-	content := fullDiff
-
-	if totalLines > MaxDiffLines {
-		truncated = true
-		shownLines = MaxDiffLines
-		// Truncate diff content but keep file statistics
-		truncatedLines := diffLines[:MaxDiffLines]
-		truncationNote := fmt.Sprintf("\n\n[Diff truncated: %d lines total, showing first %d lines]", totalLines, MaxDiffLines)
-		content = strings.Join(truncatedLines, "\n") + truncationNote
-
+	// Large diffs keep their first lines and file statistics
+	totalLines := writer.TotalLines()
+	truncated := writer.Truncated()
+	shownLines := writer.ShownLines()
+	if truncated {
 		ce.logger.Info("truncated large diff", "commit", commit.Hash.String(), "total_lines", totalLines, "shown_lines", shownLines, "file_count", len(files))
 	}
 
 	ce.logger.Debug("extracted commit diff", "commit", commit.Hash.String(), "file_count", len(files), "total_lines", totalLines, "truncated", truncated)
 	return &Diff{
-		Content:    content,
+		Content:    writer.Content(),
 		Files:      files,
 		Truncated:  truncated,
 		TotalLines: totalLines,
@@ -418,6 +410,11 @@ func (ce *commitExtractor) ExtractCommit(repo *git.Repository, hash plumbing.Has
 		return nil, fmt.Errorf("failed to extract diff: %w", err)
 	}
 
+	// File paths let correlation score overlap with files discussed in conversations
+	for _, file := range diff.Files {
+		metadata.FilePaths = append(metadata.FilePaths, file.Path)
+	}
+
 	ce.logger.Info("extracted complete commit information", "commit", hash.String(), "file_count", len(diff.Files))
 	return &CommitInfo{
 		Commit: *metadata,
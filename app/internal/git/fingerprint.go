@@ -0,0 +1,270 @@
+package git
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stwalsh4118/clio/internal/cursor"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+// upsertFingerprintQuery stores the fingerprint of a repository path
+const upsertFingerprintQuery = `
+	INSERT INTO repository_fingerprints (repository_path, root_commit, remote, created_at, updated_at)
+	VALUES (?, ?, ?, ?, ?)
+	ON CONFLICT(repository_path) DO UPDATE SET
+		root_commit = excluded.root_commit,
+		remote = excluded.remote,
+		updated_at = excluded.updated_at
+`
+
+// Fingerprint identifies a repository wherever it's checked out
+type Fingerprint struct {
+	RootCommit string // Root of HEAD's first-parent history
+	Remote     string // Normalized origin remote URL, empty without one
+}
+
+// RepositoryMove describes a repository re-bound from its old path to a new one
+type RepositoryMove struct {
+	From    string
+	To      string
+	Commits int // Stored commits moved to the new path
+}
+
+// FingerprintStore defines the interface for re-binding repositories that were
+// moved or renamed, so their history stays linked and polling resumes from their checkpoint
+type FingerprintStore interface {
+	// Rebind fingerprints newly seen repositories and moves the history of any
+	// repository whose old path is gone to the path it was discovered at
+	Rebind(repos []Repository) ([]RepositoryMove, error)
+}
+
+// fingerprintStore implements FingerprintStore for database persistence
+type fingerprintStore struct {
+	db     *sql.DB
+	logger logging.Logger
+}
+
+// knownFingerprint is a stored fingerprint and when it was last confirmed
+type knownFingerprint struct {
+	Fingerprint
+	updatedAt time.Time
+}
+
+// NewFingerprintStore creates a new repository fingerprint store instance
+func NewFingerprintStore(db *sql.DB, logger logging.Logger) (FingerprintStore, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database cannot be nil")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	return &fingerprintStore{
+		db:     db,
+		logger: logger.With("component", "repository_fingerprints"),
+	}, nil
+}
+
+// ComputeFingerprint returns the fingerprint of the repository at path. It fails
+// for repositories without commits.
+func ComputeFingerprint(path string) (Fingerprint, error) {
+	repo, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{EnableDotGitCommonDir: true})
+	if err != nil {
+		return Fingerprint{}, fmt.Errorf("failed to open repository: %w", err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return Fingerprint{}, fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return Fingerprint{}, fmt.Errorf("failed to read HEAD commit: %w", err)
+	}
+
+	// A shallow clone ends at its boundary, which stands in for the root
+	for commit.NumParents() > 0 {
+		var parent *object.Commit
+		if parent, err = commit.Parent(0); err != nil {
+			break
+		}
+		commit = parent
+	}
+
+	fp := Fingerprint{RootCommit: commit.Hash.String()}
+	if remote, err := repo.Remote("origin"); err == nil && len(remote.Config().URLs) > 0 {
+		fp.Remote = cursor.NormalizeRemoteURL(remote.Config().URLs[0])
+	}
+	return fp, nil
+}
+
+// Rebind fingerprints repositories seen for the first time. A repository whose
+// fingerprint matches one stored for a path that is gone, and not just on an
+// offline drive, was moved: its commits and poller checkpoint follow it to the
+// new path. Fingerprints of gone paths are kept so a later move is still matched.
+func (fs *fingerprintStore) Rebind(repos []Repository) ([]RepositoryMove, error) {
+	known, err := fs.load()
+	if err != nil {
+		return nil, err
+	}
+	offline, err := fs.offlinePaths()
+	if err != nil {
+		return nil, err
+	}
+
+	discovered := make(map[string]bool, len(repos))
+	for _, repo := range repos {
+		discovered[repo.Path] = true
+	}
+
+	var moves []RepositoryMove
+	now := time.Now()
+	for _, repo := range repos {
+		if _, ok := known[repo.Path]; ok {
+			continue
+		}
+		fp, err := ComputeFingerprint(repo.Path)
+		if err != nil {
+			// Typically an empty repository; it's fingerprinted once it has commits
+			fs.logger.Debug("failed to fingerprint repository, skipping", "repository", repo.Path, "error", err)
+			continue
+		}
+
+		from := fs.movedFrom(fp, known, discovered, offline)
+		if from == "" {
+			if err := fs.save(repo.Path, fp, now); err != nil {
+				return moves, err
+			}
+		} else {
+			moved, err := fs.move(from, repo, fp, now)
+			if err != nil {
+				return moves, err
+			}
+			delete(known, from)
+			moves = append(moves, RepositoryMove{From: from, To: repo.Path, Commits: moved})
+			fs.logger.Info("repository moved, re-bound its history", "from", from, "to", repo.Path, "commits", moved)
+		}
+		known[repo.Path] = knownFingerprint{Fingerprint: fp, updatedAt: now}
+	}
+	return moves, nil
+}
+
+// movedFrom returns the gone path most recently fingerprinted as fp, or "" when there is none
+func (fs *fingerprintStore) movedFrom(fp Fingerprint, known map[string]knownFingerprint, discovered, offline map[string]bool) string {
+	var candidates []string
+	for path, k := range known {
+		if k.Fingerprint != fp || discovered[path] || offline[path] || pathAvailable(path) {
+			continue
+		}
+		candidates = append(candidates, path)
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := known[candidates[i]], known[candidates[j]]
+		if !a.updatedAt.Equal(b.updatedAt) {
+			return a.updatedAt.After(b.updatedAt)
+		}
+		return candidates[i] < candidates[j]
+	})
+	return candidates[0]
+}
+
+// move re-binds the history at from to repo in a single transaction and returns the number of commits moved
+func (fs *fingerprintStore) move(from string, repo Repository, fp Fingerprint, now time.Time) (int, error) {
+	tx, err := fs.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin re-bind transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	res, err := tx.Exec(`
+		UPDATE commits SET repository_path = ?, repository_name = ?, updated_at = ?
+		WHERE repository_path = ?
+	`, repo.Path, repo.Name, now, from)
+	if err != nil {
+		return 0, fmt.Errorf("failed to move commits: %w", err)
+	}
+	moved, _ := res.RowsAffected()
+
+	if _, err := tx.Exec(`
+		UPDATE OR REPLACE poller_checkpoints SET repository_path = ?, updated_at = ? WHERE repository_path = ?
+	`, repo.Path, now, from); err != nil {
+		return 0, fmt.Errorf("failed to move poller checkpoint: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM repository_health WHERE repository_path = ?", from); err != nil {
+		return 0, fmt.Errorf("failed to remove health of old path: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM repository_fingerprints WHERE repository_path = ?", from); err != nil {
+		return 0, fmt.Errorf("failed to remove fingerprint of old path: %w", err)
+	}
+	if _, err := tx.Exec(upsertFingerprintQuery, repo.Path, fp.RootCommit, fp.Remote, now, now); err != nil {
+		return 0, fmt.Errorf("failed to save repository fingerprint for %s: %w", repo.Path, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit re-bind: %w", err)
+	}
+	return int(moved), nil
+}
+
+// save stores the fingerprint of a repository path
+func (fs *fingerprintStore) save(path string, fp Fingerprint, now time.Time) error {
+	if _, err := fs.db.Exec(upsertFingerprintQuery, path, fp.RootCommit, fp.Remote, now, now); err != nil {
+		return fmt.Errorf("failed to save repository fingerprint for %s: %w", path, err)
+	}
+	return nil
+}
+
+// load returns every stored fingerprint, keyed by path
+func (fs *fingerprintStore) load() (map[string]knownFingerprint, error) {
+	rows, err := fs.db.Query("SELECT repository_path, root_commit, remote, updated_at FROM repository_fingerprints")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query repository fingerprints: %w", err)
+	}
+	defer rows.Close()
+
+	known := make(map[string]knownFingerprint)
+	for rows.Next() {
+		var path string
+		var k knownFingerprint
+		if err := rows.Scan(&path, &k.RootCommit, &k.Remote, &k.updatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan repository fingerprint: %w", err)
+		}
+		known[path] = k
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate repository fingerprints: %w", err)
+	}
+	return known, nil
+}
+
+// offlinePaths returns the paths of repositories last known to be offline. Their
+// drive or share may return, so they aren't treated as moved.
+func (fs *fingerprintStore) offlinePaths() (map[string]bool, error) {
+	rows, err := fs.db.Query("SELECT repository_path FROM repository_health WHERE status = ?", RepositoryOffline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query offline repositories: %w", err)
+	}
+	defer rows.Close()
+
+	offline := make(map[string]bool)
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return nil, fmt.Errorf("failed to scan offline repository: %w", err)
+		}
+		offline[path] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate offline repositories: %w", err)
+	}
+	return offline, nil
+}
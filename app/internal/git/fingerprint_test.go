@@ -0,0 +1,131 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+func TestComputeFingerprint(t *testing.T) {
+	dir := t.TempDir()
+	repoPath := filepath.Join(dir, "project")
+	gitRepo, err := createGitRepoWithCommits(t, repoPath, 3)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	if _, err := gitRepo.CreateRemote(&gitconfig.RemoteConfig{Name: "origin", URLs: []string{"git@github.com:acme/project.git"}}); err != nil {
+		t.Fatalf("failed to create remote: %v", err)
+	}
+
+	fp, err := ComputeFingerprint(repoPath)
+	if err != nil {
+		t.Fatalf("ComputeFingerprint() error = %v", err)
+	}
+	if fp.Remote != "github.com/acme/project" {
+		t.Errorf("Remote = %q, want github.com/acme/project", fp.Remote)
+	}
+
+	// New commits don't change the root
+	worktree, _ := gitRepo.Worktree()
+	os.WriteFile(filepath.Join(repoPath, "later.txt"), []byte("content"), 0644)
+	worktree.Add("later.txt")
+	if _, err := worktree.Commit("Later commit", &git.CommitOptions{
+		Author: &object.Signature{Name: "Author", Email: "test@example.com", When: time.Now()},
+	}); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+	again, err := ComputeFingerprint(repoPath)
+	if err != nil || again != fp {
+		t.Errorf("ComputeFingerprint() after a commit = %+v, %v, want %+v", again, err, fp)
+	}
+
+	empty := filepath.Join(dir, "empty")
+	if _, err := git.PlainInit(empty, false); err != nil {
+		t.Fatalf("failed to init repository: %v", err)
+	}
+	if _, err := ComputeFingerprint(empty); err == nil {
+		t.Error("ComputeFingerprint() of an empty repository should fail")
+	}
+}
+
+func TestFingerprintStore_Rebind(t *testing.T) {
+	database, cleanup := setupTestCorrelationDB(t)
+	t.Cleanup(cleanup)
+	store, err := NewFingerprintStore(database, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewFingerprintStore() error = %v", err)
+	}
+	checkpoints, err := NewCheckpointStore(database, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewCheckpointStore() error = %v", err)
+	}
+
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "project")
+	if _, err := createGitRepoWithCommits(t, oldPath, 2); err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	// An unrelated repository, distinct by its remote even if its root commit is identical
+	otherPath := filepath.Join(dir, "other")
+	otherRepo, err := createGitRepoWithCommits(t, otherPath, 1)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	if _, err := otherRepo.CreateRemote(&gitconfig.RemoteConfig{Name: "origin", URLs: []string{"https://github.com/acme/other"}}); err != nil {
+		t.Fatalf("failed to create remote: %v", err)
+	}
+
+	// First run fingerprints both; nothing has moved
+	moves, err := store.Rebind([]Repository{{Path: oldPath, Name: "project"}, {Path: otherPath, Name: "other"}})
+	if err != nil || len(moves) != 0 {
+		t.Fatalf("Rebind() = %+v, %v, want no moves", moves, err)
+	}
+
+	now := time.Now()
+	if _, err := database.Exec(`
+		INSERT INTO commits (id, repository_path, repository_name, hash, message, author_name, author_email,
+			timestamp, branch, created_at, updated_at)
+		VALUES ('c1', ?, 'project', 'abc123', 'Fix', 'Dev', 'dev@example.com', ?, 'main', ?, ?)
+	`, oldPath, now, now, now); err != nil {
+		t.Fatalf("failed to create commit: %v", err)
+	}
+	if err := checkpoints.SaveAll(map[string]string{oldPath: "abc123"}); err != nil {
+		t.Fatalf("SaveAll() error = %v", err)
+	}
+
+	// The project is renamed while the daemon is stopped
+	newPath := filepath.Join(dir, "renamed")
+	if err := os.Rename(oldPath, newPath); err != nil {
+		t.Fatalf("failed to rename repository: %v", err)
+	}
+	moves, err = store.Rebind([]Repository{{Path: newPath, Name: "renamed"}, {Path: otherPath, Name: "other"}})
+	if err != nil {
+		t.Fatalf("Rebind() error = %v", err)
+	}
+	if len(moves) != 1 || moves[0].From != oldPath || moves[0].To != newPath || moves[0].Commits != 1 {
+		t.Fatalf("Rebind() = %+v, want the rename with one commit", moves)
+	}
+
+	var path, name string
+	if err := database.QueryRow("SELECT repository_path, repository_name FROM commits WHERE id = 'c1'").Scan(&path, &name); err != nil {
+		t.Fatalf("failed to read commit: %v", err)
+	}
+	if path != newPath || name != "renamed" {
+		t.Errorf("commit repository = %s (%s), want %s (renamed)", path, name, newPath)
+	}
+	hashes, err := checkpoints.Load()
+	if err != nil || len(hashes) != 1 || hashes[newPath] != "abc123" {
+		t.Errorf("checkpoints = %v, %v, want abc123 at the new path", hashes, err)
+	}
+
+	// Once re-bound, the new path is known and isn't fingerprinted again
+	if moves, err := store.Rebind([]Repository{{Path: newPath, Name: "renamed"}}); err != nil || len(moves) != 0 {
+		t.Errorf("Rebind() again = %+v, %v, want no moves", moves, err)
+	}
+}
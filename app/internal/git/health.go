@@ -0,0 +1,318 @@
+package git
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+const (
+	// RepositoryHealthy marks a repository that polled successfully
+	RepositoryHealthy = "healthy"
+	// RepositoryUnhealthy marks a repository that keeps failing to poll
+	RepositoryUnhealthy = "unhealthy"
+	// RepositoryOffline marks a repository whose network share or external drive is unavailable
+	RepositoryOffline = "offline"
+
+	// unhealthyFailureThreshold is the number of consecutive failures before a repository is unhealthy
+	unhealthyFailureThreshold = 3
+	// maxHealthBackoff caps the delay between retries of a failing repository
+	maxHealthBackoff = 30 * time.Minute
+	// offlineRetention is how long an offline repository that is no longer discovered
+	// keeps its health and checkpoint across restarts before it is forgotten
+	offlineRetention = 30 * 24 * time.Hour
+)
+
+// RepositoryHealth describes the polling health of a watched repository
+type RepositoryHealth struct {
+	Path                string
+	Name                string
+	Status              string     // RepositoryHealthy, RepositoryUnhealthy, or RepositoryOffline
+	ConsecutiveFailures int        // Failed polls since the last success
+	LastError           string     // Most recent failure, empty when healthy
+	UnhealthySince      *time.Time // When the repository became unhealthy or went offline
+	NextRetryAt         time.Time  // Polls are skipped until this time while failing
+}
+
+// HealthStore defines the interface for persisting repository health so other
+// processes (status, doctor) can report it
+type HealthStore interface {
+	Save(health RepositoryHealth) error
+	List() ([]RepositoryHealth, error)
+	Prune(keepPaths []string) error
+}
+
+// healthStore implements HealthStore for database persistence
+type healthStore struct {
+	db     *sql.DB
+	logger logging.Logger
+}
+
+// NewHealthStore creates a new repository health store instance
+func NewHealthStore(db *sql.DB, logger logging.Logger) (HealthStore, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database cannot be nil")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	return &healthStore{
+		db:     db,
+		logger: logger.With("component", "repository_health"),
+	}, nil
+}
+
+// Save upserts the health of a repository
+func (hs *healthStore) Save(health RepositoryHealth) error {
+	var unhealthySince, nextRetryAt sql.NullTime
+	if health.UnhealthySince != nil {
+		unhealthySince = sql.NullTime{Time: *health.UnhealthySince, Valid: true}
+	}
+	if !health.NextRetryAt.IsZero() {
+		nextRetryAt = sql.NullTime{Time: health.NextRetryAt, Valid: true}
+	}
+
+	_, err := hs.db.Exec(`
+		INSERT INTO repository_health (repository_path, repository_name, status, consecutive_failures,
+			last_error, unhealthy_since, next_retry_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(repository_path) DO UPDATE SET
+			repository_name = excluded.repository_name,
+			status = excluded.status,
+			consecutive_failures = excluded.consecutive_failures,
+			last_error = excluded.last_error,
+			unhealthy_since = excluded.unhealthy_since,
+			next_retry_at = excluded.next_retry_at,
+			updated_at = excluded.updated_at
+	`, health.Path, health.Name, health.Status, health.ConsecutiveFailures,
+		health.LastError, unhealthySince, nextRetryAt, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to save repository health: %w", err)
+	}
+	return nil
+}
+
+// List returns the stored health of every repository, unhealthy first
+func (hs *healthStore) List() ([]RepositoryHealth, error) {
+	return ListRepositoryHealth(hs.db)
+}
+
+// Prune removes health records for repositories that are no longer watched
+func (hs *healthStore) Prune(keepPaths []string) error {
+	keep := make(map[string]bool, len(keepPaths))
+	for _, path := range keepPaths {
+		keep[path] = true
+	}
+
+	records, err := hs.List()
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		if keep[record.Path] {
+			continue
+		}
+		if _, err := hs.db.Exec("DELETE FROM repository_health WHERE repository_path = ?", record.Path); err != nil {
+			return fmt.Errorf("failed to prune repository health: %w", err)
+		}
+		hs.logger.Debug("pruned health record for unwatched repository", "repository", record.Path)
+	}
+	return nil
+}
+
+// ListRepositoryHealth returns the stored health of every repository, unhealthy first.
+// The table is written by the daemon's git poller.
+func ListRepositoryHealth(db *sql.DB) ([]RepositoryHealth, error) {
+	rows, err := db.Query(`
+		SELECT repository_path, repository_name, status, consecutive_failures, last_error,
+			unhealthy_since, next_retry_at
+		FROM repository_health
+		ORDER BY status DESC, repository_path ASC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query repository health: %w", err)
+	}
+	defer rows.Close()
+
+	var records []RepositoryHealth
+	for rows.Next() {
+		var health RepositoryHealth
+		var unhealthySince, nextRetryAt sql.NullTime
+		if err := rows.Scan(&health.Path, &health.Name, &health.Status, &health.ConsecutiveFailures,
+			&health.LastError, &unhealthySince, &nextRetryAt); err != nil {
+			return nil, fmt.Errorf("failed to scan repository health: %w", err)
+		}
+		if unhealthySince.Valid {
+			health.UnhealthySince = &unhealthySince.Time
+		}
+		if nextRetryAt.Valid {
+			health.NextRetryAt = nextRetryAt.Time
+		}
+		records = append(records, health)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating repository health: %w", err)
+	}
+
+	return records, nil
+}
+
+// healthTracker tracks per-repository poll failures in memory and backs off
+// repositories that keep failing. Changes are mirrored to an optional HealthStore.
+type healthTracker struct {
+	store       HealthStore // Optional, nil keeps health in memory only
+	logger      logging.Logger
+	baseBackoff time.Duration
+	mu          sync.Mutex
+	repos       map[string]*RepositoryHealth
+}
+
+// newHealthTracker creates a tracker whose backoff starts at baseBackoff
+func newHealthTracker(store HealthStore, logger logging.Logger, baseBackoff time.Duration) *healthTracker {
+	return &healthTracker{
+		store:       store,
+		logger:      logger,
+		baseBackoff: baseBackoff,
+		repos:       make(map[string]*RepositoryHealth),
+	}
+}
+
+// shouldPoll reports whether a repository is due to be polled
+func (ht *healthTracker) shouldPoll(path string, now time.Time) bool {
+	ht.mu.Lock()
+	defer ht.mu.Unlock()
+
+	health, ok := ht.repos[path]
+	return !ok || health.NextRetryAt.IsZero() || !now.Before(health.NextRetryAt)
+}
+
+// recordSuccess marks a repository healthy, persisting the change if it was failing
+func (ht *healthTracker) recordSuccess(repo Repository) {
+	ht.mu.Lock()
+	health, ok := ht.repos[repo.Path]
+	wasOffline := ok && health.Status == RepositoryOffline
+	wasFailing := ok && (health.ConsecutiveFailures > 0 || wasOffline)
+	ht.repos[repo.Path] = &RepositoryHealth{Path: repo.Path, Name: repo.Name, Status: RepositoryHealthy}
+	snapshot := *ht.repos[repo.Path]
+	ht.mu.Unlock()
+
+	if !ok || wasFailing {
+		if wasOffline {
+			ht.logger.Info("repository back online, resuming polling", "repository", repo.Path)
+		} else if wasFailing {
+			ht.logger.Info("repository recovered", "repository", repo.Path, "failures", health.ConsecutiveFailures)
+		}
+		ht.save(snapshot)
+	}
+}
+
+// recordOffline marks a repository whose drive or share is unavailable. Offline
+// repositories are checked every base interval without backing off, so polling
+// resumes soon after the path returns, and only going offline is logged.
+func (ht *healthTracker) recordOffline(repo Repository, err error, now time.Time) RepositoryHealth {
+	ht.mu.Lock()
+	health, ok := ht.repos[repo.Path]
+	if !ok {
+		health = &RepositoryHealth{Path: repo.Path, Name: repo.Name, Status: RepositoryHealthy}
+		ht.repos[repo.Path] = health
+	}
+	wentOffline := health.Status != RepositoryOffline
+	if wentOffline {
+		health.Status = RepositoryOffline
+		health.UnhealthySince = &now
+		health.LastError = "repository path is unavailable (network share or external drive offline)"
+		if err != nil {
+			health.LastError += ": " + err.Error()
+		}
+	}
+	health.ConsecutiveFailures++
+	health.NextRetryAt = now.Add(ht.baseBackoff)
+	snapshot := *health
+	ht.mu.Unlock()
+
+	// Persisting every check would only move the retry time, so only transitions are saved
+	if wentOffline {
+		ht.logger.Info("repository offline, will resume when its path returns", "repository", repo.Path, "error", err)
+		ht.save(snapshot)
+	}
+	return snapshot
+}
+
+// isOffline reports whether a repository is currently marked offline
+func (ht *healthTracker) isOffline(path string) bool {
+	ht.mu.Lock()
+	defer ht.mu.Unlock()
+
+	health, ok := ht.repos[path]
+	return ok && health.Status == RepositoryOffline
+}
+
+// recordFailure counts a failed poll and schedules the next retry with exponential backoff
+func (ht *healthTracker) recordFailure(repo Repository, err error, now time.Time) RepositoryHealth {
+	reason, stale := describeRepositoryError(repo.Path, err)
+
+	ht.mu.Lock()
+	health, ok := ht.repos[repo.Path]
+	if !ok {
+		health = &RepositoryHealth{Path: repo.Path, Name: repo.Name, Status: RepositoryHealthy}
+		ht.repos[repo.Path] = health
+	}
+	health.ConsecutiveFailures++
+	health.LastError = reason
+
+	backoff := ht.baseBackoff << uint(min(health.ConsecutiveFailures-1, 16))
+	if backoff <= 0 || backoff > maxHealthBackoff {
+		backoff = maxHealthBackoff
+	}
+	health.NextRetryAt = now.Add(backoff)
+
+	// Missing repositories won't come back on their own, so flag them immediately
+	becameUnhealthy := false
+	if health.Status != RepositoryUnhealthy && (stale || health.ConsecutiveFailures >= unhealthyFailureThreshold) {
+		health.Status = RepositoryUnhealthy
+		health.UnhealthySince = &now
+		becameUnhealthy = true
+	}
+	snapshot := *health
+	ht.mu.Unlock()
+
+	if becameUnhealthy {
+		ht.logger.Warn("repository marked unhealthy", "repository", repo.Path, "reason", reason, "failures", snapshot.ConsecutiveFailures, "next_retry", snapshot.NextRetryAt)
+	} else {
+		ht.logger.Debug("repository poll failed, backing off", "repository", repo.Path, "reason", reason, "failures", snapshot.ConsecutiveFailures, "next_retry", snapshot.NextRetryAt)
+	}
+	ht.save(snapshot)
+	return snapshot
+}
+
+// save persists a health snapshot, logging rather than failing on errors
+func (ht *healthTracker) save(health RepositoryHealth) {
+	if ht.store == nil {
+		return
+	}
+	if err := ht.store.Save(health); err != nil {
+		ht.logger.Warn("failed to persist repository health", "repository", health.Path, "error", err)
+	}
+}
+
+// describeRepositoryError explains a poll failure and reports whether the
+// repository looks moved, renamed, or deleted
+func describeRepositoryError(path string, err error) (string, bool) {
+	if _, statErr := os.Stat(path); errors.Is(statErr, os.ErrNotExist) {
+		return "repository path no longer exists (moved, renamed, or deleted)", true
+	}
+	if errors.Is(err, git.ErrRepositoryNotExists) {
+		return "path is no longer a git repository", true
+	}
+	if err == nil {
+		return "unknown error", false
+	}
+	return err.Error(), false
+}
@@ -0,0 +1,143 @@
+package git
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+func setupTestHealthDB(t *testing.T) *sql.DB {
+	database, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "clio.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if err := db.RunMigrations(database); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+	return database
+}
+
+func TestHealthTracker_BackoffAndRecovery(t *testing.T) {
+	repoPath := t.TempDir()
+	repo := Repository{Path: repoPath, Name: "repo"}
+	tracker := newHealthTracker(nil, logging.NewNoopLogger(), time.Second)
+	now := time.Now()
+
+	if !tracker.shouldPoll(repoPath, now) {
+		t.Fatal("unknown repository should be polled")
+	}
+
+	// Transient failures back off exponentially and mark unhealthy after the threshold
+	wantBackoff := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}
+	for i, backoff := range wantBackoff {
+		health := tracker.recordFailure(repo, errors.New("locked"), now)
+		if !health.NextRetryAt.Equal(now.Add(backoff)) {
+			t.Errorf("failure %d: NextRetryAt = %v, want now+%v", i+1, health.NextRetryAt.Sub(now), backoff)
+		}
+		wantStatus := RepositoryHealthy
+		if i+1 >= unhealthyFailureThreshold {
+			wantStatus = RepositoryUnhealthy
+		}
+		if health.Status != wantStatus {
+			t.Errorf("failure %d: Status = %q, want %q", i+1, health.Status, wantStatus)
+		}
+	}
+
+	if tracker.shouldPoll(repoPath, now.Add(3*time.Second)) {
+		t.Error("repository should not be polled during backoff")
+	}
+	if !tracker.shouldPoll(repoPath, now.Add(4*time.Second)) {
+		t.Error("repository should be polled once backoff expires")
+	}
+
+	tracker.recordSuccess(repo)
+	if !tracker.shouldPoll(repoPath, now) {
+		t.Error("recovered repository should be polled")
+	}
+	if health := tracker.repos[repoPath]; health.Status != RepositoryHealthy || health.ConsecutiveFailures != 0 {
+		t.Errorf("after recovery = %+v, want healthy with no failures", health)
+	}
+}
+
+func TestHealthTracker_BackoffIsCapped(t *testing.T) {
+	repo := Repository{Path: t.TempDir(), Name: "repo"}
+	tracker := newHealthTracker(nil, logging.NewNoopLogger(), time.Minute)
+	now := time.Now()
+
+	var health RepositoryHealth
+	for i := 0; i < 40; i++ {
+		health = tracker.recordFailure(repo, errors.New("locked"), now)
+	}
+	if got := health.NextRetryAt.Sub(now); got != maxHealthBackoff {
+		t.Errorf("backoff = %v, want cap %v", got, maxHealthBackoff)
+	}
+}
+
+func TestHealthTracker_MissingRepositoryIsUnhealthyImmediately(t *testing.T) {
+	missingPath := filepath.Join(t.TempDir(), "moved-away")
+	repo := Repository{Path: missingPath, Name: "moved-away"}
+	tracker := newHealthTracker(nil, logging.NewNoopLogger(), time.Second)
+
+	health := tracker.recordFailure(repo, os.ErrNotExist, time.Now())
+	if health.Status != RepositoryUnhealthy {
+		t.Errorf("Status = %q, want %q", health.Status, RepositoryUnhealthy)
+	}
+	if health.UnhealthySince == nil {
+		t.Error("UnhealthySince should be set")
+	}
+	if health.LastError != "repository path no longer exists (moved, renamed, or deleted)" {
+		t.Errorf("LastError = %q", health.LastError)
+	}
+}
+
+func TestHealthStore_SaveListPrune(t *testing.T) {
+	database := setupTestHealthDB(t)
+
+	if _, err := NewHealthStore(nil, logging.NewNoopLogger()); err == nil {
+		t.Error("NewHealthStore(nil, ...) expected error, got nil")
+	}
+	store, err := NewHealthStore(database, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewHealthStore() error = %v", err)
+	}
+
+	// Health changes are mirrored to the store
+	tracker := newHealthTracker(store, logging.NewNoopLogger(), time.Second)
+	healthyRepo := Repository{Path: "/repos/healthy", Name: "healthy"}
+	missingRepo := Repository{Path: filepath.Join(t.TempDir(), "missing"), Name: "missing"}
+	tracker.recordSuccess(healthyRepo)
+	tracker.recordFailure(missingRepo, os.ErrNotExist, time.Now())
+
+	records, err := ListRepositoryHealth(database)
+	if err != nil {
+		t.Fatalf("ListRepositoryHealth() error = %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("records = %d, want 2", len(records))
+	}
+	// Unhealthy repositories are listed first
+	if records[0].Path != missingRepo.Path || records[0].Status != RepositoryUnhealthy || records[0].UnhealthySince == nil {
+		t.Errorf("first record = %+v, want unhealthy %s", records[0], missingRepo.Path)
+	}
+	if records[1].Path != healthyRepo.Path || records[1].Status != RepositoryHealthy {
+		t.Errorf("second record = %+v, want healthy %s", records[1], healthyRepo.Path)
+	}
+
+	if err := store.Prune([]string{healthyRepo.Path}); err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	records, err = store.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(records) != 1 || records[0].Path != healthyRepo.Path {
+		t.Errorf("after prune = %+v, want only %s", records, healthyRepo.Path)
+	}
+}
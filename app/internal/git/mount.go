@@ -0,0 +1,112 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+)
+
+// unavailableStorageErrors are errors from drives and network shares that have
+// gone away rather than from the repository itself
+var unavailableStorageErrors = []error{
+	syscall.ESTALE,
+	syscall.ENOTCONN,
+	syscall.EHOSTDOWN,
+	syscall.EHOSTUNREACH,
+	syscall.ENODEV,
+	syscall.ENXIO,
+	syscall.EIO,
+}
+
+// mountTracker remembers the device each repository was last seen on, so a
+// repository that disappears with its network share or external drive can be
+// told apart from one that was moved or deleted
+type mountTracker struct {
+	mu      sync.Mutex
+	devices map[string]uint64 // Repository path -> device ID
+}
+
+// newMountTracker creates an empty mount tracker
+func newMountTracker() *mountTracker {
+	return &mountTracker{devices: make(map[string]uint64)}
+}
+
+// record remembers the device currently holding a repository
+func (mt *mountTracker) record(path string) {
+	device, ok := deviceOf(path)
+	if !ok {
+		return
+	}
+	mt.mu.Lock()
+	mt.devices[path] = device
+	mt.mu.Unlock()
+}
+
+// unmounted reports whether a poll failure means the drive or share holding a
+// repository is unavailable: the error comes from unavailable storage, or the
+// path is gone and its nearest remaining parent is on a different device than
+// the repository was
+func (mt *mountTracker) unmounted(path string, err error) bool {
+	_, statErr := os.Stat(path)
+	if isUnavailableStorage(err) || isUnavailableStorage(statErr) {
+		return true
+	}
+	if !errors.Is(statErr, os.ErrNotExist) {
+		return false
+	}
+
+	mt.mu.Lock()
+	device, ok := mt.devices[path]
+	mt.mu.Unlock()
+	if !ok {
+		return false
+	}
+	current, ok := deviceOf(nearestExistingParent(path))
+	return ok && current != device
+}
+
+// isUnavailableStorage reports whether err comes from a drive or share that has gone away
+func isUnavailableStorage(err error) bool {
+	for _, target := range unavailableStorageErrors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// pathAvailable reports whether path can currently be accessed
+func pathAvailable(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// deviceOf returns the ID of the device holding path
+func deviceOf(path string) (uint64, bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, false
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return uint64(stat.Dev), true
+}
+
+// nearestExistingParent returns the closest parent directory of path that exists
+func nearestExistingParent(path string) string {
+	dir := filepath.Dir(path)
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,148 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+func TestMountTracker_Unmounted(t *testing.T) {
+	mounts := newMountTracker()
+	repoPath := filepath.Join(t.TempDir(), "repo")
+	if err := os.MkdirAll(repoPath, 0755); err != nil {
+		t.Fatalf("failed to create repository directory: %v", err)
+	}
+	mounts.record(repoPath)
+
+	if !mounts.unmounted(repoPath, fmt.Errorf("failed to open repository: %w", syscall.ESTALE)) {
+		t.Error("stale network handle should mean the share is unavailable")
+	}
+	if mounts.unmounted(repoPath, os.ErrPermission) {
+		t.Error("repository that is still present should not be unmounted")
+	}
+
+	// Deleted from the same device: moved or removed, not unmounted
+	if err := os.RemoveAll(repoPath); err != nil {
+		t.Fatalf("failed to remove repository: %v", err)
+	}
+	if mounts.unmounted(repoPath, os.ErrNotExist) {
+		t.Error("repository deleted from its own device should not be unmounted")
+	}
+
+	// Gone along with the device it was on
+	mounts.devices[repoPath]++
+	if !mounts.unmounted(repoPath, os.ErrNotExist) {
+		t.Error("repository whose device is gone should be unmounted")
+	}
+
+	if mounts.unmounted(filepath.Join(t.TempDir(), "unknown"), os.ErrNotExist) {
+		t.Error("repository never seen should not be unmounted")
+	}
+}
+
+func TestHealthTracker_OfflineRepository(t *testing.T) {
+	repo := Repository{Path: filepath.Join(t.TempDir(), "share"), Name: "share"}
+	tracker := newHealthTracker(nil, logging.NewNoopLogger(), time.Second)
+	now := time.Now()
+
+	// Offline repositories are checked every base interval without backing off
+	for i := 0; i < 5; i++ {
+		health := tracker.recordOffline(repo, syscall.ESTALE, now)
+		if health.Status != RepositoryOffline {
+			t.Fatalf("Status = %q, want %q", health.Status, RepositoryOffline)
+		}
+		if !health.NextRetryAt.Equal(now.Add(time.Second)) {
+			t.Errorf("check %d: NextRetryAt = now+%v, want now+1s", i+1, health.NextRetryAt.Sub(now))
+		}
+	}
+	if !tracker.isOffline(repo.Path) {
+		t.Error("repository should be offline")
+	}
+
+	tracker.recordSuccess(repo)
+	if tracker.isOffline(repo.Path) {
+		t.Error("repository should be back online after a successful poll")
+	}
+}
+
+func TestPollerService_ResumesOfflineRepository(t *testing.T) {
+	checkpoints := newTestCheckpointStore(t)
+	healthStore, err := NewHealthStore(setupTestHealthDB(t), logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("failed to create health store: %v", err)
+	}
+	cfg := &config.Config{Git: config.GitConfig{PollIntervalSeconds: 1}}
+
+	drive := t.TempDir()
+	repoPath := filepath.Join(drive, "mnt", "project")
+	gitRepo, err := createGitRepoWithCommits(t, repoPath, 1)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+
+	first, err := NewPollerService(cfg, logging.NewNoopLogger(), healthStore, checkpoints)
+	if err != nil {
+		t.Fatalf("failed to create poller: %v", err)
+	}
+	if err := first.Start(context.Background(), []Repository{{Path: repoPath, Name: "project"}}); err != nil {
+		t.Fatalf("failed to start poller: %v", err)
+	}
+	first.Stop()
+
+	// The drive goes away after a commit, so discovery no longer finds the repository
+	worktree, _ := gitRepo.Worktree()
+	os.WriteFile(filepath.Join(repoPath, "away.txt"), []byte("content"), 0644)
+	worktree.Add("away.txt")
+	missed, err := worktree.Commit("Commit on the drive", &git.CommitOptions{
+		Author: &object.Signature{Name: "Author", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+	unplugged := filepath.Join(drive, "unplugged")
+	if err := os.Rename(filepath.Join(drive, "mnt"), unplugged); err != nil {
+		t.Fatalf("failed to unplug drive: %v", err)
+	}
+
+	second, err := NewPollerService(cfg, logging.NewNoopLogger(), healthStore, checkpoints)
+	if err != nil {
+		t.Fatalf("failed to create poller: %v", err)
+	}
+	if err := second.Start(context.Background(), nil); err != nil {
+		t.Fatalf("failed to start poller: %v", err)
+	}
+	defer second.Stop()
+
+	records, err := healthStore.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(records) != 1 || records[0].Status != RepositoryOffline {
+		t.Fatalf("health = %+v, want the repository kept as offline", records)
+	}
+	if hashes, _ := checkpoints.Load(); hashes[repoPath] == "" {
+		t.Fatal("checkpoint of the offline repository should be kept")
+	}
+
+	// Polling resumes from the checkpoint once the drive returns
+	if err := os.Rename(unplugged, filepath.Join(drive, "mnt")); err != nil {
+		t.Fatalf("failed to plug drive back in: %v", err)
+	}
+	select {
+	case result := <-second.PollResults():
+		if result.Error != nil || len(result.NewCommits) != 1 || result.NewCommits[0].Hash != missed.String() {
+			t.Errorf("resumed poll = %+v, want the commit made while offline", result)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("poller did not resume the repository when its path returned")
+	}
+}
@@ -0,0 +1,623 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/cursor"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+const (
+	// pipelineMaxAttempts is how many times each pipeline stage is attempted per commit
+	pipelineMaxAttempts = 3
+	// pipelineRetryDelay is the initial delay between stage attempts (doubles each retry)
+	pipelineRetryDelay = 500 * time.Millisecond
+	// parallelExtractMinCommits is the smallest result extracted by a worker pool;
+	// smaller results are processed one commit at a time
+	parallelExtractMinCommits = 8
+	// extractBufferPerWorker bounds memory: at most this many extracted commits per
+	// worker wait to be correlated
+	extractBufferPerWorker = 2
+	// storeBatchSize is how many extracted commits are stored per transaction
+	storeBatchSize = 25
+)
+
+// CommitPipeline consumes poller output and extracts, correlates, and stores each new commit
+type CommitPipeline interface {
+	Start(ctx context.Context, poller PollerService) error
+	Stop() error
+	ProcessResult(result PollResult) error
+	ProcessBatch(batch PollBatch) error
+	OnCommitStored(handler CommitStoredHandler)
+	OnError(handler ErrorHandler)
+	Metrics() PipelineMetrics
+}
+
+// CommitStoredHandler is called after a commit is stored. correlation is nil if correlation failed.
+type CommitStoredHandler func(commit Commit, repository Repository, correlation *CommitSessionCorrelation)
+
+// ErrorHandler is called when polling a repository fails or a commit can't be processed
+type ErrorHandler func(repository Repository, err error)
+
+// PipelineMetrics counts what the commit pipeline has processed since it was created
+type PipelineMetrics struct {
+	CommitsReceived   int       // Commits delivered by the poller
+	CommitsStored     int       // Commits written to the database
+	CommitsCorrelated int       // Stored commits linked to a session
+	CommitsFailed     int       // Commits dropped after exhausting retries
+	Retries           int       // Stage attempts that were retried
+	PollErrors        int       // Poll results that carried a repository error
+	LastStoredAt      time.Time // When the most recent commit was stored
+}
+
+// commitPipeline implements CommitPipeline
+type commitPipeline struct {
+	config         *config.Config
+	logger         logging.Logger
+	extractor      CommitExtractor
+	correlation    CorrelationService
+	storage        CommitStorage
+	sessionManager cursor.SessionManager
+	repos          *repoHandleCache // Shared with the poller once started
+	poller         PollerService    // Acknowledges each result once it is processed (nil until started)
+	retryDelay     time.Duration
+	ctx            context.Context
+	cancel         context.CancelFunc
+	wg             sync.WaitGroup
+	mu             sync.Mutex // Mutex for started, metrics, and handlers
+	started        bool
+	metrics        PipelineMetrics
+	handlers       []CommitStoredHandler
+	errorHandlers  []ErrorHandler
+}
+
+// NewCommitPipeline creates a new commit pipeline.
+// sessionManager is optional; without it commits are stored uncorrelated.
+func NewCommitPipeline(cfg *config.Config, logger logging.Logger, extractor CommitExtractor, correlation CorrelationService, storage CommitStorage, sessionManager cursor.SessionManager) (CommitPipeline, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+	if extractor == nil {
+		return nil, fmt.Errorf("extractor cannot be nil")
+	}
+	if correlation == nil {
+		return nil, fmt.Errorf("correlation service cannot be nil")
+	}
+	if storage == nil {
+		return nil, fmt.Errorf("storage cannot be nil")
+	}
+
+	logger = logger.With("component", "commit_pipeline")
+	return &commitPipeline{
+		config:         cfg,
+		logger:         logger,
+		extractor:      extractor,
+		correlation:    correlation,
+		storage:        storage,
+		sessionManager: sessionManager,
+		repos:          newRepoHandleCache(logger),
+		retryDelay:     pipelineRetryDelay,
+	}, nil
+}
+
+// Start consumes the poller's results (or batches, in batch mode) until the poller stops or Stop is called
+func (cp *commitPipeline) Start(ctx context.Context, poller PollerService) error {
+	if poller == nil {
+		return fmt.Errorf("poller cannot be nil")
+	}
+
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	if cp.started {
+		return fmt.Errorf("commit pipeline is already started")
+	}
+
+	cp.ctx, cp.cancel = context.WithCancel(ctx)
+	cp.poller = poller
+	// Reuse the poller's repository handles rather than reopening each repository per result
+	if source, ok := poller.(handleSource); ok {
+		cp.repos = source.handles()
+	}
+	cp.wg.Add(1)
+	if cp.config.Git.BatchPollResults {
+		go cp.consumeBatches(poller.PollBatches())
+	} else {
+		go cp.consumeResults(poller.PollResults())
+	}
+
+	cp.started = true
+	cp.logger.Info("commit pipeline started", "batch_mode", cp.config.Git.BatchPollResults)
+	return nil
+}
+
+// consumeResults processes per-repository poll results until the channel closes
+func (cp *commitPipeline) consumeResults(results <-chan PollResult) {
+	defer cp.wg.Done()
+
+	for {
+		select {
+		case <-cp.ctx.Done():
+			cp.drainResults(results)
+			return
+		case result, ok := <-results:
+			if !ok {
+				cp.logger.Debug("poll results channel closed, pipeline stopping")
+				return
+			}
+			cp.consumeResult(result)
+		}
+	}
+}
+
+// drainResults processes the results already delivered when the pipeline stops
+func (cp *commitPipeline) drainResults(results <-chan PollResult) {
+	for {
+		select {
+		case result, ok := <-results:
+			if !ok {
+				return
+			}
+			cp.consumeResult(result)
+		default:
+			return
+		}
+	}
+}
+
+// consumeResult processes one poll result, logging a failure
+func (cp *commitPipeline) consumeResult(result PollResult) {
+	if err := cp.ProcessResult(result); err != nil {
+		cp.logger.Warn("failed to process poll result", "repository", result.Repository.Path, "error", err)
+	}
+}
+
+// consumeBatches processes per-cycle poll batches until the channel closes
+func (cp *commitPipeline) consumeBatches(batches <-chan PollBatch) {
+	defer cp.wg.Done()
+
+	for {
+		select {
+		case <-cp.ctx.Done():
+			cp.drainBatches(batches)
+			return
+		case batch, ok := <-batches:
+			if !ok {
+				cp.logger.Debug("poll batches channel closed, pipeline stopping")
+				return
+			}
+			cp.consumeBatch(batch)
+		}
+	}
+}
+
+// drainBatches processes the batches already delivered when the pipeline stops
+func (cp *commitPipeline) drainBatches(batches <-chan PollBatch) {
+	for {
+		select {
+		case batch, ok := <-batches:
+			if !ok {
+				return
+			}
+			cp.consumeBatch(batch)
+		default:
+			return
+		}
+	}
+}
+
+// consumeBatch processes one poll batch, logging a failure
+func (cp *commitPipeline) consumeBatch(batch PollBatch) {
+	if err := cp.ProcessBatch(batch); err != nil {
+		cp.logger.Warn("failed to process poll batch", "repositories", len(batch.Repositories), "error", err)
+	}
+}
+
+// Stop stops consuming poller output and waits for the in-flight commit to finish.
+// Output the poller already delivered is processed first, without retries.
+func (cp *commitPipeline) Stop() error {
+	cp.mu.Lock()
+	if !cp.started {
+		cp.mu.Unlock()
+		return nil
+	}
+	cp.started = false
+	cp.mu.Unlock()
+
+	cp.cancel()
+	cp.wg.Wait()
+
+	metrics := cp.Metrics()
+	cp.logger.Info("commit pipeline stopped", "received", metrics.CommitsReceived, "stored", metrics.CommitsStored,
+		"correlated", metrics.CommitsCorrelated, "failed", metrics.CommitsFailed, "retries", metrics.Retries)
+	return nil
+}
+
+// ProcessResult extracts, correlates, and stores the commits in one poll result,
+// then acknowledges the result's range to the poller so it is checkpointed only once
+// stored. Returns an error if any commit could not be stored.
+func (cp *commitPipeline) ProcessResult(result PollResult) error {
+	if result.Error != nil {
+		cp.mu.Lock()
+		cp.metrics.PollErrors++
+		cp.mu.Unlock()
+		cp.logger.Debug("skipping poll result with error", "repository", result.Repository.Path, "error", result.Error)
+		cp.reportError(result.Repository, result.Error)
+		return nil
+	}
+
+	err := cp.processCommits(result.Repository, result.NewCommits, result.GapFill)
+	if cp.poller != nil {
+		cp.poller.Acknowledge(result.Repository.Path, result.Range, err == nil)
+	}
+	return err
+}
+
+// ProcessBatch processes every repository in a poll batch.
+// Returns the first error, after attempting all repositories.
+func (cp *commitPipeline) ProcessBatch(batch PollBatch) error {
+	var firstErr error
+	for path, repository := range batch.Repositories {
+		err := cp.ProcessResult(PollResult{
+			Repository: repository,
+			NewCommits: batch.Commits[path],
+			Error:      batch.Errors[path],
+			GapFill:    batch.GapFill,
+			Range:      batch.Ranges[path],
+		})
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// processCommits runs each commit through the pipeline. Gap-filled commits may
+// also be attributed to sessions that ended shortly before them.
+func (cp *commitPipeline) processCommits(repository Repository, commits []Commit, gapFill bool) error {
+	if len(commits) == 0 {
+		return nil
+	}
+
+	cp.mu.Lock()
+	cp.metrics.CommitsReceived += len(commits)
+	cp.mu.Unlock()
+
+	handle, _, err := cp.repos.acquire(repository.Path)
+	if err != nil {
+		err = fmt.Errorf("failed to open repository %s: %w", repository.Path, err)
+		cp.recordFailures(repository, len(commits), err)
+		return err
+	}
+	defer cp.repos.release(handle)
+
+	var opts CorrelationOptions
+	if gapFill {
+		opts.PostSessionWindow = time.Duration(cp.config.Git.PostSessionWindowMinutes) * time.Minute
+	}
+
+	var failed int
+	if workers := cp.config.Git.ExtractWorkers; workers > 1 && len(commits) >= parallelExtractMinCommits {
+		failed = cp.processCommitsParallel(repository, commits, opts, workers)
+	} else {
+		for _, commit := range commits {
+			if err := cp.processCommit(handle.repo, repository, commit, opts); err != nil {
+				failed++
+				cp.recordFailures(repository, 1, err)
+				cp.logger.Warn("failed to process commit", "repository", repository.Path, "commit", commit.Hash, "error", err)
+			}
+		}
+	}
+
+	if failed > 0 {
+		// The cached handle may be stale (e.g. after a repack), so the next result reopens the repository
+		cp.repos.invalidate(repository.Path)
+		return fmt.Errorf("failed to process %d of %d commits in %s", failed, len(commits), repository.Path)
+	}
+	return nil
+}
+
+// extraction is the outcome of extracting one commit in a worker pool
+type extraction struct {
+	info *CommitInfo
+	err  error
+}
+
+// processCommitsParallel extracts commits across a pool of workers, then correlates
+// them in order and stores them in batches. Extraction runs ahead of storage by at
+// most extractBufferPerWorker commits per worker, so memory stays bounded however
+// many commits there are. Returns the number of commits that failed.
+func (cp *commitPipeline) processCommitsParallel(repository Repository, commits []Commit, opts CorrelationOptions, workers int) int {
+	if workers > len(commits) {
+		workers = len(commits)
+	}
+	cp.logger.Debug("extracting commits in parallel", "repository", repository.Path, "count", len(commits), "workers", workers)
+
+	// Each commit gets its own result slot so results are consumed in commit order
+	results := make([]chan extraction, len(commits))
+	for i := range results {
+		results[i] = make(chan extraction, 1)
+	}
+	slots := make(chan struct{}, workers*extractBufferPerWorker)
+	jobs := make(chan int)
+
+	go func() {
+		defer close(jobs)
+		for i := range commits {
+			slots <- struct{}{}
+			jobs <- i
+		}
+	}()
+	for w := 0; w < workers; w++ {
+		go func() {
+			// A go-git repository isn't safe for concurrent use, so each worker opens its own
+			repo, openErr := git.PlainOpen(repository.Path)
+			for i := range jobs {
+				if openErr != nil {
+					results[i] <- extraction{err: fmt.Errorf("failed to open repository: %w", openErr)}
+					continue
+				}
+				info, err := cp.extract(repo, commits[i])
+				results[i] <- extraction{info: info, err: err}
+			}
+		}()
+	}
+
+	var failed int
+	var batch []CommitRecord
+	for i, commit := range commits {
+		result := <-results[i]
+		<-slots
+		if result.err != nil {
+			failed++
+			err := fmt.Errorf("failed to extract commit: %w", result.err)
+			cp.recordFailures(repository, 1, err)
+			cp.logger.Warn("failed to process commit", "repository", repository.Path, "commit", commit.Hash, "error", err)
+			continue
+		}
+
+		batch = append(batch, cp.correlate(result.info, commit, repository, opts))
+		if len(batch) == storeBatchSize {
+			failed += cp.storeBatch(batch)
+			batch = nil
+		}
+	}
+	if len(batch) > 0 {
+		failed += cp.storeBatch(batch)
+	}
+	return failed
+}
+
+// storeBatch stores records in one transaction, falling back to storing them one at
+// a time with retries if the batch fails. Returns the number of records not stored.
+func (cp *commitPipeline) storeBatch(batch []CommitRecord) int {
+	err := cp.storage.StoreCommits(batch)
+	if err == nil {
+		for _, record := range batch {
+			cp.commitStored(record)
+		}
+		return 0
+	}
+	cp.logger.Debug("failed to store commit batch, storing commits one at a time", "count", len(batch), "error", err)
+
+	var failed int
+	for _, record := range batch {
+		if err := cp.store(record); err != nil {
+			failed++
+			cp.recordFailures(*record.Repository, 1, err)
+			cp.logger.Warn("failed to process commit", "repository", record.Repository.Path, "commit", record.Commit.Hash, "error", err)
+			continue
+		}
+		cp.commitStored(record)
+	}
+	return failed
+}
+
+// processCommit extracts, correlates, and stores a single commit
+func (cp *commitPipeline) processCommit(repo *git.Repository, repository Repository, commit Commit, opts CorrelationOptions) error {
+	info, err := cp.extract(repo, commit)
+	if err != nil {
+		return fmt.Errorf("failed to extract commit: %w", err)
+	}
+
+	record := cp.correlate(info, commit, repository, opts)
+	if err := cp.store(record); err != nil {
+		return err
+	}
+	cp.commitStored(record)
+	return nil
+}
+
+// extract extracts a commit's metadata and diff, with retries
+func (cp *commitPipeline) extract(repo *git.Repository, commit Commit) (*CommitInfo, error) {
+	hash := plumbing.NewHash(commit.Hash)
+
+	var info *CommitInfo
+	err := cp.withRetry("extract", commit.Hash, func() error {
+		var err error
+		info, err = cp.extractor.ExtractCommit(repo, hash)
+		return err
+	})
+	return info, err
+}
+
+// correlate links an extracted commit to a session, returning it ready to store.
+// A commit that fails to correlate is stored without a session rather than lost.
+func (cp *commitPipeline) correlate(info *CommitInfo, commit Commit, repository Repository, opts CorrelationOptions) CommitRecord {
+	var correlation *CommitSessionCorrelation
+	if err := cp.withRetry("correlate", commit.Hash, func() error {
+		var err error
+		correlation, err = cp.correlation.CorrelateCommitWithOptions(info.Commit, repository, cp.sessionManager, opts)
+		return err
+	}); err != nil {
+		// Store the commit uncorrelated rather than losing it
+		cp.logger.Warn("failed to correlate commit, storing without session", "commit", commit.Hash, "error", err)
+		correlation = nil
+	}
+
+	sessionID := ""
+	if correlation != nil {
+		sessionID = correlation.SessionID
+	}
+
+	storable, diff := toStorableCommit(info, commit)
+	return CommitRecord{Commit: storable, Diff: diff, Correlation: correlation, Repository: &repository, SessionID: sessionID}
+}
+
+// store stores a single record, with retries
+func (cp *commitPipeline) store(record CommitRecord) error {
+	if err := cp.withRetry("store", record.Commit.Hash, func() error {
+		return cp.storage.StoreCommit(record.Commit, record.Diff, record.Correlation, record.Repository, record.SessionID)
+	}); err != nil {
+		return fmt.Errorf("failed to store commit: %w", err)
+	}
+	return nil
+}
+
+// commitStored updates the metrics for a stored record and calls the registered handlers
+func (cp *commitPipeline) commitStored(record CommitRecord) {
+	cp.mu.Lock()
+	cp.metrics.CommitsStored++
+	if record.SessionID != "" {
+		cp.metrics.CommitsCorrelated++
+	}
+	cp.metrics.LastStoredAt = time.Now()
+	handlers := cp.handlers
+	cp.mu.Unlock()
+
+	for _, handler := range handlers {
+		handler(*record.Commit, *record.Repository, record.Correlation)
+	}
+}
+
+// OnCommitStored registers a handler called after each commit is stored
+func (cp *commitPipeline) OnCommitStored(handler CommitStoredHandler) {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	cp.handlers = append(cp.handlers, handler)
+}
+
+// OnError registers a handler called when a poll fails or a commit can't be processed
+func (cp *commitPipeline) OnError(handler ErrorHandler) {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	cp.errorHandlers = append(cp.errorHandlers, handler)
+}
+
+// withRetry runs fn up to pipelineMaxAttempts times with exponential backoff
+func (cp *commitPipeline) withRetry(stage, commitHash string, fn func() error) error {
+	var err error
+	for attempt := 0; attempt < pipelineMaxAttempts; attempt++ {
+		if attempt > 0 {
+			cp.mu.Lock()
+			cp.metrics.Retries++
+			cp.mu.Unlock()
+
+			delay := cp.retryDelay * time.Duration(1<<uint(attempt-1))
+			cp.logger.Debug("retrying pipeline stage", "stage", stage, "commit", commitHash, "attempt", attempt+1, "delay_ms", delay.Milliseconds())
+			if !cp.sleep(delay) {
+				return fmt.Errorf("%s cancelled: %w", stage, err)
+			}
+		}
+
+		if err = fn(); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// sleep waits for d, returning false if the pipeline is stopped first
+func (cp *commitPipeline) sleep(d time.Duration) bool {
+	if cp.ctx == nil {
+		time.Sleep(d)
+		return true
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-cp.ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// recordFailures adds n to the failed commit count and reports their error
+func (cp *commitPipeline) recordFailures(repository Repository, n int, err error) {
+	cp.mu.Lock()
+	cp.metrics.CommitsFailed += n
+	cp.mu.Unlock()
+	cp.reportError(repository, err)
+}
+
+// reportError calls the registered error handlers
+func (cp *commitPipeline) reportError(repository Repository, err error) {
+	cp.mu.Lock()
+	handlers := cp.errorHandlers
+	cp.mu.Unlock()
+
+	for _, handler := range handlers {
+		handler(repository, err)
+	}
+}
+
+// Metrics returns a snapshot of the pipeline's counters
+func (cp *commitPipeline) Metrics() PipelineMetrics {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	return cp.metrics
+}
+
+// toStorableCommit converts extracted commit information into the storage representation,
+// falling back to the poller's commit for fields the extractor left empty
+func toStorableCommit(info *CommitInfo, polled Commit) (*Commit, *CommitDiff) {
+	commit := &Commit{
+		Hash:      info.Commit.Hash,
+		Message:   info.Commit.Message,
+		Author:    info.Commit.Author.Name,
+		Email:     info.Commit.Author.Email,
+		Timestamp: info.Commit.Timestamp,
+		Branch:    info.Commit.Branch,
+		IsMerge:   info.Commit.IsMerge,
+		Parents:   info.Commit.ParentHashes,
+	}
+	if commit.Hash == "" {
+		commit.Hash = polled.Hash
+	}
+	if commit.Branch == "" || commit.Branch == "detached" {
+		if polled.Branch != "" {
+			commit.Branch = polled.Branch
+		}
+	}
+
+	diff := &CommitDiff{
+		CommitHash:  commit.Hash,
+		FullDiff:    info.Diff.Content,
+		IsTruncated: info.Diff.Truncated,
+	}
+	if info.Diff.Truncated {
+		diff.TruncatedAt = info.Diff.ShownLines
+	}
+	for _, file := range info.Diff.Files {
+		diff.Files = append(diff.Files, FileDiff{
+			Path:         file.Path,
+			LinesAdded:   file.Additions,
+			LinesRemoved: file.Deletions,
+		})
+	}
+
+	return commit, diff
+}
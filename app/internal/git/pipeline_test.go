@@ -0,0 +1,375 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+// flakyStorage fails the first failures StoreCommit calls before delegating
+type flakyStorage struct {
+	CommitStorage
+	failures int
+	calls    int
+}
+
+func (fs *flakyStorage) StoreCommit(commit *Commit, diff *CommitDiff, correlation *CommitSessionCorrelation, repository *Repository, sessionID string) error {
+	fs.calls++
+	if fs.calls <= fs.failures {
+		return errors.New("database is locked")
+	}
+	return fs.CommitStorage.StoreCommit(commit, diff, correlation, repository, sessionID)
+}
+
+func newTestPipeline(t *testing.T, failures int) (*commitPipeline, CommitStorage) {
+	t.Helper()
+
+	database, cleanup := setupTestCorrelationDB(t)
+	t.Cleanup(cleanup)
+	database.SetMaxOpenConns(1)
+
+	logger := logging.NewNoopLogger()
+	extractor, err := NewCommitExtractor(logger)
+	if err != nil {
+		t.Fatalf("failed to create extractor: %v", err)
+	}
+	correlation, err := NewCorrelationService(logger, database, nil)
+	if err != nil {
+		t.Fatalf("failed to create correlation service: %v", err)
+	}
+	storage, err := NewCommitStorage(database, logger)
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+
+	pipeline, err := NewCommitPipeline(&config.Config{}, logger, extractor, correlation,
+		&flakyStorage{CommitStorage: storage, failures: failures}, createMockSessionManager(t, database))
+	if err != nil {
+		t.Fatalf("failed to create pipeline: %v", err)
+	}
+	cp := pipeline.(*commitPipeline)
+	cp.retryDelay = time.Millisecond
+	return cp, storage
+}
+
+func TestCommitPipeline_ProcessResultStoresCommits(t *testing.T) {
+	pipeline, storage := newTestPipeline(t, 1)
+
+	repoPath := filepath.Join(t.TempDir(), "project")
+	repo, err := createGitRepoWithCommits(t, repoPath, 1)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("failed to get HEAD: %v", err)
+	}
+
+	repository := Repository{Path: repoPath, Name: "project"}
+	result := PollResult{Repository: repository, NewCommits: []Commit{{Hash: head.Hash().String(), Branch: "master"}}}
+	if err := pipeline.ProcessResult(result); err != nil {
+		t.Fatalf("ProcessResult() error = %v", err)
+	}
+
+	stored, err := storage.GetCommit(head.Hash().String())
+	if err != nil {
+		t.Fatalf("GetCommit() error = %v", err)
+	}
+	if stored.Message != "Test commit" || stored.AuthorEmail != "test@example.com" {
+		t.Errorf("stored commit = %q by %q, want extracted metadata", stored.Message, stored.AuthorEmail)
+	}
+	if len(stored.Files) != 1 || stored.Files[0].FilePath != "test.txt" {
+		t.Errorf("stored files = %+v, want test.txt", stored.Files)
+	}
+
+	metrics := pipeline.Metrics()
+	if metrics.CommitsReceived != 1 || metrics.CommitsStored != 1 || metrics.CommitsFailed != 0 {
+		t.Errorf("metrics = %+v, want 1 received and stored", metrics)
+	}
+	if metrics.Retries != 1 {
+		t.Errorf("retries = %d, want 1 after a transient storage failure", metrics.Retries)
+	}
+}
+
+func TestCommitPipeline_FailuresAndPollErrors(t *testing.T) {
+	pipeline, _ := newTestPipeline(t, pipelineMaxAttempts)
+
+	repoPath := filepath.Join(t.TempDir(), "project")
+	repo, err := createGitRepoWithCommits(t, repoPath, 1)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	head, _ := repo.Head()
+
+	repository := Repository{Path: repoPath, Name: "project"}
+	batch := newPollBatch(time.Now())
+	batch.add(PollResult{Repository: repository, NewCommits: []Commit{{Hash: head.Hash().String()}}})
+	batch.add(PollResult{Repository: Repository{Path: "/missing"}, Error: errors.New("repository not found")})
+
+	reported := make(map[string]int)
+	pipeline.OnError(func(repository Repository, err error) {
+		reported[repository.Path]++
+	})
+
+	if err := pipeline.ProcessBatch(*batch); err == nil {
+		t.Error("ProcessBatch() should report commits that exhausted their retries")
+	}
+
+	metrics := pipeline.Metrics()
+	if metrics.CommitsFailed != 1 || metrics.CommitsStored != 0 {
+		t.Errorf("metrics = %+v, want 1 failed and none stored", metrics)
+	}
+	if metrics.PollErrors != 1 {
+		t.Errorf("poll errors = %d, want 1", metrics.PollErrors)
+	}
+	if reported[repoPath] != 1 || reported["/missing"] != 1 {
+		t.Errorf("reported errors = %v, want the failed commit and the poll error", reported)
+	}
+}
+
+func TestCommitPipeline_ProcessResultInParallel(t *testing.T) {
+	pipeline, storage := newTestPipeline(t, 0)
+	pipeline.config.Git.ExtractWorkers = 3
+
+	repoPath := filepath.Join(t.TempDir(), "project")
+	repo, err := createGitRepoWithCommits(t, repoPath, parallelExtractMinCommits+2)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	history, err := repo.Log(&git.LogOptions{})
+	if err != nil {
+		t.Fatalf("failed to read history: %v", err)
+	}
+	var commits []Commit
+	if err := history.ForEach(func(c *object.Commit) error {
+		commits = append(commits, Commit{Hash: c.Hash.String(), Branch: "master"})
+		return nil
+	}); err != nil {
+		t.Fatalf("failed to read history: %v", err)
+	}
+	// One commit the workers can't extract
+	commits = append(commits, Commit{Hash: strings.Repeat("0", 40)})
+
+	var order []string
+	pipeline.OnCommitStored(func(commit Commit, repository Repository, correlation *CommitSessionCorrelation) {
+		order = append(order, commit.Hash)
+	})
+
+	repository := Repository{Path: repoPath, Name: "project"}
+	if err := pipeline.ProcessResult(PollResult{Repository: repository, NewCommits: commits, GapFill: true}); err == nil {
+		t.Error("ProcessResult() should report the commit that couldn't be extracted")
+	}
+
+	stored := len(commits) - 1
+	metrics := pipeline.Metrics()
+	if metrics.CommitsStored != stored || metrics.CommitsFailed != 1 {
+		t.Errorf("metrics = %+v, want %d stored and 1 failed", metrics, stored)
+	}
+	for i, hash := range order {
+		if hash != commits[i].Hash {
+			t.Fatalf("commit %d stored = %s, want %s: commits should be stored in order", i, hash, commits[i].Hash)
+		}
+	}
+	if len(order) != stored {
+		t.Errorf("handlers called for %d commits, want %d", len(order), stored)
+	}
+	for _, commit := range commits[:stored] {
+		if _, err := storage.GetCommit(commit.Hash); err != nil {
+			t.Errorf("GetCommit(%s) error = %v", commit.Hash, err)
+		}
+	}
+}
+
+func TestCommitPipeline_SharesPollerHandles(t *testing.T) {
+	pipeline, _ := newTestPipeline(t, 0)
+
+	service, err := NewPollerService(&config.Config{Git: config.GitConfig{PollIntervalSeconds: 60}}, logging.NewNoopLogger(), nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create poller: %v", err)
+	}
+	if err := pipeline.Start(context.Background(), service); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer pipeline.Stop()
+
+	repoPath := filepath.Join(t.TempDir(), "project")
+	repo, err := createGitRepoWithCommits(t, repoPath, 1)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	head, _ := repo.Head()
+
+	cache := service.(*poller).repos
+	if pipeline.repos != cache {
+		t.Fatal("pipeline should use the poller's repository handle cache")
+	}
+	result := PollResult{Repository: Repository{Path: repoPath, Name: "project"}, NewCommits: []Commit{{Hash: head.Hash().String()}}}
+	for i := 0; i < 2; i++ {
+		if err := pipeline.ProcessResult(result); err != nil {
+			t.Fatalf("ProcessResult() error = %v", err)
+		}
+	}
+
+	opened := 0
+	cache.open = func(path string) (*git.Repository, error) {
+		opened++
+		return git.PlainOpen(path)
+	}
+	if err := pipeline.ProcessResult(result); err != nil {
+		t.Fatalf("ProcessResult() error = %v", err)
+	}
+	if opened != 0 {
+		t.Errorf("repository opened %d times, want the cached handle reused", opened)
+	}
+}
+
+func TestCommitPipeline_FailedStoreIsPolledAgain(t *testing.T) {
+	// Every attempt for the first commit fails, so the first delivery isn't stored
+	pipeline, storage := newTestPipeline(t, pipelineMaxAttempts)
+	checkpoints := newTestCheckpointStore(t)
+
+	service, err := NewPollerService(&config.Config{Git: config.GitConfig{PollIntervalSeconds: 60}}, logging.NewNoopLogger(), nil, checkpoints)
+	if err != nil {
+		t.Fatalf("failed to create poller: %v", err)
+	}
+	p := service.(*poller)
+
+	repoPath := filepath.Join(t.TempDir(), "project")
+	gitRepo, err := createGitRepoWithCommits(t, repoPath, 1)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	base, _ := gitRepo.Head()
+	repos := []Repository{{Path: repoPath, Name: "project"}}
+	p.pollAllRepositories(repos)
+
+	failed := make(chan struct{}, 1)
+	stored := make(chan struct{}, 1)
+	notify := func(ch chan struct{}) {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+	pipeline.OnError(func(Repository, error) { notify(failed) })
+	pipeline.OnCommitStored(func(Commit, Repository, *CommitSessionCorrelation) { notify(stored) })
+	if err := pipeline.Start(context.Background(), service); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer pipeline.Stop()
+
+	worktree, _ := gitRepo.Worktree()
+	os.WriteFile(filepath.Join(repoPath, "new.txt"), []byte("content"), 0644)
+	worktree.Add("new.txt")
+	hash, err := worktree.Commit("New commit", &git.CommitOptions{
+		Author: &object.Signature{Name: "Author", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+
+	checkpointIs := func(want string) bool {
+		hashes, err := checkpoints.Load()
+		return err == nil && hashes[repoPath] == want
+	}
+
+	p.pollAllRepositories(repos)
+	select {
+	case <-failed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the commit to fail")
+	}
+	if !checkpointIs(base.Hash().String()) {
+		t.Fatal("checkpoint advanced past a commit that wasn't stored")
+	}
+
+	// A later poll delivers the commit again once the failure is acknowledged,
+	// and the checkpoint follows once it's stored
+	deadline := time.Now().Add(5 * time.Second)
+	for delivered := false; !delivered; {
+		if time.Now().After(deadline) {
+			t.Fatal("commit that wasn't stored was not polled again")
+		}
+		p.pollAllRepositories(repos)
+		select {
+		case <-stored:
+			delivered = true
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+	for !checkpointIs(hash.String()) {
+		if time.Now().After(deadline) {
+			t.Fatal("checkpoint did not advance after the commit was stored")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, err := storage.GetCommit(hash.String()); err != nil {
+		t.Errorf("GetCommit() error = %v", err)
+	}
+}
+
+func TestCommitPipeline_StopDrainsDeliveredResults(t *testing.T) {
+	pipeline, storage := newTestPipeline(t, 0)
+
+	service, err := NewPollerService(&config.Config{Git: config.GitConfig{PollIntervalSeconds: 60}}, logging.NewNoopLogger(), nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create poller: %v", err)
+	}
+	p := service.(*poller)
+
+	repoPath := filepath.Join(t.TempDir(), "project")
+	repo, err := createGitRepoWithCommits(t, repoPath, 3)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	history, err := repo.Log(&git.LogOptions{})
+	if err != nil {
+		t.Fatalf("failed to read history: %v", err)
+	}
+	var hashes []string
+	history.ForEach(func(c *object.Commit) error {
+		hashes = append(hashes, c.Hash.String())
+		return nil
+	})
+
+	// The first commit holds up the pipeline while the rest wait in the channel
+	release := make(chan struct{})
+	var once bool
+	pipeline.OnCommitStored(func(Commit, Repository, *CommitSessionCorrelation) {
+		if !once {
+			once = true
+			<-release
+		}
+	})
+	if err := pipeline.Start(context.Background(), service); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	repository := Repository{Path: repoPath, Name: "project"}
+	for _, hash := range hashes {
+		p.pollResults <- PollResult{Repository: repository, NewCommits: []Commit{{Hash: hash, Branch: "master"}}}
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		pipeline.Stop()
+		close(stopped)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	<-stopped
+
+	for _, hash := range hashes {
+		if _, err := storage.GetCommit(hash); err != nil {
+			t.Errorf("GetCommit(%s) error = %v, want results delivered before Stop stored", hash, err)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+func TestPollerService_BatchMode(t *testing.T) {
+	cfg := &config.Config{
+		Git: config.GitConfig{
+			PollIntervalSeconds: 1,
+			BatchPollResults:    true,
+		},
+	}
+
+	service, err := NewPollerService(cfg, logging.NewNoopLogger(), nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create poller: %v", err)
+	}
+	p := service.(*poller)
+
+	tmpDir := t.TempDir()
+	repoPaths := []string{filepath.Join(tmpDir, "repo1"), filepath.Join(tmpDir, "repo2")}
+	var repos []Repository
+	var gitRepos []*git.Repository
+	for _, path := range repoPaths {
+		gitRepo, err := createGitRepoWithCommits(t, path, 1)
+		if err != nil {
+			t.Fatalf("failed to create repository: %v", err)
+		}
+		gitRepos = append(gitRepos, gitRepo)
+		repos = append(repos, Repository{Path: path, Name: filepath.Base(path), GitDir: filepath.Join(path, ".git")})
+	}
+	repos = append(repos, Repository{Path: filepath.Join(tmpDir, "missing"), Name: "missing"})
+
+	// First cycle records HEADs; only the missing repository produces a result
+	p.pollAllRepositories(repos)
+	batch := <-service.PollBatches()
+	if len(batch.Commits) != 0 || len(batch.Errors) != 1 {
+		t.Fatalf("first batch: %d commits, %d errors, want 0 and 1", len(batch.Commits), len(batch.Errors))
+	}
+
+	for i, gitRepo := range gitRepos {
+		worktree, _ := gitRepo.Worktree()
+		os.WriteFile(filepath.Join(repoPaths[i], "new.txt"), []byte("content"), 0644)
+		worktree.Add("new.txt")
+		if _, err := worktree.Commit("New commit", &git.CommitOptions{
+			Author: &object.Signature{Name: "Author", Email: "test@example.com", When: time.Now()},
+		}); err != nil {
+			t.Fatalf("failed to commit: %v", err)
+		}
+	}
+
+	// Second cycle delivers both repositories' commits in one batch
+	p.pollAllRepositories(repos[:2])
+	batch = <-service.PollBatches()
+	for _, path := range repoPaths {
+		if len(batch.Commits[path]) != 1 {
+			t.Errorf("batch commits for %s = %d, want 1", path, len(batch.Commits[path]))
+		}
+		if _, ok := batch.Repositories[path]; !ok {
+			t.Errorf("batch missing repository %s", path)
+		}
+	}
+	if batch.StartedAt.IsZero() {
+		t.Error("batch should record the cycle start time")
+	}
+
+	// Quiet cycles emit nothing, and per-repository results are not used
+	p.pollAllRepositories(repos[:2])
+	select {
+	case batch := <-service.PollBatches():
+		t.Errorf("quiet cycle emitted a batch with %d repositories", len(batch.Repositories))
+	case result := <-service.PollResults():
+		t.Errorf("batch mode emitted a per-repository result for %s", result.Repository.Path)
+	default:
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -22,6 +24,10 @@ const (
 	minPollInterval = 1 * time.Second
 	// pollResultChanBuffer is the buffer size for the poll results channel
 	pollResultChanBuffer = 10
+	// pollBatchChanBuffer is the buffer size for the poll batches channel
+	pollBatchChanBuffer = 10
+	// defaultMaxGapFillCommits bounds the commits ingested per repository on startup if not configured
+	defaultMaxGapFillCommits = 500
 )
 
 // PollerService defines the interface for polling git repositories for new commits
@@ -29,6 +35,9 @@ type PollerService interface {
 	Start(ctx context.Context, repos []Repository) error
 	Stop() error
 	PollResults() <-chan PollResult
+	PollBatches() <-chan PollBatch
+	NotifyActivity(repoPath string)
+	Acknowledge(repoPath string, r PollRange, stored bool)
 }
 
 // PollResult represents the result of polling a repository
@@ -36,6 +45,61 @@ type PollResult struct {
 	Repository Repository
 	NewCommits []Commit
 	Error      error
+	GapFill    bool      // Commits were made while the poller was stopped
+	Range      PollRange // History the commits were read from, acknowledged once they are stored
+}
+
+// PollRange is the span of a repository's history covered by a poll: the commits
+// after From up to and including To
+type PollRange struct {
+	From string
+	To   string
+}
+
+// PollBatch holds every result from one polling cycle, keyed by repository path.
+// Emitted on PollBatches instead of per-repository PollResults when git.batch_poll_results is enabled.
+type PollBatch struct {
+	StartedAt    time.Time
+	Repositories map[string]Repository // Every repository with new commits or an error
+	Commits      map[string][]Commit   // New commits per repository
+	Errors       map[string]error      // Poll errors per repository
+	Ranges       map[string]PollRange  // History covered per repository with commits
+	GapFill      bool                  // Commits were made while the poller was stopped
+}
+
+// newPollBatch creates an empty batch for a cycle starting at startedAt
+func newPollBatch(startedAt time.Time) *PollBatch {
+	return &PollBatch{
+		StartedAt:    startedAt,
+		Repositories: make(map[string]Repository),
+		Commits:      make(map[string][]Commit),
+		Errors:       make(map[string]error),
+		Ranges:       make(map[string]PollRange),
+	}
+}
+
+// add records a repository's result in the batch
+func (b *PollBatch) add(result PollResult) {
+	path := result.Repository.Path
+	b.Repositories[path] = result.Repository
+	if result.Error != nil {
+		b.Errors[path] = result.Error
+	}
+	if len(result.NewCommits) > 0 {
+		b.Commits[path] = append(b.Commits[path], result.NewCommits...)
+	}
+	if result.Range.To != "" {
+		r := result.Range
+		if existing, ok := b.Ranges[path]; ok {
+			r.From = existing.From
+		}
+		b.Ranges[path] = r
+	}
+}
+
+// Empty reports whether the cycle produced no commits or errors
+func (b *PollBatch) Empty() bool {
+	return len(b.Repositories) == 0
 }
 
 // poller implements PollerService for polling git repositories
@@ -46,17 +110,42 @@ type poller struct {
 	ticker         *time.Ticker
 	done           chan struct{}
 	pollResults    chan PollResult
+	pollBatches    chan PollBatch
+	batchMode      bool       // Emit one PollBatch per cycle instead of per-repository results
+	batch          *PollBatch // Results of the cycle in progress (batch mode only)
+	batchMu        sync.Mutex // Mutex for batch
 	started        bool
 	mu             sync.Mutex
 	wg             sync.WaitGroup
 	ctx            context.Context
 	cancel         context.CancelFunc
 	lastSeenHashes map[string]string // Repository path -> last seen commit hash
-	stateMu        sync.RWMutex      // Mutex for lastSeenHashes
+	confirmed      map[string]string // Repository path -> last hash whose commits are all stored
+	dirtyHashes    map[string]string // Confirmed hashes not yet checkpointed
+	stateMu        sync.RWMutex      // Mutex for lastSeenHashes, confirmed, and dirtyHashes
+	saveMu         sync.Mutex        // Serializes checkpoint saves so an older hash can't overwrite a newer one
+	health         *healthTracker    // Backs off and reports repositories that keep failing
+	breaker        *circuitBreaker   // Suspends repositories that exhaust their error budget
+	schedule       *pollSchedule     // Per-repository adaptive intervals (nil when adaptive polling is off)
+	repos          *repoHandleCache  // Shared repository handles reused across polls
+	mounts         *mountTracker     // Devices repositories were last seen on, to detect unmounted drives and shares
+	healthStore    HealthStore       // Optional persistence for repository health
+	checkpoints    CheckpointStore   // Optional persistence for last seen hashes
+	gaps           []commitGap       // Repositories that gained commits while the poller was stopped
+	maxGapFill     int               // Most recent commits ingested per gap
+}
+
+// commitGap is the range of commits made in a repository while the poller was stopped
+type commitGap struct {
+	repo Repository
+	from string // Checkpointed hash
+	to   string // HEAD at startup
 }
 
-// NewPollerService creates a new poller service instance
-func NewPollerService(cfg *config.Config, logger logging.Logger) (PollerService, error) {
+// NewPollerService creates a new poller service instance.
+// healthStore and checkpoints are optional; when nil, repository health and
+// last seen hashes are only tracked in memory.
+func NewPollerService(cfg *config.Config, logger logging.Logger, healthStore HealthStore, checkpoints CheckpointStore) (PollerService, error) {
 	if cfg == nil {
 		return nil, fmt.Errorf("config cannot be nil")
 	}
@@ -81,14 +170,44 @@ func NewPollerService(cfg *config.Config, logger logging.Logger) (PollerService,
 		componentLogger.Warn("polling interval too small, using minimum", "requested_seconds", intervalSeconds, "minimum_seconds", int(minPollInterval.Seconds()))
 	}
 
+	// With adaptive polling the ticker runs at the minimum interval and each
+	// repository is polled when its own interval has elapsed
+	var schedule *pollSchedule
+	tickInterval := interval
+	if cfg.Git.AdaptivePolling {
+		minInterval := time.Duration(max(cfg.Git.MinPollIntervalSeconds, 1)) * time.Second
+		maxInterval := time.Duration(max(cfg.Git.MaxPollIntervalSeconds, cfg.Git.MinPollIntervalSeconds, 1)) * time.Second
+		schedule = newPollSchedule(componentLogger, minInterval, maxInterval, interval)
+		tickInterval = minInterval
+	}
+
+	maxGapFill := cfg.Git.MaxGapFillCommits
+	if maxGapFill < 1 {
+		maxGapFill = defaultMaxGapFillCommits
+	}
+
 	return &poller{
 		config:         cfg,
 		logger:         componentLogger,
-		interval:       interval,
+		interval:       tickInterval,
 		done:           make(chan struct{}),
 		pollResults:    make(chan PollResult, pollResultChanBuffer),
+		pollBatches:    make(chan PollBatch, pollBatchChanBuffer),
+		batchMode:      cfg.Git.BatchPollResults,
 		started:        false,
 		lastSeenHashes: make(map[string]string),
+		confirmed:      make(map[string]string),
+		dirtyHashes:    make(map[string]string),
+		health:         newHealthTracker(healthStore, componentLogger, interval),
+		breaker: newCircuitBreaker(componentLogger, cfg.Git.ErrorBudget,
+			time.Duration(cfg.Git.ErrorBudgetWindowSeconds)*time.Second,
+			time.Duration(cfg.Git.CircuitCooldownSeconds)*time.Second),
+		healthStore: healthStore,
+		checkpoints: checkpoints,
+		maxGapFill:  maxGapFill,
+		schedule:    schedule,
+		repos:       newRepoHandleCache(componentLogger),
+		mounts:      newMountTracker(),
 	}, nil
 }
 
@@ -104,21 +223,79 @@ func (p *poller) Start(ctx context.Context, repos []Repository) error {
 	// Create context with cancellation
 	p.ctx, p.cancel = context.WithCancel(ctx)
 
+	checkpointed := make(map[string]string)
+	if p.checkpoints != nil {
+		loaded, err := p.checkpoints.Load()
+		if err != nil {
+			p.logger.Warn("failed to load poller checkpoints, baselining at current HEAD", "error", err)
+		} else {
+			checkpointed = loaded
+		}
+	}
+
+	// Repositories on an unavailable network share or external drive aren't discovered,
+	// so keep them as offline to resume from their checkpoint when their path returns
+	offline := p.offlineRepositories(repos, checkpointed, time.Now())
+	for _, repo := range offline {
+		p.health.recordOffline(repo, nil, time.Now())
+	}
+	repos = append(repos, offline...)
+
+	// Forget health and checkpoints of repositories that are no longer watched
+	paths := make([]string, 0, len(repos))
+	for _, repo := range repos {
+		paths = append(paths, repo.Path)
+	}
+	if p.healthStore != nil {
+		if err := p.healthStore.Prune(paths); err != nil {
+			p.logger.Warn("failed to prune repository health", "error", err)
+		}
+	}
+	if p.checkpoints != nil {
+		if err := p.checkpoints.Prune(paths); err != nil {
+			p.logger.Warn("failed to prune poller checkpoints", "error", err)
+		}
+	}
+
 	// Initialize state: get current HEAD hash for each repository
 	p.logger.Debug("initializing poller state", "repository_count", len(repos))
+	p.gaps = nil
 	var initializedCount, skippedCount int
 	for _, repo := range repos {
+		if p.health.isOffline(repo.Path) && !pathAvailable(repo.Path) {
+			p.resumeOffline(repo, checkpointed)
+			skippedCount++
+			continue
+		}
 		hash, err := p.getCurrentHEADHash(repo.Path)
 		if err != nil {
+			if p.markOffline(repo, err) {
+				p.resumeOffline(repo, checkpointed)
+				skippedCount++
+				continue
+			}
 			// Log error but continue - repository might be empty, invalid, or temporarily unavailable
-			p.logger.Warn("failed to get initial HEAD hash, repository will be skipped", "repository", repo.Path, "error", err)
+			p.logger.Warn("failed to get initial HEAD hash, repository will be retried with backoff", "repository", repo.Path, "error", err)
+			p.health.recordFailure(repo, err, time.Now())
 			skippedCount++
 			continue
 		}
+		p.health.recordSuccess(repo)
+		p.mounts.record(repo.Path)
 		if hash != "" {
-			p.stateMu.Lock()
-			p.lastSeenHashes[repo.Path] = hash
-			p.stateMu.Unlock()
+			// Resume from the checkpoint so commits made while stopped are detected by
+			// the first poll. A checkpoint that no longer resolves (e.g. after a
+			// rewritten history) would match nothing, so fall back to HEAD.
+			if checkpoint, ok := checkpointed[repo.Path]; ok && checkpoint != hash {
+				if p.hasCommit(repo.Path, checkpoint) {
+					p.logger.Info("resuming repository from checkpoint", "repository", repo.Path, "checkpoint", checkpoint, "head", hash)
+					p.gaps = append(p.gaps, commitGap{repo: repo, from: checkpoint, to: hash})
+					hash = checkpoint
+				} else {
+					p.logger.Warn("checkpointed commit not found, baselining at current HEAD", "repository", repo.Path, "checkpoint", checkpoint)
+				}
+			}
+			p.baselineHash(repo.Path, hash)
 			p.logger.Debug("initialized repository state", "repository", repo.Path, "hash", hash)
 			initializedCount++
 		} else {
@@ -127,6 +304,7 @@ func (p *poller) Start(ctx context.Context, repos []Repository) error {
 		}
 	}
 	p.logger.Info("poller state initialization completed", "initialized", initializedCount, "skipped", skippedCount, "total", len(repos))
+	p.saveCheckpoints()
 
 	// Create ticker with configured interval
 	p.ticker = time.NewTicker(p.interval)
@@ -146,6 +324,9 @@ func (p *poller) pollLoop(repos []Repository) {
 
 	p.logger.Debug("polling loop started", "interval_seconds", int(p.interval.Seconds()))
 
+	// Ingest commits made while stopped before regular polling begins
+	p.fillGaps()
+
 	for {
 		select {
 		case <-p.ctx.Done():
@@ -165,36 +346,340 @@ func (p *poller) pollLoop(repos []Repository) {
 func (p *poller) pollAllRepositories(repos []Repository) {
 	var wg sync.WaitGroup
 
+	now := time.Now()
+
+	if p.batchMode {
+		p.batchMu.Lock()
+		p.batch = newPollBatch(now)
+		p.batchMu.Unlock()
+	}
+
 	for _, repo := range repos {
+		if p.schedule != nil && !p.schedule.due(repo.Path, now) {
+			continue
+		}
+
 		wg.Add(1)
 		go func(r Repository) {
 			defer wg.Done()
-			p.pollRepository(r)
+			active := p.pollRepository(r)
+			if p.schedule == nil {
+				return
+			}
+			if active {
+				p.schedule.recordActivity(r.Path, time.Now())
+			} else {
+				p.schedule.recordQuiet(r.Path, time.Now())
+			}
 		}(repo)
 	}
 
 	wg.Wait()
+
+	if p.batchMode {
+		p.emitBatch()
+	}
+	p.saveCheckpoints()
+}
+
+// fillGaps ingests the commits each repository gained while the poller was stopped,
+// keeping at most maxGapFill of the most recent. A gap whose commits can't be read
+// is left to regular polling, which resumes from the same checkpoint.
+func (p *poller) fillGaps() {
+	if len(p.gaps) == 0 {
+		return
+	}
+
+	var results []PollResult
+	for _, gap := range p.gaps {
+		commits, err := p.getCommitsBetween(gap.repo.Path, gap.from, gap.to)
+		if err != nil {
+			p.logger.Warn("failed to read commits made while stopped, leaving them to regular polling", "repository", gap.repo.Path, "error", err)
+			continue
+		}
+
+		// Commits are newest first
+		if len(commits) > p.maxGapFill {
+			p.logger.Warn("too many commits made while stopped, ingesting the most recent", "repository", gap.repo.Path,
+				"count", len(commits), "ingesting", p.maxGapFill, "skipped", len(commits)-p.maxGapFill)
+			commits = commits[:p.maxGapFill]
+		}
+		p.logger.Info("filling commit gap from downtime", "repository", gap.repo.Path, "count", len(commits), "from", gap.from, "to", gap.to)
+
+		r := PollRange{From: gap.from, To: gap.to}
+		if !p.advanceLastSeenHash(gap.repo.Path, r) {
+			continue
+		}
+		if len(commits) > 0 {
+			results = append(results, PollResult{Repository: gap.repo, NewCommits: commits, GapFill: true, Range: r})
+		} else {
+			// Nothing to store, so the gap is confirmed as soon as it's read
+			p.Acknowledge(gap.repo.Path, r, true)
+		}
+	}
+	p.gaps = nil
+
+	p.deliverGapFill(results)
+}
+
+// deliverGapFill sends gap-fill results, blocking until they are received or the
+// poller stops. Returns false if the poller stopped first.
+func (p *poller) deliverGapFill(results []PollResult) bool {
+	if len(results) == 0 {
+		return true
+	}
+
+	if p.batchMode {
+		batch := newPollBatch(time.Now())
+		batch.GapFill = true
+		for _, result := range results {
+			batch.add(result)
+		}
+		select {
+		case p.pollBatches <- *batch:
+			return true
+		case <-p.ctx.Done():
+			return false
+		}
+	}
+
+	for _, result := range results {
+		select {
+		case p.pollResults <- result:
+		case <-p.ctx.Done():
+			return false
+		}
+	}
+	return true
 }
 
-// pollRepository polls a single repository for new commits
-func (p *poller) pollRepository(repo Repository) {
+// baselineHash records a hash with no commits to ingest before it, as both the
+// repository's last seen hash and its checkpoint
+func (p *poller) baselineHash(repoPath, hash string) {
+	p.stateMu.Lock()
+	defer p.stateMu.Unlock()
+
+	p.lastSeenHashes[repoPath] = hash
+	p.confirmed[repoPath] = hash
+	if p.checkpoints != nil {
+		p.dirtyHashes[repoPath] = hash
+	}
+}
+
+// advanceLastSeenHash moves a repository's last seen hash across a polled range,
+// unless an unstored result rewound it while the range was read. Reports whether it moved.
+func (p *poller) advanceLastSeenHash(repoPath string, r PollRange) bool {
+	p.stateMu.Lock()
+	defer p.stateMu.Unlock()
+
+	if p.lastSeenHashes[repoPath] != r.From {
+		return false
+	}
+	p.lastSeenHashes[repoPath] = r.To
+	return true
+}
+
+// Acknowledge reports whether the commits of a polled range were stored. A stored
+// range that continues from the last confirmed hash is checkpointed; a range that
+// wasn't stored rewinds the repository to its last confirmed hash, so the next poll
+// reads the range again. Stored ranges that follow an unstored one aren't confirmed
+// until that re-poll, so the checkpoint never skips commits.
+func (p *poller) Acknowledge(repoPath string, r PollRange, stored bool) {
+	if r.To == "" {
+		return
+	}
+
+	p.stateMu.Lock()
+	confirmed, ok := p.confirmed[repoPath]
+	if !ok {
+		p.stateMu.Unlock()
+		return
+	}
+	if !stored {
+		p.logger.Debug("polled commits not stored, will poll them again", "repository", repoPath, "from", confirmed, "to", r.To)
+		p.lastSeenHashes[repoPath] = confirmed
+		p.stateMu.Unlock()
+		return
+	}
+	if confirmed != r.From {
+		p.stateMu.Unlock()
+		return
+	}
+	p.confirmed[repoPath] = r.To
+	if p.checkpoints != nil {
+		p.dirtyHashes[repoPath] = r.To
+	}
+	p.stateMu.Unlock()
+
+	p.saveCheckpoints()
+}
+
+// saveCheckpoints persists the confirmed hashes that changed since the previous save.
+// On failure they stay dirty and are retried with the next save.
+func (p *poller) saveCheckpoints() {
+	if p.checkpoints == nil {
+		return
+	}
+
+	p.saveMu.Lock()
+	defer p.saveMu.Unlock()
+
+	p.stateMu.Lock()
+	if len(p.dirtyHashes) == 0 {
+		p.stateMu.Unlock()
+		return
+	}
+	pending := p.dirtyHashes
+	p.dirtyHashes = make(map[string]string)
+	p.stateMu.Unlock()
+
+	if err := p.checkpoints.SaveAll(pending); err != nil {
+		p.logger.Warn("failed to save poller checkpoints, will retry with the next save", "count", len(pending), "error", err)
+		p.stateMu.Lock()
+		for path, hash := range pending {
+			// Keep newer hashes recorded while saving
+			if _, ok := p.dirtyHashes[path]; !ok {
+				p.dirtyHashes[path] = hash
+			}
+		}
+		p.stateMu.Unlock()
+	}
+}
+
+// hasCommit reports whether a commit exists in a repository
+func (p *poller) hasCommit(repoPath, hash string) bool {
+	handle, _, err := p.repos.acquire(repoPath)
+	if err != nil {
+		return false
+	}
+	defer p.repos.release(handle)
+
+	_, err = handle.repo.CommitObject(plumbing.NewHash(hash))
+	return err == nil
+}
+
+// offlineRepositories returns checkpointed repositories missing from repos whose
+// path is gone, as on an unmounted drive or share. Repositories last known to be
+// unhealthy (moved or deleted) or offline longer than offlineRetention are left out.
+func (p *poller) offlineRepositories(repos []Repository, checkpointed map[string]string, now time.Time) []Repository {
+	discovered := make(map[string]bool, len(repos))
+	for _, repo := range repos {
+		discovered[repo.Path] = true
+	}
+
+	records := make(map[string]RepositoryHealth)
+	if p.healthStore != nil {
+		list, err := p.healthStore.List()
+		if err != nil {
+			p.logger.Warn("failed to load repository health, not resuming offline repositories", "error", err)
+			return nil
+		}
+		for _, record := range list {
+			records[record.Path] = record
+		}
+	}
+
+	var offline []Repository
+	for path := range checkpointed {
+		if discovered[path] || pathAvailable(path) {
+			continue
+		}
+		record, ok := records[path]
+		if ok && record.Status == RepositoryUnhealthy {
+			continue
+		}
+		if ok && record.Status == RepositoryOffline && record.UnhealthySince != nil && now.Sub(*record.UnhealthySince) > offlineRetention {
+			p.logger.Info("forgetting repository offline too long", "repository", path, "offline_since", *record.UnhealthySince)
+			continue
+		}
+
+		name := filepath.Base(path)
+		if ok && record.Name != "" {
+			name = record.Name
+		}
+		offline = append(offline, Repository{Path: path, Name: name})
+	}
+	sort.Slice(offline, func(i, j int) bool { return offline[i].Path < offline[j].Path })
+	return offline
+}
+
+// markOffline records a repository as offline if a failure means its drive or share
+// is unavailable, and reports whether it did. Offline repositories don't emit
+// errors or count against the circuit breaker.
+func (p *poller) markOffline(repo Repository, err error) bool {
+	if !p.mounts.unmounted(repo.Path, err) && !(p.health.isOffline(repo.Path) && !pathAvailable(repo.Path)) {
+		return false
+	}
+	p.repos.invalidate(repo.Path)
+	p.health.recordOffline(repo, err, time.Now())
+	return true
+}
+
+// resumeOffline seeds an offline repository's last seen hash from its checkpoint,
+// so commits made while it was unavailable are detected once its path returns
+func (p *poller) resumeOffline(repo Repository, checkpointed map[string]string) {
+	checkpoint, ok := checkpointed[repo.Path]
+	if !ok {
+		return
+	}
+	p.stateMu.Lock()
+	p.lastSeenHashes[repo.Path] = checkpoint
+	p.confirmed[repo.Path] = checkpoint
+	p.stateMu.Unlock()
+	p.logger.Debug("repository offline at startup, will resume from checkpoint", "repository", repo.Path, "checkpoint", checkpoint)
+}
+
+// NotifyActivity tells the poller a repository is in active use (e.g. an active
+// Cursor session), so adaptive polling checks it at the minimum interval
+func (p *poller) NotifyActivity(repoPath string) {
+	if p.schedule == nil {
+		return
+	}
+	p.schedule.recordActivity(repoPath, time.Now())
+}
+
+// pollRepository polls a single repository for new commits and reports whether any were found
+func (p *poller) pollRepository(repo Repository) bool {
+	// Suspended repositories are skipped until their cooldown expires, and
+	// failing repositories are retried with backoff rather than every poll
+	now := time.Now()
+	if !p.breaker.allow(repo.Path, now) || !p.health.shouldPoll(repo.Path, now) {
+		return false
+	}
+
+	// Offline repositories wait quietly for their path to return
+	if p.health.isOffline(repo.Path) && !pathAvailable(repo.Path) {
+		p.health.recordOffline(repo, nil, now)
+		return false
+	}
+
 	// Get current HEAD hash
 	currentHash, err := p.getCurrentHEADHash(repo.Path)
 	if err != nil {
+		if p.markOffline(repo, err) {
+			return false
+		}
 		// Emit error result with context
-		p.logger.Warn("failed to get HEAD hash during poll", "repository", repo.Path, "error", err)
+		health := p.health.recordFailure(repo, err, time.Now())
+		// Opening the circuit logs its own aggregated warning
+		if !p.breaker.recordFailure(repo.Path, err, time.Now()) {
+			p.logger.Warn("failed to get HEAD hash during poll", "repository", repo.Path, "error", err, "status", health.Status, "next_retry", health.NextRetryAt)
+		}
 		p.emitResult(PollResult{
 			Repository: repo,
 			NewCommits: nil,
 			Error:      fmt.Errorf("failed to get HEAD hash: %w", err),
 		})
-		return
+		return false
 	}
+	p.health.recordSuccess(repo)
+	p.breaker.recordSuccess(repo.Path)
+	p.mounts.record(repo.Path)
 
 	// Handle empty repository (no HEAD)
 	if currentHash == "" {
 		p.logger.Debug("repository has no HEAD, skipping poll", "repository", repo.Path)
-		return
+		return false
 	}
 
 	// Get last seen hash
@@ -204,24 +689,25 @@ func (p *poller) pollRepository(repo Repository) {
 
 	// If no last seen hash, this is the first poll - store current hash
 	if !hasLastSeen || lastSeenHash == "" {
-		p.stateMu.Lock()
-		p.lastSeenHashes[repo.Path] = currentHash
-		p.stateMu.Unlock()
+		p.baselineHash(repo.Path, currentHash)
 		p.logger.Debug("first poll for repository, storing HEAD", "repository", repo.Path, "hash", currentHash)
-		return
+		return false
 	}
 
 	// Compare hashes
 	if currentHash == lastSeenHash {
 		// No new commits
 		p.logger.Debug("no new commits detected", "repository", repo.Path, "hash", currentHash)
-		return
+		return false
 	}
 
 	// New commits detected - get commits between last seen and current
 	p.logger.Debug("new commits detected, fetching commit history", "repository", repo.Path, "last_seen", lastSeenHash, "current", currentHash)
 	commits, err := p.getCommitsBetween(repo.Path, lastSeenHash, currentHash)
 	if err != nil {
+		if p.markOffline(repo, err) {
+			return false
+		}
 		// Emit error result but don't update last seen hash (so we can retry next poll)
 		p.logger.Warn("failed to get commits between hashes", "repository", repo.Path, "last_seen", lastSeenHash, "current", currentHash, "error", err)
 		p.emitResult(PollResult{
@@ -229,13 +715,15 @@ func (p *poller) pollRepository(repo Repository) {
 			NewCommits: nil,
 			Error:      fmt.Errorf("failed to get commits: %w", err),
 		})
-		return
+		return false
 	}
 
-	// Update last seen hash
-	p.stateMu.Lock()
-	p.lastSeenHashes[repo.Path] = currentHash
-	p.stateMu.Unlock()
+	// Update last seen hash; the checkpoint follows once the commits are stored
+	r := PollRange{From: lastSeenHash, To: currentHash}
+	if !p.advanceLastSeenHash(repo.Path, r) {
+		p.logger.Debug("repository rewound while polling, will poll again", "repository", repo.Path)
+		return false
+	}
 
 	// Emit result with new commits
 	if len(commits) > 0 {
@@ -244,10 +732,14 @@ func (p *poller) pollRepository(repo Repository) {
 			Repository: repo,
 			NewCommits: commits,
 			Error:      nil,
+			Range:      r,
 		})
+		return true
 	} else {
 		p.logger.Debug("no commits found between hashes (possible reset/rebase)", "repository", repo.Path, "last_seen", lastSeenHash, "current", currentHash)
+		p.Acknowledge(repo.Path, r, true)
 	}
+	return false
 }
 
 // getCurrentHEADHash gets the current HEAD commit hash for a repository
@@ -261,7 +753,7 @@ func (p *poller) getCurrentHEADHash(repoPath string) (string, error) {
 			time.Sleep(delay)
 		}
 
-		repo, err := git.PlainOpen(repoPath)
+		handle, cached, err := p.repos.acquire(repoPath)
 		if err != nil {
 			lastErr = err
 			// Check if this is a transient error that might benefit from retry
@@ -274,15 +766,18 @@ func (p *poller) getCurrentHEADHash(repoPath string) (string, error) {
 			return "", fmt.Errorf("failed to open repository: %w", err)
 		}
 
-		ref, err := repo.Head()
+		ref, err := handle.repo.Head()
+		p.repos.release(handle)
 		if err != nil {
 			if err == plumbing.ErrReferenceNotFound {
 				// Empty repository - no HEAD (not an error)
 				p.logger.Debug("repository has no HEAD (empty repository)", "repository", repoPath)
 				return "", nil
 			}
-			// Check if this is a transient error
-			if p.isTransientError(err) && attempt < maxRetries {
+			lastErr = err
+			// A cached handle may be stale, so retry with a freshly opened repository
+			p.repos.invalidate(repoPath)
+			if (cached || p.isTransientError(err)) && attempt < maxRetries {
 				p.logger.Warn("transient error getting HEAD, will retry", "repository", repoPath, "attempt", attempt+1, "error", err)
 				continue
 			}
@@ -335,7 +830,7 @@ func (p *poller) getCommitsBetween(repoPath, fromHash, toHash string) ([]Commit,
 			time.Sleep(delay)
 		}
 
-		repo, err := git.PlainOpen(repoPath)
+		handle, cached, err := p.repos.acquire(repoPath)
 		if err != nil {
 			lastErr = err
 			if p.isTransientError(err) && attempt < maxRetries {
@@ -349,14 +844,17 @@ func (p *poller) getCommitsBetween(repoPath, fromHash, toHash string) ([]Commit,
 		to := plumbing.NewHash(toHash)
 
 		// Get HEAD reference for branch name
-		headRef, err := repo.Head()
+		headRef, err := handle.repo.Head()
 		if err != nil {
+			p.repos.release(handle)
 			lastErr = err
 			if err == plumbing.ErrReferenceNotFound {
 				// Empty repository - return empty commits
 				return []Commit{}, nil
 			}
-			if p.isTransientError(err) && attempt < maxRetries {
+			// A cached handle may be stale, so retry with a freshly opened repository
+			p.repos.invalidate(repoPath)
+			if (cached || p.isTransientError(err)) && attempt < maxRetries {
 				p.logger.Warn("transient error getting HEAD, will retry", "repository", repoPath, "attempt", attempt+1, "error", err)
 				continue
 			}
@@ -365,10 +863,12 @@ func (p *poller) getCommitsBetween(repoPath, fromHash, toHash string) ([]Commit,
 		branchName := headRef.Name().Short()
 
 		// Get commit log starting from toHash
-		commitIter, err := repo.Log(&git.LogOptions{From: to})
+		commitIter, err := handle.repo.Log(&git.LogOptions{From: to})
 		if err != nil {
+			p.repos.release(handle)
 			lastErr = err
-			if p.isTransientError(err) && attempt < maxRetries {
+			p.repos.invalidate(repoPath)
+			if (cached || p.isTransientError(err)) && attempt < maxRetries {
 				p.logger.Warn("transient error getting commit log, will retry", "repository", repoPath, "attempt", attempt+1, "error", err)
 				continue
 			}
@@ -419,13 +919,21 @@ func (p *poller) getCommitsBetween(repoPath, fromHash, toHash string) ([]Commit,
 			return nil
 		})
 
+		// History ends early at the edge of a shallow clone
+		if err != nil && isShallowBoundary(handle.repo, err) {
+			p.logger.Debug("reached shallow clone boundary", "repository", repoPath, "to_hash", toHash)
+			err = nil
+		}
+
 		// Always close the iterator
 		commitIter.Close()
+		p.repos.release(handle)
 
 		// Check if error is our stop iteration sentinel
 		if err != nil && !errors.Is(err, stopIteration) {
 			lastErr = err
-			if p.isTransientError(err) && attempt < maxRetries {
+			p.repos.invalidate(repoPath)
+			if (cached || p.isTransientError(err)) && attempt < maxRetries {
 				p.logger.Warn("transient error iterating commits, will retry", "repository", repoPath, "attempt", attempt+1, "error", err)
 				continue
 			}
@@ -451,17 +959,56 @@ func (p *poller) getCommitsBetween(repoPath, fromHash, toHash string) ([]Commit,
 	return nil, fmt.Errorf("failed after %d attempts: %w", maxRetries+1, lastErr)
 }
 
-// emitResult emits a poll result to the results channel (non-blocking)
+// emitResult emits a poll result to the results channel, blocking until it is received
+// or the poller stops. In batch mode the result is added to the current cycle's batch instead.
 func (p *poller) emitResult(result PollResult) {
+	if p.batchMode {
+		p.batchMu.Lock()
+		if p.batch == nil {
+			p.batch = newPollBatch(time.Now())
+		}
+		p.batch.add(result)
+		p.batchMu.Unlock()
+		return
+	}
+
+	// A slow consumer holds up the next cycle rather than losing results. A result
+	// abandoned on shutdown isn't acknowledged, so its commits are polled again on restart.
 	select {
 	case p.pollResults <- result:
-		// Result sent successfully
-	default:
-		// Channel full - log warning but don't block
-		p.logger.Warn("poll results channel full, dropping result", "repository", result.Repository.Path)
+	case <-p.stopping():
+		p.logger.Debug("poller stopped before result was received", "repository", result.Repository.Path)
 	}
 }
 
+// emitBatch emits the current cycle's batch to the batches channel, blocking until
+// it is received or the poller stops
+func (p *poller) emitBatch() {
+	p.batchMu.Lock()
+	batch := p.batch
+	p.batch = nil
+	p.batchMu.Unlock()
+
+	if batch == nil || batch.Empty() {
+		return
+	}
+
+	select {
+	case p.pollBatches <- *batch:
+		p.logger.Debug("emitted poll batch", "repositories", len(batch.Repositories), "with_commits", len(batch.Commits), "with_errors", len(batch.Errors))
+	case <-p.stopping():
+		p.logger.Debug("poller stopped before batch was received", "repositories", len(batch.Repositories))
+	}
+}
+
+// stopping returns a channel closed when the poller stops
+func (p *poller) stopping() <-chan struct{} {
+	if p.ctx == nil {
+		return p.done
+	}
+	return p.ctx.Done()
+}
+
 // Stop stops polling and cleans up resources
 func (p *poller) Stop() error {
 	p.mu.Lock()
@@ -489,15 +1036,29 @@ func (p *poller) Stop() error {
 	// Wait for polling goroutine to finish
 	p.wg.Wait()
 
-	// Close poll results channel
+	// Close poll results channels
 	close(p.pollResults)
+	close(p.pollBatches)
+
+	// Release cached repository handles
+	p.repos.clear()
 
 	p.started = false
 	p.logger.Info("poller stopped")
 	return nil
 }
 
+// handles returns the poller's repository handle cache, shared with the commit pipeline
+func (p *poller) handles() *repoHandleCache {
+	return p.repos
+}
+
 // PollResults returns the channel for receiving poll results
 func (p *poller) PollResults() <-chan PollResult {
 	return p.pollResults
 }
+
+// PollBatches returns the channel for receiving one batch per polling cycle (batch mode only)
+func (p *poller) PollBatches() <-chan PollBatch {
+	return p.pollBatches
+}
@@ -21,7 +21,7 @@ func TestPollerService_StartStop(t *testing.T) {
 		},
 	}
 
-	poller, err := NewPollerService(cfg, logger)
+	poller, err := NewPollerService(cfg, logger, nil, nil)
 	if err != nil {
 		t.Fatalf("failed to create poller: %v", err)
 	}
@@ -48,7 +48,7 @@ func TestPollerService_DetectNewCommits_SingleRepository(t *testing.T) {
 		},
 	}
 
-	poller, err := NewPollerService(cfg, logger)
+	poller, err := NewPollerService(cfg, logger, nil, nil)
 	if err != nil {
 		t.Fatalf("failed to create poller: %v", err)
 	}
@@ -145,7 +145,7 @@ func TestPollerService_DetectNewCommits_MultipleRepositories(t *testing.T) {
 		},
 	}
 
-	poller, err := NewPollerService(cfg, logger)
+	poller, err := NewPollerService(cfg, logger, nil, nil)
 	if err != nil {
 		t.Fatalf("failed to create poller: %v", err)
 	}
@@ -261,7 +261,7 @@ func TestPollerService_NoNewCommits(t *testing.T) {
 		},
 	}
 
-	poller, err := NewPollerService(cfg, logger)
+	poller, err := NewPollerService(cfg, logger, nil, nil)
 	if err != nil {
 		t.Fatalf("failed to create poller: %v", err)
 	}
@@ -310,7 +310,7 @@ func TestPollerService_TracksLastSeenHash(t *testing.T) {
 		},
 	}
 
-	poller, err := NewPollerService(cfg, logger)
+	poller, err := NewPollerService(cfg, logger, nil, nil)
 	if err != nil {
 		t.Fatalf("failed to create poller: %v", err)
 	}
@@ -398,7 +398,7 @@ func TestPollerService_HandlesRepositoryErrors(t *testing.T) {
 		},
 	}
 
-	poller, err := NewPollerService(cfg, logger)
+	poller, err := NewPollerService(cfg, logger, nil, nil)
 	if err != nil {
 		t.Fatalf("failed to create poller: %v", err)
 	}
@@ -473,7 +473,7 @@ func TestPollerService_HandlesEmptyRepository(t *testing.T) {
 		},
 	}
 
-	poller, err := NewPollerService(cfg, logger)
+	poller, err := NewPollerService(cfg, logger, nil, nil)
 	if err != nil {
 		t.Fatalf("failed to create poller: %v", err)
 	}
@@ -558,7 +558,7 @@ func TestPollerService_ConfigurableInterval(t *testing.T) {
 				},
 			}
 
-			poller, err := NewPollerService(cfg, logger)
+			poller, err := NewPollerService(cfg, logger, nil, nil)
 			if err != nil {
 				t.Fatalf("failed to create poller: %v", err)
 			}
@@ -579,7 +579,7 @@ func TestPollerService_ContextCancellation(t *testing.T) {
 		},
 	}
 
-	poller, err := NewPollerService(cfg, logger)
+	poller, err := NewPollerService(cfg, logger, nil, nil)
 	if err != nil {
 		t.Fatalf("failed to create poller: %v", err)
 	}
@@ -0,0 +1,116 @@
+package git
+
+import (
+	"io"
+	"sync"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+// repoHandle is a shared, reference-counted repository handle
+type repoHandle struct {
+	path  string
+	repo  *git.Repository
+	refs  int
+	stale bool // Invalidated; closed once the last reference is released
+}
+
+// repoHandleCache keeps one open repository per path so polls don't reopen every
+// repository with PlainOpen. Handles are invalidated on errors, since a cached
+// handle can miss on-disk changes such as repacked objects.
+type repoHandleCache struct {
+	logger  logging.Logger
+	open    func(path string) (*git.Repository, error)
+	mu      sync.Mutex
+	handles map[string]*repoHandle
+}
+
+// handleSource is implemented by services whose repository handles can be shared
+type handleSource interface {
+	handles() *repoHandleCache
+}
+
+// newRepoHandleCache creates an empty repository handle cache
+func newRepoHandleCache(logger logging.Logger) *repoHandleCache {
+	return &repoHandleCache{
+		logger:  logger,
+		open:    git.PlainOpen,
+		handles: make(map[string]*repoHandle),
+	}
+}
+
+// acquire returns a handle for path, opening the repository if it isn't cached.
+// cached reports whether an existing handle was reused. Callers must release the handle.
+func (c *repoHandleCache) acquire(path string) (handle *repoHandle, cached bool, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if handle, ok := c.handles[path]; ok {
+		handle.refs++
+		return handle, true, nil
+	}
+
+	repo, err := c.open(path)
+	if err != nil {
+		return nil, false, err
+	}
+
+	handle = &repoHandle{path: path, repo: repo, refs: 1}
+	c.handles[path] = handle
+	c.logger.Debug("opened repository handle", "repository", path)
+	return handle, false, nil
+}
+
+// release drops a reference, closing the handle if it was invalidated and unused
+func (c *repoHandleCache) release(handle *repoHandle) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	handle.refs--
+	if handle.stale && handle.refs <= 0 {
+		c.closeHandle(handle)
+	}
+}
+
+// invalidate drops the cached handle for path so the next acquire reopens the repository.
+// Holders of the old handle can keep using it until they release it.
+func (c *repoHandleCache) invalidate(path string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	handle, ok := c.handles[path]
+	if !ok {
+		return
+	}
+	delete(c.handles, path)
+	handle.stale = true
+	if handle.refs <= 0 {
+		c.closeHandle(handle)
+	}
+	c.logger.Debug("invalidated repository handle", "repository", path)
+}
+
+// clear invalidates every cached handle
+func (c *repoHandleCache) clear() {
+	c.mu.Lock()
+	paths := make([]string, 0, len(c.handles))
+	for path := range c.handles {
+		paths = append(paths, path)
+	}
+	c.mu.Unlock()
+
+	for _, path := range paths {
+		c.invalidate(path)
+	}
+}
+
+// closeHandle releases file descriptors held by the repository storage.
+// Callers must hold c.mu.
+func (c *repoHandleCache) closeHandle(handle *repoHandle) {
+	if closer, ok := handle.repo.Storer.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			c.logger.Debug("failed to close repository storage", "repository", handle.path, "error", err)
+		}
+	}
+}
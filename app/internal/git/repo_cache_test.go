@@ -0,0 +1,79 @@
+package git
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+func TestRepoHandleCache_ReusesAndInvalidates(t *testing.T) {
+	repoPath := t.TempDir()
+	if _, err := git.PlainInit(repoPath, false); err != nil {
+		t.Fatalf("failed to init repository: %v", err)
+	}
+
+	cache := newRepoHandleCache(logging.NewNoopLogger())
+	opens := 0
+	cache.open = func(path string) (*git.Repository, error) {
+		opens++
+		return git.PlainOpen(path)
+	}
+
+	first, cached, err := cache.acquire(repoPath)
+	if err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+	if cached {
+		t.Error("first acquire should open the repository")
+	}
+
+	second, cached, err := cache.acquire(repoPath)
+	if err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+	if !cached || second != first || opens != 1 {
+		t.Errorf("second acquire: cached=%v same=%v opens=%d, want reuse of one handle", cached, second == first, opens)
+	}
+	if first.refs != 2 {
+		t.Errorf("refs = %d, want 2", first.refs)
+	}
+
+	// Invalidated handles stay usable by holders until released
+	cache.invalidate(repoPath)
+	if !first.stale {
+		t.Error("invalidated handle should be marked stale")
+	}
+	if _, err := first.repo.Head(); err != nil && !errors.Is(err, plumbing.ErrReferenceNotFound) {
+		t.Errorf("stale handle should remain usable, got %v", err)
+	}
+	cache.release(first)
+	cache.release(second)
+
+	third, cached, err := cache.acquire(repoPath)
+	if err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+	if cached || third == first || opens != 2 {
+		t.Errorf("acquire after invalidate: cached=%v opens=%d, want a fresh handle", cached, opens)
+	}
+	cache.release(third)
+
+	cache.clear()
+	if len(cache.handles) != 0 {
+		t.Errorf("clear() left %d handles", len(cache.handles))
+	}
+}
+
+func TestRepoHandleCache_OpenError(t *testing.T) {
+	cache := newRepoHandleCache(logging.NewNoopLogger())
+
+	if _, _, err := cache.acquire(t.TempDir()); err == nil {
+		t.Fatal("acquire() of a non-repository should fail")
+	}
+	if len(cache.handles) != 0 {
+		t.Error("failed opens should not be cached")
+	}
+}
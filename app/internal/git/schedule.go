@@ -0,0 +1,99 @@
+package git
+
+import (
+	"sync"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+const (
+	// quietPollBackoffFactor multiplies a repository's interval after a poll with no new commits
+	quietPollBackoffFactor = 2
+)
+
+// repoSchedule tracks when a repository is next due to be polled
+type repoSchedule struct {
+	interval   time.Duration
+	nextPollAt time.Time
+}
+
+// pollSchedule adapts each repository's polling interval to its activity: new
+// commits or session activity drop it to the minimum, quiet polls lengthen it
+// up to the maximum
+type pollSchedule struct {
+	logger  logging.Logger
+	min     time.Duration
+	max     time.Duration
+	initial time.Duration
+	mu      sync.Mutex
+	repos   map[string]*repoSchedule
+}
+
+// newPollSchedule creates a schedule bounded by minInterval and maxInterval, starting repositories at initial
+func newPollSchedule(logger logging.Logger, minInterval, maxInterval, initial time.Duration) *pollSchedule {
+	if initial < minInterval {
+		initial = minInterval
+	}
+	if initial > maxInterval {
+		initial = maxInterval
+	}
+
+	return &pollSchedule{
+		logger:  logger,
+		min:     minInterval,
+		max:     maxInterval,
+		initial: initial,
+		repos:   make(map[string]*repoSchedule),
+	}
+}
+
+// due reports whether a repository should be polled at now
+func (ps *pollSchedule) due(path string, now time.Time) bool {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	schedule, ok := ps.repos[path]
+	return !ok || !now.Before(schedule.nextPollAt)
+}
+
+// recordActivity resets a repository to the minimum interval
+func (ps *pollSchedule) recordActivity(path string, now time.Time) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	schedule := ps.get(path)
+	if schedule.interval != ps.min {
+		ps.logger.Debug("repository active, shortening poll interval", "repository", path, "interval", ps.min)
+	}
+	schedule.interval = ps.min
+	schedule.nextPollAt = now.Add(ps.min)
+}
+
+// recordQuiet lengthens a repository's interval after a poll found nothing new
+func (ps *pollSchedule) recordQuiet(path string, now time.Time) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	schedule := ps.get(path)
+	interval := schedule.interval * quietPollBackoffFactor
+	if interval > ps.max {
+		interval = ps.max
+	}
+	if interval != schedule.interval {
+		ps.logger.Debug("repository quiet, lengthening poll interval", "repository", path, "interval", interval)
+	}
+	schedule.interval = interval
+	schedule.nextPollAt = now.Add(interval)
+}
+
+// get returns the schedule for a repository, creating it at the initial interval.
+// Callers must hold ps.mu.
+func (ps *pollSchedule) get(path string) *repoSchedule {
+	schedule, ok := ps.repos[path]
+	if !ok {
+		schedule = &repoSchedule{interval: ps.initial}
+		ps.repos[path] = schedule
+	}
+	return schedule
+}
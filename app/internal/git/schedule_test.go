@@ -0,0 +1,93 @@
+package git
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+func TestPollSchedule_AdaptsToActivity(t *testing.T) {
+	schedule := newPollSchedule(logging.NewNoopLogger(), 5*time.Second, 40*time.Second, 10*time.Second)
+	path := "/repos/project"
+	now := time.Now()
+
+	if !schedule.due(path, now) {
+		t.Fatal("unscheduled repository should be due")
+	}
+
+	// Quiet polls lengthen the interval up to the maximum
+	wantIntervals := []time.Duration{20 * time.Second, 40 * time.Second, 40 * time.Second}
+	for i, want := range wantIntervals {
+		schedule.recordQuiet(path, now)
+		if got := schedule.repos[path].interval; got != want {
+			t.Errorf("quiet poll %d: interval = %v, want %v", i+1, got, want)
+		}
+	}
+	if schedule.due(path, now.Add(39*time.Second)) {
+		t.Error("quiet repository should not be due before its interval")
+	}
+	if !schedule.due(path, now.Add(40*time.Second)) {
+		t.Error("quiet repository should be due once its interval elapses")
+	}
+
+	// Activity drops straight to the minimum
+	schedule.recordActivity(path, now)
+	if got := schedule.repos[path].interval; got != 5*time.Second {
+		t.Errorf("after activity interval = %v, want 5s", got)
+	}
+	if !schedule.due(path, now.Add(5*time.Second)) {
+		t.Error("active repository should be due after the minimum interval")
+	}
+}
+
+func TestNewPollSchedule_ClampsInitialInterval(t *testing.T) {
+	if got := newPollSchedule(logging.NewNoopLogger(), 5*time.Second, 60*time.Second, time.Second).initial; got != 5*time.Second {
+		t.Errorf("initial below min = %v, want 5s", got)
+	}
+	if got := newPollSchedule(logging.NewNoopLogger(), 5*time.Second, 60*time.Second, time.Hour).initial; got != 60*time.Second {
+		t.Errorf("initial above max = %v, want 60s", got)
+	}
+}
+
+func TestPollerService_AdaptivePollingConfig(t *testing.T) {
+	cfg := &config.Config{
+		Git: config.GitConfig{
+			PollIntervalSeconds:    30,
+			AdaptivePolling:        true,
+			MinPollIntervalSeconds: 2,
+			MaxPollIntervalSeconds: 120,
+		},
+	}
+
+	service, err := NewPollerService(cfg, logging.NewNoopLogger(), nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create poller: %v", err)
+	}
+	p := service.(*poller)
+
+	// The ticker runs at the minimum so active repositories can be polled that often
+	if p.interval != 2*time.Second {
+		t.Errorf("tick interval = %v, want 2s", p.interval)
+	}
+	if p.schedule == nil {
+		t.Fatal("adaptive polling should create a schedule")
+	}
+
+	service.NotifyActivity("/repos/project")
+	if got := p.schedule.repos["/repos/project"].interval; got != 2*time.Second {
+		t.Errorf("interval after NotifyActivity = %v, want 2s", got)
+	}
+
+	// Without adaptive polling NotifyActivity is a no-op
+	cfg.Git.AdaptivePolling = false
+	service, err = NewPollerService(cfg, logging.NewNoopLogger(), nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create poller: %v", err)
+	}
+	service.NotifyActivity("/repos/project")
+	if service.(*poller).schedule != nil {
+		t.Error("schedule should be nil without adaptive polling")
+	}
+}
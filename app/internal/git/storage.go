@@ -13,11 +13,21 @@ import (
 // CommitStorage defines the interface for storing and retrieving commits and file changes
 type CommitStorage interface {
 	StoreCommit(commit *Commit, diff *CommitDiff, correlation *CommitSessionCorrelation, repository *Repository, sessionID string) error
+	StoreCommits(records []CommitRecord) error
 	GetCommit(commitHash string) (*StoredCommit, error)
 	GetCommitsBySession(sessionID string) ([]*StoredCommit, error)
 	GetCommitsByRepository(repoPath string) ([]*StoredCommit, error)
 }
 
+// CommitRecord is a commit ready to store, with its diff and session correlation
+type CommitRecord struct {
+	Commit      *Commit
+	Diff        *CommitDiff
+	Correlation *CommitSessionCorrelation // nil if correlation failed
+	Repository  *Repository
+	SessionID   string // Empty to store the commit uncorrelated
+}
+
 // StoredCommit represents a commit retrieved from the database
 type StoredCommit struct {
 	ID              string
@@ -36,9 +46,11 @@ type StoredCommit struct {
 	DiffTruncated   bool
 	DiffTruncatedAt *int
 	CorrelationType *string
-	CreatedAt       time.Time
-	UpdatedAt       time.Time
-	Files           []StoredFileDiff
+	// CorrelationConfidence is nil for commits stored before confidence scoring
+	CorrelationConfidence *float64
+	CreatedAt             time.Time
+	UpdatedAt             time.Time
+	Files                 []StoredFileDiff
 }
 
 // StoredFileDiff represents a file diff retrieved from the database
@@ -78,13 +90,62 @@ func NewCommitStorage(db *sql.DB, logger logging.Logger) (CommitStorage, error)
 
 // StoreCommit stores a commit and all its file changes in a single transaction
 func (cs *commitStorage) StoreCommit(commit *Commit, diff *CommitDiff, correlation *CommitSessionCorrelation, repository *Repository, sessionID string) error {
-	if commit == nil {
-		return fmt.Errorf("commit cannot be nil")
+	return cs.StoreCommits([]CommitRecord{{Commit: commit, Diff: diff, Correlation: correlation, Repository: repository, SessionID: sessionID}})
+}
+
+// StoreCommits stores a batch of commits and their file changes in a single transaction,
+// so ingesting many commits doesn't pay for a transaction each. Nothing is stored if any
+// commit fails.
+func (cs *commitStorage) StoreCommits(records []CommitRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	for _, record := range records {
+		if record.Commit == nil {
+			return fmt.Errorf("commit cannot be nil")
+		}
+		if record.Repository == nil {
+			return fmt.Errorf("repository cannot be nil")
+		}
+	}
+
+	cs.logger.Debug("starting transaction for commit storage", "commit_count", len(records))
+	tx, err := cs.db.Begin()
+	if err != nil {
+		cs.logger.Error("failed to begin transaction", "commit_count", len(records), "error", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil {
+			cs.logger.Debug("transaction rollback completed", "commit_count", len(records))
+		}
+	}()
+
+	for _, record := range records {
+		if err := cs.storeCommitInTx(tx, record.Commit, record.Diff, record.Correlation, record.Repository, record.SessionID); err != nil {
+			return err
+		}
+	}
+
+	// Commit transaction
+	cs.logger.Debug("committing transaction", "commit_count", len(records))
+	if err := tx.Commit(); err != nil {
+		cs.logger.Error("failed to commit transaction", "commit_count", len(records), "error", err)
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
-	if repository == nil {
-		return fmt.Errorf("repository cannot be nil")
+
+	for _, record := range records {
+		fileCount := 0
+		if record.Diff != nil {
+			fileCount = len(record.Diff.Files)
+		}
+		cs.logger.Info("stored commit successfully", "hash", record.Commit.Hash, "session_id", record.SessionID, "repository", record.Repository.Path, "file_count", fileCount)
 	}
+	return nil
+}
 
+// storeCommitInTx stores a commit and its file changes within an existing transaction
+func (cs *commitStorage) storeCommitInTx(tx *sql.Tx, commit *Commit, diff *CommitDiff, correlation *CommitSessionCorrelation, repository *Repository, sessionID string) error {
 	// Calculate file count safely, handling nil diff
 	fileCount := 0
 	if diff != nil {
@@ -96,7 +157,7 @@ func (cs *commitStorage) StoreCommit(commit *Commit, diff *CommitDiff, correlati
 	// Verify session exists if sessionID is provided
 	if sessionID != "" {
 		var exists bool
-		err := cs.db.QueryRow("SELECT EXISTS(SELECT 1 FROM sessions WHERE id = ?)", sessionID).Scan(&exists)
+		err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM sessions WHERE id = ?)", sessionID).Scan(&exists)
 		if err != nil {
 			cs.logger.Error("failed to verify session exists", "session_id", sessionID, "error", err)
 			return fmt.Errorf("failed to verify session exists: %w", err)
@@ -107,19 +168,6 @@ func (cs *commitStorage) StoreCommit(commit *Commit, diff *CommitDiff, correlati
 		}
 	}
 
-	// Begin transaction
-	cs.logger.Debug("starting transaction for commit storage", "hash", commit.Hash, "session_id", sessionID, "repository", repository.Path, "file_count", fileCount)
-	tx, err := cs.db.Begin()
-	if err != nil {
-		cs.logger.Error("failed to begin transaction", "hash", commit.Hash, "session_id", sessionID, "repository", repository.Path, "error", err)
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer func() {
-		if err := tx.Rollback(); err != nil {
-			cs.logger.Debug("transaction rollback completed", "hash", commit.Hash)
-		}
-	}()
-
 	// Marshal parent hashes to JSON
 	var parentHashesJSON sql.NullString
 	if len(commit.Parents) > 0 {
@@ -153,6 +201,11 @@ func (cs *commitStorage) StoreCommit(commit *Commit, diff *CommitDiff, correlati
 		correlationTypeNull = sql.NullString{String: correlation.CorrelationType, Valid: true}
 	}
 
+	var correlationConfidenceNull sql.NullFloat64
+	if correlation != nil && correlation.SessionID != "" {
+		correlationConfidenceNull = sql.NullFloat64{Float64: correlation.Confidence, Valid: true}
+	}
+
 	var diffTruncatedAtNull sql.NullInt64
 	if diff != nil && diff.IsTruncated && diff.TruncatedAt > 0 {
 		diffTruncatedAtNull = sql.NullInt64{Int64: int64(diff.TruncatedAt), Valid: true}
@@ -166,14 +219,14 @@ func (cs *commitStorage) StoreCommit(commit *Commit, diff *CommitDiff, correlati
 	now := time.Now()
 
 	// Store commit (use commit hash as primary key)
-	_, err = tx.Exec(`
+	_, err := tx.Exec(`
 		INSERT INTO commits (
 			id, session_id, repository_path, repository_name, hash, message,
 			author_name, author_email, timestamp, branch, is_merge, parent_hashes,
 			full_diff, diff_truncated, diff_truncated_at, correlation_type,
-			created_at, updated_at
+			correlation_confidence, created_at, updated_at
 		)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(id) DO UPDATE SET
 			session_id = excluded.session_id,
 			repository_path = excluded.repository_path,
@@ -186,9 +239,11 @@ func (cs *commitStorage) StoreCommit(commit *Commit, diff *CommitDiff, correlati
 			is_merge = excluded.is_merge,
 			parent_hashes = excluded.parent_hashes,
 			full_diff = excluded.full_diff,
+			full_diff_blob = NULL,
 			diff_truncated = excluded.diff_truncated,
 			diff_truncated_at = excluded.diff_truncated_at,
 			correlation_type = excluded.correlation_type,
+			correlation_confidence = excluded.correlation_confidence,
 			updated_at = excluded.updated_at
 	`,
 		commit.Hash, // id = commit hash
@@ -207,6 +262,7 @@ func (cs *commitStorage) StoreCommit(commit *Commit, diff *CommitDiff, correlati
 		diffTruncatedInt,
 		diffTruncatedAtNull,
 		correlationTypeNull,
+		correlationConfidenceNull,
 		now,
 		now,
 	)
@@ -225,21 +281,13 @@ func (cs *commitStorage) StoreCommit(commit *Commit, diff *CommitDiff, correlati
 		}
 	}
 
-	// Commit transaction
-	cs.logger.Debug("committing transaction", "hash", commit.Hash, "file_count", fileCount)
-	if err := tx.Commit(); err != nil {
-		cs.logger.Error("failed to commit transaction", "hash", commit.Hash, "session_id", sessionID, "repository", repository.Path, "file_count", fileCount, "error", err)
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
-	cs.logger.Info("stored commit successfully", "hash", commit.Hash, "session_id", sessionID, "repository", repository.Path, "file_count", fileCount)
 	return nil
 }
 
 // storeFileDiffInTx stores a file diff within an existing transaction
 func (cs *commitStorage) storeFileDiffInTx(tx *sql.Tx, fileDiff *FileDiff, commitID string) error {
 	cs.logger.Debug("storing file diff in transaction", "commit_id", commitID, "file_path", fileDiff.Path, "lines_added", fileDiff.LinesAdded, "lines_removed", fileDiff.LinesRemoved)
-	
+
 	// Generate UUID for file diff ID
 	fileDiffID := uuid.New().String()
 
@@ -258,7 +306,8 @@ func (cs *commitStorage) storeFileDiffInTx(tx *sql.Tx, fileDiff *FileDiff, commi
 		ON CONFLICT(commit_id, file_path) DO UPDATE SET
 			lines_added = excluded.lines_added,
 			lines_removed = excluded.lines_removed,
-			diff = excluded.diff
+			diff = excluded.diff,
+			diff_blob = NULL
 	`,
 		fileDiffID,
 		commitID,
@@ -289,13 +338,14 @@ func (cs *commitStorage) GetCommit(commitHash string) (*StoredCommit, error) {
 	var commit StoredCommit
 	var sessionIDNull, correlationTypeNull, parentHashesJSON, fullDiffNull sql.NullString
 	var diffTruncatedAtNull sql.NullInt64
+	var correlationConfidenceNull sql.NullFloat64
 	var isMergeInt, diffTruncatedInt int
 
 	err := cs.db.QueryRow(`
 		SELECT id, session_id, repository_path, repository_name, hash, message,
 			author_name, author_email, timestamp, branch, is_merge, parent_hashes,
 			full_diff, diff_truncated, diff_truncated_at, correlation_type,
-			created_at, updated_at
+			correlation_confidence, created_at, updated_at
 		FROM commits
 		WHERE hash = ?
 	`, commitHash).Scan(
@@ -315,6 +365,7 @@ func (cs *commitStorage) GetCommit(commitHash string) (*StoredCommit, error) {
 		&diffTruncatedInt,
 		&diffTruncatedAtNull,
 		&correlationTypeNull,
+		&correlationConfidenceNull,
 		&commit.CreatedAt,
 		&commit.UpdatedAt,
 	)
@@ -334,6 +385,9 @@ func (cs *commitStorage) GetCommit(commitHash string) (*StoredCommit, error) {
 	if correlationTypeNull.Valid {
 		commit.CorrelationType = &correlationTypeNull.String
 	}
+	if correlationConfidenceNull.Valid {
+		commit.CorrelationConfidence = &correlationConfidenceNull.Float64
+	}
 	if diffTruncatedAtNull.Valid {
 		truncatedAt := int(diffTruncatedAtNull.Int64)
 		commit.DiffTruncatedAt = &truncatedAt
@@ -380,7 +434,7 @@ func (cs *commitStorage) GetCommitsBySession(sessionID string) ([]*StoredCommit,
 		SELECT id, session_id, repository_path, repository_name, hash, message,
 			author_name, author_email, timestamp, branch, is_merge, parent_hashes,
 			full_diff, diff_truncated, diff_truncated_at, correlation_type,
-			created_at, updated_at
+			correlation_confidence, created_at, updated_at
 		FROM commits
 		WHERE session_id = ?
 		ORDER BY timestamp ASC
@@ -439,7 +493,7 @@ func (cs *commitStorage) GetCommitsByRepository(repoPath string) ([]*StoredCommi
 		SELECT id, session_id, repository_path, repository_name, hash, message,
 			author_name, author_email, timestamp, branch, is_merge, parent_hashes,
 			full_diff, diff_truncated, diff_truncated_at, correlation_type,
-			created_at, updated_at
+			correlation_confidence, created_at, updated_at
 		FROM commits
 		WHERE repository_path = ?
 		ORDER BY timestamp ASC
@@ -490,6 +544,7 @@ func (cs *commitStorage) scanCommitRow(rows *sql.Rows) (*StoredCommit, error) {
 	var commit StoredCommit
 	var sessionIDNull, correlationTypeNull, parentHashesJSON, fullDiffNull sql.NullString
 	var diffTruncatedAtNull sql.NullInt64
+	var correlationConfidenceNull sql.NullFloat64
 	var isMergeInt, diffTruncatedInt int
 
 	err := rows.Scan(
@@ -509,6 +564,7 @@ func (cs *commitStorage) scanCommitRow(rows *sql.Rows) (*StoredCommit, error) {
 		&diffTruncatedInt,
 		&diffTruncatedAtNull,
 		&correlationTypeNull,
+		&correlationConfidenceNull,
 		&commit.CreatedAt,
 		&commit.UpdatedAt,
 	)
@@ -523,6 +579,9 @@ func (cs *commitStorage) scanCommitRow(rows *sql.Rows) (*StoredCommit, error) {
 	if correlationTypeNull.Valid {
 		commit.CorrelationType = &correlationTypeNull.String
 	}
+	if correlationConfidenceNull.Valid {
+		commit.CorrelationConfidence = &correlationConfidenceNull.Float64
+	}
 	if diffTruncatedAtNull.Valid {
 		truncatedAt := int(diffTruncatedAtNull.Int64)
 		commit.DiffTruncatedAt = &truncatedAt
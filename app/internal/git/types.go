@@ -13,8 +13,9 @@ const (
 type Repository struct {
 	Path       string // Repository root path
 	Name       string // Repository name (derived from directory name)
-	GitDir     string // Path to .git directory or file (for worktrees)
+	GitDir     string // Path to .git directory or file (for worktrees); Path itself for bare repositories
 	IsWorktree bool   // Whether this is a git worktree
+	IsBare     bool   // Whether this is a bare or mirror repository without a worktree
 }
 
 // Commit represents a git commit with metadata
@@ -31,19 +32,19 @@ type Commit struct {
 
 // CommitDiff represents a commit diff with file-level changes
 type CommitDiff struct {
-	CommitHash  string    // Commit hash this diff belongs to
-	FullDiff    string    // Full commit diff (may be truncated)
+	CommitHash  string     // Commit hash this diff belongs to
+	FullDiff    string     // Full commit diff (may be truncated)
 	Files       []FileDiff // File-level diffs
-	IsTruncated bool      // Whether diff was truncated
-	TruncatedAt int       // Line count where truncated (if applicable)
+	IsTruncated bool       // Whether diff was truncated
+	TruncatedAt int        // Line count where truncated (if applicable)
 }
 
 // FileDiff represents file-level diff information
 type FileDiff struct {
-	Path        string // File path relative to repository root
-	LinesAdded  int    // Lines added
-	LinesRemoved int   // Lines removed
-	Diff        string // File-level diff content
+	Path         string // File path relative to repository root
+	LinesAdded   int    // Lines added
+	LinesRemoved int    // Lines removed
+	Diff         string // File-level diff content
 }
 
 // CommitSessionCorrelation represents correlation between a commit and a session
@@ -51,19 +52,21 @@ type CommitSessionCorrelation struct {
 	CommitHash      string        // Commit hash
 	SessionID       string        // Session ID (may be empty if no correlation)
 	Project         string        // Project name
-	CorrelationType string        // "active", "proximate", or "none"
+	CorrelationType string        // "active", "proximate", "post_session", or "none"
 	TimeDiff        time.Duration // Time difference to nearest conversation
+	Confidence      float64       // Confidence in [0, 1] from time distance, file overlap, and session activity
 }
 
 // CommitMetadata represents commit metadata extracted from a git commit
 type CommitMetadata struct {
-	Hash         string      // Commit hash (full SHA-1)
-	Message      string      // Commit message (including multi-line)
-	Timestamp    time.Time   // Commit timestamp (author time)
-	Author       AuthorInfo  // Author information
-	Branch       string      // Branch name (or "detached" if in detached HEAD state)
-	IsMerge      bool        // Whether this is a merge commit
-	ParentHashes []string    // Parent commit hashes
+	Hash         string     // Commit hash (full SHA-1)
+	Message      string     // Commit message (including multi-line)
+	Timestamp    time.Time  // Commit timestamp (author time)
+	Author       AuthorInfo // Author information
+	Branch       string     // Branch name (or "detached" if in detached HEAD state)
+	IsMerge      bool       // Whether this is a merge commit
+	ParentHashes []string   // Parent commit hashes
+	FilePaths    []string   // Paths changed by the commit (optional, improves correlation confidence)
 }
 
 // AuthorInfo represents author information for a commit
@@ -71,4 +74,3 @@ type AuthorInfo struct {
 	Name  string // Author name
 	Email string // Author email
 }
-
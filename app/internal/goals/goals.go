@@ -0,0 +1,473 @@
+// Package goals tracks milestones, inferring their progress from the commits and
+// sessions tagged with them.
+package goals
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+const (
+	// StatusDone marks a goal that was completed
+	StatusDone = "done"
+	// StatusOverdue marks an open goal past its due date
+	StatusOverdue = "overdue"
+	// StatusStalled marks an open goal without recent activity
+	StatusStalled = "stalled"
+	// StatusActive marks an open goal with recent activity
+	StatusActive = "active"
+
+	// StalledAfter is how long a goal can go without tagged activity before it's stalled
+	StalledAfter = 7 * 24 * time.Hour
+	// maxTagLength bounds tags derived from titles
+	maxTagLength = 40
+)
+
+var (
+	// tagPattern matches valid goal tags
+	tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+	// slugSeparators matches runs of characters replaced when deriving a tag from a title
+	slugSeparators = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// Goal is a milestone to track
+type Goal struct {
+	Tag           string // Short identifier; commits mentioning #<tag> count towards the goal
+	Title         string
+	Project       string    // Only count work in this project (case-insensitive); empty counts all
+	Due           time.Time // Local midnight of the due day; zero when there's no due date
+	TargetCommits int       // Tagged commits expected to finish the goal; zero when unknown
+	CreatedAt     time.Time
+	CompletedAt   time.Time // Zero while the goal is open
+}
+
+// Done reports whether the goal was completed
+func (g Goal) Done() bool {
+	return !g.CompletedAt.IsZero()
+}
+
+// Progress is a goal's progress inferred from its tagged work
+type Progress struct {
+	Goal
+	Commits      int           // Commits mentioning #<tag>
+	Sessions     int           // Sessions tagged with the goal or behind its commits
+	TimeSpent    time.Duration // Total duration of those sessions
+	LastActivity time.Time     // Latest tagged commit or session activity; zero when there's none
+	Percent      float64       // Share of the target reached in [0, 1]; -1 without a target
+	Status       string        // StatusDone, StatusOverdue, StatusStalled, or StatusActive
+}
+
+// Tracker defines the interface for managing goals
+type Tracker interface {
+	// Add creates a goal, deriving its tag from the title when it has none
+	Add(goal Goal) (*Goal, error)
+	// Get returns the goal with tag
+	Get(tag string) (*Goal, error)
+	// List returns goals by due date (goals without one last), including completed
+	// goals when includeDone is set
+	List(includeDone bool) ([]Goal, error)
+	// TagSession counts a session's work towards a goal
+	TagSession(tag, sessionID string, at time.Time) error
+	// Complete marks a goal as done
+	Complete(tag string, at time.Time) error
+	// Remove deletes a goal and its session tags
+	Remove(tag string) error
+	// Progress infers a goal's progress as of now
+	Progress(goal Goal, now time.Time) (*Progress, error)
+}
+
+// tracker implements Tracker on top of the clio database
+type tracker struct {
+	db     *sql.DB
+	logger logging.Logger
+}
+
+// NewTracker creates a goal tracker backed by the database
+func NewTracker(db *sql.DB, logger logging.Logger) (Tracker, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database cannot be nil")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	return &tracker{
+		db:     db,
+		logger: logger.With("component", "goals"),
+	}, nil
+}
+
+// Slug derives a tag from a goal title, e.g. "Ship v1 importer" becomes "ship-v1-importer"
+func Slug(title string) string {
+	slug := strings.Trim(slugSeparators.ReplaceAllString(strings.ToLower(title), "-"), "-")
+	if len(slug) > maxTagLength {
+		slug = strings.TrimRight(slug[:maxTagLength], "-")
+	}
+	return slug
+}
+
+// Add stores a new goal
+func (t *tracker) Add(goal Goal) (*Goal, error) {
+	goal.Title = strings.TrimSpace(goal.Title)
+	if goal.Title == "" {
+		return nil, fmt.Errorf("goal title cannot be empty")
+	}
+	if goal.Tag == "" {
+		goal.Tag = Slug(goal.Title)
+	}
+	goal.Tag = strings.ToLower(goal.Tag)
+	if !tagPattern.MatchString(goal.Tag) {
+		return nil, fmt.Errorf("invalid goal tag %q: use lowercase letters, digits, '.', '_', and '-'", goal.Tag)
+	}
+	if goal.TargetCommits < 0 {
+		return nil, fmt.Errorf("target commits cannot be negative")
+	}
+	if goal.CreatedAt.IsZero() {
+		goal.CreatedAt = time.Now()
+	}
+
+	if existing, err := t.Get(goal.Tag); err == nil {
+		return nil, fmt.Errorf("goal %q already exists: %s", existing.Tag, existing.Title)
+	}
+
+	if _, err := t.db.Exec(`
+		INSERT INTO goals (tag, title, project, due_date, target_commits, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, goal.Tag, goal.Title, nullString(goal.Project), nullTime(goal.Due), goal.TargetCommits, goal.CreatedAt); err != nil {
+		return nil, fmt.Errorf("failed to store goal: %w", err)
+	}
+
+	t.logger.Debug("added goal", "tag", goal.Tag, "project", goal.Project)
+	return &goal, nil
+}
+
+// Get looks up a goal by tag
+func (t *tracker) Get(tag string) (*Goal, error) {
+	goal, err := scanGoal(t.db.QueryRow(`
+		SELECT tag, title, project, due_date, target_commits, created_at, completed_at
+		FROM goals WHERE tag = ?
+	`, strings.ToLower(tag)))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("goal %q not found", tag)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up goal: %w", err)
+	}
+	return goal, nil
+}
+
+// List returns the stored goals
+func (t *tracker) List(includeDone bool) ([]Goal, error) {
+	rows, err := t.db.Query(`
+		SELECT tag, title, project, due_date, target_commits, created_at, completed_at
+		FROM goals
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query goals: %w", err)
+	}
+	defer rows.Close()
+
+	var goals []Goal
+	for rows.Next() {
+		goal, err := scanGoal(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan goal: %w", err)
+		}
+		if goal.Done() && !includeDone {
+			continue
+		}
+		goals = append(goals, *goal)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating goals: %w", err)
+	}
+
+	// Goals with a due date first, soonest due, then oldest
+	sort.SliceStable(goals, func(i, j int) bool {
+		a, b := goals[i], goals[j]
+		if a.Due.IsZero() != b.Due.IsZero() {
+			return b.Due.IsZero()
+		}
+		if !a.Due.Equal(b.Due) {
+			return a.Due.Before(b.Due)
+		}
+		return a.CreatedAt.Before(b.CreatedAt)
+	})
+	return goals, nil
+}
+
+// TagSession links a session to a goal
+func (t *tracker) TagSession(tag, sessionID string, at time.Time) error {
+	goal, err := t.Get(tag)
+	if err != nil {
+		return err
+	}
+
+	var exists bool
+	if err := t.db.QueryRow("SELECT EXISTS(SELECT 1 FROM sessions WHERE id = ?)", sessionID).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to look up session: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("session %s not found", sessionID)
+	}
+
+	if _, err := t.db.Exec(`
+		INSERT OR IGNORE INTO goal_sessions (goal_tag, session_id, created_at)
+		VALUES (?, ?, ?)
+	`, goal.Tag, sessionID, at); err != nil {
+		return fmt.Errorf("failed to tag session: %w", err)
+	}
+	return nil
+}
+
+// Complete records when a goal was finished
+func (t *tracker) Complete(tag string, at time.Time) error {
+	goal, err := t.Get(tag)
+	if err != nil {
+		return err
+	}
+	if _, err := t.db.Exec("UPDATE goals SET completed_at = ? WHERE tag = ?", at, goal.Tag); err != nil {
+		return fmt.Errorf("failed to complete goal: %w", err)
+	}
+	return nil
+}
+
+// Remove deletes a goal
+func (t *tracker) Remove(tag string) error {
+	goal, err := t.Get(tag)
+	if err != nil {
+		return err
+	}
+
+	// Foreign keys aren't enforced, so session tags are removed explicitly
+	if _, err := t.db.Exec("DELETE FROM goal_sessions WHERE goal_tag = ?", goal.Tag); err != nil {
+		return fmt.Errorf("failed to remove goal sessions: %w", err)
+	}
+	if _, err := t.db.Exec("DELETE FROM goals WHERE tag = ?", goal.Tag); err != nil {
+		return fmt.Errorf("failed to remove goal: %w", err)
+	}
+	return nil
+}
+
+// Progress counts the goal's tagged commits and sessions. A commit counts when its
+// message mentions #<tag>; a session counts when it was tagged with the goal or is
+// behind a counted commit. Work outside the goal's project isn't counted.
+func (t *tracker) Progress(goal Goal, now time.Time) (*Progress, error) {
+	progress := &Progress{Goal: goal, Percent: -1}
+
+	sessions, err := t.taggedSessions(goal.Tag)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.countCommits(goal, progress, sessions); err != nil {
+		return nil, err
+	}
+	if err := t.countSessions(goal, progress, sessions); err != nil {
+		return nil, err
+	}
+
+	if goal.TargetCommits > 0 {
+		progress.Percent = min(float64(progress.Commits)/float64(goal.TargetCommits), 1)
+	}
+
+	switch {
+	case goal.Done():
+		progress.Status = StatusDone
+		if goal.TargetCommits > 0 {
+			progress.Percent = 1
+		}
+	case !goal.Due.IsZero() && !now.Before(goal.Due.AddDate(0, 0, 1)):
+		progress.Status = StatusOverdue
+	case now.Sub(latest(progress.LastActivity, goal.CreatedAt)) > StalledAfter:
+		progress.Status = StatusStalled
+	default:
+		progress.Status = StatusActive
+	}
+	return progress, nil
+}
+
+// taggedSessions returns the IDs of sessions explicitly tagged with a goal
+func (t *tracker) taggedSessions(tag string) (map[string]bool, error) {
+	return taggedSessions(t.db, tag)
+}
+
+// taggedSessions returns the IDs of sessions explicitly tagged with tag
+func taggedSessions(db *sql.DB, tag string) (map[string]bool, error) {
+	rows, err := db.Query("SELECT session_id FROM goal_sessions WHERE goal_tag = ?", tag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query goal sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan goal session: %w", err)
+		}
+		sessions[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating goal sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// Sessions returns the IDs of the sessions behind a tag, counted as Progress
+// counts them: sessions tagged with it and sessions with a commit mentioning
+// #<tag>, in any project
+func Sessions(db *sql.DB, tag string) (map[string]bool, error) {
+	tag = strings.ToLower(tag)
+	sessions, err := taggedSessions(db, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	mention := mentionPattern(tag)
+	rows, err := db.Query(`
+		SELECT message, session_id
+		FROM commits
+		WHERE session_id IS NOT NULL AND message LIKE ? ESCAPE '\'
+	`, "%#"+escapeLike(tag)+"%")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query goal commits: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var message, sessionID string
+		if err := rows.Scan(&message, &sessionID); err != nil {
+			return nil, fmt.Errorf("failed to scan commit: %w", err)
+		}
+		if mention.MatchString(message) {
+			sessions[sessionID] = true
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating commits: %w", err)
+	}
+	return sessions, nil
+}
+
+// mentionPattern matches #<tag> in a commit message. A longer tag sharing the
+// prefix, like #ship-v1-importer for ship-v1, doesn't match.
+func mentionPattern(tag string) *regexp.Regexp {
+	return regexp.MustCompile(`(?i)#` + regexp.QuoteMeta(tag) + `([^\w.-]|$)`)
+}
+
+// countCommits counts commits mentioning the goal's tag and adds their sessions
+func (t *tracker) countCommits(goal Goal, progress *Progress, sessions map[string]bool) error {
+	mention := mentionPattern(goal.Tag)
+	rows, err := t.db.Query(`
+		SELECT hash, repository_name, message, timestamp, session_id
+		FROM commits
+		WHERE message LIKE ? ESCAPE '\'
+	`, "%#"+escapeLike(goal.Tag)+"%")
+	if err != nil {
+		return fmt.Errorf("failed to query goal commits: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var hash, repository, message string
+		var timestamp time.Time
+		var sessionID sql.NullString
+		if err := rows.Scan(&hash, &repository, &message, &timestamp, &sessionID); err != nil {
+			return fmt.Errorf("failed to scan commit: %w", err)
+		}
+		if !mention.MatchString(message) {
+			continue
+		}
+		if goal.Project != "" && !strings.EqualFold(goal.Project, repository) {
+			continue
+		}
+		progress.Commits++
+		progress.LastActivity = latest(progress.LastActivity, timestamp)
+		if sessionID.Valid {
+			sessions[sessionID.String] = true
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating commits: %w", err)
+	}
+	return nil
+}
+
+// countSessions adds the duration and activity of the goal's sessions
+func (t *tracker) countSessions(goal Goal, progress *Progress, sessions map[string]bool) error {
+	for id := range sessions {
+		var project sql.NullString
+		var start, lastActivity time.Time
+		var end sql.NullTime
+		err := t.db.QueryRow("SELECT project, start_time, end_time, last_activity FROM sessions WHERE id = ?", id).
+			Scan(&project, &start, &end, &lastActivity)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to query session: %w", err)
+		}
+		if goal.Project != "" && !strings.EqualFold(goal.Project, project.String) {
+			continue
+		}
+
+		finish := lastActivity
+		if end.Valid {
+			finish = end.Time
+		}
+		progress.Sessions++
+		if finish.After(start) {
+			progress.TimeSpent += finish.Sub(start)
+		}
+		progress.LastActivity = latest(progress.LastActivity, lastActivity)
+	}
+	return nil
+}
+
+// scanner is implemented by *sql.Row and *sql.Rows
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanGoal reads a goal row
+func scanGoal(row scanner) (*Goal, error) {
+	var goal Goal
+	var project sql.NullString
+	var due, completed sql.NullTime
+	if err := row.Scan(&goal.Tag, &goal.Title, &project, &due, &goal.TargetCommits, &goal.CreatedAt, &completed); err != nil {
+		return nil, err
+	}
+	goal.Project = project.String
+	goal.Due = due.Time
+	goal.CompletedAt = completed.Time
+	return &goal, nil
+}
+
+// latest returns the later of two times
+func latest(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+// escapeLike escapes LIKE wildcards so s matches literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// nullString stores empty strings as NULL
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// nullTime stores zero times as NULL
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
@@ -0,0 +1,162 @@
+package goals
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/logging"
+	_ "modernc.org/sqlite"
+)
+
+func setupTestTracker(t *testing.T) (*sql.DB, Tracker) {
+	database, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "clio.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if err := db.RunMigrations(database); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	tracker, err := NewTracker(database, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewTracker() error = %v", err)
+	}
+	return database, tracker
+}
+
+func TestSlug(t *testing.T) {
+	tests := map[string]string{
+		"Ship v1 importer":    "ship-v1-importer",
+		"  Fix #42: crashes ": "fix-42-crashes",
+		"Über-fast search!!":  "ber-fast-search",
+	}
+	for title, want := range tests {
+		if got := Slug(title); got != want {
+			t.Errorf("Slug(%q) = %q, want %q", title, got, want)
+		}
+	}
+}
+
+func TestTracker_AddListComplete(t *testing.T) {
+	_, tracker := setupTestTracker(t)
+
+	due := time.Date(2024, 6, 1, 0, 0, 0, 0, time.Local)
+	goal, err := tracker.Add(Goal{Title: "Ship v1 importer", Project: "clio", Due: due})
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if goal.Tag != "ship-v1-importer" {
+		t.Errorf("tag = %q, want it derived from the title", goal.Tag)
+	}
+	if _, err := tracker.Add(Goal{Title: "Again", Tag: "ship-v1-importer"}); err == nil {
+		t.Error("Add() with a duplicate tag should fail")
+	}
+	if _, err := tracker.Add(Goal{Title: "Bad", Tag: "has space"}); err == nil {
+		t.Error("Add() with an invalid tag should fail")
+	}
+	if _, err := tracker.Add(Goal{Title: "Docs", Tag: "docs"}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	goals, err := tracker.List(false)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(goals) != 2 || goals[0].Tag != "ship-v1-importer" || !goals[0].Due.Equal(due) {
+		t.Fatalf("List() = %+v, want the dated goal first", goals)
+	}
+
+	if err := tracker.Complete("SHIP-V1-IMPORTER", time.Now()); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if goals, _ = tracker.List(false); len(goals) != 1 || goals[0].Tag != "docs" {
+		t.Errorf("open goals = %+v, want only docs", goals)
+	}
+	if goals, _ = tracker.List(true); len(goals) != 2 {
+		t.Errorf("all goals = %+v, want both", goals)
+	}
+
+	if err := tracker.Remove("docs"); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if _, err := tracker.Get("docs"); err == nil {
+		t.Error("removed goal should not be found")
+	}
+}
+
+func TestTracker_Progress(t *testing.T) {
+	database, tracker := setupTestTracker(t)
+	base := time.Date(2024, 5, 1, 9, 0, 0, 0, time.Local)
+
+	for _, s := range []struct {
+		id, project string
+		start       time.Time
+	}{
+		{"s1", "clio", base},
+		{"s2", "clio", base.Add(24 * time.Hour)},
+		{"s3", "other", base.Add(48 * time.Hour)},
+	} {
+		if _, err := database.Exec(`
+			INSERT INTO sessions (id, project, start_time, end_time, last_activity, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, s.id, s.project, s.start, s.start.Add(time.Hour), s.start.Add(time.Hour), s.start, s.start); err != nil {
+			t.Fatalf("failed to create session: %v", err)
+		}
+	}
+	for _, c := range []struct {
+		hash, repo, message string
+		session             interface{}
+		at                  time.Time
+	}{
+		{"c1", "clio", "Parse exports #importer", "s1", base.Add(30 * time.Minute)},
+		{"c2", "clio", "Map fields (#Importer)", nil, base.Add(26 * time.Hour)},
+		{"c3", "clio", "Unrelated #importer-v2 work", "s2", base.Add(25 * time.Hour)},
+		{"c4", "other", "Elsewhere #importer", "s3", base.Add(49 * time.Hour)},
+	} {
+		if _, err := database.Exec(`
+			INSERT INTO commits (id, session_id, repository_path, repository_name, hash, message,
+				author_name, author_email, timestamp, branch, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, 'Dev', 'dev@example.com', ?, 'main', ?, ?)
+		`, c.hash, c.session, "/src/"+c.repo, c.repo, c.hash, c.message, c.at, c.at, c.at); err != nil {
+			t.Fatalf("failed to create commit: %v", err)
+		}
+	}
+
+	goal, err := tracker.Add(Goal{Title: "Importer", Tag: "importer", Project: "clio", TargetCommits: 4,
+		Due: time.Date(2024, 5, 10, 0, 0, 0, 0, time.Local), CreatedAt: base})
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := tracker.TagSession("importer", "s2", base); err != nil {
+		t.Fatalf("TagSession() error = %v", err)
+	}
+	if err := tracker.TagSession("importer", "missing", base); err == nil {
+		t.Error("TagSession() with an unknown session should fail")
+	}
+
+	progress, err := tracker.Progress(*goal, base.Add(3*24*time.Hour))
+	if err != nil {
+		t.Fatalf("Progress() error = %v", err)
+	}
+	if progress.Commits != 2 || progress.Sessions != 2 || progress.TimeSpent != 2*time.Hour {
+		t.Errorf("progress = %+v, want c1 and c2 across s1 and the tagged s2 (2h)", progress)
+	}
+	if progress.Percent != 0.5 || progress.Status != StatusActive {
+		t.Errorf("percent = %v, status = %s, want 0.5 and active", progress.Percent, progress.Status)
+	}
+	if !progress.LastActivity.Equal(base.Add(26 * time.Hour)) {
+		t.Errorf("last activity = %v, want c2's time", progress.LastActivity)
+	}
+
+	if progress, _ = tracker.Progress(*goal, base.Add(12*24*time.Hour)); progress.Status != StatusOverdue {
+		t.Errorf("status after the due date = %s, want overdue", progress.Status)
+	}
+	goal.Due = time.Time{}
+	if progress, _ = tracker.Progress(*goal, base.Add(12*24*time.Hour)); progress.Status != StatusStalled {
+		t.Errorf("status after a quiet week = %s, want stalled", progress.Status)
+	}
+}
@@ -0,0 +1,169 @@
+package heartbeat
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/cursor"
+	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/logging"
+	_ "modernc.org/sqlite"
+)
+
+func setupTestDB(t *testing.T) *sql.DB {
+	database, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "clio.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if err := db.RunMigrations(database); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+	return database
+}
+
+func countHeartbeats(t *testing.T, database *sql.DB, where string, args ...interface{}) int {
+	var count int
+	if err := database.QueryRow("SELECT COUNT(*) FROM heartbeats WHERE "+where, args...).Scan(&count); err != nil {
+		t.Fatalf("failed to count heartbeats: %v", err)
+	}
+	return count
+}
+
+func TestRecorder_Record(t *testing.T) {
+	database := setupTestDB(t)
+	cfg := &config.Config{Session: config.SessionConfig{InactivityTimeoutMinutes: 30}}
+	sessions, err := cursor.NewSessionManager(cfg, database)
+	if err != nil {
+		t.Fatalf("failed to create session manager: %v", err)
+	}
+
+	now := time.Now()
+	session, err := sessions.GetOrCreateSession("clio", &cursor.Conversation{
+		ComposerID: "composer-1",
+		CreatedAt:  now,
+		Messages:   []cursor.Message{{BubbleID: "b1", Type: 1, Role: "user", Text: "hi", CreatedAt: now}},
+	})
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+
+	recorder, err := NewRecorder(database, sessions, []string{"/home/dev/code"}, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewRecorder() error = %v", err)
+	}
+
+	at := float64(now.Add(10*time.Minute).UnixMilli()) / 1000
+	heartbeats := []Heartbeat{
+		{Entity: "/home/dev/code/clio/main.go", Time: at, IsWrite: true, Language: "Go"},
+		{Entity: "/elsewhere/notes.md", Time: at, Project: "notes"},
+		{Entity: "", Time: at},
+		{Entity: "/home/dev/code/clio/go.mod"},
+	}
+
+	result, err := recorder.Record(heartbeats)
+	if err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if result.Received != 4 || result.Stored != 2 || result.Invalid != 2 {
+		t.Errorf("result = %+v, want 4 received, 2 stored, 2 invalid", result)
+	}
+
+	if n := countHeartbeats(t, database, "project = 'clio' AND session_id = ? AND is_write = 1", session.ID); n != 1 {
+		t.Errorf("clio heartbeats in the active session = %d, want 1 (project from watched directory)", n)
+	}
+	if n := countHeartbeats(t, database, "project = 'notes' AND session_id IS NULL"); n != 1 {
+		t.Errorf("notes heartbeats without a session = %d, want 1", n)
+	}
+	if got := session.LastActivity.Unix(); got != now.Add(10*time.Minute).Unix() {
+		t.Errorf("session last activity = %v, want extended to the heartbeat", session.LastActivity)
+	}
+
+	// Editors resend heartbeats queued while offline
+	result, err = recorder.Record(heartbeats[:1])
+	if err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if result.Stored != 0 {
+		t.Errorf("resent heartbeat stored %d, want 0", result.Stored)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    int
+		wantErr bool
+	}{
+		{name: "object", data: `{"entity":"a.go","time":1}`, want: 1},
+		{name: "array", data: ` [{"entity":"a.go","time":1},{"entity":"b.go","time":2}]`, want: 2},
+		{name: "blank", data: "  ", want: 0},
+		{name: "invalid", data: `{"entity":`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			heartbeats, err := Decode([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Decode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(heartbeats) != tt.want {
+				t.Errorf("Decode() returned %d heartbeats, want %d", len(heartbeats), tt.want)
+			}
+		})
+	}
+}
+
+func TestLogWatcher_Poll(t *testing.T) {
+	database := setupTestDB(t)
+	recorder, err := NewRecorder(database, nil, nil, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewRecorder() error = %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "heartbeats.jsonl")
+	watcher, err := NewLogWatcher(path, time.Second, recorder, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewLogWatcher() error = %v", err)
+	}
+	w := watcher.(*logWatcher)
+	w.poll() // A missing log is not an error
+
+	content := `{"entity":"/src/a.go","time":1700000000}
+not json
+[{"entity":"/src/b.go","time":1700000060},{"entity":"/src/c.go","time":1700000120}]
+{"entity":"/src/partial.go","ti`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write log: %v", err)
+	}
+	w.poll()
+	if n := countHeartbeats(t, database, "1 = 1"); n != 3 {
+		t.Fatalf("stored %d heartbeats, want 3 (invalid and partial lines skipped)", n)
+	}
+
+	// Completing the partial line records it on the next poll
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("failed to open log: %v", err)
+	}
+	file.WriteString(`me":1700000180}` + "\n")
+	file.Close()
+	w.poll()
+	if n := countHeartbeats(t, database, "entity = '/src/partial.go'"); n != 1 {
+		t.Errorf("completed line stored %d times, want 1", n)
+	}
+
+	// A rotated log is read from the start
+	if err := os.WriteFile(path, []byte(`{"entity":"/src/d.go","time":1700000240}`+"\n"), 0644); err != nil {
+		t.Fatalf("failed to rotate log: %v", err)
+	}
+	w.poll()
+	if n := countHeartbeats(t, database, "entity = '/src/d.go'"); n != 1 {
+		t.Errorf("heartbeat from rotated log stored %d times, want 1", n)
+	}
+}
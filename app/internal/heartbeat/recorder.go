@@ -0,0 +1,149 @@
+package heartbeat
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/cursor"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+const (
+	// EntityTypeFile is the default heartbeat entity type
+	EntityTypeFile = "file"
+)
+
+// Heartbeat is editor activity on an entity, in WakaTime's heartbeat format
+type Heartbeat struct {
+	Entity   string  `json:"entity"`   // File path, app name, or domain
+	Type     string  `json:"type"`     // "file" (default), "app", or "domain"
+	Category string  `json:"category"` // e.g. "coding", "debugging", "code reviewing"
+	Time     float64 `json:"time"`     // Unix seconds with a fractional part
+	Project  string  `json:"project"`  // Detected from the entity's path when empty
+	Branch   string  `json:"branch"`
+	Language string  `json:"language"`
+	IsWrite  bool    `json:"is_write"` // The heartbeat was triggered by saving the file
+}
+
+// Timestamp returns the heartbeat time
+func (h Heartbeat) Timestamp() time.Time {
+	seconds, fraction := math.Modf(h.Time)
+	return time.Unix(int64(seconds), int64(fraction*float64(time.Second)))
+}
+
+// Result reports what a batch of heartbeats did
+type Result struct {
+	Received int `json:"received"`
+	Stored   int `json:"stored"`  // New heartbeats written; duplicates aren't counted
+	Invalid  int `json:"invalid"` // Heartbeats without an entity or time
+}
+
+// Recorder defines the interface for storing editor heartbeats
+type Recorder interface {
+	// Record stores heartbeats and extends the active session of each heartbeat's
+	// project. Heartbeats already stored (same entity and time) are ignored.
+	Record(heartbeats []Heartbeat) (*Result, error)
+}
+
+// recorder implements Recorder on top of the clio database
+type recorder struct {
+	db                 *sql.DB
+	sessions           cursor.SessionManager // Nil when heartbeats are only stored
+	projects           cursor.ProjectNamer
+	watchedDirectories []string
+	logger             logging.Logger
+}
+
+// NewRecorder creates a heartbeat recorder. Heartbeats extend sessions in sessions
+// when given; projects are detected from watchedDirectories when a heartbeat has none.
+func NewRecorder(db *sql.DB, sessions cursor.SessionManager, watchedDirectories []string, logger logging.Logger) (Recorder, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database cannot be nil")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	projects, err := cursor.NewProjectNamer(db, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create project namer: %w", err)
+	}
+
+	return &recorder{
+		db:                 db,
+		sessions:           sessions,
+		projects:           projects,
+		watchedDirectories: watchedDirectories,
+		logger:             logger.With("component", "heartbeats"),
+	}, nil
+}
+
+// Record stores a batch of heartbeats
+func (r *recorder) Record(heartbeats []Heartbeat) (*Result, error) {
+	result := &Result{Received: len(heartbeats)}
+	now := time.Now()
+
+	for _, h := range heartbeats {
+		if strings.TrimSpace(h.Entity) == "" || h.Time <= 0 {
+			result.Invalid++
+			continue
+		}
+		if h.Type == "" {
+			h.Type = EntityTypeFile
+		}
+
+		at := h.Timestamp()
+		project := h.Project
+		if project == "" {
+			project = detectProject(h.Entity, r.watchedDirectories)
+		}
+		project = r.projects.ProjectName(project)
+
+		var sessionID string
+		if r.sessions != nil {
+			sessionID, _ = r.sessions.RecordActivity(project, at)
+		}
+
+		res, err := r.db.Exec(`
+			INSERT OR IGNORE INTO heartbeats (session_id, project, entity, entity_type, category, language, branch, is_write, time, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, nullString(sessionID), project, h.Entity, h.Type, nullString(h.Category), nullString(h.Language),
+			nullString(h.Branch), h.IsWrite, at, now)
+		if err != nil {
+			return result, fmt.Errorf("failed to store heartbeat: %w", err)
+		}
+		if n, err := res.RowsAffected(); err == nil {
+			result.Stored += int(n)
+		}
+	}
+
+	if result.Stored > 0 || result.Invalid > 0 {
+		r.logger.Debug("recorded heartbeats", "received", result.Received, "stored", result.Stored, "invalid", result.Invalid)
+	}
+	return result, nil
+}
+
+// detectProject returns the path of the directory directly under a watched directory that
+// contains entity, or "" when entity is outside every watched directory
+func detectProject(entity string, watchedDirectories []string) string {
+	entity = filepath.Clean(entity)
+	for _, dir := range watchedDirectories {
+		rel, err := filepath.Rel(filepath.Clean(dir), entity)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		if first, _, found := strings.Cut(filepath.ToSlash(rel), "/"); found {
+			return filepath.Join(dir, first)
+		}
+	}
+	return ""
+}
+
+// nullString stores empty strings as NULL
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
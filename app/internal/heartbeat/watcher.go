@@ -0,0 +1,206 @@
+package heartbeat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+const (
+	// maxReadPerPoll bounds how much of the log is read in one poll
+	maxReadPerPoll = 4 * 1024 * 1024
+)
+
+// LogWatcher defines the interface for tailing a heartbeat log
+type LogWatcher interface {
+	Start() error
+	Stop() error
+}
+
+// logWatcher tails a JSON-lines heartbeat log, recording complete lines as they're appended
+type logWatcher struct {
+	path     string
+	interval time.Duration
+	recorder Recorder
+	logger   logging.Logger
+	offset   int64 // Bytes of the log already recorded (poll goroutine only)
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	started  bool
+	mu       sync.Mutex
+}
+
+// NewLogWatcher creates a watcher for the heartbeat log at path. The whole log is
+// recorded on start; heartbeats already stored are ignored.
+func NewLogWatcher(path string, interval time.Duration, recorder Recorder, logger logging.Logger) (LogWatcher, error) {
+	if path == "" {
+		return nil, fmt.Errorf("log path cannot be empty")
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("poll interval must be positive")
+	}
+	if recorder == nil {
+		return nil, fmt.Errorf("recorder cannot be nil")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &logWatcher{
+		path:     path,
+		interval: interval,
+		recorder: recorder,
+		logger:   logger.With("component", "heartbeat_log"),
+		ctx:      ctx,
+		cancel:   cancel,
+	}, nil
+}
+
+// Start begins tailing the log
+func (w *logWatcher) Start() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.started {
+		return fmt.Errorf("heartbeat log watcher is already started")
+	}
+
+	w.wg.Add(1)
+	go w.run()
+
+	w.started = true
+	w.logger.Info("heartbeat log watcher started", "path", w.path, "interval", w.interval)
+	return nil
+}
+
+// Stop stops tailing and waits for an in-progress poll to finish
+func (w *logWatcher) Stop() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.started {
+		return nil
+	}
+
+	w.cancel()
+	w.wg.Wait()
+
+	w.started = false
+	w.logger.Info("heartbeat log watcher stopped")
+	return nil
+}
+
+// run polls immediately, then on every interval until stopped
+func (w *logWatcher) run() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.poll()
+
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll records the complete lines appended since the last poll. A log that shrank
+// was truncated or rotated and is read from the start.
+func (w *logWatcher) poll() {
+	file, err := os.Open(w.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			w.logger.Warn("failed to open heartbeat log", "path", w.path, "error", err)
+		}
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		w.logger.Warn("failed to stat heartbeat log", "path", w.path, "error", err)
+		return
+	}
+	if info.Size() < w.offset {
+		w.offset = 0
+	}
+	if info.Size() == w.offset {
+		return
+	}
+
+	data, err := io.ReadAll(io.NewSectionReader(file, w.offset, min(info.Size()-w.offset, maxReadPerPoll)))
+	if err != nil {
+		w.logger.Warn("failed to read heartbeat log", "path", w.path, "error", err)
+		return
+	}
+
+	// A trailing partial line is still being written; it's read on the next poll
+	end := bytes.LastIndexByte(data, '\n')
+	if end < 0 {
+		return
+	}
+
+	heartbeats, invalidLines := parseLines(data[:end+1])
+	if invalidLines > 0 {
+		w.logger.Warn("skipped invalid heartbeat log lines", "path", w.path, "lines", invalidLines)
+	}
+	if len(heartbeats) > 0 {
+		if _, err := w.recorder.Record(heartbeats); err != nil {
+			w.logger.Error("failed to record heartbeats", "path", w.path, "error", err)
+			return // Retried on the next poll
+		}
+	}
+	w.offset += int64(end + 1)
+}
+
+// parseLines decodes heartbeat log lines, each a heartbeat object or an array of them,
+// and counts lines that couldn't be decoded
+func parseLines(data []byte) ([]Heartbeat, int) {
+	var heartbeats []Heartbeat
+	invalid := 0
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		batch, err := Decode(line)
+		if err != nil {
+			invalid++
+			continue
+		}
+		heartbeats = append(heartbeats, batch...)
+	}
+	return heartbeats, invalid
+}
+
+// Decode reads a heartbeat object or an array of heartbeats, as sent to WakaTime's
+// heartbeats and heartbeats.bulk endpoints. Blank input decodes to no heartbeats.
+func Decode(data []byte) ([]Heartbeat, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	if data[0] == '[' {
+		var heartbeats []Heartbeat
+		if err := json.Unmarshal(data, &heartbeats); err != nil {
+			return nil, fmt.Errorf("failed to decode heartbeats: %w", err)
+		}
+		return heartbeats, nil
+	}
+
+	var heartbeat Heartbeat
+	if err := json.Unmarshal(data, &heartbeat); err != nil {
+		return nil, fmt.Errorf("failed to decode heartbeat: %w", err)
+	}
+	return []Heartbeat{heartbeat}, nil
+}
@@ -0,0 +1,154 @@
+// Package idle records periods the machine was asleep, so sessions don't run
+// across an overnight sleep and reports can leave the gaps out of time worked.
+// Sleep is detected from clock jumps: the wall clock keeps running while the
+// machine sleeps, but the monotonic clock Go measures elapsed time with doesn't.
+package idle
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+// Kind is what made the machine idle
+type Kind string
+
+const (
+	// KindSleep is a period the machine was suspended
+	KindSleep Kind = "sleep"
+
+	// SourceClockJump marks gaps detected from the wall clock jumping ahead of the monotonic clock
+	SourceClockJump = "clock_jump"
+
+	// DefaultJumpThreshold is the shortest clock jump treated as sleep; shorter
+	// jumps are more likely clock adjustments
+	DefaultJumpThreshold = 2 * time.Minute
+)
+
+// Gap is a period the machine was idle
+type Gap struct {
+	ID     int64
+	Kind   Kind
+	Start  time.Time
+	End    time.Time
+	Source string
+}
+
+// Duration returns how long the gap lasted
+func (g Gap) Duration() time.Duration {
+	return g.End.Sub(g.Start)
+}
+
+// Store defines the interface for recording idle gaps
+type Store interface {
+	// Record stores a gap and returns it with its ID
+	Record(gap Gap) (*Gap, error)
+}
+
+// store implements Store on top of the clio database
+type store struct {
+	db     *sql.DB
+	logger logging.Logger
+}
+
+// NewStore creates an idle gap store backed by the database
+func NewStore(db *sql.DB, logger logging.Logger) (Store, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database cannot be nil")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	return &store{
+		db:     db,
+		logger: logger.With("component", "idle"),
+	}, nil
+}
+
+// Record stores a gap
+func (s *store) Record(gap Gap) (*Gap, error) {
+	if !gap.End.After(gap.Start) {
+		return nil, fmt.Errorf("gap must end after it starts")
+	}
+	result, err := s.db.Exec(`
+		INSERT INTO idle_gaps (kind, start_time, end_time, source, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, string(gap.Kind), gap.Start, gap.End, gap.Source, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to record idle gap: %w", err)
+	}
+	if gap.ID, err = result.LastInsertId(); err != nil {
+		return nil, fmt.Errorf("failed to read idle gap ID: %w", err)
+	}
+	s.logger.Info("recorded idle gap", "kind", gap.Kind, "start", gap.Start, "end", gap.End, "duration", gap.Duration().String())
+	return &gap, nil
+}
+
+// Between returns the gaps overlapping [from, to), oldest first
+func Between(db *sql.DB, from, to time.Time) ([]Gap, error) {
+	rows, err := db.Query("SELECT id, kind, start_time, end_time, source FROM idle_gaps")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query idle gaps: %w", err)
+	}
+	defer rows.Close()
+
+	var gaps []Gap
+	for rows.Next() {
+		var gap Gap
+		var kind string
+		if err := rows.Scan(&gap.ID, &kind, &gap.Start, &gap.End, &gap.Source); err != nil {
+			return nil, fmt.Errorf("failed to scan idle gap: %w", err)
+		}
+		gap.Kind = Kind(kind)
+		if gap.End.After(from) && gap.Start.Before(to) {
+			gaps = append(gaps, gap)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate idle gaps: %w", err)
+	}
+
+	sort.Slice(gaps, func(i, j int) bool { return gaps[i].Start.Before(gaps[j].Start) })
+	return gaps, nil
+}
+
+// JumpWatch detects sleep from clock jumps between successive observations
+type JumpWatch struct {
+	mu        sync.Mutex
+	last      time.Time
+	threshold time.Duration
+}
+
+// NewJumpWatch creates a watch that treats clock jumps of at least threshold as sleep
+func NewJumpWatch(threshold time.Duration) *JumpWatch {
+	return &JumpWatch{threshold: threshold}
+}
+
+// Observe records the current time, which must carry a monotonic reading as
+// time.Now does, and returns the sleep since the previous observation, if any.
+// The gap spans the two observations, so observe often to keep it tight.
+func (w *JumpWatch) Observe(now time.Time) (Gap, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	last := w.last
+	w.last = now
+	if last.IsZero() {
+		return Gap{}, false
+	}
+	return detectJump(last.Round(0), now.Round(0), now.Sub(last), w.threshold)
+}
+
+// detectJump returns a sleep gap when the wall clock advanced at least threshold
+// more than the awake time between two observations
+func detectJump(lastWall, nowWall time.Time, awake, threshold time.Duration) (Gap, bool) {
+	if nowWall.Sub(lastWall)-awake < threshold {
+		return Gap{}, false
+	}
+	return Gap{Kind: KindSleep, Start: lastWall, End: nowWall, Source: SourceClockJump}, true
+}
@@ -0,0 +1,79 @@
+package idle
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/logging"
+	_ "modernc.org/sqlite"
+)
+
+func TestDetectJump(t *testing.T) {
+	last := time.Date(2024, 5, 1, 18, 0, 0, 0, time.UTC)
+
+	// 15s awake and 15s of wall clock: no sleep
+	if _, ok := detectJump(last, last.Add(15*time.Second), 15*time.Second, DefaultJumpThreshold); ok {
+		t.Error("detectJump() without a jump should report no sleep")
+	}
+	// A one minute clock adjustment is below the threshold
+	if _, ok := detectJump(last, last.Add(75*time.Second), 15*time.Second, DefaultJumpThreshold); ok {
+		t.Error("detectJump() below the threshold should report no sleep")
+	}
+
+	now := last.Add(14 * time.Hour)
+	gap, ok := detectJump(last, now, 10*time.Second, DefaultJumpThreshold)
+	if !ok || !gap.Start.Equal(last) || !gap.End.Equal(now) || gap.Kind != KindSleep || gap.Source != SourceClockJump {
+		t.Errorf("detectJump() = %+v, %v, want a sleep between the observations", gap, ok)
+	}
+}
+
+func TestJumpWatch_Observe(t *testing.T) {
+	watch := NewJumpWatch(DefaultJumpThreshold)
+	now := time.Now()
+	if _, ok := watch.Observe(now); ok {
+		t.Error("first Observe() should report no sleep")
+	}
+	if _, ok := watch.Observe(now.Add(time.Hour)); ok {
+		t.Error("Observe() with the monotonic clock advancing as much as the wall clock should report no sleep")
+	}
+}
+
+func TestStore_RecordBetween(t *testing.T) {
+	database, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "clio.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if err := db.RunMigrations(database); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	store, err := NewStore(database, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+
+	night := time.Date(2024, 5, 1, 19, 0, 0, 0, time.UTC)
+	for _, gap := range []Gap{
+		{Kind: KindSleep, Start: night, End: night.Add(12 * time.Hour), Source: SourceClockJump},
+		{Kind: KindSleep, Start: night.Add(-6 * time.Hour), End: night.Add(-5 * time.Hour), Source: SourceClockJump},
+	} {
+		if recorded, err := store.Record(gap); err != nil || recorded.ID == 0 {
+			t.Fatalf("Record() = %+v, %v", recorded, err)
+		}
+	}
+	if _, err := store.Record(Gap{Kind: KindSleep, Start: night, End: night}); err == nil {
+		t.Error("Record() of an empty gap should fail")
+	}
+
+	gaps, err := Between(database, night.Add(-6*time.Hour), night.Add(time.Hour))
+	if err != nil || len(gaps) != 2 || !gaps[0].Start.Equal(night.Add(-6*time.Hour)) {
+		t.Errorf("Between() = %+v, %v, want both gaps, oldest first", gaps, err)
+	}
+	if gaps, err := Between(database, night.Add(-4*time.Hour), night); err != nil || len(gaps) != 0 {
+		t.Errorf("Between() of the evening = %+v, %v, want none", gaps, err)
+	}
+}
@@ -0,0 +1,205 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/cursor"
+)
+
+// chatGPTConversation is a conversation in a ChatGPT export's conversations.json.
+// Messages form a tree (edits and regenerations branch it); current_node is the
+// leaf of the branch shown in the UI.
+type chatGPTConversation struct {
+	ID             string                 `json:"id"`
+	ConversationID string                 `json:"conversation_id"`
+	Title          string                 `json:"title"`
+	CreateTime     float64                `json:"create_time"`
+	CurrentNode    string                 `json:"current_node"`
+	Mapping        map[string]chatGPTNode `json:"mapping"`
+}
+
+// chatGPTNode is a node in a ChatGPT conversation tree
+type chatGPTNode struct {
+	ID      string          `json:"id"`
+	Parent  string          `json:"parent"`
+	Message *chatGPTMessage `json:"message"`
+}
+
+// chatGPTMessage is a message attached to a ChatGPT conversation tree node
+type chatGPTMessage struct {
+	ID     string `json:"id"`
+	Author struct {
+		Role string `json:"role"`
+	} `json:"author"`
+	CreateTime float64 `json:"create_time"`
+	Content    struct {
+		ContentType string            `json:"content_type"`
+		Parts       []json.RawMessage `json:"parts"`
+	} `json:"content"`
+}
+
+// parseChatGPT converts a ChatGPT export into conversations, following the current branch of each tree
+func parseChatGPT(data []byte) ([]*cursor.Conversation, error) {
+	var exported []chatGPTConversation
+	if err := json.Unmarshal(data, &exported); err != nil {
+		return nil, fmt.Errorf("failed to decode conversations: %w", err)
+	}
+
+	conversations := make([]*cursor.Conversation, 0, len(exported))
+	for _, conv := range exported {
+		id := conv.ID
+		if id == "" {
+			id = conv.ConversationID
+		}
+		if id == "" {
+			continue
+		}
+
+		var messages []cursor.Message
+		for _, node := range chatGPTBranch(conv) {
+			msg := node.Message
+			if msg == nil || (msg.Author.Role != "user" && msg.Author.Role != "assistant") {
+				continue
+			}
+			text := chatGPTText(msg.Content.Parts)
+			if text == "" {
+				continue
+			}
+			createdAt := unixSeconds(msg.CreateTime)
+			if createdAt.IsZero() {
+				createdAt = unixSeconds(conv.CreateTime)
+			}
+			messageID := msg.ID
+			if messageID == "" {
+				messageID = node.ID
+			}
+			messages = append(messages, newMessage(FormatChatGPT+"-"+messageID, msg.Author.Role, text, createdAt))
+		}
+
+		conversations = append(conversations, newConversation(FormatChatGPT, id, conv.Title, unixSeconds(conv.CreateTime), messages))
+	}
+	return conversations, nil
+}
+
+// chatGPTBranch returns the nodes from the root to current_node in order
+func chatGPTBranch(conv chatGPTConversation) []chatGPTNode {
+	var branch []chatGPTNode
+	visited := make(map[string]bool)
+	for id := conv.CurrentNode; id != "" && !visited[id]; {
+		node, ok := conv.Mapping[id]
+		if !ok {
+			break
+		}
+		visited[id] = true
+		branch = append(branch, node)
+		id = node.Parent
+	}
+
+	for left, right := 0, len(branch)-1; left < right; left, right = left+1, right-1 {
+		branch[left], branch[right] = branch[right], branch[left]
+	}
+	return branch
+}
+
+// chatGPTText joins the string parts of a message; non-text parts such as images are skipped
+func chatGPTText(parts []json.RawMessage) string {
+	var texts []string
+	for _, part := range parts {
+		var text string
+		if err := json.Unmarshal(part, &text); err != nil {
+			continue
+		}
+		if text = strings.TrimSpace(text); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	return strings.Join(texts, "\n\n")
+}
+
+// unixSeconds converts a fractional Unix timestamp, returning the zero time for zero
+func unixSeconds(seconds float64) time.Time {
+	if seconds <= 0 {
+		return time.Time{}
+	}
+	whole, frac := math.Modf(seconds)
+	return time.Unix(int64(whole), int64(frac*float64(time.Second)))
+}
+
+// claudeConversation is a conversation in a Claude export's conversations.json
+type claudeConversation struct {
+	UUID         string          `json:"uuid"`
+	Name         string          `json:"name"`
+	CreatedAt    time.Time       `json:"created_at"`
+	ChatMessages []claudeMessage `json:"chat_messages"`
+}
+
+// claudeMessage is a message in a Claude conversation
+type claudeMessage struct {
+	UUID      string    `json:"uuid"`
+	Text      string    `json:"text"`
+	Sender    string    `json:"sender"`
+	CreatedAt time.Time `json:"created_at"`
+	Content   []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+}
+
+// parseClaude converts a Claude export into conversations
+func parseClaude(data []byte) ([]*cursor.Conversation, error) {
+	var exported []claudeConversation
+	if err := json.Unmarshal(data, &exported); err != nil {
+		return nil, fmt.Errorf("failed to decode conversations: %w", err)
+	}
+
+	conversations := make([]*cursor.Conversation, 0, len(exported))
+	for _, conv := range exported {
+		if conv.UUID == "" {
+			continue
+		}
+
+		var messages []cursor.Message
+		for _, msg := range conv.ChatMessages {
+			if msg.UUID == "" || (msg.Sender != "human" && msg.Sender != "assistant") {
+				continue
+			}
+			text := claudeText(msg)
+			if text == "" {
+				continue
+			}
+			createdAt := msg.CreatedAt
+			if createdAt.IsZero() {
+				createdAt = conv.CreatedAt
+			}
+			role := "assistant"
+			if msg.Sender == "human" {
+				role = "user"
+			}
+			messages = append(messages, newMessage(FormatClaude+"-"+msg.UUID, role, text, createdAt))
+		}
+
+		conversations = append(conversations, newConversation(FormatClaude, conv.UUID, conv.Name, conv.CreatedAt, messages))
+	}
+	return conversations, nil
+}
+
+// claudeText prefers the structured text content blocks, falling back to the flat text field
+func claudeText(msg claudeMessage) string {
+	var texts []string
+	for _, block := range msg.Content {
+		if block.Type != "text" {
+			continue
+		}
+		if text := strings.TrimSpace(block.Text); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	if len(texts) > 0 {
+		return strings.Join(texts, "\n\n")
+	}
+	return strings.TrimSpace(msg.Text)
+}
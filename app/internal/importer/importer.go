@@ -0,0 +1,265 @@
+package importer
+
+import (
+	"archive/zip"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/cursor"
+	"github.com/stwalsh4118/clio/internal/logging"
+	"github.com/stwalsh4118/clio/internal/sensitive"
+)
+
+const (
+	// FormatChatGPT is the ChatGPT data export (Settings > Data controls > Export data)
+	FormatChatGPT = "chatgpt"
+	// FormatClaude is the Claude data export (Settings > Privacy > Export data)
+	FormatClaude = "claude"
+
+	// conversationsFileName is the file holding conversations in both export formats
+	conversationsFileName = "conversations.json"
+
+	// importSessionPrefix prefixes session IDs created for imported conversations
+	importSessionPrefix = "import"
+)
+
+// Formats returns the supported export formats
+func Formats() []string {
+	return []string{FormatChatGPT, FormatClaude}
+}
+
+// Options controls an import
+type Options struct {
+	Project string // Project the imported conversations are attributed to (required)
+	DryRun  bool   // Parse and count without writing to the database
+}
+
+// Result reports what an import did
+type Result struct {
+	Conversations int // Conversations imported (or that would be imported)
+	Messages      int // Messages imported (or that would be imported)
+	Skipped       int // Conversations skipped because they had no messages
+}
+
+// Importer defines the interface for importing conversations from AI assistant data exports
+type Importer interface {
+	// Import reads an export (zip archive, extracted directory, or conversations.json)
+	// and stores its conversations. Re-importing the same export updates in place.
+	Import(format, path string, opts Options) (*Result, error)
+}
+
+// importer implements Importer on top of conversation storage
+type importer struct {
+	db      *sql.DB
+	storage cursor.ConversationStorage
+	logger  logging.Logger
+}
+
+// NewImporter creates an importer writing to the given database
+func NewImporter(db *sql.DB, logger logging.Logger) (Importer, error) {
+	return NewImporterWithGate(db, nil, logger)
+}
+
+// NewImporterWithGate creates an importer whose messages pass the sensitive
+// gate before they're stored. A nil gate imports messages as exported.
+func NewImporterWithGate(db *sql.DB, gate *sensitive.Gate, logger logging.Logger) (Importer, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database cannot be nil")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	storage, err := cursor.NewConversationStorageWithGate(db, gate, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create conversation storage: %w", err)
+	}
+
+	return &importer{
+		db:      db,
+		storage: storage,
+		logger:  logger.With("component", "importer"),
+	}, nil
+}
+
+// Import reads and stores the conversations in an export
+func (i *importer) Import(format, path string, opts Options) (*Result, error) {
+	if strings.TrimSpace(opts.Project) == "" {
+		return nil, fmt.Errorf("project cannot be empty")
+	}
+
+	data, err := readConversationsFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var conversations []*cursor.Conversation
+	switch format {
+	case FormatChatGPT:
+		conversations, err = parseChatGPT(data)
+	case FormatClaude:
+		conversations, err = parseClaude(data)
+	default:
+		return nil, fmt.Errorf("unsupported import format %q (supported: %s)", format, strings.Join(Formats(), ", "))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s export: %w", format, err)
+	}
+
+	result := &Result{}
+	for _, conversation := range conversations {
+		if len(conversation.Messages) == 0 {
+			result.Skipped++
+			continue
+		}
+		result.Conversations++
+		result.Messages += len(conversation.Messages)
+
+		if opts.DryRun {
+			continue
+		}
+		if err := i.storeConversation(conversation, opts.Project); err != nil {
+			return result, err
+		}
+	}
+
+	i.logger.Info("imported conversations",
+		"format", format,
+		"project", opts.Project,
+		"conversations", result.Conversations,
+		"messages", result.Messages,
+		"skipped", result.Skipped,
+		"dry_run", opts.DryRun)
+
+	return result, nil
+}
+
+// storeConversation writes an ended session spanning the conversation, then the conversation itself
+func (i *importer) storeConversation(conversation *cursor.Conversation, project string) error {
+	sessionID := fmt.Sprintf("%s-%s", importSessionPrefix, conversation.ComposerID)
+	start := conversation.Messages[0].CreatedAt
+	end := conversation.Messages[len(conversation.Messages)-1].CreatedAt
+	now := time.Now()
+
+	_, err := i.db.Exec(`
+		INSERT INTO sessions (id, project, start_time, end_time, last_activity, conversations_json, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			project = excluded.project,
+			start_time = excluded.start_time,
+			end_time = excluded.end_time,
+			last_activity = excluded.last_activity,
+			updated_at = excluded.updated_at
+	`, sessionID, project, start, end, end, nil, now, now)
+	if err != nil {
+		return fmt.Errorf("failed to save session %s: %w", sessionID, err)
+	}
+
+	if err := i.storage.StoreConversation(conversation, sessionID); err != nil {
+		return fmt.Errorf("failed to store conversation %s: %w", conversation.ComposerID, err)
+	}
+	return nil
+}
+
+// readConversationsFile returns conversations.json from a zip archive, a directory, or the file itself
+func readConversationsFile(path string) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to access export: %w", err)
+	}
+
+	if info.IsDir() {
+		data, err := os.ReadFile(filepath.Join(path, conversationsFileName))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s from export directory: %w", conversationsFileName, err)
+		}
+		return data, nil
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".zip") {
+		return readConversationsFromZip(path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read export: %w", err)
+	}
+	return data, nil
+}
+
+// readConversationsFromZip finds conversations.json in an export archive, preferring the shallowest match
+func readConversationsFromZip(path string) ([]byte, error) {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open export archive: %w", err)
+	}
+	defer archive.Close()
+
+	var candidates []*zip.File
+	for _, file := range archive.File {
+		if filepath.Base(file.Name) == conversationsFileName {
+			candidates = append(candidates, file)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("export archive does not contain %s", conversationsFileName)
+	}
+	sort.Slice(candidates, func(a, b int) bool {
+		return strings.Count(candidates[a].Name, "/") < strings.Count(candidates[b].Name, "/")
+	})
+
+	reader, err := candidates[0].Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s in export archive: %w", conversationsFileName, err)
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s in export archive: %w", conversationsFileName, err)
+	}
+	return data, nil
+}
+
+// newMessage builds a text message attributed to the user or the agent
+func newMessage(id, role, text string, createdAt time.Time) cursor.Message {
+	messageType := 1
+	messageRole := "user"
+	if role != "user" {
+		messageType = 2
+		messageRole = "agent"
+	}
+	return cursor.Message{
+		BubbleID:      id,
+		Type:          messageType,
+		Role:          messageRole,
+		Text:          text,
+		ContentSource: "text",
+		CreatedAt:     createdAt,
+		ParserVersion: cursor.ParserVersion,
+	}
+}
+
+// newConversation builds an imported conversation with IDs namespaced by format
+func newConversation(format, id, name string, createdAt time.Time, messages []cursor.Message) *cursor.Conversation {
+	if name == "" {
+		name = "Untitled"
+	}
+	if createdAt.IsZero() && len(messages) > 0 {
+		createdAt = messages[0].CreatedAt
+	}
+	return &cursor.Conversation{
+		ComposerID: fmt.Sprintf("%s-%s", format, id),
+		Name:       name,
+		Status:     "completed",
+		CreatedAt:  createdAt,
+		Messages:   messages,
+		Source:     format,
+	}
+}
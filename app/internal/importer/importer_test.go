@@ -0,0 +1,185 @@
+package importer
+
+import (
+	"archive/zip"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stwalsh4118/clio/internal/cursor"
+	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/logging"
+	_ "modernc.org/sqlite"
+)
+
+const chatGPTExport = `[{
+	"id": "c1",
+	"title": "Regex help",
+	"create_time": 1700000000.5,
+	"current_node": "n4",
+	"mapping": {
+		"n0": {"id": "n0", "parent": "", "message": null},
+		"n1": {"id": "n1", "parent": "n0", "message": {"id": "m1", "author": {"role": "system"}, "create_time": 1700000000, "content": {"content_type": "text", "parts": ["You are helpful"]}}},
+		"n2": {"id": "n2", "parent": "n1", "message": {"id": "m2", "author": {"role": "user"}, "create_time": 1700000001, "content": {"content_type": "text", "parts": ["How do I match digits?"]}}},
+		"n3": {"id": "n3", "parent": "n2", "message": {"id": "m3", "author": {"role": "assistant"}, "create_time": 1700000002, "content": {"content_type": "text", "parts": ["An abandoned answer"]}}},
+		"n4": {"id": "n4", "parent": "n2", "message": {"id": "m4", "author": {"role": "assistant"}, "create_time": 1700000003, "content": {"content_type": "text", "parts": ["Use \\d+", {"asset": "image"}]}}}
+	}
+}, {
+	"id": "c2",
+	"title": "Empty",
+	"create_time": 1700000100,
+	"current_node": "",
+	"mapping": {}
+}]`
+
+const claudeExport = `[{
+	"uuid": "k1",
+	"name": "Go generics",
+	"created_at": "2024-03-01T10:00:00Z",
+	"chat_messages": [
+		{"uuid": "u1", "sender": "human", "text": "Explain constraints", "created_at": "2024-03-01T10:00:00Z", "content": []},
+		{"uuid": "u2", "sender": "assistant", "text": "flat", "created_at": "2024-03-01T10:00:05Z", "content": [{"type": "text", "text": "Constraints are interfaces"}]}
+	]
+}]`
+
+func setupTestImportDB(t *testing.T) *sql.DB {
+	database, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "clio.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if err := db.RunMigrations(database); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+	return database
+}
+
+func TestParseChatGPT_FollowsCurrentBranch(t *testing.T) {
+	conversations, err := parseChatGPT([]byte(chatGPTExport))
+	if err != nil {
+		t.Fatalf("parseChatGPT() error = %v", err)
+	}
+	if len(conversations) != 2 {
+		t.Fatalf("got %d conversations, want 2", len(conversations))
+	}
+
+	conv := conversations[0]
+	if conv.ComposerID != "chatgpt-c1" || conv.Source != FormatChatGPT || conv.Name != "Regex help" {
+		t.Errorf("conversation = %q/%q/%q", conv.ComposerID, conv.Source, conv.Name)
+	}
+	if len(conv.Messages) != 2 {
+		t.Fatalf("got %d messages, want 2 (system and abandoned branch dropped)", len(conv.Messages))
+	}
+	if conv.Messages[0].Role != "user" || conv.Messages[1].Role != "agent" {
+		t.Errorf("roles = %q, %q", conv.Messages[0].Role, conv.Messages[1].Role)
+	}
+	if conv.Messages[1].Text != `Use \d+` || conv.Messages[1].BubbleID != "chatgpt-m4" {
+		t.Errorf("assistant message = %q (%s)", conv.Messages[1].Text, conv.Messages[1].BubbleID)
+	}
+	if len(conversations[1].Messages) != 0 {
+		t.Error("conversation without a current node should have no messages")
+	}
+}
+
+func TestParseClaude(t *testing.T) {
+	conversations, err := parseClaude([]byte(claudeExport))
+	if err != nil {
+		t.Fatalf("parseClaude() error = %v", err)
+	}
+	if len(conversations) != 1 || len(conversations[0].Messages) != 2 {
+		t.Fatalf("unexpected parse result: %+v", conversations)
+	}
+	messages := conversations[0].Messages
+	if messages[0].Role != "user" || messages[0].Text != "Explain constraints" {
+		t.Errorf("user message = %q/%q", messages[0].Role, messages[0].Text)
+	}
+	if messages[1].Text != "Constraints are interfaces" {
+		t.Errorf("assistant text = %q, want content blocks preferred", messages[1].Text)
+	}
+}
+
+func TestImport_ZipIsIdempotent(t *testing.T) {
+	database := setupTestImportDB(t)
+
+	archivePath := filepath.Join(t.TempDir(), "export.zip")
+	file, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatalf("failed to create archive: %v", err)
+	}
+	writer := zip.NewWriter(file)
+	entry, err := writer.Create("export/conversations.json")
+	if err != nil {
+		t.Fatalf("failed to add archive entry: %v", err)
+	}
+	if _, err := entry.Write([]byte(chatGPTExport)); err != nil {
+		t.Fatalf("failed to write archive entry: %v", err)
+	}
+	writer.Close()
+	file.Close()
+
+	imp, err := NewImporter(database, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewImporter() error = %v", err)
+	}
+
+	dryRun, err := imp.Import(FormatChatGPT, archivePath, Options{Project: "regexes", DryRun: true})
+	if err != nil {
+		t.Fatalf("dry run error = %v", err)
+	}
+	if dryRun.Conversations != 1 || dryRun.Messages != 2 || dryRun.Skipped != 1 {
+		t.Errorf("dry run result = %+v", dryRun)
+	}
+	var count int
+	database.QueryRow("SELECT COUNT(*) FROM conversations").Scan(&count)
+	if count != 0 {
+		t.Fatalf("dry run wrote %d conversations", count)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := imp.Import(FormatChatGPT, archivePath, Options{Project: "regexes"}); err != nil {
+			t.Fatalf("Import() error = %v", err)
+		}
+	}
+
+	var source, project string
+	if err := database.QueryRow(`
+		SELECT c.source, s.project FROM conversations c JOIN sessions s ON s.id = c.session_id
+	`).Scan(&source, &project); err != nil {
+		t.Fatalf("failed to query imported conversation: %v", err)
+	}
+	if source != FormatChatGPT || project != "regexes" {
+		t.Errorf("source/project = %q/%q", source, project)
+	}
+	database.QueryRow("SELECT COUNT(*) FROM messages").Scan(&count)
+	if count != 2 {
+		t.Errorf("messages after re-import = %d, want 2", count)
+	}
+
+	// Imported conversations have no Cursor payloads, so reparse must not see them as Cursor captures
+	database.QueryRow("SELECT COUNT(*) FROM conversations WHERE source = ?", cursor.SourceCursor).Scan(&count)
+	if count != 0 {
+		t.Errorf("imported conversations marked as cursor = %d", count)
+	}
+}
+
+func TestImport_RequiresProjectAndKnownFormat(t *testing.T) {
+	imp, err := NewImporter(setupTestImportDB(t), logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewImporter() error = %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "conversations.json")
+	os.WriteFile(path, []byte(claudeExport), 0644)
+
+	if _, err := imp.Import(FormatClaude, path, Options{}); err == nil {
+		t.Error("expected an error without a project")
+	}
+	if _, err := imp.Import("gemini", path, Options{Project: "p"}); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+	result, err := imp.Import(FormatClaude, filepath.Dir(path), Options{Project: "p"})
+	if err != nil || result.Conversations != 1 {
+		t.Errorf("directory import = %+v, %v", result, err)
+	}
+}
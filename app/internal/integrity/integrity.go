@@ -0,0 +1,467 @@
+// Package integrity verifies the clio database: rows referring to rows that
+// don't exist, JSON columns that don't parse, and timestamps that can't be
+// right. SQLite doesn't enforce clio's foreign keys, so a crash or an older
+// version can leave such rows behind, where they silently drop out of reports.
+package integrity
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/dedup"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+// Check identifies one kind of integrity problem
+type Check string
+
+const (
+	// CheckOrphanMessages finds messages whose conversation doesn't exist
+	CheckOrphanMessages Check = "orphan_messages"
+	// CheckOrphanConversations finds conversations whose session doesn't exist
+	CheckOrphanConversations Check = "orphan_conversations"
+	// CheckCommitSessions finds commits correlated with a session that doesn't exist
+	CheckCommitSessions Check = "commit_sessions"
+	// CheckJSON finds JSON columns that don't parse
+	CheckJSON Check = "json"
+	// CheckTimestamps finds timestamps that don't parse or can't be right
+	CheckTimestamps Check = "timestamps"
+)
+
+// Checks lists every check in the order they run
+var Checks = []Check{CheckOrphanMessages, CheckOrphanConversations, CheckCommitSessions, CheckJSON, CheckTimestamps}
+
+// Title describes the check for people
+func (c Check) Title() string {
+	switch c {
+	case CheckOrphanMessages:
+		return "Messages with a missing conversation"
+	case CheckOrphanConversations:
+		return "Conversations with a missing session"
+	case CheckCommitSessions:
+		return "Commits referencing a missing session"
+	case CheckJSON:
+		return "JSON columns"
+	case CheckTimestamps:
+		return "Timestamps"
+	default:
+		return string(c)
+	}
+}
+
+const (
+	// earliestTimestamp is the earliest plausible timestamp; anything before it,
+	// including the zero time, was stored by mistake
+	earliestTimestamp = "2000-01-01T00:00:00Z"
+	// futureTolerance is how far past now a timestamp may lie, for clock skew
+	// between machines
+	futureTolerance = 24 * time.Hour
+)
+
+// Issue is one problem found in one row
+type Issue struct {
+	Check  Check
+	Table  string
+	RowID  string
+	Detail string
+
+	// repair fixes the row, or is nil when it needs attention by hand
+	repair func(tx *sql.Tx) error
+}
+
+// Repairable reports whether Repair can fix the issue
+func (i Issue) Repairable() bool {
+	return i.repair != nil
+}
+
+// Report lists the issues found by Verify, in check order
+type Report struct {
+	Issues []Issue
+}
+
+// ByCheck returns the issues found by one check
+func (r *Report) ByCheck(check Check) []Issue {
+	var issues []Issue
+	for _, issue := range r.Issues {
+		if issue.Check == check {
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+// Repairable returns the number of issues Repair can fix
+func (r *Report) Repairable() int {
+	count := 0
+	for _, issue := range r.Issues {
+		if issue.Repairable() {
+			count++
+		}
+	}
+	return count
+}
+
+// Verifier defines the interface for checking and repairing the database
+type Verifier interface {
+	// Verify runs every check, judging timestamps against now
+	Verify(now time.Time) (*Report, error)
+	// Repair fixes the repairable issues of a report in one transaction and
+	// returns how many it fixed
+	Repair(report *Report) (int, error)
+}
+
+// verifier implements Verifier on top of the clio database
+type verifier struct {
+	db     *sql.DB
+	logger logging.Logger
+}
+
+// NewVerifier creates a verifier for the database
+func NewVerifier(db *sql.DB, logger logging.Logger) (Verifier, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database cannot be nil")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	return &verifier{
+		db:     db,
+		logger: logger.With("component", "integrity"),
+	}, nil
+}
+
+// Verify runs every check
+func (v *verifier) Verify(now time.Time) (*Report, error) {
+	report := &Report{}
+	checks := []func(time.Time) ([]Issue, error){
+		v.orphanMessages,
+		v.orphanConversations,
+		v.commitSessions,
+		v.invalidJSON,
+		v.timestamps,
+	}
+	for _, check := range checks {
+		issues, err := check(now)
+		if err != nil {
+			return nil, err
+		}
+		report.Issues = append(report.Issues, issues...)
+	}
+
+	v.logger.Debug("verified database", "issues", len(report.Issues), "repairable", report.Repairable())
+	return report, nil
+}
+
+// Repair fixes the repairable issues of a report
+func (v *verifier) Repair(report *Report) (int, error) {
+	tx, err := v.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	repaired := 0
+	for _, issue := range report.Issues {
+		if issue.repair == nil {
+			continue
+		}
+		if err := issue.repair(tx); err != nil {
+			return 0, fmt.Errorf("failed to repair %s %s: %w", issue.Table, issue.RowID, err)
+		}
+		repaired++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit repairs: %w", err)
+	}
+	v.logger.Info("repaired database", "repaired", repaired, "remaining", len(report.Issues)-repaired)
+	return repaired, nil
+}
+
+// orphanMessages finds messages whose conversation doesn't exist. Nothing can
+// show them, so repair deletes them with their revisions, releasing the shared
+// content their code blocks refer to.
+func (v *verifier) orphanMessages(time.Time) ([]Issue, error) {
+	rows, err := v.db.Query(`
+		SELECT m.id, m.conversation_id FROM messages m
+		WHERE NOT EXISTS (SELECT 1 FROM conversations c WHERE c.id = m.conversation_id)
+		ORDER BY m.conversation_id, m.id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query orphaned messages: %w", err)
+	}
+	defer rows.Close()
+
+	var issues []Issue
+	for rows.Next() {
+		var id, conversationID string
+		if err := rows.Scan(&id, &conversationID); err != nil {
+			return nil, fmt.Errorf("failed to scan orphaned message: %w", err)
+		}
+		issues = append(issues, Issue{
+			Check:  CheckOrphanMessages,
+			Table:  "messages",
+			RowID:  id,
+			Detail: fmt.Sprintf("conversation %s does not exist", conversationID),
+			repair: func(tx *sql.Tx) error { return deleteMessage(tx, id) },
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating orphaned messages: %w", err)
+	}
+	return issues, nil
+}
+
+// deleteMessage deletes a message and its revisions
+func deleteMessage(tx *sql.Tx, id string) error {
+	var blocks sql.NullString
+	if err := tx.QueryRow("SELECT code_blocks FROM messages WHERE id = ?", id).Scan(&blocks); err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to query code blocks: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM messages WHERE id = ?", id); err != nil {
+		return fmt.Errorf("failed to delete message: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM message_revisions WHERE message_id = ?", id); err != nil {
+		return fmt.Errorf("failed to delete message revisions: %w", err)
+	}
+	for _, ref := range dedup.CodeBlockRefs(blocks.String) {
+		if err := dedup.Release(tx, ref); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// orphanConversations finds conversations whose session doesn't exist. They
+// hold captured messages, so they aren't deleted; the session manager
+// reconstructs their session the next time it loads sessions.
+func (v *verifier) orphanConversations(time.Time) ([]Issue, error) {
+	rows, err := v.db.Query(`
+		SELECT c.id, c.session_id, c.message_count FROM conversations c
+		WHERE NOT EXISTS (SELECT 1 FROM sessions s WHERE s.id = c.session_id)
+		ORDER BY c.session_id, c.id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query orphaned conversations: %w", err)
+	}
+	defer rows.Close()
+
+	var issues []Issue
+	for rows.Next() {
+		var id, sessionID string
+		var messages int
+		if err := rows.Scan(&id, &sessionID, &messages); err != nil {
+			return nil, fmt.Errorf("failed to scan orphaned conversation: %w", err)
+		}
+		issues = append(issues, Issue{
+			Check:  CheckOrphanConversations,
+			Table:  "conversations",
+			RowID:  id,
+			Detail: fmt.Sprintf("session %s does not exist (%d messages); the daemon reconstructs it at startup", sessionID, messages),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating orphaned conversations: %w", err)
+	}
+	return issues, nil
+}
+
+// commitSessions finds commits correlated with a session that doesn't exist;
+// repair uncorrelates them, as deleting the session would have
+func (v *verifier) commitSessions(time.Time) ([]Issue, error) {
+	rows, err := v.db.Query(`
+		SELECT c.id, c.hash, c.session_id FROM commits c
+		WHERE c.session_id IS NOT NULL
+		  AND NOT EXISTS (SELECT 1 FROM sessions s WHERE s.id = c.session_id)
+		ORDER BY c.hash
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query commit sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var issues []Issue
+	for rows.Next() {
+		var id, hash, sessionID string
+		if err := rows.Scan(&id, &hash, &sessionID); err != nil {
+			return nil, fmt.Errorf("failed to scan commit: %w", err)
+		}
+		issues = append(issues, Issue{
+			Check:  CheckCommitSessions,
+			Table:  "commits",
+			RowID:  hash,
+			Detail: fmt.Sprintf("session %s does not exist", sessionID),
+			repair: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`
+					UPDATE commits SET session_id = NULL, correlation_type = NULL, correlation_confidence = NULL
+					WHERE id = ?
+				`, id)
+				return err
+			},
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating commits: %w", err)
+	}
+	return issues, nil
+}
+
+// jsonColumn is a column holding JSON, and the statement clearing it on repair
+type jsonColumn struct {
+	table  string
+	column string
+	clear  string
+}
+
+// jsonColumns lists the JSON columns checked. Readers treat a missing value as
+// empty, so repair clears values that don't parse, with the flags derived from them.
+var jsonColumns = []jsonColumn{
+	{"sessions", "conversations_json", "UPDATE sessions SET conversations_json = NULL WHERE id = ?"},
+	{"messages", "code_blocks", "UPDATE messages SET code_blocks = NULL, has_code = 0 WHERE id = ?"},
+	{"messages", "tool_calls", "UPDATE messages SET tool_calls = NULL, has_tool_calls = 0 WHERE id = ?"},
+	{"messages", "metadata", "UPDATE messages SET metadata = NULL WHERE id = ?"},
+}
+
+// invalidJSON finds JSON columns whose values don't parse
+func (v *verifier) invalidJSON(time.Time) ([]Issue, error) {
+	var issues []Issue
+	for _, column := range jsonColumns {
+		rows, err := v.db.Query(fmt.Sprintf("SELECT id, %s FROM %s WHERE %s IS NOT NULL AND %s != '' ORDER BY id",
+			column.column, column.table, column.column, column.column))
+		if err != nil {
+			return nil, fmt.Errorf("failed to query %s.%s: %w", column.table, column.column, err)
+		}
+		for rows.Next() {
+			var id, value string
+			if err := rows.Scan(&id, &value); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan %s.%s: %w", column.table, column.column, err)
+			}
+			if json.Valid([]byte(value)) {
+				continue
+			}
+			issues = append(issues, Issue{
+				Check:  CheckJSON,
+				Table:  column.table,
+				RowID:  id,
+				Detail: fmt.Sprintf("%s is not valid JSON", column.column),
+				repair: func(tx *sql.Tx) error {
+					_, err := tx.Exec(column.clear, id)
+					return err
+				},
+			})
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error iterating %s.%s: %w", column.table, column.column, err)
+		}
+	}
+	return issues, nil
+}
+
+// timestampTable lists a table's timestamp columns, and the pair that must be
+// in order when both are set
+type timestampTable struct {
+	table   string
+	key     string
+	columns []string
+	first   string
+	last    string
+}
+
+// timestampTables lists the timestamp columns checked
+var timestampTables = []timestampTable{
+	{table: "sessions", key: "id", columns: []string{"start_time", "end_time", "last_activity"}, first: "start_time", last: "end_time"},
+	{table: "conversations", key: "id", columns: []string{"first_message_time", "last_message_time", "created_at"}, first: "first_message_time", last: "last_message_time"},
+	{table: "messages", key: "id", columns: []string{"created_at"}},
+	{table: "commits", key: "hash", columns: []string{"timestamp"}},
+}
+
+// timestamps finds timestamps that don't parse, lie before 2000 or more than a
+// day after now, and sessions or conversations ending before they start. Only a
+// session ending before it starts is repaired, by ending it at its last activity
+// or, when that is earlier still, its start; the other values can't be recovered.
+func (v *verifier) timestamps(now time.Time) ([]Issue, error) {
+	earliest, _ := time.Parse(time.RFC3339, earliestTimestamp)
+	latest := now.Add(futureTolerance)
+
+	var issues []Issue
+	for _, table := range timestampTables {
+		query := fmt.Sprintf("SELECT %s", table.key)
+		for _, column := range table.columns {
+			query += ", " + column
+		}
+		rows, err := v.db.Query(fmt.Sprintf("%s FROM %s ORDER BY %s", query, table.table, table.key))
+		if err != nil {
+			return nil, fmt.Errorf("failed to query %s timestamps: %w", table.table, err)
+		}
+
+		for rows.Next() {
+			var id string
+			values := make([]interface{}, len(table.columns))
+			dest := []interface{}{&id}
+			for i := range values {
+				dest = append(dest, &values[i])
+			}
+			if err := rows.Scan(dest...); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan %s timestamps: %w", table.table, err)
+			}
+
+			parsed := make(map[string]time.Time)
+			for i, column := range table.columns {
+				issue := Issue{Check: CheckTimestamps, Table: table.table, RowID: id}
+				switch value := values[i].(type) {
+				case nil:
+					continue
+				case time.Time:
+					switch {
+					case value.Before(earliest):
+						issue.Detail = fmt.Sprintf("%s is implausibly early (%s)", column, value.Format(time.RFC3339))
+					case value.After(latest):
+						issue.Detail = fmt.Sprintf("%s is in the future (%s)", column, value.Format(time.RFC3339))
+					default:
+						parsed[column] = value
+						continue
+					}
+				default:
+					issue.Detail = fmt.Sprintf("%s is not a timestamp (%v)", column, value)
+				}
+				issues = append(issues, issue)
+			}
+
+			first, hasFirst := parsed[table.first]
+			last, hasLast := parsed[table.last]
+			if table.first == "" || !hasFirst || !hasLast || !last.Before(first) {
+				continue
+			}
+			issue := Issue{
+				Check:  CheckTimestamps,
+				Table:  table.table,
+				RowID:  id,
+				Detail: fmt.Sprintf("%s (%s) is before %s (%s)", table.last, last.Format(time.RFC3339), table.first, first.Format(time.RFC3339)),
+			}
+			if table.table == "sessions" {
+				end := first
+				if activity, ok := parsed["last_activity"]; ok && activity.After(first) {
+					end = activity
+				}
+				issue.repair = func(tx *sql.Tx) error {
+					_, err := tx.Exec("UPDATE sessions SET end_time = ? WHERE id = ?", end, id)
+					return err
+				}
+			}
+			issues = append(issues, issue)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error iterating %s timestamps: %w", table.table, err)
+		}
+	}
+	return issues, nil
+}
@@ -0,0 +1,168 @@
+package integrity
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/logging"
+	_ "modernc.org/sqlite"
+)
+
+func setupTestVerifier(t *testing.T) (*sql.DB, Verifier) {
+	database, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "clio.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if err := db.RunMigrations(database); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	verifier, err := NewVerifier(database, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewVerifier() error = %v", err)
+	}
+	return database, verifier
+}
+
+func mustExec(t *testing.T, database *sql.DB, query string, args ...interface{}) {
+	t.Helper()
+	if _, err := database.Exec(query, args...); err != nil {
+		t.Fatalf("failed to exec %q: %v", query, err)
+	}
+}
+
+func TestVerifier_VerifyAndRepair(t *testing.T) {
+	database, verifier := setupTestVerifier(t)
+	now := time.Date(2024, 5, 1, 9, 0, 0, 0, time.Local)
+
+	insertSession := `
+		INSERT INTO sessions (id, project, start_time, end_time, last_activity, created_at, updated_at)
+		VALUES (?, 'clio', ?, ?, ?, ?, ?)
+	`
+	mustExec(t, database, insertSession, "s1", now, now.Add(time.Hour), now.Add(time.Hour), now, now)
+	mustExec(t, database, insertSession, "s2", now, now.Add(-time.Hour), now.Add(30*time.Minute), now, now)
+
+	insertConversation := `
+		INSERT INTO conversations (id, session_id, composer_id, message_count, created_at, updated_at)
+		VALUES (?, ?, ?, 1, ?, ?)
+	`
+	mustExec(t, database, insertConversation, "c1", "s1", "c1", now, now)
+	mustExec(t, database, insertConversation, "c2", "gone", "c2", now, now)
+
+	insertMessage := `
+		INSERT INTO messages (id, conversation_id, bubble_id, type, role, content, created_at, code_blocks, tool_calls, has_tool_calls)
+		VALUES (?, ?, ?, 2, 'agent', 'hi', ?, ?, ?, 1)
+	`
+	mustExec(t, database, insertMessage, "m1", "c1", "m1", now, nil, `[{"name":`)
+	mustExec(t, database, insertMessage, "m2", "missing", "m2", now, `[{"contentRef":"ref1"}]`, nil)
+	mustExec(t, database, insertMessage, "m3", "c1", "m3", now.Add(72*time.Hour), nil, nil)
+	mustExec(t, database, `INSERT INTO message_revisions (message_id, conversation_id, revision, content, created_at, replaced_at) VALUES ('m2', 'missing', 1, 'old', ?, ?)`, now, now)
+	mustExec(t, database, `INSERT INTO shared_contents (hash, content, ref_count, created_at) VALUES ('ref1', 'shared', 1, ?)`, now)
+
+	mustExec(t, database, `
+		INSERT INTO commits (id, session_id, repository_path, repository_name, hash, message, author_name, author_email,
+			timestamp, branch, correlation_type, correlation_confidence, created_at, updated_at)
+		VALUES ('k1', 'gone', '/repo', 'repo', 'abc123', 'fix', 'A', 'a@b.c', ?, 'main', 'active', 0.9, ?, ?)
+	`, now, now, now)
+
+	report, err := verifier.Verify(now)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+
+	want := map[Check][]string{
+		CheckOrphanMessages:      {"m2"},
+		CheckOrphanConversations: {"c2"},
+		CheckCommitSessions:      {"abc123"},
+		CheckJSON:                {"m1"},
+		CheckTimestamps:          {"s2", "m3"},
+	}
+	for _, check := range Checks {
+		issues := report.ByCheck(check)
+		if len(issues) != len(want[check]) {
+			t.Errorf("%s issues = %+v, want rows %v", check, issues, want[check])
+			continue
+		}
+		for i, issue := range issues {
+			if issue.RowID != want[check][i] {
+				t.Errorf("%s issue %d = %s, want %s", check, i, issue.RowID, want[check][i])
+			}
+		}
+	}
+	// The orphaned conversation and the future message need attention by hand
+	if got := report.Repairable(); got != 4 {
+		t.Errorf("Repairable() = %d, want 4", got)
+	}
+
+	repaired, err := verifier.Repair(report)
+	if err != nil {
+		t.Fatalf("Repair() error = %v", err)
+	}
+	if repaired != 4 {
+		t.Errorf("Repair() = %d, want 4", repaired)
+	}
+
+	var count int
+	database.QueryRow("SELECT COUNT(*) FROM messages WHERE id = 'm2'").Scan(&count)
+	if count != 0 {
+		t.Error("orphaned message was not deleted")
+	}
+	database.QueryRow("SELECT COUNT(*) FROM message_revisions WHERE message_id = 'm2'").Scan(&count)
+	if count != 0 {
+		t.Error("orphaned message revisions were not deleted")
+	}
+	database.QueryRow("SELECT COUNT(*) FROM shared_contents WHERE hash = 'ref1'").Scan(&count)
+	if count != 0 {
+		t.Error("shared content of the orphaned message was not released")
+	}
+
+	var sessionID, toolCalls sql.NullString
+	var hasToolCalls int
+	database.QueryRow("SELECT session_id FROM commits WHERE id = 'k1'").Scan(&sessionID)
+	if sessionID.Valid {
+		t.Errorf("commit session = %q, want NULL", sessionID.String)
+	}
+	database.QueryRow("SELECT tool_calls, has_tool_calls FROM messages WHERE id = 'm1'").Scan(&toolCalls, &hasToolCalls)
+	if toolCalls.Valid || hasToolCalls != 0 {
+		t.Errorf("tool_calls = %v, has_tool_calls = %d; want cleared", toolCalls, hasToolCalls)
+	}
+	var endTime time.Time
+	database.QueryRow("SELECT end_time FROM sessions WHERE id = 's2'").Scan(&endTime)
+	if !endTime.Equal(now.Add(30 * time.Minute)) {
+		t.Errorf("s2 end_time = %v, want its last activity", endTime)
+	}
+
+	report, err = verifier.Verify(now)
+	if err != nil {
+		t.Fatalf("Verify() after repair error = %v", err)
+	}
+	if len(report.Issues) != 2 || report.Repairable() != 0 {
+		t.Errorf("issues after repair = %+v, want the two needing attention by hand", report.Issues)
+	}
+}
+
+func TestVerifier_UnparseableTimestamp(t *testing.T) {
+	database, verifier := setupTestVerifier(t)
+	now := time.Date(2024, 5, 1, 9, 0, 0, 0, time.Local)
+
+	mustExec(t, database, `
+		INSERT INTO sessions (id, project, start_time, last_activity, created_at, updated_at)
+		VALUES ('s1', 'clio', 'yesterday', ?, ?, ?)
+	`, time.Time{}, now, now)
+
+	report, err := verifier.Verify(now)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	issues := report.ByCheck(CheckTimestamps)
+	if len(issues) != 2 {
+		t.Fatalf("timestamp issues = %+v, want start_time and last_activity", issues)
+	}
+	if issues[0].Detail != "start_time is not a timestamp (yesterday)" {
+		t.Errorf("issue = %q", issues[0].Detail)
+	}
+}
@@ -0,0 +1,256 @@
+// Package issues drafts GitHub and GitLab issues from captured conversations
+// where a bug or TODO was discussed, and opens them through the trackers'
+// REST APIs. A draft leads with the request that started the conversation,
+// then quotes the lines that mention the problem and the code blocks around
+// them, and ends with where it came from.
+package issues
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/stwalsh4118/clio/pkg/export"
+)
+
+const (
+	// DefaultExcerpts is how many lines mentioning the problem a draft quotes
+	DefaultExcerpts = 5
+	// DefaultCodeBlocks is how many code blocks a draft includes
+	DefaultCodeBlocks = 3
+
+	// titleLength caps a title derived from the conversation, in runes
+	titleLength = 80
+	// contextLength caps the request quoted at the top of the body, in runes
+	contextLength = 1500
+	// excerptLength caps a quoted line, in runes
+	excerptLength = 300
+	// codeBlockLines caps the lines kept from one code block
+	codeBlockLines = 40
+	// codeFence opens and closes Markdown code blocks
+	codeFence = "```"
+)
+
+// problemPattern matches lines that talk about a bug or unfinished work
+var problemPattern = regexp.MustCompile(`(?i)\b(bugs?|errors?|fail(s|ed|ing|ure)?|panic(s|ked)?|crash(es|ed|ing)?|broken|exceptions?|traceback|regression|todo|fixme|doesn't work|not working)\b`)
+
+// Draft is an issue ready to be created
+type Draft struct {
+	Title  string
+	Body   string // Markdown
+	Labels []string
+}
+
+// Options control how a conversation is drafted into an issue
+type Options struct {
+	Title      string   // Overrides the title derived from the conversation
+	Labels     []string // Applied as given
+	Excerpts   int      // Lines mentioning the problem to quote; 0 uses DefaultExcerpts
+	CodeBlocks int      // Code blocks to include; 0 uses DefaultCodeBlocks
+}
+
+// codeBlock is a fenced code block found in a message
+type codeBlock struct {
+	language string
+	lines    []string
+}
+
+// BuildDraft drafts an issue from a conversation. Lines mentioning a bug or
+// TODO are quoted, and code blocks from the messages containing them are
+// preferred over the rest.
+func BuildDraft(conversation export.Conversation, opts Options) Draft {
+	if opts.Excerpts <= 0 {
+		opts.Excerpts = DefaultExcerpts
+	}
+	if opts.CodeBlocks <= 0 {
+		opts.CodeBlocks = DefaultCodeBlocks
+	}
+
+	request := -1
+	for i, message := range conversation.Messages {
+		if message.Role == "user" && strings.TrimSpace(message.Text) != "" {
+			request = i
+			break
+		}
+	}
+
+	title := strings.TrimSpace(opts.Title)
+	if title == "" {
+		title = strings.TrimSpace(conversation.Name)
+	}
+	if title == "" && request >= 0 {
+		title = firstLine(stripCode(conversation.Messages[request].Text))
+	}
+	if title == "" {
+		title = "Issue from conversation " + shortID(conversation.ComposerID)
+	}
+
+	var b strings.Builder
+	if request >= 0 {
+		text := truncate(strings.TrimSpace(conversation.Messages[request].Text), contextLength)
+		// A cut inside a code block would leave it open
+		if strings.Count(text, codeFence)%2 == 1 {
+			text += "\n" + codeFence
+		}
+		b.WriteString(quote(text))
+		b.WriteString("\n")
+	}
+
+	var excerpts []string
+	var preferred, other []codeBlock
+	for i, message := range conversation.Messages {
+		mentions := false
+		for _, line := range strings.Split(stripCode(message.Text), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || !problemPattern.MatchString(line) {
+				continue
+			}
+			mentions = true
+			// The request is already quoted in full
+			if i != request && len(excerpts) < opts.Excerpts {
+				excerpts = append(excerpts, fmt.Sprintf("> **%s**, %s: %s", message.Role, message.CreatedAt.Format("Jan 2 15:04"), truncate(line, excerptLength)))
+			}
+		}
+		if i == request {
+			continue
+		}
+		if mentions {
+			preferred = append(preferred, codeBlocks(message.Text)...)
+		} else {
+			other = append(other, codeBlocks(message.Text)...)
+		}
+	}
+
+	if len(excerpts) > 0 {
+		b.WriteString("\n## Excerpts\n\n")
+		b.WriteString(strings.Join(excerpts, "\n>\n"))
+		b.WriteString("\n")
+	}
+
+	blocks := uniqueBlocks(append(preferred, other...), opts.CodeBlocks)
+	if len(blocks) > 0 {
+		b.WriteString("\n## Code\n")
+		for _, block := range blocks {
+			b.WriteString("\n" + codeFence + block.language + "\n")
+			lines := block.lines
+			if len(lines) > codeBlockLines {
+				lines = lines[:codeBlockLines]
+			}
+			for _, line := range lines {
+				b.WriteString(line + "\n")
+			}
+			if omitted := len(block.lines) - len(lines); omitted > 0 {
+				fmt.Fprintf(&b, "… (%d more lines)\n", omitted)
+			}
+			b.WriteString(codeFence + "\n")
+		}
+	}
+
+	source := "conversation " + shortID(conversation.ComposerID)
+	if conversation.Name != "" {
+		source = fmt.Sprintf("conversation %q (%s)", conversation.Name, shortID(conversation.ComposerID))
+	}
+	if len(conversation.Messages) > 0 {
+		first := conversation.Messages[0].CreatedAt
+		source += fmt.Sprintf(", %d message(s) from %s", len(conversation.Messages), first.Format("Jan 2, 2006"))
+	}
+	fmt.Fprintf(&b, "\n---\n\n_Drafted by clio from %s._\n", source)
+
+	return Draft{
+		Title:  truncate(title, titleLength),
+		Body:   strings.TrimLeft(b.String(), "\n"),
+		Labels: opts.Labels,
+	}
+}
+
+// codeBlocks returns the fenced code blocks in a message's text
+func codeBlocks(text string) []codeBlock {
+	var blocks []codeBlock
+	var current *codeBlock
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, codeFence) {
+			if current != nil {
+				current.lines = append(current.lines, line)
+			}
+			continue
+		}
+		if current == nil {
+			current = &codeBlock{language: strings.TrimSpace(strings.TrimPrefix(trimmed, codeFence))}
+			continue
+		}
+		if len(current.lines) > 0 {
+			blocks = append(blocks, *current)
+		}
+		current = nil
+	}
+	return blocks
+}
+
+// uniqueBlocks returns up to limit blocks, leaving out repeats
+func uniqueBlocks(blocks []codeBlock, limit int) []codeBlock {
+	seen := make(map[string]bool)
+	var unique []codeBlock
+	for _, block := range blocks {
+		key := strings.Join(block.lines, "\n")
+		if seen[key] || len(unique) >= limit {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, block)
+	}
+	return unique
+}
+
+// stripCode removes fenced code blocks, leaving the prose around them
+func stripCode(text string) string {
+	var b strings.Builder
+	inCode := false
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), codeFence) {
+			inCode = !inCode
+			continue
+		}
+		if !inCode {
+			b.WriteString(line + "\n")
+		}
+	}
+	return b.String()
+}
+
+// quote renders text as a Markdown blockquote
+func quote(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight("> "+line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// firstLine returns the first non-blank line of text, trimmed
+func firstLine(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// truncate cuts text to at most limit runes, marking the cut with an ellipsis
+func truncate(text string, limit int) string {
+	if utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+	runes := []rune(text)
+	return strings.TrimSpace(string(runes[:limit-1])) + "…"
+}
+
+// shortID shortens a composer ID for display
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
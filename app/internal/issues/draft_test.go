@@ -0,0 +1,59 @@
+package issues
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/pkg/export"
+)
+
+func TestBuildDraft(t *testing.T) {
+	at := func(minute int) time.Time { return time.Date(2024, 5, 2, 14, minute, 0, 0, time.Local) }
+	conversation := export.Conversation{
+		ComposerID: "3f2a9c41-aaaa-bbbb",
+		Name:       "Lexer crash",
+		Messages: []export.Message{
+			{Role: "user", Text: "The lexer panics on empty input:\n\n```\npanic: index out of range\n```", CreatedAt: at(0)},
+			{Role: "agent", Text: "Here's the tokenizer loop:\n\n```go\nfor i := 0; i <= len(s); i++ {\n}\n```\nNothing else stands out.", CreatedAt: at(1)},
+			{Role: "agent", Text: "The bug is an off-by-one in the loop bound.\n\n```go\nfor i := 0; i < len(s); i++ {\n}\n```", CreatedAt: at(2)},
+			{Role: "user", Text: "Great. TODO: add a regression test later", CreatedAt: at(3)},
+		},
+	}
+
+	draft := BuildDraft(conversation, Options{Labels: []string{"bug"}, CodeBlocks: 1})
+	if draft.Title != "Lexer crash" || len(draft.Labels) != 1 || draft.Labels[0] != "bug" {
+		t.Errorf("BuildDraft() = title %q, labels %v", draft.Title, draft.Labels)
+	}
+
+	for _, want := range []string{
+		"> The lexer panics on empty input:\n>\n> ```\n> panic: index out of range\n> ```\n",
+		"## Excerpts\n\n> **agent**, May 2 14:02: The bug is an off-by-one in the loop bound.\n>\n> **user**, May 2 14:03: Great. TODO: add a regression test later\n",
+		// The block from the message mentioning the bug is preferred, and the request's isn't repeated
+		"## Code\n\n```go\nfor i := 0; i < len(s); i++ {\n}\n```\n\n---",
+		`_Drafted by clio from conversation "Lexer crash" (3f2a9c41), 4 message(s) from May 2, 2024._`,
+	} {
+		if !strings.Contains(draft.Body, want) {
+			t.Errorf("Body missing %q:\n%s", want, draft.Body)
+		}
+	}
+	if strings.Contains(draft.Body, "i <= len(s)") {
+		t.Errorf("Body includes more code blocks than requested:\n%s", draft.Body)
+	}
+
+	// Without a name, the title comes from the request's first line of prose
+	conversation.Name = ""
+	if draft := BuildDraft(conversation, Options{}); draft.Title != "The lexer panics on empty input:" {
+		t.Errorf("BuildDraft() title = %q", draft.Title)
+	}
+	if draft := BuildDraft(conversation, Options{Title: "Fix lexer"}); draft.Title != "Fix lexer" {
+		t.Errorf("BuildDraft() with a title = %q", draft.Title)
+	}
+}
+
+func TestCodeBlocks(t *testing.T) {
+	blocks := codeBlocks("intro\n```sh\ngo test ./...\n```\n```\n```\ntext\n```python\nprint(1)")
+	if len(blocks) != 1 || blocks[0].language != "sh" || strings.Join(blocks[0].lines, "\n") != "go test ./..." {
+		t.Errorf("codeBlocks() = %+v, want the closed sh block", blocks)
+	}
+}
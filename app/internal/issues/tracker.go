@@ -0,0 +1,191 @@
+package issues
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/secrets"
+)
+
+const (
+	// ProviderGitHub creates issues on GitHub or GitHub Enterprise
+	ProviderGitHub = "github"
+	// ProviderGitLab creates issues on GitLab.com or a self-managed GitLab
+	ProviderGitLab = "gitlab"
+
+	// defaultGitHubAPI is used when issues.github.api_url isn't set
+	defaultGitHubAPI = "https://api.github.com"
+	// defaultGitLabAPI is used when issues.gitlab.api_url isn't set
+	defaultGitLabAPI = "https://gitlab.com/api/v4"
+	// requestTimeout bounds a single API request
+	requestTimeout = 30 * time.Second
+	// maxErrorBody caps how much of an error response is read
+	maxErrorBody = 4096
+)
+
+// Issue is an issue a tracker created
+type Issue struct {
+	Number int    // The issue number within the repository
+	URL    string // The issue's web page
+}
+
+// Tracker creates issues through one issue tracker's API
+type Tracker interface {
+	// Create opens an issue in a repository, named owner/name (GitLab also
+	// accepts nested groups, e.g. group/subgroup/name)
+	Create(ctx context.Context, repo string, draft Draft) (*Issue, error)
+}
+
+// tracker implements Tracker for GitHub and GitLab
+type tracker struct {
+	provider string
+	apiURL   string
+	token    string
+	client   *http.Client
+}
+
+// ValidateRepo checks that repo names a repository as owner/name; GitLab
+// repositories may be nested in subgroups
+func ValidateRepo(provider, repo string) error {
+	parts := strings.Split(repo, "/")
+	if len(parts) < 2 || provider == ProviderGitHub && len(parts) != 2 {
+		return fmt.Errorf("repository %q must be owner/name", repo)
+	}
+	for _, part := range parts {
+		if part == "" || strings.TrimSpace(part) != part {
+			return fmt.Errorf("repository %q must be owner/name", repo)
+		}
+	}
+	return nil
+}
+
+// NewTracker creates a tracker for the provider from the issues
+// configuration, resolving the secret reference in its token
+func NewTracker(cfg config.IssuesConfig, provider string) (Tracker, error) {
+	var trackerCfg config.IssueTrackerConfig
+	var apiURL string
+	switch provider {
+	case ProviderGitHub:
+		trackerCfg, apiURL = cfg.GitHub, defaultGitHubAPI
+	case ProviderGitLab:
+		trackerCfg, apiURL = cfg.GitLab, defaultGitLabAPI
+	default:
+		return nil, fmt.Errorf("unknown issue tracker %q (valid: %s, %s)", provider, ProviderGitHub, ProviderGitLab)
+	}
+	if trackerCfg.Token == "" {
+		return nil, fmt.Errorf("issues.%s.token must be set to create issues", provider)
+	}
+
+	token, err := secrets.Resolve(trackerCfg.Token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve issues.%s.token: %w", provider, err)
+	}
+	if trackerCfg.APIURL != "" {
+		apiURL = trackerCfg.APIURL
+	}
+
+	return &tracker{
+		provider: provider,
+		apiURL:   strings.TrimRight(apiURL, "/"),
+		token:    token,
+		client:   &http.Client{Timeout: requestTimeout},
+	}, nil
+}
+
+// Create opens an issue with a POST to the tracker's issues endpoint
+func (t *tracker) Create(ctx context.Context, repo string, draft Draft) (*Issue, error) {
+	if err := ValidateRepo(t.provider, repo); err != nil {
+		return nil, err
+	}
+
+	var endpoint string
+	var payload map[string]any
+	if t.provider == ProviderGitHub {
+		endpoint = t.apiURL + "/repos/" + repo + "/issues"
+		payload = map[string]any{"title": draft.Title, "body": draft.Body}
+		if len(draft.Labels) > 0 {
+			payload["labels"] = draft.Labels
+		}
+	} else {
+		endpoint = t.apiURL + "/projects/" + url.PathEscape(repo) + "/issues"
+		payload = map[string]any{"title": draft.Title, "description": draft.Body}
+		if len(draft.Labels) > 0 {
+			payload["labels"] = strings.Join(draft.Labels, ",")
+		}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode issue: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if t.provider == ProviderGitHub {
+		req.Header.Set("Accept", "application/vnd.github+json")
+		req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+		req.Header.Set("Authorization", "Bearer "+t.token)
+	} else {
+		req.Header.Set("PRIVATE-TOKEN", t.token)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create issue: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return nil, fmt.Errorf("failed to create issue in %s: %s%s", repo, resp.Status, errorMessage(detail))
+	}
+
+	var created struct {
+		Number  int    `json:"number"`   // GitHub
+		HTMLURL string `json:"html_url"` // GitHub
+		IID     int    `json:"iid"`      // GitLab
+		WebURL  string `json:"web_url"`  // GitLab
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return nil, fmt.Errorf("failed to decode created issue: %w", err)
+	}
+	if t.provider == ProviderGitHub {
+		return &Issue{Number: created.Number, URL: created.HTMLURL}, nil
+	}
+	return &Issue{Number: created.IID, URL: created.WebURL}, nil
+}
+
+// errorMessage returns the message of an API error response, for appending to an error
+func errorMessage(body []byte) string {
+	var response struct {
+		Message any    `json:"message"` // A string on GitHub; GitLab also sends objects of field errors
+		Error   string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &response); err == nil {
+		switch {
+		case response.Message != nil:
+			if text, ok := response.Message.(string); ok {
+				return ": " + text
+			}
+			if encoded, err := json.Marshal(response.Message); err == nil {
+				return ": " + string(encoded)
+			}
+		case response.Error != "":
+			return ": " + response.Error
+		}
+	}
+	if text := strings.TrimSpace(string(body)); text != "" {
+		return ": " + firstLine(text)
+	}
+	return ""
+}
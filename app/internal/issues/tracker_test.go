@@ -0,0 +1,122 @@
+package issues
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stwalsh4118/clio/internal/config"
+)
+
+func TestValidateRepo(t *testing.T) {
+	tests := []struct {
+		provider string
+		repo     string
+		wantErr  bool
+	}{
+		{provider: ProviderGitHub, repo: "stwalsh4118/clio"},
+		{provider: ProviderGitHub, repo: "group/sub/clio", wantErr: true},
+		{provider: ProviderGitLab, repo: "group/sub/clio"},
+		{provider: ProviderGitLab, repo: "clio", wantErr: true},
+		{provider: ProviderGitHub, repo: "owner/", wantErr: true},
+	}
+	for _, tt := range tests {
+		if err := ValidateRepo(tt.provider, tt.repo); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateRepo(%s, %q) error = %v, wantErr %v", tt.provider, tt.repo, err, tt.wantErr)
+		}
+	}
+}
+
+func TestTracker_CreateGitHub(t *testing.T) {
+	t.Setenv("CLIO_SECRET_TEST_GITHUB_TOKEN", "gh-token")
+	var got map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/repos/stwalsh4118/clio/issues" {
+			t.Errorf("request = %s %s", r.Method, r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer gh-token" {
+			t.Errorf("Authorization = %q", auth)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"number": 42, "html_url": "https://github.com/stwalsh4118/clio/issues/42"}`))
+	}))
+	defer server.Close()
+
+	tracker, err := NewTracker(config.IssuesConfig{GitHub: config.IssueTrackerConfig{Token: "secret:test-github-token", APIURL: server.URL}}, ProviderGitHub)
+	if err != nil {
+		t.Fatalf("NewTracker() error = %v", err)
+	}
+	issue, err := tracker.Create(context.Background(), "stwalsh4118/clio", Draft{Title: "Lexer crash", Body: "Details", Labels: []string{"bug"}})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if issue.Number != 42 || issue.URL != "https://github.com/stwalsh4118/clio/issues/42" {
+		t.Errorf("Create() = %+v", issue)
+	}
+	if got["title"] != "Lexer crash" || got["body"] != "Details" || len(got["labels"].([]any)) != 1 {
+		t.Errorf("request body = %v", got)
+	}
+}
+
+func TestTracker_CreateGitLab(t *testing.T) {
+	var got map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/projects/group%2Fsub%2Fclio/issues" {
+			t.Errorf("path = %s", r.URL.EscapedPath())
+		}
+		if token := r.Header.Get("PRIVATE-TOKEN"); token != "gl-token" {
+			t.Errorf("PRIVATE-TOKEN = %q", token)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"iid": 7, "web_url": "https://gitlab.com/group/sub/clio/-/issues/7"}`))
+	}))
+	defer server.Close()
+
+	tracker, err := NewTracker(config.IssuesConfig{GitLab: config.IssueTrackerConfig{Token: "gl-token", APIURL: server.URL + "/"}}, ProviderGitLab)
+	if err != nil {
+		t.Fatalf("NewTracker() error = %v", err)
+	}
+	issue, err := tracker.Create(context.Background(), "group/sub/clio", Draft{Title: "Lexer crash", Body: "Details", Labels: []string{"bug", "lexer"}})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if issue.Number != 7 || issue.URL != "https://gitlab.com/group/sub/clio/-/issues/7" {
+		t.Errorf("Create() = %+v", issue)
+	}
+	if got["description"] != "Details" || got["labels"] != "bug,lexer" {
+		t.Errorf("request body = %v", got)
+	}
+}
+
+func TestTracker_CreateError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message": "Not Found"}`))
+	}))
+	defer server.Close()
+
+	tracker, err := NewTracker(config.IssuesConfig{GitHub: config.IssueTrackerConfig{Token: "token", APIURL: server.URL}}, ProviderGitHub)
+	if err != nil {
+		t.Fatalf("NewTracker() error = %v", err)
+	}
+	_, err = tracker.Create(context.Background(), "stwalsh4118/missing", Draft{Title: "t"})
+	if err == nil || !strings.Contains(err.Error(), "404 Not Found: Not Found") {
+		t.Errorf("Create() error = %v, want the API's message", err)
+	}
+
+	if _, err := NewTracker(config.IssuesConfig{}, ProviderGitHub); err == nil {
+		t.Error("NewTracker() without a token should fail")
+	}
+	if _, err := NewTracker(config.IssuesConfig{}, "jira"); err == nil {
+		t.Error("NewTracker() with an unknown provider should fail")
+	}
+}
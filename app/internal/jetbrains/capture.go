@@ -0,0 +1,318 @@
+package jetbrains
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/cursor"
+	"github.com/stwalsh4118/clio/internal/logging"
+	"github.com/stwalsh4118/clio/internal/sensitive"
+)
+
+const (
+	// SourceJetBrains marks conversations captured from JetBrains AI Assistant
+	SourceJetBrains = "jetbrains"
+
+	// workspaceDirName holds per-project workspace files inside an IDE config directory
+	workspaceDirName = "workspace"
+	// recentProjectsFile maps workspace IDs to project paths inside an IDE config directory
+	recentProjectsFile = "options/recentProjects.xml"
+	// workspaceFileSuffix is the extension of workspace files
+	workspaceFileSuffix = ".xml"
+)
+
+// CaptureService defines the interface for the JetBrains AI Assistant capture service
+type CaptureService interface {
+	Start() error
+	Stop() error
+	// OnSessionEnd registers a handler for sessions this service owns. Sessions shared with
+	// another capture source report their ends through that source.
+	OnSessionEnd(handler cursor.SessionEndHandler)
+}
+
+// captureService polls the workspace files of every JetBrains IDE under the config root
+type captureService struct {
+	config         *config.Config
+	logger         logging.Logger
+	recorder       cursor.ConversationRecorder
+	projects       cursor.ProjectNamer
+	sessionManager cursor.SessionManager
+	ownsSessions   bool // sessionManager was created here rather than shared
+	configPath     string
+	homeDir        string
+	interval       time.Duration
+	modTimes       map[string]time.Time // Last captured modification time per workspace file (poll goroutine only)
+	ctx            context.Context
+	cancel         context.CancelFunc
+	wg             sync.WaitGroup
+	started        bool
+	mu             sync.Mutex
+}
+
+// NewCaptureService creates a JetBrains capture service. Conversations join sessions from
+// sessions when given, so work on a project in several editors shares sessions; otherwise
+// the service manages its own.
+func NewCaptureService(cfg *config.Config, database *sql.DB, sessions cursor.SessionManager) (CaptureService, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+	if database == nil {
+		return nil, fmt.Errorf("database cannot be nil")
+	}
+
+	logger, err := logging.NewLogger(cfg)
+	if err != nil {
+		logger = logging.NewNoopLogger()
+	}
+	logger = logger.With("component", "jetbrains_capture")
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+	configPath := cfg.JetBrains.ConfigPath
+	if configPath == "" {
+		if configPath, err = DefaultConfigPath(); err != nil {
+			return nil, err
+		}
+	}
+
+	ownsSessions := sessions == nil
+	if ownsSessions {
+		sessions, err = cursor.NewSessionManager(cfg, database)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create session manager: %w", err)
+		}
+		if err := sessions.LoadSessions(); err != nil {
+			logger.Warn("failed to load existing sessions", "error", err)
+		}
+	}
+
+	gate, err := sensitive.NewGate(cfg.Sensitive, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sensitive gate: %w", err)
+	}
+	recorder, err := cursor.NewConversationRecorderWithGate(database, sessions, gate, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create conversation recorder: %w", err)
+	}
+	projects, err := cursor.NewProjectNamer(database, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create project namer: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &captureService{
+		config:         cfg,
+		logger:         logger,
+		recorder:       recorder,
+		projects:       projects,
+		sessionManager: sessions,
+		ownsSessions:   ownsSessions,
+		configPath:     configPath,
+		homeDir:        homeDir,
+		interval:       time.Duration(cfg.JetBrains.PollIntervalSeconds) * time.Second,
+		modTimes:       make(map[string]time.Time),
+		ctx:            ctx,
+		cancel:         cancel,
+	}, nil
+}
+
+// DefaultConfigPath returns the JetBrains config root for this platform
+func DefaultConfigPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	switch runtime.GOOS {
+	case "darwin":
+		return filepath.Join(homeDir, "Library", "Application Support", "JetBrains"), nil
+	case "windows":
+		if appData := os.Getenv("APPDATA"); appData != "" {
+			return filepath.Join(appData, "JetBrains"), nil
+		}
+		return filepath.Join(homeDir, "AppData", "Roaming", "JetBrains"), nil
+	}
+	if xdgConfig := os.Getenv("XDG_CONFIG_HOME"); xdgConfig != "" {
+		return filepath.Join(xdgConfig, "JetBrains"), nil
+	}
+	return filepath.Join(homeDir, ".config", "JetBrains"), nil
+}
+
+// Start begins polling
+func (cs *captureService) Start() error {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if cs.started {
+		return fmt.Errorf("jetbrains capture service is already started")
+	}
+
+	if cs.ownsSessions {
+		if err := cs.sessionManager.StartInactivityMonitor(cs.ctx); err != nil {
+			return fmt.Errorf("failed to start inactivity monitor: %w", err)
+		}
+	}
+
+	cs.wg.Add(1)
+	go cs.run()
+
+	cs.started = true
+	cs.logger.Info("jetbrains capture started", "config_path", cs.configPath, "interval", cs.interval)
+	return nil
+}
+
+// Stop stops polling and waits for an in-progress poll to finish
+func (cs *captureService) Stop() error {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if !cs.started {
+		return nil
+	}
+
+	cs.cancel()
+	cs.wg.Wait()
+
+	if cs.ownsSessions {
+		if err := cs.sessionManager.Stop(); err != nil {
+			return fmt.Errorf("failed to stop session manager: %w", err)
+		}
+	}
+
+	cs.started = false
+	cs.logger.Info("jetbrains capture stopped")
+	return nil
+}
+
+// OnSessionEnd registers a handler when this service owns its sessions
+func (cs *captureService) OnSessionEnd(handler cursor.SessionEndHandler) {
+	if cs.ownsSessions {
+		cs.sessionManager.OnSessionEnd(handler)
+	}
+}
+
+// run polls immediately, then on every interval until stopped
+func (cs *captureService) run() {
+	defer cs.wg.Done()
+
+	ticker := time.NewTicker(cs.interval)
+	defer ticker.Stop()
+
+	for {
+		cs.poll()
+
+		select {
+		case <-cs.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll scans every IDE config directory under the root (e.g. GoLand2024.2)
+func (cs *captureService) poll() {
+	entries, err := os.ReadDir(cs.configPath)
+	if err != nil {
+		cs.logger.Debug("failed to read jetbrains config root", "path", cs.configPath, "error", err)
+		return
+	}
+
+	for _, entry := range entries {
+		if cs.ctx.Err() != nil {
+			return
+		}
+		if entry.IsDir() {
+			cs.pollIDE(filepath.Join(cs.configPath, entry.Name()))
+		}
+	}
+}
+
+// pollIDE captures chats from the workspace files of one IDE modified since the last poll
+func (cs *captureService) pollIDE(ideDir string) {
+	workspaceDir := filepath.Join(ideDir, workspaceDirName)
+	entries, err := os.ReadDir(workspaceDir)
+	if err != nil {
+		return
+	}
+
+	var projects map[string]string // Loaded on the first changed workspace file
+	for _, entry := range entries {
+		if cs.ctx.Err() != nil {
+			return
+		}
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, workspaceFileSuffix) {
+			continue
+		}
+
+		path := filepath.Join(workspaceDir, name)
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if modTime, ok := cs.modTimes[path]; ok && modTime.Equal(info.ModTime()) {
+			continue
+		}
+		cs.modTimes[path] = info.ModTime()
+
+		chats, err := readWorkspace(path, info.ModTime())
+		if err != nil {
+			cs.logger.Warn("failed to read jetbrains workspace", "path", path, "error", err)
+			continue
+		}
+		if len(chats) == 0 {
+			continue
+		}
+
+		if projects == nil {
+			projects = cs.readRecentProjects(ideDir)
+		}
+		workspaceProject := projects[strings.TrimSuffix(name, workspaceFileSuffix)]
+
+		for _, c := range chats {
+			projectPath := c.ProjectPath
+			if projectPath == "" {
+				projectPath = workspaceProject
+			}
+			if _, err := cs.recorder.Record(c.Conversation, cs.projects.ProjectName(projectPath)); err != nil {
+				cs.logger.Error("failed to record jetbrains chat", "path", path, "composer_id", c.Conversation.ComposerID, "error", err)
+			}
+		}
+	}
+}
+
+// readRecentProjects loads an IDE's workspace-to-project mapping, returning an empty map when unavailable
+func (cs *captureService) readRecentProjects(ideDir string) map[string]string {
+	file, err := os.Open(filepath.Join(ideDir, filepath.FromSlash(recentProjectsFile)))
+	if err != nil {
+		return map[string]string{}
+	}
+	defer file.Close()
+
+	projects, err := parseRecentProjects(file, cs.homeDir)
+	if err != nil {
+		cs.logger.Warn("failed to parse recent projects", "ide", filepath.Base(ideDir), "error", err)
+		return map[string]string{}
+	}
+	return projects
+}
+
+// readWorkspace parses the chats in a workspace file
+func readWorkspace(path string, modTime time.Time) ([]chat, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return parseWorkspace(file, modTime)
+}
@@ -0,0 +1,183 @@
+package jetbrains
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/db"
+	_ "modernc.org/sqlite"
+)
+
+const workspaceXML = `<?xml version="1.0" encoding="UTF-8"?>
+<project version="4">
+  <component name="ProjectId" id="2abc" />
+  <component name="ChatSessionStateTemp">
+    <option name="chats">
+      <list>
+        <SerializedChat>
+          <option name="uid" value="chat-1" />
+          <option name="title" value="Fix flaky test" />
+          <option name="messages">
+            <list>
+              <SerializedChatMessage>
+                <option name="uid" value="m1" />
+                <option name="author" value="User" />
+                <option name="text" value="Why is TestPoll flaky?" />
+                <option name="timestamp" value="1700000000000" />
+              </SerializedChatMessage>
+              <SerializedChatMessage>
+                <option name="uid" value="m2" />
+                <option name="author" value="System" />
+                <option name="text" value="context" />
+              </SerializedChatMessage>
+              <SerializedChatMessage>
+                <option name="uid" value="m2b" />
+                <option name="author" value="Assistant" />
+                <option name="text">It races with the ticker.</option>
+                <option name="timestamp" value="1700000005000" />
+              </SerializedChatMessage>
+            </list>
+          </option>
+        </SerializedChat>
+        <SerializedChat>
+          <option name="title" value="Other project" />
+          <option name="projectPath" value="/work/elsewhere" />
+          <option name="messages">
+            <list>
+              <SerializedChatMessage>
+                <option name="author" value="User" />
+                <option name="text" value="hello" />
+              </SerializedChatMessage>
+            </list>
+          </option>
+        </SerializedChat>
+      </list>
+    </option>
+  </component>
+  <component name="RunManager">
+    <option name="messages" value="not a chat" />
+  </component>
+</project>`
+
+const recentProjectsXML = `<application>
+  <component name="RecentProjectsManager">
+    <option name="additionalInfo">
+      <map>
+        <entry key="$USER_HOME$/code/clio">
+          <value>
+            <RecentProjectMetaInfo frameTitle="clio" projectWorkspaceId="2abc" />
+          </value>
+        </entry>
+      </map>
+    </option>
+  </component>
+</application>`
+
+func TestParseWorkspace(t *testing.T) {
+	fallback := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	chats, err := parseWorkspace(strings.NewReader(workspaceXML), fallback)
+	if err != nil {
+		t.Fatalf("parseWorkspace() error = %v", err)
+	}
+	if len(chats) != 2 {
+		t.Fatalf("got %d chats, want 2", len(chats))
+	}
+
+	first := chats[0].Conversation
+	if first.ComposerID != "jetbrains-chat-1" || first.Name != "Fix flaky test" || first.Source != SourceJetBrains {
+		t.Errorf("chat = %q/%q/%q", first.ComposerID, first.Name, first.Source)
+	}
+	if len(first.Messages) != 2 {
+		t.Fatalf("got %d messages, want 2 (system skipped)", len(first.Messages))
+	}
+	if got := first.Messages[1]; got.Role != "agent" || got.Text != "It races with the ticker." || got.BubbleID != "jetbrains-chat-1-m2b" {
+		t.Errorf("assistant message = %q %q (%s)", got.Role, got.Text, got.BubbleID)
+	}
+	if !first.Messages[0].CreatedAt.Equal(time.UnixMilli(1700000000000)) {
+		t.Errorf("timestamp = %v", first.Messages[0].CreatedAt)
+	}
+
+	second := chats[1]
+	if second.ProjectPath != "/work/elsewhere" || !strings.HasPrefix(second.Conversation.ComposerID, "jetbrains-") {
+		t.Errorf("second chat = %q (%s)", second.ProjectPath, second.Conversation.ComposerID)
+	}
+	if !second.Conversation.Messages[0].CreatedAt.Equal(fallback) {
+		t.Errorf("message without timestamp = %v, want fallback", second.Conversation.Messages[0].CreatedAt)
+	}
+}
+
+func TestParseRecentProjects(t *testing.T) {
+	projects, err := parseRecentProjects(strings.NewReader(recentProjectsXML), "/home/dev")
+	if err != nil {
+		t.Fatalf("parseRecentProjects() error = %v", err)
+	}
+	if got := projects["2abc"]; got != "/home/dev/code/clio" {
+		t.Errorf("workspace 2abc = %q", got)
+	}
+}
+
+func TestCaptureService_AttributesChatsToProjects(t *testing.T) {
+	home := t.TempDir()
+	database, err := sql.Open("sqlite", filepath.Join(home, "clio.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer database.Close()
+	if err := db.RunMigrations(database); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	ideDir := filepath.Join(home, "JetBrains", "GoLand2024.2")
+	os.MkdirAll(filepath.Join(ideDir, "workspace"), 0755)
+	os.MkdirAll(filepath.Join(ideDir, "options"), 0755)
+	os.WriteFile(filepath.Join(ideDir, "workspace", "2abc.xml"), []byte(workspaceXML), 0644)
+	os.WriteFile(filepath.Join(ideDir, "options", "recentProjects.xml"), []byte(recentProjectsXML), 0644)
+
+	cfg := &config.Config{
+		Session: config.SessionConfig{InactivityTimeoutMinutes: 30},
+		JetBrains: config.JetBrainsConfig{
+			Enabled:             true,
+			ConfigPath:          filepath.Join(home, "JetBrains"),
+			PollIntervalSeconds: 1,
+		},
+	}
+	service, err := NewCaptureService(cfg, database, nil)
+	if err != nil {
+		t.Fatalf("NewCaptureService() error = %v", err)
+	}
+	cs := service.(*captureService)
+	cs.poll()
+	cs.poll() // Unchanged files are skipped
+
+	rows, err := database.Query(`
+		SELECT c.id, s.project FROM conversations c JOIN sessions s ON s.id = c.session_id
+		WHERE c.source = ? ORDER BY c.name
+	`, SourceJetBrains)
+	if err != nil {
+		t.Fatalf("failed to query conversations: %v", err)
+	}
+	defer rows.Close()
+
+	projects := make(map[string]string)
+	for rows.Next() {
+		var id, project string
+		rows.Scan(&id, &project)
+		projects[id] = project
+	}
+	if len(projects) != 2 {
+		t.Fatalf("captured %d chats, want 2: %v", len(projects), projects)
+	}
+	if projects["jetbrains-chat-1"] != "clio" {
+		t.Errorf("workspace chat project = %q, want clio from recentProjects.xml", projects["jetbrains-chat-1"])
+	}
+	for id, project := range projects {
+		if id != "jetbrains-chat-1" && project != "elsewhere" {
+			t.Errorf("chat %s project = %q, want elsewhere from its projectPath", id, project)
+		}
+	}
+}
@@ -0,0 +1,294 @@
+package jetbrains
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/cursor"
+)
+
+const (
+	// chatComponentMarker identifies workspace components holding AI Assistant chats
+	chatComponentMarker = "chat"
+	// messagesOption is the option holding a chat's message list
+	messagesOption = "messages"
+	// userHomeMacro is the placeholder JetBrains writes for the home directory in paths
+	userHomeMacro = "$USER_HOME$"
+	// generatedIDLength is the number of hex characters used for IDs derived from content
+	generatedIDLength = 16
+	// millisecondThreshold separates millisecond from second epoch timestamps
+	millisecondThreshold = 1e11
+)
+
+// Option names tried, in order, for each chat and message field. AI Assistant has
+// renamed fields between releases, so each field accepts several spellings.
+var (
+	chatIDOptions      = []string{"uid", "id", "chatId", "sessionId"}
+	chatTitleOptions   = []string{"title", "name", "chatTitle"}
+	projectPathOptions = []string{"projectPath", "project", "basePath"}
+	messageIDOptions   = []string{"uid", "id", "messageId"}
+	messageRoleOptions = []string{"author", "role", "type", "sender", "kind"}
+	messageTextOptions = []string{"text", "content", "message", "formattedText"}
+	messageTimeOptions = []string{"timestamp", "createdAt", "time", "date"}
+)
+
+// xmlNode is a generic XML element. JetBrains persists component state as nested
+// <option name="..." value="..."/> elements rather than a fixed schema.
+type xmlNode struct {
+	Name     string
+	Attrs    map[string]string
+	Text     string
+	Children []*xmlNode
+}
+
+// parseXML reads an XML document into a node tree
+func parseXML(r io.Reader) (*xmlNode, error) {
+	decoder := xml.NewDecoder(r)
+	root := &xmlNode{}
+	stack := []*xmlNode{root}
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse XML: %w", err)
+		}
+
+		parent := stack[len(stack)-1]
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{Name: t.Name.Local, Attrs: make(map[string]string, len(t.Attr))}
+			for _, attr := range t.Attr {
+				node.Attrs[attr.Name.Local] = attr.Value
+			}
+			parent.Children = append(parent.Children, node)
+			stack = append(stack, node)
+		case xml.EndElement:
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			parent.Text += string(t)
+		}
+	}
+	return root, nil
+}
+
+// option returns the child <option name="name">
+func (n *xmlNode) option(name string) *xmlNode {
+	for _, child := range n.Children {
+		if child.Name == "option" && strings.EqualFold(child.Attrs["name"], name) {
+			return child
+		}
+	}
+	return nil
+}
+
+// value returns the first non-empty field among names, read from an attribute, an
+// option's value attribute, or an option's text content
+func (n *xmlNode) value(names ...string) string {
+	for _, name := range names {
+		if value := strings.TrimSpace(n.Attrs[name]); value != "" {
+			return value
+		}
+		if option := n.option(name); option != nil {
+			if value := strings.TrimSpace(option.Attrs["value"]); value != "" {
+				return value
+			}
+			if value := strings.TrimSpace(option.Text); value != "" {
+				return value
+			}
+		}
+	}
+	return ""
+}
+
+// listItems returns the elements of a serialized list option (<option><list>items</list></option>)
+func (n *xmlNode) listItems() []*xmlNode {
+	var items []*xmlNode
+	for _, child := range n.Children {
+		if child.Name == "list" || child.Name == "array" {
+			items = append(items, child.Children...)
+		} else {
+			items = append(items, child)
+		}
+	}
+	return items
+}
+
+// walk calls fn for n and every descendant until fn returns false for a subtree
+func (n *xmlNode) walk(fn func(*xmlNode) bool) {
+	if !fn(n) {
+		return
+	}
+	for _, child := range n.Children {
+		child.walk(fn)
+	}
+}
+
+// chat is an AI Assistant chat read from a workspace file
+type chat struct {
+	Conversation *cursor.Conversation
+	ProjectPath  string // Set when the chat records its own project
+}
+
+// parseWorkspace extracts chats from an IDE workspace file. Messages without a
+// timestamp are given fallbackTime.
+func parseWorkspace(r io.Reader, fallbackTime time.Time) ([]chat, error) {
+	root, err := parseXML(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var chats []chat
+	root.walk(func(node *xmlNode) bool {
+		if node.Name != "component" {
+			return true
+		}
+		if !strings.Contains(strings.ToLower(node.Attrs["name"]), chatComponentMarker) {
+			return false
+		}
+		node.walk(func(candidate *xmlNode) bool {
+			messages := candidate.option(messagesOption)
+			if messages == nil {
+				return true
+			}
+			if c, ok := parseChat(candidate, messages, fallbackTime); ok {
+				chats = append(chats, c)
+			}
+			return false
+		})
+		return false
+	})
+	return chats, nil
+}
+
+// parseChat converts a serialized chat into a conversation
+func parseChat(node, messagesNode *xmlNode, fallbackTime time.Time) (chat, bool) {
+	var messages []cursor.Message
+	for i, item := range messagesNode.listItems() {
+		messageType, role, ok := messageRole(item.value(messageRoleOptions...))
+		if !ok {
+			continue
+		}
+		text := item.value(messageTextOptions...)
+		if text == "" {
+			continue
+		}
+		createdAt := parseTimestamp(item.value(messageTimeOptions...))
+		if createdAt.IsZero() {
+			createdAt = fallbackTime
+		}
+		messageID := item.value(messageIDOptions...)
+		if messageID == "" {
+			messageID = strconv.Itoa(i)
+		}
+		messages = append(messages, cursor.Message{
+			BubbleID:      messageID,
+			Type:          messageType,
+			Role:          role,
+			Text:          text,
+			ContentSource: "text",
+			CreatedAt:     createdAt,
+			ParserVersion: cursor.ParserVersion,
+		})
+	}
+	if len(messages) == 0 {
+		return chat{}, false
+	}
+
+	title := node.value(chatTitleOptions...)
+	id := node.value(chatIDOptions...)
+	if id == "" {
+		// Chats without IDs are identified by their title and opening message
+		id = contentID(title + "\x00" + messages[0].Text)
+	}
+	composerID := fmt.Sprintf("%s-%s", SourceJetBrains, id)
+	for i := range messages {
+		messages[i].BubbleID = fmt.Sprintf("%s-%s", composerID, messages[i].BubbleID)
+	}
+	if title == "" {
+		title = "Untitled"
+	}
+
+	return chat{
+		Conversation: &cursor.Conversation{
+			ComposerID: composerID,
+			Name:       title,
+			Status:     "completed",
+			CreatedAt:  messages[0].CreatedAt,
+			Messages:   messages,
+			Source:     SourceJetBrains,
+		},
+		ProjectPath: node.value(projectPathOptions...),
+	}, true
+}
+
+// messageRole maps an AI Assistant author to a message type and role; system and
+// unrecognised authors are skipped
+func messageRole(author string) (int, string, bool) {
+	switch author := strings.ToLower(author); {
+	case strings.Contains(author, "user"), strings.Contains(author, "human"):
+		return 1, "user", true
+	case strings.Contains(author, "assistant"), strings.Contains(author, "agent"),
+		strings.Contains(author, "bot"), strings.Contains(author, "model"), author == "ai":
+		return 2, "agent", true
+	}
+	return 0, "", false
+}
+
+// parseTimestamp reads epoch seconds, epoch milliseconds, or RFC 3339, returning the zero time otherwise
+func parseTimestamp(value string) time.Time {
+	if value == "" {
+		return time.Time{}
+	}
+	if number, err := strconv.ParseInt(value, 10, 64); err == nil && number > 0 {
+		if number > millisecondThreshold {
+			return time.UnixMilli(number)
+		}
+		return time.Unix(number, 0)
+	}
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed
+	}
+	return time.Time{}
+}
+
+// contentID derives a stable ID from content
+func contentID(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])[:generatedIDLength]
+}
+
+// parseRecentProjects maps workspace IDs to project paths from an IDE's recentProjects.xml
+func parseRecentProjects(r io.Reader, homeDir string) (map[string]string, error) {
+	root, err := parseXML(r)
+	if err != nil {
+		return nil, err
+	}
+
+	projects := make(map[string]string)
+	root.walk(func(node *xmlNode) bool {
+		if node.Name != "entry" || node.Attrs["key"] == "" {
+			return true
+		}
+		path := strings.ReplaceAll(node.Attrs["key"], userHomeMacro, homeDir)
+		node.walk(func(meta *xmlNode) bool {
+			if workspaceID := meta.Attrs["projectWorkspaceId"]; workspaceID != "" {
+				projects[workspaceID] = path
+			}
+			return true
+		})
+		return false
+	})
+	return projects, nil
+}
@@ -0,0 +1,111 @@
+// Package journal stores freeform notes written during a session, recording
+// intent and decisions the AI conversations don't capture.
+package journal
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+// Entry is a timestamped note attached to a session
+type Entry struct {
+	ID        string
+	SessionID string
+	Text      string
+	CreatedAt time.Time
+}
+
+// Journal defines the interface for writing and reading session notes
+type Journal interface {
+	// Add records a note in a session at the given time
+	Add(sessionID, text string, at time.Time) (*Entry, error)
+	// List returns a session's notes, oldest first
+	List(sessionID string) ([]Entry, error)
+}
+
+// journal implements Journal on top of the clio database
+type journal struct {
+	db     *sql.DB
+	logger logging.Logger
+}
+
+// NewJournal creates a journal backed by the database
+func NewJournal(db *sql.DB, logger logging.Logger) (Journal, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database cannot be nil")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	return &journal{
+		db:     db,
+		logger: logger.With("component", "journal"),
+	}, nil
+}
+
+// Add stores a note
+func (j *journal) Add(sessionID, text string, at time.Time) (*Entry, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, fmt.Errorf("journal entry cannot be empty")
+	}
+
+	var exists bool
+	if err := j.db.QueryRow("SELECT EXISTS(SELECT 1 FROM sessions WHERE id = ?)", sessionID).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to look up session: %w", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("session %s not found", sessionID)
+	}
+
+	entry := &Entry{
+		ID:        uuid.New().String(),
+		SessionID: sessionID,
+		Text:      text,
+		CreatedAt: at,
+	}
+	if _, err := j.db.Exec(`
+		INSERT INTO journal_entries (id, session_id, text, created_at)
+		VALUES (?, ?, ?, ?)
+	`, entry.ID, sessionID, text, at); err != nil {
+		return nil, fmt.Errorf("failed to store journal entry: %w", err)
+	}
+
+	j.logger.Debug("added journal entry", "session_id", sessionID, "entry_id", entry.ID)
+	return entry, nil
+}
+
+// List returns a session's notes
+func (j *journal) List(sessionID string) ([]Entry, error) {
+	rows, err := j.db.Query(`
+		SELECT id, session_id, text, created_at
+		FROM journal_entries
+		WHERE session_id = ?
+	`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query journal entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []Entry
+	for rows.Next() {
+		var entry Entry
+		if err := rows.Scan(&entry.ID, &entry.SessionID, &entry.Text, &entry.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan journal entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating journal entries: %w", err)
+	}
+
+	db.SortOldestFirst(entries, func(e Entry) time.Time { return e.CreatedAt })
+	return entries, nil
+}
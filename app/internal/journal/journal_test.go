@@ -0,0 +1,59 @@
+package journal
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/logging"
+	_ "modernc.org/sqlite"
+)
+
+func TestJournal_AddAndList(t *testing.T) {
+	database, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "clio.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer database.Close()
+	if err := db.RunMigrations(database); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	base := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	if _, err := database.Exec(`
+		INSERT INTO sessions (id, project, start_time, last_activity, created_at, updated_at)
+		VALUES ('session-1', 'clio', ?, ?, ?, ?)
+	`, base, base, base, base); err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+
+	j, err := NewJournal(database, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewJournal() error = %v", err)
+	}
+
+	// Added out of order to check entries are listed by time
+	if _, err := j.Add("session-1", "  Trying the cache before the index  ", base.Add(time.Hour)); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if _, err := j.Add("session-1", "Goal: make search fast", base); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	entries, err := j.List("session-1")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(entries) != 2 || entries[0].Text != "Goal: make search fast" || entries[1].Text != "Trying the cache before the index" {
+		t.Errorf("entries = %+v, want both notes oldest first and trimmed", entries)
+	}
+
+	if _, err := j.Add("session-1", " ", base); err == nil {
+		t.Error("Add() of an empty note should fail")
+	}
+	if _, err := j.Add("missing", "note", base); err == nil {
+		t.Error("Add() to an unknown session should fail")
+	}
+}
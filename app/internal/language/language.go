@@ -0,0 +1,151 @@
+// Package language detects the natural language of message prose, so exports
+// can tell which messages need translating. Latin-script languages are told
+// apart by their stop words; other scripts by the script itself.
+package language
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/stwalsh4118/clio/internal/search"
+	"github.com/stwalsh4118/clio/internal/search/stopwords"
+)
+
+const (
+	// minStopWords is the fewest stop words of a language text needs to be
+	// detected as it, since short messages like "ok" give no signal
+	minStopWords = 2
+	// minStopWordShare is the smallest share of the words that must be stop
+	// words of the detected language
+	minStopWordShare = 0.1
+	// minScriptShare is the share of letters a non-Latin script needs to decide
+	// the language
+	minScriptShare = 0.5
+)
+
+// codes maps the stop word list languages to ISO 639-1 codes
+var codes = map[string]string{
+	"dutch":      "nl",
+	"english":    "en",
+	"french":     "fr",
+	"german":     "de",
+	"italian":    "it",
+	"portuguese": "pt",
+	"spanish":    "es",
+}
+
+// scripts are the non-Latin scripts recognized, with the language each stands
+// for. Han is Chinese unless Japanese kana appear too.
+var scripts = []struct {
+	table    *unicode.RangeTable
+	language string
+}{
+	{unicode.Hiragana, "ja"},
+	{unicode.Katakana, "ja"},
+	{unicode.Hangul, "ko"},
+	{unicode.Han, "zh"},
+	{unicode.Cyrillic, "ru"},
+	{unicode.Greek, "el"},
+	{unicode.Arabic, "ar"},
+	{unicode.Hebrew, "he"},
+	{unicode.Devanagari, "hi"},
+	{unicode.Thai, "th"},
+}
+
+// stopLists are the stop word sets by ISO 639-1 code
+var stopLists = func() map[string]map[string]bool {
+	lists := make(map[string]map[string]bool, len(codes))
+	for _, name := range stopwords.Languages() {
+		code, ok := codes[name]
+		if !ok {
+			continue
+		}
+		words, _ := stopwords.Words(name)
+		set := make(map[string]bool, len(words))
+		for _, word := range words {
+			set[word] = true
+		}
+		lists[code] = set
+	}
+	return lists
+}()
+
+// Detect returns the ISO 639-1 code of the language of markdown's prose, or an
+// empty string when it's too short or mixed to tell. Code blocks are left out,
+// since their keywords would read as English.
+func Detect(markdown string) string {
+	prose := search.Normalize(markdown).Prose
+	if language := detectScript(prose); language != "" {
+		return language
+	}
+	return detectStopWords(prose)
+}
+
+// detectScript returns the language of the non-Latin script most letters are
+// written in, or an empty string
+func detectScript(text string) string {
+	letters := 0
+	counts := make(map[string]int)
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		for _, script := range scripts {
+			if unicode.Is(script.table, r) {
+				counts[script.language]++
+				break
+			}
+		}
+	}
+	if letters == 0 {
+		return ""
+	}
+
+	// Kana mark Japanese even though most Japanese text is Han
+	if counts["ja"] > 0 && float64(counts["ja"]+counts["zh"]) >= minScriptShare*float64(letters) {
+		return "ja"
+	}
+	best, bestCount := "", 0
+	for language, count := range counts {
+		if count > bestCount || count == bestCount && language < best {
+			best, bestCount = language, count
+		}
+	}
+	if float64(bestCount) < minScriptShare*float64(letters) {
+		return ""
+	}
+	return best
+}
+
+// detectStopWords returns the language whose stop words are most frequent in
+// text, or an empty string when none stands out
+func detectStopWords(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.Is(unicode.Mn, r)
+	})
+	if len(words) == 0 {
+		return ""
+	}
+
+	best, bestCount, runnerUp := "", 0, 0
+	for code, stop := range stopLists {
+		count := 0
+		for _, word := range words {
+			if stop[word] {
+				count++
+			}
+		}
+		switch {
+		case count > bestCount:
+			best, bestCount, runnerUp = code, count, bestCount
+		case count > runnerUp:
+			runnerUp = count
+		}
+	}
+	// Ties are undecided rather than resolved by map order
+	if bestCount < minStopWords || bestCount == runnerUp || float64(bestCount) < minStopWordShare*float64(len(words)) {
+		return ""
+	}
+	return best
+}
@@ -0,0 +1,33 @@
+package language
+
+import "testing"
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"english", "Can you fix the failing test in the parser? It is not handling empty input.", "en"},
+		{"german", "Kannst du bitte den Test reparieren? Er schlägt fehl, wenn die Eingabe leer ist.", "de"},
+		{"french", "Peux-tu corriger le test qui échoue dans le parseur ? Il ne gère pas les entrées vides.", "fr"},
+		{"spanish", "¿Puedes arreglar la prueba que falla en el analizador? No maneja la entrada vacía.", "es"},
+		{"dutch", "Kun je de falende test in de parser repareren? Het werkt niet met een lege invoer.", "nl"},
+		{"japanese", "パーサーのテストを修正してください。", "ja"},
+		{"chinese", "请修复解析器中失败的测试。", "zh"},
+		{"russian", "Пожалуйста, исправь тест в парсере.", "ru"},
+		{"korean", "파서의 테스트를 고쳐 주세요.", "ko"},
+		{"too short", "ok", ""},
+		{"empty", "", ""},
+		{"code is ignored", "Bitte schau dir das an, es ist kaputt:\n\n```go\nif err != nil {\n\treturn the error for this and that\n}\n```", "de"},
+		{"only code", "```go\nfor i := 0; i < n; i++ {\n}\n```", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Detect(tt.text); got != tt.want {
+				t.Errorf("Detect(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
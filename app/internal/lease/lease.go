@@ -0,0 +1,230 @@
+// Package lease keeps two daemons from writing to the same database, e.g. one
+// started with 'clio start' and another by systemd, or two profiles sharing a
+// database path. The daemon holding the lease refreshes its heartbeat in the
+// daemon_lease table; another daemon finding a fresh lease held by someone else
+// refuses to start.
+package lease
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+const (
+	// HeartbeatInterval is how often the holder refreshes the lease
+	HeartbeatInterval = 10 * time.Second
+	// Expiry is how long a lease without a heartbeat is honoured, after which
+	// another daemon may take it over
+	Expiry = 6 * HeartbeatInterval
+)
+
+var (
+	// ErrHeld is returned when another daemon holds the lease
+	ErrHeld = errors.New("database is in use by another clio daemon")
+	// ErrLost is returned when the lease was taken over by another daemon
+	ErrLost = errors.New("database lease was taken over by another clio daemon")
+)
+
+// Holder describes the daemon holding the lease
+type Holder struct {
+	Owner       string // Unique per daemon run
+	PID         int
+	Hostname    string
+	AcquiredAt  time.Time
+	HeartbeatAt time.Time
+}
+
+// Lease is one daemon's claim on the database
+type Lease interface {
+	Acquire() error
+	Heartbeat() error
+	Release() error
+}
+
+// lease implements Lease
+type lease struct {
+	db       *sql.DB
+	logger   logging.Logger
+	owner    string
+	pid      int
+	hostname string
+}
+
+// NewLease creates a lease for the current process. Nothing is claimed until Acquire.
+func NewLease(db *sql.DB, logger logging.Logger) (Lease, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database cannot be nil")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hostname: %w", err)
+	}
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, fmt.Errorf("failed to generate lease owner: %w", err)
+	}
+	pid := os.Getpid()
+
+	return &lease{
+		db:       db,
+		logger:   logger.With("component", "lease"),
+		owner:    fmt.Sprintf("%s:%d:%s", hostname, pid, hex.EncodeToString(suffix)),
+		pid:      pid,
+		hostname: hostname,
+	}, nil
+}
+
+// Acquire claims the lease, taking it over when its holder has stopped
+// heartbeating or, on this host, is no longer running. It returns ErrHeld with
+// the holder's details when another daemon holds it.
+func (l *lease) Acquire() error {
+	return l.acquire(time.Now())
+}
+
+// acquire claims the lease as of now
+func (l *lease) acquire(now time.Time) error {
+	holder, err := Current(l.db)
+	if err != nil {
+		return err
+	}
+
+	if holder == nil {
+		result, err := l.db.Exec(`
+			INSERT OR IGNORE INTO daemon_lease (id, owner, pid, hostname, acquired_at, heartbeat_at)
+			VALUES (1, ?, ?, ?, ?, ?)
+		`, l.owner, l.pid, l.hostname, now, now)
+		if err != nil {
+			return fmt.Errorf("failed to acquire database lease: %w", err)
+		}
+		if claimed(result) {
+			l.logger.Info("database lease acquired", "owner", l.owner)
+			return nil
+		}
+		// Another daemon claimed it first
+		return l.heldError(now)
+	}
+
+	if holder.Owner != l.owner && l.active(holder, now) {
+		return heldBy(holder, now)
+	}
+
+	// Only replace the holder read above, so of two daemons taking over at once one wins
+	result, err := l.db.Exec(`
+		UPDATE daemon_lease
+		SET owner = ?, pid = ?, hostname = ?, acquired_at = ?, heartbeat_at = ?
+		WHERE id = 1 AND owner = ?
+	`, l.owner, l.pid, l.hostname, now, now, holder.Owner)
+	if err != nil {
+		return fmt.Errorf("failed to acquire database lease: %w", err)
+	}
+	if !claimed(result) {
+		return l.heldError(now)
+	}
+	if holder.Owner != l.owner {
+		l.logger.Warn("took over abandoned database lease", "previous_owner", holder.Owner, "last_heartbeat", holder.HeartbeatAt)
+	}
+	l.logger.Info("database lease acquired", "owner", l.owner)
+	return nil
+}
+
+// Heartbeat refreshes the lease. It returns ErrLost when another daemon has
+// taken it over, after which this daemon must stop writing.
+func (l *lease) Heartbeat() error {
+	return l.heartbeat(time.Now())
+}
+
+// heartbeat refreshes the lease as of now
+func (l *lease) heartbeat(now time.Time) error {
+	result, err := l.db.Exec("UPDATE daemon_lease SET heartbeat_at = ? WHERE id = 1 AND owner = ?", now, l.owner)
+	if err != nil {
+		return fmt.Errorf("failed to refresh database lease: %w", err)
+	}
+	if !claimed(result) {
+		if holder, err := Current(l.db); err == nil && holder != nil {
+			return fmt.Errorf("%w (now held by pid %d on %s)", ErrLost, holder.PID, holder.Hostname)
+		}
+		return ErrLost
+	}
+	return nil
+}
+
+// Release gives up the lease if this daemon still holds it
+func (l *lease) Release() error {
+	if _, err := l.db.Exec("DELETE FROM daemon_lease WHERE id = 1 AND owner = ?", l.owner); err != nil {
+		return fmt.Errorf("failed to release database lease: %w", err)
+	}
+	l.logger.Info("database lease released", "owner", l.owner)
+	return nil
+}
+
+// active reports whether holder still holds the lease: it heartbeated within
+// Expiry and, when it runs on this host, its process is alive
+func (l *lease) active(holder *Holder, now time.Time) bool {
+	if now.Sub(holder.HeartbeatAt) >= Expiry {
+		return false
+	}
+	if holder.Hostname == l.hostname && !processAlive(holder.PID) {
+		return false
+	}
+	return true
+}
+
+// heldError re-reads the holder after losing a race to claim the lease
+func (l *lease) heldError(now time.Time) error {
+	holder, err := Current(l.db)
+	if err != nil {
+		return err
+	}
+	if holder == nil {
+		return ErrHeld
+	}
+	return heldBy(holder, now)
+}
+
+// heldBy describes holder in an ErrHeld error
+func heldBy(holder *Holder, now time.Time) error {
+	return fmt.Errorf("%w (pid %d on %s, last heartbeat %s ago); stop it first - a lease left by a daemon that is no longer running expires %s after its last heartbeat",
+		ErrHeld, holder.PID, holder.Hostname, now.Sub(holder.HeartbeatAt).Round(time.Second), Expiry)
+}
+
+// claimed reports whether a conditional write changed the lease row
+func claimed(result sql.Result) bool {
+	rows, err := result.RowsAffected()
+	return err == nil && rows > 0
+}
+
+// processAlive reports whether a process with pid exists on this host
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// Current returns the lease holder, nil when no daemon holds the lease
+func Current(db *sql.DB) (*Holder, error) {
+	holder := &Holder{}
+	err := db.QueryRow(
+		"SELECT owner, pid, hostname, acquired_at, heartbeat_at FROM daemon_lease WHERE id = 1",
+	).Scan(&holder.Owner, &holder.PID, &holder.Hostname, &holder.AcquiredAt, &holder.HeartbeatAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read database lease: %w", err)
+	}
+	return holder, nil
+}
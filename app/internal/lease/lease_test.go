@@ -0,0 +1,165 @@
+package lease
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/logging"
+	_ "modernc.org/sqlite"
+)
+
+func openTestDB(t *testing.T) *sql.DB {
+	database, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "clio.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	database.SetMaxOpenConns(1)
+	t.Cleanup(func() { database.Close() })
+	if err := db.RunMigrations(database); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	return database
+}
+
+func newTestLease(t *testing.T, database *sql.DB) *lease {
+	l, err := NewLease(database, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewLease() error = %v", err)
+	}
+	return l.(*lease)
+}
+
+func TestNewLease(t *testing.T) {
+	database := openTestDB(t)
+	if _, err := NewLease(nil, logging.NewNoopLogger()); err == nil {
+		t.Error("NewLease(nil, ...) expected error, got nil")
+	}
+	if _, err := NewLease(database, nil); err == nil {
+		t.Error("NewLease(..., nil) expected error, got nil")
+	}
+
+	a, b := newTestLease(t, database), newTestLease(t, database)
+	if a.owner == b.owner {
+		t.Errorf("leases share owner %q, want one per daemon run", a.owner)
+	}
+}
+
+func TestLease_SecondDaemonRefused(t *testing.T) {
+	database := openTestDB(t)
+	first, second := newTestLease(t, database), newTestLease(t, database)
+
+	if err := first.Acquire(); err != nil {
+		t.Fatalf("first Acquire() error = %v", err)
+	}
+	// Acquiring again is a no-op for the holder
+	if err := first.Acquire(); err != nil {
+		t.Fatalf("repeated Acquire() error = %v", err)
+	}
+
+	err := second.Acquire()
+	if !errors.Is(err, ErrHeld) {
+		t.Fatalf("second Acquire() error = %v, want ErrHeld", err)
+	}
+
+	holder, err := Current(database)
+	if err != nil {
+		t.Fatalf("Current() error = %v", err)
+	}
+	if holder == nil || holder.Owner != first.owner {
+		t.Errorf("Current() = %+v, want the first daemon", holder)
+	}
+}
+
+func TestLease_ReleaseLetsAnotherDaemonAcquire(t *testing.T) {
+	database := openTestDB(t)
+	first, second := newTestLease(t, database), newTestLease(t, database)
+
+	if err := first.Acquire(); err != nil {
+		t.Fatalf("first Acquire() error = %v", err)
+	}
+	if err := first.Release(); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if holder, err := Current(database); err != nil || holder != nil {
+		t.Fatalf("Current() = %+v, %v; want no holder", holder, err)
+	}
+	if err := second.Acquire(); err != nil {
+		t.Fatalf("second Acquire() error = %v", err)
+	}
+
+	// Releasing a lease held by someone else leaves it alone
+	if err := first.Release(); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if holder, _ := Current(database); holder == nil || holder.Owner != second.owner {
+		t.Errorf("Current() = %+v, want the second daemon", holder)
+	}
+}
+
+func TestLease_ExpiredLeaseTakenOver(t *testing.T) {
+	database := openTestDB(t)
+	first, second := newTestLease(t, database), newTestLease(t, database)
+	// Pretend the first daemon runs elsewhere so only its heartbeat decides
+	first.hostname = "other-host"
+
+	now := time.Now()
+	if err := first.acquire(now); err != nil {
+		t.Fatalf("first acquire() error = %v", err)
+	}
+	if err := second.acquire(now.Add(Expiry - time.Second)); !errors.Is(err, ErrHeld) {
+		t.Fatalf("acquire() before expiry error = %v, want ErrHeld", err)
+	}
+	if err := second.acquire(now.Add(Expiry)); err != nil {
+		t.Fatalf("acquire() after expiry error = %v", err)
+	}
+
+	// The first daemon learns it lost the lease on its next heartbeat
+	if err := first.heartbeat(now.Add(Expiry + time.Second)); !errors.Is(err, ErrLost) {
+		t.Errorf("heartbeat() error = %v, want ErrLost", err)
+	}
+	if err := second.heartbeat(now.Add(Expiry + time.Second)); err != nil {
+		t.Errorf("heartbeat() by holder error = %v", err)
+	}
+}
+
+func TestLease_HeartbeatKeepsLease(t *testing.T) {
+	database := openTestDB(t)
+	first, second := newTestLease(t, database), newTestLease(t, database)
+	first.hostname = "other-host"
+
+	now := time.Now()
+	if err := first.acquire(now); err != nil {
+		t.Fatalf("first acquire() error = %v", err)
+	}
+	if err := first.heartbeat(now.Add(Expiry / 2)); err != nil {
+		t.Fatalf("heartbeat() error = %v", err)
+	}
+	if err := second.acquire(now.Add(Expiry)); !errors.Is(err, ErrHeld) {
+		t.Errorf("acquire() after heartbeat error = %v, want ErrHeld", err)
+	}
+}
+
+func TestLease_DeadLocalHolderTakenOver(t *testing.T) {
+	database := openTestDB(t)
+	l := newTestLease(t, database)
+
+	// A daemon on this host that exited without releasing its lease
+	now := time.Now()
+	if _, err := database.Exec(`
+		INSERT INTO daemon_lease (id, owner, pid, hostname, acquired_at, heartbeat_at)
+		VALUES (1, 'crashed', ?, ?, ?, ?)
+	`, 1<<30, l.hostname, now, now); err != nil {
+		t.Fatalf("failed to insert lease: %v", err)
+	}
+
+	if err := l.acquire(now); err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+	if holder, _ := Current(database); holder == nil || holder.Owner != l.owner {
+		t.Errorf("Current() = %+v, want this daemon", holder)
+	}
+}
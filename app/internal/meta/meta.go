@@ -0,0 +1,292 @@
+// Package meta stores arbitrary key=value context on sessions and commits, such
+// as the ticket or customer they were for, set with clio meta and available to
+// filters and exporters.
+package meta
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+// EntityType is what metadata is attached to
+type EntityType string
+
+const (
+	// EntitySession attaches metadata to a session by its ID
+	EntitySession EntityType = "session"
+	// EntityCommit attaches metadata to a commit by its hash
+	EntityCommit EntityType = "commit"
+)
+
+// keyPattern keeps keys usable as template map keys and filter terms
+var keyPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]*$`)
+
+// Entry is one key=value pair on an entity
+type Entry struct {
+	EntityType EntityType
+	EntityID   string
+	Key        string
+	Value      string
+	UpdatedAt  time.Time
+}
+
+// Store defines the interface for setting and reading metadata
+type Store interface {
+	// Set sets keys on an existing session or commit, replacing their values
+	Set(entityType EntityType, entityID string, values map[string]string, at time.Time) error
+	// Unset removes keys from an entity and returns how many were removed
+	Unset(entityType EntityType, entityID string, keys []string) (int, error)
+	// Get returns an entity's metadata
+	Get(entityType EntityType, entityID string) (map[string]string, error)
+	// List returns every entry, or the entries with key when it's set, sorted by key, entity type, and entity ID
+	List(key string) ([]Entry, error)
+}
+
+// store implements Store on top of the clio database
+type store struct {
+	db     *sql.DB
+	logger logging.Logger
+}
+
+// NewStore creates a metadata store backed by the database
+func NewStore(db *sql.DB, logger logging.Logger) (Store, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database cannot be nil")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	return &store{
+		db:     db,
+		logger: logger.With("component", "meta"),
+	}, nil
+}
+
+// ParsePair parses a key=value argument. The key is lowercased; the value is
+// kept as written and may contain '='.
+func ParsePair(pair string) (string, string, error) {
+	key, value, ok := strings.Cut(pair, "=")
+	if !ok {
+		return "", "", fmt.Errorf("%q is not key=value", pair)
+	}
+	key = strings.ToLower(strings.TrimSpace(key))
+	if err := ValidateKey(key); err != nil {
+		return "", "", err
+	}
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", "", fmt.Errorf("%s: value cannot be empty (remove a key with clio meta unset)", key)
+	}
+	return key, value, nil
+}
+
+// ValidateKey checks that a key is a lowercase name usable in filters and templates
+func ValidateKey(key string) error {
+	if !keyPattern.MatchString(key) {
+		return fmt.Errorf("invalid key %q (use lowercase letters, digits, '.', '-' and '_')", key)
+	}
+	return nil
+}
+
+// Set sets keys on an existing session or commit
+func (s *store) Set(entityType EntityType, entityID string, values map[string]string, at time.Time) error {
+	if err := s.checkEntity(entityType, entityID); err != nil {
+		return err
+	}
+	for key := range values {
+		if err := ValidateKey(key); err != nil {
+			return err
+		}
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for key, value := range values {
+		_, err := tx.Exec(`
+			INSERT INTO entity_metadata (entity_type, entity_id, key, value, updated_at)
+			VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT(entity_type, entity_id, key) DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at
+		`, string(entityType), entityID, key, value, at)
+		if err != nil {
+			return fmt.Errorf("failed to store metadata: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit metadata: %w", err)
+	}
+
+	s.logger.Debug("set metadata", "entity_type", entityType, "entity_id", entityID, "keys", len(values))
+	return nil
+}
+
+// Unset removes keys from an entity
+func (s *store) Unset(entityType EntityType, entityID string, keys []string) (int, error) {
+	removed := 0
+	for _, key := range keys {
+		result, err := s.db.Exec("DELETE FROM entity_metadata WHERE entity_type = ? AND entity_id = ? AND key = ?",
+			string(entityType), entityID, strings.ToLower(key))
+		if err != nil {
+			return removed, fmt.Errorf("failed to remove metadata: %w", err)
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return removed, fmt.Errorf("failed to remove metadata: %w", err)
+		}
+		removed += int(n)
+	}
+	return removed, nil
+}
+
+// Get returns an entity's metadata
+func (s *store) Get(entityType EntityType, entityID string) (map[string]string, error) {
+	rows, err := s.db.Query("SELECT key, value FROM entity_metadata WHERE entity_type = ? AND entity_id = ?",
+		string(entityType), entityID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query metadata: %w", err)
+	}
+	defer rows.Close()
+
+	values := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan metadata: %w", err)
+		}
+		values[key] = value
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating metadata: %w", err)
+	}
+	return values, nil
+}
+
+// List returns every entry, or the entries with key
+func (s *store) List(key string) ([]Entry, error) {
+	query := "SELECT entity_type, entity_id, key, value, updated_at FROM entity_metadata"
+	var args []interface{}
+	if key != "" {
+		query += " WHERE key = ?"
+		args = append(args, strings.ToLower(key))
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query metadata: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []Entry
+	for rows.Next() {
+		var entry Entry
+		var entityType string
+		if err := rows.Scan(&entityType, &entry.EntityID, &entry.Key, &entry.Value, &entry.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan metadata: %w", err)
+		}
+		entry.EntityType = EntityType(entityType)
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating metadata: %w", err)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.Key != b.Key {
+			return a.Key < b.Key
+		}
+		if a.EntityType != b.EntityType {
+			return a.EntityType < b.EntityType
+		}
+		return a.EntityID < b.EntityID
+	})
+	return entries, nil
+}
+
+// checkEntity errors unless the session or commit exists
+func (s *store) checkEntity(entityType EntityType, entityID string) error {
+	var query string
+	switch entityType {
+	case EntitySession:
+		query = "SELECT COUNT(*) FROM sessions WHERE id = ?"
+	case EntityCommit:
+		query = "SELECT COUNT(*) FROM commits WHERE hash = ?"
+	default:
+		return fmt.Errorf("unknown entity type %q", entityType)
+	}
+
+	var count int
+	if err := s.db.QueryRow(query, entityID).Scan(&count); err != nil {
+		return fmt.Errorf("failed to look up %s: %w", entityType, err)
+	}
+	if count == 0 {
+		return fmt.Errorf("%s %s not found", entityType, entityID)
+	}
+	return nil
+}
+
+// All returns the metadata of every entity of a type, by entity ID
+func All(db *sql.DB, entityType EntityType) (map[string]map[string]string, error) {
+	rows, err := db.Query("SELECT entity_id, key, value FROM entity_metadata WHERE entity_type = ?", string(entityType))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query metadata: %w", err)
+	}
+	defer rows.Close()
+
+	all := make(map[string]map[string]string)
+	for rows.Next() {
+		var entityID, key, value string
+		if err := rows.Scan(&entityID, &key, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan metadata: %w", err)
+		}
+		if all[entityID] == nil {
+			all[entityID] = make(map[string]string)
+		}
+		all[entityID][key] = value
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating metadata: %w", err)
+	}
+	return all, nil
+}
+
+// Sessions returns the IDs of the sessions with key=value: sessions that carry
+// it and sessions with a correlated commit that carries it. Values match
+// case-insensitively.
+func Sessions(db *sql.DB, key, value string) (map[string]bool, error) {
+	rows, err := db.Query(`
+		SELECT m.entity_id FROM entity_metadata m
+		WHERE m.entity_type = ? AND m.key = ? AND m.value = ? COLLATE NOCASE
+		UNION
+		SELECT c.session_id FROM entity_metadata m
+		JOIN commits c ON c.hash = m.entity_id
+		WHERE m.entity_type = ? AND m.key = ? AND m.value = ? COLLATE NOCASE AND c.session_id IS NOT NULL
+	`, string(EntitySession), strings.ToLower(key), value, string(EntityCommit), strings.ToLower(key), value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query metadata sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		sessions[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sessions: %w", err)
+	}
+	return sessions, nil
+}
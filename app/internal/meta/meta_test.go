@@ -0,0 +1,126 @@
+package meta
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/logging"
+	_ "modernc.org/sqlite"
+)
+
+func setupTestStore(t *testing.T) (*sql.DB, Store) {
+	database, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "clio.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if err := db.RunMigrations(database); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	store, err := NewStore(database, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	return database, store
+}
+
+func TestParsePair(t *testing.T) {
+	tests := []struct {
+		pair      string
+		wantKey   string
+		wantValue string
+		wantErr   bool
+	}{
+		{pair: "ticket=ABC-12", wantKey: "ticket", wantValue: "ABC-12"},
+		{pair: " Customer = Acme Corp ", wantKey: "customer", wantValue: "Acme Corp"},
+		{pair: "query=a=b", wantKey: "query", wantValue: "a=b"},
+		{pair: "ticket", wantErr: true},
+		{pair: "ticket=", wantErr: true},
+		{pair: "=ABC-12", wantErr: true},
+		{pair: "my key=1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pair, func(t *testing.T) {
+			key, value, err := ParsePair(tt.pair)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePair(%q) error = %v, wantErr %v", tt.pair, err, tt.wantErr)
+			}
+			if !tt.wantErr && (key != tt.wantKey || value != tt.wantValue) {
+				t.Errorf("ParsePair(%q) = %q, %q, want %q, %q", tt.pair, key, value, tt.wantKey, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestStore_SetGetUnset(t *testing.T) {
+	database, store := setupTestStore(t)
+	base := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+
+	if _, err := database.Exec(`
+		INSERT INTO sessions (id, project, start_time, last_activity, created_at, updated_at)
+		VALUES ('s1', 'clio', ?, ?, ?, ?), ('s2', 'clio', ?, ?, ?, ?)
+	`, base, base, base, base, base, base, base, base); err != nil {
+		t.Fatalf("failed to create sessions: %v", err)
+	}
+	// The commit was correlated with s2
+	if _, err := database.Exec(`
+		INSERT INTO commits (id, session_id, repository_path, repository_name, hash, message,
+			author_name, author_email, timestamp, branch, created_at, updated_at)
+		VALUES ('abc123', 's2', '/src/clio', 'clio', 'abc123', 'Fix', 'Dev', 'dev@example.com', ?, 'main', ?, ?)
+	`, base, base, base); err != nil {
+		t.Fatalf("failed to create commits: %v", err)
+	}
+
+	if err := store.Set(EntitySession, "s1", map[string]string{"customer": "acme", "ticket": "CLI-1"}, base); err != nil {
+		t.Fatalf("Set(session) error = %v", err)
+	}
+	if err := store.Set(EntitySession, "s1", map[string]string{"ticket": "CLI-2"}, base.Add(time.Hour)); err != nil {
+		t.Fatalf("Set(session) again error = %v", err)
+	}
+	if err := store.Set(EntityCommit, "abc123", map[string]string{"customer": "Acme"}, base); err != nil {
+		t.Fatalf("Set(commit) error = %v", err)
+	}
+	if err := store.Set(EntitySession, "missing", map[string]string{"customer": "acme"}, base); err == nil {
+		t.Error("Set() on a missing session should fail")
+	}
+	if err := store.Set(EntityType("file"), "s1", map[string]string{"customer": "acme"}, base); err == nil {
+		t.Error("Set() on an unknown entity type should fail")
+	}
+	if err := store.Set(EntitySession, "s1", map[string]string{"Bad Key": "x"}, base); err == nil {
+		t.Error("Set() with an invalid key should fail")
+	}
+
+	values, err := store.Get(EntitySession, "s1")
+	if err != nil || len(values) != 2 || values["ticket"] != "CLI-2" || values["customer"] != "acme" {
+		t.Errorf("Get() = %v, %v, want customer and the replaced ticket", values, err)
+	}
+
+	entries, err := store.List("customer")
+	if err != nil || len(entries) != 2 || entries[0].EntityType != EntityCommit || entries[1].EntityID != "s1" {
+		t.Errorf("List(customer) = %+v, %v, want the commit then s1", entries, err)
+	}
+
+	// s1 carries the value itself, s2 through its commit; values match case-insensitively
+	sessions, err := Sessions(database, "customer", "ACME")
+	if err != nil || len(sessions) != 2 || !sessions["s1"] || !sessions["s2"] {
+		t.Errorf("Sessions() = %v, %v, want s1 and s2", sessions, err)
+	}
+
+	all, err := All(database, EntityCommit)
+	if err != nil || all["abc123"]["customer"] != "Acme" {
+		t.Errorf("All(commit) = %v, %v", all, err)
+	}
+
+	removed, err := store.Unset(EntitySession, "s1", []string{"TICKET", "missing"})
+	if err != nil || removed != 1 {
+		t.Errorf("Unset() = %d, %v, want 1", removed, err)
+	}
+	if entries, err = store.List(""); err != nil || len(entries) != 2 {
+		t.Errorf("List() after Unset() = %+v, %v", entries, err)
+	}
+}
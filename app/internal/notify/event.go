@@ -0,0 +1,159 @@
+package notify
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// EventSessionEnded is emitted when a Cursor session ends
+	EventSessionEnded = "session.ended"
+	// EventCommitCaptured is emitted when a commit has been stored
+	EventCommitCaptured = "commit.captured"
+	// EventReminderDue is emitted once when a reminder set with clio remind comes due
+	EventReminderDue = "reminder.due"
+	// EventAlertMatched is emitted when a configured alert matches a newly captured message or diff
+	EventAlertMatched = "alert.matched"
+	// EventSearchMatched is emitted when a saved search subscription matches a newly captured message
+	EventSearchMatched = "search.matched"
+	// EventHabitNudge is emitted when recent sessions suggest a working habit worth changing
+	EventHabitNudge = "habit.nudge"
+)
+
+// EventTypes lists every event type that can be subscribed to
+var EventTypes = []string{EventSessionEnded, EventCommitCaptured, EventReminderDue, EventAlertMatched, EventSearchMatched, EventHabitNudge}
+
+// Event is a notification delivered to external automation
+type Event struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+	Data      any       `json:"data"`
+}
+
+// NewEvent creates an event of the given type with a unique ID
+func NewEvent(eventType string, data any) Event {
+	return Event{
+		ID:        uuid.New().String(),
+		Type:      eventType,
+		Timestamp: time.Now().UTC(),
+		Data:      data,
+	}
+}
+
+// SessionEnded is the payload of EventSessionEnded
+type SessionEnded struct {
+	SessionID         string    `json:"session_id"`
+	Project           string    `json:"project"`
+	StartTime         time.Time `json:"start_time"`
+	EndTime           time.Time `json:"end_time"`
+	ConversationCount int       `json:"conversation_count"`
+}
+
+// CommitCaptured is the payload of EventCommitCaptured
+type CommitCaptured struct {
+	Hash            string    `json:"hash"`
+	RepositoryPath  string    `json:"repository_path"`
+	RepositoryName  string    `json:"repository_name"`
+	Branch          string    `json:"branch"`
+	Message         string    `json:"message"`
+	Author          string    `json:"author"`
+	Timestamp       time.Time `json:"timestamp"`
+	SessionID       string    `json:"session_id,omitempty"`
+	CorrelationType string    `json:"correlation_type,omitempty"`
+	Confidence      float64   `json:"confidence,omitempty"`
+}
+
+// ReminderDue is the payload of EventReminderDue. The session fields point back
+// to where the reminder was set and are empty when it wasn't set in a session.
+type ReminderDue struct {
+	ID           int64     `json:"id"`
+	Text         string    `json:"text"`
+	DueAt        time.Time `json:"due_at"`
+	CreatedAt    time.Time `json:"created_at"`
+	SessionID    string    `json:"session_id,omitempty"`
+	Project      string    `json:"project,omitempty"`
+	SessionStart time.Time `json:"session_start,omitzero"`
+}
+
+// AlertMatched is the payload of EventAlertMatched. Source is "messages" or
+// "diffs"; composer_id and role are set for messages, and commit_hash,
+// repository, and file for diffs.
+type AlertMatched struct {
+	Alert      string    `json:"alert"`
+	Source     string    `json:"source"`
+	Excerpt    string    `json:"excerpt"`
+	Timestamp  time.Time `json:"timestamp"`
+	SessionID  string    `json:"session_id,omitempty"`
+	Project    string    `json:"project,omitempty"`
+	ComposerID string    `json:"composer_id,omitempty"`
+	Role       string    `json:"role,omitempty"`
+	CommitHash string    `json:"commit_hash,omitempty"`
+	Repository string    `json:"repository,omitempty"`
+	File       string    `json:"file,omitempty"`
+}
+
+// SearchMatched is the payload of EventSearchMatched. Excerpt is the first line
+// of the message containing a query term.
+type SearchMatched struct {
+	Subscription     string    `json:"subscription"`
+	Query            string    `json:"query"`
+	Excerpt          string    `json:"excerpt"`
+	Timestamp        time.Time `json:"timestamp"`
+	MessageID        string    `json:"message_id"`
+	SessionID        string    `json:"session_id"`
+	Project          string    `json:"project,omitempty"`
+	ConversationName string    `json:"conversation_name,omitempty"`
+	ComposerID       string    `json:"composer_id"`
+	Role             string    `json:"role"`
+}
+
+// HabitNudge is the payload of EventHabitNudge. Kind names the heuristic that
+// raised it; session_id is the session it was raised for.
+type HabitNudge struct {
+	Kind      string `json:"kind"`
+	Message   string `json:"message"`
+	Project   string `json:"project"`
+	SessionID string `json:"session_id,omitempty"`
+}
+
+// Notifier delivers events to external automation
+type Notifier interface {
+	Notify(event Event)
+	Stop() error
+}
+
+// multiNotifier fans events out to several notifiers
+type multiNotifier struct {
+	notifiers []Notifier
+}
+
+// NewMultiNotifier combines notifiers into one, skipping nil entries
+func NewMultiNotifier(notifiers ...Notifier) Notifier {
+	var active []Notifier
+	for _, n := range notifiers {
+		if n != nil {
+			active = append(active, n)
+		}
+	}
+	return &multiNotifier{notifiers: active}
+}
+
+// Notify delivers the event to every notifier
+func (m *multiNotifier) Notify(event Event) {
+	for _, n := range m.notifiers {
+		n.Notify(event)
+	}
+}
+
+// Stop stops every notifier, returning the first error
+func (m *multiNotifier) Stop() error {
+	var firstErr error
+	for _, n := range m.notifiers {
+		if err := n.Stop(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
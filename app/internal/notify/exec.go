@@ -0,0 +1,211 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+const (
+	// hookQueueSize is the number of events waiting for a hook slot before new ones are dropped
+	hookQueueSize = 100
+	// hookDrainTimeout bounds how long Stop waits for queued hooks to run
+	hookDrainTimeout = 5 * time.Second
+	// maxHookOutput caps the hook output kept for failure logging
+	maxHookOutput = 4096
+	// hookWaitDelay bounds how long a killed hook's output pipes are drained (e.g. held by child processes)
+	hookWaitDelay = time.Second
+
+	// HookEventEnv is the environment variable holding the event type
+	HookEventEnv = "CLIO_EVENT"
+)
+
+// hookRun is an event queued for a hook executable
+type hookRun struct {
+	path  string
+	event Event
+}
+
+// execNotifier runs configured executables for events, passing the event JSON on stdin
+type execNotifier struct {
+	hooks   map[string]string // Event type -> executable path
+	timeout time.Duration
+	logger  logging.Logger
+	queue   chan hookRun
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	mu      sync.RWMutex // Guards stopped against sends on the closed queue
+	stopped bool
+}
+
+// NewExecNotifier creates a notifier for the hooks in cfg and starts hooks.max_concurrency workers.
+// Returns nil if no hooks are configured.
+func NewExecNotifier(cfg *config.Config, logger logging.Logger) (Notifier, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	hooks := make(map[string]string)
+	for eventType, path := range map[string]string{
+		EventSessionEnded:   cfg.Hooks.OnSessionEnd,
+		EventCommitCaptured: cfg.Hooks.OnCommitCaptured,
+		EventReminderDue:    cfg.Hooks.OnReminderDue,
+		EventAlertMatched:   cfg.Hooks.OnAlertMatched,
+		EventSearchMatched:  cfg.Hooks.OnSearchMatched,
+		EventHabitNudge:     cfg.Hooks.OnHabitNudge,
+	} {
+		if path != "" {
+			hooks[eventType] = path
+		}
+	}
+	if len(hooks) == 0 {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	en := &execNotifier{
+		hooks:   hooks,
+		timeout: time.Duration(max(cfg.Hooks.TimeoutSeconds, 1)) * time.Second,
+		logger:  logger.With("component", "hooks"),
+		queue:   make(chan hookRun, hookQueueSize),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+
+	workers := max(cfg.Hooks.MaxConcurrency, 1)
+	for i := 0; i < workers; i++ {
+		en.wg.Add(1)
+		go en.worker()
+	}
+
+	en.logger.Info("hooks enabled", "count", len(hooks), "max_concurrency", workers)
+	return en, nil
+}
+
+// Notify queues the event's hook, if one is configured (non-blocking)
+func (en *execNotifier) Notify(event Event) {
+	path, ok := en.hooks[event.Type]
+	if !ok {
+		return
+	}
+
+	en.mu.RLock()
+	defer en.mu.RUnlock()
+
+	if en.stopped {
+		en.logger.Debug("hooks stopped, dropping event", "event", event.Type, "id", event.ID)
+		return
+	}
+
+	select {
+	case en.queue <- hookRun{path: path, event: event}:
+	default:
+		en.logger.Warn("hook queue full, dropping event", "event", event.Type, "hook", path)
+	}
+}
+
+// Stop runs queued hooks and stops the workers. Hooks still queued or running
+// after hookDrainTimeout are killed.
+func (en *execNotifier) Stop() error {
+	en.mu.Lock()
+	if en.stopped {
+		en.mu.Unlock()
+		return nil
+	}
+	en.stopped = true
+	close(en.queue)
+	en.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		en.wg.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+	case <-time.After(hookDrainTimeout):
+		en.logger.Warn("hooks still running at shutdown, killing them", "queued", len(en.queue))
+		en.cancel()
+		<-drained
+	}
+	en.cancel()
+	return nil
+}
+
+// worker runs queued hooks until the queue is closed
+func (en *execNotifier) worker() {
+	defer en.wg.Done()
+
+	for run := range en.queue {
+		start := time.Now()
+		if err := en.run(run); err != nil {
+			en.logger.Warn("hook failed", "hook", run.path, "event", run.event.Type, "id", run.event.ID, "duration_ms", time.Since(start).Milliseconds(), "error", err)
+			continue
+		}
+		en.logger.Debug("hook completed", "hook", run.path, "event", run.event.Type, "duration_ms", time.Since(start).Milliseconds())
+	}
+}
+
+// run executes one hook with the event JSON on stdin
+func (en *execNotifier) run(run hookRun) error {
+	payload, err := json.Marshal(run.event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(en.ctx, en.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, run.path)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Env = append(os.Environ(), HookEventEnv+"="+run.event.Type)
+	output := &limitedBuffer{limit: maxHookOutput}
+	cmd.Stdout = output
+	cmd.Stderr = output
+	cmd.WaitDelay = hookWaitDelay
+
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("timed out after %v: %w (output: %q)", en.timeout, err, output.String())
+		}
+		return fmt.Errorf("%w (output: %q)", err, output.String())
+	}
+	return nil
+}
+
+// limitedBuffer keeps the first limit bytes written and discards the rest
+type limitedBuffer struct {
+	buf   bytes.Buffer
+	limit int
+}
+
+// Write implements io.Writer, always reporting the full length so the hook isn't interrupted
+func (lb *limitedBuffer) Write(p []byte) (int, error) {
+	if remaining := lb.limit - lb.buf.Len(); remaining > 0 {
+		if len(p) > remaining {
+			lb.buf.Write(p[:remaining])
+		} else {
+			lb.buf.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+// String returns the kept output
+func (lb *limitedBuffer) String() string {
+	return lb.buf.String()
+}
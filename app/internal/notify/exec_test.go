@@ -0,0 +1,79 @@
+package notify
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+// writeHook writes an executable shell script
+func writeHook(t *testing.T, dir, name, script string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0755); err != nil {
+		t.Fatalf("failed to write hook: %v", err)
+	}
+	return path
+}
+
+func TestExecNotifier_RunsHookWithEventOnStdin(t *testing.T) {
+	dir := t.TempDir()
+	outPath := filepath.Join(dir, "event.json")
+	hook := writeHook(t, dir, "on-commit.sh", `echo "$CLIO_EVENT" > "`+outPath+`.type"; cat > "`+outPath+`"`)
+
+	cfg := &config.Config{Hooks: config.HooksConfig{OnCommitCaptured: hook, TimeoutSeconds: 5, MaxConcurrency: 1}}
+	notifier, err := NewExecNotifier(cfg, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewExecNotifier() error = %v", err)
+	}
+
+	notifier.Notify(NewEvent(EventSessionEnded, SessionEnded{SessionID: "ignored"}))
+	notifier.Notify(NewEvent(EventCommitCaptured, CommitCaptured{Hash: "abc123"}))
+	notifier.Stop()
+
+	payload, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("hook did not run: %v", err)
+	}
+	if !strings.Contains(string(payload), `"hash":"abc123"`) {
+		t.Errorf("hook stdin = %s, want the commit event", payload)
+	}
+	eventType, _ := os.ReadFile(outPath + ".type")
+	if strings.TrimSpace(string(eventType)) != EventCommitCaptured {
+		t.Errorf("%s = %q, want %q", HookEventEnv, eventType, EventCommitCaptured)
+	}
+}
+
+func TestExecNotifier_TimesOutAndReportsFailures(t *testing.T) {
+	dir := t.TempDir()
+	slow := writeHook(t, dir, "slow.sh", "sleep 10")
+	failing := writeHook(t, dir, "failing.sh", "echo boom >&2; exit 3")
+
+	en := &execNotifier{timeout: 100 * time.Millisecond, logger: logging.NewNoopLogger(), ctx: t.Context()}
+
+	start := time.Now()
+	if err := en.run(hookRun{path: slow, event: NewEvent(EventSessionEnded, nil)}); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("slow hook error = %v, want timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("slow hook ran for %v, want it killed at the timeout", elapsed)
+	}
+
+	err := en.run(hookRun{path: failing, event: NewEvent(EventSessionEnded, nil)})
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("failing hook error = %v, want exit error with output", err)
+	}
+}
+
+func TestNewExecNotifier_NoHooks(t *testing.T) {
+	notifier, err := NewExecNotifier(&config.Config{}, logging.NewNoopLogger())
+	if err != nil || notifier != nil {
+		t.Errorf("NewExecNotifier() = %v, %v; want nil, nil without hooks", notifier, err)
+	}
+}
@@ -0,0 +1,219 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/logging"
+	"github.com/stwalsh4118/clio/internal/secrets"
+)
+
+const (
+	// webhookQueueSize is the number of events waiting for delivery before new ones are dropped
+	webhookQueueSize = 100
+	// webhookTimeout bounds a single delivery attempt
+	webhookTimeout = 10 * time.Second
+	// webhookMaxAttempts is how many times a delivery is attempted
+	webhookMaxAttempts = 3
+	// webhookRetryDelay is the initial delay between delivery attempts (doubles each retry)
+	webhookRetryDelay = time.Second
+	// webhookDrainTimeout bounds how long Stop waits for queued events to be delivered
+	webhookDrainTimeout = 5 * time.Second
+
+	// SignatureHeader carries the hex HMAC-SHA256 of the body, prefixed with "sha256="
+	SignatureHeader = "X-Clio-Signature"
+	// EventHeader carries the event type
+	EventHeader = "X-Clio-Event"
+	// DeliveryHeader carries the event ID, stable across retries
+	DeliveryHeader = "X-Clio-Delivery"
+)
+
+// webhookNotifier delivers events to configured webhook URLs in the background
+type webhookNotifier struct {
+	webhooks   []config.WebhookConfig
+	client     *http.Client
+	logger     logging.Logger
+	queue      chan Event
+	retryDelay time.Duration
+	ctx        context.Context
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+	mu         sync.RWMutex // Guards stopped against sends on the closed queue
+	stopped    bool
+}
+
+// NewWebhookNotifier creates a notifier for the webhooks in cfg and starts its delivery worker.
+// Returns nil if no webhooks are configured.
+func NewWebhookNotifier(cfg *config.Config, logger logging.Logger) (Notifier, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+	if len(cfg.Webhooks) == 0 {
+		return nil, nil
+	}
+
+	// Resolve secret references once so deliveries don't hit the keychain
+	webhooks := make([]config.WebhookConfig, len(cfg.Webhooks))
+	for i, webhook := range cfg.Webhooks {
+		var err error
+		if webhook.URL, err = secrets.Resolve(webhook.URL); err != nil {
+			return nil, fmt.Errorf("webhook %d url: %w", i+1, err)
+		}
+		if webhook.Secret, err = secrets.Resolve(webhook.Secret); err != nil {
+			return nil, fmt.Errorf("webhook %d secret: %w", i+1, err)
+		}
+		webhooks[i] = webhook
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wn := &webhookNotifier{
+		webhooks:   webhooks,
+		client:     &http.Client{Timeout: webhookTimeout},
+		logger:     logger.With("component", "webhooks"),
+		queue:      make(chan Event, webhookQueueSize),
+		retryDelay: webhookRetryDelay,
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+
+	wn.wg.Add(1)
+	go wn.deliverLoop()
+
+	wn.logger.Info("webhooks enabled", "count", len(cfg.Webhooks))
+	return wn, nil
+}
+
+// Notify queues an event for delivery (non-blocking)
+func (wn *webhookNotifier) Notify(event Event) {
+	wn.mu.RLock()
+	defer wn.mu.RUnlock()
+
+	if wn.stopped {
+		wn.logger.Debug("webhooks stopped, dropping event", "event", event.Type, "id", event.ID)
+		return
+	}
+
+	select {
+	case wn.queue <- event:
+	default:
+		wn.logger.Warn("webhook queue full, dropping event", "event", event.Type, "id", event.ID)
+	}
+}
+
+// Stop delivers queued events and stops the worker. Deliveries still pending
+// after webhookDrainTimeout are abandoned.
+func (wn *webhookNotifier) Stop() error {
+	wn.mu.Lock()
+	if wn.stopped {
+		wn.mu.Unlock()
+		return nil
+	}
+	wn.stopped = true
+	close(wn.queue)
+	wn.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		wn.wg.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+	case <-time.After(webhookDrainTimeout):
+		wn.logger.Warn("webhook deliveries still pending at shutdown, abandoning them", "queued", len(wn.queue))
+		wn.cancel()
+		<-drained
+	}
+	wn.cancel()
+	return nil
+}
+
+// deliverLoop delivers queued events until the queue is closed
+func (wn *webhookNotifier) deliverLoop() {
+	defer wn.wg.Done()
+
+	for event := range wn.queue {
+		body, err := json.Marshal(event)
+		if err != nil {
+			wn.logger.Error("failed to marshal webhook event", "event", event.Type, "error", err)
+			continue
+		}
+
+		for _, webhook := range wn.webhooks {
+			if !slices.Contains(webhook.Events, event.Type) {
+				continue
+			}
+			if err := wn.deliver(webhook, event, body); err != nil {
+				wn.logger.Warn("webhook delivery failed", "url", webhook.URL, "event", event.Type, "id", event.ID, "error", err)
+			}
+		}
+	}
+}
+
+// deliver posts body to a webhook, retrying failed attempts with exponential backoff
+func (wn *webhookNotifier) deliver(webhook config.WebhookConfig, event Event, body []byte) error {
+	var lastErr error
+	for attempt := 0; attempt < webhookMaxAttempts; attempt++ {
+		if attempt > 0 {
+			delay := wn.retryDelay * time.Duration(1<<uint(attempt-1))
+			select {
+			case <-wn.ctx.Done():
+				return fmt.Errorf("delivery abandoned: %w", lastErr)
+			case <-time.After(delay):
+			}
+		}
+
+		if lastErr = wn.post(webhook, event, body); lastErr == nil {
+			wn.logger.Debug("delivered webhook", "url", webhook.URL, "event", event.Type, "id", event.ID, "attempts", attempt+1)
+			return nil
+		}
+	}
+	return lastErr
+}
+
+// post sends one delivery attempt
+func (wn *webhookNotifier) post(webhook config.WebhookConfig, event Event, body []byte) error {
+	req, err := http.NewRequestWithContext(wn.ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, event.Type)
+	req.Header.Set(DeliveryHeader, event.ID)
+	if webhook.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(webhook.Secret, body))
+	}
+
+	resp, err := wn.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	return nil
+}
+
+// Sign returns the signature header value for body: "sha256=" followed by the
+// hex HMAC-SHA256 of body keyed with secret
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
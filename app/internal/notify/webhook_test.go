@@ -0,0 +1,96 @@
+package notify
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+// recordingServer records webhook requests, failing the first failures of them
+type recordingServer struct {
+	mu       sync.Mutex
+	failures int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (rs *recordingServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.requests = append(rs.requests, r)
+	rs.bodies = append(rs.bodies, body)
+	if len(rs.requests) <= rs.failures {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func TestWebhookNotifier_DeliversSubscribedEvents(t *testing.T) {
+	commits := &recordingServer{failures: 1}
+	sessions := &recordingServer{}
+	commitServer := httptest.NewServer(commits)
+	defer commitServer.Close()
+	sessionServer := httptest.NewServer(sessions)
+	defer sessionServer.Close()
+
+	cfg := &config.Config{Webhooks: []config.WebhookConfig{
+		{URL: commitServer.URL, Events: []string{EventCommitCaptured}, Secret: "s3cret"},
+		{URL: sessionServer.URL, Events: []string{EventSessionEnded}},
+	}}
+	notifier, err := NewWebhookNotifier(cfg, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewWebhookNotifier() error = %v", err)
+	}
+	notifier.(*webhookNotifier).retryDelay = time.Millisecond
+
+	event := NewEvent(EventCommitCaptured, CommitCaptured{Hash: "abc123", RepositoryName: "clio"})
+	notifier.Notify(event)
+	notifier.Stop()
+
+	// The first attempt fails and is retried
+	if len(commits.requests) != 2 {
+		t.Fatalf("commit webhook requests = %d, want 2", len(commits.requests))
+	}
+	if len(sessions.requests) != 0 {
+		t.Errorf("session webhook received %d unsubscribed events", len(sessions.requests))
+	}
+
+	req, body := commits.requests[1], commits.bodies[1]
+	if got := req.Header.Get(SignatureHeader); got != Sign("s3cret", body) {
+		t.Errorf("signature = %q, want HMAC of body", got)
+	}
+	if req.Header.Get(EventHeader) != EventCommitCaptured || req.Header.Get(DeliveryHeader) != event.ID {
+		t.Errorf("headers = %v, want event type and delivery ID", req.Header)
+	}
+
+	var delivered struct {
+		Type string         `json:"type"`
+		Data CommitCaptured `json:"data"`
+	}
+	if err := json.Unmarshal(body, &delivered); err != nil {
+		t.Fatalf("failed to decode body: %v", err)
+	}
+	if delivered.Type != EventCommitCaptured || delivered.Data.Hash != "abc123" {
+		t.Errorf("delivered = %+v, want the commit event", delivered)
+	}
+
+	// Events after Stop are dropped rather than panicking
+	notifier.Notify(event)
+}
+
+func TestNewWebhookNotifier_NoWebhooks(t *testing.T) {
+	notifier, err := NewWebhookNotifier(&config.Config{}, logging.NewNoopLogger())
+	if err != nil || notifier != nil {
+		t.Errorf("NewWebhookNotifier() = %v, %v; want nil, nil without webhooks", notifier, err)
+	}
+}
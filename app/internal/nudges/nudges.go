@@ -0,0 +1,278 @@
+// Package nudges raises habit nudges from recent sessions, such as "no commits
+// correlated with the last 3 sessions of clio — consider committing more
+// often". Each nudge is recorded, so a condition is nudged once and nudges of
+// one kind in one project are spaced out by a cooldown.
+package nudges
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/logging"
+	"github.com/stwalsh4118/clio/internal/recap"
+)
+
+// Kinds of nudge, one per heuristic
+const (
+	// KindUncommittedSessions is raised when a project's latest sessions have no correlated commits
+	KindUncommittedSessions = "uncommitted_sessions"
+	// KindLongSession is raised when an active session goes a long time without a commit
+	KindLongSession = "long_session"
+)
+
+const (
+	// recentWindow is how recently the latest of an uncommitted run of sessions
+	// must have ended, so enabling nudges doesn't raise them for old history
+	recentWindow = 24 * time.Hour
+	// activeWindow is how recently an open session must have seen activity to
+	// count as active; sessions left open while the daemon was stopped don't
+	activeWindow = 30 * time.Minute
+)
+
+// Nudge is a suggestion raised by one of the heuristics
+type Nudge struct {
+	Kind      string
+	Project   string
+	SessionID string // The session the nudge was raised for
+	Key       string // Identifies the condition, so it is only nudged once
+	Message   string
+}
+
+// Checker defines the interface for raising nudges
+type Checker interface {
+	// Check runs the enabled heuristics over recent sessions and returns the
+	// nudges not raised before or held back by the cooldown, recording them
+	Check(now time.Time) ([]Nudge, error)
+}
+
+// session is a stored session with its latest correlated commit
+type session struct {
+	id           string
+	project      string
+	start        time.Time
+	end          time.Time // Zero while the session is open
+	lastActivity time.Time
+	lastCommit   time.Time // Zero without correlated commits
+}
+
+// checker implements Checker on top of the clio database
+type checker struct {
+	db     *sql.DB
+	cfg    config.NudgesConfig
+	logger logging.Logger
+}
+
+// NewChecker creates a nudge checker backed by the database
+func NewChecker(db *sql.DB, cfg config.NudgesConfig, logger logging.Logger) (Checker, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database cannot be nil")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	return &checker{
+		db:     db,
+		cfg:    cfg,
+		logger: logger.With("component", "nudges"),
+	}, nil
+}
+
+// Check runs the heuristics and records the nudges they raise
+func (c *checker) Check(now time.Time) ([]Nudge, error) {
+	if c.cfg.UncommittedSessions <= 0 && c.cfg.LongSessionMinutes <= 0 {
+		return nil, nil
+	}
+
+	sessions, err := c.sessions()
+	if err != nil {
+		return nil, err
+	}
+	candidates := append(c.uncommitted(sessions, now), c.longSessions(sessions, now)...)
+
+	var nudges []Nudge
+	for _, nudge := range candidates {
+		due, err := c.due(nudge, now)
+		if err != nil {
+			return nudges, err
+		}
+		if !due {
+			continue
+		}
+		if _, err := c.db.Exec(`
+			INSERT INTO nudges (kind, project, trigger_key, message, sent_at)
+			VALUES (?, ?, ?, ?, ?)
+		`, nudge.Kind, nudge.Project, nudge.Key, nudge.Message, now); err != nil {
+			return nudges, fmt.Errorf("failed to record nudge: %w", err)
+		}
+		c.logger.Debug("raised nudge", "kind", nudge.Kind, "project", nudge.Project, "key", nudge.Key)
+		nudges = append(nudges, nudge)
+	}
+	return nudges, nil
+}
+
+// uncommitted nudges each project whose latest sessions, ending recently, have no correlated commits
+func (c *checker) uncommitted(sessions []session, now time.Time) []Nudge {
+	count := c.cfg.UncommittedSessions
+	if count <= 0 {
+		return nil
+	}
+
+	byProject := make(map[string][]session)
+	for _, s := range sessions {
+		if s.project != "" && !s.end.IsZero() {
+			byProject[s.project] = append(byProject[s.project], s)
+		}
+	}
+
+	var nudges []Nudge
+	for project, ended := range byProject {
+		if len(ended) < count {
+			continue
+		}
+		sort.Slice(ended, func(i, j int) bool { return ended[i].start.After(ended[j].start) })
+		latest := ended[:count]
+		if now.Sub(latest[0].end) > recentWindow {
+			continue
+		}
+		committed := false
+		for _, s := range latest {
+			if !s.lastCommit.IsZero() {
+				committed = true
+				break
+			}
+		}
+		if committed {
+			continue
+		}
+		nudges = append(nudges, Nudge{
+			Kind:      KindUncommittedSessions,
+			Project:   project,
+			SessionID: latest[0].id,
+			Key:       latest[0].id,
+			Message:   fmt.Sprintf("No commits correlated with the last %d sessions of %s — consider committing more often", count, project),
+		})
+	}
+	sort.Slice(nudges, func(i, j int) bool { return nudges[i].Project < nudges[j].Project })
+	return nudges
+}
+
+// longSessions nudges each active session that has gone the configured time without a commit
+func (c *checker) longSessions(sessions []session, now time.Time) []Nudge {
+	if c.cfg.LongSessionMinutes <= 0 {
+		return nil
+	}
+	limit := time.Duration(c.cfg.LongSessionMinutes) * time.Minute
+
+	var nudges []Nudge
+	for _, s := range sessions {
+		if !s.end.IsZero() || now.Sub(s.lastActivity) > activeWindow {
+			continue
+		}
+		since := s.start
+		if s.lastCommit.After(since) {
+			since = s.lastCommit
+		}
+		if now.Sub(since) < limit {
+			continue
+		}
+		project := s.project
+		if project == "" {
+			project = "this session"
+		}
+		message := fmt.Sprintf("%s without a commit in %s — consider committing what works so far", recap.FormatDuration(now.Sub(since)), project)
+		nudges = append(nudges, Nudge{
+			Kind:      KindLongSession,
+			Project:   s.project,
+			SessionID: s.id,
+			// Keyed by the last commit too, so a session can be nudged again after committing
+			Key:     s.id + "@" + since.UTC().Format(time.RFC3339),
+			Message: message,
+		})
+	}
+	sort.Slice(nudges, func(i, j int) bool { return nudges[i].SessionID < nudges[j].SessionID })
+	return nudges
+}
+
+// due reports whether a nudge hasn't been raised before and is past the cooldown
+func (c *checker) due(nudge Nudge, now time.Time) (bool, error) {
+	rows, err := c.db.Query(`
+		SELECT trigger_key, sent_at FROM nudges WHERE kind = ? AND project = ?
+	`, nudge.Kind, nudge.Project)
+	if err != nil {
+		return false, fmt.Errorf("failed to query nudges: %w", err)
+	}
+	defer rows.Close()
+
+	cooldown := time.Duration(c.cfg.CooldownHours) * time.Hour
+	due := true
+	for rows.Next() {
+		var key string
+		var sentAt time.Time
+		if err := rows.Scan(&key, &sentAt); err != nil {
+			return false, fmt.Errorf("failed to scan nudge: %w", err)
+		}
+		if key == nudge.Key || now.Sub(sentAt) < cooldown {
+			due = false
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return false, fmt.Errorf("error iterating nudges: %w", err)
+	}
+	return due, nil
+}
+
+// sessions returns the stored sessions with their latest correlated commits
+func (c *checker) sessions() ([]session, error) {
+	rows, err := c.db.Query(`
+		SELECT id, COALESCE(project, ''), start_time, end_time, last_activity
+		FROM sessions
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []session
+	index := make(map[string]int)
+	for rows.Next() {
+		var s session
+		var end sql.NullTime
+		if err := rows.Scan(&s.id, &s.project, &s.start, &end, &s.lastActivity); err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		s.end = end.Time
+		index[s.id] = len(sessions)
+		sessions = append(sessions, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sessions: %w", err)
+	}
+	rows.Close()
+
+	commits, err := c.db.Query(`
+		SELECT session_id, timestamp FROM commits WHERE session_id IS NOT NULL AND session_id != ''
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query commits: %w", err)
+	}
+	defer commits.Close()
+
+	for commits.Next() {
+		var sessionID string
+		var timestamp time.Time
+		if err := commits.Scan(&sessionID, &timestamp); err != nil {
+			return nil, fmt.Errorf("failed to scan commit: %w", err)
+		}
+		if i, ok := index[sessionID]; ok && timestamp.After(sessions[i].lastCommit) {
+			sessions[i].lastCommit = timestamp
+		}
+	}
+	if err := commits.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating commits: %w", err)
+	}
+	return sessions, nil
+}
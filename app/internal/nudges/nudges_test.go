@@ -0,0 +1,131 @@
+package nudges
+
+import (
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/logging"
+	_ "modernc.org/sqlite"
+)
+
+func setupTestDB(t *testing.T) *sql.DB {
+	database, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "clio.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if err := db.RunMigrations(database); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+	return database
+}
+
+func insertSession(t *testing.T, database *sql.DB, id, project string, start time.Time, end interface{}, lastActivity time.Time) {
+	if _, err := database.Exec(`
+		INSERT INTO sessions (id, project, start_time, end_time, last_activity, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, id, project, start, end, lastActivity, start, start); err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+}
+
+func insertCommit(t *testing.T, database *sql.DB, hash, sessionID string, at time.Time) {
+	if _, err := database.Exec(`
+		INSERT INTO commits (id, session_id, repository_path, repository_name, hash, message,
+			author_name, author_email, timestamp, branch, created_at, updated_at)
+		VALUES (?, ?, '/home/user/clio', 'clio', ?, 'feat: work', 'Test User', 'test@example.com', ?, 'main', ?, ?)
+	`, hash, sessionID, hash, at, at, at); err != nil {
+		t.Fatalf("failed to create commit: %v", err)
+	}
+}
+
+func TestChecker_UncommittedSessions(t *testing.T) {
+	database := setupTestDB(t)
+	now := time.Date(2024, 5, 10, 18, 0, 0, 0, time.Local)
+	at := func(hoursAgo int) time.Time { return now.Add(-time.Duration(hoursAgo) * time.Hour) }
+
+	// clio: a committed session followed by three without commits
+	insertSession(t, database, "c0", "clio", at(30), at(29), at(29))
+	insertCommit(t, database, "aaaa", "c0", at(29))
+	insertSession(t, database, "c1", "clio", at(12), at(11), at(11))
+	insertSession(t, database, "c2", "clio", at(8), at(7), at(7))
+	insertSession(t, database, "c3", "clio", at(4), at(3), at(3))
+	// blog: only two sessions
+	insertSession(t, database, "b1", "blog", at(6), at(5), at(5))
+	insertSession(t, database, "b2", "blog", at(4), at(3), at(3))
+
+	checker, err := NewChecker(database, config.NudgesConfig{UncommittedSessions: 3, CooldownHours: 24}, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewChecker() error = %v", err)
+	}
+	nudges, err := checker.Check(now)
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if len(nudges) != 1 || nudges[0].Kind != KindUncommittedSessions || nudges[0].Project != "clio" || nudges[0].SessionID != "c3" {
+		t.Fatalf("Check() = %+v, want one uncommitted nudge for clio's c3", nudges)
+	}
+	if !strings.Contains(nudges[0].Message, "last 3 sessions of clio") {
+		t.Errorf("Message = %q", nudges[0].Message)
+	}
+
+	// The same run isn't nudged again, and a new run waits for the cooldown
+	if nudges, err := checker.Check(now.Add(time.Hour)); err != nil || len(nudges) != 0 {
+		t.Errorf("second Check() = %+v, %v, want nothing", nudges, err)
+	}
+	insertSession(t, database, "c4", "clio", at(-1), at(-2), at(-2))
+	if nudges, err := checker.Check(now.Add(3 * time.Hour)); err != nil || len(nudges) != 0 {
+		t.Errorf("Check() within the cooldown = %+v, %v, want nothing", nudges, err)
+	}
+	if nudges, err := checker.Check(now.Add(25 * time.Hour)); err != nil || len(nudges) != 1 || nudges[0].SessionID != "c4" {
+		t.Errorf("Check() after the cooldown = %+v, %v, want c4", nudges, err)
+	}
+}
+
+func TestChecker_LongSession(t *testing.T) {
+	database := setupTestDB(t)
+	now := time.Date(2024, 5, 10, 18, 0, 0, 0, time.Local)
+
+	// Open for four hours, last committed three and a half hours ago
+	insertSession(t, database, "long", "clio", now.Add(-4*time.Hour), nil, now.Add(-5*time.Minute))
+	insertCommit(t, database, "aaaa", "long", now.Add(-210*time.Minute))
+	// Open for four hours but committed recently
+	insertSession(t, database, "busy", "blog", now.Add(-4*time.Hour), nil, now.Add(-5*time.Minute))
+	insertCommit(t, database, "bbbb", "busy", now.Add(-time.Hour))
+	// Left open, no longer active
+	insertSession(t, database, "stale", "notes", now.Add(-10*time.Hour), nil, now.Add(-8*time.Hour))
+
+	checker, err := NewChecker(database, config.NudgesConfig{LongSessionMinutes: 180, CooldownHours: 24}, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewChecker() error = %v", err)
+	}
+	nudges, err := checker.Check(now)
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if len(nudges) != 1 || nudges[0].Kind != KindLongSession || nudges[0].SessionID != "long" {
+		t.Fatalf("Check() = %+v, want one long-session nudge for long", nudges)
+	}
+	if want := "3h30m without a commit in clio"; !strings.HasPrefix(nudges[0].Message, want) {
+		t.Errorf("Message = %q, want prefix %q", nudges[0].Message, want)
+	}
+}
+
+func TestChecker_Disabled(t *testing.T) {
+	database := setupTestDB(t)
+	now := time.Now()
+	insertSession(t, database, "long", "clio", now.Add(-10*time.Hour), nil, now)
+
+	checker, err := NewChecker(database, config.NudgesConfig{CooldownHours: 24}, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewChecker() error = %v", err)
+	}
+	if nudges, err := checker.Check(now); err != nil || len(nudges) != 0 {
+		t.Errorf("Check() with every heuristic off = %+v, %v, want nothing", nudges, err)
+	}
+}
@@ -0,0 +1,110 @@
+// Package opener opens files in the user's editor or the platform's default
+// viewer.
+package opener
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Editors by how they're told which line to open a file at
+var (
+	// plusLineEditors take +N before the file
+	plusLineEditors = map[string]bool{
+		"vi": true, "vim": true, "nvim": true, "gvim": true, "nano": true, "emacs": true,
+		"emacsclient": true, "micro": true, "kak": true, "mg": true, "joe": true, "ne": true,
+	}
+	// gotoEditors take -g file:N
+	gotoEditors = map[string]bool{"code": true, "code-insiders": true, "codium": true, "cursor": true, "windsurf": true}
+	// suffixEditors take file:N
+	suffixEditors = map[string]bool{"subl": true, "zed": true, "hx": true, "helix": true}
+)
+
+// Command is how a file is opened
+type Command struct {
+	Name string
+	Args []string // Including the file
+	// Interactive commands take over the terminal until they exit; viewers
+	// return once the file is handed off
+	Interactive bool
+}
+
+// Resolve returns the command that opens path: $VISUAL or $EDITOR, split on
+// spaces so "code -w" works, unless viewer is set or neither is set, then the
+// platform's default viewer (open on macOS, start on Windows, xdg-open elsewhere)
+func Resolve(path string, viewer bool) (Command, error) {
+	return resolve(runtime.GOOS, os.Getenv, path, 0, viewer)
+}
+
+// ResolveAt is Resolve for opening path at a 1-based line. Editors known to
+// take a line are given it; others, and viewers, open the file at the top.
+func ResolveAt(path string, line int, viewer bool) (Command, error) {
+	return resolve(runtime.GOOS, os.Getenv, path, line, viewer)
+}
+
+// resolve picks the command for goos, reading the environment through getenv
+func resolve(goos string, getenv func(string) string, path string, line int, viewer bool) (Command, error) {
+	if !viewer {
+		for _, key := range []string{"VISUAL", "EDITOR"} {
+			if fields := strings.Fields(getenv(key)); len(fields) > 0 {
+				return Command{Name: fields[0], Args: append(fields[1:], lineArgs(fields[0], path, line)...), Interactive: true}, nil
+			}
+		}
+	}
+
+	switch goos {
+	case "darwin":
+		return Command{Name: "open", Args: []string{path}}, nil
+	case "windows":
+		// start is a cmd builtin; its first quoted argument is the window title
+		return Command{Name: "cmd", Args: []string{"/c", "start", "", path}}, nil
+	case "linux", "freebsd", "openbsd", "netbsd":
+		return Command{Name: "xdg-open", Args: []string{path}}, nil
+	default:
+		return Command{}, fmt.Errorf("no default viewer on %s; set $EDITOR", goos)
+	}
+}
+
+// lineArgs returns the arguments that open path at line in editor
+func lineArgs(editor, path string, line int) []string {
+	if line <= 0 {
+		return []string{path}
+	}
+	name := strings.TrimSuffix(filepath.Base(editor), ".exe")
+	switch {
+	case plusLineEditors[name]:
+		return []string{"+" + strconv.Itoa(line), path}
+	case gotoEditors[name]:
+		return []string{"-g", path + ":" + strconv.Itoa(line)}
+	case suffixEditors[name]:
+		return []string{path + ":" + strconv.Itoa(line)}
+	}
+	return []string{path}
+}
+
+// Run runs the command. Interactive commands are attached to the terminal and
+// waited for.
+func (c Command) Run() error {
+	cmd := exec.Command(c.Name, c.Args...)
+	if c.Interactive {
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("failed to run %s: %w", c.Name, err)
+		}
+		return nil
+	}
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("failed to run %s: %w: %s", c.Name, err, msg)
+		}
+		return fmt.Errorf("failed to run %s: %w", c.Name, err)
+	}
+	return nil
+}
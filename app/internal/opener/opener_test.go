@@ -0,0 +1,50 @@
+package opener
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name    string
+		goos    string
+		env     map[string]string
+		line    int
+		viewer  bool
+		want    Command
+		wantErr bool
+	}{
+		{name: "editor", goos: "linux", env: map[string]string{"EDITOR": "vim"},
+			want: Command{Name: "vim", Args: []string{"/tmp/s.md"}, Interactive: true}},
+		{name: "visual wins", goos: "linux", env: map[string]string{"VISUAL": "code -w", "EDITOR": "vim"},
+			want: Command{Name: "code", Args: []string{"-w", "/tmp/s.md"}, Interactive: true}},
+		{name: "viewer requested", goos: "darwin", env: map[string]string{"EDITOR": "vim"}, viewer: true,
+			want: Command{Name: "open", Args: []string{"/tmp/s.md"}}},
+		{name: "linux viewer", goos: "linux", env: map[string]string{"EDITOR": "  "},
+			want: Command{Name: "xdg-open", Args: []string{"/tmp/s.md"}}},
+		{name: "windows viewer", goos: "windows",
+			want: Command{Name: "cmd", Args: []string{"/c", "start", "", "/tmp/s.md"}}},
+		{name: "no viewer", goos: "plan9", wantErr: true},
+		{name: "vim at a line", goos: "linux", env: map[string]string{"EDITOR": "/usr/bin/nvim"}, line: 12,
+			want: Command{Name: "/usr/bin/nvim", Args: []string{"+12", "/tmp/s.md"}, Interactive: true}},
+		{name: "code at a line", goos: "linux", env: map[string]string{"VISUAL": "code -w"}, line: 12,
+			want: Command{Name: "code", Args: []string{"-w", "-g", "/tmp/s.md:12"}, Interactive: true}},
+		{name: "unknown editor ignores the line", goos: "linux", env: map[string]string{"EDITOR": "ed"}, line: 12,
+			want: Command{Name: "ed", Args: []string{"/tmp/s.md"}, Interactive: true}},
+		{name: "viewer ignores the line", goos: "darwin", line: 12,
+			want: Command{Name: "open", Args: []string{"/tmp/s.md"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolve(tt.goos, func(key string) string { return tt.env[key] }, "/tmp/s.md", tt.line, tt.viewer)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resolve() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,398 @@
+// Package outcomes tracks how sessions ended: shipped, abandoned, or blocked.
+// An outcome can be set by hand; otherwise it's inferred from the session's
+// commits and whether later commits reverted them. Completion rates per week
+// and project are derived from both.
+package outcomes
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/commitmsg"
+	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+// Outcome is how a session ended
+type Outcome string
+
+const (
+	// Shipped sessions produced commits that weren't reverted
+	Shipped Outcome = "shipped"
+	// Abandoned sessions produced nothing that stayed
+	Abandoned Outcome = "abandoned"
+	// Blocked sessions stopped on something outside them; only set by hand
+	Blocked Outcome = "blocked"
+)
+
+// Source is where an outcome came from
+type Source string
+
+const (
+	// SourceManual outcomes were set with Set
+	SourceManual Source = "manual"
+	// SourceInferred outcomes were inferred from commits and reverts
+	SourceInferred Source = "inferred"
+)
+
+// DefaultGrace is how long after a session ends its work may still be committed
+// outside it before the session counts as abandoned
+const DefaultGrace = 24 * time.Hour
+
+// Parse parses an outcome name
+func Parse(s string) (Outcome, error) {
+	switch outcome := Outcome(strings.ToLower(strings.TrimSpace(s))); outcome {
+	case Shipped, Abandoned, Blocked:
+		return outcome, nil
+	}
+	return "", fmt.Errorf("unknown outcome %q (use %s, %s, or %s)", s, Shipped, Abandoned, Blocked)
+}
+
+// SessionOutcome is a session with its outcome
+type SessionOutcome struct {
+	SessionID string
+	Project   string
+	Start     time.Time
+	End       time.Time // Zero while the session is active
+	Outcome   Outcome   // Empty while it can't be told yet
+	Source    Source    // Empty without an outcome
+	Note      string    // The note given with a manual outcome, or why the outcome was inferred
+	SetAt     time.Time // When a manual outcome was set
+	Commits   int       // Non-merge commits correlated with the session
+	Reverted  int       // Of those, how many a later commit reverted
+}
+
+// Options filters sessions by project and start time
+type Options struct {
+	Project string    // Only include this project (case-insensitive); empty includes all
+	Since   time.Time // Only include sessions started at or after this time; zero means no lower bound
+	Until   time.Time // Only include sessions started before this time; zero means no upper bound
+	// Grace is how long after a session ends its work may still be committed;
+	// zero uses DefaultGrace
+	Grace time.Duration
+}
+
+// Counts counts sessions by outcome
+type Counts struct {
+	Sessions  int
+	Shipped   int
+	Abandoned int
+	Blocked   int
+	Unknown   int // Active, or ended too recently to tell
+}
+
+// CompletionRate returns the share of sessions with an outcome that shipped
+func (c Counts) CompletionRate() float64 {
+	decided := c.Shipped + c.Abandoned + c.Blocked
+	if decided == 0 {
+		return 0
+	}
+	return float64(c.Shipped) / float64(decided)
+}
+
+// add counts a session's outcome
+func (c *Counts) add(outcome Outcome) {
+	c.Sessions++
+	switch outcome {
+	case Shipped:
+		c.Shipped++
+	case Abandoned:
+		c.Abandoned++
+	case Blocked:
+		c.Blocked++
+	default:
+		c.Unknown++
+	}
+}
+
+// WeekCounts counts the outcomes of sessions started in one week
+type WeekCounts struct {
+	Start time.Time // Monday 00:00 local time
+	Counts
+}
+
+// ProjectCounts counts one project's outcomes
+type ProjectCounts struct {
+	Project string
+	Counts
+}
+
+// Report summarizes outcomes by week and by project
+type Report struct {
+	Total    Counts
+	Weeks    []WeekCounts    // Oldest first
+	Projects []ProjectCounts // By project name
+}
+
+// Store defines the interface for setting and reading session outcomes
+type Store interface {
+	// Set sets a session's outcome by hand, replacing an inferred one
+	Set(sessionID string, outcome Outcome, note string, at time.Time) error
+	// Clear removes a manual outcome, so the session's outcome is inferred again
+	Clear(sessionID string) error
+	// Sessions returns sessions with their outcomes as of now, newest first
+	Sessions(opts Options, now time.Time) ([]SessionOutcome, error)
+	// Report counts outcomes as of now by week and by project
+	Report(opts Options, now time.Time) (*Report, error)
+}
+
+// store implements Store on the sessions and commits tables
+type store struct {
+	db     *sql.DB
+	logger logging.Logger
+}
+
+// NewStore creates an outcome store backed by the database
+func NewStore(db *sql.DB, logger logging.Logger) (Store, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database cannot be nil")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	return &store{
+		db:     db,
+		logger: logger.With("component", "outcomes"),
+	}, nil
+}
+
+// Set implements Store
+func (s *store) Set(sessionID string, outcome Outcome, note string, at time.Time) error {
+	if _, err := Parse(string(outcome)); err != nil {
+		return err
+	}
+	var noteValue interface{}
+	if note = strings.TrimSpace(note); note != "" {
+		noteValue = note
+	}
+	result, err := s.db.Exec(`UPDATE sessions SET outcome = ?, outcome_note = ?, outcome_set_at = ? WHERE id = ?`,
+		string(outcome), noteValue, at, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to set session outcome: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("session %s not found", sessionID)
+	}
+	s.logger.Debug("set session outcome", "session_id", sessionID, "outcome", outcome)
+	return nil
+}
+
+// Clear implements Store
+func (s *store) Clear(sessionID string) error {
+	result, err := s.db.Exec(`UPDATE sessions SET outcome = NULL, outcome_note = NULL, outcome_set_at = NULL WHERE id = ?`, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to clear session outcome: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("session %s not found", sessionID)
+	}
+	return nil
+}
+
+// storedCommit is a commit considered for inference
+type storedCommit struct {
+	hash      string
+	project   string
+	sessionID string
+	timestamp time.Time
+}
+
+// Sessions implements Store
+func (s *store) Sessions(opts Options, now time.Time) ([]SessionOutcome, error) {
+	grace := opts.Grace
+	if grace <= 0 {
+		grace = DefaultGrace
+	}
+
+	sessions, err := s.sessions(opts)
+	if err != nil {
+		return nil, err
+	}
+	commits, reverted, err := s.commits()
+	if err != nil {
+		return nil, err
+	}
+
+	bySession := make(map[string][]storedCommit)
+	var uncorrelated []storedCommit
+	for _, commit := range commits {
+		if commit.sessionID == "" {
+			uncorrelated = append(uncorrelated, commit)
+		} else {
+			bySession[commit.sessionID] = append(bySession[commit.sessionID], commit)
+		}
+	}
+
+	for i := range sessions {
+		session := &sessions[i]
+		for _, commit := range bySession[session.SessionID] {
+			session.Commits++
+			if reverted[commit.hash] {
+				session.Reverted++
+			}
+		}
+		if session.Outcome != "" {
+			session.Source = SourceManual
+			continue
+		}
+		session.Outcome, session.Note = infer(*session, uncorrelated, grace, now)
+		if session.Outcome != "" {
+			session.Source = SourceInferred
+		}
+	}
+
+	sort.SliceStable(sessions, func(i, j int) bool { return sessions[i].Start.After(sessions[j].Start) })
+	return sessions, nil
+}
+
+// infer infers an ended session's outcome from its commits, or from commits to
+// its project made soon after it when it has none, and says why
+func infer(session SessionOutcome, uncorrelated []storedCommit, grace time.Duration, now time.Time) (Outcome, string) {
+	switch {
+	case session.End.IsZero():
+		return "", "still active"
+	case session.Commits > session.Reverted:
+		return Shipped, fmt.Sprintf("%d commit(s) kept", session.Commits-session.Reverted)
+	case session.Commits > 0:
+		return Abandoned, "its commits were reverted"
+	}
+	for _, commit := range uncorrelated {
+		if strings.EqualFold(commit.project, session.Project) &&
+			commit.timestamp.After(session.End) && !commit.timestamp.After(session.End.Add(grace)) {
+			return Shipped, "committed after the session"
+		}
+	}
+	if now.Sub(session.End) < grace {
+		return "", "ended recently, without commits yet"
+	}
+	return Abandoned, "no commits followed"
+}
+
+// sessions returns the sessions in range with their manual outcomes
+func (s *store) sessions(opts Options) ([]SessionOutcome, error) {
+	rows, err := s.db.Query(`
+		SELECT id, COALESCE(project, ''), start_time, end_time,
+			COALESCE(outcome, ''), COALESCE(outcome_note, ''), outcome_set_at
+		FROM sessions
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []SessionOutcome
+	for rows.Next() {
+		var session SessionOutcome
+		var outcome string
+		var end, setAt sql.NullTime
+		if err := rows.Scan(&session.SessionID, &session.Project, &session.Start, &end, &outcome, &session.Note, &setAt); err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		if opts.Project != "" && !strings.EqualFold(opts.Project, session.Project) ||
+			!db.InRange(session.Start, opts.Since, opts.Until) {
+			continue
+		}
+		session.Outcome = Outcome(outcome)
+		session.End = end.Time
+		session.SetAt = setAt.Time
+		sessions = append(sessions, session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// commits returns the non-merge commits, each hash once, and the set of hashes
+// a later commit reverted
+func (s *store) commits() ([]storedCommit, map[string]bool, error) {
+	rows, err := s.db.Query(`
+		SELECT hash, repository_name, COALESCE(session_id, ''), message, timestamp
+		FROM commits
+		WHERE is_merge = 0
+	`)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query commits: %w", err)
+	}
+	defer rows.Close()
+
+	var commits []storedCommit
+	var reverts []string
+	for rows.Next() {
+		var commit storedCommit
+		var message string
+		if err := rows.Scan(&commit.hash, &commit.project, &commit.sessionID, &message, &commit.timestamp); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan commit: %w", err)
+		}
+		if hash := commitmsg.RevertedHash(message); hash != "" {
+			reverts = append(reverts, hash)
+		}
+		commits = append(commits, commit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("error iterating commits: %w", err)
+	}
+
+	// Revert messages may name an abbreviated hash
+	reverted := make(map[string]bool)
+	for _, hash := range reverts {
+		for _, commit := range commits {
+			if strings.HasPrefix(commit.hash, hash) {
+				reverted[commit.hash] = true
+			}
+		}
+	}
+	return commits, reverted, nil
+}
+
+// Report implements Store
+func (s *store) Report(opts Options, now time.Time) (*Report, error) {
+	sessions, err := s.Sessions(opts, now)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{}
+	weeks := make(map[time.Time]*WeekCounts)
+	projects := make(map[string]*ProjectCounts)
+	for _, session := range sessions {
+		report.Total.add(session.Outcome)
+
+		start := weekStart(session.Start)
+		if weeks[start] == nil {
+			weeks[start] = &WeekCounts{Start: start}
+		}
+		weeks[start].add(session.Outcome)
+
+		key := strings.ToLower(session.Project)
+		if projects[key] == nil {
+			projects[key] = &ProjectCounts{Project: session.Project}
+		}
+		projects[key].add(session.Outcome)
+	}
+
+	for _, week := range weeks {
+		report.Weeks = append(report.Weeks, *week)
+	}
+	sort.Slice(report.Weeks, func(i, j int) bool { return report.Weeks[i].Start.Before(report.Weeks[j].Start) })
+	for _, project := range projects {
+		report.Projects = append(report.Projects, *project)
+	}
+	sort.Slice(report.Projects, func(i, j int) bool {
+		return strings.ToLower(report.Projects[i].Project) < strings.ToLower(report.Projects[j].Project)
+	})
+
+	s.logger.Debug("generated outcome report", "sessions", report.Total.Sessions, "shipped", report.Total.Shipped)
+	return report, nil
+}
+
+// weekStart returns midnight on the Monday starting t's week, in local time
+func weekStart(t time.Time) time.Time {
+	t = t.Local()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
@@ -0,0 +1,151 @@
+package outcomes
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/logging"
+	_ "modernc.org/sqlite"
+)
+
+func setupTestStore(t *testing.T) (*sql.DB, Store) {
+	database, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "clio.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if err := db.RunMigrations(database); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	store, err := NewStore(database, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	return database, store
+}
+
+func insertSession(t *testing.T, database *sql.DB, id string, start time.Time, end interface{}) {
+	if _, err := database.Exec(`
+		INSERT INTO sessions (id, project, start_time, end_time, last_activity, created_at, updated_at)
+		VALUES (?, 'clio', ?, ?, ?, ?, ?)
+	`, id, start, end, start, start, start); err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+}
+
+func insertCommit(t *testing.T, database *sql.DB, hash, repoName string, sessionID interface{}, message string, at time.Time) {
+	if _, err := database.Exec(`
+		INSERT INTO commits (id, session_id, repository_path, repository_name, hash, message,
+			author_name, author_email, timestamp, branch, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, 'Test User', 'test@example.com', ?, 'main', ?, ?)
+	`, hash, sessionID, "/home/user/"+repoName, repoName, hash, message, at, at, at); err != nil {
+		t.Fatalf("failed to create commit: %v", err)
+	}
+}
+
+func TestParse(t *testing.T) {
+	if outcome, err := Parse(" Shipped "); err != nil || outcome != Shipped {
+		t.Errorf("Parse(Shipped) = %q, %v", outcome, err)
+	}
+	if _, err := Parse("done"); err == nil {
+		t.Error("Parse(done) should fail")
+	}
+}
+
+func TestStore_Sessions(t *testing.T) {
+	database, store := setupTestStore(t)
+	// A Monday, so every session falls in one week
+	base := time.Date(2024, 5, 6, 9, 0, 0, 0, time.Local)
+	day := 24 * time.Hour
+	now := base.Add(6 * day)
+
+	insertSession(t, database, "shipped", base, base.Add(time.Hour))
+	insertCommit(t, database, "aaaaaaa111", "clio", "shipped", "feat: dates", base.Add(30*time.Minute))
+
+	insertSession(t, database, "reverted", base.Add(2*time.Hour), base.Add(3*time.Hour))
+	insertCommit(t, database, "bbbbbbb222", "clio", "reverted", "feat: quoting", base.Add(150*time.Minute))
+	insertCommit(t, database, "ccccccc333", "clio", nil, "Revert \"feat: quoting\"\n\nThis reverts commit bbbbbbb.", base.Add(4*time.Hour))
+
+	insertSession(t, database, "committed-later", base.Add(2*day), base.Add(2*day+time.Hour))
+	insertCommit(t, database, "ddddddd444", "CLIO", nil, "fix: lexer", base.Add(2*day+3*time.Hour))
+
+	insertSession(t, database, "blocked", base.Add(3*day), base.Add(3*day+time.Hour))
+	insertSession(t, database, "quiet", base.Add(4*day), base.Add(4*day+time.Hour))
+	insertSession(t, database, "recent", now.Add(-2*time.Hour), now.Add(-time.Hour))
+	insertSession(t, database, "active", now.Add(-30*time.Minute), nil)
+
+	if err := store.Set("blocked", Blocked, " waiting on API access ", now); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := store.Set("missing", Shipped, "", now); err == nil {
+		t.Error("Set() on a missing session should fail")
+	}
+
+	sessions, err := store.Sessions(Options{}, now)
+	if err != nil {
+		t.Fatalf("Sessions() error = %v", err)
+	}
+	want := map[string]struct {
+		outcome Outcome
+		source  Source
+	}{
+		"shipped":         {Shipped, SourceInferred},
+		"reverted":        {Abandoned, SourceInferred},
+		"committed-later": {Shipped, SourceInferred},
+		"blocked":         {Blocked, SourceManual},
+		"quiet":           {Abandoned, SourceInferred},
+		"recent":          {"", ""},
+		"active":          {"", ""},
+	}
+	if len(sessions) != len(want) || sessions[0].SessionID != "active" {
+		t.Fatalf("Sessions() = %+v, want %d sessions, newest first", sessions, len(want))
+	}
+	for _, session := range sessions {
+		if w := want[session.SessionID]; session.Outcome != w.outcome || session.Source != w.source {
+			t.Errorf("%s outcome = %q (%s), want %q (%s)", session.SessionID, session.Outcome, session.Source, w.outcome, w.source)
+		}
+		switch session.SessionID {
+		case "reverted":
+			if session.Commits != 1 || session.Reverted != 1 {
+				t.Errorf("reverted commits = %d, %d reverted, want 1, 1", session.Commits, session.Reverted)
+			}
+		case "blocked":
+			if session.Note != "waiting on API access" || !session.SetAt.Equal(now) {
+				t.Errorf("blocked note = %q, set at %v", session.Note, session.SetAt)
+			}
+		}
+	}
+
+	report, err := store.Report(Options{Project: "CLIO"}, now)
+	if err != nil {
+		t.Fatalf("Report() error = %v", err)
+	}
+	total := report.Total
+	if total.Sessions != 7 || total.Shipped != 2 || total.Abandoned != 2 || total.Blocked != 1 || total.Unknown != 2 {
+		t.Errorf("total = %+v", total)
+	}
+	if got := total.CompletionRate(); got != 0.4 {
+		t.Errorf("CompletionRate() = %v, want 0.4", got)
+	}
+	if len(report.Weeks) != 1 || !report.Weeks[0].Start.Equal(base.Add(-9*time.Hour)) {
+		t.Errorf("weeks = %+v, want the week of %v", report.Weeks, base)
+	}
+	if len(report.Projects) != 1 || report.Projects[0].Sessions != 7 {
+		t.Errorf("projects = %+v", report.Projects)
+	}
+
+	if err := store.Clear("blocked"); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	sessions, err = store.Sessions(Options{Since: base.Add(3 * day), Until: base.Add(4 * day)}, now)
+	if err != nil {
+		t.Fatalf("Sessions() error = %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].Outcome != Abandoned || sessions[0].Source != SourceInferred {
+		t.Errorf("after Clear() = %+v, want blocked inferred as abandoned", sessions)
+	}
+}
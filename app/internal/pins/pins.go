@@ -0,0 +1,215 @@
+// Package pins stores favorite sessions and conversations, such as a debugging
+// session worth finding again, so lists and digests can show them first.
+package pins
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+// Kind is what a pin refers to
+type Kind string
+
+const (
+	// KindSession pins a session by its ID
+	KindSession Kind = "session"
+	// KindConversation pins a conversation by its composer ID, across the sessions it spans
+	KindConversation Kind = "conversation"
+)
+
+// Pin is a pinned session or conversation
+type Pin struct {
+	ID       int64
+	Kind     Kind
+	TargetID string // Session ID or composer ID
+	Note     string
+	PinnedAt time.Time
+
+	Name    string    // The conversation's name; empty for sessions and unnamed conversations
+	Project string    // The session's project, or the project of the conversation's first session
+	Start   time.Time // When the session or conversation started
+	Missing bool      // The target is no longer in the database
+}
+
+// Store defines the interface for pinning and listing favorites
+type Store interface {
+	// Pin pins a session or conversation, or updates the note of an existing pin when note is set
+	Pin(kind Kind, targetID, note string, at time.Time) (*Pin, error)
+	// List returns every pin, most recently pinned first
+	List() ([]Pin, error)
+	// Unpin removes a pin
+	Unpin(id int64) error
+}
+
+// store implements Store on top of the clio database
+type store struct {
+	db     *sql.DB
+	logger logging.Logger
+}
+
+// NewStore creates a pin store backed by the database
+func NewStore(db *sql.DB, logger logging.Logger) (Store, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database cannot be nil")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	return &store{
+		db:     db,
+		logger: logger.With("component", "pins"),
+	}, nil
+}
+
+// Pin pins a session or conversation
+func (s *store) Pin(kind Kind, targetID, note string, at time.Time) (*Pin, error) {
+	if kind != KindSession && kind != KindConversation {
+		return nil, fmt.Errorf("unknown pin kind %q", kind)
+	}
+	pin := Pin{Kind: kind, TargetID: targetID, Note: strings.TrimSpace(note), PinnedAt: at}
+	if err := s.describe(&pin); err != nil {
+		return nil, err
+	}
+	if pin.Missing {
+		return nil, fmt.Errorf("%s %s not found", kind, targetID)
+	}
+
+	var storedNote any
+	if pin.Note != "" {
+		storedNote = pin.Note
+	}
+	_, err := s.db.Exec(`
+		INSERT INTO pins (kind, target_id, note, pinned_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(kind, target_id) DO UPDATE SET note = COALESCE(excluded.note, pins.note)
+	`, string(kind), targetID, storedNote, at)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store pin: %w", err)
+	}
+
+	var stored sql.NullString
+	err = s.db.QueryRow("SELECT id, note, pinned_at FROM pins WHERE kind = ? AND target_id = ?", string(kind), targetID).
+		Scan(&pin.ID, &stored, &pin.PinnedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pin: %w", err)
+	}
+	pin.Note = stored.String
+
+	s.logger.Debug("pinned", "id", pin.ID, "kind", kind, "target_id", targetID)
+	return &pin, nil
+}
+
+// List returns every pin, most recently pinned first
+func (s *store) List() ([]Pin, error) {
+	rows, err := s.db.Query("SELECT id, kind, target_id, note, pinned_at FROM pins")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pins: %w", err)
+	}
+
+	var list []Pin
+	for rows.Next() {
+		var pin Pin
+		var kind string
+		var note sql.NullString
+		if err := rows.Scan(&pin.ID, &kind, &pin.TargetID, &note, &pin.PinnedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan pin: %w", err)
+		}
+		pin.Kind, pin.Note = Kind(kind), note.String
+		list = append(list, pin)
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return nil, fmt.Errorf("error iterating pins: %w", err)
+	}
+
+	for i := range list {
+		if err := s.describe(&list[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	// Newest first, latest pin first within the same second
+	sort.SliceStable(list, func(i, j int) bool {
+		if !list[i].PinnedAt.Equal(list[j].PinnedAt) {
+			return list[i].PinnedAt.After(list[j].PinnedAt)
+		}
+		return list[i].ID > list[j].ID
+	})
+	return list, nil
+}
+
+// Unpin removes a pin
+func (s *store) Unpin(id int64) error {
+	result, err := s.db.Exec("DELETE FROM pins WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to remove pin: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to remove pin: %w", err)
+	} else if n == 0 {
+		return fmt.Errorf("no pin %d", id)
+	}
+	return nil
+}
+
+// describe fills in the pin's name, project, and start from its target, or
+// marks it missing
+func (s *store) describe(pin *Pin) error {
+	if pin.Kind == KindSession {
+		err := s.db.QueryRow("SELECT COALESCE(project, ''), start_time FROM sessions WHERE id = ?", pin.TargetID).
+			Scan(&pin.Project, &pin.Start)
+		if err == sql.ErrNoRows {
+			pin.Missing = true
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to look up session: %w", err)
+		}
+		return nil
+	}
+
+	// A composer resumed in a later session has a conversation row per session
+	rows, err := s.db.Query(`
+		SELECT COALESCE(c.name, ''), COALESCE(s.project, ''), c.first_message_time, c.created_at
+		FROM conversations c
+		LEFT JOIN sessions s ON s.id = c.session_id
+		WHERE c.composer_id = ?
+	`, pin.TargetID)
+	if err != nil {
+		return fmt.Errorf("failed to look up conversation: %w", err)
+	}
+	defer rows.Close()
+
+	pin.Missing = true
+	for rows.Next() {
+		var name, project string
+		var first sql.NullTime
+		var createdAt time.Time
+		if err := rows.Scan(&name, &project, &first, &createdAt); err != nil {
+			return fmt.Errorf("failed to scan conversation: %w", err)
+		}
+		start := createdAt
+		if first.Valid {
+			start = first.Time
+		}
+		if pin.Missing || start.Before(pin.Start) {
+			pin.Project, pin.Start = project, start
+		}
+		if pin.Name == "" {
+			pin.Name = name
+		}
+		pin.Missing = false
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating conversations: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package pins
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/logging"
+	_ "modernc.org/sqlite"
+)
+
+func setupTestStore(t *testing.T) (*sql.DB, Store) {
+	database, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "clio.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if err := db.RunMigrations(database); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	store, err := NewStore(database, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	return database, store
+}
+
+func TestStore_PinListUnpin(t *testing.T) {
+	database, store := setupTestStore(t)
+	base := time.Date(2024, 5, 1, 9, 0, 0, 0, time.Local)
+
+	for i, id := range []string{"s1", "s2"} {
+		start := base.Add(time.Duration(i) * 24 * time.Hour)
+		if _, err := database.Exec(`
+			INSERT INTO sessions (id, project, start_time, last_activity, created_at, updated_at)
+			VALUES (?, 'clio', ?, ?, ?, ?)
+		`, id, start, start, start, start); err != nil {
+			t.Fatalf("failed to create session: %v", err)
+		}
+	}
+	// The composer was resumed in s2, whose row has no name
+	if _, err := database.Exec(`
+		INSERT INTO conversations (id, session_id, composer_id, name, status, message_count, first_message_time, created_at, updated_at)
+		VALUES ('conv-1', 's1', 'composer-1', 'Flaky lexer test', 'completed', 1, ?, ?, ?),
+			('conv-2', 's2', 'composer-1', NULL, 'completed', 1, ?, ?, ?)
+	`, base, base, base, base.Add(24*time.Hour), base, base); err != nil {
+		t.Fatalf("failed to create conversations: %v", err)
+	}
+
+	session, err := store.Pin(KindSession, "s2", "", base)
+	if err != nil {
+		t.Fatalf("Pin(session) error = %v", err)
+	}
+	if session.Project != "clio" || !session.Start.Equal(base.Add(24*time.Hour)) {
+		t.Errorf("session pin = %+v", session)
+	}
+
+	conversation, err := store.Pin(KindConversation, "composer-1", "great debugging", base.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Pin(conversation) error = %v", err)
+	}
+	if conversation.Name != "Flaky lexer test" || !conversation.Start.Equal(base) {
+		t.Errorf("conversation pin = %+v, want named from its first session", conversation)
+	}
+
+	// Pinning again keeps the pin, and its note unless a new one is given
+	again, err := store.Pin(KindConversation, "composer-1", "", base.Add(2*time.Hour))
+	if err != nil || again.ID != conversation.ID || again.Note != "great debugging" || !again.PinnedAt.Equal(base.Add(time.Hour)) {
+		t.Errorf("Pin() again = %+v, %v; want the existing pin", again, err)
+	}
+
+	if _, err := store.Pin(KindSession, "missing", "", base); err == nil {
+		t.Error("Pin() of a missing session should fail")
+	}
+	if _, err := store.Pin(Kind("commit"), "s1", "", base); err == nil {
+		t.Error("Pin() of an unknown kind should fail")
+	}
+
+	list, err := store.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(list) != 2 || list[0].ID != conversation.ID || list[1].ID != session.ID {
+		t.Fatalf("List() = %+v, want the conversation then the session", list)
+	}
+
+	if _, err := database.Exec("DELETE FROM sessions WHERE id = 's2'"); err != nil {
+		t.Fatalf("failed to delete session: %v", err)
+	}
+	if list, err = store.List(); err != nil || !list[1].Missing {
+		t.Errorf("List() = %+v, %v; want the deleted session marked missing", list, err)
+	}
+
+	if err := store.Unpin(session.ID); err != nil {
+		t.Fatalf("Unpin() error = %v", err)
+	}
+	if err := store.Unpin(session.ID); err == nil {
+		t.Error("Unpin() of a removed pin should fail")
+	}
+	if list, err = store.List(); err != nil || len(list) != 1 {
+		t.Errorf("List() after Unpin() = %+v, %v", list, err)
+	}
+}
@@ -0,0 +1,345 @@
+package provenance
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/dedup"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+const (
+	// DefaultMinScore is the share of a query's shingles a block must contain to match
+	DefaultMinScore = 0.5
+	// DefaultFindLimit is how many matches Find returns by default
+	DefaultFindLimit = 5
+	// shingleQueryBatch bounds the shingle hashes bound into a single query
+	shingleQueryBatch = 500
+)
+
+// FindOptions tunes code lookups
+type FindOptions struct {
+	Limit    int     // Maximum matches; zero uses DefaultFindLimit
+	MinScore float64 // Minimum score in (0, 1]; zero uses DefaultMinScore
+}
+
+// Match is a captured code block that contains the code being looked up
+type Match struct {
+	SessionID        string
+	Project          string
+	ConversationName string // Falls back to the composer ID
+	Role             string
+	MessageCreatedAt time.Time
+	Language         string
+	Code             string  // The captured block
+	Score            float64 // Share of the query's shingles found in the block
+	Coverage         float64 // Share of the block's shingles found in the query
+	Exact            bool    // The normalized code is identical
+}
+
+// Index defines the interface for the code provenance index
+type Index interface {
+	// Sync indexes code blocks in messages that are new or changed since the last
+	// sync and returns how many blocks were indexed
+	Sync() (int, error)
+	// Rebuild discards the index and indexes every message again
+	Rebuild() (int, error)
+	// Find returns the captured blocks that contain code, best match first. Ties go
+	// to the earliest message, where the code most likely originated.
+	Find(code string, opts FindOptions) ([]Match, error)
+}
+
+// index implements Index with tables in the clio database
+type index struct {
+	db     *sql.DB
+	logger logging.Logger
+}
+
+// codeBlock is an element of a message's code_blocks JSON
+type codeBlock struct {
+	Content    string `json:"content"`
+	LanguageID string `json:"languageId"`
+}
+
+// NewIndex creates a provenance index over the database's messages
+func NewIndex(db *sql.DB, logger logging.Logger) (Index, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database cannot be nil")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	return &index{
+		db:     db,
+		logger: logger.With("component", "provenance"),
+	}, nil
+}
+
+// Rebuild reindexes every message
+func (x *index) Rebuild() (int, error) {
+	if _, err := x.db.Exec("DELETE FROM code_shingles"); err != nil {
+		return 0, fmt.Errorf("failed to clear code shingles: %w", err)
+	}
+	if _, err := x.db.Exec("DELETE FROM code_blocks"); err != nil {
+		return 0, fmt.Errorf("failed to clear code blocks: %w", err)
+	}
+	return x.Sync()
+}
+
+// Sync indexes new and changed messages
+func (x *index) Sync() (int, error) {
+	indexed, err := x.indexedSources()
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := x.db.Query("SELECT id, code_blocks FROM messages WHERE code_blocks IS NOT NULL AND code_blocks != ''")
+	if err != nil {
+		return 0, fmt.Errorf("failed to query messages: %w", err)
+	}
+	pending := make(map[string]string)
+	for rows.Next() {
+		var id, blocks string
+		if err := rows.Scan(&id, &blocks); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan message: %w", err)
+		}
+		if indexed[id] != sourceHash(blocks) {
+			pending[id] = blocks
+		}
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return 0, fmt.Errorf("error iterating messages: %w", err)
+	}
+	if len(pending) == 0 {
+		return 0, nil
+	}
+
+	tx, err := x.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	count := 0
+	now := time.Now()
+	for messageID, blocksJSON := range pending {
+		// Messages are indexed by their stored form, with shared blocks restored
+		expanded, err := dedup.ExpandCodeBlocks(tx, blocksJSON)
+		if err != nil {
+			x.logger.Warn("skipping message with unresolvable code blocks", "message_id", messageID, "error", err)
+			continue
+		}
+		var blocks []codeBlock
+		if err := json.Unmarshal([]byte(expanded), &blocks); err != nil {
+			x.logger.Warn("skipping message with malformed code blocks", "message_id", messageID, "error", err)
+			continue
+		}
+		if err := indexMessage(tx, messageID, sourceHash(blocksJSON), blocks, now); err != nil {
+			return 0, err
+		}
+		count += len(blocks)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit code index: %w", err)
+	}
+	x.logger.Debug("indexed code blocks", "messages", len(pending), "blocks", count)
+	return count, nil
+}
+
+// indexedSources returns the code_blocks hash each indexed message was indexed from
+func (x *index) indexedSources() (map[string]string, error) {
+	rows, err := x.db.Query("SELECT message_id, source_hash FROM code_blocks GROUP BY message_id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query code index: %w", err)
+	}
+	defer rows.Close()
+
+	sources := make(map[string]string)
+	for rows.Next() {
+		var messageID, hash string
+		if err := rows.Scan(&messageID, &hash); err != nil {
+			return nil, fmt.Errorf("failed to scan code block: %w", err)
+		}
+		sources[messageID] = hash
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating code blocks: %w", err)
+	}
+	return sources, nil
+}
+
+// indexMessage replaces a message's indexed blocks
+func indexMessage(tx *sql.Tx, messageID, source string, blocks []codeBlock, now time.Time) error {
+	// Foreign keys aren't enforced, so a message's shingles are removed explicitly
+	if _, err := tx.Exec("DELETE FROM code_shingles WHERE block_id IN (SELECT id FROM code_blocks WHERE message_id = ?)", messageID); err != nil {
+		return fmt.Errorf("failed to clear code shingles: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM code_blocks WHERE message_id = ?", messageID); err != nil {
+		return fmt.Errorf("failed to clear code blocks: %w", err)
+	}
+
+	for i, block := range blocks {
+		shingles := Shingles(block.Content)
+		res, err := tx.Exec(`
+			INSERT INTO code_blocks (message_id, block_index, language, content_hash, shingle_count, source_hash, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, messageID, i, sql.NullString{String: block.LanguageID, Valid: block.LanguageID != ""},
+			ContentHash(block.Content), len(shingles), source, now)
+		if err != nil {
+			return fmt.Errorf("failed to store code block: %w", err)
+		}
+		blockID, err := res.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("failed to get code block ID: %w", err)
+		}
+		for _, shingle := range shingles {
+			if _, err := tx.Exec("INSERT OR IGNORE INTO code_shingles (hash, block_id) VALUES (?, ?)", shingle, blockID); err != nil {
+				return fmt.Errorf("failed to store code shingle: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// Find looks up the blocks containing code
+func (x *index) Find(code string, opts FindOptions) ([]Match, error) {
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = DefaultFindLimit
+	}
+	minScore := opts.MinScore
+	if minScore <= 0 {
+		minScore = DefaultMinScore
+	}
+
+	shingles := Shingles(code)
+	if len(shingles) == 0 {
+		return nil, fmt.Errorf("code to find cannot be empty")
+	}
+
+	// Count each block's shared shingles
+	shared := make(map[int64]int)
+	for start := 0; start < len(shingles); start += shingleQueryBatch {
+		batch := shingles[start:min(start+shingleQueryBatch, len(shingles))]
+		args := make([]interface{}, len(batch))
+		for i, s := range batch {
+			args[i] = s
+		}
+		rows, err := x.db.Query(`
+			SELECT block_id, COUNT(*)
+			FROM code_shingles
+			WHERE hash IN (?`+strings.Repeat(", ?", len(batch)-1)+`)
+			GROUP BY block_id
+		`, args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query code shingles: %w", err)
+		}
+		for rows.Next() {
+			var blockID int64
+			var n int
+			if err := rows.Scan(&blockID, &n); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan code shingles: %w", err)
+			}
+			shared[blockID] += n
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error iterating code shingles: %w", err)
+		}
+	}
+
+	contentHash := ContentHash(code)
+	var matches []Match
+	for blockID, n := range shared {
+		score := float64(n) / float64(len(shingles))
+		if score < minScore {
+			continue
+		}
+		match, blockShingles, err := x.loadMatch(blockID, contentHash)
+		if err != nil {
+			return nil, err
+		}
+		if match == nil {
+			continue
+		}
+		match.Score = score
+		if blockShingles > 0 {
+			match.Coverage = float64(n) / float64(blockShingles)
+		}
+		matches = append(matches, *match)
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.Exact != b.Exact {
+			return a.Exact
+		}
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Coverage != b.Coverage {
+			return a.Coverage > b.Coverage
+		}
+		return a.MessageCreatedAt.Before(b.MessageCreatedAt)
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
+
+// loadMatch loads a block with its message and conversation, and returns the block's
+// shingle count. The match is nil when the message no longer exists.
+func (x *index) loadMatch(blockID int64, contentHash string) (*Match, int, error) {
+	var match Match
+	var blockIndex, shingleCount int
+	var hash, blocksJSON string
+	var language, project sql.NullString
+	err := x.db.QueryRow(`
+		SELECT b.block_index, b.language, b.content_hash, b.shingle_count, m.code_blocks, m.role, m.created_at,
+			COALESCE(NULLIF(c.name, ''), c.composer_id), c.session_id, s.project
+		FROM code_blocks b
+		JOIN messages m ON m.id = b.message_id
+		JOIN conversations c ON c.id = m.conversation_id
+		LEFT JOIN sessions s ON s.id = c.session_id
+		WHERE b.id = ?
+	`, blockID).Scan(&blockIndex, &language, &hash, &shingleCount, &blocksJSON, &match.Role, &match.MessageCreatedAt,
+		&match.ConversationName, &match.SessionID, &project)
+	if err == sql.ErrNoRows {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to load code block: %w", err)
+	}
+
+	if expanded, err := dedup.ExpandCodeBlocks(x.db, blocksJSON); err == nil {
+		blocksJSON = expanded
+	}
+	var blocks []codeBlock
+	if err := json.Unmarshal([]byte(blocksJSON), &blocks); err == nil && blockIndex < len(blocks) {
+		match.Code = blocks[blockIndex].Content
+	}
+	match.Language = language.String
+	match.Project = project.String
+	match.Exact = hash == contentHash
+	return &match, shingleCount, nil
+}
+
+// sourceHash identifies the code_blocks JSON a message was indexed from
+func sourceHash(blocksJSON string) string {
+	sum := sha256.Sum256([]byte(blocksJSON))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,93 @@
+// Package provenance indexes the code blocks captured in conversations so a piece
+// of code can be traced back to the conversation it came from, even after light
+// edits.
+package provenance
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	// ShingleSize is the number of tokens in each shingle. Smaller shingles match
+	// more loosely; an edit changes at most this many shingles per changed token.
+	ShingleSize = 5
+)
+
+// Normalize reduces code to the form that's hashed: lines are trimmed, runs of
+// whitespace collapse to one space, and blank lines are dropped, so indentation and
+// formatting changes don't affect matching
+func Normalize(code string) string {
+	var lines []string
+	for _, line := range strings.Split(code, "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ContentHash returns the SHA-256 of the normalized code
+func ContentHash(code string) string {
+	sum := sha256.Sum256([]byte(Normalize(code)))
+	return hex.EncodeToString(sum[:])
+}
+
+// Shingles returns the distinct hashes of every window of ShingleSize consecutive
+// tokens, sorted. Code with fewer tokens is one shingle; code with no tokens has none.
+func Shingles(code string) []int64 {
+	tokens := tokenize(code)
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	windows := max(len(tokens)-ShingleSize+1, 1)
+	seen := make(map[int64]bool, windows)
+	shingles := make([]int64, 0, windows)
+	for i := 0; i < windows; i++ {
+		h := fnv.New64a()
+		for _, token := range tokens[i:min(i+ShingleSize, len(tokens))] {
+			h.Write([]byte(token))
+			h.Write([]byte{0})
+		}
+		// Stored as SQLite's signed 64-bit integer
+		sum := int64(h.Sum64())
+		if !seen[sum] {
+			seen[sum] = true
+			shingles = append(shingles, sum)
+		}
+	}
+
+	sort.Slice(shingles, func(i, j int) bool { return shingles[i] < shingles[j] })
+	return shingles
+}
+
+// tokenize splits code into identifiers, numbers, and individual symbols
+func tokenize(code string) []string {
+	var tokens []string
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			tokens = append(tokens, word.String())
+			word.Reset()
+		}
+	}
+
+	for _, r := range code {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			word.WriteRune(r)
+		case unicode.IsSpace(r):
+			flush()
+		default:
+			flush()
+			tokens = append(tokens, string(r))
+		}
+	}
+	flush()
+	return tokens
+}
@@ -0,0 +1,138 @@
+package quality
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// StatusResolved marks a conversation that reached a working result
+	StatusResolved = "resolved"
+	// StatusAbandoned marks a conversation that went idle without one
+	StatusAbandoned = "abandoned"
+	// StatusOpen marks a conversation that may still continue
+	StatusOpen = "open"
+
+	// DefaultIdleTimeout is how long a conversation must be quiet before it's
+	// classified as resolved or abandoned
+	DefaultIdleTimeout = 24 * time.Hour
+	// duplicateSimilarity is the word overlap above which a user turn repeats the previous one
+	duplicateSimilarity = 0.8
+)
+
+var (
+	// errorPattern matches messages reporting an error
+	errorPattern = regexp.MustCompile(`(?i)\b(error|errors|exception|panic|panicked|traceback|stack ?trace|segfault|crash|crashed|crashes|failed|failing|fails)\b`)
+	// retryPattern matches user turns asking for another attempt at the same thing
+	retryPattern = regexp.MustCompile(`(?i)\b(try again|retry|still (not|doesn't|does not|isn't|is not|fails|failing|broken|getting|seeing|wrong)|(didn't|did not|doesn't|does not) work|not working|same (error|issue|problem)|that's (wrong|not right)|undo that|revert that)\b`)
+	// resolvedPattern matches user turns confirming the result works
+	resolvedPattern = regexp.MustCompile(`(?i)\b(thanks|thank you|thx|perfect|works now|that works|it works|that (fixed|worked|did) it|lgtm|looks good|great,? (that|it|thanks)|awesome)\b`)
+	// wordPattern splits turns into words for duplicate detection
+	wordPattern = regexp.MustCompile(`[\p{L}\p{N}_]+`)
+)
+
+// Message is a conversation message as seen by the analyzer
+type Message struct {
+	Role      string // "user" or "agent"
+	Text      string
+	CreatedAt time.Time
+}
+
+// Metrics are the quality metrics of one conversation
+type Metrics struct {
+	Turns             int    // User messages
+	TurnsToResolution int    // User turns before the conversation resolved; zero unless resolved
+	Retries           int    // User turns asking to redo or fix the previous attempt
+	ErrorMentions     int    // User turns reporting an error
+	Status            string // StatusResolved, StatusAbandoned, or StatusOpen
+	StartedAt         time.Time
+	EndedAt           time.Time
+}
+
+// Analyze computes a conversation's metrics from its messages, oldest first. A
+// conversation resolves at the first user turn confirming the result works. One
+// that's been idle for idleTimeout without a confirmation is abandoned when it
+// ended on a user turn, a retry, or an error report, and otherwise counts as
+// resolved once the agent had the last word.
+func Analyze(messages []Message, now time.Time, idleTimeout time.Duration) Metrics {
+	if idleTimeout <= 0 {
+		idleTimeout = DefaultIdleTimeout
+	}
+
+	var m Metrics
+	if len(messages) == 0 {
+		m.Status = StatusOpen
+		return m
+	}
+	m.StartedAt = messages[0].CreatedAt
+	m.EndedAt = messages[len(messages)-1].CreatedAt
+
+	var previous []string
+	agentReplied := false
+	lastTurnTroubled := false
+	for _, msg := range messages {
+		if msg.Role != "user" {
+			agentReplied = agentReplied || m.Turns > 0
+			continue
+		}
+
+		words := wordPattern.FindAllString(strings.ToLower(msg.Text), -1)
+		retry := m.Turns > 0 && (retryPattern.MatchString(msg.Text) || similarity(previous, words) >= duplicateSimilarity)
+		mentionsError := errorPattern.MatchString(msg.Text)
+		if retry {
+			m.Retries++
+		}
+		if mentionsError {
+			m.ErrorMentions++
+		}
+
+		if m.Status == "" && agentReplied && !retry && !mentionsError && resolvedPattern.MatchString(msg.Text) {
+			m.Status = StatusResolved
+			m.TurnsToResolution = m.Turns
+		}
+		m.Turns++
+		previous = words
+		lastTurnTroubled = retry || mentionsError
+	}
+
+	if m.Status != "" {
+		return m
+	}
+	switch {
+	case now.Sub(m.EndedAt) < idleTimeout:
+		m.Status = StatusOpen
+	case messages[len(messages)-1].Role == "user" || lastTurnTroubled || m.Turns == 0:
+		m.Status = StatusAbandoned
+	default:
+		m.Status = StatusResolved
+		m.TurnsToResolution = m.Turns
+	}
+	return m
+}
+
+// similarity returns the Jaccard similarity of two word lists
+func similarity(a, b []string) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	set := make(map[string]bool, len(a))
+	for _, w := range a {
+		set[w] = true
+	}
+	shared := 0
+	union := len(set)
+	seen := make(map[string]bool, len(b))
+	for _, w := range b {
+		if seen[w] {
+			continue
+		}
+		seen[w] = true
+		if set[w] {
+			shared++
+		} else {
+			union++
+		}
+	}
+	return float64(shared) / float64(union)
+}
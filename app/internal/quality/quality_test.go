@@ -0,0 +1,236 @@
+package quality
+
+import (
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/blobs"
+	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/logging"
+	_ "modernc.org/sqlite"
+)
+
+// conversation builds alternating messages a minute apart, starting with the user
+func conversation(start time.Time, turns ...string) []Message {
+	messages := make([]Message, len(turns))
+	for i, text := range turns {
+		role := "user"
+		if i%2 == 1 {
+			role = "agent"
+		}
+		messages[i] = Message{Role: role, Text: text, CreatedAt: start.Add(time.Duration(i) * time.Minute)}
+	}
+	return messages
+}
+
+func TestAnalyze(t *testing.T) {
+	start := time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)
+	later := start.Add(48 * time.Hour)
+
+	tests := []struct {
+		name       string
+		messages   []Message
+		now        time.Time
+		status     string
+		resolution int
+		retries    int
+		errors     int
+	}{
+		{
+			name: "confirmed",
+			messages: conversation(start, "add a flag", "done", "that fails with error: unknown flag", "fixed",
+				"still not working", "try this", "thanks, works now", "glad to help"),
+			now:    later,
+			status: StatusResolved, resolution: 3, retries: 1, errors: 1,
+		},
+		{
+			name:     "ended quietly",
+			messages: conversation(start, "rename the package", "renamed", "also update the imports", "updated"),
+			now:      later,
+			status:   StatusResolved, resolution: 2,
+		},
+		{
+			name:     "unanswered",
+			messages: conversation(start, "explain the cache", "it stores results", "why is it slow?"),
+			now:      later,
+			status:   StatusAbandoned,
+		},
+		{
+			name:     "gave up after an error",
+			messages: conversation(start, "run the migration", "ran it", "it panicked with a nil pointer", "try again"),
+			now:      later,
+			status:   StatusAbandoned, errors: 1,
+		},
+		{
+			name:     "repeated request",
+			messages: conversation(start, "make the build pass on windows", "changed paths", "make the build pass on windows please", "changed more"),
+			now:      later,
+			status:   StatusAbandoned, retries: 1,
+		},
+		{
+			name:     "recent",
+			messages: conversation(start, "explain the cache"),
+			now:      start.Add(time.Hour),
+			status:   StatusOpen,
+		},
+		{
+			name:     "thanks before any answer",
+			messages: conversation(start, "thanks in advance, add tests", "added"),
+			now:      start.Add(time.Hour),
+			status:   StatusOpen,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := Analyze(tt.messages, tt.now, DefaultIdleTimeout)
+			if m.Status != tt.status || m.TurnsToResolution != tt.resolution || m.Retries != tt.retries || m.ErrorMentions != tt.errors {
+				t.Errorf("Analyze() = %+v, want status %s, %d turns to resolution, %d retries, %d error mentions",
+					m, tt.status, tt.resolution, tt.retries, tt.errors)
+			}
+		})
+	}
+}
+
+func TestStore_SyncAndReport(t *testing.T) {
+	database, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "clio.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer database.Close()
+	if err := db.RunMigrations(database); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	start := time.Date(2024, 3, 4, 10, 0, 0, 0, time.Local) // A Monday
+	insertConversation := func(id, project string, messages []Message) {
+		if _, err := database.Exec(`
+			INSERT OR IGNORE INTO sessions (id, project, start_time, last_activity, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, "session-"+project, project, start, start, start, start); err != nil {
+			t.Fatalf("failed to create session: %v", err)
+		}
+		if _, err := database.Exec(`
+			INSERT OR IGNORE INTO conversations (id, session_id, composer_id, message_count, created_at, updated_at)
+			VALUES (?, ?, ?, 0, ?, ?)
+		`, id, "session-"+project, id, start, start); err != nil {
+			t.Fatalf("failed to create conversation: %v", err)
+		}
+		for i, msg := range messages {
+			messageID := id + "-" + msg.CreatedAt.Format(time.RFC3339)
+			if _, err := database.Exec(`
+				INSERT OR IGNORE INTO messages (id, conversation_id, bubble_id, type, role, content, created_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?)
+			`, messageID, id, messageID, i%2+1, msg.Role, msg.Text, msg.CreatedAt); err != nil {
+				t.Fatalf("failed to create message: %v", err)
+			}
+		}
+	}
+
+	insertConversation("c1", "alpha", conversation(start, "add a flag", "done", "thanks", "welcome"))
+	insertConversation("c2", "alpha", conversation(start.Add(7*24*time.Hour), "fix the error in main", "fixed", "same error"))
+	insertConversation("c3", "beta", conversation(start.Add(7*24*time.Hour), "write docs"))
+
+	store, err := NewStore(database, nil, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+
+	now := start.Add(7*24*time.Hour + time.Hour)
+	computed, err := store.Sync(now)
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if computed != 3 {
+		t.Errorf("Sync() computed %d conversations, want 3", computed)
+	}
+
+	// Only open conversations are recomputed when nothing changed
+	computed, err = store.Sync(now)
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if computed != 2 {
+		t.Errorf("second Sync() computed %d conversations, want the 2 open ones", computed)
+	}
+
+	later := now.Add(48 * time.Hour)
+	if _, err := store.Sync(later); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	report, err := store.Report(ReportOptions{})
+	if err != nil {
+		t.Fatalf("Report() error = %v", err)
+	}
+	if report.Total.Conversations != 3 || report.Total.Resolved != 1 || report.Total.Abandoned != 2 || report.Total.Retries != 1 {
+		t.Errorf("total = %+v, want 3 conversations, 1 resolved, 2 abandoned, 1 retry", report.Total)
+	}
+	if len(report.Weeks) != 2 || !report.Weeks[0].Start.Equal(time.Date(2024, 3, 4, 0, 0, 0, 0, time.Local)) || report.Weeks[1].Conversations != 2 {
+		t.Errorf("weeks = %+v, want two weeks starting on %v", report.Weeks, start)
+	}
+	if len(report.Projects) != 2 || report.Projects[0].Project != "alpha" || report.Projects[0].ResolutionRate() != 0.5 {
+		t.Errorf("projects = %+v, want alpha first with half its conversations resolved", report.Projects)
+	}
+
+	filtered, err := store.Report(ReportOptions{Project: "BETA"})
+	if err != nil {
+		t.Fatalf("Report() error = %v", err)
+	}
+	if filtered.Total.Conversations != 1 {
+		t.Errorf("filtered total = %+v, want only beta's conversation", filtered.Total)
+	}
+}
+
+func TestStore_SyncResolvesBlobs(t *testing.T) {
+	database, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "clio.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer database.Close()
+	if err := db.RunMigrations(database); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	blobStore, err := blobs.NewStore(filepath.Join(t.TempDir(), "blobs"), logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+
+	// The error report lies past the preview the row keeps
+	long := strings.Repeat("pasting the log ", blobs.PreviewLength/16+1) + "and it panicked"
+	ref, err := blobStore.Put([]byte(long))
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	start := time.Date(2024, 3, 4, 10, 0, 0, 0, time.Local)
+	if _, err := database.Exec(`
+		INSERT INTO sessions (id, project, start_time, last_activity, created_at, updated_at) VALUES ('s1', 'alpha', ?, ?, ?, ?);
+		INSERT INTO conversations (id, session_id, composer_id, message_count, created_at, updated_at) VALUES ('c1', 's1', 'c1', 0, ?, ?);
+	`, start, start, start, start, start, start); err != nil {
+		t.Fatalf("failed to create conversation: %v", err)
+	}
+	if _, err := database.Exec(`
+		INSERT INTO messages (id, conversation_id, bubble_id, type, role, content, content_blob, created_at)
+		VALUES ('m1', 'c1', 'm1', 1, 'user', ?, ?, ?)
+	`, blobs.Preview(long), ref, start); err != nil {
+		t.Fatalf("failed to create message: %v", err)
+	}
+
+	store, err := NewStore(database, blobStore, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	if _, err := store.Sync(start.Add(time.Hour)); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	report, err := store.Report(ReportOptions{})
+	if err != nil {
+		t.Fatalf("Report() error = %v", err)
+	}
+	if report.Total.ErrorMentions != 1 {
+		t.Errorf("error mentions = %d, want the one past the preview", report.Total.ErrorMentions)
+	}
+}
@@ -0,0 +1,319 @@
+package quality
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/blobs"
+	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+// ReportOptions filters the quality report
+type ReportOptions struct {
+	Project string    // Only include this project (case-insensitive); empty includes all
+	Since   time.Time // Only include conversations started at or after this time; zero means no lower bound
+	Until   time.Time // Only include conversations started before this time; zero means no upper bound
+}
+
+// Summary aggregates the metrics of a group of conversations
+type Summary struct {
+	Conversations   int
+	Resolved        int
+	Abandoned       int
+	Open            int
+	ResolutionTurns int // Sum of turns to resolution over resolved conversations
+	Retries         int
+	ErrorMentions   int
+}
+
+// ResolutionRate returns the share of finished conversations that were resolved
+func (s Summary) ResolutionRate() float64 {
+	return ratio(s.Resolved, s.Resolved+s.Abandoned)
+}
+
+// AvgTurnsToResolution returns the mean turns to resolution of resolved conversations
+func (s Summary) AvgTurnsToResolution() float64 {
+	return ratio(s.ResolutionTurns, s.Resolved)
+}
+
+// RetriesPerConversation returns the mean retries per conversation
+func (s Summary) RetriesPerConversation() float64 {
+	return ratio(s.Retries, s.Conversations)
+}
+
+// ErrorsPerConversation returns the mean error mentions per conversation
+func (s Summary) ErrorsPerConversation() float64 {
+	return ratio(s.ErrorMentions, s.Conversations)
+}
+
+// add includes a conversation's metrics in the summary
+func (s *Summary) add(m Metrics) {
+	s.Conversations++
+	switch m.Status {
+	case StatusResolved:
+		s.Resolved++
+		s.ResolutionTurns += m.TurnsToResolution
+	case StatusAbandoned:
+		s.Abandoned++
+	default:
+		s.Open++
+	}
+	s.Retries += m.Retries
+	s.ErrorMentions += m.ErrorMentions
+}
+
+// WeekSummary is the summary of conversations started in one week
+type WeekSummary struct {
+	Start time.Time // Monday 00:00 local time
+	Summary
+}
+
+// ProjectSummary is the summary of one project's conversations
+type ProjectSummary struct {
+	Project string
+	Summary
+}
+
+// Report is the quality report for a time range
+type Report struct {
+	Total    Summary
+	Weeks    []WeekSummary    // Oldest first
+	Projects []ProjectSummary // Most conversations first
+}
+
+// Store defines the interface for derived conversation quality metrics
+type Store interface {
+	// Sync computes metrics for conversations that are new, gained messages, or
+	// were still open, and returns how many were computed
+	Sync(now time.Time) (int, error)
+	// Report summarizes stored metrics by week and by project
+	Report(opts ReportOptions) (*Report, error)
+}
+
+// store implements Store with the conversation_metrics table
+type store struct {
+	db     *sql.DB
+	blobs  blobs.Store
+	logger logging.Logger
+}
+
+// storedConversation is a conversation row considered for a sync
+type storedConversation struct {
+	id           string
+	sessionID    string
+	project      string
+	messageCount int
+}
+
+// NewStore creates a quality metrics store over the database's conversations.
+// blobStore resolves messages too large to store inline; nil analyzes their previews.
+func NewStore(db *sql.DB, blobStore blobs.Store, logger logging.Logger) (Store, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database cannot be nil")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	return &store{
+		db:     db,
+		blobs:  blobStore,
+		logger: logger.With("component", "quality"),
+	}, nil
+}
+
+// Sync recomputes stale conversation metrics
+func (s *store) Sync(now time.Time) (int, error) {
+	// Foreign keys aren't enforced, so metrics of deleted conversations are removed explicitly
+	if _, err := s.db.Exec("DELETE FROM conversation_metrics WHERE conversation_id NOT IN (SELECT id FROM conversations)"); err != nil {
+		return 0, fmt.Errorf("failed to remove stale conversation metrics: %w", err)
+	}
+
+	pending, err := s.pendingConversations()
+	if err != nil {
+		return 0, err
+	}
+
+	computed := 0
+	for _, conv := range pending {
+		messages, err := s.loadMessages(conv.id)
+		if err != nil {
+			return computed, err
+		}
+		if len(messages) == 0 {
+			continue
+		}
+
+		m := Analyze(messages, now, DefaultIdleTimeout)
+		var turnsToResolution sql.NullInt64
+		if m.Status == StatusResolved {
+			turnsToResolution = sql.NullInt64{Int64: int64(m.TurnsToResolution), Valid: true}
+		}
+		if _, err := s.db.Exec(`
+			INSERT INTO conversation_metrics (conversation_id, session_id, project, message_count, turns, turns_to_resolution,
+				retries, error_mentions, status, started_at, ended_at, computed_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT(conversation_id) DO UPDATE SET
+				session_id = excluded.session_id,
+				project = excluded.project,
+				message_count = excluded.message_count,
+				turns = excluded.turns,
+				turns_to_resolution = excluded.turns_to_resolution,
+				retries = excluded.retries,
+				error_mentions = excluded.error_mentions,
+				status = excluded.status,
+				started_at = excluded.started_at,
+				ended_at = excluded.ended_at,
+				computed_at = excluded.computed_at
+		`, conv.id, conv.sessionID, conv.project, len(messages), m.Turns, turnsToResolution,
+			m.Retries, m.ErrorMentions, m.Status, m.StartedAt, m.EndedAt, now); err != nil {
+			return computed, fmt.Errorf("failed to store conversation metrics: %w", err)
+		}
+		computed++
+	}
+
+	if computed > 0 {
+		s.logger.Debug("computed conversation metrics", "conversations", computed)
+	}
+	return computed, nil
+}
+
+// pendingConversations returns conversations without metrics, with a different
+// message count than their metrics were computed from, or still open
+func (s *store) pendingConversations() ([]storedConversation, error) {
+	rows, err := s.db.Query(`
+		SELECT c.id, c.session_id, COALESCE(s.project, ''),
+			(SELECT COUNT(*) FROM messages m WHERE m.conversation_id = c.id)
+		FROM conversations c
+		LEFT JOIN sessions s ON s.id = c.session_id
+		LEFT JOIN conversation_metrics q ON q.conversation_id = c.id
+		WHERE q.conversation_id IS NULL OR q.status = ?
+			OR q.message_count != (SELECT COUNT(*) FROM messages m WHERE m.conversation_id = c.id)
+	`, StatusOpen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query conversations: %w", err)
+	}
+	defer rows.Close()
+
+	var pending []storedConversation
+	for rows.Next() {
+		var conv storedConversation
+		if err := rows.Scan(&conv.id, &conv.sessionID, &conv.project, &conv.messageCount); err != nil {
+			return nil, fmt.Errorf("failed to scan conversation: %w", err)
+		}
+		if conv.messageCount > 0 {
+			pending = append(pending, conv)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating conversations: %w", err)
+	}
+	return pending, nil
+}
+
+// loadMessages returns a conversation's messages, oldest first
+func (s *store) loadMessages(conversationID string) ([]Message, error) {
+	rows, err := s.db.Query("SELECT role, content, content_blob, created_at FROM messages WHERE conversation_id = ?", conversationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []Message
+	for rows.Next() {
+		var msg Message
+		var ref sql.NullString
+		if err := rows.Scan(&msg.Role, &msg.Text, &ref, &msg.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan message: %w", err)
+		}
+		if msg.Text, err = blobs.Resolve(s.blobs, msg.Text, ref); err != nil {
+			s.logger.Warn("failed to load blob, analyzing the stored preview", "conversation_id", conversationID, "error", err)
+		}
+		messages = append(messages, msg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating messages: %w", err)
+	}
+
+	db.SortOldestFirst(messages, func(m Message) time.Time { return m.CreatedAt })
+	return messages, nil
+}
+
+// Report summarizes the stored metrics of conversations started in the range
+func (s *store) Report(opts ReportOptions) (*Report, error) {
+	rows, err := s.db.Query(`
+		SELECT project, turns, turns_to_resolution, retries, error_mentions, status, started_at
+		FROM conversation_metrics
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query conversation metrics: %w", err)
+	}
+	defer rows.Close()
+
+	report := &Report{}
+	weeks := make(map[time.Time]*WeekSummary)
+	projects := make(map[string]*ProjectSummary)
+	for rows.Next() {
+		var m Metrics
+		var project string
+		var turnsToResolution sql.NullInt64
+		if err := rows.Scan(&project, &m.Turns, &turnsToResolution, &m.Retries, &m.ErrorMentions, &m.Status, &m.StartedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan conversation metrics: %w", err)
+		}
+		if opts.Project != "" && !strings.EqualFold(opts.Project, project) {
+			continue
+		}
+		if !db.InRange(m.StartedAt, opts.Since, opts.Until) {
+			continue
+		}
+		m.TurnsToResolution = int(turnsToResolution.Int64)
+
+		report.Total.add(m)
+		start := weekStart(m.StartedAt)
+		if weeks[start] == nil {
+			weeks[start] = &WeekSummary{Start: start}
+		}
+		weeks[start].add(m)
+		if projects[project] == nil {
+			projects[project] = &ProjectSummary{Project: project}
+		}
+		projects[project].add(m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating conversation metrics: %w", err)
+	}
+
+	for _, week := range weeks {
+		report.Weeks = append(report.Weeks, *week)
+	}
+	sort.Slice(report.Weeks, func(i, j int) bool { return report.Weeks[i].Start.Before(report.Weeks[j].Start) })
+	for _, project := range projects {
+		report.Projects = append(report.Projects, *project)
+	}
+	sort.Slice(report.Projects, func(i, j int) bool {
+		if report.Projects[i].Conversations != report.Projects[j].Conversations {
+			return report.Projects[i].Conversations > report.Projects[j].Conversations
+		}
+		return report.Projects[i].Project < report.Projects[j].Project
+	})
+	return report, nil
+}
+
+// weekStart returns midnight on the Monday of t's week in local time
+func weekStart(t time.Time) time.Time {
+	t = t.Local()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+// ratio returns n/d, or zero when d is zero
+func ratio(n, d int) float64 {
+	if d == 0 {
+		return 0
+	}
+	return float64(n) / float64(d)
+}
@@ -0,0 +1,57 @@
+// Package recap holds the helpers shared by the digests clio drafts from
+// exported sessions, such as standups and retrospectives: naming a
+// conversation, a commit's subject, and durations for display.
+package recap
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/quality"
+	"github.com/stwalsh4118/clio/pkg/export"
+)
+
+// maxTopicLength truncates conversation topics
+const maxTopicLength = 80
+
+// ConversationTopic names a conversation by its name, falling back to its opening prompt
+func ConversationTopic(conversation export.Conversation) string {
+	topic := strings.TrimSpace(conversation.Name)
+	if topic == "" {
+		for _, msg := range conversation.Messages {
+			if msg.Role == "user" && strings.TrimSpace(msg.Text) != "" {
+				topic = strings.Join(strings.Fields(msg.Text), " ")
+				break
+			}
+		}
+	}
+	if runes := []rune(topic); len(runes) > maxTopicLength {
+		topic = string(runes[:maxTopicLength-3]) + "..."
+	}
+	return topic
+}
+
+// QualityMessages converts a conversation's messages for quality analysis
+func QualityMessages(conversation export.Conversation) []quality.Message {
+	messages := make([]quality.Message, len(conversation.Messages))
+	for i, msg := range conversation.Messages {
+		messages[i] = quality.Message{Role: msg.Role, Text: msg.Text, CreatedAt: msg.CreatedAt}
+	}
+	return messages
+}
+
+// CommitSubject returns the first line of a commit message
+func CommitSubject(message string) string {
+	subject, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	return strings.TrimSpace(subject)
+}
+
+// FormatDuration formats a duration as hours and minutes, e.g. 1h05m or 12m
+func FormatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	if d >= time.Hour {
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return fmt.Sprintf("%dm", int(d.Minutes()))
+}
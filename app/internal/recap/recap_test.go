@@ -0,0 +1,92 @@
+package recap
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/pkg/export"
+)
+
+func TestConversationTopic(t *testing.T) {
+	long := strings.Repeat("é", 100)
+
+	tests := []struct {
+		name         string
+		conversation export.Conversation
+		want         string
+	}{
+		{name: "named", conversation: export.Conversation{Name: "  Fix the watcher  "}, want: "Fix the watcher"},
+		{
+			name: "falls back to the first user prompt",
+			conversation: export.Conversation{Messages: []export.Message{
+				{Role: "agent", Text: "Hello"},
+				{Role: "user", Text: "   "},
+				{Role: "user", Text: "Why does\n\nthe build   fail?"},
+			}},
+			want: "Why does the build fail?",
+		},
+		{name: "empty", conversation: export.Conversation{}, want: ""},
+		{name: "truncated by rune", conversation: export.Conversation{Name: long}, want: strings.Repeat("é", 77) + "..."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ConversationTopic(tt.conversation); got != tt.want {
+				t.Errorf("ConversationTopic() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQualityMessages(t *testing.T) {
+	at := time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)
+	conversation := export.Conversation{Messages: []export.Message{
+		{Role: "user", Text: "It fails", CreatedAt: at},
+		{Role: "agent", Text: "Fixed", Thinking: "Look at the log", CreatedAt: at.Add(time.Minute)},
+	}}
+
+	messages := QualityMessages(conversation)
+	if len(messages) != 2 {
+		t.Fatalf("QualityMessages() returned %d messages, want 2", len(messages))
+	}
+	if got := messages[1]; got.Role != "agent" || got.Text != "Fixed" || !got.CreatedAt.Equal(at.Add(time.Minute)) {
+		t.Errorf("QualityMessages()[1] = %+v, want the agent reply", got)
+	}
+}
+
+func TestCommitSubject(t *testing.T) {
+	tests := []struct {
+		message string
+		want    string
+	}{
+		{message: "Fix watcher\n\nDetails here", want: "Fix watcher"},
+		{message: "\n  Add export  \n", want: "Add export"},
+		{message: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := CommitSubject(tt.message); got != tt.want {
+			t.Errorf("CommitSubject(%q) = %q, want %q", tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{d: 0, want: "0m"},
+		{d: 12*time.Minute + 20*time.Second, want: "12m"},
+		{d: 59*time.Minute + 40*time.Second, want: "1h00m"},
+		{d: time.Hour + 5*time.Minute, want: "1h05m"},
+		{d: 27*time.Hour + 30*time.Minute, want: "27h30m"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatDuration(tt.d); got != tt.want {
+			t.Errorf("FormatDuration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package releasenotes
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const (
+	// ChangelogName is the changelog file kept at a repository's root
+	ChangelogName = "CHANGELOG.md"
+	// unreleased names the section of changes not yet released
+	unreleased = "Unreleased"
+	// changelogHeader starts a new changelog
+	changelogHeader = `# Changelog
+
+All notable changes to this project will be documented in this file.
+
+The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.1.0/),
+and this project adheres to [Semantic Versioning](https://semver.org/spec/v2.0.0.html).
+`
+)
+
+// Changelog updates
+const (
+	ChangelogCreated   = "created"   // The file didn't exist
+	ChangelogAdded     = "added"     // The version's section was added
+	ChangelogReplaced  = "replaced"  // The version's section was regenerated with other entries
+	ChangelogUnchanged = "unchanged" // The version's section was already up to date
+)
+
+// changelogCategories maps Conventional Commits types to Keep a Changelog
+// categories, in the order they're rendered. Types without a category, such as
+// docs and chore, aren't notable to users and are left out.
+var changelogCategories = []struct {
+	title string
+	kinds []string
+}{
+	{"Added", []string{"feat"}},
+	{"Changed", []string{"perf", "refactor", "revert", ""}},
+	{"Fixed", []string{"fix"}},
+}
+
+// versionHeading matches a changelog's version headings, such as "## [1.2.0] - 2024-03-01"
+var versionHeading = regexp.MustCompile(`^## \[?([^\]\s]+)\]?`)
+
+// linkDefinition matches the link reference definitions that end a changelog
+var linkDefinition = regexp.MustCompile(`^\[[^\]]+\]: \S`)
+
+// changelogVersion returns the version a tag is listed under: the tag without a
+// leading v, or Unreleased for HEAD
+func changelogVersion(tag string) string {
+	if tag == "HEAD" {
+		return unreleased
+	}
+	if len(tag) > 1 && (tag[0] == 'v' || tag[0] == 'V') && tag[1] >= '0' && tag[1] <= '9' {
+		return tag[1:]
+	}
+	return tag
+}
+
+// ChangelogSection renders the notes as a Keep a Changelog section: features
+// under Added, fixes under Fixed, and performance, refactoring, reverts, and
+// other changes under Changed. Breaking changes are marked in their category.
+func (n *Notes) ChangelogSection() string {
+	var b strings.Builder
+	version := changelogVersion(n.To)
+	if version == unreleased {
+		fmt.Fprintf(&b, "## [%s]\n", version)
+	} else {
+		fmt.Fprintf(&b, "## [%s] - %s\n", version, n.Date.Local().Format("2006-01-02"))
+	}
+
+	byType := make(map[string][]Entry)
+	for _, section := range n.Sections {
+		byType[section.Type] = section.Entries
+	}
+	listed := false
+	for _, category := range changelogCategories {
+		var entries []Entry
+		for _, kind := range category.kinds {
+			entries = append(entries, byType[kind]...)
+		}
+		if len(entries) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n### %s\n\n", category.title)
+		for _, entry := range entries {
+			if entry.Breaking {
+				entry.Description = "**Breaking:** " + entry.Description
+			}
+			writeEntry(&b, entry)
+		}
+		listed = true
+	}
+	if !listed {
+		b.WriteString("\nNo notable changes.\n")
+	}
+	return b.String()
+}
+
+// UpdateChangelog returns the changelog text with the notes' section in place
+// and what changed. A version already listed has its section replaced, so
+// generating the same release again doesn't duplicate it; a new version is
+// inserted above the latest one, below Unreleased. Empty text starts a new
+// changelog.
+func UpdateChangelog(text string, notes *Notes) (string, string) {
+	section := notes.ChangelogSection()
+	if strings.TrimSpace(text) == "" {
+		return changelogHeader + "\n" + section, ChangelogCreated
+	}
+
+	version := changelogVersion(notes.To)
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	start, end, insert := -1, len(lines), -1
+	for i, line := range lines {
+		if start >= 0 {
+			if versionHeading.MatchString(line) || linkDefinition.MatchString(line) {
+				end = i
+				break
+			}
+			continue
+		}
+		groups := versionHeading.FindStringSubmatch(line)
+		if groups == nil {
+			if linkDefinition.MatchString(line) && insert < 0 {
+				insert = i
+			}
+			continue
+		}
+		if strings.EqualFold(changelogVersion(groups[1]), version) {
+			start = i
+			continue
+		}
+		if insert < 0 && (version == unreleased || !strings.EqualFold(groups[1], unreleased)) {
+			insert = i
+		}
+	}
+
+	if start >= 0 {
+		existing := strings.Join(lines[start:end], "\n")
+		if strings.TrimSpace(existing) == strings.TrimSpace(section) {
+			return text, ChangelogUnchanged
+		}
+		return joinChangelog(lines[:start], section, lines[end:]), ChangelogReplaced
+	}
+	if insert < 0 {
+		insert = len(lines)
+	}
+	return joinChangelog(lines[:insert], section, lines[insert:]), ChangelogAdded
+}
+
+// joinChangelog places a section between the lines before and after it,
+// separated by blank lines
+func joinChangelog(before []string, section string, after []string) string {
+	var b strings.Builder
+	if head := strings.TrimRight(strings.Join(before, "\n"), "\n"); head != "" {
+		b.WriteString(head)
+		b.WriteString("\n\n")
+	}
+	b.WriteString(strings.TrimRight(section, "\n"))
+	b.WriteString("\n")
+	if tail := strings.TrimLeft(strings.Join(after, "\n"), "\n"); tail != "" {
+		b.WriteString("\n")
+		b.WriteString(tail)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// WriteChangelog adds or replaces the notes' section in the changelog at path,
+// creating it when it doesn't exist, and returns what changed
+func WriteChangelog(path string, notes *Notes) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read changelog: %w", err)
+	}
+	updated, change := UpdateChangelog(string(data), notes)
+	if change == ChangelogUnchanged {
+		return change, nil
+	}
+	if err := os.WriteFile(path, []byte(updated), 0644); err != nil {
+		return "", fmt.Errorf("failed to write changelog: %w", err)
+	}
+	return change, nil
+}
@@ -0,0 +1,96 @@
+package releasenotes
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNotes_ChangelogSection(t *testing.T) {
+	date := time.Date(2024, 3, 1, 12, 0, 0, 0, time.Local)
+	notes := Build("v1.0.0", "v1.1.0", date, []Commit{
+		{Hash: "aaaa1111", Message: "feat(parser): add dates", Summary: "Parse ISO dates"},
+		{Hash: "bbbb2222", Message: "fix!: drop the legacy flag"},
+		{Hash: "cccc3333", Message: "docs: explain dates"},
+		{Hash: "dddd4444", Message: "Tidy imports"},
+	})
+
+	want := "## [1.1.0] - 2024-03-01\n\n" +
+		"### Added\n\n- **parser:** add dates (aaaa111)\n  - Parse ISO dates\n\n" +
+		"### Changed\n\n- Tidy imports (dddd444)\n\n" +
+		"### Fixed\n\n- **Breaking:** drop the legacy flag (bbbb222)\n"
+	if got := notes.ChangelogSection(); got != want {
+		t.Errorf("ChangelogSection() =\n%s\nwant\n%s", got, want)
+	}
+
+	unreleasedNotes := Build("v1.1.0", "HEAD", date, []Commit{{Hash: "eeee5555", Message: "chore: bump deps"}})
+	if got := unreleasedNotes.ChangelogSection(); got != "## [Unreleased]\n\nNo notable changes.\n" {
+		t.Errorf("ChangelogSection(HEAD) = %q", got)
+	}
+}
+
+func TestUpdateChangelog(t *testing.T) {
+	date := time.Date(2024, 3, 1, 12, 0, 0, 0, time.Local)
+	first := Build("v1.0.0", "v1.1.0", date, []Commit{{Hash: "aaaa1111", Message: "feat: add dates"}})
+
+	created, change := UpdateChangelog("", first)
+	if change != ChangelogCreated || !strings.HasPrefix(created, "# Changelog\n") || !strings.Contains(created, "## [1.1.0] - 2024-03-01") {
+		t.Fatalf("UpdateChangelog(empty) = %q, %q", created, change)
+	}
+	if again, change := UpdateChangelog(created, first); change != ChangelogUnchanged || again != created {
+		t.Errorf("UpdateChangelog(same notes) = %q, want unchanged", change)
+	}
+
+	existing := "# Changelog\n\n## [Unreleased]\n\n- Work in progress\n\n" +
+		"## [1.0.0] - 2024-01-01\n\n### Added\n\n- First release\n\n" +
+		"[1.0.0]: https://example.com/releases/v1.0.0\n"
+	added, change := UpdateChangelog(existing, first)
+	if change != ChangelogAdded {
+		t.Fatalf("UpdateChangelog(new version) change = %q, want added", change)
+	}
+	unreleasedAt := strings.Index(added, "## [Unreleased]")
+	newAt := strings.Index(added, "## [1.1.0]")
+	oldAt := strings.Index(added, "## [1.0.0]")
+	if unreleasedAt < 0 || newAt < unreleasedAt || oldAt < newAt {
+		t.Errorf("UpdateChangelog(new version) order wrong:\n%s", added)
+	}
+
+	second := Build("v1.0.0", "v1.1.0", date, []Commit{
+		{Hash: "aaaa1111", Message: "feat: add dates"},
+		{Hash: "bbbb2222", Message: "fix: parse leap days"},
+	})
+	replaced, change := UpdateChangelog(added, second)
+	if change != ChangelogReplaced {
+		t.Fatalf("UpdateChangelog(regenerated) change = %q, want replaced", change)
+	}
+	if strings.Count(replaced, "## [1.1.0]") != 1 || !strings.Contains(replaced, "parse leap days") {
+		t.Errorf("UpdateChangelog(regenerated) =\n%s", replaced)
+	}
+	if !strings.Contains(replaced, "- First release\n\n[1.0.0]: https://example.com/releases/v1.0.0\n") {
+		t.Errorf("UpdateChangelog(regenerated) lost the other sections:\n%s", replaced)
+	}
+}
+
+func TestWriteChangelog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ChangelogName)
+	notes := Build("v1.0.0", "v1.1.0", time.Now(), []Commit{{Hash: "aaaa1111", Message: "feat: add dates"}})
+
+	for _, want := range []string{ChangelogCreated, ChangelogUnchanged} {
+		change, err := WriteChangelog(path, notes)
+		if err != nil {
+			t.Fatalf("WriteChangelog() error = %v", err)
+		}
+		if change != want {
+			t.Errorf("WriteChangelog() = %q, want %q", change, want)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read changelog: %v", err)
+	}
+	if !strings.Contains(string(data), "- add dates (aaaa111)") {
+		t.Errorf("changelog = %q", data)
+	}
+}
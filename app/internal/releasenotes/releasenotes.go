@@ -0,0 +1,283 @@
+// Package releasenotes compiles Markdown release notes from the commits between
+// two tags, grouped by Conventional Commits type and annotated with a one-line
+// summary of the conversation each commit came out of.
+package releasenotes
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stwalsh4118/clio/internal/commitmsg"
+	"github.com/stwalsh4118/clio/internal/logging"
+	"github.com/stwalsh4118/clio/internal/summaries"
+)
+
+const (
+	// breakingTitle heads the section repeating breaking changes
+	breakingTitle = "⚠ Breaking Changes"
+	// otherTitle heads commits that don't follow Conventional Commits or have an unknown type
+	otherTitle = "Other Changes"
+	// maxSummaryLength truncates conversation summaries
+	maxSummaryLength = 120
+)
+
+// sectionTitles names the sections by Conventional Commits type, in the order
+// they're rendered
+var sectionTitles = []struct{ kind, title string }{
+	{"feat", "Features"},
+	{"fix", "Bug Fixes"},
+	{"perf", "Performance"},
+	{"refactor", "Refactoring"},
+	{"revert", "Reverts"},
+	{"docs", "Documentation"},
+	{"test", "Tests"},
+	{"build", "Build"},
+	{"ci", "Continuous Integration"},
+	{"style", "Style"},
+	{"chore", "Chores"},
+}
+
+// Commit is a commit in the released range
+type Commit struct {
+	Hash    string
+	Message string
+	Merge   bool
+	Summary string // One-line summary of the conversation behind the commit; empty when there's none
+}
+
+// Entry is a commit listed in the notes
+type Entry struct {
+	Hash        string
+	Scope       string // Empty when the commit has none
+	Description string // The description after the type, or the whole subject of other changes
+	Breaking    bool
+	Summary     string
+}
+
+// Section is the entries of one commit type
+type Section struct {
+	Type    string // Conventional Commits type; empty for other changes
+	Title   string
+	Entries []Entry // In the order of the commits given to Build
+}
+
+// Notes are the release notes of a range
+type Notes struct {
+	From     string // The previous release's tag
+	To       string // The released tag
+	Date     time.Time
+	Breaking []Entry   // Breaking changes, also listed in their type's section
+	Sections []Section // Sections with entries, in sectionTitles order then other changes
+}
+
+// Build groups commits into release notes. Merge commits and fixup! or squash!
+// commits, which describe history rather than changes, are left out.
+func Build(from, to string, date time.Time, commits []Commit) *Notes {
+	notes := &Notes{From: from, To: to, Date: date}
+	byType := make(map[string][]Entry)
+	for _, commit := range commits {
+		if commit.Merge {
+			continue
+		}
+		if followUp, ok := commitmsg.ParseFollowUp(commit.Message); ok && followUp.Kind == commitmsg.FollowUpFixup {
+			continue
+		}
+		entry := Entry{Hash: commit.Hash, Summary: commit.Summary}
+		kind := ""
+		if parsed, ok := commitmsg.ParseConventional(commit.Message); ok && knownType(parsed.Type) {
+			kind = parsed.Type
+			entry.Scope, entry.Description, entry.Breaking = parsed.Scope, parsed.Description, parsed.Breaking
+		} else {
+			entry.Description = commitmsg.Subject(commit.Message)
+		}
+		if entry.Breaking {
+			notes.Breaking = append(notes.Breaking, entry)
+		}
+		byType[kind] = append(byType[kind], entry)
+	}
+
+	for _, section := range sectionTitles {
+		if entries := byType[section.kind]; len(entries) > 0 {
+			notes.Sections = append(notes.Sections, Section{Type: section.kind, Title: section.title, Entries: entries})
+		}
+	}
+	if entries := byType[""]; len(entries) > 0 {
+		notes.Sections = append(notes.Sections, Section{Title: otherTitle, Entries: entries})
+	}
+	return notes
+}
+
+// knownType reports whether kind has a section
+func knownType(kind string) bool {
+	for _, section := range sectionTitles {
+		if section.kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Markdown renders the notes for a GitHub release: a heading with the tag and
+// date, breaking changes first, then a section per type. Each entry shows its
+// scope, description, and abbreviated hash, which GitHub links to the commit,
+// with its conversation summary nested beneath.
+func (n *Notes) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s (%s)\n", n.To, n.Date.Local().Format("2006-01-02"))
+	if len(n.Breaking) == 0 && len(n.Sections) == 0 {
+		fmt.Fprintf(&b, "\nNo changes since %s.\n", n.From)
+		return b.String()
+	}
+	if len(n.Breaking) > 0 {
+		fmt.Fprintf(&b, "\n### %s\n\n", breakingTitle)
+		for _, entry := range n.Breaking {
+			writeEntry(&b, entry)
+		}
+	}
+	for _, section := range n.Sections {
+		fmt.Fprintf(&b, "\n### %s\n\n", section.Title)
+		for _, entry := range section.Entries {
+			writeEntry(&b, entry)
+		}
+	}
+	fmt.Fprintf(&b, "\n**Full Changelog**: %s...%s\n", n.From, n.To)
+	return b.String()
+}
+
+// writeEntry renders an entry as a list item
+func writeEntry(b *strings.Builder, entry Entry) {
+	b.WriteString("- ")
+	if entry.Scope != "" {
+		fmt.Fprintf(b, "**%s:** ", entry.Scope)
+	}
+	fmt.Fprintf(b, "%s (%s)\n", entry.Description, shortHash(entry.Hash))
+	if entry.Summary != "" {
+		fmt.Fprintf(b, "  - %s\n", entry.Summary)
+	}
+}
+
+// shortHash abbreviates a commit hash as git does by default
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
+
+// Enricher summarizes the conversations behind commits
+type Enricher struct {
+	db         *sql.DB
+	cache      summaries.Cache
+	summarizer summaries.Summarizer
+	logger     logging.Logger
+	// byConversation keeps each conversation's line, as several commits often come out of one
+	byConversation map[string]string
+}
+
+// NewEnricher creates an enricher summarizing with summarizer through cache, so
+// conversations already summarized aren't summarized again
+func NewEnricher(db *sql.DB, cache summaries.Cache, summarizer summaries.Summarizer, logger logging.Logger) (*Enricher, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database cannot be nil")
+	}
+	if cache == nil {
+		return nil, fmt.Errorf("summary cache cannot be nil")
+	}
+	if summarizer == nil {
+		return nil, fmt.Errorf("summarizer cannot be nil")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+	return &Enricher{
+		db:             db,
+		cache:          cache,
+		summarizer:     summarizer,
+		logger:         logger.With("component", "releasenotes"),
+		byConversation: make(map[string]string),
+	}, nil
+}
+
+// Summary returns a one-line summary of the conversation a commit came out of:
+// the conversation of its correlated session with the latest message at or
+// before the commit. It's empty when clio didn't capture the commit, or the
+// commit has no session or conversation before it.
+func (e *Enricher) Summary(ctx context.Context, hash string) (string, error) {
+	conversationID, err := e.conversation(hash)
+	if err != nil || conversationID == "" {
+		return "", err
+	}
+	if line, ok := e.byConversation[conversationID]; ok {
+		return line, nil
+	}
+	summary, err := e.cache.Conversation(ctx, conversationID, e.summarizer)
+	if err != nil {
+		return "", fmt.Errorf("failed to summarize conversation %s: %w", conversationID, err)
+	}
+	line := firstLine(summary.Text)
+	e.logger.Debug("summarized commit conversation", "hash", hash, "conversation", conversationID, "cached", summary.Cached)
+	e.byConversation[conversationID] = line
+	return line, nil
+}
+
+// conversation returns the ID of the conversation a commit came out of, or ""
+func (e *Enricher) conversation(hash string) (string, error) {
+	var sessionID string
+	var committedAt time.Time
+	err := e.db.QueryRow(`SELECT session_id, timestamp FROM commits WHERE hash = ? AND session_id IS NOT NULL`, hash).Scan(&sessionID, &committedAt)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to query commit: %w", err)
+	}
+
+	rows, err := e.db.Query(`
+		SELECT m.conversation_id, m.created_at
+		FROM messages m
+		JOIN conversations c ON c.id = m.conversation_id
+		WHERE c.session_id = ?
+	`, sessionID)
+	if err != nil {
+		return "", fmt.Errorf("failed to query messages: %w", err)
+	}
+	defer rows.Close()
+
+	var conversationID string
+	var latest time.Time
+	for rows.Next() {
+		var id string
+		var createdAt time.Time
+		if err := rows.Scan(&id, &createdAt); err != nil {
+			return "", fmt.Errorf("failed to scan message: %w", err)
+		}
+		if createdAt.After(committedAt) || conversationID != "" && !createdAt.After(latest) {
+			continue
+		}
+		conversationID, latest = id, createdAt
+	}
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("error iterating messages: %w", err)
+	}
+	return conversationID, nil
+}
+
+// firstLine returns a summary's first non-empty line without a list marker,
+// truncated
+func firstLine(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "-*•"))
+		if line == "" {
+			continue
+		}
+		if utf8.RuneCountInString(line) > maxSummaryLength {
+			line = string([]rune(line)[:maxSummaryLength-1]) + "…"
+		}
+		return line
+	}
+	return ""
+}
@@ -0,0 +1,166 @@
+package releasenotes
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/logging"
+	"github.com/stwalsh4118/clio/internal/summaries"
+	_ "modernc.org/sqlite"
+)
+
+func TestBuild(t *testing.T) {
+	commits := []Commit{
+		{Hash: "aaaa1111aaaa", Message: "feat(parser): add dates", Summary: "Parse ISO dates in the lexer"},
+		{Hash: "bbbb2222bbbb", Message: "fix!: drop the legacy flag"},
+		{Hash: "cccc3333cccc", Message: "Update README"},
+		{Hash: "dddd4444dddd", Message: "fixup! feat(parser): add dates"},
+		{Hash: "eeee5555eeee", Message: "Merge branch 'dates'", Merge: true},
+		{Hash: "ffff6666ffff", Message: "feat: export notes"},
+		{Hash: "9999aaaa9999", Message: "wip: unknown type"},
+	}
+	notes := Build("v1.0.0", "v1.1.0", time.Date(2024, 3, 1, 12, 0, 0, 0, time.Local), commits)
+
+	if len(notes.Breaking) != 1 || notes.Breaking[0].Hash != "bbbb2222bbbb" {
+		t.Errorf("Breaking = %+v, want the fix!", notes.Breaking)
+	}
+	var titles []string
+	for _, section := range notes.Sections {
+		titles = append(titles, section.Title)
+	}
+	if got := strings.Join(titles, ","); got != "Features,Bug Fixes,Other Changes" {
+		t.Errorf("sections = %s, want Features,Bug Fixes,Other Changes", got)
+	}
+	if features := notes.Sections[0].Entries; len(features) != 2 || features[0].Scope != "parser" || features[1].Description != "export notes" {
+		t.Errorf("features = %+v, want add dates then export notes", features)
+	}
+	if other := notes.Sections[2].Entries; len(other) != 2 || other[1].Description != "wip: unknown type" {
+		t.Errorf("other changes = %+v, want the README and unknown type commits", other)
+	}
+
+	markdown := notes.Markdown()
+	for _, want := range []string{
+		"## v1.1.0 (2024-03-01)",
+		"### ⚠ Breaking Changes\n\n- drop the legacy flag (bbbb222)\n",
+		"- **parser:** add dates (aaaa111)\n  - Parse ISO dates in the lexer\n",
+		"**Full Changelog**: v1.0.0...v1.1.0",
+	} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("Markdown() missing %q:\n%s", want, markdown)
+		}
+	}
+	if strings.Contains(markdown, "dddd444") || strings.Contains(markdown, "eeee555") {
+		t.Errorf("Markdown() lists fixup or merge commits:\n%s", markdown)
+	}
+
+	empty := Build("v1.0.0", "v1.0.1", time.Now(), nil).Markdown()
+	if !strings.Contains(empty, "No changes since v1.0.0.") {
+		t.Errorf("Markdown() of no commits = %q", empty)
+	}
+}
+
+func TestFirstLine(t *testing.T) {
+	tests := map[string]string{
+		"- Parse dates\n- Add tests": "Parse dates",
+		"\n\n  Plain summary  ":      "Plain summary",
+		"":                           "",
+		strings.Repeat("x", 200):     strings.Repeat("x", maxSummaryLength-1) + "…",
+	}
+	for text, want := range tests {
+		if got := firstLine(text); got != want {
+			t.Errorf("firstLine(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+// promptSummarizer summarizes as the first message, counting its calls
+type promptSummarizer struct {
+	calls int
+}
+
+func (s *promptSummarizer) Name() string { return "test" }
+
+func (s *promptSummarizer) Summarize(_ context.Context, input *summaries.Input) (string, error) {
+	s.calls++
+	if len(input.Messages) == 0 {
+		return "", nil
+	}
+	return "- " + input.Messages[0].Text, nil
+}
+
+func TestEnricher_Summary(t *testing.T) {
+	database, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "clio.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if err := db.RunMigrations(database); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	base := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	for _, statement := range []struct {
+		query string
+		args  []interface{}
+	}{
+		{`INSERT INTO sessions (id, project, start_time, last_activity, created_at, updated_at) VALUES ('s1', 'clio', ?, ?, ?, ?)`,
+			[]interface{}{base, base, base, base}},
+		{`INSERT INTO conversations (id, session_id, composer_id, name, status, message_count, created_at, updated_at)
+			VALUES ('c1', 's1', 'c1', 'Dates', 'completed', 1, ?, ?), ('c2', 's1', 'c2', 'Later', 'completed', 1, ?, ?)`,
+			[]interface{}{base, base, base, base}},
+		{`INSERT INTO messages (id, conversation_id, bubble_id, type, role, content, created_at)
+			VALUES ('m1', 'c1', 'm1', 1, 'user', 'Parse dates', ?), ('m2', 'c2', 'm2', 1, 'user', 'Add docs', ?)`,
+			[]interface{}{base.Add(10 * time.Minute), base.Add(2 * time.Hour)}},
+	} {
+		if _, err := database.Exec(statement.query, statement.args...); err != nil {
+			t.Fatalf("failed to insert test data: %v", err)
+		}
+	}
+	for _, commit := range []struct {
+		hash    string
+		session interface{}
+	}{{"aaaa1111", "s1"}, {"bbbb2222", "s1"}, {"cccc3333", nil}} {
+		if _, err := database.Exec(`
+			INSERT INTO commits (id, session_id, repository_path, repository_name, hash, message,
+				author_name, author_email, timestamp, branch, created_at, updated_at)
+			VALUES (?, ?, '/home/user/clio', 'clio', ?, 'feat: dates', 'Test User', 'test@example.com', ?, 'main', ?, ?)
+		`, commit.hash, commit.session, commit.hash, base.Add(time.Hour), base, base); err != nil {
+			t.Fatalf("failed to create commit: %v", err)
+		}
+	}
+
+	cache, err := summaries.NewCache(database, nil, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewCache() error = %v", err)
+	}
+	summarizer := &promptSummarizer{}
+	enricher, err := NewEnricher(database, cache, summarizer, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewEnricher() error = %v", err)
+	}
+
+	for _, hash := range []string{"aaaa1111", "bbbb2222"} {
+		summary, err := enricher.Summary(context.Background(), hash)
+		if err != nil {
+			t.Fatalf("Summary(%s) error = %v", hash, err)
+		}
+		// The later conversation started after the commit
+		if summary != "Parse dates" {
+			t.Errorf("Summary(%s) = %q, want Parse dates", hash, summary)
+		}
+	}
+	if summarizer.calls != 1 {
+		t.Errorf("summarizer called %d times, want once for the shared conversation", summarizer.calls)
+	}
+
+	for _, hash := range []string{"cccc3333", "ffff9999"} {
+		if summary, err := enricher.Summary(context.Background(), hash); err != nil || summary != "" {
+			t.Errorf("Summary(%s) = %q, %v, want no summary", hash, summary, err)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+// Package reminders stores notes to self that come due later, such as "revisit
+// the flaky test in 3 days", linked to the session they were written in so the
+// context can be found again when they come due.
+package reminders
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+// Reminder is a note to self due at a given time
+type Reminder struct {
+	ID         int64
+	Text       string
+	DueAt      time.Time
+	CreatedAt  time.Time
+	NotifiedAt *time.Time // Set once the daemon announced the reminder
+	DoneAt     *time.Time // Set once dismissed
+
+	SessionID    string    // Session the reminder was set in; empty for none
+	Project      string    // The session's project
+	SessionStart time.Time // The session's start; zero without a session
+}
+
+// IsDue reports whether the reminder is open and due at now
+func (r Reminder) IsDue(now time.Time) bool {
+	return r.DoneAt == nil && !r.DueAt.After(now)
+}
+
+// Store defines the interface for setting and reading reminders
+type Store interface {
+	// Add stores a reminder; SessionID may be empty
+	Add(reminder Reminder) (*Reminder, error)
+	// List returns open reminders, or all with all set, soonest due first
+	List(all bool) ([]Reminder, error)
+	// Due returns the open reminders due at now, soonest first
+	Due(now time.Time) ([]Reminder, error)
+	// Announce returns the due reminders not announced yet and marks them announced at now
+	Announce(now time.Time) ([]Reminder, error)
+	// Done dismisses a reminder
+	Done(id int64, at time.Time) error
+}
+
+// store implements Store on top of the clio database
+type store struct {
+	db     *sql.DB
+	logger logging.Logger
+}
+
+// NewStore creates a reminder store backed by the database
+func NewStore(db *sql.DB, logger logging.Logger) (Store, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database cannot be nil")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	return &store{
+		db:     db,
+		logger: logger.With("component", "reminders"),
+	}, nil
+}
+
+// Add stores a reminder
+func (s *store) Add(reminder Reminder) (*Reminder, error) {
+	reminder.Text = strings.TrimSpace(reminder.Text)
+	if reminder.Text == "" {
+		return nil, fmt.Errorf("reminder text cannot be empty")
+	}
+	if reminder.DueAt.IsZero() {
+		return nil, fmt.Errorf("reminder needs a due time")
+	}
+	if reminder.CreatedAt.IsZero() {
+		reminder.CreatedAt = time.Now()
+	}
+
+	var sessionID any
+	if reminder.SessionID != "" {
+		var start time.Time
+		var project string
+		err := s.db.QueryRow("SELECT project, start_time FROM sessions WHERE id = ?", reminder.SessionID).Scan(&project, &start)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("session %s not found", reminder.SessionID)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up session: %w", err)
+		}
+		reminder.Project, reminder.SessionStart = project, start
+		sessionID = reminder.SessionID
+	}
+
+	result, err := s.db.Exec(`
+		INSERT INTO reminders (session_id, text, due_at, created_at)
+		VALUES (?, ?, ?, ?)
+	`, sessionID, reminder.Text, reminder.DueAt, reminder.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store reminder: %w", err)
+	}
+	if reminder.ID, err = result.LastInsertId(); err != nil {
+		return nil, fmt.Errorf("failed to get reminder ID: %w", err)
+	}
+	reminder.NotifiedAt, reminder.DoneAt = nil, nil
+
+	s.logger.Debug("added reminder", "id", reminder.ID, "due_at", reminder.DueAt)
+	return &reminder, nil
+}
+
+// List returns open reminders, or all of them
+func (s *store) List(all bool) ([]Reminder, error) {
+	query := selectReminders
+	if !all {
+		query += " WHERE r.done_at IS NULL"
+	}
+	return s.query(query)
+}
+
+// Due returns the open reminders due at now
+func (s *store) Due(now time.Time) ([]Reminder, error) {
+	open, err := s.List(false)
+	if err != nil {
+		return nil, err
+	}
+
+	var due []Reminder
+	for _, reminder := range open {
+		if reminder.IsDue(now) {
+			due = append(due, reminder)
+		}
+	}
+	return due, nil
+}
+
+// Announce returns the due reminders not announced yet, marking them announced
+func (s *store) Announce(now time.Time) ([]Reminder, error) {
+	due, err := s.Due(now)
+	if err != nil {
+		return nil, err
+	}
+
+	var announced []Reminder
+	for _, reminder := range due {
+		if reminder.NotifiedAt != nil {
+			continue
+		}
+		if _, err := s.db.Exec("UPDATE reminders SET notified_at = ? WHERE id = ?", now, reminder.ID); err != nil {
+			return nil, fmt.Errorf("failed to mark reminder announced: %w", err)
+		}
+		reminder.NotifiedAt = &now
+		announced = append(announced, reminder)
+	}
+	return announced, nil
+}
+
+// Done dismisses a reminder
+func (s *store) Done(id int64, at time.Time) error {
+	result, err := s.db.Exec("UPDATE reminders SET done_at = ? WHERE id = ? AND done_at IS NULL", at, id)
+	if err != nil {
+		return fmt.Errorf("failed to dismiss reminder: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to dismiss reminder: %w", err)
+	} else if n == 0 {
+		return fmt.Errorf("no open reminder %d", id)
+	}
+	return nil
+}
+
+// selectReminders selects reminders with their session's project and start
+const selectReminders = `
+	SELECT r.id, r.text, r.due_at, r.created_at, r.notified_at, r.done_at,
+		COALESCE(r.session_id, ''), COALESCE(s.project, ''), s.start_time
+	FROM reminders r
+	LEFT JOIN sessions s ON s.id = r.session_id`
+
+// query runs a reminders query and sorts the results soonest due first
+func (s *store) query(query string) ([]Reminder, error) {
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reminders: %w", err)
+	}
+	defer rows.Close()
+
+	var list []Reminder
+	for rows.Next() {
+		var reminder Reminder
+		var notifiedAt, doneAt, sessionStart sql.NullTime
+		if err := rows.Scan(&reminder.ID, &reminder.Text, &reminder.DueAt, &reminder.CreatedAt, &notifiedAt, &doneAt,
+			&reminder.SessionID, &reminder.Project, &sessionStart); err != nil {
+			return nil, fmt.Errorf("failed to scan reminder: %w", err)
+		}
+		if notifiedAt.Valid {
+			reminder.NotifiedAt = &notifiedAt.Time
+		}
+		if doneAt.Valid {
+			reminder.DoneAt = &doneAt.Time
+		}
+		reminder.SessionStart = sessionStart.Time
+		list = append(list, reminder)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reminders: %w", err)
+	}
+
+	db.SortOldestFirst(list, func(r Reminder) time.Time { return r.DueAt })
+	return list, nil
+}
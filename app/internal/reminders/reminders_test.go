@@ -0,0 +1,93 @@
+package reminders
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/logging"
+	_ "modernc.org/sqlite"
+)
+
+func setupTestStore(t *testing.T) (*sql.DB, Store) {
+	database, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "clio.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if err := db.RunMigrations(database); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	store, err := NewStore(database, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	return database, store
+}
+
+func TestStore_AddAnnounceDone(t *testing.T) {
+	database, store := setupTestStore(t)
+	now := time.Date(2024, 5, 1, 9, 0, 0, 0, time.Local)
+	if _, err := database.Exec(`
+		INSERT INTO sessions (id, project, start_time, last_activity, created_at, updated_at)
+		VALUES ('s1', 'clio', ?, ?, ?, ?)
+	`, now, now, now, now); err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+
+	later, err := store.Add(Reminder{Text: "  revisit flaky test ", DueAt: now.Add(72 * time.Hour), SessionID: "s1", CreatedAt: now})
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if later.Text != "revisit flaky test" || later.Project != "clio" {
+		t.Errorf("added = %+v, want trimmed text and the session's project", later)
+	}
+	soon, err := store.Add(Reminder{Text: "check CI", DueAt: now.Add(time.Hour), CreatedAt: now})
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if _, err := store.Add(Reminder{Text: "", DueAt: now}); err == nil {
+		t.Error("Add() with empty text should fail")
+	}
+	if _, err := store.Add(Reminder{Text: "x", DueAt: now, SessionID: "missing"}); err == nil {
+		t.Error("Add() with an unknown session should fail")
+	}
+
+	list, err := store.List(false)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(list) != 2 || list[0].ID != soon.ID || list[1].SessionID != "s1" || !list[1].SessionStart.Equal(now) {
+		t.Fatalf("List() = %+v, want both, soonest first, with session context", list)
+	}
+
+	announced, err := store.Announce(now.Add(2 * time.Hour))
+	if err != nil {
+		t.Fatalf("Announce() error = %v", err)
+	}
+	if len(announced) != 1 || announced[0].ID != soon.ID {
+		t.Fatalf("Announce() = %+v, want only the due reminder", announced)
+	}
+	if again, err := store.Announce(now.Add(3 * time.Hour)); err != nil || len(again) != 0 {
+		t.Errorf("second Announce() = %+v, %v; want nothing new", again, err)
+	}
+	if due, err := store.Due(now.Add(3 * time.Hour)); err != nil || len(due) != 1 {
+		t.Errorf("Due() = %+v, %v; want the announced reminder still due", due, err)
+	}
+
+	if err := store.Done(soon.ID, now.Add(4*time.Hour)); err != nil {
+		t.Fatalf("Done() error = %v", err)
+	}
+	if err := store.Done(soon.ID, now.Add(4*time.Hour)); err == nil {
+		t.Error("Done() twice should fail")
+	}
+	if open, err := store.List(false); err != nil || len(open) != 1 || open[0].ID != later.ID {
+		t.Errorf("List(false) = %+v, %v; want only the open reminder", open, err)
+	}
+	if all, err := store.List(true); err != nil || len(all) != 2 || all[0].DoneAt == nil {
+		t.Errorf("List(true) = %+v, %v; want both with the done time", all, err)
+	}
+}
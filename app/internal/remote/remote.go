@@ -0,0 +1,259 @@
+// Package remote uploads exports and archives to cloud storage: s3:// URLs go
+// to Amazon S3 or an S3-compatible service, and gs:// URLs to Google Cloud
+// Storage through its S3-compatible API with HMAC keys. Requests are signed
+// with AWS Signature Version 4, which both services accept, so no cloud SDK is
+// needed. Credentials come from the secrets store.
+package remote
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/secrets"
+)
+
+const (
+	// SchemeS3 addresses Amazon S3 or an S3-compatible service
+	SchemeS3 = "s3"
+	// SchemeGCS addresses Google Cloud Storage
+	SchemeGCS = "gs"
+
+	// defaultS3Region is used when remote_storage.s3.region isn't set
+	defaultS3Region = "us-east-1"
+	// gcsEndpoint is Cloud Storage's S3-compatible XML API
+	gcsEndpoint = "https://storage.googleapis.com"
+	// gcsRegion is the region Cloud Storage expects in signatures
+	gcsRegion = "auto"
+	// uploadTimeout bounds a single upload
+	uploadTimeout = 5 * time.Minute
+	// maxErrorBody caps how much of an error response is read
+	maxErrorBody = 4096
+
+	// signingAlgorithm identifies AWS Signature Version 4
+	signingAlgorithm = "AWS4-HMAC-SHA256"
+	// amzDateLayout formats the request time in signatures
+	amzDateLayout = "20060102T150405Z"
+)
+
+// Location is a bucket and object key in cloud storage
+type Location struct {
+	Scheme string
+	Bucket string
+	Key    string
+}
+
+// String returns the location as a URL, e.g. s3://bucket/key
+func (l Location) String() string {
+	return l.Scheme + "://" + l.Bucket + "/" + l.Key
+}
+
+// IsRemote reports whether dest is an s3:// or gs:// URL rather than a local path
+func IsRemote(dest string) bool {
+	return strings.HasPrefix(dest, SchemeS3+"://") || strings.HasPrefix(dest, SchemeGCS+"://")
+}
+
+// Parse parses an s3:// or gs:// URL naming an object
+func Parse(dest string) (Location, error) {
+	if !IsRemote(dest) {
+		return Location{}, fmt.Errorf("%q is not an s3:// or gs:// URL", dest)
+	}
+	scheme, rest, _ := strings.Cut(dest, "://")
+	bucket, key, _ := strings.Cut(rest, "/")
+	if bucket == "" || key == "" || strings.HasSuffix(key, "/") {
+		return Location{}, fmt.Errorf("%q must name a bucket and an object, e.g. %s://bucket/exports/week.md", dest, scheme)
+	}
+	return Location{Scheme: scheme, Bucket: bucket, Key: key}, nil
+}
+
+// Uploader writes objects to one cloud storage service
+type Uploader interface {
+	// Upload stores body at the location, replacing any object there
+	Upload(ctx context.Context, location Location, body []byte) error
+}
+
+// uploader uploads with Signature Version 4 signed PUT requests
+type uploader struct {
+	endpoint        *url.URL
+	pathStyle       bool // Bucket in the path rather than the host name
+	region          string
+	accessKeyID     string
+	secretAccessKey string
+	client          *http.Client
+	now             func() time.Time
+}
+
+// NewUploader creates an uploader for the scheme's service from the remote
+// storage configuration, resolving secret references in its credentials
+func NewUploader(cfg config.RemoteStorageConfig, scheme string) (Uploader, error) {
+	var bucket config.RemoteBucketConfig
+	var section string
+	switch scheme {
+	case SchemeS3:
+		bucket, section = cfg.S3, "remote_storage.s3"
+	case SchemeGCS:
+		bucket, section = cfg.GCS, "remote_storage.gcs"
+	default:
+		return nil, fmt.Errorf("unknown remote storage scheme %q", scheme)
+	}
+	if bucket.AccessKeyID == "" || bucket.SecretAccessKey == "" {
+		return nil, fmt.Errorf("%s.access_key_id and secret_access_key must be set to write %s:// URLs", section, scheme)
+	}
+
+	accessKeyID, err := secrets.Resolve(bucket.AccessKeyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s.access_key_id: %w", section, err)
+	}
+	secretAccessKey, err := secrets.Resolve(bucket.SecretAccessKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s.secret_access_key: %w", section, err)
+	}
+
+	u := &uploader{
+		region:          bucket.Region,
+		accessKeyID:     accessKeyID,
+		secretAccessKey: secretAccessKey,
+		client:          &http.Client{Timeout: uploadTimeout},
+		now:             time.Now,
+	}
+	endpoint := bucket.Endpoint
+	switch {
+	case scheme == SchemeGCS:
+		endpoint, u.region, u.pathStyle = gcsEndpoint, gcsRegion, true
+	case endpoint != "":
+		// S3-compatible services generally don't resolve bucket host names
+		u.pathStyle = true
+	}
+	if u.region == "" {
+		u.region = defaultS3Region
+	}
+	if endpoint == "" {
+		endpoint = "https://s3." + u.region + ".amazonaws.com"
+	}
+	if u.endpoint, err = url.Parse(strings.TrimSuffix(endpoint, "/")); err != nil {
+		return nil, fmt.Errorf("invalid %s.endpoint: %w", section, err)
+	}
+	return u, nil
+}
+
+// Upload stores body at the location with a single PUT
+func (u *uploader) Upload(ctx context.Context, location Location, body []byte) error {
+	target := *u.endpoint
+	objectPath := "/" + location.Key
+	if u.pathStyle {
+		objectPath = "/" + location.Bucket + objectPath
+	} else {
+		target.Host = location.Bucket + "." + target.Host
+	}
+	target.Path = target.Path + objectPath
+	target.RawPath = escapePath(target.Path)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, target.String(), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create upload request: %w", err)
+	}
+	if contentType := mime.TypeByExtension(path.Ext(location.Key)); contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	u.sign(req, body)
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to upload to %s: %w", location, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("failed to upload to %s: %s%s", location, resp.Status, errorDetail(resp.Body))
+	}
+	return nil
+}
+
+// sign adds Signature Version 4 headers covering the host, payload hash, and date
+func (u *uploader) sign(req *http.Request, body []byte) {
+	now := u.now().UTC()
+	amzDate := now.Format(amzDateLayout)
+	day := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	const signedHeaders = "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		"host:" + req.URL.Host + "\n" +
+			"x-amz-content-sha256:" + payloadHash + "\n" +
+			"x-amz-date:" + amzDate + "\n",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := day + "/" + u.region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{signingAlgorithm, amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := []byte("AWS4" + u.secretAccessKey)
+	for _, part := range []string{day, u.region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		signingAlgorithm, u.accessKeyID, scope, signedHeaders, signature))
+}
+
+// escapePath percent-encodes everything in an object path but unreserved
+// characters and slashes, as signatures expect
+func escapePath(p string) string {
+	var b strings.Builder
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		if c == '/' || c == '-' || c == '_' || c == '.' || c == '~' ||
+			('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+// errorDetail returns the code and message of an S3-style XML error body, if any
+func errorDetail(body io.Reader) string {
+	var resp struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(body, maxErrorBody))
+	if err := xml.Unmarshal(data, &resp); err != nil || resp.Code == "" {
+		return ""
+	}
+	return ": " + resp.Code + ": " + resp.Message
+}
+
+// sha256Hex returns the hex SHA-256 of data
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of data under key
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
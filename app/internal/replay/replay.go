@@ -0,0 +1,250 @@
+// Package replay plays a captured session back in time order, for reviewing how a
+// solution evolved or recording a screencast.
+package replay
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stwalsh4118/clio/pkg/export"
+)
+
+const (
+	// DefaultSpeed plays the session in real time
+	DefaultSpeed = 1.0
+	// DefaultMaxPause caps the wait between frames so idle stretches don't stall playback
+	DefaultMaxPause = 2 * time.Second
+	// speedStep is how much faster or slower the Faster and Slower controls make playback
+	speedStep = 2.0
+	// frameTimeLayout is the timestamp shown before each frame
+	frameTimeLayout = "15:04:05"
+)
+
+// Frame is one step of a replay: a message, commit, test run, note, or attachment
+type Frame struct {
+	Time time.Time
+	Text string // Rendered lines, without a trailing newline
+}
+
+// Frames renders a session's activity as frames in time order. Messages from
+// different conversations are interleaved, with a header whenever the
+// conversation changes.
+func Frames(session export.Session) []Frame {
+	type entry struct {
+		time         time.Time
+		conversation *export.Conversation
+		message      *export.Message
+		event        *export.Event
+	}
+
+	var entries []entry
+	for i := range session.Conversations {
+		conversation := &session.Conversations[i]
+		for j := range conversation.Messages {
+			entries = append(entries, entry{time: conversation.Messages[j].CreatedAt, conversation: conversation, message: &conversation.Messages[j]})
+		}
+	}
+	for _, event := range session.Timeline() {
+		if event.Kind == export.EventConversation {
+			continue
+		}
+		event := event
+		entries = append(entries, entry{time: event.Time, event: &event})
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].time.Before(entries[j].time) })
+
+	frames := make([]Frame, 0, len(entries))
+	var current *export.Conversation
+	for _, e := range entries {
+		var b strings.Builder
+		if e.message != nil {
+			if e.conversation != current {
+				current = e.conversation
+				fmt.Fprintf(&b, "── %s ──\n", conversationTitle(*current))
+			}
+			writeMessage(&b, *e.message)
+		} else {
+			writeEvent(&b, *e.event)
+		}
+		frames = append(frames, Frame{Time: e.time, Text: strings.TrimRight(b.String(), "\n")})
+	}
+	return frames
+}
+
+// writeMessage renders a message and the tools the agent ran for it
+func writeMessage(b *strings.Builder, message export.Message) {
+	fmt.Fprintf(b, "[%s] %s: %s\n", message.CreatedAt.Local().Format(frameTimeLayout), message.Role, indent(message.Text))
+	for _, call := range message.ToolCalls {
+		if call.Status != "" {
+			fmt.Fprintf(b, "           tool %s (%s)\n", call.Name, call.Status)
+		} else {
+			fmt.Fprintf(b, "           tool %s\n", call.Name)
+		}
+	}
+}
+
+// writeEvent renders non-conversation activity
+func writeEvent(b *strings.Builder, event export.Event) {
+	at := event.Time.Local().Format(frameTimeLayout)
+	switch event.Kind {
+	case export.EventCommit:
+		subject, _, _ := strings.Cut(event.Commit.Message, "\n")
+		hash := event.Commit.Hash
+		if len(hash) > 7 {
+			hash = hash[:7]
+		}
+		fmt.Fprintf(b, "[%s] commit %s %s (%s, %s)", at, hash, subject, event.Commit.Repository, event.Commit.Branch)
+	case export.EventTestRun:
+		run := event.TestRun
+		status := "pass"
+		if run.Failed > 0 {
+			status = "FAIL"
+		}
+		fmt.Fprintf(b, "[%s] tests %s: %d passed, %d failed, %d skipped", at, status, run.Passed, run.Failed, run.Skipped)
+		if len(run.FailedTests) > 0 {
+			fmt.Fprintf(b, " (%s)", strings.Join(run.FailedTests, ", "))
+		}
+	case export.EventJournal:
+		fmt.Fprintf(b, "[%s] note: %s", at, indent(event.Journal.Text))
+	case export.EventAttachment:
+		fmt.Fprintf(b, "[%s] attached %s (%s)", at, event.Attachment.Name, event.Attachment.Path)
+		if event.Attachment.Caption != "" {
+			fmt.Fprintf(b, ": %s", event.Attachment.Caption)
+		}
+	}
+}
+
+// indent aligns continuation lines of multi-line text under the frame timestamp
+func indent(text string) string {
+	return strings.ReplaceAll(strings.TrimRight(text, "\n"), "\n", "\n           ")
+}
+
+// conversationTitle returns a conversation's name, falling back to its ID
+func conversationTitle(conversation export.Conversation) string {
+	if conversation.Name != "" {
+		return conversation.Name
+	}
+	return conversation.ComposerID
+}
+
+// Control changes playback while a replay is running
+type Control int
+
+const (
+	// TogglePause pauses a playing replay or resumes a paused one
+	TogglePause Control = iota
+	// Faster doubles the playback speed
+	Faster
+	// Slower halves the playback speed
+	Slower
+	// Quit stops the replay
+	Quit
+)
+
+// Options configures playback
+type Options struct {
+	Speed    float64       // Multiple of real time; zero uses DefaultSpeed
+	MaxPause time.Duration // Longest wait between frames; zero uses DefaultMaxPause
+	Instant  bool          // Print every frame without waiting
+}
+
+// Player plays frames to a writer
+type Player interface {
+	// Play writes frames to w, waiting between them in proportion to the time
+	// between them. It returns when every frame is written, Quit is received, or
+	// ctx is done; controls may be nil.
+	Play(ctx context.Context, frames []Frame, w io.Writer, controls <-chan Control) error
+}
+
+// player implements Player with real timers
+type player struct {
+	opts  Options
+	after func(d time.Duration) <-chan time.Time // Replaced in tests
+}
+
+// NewPlayer creates a player
+func NewPlayer(opts Options) (Player, error) {
+	if opts.Speed < 0 {
+		return nil, fmt.Errorf("speed cannot be negative")
+	}
+	if opts.MaxPause < 0 {
+		return nil, fmt.Errorf("max pause cannot be negative")
+	}
+	if opts.Speed == 0 {
+		opts.Speed = DefaultSpeed
+	}
+	if opts.MaxPause == 0 {
+		opts.MaxPause = DefaultMaxPause
+	}
+
+	return &player{opts: opts, after: time.After}, nil
+}
+
+// Play plays the frames
+func (p *player) Play(ctx context.Context, frames []Frame, w io.Writer, controls <-chan Control) error {
+	speed := p.opts.Speed
+	paused := false
+
+	for i, frame := range frames {
+		if i > 0 && !p.opts.Instant {
+			wait := p.delay(frames[i-1].Time, frame.Time, speed)
+			var timer <-chan time.Time
+			if !paused {
+				timer = p.after(wait)
+			}
+
+		waiting:
+			for {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-timer:
+					break waiting
+				case control, ok := <-controls:
+					if !ok {
+						controls = nil
+						continue
+					}
+					switch control {
+					case Quit:
+						return nil
+					case TogglePause:
+						paused = !paused
+						if paused {
+							timer = nil
+							fmt.Fprintln(w, "-- paused --")
+						} else {
+							// The wait restarts rather than resuming partway through
+							timer = p.after(wait)
+						}
+					// Speed changes apply from the next wait
+					case Faster:
+						speed *= speedStep
+						wait = p.delay(frames[i-1].Time, frame.Time, speed)
+					case Slower:
+						speed /= speedStep
+						wait = p.delay(frames[i-1].Time, frame.Time, speed)
+					}
+				}
+			}
+		}
+
+		if _, err := fmt.Fprintln(w, frame.Text); err != nil {
+			return fmt.Errorf("failed to write frame: %w", err)
+		}
+	}
+	return nil
+}
+
+// delay is the wait between frames at from and to at the given speed
+func (p *player) delay(from, to time.Time, speed float64) time.Duration {
+	gap := to.Sub(from)
+	if gap <= 0 {
+		return 0
+	}
+	return min(time.Duration(float64(gap)/speed), p.opts.MaxPause)
+}
@@ -0,0 +1,123 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/pkg/export"
+)
+
+func testSession() export.Session {
+	start := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	return export.Session{
+		ID:        "session-1",
+		Project:   "clio",
+		StartTime: start,
+		Conversations: []export.Conversation{
+			{ComposerID: "c1", Name: "Parser", Messages: []export.Message{
+				{Role: "user", Text: "Fix the lexer", CreatedAt: start},
+				{Role: "agent", Text: "Done.\nSee lexer.go", CreatedAt: start.Add(2 * time.Minute),
+					ToolCalls: []export.ToolCall{{Name: "edit_file", Status: "completed"}}},
+			}},
+			{ComposerID: "c2", Messages: []export.Message{
+				{Role: "user", Text: "Unrelated question", CreatedAt: start.Add(time.Minute)},
+			}},
+		},
+		Commits: []export.Commit{{Hash: "0123456789", Message: "Fix lexer\n\nbody", Repository: "clio", Branch: "main", Timestamp: start.Add(3 * time.Minute)}},
+		Journal: []export.JournalEntry{{Text: "Lexer was dropping quotes", CreatedAt: start.Add(30 * time.Second)}},
+	}
+}
+
+func TestFrames(t *testing.T) {
+	frames := Frames(testSession())
+	if len(frames) != 5 {
+		t.Fatalf("got %d frames, want 5", len(frames))
+	}
+
+	wants := []string{"── Parser ──", "note: Lexer was dropping quotes", "── c2 ──", "── Parser ──", "commit 0123456 Fix lexer (clio, main)"}
+	for i, want := range wants {
+		if !strings.Contains(frames[i].Text, want) {
+			t.Errorf("frame %d = %q, want it to contain %q", i, frames[i].Text, want)
+		}
+	}
+	if !strings.Contains(frames[3].Text, "\n           See lexer.go\n           tool edit_file (completed)") {
+		t.Errorf("agent frame = %q, want indented continuation and tool call lines", frames[3].Text)
+	}
+}
+
+func TestPlayer_Play(t *testing.T) {
+	frames := Frames(testSession())
+	p, err := NewPlayer(Options{Speed: 2, MaxPause: 40 * time.Second})
+	if err != nil {
+		t.Fatalf("NewPlayer() error = %v", err)
+	}
+
+	var waits []time.Duration
+	p.(*player).after = func(d time.Duration) <-chan time.Time {
+		waits = append(waits, d)
+		ch := make(chan time.Time, 1)
+		ch <- time.Time{}
+		return ch
+	}
+
+	var out bytes.Buffer
+	if err := p.Play(context.Background(), frames, &out, nil); err != nil {
+		t.Fatalf("Play() error = %v", err)
+	}
+	if strings.Count(out.String(), "\n") < len(frames) {
+		t.Errorf("output = %q, want every frame", out.String())
+	}
+
+	// Gaps of 30s, 30s, 1m, and 1m at double speed, capped at 40s
+	want := []time.Duration{15 * time.Second, 15 * time.Second, 30 * time.Second, 30 * time.Second}
+	if len(waits) != len(want) {
+		t.Fatalf("waits = %v, want %v", waits, want)
+	}
+	for i := range want {
+		if waits[i] != want[i] {
+			t.Errorf("waits = %v, want %v", waits, want)
+			break
+		}
+	}
+}
+
+func TestPlayer_Controls(t *testing.T) {
+	frames := Frames(testSession())
+	p, err := NewPlayer(Options{})
+	if err != nil {
+		t.Fatalf("NewPlayer() error = %v", err)
+	}
+	// Timers never fire, so playback only advances through controls
+	p.(*player).after = func(time.Duration) <-chan time.Time { return make(chan time.Time) }
+
+	controls := make(chan Control, 3)
+	controls <- TogglePause
+	controls <- Faster
+	controls <- Quit
+
+	var out bytes.Buffer
+	if err := p.Play(context.Background(), frames, &out, controls); err != nil {
+		t.Fatalf("Play() error = %v", err)
+	}
+	if got := out.String(); !strings.HasPrefix(got, frames[0].Text+"\n-- paused --\n") || strings.Contains(got, frames[1].Text) {
+		t.Errorf("output = %q, want the first frame, a pause, and nothing after quitting", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := p.Play(ctx, frames, &out, nil); err != context.Canceled {
+		t.Errorf("Play() with a cancelled context error = %v, want context.Canceled", err)
+	}
+}
+
+func TestNewPlayer_Errors(t *testing.T) {
+	if _, err := NewPlayer(Options{Speed: -1}); err == nil {
+		t.Error("NewPlayer() with a negative speed should fail")
+	}
+	if _, err := NewPlayer(Options{MaxPause: -time.Second}); err == nil {
+		t.Error("NewPlayer() with a negative max pause should fail")
+	}
+}
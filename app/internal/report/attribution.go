@@ -0,0 +1,272 @@
+package report
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/stwalsh4118/clio/internal/blobs"
+	"github.com/stwalsh4118/clio/internal/dedup"
+)
+
+const (
+	// minAttributableChars is the fewest letters and digits a line needs to be
+	// attributed; shorter lines such as "}" or "return" could come from anywhere
+	minAttributableChars = 3
+)
+
+// AttributionOptions filters the AI-vs-human attribution report
+type AttributionOptions struct {
+	Project string    // Only include this project (case-insensitive); empty includes all
+	Since   time.Time // Only include commits at or after this time; zero means no lower bound
+	Until   time.Time // Only include commits before this time; zero means no upper bound
+}
+
+// CommitAttribution estimates where one commit's added lines came from
+type CommitAttribution struct {
+	Hash         string
+	Project      string // Repository name
+	Message      string
+	Timestamp    time.Time
+	AuthorName   string
+	AuthorEmail  string
+	SessionID    string // Empty when the commit isn't correlated with a session
+	AILines      int    // Added lines that appeared in a code block earlier in the session
+	HumanLines   int    // Other attributable added lines
+	TrivialLines int    // Blank and punctuation-only lines, not attributed either way
+	Truncated    bool   // The stored diff was truncated, so only part of the commit was counted
+}
+
+// AIShare returns the fraction of attributable lines that came from AI suggestions
+func (c CommitAttribution) AIShare() float64 {
+	return share(c.AILines, c.HumanLines)
+}
+
+// WeekAttribution totals attribution for the commits in one week
+type WeekAttribution struct {
+	Start      time.Time // Monday 00:00 local time
+	Commits    int
+	AILines    int
+	HumanLines int
+}
+
+// AIShare returns the fraction of the week's attributable lines that came from AI suggestions
+func (w WeekAttribution) AIShare() float64 {
+	return share(w.AILines, w.HumanLines)
+}
+
+// AttributionReport lists per-commit estimates, newest first, and weekly totals, oldest first
+type AttributionReport struct {
+	Commits []CommitAttribution
+	Weeks   []WeekAttribution
+}
+
+// attributionCommit is a commit row used for attribution
+type attributionCommit struct {
+	CommitAttribution
+	diff string
+}
+
+// Attribution estimates, per commit and per week, what fraction of added lines
+// originated from AI suggestions. An added line counts as AI-originated when the
+// same line (ignoring whitespace) appeared in a code block of an agent message in
+// the commit's session before the commit was made; other lines count as manual
+// edits. Commits without a session are all manual.
+func (r *reporter) Attribution(opts AttributionOptions) (*AttributionReport, error) {
+	commits, err := r.attributionCommits(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	suggested := make(map[string]map[string]time.Time)
+	for _, commit := range commits {
+		if commit.SessionID == "" || suggested[commit.SessionID] != nil {
+			continue
+		}
+		if suggested[commit.SessionID], err = r.suggestedLines(commit.SessionID); err != nil {
+			return nil, err
+		}
+	}
+
+	report := &AttributionReport{Commits: make([]CommitAttribution, 0, len(commits))}
+	weeks := make(map[time.Time]*WeekAttribution)
+	for _, commit := range commits {
+		commit.AILines, commit.HumanLines, commit.TrivialLines = attributeLines(commit.diff, suggested[commit.SessionID], commit.Timestamp)
+		report.Commits = append(report.Commits, commit.CommitAttribution)
+
+		start := weekStart(commit.Timestamp)
+		week := weeks[start]
+		if week == nil {
+			week = &WeekAttribution{Start: start}
+			weeks[start] = week
+		}
+		week.Commits++
+		week.AILines += commit.AILines
+		week.HumanLines += commit.HumanLines
+	}
+
+	sort.SliceStable(report.Commits, func(i, j int) bool { return report.Commits[i].Timestamp.After(report.Commits[j].Timestamp) })
+	for _, week := range weeks {
+		report.Weeks = append(report.Weeks, *week)
+	}
+	sort.Slice(report.Weeks, func(i, j int) bool { return report.Weeks[i].Start.Before(report.Weeks[j].Start) })
+
+	r.logger.Debug("generated attribution report", "commits", len(report.Commits), "weeks", len(report.Weeks))
+	return report, nil
+}
+
+// attributionCommits returns the non-merge commits matching opts with their diffs
+func (r *reporter) attributionCommits(opts AttributionOptions) ([]attributionCommit, error) {
+	rows, err := r.db.Query(`
+		SELECT hash, repository_name, message, timestamp, author_name, author_email,
+			session_id, full_diff, full_diff_blob, diff_truncated
+		FROM commits
+		WHERE is_merge = 0
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query commits: %w", err)
+	}
+	defer rows.Close()
+
+	var commits []attributionCommit
+	filter := ExportOptions{Project: opts.Project, Since: opts.Since, Until: opts.Until}
+	for rows.Next() {
+		var commit attributionCommit
+		var sessionID, diff, diffBlob sql.NullString
+		if err := rows.Scan(&commit.Hash, &commit.Project, &commit.Message, &commit.Timestamp, &commit.AuthorName, &commit.AuthorEmail,
+			&sessionID, &diff, &diffBlob, &commit.Truncated); err != nil {
+			return nil, fmt.Errorf("failed to scan commit: %w", err)
+		}
+		if !filter.matches(commit.Project, commit.Timestamp) {
+			continue
+		}
+		commit.SessionID = sessionID.String
+		if commit.diff, err = blobs.Resolve(r.blobs, diff.String, diffBlob); err != nil {
+			// A missing blob only shortens the diff to its preview
+			r.logger.Warn("failed to load diff blob", "hash", commit.Hash, "error", err)
+		}
+		commits = append(commits, commit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating commits: %w", err)
+	}
+
+	return commits, nil
+}
+
+// suggestedLines maps each normalized line of the session's agent code blocks to
+// the first time it was suggested
+func (r *reporter) suggestedLines(sessionID string) (map[string]time.Time, error) {
+	rows, err := r.db.Query(`
+		SELECT m.code_blocks, m.created_at
+		FROM messages m
+		JOIN conversations c ON c.id = m.conversation_id
+		WHERE c.session_id = ? AND m.role = 'agent' AND m.code_blocks IS NOT NULL AND m.code_blocks != ''
+	`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query code blocks: %w", err)
+	}
+	type suggestion struct {
+		blocksJSON string
+		createdAt  time.Time
+	}
+	var suggestions []suggestion
+	for rows.Next() {
+		var s suggestion
+		if err := rows.Scan(&s.blocksJSON, &s.createdAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan code blocks: %w", err)
+		}
+		suggestions = append(suggestions, s)
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return nil, fmt.Errorf("error iterating code blocks: %w", err)
+	}
+
+	lines := make(map[string]time.Time)
+	for _, s := range suggestions {
+		blocksJSON, err := dedup.ExpandCodeBlocks(r.db, s.blocksJSON)
+		if err != nil {
+			r.logger.Debug("skipping unresolvable code blocks", "session_id", sessionID, "error", err)
+			continue
+		}
+		var blocks []struct {
+			Content string `json:"content"`
+		}
+		if err := json.Unmarshal([]byte(blocksJSON), &blocks); err != nil {
+			r.logger.Debug("skipping malformed code blocks", "session_id", sessionID, "error", err)
+			continue
+		}
+		for _, block := range blocks {
+			for _, line := range strings.Split(block.Content, "\n") {
+				normalized := strings.Join(strings.Fields(line), " ")
+				if first, ok := lines[normalized]; !ok || s.createdAt.Before(first) {
+					lines[normalized] = s.createdAt
+				}
+			}
+		}
+	}
+
+	return lines, nil
+}
+
+// attributeLines counts the lines a diff committed at committedAt adds that
+// were suggested at or before then, the other attributable lines, and the lines
+// too short to attribute
+func attributeLines(diff string, suggested map[string]time.Time, committedAt time.Time) (ai, human, trivial int) {
+	for _, line := range addedLines(diff) {
+		normalized := strings.Join(strings.Fields(line), " ")
+		switch first, ok := suggested[normalized]; {
+		case !isAttributable(normalized):
+			trivial++
+		case ok && !first.After(committedAt):
+			ai++
+		default:
+			human++
+		}
+	}
+	return ai, human, trivial
+}
+
+// addedLines returns the lines a unified diff adds, without the leading "+"
+func addedLines(diff string) []string {
+	var lines []string
+	for _, line := range strings.Split(diff, "\n") {
+		if strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "+++ ") {
+			lines = append(lines, line[1:])
+		}
+	}
+	return lines
+}
+
+// isAttributable reports whether a line has enough content to say where it came from
+func isAttributable(line string) bool {
+	chars := 0
+	for _, r := range line {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			chars++
+		}
+	}
+	return chars >= minAttributableChars
+}
+
+// weekStart returns midnight on the Monday starting t's week, in local time
+func weekStart(t time.Time) time.Time {
+	t = t.Local()
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, time.Local)
+}
+
+// share returns part / (part + rest), or 0 when both are zero
+func share(part, rest int) float64 {
+	if part+rest == 0 {
+		return 0
+	}
+	return float64(part) / float64(part+rest)
+}
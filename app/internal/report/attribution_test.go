@@ -0,0 +1,93 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+func TestReporter_Attribution(t *testing.T) {
+	database := setupTestReportDB(t)
+	// Wednesday, so both commits fall in the week starting Monday 2024-01-08
+	base := time.Date(2024, 1, 10, 12, 0, 0, 0, time.Local)
+
+	insertTestSession(t, database, "alpha-1", "alpha", base)
+	insertTestCommit(t, database, "assisted", "alpha", "alpha-1", base.Add(30*time.Minute))
+	insertTestCommit(t, database, "manual", "alpha", nil, base.Add(24*time.Hour))
+	insertTestCommit(t, database, "next-week", "alpha", nil, base.Add(7*24*time.Hour))
+
+	diffs := map[string]string{
+		"assisted": "diff --git a/lexer.go b/lexer.go\n--- a/lexer.go\n+++ b/lexer.go\n@@ -1,2 +1,6 @@\n" +
+			"+func escape(s string) string {\n" +
+			"+    return strings.ReplaceAll(s, `\"`, `\\\"`)\n" +
+			"+}\n" +
+			"+// handwritten comment\n" +
+			"+suggestedLater()\n" +
+			"-old line\n",
+		"manual":    "+++ b/readme.md\n+Some docs\n+\n",
+		"next-week": "+later work\n",
+	}
+	for hash, diff := range diffs {
+		if _, err := database.Exec("UPDATE commits SET full_diff = ? WHERE hash = ?", diff, hash); err != nil {
+			t.Fatalf("failed to set diff: %v", err)
+		}
+	}
+
+	if _, err := database.Exec(`
+		INSERT INTO conversations (id, session_id, composer_id, name, status, message_count, created_at, updated_at)
+		VALUES ('conv-1', 'alpha-1', 'composer-1', 'Escaping', 'completed', 2, ?, ?)
+	`, base, base); err != nil {
+		t.Fatalf("failed to create conversation: %v", err)
+	}
+	for _, m := range []struct {
+		id, blocks string
+		at         time.Time
+	}{
+		{"before", `[{"content":"func escape(s string) string {\n\treturn strings.ReplaceAll(s, ` + "`\\\"`, `\\\\\\\"`" + `)\n}"}]`, base.Add(10 * time.Minute)},
+		{"after", `[{"content":"suggestedLater()"}]`, base.Add(time.Hour)},
+	} {
+		if _, err := database.Exec(`
+			INSERT INTO messages (id, conversation_id, bubble_id, type, role, content, created_at, code_blocks)
+			VALUES (?, 'conv-1', ?, 2, 'agent', 'code', ?, ?)
+		`, m.id, m.id, m.at, m.blocks); err != nil {
+			t.Fatalf("failed to create message: %v", err)
+		}
+	}
+
+	reporter, err := NewReporter(database, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewReporter() error = %v", err)
+	}
+
+	result, err := reporter.Attribution(AttributionOptions{Project: "Alpha", Until: base.Add(48 * time.Hour)})
+	if err != nil {
+		t.Fatalf("Attribution() error = %v", err)
+	}
+	if len(result.Commits) != 2 || result.Commits[0].Hash != "manual" {
+		t.Fatalf("commits = %+v, want manual then assisted", result.Commits)
+	}
+
+	assisted := result.Commits[1]
+	// The signature and return line were suggested; "}" is trivial; the comment is
+	// manual, and so is a line only suggested after the commit
+	if assisted.AILines != 2 || assisted.HumanLines != 2 || assisted.TrivialLines != 1 {
+		t.Errorf("assisted = %+v, want 2 AI, 2 human, 1 trivial", assisted)
+	}
+	if got := assisted.AIShare(); got != 0.5 {
+		t.Errorf("AIShare() = %v, want 0.5", got)
+	}
+
+	manual := result.Commits[0]
+	if manual.AILines != 0 || manual.HumanLines != 1 || manual.TrivialLines != 1 {
+		t.Errorf("manual = %+v, want 1 human line and the blank line trivial", manual)
+	}
+
+	if len(result.Weeks) != 1 {
+		t.Fatalf("weeks = %+v, want one", result.Weeks)
+	}
+	week := result.Weeks[0]
+	if week.Start.Weekday() != time.Monday || week.Commits != 2 || week.AILines != 2 || week.HumanLines != 3 {
+		t.Errorf("week = %+v, want Monday start with 2 commits, 2 AI and 3 human lines", week)
+	}
+}
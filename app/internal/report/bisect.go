@@ -0,0 +1,161 @@
+package report
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultBisectConversations is how many conversations are shown per commit by default
+	DefaultBisectConversations = 3
+)
+
+// BisectOptions selects the commits to give context for
+type BisectOptions struct {
+	Hashes []string // Full hashes of the commits in the suspect range
+	// Conversations is how many of a session's conversations are returned per
+	// commit, most recently active first; zero uses DefaultBisectConversations
+	Conversations int
+}
+
+// BisectConversation is a conversation worked in before a commit
+type BisectConversation struct {
+	ComposerID string
+	Name       string    // Falls back to the composer ID
+	LastActive time.Time // The conversation's latest message at or before the commit
+	Prompt     string    // The latest user message at or before the commit, flattened to one line and truncated
+}
+
+// BisectCommit is the captured context of one commit in a suspect range
+type BisectCommit struct {
+	Hash          string
+	Project       string // Repository name
+	Message       string
+	Timestamp     time.Time
+	SessionID     string               // Empty when the commit isn't correlated with a session
+	Conversations []BisectConversation // Most recently active first
+}
+
+// BisectContext returns the captured context of the commits in a suspect
+// range, such as the commits git bisect chooses between: the session each
+// commit is correlated with and the conversations worked in before it, with
+// the latest prompt of each, so a bisect run can see what each commit was
+// trying to do. Commits clio hasn't captured are left out; the rest keep the
+// order of opts.Hashes.
+func (r *reporter) BisectContext(opts BisectOptions) ([]BisectCommit, error) {
+	limit := opts.Conversations
+	if limit <= 0 {
+		limit = DefaultBisectConversations
+	}
+
+	wanted := make(map[string]bool, len(opts.Hashes))
+	for _, hash := range opts.Hashes {
+		wanted[strings.ToLower(hash)] = true
+	}
+
+	rows, err := r.db.Query(`SELECT hash, repository_name, message, timestamp, session_id FROM commits`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query commits: %w", err)
+	}
+	defer rows.Close()
+
+	captured := make(map[string]*BisectCommit)
+	for rows.Next() {
+		var commit BisectCommit
+		var sessionID sql.NullString
+		if err := rows.Scan(&commit.Hash, &commit.Project, &commit.Message, &commit.Timestamp, &sessionID); err != nil {
+			return nil, fmt.Errorf("failed to scan commit: %w", err)
+		}
+		if !wanted[commit.Hash] {
+			continue
+		}
+		commit.SessionID = sessionID.String
+		captured[commit.Hash] = &commit
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating commits: %w", err)
+	}
+
+	var commits []BisectCommit
+	for _, hash := range opts.Hashes {
+		commit := captured[strings.ToLower(hash)]
+		if commit == nil {
+			continue
+		}
+		if commit.SessionID != "" {
+			if commit.Conversations, err = r.conversationsBefore(commit.SessionID, commit.Timestamp, limit); err != nil {
+				return nil, err
+			}
+		}
+		commits = append(commits, *commit)
+	}
+
+	r.logger.Debug("generated bisect context", "range", len(opts.Hashes), "captured", len(commits))
+	return commits, nil
+}
+
+// conversationsBefore returns up to limit of the session's conversations with
+// messages at or before at, most recently active first
+func (r *reporter) conversationsBefore(sessionID string, at time.Time, limit int) ([]BisectConversation, error) {
+	rows, err := r.db.Query(`
+		SELECT c.composer_id, COALESCE(NULLIF(c.name, ''), c.composer_id), m.role, COALESCE(m.content, ''), m.content_blob, m.created_at
+		FROM messages m
+		JOIN conversations c ON c.id = m.conversation_id
+		WHERE c.session_id = ?
+	`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query messages: %w", err)
+	}
+	defer rows.Close()
+
+	type promptState struct {
+		conversation *BisectConversation
+		promptAt     time.Time
+	}
+	var order []string
+	states := make(map[string]*promptState)
+	for rows.Next() {
+		var composerID, name, role, content string
+		var contentBlob sql.NullString
+		var createdAt time.Time
+		if err := rows.Scan(&composerID, &name, &role, &content, &contentBlob, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan message: %w", err)
+		}
+		if createdAt.After(at) {
+			continue
+		}
+		state := states[composerID]
+		if state == nil {
+			state = &promptState{conversation: &BisectConversation{ComposerID: composerID, Name: name}}
+			states[composerID] = state
+			order = append(order, composerID)
+		}
+		if createdAt.After(state.conversation.LastActive) {
+			state.conversation.LastActive = createdAt
+		}
+		content = strings.Join(strings.Fields(r.messageText(content, contentBlob)), " ")
+		if role == "user" && content != "" && !createdAt.Before(state.promptAt) {
+			state.conversation.Prompt, state.promptAt = content, createdAt
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating messages: %w", err)
+	}
+
+	conversations := make([]BisectConversation, 0, len(order))
+	for _, composerID := range order {
+		conversation := *states[composerID].conversation
+		if runes := []rune(conversation.Prompt); len(runes) > maxConversationSummaryLength {
+			conversation.Prompt = string(runes[:maxConversationSummaryLength-3]) + "..."
+		}
+		conversations = append(conversations, conversation)
+	}
+	sort.SliceStable(conversations, func(i, j int) bool { return conversations[i].LastActive.After(conversations[j].LastActive) })
+	if len(conversations) > limit {
+		conversations = conversations[:limit]
+	}
+	return conversations, nil
+}
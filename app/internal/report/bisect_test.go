@@ -0,0 +1,110 @@
+package report
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/blobs"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+func TestReporter_BisectContext(t *testing.T) {
+	database := setupTestReportDB(t)
+	base := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	insertTestSession(t, database, "alpha-1", "alpha", base)
+	insertTestCommit(t, database, "aaaa1111", "alpha", "alpha-1", base.Add(time.Hour))
+	insertTestCommit(t, database, "bbbb2222", "alpha", nil, base.Add(2*time.Hour))
+
+	if _, err := database.Exec(`
+		INSERT INTO conversations (id, session_id, composer_id, name, status, message_count, created_at, updated_at)
+		VALUES ('conv-1', 'alpha-1', 'composer-1', 'Escaping', 'completed', 2, ?, ?),
+			('conv-2', 'alpha-1', 'composer-2', '', 'completed', 1, ?, ?),
+			('conv-3', 'alpha-1', 'composer-3', 'Later chat', 'completed', 1, ?, ?)
+	`, base, base, base, base, base, base); err != nil {
+		t.Fatalf("failed to create conversations: %v", err)
+	}
+	for _, m := range []struct {
+		id, conversation, role, content string
+		at                              time.Time
+	}{
+		{"first", "conv-1", "user", "Escape quotes\nin the lexer", base.Add(10 * time.Minute)},
+		{"second", "conv-1", "agent", "Done", base.Add(40 * time.Minute)},
+		{"other", "conv-2", "user", "Rename the package", base.Add(20 * time.Minute)},
+		// After the commit, so it isn't context for it
+		{"later", "conv-3", "user", "Add tests", base.Add(90 * time.Minute)},
+	} {
+		if _, err := database.Exec(`
+			INSERT INTO messages (id, conversation_id, bubble_id, type, role, content, created_at)
+			VALUES (?, ?, ?, 1, ?, ?, ?)
+		`, m.id, m.conversation, m.id, m.role, m.content, m.at); err != nil {
+			t.Fatalf("failed to create message: %v", err)
+		}
+	}
+
+	reporter, err := NewReporter(database, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewReporter() error = %v", err)
+	}
+
+	commits, err := reporter.BisectContext(BisectOptions{Hashes: []string{"bbbb2222", "ffff9999", "aaaa1111"}})
+	if err != nil {
+		t.Fatalf("BisectContext() error = %v", err)
+	}
+	if len(commits) != 2 || commits[0].Hash != "bbbb2222" || commits[1].Hash != "aaaa1111" {
+		t.Fatalf("BisectContext() = %+v, want bbbb2222 and aaaa1111 in range order", commits)
+	}
+	if commits[0].SessionID != "" || len(commits[0].Conversations) != 0 {
+		t.Errorf("uncorrelated commit = %+v, want no session or conversations", commits[0])
+	}
+
+	conversations := commits[1].Conversations
+	if len(conversations) != 2 {
+		t.Fatalf("conversations = %+v, want 2", conversations)
+	}
+	if conversations[0].Name != "Escaping" || conversations[0].Prompt != "Escape quotes in the lexer" {
+		t.Errorf("first conversation = %+v, want Escaping with its flattened prompt", conversations[0])
+	}
+	if conversations[1].Name != "composer-2" || conversations[1].Prompt != "Rename the package" {
+		t.Errorf("second conversation = %+v, want the unnamed conversation by composer ID", conversations[1])
+	}
+
+	limited, err := reporter.BisectContext(BisectOptions{Hashes: []string{"aaaa1111"}, Conversations: 1})
+	if err != nil {
+		t.Fatalf("BisectContext() error = %v", err)
+	}
+	if len(limited) != 1 || len(limited[0].Conversations) != 1 || limited[0].Conversations[0].ComposerID != "composer-1" {
+		t.Errorf("BisectContext(limit 1) = %+v, want only the most recent conversation", limited)
+	}
+}
+
+func TestReporter_BisectContextResolvesBlobs(t *testing.T) {
+	database := setupTestReportDB(t)
+	store := newTestBlobStore(t)
+	base := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	insertTestSession(t, database, "alpha-1", "alpha", base)
+	insertTestCommit(t, database, "aaaa1111", "alpha", "alpha-1", base.Add(time.Hour))
+	if _, err := database.Exec(`
+		INSERT INTO conversations (id, session_id, composer_id, name, status, message_count, created_at, updated_at)
+		VALUES ('conv-1', 'alpha-1', 'composer-1', 'Logs', 'completed', 1, ?, ?)
+	`, base, base); err != nil {
+		t.Fatalf("failed to create conversation: %v", err)
+	}
+	// Only the pasted blank lines fit in the preview
+	prompt := strings.Repeat("\n", blobs.PreviewLength) + "Why does the worker hang?"
+	insertTestBlobMessage(t, database, store, "prompt", "conv-1", "user", prompt, base.Add(10*time.Minute))
+
+	reporter, err := NewReporterWithBlobs(database, store, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewReporterWithBlobs() error = %v", err)
+	}
+	commits, err := reporter.BisectContext(BisectOptions{Hashes: []string{"aaaa1111"}})
+	if err != nil {
+		t.Fatalf("BisectContext() error = %v", err)
+	}
+	if len(commits) != 1 || len(commits[0].Conversations) != 1 || commits[0].Conversations[0].Prompt != "Why does the worker hang?" {
+		t.Errorf("BisectContext() = %+v, want the full prompt from the blob store", commits)
+	}
+}
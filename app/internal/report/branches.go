@@ -0,0 +1,257 @@
+package report
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/db"
+)
+
+const (
+	// maxConversationSummaryLength truncates the opening prompt used as a conversation summary
+	maxConversationSummaryLength = 160
+)
+
+// BranchCompareOptions selects the branches to compare
+type BranchCompareOptions struct {
+	Repository string   // Repository name (case-insensitive) or path
+	Branches   []string // Branch names as recorded when the commits were captured
+}
+
+// BranchCommit is a captured commit on a compared branch
+type BranchCommit struct {
+	Hash         string
+	Message      string
+	Timestamp    time.Time
+	SessionID    string // Empty when the commit isn't correlated with a session
+	FilesChanged int
+	LinesAdded   int
+	LinesRemoved int
+}
+
+// BranchConversation summarizes a conversation in a session behind a branch
+type BranchConversation struct {
+	Name     string // Falls back to the composer ID
+	Messages int
+	Summary  string // The conversation's opening user prompt, truncated
+}
+
+// BranchSession is a session correlated with a branch's commits
+type BranchSession struct {
+	ID            string
+	StartTime     time.Time
+	Duration      time.Duration // Until the session's end, or its last activity while active
+	Shared        bool          // Also correlated with another compared branch
+	Conversations []BranchConversation
+}
+
+// BranchSummary is one side of a branch comparison
+type BranchSummary struct {
+	Branch       string
+	Commits      []BranchCommit  // Oldest first
+	Sessions     []BranchSession // Oldest first
+	TimeSpent    time.Duration   // Total duration of the branch's sessions
+	FilesChanged int
+	LinesAdded   int
+	LinesRemoved int
+}
+
+// CompareBranches summarizes the captured work behind each branch: its commits, the
+// sessions they're correlated with, the time spent in those sessions, and what the
+// sessions' conversations set out to do. Commits belong to the branch that was checked
+// out when they were captured, so deleted experiment branches can still be compared.
+func (r *reporter) CompareBranches(opts BranchCompareOptions) ([]BranchSummary, error) {
+	if strings.TrimSpace(opts.Repository) == "" {
+		return nil, fmt.Errorf("repository cannot be empty")
+	}
+	if len(opts.Branches) < 2 {
+		return nil, fmt.Errorf("at least two branches are required")
+	}
+
+	summaries := make([]BranchSummary, len(opts.Branches))
+	sessionBranches := make(map[string]int)
+	for i, branch := range opts.Branches {
+		commits, err := r.branchCommits(opts.Repository, branch)
+		if err != nil {
+			return nil, err
+		}
+		summaries[i] = BranchSummary{Branch: branch, Commits: commits}
+
+		seen := make(map[string]bool)
+		for _, commit := range commits {
+			summaries[i].FilesChanged += commit.FilesChanged
+			summaries[i].LinesAdded += commit.LinesAdded
+			summaries[i].LinesRemoved += commit.LinesRemoved
+			if commit.SessionID != "" && !seen[commit.SessionID] {
+				seen[commit.SessionID] = true
+				sessionBranches[commit.SessionID]++
+			}
+		}
+	}
+
+	for i := range summaries {
+		seen := make(map[string]bool)
+		for _, commit := range summaries[i].Commits {
+			if commit.SessionID == "" || seen[commit.SessionID] {
+				continue
+			}
+			seen[commit.SessionID] = true
+
+			session, err := r.branchSession(commit.SessionID)
+			if err != nil {
+				return nil, err
+			}
+			if session == nil {
+				continue
+			}
+			session.Shared = sessionBranches[session.ID] > 1
+			summaries[i].Sessions = append(summaries[i].Sessions, *session)
+			summaries[i].TimeSpent += session.Duration
+		}
+		sort.SliceStable(summaries[i].Sessions, func(a, b int) bool {
+			return summaries[i].Sessions[a].StartTime.Before(summaries[i].Sessions[b].StartTime)
+		})
+	}
+
+	r.logger.Debug("generated branch comparison", "repository", opts.Repository, "branches", len(summaries))
+	return summaries, nil
+}
+
+// branchCommits returns a branch's captured non-merge commits in a repository, oldest first
+func (r *reporter) branchCommits(repository, branch string) ([]BranchCommit, error) {
+	rows, err := r.db.Query(`
+		SELECT c.hash, c.message, c.timestamp, c.session_id,
+			COUNT(f.id), COALESCE(SUM(f.lines_added), 0), COALESCE(SUM(f.lines_removed), 0)
+		FROM commits c
+		LEFT JOIN commit_files f ON f.commit_id = c.id
+		WHERE c.branch = ? AND c.is_merge = 0
+			AND (LOWER(c.repository_name) = LOWER(?) OR c.repository_path = ?)
+		GROUP BY c.id
+	`, branch, repository, filepath.Clean(repository))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query branch commits: %w", err)
+	}
+	defer rows.Close()
+
+	var commits []BranchCommit
+	for rows.Next() {
+		var commit BranchCommit
+		var sessionID sql.NullString
+		if err := rows.Scan(&commit.Hash, &commit.Message, &commit.Timestamp, &sessionID,
+			&commit.FilesChanged, &commit.LinesAdded, &commit.LinesRemoved); err != nil {
+			return nil, fmt.Errorf("failed to scan commit: %w", err)
+		}
+		commit.SessionID = sessionID.String
+		commits = append(commits, commit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating commits: %w", err)
+	}
+
+	db.SortOldestFirst(commits, func(b BranchCommit) time.Time { return b.Timestamp })
+	return commits, nil
+}
+
+// branchSession returns a session with its conversation summaries, or nil when
+// the session no longer exists
+func (r *reporter) branchSession(sessionID string) (*BranchSession, error) {
+	session := &BranchSession{ID: sessionID}
+	var endTime sql.NullTime
+	var lastActivity time.Time
+	err := r.db.QueryRow("SELECT start_time, end_time, last_activity FROM sessions WHERE id = ?", sessionID).
+		Scan(&session.StartTime, &endTime, &lastActivity)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query session: %w", err)
+	}
+	end := lastActivity
+	if endTime.Valid {
+		end = endTime.Time
+	}
+	if end.After(session.StartTime) {
+		session.Duration = end.Sub(session.StartTime)
+	}
+
+	rows, err := r.db.Query(`
+		SELECT c.id, COALESCE(NULLIF(c.name, ''), c.composer_id), c.first_message_time,
+			(SELECT COUNT(*) FROM messages m WHERE m.conversation_id = c.id)
+		FROM conversations c
+		WHERE c.session_id = ?
+	`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query conversations: %w", err)
+	}
+	type conversationRow struct {
+		id           string
+		started      time.Time
+		conversation BranchConversation
+	}
+	var conversations []conversationRow
+	for rows.Next() {
+		var row conversationRow
+		var firstMessage sql.NullTime
+		if err := rows.Scan(&row.id, &row.conversation.Name, &firstMessage, &row.conversation.Messages); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan conversation: %w", err)
+		}
+		row.started = firstMessage.Time
+		conversations = append(conversations, row)
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return nil, fmt.Errorf("error iterating conversations: %w", err)
+	}
+	sort.SliceStable(conversations, func(i, j int) bool { return conversations[i].started.Before(conversations[j].started) })
+
+	// Prompts are loaded after the conversation rows are closed so a single
+	// connection database isn't holding two result sets
+	for _, row := range conversations {
+		if row.conversation.Summary, err = r.openingPrompt(row.id); err != nil {
+			return nil, err
+		}
+		session.Conversations = append(session.Conversations, row.conversation)
+	}
+
+	return session, nil
+}
+
+// openingPrompt returns a conversation's first non-empty user message, flattened to
+// one line and truncated
+func (r *reporter) openingPrompt(conversationID string) (string, error) {
+	rows, err := r.db.Query("SELECT content, content_blob, created_at FROM messages WHERE conversation_id = ? AND role = 'user'", conversationID)
+	if err != nil {
+		return "", fmt.Errorf("failed to query messages: %w", err)
+	}
+	defer rows.Close()
+
+	var prompt string
+	var earliest time.Time
+	for rows.Next() {
+		var content string
+		var contentBlob sql.NullString
+		var createdAt time.Time
+		if err := rows.Scan(&content, &contentBlob, &createdAt); err != nil {
+			return "", fmt.Errorf("failed to scan message: %w", err)
+		}
+		content = strings.Join(strings.Fields(r.messageText(content, contentBlob)), " ")
+		if content == "" || (prompt != "" && !createdAt.Before(earliest)) {
+			continue
+		}
+		prompt, earliest = content, createdAt
+	}
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("error iterating messages: %w", err)
+	}
+
+	if runes := []rune(prompt); len(runes) > maxConversationSummaryLength {
+		prompt = string(runes[:maxConversationSummaryLength-3]) + "..."
+	}
+	return prompt, nil
+}
@@ -0,0 +1,130 @@
+package report
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/blobs"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+func TestReporter_CompareBranches(t *testing.T) {
+	database := setupTestReportDB(t)
+	base := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+
+	insertTestSession(t, database, "s-cache", "api", base)
+	insertTestSession(t, database, "s-shared", "api", base.Add(3*time.Hour))
+	insertTestSession(t, database, "s-rewrite", "api", base.Add(6*time.Hour))
+	for _, c := range []struct {
+		hash, session, branch string
+		at                    time.Duration
+	}{
+		{"a1", "s-cache", "exp/cache", 30 * time.Minute},
+		{"a2", "s-shared", "exp/cache", 3*time.Hour + 30*time.Minute},
+		{"b1", "s-shared", "exp/rewrite", 3*time.Hour + 40*time.Minute},
+		{"b2", "s-rewrite", "exp/rewrite", 6*time.Hour + 30*time.Minute},
+		{"m1", "s-cache", "main", 45 * time.Minute},
+	} {
+		insertTestCommit(t, database, c.hash, "api", c.session, base.Add(c.at))
+		if _, err := database.Exec("UPDATE commits SET branch = ? WHERE hash = ?", c.branch, c.hash); err != nil {
+			t.Fatalf("failed to set branch: %v", err)
+		}
+	}
+	if _, err := database.Exec(`
+		INSERT INTO commit_files (id, commit_id, file_path, lines_added, lines_removed, created_at)
+		VALUES ('f1', 'a1', 'cache.go', 40, 2, ?), ('f2', 'a1', 'cache_test.go', 20, 0, ?)
+	`, base, base); err != nil {
+		t.Fatalf("failed to create commit files: %v", err)
+	}
+	if _, err := database.Exec(`
+		INSERT INTO conversations (id, session_id, composer_id, name, message_count, first_message_time, created_at, updated_at)
+		VALUES ('conv-1', 's-cache', 'composer-1', 'Cache layer', 2, ?, ?, ?)
+	`, base, base, base); err != nil {
+		t.Fatalf("failed to create conversation: %v", err)
+	}
+	for i, m := range []struct{ role, content string }{
+		{"user", "Add an LRU   cache\nin front of the store"},
+		{"agent", "Here's a cache"},
+	} {
+		at := base.Add(time.Duration(i) * time.Minute)
+		if _, err := database.Exec(`
+			INSERT INTO messages (id, conversation_id, bubble_id, type, role, content, created_at)
+			VALUES (?, 'conv-1', ?, 1, ?, ?, ?)
+		`, m.role, m.role, m.role, m.content, at); err != nil {
+			t.Fatalf("failed to create message: %v", err)
+		}
+	}
+
+	reporter, err := NewReporter(database, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewReporter() error = %v", err)
+	}
+
+	if _, err := reporter.CompareBranches(BranchCompareOptions{Repository: "api", Branches: []string{"exp/cache"}}); err == nil {
+		t.Error("CompareBranches() with one branch should fail")
+	}
+
+	summaries, err := reporter.CompareBranches(BranchCompareOptions{Repository: "API", Branches: []string{"exp/cache", "exp/rewrite"}})
+	if err != nil {
+		t.Fatalf("CompareBranches() error = %v", err)
+	}
+	if len(summaries) != 2 {
+		t.Fatalf("got %d summaries, want 2", len(summaries))
+	}
+
+	cache := summaries[0]
+	if len(cache.Commits) != 2 || cache.Commits[0].Hash != "a1" || cache.FilesChanged != 2 || cache.LinesAdded != 60 || cache.LinesRemoved != 2 {
+		t.Errorf("cache branch = %+v, want a1 and a2 with a1's file stats", cache)
+	}
+	if len(cache.Sessions) != 2 || cache.TimeSpent != 2*time.Hour {
+		t.Fatalf("cache sessions = %+v, time %v, want 2 sessions totalling 2h", cache.Sessions, cache.TimeSpent)
+	}
+	if cache.Sessions[0].Shared || !cache.Sessions[1].Shared {
+		t.Errorf("only s-shared should be marked shared: %+v", cache.Sessions)
+	}
+	conversations := cache.Sessions[0].Conversations
+	if len(conversations) != 1 || conversations[0].Name != "Cache layer" || conversations[0].Messages != 2 ||
+		conversations[0].Summary != "Add an LRU cache in front of the store" {
+		t.Errorf("conversations = %+v, want the cache conversation summarized by its opening prompt", conversations)
+	}
+
+	rewrite := summaries[1]
+	if len(rewrite.Commits) != 2 || len(rewrite.Sessions) != 2 || rewrite.Sessions[1].ID != "s-rewrite" {
+		t.Errorf("rewrite branch = %+v, want b1 and b2 across s-shared and s-rewrite", rewrite)
+	}
+}
+
+func TestReporter_CompareBranchesResolvesBlobs(t *testing.T) {
+	database := setupTestReportDB(t)
+	store := newTestBlobStore(t)
+	base := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+
+	insertTestSession(t, database, "s-cache", "api", base)
+	insertTestCommit(t, database, "a1", "api", "s-cache", base.Add(30*time.Minute))
+	insertTestCommit(t, database, "b1", "api", nil, base.Add(40*time.Minute))
+	if _, err := database.Exec("UPDATE commits SET branch = CASE hash WHEN 'a1' THEN 'exp/cache' ELSE 'exp/rewrite' END"); err != nil {
+		t.Fatalf("failed to set branches: %v", err)
+	}
+	if _, err := database.Exec(`
+		INSERT INTO conversations (id, session_id, composer_id, name, message_count, first_message_time, created_at, updated_at)
+		VALUES ('conv-1', 's-cache', 'composer-1', 'Cache layer', 1, ?, ?, ?)
+	`, base, base, base); err != nil {
+		t.Fatalf("failed to create conversation: %v", err)
+	}
+	// Only the pasted blank lines fit in the preview
+	insertTestBlobMessage(t, database, store, "prompt", "conv-1", "user", strings.Repeat("\n", blobs.PreviewLength)+"Add an LRU cache", base)
+
+	reporter, err := NewReporterWithBlobs(database, store, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewReporterWithBlobs() error = %v", err)
+	}
+	summaries, err := reporter.CompareBranches(BranchCompareOptions{Repository: "api", Branches: []string{"exp/cache", "exp/rewrite"}})
+	if err != nil {
+		t.Fatalf("CompareBranches() error = %v", err)
+	}
+	if len(summaries[0].Sessions) != 1 || len(summaries[0].Sessions[0].Conversations) != 1 ||
+		summaries[0].Sessions[0].Conversations[0].Summary != "Add an LRU cache" {
+		t.Errorf("cache branch = %+v, want the conversation summarized from the blob store", summaries[0])
+	}
+}
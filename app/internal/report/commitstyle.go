@@ -0,0 +1,165 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/commitmsg"
+	"github.com/stwalsh4118/clio/internal/db"
+)
+
+// CommitStyleOptions selects the commits checked by the commit style report
+type CommitStyleOptions struct {
+	Convention *commitmsg.Convention // The convention messages are checked against
+	Project    string                // Only include this project (case-insensitive); empty includes all
+	Since      time.Time             // Only include commits at or after this time; zero means no lower bound
+	Until      time.Time             // Only include commits before this time; zero means no upper bound
+}
+
+// StyleCounts counts commits and those following the convention
+type StyleCounts struct {
+	Commits   int
+	Compliant int
+}
+
+// Rate returns the fraction of commits following the convention, or 0 without commits
+func (c StyleCounts) Rate() float64 {
+	if c.Commits == 0 {
+		return 0
+	}
+	return float64(c.Compliant) / float64(c.Commits)
+}
+
+// add counts a commit
+func (c *StyleCounts) add(compliant bool) {
+	c.Commits++
+	if compliant {
+		c.Compliant++
+	}
+}
+
+// WeekStyle counts one week's commits
+type WeekStyle struct {
+	Start time.Time // Monday 00:00 local time
+	StyleCounts
+}
+
+// StyleProblem counts the commits with one problem, such as "unknown type"
+type StyleProblem struct {
+	Problem string
+	Commits int
+}
+
+// ProjectStyle summarizes one project's commits
+type ProjectStyle struct {
+	Project string
+	StyleCounts
+	Weeks    []WeekStyle    // Oldest first
+	Problems []StyleProblem // Most common first
+}
+
+// NonCompliantCommit is a commit breaking the convention
+type NonCompliantCommit struct {
+	Hash      string
+	Project   string // Repository name
+	Message   string
+	Timestamp time.Time
+	Problems  []string
+}
+
+// CommitStyleReport summarizes how closely commit messages follow the convention
+type CommitStyleReport struct {
+	StyleCounts
+	Projects     []ProjectStyle       // By project name
+	NonCompliant []NonCompliantCommit // Newest first
+}
+
+// CommitStyle checks the messages of stored non-merge commits against a
+// convention and summarizes compliance per project and week
+func (r *reporter) CommitStyle(opts CommitStyleOptions) (*CommitStyleReport, error) {
+	if opts.Convention == nil {
+		return nil, fmt.Errorf("convention cannot be nil")
+	}
+
+	rows, err := r.db.Query(`
+		SELECT hash, repository_name, message, timestamp
+		FROM commits
+		WHERE is_merge = 0
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query commits: %w", err)
+	}
+	defer rows.Close()
+
+	report := &CommitStyleReport{}
+	projects := make(map[string]*ProjectStyle)
+	weeks := make(map[string]map[time.Time]*WeekStyle)
+	problems := make(map[string]map[string]int)
+	for rows.Next() {
+		var commit NonCompliantCommit
+		if err := rows.Scan(&commit.Hash, &commit.Project, &commit.Message, &commit.Timestamp); err != nil {
+			return nil, fmt.Errorf("failed to scan commit: %w", err)
+		}
+		if opts.Project != "" && !strings.EqualFold(opts.Project, commit.Project) ||
+			!db.InRange(commit.Timestamp, opts.Since, opts.Until) {
+			continue
+		}
+
+		commit.Problems = opts.Convention.Check(commit.Message)
+		compliant := len(commit.Problems) == 0
+
+		key := strings.ToLower(commit.Project)
+		project := projects[key]
+		if project == nil {
+			project = &ProjectStyle{Project: commit.Project}
+			projects[key] = project
+			weeks[key] = make(map[time.Time]*WeekStyle)
+			problems[key] = make(map[string]int)
+		}
+		start := weekStart(commit.Timestamp)
+		week := weeks[key][start]
+		if week == nil {
+			week = &WeekStyle{Start: start}
+			weeks[key][start] = week
+		}
+
+		report.add(compliant)
+		project.add(compliant)
+		week.add(compliant)
+		for _, problem := range commit.Problems {
+			problems[key][problem]++
+		}
+		if !compliant {
+			report.NonCompliant = append(report.NonCompliant, commit)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating commits: %w", err)
+	}
+
+	for key, project := range projects {
+		for _, week := range weeks[key] {
+			project.Weeks = append(project.Weeks, *week)
+		}
+		sort.Slice(project.Weeks, func(i, j int) bool { return project.Weeks[i].Start.Before(project.Weeks[j].Start) })
+		for problem, count := range problems[key] {
+			project.Problems = append(project.Problems, StyleProblem{Problem: problem, Commits: count})
+		}
+		sort.Slice(project.Problems, func(i, j int) bool {
+			if project.Problems[i].Commits != project.Problems[j].Commits {
+				return project.Problems[i].Commits > project.Problems[j].Commits
+			}
+			return project.Problems[i].Problem < project.Problems[j].Problem
+		})
+		report.Projects = append(report.Projects, *project)
+	}
+	sort.Slice(report.Projects, func(i, j int) bool {
+		return strings.ToLower(report.Projects[i].Project) < strings.ToLower(report.Projects[j].Project)
+	})
+	db.SortNewestFirst(report.NonCompliant, func(c NonCompliantCommit) time.Time { return c.Timestamp })
+
+	r.logger.Debug("generated commit style report", "commits", report.Commits, "compliant", report.Compliant)
+	return report, nil
+}
@@ -0,0 +1,77 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/commitmsg"
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+func TestReporter_CommitStyle(t *testing.T) {
+	database := setupTestReportDB(t)
+	// Wednesday, so the first two commits fall in the week starting Monday 2024-01-08
+	base := time.Date(2024, 1, 10, 12, 0, 0, 0, time.Local)
+
+	messages := map[string]string{
+		"feat":     "feat(parser): add date ranges",
+		"vague":    "fixed stuff",
+		"typo":     "feture: add search",
+		"fix":      "fix: handle empty input",
+		"merge":    "Merge branch 'main'",
+		"other":    "wip",
+		"too-late": "wip",
+	}
+	insertTestCommit(t, database, "feat", "alpha", nil, base)
+	insertTestCommit(t, database, "vague", "alpha", nil, base.Add(time.Hour))
+	insertTestCommit(t, database, "typo", "alpha", nil, base.Add(7*24*time.Hour))
+	insertTestCommit(t, database, "fix", "alpha", nil, base.Add(8*24*time.Hour))
+	insertTestCommit(t, database, "merge", "alpha", nil, base.Add(8*24*time.Hour))
+	insertTestCommit(t, database, "other", "beta", nil, base)
+	insertTestCommit(t, database, "too-late", "beta", nil, base.Add(30*24*time.Hour))
+	for hash, message := range messages {
+		if _, err := database.Exec("UPDATE commits SET message = ?, is_merge = ? WHERE hash = ?", message, hash == "merge", hash); err != nil {
+			t.Fatalf("failed to set message: %v", err)
+		}
+	}
+
+	reporter, err := NewReporter(database, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewReporter() error = %v", err)
+	}
+	convention, err := commitmsg.NewConvention(config.CommitStyleConfig{Convention: commitmsg.ConventionConventional})
+	if err != nil {
+		t.Fatalf("NewConvention() error = %v", err)
+	}
+
+	result, err := reporter.CommitStyle(CommitStyleOptions{Convention: convention, Until: base.Add(14 * 24 * time.Hour)})
+	if err != nil {
+		t.Fatalf("CommitStyle() error = %v", err)
+	}
+	if result.Commits != 5 || result.Compliant != 2 {
+		t.Errorf("totals = %+v, want 2 of 5 compliant", result.StyleCounts)
+	}
+	if len(result.Projects) != 2 || result.Projects[0].Project != "alpha" {
+		t.Fatalf("projects = %+v, want alpha then beta", result.Projects)
+	}
+
+	alpha := result.Projects[0]
+	if len(alpha.Weeks) != 2 || alpha.Weeks[0].Commits != 2 || alpha.Weeks[0].Compliant != 1 || alpha.Weeks[1].Rate() != 0.5 {
+		t.Errorf("alpha weeks = %+v, want 1 of 2 compliant in each", alpha.Weeks)
+	}
+	if !alpha.Weeks[0].Start.Equal(time.Date(2024, 1, 8, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("first week starts %v, want Monday 2024-01-08", alpha.Weeks[0].Start)
+	}
+	if len(alpha.Problems) != 2 || alpha.Problems[0].Problem != "not type(scope): description" || alpha.Problems[1].Problem != "unknown type" {
+		t.Errorf("alpha problems = %+v", alpha.Problems)
+	}
+
+	if len(result.NonCompliant) != 3 || result.NonCompliant[0].Hash != "typo" {
+		t.Errorf("non-compliant = %+v, want typo, vague, and other, newest first", result.NonCompliant)
+	}
+
+	if _, err := reporter.CommitStyle(CommitStyleOptions{}); err == nil {
+		t.Error("CommitStyle() without a convention should fail")
+	}
+}
@@ -0,0 +1,117 @@
+package report
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/pkg/export"
+)
+
+// ExportConversation loads a conversation by composer ID, or a unique prefix of
+// one, with its messages from every session it spans in time order
+func (r *reporter) ExportConversation(composerRef string) (*export.Conversation, error) {
+	composerRef = strings.TrimSpace(composerRef)
+	if composerRef == "" {
+		return nil, fmt.Errorf("composer ID cannot be empty")
+	}
+
+	rows, err := r.db.Query("SELECT id, composer_id, name FROM conversations ORDER BY first_message_time ASC")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query conversations: %w", err)
+	}
+
+	// A composer resumed in a later session has a conversation row per session
+	rowIDs := make(map[string][]string)
+	names := make(map[string]string)
+	for rows.Next() {
+		var id, composerID string
+		var name sql.NullString
+		if err := rows.Scan(&id, &composerID, &name); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan conversation: %w", err)
+		}
+		if !strings.HasPrefix(composerID, composerRef) {
+			continue
+		}
+		rowIDs[composerID] = append(rowIDs[composerID], id)
+		if name.String != "" {
+			names[composerID] = name.String
+		}
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return nil, fmt.Errorf("error iterating conversations: %w", err)
+	}
+
+	composerID := composerRef
+	if _, exact := rowIDs[composerRef]; !exact {
+		switch len(rowIDs) {
+		case 0:
+			return nil, fmt.Errorf("conversation %q not found", composerRef)
+		case 1:
+			for id := range rowIDs {
+				composerID = id
+			}
+		default:
+			return nil, fmt.Errorf("composer ID prefix %q is ambiguous (%d conversations match)", composerRef, len(rowIDs))
+		}
+	}
+
+	conversation := &export.Conversation{ComposerID: composerID, Name: names[composerID], Messages: []export.Message{}}
+	for _, id := range rowIDs[composerID] {
+		messages, err := r.exportMessages(id)
+		if err != nil {
+			return nil, err
+		}
+		conversation.Messages = append(conversation.Messages, messages...)
+	}
+	db.SortOldestFirst(conversation.Messages, func(m export.Message) time.Time { return m.CreatedAt })
+
+	r.logger.Debug("loaded conversation", "composer_id", composerID, "messages", len(conversation.Messages))
+	return conversation, nil
+}
+
+// ResolveConversation returns the composer ID of the conversation ref refers to:
+// a full composer ID or a unique prefix of one
+func (r *reporter) ResolveConversation(ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return "", fmt.Errorf("composer ID cannot be empty")
+	}
+
+	rows, err := r.db.Query("SELECT DISTINCT composer_id FROM conversations WHERE composer_id LIKE ? || '%'", ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to query conversations: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []string
+	for rows.Next() {
+		var composerID string
+		if err := rows.Scan(&composerID); err != nil {
+			return "", fmt.Errorf("failed to scan conversation: %w", err)
+		}
+		if composerID == ref {
+			return composerID, nil
+		}
+		if strings.HasPrefix(composerID, ref) {
+			matches = append(matches, composerID)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("error iterating conversations: %w", err)
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("conversation %q not found", ref)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("composer ID prefix %q is ambiguous (%d conversations match)", ref, len(matches))
+	}
+}
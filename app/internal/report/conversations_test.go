@@ -0,0 +1,67 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+func TestReporter_ExportConversation(t *testing.T) {
+	database := setupTestReportDB(t)
+	base := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	insertTestSession(t, database, "alpha-1", "alpha", base)
+	insertTestSession(t, database, "alpha-2", "alpha", base.Add(24*time.Hour))
+	// composer-1 was resumed in the next session, whose row has no name
+	if _, err := database.Exec(`
+		INSERT INTO conversations (id, session_id, composer_id, name, status, message_count, first_message_time, created_at, updated_at)
+		VALUES ('conv-1', 'alpha-1', 'composer-1', 'Refactor', 'completed', 1, ?, ?, ?),
+			('conv-2', 'alpha-2', 'composer-1', NULL, 'completed', 1, ?, ?, ?),
+			('conv-3', 'alpha-2', 'composer-2', 'Other', 'completed', 0, ?, ?, ?)
+	`, base, base, base, base.Add(24*time.Hour), base, base, base, base, base); err != nil {
+		t.Fatalf("failed to create conversations: %v", err)
+	}
+	for i, conversationID := range []string{"conv-2", "conv-1"} {
+		at := base.Add(time.Duration(1-i) * 24 * time.Hour)
+		if _, err := database.Exec(`
+			INSERT INTO messages (id, conversation_id, bubble_id, type, role, content, created_at)
+			VALUES (?, ?, ?, 1, 'user', ?, ?)
+		`, conversationID, conversationID, conversationID, "from "+conversationID, at); err != nil {
+			t.Fatalf("failed to create message: %v", err)
+		}
+	}
+
+	reporter, err := NewReporter(database, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewReporter() error = %v", err)
+	}
+
+	conversation, err := reporter.ExportConversation("composer-1")
+	if err != nil {
+		t.Fatalf("ExportConversation() error = %v", err)
+	}
+	if conversation.Name != "Refactor" || conversation.ComposerID != "composer-1" {
+		t.Errorf("conversation = %+v, want composer-1 named Refactor", conversation)
+	}
+	if len(conversation.Messages) != 2 || conversation.Messages[0].Text != "from conv-1" || conversation.Messages[1].Text != "from conv-2" {
+		t.Errorf("messages = %+v, want both sessions' messages oldest first", conversation.Messages)
+	}
+
+	if conversation, err := reporter.ExportConversation("composer-2"); err != nil || len(conversation.Messages) != 0 {
+		t.Errorf("ExportConversation(composer-2) = %+v, %v, want no messages", conversation, err)
+	}
+	for _, ref := range []string{"composer", "missing", " "} {
+		if _, err := reporter.ExportConversation(ref); err == nil {
+			t.Errorf("ExportConversation(%q) should fail", ref)
+		}
+		if _, err := reporter.ResolveConversation(ref); err == nil {
+			t.Errorf("ResolveConversation(%q) should fail", ref)
+		}
+	}
+	for ref, want := range map[string]string{"composer-1": "composer-1", "composer-2": "composer-2"} {
+		if got, err := reporter.ResolveConversation(ref); err != nil || got != want {
+			t.Errorf("ResolveConversation(%q) = %q, %v, want %q", ref, got, err, want)
+		}
+	}
+}
@@ -0,0 +1,434 @@
+package report
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/blobs"
+	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/goals"
+	"github.com/stwalsh4118/clio/internal/idle"
+	"github.com/stwalsh4118/clio/internal/language"
+	"github.com/stwalsh4118/clio/internal/meta"
+	"github.com/stwalsh4118/clio/pkg/export"
+)
+
+// ExportOptions filters the sessions loaded for an export
+type ExportOptions struct {
+	SessionID     string    // Only include this session; empty includes all
+	Project       string    // Only include this project (case-insensitive); empty includes all
+	Since         time.Time // Only include sessions starting at or after this time; zero means no lower bound
+	Until         time.Time // Only include sessions starting before this time; zero means no upper bound
+	Tag           string    // Only include sessions behind this goal tag (see goals.Sessions); empty includes all
+	MetaKey       string    // With MetaValue, only include sessions with this metadata (see meta.Sessions); empty includes all
+	MetaValue     string
+	MinConfidence float64 // Leave out commits correlated below this confidence (git.min_correlation_confidence); unscored commits are kept
+}
+
+// ExportData loads sessions with their conversations, messages, correlated
+// commits, test runs, attachments, journal notes, and metadata in the public
+// export format
+func (r *reporter) ExportData(opts ExportOptions) (*export.Data, error) {
+	sessions, err := r.exportSessions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionMeta, err := meta.All(r.db, meta.EntitySession)
+	if err != nil {
+		return nil, err
+	}
+	commitMeta, err := meta.All(r.db, meta.EntityCommit)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range sessions {
+		sessions[i].Metadata = sessionMeta[sessions[i].ID]
+		if sessions[i].Conversations, err = r.exportConversations(sessions[i].ID); err != nil {
+			return nil, err
+		}
+		if sessions[i].Commits, err = r.exportCommits(sessions[i].ID, opts.MinConfidence); err != nil {
+			return nil, err
+		}
+		for j := range sessions[i].Commits {
+			sessions[i].Commits[j].Metadata = commitMeta[sessions[i].Commits[j].Hash]
+		}
+		if sessions[i].TestRuns, err = r.exportTestRuns(sessions[i].ID); err != nil {
+			return nil, err
+		}
+		if sessions[i].Attachments, err = r.exportAttachments(sessions[i].ID); err != nil {
+			return nil, err
+		}
+		if sessions[i].Journal, err = r.exportJournal(sessions[i].ID); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	gaps, err := exportIdleGaps(r.db, sessions, now)
+	if err != nil {
+		return nil, err
+	}
+
+	r.logger.Debug("loaded export data", "sessions", len(sessions), "idle_gaps", len(gaps))
+	return &export.Data{GeneratedAt: now, Sessions: sessions, IdleGaps: gaps}, nil
+}
+
+// exportIdleGaps returns the idle gaps overlapping any of the sessions, oldest first
+func exportIdleGaps(db *sql.DB, sessions []export.Session, now time.Time) ([]export.IdleGap, error) {
+	if len(sessions) == 0 {
+		return nil, nil
+	}
+	from, to := sessions[0].StartTime, sessions[0].StartTime
+	for _, session := range sessions {
+		end := now
+		if session.EndTime != nil {
+			end = *session.EndTime
+		}
+		if session.StartTime.Before(from) {
+			from = session.StartTime
+		}
+		if end.After(to) {
+			to = end
+		}
+	}
+
+	gaps, err := idle.Between(db, from, to)
+	if err != nil {
+		return nil, err
+	}
+	var exported []export.IdleGap
+	for _, gap := range gaps {
+		exported = append(exported, export.IdleGap{Kind: string(gap.Kind), Start: gap.Start, End: gap.End})
+	}
+	return exported, nil
+}
+
+// exportSessions returns the sessions matching opts, oldest first
+func (r *reporter) exportSessions(opts ExportOptions) ([]export.Session, error) {
+	var tagged map[string]bool
+	if opts.Tag != "" {
+		var err error
+		if tagged, err = goals.Sessions(r.db, opts.Tag); err != nil {
+			return nil, err
+		}
+	}
+	var withMeta map[string]bool
+	if opts.MetaKey != "" {
+		var err error
+		if withMeta, err = meta.Sessions(r.db, opts.MetaKey, opts.MetaValue); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := r.db.Query(`
+		SELECT id, project, start_time, end_time
+		FROM sessions
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := []export.Session{}
+	for rows.Next() {
+		var session export.Session
+		var project sql.NullString
+		var endTime sql.NullTime
+		if err := rows.Scan(&session.ID, &project, &session.StartTime, &endTime); err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		session.Project = project.String
+		if endTime.Valid {
+			session.EndTime = &endTime.Time
+		}
+		if (opts.SessionID != "" && session.ID != opts.SessionID) || !opts.matches(session.Project, session.StartTime) {
+			continue
+		}
+		if (tagged != nil && !tagged[session.ID]) || (withMeta != nil && !withMeta[session.ID]) {
+			continue
+		}
+		sessions = append(sessions, session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sessions: %w", err)
+	}
+
+	db.SortOldestFirst(sessions, func(s export.Session) time.Time { return s.StartTime })
+	return sessions, nil
+}
+
+// exportConversations returns a session's conversations with their messages,
+// in the order they started
+func (r *reporter) exportConversations(sessionID string) ([]export.Conversation, error) {
+	rows, err := r.db.Query(`
+		SELECT id, composer_id, name, first_message_time
+		FROM conversations
+		WHERE session_id = ?
+	`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query conversations: %w", err)
+	}
+
+	type stored struct {
+		id           string
+		firstMessage time.Time // Zero for conversations without messages, which sort first
+		conversation export.Conversation
+	}
+	var found []stored
+	for rows.Next() {
+		var s stored
+		var name sql.NullString
+		var firstMessage sql.NullTime
+		if err := rows.Scan(&s.id, &s.conversation.ComposerID, &name, &firstMessage); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan conversation: %w", err)
+		}
+		s.conversation.Name = name.String
+		s.firstMessage = firstMessage.Time
+		found = append(found, s)
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return nil, fmt.Errorf("error iterating conversations: %w", err)
+	}
+	db.SortOldestFirst(found, func(s stored) time.Time { return s.firstMessage })
+
+	// Messages are loaded after the conversation rows are closed so a single
+	// connection database isn't holding two result sets
+	conversations := make([]export.Conversation, 0, len(found))
+	for _, s := range found {
+		if s.conversation.Messages, err = r.exportMessages(s.id); err != nil {
+			return nil, err
+		}
+		conversations = append(conversations, s.conversation)
+	}
+
+	return conversations, nil
+}
+
+// exportMessages returns a conversation's messages in order
+func (r *reporter) exportMessages(conversationID string) ([]export.Message, error) {
+	rows, err := r.db.Query(`
+		SELECT role, content, content_blob, thinking_text, created_at, tool_calls, language
+		FROM messages
+		WHERE conversation_id = ?
+	`, conversationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query messages: %w", err)
+	}
+	defer rows.Close()
+
+	messages := []export.Message{}
+	for rows.Next() {
+		var message export.Message
+		var contentBlob, thinking, toolCalls, lang sql.NullString
+		if err := rows.Scan(&message.Role, &message.Text, &contentBlob, &thinking, &message.CreatedAt, &toolCalls, &lang); err != nil {
+			return nil, fmt.Errorf("failed to scan message: %w", err)
+		}
+		if message.Text, err = blobs.Resolve(r.blobs, message.Text, contentBlob); err != nil {
+			// A missing blob only shortens the message to its preview
+			r.logger.Warn("failed to load message blob", "conversation_id", conversationID, "error", err)
+		}
+		message.Thinking = thinking.String
+		message.Language = lang.String
+		if !lang.Valid {
+			// Messages stored before detection are detected as they're read
+			message.Language = language.Detect(message.Text)
+		}
+		if toolCalls.Valid && toolCalls.String != "" {
+			// Malformed tool call data only loses the tool calls, not the message
+			if err := json.Unmarshal([]byte(toolCalls.String), &message.ToolCalls); err != nil {
+				r.logger.Debug("failed to decode tool calls", "conversation_id", conversationID, "error", err)
+			}
+		}
+		messages = append(messages, message)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating messages: %w", err)
+	}
+
+	db.SortOldestFirst(messages, func(m export.Message) time.Time { return m.CreatedAt })
+	return messages, nil
+}
+
+// exportCommits returns the commits correlated with a session at or above
+// minConfidence. Commits stored before confidence scoring have none and are kept.
+func (r *reporter) exportCommits(sessionID string, minConfidence float64) ([]export.Commit, error) {
+	rows, err := r.db.Query(`
+		SELECT hash, message, author_name, repository_name, branch, timestamp,
+			correlation_type, correlation_confidence
+		FROM commits
+		WHERE session_id = ? AND (correlation_confidence IS NULL OR correlation_confidence >= ?)
+	`, sessionID, minConfidence)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query commits: %w", err)
+	}
+	defer rows.Close()
+
+	commits := []export.Commit{}
+	for rows.Next() {
+		var commit export.Commit
+		var correlationType sql.NullString
+		var confidence sql.NullFloat64
+		if err := rows.Scan(&commit.Hash, &commit.Message, &commit.Author, &commit.Repository, &commit.Branch,
+			&commit.Timestamp, &correlationType, &confidence); err != nil {
+			return nil, fmt.Errorf("failed to scan commit: %w", err)
+		}
+		commit.CorrelationType = correlationType.String
+		if confidence.Valid {
+			commit.Confidence = &confidence.Float64
+		}
+		commits = append(commits, commit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating commits: %w", err)
+	}
+
+	db.SortOldestFirst(commits, func(c export.Commit) time.Time { return c.Timestamp })
+	return commits, nil
+}
+
+// exportTestRuns returns the test runs ingested during a session, oldest first
+func (r *reporter) exportTestRuns(sessionID string) ([]export.TestRun, error) {
+	rows, err := r.db.Query(`
+		SELECT id, run_time, format, total, passed, failed, skipped, commit_hash
+		FROM test_runs
+		WHERE session_id = ?
+	`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query test runs: %w", err)
+	}
+
+	var ids []string
+	runs := []export.TestRun{}
+	for rows.Next() {
+		var id string
+		var run export.TestRun
+		var commitHash sql.NullString
+		if err := rows.Scan(&id, &run.RunTime, &run.Format, &run.Total, &run.Passed, &run.Failed, &run.Skipped, &commitHash); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan test run: %w", err)
+		}
+		run.CommitHash = commitHash.String
+		ids = append(ids, id)
+		runs = append(runs, run)
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return nil, fmt.Errorf("error iterating test runs: %w", err)
+	}
+
+	for i, id := range ids {
+		if runs[i].Failed == 0 {
+			continue
+		}
+		if runs[i].FailedTests, err = r.exportFailedTests(id); err != nil {
+			return nil, err
+		}
+	}
+
+	db.SortOldestFirst(runs, func(run export.TestRun) time.Time { return run.RunTime })
+	return runs, nil
+}
+
+// exportFailedTests returns the names of a run's failed tests
+func (r *reporter) exportFailedTests(runID string) ([]string, error) {
+	rows, err := r.db.Query(`
+		SELECT name
+		FROM test_cases
+		WHERE run_id = ? AND status = 'failed'
+		ORDER BY id ASC
+	`, runID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query test cases: %w", err)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan test case: %w", err)
+		}
+		names = append(names, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating test cases: %w", err)
+	}
+
+	return names, nil
+}
+
+// exportAttachments returns the files attached to a session, oldest first
+func (r *reporter) exportAttachments(sessionID string) ([]export.Attachment, error) {
+	rows, err := r.db.Query(`
+		SELECT file_name, media_type, path, sha256, size, caption, attached_at
+		FROM attachments
+		WHERE session_id = ?
+	`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query attachments: %w", err)
+	}
+	defer rows.Close()
+
+	attachments := []export.Attachment{}
+	for rows.Next() {
+		var attachment export.Attachment
+		var caption sql.NullString
+		if err := rows.Scan(&attachment.Name, &attachment.MediaType, &attachment.Path, &attachment.SHA256,
+			&attachment.Size, &caption, &attachment.AttachedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan attachment: %w", err)
+		}
+		attachment.Caption = caption.String
+		attachments = append(attachments, attachment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating attachments: %w", err)
+	}
+
+	db.SortOldestFirst(attachments, func(a export.Attachment) time.Time { return a.AttachedAt })
+	return attachments, nil
+}
+
+// exportJournal returns a session's journal notes, oldest first
+func (r *reporter) exportJournal(sessionID string) ([]export.JournalEntry, error) {
+	rows, err := r.db.Query(`
+		SELECT text, created_at
+		FROM journal_entries
+		WHERE session_id = ?
+	`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query journal entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []export.JournalEntry{}
+	for rows.Next() {
+		var entry export.JournalEntry
+		if err := rows.Scan(&entry.Text, &entry.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan journal entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating journal entries: %w", err)
+	}
+
+	db.SortOldestFirst(entries, func(e export.JournalEntry) time.Time { return e.CreatedAt })
+	return entries, nil
+}
+
+// matches reports whether a session in project starting at t falls within the filter
+func (o ExportOptions) matches(project string, t time.Time) bool {
+	if o.Project != "" && !strings.EqualFold(o.Project, project) {
+		return false
+	}
+	return db.InRange(t, o.Since, o.Until)
+}
@@ -0,0 +1,434 @@
+package report
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/blobs"
+	"github.com/stwalsh4118/clio/internal/logging"
+	"github.com/stwalsh4118/clio/internal/search"
+)
+
+func TestReporter_ExportData(t *testing.T) {
+	database := setupTestReportDB(t)
+	base := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	insertTestSession(t, database, "alpha-1", "alpha", base)
+	insertTestSession(t, database, "beta-1", "beta", base.Add(24*time.Hour))
+	insertTestCommit(t, database, "correlated", "alpha", "alpha-1", base.Add(10*time.Minute))
+	insertTestCommit(t, database, "orphan", "alpha", nil, base.Add(20*time.Minute))
+
+	if _, err := database.Exec(`
+		INSERT INTO conversations (id, session_id, composer_id, name, status, message_count, first_message_time, created_at, updated_at)
+		VALUES ('conv-1', 'alpha-1', 'composer-1', 'Refactor', 'completed', 2, ?, ?, ?)
+	`, base, base, base); err != nil {
+		t.Fatalf("failed to create conversation: %v", err)
+	}
+	for i, role := range []string{"user", "agent"} {
+		if _, err := database.Exec(`
+			INSERT INTO messages (id, conversation_id, bubble_id, type, role, content, created_at)
+			VALUES (?, 'conv-1', ?, ?, ?, ?, ?)
+		`, role, role, i+1, role, "text from "+role, base.Add(time.Duration(i)*time.Minute)); err != nil {
+			t.Fatalf("failed to create message: %v", err)
+		}
+	}
+
+	if _, err := database.Exec(`UPDATE messages SET tool_calls = '[{"name":"read_file","status":"completed","toolIndex":0}]' WHERE id = 'agent'`); err != nil {
+		t.Fatalf("failed to add tool calls: %v", err)
+	}
+
+	if _, err := database.Exec(`
+		INSERT INTO test_runs (id, session_id, commit_hash, format, source_path, run_time, total, passed, failed, created_at)
+		VALUES ('run-1', 'alpha-1', 'correlated', 'gotest', 'results.json', ?, 2, 1, 1, ?)
+	`, base.Add(15*time.Minute), base); err != nil {
+		t.Fatalf("failed to create test run: %v", err)
+	}
+	if _, err := database.Exec(`
+		INSERT INTO test_cases (run_id, name, status) VALUES ('run-1', 'TestOK', 'passed'), ('run-1', 'TestBroken', 'failed')
+	`); err != nil {
+		t.Fatalf("failed to create test cases: %v", err)
+	}
+
+	if _, err := database.Exec(`
+		INSERT INTO attachments (id, session_id, sha256, file_name, media_type, size, path, caption, attached_at, created_at)
+		VALUES ('att-1', 'alpha-1', 'ab12', 'ui.png', 'image/png', 4, '/assets/ab/ab12.png', 'New layout', ?, ?)
+	`, base.Add(30*time.Minute), base); err != nil {
+		t.Fatalf("failed to create attachment: %v", err)
+	}
+	if _, err := database.Exec(`
+		INSERT INTO journal_entries (id, session_id, text, created_at)
+		VALUES ('note-2', 'alpha-1', 'Second', ?), ('note-1', 'alpha-1', 'First', ?)
+	`, base.Add(40*time.Minute), base.Add(5*time.Minute)); err != nil {
+		t.Fatalf("failed to create journal entries: %v", err)
+	}
+
+	reporter, err := NewReporter(database, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewReporter() error = %v", err)
+	}
+
+	data, err := reporter.ExportData(ExportOptions{Project: "ALPHA"})
+	if err != nil {
+		t.Fatalf("ExportData() error = %v", err)
+	}
+	if len(data.Sessions) != 1 || data.Sessions[0].ID != "alpha-1" {
+		t.Fatalf("sessions = %+v, want only alpha-1", data.Sessions)
+	}
+
+	session := data.Sessions[0]
+	if len(session.Conversations) != 1 || len(session.Conversations[0].Messages) != 2 {
+		t.Fatalf("conversations = %+v, want one with two messages", session.Conversations)
+	}
+	if got := session.Conversations[0].Messages[0].Text; got != "text from user" {
+		t.Errorf("first message = %q, want the user message", got)
+	}
+	if calls := session.Conversations[0].Messages[1].ToolCalls; len(calls) != 1 || calls[0].Name != "read_file" {
+		t.Errorf("agent tool calls = %+v, want read_file", calls)
+	}
+	if len(session.Commits) != 1 || session.Commits[0].Hash != "correlated" {
+		t.Errorf("commits = %+v, want only the correlated commit", session.Commits)
+	}
+	if len(session.TestRuns) != 1 || session.TestRuns[0].CommitHash != "correlated" ||
+		len(session.TestRuns[0].FailedTests) != 1 || session.TestRuns[0].FailedTests[0] != "TestBroken" {
+		t.Errorf("test runs = %+v, want one run with TestBroken failed", session.TestRuns)
+	}
+	if len(session.Attachments) != 1 || !session.Attachments[0].IsImage() || session.Attachments[0].Caption != "New layout" {
+		t.Errorf("attachments = %+v, want the captioned image", session.Attachments)
+	}
+	if len(session.Journal) != 2 || session.Journal[0].Text != "First" {
+		t.Errorf("journal = %+v, want both notes oldest first", session.Journal)
+	}
+
+	data, err = reporter.ExportData(ExportOptions{SessionID: "beta-1"})
+	if err != nil {
+		t.Fatalf("ExportData() error = %v", err)
+	}
+	if len(data.Sessions) != 1 || data.Sessions[0].ID != "beta-1" {
+		t.Errorf("sessions by ID = %+v, want only beta-1", data.Sessions)
+	}
+
+	data, err = reporter.ExportData(ExportOptions{Since: base.Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("ExportData() error = %v", err)
+	}
+	if len(data.Sessions) != 1 || data.Sessions[0].ID != "beta-1" {
+		t.Errorf("sessions since = %+v, want only beta-1", data.Sessions)
+	}
+
+	// alpha-1 counts through its commit mentioning #bugfix, beta-1 is tagged explicitly
+	if _, err := database.Exec(`INSERT INTO goals (tag, title, created_at) VALUES ('bugfix', 'Bug fixes', ?)`, base); err != nil {
+		t.Fatalf("failed to create goal: %v", err)
+	}
+	if _, err := database.Exec(`INSERT INTO goal_sessions (goal_tag, session_id, created_at) VALUES ('bugfix', 'beta-1', ?)`, base); err != nil {
+		t.Fatalf("failed to tag session: %v", err)
+	}
+	data, err = reporter.ExportData(ExportOptions{Tag: "bugfix"})
+	if err != nil {
+		t.Fatalf("ExportData() error = %v", err)
+	}
+	if len(data.Sessions) != 1 || data.Sessions[0].ID != "beta-1" {
+		t.Errorf("sessions by tag = %+v, want only beta-1", data.Sessions)
+	}
+
+	if _, err := database.Exec(`UPDATE commits SET message = 'Fix crash #BugFix' WHERE hash = 'correlated'`); err != nil {
+		t.Fatalf("failed to update commit: %v", err)
+	}
+	data, err = reporter.ExportData(ExportOptions{Tag: "BUGFIX"})
+	if err != nil {
+		t.Fatalf("ExportData() error = %v", err)
+	}
+	if len(data.Sessions) != 2 {
+		t.Errorf("sessions by tag = %+v, want alpha-1 and beta-1", data.Sessions)
+	}
+
+	// alpha-1 matches through its commit's metadata, beta-1 through its own
+	if _, err := database.Exec(`
+		INSERT INTO entity_metadata (entity_type, entity_id, key, value, updated_at)
+		VALUES ('commit', 'correlated', 'customer', 'acme', ?), ('session', 'beta-1', 'customer', 'Acme', ?),
+			('session', 'alpha-1', 'customer', 'globex', ?)
+	`, base, base, base); err != nil {
+		t.Fatalf("failed to create metadata: %v", err)
+	}
+	data, err = reporter.ExportData(ExportOptions{MetaKey: "customer", MetaValue: "acme"})
+	if err != nil {
+		t.Fatalf("ExportData() error = %v", err)
+	}
+	if len(data.Sessions) != 2 {
+		t.Fatalf("sessions by metadata = %+v, want alpha-1 and beta-1", data.Sessions)
+	}
+	if got := data.Sessions[0]; got.Metadata["customer"] != "globex" || got.Commits[0].Metadata["customer"] != "acme" {
+		t.Errorf("alpha-1 = %+v, want session and commit metadata", got)
+	}
+	data, err = reporter.ExportData(ExportOptions{MetaKey: "customer", MetaValue: "initech"})
+	if err != nil || len(data.Sessions) != 0 {
+		t.Errorf("ExportData() by unknown metadata = %+v, %v, want no sessions", data, err)
+	}
+}
+
+func TestReporter_ExportDataMixedOffsets(t *testing.T) {
+	database := setupTestReportDB(t)
+	base := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	berlin := time.FixedZone("CET", 3600)
+	// Stored oldest first by time, but the middle one sorts last as text
+	times := []time.Time{base, base.Add(30 * time.Minute).In(berlin), base.Add(time.Hour)}
+	names := []string{"first", "second", "third"}
+
+	for i, at := range times {
+		insertTestSession(t, database, "s-"+names[i], "alpha", at)
+	}
+	for i, at := range times {
+		insertTestCommit(t, database, names[i], "alpha", "s-first", at)
+		if _, err := database.Exec(`
+			INSERT INTO conversations (id, session_id, composer_id, name, status, message_count, first_message_time, created_at, updated_at)
+			VALUES (?, 's-first', ?, ?, 'completed', 1, ?, ?, ?)
+		`, "conv-"+names[i], "composer-"+names[i], names[i], at, at, at); err != nil {
+			t.Fatalf("failed to create conversation: %v", err)
+		}
+		if _, err := database.Exec(`
+			INSERT INTO messages (id, conversation_id, bubble_id, type, role, content, created_at)
+			VALUES (?, 'conv-first', ?, 1, 'user', ?, ?)
+		`, names[i], names[i], names[i], at); err != nil {
+			t.Fatalf("failed to create message: %v", err)
+		}
+		if _, err := database.Exec(`
+			INSERT INTO test_runs (id, session_id, format, source_path, run_time, total, passed, failed, created_at)
+			VALUES (?, 's-first', ?, 'results.json', ?, 1, 1, 0, ?)
+		`, names[i], names[i], at, at); err != nil {
+			t.Fatalf("failed to create test run: %v", err)
+		}
+	}
+
+	reporter, err := NewReporter(database, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewReporter() error = %v", err)
+	}
+	data, err := reporter.ExportData(ExportOptions{})
+	if err != nil {
+		t.Fatalf("ExportData() error = %v", err)
+	}
+
+	var sessions, conversations, messages, commits, runs []string
+	for _, session := range data.Sessions {
+		sessions = append(sessions, strings.TrimPrefix(session.ID, "s-"))
+	}
+	first := data.Sessions[0]
+	for _, conversation := range first.Conversations {
+		conversations = append(conversations, conversation.Name)
+	}
+	for _, message := range first.Conversations[0].Messages {
+		messages = append(messages, message.Text)
+	}
+	for _, commit := range first.Commits {
+		commits = append(commits, commit.Hash)
+	}
+	for _, run := range first.TestRuns {
+		runs = append(runs, run.Format)
+	}
+	for name, got := range map[string][]string{
+		"sessions": sessions, "conversations": conversations, "messages": messages, "commits": commits, "test runs": runs,
+	} {
+		if !slices.Equal(got, names) {
+			t.Errorf("%s = %v, want %v", name, got, names)
+		}
+	}
+}
+
+func TestReporter_ExportDataMinConfidence(t *testing.T) {
+	database := setupTestReportDB(t)
+	base := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	insertTestSession(t, database, "alpha-1", "alpha", base)
+	insertTestCommit(t, database, "weak", "alpha", "alpha-1", base.Add(10*time.Minute))
+	insertTestCommit(t, database, "at-threshold", "alpha", "alpha-1", base.Add(20*time.Minute))
+	insertTestCommit(t, database, "strong", "alpha", "alpha-1", base.Add(30*time.Minute))
+	// Stored before confidence scoring
+	insertTestCommit(t, database, "unscored", "alpha", "alpha-1", base.Add(40*time.Minute))
+	for hash, confidence := range map[string]float64{"weak": 0.3, "at-threshold": 0.5, "strong": 0.9} {
+		if _, err := database.Exec("UPDATE commits SET correlation_confidence = ? WHERE hash = ?", confidence, hash); err != nil {
+			t.Fatalf("failed to set confidence: %v", err)
+		}
+	}
+
+	reporter, err := NewReporter(database, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewReporter() error = %v", err)
+	}
+
+	hashes := func(opts ExportOptions) []string {
+		t.Helper()
+		data, err := reporter.ExportData(opts)
+		if err != nil {
+			t.Fatalf("ExportData() error = %v", err)
+		}
+		if len(data.Sessions) != 1 {
+			t.Fatalf("ExportData() sessions = %d, want 1", len(data.Sessions))
+		}
+		var hashes []string
+		for _, commit := range data.Sessions[0].Commits {
+			hashes = append(hashes, commit.Hash)
+		}
+		return hashes
+	}
+
+	if got := hashes(ExportOptions{MinConfidence: 0.5}); !slices.Equal(got, []string{"at-threshold", "strong", "unscored"}) {
+		t.Errorf("commits at 0.5 = %v, want at-threshold, strong, and unscored", got)
+	}
+	if got := hashes(ExportOptions{}); len(got) != 4 {
+		t.Errorf("commits without a threshold = %v, want all 4", got)
+	}
+}
+
+func TestReporter_Search(t *testing.T) {
+	database := setupTestReportDB(t)
+	base := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	insertTestSession(t, database, "alpha-1", "alpha", base)
+	if _, err := database.Exec(`
+		INSERT INTO conversations (id, session_id, composer_id, name, status, message_count, created_at, updated_at)
+		VALUES ('conv-1', 'alpha-1', 'composer-1', 'Retries', 'completed', 3, ?, ?)
+	`, base, base); err != nil {
+		t.Fatalf("failed to create conversation: %v", err)
+	}
+	contents := []string{"Add RETRY logic to the pipeline", "unrelated", "retry with 100% backoff"}
+	for i, content := range contents {
+		if _, err := database.Exec(`
+			INSERT INTO messages (id, conversation_id, bubble_id, type, role, content, created_at)
+			VALUES (?, 'conv-1', ?, 1, 'user', ?, ?)
+		`, content, content, content, base.Add(time.Duration(i)*time.Minute)); err != nil {
+			t.Fatalf("failed to create message: %v", err)
+		}
+	}
+
+	reporter, err := NewReporter(database, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewReporter() error = %v", err)
+	}
+
+	// Search only reads the index, which the daemon keeps up to date
+	if _, err := reporter.Search(SearchOptions{Query: "retry"}); !errors.Is(err, search.ErrNotBuilt) {
+		t.Errorf("Search() before indexing error = %v, want ErrNotBuilt", err)
+	}
+	index, err := search.NewIndex(database, nil, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewIndex() error = %v", err)
+	}
+	if _, err := index.Sync(); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	hits, err := reporter.Search(SearchOptions{Query: "retry"})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(hits) != 2 || hits[0].Snippet != "retry with 100% backoff" || hits[0].ConversationName != "Retries" {
+		t.Errorf("Search() = %+v, want both retry messages newest first", hits)
+	}
+
+	// Punctuation in the query is ignored, like in the indexed text
+	if hits, _ := reporter.Search(SearchOptions{Query: "100%"}); len(hits) != 1 {
+		t.Errorf("Search(100%%) returned %d hits, want 1", len(hits))
+	}
+	if hits, _ := reporter.Search(SearchOptions{Query: "retry", Limit: 1}); len(hits) != 1 || hits[0].Snippet != "retry with 100% backoff" {
+		t.Errorf("Search() with limit = %+v, want the newest hit", hits)
+	}
+	if hits, _ := reporter.Search(SearchOptions{Query: "retry", Project: "beta"}); len(hits) != 0 {
+		t.Errorf("Search() for another project returned %d hits, want 0", len(hits))
+	}
+	if _, err := reporter.Search(SearchOptions{Query: "  "}); err == nil {
+		t.Error("Search() with an empty query should fail")
+	}
+
+	// Markdown syntax doesn't get in the way, and code is searched on its own
+	markdown := "## Plan\n\nWrap the **fetch call** in a [backoff](https://example.com) loop:\n\n```go\nfor attempt := 0; attempt < maxAttempts; attempt++ {\n```"
+	if _, err := database.Exec(`
+		INSERT INTO messages (id, conversation_id, bubble_id, type, role, content, created_at)
+		VALUES ('markdown', 'conv-1', 'markdown', 2, 'agent', ?, ?)
+	`, markdown, base.Add(time.Hour)); err != nil {
+		t.Fatalf("failed to create message: %v", err)
+	}
+	if _, err := index.Sync(); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if hits, err := reporter.Search(SearchOptions{Query: "fetch call in a backoff loop"}); err != nil || len(hits) != 1 {
+		t.Errorf("Search() across markdown = %+v, %v, want the markdown message", hits, err)
+	}
+	if hits, _ := reporter.Search(SearchOptions{Query: "attempt", CodeOnly: true}); len(hits) != 1 || !strings.HasPrefix(hits[0].Snippet, "for attempt") {
+		t.Errorf("Search() code only = %+v, want the code block", hits)
+	}
+	if hits, _ := reporter.Search(SearchOptions{Query: "backoff", CodeOnly: true}); len(hits) != 0 {
+		t.Errorf("Search() code only matched prose: %+v", hits)
+	}
+
+	// The query language combines terms, fields, and time bounds
+	queries := []struct {
+		query string
+		want  int
+	}{
+		{query: "retry AND NOT backoff", want: 1},
+		{query: `"retry with" OR unrelated`, want: 2},
+		{query: "code:attempt prose:backoff", want: 1},
+		{query: "role:agent loop", want: 1},
+		{query: "role:user loop", want: 0},
+		{query: "project:ALPHA conversation:retr backoff", want: 2},
+		{query: "session:alpha -", want: 4},
+		{query: "retry date:2024-01-01..2024-01-31", want: 2},
+		{query: "retry since:2024-02-01", want: 0},
+	}
+	for _, tt := range queries {
+		hits, err := reporter.Search(SearchOptions{Query: tt.query})
+		if err != nil || len(hits) != tt.want {
+			t.Errorf("Search(%s) = %d hits, %v, want %d", tt.query, len(hits), err, tt.want)
+		}
+	}
+	if _, err := reporter.Search(SearchOptions{Query: "retry OR since:7d"}); err == nil {
+		t.Error("Search() with a time bound inside OR should fail")
+	}
+
+	// Context comes from the message as written, or its code with CodeOnly
+	hits, err = reporter.Search(SearchOptions{Query: "backoff role:agent", Context: 1})
+	wantContext := []string{"", "Wrap the **fetch call** in a [backoff](https://example.com) loop:", ""}
+	if err != nil || len(hits) != 1 || hits[0].MessageID != "markdown" || !slices.Equal(hits[0].Context, wantContext) {
+		t.Errorf("Search() with context = %+v, %v, want lines %q", hits, err, wantContext)
+	}
+	hits, err = reporter.Search(SearchOptions{Query: "attempt", CodeOnly: true, Context: 3})
+	if err != nil || len(hits) != 1 || len(hits[0].Context) != 1 || !strings.HasPrefix(hits[0].Context[0], "for attempt") {
+		t.Errorf("Search() code only with context = %+v, %v, want the code line", hits, err)
+	}
+}
+
+func TestReporter_SearchContextResolvesBlobs(t *testing.T) {
+	database := setupTestReportDB(t)
+	store := newTestBlobStore(t)
+	base := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	insertTestSession(t, database, "alpha-1", "alpha", base)
+	if _, err := database.Exec(`
+		INSERT INTO conversations (id, session_id, composer_id, name, status, message_count, created_at, updated_at)
+		VALUES ('conv-1', 'alpha-1', 'composer-1', 'Retries', 'completed', 1, ?, ?)
+	`, base, base); err != nil {
+		t.Fatalf("failed to create conversation: %v", err)
+	}
+	long := strings.Repeat("trace line\n", blobs.PreviewLength/11+1) + "retry with backoff"
+	insertTestBlobMessage(t, database, store, "long", "conv-1", "agent", long, base)
+
+	index, err := search.NewIndex(database, store, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewIndex() error = %v", err)
+	}
+	if _, err := index.Sync(); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	reporter, err := NewReporterWithBlobs(database, store, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewReporterWithBlobs() error = %v", err)
+	}
+
+	// The matching line lies past the preview kept in the row
+	hits, err := reporter.Search(SearchOptions{Query: "backoff", Context: 1})
+	if err != nil || len(hits) != 1 || !slices.Contains(hits[0].Context, "retry with backoff") {
+		t.Errorf("Search() with context = %+v, %v, want the line from the blob store", hits, err)
+	}
+}
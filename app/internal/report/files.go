@@ -0,0 +1,136 @@
+package report
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/db"
+)
+
+const (
+	// DefaultHeartbeatTimeout is the longest gap between heartbeats counted as
+	// activity, matching WakaTime's default keystroke timeout
+	DefaultHeartbeatTimeout = 15 * time.Minute
+)
+
+// FileActivityOptions filters the per-file time report
+type FileActivityOptions struct {
+	Project string        // Only include this project (case-insensitive); empty includes all
+	Since   time.Time     // Only include heartbeats at or after this time; zero means no lower bound
+	Until   time.Time     // Only include heartbeats before this time; zero means no upper bound
+	Timeout time.Duration // Longer gaps between heartbeats aren't counted; zero uses DefaultHeartbeatTimeout
+}
+
+// FileActivity is the time spent on one file, derived from editor heartbeats
+type FileActivity struct {
+	Project    string
+	Entity     string // File path
+	Language   string // Most recently reported language
+	Duration   time.Duration
+	Heartbeats int
+	Writes     int // Heartbeats triggered by saving the file
+	LastSeen   time.Time
+}
+
+// fileHeartbeat is a heartbeat row used for time attribution
+type fileHeartbeat struct {
+	project  string
+	entity   string
+	language string
+	isWrite  bool
+	time     time.Time
+}
+
+// FileActivity attributes editing time to files from heartbeats. As in WakaTime,
+// the gap between consecutive heartbeats counts towards the earlier heartbeat's file
+// unless it exceeds the timeout. Gaps are measured across all projects, so switching
+// projects ends the previous file's time. Results are sorted by duration, longest first.
+func (r *reporter) FileActivity(opts FileActivityOptions) ([]FileActivity, error) {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultHeartbeatTimeout
+	}
+
+	heartbeats, err := r.loadHeartbeats()
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]*FileActivity)
+	for i, h := range heartbeats {
+		if opts.Project != "" && !strings.EqualFold(opts.Project, h.project) {
+			continue
+		}
+		if !db.InRange(h.time, opts.Since, opts.Until) {
+			continue
+		}
+
+		key := h.project + "\x00" + h.entity
+		file := files[key]
+		if file == nil {
+			file = &FileActivity{Project: h.project, Entity: h.entity}
+			files[key] = file
+		}
+		file.Heartbeats++
+		if h.isWrite {
+			file.Writes++
+		}
+		if h.language != "" {
+			file.Language = h.language
+		}
+		file.LastSeen = h.time
+
+		if i+1 < len(heartbeats) {
+			if gap := heartbeats[i+1].time.Sub(h.time); gap <= timeout {
+				file.Duration += gap
+			}
+		}
+	}
+
+	result := make([]FileActivity, 0, len(files))
+	for _, file := range files {
+		result = append(result, *file)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Duration != result[j].Duration {
+			return result[i].Duration > result[j].Duration
+		}
+		return result[i].Entity < result[j].Entity
+	})
+
+	r.logger.Debug("generated file activity report", "files", len(result))
+	return result, nil
+}
+
+// loadHeartbeats returns every file heartbeat, oldest first
+func (r *reporter) loadHeartbeats() ([]fileHeartbeat, error) {
+	rows, err := r.db.Query(`
+		SELECT project, entity, language, is_write, time
+		FROM heartbeats
+		WHERE entity_type = 'file'
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query heartbeats: %w", err)
+	}
+	defer rows.Close()
+
+	var heartbeats []fileHeartbeat
+	for rows.Next() {
+		var h fileHeartbeat
+		var language sql.NullString
+		if err := rows.Scan(&h.project, &h.entity, &language, &h.isWrite, &h.time); err != nil {
+			return nil, fmt.Errorf("failed to scan heartbeat: %w", err)
+		}
+		h.language = language.String
+		heartbeats = append(heartbeats, h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating heartbeats: %w", err)
+	}
+
+	db.SortOldestFirst(heartbeats, func(f fileHeartbeat) time.Time { return f.time })
+	return heartbeats, nil
+}
@@ -0,0 +1,67 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+func TestReporter_FileActivity(t *testing.T) {
+	database := setupTestReportDB(t)
+	base := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	heartbeats := []struct {
+		project, entity string
+		offset          time.Duration
+		isWrite         bool
+	}{
+		{"clio", "/src/clio/main.go", 0, false},
+		{"clio", "/src/clio/main.go", 2 * time.Minute, true},
+		{"clio", "/src/clio/api.go", 5 * time.Minute, false},
+		{"other", "/src/other/x.go", 8 * time.Minute, false},
+		// A gap longer than the timeout isn't counted
+		{"clio", "/src/clio/main.go", time.Hour, false},
+		{"clio", "/src/clio/main.go", time.Hour + time.Minute, false},
+	}
+	// Inserted out of order to check times are sorted before attribution
+	for i := len(heartbeats) - 1; i >= 0; i-- {
+		h := heartbeats[i]
+		if _, err := database.Exec(`
+			INSERT INTO heartbeats (project, entity, is_write, language, time, created_at)
+			VALUES (?, ?, ?, 'Go', ?, ?)
+		`, h.project, h.entity, h.isWrite, base.Add(h.offset), base); err != nil {
+			t.Fatalf("failed to create heartbeat: %v", err)
+		}
+	}
+
+	reporter, err := NewReporter(database, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewReporter() error = %v", err)
+	}
+
+	files, err := reporter.FileActivity(FileActivityOptions{Project: "CLIO"})
+	if err != nil {
+		t.Fatalf("FileActivity() error = %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("files = %+v, want main.go and api.go", files)
+	}
+
+	mainFile, apiFile := files[0], files[1]
+	if mainFile.Entity != "/src/clio/main.go" || mainFile.Duration != 6*time.Minute || mainFile.Heartbeats != 4 || mainFile.Writes != 1 {
+		t.Errorf("main.go = %+v, want 6m over 4 heartbeats with 1 save", mainFile)
+	}
+	// Time on api.go ends when work switches to the other project
+	if apiFile.Entity != "/src/clio/api.go" || apiFile.Duration != 3*time.Minute || apiFile.Language != "Go" {
+		t.Errorf("api.go = %+v, want 3m", apiFile)
+	}
+
+	files, err = reporter.FileActivity(FileActivityOptions{Timeout: 2 * time.Hour, Until: base.Add(30 * time.Minute)})
+	if err != nil {
+		t.Fatalf("FileActivity() error = %v", err)
+	}
+	if len(files) != 3 || files[0].Entity != "/src/other/x.go" || files[0].Duration != 52*time.Minute {
+		t.Errorf("files with a long timeout = %+v, want x.go first with 52m", files)
+	}
+}
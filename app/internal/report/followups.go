@@ -0,0 +1,212 @@
+package report
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/blobs"
+	"github.com/stwalsh4118/clio/internal/commitmsg"
+	"github.com/stwalsh4118/clio/internal/db"
+)
+
+// FollowUpOptions filters the revert and fixup report
+type FollowUpOptions struct {
+	Project string    // Only include this project (case-insensitive); empty includes all
+	Since   time.Time // Only include follow-up commits at or after this time; zero means no lower bound
+	Until   time.Time // Only include follow-up commits before this time; zero means no upper bound
+}
+
+// FollowUpCommit is a revert or fixup commit and the commit it follows up on
+type FollowUpCommit struct {
+	Kind      string // commitmsg.FollowUpRevert or commitmsg.FollowUpFixup
+	Hash      string
+	Project   string // Repository name
+	Message   string
+	Timestamp time.Time
+	SessionID string          // Empty when the follow-up isn't correlated with a session
+	Original  *OriginalCommit // Nil when the commit it names wasn't captured
+}
+
+// OriginalCommit is a commit that was later reverted or fixed up, with where it came from
+type OriginalCommit struct {
+	Hash      string
+	Message   string
+	Timestamp time.Time
+	SessionID string // Empty when the commit isn't correlated with a session
+	// ComposerID and Conversation name the conversation of the session that was
+	// active last before the commit, the one it most likely came out of; empty
+	// without a session or conversations before the commit
+	ComposerID   string
+	Conversation string
+	AILines      int // Added lines that appeared in a code block earlier in the session, as Attribution counts them
+	HumanLines   int
+}
+
+// AIShare returns the fraction of the original's attributable lines that came from AI suggestions
+func (c OriginalCommit) AIShare() float64 {
+	return share(c.AILines, c.HumanLines)
+}
+
+// followUpCandidate is a stored commit considered as a follow-up or an original
+type followUpCandidate struct {
+	hash      string
+	project   string
+	message   string
+	timestamp time.Time
+	sessionID string
+}
+
+// FollowUps finds revert and fixup commits and links each to the commit it
+// reverts or fixes up, that commit's session, and the conversation it most
+// likely came from, so changes that were later undone can be traced back to
+// the conversation that produced them. Reverts name their commit by hash, or by
+// subject when the message was edited; fixups by subject, matched against the
+// latest earlier commit with that subject in the same repository. Follow-ups
+// are returned newest first.
+func (r *reporter) FollowUps(opts FollowUpOptions) ([]FollowUpCommit, error) {
+	rows, err := r.db.Query(`
+		SELECT hash, repository_name, message, timestamp, session_id
+		FROM commits
+		WHERE is_merge = 0
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query commits: %w", err)
+	}
+	defer rows.Close()
+
+	var commits []followUpCandidate
+	for rows.Next() {
+		var commit followUpCandidate
+		var sessionID sql.NullString
+		if err := rows.Scan(&commit.hash, &commit.project, &commit.message, &commit.timestamp, &sessionID); err != nil {
+			return nil, fmt.Errorf("failed to scan commit: %w", err)
+		}
+		commit.sessionID = sessionID.String
+		commits = append(commits, commit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating commits: %w", err)
+	}
+	sort.SliceStable(commits, func(i, j int) bool { return commits[i].timestamp.Before(commits[j].timestamp) })
+
+	var followUps []FollowUpCommit
+	suggested := make(map[string]map[string]time.Time)
+	for i, commit := range commits {
+		parsed, ok := commitmsg.ParseFollowUp(commit.message)
+		if !ok || opts.Project != "" && !strings.EqualFold(opts.Project, commit.project) ||
+			!db.InRange(commit.timestamp, opts.Since, opts.Until) {
+			continue
+		}
+		followUp := FollowUpCommit{
+			Kind:      parsed.Kind,
+			Hash:      commit.hash,
+			Project:   commit.project,
+			Message:   commit.message,
+			Timestamp: commit.timestamp,
+			SessionID: commit.sessionID,
+		}
+		if original := findOriginal(commits[:i], commit.project, parsed); original != nil {
+			if followUp.Original, err = r.describeOriginal(*original, suggested); err != nil {
+				return nil, err
+			}
+		}
+		followUps = append(followUps, followUp)
+	}
+
+	sort.SliceStable(followUps, func(i, j int) bool { return followUps[i].Timestamp.After(followUps[j].Timestamp) })
+	r.logger.Debug("found follow-up commits", "count", len(followUps))
+	return followUps, nil
+}
+
+// findOriginal returns the commit a follow-up names among the earlier commits
+// of its repository, latest first, or nil
+func findOriginal(earlier []followUpCandidate, project string, parsed commitmsg.FollowUp) *followUpCandidate {
+	for i := len(earlier) - 1; i >= 0; i-- {
+		candidate := &earlier[i]
+		if !strings.EqualFold(candidate.project, project) {
+			continue
+		}
+		if parsed.Hash != "" {
+			if strings.HasPrefix(candidate.hash, parsed.Hash) {
+				return candidate
+			}
+			continue
+		}
+		if commitmsg.Subject(candidate.message) == parsed.Subject {
+			return candidate
+		}
+	}
+	return nil
+}
+
+// describeOriginal looks up where an original commit came from: its
+// conversation and how many of its lines came from AI suggestions. suggested
+// caches each session's suggested lines.
+func (r *reporter) describeOriginal(commit followUpCandidate, suggested map[string]map[string]time.Time) (*OriginalCommit, error) {
+	original := &OriginalCommit{
+		Hash:      commit.hash,
+		Message:   commit.message,
+		Timestamp: commit.timestamp,
+		SessionID: commit.sessionID,
+	}
+
+	var diff, diffBlob sql.NullString
+	err := r.db.QueryRow(`SELECT full_diff, full_diff_blob FROM commits WHERE hash = ? LIMIT 1`, commit.hash).Scan(&diff, &diffBlob)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query diff: %w", err)
+	}
+	diffText, err := blobs.Resolve(r.blobs, diff.String, diffBlob)
+	if err != nil {
+		// A missing blob only shortens the diff to its preview
+		r.logger.Warn("failed to load diff blob", "hash", commit.hash, "error", err)
+	}
+
+	if commit.sessionID != "" {
+		if suggested[commit.sessionID] == nil {
+			if suggested[commit.sessionID], err = r.suggestedLines(commit.sessionID); err != nil {
+				return nil, err
+			}
+		}
+		if original.ComposerID, original.Conversation, err = r.activeConversation(commit.sessionID, commit.timestamp); err != nil {
+			return nil, err
+		}
+	}
+	original.AILines, original.HumanLines, _ = attributeLines(diffText, suggested[commit.sessionID], commit.timestamp)
+	return original, nil
+}
+
+// activeConversation returns the composer ID and name of the session's
+// conversation with the latest message at or before at, or empty strings
+func (r *reporter) activeConversation(sessionID string, at time.Time) (string, string, error) {
+	rows, err := r.db.Query(`
+		SELECT c.composer_id, COALESCE(c.name, ''), m.created_at
+		FROM messages m
+		JOIN conversations c ON c.id = m.conversation_id
+		WHERE c.session_id = ?
+	`, sessionID)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to query messages: %w", err)
+	}
+	defer rows.Close()
+
+	var composerID, name string
+	var latest time.Time
+	for rows.Next() {
+		var id, conversationName string
+		var createdAt time.Time
+		if err := rows.Scan(&id, &conversationName, &createdAt); err != nil {
+			return "", "", fmt.Errorf("failed to scan message: %w", err)
+		}
+		if createdAt.After(at) || !createdAt.After(latest) && composerID != "" {
+			continue
+		}
+		composerID, name, latest = id, conversationName, createdAt
+	}
+	if err := rows.Err(); err != nil {
+		return "", "", fmt.Errorf("error iterating messages: %w", err)
+	}
+	return composerID, name, nil
+}
@@ -0,0 +1,109 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/commitmsg"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+func TestReporter_FollowUps(t *testing.T) {
+	database := setupTestReportDB(t)
+	base := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	insertTestSession(t, database, "alpha-1", "alpha", base)
+	insertTestCommit(t, database, "aaaa1111", "alpha", "alpha-1", base.Add(30*time.Minute))
+	insertTestCommit(t, database, "bbbb2222", "alpha", nil, base.Add(2*time.Hour))
+	insertTestCommit(t, database, "cccc3333", "alpha", nil, base.Add(3*time.Hour))
+	insertTestCommit(t, database, "dddd4444", "alpha", nil, base.Add(4*time.Hour))
+	insertTestCommit(t, database, "eeee5555", "beta", nil, base.Add(5*time.Hour))
+	for hash, message := range map[string]string{
+		"aaaa1111": "feat: escape quotes",
+		"bbbb2222": "fixup! feat: escape quotes",
+		"cccc3333": "Revert \"feat: escape quotes\"\n\nThis reverts commit aaaa111.",
+		"dddd4444": "Revert \"feat: never captured\"\n\nThis reverts commit 99999999.",
+		// Same subject in another repository isn't alpha's commit
+		"eeee5555": "fixup! feat: escape quotes",
+	} {
+		if _, err := database.Exec("UPDATE commits SET message = ? WHERE hash = ?", message, hash); err != nil {
+			t.Fatalf("failed to set message: %v", err)
+		}
+	}
+	diff := "diff --git a/lexer.go b/lexer.go\n--- a/lexer.go\n+++ b/lexer.go\n@@ -1 +1,2 @@\n" +
+		"+return strings.ReplaceAll(s, quote, escaped)\n+// handwritten comment\n"
+	if _, err := database.Exec("UPDATE commits SET full_diff = ? WHERE hash = 'aaaa1111'", diff); err != nil {
+		t.Fatalf("failed to set diff: %v", err)
+	}
+
+	if _, err := database.Exec(`
+		INSERT INTO conversations (id, session_id, composer_id, name, status, message_count, created_at, updated_at)
+		VALUES ('conv-1', 'alpha-1', 'composer-1', 'Escaping', 'completed', 1, ?, ?),
+			('conv-2', 'alpha-1', 'composer-2', 'Later chat', 'completed', 1, ?, ?)
+	`, base, base, base, base); err != nil {
+		t.Fatalf("failed to create conversations: %v", err)
+	}
+	for _, m := range []struct {
+		id, conversation, role, blocks string
+		at                             time.Time
+	}{
+		{"suggestion", "conv-1", "agent", `[{"content":"return strings.ReplaceAll(s, quote, escaped)"}]`, base.Add(10 * time.Minute)},
+		// After the commit, so it isn't the conversation the commit came out of
+		{"later", "conv-2", "user", "", base.Add(time.Hour)},
+	} {
+		if _, err := database.Exec(`
+			INSERT INTO messages (id, conversation_id, bubble_id, type, role, content, created_at, code_blocks)
+			VALUES (?, ?, ?, 2, ?, 'text', ?, NULLIF(?, ''))
+		`, m.id, m.conversation, m.id, m.role, m.at, m.blocks); err != nil {
+			t.Fatalf("failed to create message: %v", err)
+		}
+	}
+
+	reporter, err := NewReporter(database, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewReporter() error = %v", err)
+	}
+
+	followUps, err := reporter.FollowUps(FollowUpOptions{Project: "Alpha"})
+	if err != nil {
+		t.Fatalf("FollowUps() error = %v", err)
+	}
+	if len(followUps) != 3 {
+		t.Fatalf("FollowUps() = %d follow-ups, want 3", len(followUps))
+	}
+
+	if followUps[0].Hash != "dddd4444" || followUps[0].Original != nil {
+		t.Errorf("first follow-up = %+v, want the revert of an uncaptured commit", followUps[0])
+	}
+	for _, followUp := range followUps[1:] {
+		original := followUp.Original
+		if original == nil || original.Hash != "aaaa1111" {
+			t.Fatalf("%s original = %+v, want aaaa1111", followUp.Hash, original)
+		}
+		if original.SessionID != "alpha-1" || original.ComposerID != "composer-1" || original.Conversation != "Escaping" {
+			t.Errorf("%s original source = %+v, want alpha-1's Escaping conversation", followUp.Hash, original)
+		}
+		if original.AILines != 1 || original.HumanLines != 1 {
+			t.Errorf("%s original attribution = %d AI, %d human, want 1 and 1", followUp.Hash, original.AILines, original.HumanLines)
+		}
+	}
+	if followUps[1].Kind != commitmsg.FollowUpRevert || followUps[2].Kind != commitmsg.FollowUpFixup {
+		t.Errorf("kinds = %s, %s, want revert then fixup", followUps[1].Kind, followUps[2].Kind)
+	}
+
+	beta, err := reporter.FollowUps(FollowUpOptions{Project: "beta"})
+	if err != nil {
+		t.Fatalf("FollowUps() error = %v", err)
+	}
+	if len(beta) != 1 || beta[0].Original != nil {
+		t.Errorf("beta follow-ups = %+v, want one without an original", beta)
+	}
+
+	recent, err := reporter.FollowUps(FollowUpOptions{Since: base.Add(150 * time.Minute), Until: base.Add(210 * time.Minute)})
+	if err != nil {
+		t.Fatalf("FollowUps() error = %v", err)
+	}
+	if len(recent) != 1 || recent[0].Hash != "cccc3333" {
+		t.Errorf("FollowUps(range) = %+v, want only the revert", recent)
+	}
+}
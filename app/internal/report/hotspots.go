@@ -0,0 +1,235 @@
+package report
+
+import (
+	"database/sql"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/db"
+)
+
+const (
+	// DefaultHotspots is how many files the hotspot report lists by default
+	DefaultHotspots = 20
+)
+
+// HotspotOptions filters the file hotspot report
+type HotspotOptions struct {
+	Project string    // Only include this repository (case-insensitive); empty includes all
+	Since   time.Time // Only include commits and messages at or after this time; zero means no lower bound
+	Until   time.Time // Only include commits and messages before this time; zero means no upper bound
+	Limit   int       // Files returned; zero uses DefaultHotspots, negative returns all
+	// Exclude drops files matching any of these path.Match patterns, tried
+	// against the file's path and its base name, e.g. "go.sum" or "docs/*"
+	Exclude []string
+}
+
+// Hotspot is a frequently changed file
+type Hotspot struct {
+	Project       string // Repository name
+	File          string // Path relative to the repository root
+	Commits       int
+	LinesAdded    int
+	LinesRemoved  int
+	Sessions      int       // Distinct sessions behind the commits
+	Conversations int       // Conversations mentioning the file in the repository's sessions
+	Mentions      int       // Messages mentioning the file in those conversations
+	LastChanged   time.Time // The latest commit touching the file
+}
+
+// Churn returns the lines added and removed
+func (h Hotspot) Churn() int {
+	return h.LinesAdded + h.LinesRemoved
+}
+
+// hotspotKey identifies a file in a repository
+type hotspotKey struct {
+	project, file string
+}
+
+// Hotspots returns the most frequently changed files, ranked by commits and then
+// by lines changed, with how often the conversations behind the repository's
+// commits mention them. Files changed often and discussed often are candidates
+// for refactoring. Merge commits aren't counted.
+func (r *reporter) Hotspots(opts HotspotOptions) ([]Hotspot, error) {
+	for _, pattern := range opts.Exclude {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid exclude pattern %q: %w", pattern, err)
+		}
+	}
+
+	rows, err := r.db.Query(`
+		SELECT c.hash, c.repository_name, c.session_id, c.timestamp, f.file_path, f.lines_added, f.lines_removed
+		FROM commit_files f
+		JOIN commits c ON c.id = f.commit_id
+		WHERE c.is_merge = 0
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query commit files: %w", err)
+	}
+	defer rows.Close()
+
+	files := make(map[hotspotKey]*Hotspot)
+	sessions := make(map[hotspotKey]map[string]bool)
+	for rows.Next() {
+		var hash, project, file string
+		var sessionID *string
+		var timestamp time.Time
+		var added, removed int
+		if err := rows.Scan(&hash, &project, &sessionID, &timestamp, &file, &added, &removed); err != nil {
+			return nil, fmt.Errorf("failed to scan commit file: %w", err)
+		}
+		if opts.Project != "" && !strings.EqualFold(opts.Project, project) ||
+			!db.InRange(timestamp, opts.Since, opts.Until) ||
+			excluded(file, opts.Exclude) {
+			continue
+		}
+
+		key := hotspotKey{project: project, file: file}
+		hotspot := files[key]
+		if hotspot == nil {
+			hotspot = &Hotspot{Project: project, File: file}
+			files[key] = hotspot
+			sessions[key] = make(map[string]bool)
+		}
+		hotspot.Commits++
+		hotspot.LinesAdded += added
+		hotspot.LinesRemoved += removed
+		if timestamp.After(hotspot.LastChanged) {
+			hotspot.LastChanged = timestamp
+		}
+		if sessionID != nil {
+			sessions[key][*sessionID] = true
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating commit files: %w", err)
+	}
+
+	hotspots := make([]Hotspot, 0, len(files))
+	for key, hotspot := range files {
+		hotspot.Sessions = len(sessions[key])
+		hotspots = append(hotspots, *hotspot)
+	}
+	sort.Slice(hotspots, func(i, j int) bool {
+		a, b := hotspots[i], hotspots[j]
+		if a.Commits != b.Commits {
+			return a.Commits > b.Commits
+		}
+		if a.Churn() != b.Churn() {
+			return a.Churn() > b.Churn()
+		}
+		if a.Project != b.Project {
+			return a.Project < b.Project
+		}
+		return a.File < b.File
+	})
+	limit := opts.Limit
+	if limit == 0 {
+		limit = DefaultHotspots
+	}
+	if limit > 0 && len(hotspots) > limit {
+		hotspots = hotspots[:limit]
+	}
+
+	// Mentions are only counted for the files returned, one project's messages at a time
+	messages := make(map[string][]mentionMessage)
+	for i := range hotspots {
+		project := hotspots[i].Project
+		if _, ok := messages[project]; !ok {
+			if messages[project], err = r.projectMessages(project, opts); err != nil {
+				return nil, err
+			}
+		}
+		conversations := make(map[string]bool)
+		for _, m := range messages[project] {
+			if mentionsFile(m.content, hotspots[i].File) {
+				hotspots[i].Mentions++
+				conversations[m.conversationID] = true
+			}
+		}
+		hotspots[i].Conversations = len(conversations)
+	}
+
+	r.logger.Debug("generated hotspot report", "files", len(files), "returned", len(hotspots))
+	return hotspots, nil
+}
+
+// mentionMessage is a message searched for file mentions
+type mentionMessage struct {
+	conversationID string
+	content        string
+}
+
+// projectMessages returns the messages in range from the sessions correlated
+// with a repository's commits
+func (r *reporter) projectMessages(project string, opts HotspotOptions) ([]mentionMessage, error) {
+	rows, err := r.db.Query(`
+		SELECT m.conversation_id, COALESCE(m.content, ''), m.content_blob, m.created_at
+		FROM messages m
+		JOIN conversations c ON c.id = m.conversation_id
+		WHERE c.session_id IN (SELECT session_id FROM commits WHERE repository_name = ? AND session_id IS NOT NULL)
+	`, project)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []mentionMessage
+	for rows.Next() {
+		var m mentionMessage
+		var contentBlob sql.NullString
+		var createdAt time.Time
+		if err := rows.Scan(&m.conversationID, &m.content, &contentBlob, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan message: %w", err)
+		}
+		if !db.InRange(createdAt, opts.Since, opts.Until) {
+			continue
+		}
+		m.content = r.messageText(m.content, contentBlob)
+		messages = append(messages, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating messages: %w", err)
+	}
+	return messages, nil
+}
+
+// mentionsFile reports whether content names file by its base name, or a path
+// ending in it, as a whole word; "parser.go" doesn't mention "er.go"
+func mentionsFile(content, file string) bool {
+	name := path.Base(file)
+	for rest, offset := content, 0; ; {
+		i := strings.Index(rest, name)
+		if i < 0 {
+			return false
+		}
+		start, end := offset+i, offset+i+len(name)
+		if (start == 0 || !isFileNameByte(content[start-1])) && (end == len(content) || !isFileNameByte(content[end]) || content[end] == '.' && (end+1 == len(content) || !isFileNameByte(content[end+1]))) {
+			return true
+		}
+		rest, offset = content[end:], end
+	}
+}
+
+// isFileNameByte reports whether b can be part of a file name, besides the
+// path separator, which may precede a base name
+func isFileNameByte(b byte) bool {
+	return b == '.' || b == '_' || b == '-' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
+
+// excluded reports whether file matches one of patterns, by path or base name
+func excluded(file string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, file); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, path.Base(file)); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,156 @@
+package report
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/blobs"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+func TestReporter_Hotspots(t *testing.T) {
+	database := setupTestReportDB(t)
+	base := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	insertTestSession(t, database, "alpha-1", "alpha", base)
+	insertTestCommit(t, database, "c1", "alpha", "alpha-1", base.Add(10*time.Minute))
+	insertTestCommit(t, database, "c2", "alpha", "alpha-1", base.Add(20*time.Minute))
+	insertTestCommit(t, database, "c3", "alpha", nil, base.Add(30*time.Minute))
+	insertTestCommit(t, database, "old", "alpha", nil, base.Add(-48*time.Hour))
+	insertTestCommit(t, database, "b1", "beta", nil, base.Add(40*time.Minute))
+	for _, f := range []struct {
+		commit, path   string
+		added, removed int
+	}{
+		{"c1", "internal/parser/lexer.go", 10, 2},
+		{"c2", "internal/parser/lexer.go", 5, 5},
+		{"c3", "internal/parser/lexer.go", 1, 0},
+		{"c1", "internal/parser/parser.go", 30, 0},
+		{"c2", "internal/parser/parser.go", 3, 1},
+		{"c3", "go.sum", 40, 40},
+		{"old", "README.md", 1, 1},
+		{"b1", "main.go", 2, 0},
+	} {
+		if _, err := database.Exec(`
+			INSERT INTO commit_files (id, commit_id, file_path, lines_added, lines_removed, created_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, f.commit+f.path, f.commit, f.path, f.added, f.removed, base); err != nil {
+			t.Fatalf("failed to create commit file: %v", err)
+		}
+	}
+
+	for _, conversation := range []string{"conv-1", "conv-2"} {
+		if _, err := database.Exec(`
+			INSERT INTO conversations (id, session_id, composer_id, name, status, message_count, created_at, updated_at)
+			VALUES (?, 'alpha-1', ?, 'Parser', 'completed', 2, ?, ?)
+		`, conversation, conversation, base, base); err != nil {
+			t.Fatalf("failed to create conversation: %v", err)
+		}
+	}
+	for i, m := range []struct{ conversation, text string }{
+		{"conv-1", "Why does lexer.go drop escaped quotes?"},
+		{"conv-1", "See internal/parser/lexer.go, line 40."},
+		{"conv-2", "The lexer.go state machine is hard to follow."},
+		{"conv-2", "Splitting parser.gopher isn't a mention, nor is myparser.go."},
+		{"conv-2", "parser.go."},
+	} {
+		if _, err := database.Exec(`
+			INSERT INTO messages (id, conversation_id, bubble_id, type, role, content, created_at)
+			VALUES (?, ?, ?, 1, 'user', ?, ?)
+		`, m.text, m.conversation, m.text, m.text, base.Add(time.Duration(i)*time.Minute)); err != nil {
+			t.Fatalf("failed to create message: %v", err)
+		}
+	}
+
+	reporter, err := NewReporter(database, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewReporter() error = %v", err)
+	}
+
+	hotspots, err := reporter.Hotspots(HotspotOptions{Since: base, Exclude: []string{"*.sum"}})
+	if err != nil {
+		t.Fatalf("Hotspots() error = %v", err)
+	}
+	if len(hotspots) != 3 {
+		t.Fatalf("Hotspots() returned %d files, want 3: %+v", len(hotspots), hotspots)
+	}
+
+	lexer := hotspots[0]
+	if lexer.File != "internal/parser/lexer.go" || lexer.Project != "alpha" {
+		t.Fatalf("first hotspot = %s %s, want alpha internal/parser/lexer.go", lexer.Project, lexer.File)
+	}
+	if lexer.Commits != 3 || lexer.LinesAdded != 16 || lexer.LinesRemoved != 7 {
+		t.Errorf("lexer.go counts = %d commits, +%d -%d, want 3 commits, +16 -7", lexer.Commits, lexer.LinesAdded, lexer.LinesRemoved)
+	}
+	if lexer.Sessions != 1 {
+		t.Errorf("lexer.go sessions = %d, want 1", lexer.Sessions)
+	}
+	if lexer.Mentions != 3 || lexer.Conversations != 2 {
+		t.Errorf("lexer.go mentions = %d in %d conversations, want 3 in 2", lexer.Mentions, lexer.Conversations)
+	}
+	if !lexer.LastChanged.Equal(base.Add(30 * time.Minute)) {
+		t.Errorf("lexer.go last changed = %v, want %v", lexer.LastChanged, base.Add(30*time.Minute))
+	}
+
+	parser := hotspots[1]
+	if parser.File != "internal/parser/parser.go" || parser.Commits != 2 {
+		t.Errorf("second hotspot = %s with %d commits, want internal/parser/parser.go with 2", parser.File, parser.Commits)
+	}
+	if parser.Mentions != 1 || parser.Conversations != 1 {
+		t.Errorf("parser.go mentions = %d in %d conversations, want 1 in 1", parser.Mentions, parser.Conversations)
+	}
+
+	// beta's commits have no session, so nothing mentions its files
+	if hotspots[2].Project != "beta" || hotspots[2].Mentions != 0 {
+		t.Errorf("third hotspot = %+v, want beta main.go without mentions", hotspots[2])
+	}
+
+	limited, err := reporter.Hotspots(HotspotOptions{Project: "ALPHA", Limit: 1})
+	if err != nil {
+		t.Fatalf("Hotspots() error = %v", err)
+	}
+	if len(limited) != 1 || limited[0].File != "internal/parser/lexer.go" {
+		t.Errorf("Hotspots(limit 1) = %+v, want lexer.go only", limited)
+	}
+
+	if _, err := reporter.Hotspots(HotspotOptions{Exclude: []string{"["}}); err == nil {
+		t.Error("Hotspots() with an invalid exclude pattern should fail")
+	}
+}
+
+func TestReporter_HotspotsResolvesBlobs(t *testing.T) {
+	database := setupTestReportDB(t)
+	store := newTestBlobStore(t)
+	base := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	insertTestSession(t, database, "alpha-1", "alpha", base)
+	insertTestCommit(t, database, "c1", "alpha", "alpha-1", base.Add(10*time.Minute))
+	if _, err := database.Exec(`
+		INSERT INTO commit_files (id, commit_id, file_path, lines_added, lines_removed, created_at)
+		VALUES ('f1', 'c1', 'internal/parser/lexer.go', 10, 2, ?)
+	`, base); err != nil {
+		t.Fatalf("failed to create commit file: %v", err)
+	}
+	if _, err := database.Exec(`
+		INSERT INTO conversations (id, session_id, composer_id, name, status, message_count, created_at, updated_at)
+		VALUES ('conv-1', 'alpha-1', 'conv-1', 'Parser', 'completed', 1, ?, ?)
+	`, base, base); err != nil {
+		t.Fatalf("failed to create conversation: %v", err)
+	}
+	// The file is only named past the preview
+	long := strings.Repeat("trace line\n", blobs.PreviewLength/11+1) + "The fix belongs in lexer.go."
+	insertTestBlobMessage(t, database, store, "long", "conv-1", "agent", long, base)
+
+	reporter, err := NewReporterWithBlobs(database, store, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewReporterWithBlobs() error = %v", err)
+	}
+	hotspots, err := reporter.Hotspots(HotspotOptions{})
+	if err != nil {
+		t.Fatalf("Hotspots() error = %v", err)
+	}
+	if len(hotspots) != 1 || hotspots[0].Mentions != 1 {
+		t.Errorf("Hotspots() = %+v, want the mention past the preview counted", hotspots)
+	}
+}
@@ -0,0 +1,235 @@
+package report
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/blobs"
+	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/logging"
+	"github.com/stwalsh4118/clio/pkg/export"
+)
+
+// OrphanOptions filters the uncorrelated-work report
+type OrphanOptions struct {
+	Project string    // Only include this project (case-insensitive); empty includes all
+	Since   time.Time // Only include work at or after this time; zero means no lower bound
+	Until   time.Time // Only include work before this time; zero means no upper bound
+}
+
+// OrphanCommit is a commit that isn't correlated with any session
+type OrphanCommit struct {
+	Hash           string
+	Project        string // Repository name
+	RepositoryPath string
+	Message        string
+	Branch         string
+	Timestamp      time.Time
+}
+
+// OrphanSession is a session with no correlated commits
+type OrphanSession struct {
+	ID                string
+	Project           string
+	StartTime         time.Time
+	EndTime           *time.Time // Nil while the session is active
+	ConversationCount int
+}
+
+// ProjectOrphans groups orphaned work for a single project
+type ProjectOrphans struct {
+	Project  string
+	Commits  []OrphanCommit
+	Sessions []OrphanSession
+}
+
+// OrphanReport lists commits without sessions and sessions without commits
+type OrphanReport struct {
+	Commits  []OrphanCommit
+	Sessions []OrphanSession
+}
+
+// ByProject groups the report by project, sorted by project name
+func (r *OrphanReport) ByProject() []ProjectOrphans {
+	groups := make(map[string]*ProjectOrphans)
+	group := func(project string) *ProjectOrphans {
+		key := strings.ToLower(project)
+		if groups[key] == nil {
+			groups[key] = &ProjectOrphans{Project: project}
+		}
+		return groups[key]
+	}
+
+	for _, commit := range r.Commits {
+		g := group(commit.Project)
+		g.Commits = append(g.Commits, commit)
+	}
+	for _, session := range r.Sessions {
+		g := group(session.Project)
+		g.Sessions = append(g.Sessions, session)
+	}
+
+	result := make([]ProjectOrphans, 0, len(groups))
+	for _, g := range groups {
+		result = append(result, *g)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return strings.ToLower(result[i].Project) < strings.ToLower(result[j].Project)
+	})
+	return result
+}
+
+// Reporter defines the interface for generating reports over captured data
+type Reporter interface {
+	Orphans(opts OrphanOptions) (*OrphanReport, error)
+	ExportData(opts ExportOptions) (*export.Data, error)
+	ExportConversation(composerRef string) (*export.Conversation, error)
+	Search(opts SearchOptions) ([]SearchHit, error)
+	FileActivity(opts FileActivityOptions) ([]FileActivity, error)
+	ResolveSession(ref string) (string, error)
+	ResolveConversation(ref string) (string, error)
+	ResolveCommit(ref string) (string, error)
+	Why(opts WhyOptions) ([]WhyCommit, error)
+	Attribution(opts AttributionOptions) (*AttributionReport, error)
+	Team(opts TeamOptions) ([]MemberStats, error)
+	CompareBranches(opts BranchCompareOptions) ([]BranchSummary, error)
+	CommitStyle(opts CommitStyleOptions) (*CommitStyleReport, error)
+	Hotspots(opts HotspotOptions) ([]Hotspot, error)
+	Rework(opts ReworkOptions) (*ReworkReport, error)
+	FollowUps(opts FollowUpOptions) ([]FollowUpCommit, error)
+	BisectContext(opts BisectOptions) ([]BisectCommit, error)
+}
+
+// reporter implements Reporter over the clio database
+type reporter struct {
+	db     *sql.DB
+	blobs  blobs.Store // Nil reads the previews kept in rows instead of full values
+	logger logging.Logger
+}
+
+// NewReporter creates a new reporter instance. Values moved to the blob store are
+// reported by their previews; use NewReporterWithBlobs where full values matter.
+func NewReporter(db *sql.DB, logger logging.Logger) (Reporter, error) {
+	return NewReporterWithBlobs(db, nil, logger)
+}
+
+// NewReporterWithBlobs creates a reporter that loads values moved to the blob
+// store, such as long messages in exports and large diffs in attribution
+func NewReporterWithBlobs(db *sql.DB, store blobs.Store, logger logging.Logger) (Reporter, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database cannot be nil")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	return &reporter{
+		db:     db,
+		blobs:  store,
+		logger: logger.With("component", "reporter"),
+	}, nil
+}
+
+// messageText returns a message's full content, loading it from the blob store when
+// the row keeps only a preview. A blob that can't be loaded falls back to the preview.
+func (r *reporter) messageText(content string, ref sql.NullString) string {
+	text, err := blobs.Resolve(r.blobs, content, ref)
+	if err != nil {
+		r.logger.Warn("failed to load message blob, using the stored preview", "blob", ref.String, "error", err)
+	}
+	return text
+}
+
+// Orphans returns commits with no correlated session and sessions with no commits
+func (r *reporter) Orphans(opts OrphanOptions) (*OrphanReport, error) {
+	commits, err := r.orphanCommits(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := r.orphanSessions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	r.logger.Debug("generated orphan report", "commits", len(commits), "sessions", len(sessions))
+	return &OrphanReport{Commits: commits, Sessions: sessions}, nil
+}
+
+// orphanCommits returns commits whose session_id is unset, oldest first
+func (r *reporter) orphanCommits(opts OrphanOptions) ([]OrphanCommit, error) {
+	rows, err := r.db.Query(`
+		SELECT hash, repository_name, repository_path, message, branch, timestamp
+		FROM commits
+		WHERE session_id IS NULL
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query uncorrelated commits: %w", err)
+	}
+	defer rows.Close()
+
+	var commits []OrphanCommit
+	for rows.Next() {
+		var commit OrphanCommit
+		if err := rows.Scan(&commit.Hash, &commit.Project, &commit.RepositoryPath, &commit.Message, &commit.Branch, &commit.Timestamp); err != nil {
+			return nil, fmt.Errorf("failed to scan commit: %w", err)
+		}
+		if !opts.matches(commit.Project, commit.Timestamp) {
+			continue
+		}
+		commits = append(commits, commit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating commits: %w", err)
+	}
+
+	db.SortOldestFirst(commits, func(c OrphanCommit) time.Time { return c.Timestamp })
+	return commits, nil
+}
+
+// orphanSessions returns sessions that no stored commit references, oldest first
+func (r *reporter) orphanSessions(opts OrphanOptions) ([]OrphanSession, error) {
+	rows, err := r.db.Query(`
+		SELECT s.id, s.project, s.start_time, s.end_time,
+			(SELECT COUNT(*) FROM conversations c WHERE c.session_id = s.id)
+		FROM sessions s
+		WHERE NOT EXISTS (SELECT 1 FROM commits WHERE commits.session_id = s.id)
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sessions without commits: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []OrphanSession
+	for rows.Next() {
+		var session OrphanSession
+		var endTime sql.NullTime
+		if err := rows.Scan(&session.ID, &session.Project, &session.StartTime, &endTime, &session.ConversationCount); err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		if endTime.Valid {
+			session.EndTime = &endTime.Time
+		}
+		if !opts.matches(session.Project, session.StartTime) {
+			continue
+		}
+		sessions = append(sessions, session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sessions: %w", err)
+	}
+
+	db.SortOldestFirst(sessions, func(s OrphanSession) time.Time { return s.StartTime })
+	return sessions, nil
+}
+
+// matches reports whether work in project at time t falls within the filter
+func (o OrphanOptions) matches(project string, t time.Time) bool {
+	if o.Project != "" && !strings.EqualFold(o.Project, project) {
+		return false
+	}
+	return db.InRange(t, o.Since, o.Until)
+}
@@ -0,0 +1,217 @@
+package report
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/blobs"
+	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/logging"
+	_ "modernc.org/sqlite"
+)
+
+func setupTestReportDB(t *testing.T) *sql.DB {
+	database, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "clio.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if err := db.RunMigrations(database); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+	return database
+}
+
+func insertTestSession(t *testing.T, database *sql.DB, id, project string, start time.Time) {
+	if _, err := database.Exec(`
+		INSERT INTO sessions (id, project, start_time, end_time, last_activity, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, id, project, start, start.Add(time.Hour), start, start, start); err != nil {
+		t.Fatalf("failed to create test session: %v", err)
+	}
+}
+
+func newTestBlobStore(t *testing.T) blobs.Store {
+	store, err := blobs.NewStore(filepath.Join(t.TempDir(), "blobs"), logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("failed to create blob store: %v", err)
+	}
+	return store
+}
+
+// insertTestBlobMessage stores a message the way compaction leaves one over the
+// preview length, with a preview in the row and the content in the blob store
+func insertTestBlobMessage(t *testing.T, database *sql.DB, store blobs.Store, id, conversationID, role, content string, at time.Time) {
+	ref, err := store.Put([]byte(content))
+	if err != nil {
+		t.Fatalf("failed to store blob: %v", err)
+	}
+	if _, err := database.Exec(`
+		INSERT INTO messages (id, conversation_id, bubble_id, type, role, content, content_blob, created_at)
+		VALUES (?, ?, ?, 1, ?, ?, ?, ?)
+	`, id, conversationID, id, role, blobs.Preview(content), ref, at); err != nil {
+		t.Fatalf("failed to create message: %v", err)
+	}
+}
+
+func insertTestCommit(t *testing.T, database *sql.DB, hash, repoName string, sessionID interface{}, timestamp time.Time) {
+	if _, err := database.Exec(`
+		INSERT INTO commits (id, session_id, repository_path, repository_name, hash, message,
+			author_name, author_email, timestamp, branch, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, hash, sessionID, "/home/user/"+repoName, repoName, hash, "Commit "+hash,
+		"Test User", "test@example.com", timestamp, "main", timestamp, timestamp); err != nil {
+		t.Fatalf("failed to create test commit: %v", err)
+	}
+}
+
+func TestNewReporter(t *testing.T) {
+	database := setupTestReportDB(t)
+
+	if _, err := NewReporter(database, logging.NewNoopLogger()); err != nil {
+		t.Fatalf("NewReporter() error = %v, want nil", err)
+	}
+	if _, err := NewReporter(nil, logging.NewNoopLogger()); err == nil {
+		t.Error("NewReporter(nil, ...) expected error, got nil")
+	}
+	if _, err := NewReporter(database, nil); err == nil {
+		t.Error("NewReporter(..., nil) expected error, got nil")
+	}
+}
+
+func TestReporter_Orphans(t *testing.T) {
+	database := setupTestReportDB(t)
+	base := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	insertTestSession(t, database, "with-commit", "alpha", base)
+	insertTestSession(t, database, "no-commit", "alpha", base.Add(24*time.Hour))
+	insertTestSession(t, database, "old-no-commit", "beta", base.Add(-10*24*time.Hour))
+
+	insertTestCommit(t, database, "correlated", "alpha", "with-commit", base.Add(10*time.Minute))
+	insertTestCommit(t, database, "orphan-alpha", "alpha", nil, base.Add(2*24*time.Hour))
+	insertTestCommit(t, database, "orphan-beta", "beta", nil, base.Add(3*24*time.Hour))
+
+	reporter, err := NewReporter(database, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewReporter() error = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		opts         OrphanOptions
+		wantCommits  []string
+		wantSessions []string
+	}{
+		{
+			name:         "all",
+			opts:         OrphanOptions{},
+			wantCommits:  []string{"orphan-alpha", "orphan-beta"},
+			wantSessions: []string{"old-no-commit", "no-commit"},
+		},
+		{
+			name:         "project filter is case-insensitive",
+			opts:         OrphanOptions{Project: "ALPHA"},
+			wantCommits:  []string{"orphan-alpha"},
+			wantSessions: []string{"no-commit"},
+		},
+		{
+			name:         "time range",
+			opts:         OrphanOptions{Since: base, Until: base.Add(3 * 24 * time.Hour)},
+			wantCommits:  []string{"orphan-alpha"},
+			wantSessions: []string{"no-commit"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orphans, err := reporter.Orphans(tt.opts)
+			if err != nil {
+				t.Fatalf("Orphans() error = %v", err)
+			}
+
+			var commits, sessions []string
+			for _, commit := range orphans.Commits {
+				commits = append(commits, commit.Hash)
+			}
+			for _, session := range orphans.Sessions {
+				sessions = append(sessions, session.ID)
+			}
+
+			if !equalStrings(commits, tt.wantCommits) {
+				t.Errorf("commits = %v, want %v", commits, tt.wantCommits)
+			}
+			if !equalStrings(sessions, tt.wantSessions) {
+				t.Errorf("sessions = %v, want %v", sessions, tt.wantSessions)
+			}
+		})
+	}
+}
+
+func TestReporter_OrphansMixedOffsets(t *testing.T) {
+	database := setupTestReportDB(t)
+	base := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	berlin := time.FixedZone("CET", 3600)
+
+	// 12:30 UTC is stored as 13:30 +0100, after 13:00 +0000 as text
+	insertTestCommit(t, database, "first", "alpha", nil, base)
+	insertTestCommit(t, database, "second", "alpha", nil, base.Add(30*time.Minute).In(berlin))
+	insertTestCommit(t, database, "third", "alpha", nil, base.Add(time.Hour))
+	insertTestSession(t, database, "s-first", "alpha", base)
+	insertTestSession(t, database, "s-second", "alpha", base.Add(30*time.Minute).In(berlin))
+	insertTestSession(t, database, "s-third", "alpha", base.Add(time.Hour))
+
+	reporter, err := NewReporter(database, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewReporter() error = %v", err)
+	}
+	result, err := reporter.Orphans(OrphanOptions{})
+	if err != nil {
+		t.Fatalf("Orphans() error = %v", err)
+	}
+
+	var commits, sessions []string
+	for _, commit := range result.Commits {
+		commits = append(commits, commit.Hash)
+	}
+	for _, session := range result.Sessions {
+		sessions = append(sessions, session.ID)
+	}
+	if !equalStrings(commits, []string{"first", "second", "third"}) {
+		t.Errorf("commits = %v, want oldest first", commits)
+	}
+	if !equalStrings(sessions, []string{"s-first", "s-second", "s-third"}) {
+		t.Errorf("sessions = %v, want oldest first", sessions)
+	}
+}
+
+func TestOrphanReport_ByProject(t *testing.T) {
+	orphans := &OrphanReport{
+		Commits:  []OrphanCommit{{Hash: "a", Project: "beta"}, {Hash: "b", Project: "Alpha"}},
+		Sessions: []OrphanSession{{ID: "s1", Project: "alpha"}},
+	}
+
+	groups := orphans.ByProject()
+	if len(groups) != 2 {
+		t.Fatalf("ByProject() returned %d groups, want 2", len(groups))
+	}
+	if groups[0].Project != "Alpha" || len(groups[0].Commits) != 1 || len(groups[0].Sessions) != 1 {
+		t.Errorf("first group = %+v, want Alpha with 1 commit and 1 session", groups[0])
+	}
+	if groups[1].Project != "beta" || len(groups[1].Commits) != 1 {
+		t.Errorf("second group = %+v, want beta with 1 commit", groups[1])
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,276 @@
+package report
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultReworkDays is how soon a changed line must be changed again to count as rework
+	DefaultReworkDays = 14
+	// DefaultReworkSessions is how many sessions the rework report lists by default
+	DefaultReworkSessions = 20
+)
+
+// ReworkOptions filters the rework report
+type ReworkOptions struct {
+	Project string        // Only include this project (case-insensitive); empty includes all
+	Since   time.Time     // Only include lines added at or after this time; zero means no lower bound
+	Until   time.Time     // Only include lines added before this time; zero means no upper bound
+	Window  time.Duration // How soon a line must be changed again to count as rework; zero uses DefaultReworkDays
+	// Sessions is how many sessions are returned, most rework first; zero uses
+	// DefaultReworkSessions, negative returns all
+	Sessions int
+}
+
+// ReworkCounts counts added lines and how many of them were changed again within the window.
+// Lines with fewer than three letters or digits, such as closing braces, aren't counted.
+type ReworkCounts struct {
+	Commits    int
+	Added      int // Lines added
+	Removed    int // Lines removed
+	Reworked   int // Added lines that a later commit changed or removed within the window
+	AIAdded    int // Added lines that originated from AI suggestions, as Attribution estimates them
+	AIReworked int // Reworked lines among AIAdded
+}
+
+// ReworkRate returns the fraction of added lines that were reworked
+func (c ReworkCounts) ReworkRate() float64 {
+	return share(c.Reworked, c.Added-c.Reworked)
+}
+
+// AIReworkRate returns the fraction of AI-originated lines that were reworked
+func (c ReworkCounts) AIReworkRate() float64 {
+	return share(c.AIReworked, c.AIAdded-c.AIReworked)
+}
+
+// ManualReworkRate returns the fraction of manually written lines that were reworked
+func (c ReworkCounts) ManualReworkRate() float64 {
+	return share(c.Reworked-c.AIReworked, (c.Added-c.AIAdded)-(c.Reworked-c.AIReworked))
+}
+
+// ProjectRework counts one project's rework
+type ProjectRework struct {
+	Project string
+	ReworkCounts
+}
+
+// SessionRework counts the rework of the lines a session's commits added
+type SessionRework struct {
+	SessionID   string
+	Project     string
+	FirstCommit time.Time
+	ReworkCounts
+}
+
+// ReworkReport summarizes churn and rework
+type ReworkReport struct {
+	Window time.Duration
+	ReworkCounts
+	Projects  []ProjectRework // By project name
+	Sessions  []SessionRework // Most reworked lines first
+	Truncated int             // Commits whose stored diff was truncated, so only part of them was counted
+}
+
+// reworkLine is an added line that hasn't been changed since
+type reworkLine struct {
+	commit int // Index of the commit that added it
+	ai     bool
+}
+
+// reworkKey identifies a line of a file in a project
+type reworkKey struct {
+	project, file, line string
+}
+
+// Rework computes churn and rework from stored diffs. An added line counts as
+// reworked when a later commit removes the same line (ignoring whitespace) of
+// the same file within the window, whether to change or delete it; the rework
+// is counted against the commit, session, and project that added the line, so
+// sessions whose code needed heavy follow-up fixes stand out. Follow-up commits
+// after the range still count, as long as they're within the window.
+func (r *reporter) Rework(opts ReworkOptions) (*ReworkReport, error) {
+	window := opts.Window
+	if window <= 0 {
+		window = DefaultReworkDays * 24 * time.Hour
+	}
+	until := opts.Until
+	if !until.IsZero() {
+		until = until.Add(window)
+	}
+	commits, err := r.attributionCommits(AttributionOptions{Project: opts.Project, Since: opts.Since, Until: until})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(commits, func(i, j int) bool { return commits[i].Timestamp.Before(commits[j].Timestamp) })
+
+	inRange := func(t time.Time) bool { return opts.Until.IsZero() || t.Before(opts.Until) }
+	suggested := make(map[string]map[string]time.Time)
+	for _, commit := range commits {
+		if commit.SessionID == "" || suggested[commit.SessionID] != nil || !inRange(commit.Timestamp) {
+			continue
+		}
+		if suggested[commit.SessionID], err = r.suggestedLines(commit.SessionID); err != nil {
+			return nil, err
+		}
+	}
+
+	counts := make([]ReworkCounts, len(commits))
+	live := make(map[reworkKey][]reworkLine)
+	report := &ReworkReport{Window: window}
+	for i, commit := range commits {
+		origin := inRange(commit.Timestamp)
+		if origin {
+			counts[i].Commits = 1
+			if commit.Truncated {
+				report.Truncated++
+			}
+		}
+		for _, change := range diffLines(commit.diff) {
+			line := strings.Join(strings.Fields(change.line), " ")
+			if !isAttributable(line) {
+				continue
+			}
+			key := reworkKey{project: strings.ToLower(commit.Project), file: change.file, line: line}
+			if !change.added {
+				if origin {
+					counts[i].Removed++
+				}
+				// The most recently added copy of a line is the one changed
+				lines := live[key]
+				if len(lines) == 0 {
+					continue
+				}
+				added := lines[len(lines)-1]
+				live[key] = lines[:len(lines)-1]
+				if commit.Timestamp.Sub(commits[added.commit].Timestamp) <= window {
+					counts[added.commit].Reworked++
+					if added.ai {
+						counts[added.commit].AIReworked++
+					}
+				}
+				continue
+			}
+			if !origin {
+				continue
+			}
+			first, ok := suggested[commit.SessionID][line]
+			ai := ok && !first.After(commit.Timestamp)
+			counts[i].Added++
+			if ai {
+				counts[i].AIAdded++
+			}
+			live[key] = append(live[key], reworkLine{commit: i, ai: ai})
+		}
+	}
+
+	projects := make(map[string]*ProjectRework)
+	sessions := make(map[string]*SessionRework)
+	for i, commit := range commits {
+		if !inRange(commit.Timestamp) {
+			continue
+		}
+		report.add(counts[i])
+		key := strings.ToLower(commit.Project)
+		if projects[key] == nil {
+			projects[key] = &ProjectRework{Project: commit.Project}
+		}
+		projects[key].add(counts[i])
+		if commit.SessionID == "" {
+			continue
+		}
+		if sessions[commit.SessionID] == nil {
+			sessions[commit.SessionID] = &SessionRework{SessionID: commit.SessionID, Project: commit.Project, FirstCommit: commit.Timestamp}
+		}
+		sessions[commit.SessionID].add(counts[i])
+	}
+
+	for _, project := range projects {
+		report.Projects = append(report.Projects, *project)
+	}
+	sort.Slice(report.Projects, func(i, j int) bool {
+		return strings.ToLower(report.Projects[i].Project) < strings.ToLower(report.Projects[j].Project)
+	})
+	for _, session := range sessions {
+		report.Sessions = append(report.Sessions, *session)
+	}
+	sort.Slice(report.Sessions, func(i, j int) bool {
+		a, b := report.Sessions[i], report.Sessions[j]
+		if a.Reworked != b.Reworked {
+			return a.Reworked > b.Reworked
+		}
+		return a.FirstCommit.After(b.FirstCommit)
+	})
+	limit := opts.Sessions
+	if limit == 0 {
+		limit = DefaultReworkSessions
+	}
+	if limit > 0 && len(report.Sessions) > limit {
+		report.Sessions = report.Sessions[:limit]
+	}
+
+	r.logger.Debug("generated rework report", "commits", report.Commits, "added", report.Added, "reworked", report.Reworked)
+	return report, nil
+}
+
+// add adds other's counts to c
+func (c *ReworkCounts) add(other ReworkCounts) {
+	c.Commits += other.Commits
+	c.Added += other.Added
+	c.Removed += other.Removed
+	c.Reworked += other.Reworked
+	c.AIAdded += other.AIAdded
+	c.AIReworked += other.AIReworked
+}
+
+// diffLine is a line a diff adds or removes
+type diffLine struct {
+	file  string
+	line  string // Without the leading "+" or "-"
+	added bool
+}
+
+// diffLines returns the lines a unified diff of several files adds and removes,
+// in order. File headers are only read outside hunks, so a removed line
+// starting with "-- " isn't taken for one.
+func diffLines(diff string) []diffLine {
+	var lines []diffLine
+	var file string
+	inHunk := false
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			file, inHunk = "", false
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+		case !inHunk && strings.HasPrefix(line, "--- "):
+			if path := diffPath(line[4:]); path != "" {
+				file = path
+			}
+		case !inHunk && strings.HasPrefix(line, "+++ "):
+			// The new path wins, except for deleted files
+			if path := diffPath(line[4:]); path != "" {
+				file = path
+			}
+		case inHunk && strings.HasPrefix(line, "+"):
+			lines = append(lines, diffLine{file: file, line: line[1:], added: true})
+		case inHunk && strings.HasPrefix(line, "-"):
+			lines = append(lines, diffLine{file: file, line: line[1:]})
+		}
+	}
+	return lines
+}
+
+// diffPath returns the path of a diff file header without its a/ or b/
+// prefix, or "" for /dev/null
+func diffPath(header string) string {
+	header = strings.TrimSpace(header)
+	if header == "/dev/null" {
+		return ""
+	}
+	if strings.HasPrefix(header, "a/") || strings.HasPrefix(header, "b/") {
+		return header[2:]
+	}
+	return header
+}
@@ -0,0 +1,127 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+func TestReporter_Rework(t *testing.T) {
+	database := setupTestReportDB(t)
+	base := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	insertTestSession(t, database, "alpha-1", "alpha", base)
+	insertTestCommit(t, database, "assisted", "alpha", "alpha-1", base.Add(30*time.Minute))
+	insertTestCommit(t, database, "fix", "alpha", nil, base.Add(2*day))
+	insertTestCommit(t, database, "much-later", "alpha", nil, base.Add(30*day))
+
+	diffs := map[string]string{
+		"assisted": "diff --git a/lexer.go b/lexer.go\n--- a/lexer.go\n+++ b/lexer.go\n@@ -1,2 +1,6 @@\n" +
+			"+func escape(s string) string {\n" +
+			"+\treturn strings.ReplaceAll(s, quote, escaped)\n" +
+			"+}\n" +
+			"+// handwritten comment\n" +
+			"+stays()\n" +
+			"+rewrittenLater()\n" +
+			"diff --git a/other.go b/other.go\n--- a/other.go\n+++ b/other.go\n@@ -1 +1 @@\n" +
+			"+stays()\n",
+		// Rewrites the suggested return line and the comment; stays() in another file isn't lexer.go's
+		"fix": "diff --git a/lexer.go b/lexer.go\n--- a/lexer.go\n+++ b/lexer.go\n@@ -1,4 +1,4 @@\n" +
+			"-    return strings.ReplaceAll(s, quote, escaped)\n" +
+			"-// handwritten comment\n" +
+			"+\treturn escapeQuotes(s)\n" +
+			"+// fixed comment\n" +
+			"diff --git a/gone.go b/gone.go\ndeleted file mode 100644\n--- a/gone.go\n+++ /dev/null\n@@ -1 +0,0 @@\n" +
+			"--- not a header\n",
+		// Outside the 14 day window
+		"much-later": "diff --git a/lexer.go b/lexer.go\n--- a/lexer.go\n+++ b/lexer.go\n@@ -1 +1 @@\n" +
+			"-rewrittenLater()\n",
+	}
+	for hash, diff := range diffs {
+		if _, err := database.Exec("UPDATE commits SET full_diff = ? WHERE hash = ?", diff, hash); err != nil {
+			t.Fatalf("failed to set diff: %v", err)
+		}
+	}
+
+	if _, err := database.Exec(`
+		INSERT INTO conversations (id, session_id, composer_id, name, status, message_count, created_at, updated_at)
+		VALUES ('conv-1', 'alpha-1', 'composer-1', 'Escaping', 'completed', 1, ?, ?)
+	`, base, base); err != nil {
+		t.Fatalf("failed to create conversation: %v", err)
+	}
+	if _, err := database.Exec(`
+		INSERT INTO messages (id, conversation_id, bubble_id, type, role, content, created_at, code_blocks)
+		VALUES ('suggestion', 'conv-1', 'suggestion', 2, 'agent', 'code', ?, ?)
+	`, base.Add(10*time.Minute), `[{"content":"func escape(s string) string {\n\treturn strings.ReplaceAll(s, quote, escaped)\n}"}]`); err != nil {
+		t.Fatalf("failed to create message: %v", err)
+	}
+
+	reporter, err := NewReporter(database, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewReporter() error = %v", err)
+	}
+
+	result, err := reporter.Rework(ReworkOptions{Project: "ALPHA", Until: base.Add(day)})
+	if err != nil {
+		t.Fatalf("Rework() error = %v", err)
+	}
+	// Only the assisted commit is in range; the fix counts as its follow-up.
+	// "}" is too short to count.
+	if result.Commits != 1 || result.Added != 6 || result.Removed != 0 {
+		t.Errorf("counts = %+v, want 1 commit adding 6 lines", result.ReworkCounts)
+	}
+	if result.Reworked != 2 || result.AIAdded != 2 || result.AIReworked != 1 {
+		t.Errorf("rework = %+v, want 2 reworked, 1 of 2 AI lines", result.ReworkCounts)
+	}
+	if got := result.AIReworkRate(); got != 0.5 {
+		t.Errorf("AIReworkRate() = %v, want 0.5", got)
+	}
+	if got := result.ManualReworkRate(); got != 0.25 {
+		t.Errorf("ManualReworkRate() = %v, want 0.25", got)
+	}
+	if len(result.Projects) != 1 || result.Projects[0].Reworked != 2 {
+		t.Errorf("projects = %+v, want alpha with 2 reworked", result.Projects)
+	}
+	if len(result.Sessions) != 1 || result.Sessions[0].SessionID != "alpha-1" || result.Sessions[0].Reworked != 2 {
+		t.Errorf("sessions = %+v, want alpha-1 with 2 reworked", result.Sessions)
+	}
+
+	// A 60 day window also counts the change a month later
+	result, err = reporter.Rework(ReworkOptions{Until: base.Add(day), Window: 60 * day})
+	if err != nil {
+		t.Fatalf("Rework() error = %v", err)
+	}
+	if result.Reworked != 3 {
+		t.Errorf("reworked with a 60 day window = %d, want 3", result.Reworked)
+	}
+
+	// Without a range every commit is an origin; the fix's own lines aren't changed again
+	result, err = reporter.Rework(ReworkOptions{})
+	if err != nil {
+		t.Fatalf("Rework() error = %v", err)
+	}
+	if result.Commits != 3 || result.Added != 8 || result.Removed != 4 || result.Reworked != 2 {
+		t.Errorf("counts = %+v, want 3 commits, 8 added, 4 removed, 2 reworked", result.ReworkCounts)
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	diff := "diff --git a/old.go b/new.go\n--- a/old.go\n+++ b/new.go\n@@ -1,2 +1,2 @@\n context\n-removed\n+added\n" +
+		"diff --git a/gone.go b/gone.go\n--- a/gone.go\n+++ /dev/null\n@@ -1 +0,0 @@\n--- dashes\n"
+	lines := diffLines(diff)
+	want := []diffLine{
+		{file: "new.go", line: "removed"},
+		{file: "new.go", line: "added", added: true},
+		{file: "gone.go", line: "-- dashes"},
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("diffLines() = %+v, want %+v", lines, want)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("diffLines()[%d] = %+v, want %+v", i, lines[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package report
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/search"
+)
+
+const (
+	// defaultSearchLimit caps search results when no limit is given
+	defaultSearchLimit = 50
+	// searchSnippetRadius is the number of characters kept either side of a match
+	searchSnippetRadius = 80
+)
+
+// SearchOptions controls a message search
+type SearchOptions struct {
+	Query    string    // Search query (see search.ParseQuery), matched against message text without markdown syntax; case-insensitive
+	Project  string    // Only include this project (case-insensitive); empty includes all
+	Since    time.Time // Only include messages at or after this time; zero means no lower bound
+	Until    time.Time // Only include messages before this time; zero means no upper bound
+	Limit    int       // Maximum results; zero or less uses the default
+	CodeOnly bool      // Only match the code blocks of messages
+	Context  int       // Lines of message text to return either side of the first matching line; zero returns none
+}
+
+// SearchHit is a message matching a search
+type SearchHit struct {
+	MessageID        string
+	SessionID        string
+	Project          string
+	ConversationName string
+	ComposerID       string
+	Role             string
+	Snippet          string   // Normalized text or code around the first match
+	Context          []string // With SearchOptions.Context, the message's lines around the first matching line
+	CreatedAt        time.Time
+}
+
+// Search returns messages matching the query, newest first. Text terms match
+// message prose with markdown syntax stripped and code separately, and field
+// terms match message metadata. Only the search index is read; the daemon keeps
+// it up to date, so Search works on a read-only database.
+func (r *reporter) Search(opts SearchOptions) ([]SearchHit, error) {
+	query, err := search.ParseQuery(opts.Query, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+
+	index, err := search.NewIndex(r.db, r.blobs, r.logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create search index: %w", err)
+	}
+	where, args, err := index.Compile(query, opts.CodeOnly)
+	if err != nil {
+		return nil, err
+	}
+	if where == "" {
+		where = "1"
+	}
+
+	// Time bounds in the query narrow the ones in the options
+	since, until := opts.Since, opts.Until
+	if query.Since.After(since) {
+		since = query.Since
+	}
+	if !query.Until.IsZero() && (until.IsZero() || query.Until.Before(until)) {
+		until = query.Until
+	}
+	filter := ExportOptions{Project: opts.Project, Since: since, Until: until}
+	terms := query.Terms()
+
+	rows, err := r.db.Query(`
+		SELECT m.id, s.id, s.project, c.name, c.composer_id, m.role, COALESCE(m.content, ''), m.content_blob, d.prose, d.code, m.created_at
+		FROM search_documents d
+		JOIN messages m ON m.id = d.message_id
+		JOIN conversations c ON c.id = m.conversation_id
+		JOIN sessions s ON s.id = c.session_id
+		WHERE `+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search messages: %w", err)
+	}
+	defer rows.Close()
+
+	type match struct {
+		hit                  SearchHit
+		content, prose, code string
+		contentBlob          sql.NullString
+	}
+	var matches []match
+	for rows.Next() {
+		var m match
+		var project, name sql.NullString
+		if err := rows.Scan(&m.hit.MessageID, &m.hit.SessionID, &project, &name, &m.hit.ComposerID, &m.hit.Role,
+			&m.content, &m.contentBlob, &m.prose, &m.code, &m.hit.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan search result: %w", err)
+		}
+		m.hit.Project = project.String
+		if !filter.matches(m.hit.Project, m.hit.CreatedAt) {
+			continue
+		}
+		m.hit.ConversationName = name.String
+		matches = append(matches, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating search results: %w", err)
+	}
+
+	db.SortNewestFirst(matches, func(m match) time.Time { return m.hit.CreatedAt })
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	hits := make([]SearchHit, 0, len(matches))
+	for _, m := range matches {
+		hit := m.hit
+		// The snippet comes from the prose unless only the code contains a term
+		text := m.prose
+		if opts.CodeOnly || firstTerm(m.prose, terms) == "" && firstTerm(m.code, terms) != "" {
+			text = m.code
+		}
+		hit.Snippet = snippet(text, firstTerm(text, terms))
+		if opts.Context > 0 {
+			// Context comes from the message as written, keeping its lines
+			source := r.messageText(m.content, m.contentBlob)
+			if opts.CodeOnly {
+				source = m.code
+			}
+			hit.Context = contextLines(source, terms, opts.Context)
+		}
+		hits = append(hits, hit)
+	}
+
+	r.logger.Debug("searched messages", "query", opts.Query, "results", len(hits))
+	return hits, nil
+}
+
+// firstTerm returns the first of terms that text contains, ignoring case, or
+// an empty string when it contains none
+func firstTerm(text string, terms []string) string {
+	lower := strings.ToLower(text)
+	for _, term := range terms {
+		if strings.Contains(lower, strings.ToLower(term)) {
+			return term
+		}
+	}
+	return ""
+}
+
+// contextLines returns the lines of text within radius of the first line
+// containing a word of terms, or of the first line when none does
+func contextLines(text string, terms []string, radius int) []string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	match := 0
+	for i, line := range lines {
+		if search.ContainsTerm(line, terms) {
+			match = i
+			break
+		}
+	}
+	start := max(match-radius, 0)
+	end := min(match+radius+1, len(lines))
+	context := make([]string, 0, end-start)
+	for _, line := range lines[start:end] {
+		context = append(context, strings.TrimRight(line, " \t\r"))
+	}
+	return context
+}
+
+// snippet returns the text around the first case-insensitive match of query
+func snippet(text, query string) string {
+	idx := strings.Index(strings.ToLower(text), strings.ToLower(query))
+	if idx < 0 {
+		idx = 0
+	}
+	start := max(idx-searchSnippetRadius, 0)
+	end := min(idx+len(query)+searchSnippetRadius, len(text))
+
+	// Avoid splitting multi-byte characters at the edges
+	for start > 0 && !utf8.RuneStart(text[start]) {
+		start--
+	}
+	for end < len(text) && !utf8.RuneStart(text[end]) {
+		end++
+	}
+
+	result := strings.Join(strings.Fields(text[start:end]), " ")
+	if start > 0 {
+		result = "..." + result
+	}
+	if end < len(text) {
+		result += "..."
+	}
+	return result
+}
@@ -0,0 +1,76 @@
+package report
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// LatestSession is the session reference for the most recently started session
+	LatestSession = "latest"
+	// ActiveSession is the session reference for the most recently started session that hasn't ended
+	ActiveSession = "active"
+)
+
+// ResolveSession returns the ID of the session ref refers to: a full session ID, a
+// unique ID prefix, LatestSession, or ActiveSession
+func (r *reporter) ResolveSession(ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return "", fmt.Errorf("session reference cannot be empty")
+	}
+
+	rows, err := r.db.Query("SELECT id, start_time, end_time FROM sessions")
+	if err != nil {
+		return "", fmt.Errorf("failed to query sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var latestID, activeID string
+	var latestStart, activeStart time.Time
+	var matches []string
+	for rows.Next() {
+		var id string
+		var start time.Time
+		var end sql.NullTime
+		if err := rows.Scan(&id, &start, &end); err != nil {
+			return "", fmt.Errorf("failed to scan session: %w", err)
+		}
+		if id == ref {
+			return id, nil
+		}
+		if strings.HasPrefix(id, ref) {
+			matches = append(matches, id)
+		}
+		if latestID == "" || start.After(latestStart) {
+			latestID, latestStart = id, start
+		}
+		if !end.Valid && (activeID == "" || start.After(activeStart)) {
+			activeID, activeStart = id, start
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("error iterating sessions: %w", err)
+	}
+
+	switch {
+	case strings.EqualFold(ref, LatestSession):
+		if latestID == "" {
+			return "", fmt.Errorf("no sessions have been captured")
+		}
+		return latestID, nil
+	case strings.EqualFold(ref, ActiveSession):
+		if activeID == "" {
+			return "", fmt.Errorf("no session is active")
+		}
+		return activeID, nil
+	case len(matches) == 1:
+		return matches[0], nil
+	case len(matches) > 1:
+		return "", fmt.Errorf("session prefix %q is ambiguous (%d sessions match)", ref, len(matches))
+	default:
+		return "", fmt.Errorf("session %q not found", ref)
+	}
+}
@@ -0,0 +1,56 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+func TestReporter_ResolveSession(t *testing.T) {
+	database := setupTestReportDB(t)
+	base := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	insertTestSession(t, database, "3f2a-newest", "alpha", base.Add(2*time.Hour))
+	insertTestSession(t, database, "3f9c-middle", "alpha", base.Add(time.Hour))
+	insertTestSession(t, database, "a1b2-oldest", "beta", base)
+
+	if _, err := database.Exec(`
+		INSERT INTO sessions (id, project, start_time, last_activity, created_at, updated_at)
+		VALUES ('c4d5-active', 'beta', ?, ?, ?, ?)
+	`, base.Add(30*time.Minute), base, base, base); err != nil {
+		t.Fatalf("failed to create active session: %v", err)
+	}
+
+	reporter, err := NewReporter(database, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewReporter() error = %v", err)
+	}
+
+	tests := []struct {
+		ref     string
+		want    string
+		wantErr bool
+	}{
+		{ref: "a1b2-oldest", want: "a1b2-oldest"},
+		{ref: "a1", want: "a1b2-oldest"},
+		{ref: "3f9", want: "3f9c-middle"},
+		{ref: "latest", want: "3f2a-newest"},
+		{ref: "active", want: "c4d5-active"},
+		{ref: "3f", wantErr: true},
+		{ref: "zz", wantErr: true},
+		{ref: " ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			got, err := reporter.ResolveSession(tt.ref)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveSession(%q) error = %v, wantErr %v", tt.ref, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ResolveSession(%q) = %q, want %q", tt.ref, got, tt.want)
+			}
+		})
+	}
+}
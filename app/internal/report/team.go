@@ -0,0 +1,102 @@
+package report
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// TeamOptions filters the per-member team stats and maps author identities to members
+type TeamOptions struct {
+	Project string              // Only include this project (case-insensitive); empty includes all
+	Since   time.Time           // Only include commits at or after this time; zero means no lower bound
+	Until   time.Time           // Only include commits before this time; zero means no upper bound
+	Members map[string][]string // Member name to the author emails and names they commit as
+}
+
+// MemberStats aggregates one team member's captured commits
+type MemberStats struct {
+	Member         string
+	Identities     []string // Author emails the member's commits were made as, sorted
+	Commits        int
+	SessionCommits int // Commits correlated with a captured session
+	AILines        int
+	HumanLines     int
+	Projects       int
+	LastCommit     time.Time
+}
+
+// AIShare returns the fraction of the member's attributable lines that came from AI suggestions
+func (m MemberStats) AIShare() float64 {
+	return share(m.AILines, m.HumanLines)
+}
+
+// Team aggregates captured commits per team member, most commits first. Each
+// commit's author is mapped to a member by email or name, case-insensitively;
+// authors no member lists are reported on their own under their name. Only
+// aggregates are returned, never messages or transcripts.
+func (r *reporter) Team(opts TeamOptions) ([]MemberStats, error) {
+	attribution, err := r.Attribution(AttributionOptions{Project: opts.Project, Since: opts.Since, Until: opts.Until})
+	if err != nil {
+		return nil, err
+	}
+
+	identities := make(map[string]string)
+	for member, ids := range opts.Members {
+		for _, id := range ids {
+			identities[strings.ToLower(strings.TrimSpace(id))] = member
+		}
+	}
+
+	members := make(map[string]*MemberStats)
+	emails := make(map[string]map[string]bool)
+	projects := make(map[string]map[string]bool)
+	// Commits are newest first, so unmapped authors are named as they last committed
+	for _, commit := range attribution.Commits {
+		email := strings.ToLower(commit.AuthorEmail)
+		key, ok := identities[email]
+		if !ok {
+			key, ok = identities[strings.ToLower(commit.AuthorName)]
+		}
+		name := key
+		if !ok {
+			key = "email:" + email
+			name = commit.AuthorName
+		}
+
+		stats := members[key]
+		if stats == nil {
+			stats = &MemberStats{Member: name, LastCommit: commit.Timestamp}
+			members[key] = stats
+			emails[key] = make(map[string]bool)
+			projects[key] = make(map[string]bool)
+		}
+		stats.Commits++
+		if commit.SessionID != "" {
+			stats.SessionCommits++
+		}
+		stats.AILines += commit.AILines
+		stats.HumanLines += commit.HumanLines
+		emails[key][email] = true
+		projects[key][strings.ToLower(commit.Project)] = true
+	}
+
+	result := make([]MemberStats, 0, len(members))
+	for key, stats := range members {
+		for email := range emails[key] {
+			stats.Identities = append(stats.Identities, email)
+		}
+		sort.Strings(stats.Identities)
+		stats.Projects = len(projects[key])
+		result = append(result, *stats)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Commits != result[j].Commits {
+			return result[i].Commits > result[j].Commits
+		}
+		return strings.ToLower(result[i].Member) < strings.ToLower(result[j].Member)
+	})
+
+	r.logger.Debug("generated team stats", "members", len(result), "commits", len(attribution.Commits))
+	return result, nil
+}
@@ -0,0 +1,62 @@
+package report
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+func TestReporter_Team(t *testing.T) {
+	database := setupTestReportDB(t)
+	base := time.Date(2024, 1, 10, 12, 0, 0, 0, time.Local)
+
+	insertTestSession(t, database, "alpha-1", "alpha", base)
+	insertTestCommit(t, database, "a1", "alpha", "alpha-1", base)
+	insertTestCommit(t, database, "a2", "beta", nil, base.Add(time.Hour))
+	insertTestCommit(t, database, "b1", "alpha", nil, base.Add(2*time.Hour))
+	insertTestCommit(t, database, "c1", "alpha", nil, base.Add(3*time.Hour))
+	for hash, author := range map[string][2]string{
+		"a1": {"Alice", "alice@corp.com"},
+		"a2": {"Alice Smith", "ALICE@users.noreply.github.com"},
+		"b1": {"Bob", "bob@corp.com"},
+		"c1": {"Carol", "carol@corp.com"},
+	} {
+		if _, err := database.Exec("UPDATE commits SET author_name = ?, author_email = ? WHERE hash = ?", author[0], author[1], hash); err != nil {
+			t.Fatalf("failed to set author: %v", err)
+		}
+	}
+
+	reporter, err := NewReporter(database, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewReporter() error = %v", err)
+	}
+
+	members, err := reporter.Team(TeamOptions{Members: map[string][]string{
+		"alice": {"alice@corp.com", "alice smith"},
+		"bob":   {"Bob@Corp.com"},
+	}})
+	if err != nil {
+		t.Fatalf("Team() error = %v", err)
+	}
+	if len(members) != 3 {
+		t.Fatalf("Team() = %+v, want alice, bob, and Carol", members)
+	}
+
+	alice := members[0]
+	if alice.Member != "alice" || alice.Commits != 2 || alice.SessionCommits != 1 || alice.Projects != 2 || !alice.LastCommit.Equal(base.Add(time.Hour)) {
+		t.Errorf("alice = %+v", alice)
+	}
+	if want := []string{"alice@corp.com", "alice@users.noreply.github.com"}; !reflect.DeepEqual(alice.Identities, want) {
+		t.Errorf("alice identities = %v, want %v", alice.Identities, want)
+	}
+	if members[1].Member != "bob" || members[2].Member != "Carol" {
+		t.Errorf("members = %s, %s; want bob then the unmapped Carol", members[1].Member, members[2].Member)
+	}
+
+	members, err = reporter.Team(TeamOptions{Project: "beta"})
+	if err != nil || len(members) != 1 || members[0].Member != "Alice Smith" {
+		t.Errorf("Team() for beta = %+v, %v; want only the unmapped Alice Smith", members, err)
+	}
+}
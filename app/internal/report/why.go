@@ -0,0 +1,249 @@
+package report
+
+import (
+	"database/sql"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultWhyCommits is how many of a file's latest commits the why report explains
+	DefaultWhyCommits = 3
+	// DefaultWhyExcerpts is how many conversation excerpts are shown per commit
+	DefaultWhyExcerpts = 3
+)
+
+// WhyOptions selects the code to explain
+type WhyOptions struct {
+	RepositoryPath string // Repository root
+	File           string // Path relative to the repository root
+	CommitHash     string // Only explain this commit, e.g. from blaming a line; empty uses the file's latest commits
+	Limit          int    // Commits to explain; zero uses DefaultWhyCommits
+	Excerpts       int    // Excerpts per commit; zero uses DefaultWhyExcerpts
+}
+
+// Excerpt is part of a conversation message that led to a commit
+type Excerpt struct {
+	Conversation string // Conversation name, falling back to its composer ID
+	Role         string
+	Text         string // Snippet centred on the file name when the message mentions it
+	CreatedAt    time.Time
+	MentionsFile bool
+}
+
+// WhyCommit is a commit that touched the file, with the conversation that led to it
+type WhyCommit struct {
+	Hash         string
+	Message      string
+	Branch       string
+	Timestamp    time.Time
+	SessionID    string // Empty when the commit isn't correlated with a session
+	Project      string
+	LinesAdded   int
+	LinesRemoved int
+	Excerpts     []Excerpt // Oldest first
+}
+
+// whyMessage is a candidate excerpt
+type whyMessage struct {
+	conversation string
+	role         string
+	content      string
+	createdAt    time.Time
+}
+
+// Why explains a file's latest captured commits, or a single commit, with excerpts
+// from the conversations in the correlated sessions. Excerpts are the latest messages
+// before the commit that mention the file, topped up with the latest messages before
+// it when fewer mention the file. Commits are returned newest first.
+func (r *reporter) Why(opts WhyOptions) ([]WhyCommit, error) {
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = DefaultWhyCommits
+	}
+	excerpts := opts.Excerpts
+	if excerpts <= 0 {
+		excerpts = DefaultWhyExcerpts
+	}
+	file := filepath.ToSlash(filepath.Clean(opts.File))
+
+	commits, err := r.whyCommits(filepath.Clean(opts.RepositoryPath), file, opts.CommitHash)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(commits, func(i, j int) bool { return commits[i].Timestamp.After(commits[j].Timestamp) })
+	if len(commits) > limit {
+		commits = commits[:limit]
+	}
+
+	for i := range commits {
+		if commits[i].SessionID == "" {
+			continue
+		}
+		messages, err := r.whyMessages(commits[i].SessionID)
+		if err != nil {
+			return nil, err
+		}
+		commits[i].Excerpts = selectExcerpts(messages, path.Base(file), commits[i].Timestamp, excerpts)
+	}
+
+	r.logger.Debug("generated why report", "file", file, "commits", len(commits))
+	return commits, nil
+}
+
+// whyCommits returns the captured commits that touched file, or the commit with hash
+func (r *reporter) whyCommits(repositoryPath, file, hash string) ([]WhyCommit, error) {
+	query := `
+		SELECT c.hash, c.message, c.branch, c.timestamp, c.session_id, s.project,
+			COALESCE(f.lines_added, 0), COALESCE(f.lines_removed, 0)
+		FROM commits c
+		LEFT JOIN sessions s ON s.id = c.session_id
+		LEFT JOIN commit_files f ON f.commit_id = c.id AND f.file_path = ?
+	`
+	args := []interface{}{file}
+	if hash != "" {
+		query += "WHERE c.hash = ?"
+		args = append(args, hash)
+	} else {
+		query += "WHERE c.repository_path = ? AND f.id IS NOT NULL"
+		args = append(args, repositoryPath)
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query commits: %w", err)
+	}
+	defer rows.Close()
+
+	var commits []WhyCommit
+	for rows.Next() {
+		var commit WhyCommit
+		var sessionID, project sql.NullString
+		if err := rows.Scan(&commit.Hash, &commit.Message, &commit.Branch, &commit.Timestamp, &sessionID, &project,
+			&commit.LinesAdded, &commit.LinesRemoved); err != nil {
+			return nil, fmt.Errorf("failed to scan commit: %w", err)
+		}
+		commit.SessionID = sessionID.String
+		commit.Project = project.String
+		commits = append(commits, commit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating commits: %w", err)
+	}
+
+	return commits, nil
+}
+
+// whyMessages returns every message in a session's conversations
+func (r *reporter) whyMessages(sessionID string) ([]whyMessage, error) {
+	rows, err := r.db.Query(`
+		SELECT COALESCE(NULLIF(c.name, ''), c.composer_id), m.role, m.content, m.content_blob, m.created_at
+		FROM messages m
+		JOIN conversations c ON c.id = m.conversation_id
+		WHERE c.session_id = ?
+	`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []whyMessage
+	for rows.Next() {
+		var m whyMessage
+		var contentBlob sql.NullString
+		if err := rows.Scan(&m.conversation, &m.role, &m.content, &contentBlob, &m.createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan message: %w", err)
+		}
+		m.content = r.messageText(m.content, contentBlob)
+		messages = append(messages, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating messages: %w", err)
+	}
+
+	return messages, nil
+}
+
+// selectExcerpts picks up to n messages before the commit, preferring those that mention
+// the file name, and returns them oldest first
+func selectExcerpts(messages []whyMessage, fileName string, committedAt time.Time, n int) []Excerpt {
+	var before []whyMessage
+	for _, m := range messages {
+		if !m.createdAt.After(committedAt) && strings.TrimSpace(m.content) != "" {
+			before = append(before, m)
+		}
+	}
+	// Newest first, so the messages closest to the commit are picked
+	sort.SliceStable(before, func(i, j int) bool { return before[i].createdAt.After(before[j].createdAt) })
+
+	lowerName := strings.ToLower(fileName)
+	picked := make([]bool, len(before))
+	var selected []Excerpt
+	pick := func(mentions bool) {
+		for i, m := range before {
+			if len(selected) == n {
+				return
+			}
+			if picked[i] || (mentions && !strings.Contains(strings.ToLower(m.content), lowerName)) {
+				continue
+			}
+			picked[i] = true
+			selected = append(selected, Excerpt{
+				Conversation: m.conversation,
+				Role:         m.role,
+				Text:         snippet(m.content, fileName),
+				CreatedAt:    m.createdAt,
+				MentionsFile: mentions,
+			})
+		}
+	}
+	pick(true)
+	pick(false)
+
+	sort.SliceStable(selected, func(i, j int) bool { return selected[i].CreatedAt.Before(selected[j].CreatedAt) })
+	return selected
+}
+
+// ResolveCommit resolves a commit hash, or a unique prefix of one, to its full hash
+func (r *reporter) ResolveCommit(ref string) (string, error) {
+	ref = strings.ToLower(strings.TrimSpace(ref))
+	if ref == "" {
+		return "", fmt.Errorf("commit hash cannot be empty")
+	}
+
+	rows, err := r.db.Query("SELECT DISTINCT hash FROM commits WHERE hash LIKE ? || '%'", ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to query commits: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []string
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return "", fmt.Errorf("failed to scan commit: %w", err)
+		}
+		if hash == ref {
+			return hash, nil
+		}
+		if strings.HasPrefix(hash, ref) {
+			matches = append(matches, hash)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("error iterating commits: %w", err)
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("commit %q not found", ref)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("commit prefix %q is ambiguous (%d commits match)", ref, len(matches))
+	}
+}
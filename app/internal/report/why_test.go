@@ -0,0 +1,154 @@
+package report
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/blobs"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+func TestReporter_Why(t *testing.T) {
+	database := setupTestReportDB(t)
+	base := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	insertTestSession(t, database, "alpha-1", "alpha", base)
+	insertTestCommit(t, database, "first", "alpha", "alpha-1", base.Add(20*time.Minute))
+	insertTestCommit(t, database, "second", "alpha", nil, base.Add(2*time.Hour))
+	insertTestCommit(t, database, "unrelated", "alpha", "alpha-1", base.Add(30*time.Minute))
+	for _, f := range []struct{ commit, path string }{
+		{"first", "internal/parser/lexer.go"},
+		{"second", "internal/parser/lexer.go"},
+		{"unrelated", "README.md"},
+	} {
+		if _, err := database.Exec(`
+			INSERT INTO commit_files (id, commit_id, file_path, lines_added, lines_removed, created_at)
+			VALUES (?, ?, ?, 5, 1, ?)
+		`, f.commit+f.path, f.commit, f.path, base); err != nil {
+			t.Fatalf("failed to create commit file: %v", err)
+		}
+	}
+
+	if _, err := database.Exec(`
+		INSERT INTO conversations (id, session_id, composer_id, name, status, message_count, created_at, updated_at)
+		VALUES ('conv-1', 'alpha-1', 'composer-1', 'Lexer quotes', 'completed', 4, ?, ?)
+	`, base, base); err != nil {
+		t.Fatalf("failed to create conversation: %v", err)
+	}
+	for i, text := range []string{
+		"Why does lexer.go drop escaped quotes?",
+		"The string state never handles backslashes.",
+		"Fixed the escape handling in Lexer.go.",
+		"Written after the commit",
+	} {
+		at := base.Add(time.Duration(i*5) * time.Minute)
+		if i == 3 {
+			at = base.Add(time.Hour)
+		}
+		if _, err := database.Exec(`
+			INSERT INTO messages (id, conversation_id, bubble_id, type, role, content, created_at)
+			VALUES (?, 'conv-1', ?, 1, 'user', ?, ?)
+		`, text, text, text, at); err != nil {
+			t.Fatalf("failed to create message: %v", err)
+		}
+	}
+
+	reporter, err := NewReporter(database, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewReporter() error = %v", err)
+	}
+
+	commits, err := reporter.Why(WhyOptions{RepositoryPath: "/home/user/alpha/", File: "internal/parser/lexer.go", Excerpts: 2})
+	if err != nil {
+		t.Fatalf("Why() error = %v", err)
+	}
+	if len(commits) != 2 || commits[0].Hash != "second" || commits[1].Hash != "first" {
+		t.Fatalf("commits = %+v, want second then first", commits)
+	}
+	if len(commits[0].Excerpts) != 0 {
+		t.Errorf("uncorrelated commit excerpts = %+v, want none", commits[0].Excerpts)
+	}
+
+	first := commits[1]
+	if first.Project != "alpha" || first.LinesAdded != 5 || len(first.Excerpts) != 2 {
+		t.Fatalf("first commit = %+v, want two excerpts from alpha", first)
+	}
+	if first.Excerpts[0].Text != "Why does lexer.go drop escaped quotes?" || !first.Excerpts[1].MentionsFile {
+		t.Errorf("excerpts = %+v, want the two messages mentioning the file, oldest first", first.Excerpts)
+	}
+
+	// A blamed commit is explained even when its file list wasn't captured
+	commits, err = reporter.Why(WhyOptions{File: "other.go", CommitHash: "unrelated", Excerpts: 4})
+	if err != nil {
+		t.Fatalf("Why() error = %v", err)
+	}
+	if len(commits) != 1 || len(commits[0].Excerpts) != 3 {
+		t.Errorf("commits = %+v, want the blamed commit with every earlier message", commits)
+	}
+}
+
+func TestReporter_ResolveCommit(t *testing.T) {
+	database := setupTestReportDB(t)
+	base := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	insertTestCommit(t, database, "abc123", "alpha", nil, base)
+	insertTestCommit(t, database, "abd456", "alpha", nil, base)
+
+	reporter, err := NewReporter(database, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewReporter() error = %v", err)
+	}
+
+	for ref, want := range map[string]string{"abc123": "abc123", "ABD": "abd456", "abc": "abc123"} {
+		if got, err := reporter.ResolveCommit(ref); err != nil || got != want {
+			t.Errorf("ResolveCommit(%q) = %q, %v, want %q", ref, got, err, want)
+		}
+	}
+	for _, ref := range []string{"", "ab", "fff"} {
+		if _, err := reporter.ResolveCommit(ref); err == nil {
+			t.Errorf("ResolveCommit(%q) should fail", ref)
+		}
+	}
+}
+
+func TestReporter_WhyResolvesBlobs(t *testing.T) {
+	database := setupTestReportDB(t)
+	store := newTestBlobStore(t)
+	base := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	insertTestSession(t, database, "alpha-1", "alpha", base)
+	insertTestCommit(t, database, "first", "alpha", "alpha-1", base.Add(20*time.Minute))
+	if _, err := database.Exec(`
+		INSERT INTO commit_files (id, commit_id, file_path, lines_added, lines_removed, created_at)
+		VALUES ('f1', 'first', 'internal/parser/lexer.go', 5, 1, ?)
+	`, base); err != nil {
+		t.Fatalf("failed to create commit file: %v", err)
+	}
+	if _, err := database.Exec(`
+		INSERT INTO conversations (id, session_id, composer_id, name, status, message_count, created_at, updated_at)
+		VALUES ('conv-1', 'alpha-1', 'composer-1', 'Lexer quotes', 'completed', 2, ?, ?)
+	`, base, base); err != nil {
+		t.Fatalf("failed to create conversation: %v", err)
+	}
+	if _, err := database.Exec(`
+		INSERT INTO messages (id, conversation_id, bubble_id, type, role, content, created_at)
+		VALUES ('short', 'conv-1', 'short', 1, 'user', 'Looks good', ?)
+	`, base.Add(10*time.Minute)); err != nil {
+		t.Fatalf("failed to create message: %v", err)
+	}
+	// The file is only named past the preview
+	long := strings.Repeat("trace line\n", blobs.PreviewLength/11+1) + "The fix belongs in lexer.go."
+	insertTestBlobMessage(t, database, store, "long", "conv-1", "agent", long, base.Add(5*time.Minute))
+
+	reporter, err := NewReporterWithBlobs(database, store, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewReporterWithBlobs() error = %v", err)
+	}
+	commits, err := reporter.Why(WhyOptions{RepositoryPath: "/home/user/alpha/", File: "internal/parser/lexer.go", Excerpts: 1})
+	if err != nil {
+		t.Fatalf("Why() error = %v", err)
+	}
+	if len(commits) != 1 || len(commits[0].Excerpts) != 1 || !commits[0].Excerpts[0].MentionsFile {
+		t.Errorf("Why() = %+v, want the blob-backed message picked for mentioning the file", commits)
+	}
+}
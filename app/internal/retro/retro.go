@@ -0,0 +1,339 @@
+// Package retro builds monthly retrospectives from captured sessions: what
+// shipped, where the time went, pain points inferred from error-heavy
+// conversations, and notable sessions, rendered as Markdown.
+package retro
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/outcomes"
+	"github.com/stwalsh4118/clio/internal/quality"
+	"github.com/stwalsh4118/clio/internal/recap"
+	"github.com/stwalsh4118/clio/pkg/export"
+)
+
+const (
+	// monthLayout is how months are given and shown
+	monthLayout = "2006-01"
+	// maxShippedCommits bounds the commit subjects listed per project
+	maxShippedCommits = 10
+	// maxPainPoints bounds the pain points listed
+	maxPainPoints = 5
+	// minPainSignals is how many error reports and retries make a conversation a pain point
+	minPainSignals = 3
+	// maxPolishOutput bounds how much a polish command may print
+	maxPolishOutput = 256 * 1024
+)
+
+// ProjectShipped is what one project shipped in the month
+type ProjectShipped struct {
+	Project  string
+	Commits  int      // Distinct commits made in the month
+	Subjects []string // Distinct commit subjects, oldest first
+}
+
+// ProjectTime is the time spent in one project
+type ProjectTime struct {
+	Project  string
+	Duration time.Duration // Total duration of the project's sessions, less the time the machine slept
+	Sessions int
+}
+
+// PainPoint is a conversation that kept hitting errors or needed retries
+type PainPoint struct {
+	Project       string
+	Topic         string // The conversation's name, or its opening prompt when unnamed
+	ErrorMentions int
+	Retries       int
+	Status        string // quality.StatusResolved, StatusAbandoned, or StatusOpen
+	StartedAt     time.Time
+}
+
+// NotableSession is a session that stood out
+type NotableSession struct {
+	SessionID     string
+	Project       string
+	Start         time.Time
+	Duration      time.Duration
+	Commits       int
+	Conversations int
+	Topic         string   // The session's first conversation topic
+	Reasons       []string // Why it stood out, e.g. "longest session"
+}
+
+// Retro is the content of a monthly retrospective
+type Retro struct {
+	Month         time.Time // Local midnight of the month's first day
+	Sessions      int
+	Conversations int
+	Commits       int
+	Total         time.Duration
+	Shipped       []ProjectShipped // Most commits first
+	Time          []ProjectTime    // Most time first
+	PainPoints    []PainPoint      // Most error reports and retries first
+	Notable       []NotableSession // Longest session, then most commits, then most conversations
+	Outcomes      outcomes.Counts  // Outcomes of the month's sessions; zero when not loaded
+}
+
+// ParseMonth parses a month as YYYY-MM, or "last" or "current" relative to
+// now, and returns local midnight of its first day
+func ParseMonth(value string, now time.Time) (time.Time, error) {
+	now = now.Local()
+	current := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "last":
+		return current.AddDate(0, -1, 0), nil
+	case "current", "this":
+		return current, nil
+	}
+	month, err := time.ParseInLocation(monthLayout, strings.TrimSpace(value), time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("month must be YYYY-MM, last, or current, got %q", value)
+	}
+	return month, nil
+}
+
+// Build builds the retrospective of the month starting at month from the
+// sessions that started in it. Pain points are conversations with at least
+// three error reports and retries between them; notable sessions are the
+// longest, the one with the most commits, and the one with the most
+// conversations.
+func Build(data *export.Data, month time.Time, counts outcomes.Counts, now time.Time) *Retro {
+	r := &Retro{Month: month, Outcomes: counts}
+	end := month.AddDate(0, 1, 0)
+	if end.After(now) {
+		end = now
+	}
+
+	shipped := make(map[string]*ProjectShipped)
+	spent := make(map[string]*ProjectTime)
+	var notable []NotableSession
+	for _, session := range data.Sessions {
+		if session.StartTime.Before(month) || !session.StartTime.Before(month.AddDate(0, 1, 0)) {
+			continue
+		}
+		r.Sessions++
+		r.Conversations += len(session.Conversations)
+
+		duration := session.ActiveDuration(data.IdleGaps, end)
+		r.Total += duration
+		project := spent[session.Project]
+		if project == nil {
+			project = &ProjectTime{Project: session.Project}
+			spent[session.Project] = project
+		}
+		project.Duration += duration
+		project.Sessions++
+
+		candidate := NotableSession{
+			SessionID:     session.ID,
+			Project:       session.Project,
+			Start:         session.StartTime,
+			Duration:      duration,
+			Conversations: len(session.Conversations),
+		}
+		commits := append([]export.Commit(nil), session.Commits...)
+		sort.SliceStable(commits, func(i, j int) bool { return commits[i].Timestamp.Before(commits[j].Timestamp) })
+		for _, commit := range commits {
+			candidate.Commits++
+			r.Commits++
+			work := shipped[session.Project]
+			if work == nil {
+				work = &ProjectShipped{Project: session.Project}
+				shipped[session.Project] = work
+			}
+			work.Commits++
+			if subject := recap.CommitSubject(commit.Message); subject != "" && !slices.Contains(work.Subjects, subject) {
+				work.Subjects = append(work.Subjects, subject)
+			}
+		}
+
+		for _, conversation := range session.Conversations {
+			topic := recap.ConversationTopic(conversation)
+			if candidate.Topic == "" {
+				candidate.Topic = topic
+			}
+			metrics := quality.Analyze(recap.QualityMessages(conversation), now, quality.DefaultIdleTimeout)
+			if metrics.ErrorMentions+metrics.Retries < minPainSignals {
+				continue
+			}
+			r.PainPoints = append(r.PainPoints, PainPoint{
+				Project:       session.Project,
+				Topic:         topic,
+				ErrorMentions: metrics.ErrorMentions,
+				Retries:       metrics.Retries,
+				Status:        metrics.Status,
+				StartedAt:     metrics.StartedAt,
+			})
+		}
+		notable = append(notable, candidate)
+	}
+
+	for _, work := range shipped {
+		r.Shipped = append(r.Shipped, *work)
+	}
+	sort.Slice(r.Shipped, func(i, j int) bool {
+		if r.Shipped[i].Commits != r.Shipped[j].Commits {
+			return r.Shipped[i].Commits > r.Shipped[j].Commits
+		}
+		return r.Shipped[i].Project < r.Shipped[j].Project
+	})
+	for _, project := range spent {
+		r.Time = append(r.Time, *project)
+	}
+	sort.Slice(r.Time, func(i, j int) bool {
+		if r.Time[i].Duration != r.Time[j].Duration {
+			return r.Time[i].Duration > r.Time[j].Duration
+		}
+		return r.Time[i].Project < r.Time[j].Project
+	})
+	sort.SliceStable(r.PainPoints, func(i, j int) bool {
+		return r.PainPoints[i].ErrorMentions+r.PainPoints[i].Retries > r.PainPoints[j].ErrorMentions+r.PainPoints[j].Retries
+	})
+	if len(r.PainPoints) > maxPainPoints {
+		r.PainPoints = r.PainPoints[:maxPainPoints]
+	}
+	r.Notable = notableSessions(notable)
+	return r
+}
+
+// notableSessions picks the longest session, the one with the most commits, and
+// the one with the most conversations, merging the reasons of a session picked twice
+func notableSessions(sessions []NotableSession) []NotableSession {
+	picks := []struct {
+		reason string
+		value  func(NotableSession) int64
+	}{
+		{"longest session", func(s NotableSession) int64 { return int64(s.Duration) }},
+		{"most commits", func(s NotableSession) int64 { return int64(s.Commits) }},
+		{"most conversations", func(s NotableSession) int64 { return int64(s.Conversations) }},
+	}
+
+	var notable []NotableSession
+	for _, pick := range picks {
+		best := -1
+		for i, session := range sessions {
+			if pick.value(session) > 0 && (best < 0 || pick.value(session) > pick.value(sessions[best])) {
+				best = i
+			}
+		}
+		if best < 0 {
+			continue
+		}
+		merged := false
+		for i := range notable {
+			if notable[i].SessionID == sessions[best].SessionID {
+				notable[i].Reasons = append(notable[i].Reasons, pick.reason)
+				merged = true
+			}
+		}
+		if !merged {
+			session := sessions[best]
+			session.Reasons = []string{pick.reason}
+			notable = append(notable, session)
+		}
+	}
+	return notable
+}
+
+// Markdown renders the retrospective with a section each for what shipped,
+// where the time went, pain points, and notable sessions
+func (r *Retro) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Retrospective: %s\n\n", r.Month.Format("January 2006"))
+	if r.Sessions == 0 {
+		b.WriteString("No sessions were captured this month.\n")
+		return b.String()
+	}
+	fmt.Fprintf(&b, "%d session(s), %d conversation(s), and %d commit(s) across %d project(s), with %s of active time.\n",
+		r.Sessions, r.Conversations, r.Commits, len(r.Time), recap.FormatDuration(r.Total))
+	if decided := r.Outcomes.Shipped + r.Outcomes.Abandoned + r.Outcomes.Blocked; decided > 0 {
+		fmt.Fprintf(&b, "Sessions shipped %d, abandoned %d, and blocked %d times (%.0f%% completion).\n",
+			r.Outcomes.Shipped, r.Outcomes.Abandoned, r.Outcomes.Blocked, r.Outcomes.CompletionRate()*100)
+	}
+
+	b.WriteString("\n## What shipped\n")
+	if len(r.Shipped) == 0 {
+		b.WriteString("\nNo commits were captured.\n")
+	}
+	for _, work := range r.Shipped {
+		fmt.Fprintf(&b, "\n### %s (%d commit(s))\n\n", work.Project, work.Commits)
+		for i, subject := range work.Subjects {
+			if i == maxShippedCommits {
+				fmt.Fprintf(&b, "- ...and %d more\n", len(work.Subjects)-maxShippedCommits)
+				break
+			}
+			fmt.Fprintf(&b, "- %s\n", subject)
+		}
+	}
+
+	b.WriteString("\n## Where the time went\n\n")
+	b.WriteString("| Project | Time | Share | Sessions |\n|---|---|---|---|\n")
+	for _, project := range r.Time {
+		share := 0.0
+		if r.Total > 0 {
+			share = float64(project.Duration) / float64(r.Total) * 100
+		}
+		fmt.Fprintf(&b, "| %s | %s | %.0f%% | %d |\n", project.Project, recap.FormatDuration(project.Duration), share, project.Sessions)
+	}
+
+	b.WriteString("\n## Pain points\n\n")
+	if len(r.PainPoints) == 0 {
+		b.WriteString("No conversations kept hitting errors.\n")
+	}
+	for _, pain := range r.PainPoints {
+		fmt.Fprintf(&b, "- **%s** (%s, %s): %d error report(s), %d retry request(s), %s\n",
+			pain.Topic, pain.Project, pain.StartedAt.Local().Format("Jan 2"), pain.ErrorMentions, pain.Retries, pain.Status)
+	}
+
+	b.WriteString("\n## Notable sessions\n\n")
+	for _, session := range r.Notable {
+		fmt.Fprintf(&b, "- %s, %s (%s): %s; %s, %d commit(s), %d conversation(s)",
+			session.Start.Local().Format("Jan 2 15:04"), session.Project, shortID(session.SessionID),
+			strings.Join(session.Reasons, ", "), recap.FormatDuration(session.Duration), session.Commits, session.Conversations)
+		if session.Topic != "" {
+			fmt.Fprintf(&b, ", starting with %q", session.Topic)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// Polish rewrites a rendered retrospective with an external command, such as a
+// script that asks an LLM to turn it into prose. The retrospective is written to
+// the command's stdin and its stdout replaces it.
+func Polish(ctx context.Context, command, text string) (string, error) {
+	cmd := exec.CommandContext(ctx, command)
+	cmd.Stdin = strings.NewReader(text)
+	cmd.Stderr = os.Stderr
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("polish command failed: %w", err)
+	}
+	if stdout.Len() > maxPolishOutput {
+		return "", fmt.Errorf("polish command printed more than %d bytes", maxPolishOutput)
+	}
+	polished := strings.TrimSpace(stdout.String())
+	if polished == "" {
+		return "", fmt.Errorf("polish command printed nothing")
+	}
+	return polished + "\n", nil
+}
+
+// shortID abbreviates a session ID
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
@@ -0,0 +1,101 @@
+package retro
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/outcomes"
+	"github.com/stwalsh4118/clio/pkg/export"
+)
+
+func TestParseMonth(t *testing.T) {
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.Local)
+	tests := map[string]time.Time{
+		"":        time.Date(2024, 2, 1, 0, 0, 0, 0, time.Local),
+		"last":    time.Date(2024, 2, 1, 0, 0, 0, 0, time.Local),
+		"current": time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local),
+		"2023-11": time.Date(2023, 11, 1, 0, 0, 0, 0, time.Local),
+	}
+	for value, want := range tests {
+		got, err := ParseMonth(value, now)
+		if err != nil || !got.Equal(want) {
+			t.Errorf("ParseMonth(%q) = %v, %v, want %v", value, got, err, want)
+		}
+	}
+	if _, err := ParseMonth("March", now); err == nil {
+		t.Error("ParseMonth(March) should fail")
+	}
+}
+
+func TestBuild(t *testing.T) {
+	month := time.Date(2024, 2, 1, 0, 0, 0, 0, time.Local)
+	at := func(day, hour int) time.Time { return time.Date(2024, 2, day, hour, 0, 0, 0, time.Local) }
+	end := func(day, hour int) *time.Time { t := at(day, hour); return &t }
+	user := func(text string, at time.Time) export.Message {
+		return export.Message{Role: "user", Text: text, CreatedAt: at}
+	}
+
+	data := &export.Data{Sessions: []export.Session{
+		{
+			ID: "long-session", Project: "clio", StartTime: at(5, 9), EndTime: end(5, 15),
+			Commits: []export.Commit{
+				{Hash: "aaaa", Message: "feat: add retro\n\nBody", Timestamp: at(5, 12)},
+				{Hash: "bbbb", Message: "fix: month bounds", Timestamp: at(5, 14)},
+			},
+			Conversations: []export.Conversation{{Name: "Retro", Messages: []export.Message{
+				user("Add a retro command", at(5, 9)),
+				user("The build failed with an error", at(5, 10)),
+				user("Still failing, same error", at(5, 11)),
+				user("Try again, it crashed", at(5, 12)),
+			}}},
+		},
+		{
+			ID: "busy-session", Project: "blog", StartTime: at(10, 9), EndTime: end(10, 10),
+			Conversations: []export.Conversation{
+				{Messages: []export.Message{user("Draft a post", at(10, 9))}},
+				{Name: "Images", Messages: []export.Message{user("Resize images", at(10, 9))}},
+			},
+		},
+		// Outside the month
+		{ID: "march", Project: "clio", StartTime: time.Date(2024, 3, 1, 9, 0, 0, 0, time.Local)},
+	}}
+
+	r := Build(data, month, outcomes.Counts{Sessions: 2, Shipped: 1, Abandoned: 1}, time.Date(2024, 3, 15, 0, 0, 0, 0, time.Local))
+	if r.Sessions != 2 || r.Conversations != 3 || r.Commits != 2 || r.Total != 7*time.Hour {
+		t.Errorf("totals = %d sessions, %d conversations, %d commits, %v, want 2, 3, 2, 7h", r.Sessions, r.Conversations, r.Commits, r.Total)
+	}
+	if len(r.Shipped) != 1 || r.Shipped[0].Project != "clio" || strings.Join(r.Shipped[0].Subjects, "|") != "feat: add retro|fix: month bounds" {
+		t.Errorf("Shipped = %+v, want clio's two commits", r.Shipped)
+	}
+	if len(r.Time) != 2 || r.Time[0].Project != "clio" || r.Time[0].Duration != 6*time.Hour {
+		t.Errorf("Time = %+v, want clio first with 6h", r.Time)
+	}
+	if len(r.PainPoints) != 1 || r.PainPoints[0].Topic != "Retro" || r.PainPoints[0].ErrorMentions < 2 {
+		t.Errorf("PainPoints = %+v, want the Retro conversation", r.PainPoints)
+	}
+	if len(r.Notable) != 2 || r.Notable[0].SessionID != "long-session" || strings.Join(r.Notable[0].Reasons, ", ") != "longest session, most commits" ||
+		r.Notable[1].SessionID != "busy-session" || r.Notable[1].Topic != "Draft a post" {
+		t.Errorf("Notable = %+v, want long-session then busy-session", r.Notable)
+	}
+
+	markdown := r.Markdown()
+	for _, want := range []string{
+		"# Retrospective: February 2024",
+		"2 session(s), 3 conversation(s), and 2 commit(s) across 2 project(s), with 7h00m of active time.",
+		"(50% completion)",
+		"### clio (2 commit(s))\n\n- feat: add retro\n- fix: month bounds\n",
+		"| clio | 6h00m | 86% | 1 |",
+		"- **Retro** (clio, Feb 5)",
+		"longest session, most commits; 6h00m, 2 commit(s), 1 conversation(s), starting with \"Retro\"",
+	} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("Markdown() missing %q:\n%s", want, markdown)
+		}
+	}
+
+	empty := Build(&export.Data{}, month, outcomes.Counts{}, time.Now()).Markdown()
+	if !strings.Contains(empty, "No sessions were captured this month.") {
+		t.Errorf("Markdown() of an empty month = %q", empty)
+	}
+}
@@ -0,0 +1,207 @@
+// Package scrub is the last pass over artifacts clio publishes, blog drafts and
+// share links, removing what shouldn't leave the machine even though it was
+// captured and stored: email addresses, people's names from a configured
+// dictionary, internal hostnames, and profanity. Unlike the sensitive gate at
+// capture time, it changes only the published text, never what is stored.
+package scrub
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/stwalsh4118/clio/internal/config"
+)
+
+const (
+	// EmailPlaceholder replaces email addresses
+	EmailPlaceholder = "[email]"
+	// NamePlaceholder replaces names from the dictionary
+	NamePlaceholder = "[name]"
+	// HostPlaceholder replaces internal hostnames
+	HostPlaceholder = "[host]"
+)
+
+var (
+	// emailPattern matches email addresses
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`)
+	// hostPattern matches dotted hostnames
+	hostPattern = regexp.MustCompile(`(?i)\b[a-z0-9](?:[a-z0-9-]*[a-z0-9])?(?:\.[a-z0-9](?:[a-z0-9-]*[a-z0-9])?)+\b`)
+	// profanityPattern matches the words masked unless profanity is kept. They're
+	// whole words, so "assert" and "Scunthorpe" are left alone.
+	profanityPattern = regexp.MustCompile(`(?i)\b(?:fuck|fucks|fucked|fucking|fucker|fuckers|motherfucker|` +
+		`shit|shits|shitty|bullshit|horseshit|damn|damned|goddamn|goddammit|crap|crappy|` +
+		`asshole|assholes|bitch|bitches|bastard|bastards|piss|pissed|wtf)\b`)
+)
+
+// internalSuffixes are domains only used on private networks, scrubbed without
+// being configured
+var internalSuffixes = []string{".internal", ".corp", ".lan", ".intranet", ".localdomain", ".home.arpa"}
+
+// Scrubber removes personal and internal details from text
+type Scrubber struct {
+	emails    bool
+	profanity bool
+	names     *regexp.Regexp // Nil without names
+	bareHosts *regexp.Regexp // Configured hostnames without dots, which hostPattern doesn't match; nil without any
+	hosts     []string       // Lowercase exact hostnames
+	suffixes  []string       // Lowercase domain suffixes, each with its leading dot
+}
+
+// New creates a scrubber from the configuration, reading its names file
+func New(cfg config.ScrubConfig) (*Scrubber, error) {
+	s := &Scrubber{
+		emails:    !cfg.KeepEmails,
+		profanity: !cfg.KeepProfanity,
+		suffixes:  append([]string(nil), internalSuffixes...),
+	}
+
+	names := append([]string(nil), cfg.Names...)
+	if cfg.NamesFile != "" {
+		fromFile, err := readNames(cfg.NamesFile)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, fromFile...)
+	}
+	s.names = wordsPattern(names)
+
+	var bare []string
+	for _, host := range cfg.Hostnames {
+		host = strings.ToLower(strings.TrimSpace(host))
+		switch {
+		case host == "" || host == ".":
+		case strings.HasPrefix(host, "."):
+			s.suffixes = append(s.suffixes, host)
+		case !strings.Contains(host, "."):
+			bare = append(bare, host)
+		default:
+			s.hosts = append(s.hosts, host)
+		}
+	}
+	s.bareHosts = wordsPattern(bare)
+	return s, nil
+}
+
+// Text returns text scrubbed. Email addresses go first, so their domains aren't
+// taken for hostnames.
+func (s *Scrubber) Text(text string) string {
+	if s == nil || text == "" {
+		return text
+	}
+	if s.emails {
+		text = emailPattern.ReplaceAllString(text, EmailPlaceholder)
+	}
+	text = replaceWords(s.names, text, NamePlaceholder)
+	text = hostPattern.ReplaceAllStringFunc(text, func(host string) string {
+		if s.internal(host) {
+			return HostPlaceholder
+		}
+		return host
+	})
+	text = replaceWords(s.bareHosts, text, HostPlaceholder)
+	if s.profanity {
+		text = profanityPattern.ReplaceAllStringFunc(text, mask)
+	}
+	return text
+}
+
+// internal reports whether a dotted hostname is configured or under an internal domain
+func (s *Scrubber) internal(host string) bool {
+	host = strings.ToLower(host)
+	for _, exact := range s.hosts {
+		if host == exact {
+			return true
+		}
+	}
+	for _, suffix := range s.suffixes {
+		if strings.HasSuffix(host, suffix) || host == suffix[1:] {
+			return true
+		}
+	}
+	return false
+}
+
+// mask keeps a word's first letter and stars the rest
+func mask(word string) string {
+	first, size := utf8.DecodeRuneInString(word)
+	return string(first) + strings.Repeat("*", utf8.RuneCountInString(word[size:]))
+}
+
+// wordsPattern matches any of words ignoring case, longest first so "Ada
+// Lovelace" wins over "Ada"; nil without words. Spaces in a word match any
+// whitespace, such as a line break in wrapped text.
+func wordsPattern(words []string) *regexp.Regexp {
+	var quoted []string
+	seen := make(map[string]bool)
+	for _, word := range words {
+		word = strings.Join(strings.Fields(word), " ")
+		if word == "" || seen[strings.ToLower(word)] {
+			continue
+		}
+		seen[strings.ToLower(word)] = true
+		quoted = append(quoted, strings.ReplaceAll(regexp.QuoteMeta(word), " ", `\s+`))
+	}
+	if len(quoted) == 0 {
+		return nil
+	}
+	sort.SliceStable(quoted, func(i, j int) bool { return len(quoted[i]) > len(quoted[j]) })
+	return regexp.MustCompile(`(?i)(?:` + strings.Join(quoted, "|") + `)`)
+}
+
+// replaceWords replaces the matches of pattern that are whole words with
+// placeholder. Word boundaries are checked here rather than with \b, which
+// only knows ASCII and would miss names like "José".
+func replaceWords(pattern *regexp.Regexp, text, placeholder string) string {
+	if pattern == nil {
+		return text
+	}
+	var b strings.Builder
+	last := 0
+	for _, match := range pattern.FindAllStringIndex(text, -1) {
+		before, _ := utf8.DecodeLastRuneInString(text[:match[0]])
+		after, _ := utf8.DecodeRuneInString(text[match[1]:])
+		if isWordRune(before) || isWordRune(after) {
+			continue
+		}
+		b.WriteString(text[last:match[0]])
+		b.WriteString(placeholder)
+		last = match[1]
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// isWordRune reports whether r continues a word
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsNumber(r)
+}
+
+// readNames reads a names file: one name per line, with blank lines and lines
+// starting with # skipped
+func readNames(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open names file: %w", err)
+	}
+	defer file.Close()
+
+	var names []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		names = append(names, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read names file: %w", err)
+	}
+	return names, nil
+}
@@ -0,0 +1,65 @@
+package scrub
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stwalsh4118/clio/internal/config"
+)
+
+func TestScrubber_Text(t *testing.T) {
+	namesFile := filepath.Join(t.TempDir(), "names.txt")
+	if err := os.WriteFile(namesFile, []byte("# teammates\nJosé Núñez\n\nGrace\n"), 0644); err != nil {
+		t.Fatalf("failed to write names file: %v", err)
+	}
+	scrubber, err := New(config.ScrubConfig{
+		Names:     []string{"Ada Lovelace", "Ada"},
+		NamesFile: namesFile,
+		Hostnames: []string{".corp.example.com", "build01", "db.example.net"},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"email", "Ask ada.l@example.com about it", "Ask [email] about it"},
+		{"longest name first", "Ada Lovelace reviewed it, then Ada merged", "[name] reviewed it, then [name] merged"},
+		{"name across a line break", "thanks to Ada\nLovelace", "thanks to [name]"},
+		{"names ignore case", "ping GRACE", "ping [name]"},
+		{"non-ASCII name", "José Núñez fixed it", "[name] fixed it"},
+		{"only whole words", "Adaptive Gracefulness", "Adaptive Gracefulness"},
+		{"configured domain", "deploy to api.corp.example.com:8443", "deploy to [host]:8443"},
+		{"configured host", "ssh build01 and db.example.net", "ssh [host] and [host]"},
+		{"internal domain", "devbox.lan and vault.internal", "[host] and [host]"},
+		{"public hosts and code", "see github.com and fmt.Errorf in main.go", "see github.com and fmt.Errorf in main.go"},
+		{"profanity", "Damn, this shitty test", "D***, this s***** test"},
+		{"no false profanity", "assert the Scunthorpe class", "assert the Scunthorpe class"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scrubber.Text(tt.text); got != tt.want {
+				t.Errorf("Text(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScrubber_Keep(t *testing.T) {
+	scrubber, err := New(config.ScrubConfig{KeepEmails: true, KeepProfanity: true})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	text := "damn, mail me@example.com"
+	if got := scrubber.Text(text); got != text {
+		t.Errorf("Text(%q) = %q, want it kept", text, got)
+	}
+
+	if _, err := New(config.ScrubConfig{NamesFile: filepath.Join(t.TempDir(), "missing.txt")}); err == nil {
+		t.Error("New() with a missing names file should fail")
+	}
+}
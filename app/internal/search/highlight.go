@@ -0,0 +1,78 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Highlight wraps each word of text that starts with a word of terms, ignoring
+// case, in before and after. Matching by prefix also marks longer forms such as
+// "retrying" for "retry", which stemming may have matched.
+func Highlight(text string, terms []string, before, after string) string {
+	prefixes := termWords(terms)
+	if len(prefixes) == 0 {
+		return text
+	}
+
+	var b strings.Builder
+	last := 0
+	eachWord(text, func(start, end int) {
+		if hasPrefix(strings.ToLower(text[start:end]), prefixes) {
+			b.WriteString(text[last:start])
+			b.WriteString(before)
+			b.WriteString(text[start:end])
+			b.WriteString(after)
+			last = end
+		}
+	})
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// ContainsTerm reports whether text has a word starting with a word of terms,
+// ignoring case
+func ContainsTerm(text string, terms []string) bool {
+	prefixes := termWords(terms)
+	found := false
+	eachWord(text, func(start, end int) {
+		found = found || hasPrefix(strings.ToLower(text[start:end]), prefixes)
+	})
+	return found
+}
+
+// termWords returns the words of terms
+func termWords(terms []string) []string {
+	var all []string
+	for _, term := range terms {
+		all = append(all, words(term)...)
+	}
+	return all
+}
+
+// hasPrefix reports whether word starts with one of prefixes
+func hasPrefix(word string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(word, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// eachWord calls fn with the byte range of each word in text, split as words does
+func eachWord(text string, fn func(start, end int)) {
+	start := -1
+	for i, r := range text {
+		inWord := unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.Is(unicode.Mn, r)
+		switch {
+		case inWord && start < 0:
+			start = i
+		case !inWord && start >= 0:
+			fn(start, i)
+			start = -1
+		}
+	}
+	if start >= 0 {
+		fn(start, len(text))
+	}
+}
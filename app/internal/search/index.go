@@ -0,0 +1,450 @@
+package search
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/blobs"
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/dedup"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+var (
+	// ErrNoWords is returned for search text without any letters or digits
+	ErrNoWords = errors.New("search query has no words")
+	// ErrOnlyStopWords is returned for search text made up of stop words
+	ErrOnlyStopWords = errors.New("search query has only stop words")
+	// ErrNotBuilt is returned when searching a database whose index has never been built
+	ErrNotBuilt = errors.New("search index has not been built")
+)
+
+// Index defines the interface for the message search index
+type Index interface {
+	// Sync indexes messages that are new or changed since the last sync, drops
+	// deleted ones, and returns how many messages were indexed
+	Sync() (int, error)
+	// Rebuild discards the index and indexes every message again
+	Rebuild() (int, error)
+	// Configure applies the tokenizer and stop words of cfg, rebuilding the
+	// full-text table from the indexed documents when they changed, and reports
+	// whether they did
+	Configure(cfg config.SearchConfig) (bool, error)
+	// Match returns the full-text query for messages containing the words of
+	// query as a phrase, in their prose or code, or only their code with codeOnly
+	Match(query string, codeOnly bool) (string, error)
+	// Compile returns a SQL condition selecting the messages matching q, over
+	// search_documents d, messages m, conversations c, and sessions s, with its
+	// arguments. Text terms match code only with codeOnly. The condition is empty
+	// when q only has time bounds.
+	Compile(q *Query, codeOnly bool) (string, []any, error)
+}
+
+// index implements Index with tables in the clio database
+type index struct {
+	db     *sql.DB
+	blobs  blobs.Store // Loads messages moved to the blob store; nil indexes their previews
+	logger logging.Logger
+	opts   *options // Loaded from search_settings on first use
+}
+
+// codeBlock is an element of a message's code_blocks JSON
+type codeBlock struct {
+	Content string `json:"content"`
+}
+
+// pendingMessage is a message whose stored form changed since it was indexed
+type pendingMessage struct {
+	content    string
+	blob       sql.NullString // Blob holding the full content when content is a preview
+	codeBlocks string
+	source     string
+	indexed    bool // An earlier version is in the index
+}
+
+// NewIndex creates a search index over the database's messages. blobStore is
+// optional; without it, messages moved to the blob store are indexed by their preview.
+func NewIndex(db *sql.DB, blobStore blobs.Store, logger logging.Logger) (Index, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database cannot be nil")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	return &index{
+		db:     db,
+		blobs:  blobStore,
+		logger: logger.With("component", "search"),
+	}, nil
+}
+
+// Rebuild reindexes every message
+func (x *index) Rebuild() (int, error) {
+	if err := x.load(); err != nil {
+		return 0, err
+	}
+	if _, err := x.db.Exec("DELETE FROM search_fts"); err != nil {
+		return 0, fmt.Errorf("failed to clear full-text index: %w", err)
+	}
+	if _, err := x.db.Exec("DELETE FROM search_documents"); err != nil {
+		return 0, fmt.Errorf("failed to clear search index: %w", err)
+	}
+	return x.Sync()
+}
+
+// Sync indexes new and changed messages
+func (x *index) Sync() (int, error) {
+	if err := x.load(); err != nil {
+		return 0, err
+	}
+	indexed, err := x.indexedSources()
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := x.db.Query("SELECT id, content, content_blob, COALESCE(code_blocks, '') FROM messages")
+	if err != nil {
+		return 0, fmt.Errorf("failed to query messages: %w", err)
+	}
+	pending := make(map[string]pendingMessage)
+	for rows.Next() {
+		var id string
+		var msg pendingMessage
+		if err := rows.Scan(&id, &msg.content, &msg.blob, &msg.codeBlocks); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan message: %w", err)
+		}
+		msg.source = sourceHash(msg.content, msg.codeBlocks)
+		source, ok := indexed[id]
+		if source != msg.source {
+			msg.indexed = ok
+			pending[id] = msg
+		}
+		delete(indexed, id)
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return 0, fmt.Errorf("error iterating messages: %w", err)
+	}
+	// Whatever is left in indexed belongs to messages that no longer exist
+	if len(pending) == 0 && len(indexed) == 0 {
+		return 0, nil
+	}
+
+	tx, err := x.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for messageID := range indexed {
+		if err := removeDocument(tx, messageID); err != nil {
+			return 0, err
+		}
+	}
+
+	now := time.Now()
+	stop := x.opts.stopSet()
+	for messageID, msg := range pending {
+		// Full content is loaded one message at a time, so a rebuild doesn't hold every blob
+		content, err := blobs.Resolve(x.blobs, msg.content, msg.blob)
+		if err != nil {
+			x.logger.Warn("indexing message by its preview", "message_id", messageID, "error", err)
+		}
+		doc := Normalize(content)
+		code, err := storedCode(tx, msg.codeBlocks)
+		if err != nil {
+			x.logger.Warn("indexing message without its code blocks", "message_id", messageID, "error", err)
+		}
+		doc.Code = joinCode(doc.Code, code)
+
+		if msg.indexed {
+			if err := removeDocument(tx, messageID); err != nil {
+				return 0, err
+			}
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO search_documents (message_id, prose, code, source_hash, indexed_at)
+			VALUES (?, ?, ?, ?, ?)
+		`, messageID, doc.Prose, doc.Code, msg.source, now); err != nil {
+			return 0, fmt.Errorf("failed to index message: %w", err)
+		}
+		if err := insertFullText(tx, messageID, doc, stop); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit search index: %w", err)
+	}
+	x.logger.Debug("indexed messages for search", "messages", len(pending), "removed", len(indexed))
+	return len(pending), nil
+}
+
+// Configure rebuilds the full-text table when the options changed
+func (x *index) Configure(cfg config.SearchConfig) (bool, error) {
+	if err := x.load(); err != nil {
+		return false, err
+	}
+	want := optionsFor(cfg)
+	if want.equal(*x.opts) {
+		return false, nil
+	}
+
+	tx, err := x.db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DROP TABLE IF EXISTS search_fts"); err != nil {
+		return false, fmt.Errorf("failed to drop full-text index: %w", err)
+	}
+	if err := createFullText(tx, want); err != nil {
+		return false, err
+	}
+	if err := saveOptions(tx, want); err != nil {
+		return false, err
+	}
+
+	rows, err := tx.Query("SELECT message_id, prose, code FROM search_documents")
+	if err != nil {
+		return false, fmt.Errorf("failed to query search documents: %w", err)
+	}
+	docs := make(map[string]Document)
+	for rows.Next() {
+		var messageID string
+		var doc Document
+		if err := rows.Scan(&messageID, &doc.Prose, &doc.Code); err != nil {
+			rows.Close()
+			return false, fmt.Errorf("failed to scan search document: %w", err)
+		}
+		docs[messageID] = doc
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return false, fmt.Errorf("error iterating search documents: %w", err)
+	}
+	stop := want.stopSet()
+	for messageID, doc := range docs {
+		if err := insertFullText(tx, messageID, doc, stop); err != nil {
+			return false, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit full-text index: %w", err)
+	}
+	x.opts = &want
+	x.logger.Info("rebuilt full-text index with new options", "tokenizer", want.Tokenizer, "stop_words", len(want.StopWords), "documents", len(docs))
+	return true, nil
+}
+
+// Match builds the full-text query for query
+func (x *index) Match(query string, codeOnly bool) (string, error) {
+	columns := "{prose code}"
+	if codeOnly {
+		columns = "code"
+	}
+	return x.phrase(query, columns)
+}
+
+// phrase builds the full-text query for the words of text as a phrase in columns
+func (x *index) phrase(text, columns string) (string, error) {
+	if err := x.loadBuilt(); err != nil {
+		return "", err
+	}
+	all := words(text)
+	if len(all) == 0 {
+		return "", ErrNoWords
+	}
+	terms := withoutStopWords(all, x.opts.stopSet())
+	if len(terms) == 0 {
+		return "", ErrOnlyStopWords
+	}
+
+	// Words are letters and digits only, so they need no escaping inside the phrase
+	return fmt.Sprintf(`%s : "%s"`, columns, strings.Join(terms, " ")), nil
+}
+
+// load reads the options the full-text table was built with, creating the table
+// with the defaults when there is none yet
+func (x *index) load() error {
+	if x.opts != nil {
+		return nil
+	}
+
+	var raw string
+	err := x.db.QueryRow("SELECT options FROM search_settings WHERE id = 1").Scan(&raw)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to load search settings: %w", err)
+	}
+	opts := optionsFor(config.SearchConfig{})
+	if err == nil {
+		if err := json.Unmarshal([]byte(raw), &opts); err != nil {
+			return fmt.Errorf("failed to parse search settings: %w", err)
+		}
+	}
+
+	tx, err := x.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	if err := createFullText(tx, opts); err != nil {
+		return err
+	}
+	if raw == "" {
+		if err := saveOptions(tx, opts); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit search settings: %w", err)
+	}
+	x.opts = &opts
+	return nil
+}
+
+// loadBuilt reads the options the full-text table was built with, without
+// creating anything, so searches work on a read-only database. Returns
+// ErrNotBuilt when the index has never been built.
+func (x *index) loadBuilt() error {
+	if x.opts != nil {
+		return nil
+	}
+
+	var raw string
+	err := x.db.QueryRow("SELECT options FROM search_settings WHERE id = 1").Scan(&raw)
+	if err == sql.ErrNoRows {
+		return ErrNotBuilt
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load search settings: %w", err)
+	}
+	opts := optionsFor(config.SearchConfig{})
+	if err := json.Unmarshal([]byte(raw), &opts); err != nil {
+		return fmt.Errorf("failed to parse search settings: %w", err)
+	}
+	x.opts = &opts
+	return nil
+}
+
+// indexedSources returns the source hash each indexed message was indexed from
+func (x *index) indexedSources() (map[string]string, error) {
+	rows, err := x.db.Query("SELECT message_id, source_hash FROM search_documents")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query search index: %w", err)
+	}
+	defer rows.Close()
+
+	sources := make(map[string]string)
+	for rows.Next() {
+		var messageID, hash string
+		if err := rows.Scan(&messageID, &hash); err != nil {
+			return nil, fmt.Errorf("failed to scan search document: %w", err)
+		}
+		sources[messageID] = hash
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating search documents: %w", err)
+	}
+	return sources, nil
+}
+
+// createFullText creates the full-text table with the tokenizer of opts
+func createFullText(tx *sql.Tx, opts options) error {
+	if _, err := tx.Exec(fmt.Sprintf(`
+		CREATE VIRTUAL TABLE IF NOT EXISTS search_fts USING fts5(
+			message_id UNINDEXED, prose, code, tokenize = '%s'
+		)
+	`, opts.tokenize())); err != nil {
+		return fmt.Errorf("failed to create full-text index: %w", err)
+	}
+	return nil
+}
+
+// saveOptions records the options the full-text table was built with
+func saveOptions(tx *sql.Tx, opts options) error {
+	raw, err := json.Marshal(opts)
+	if err != nil {
+		return fmt.Errorf("failed to encode search settings: %w", err)
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO search_settings (id, options, updated_at) VALUES (1, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET options = excluded.options, updated_at = excluded.updated_at
+	`, string(raw), time.Now()); err != nil {
+		return fmt.Errorf("failed to save search settings: %w", err)
+	}
+	return nil
+}
+
+// insertFullText adds a document to the full-text table without its stop words
+func insertFullText(tx *sql.Tx, messageID string, doc Document, stop map[string]bool) error {
+	if _, err := tx.Exec("INSERT INTO search_fts (message_id, prose, code) VALUES (?, ?, ?)",
+		messageID, fullText(doc.Prose, stop), fullText(doc.Code, stop)); err != nil {
+		return fmt.Errorf("failed to add message to full-text index: %w", err)
+	}
+	return nil
+}
+
+// removeDocument removes a message from the search index
+func removeDocument(tx *sql.Tx, messageID string) error {
+	if _, err := tx.Exec("DELETE FROM search_fts WHERE message_id = ?", messageID); err != nil {
+		return fmt.Errorf("failed to remove message from full-text index: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM search_documents WHERE message_id = ?", messageID); err != nil {
+		return fmt.Errorf("failed to remove message from search index: %w", err)
+	}
+	return nil
+}
+
+// storedCode returns the contents of a message's code_blocks JSON, with shared
+// blocks restored
+func storedCode(q dedup.Querier, blocksJSON string) ([]string, error) {
+	if strings.TrimSpace(blocksJSON) == "" {
+		return nil, nil
+	}
+	expanded, err := dedup.ExpandCodeBlocks(q, blocksJSON)
+	if err != nil {
+		return nil, err
+	}
+	var blocks []codeBlock
+	if err := json.Unmarshal([]byte(expanded), &blocks); err != nil {
+		return nil, fmt.Errorf("failed to parse code blocks: %w", err)
+	}
+	code := make([]string, 0, len(blocks))
+	for _, block := range blocks {
+		code = append(code, block.Content)
+	}
+	return code, nil
+}
+
+// joinCode appends the code blocks not already fenced in the message text
+func joinCode(fenced string, blocks []string) string {
+	parts := []string{}
+	if fenced != "" {
+		parts = append(parts, fenced)
+	}
+	for _, block := range blocks {
+		if block = strings.Trim(block, "\n"); strings.TrimSpace(block) != "" && !strings.Contains(fenced, block) {
+			parts = append(parts, block)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// sourceHash identifies the stored form of a message, so changes are reindexed
+func sourceHash(content, codeBlocks string) string {
+	sum := sha256.Sum256([]byte(content + "\x00" + codeBlocks))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,119 @@
+// Package search keeps a search index of captured messages. Message text is
+// normalized as it's indexed: markdown syntax is stripped from the prose and code
+// is kept apart, so queries match the words people wrote rather than the markup
+// around them, and code searches skip the conversation around the code.
+package search
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// fencePattern opens or closes a fenced code block
+	fencePattern = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+	// inlineCodePattern matches a code span
+	inlineCodePattern = regexp.MustCompile("`+[^`]+`+")
+	// headingPattern, quotePattern, and listPattern match line prefixes
+	headingPattern = regexp.MustCompile(`^\s{0,3}#{1,6}\s+`)
+	quotePattern   = regexp.MustCompile(`^\s*(>\s?)+`)
+	listPattern    = regexp.MustCompile(`^\s*([-*+]|\d+[.)])\s+(\[[ xX]\]\s+)?`)
+	// rulePattern matches thematic breaks and table separator rows
+	rulePattern = regexp.MustCompile(`^\s*([-*_]\s*){3,}$|^\s*\|?(\s*:?-+:?\s*\|)+\s*:?-*:?\s*$`)
+	// imagePattern and linkPattern keep the text of images and links
+	imagePattern = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	linkPattern  = regexp.MustCompile(`\[([^\]]+)\]\([^)]*\)`)
+	// autolinkPattern keeps the address of <https://...> links
+	autolinkPattern = regexp.MustCompile(`<((?:https?|mailto):[^>\s]+)>`)
+	// strongPattern, strikePattern, emphasisPattern, and underscorePattern keep the
+	// emphasized text. Underscores only emphasize at word boundaries, so snake_case
+	// survives.
+	strongPattern     = regexp.MustCompile(`\*\*([^*]+)\*\*|(^|[^\w])__([^_]+)__([^\w]|$)`)
+	strikePattern     = regexp.MustCompile(`~~([^~]+)~~`)
+	emphasisPattern   = regexp.MustCompile(`\*([^*\s][^*]*)\*`)
+	underscorePattern = regexp.MustCompile(`(^|[^\w])_([^_]+)_([^\w]|$)`)
+)
+
+// Document is a message's text normalized for search
+type Document struct {
+	Prose string // Text outside code blocks without markdown syntax, whitespace collapsed
+	Code  string // Contents of the fenced code blocks, one block per paragraph
+}
+
+// Normalize splits markdown into prose and code. Fenced code blocks go to code;
+// headings, list and quote markers, emphasis, and link targets are stripped from
+// the prose, keeping their text. Inline code stays in the prose without its
+// backticks, since it's usually part of a sentence.
+func Normalize(markdown string) Document {
+	var prose []string
+	var blocks []string
+	var block []string
+	fence := ""
+	for _, line := range strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n") {
+		if fence != "" {
+			if marker := fencePattern.FindStringSubmatch(line); marker != nil && marker[1][0] == fence[0] &&
+				len(marker[1]) >= len(fence) && strings.TrimSpace(line[len(marker[0]):]) == "" {
+				blocks = append(blocks, strings.Join(block, "\n"))
+				block, fence = nil, ""
+				continue
+			}
+			block = append(block, line)
+			continue
+		}
+		if marker := fencePattern.FindStringSubmatch(line); marker != nil {
+			fence = marker[1]
+			continue
+		}
+		if text := normalizeLine(line); text != "" {
+			prose = append(prose, text)
+		}
+	}
+	// An unclosed fence runs to the end of the message
+	if fence != "" {
+		blocks = append(blocks, strings.Join(block, "\n"))
+	}
+
+	var code []string
+	for _, b := range blocks {
+		if strings.TrimSpace(b) != "" {
+			code = append(code, b)
+		}
+	}
+	return Document{
+		Prose: strings.Join(strings.Fields(strings.Join(prose, " ")), " "),
+		Code:  strings.Join(code, "\n\n"),
+	}
+}
+
+// normalizeLine strips the markdown syntax from a line of prose
+func normalizeLine(line string) string {
+	if rulePattern.MatchString(line) {
+		return ""
+	}
+	line = headingPattern.ReplaceAllString(line, "")
+	line = quotePattern.ReplaceAllString(line, "")
+	line = listPattern.ReplaceAllString(line, "")
+
+	// Code spans are kept verbatim; only the text between them is stripped
+	var b strings.Builder
+	last := 0
+	for _, span := range inlineCodePattern.FindAllStringIndex(line, -1) {
+		b.WriteString(stripInline(line[last:span[0]]))
+		b.WriteString(strings.TrimSpace(strings.Trim(line[span[0]:span[1]], "`")))
+		last = span[1]
+	}
+	b.WriteString(stripInline(line[last:]))
+	return strings.TrimSpace(b.String())
+}
+
+// stripInline removes emphasis, link, and table syntax from text outside code spans
+func stripInline(text string) string {
+	text = imagePattern.ReplaceAllString(text, "$1")
+	text = linkPattern.ReplaceAllString(text, "$1")
+	text = autolinkPattern.ReplaceAllString(text, "$1")
+	text = strongPattern.ReplaceAllString(text, "$1$2$3$4")
+	text = strikePattern.ReplaceAllString(text, "$1")
+	text = emphasisPattern.ReplaceAllString(text, "$1")
+	text = underscorePattern.ReplaceAllString(text, "$1$2$3")
+	return strings.ReplaceAll(text, "|", " ")
+}
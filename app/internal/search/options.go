@@ -0,0 +1,101 @@
+package search
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/search/stopwords"
+)
+
+// options are the settings the full-text table is built with, stored in
+// search_settings so a change can be detected
+type options struct {
+	Tokenizer      string   `json:"tokenizer"`
+	KeepDiacritics bool     `json:"keep_diacritics"`
+	StopWords      []string `json:"stop_words"` // Lowercase and sorted, from the languages and extra words
+}
+
+// optionsFor resolves the configured search options
+func optionsFor(cfg config.SearchConfig) options {
+	opts := options{Tokenizer: cfg.Tokenizer, KeepDiacritics: cfg.KeepDiacritics, StopWords: []string{}}
+	if opts.Tokenizer == "" {
+		opts.Tokenizer = config.SearchTokenizerUnicode61
+	}
+
+	seen := make(map[string]bool)
+	add := func(word string) {
+		if word = strings.ToLower(strings.TrimSpace(word)); word != "" && !seen[word] {
+			seen[word] = true
+			opts.StopWords = append(opts.StopWords, word)
+		}
+	}
+	for _, language := range cfg.Languages {
+		list, _ := stopwords.Words(language)
+		for _, word := range list {
+			add(word)
+		}
+	}
+	for _, word := range cfg.StopWords {
+		add(word)
+	}
+	sort.Strings(opts.StopWords)
+	return opts
+}
+
+// equal reports whether the full-text table built with o matches other
+func (o options) equal(other options) bool {
+	return o.Tokenizer == other.Tokenizer && o.KeepDiacritics == other.KeepDiacritics && slices.Equal(o.StopWords, other.StopWords)
+}
+
+// tokenize returns the FTS5 tokenize argument
+func (o options) tokenize() string {
+	diacritics := "2"
+	if o.KeepDiacritics {
+		diacritics = "0"
+	}
+	tokenizer := "unicode61 remove_diacritics " + diacritics
+	if o.Tokenizer == config.SearchTokenizerPorter {
+		tokenizer = "porter " + tokenizer
+	}
+	return tokenizer
+}
+
+// stopSet returns the stop words as a set
+func (o options) stopSet() map[string]bool {
+	stop := make(map[string]bool, len(o.StopWords))
+	for _, word := range o.StopWords {
+		stop[word] = true
+	}
+	return stop
+}
+
+// words splits text into lowercase words of letters and digits, as the
+// unicode61 tokenizer does
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r) && !unicode.Is(unicode.Mn, r)
+	})
+}
+
+// withoutStopWords returns the words that aren't stop words
+func withoutStopWords(all []string, stop map[string]bool) []string {
+	kept := make([]string, 0, len(all))
+	for _, word := range all {
+		if !stop[word] {
+			kept = append(kept, word)
+		}
+	}
+	return kept
+}
+
+// fullText returns text as it's stored in the full-text table: unchanged
+// without stop words, otherwise its remaining words
+func fullText(text string, stop map[string]bool) string {
+	if len(stop) == 0 {
+		return text
+	}
+	return strings.Join(withoutStopWords(words(text), stop), " ")
+}
@@ -0,0 +1,436 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/stwalsh4118/clio/internal/filters"
+)
+
+// Query fields. Text fields match words in the search index, time fields bound
+// the whole query, and the rest compare message metadata.
+const (
+	fieldCode         = "code"
+	fieldProse        = "prose"
+	fieldProject      = "project"
+	fieldRole         = "role"
+	fieldSession      = "session"
+	fieldConversation = "conversation"
+	fieldSince        = "since"
+	fieldUntil        = "until"
+	fieldDate         = "date"
+)
+
+// fields are the names recognized before a colon; other words with a colon,
+// like "panic:" or "http://host", are searched as text
+var fields = map[string]bool{
+	fieldCode: true, fieldProse: true, fieldProject: true, fieldRole: true, fieldSession: true,
+	fieldConversation: true, fieldSince: true, fieldUntil: true, fieldDate: true,
+}
+
+// Query operators
+const (
+	opTerm = "term"
+	opAnd  = "AND"
+	opOr   = "OR"
+	opNot  = "NOT"
+)
+
+// dateRangeSeparator splits the two ends of a date: range
+const dateRangeSeparator = ".."
+
+// Query is a parsed search query. Time bounds only narrow the whole query, so
+// they are kept apart from the condition on message text and metadata.
+type Query struct {
+	Since time.Time // From since: and date: terms; zero means no lower bound
+	Until time.Time // From until: and date: terms; zero means no upper bound
+	root  *node     // Nil when the query only has time bounds
+}
+
+// node is an operator or a term of a parsed query
+type node struct {
+	op       string
+	children []*node
+	field    string // For terms: a field name, or empty for text in prose or code
+	value    string
+}
+
+// token is a lexical element of a query
+type token struct {
+	text   string // Operator, parenthesis, or term value
+	field  string // For terms: a field name, or empty for text
+	term   bool
+	quoted bool // Quoted terms are never operators
+}
+
+// ParseQuery parses a search query. Terms are words, "quoted phrases", and
+// field:value pairs, combined with AND (implied between terms), OR, NOT, and
+// parentheses. Relative times in since:, until:, and date: resolve against now.
+func ParseQuery(text string, now time.Time) (*Query, error) {
+	tokens, err := lex(text)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("search query cannot be empty")
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q in search query", p.tokens[p.pos].text)
+	}
+
+	q := &Query{}
+	if q.root, err = q.bound(root, true, now); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// Terms returns the text of the query's terms that aren't negated, for
+// picking out where a message matched
+func (q *Query) Terms() []string {
+	var terms []string
+	var walk func(n *node)
+	walk = func(n *node) {
+		switch n.op {
+		case opTerm:
+			if n.field == "" || n.field == fieldCode || n.field == fieldProse {
+				terms = append(terms, n.value)
+			}
+		case opAnd, opOr:
+			for _, child := range n.children {
+				walk(child)
+			}
+		}
+	}
+	if q.root != nil {
+		walk(q.root)
+	}
+	return terms
+}
+
+// bound moves time terms into the query's bounds and returns n without them.
+// Time terms are only allowed where they narrow the whole query.
+func (q *Query) bound(n *node, top bool, now time.Time) (*node, error) {
+	if n.op == opTerm {
+		if n.field != fieldSince && n.field != fieldUntil && n.field != fieldDate {
+			return n, nil
+		}
+		if !top {
+			return nil, fmt.Errorf("%s:%s can only narrow the whole query, not part of an OR or NOT", n.field, n.value)
+		}
+		return nil, q.applyTime(n.field, n.value, now)
+	}
+
+	kept := make([]*node, 0, len(n.children))
+	for _, child := range n.children {
+		child, err := q.bound(child, top && n.op == opAnd, now)
+		if err != nil {
+			return nil, err
+		}
+		if child != nil {
+			kept = append(kept, child)
+		}
+	}
+	switch {
+	case len(kept) == 0:
+		return nil, nil
+	case len(kept) == 1 && n.op != opNot:
+		return kept[0], nil
+	}
+	n.children = kept
+	return n, nil
+}
+
+// applyTime narrows the query's bounds by a since:, until:, or date: term
+func (q *Query) applyTime(field, value string, now time.Time) error {
+	since, until := value, ""
+	switch field {
+	case fieldUntil:
+		since, until = "", value
+	case fieldDate:
+		// A single date covers that day; a range covers both end dates
+		var ok bool
+		if since, until, ok = strings.Cut(value, dateRangeSeparator); !ok {
+			if _, err := time.ParseInLocation(filters.DateLayout, value, time.Local); err != nil {
+				return fmt.Errorf("date:%s is not a date (%s) or range (A..B)", value, filters.DateLayout)
+			}
+			until = value
+		}
+	}
+
+	start, err := filters.ParseTime(since, now)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", field, err)
+	}
+	end, err := filters.ParseTime(until, now)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", field, err)
+	}
+	if field == fieldDate {
+		if day, err := time.ParseInLocation(filters.DateLayout, strings.TrimSpace(until), time.Local); err == nil {
+			end = day.AddDate(0, 0, 1)
+		}
+	}
+
+	if !start.IsZero() && start.After(q.Since) {
+		q.Since = start
+	}
+	if !end.IsZero() && (q.Until.IsZero() || end.Before(q.Until)) {
+		q.Until = end
+	}
+	return nil
+}
+
+// lex splits a query into tokens
+func lex(text string) ([]token, error) {
+	runes := []rune(text)
+	var tokens []token
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')':
+			tokens = append(tokens, token{text: string(r)})
+			i++
+		case r == '"':
+			value, next, err := quoted(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{text: value, term: true, quoted: true})
+			i = next
+		default:
+			word, next := bare(runes, i)
+			i = next
+			name, value, found := strings.Cut(word, ":")
+			name = strings.ToLower(name)
+			if !found || !fields[name] {
+				tokens = append(tokens, token{text: word, term: true})
+				continue
+			}
+			if value == "" && i < len(runes) && runes[i] == '"' {
+				var err error
+				if value, i, err = quoted(runes, i); err != nil {
+					return nil, err
+				}
+			}
+			if strings.TrimSpace(value) == "" {
+				return nil, fmt.Errorf("%s: needs a value", name)
+			}
+			tokens = append(tokens, token{text: value, field: name, term: true, quoted: true})
+		}
+	}
+	return tokens, nil
+}
+
+// quoted reads the quoted string starting at runes[start], returning its
+// contents and the position after the closing quote
+func quoted(runes []rune, start int) (string, int, error) {
+	for i := start + 1; i < len(runes); i++ {
+		if runes[i] == '"' {
+			return string(runes[start+1 : i]), i + 1, nil
+		}
+	}
+	return "", 0, errors.New("search query has an unclosed quote")
+}
+
+// bare reads an unquoted word starting at runes[start]. Parentheses that
+// balance within the word belong to it, so "ctx.Done()" stays one word.
+func bare(runes []rune, start int) (string, int) {
+	depth := 0
+	i := start
+	for ; i < len(runes); i++ {
+		r := runes[i]
+		if unicode.IsSpace(r) || r == '"' && i > start {
+			break
+		}
+		if r == '(' {
+			depth++
+		}
+		if r == ')' {
+			if depth == 0 {
+				break
+			}
+			depth--
+		}
+	}
+	return string(runes[start:i]), i
+}
+
+// parser builds a query tree from tokens by recursive descent
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// peek reports whether the next token is the operator or parenthesis op
+func (p *parser) peek(op string) bool {
+	return p.pos < len(p.tokens) && !p.tokens[p.pos].quoted && p.tokens[p.pos].text == op
+}
+
+// or parses terms joined by OR
+func (p *parser) or() (*node, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	children := []*node{left}
+	for p.peek(opOr) {
+		p.pos++
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, right)
+	}
+	if len(children) == 1 {
+		return left, nil
+	}
+	return &node{op: opOr, children: children}, nil
+}
+
+// and parses terms joined by AND or written next to each other
+func (p *parser) and() (*node, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	children := []*node{left}
+	for p.pos < len(p.tokens) && !p.peek(opOr) && !p.peek(")") {
+		if p.peek(opAnd) {
+			p.pos++
+		}
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, right)
+	}
+	if len(children) == 1 {
+		return left, nil
+	}
+	return &node{op: opAnd, children: children}, nil
+}
+
+// unary parses a term, a parenthesized group, or either negated with NOT
+func (p *parser) unary() (*node, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("search query ends where a term was expected")
+	}
+	switch {
+	case p.peek(opNot):
+		p.pos++
+		child, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &node{op: opNot, children: []*node{child}}, nil
+	case p.peek("("):
+		p.pos++
+		group, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if !p.peek(")") {
+			return nil, fmt.Errorf("search query has an unclosed parenthesis")
+		}
+		p.pos++
+		return group, nil
+	case p.peek(")"), p.peek(opAnd), p.peek(opOr):
+		return nil, fmt.Errorf("unexpected %q in search query", p.tokens[p.pos].text)
+	}
+
+	tok := p.tokens[p.pos]
+	p.pos++
+	return &node{op: opTerm, field: tok.field, value: tok.text}, nil
+}
+
+// Compile returns the SQL condition for q
+func (x *index) Compile(q *Query, codeOnly bool) (string, []any, error) {
+	if q.root == nil {
+		return "", nil, nil
+	}
+	var args []any
+	var dropped error
+	where, err := x.compile(q.root, codeOnly, &args, &dropped)
+	if err != nil {
+		return "", nil, err
+	}
+	// Terms without indexed words are left out, unless nothing else is left
+	if where == "" {
+		return "", nil, dropped
+	}
+	return where, args, nil
+}
+
+// compile returns the SQL condition for n, or an empty condition when n only
+// has text without indexed words, whose error is kept in dropped
+func (x *index) compile(n *node, codeOnly bool, args *[]any, dropped *error) (string, error) {
+	if n.op != opTerm {
+		parts := make([]string, 0, len(n.children))
+		for _, child := range n.children {
+			part, err := x.compile(child, codeOnly, args, dropped)
+			if err != nil {
+				return "", err
+			}
+			if part != "" {
+				parts = append(parts, part)
+			}
+		}
+		switch {
+		case len(parts) == 0:
+			return "", nil
+		case n.op == opNot:
+			return "NOT " + parts[0], nil
+		}
+		return "(" + strings.Join(parts, " "+n.op+" ") + ")", nil
+	}
+
+	switch n.field {
+	case "", fieldCode, fieldProse:
+		columns := "{prose code}"
+		if n.field != "" {
+			columns = n.field
+		} else if codeOnly {
+			columns = fieldCode
+		}
+		match, err := x.phrase(n.value, columns)
+		if errors.Is(err, ErrNoWords) || errors.Is(err, ErrOnlyStopWords) {
+			*dropped = err
+			return "", nil
+		}
+		if err != nil {
+			return "", err
+		}
+		*args = append(*args, match)
+		return "d.message_id IN (SELECT message_id FROM search_fts WHERE search_fts MATCH ?)", nil
+	case fieldProject:
+		*args = append(*args, n.value)
+		return "LOWER(COALESCE(s.project, '')) = LOWER(?)", nil
+	case fieldRole:
+		*args = append(*args, n.value)
+		return "LOWER(m.role) = LOWER(?)", nil
+	case fieldSession:
+		*args = append(*args, escapeLike(n.value)+"%")
+		return `s.id LIKE ? ESCAPE '\'`, nil
+	case fieldConversation:
+		*args = append(*args, "%"+escapeLike(n.value)+"%")
+		return `COALESCE(c.name, '') LIKE ? ESCAPE '\'`, nil
+	}
+	return "", fmt.Errorf("unknown search field %q", n.field)
+}
+
+// escapeLike escapes the LIKE wildcards in s
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
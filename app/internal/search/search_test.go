@@ -0,0 +1,353 @@
+package search
+
+import (
+	"database/sql"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/blobs"
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+func setupTestSearchDB(t *testing.T) *sql.DB {
+	t.Helper()
+	testDB, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	t.Cleanup(func() { testDB.Close() })
+	if err := db.RunMigrations(testDB); err != nil {
+		t.Fatalf("failed to run migrations: %v", err)
+	}
+	return testDB
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		want     Document
+	}{
+		{
+			name:     "plain text",
+			markdown: "Add retry logic\nto the  pipeline",
+			want:     Document{Prose: "Add retry logic to the pipeline"},
+		},
+		{
+			name:     "headings lists and quotes",
+			markdown: "# Plan\n\n- [x] parse the **config**\n2. write _tests_\n> note: keep snake_case_names",
+			want:     Document{Prose: "Plan parse the config write tests note: keep snake_case_names"},
+		},
+		{
+			name:     "links and images",
+			markdown: "See [the docs](https://example.com/docs) and ![diagram](d.png) or <https://example.com>",
+			want:     Document{Prose: "See the docs and diagram or https://example.com"},
+		},
+		{
+			name:     "inline code stays in the prose",
+			markdown: "Call `Retry()` with ~~three~~ *five* attempts",
+			want:     Document{Prose: "Call Retry() with three five attempts"},
+		},
+		{
+			name:     "tables and rules",
+			markdown: "| name | value |\n|------|:-----:|\n| a | 1 |\n\n---",
+			want:     Document{Prose: "name value a 1"},
+		},
+		{
+			name:     "fenced code",
+			markdown: "Try this:\n```go\nfunc main() {\n\tfmt.Println(\"**hi**\")\n}\n```\nthen\n~~~\nmake test\n~~~",
+			want:     Document{Prose: "Try this: then", Code: "func main() {\n\tfmt.Println(\"**hi**\")\n}\n\nmake test"},
+		},
+		{
+			name:     "unclosed fence",
+			markdown: "Run:\n```sh\ngo test ./...",
+			want:     Document{Prose: "Run:", Code: "go test ./..."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Normalize(tt.markdown); got != tt.want {
+				t.Errorf("Normalize() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIndex_Sync(t *testing.T) {
+	testDB := setupTestSearchDB(t)
+
+	insert := func(id, content, codeBlocks string) {
+		t.Helper()
+		if _, err := testDB.Exec(`
+			INSERT INTO messages (id, conversation_id, bubble_id, type, role, content, code_blocks, created_at)
+			VALUES (?, 'conv-1', ?, 2, 'agent', ?, ?, ?)
+		`, id, id, content, codeBlocks, time.Now()); err != nil {
+			t.Fatalf("failed to create message: %v", err)
+		}
+	}
+	document := func(id string) (prose, code string) {
+		t.Helper()
+		if err := testDB.QueryRow("SELECT prose, code FROM search_documents WHERE message_id = ?", id).Scan(&prose, &code); err != nil {
+			t.Fatalf("failed to read search document %s: %v", id, err)
+		}
+		return prose, code
+	}
+
+	insert("m1", "**Bold** claim", "")
+	insert("m2", "Use this:\n```\nfenced()\n```", `[{"content":"fenced()\n"},{"content":"separate()","languageId":"go"}]`)
+
+	index, err := NewIndex(testDB, nil, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewIndex() error = %v", err)
+	}
+	if n, err := index.Sync(); err != nil || n != 2 {
+		t.Fatalf("Sync() = %d, %v, want 2", n, err)
+	}
+	if prose, _ := document("m1"); prose != "Bold claim" {
+		t.Errorf("m1 prose = %q, want Bold claim", prose)
+	}
+	// Stored code blocks already fenced in the text aren't repeated
+	if _, code := document("m2"); code != "fenced()\n\nseparate()" {
+		t.Errorf("m2 code = %q", code)
+	}
+
+	// Unchanged messages aren't indexed again; edited and deleted ones are updated
+	if n, err := index.Sync(); err != nil || n != 0 {
+		t.Errorf("Sync() without changes = %d, %v, want 0", n, err)
+	}
+	if _, err := testDB.Exec("UPDATE messages SET content = 'Edited _claim_' WHERE id = 'm1'"); err != nil {
+		t.Fatalf("failed to edit message: %v", err)
+	}
+	if _, err := testDB.Exec("DELETE FROM messages WHERE id = 'm2'"); err != nil {
+		t.Fatalf("failed to delete message: %v", err)
+	}
+	if n, err := index.Sync(); err != nil || n != 1 {
+		t.Errorf("Sync() after an edit = %d, %v, want 1", n, err)
+	}
+	if prose, _ := document("m1"); prose != "Edited claim" {
+		t.Errorf("m1 prose after edit = %q, want Edited claim", prose)
+	}
+	var count int
+	if err := testDB.QueryRow("SELECT COUNT(*) FROM search_documents").Scan(&count); err != nil || count != 1 {
+		t.Errorf("search documents = %d, %v, want the deleted message removed", count, err)
+	}
+
+	if n, err := index.Rebuild(); err != nil || n != 1 {
+		t.Errorf("Rebuild() = %d, %v, want 1", n, err)
+	}
+}
+
+func TestIndex_SyncResolvesBlobs(t *testing.T) {
+	testDB := setupTestSearchDB(t)
+	store, err := blobs.NewStore(filepath.Join(t.TempDir(), "blobs"), logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+
+	// The row keeps only a preview of a message over the preview length
+	long := strings.Repeat("filler ", blobs.PreviewLength/7+1) + "needle at the end"
+	ref, err := store.Put([]byte(long))
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if _, err := testDB.Exec(`
+		INSERT INTO messages (id, conversation_id, bubble_id, type, role, content, content_blob, created_at)
+		VALUES ('long', 'conv-1', 'long', 2, 'agent', ?, ?, ?)
+	`, blobs.Preview(long), ref, time.Now()); err != nil {
+		t.Fatalf("failed to create message: %v", err)
+	}
+
+	index, err := NewIndex(testDB, store, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewIndex() error = %v", err)
+	}
+	if n, err := index.Sync(); err != nil || n != 1 {
+		t.Fatalf("Sync() = %d, %v, want 1", n, err)
+	}
+	match, err := index.Match("needle", false)
+	if err != nil {
+		t.Fatalf("Match() error = %v", err)
+	}
+	var id string
+	if err := testDB.QueryRow("SELECT message_id FROM search_fts WHERE search_fts MATCH ?", match).Scan(&id); err != nil || id != "long" {
+		t.Errorf("search for text past the preview = %q, %v, want the blob-backed message", id, err)
+	}
+}
+
+func TestIndex_Configure(t *testing.T) {
+	testDB := setupTestSearchDB(t)
+	if _, err := testDB.Exec(`
+		INSERT INTO messages (id, conversation_id, bubble_id, type, role, content, created_at)
+		VALUES ('m1', 'conv-1', 'm1', 2, 'agent', 'Die Wiederholungen für den Café-Export', ?)
+	`, time.Now()); err != nil {
+		t.Fatalf("failed to create message: %v", err)
+	}
+
+	index, err := NewIndex(testDB, nil, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewIndex() error = %v", err)
+	}
+	if _, err := index.Sync(); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	matches := func(query string) bool {
+		t.Helper()
+		match, err := index.Match(query, false)
+		if err != nil {
+			t.Fatalf("Match(%q) error = %v", query, err)
+		}
+		var count int
+		if err := testDB.QueryRow("SELECT COUNT(*) FROM search_fts WHERE search_fts MATCH ?", match).Scan(&count); err != nil {
+			t.Fatalf("failed to search %q: %v", query, err)
+		}
+		return count > 0
+	}
+
+	// The defaults fold diacritics but keep every word
+	if !matches("cafe export") || !matches("für den") {
+		t.Error("default options should match with folded diacritics and keep stop words")
+	}
+	if changed, err := index.Configure(config.SearchConfig{}); err != nil || changed {
+		t.Errorf("Configure() with the defaults = %v, %v, want unchanged", changed, err)
+	}
+
+	// German stop words leave "für den" out, so the words around it are adjacent
+	cfg := config.SearchConfig{Tokenizer: config.SearchTokenizerUnicode61, Languages: []string{"german"}, StopWords: []string{"Export"}}
+	if changed, err := index.Configure(cfg); err != nil || !changed {
+		t.Fatalf("Configure() = %v, %v, want the index rebuilt", changed, err)
+	}
+	if !matches("Wiederholungen für den Café") {
+		t.Error("phrase across stop words should match")
+	}
+	if _, err := index.Match("für den export", false); err == nil {
+		t.Error("Match() of only stop words should fail")
+	}
+
+	// Settings are stored, so a new index keeps them
+	reopened, err := NewIndex(testDB, nil, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewIndex() error = %v", err)
+	}
+	if changed, err := reopened.Configure(cfg); err != nil || changed {
+		t.Errorf("Configure() with stored options = %v, %v, want unchanged", changed, err)
+	}
+	if changed, err := reopened.Configure(config.SearchConfig{Tokenizer: config.SearchTokenizerUnicode61, KeepDiacritics: true}); err != nil || !changed {
+		t.Fatalf("Configure() keeping diacritics = %v, %v, want the index rebuilt", changed, err)
+	}
+	index = reopened
+	if matches("cafe") || !matches("café") {
+		t.Error("kept diacritics should tell café and cafe apart")
+	}
+}
+
+func TestIndex_Porter(t *testing.T) {
+	testDB := setupTestSearchDB(t)
+	if _, err := testDB.Exec(`
+		INSERT INTO messages (id, conversation_id, bubble_id, type, role, content, created_at)
+		VALUES ('m1', 'conv-1', 'm1', 2, 'agent', 'Retrying the connections', ?)
+	`, time.Now()); err != nil {
+		t.Fatalf("failed to create message: %v", err)
+	}
+
+	index, err := NewIndex(testDB, nil, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewIndex() error = %v", err)
+	}
+	if _, err := index.Configure(config.SearchConfig{Tokenizer: config.SearchTokenizerPorter}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	if _, err := index.Sync(); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	match, err := index.Match("retry the connection", false)
+	if err != nil {
+		t.Fatalf("Match() error = %v", err)
+	}
+	var count int
+	if err := testDB.QueryRow("SELECT COUNT(*) FROM search_fts WHERE search_fts MATCH ?", match).Scan(&count); err != nil || count != 1 {
+		t.Errorf("stemmed search found %d, %v, want the message", count, err)
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.Local)
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, time.Local) }
+
+	tests := []struct {
+		name    string
+		query   string
+		terms   []string
+		since   time.Time
+		until   time.Time
+		wantErr bool
+	}{
+		{name: "words and phrases", query: `retry "fetch call"`, terms: []string{"retry", "fetch call"}},
+		{name: "operators", query: "(retry OR backoff) AND NOT flaky", terms: []string{"retry", "backoff"}},
+		{name: "lowercase operators are words", query: "retry or not", terms: []string{"retry", "or", "not"}},
+		{name: "fields", query: `code:"ctx.Done()" project:clio role:agent`, terms: []string{"ctx.Done()"}},
+		{name: "parentheses within a word", query: "(ctx.Done() OR select)", terms: []string{"ctx.Done()", "select"}},
+		{name: "unknown fields are text", query: "panic: http://example.com", terms: []string{"panic:", "http://example.com"}},
+		{name: "since and until", query: "retry since:7d until:2024-03-09", terms: []string{"retry"}, since: day(3).Add(12 * time.Hour), until: day(9)},
+		{name: "single date", query: "date:2024-03-05", since: day(5), until: day(6)},
+		{name: "date range", query: "retry date:2024-03-01..2024-03-05 since:2024-03-02", terms: []string{"retry"}, since: day(2), until: day(6)},
+		{name: "open date range", query: "date:2024-03-01..", since: day(1)},
+		{name: "empty", query: "  ", wantErr: true},
+		{name: "unclosed quote", query: `"retry`, wantErr: true},
+		{name: "unclosed parenthesis", query: "(retry OR backoff", wantErr: true},
+		{name: "dangling operator", query: "retry OR", wantErr: true},
+		{name: "field without value", query: "project: retry", wantErr: true},
+		{name: "time inside OR", query: "retry OR since:7d", wantErr: true},
+		{name: "time inside NOT", query: "retry NOT date:2024-03-05", wantErr: true},
+		{name: "bad date", query: "date:yesterday", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := ParseQuery(tt.query, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseQuery(%q) error = %v, wantErr %v", tt.query, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := q.Terms(); !slices.Equal(got, tt.terms) {
+				t.Errorf("Terms() = %q, want %q", got, tt.terms)
+			}
+			if !q.Since.Equal(tt.since) || !q.Until.Equal(tt.until) {
+				t.Errorf("bounds = %v..%v, want %v..%v", q.Since, q.Until, tt.since, tt.until)
+			}
+		})
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		terms []string
+		want  string
+	}{
+		{name: "words and longer forms", text: "Retrying the fetch; retry later", terms: []string{"retry"}, want: "[Retrying] the fetch; [retry] later"},
+		{name: "phrase words", text: "connection was refused", terms: []string{"connection refused"}, want: "[connection] was [refused]"},
+		{name: "inside punctuation", text: "call ctx.Done() here", terms: []string{"ctx.Done()"}, want: "call [ctx].[Done]() here"},
+		{name: "diacritics and case", text: "Ein Café", terms: []string{"CAFÉ"}, want: "Ein [Café]"},
+		{name: "no terms", text: "unchanged", want: "unchanged"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Highlight(tt.text, tt.terms, "[", "]"); got != tt.want {
+				t.Errorf("Highlight() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if !ContainsTerm("retrying now", []string{"retry"}) || ContainsTerm("fetch", []string{"retry"}) {
+		t.Error("ContainsTerm() should match words starting with a term word")
+	}
+}
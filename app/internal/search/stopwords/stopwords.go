@@ -0,0 +1,61 @@
+// Package stopwords has built-in lists of common words per language, which the
+// search index leaves out so they don't drown out the words that matter. It has
+// no dependencies, so configuration validation can check language names.
+package stopwords
+
+import "sort"
+
+// lists are the built-in stop words, lowercase, by language
+var lists = map[string][]string{
+	"english": {
+		"a", "about", "an", "and", "are", "as", "at", "be", "but", "by", "for", "from", "has", "have",
+		"i", "if", "in", "into", "is", "it", "its", "of", "on", "or", "that", "the", "their", "then",
+		"there", "these", "this", "to", "was", "we", "were", "will", "with", "you", "your",
+	},
+	"german": {
+		"aber", "als", "am", "an", "auch", "auf", "aus", "bei", "bin", "bis", "das", "dass", "dem", "den",
+		"der", "des", "die", "dies", "ein", "eine", "einem", "einen", "einer", "es", "für", "hat", "ich",
+		"im", "in", "ist", "mit", "nach", "nicht", "noch", "oder", "sich", "sie", "sind", "und", "von",
+		"war", "wir", "wird", "zu", "zum", "zur",
+	},
+	"french": {
+		"à", "au", "aux", "avec", "ce", "ces", "dans", "de", "des", "du", "elle", "en", "est", "et",
+		"il", "je", "la", "le", "les", "leur", "mais", "ne", "nous", "on", "ou", "par", "pas", "pour",
+		"qu", "que", "qui", "sa", "se", "ses", "son", "sur", "un", "une", "vous",
+	},
+	"spanish": {
+		"a", "al", "como", "con", "de", "del", "el", "en", "es", "esta", "este", "la", "las", "lo",
+		"los", "más", "no", "o", "para", "pero", "por", "que", "se", "si", "sin", "su", "sus", "un",
+		"una", "y", "ya",
+	},
+	"portuguese": {
+		"a", "ao", "as", "com", "como", "da", "das", "de", "do", "dos", "e", "é", "em", "mas", "na",
+		"nas", "no", "nos", "o", "os", "ou", "para", "pela", "pelo", "por", "que", "se", "sem", "um",
+		"uma",
+	},
+	"italian": {
+		"a", "al", "alla", "che", "con", "da", "dal", "del", "della", "di", "e", "è", "gli", "i", "il",
+		"in", "la", "le", "lo", "ma", "non", "per", "più", "se", "si", "su", "un", "una", "uno",
+	},
+	"dutch": {
+		"aan", "al", "bij", "dat", "de", "die", "dit", "een", "en", "het", "hij", "ik", "in", "is",
+		"je", "maar", "met", "naar", "niet", "of", "om", "ook", "op", "te", "van", "voor", "was",
+		"we", "wordt", "zijn",
+	},
+}
+
+// Languages returns the languages with built-in lists, sorted
+func Languages() []string {
+	languages := make([]string, 0, len(lists))
+	for language := range lists {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// Words returns the built-in stop words of a language, and false when there is no list for it
+func Words(language string) ([]string, bool) {
+	words, ok := lists[language]
+	return words, ok
+}
@@ -0,0 +1,246 @@
+// Package secrets stores API keys and tokens in the operating system keychain
+// so they never sit in plaintext configuration.
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+)
+
+const (
+	// ReferencePrefix marks a config value as a reference to a stored secret, e.g. "secret:github-token"
+	ReferencePrefix = "secret:"
+	// EnvPrefix lets CLIO_SECRET_<NAME> override a stored secret, for CI and headless machines
+	EnvPrefix = "CLIO_SECRET_"
+	// serviceName groups clio's entries in the keychain
+	serviceName = "clio"
+	// commandTimeout bounds a single keychain command
+	commandTimeout = 10 * time.Second
+)
+
+// ErrNotFound is returned when no secret is stored under a name
+var ErrNotFound = errors.New("secret not found")
+
+// namePattern restricts secret names to characters safe in keychain attributes and env var names
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// Store reads and writes named secrets
+type Store interface {
+	Get(name string) (string, error)
+	Set(name, value string) error
+	Delete(name string) error
+	// Backend names the keychain in use, for display
+	Backend() string
+}
+
+// runFunc runs a command with optional stdin and returns its stdout
+type runFunc func(ctx context.Context, stdin string, name string, args ...string) ([]byte, error)
+
+// keychainStore stores secrets through a platform keychain command-line tool
+type keychainStore struct {
+	backend string
+	run     runFunc
+	get     func(name string) []string
+	set     func(name, value string) (args []string, stdin string)
+	del     func(name string) []string
+	tool    string
+}
+
+// NewStore returns the keychain store for this platform: the macOS login
+// keychain via security(1), or the Secret Service (GNOME Keyring, KWallet)
+// via libsecret's secret-tool on Linux
+func NewStore() (Store, error) {
+	return newStore(runtime.GOOS, runCommand)
+}
+
+// newStore builds the store for goos using run to execute commands
+func newStore(goos string, run runFunc) (Store, error) {
+	switch goos {
+	case "darwin":
+		return &keychainStore{
+			backend: "macOS Keychain",
+			tool:    "security",
+			run:     run,
+			get: func(name string) []string {
+				return []string{"find-generic-password", "-s", serviceName, "-a", name, "-w"}
+			},
+			set: func(name, value string) ([]string, string) {
+				// The command is read by security's interactive mode so the value never appears in
+				// the process list; -X takes it hex-encoded, which needs no quoting. -U updates an
+				// existing item instead of failing.
+				command := fmt.Sprintf("add-generic-password -U -s %s -a %s -X %s\n", serviceName, name, hex.EncodeToString([]byte(value)))
+				return []string{"-i"}, command
+			},
+			del: func(name string) []string {
+				return []string{"delete-generic-password", "-s", serviceName, "-a", name}
+			},
+		}, nil
+	case "linux", "freebsd", "openbsd":
+		return &keychainStore{
+			backend: "Secret Service (libsecret)",
+			tool:    "secret-tool",
+			run:     run,
+			get: func(name string) []string {
+				return []string{"lookup", "service", serviceName, "name", name}
+			},
+			set: func(name, value string) ([]string, string) {
+				// secret-tool reads the value from stdin so it never appears in the process list
+				return []string{"store", "--label", "clio: " + name, "service", serviceName, "name", name}, value
+			},
+			del: func(name string) []string {
+				return []string{"clear", "service", serviceName, "name", name}
+			},
+		}, nil
+	default:
+		return nil, fmt.Errorf("no supported keychain on %s; set %s<NAME> environment variables instead", goos, EnvPrefix)
+	}
+}
+
+// Backend implements Store
+func (s *keychainStore) Backend() string {
+	return s.backend
+}
+
+// Get implements Store
+func (s *keychainStore) Get(name string) (string, error) {
+	if err := ValidateName(name); err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	out, err := s.run(ctx, "", s.tool, s.get(name)...)
+	if err != nil {
+		// Both tools exit non-zero for missing items; distinguishing other failures isn't portable
+		if isExitError(err) {
+			return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+		}
+		return "", fmt.Errorf("failed to read secret from %s: %w", s.backend, err)
+	}
+
+	value := strings.TrimSuffix(string(out), "\n")
+	if value == "" {
+		// secret-tool exits 0 with no output when nothing matches
+		return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return value, nil
+}
+
+// Set implements Store
+func (s *keychainStore) Set(name, value string) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	if value == "" {
+		return fmt.Errorf("secret value cannot be empty")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	args, stdin := s.set(name, value)
+	if _, err := s.run(ctx, stdin, s.tool, args...); err != nil {
+		return fmt.Errorf("failed to store secret in %s: %w", s.backend, err)
+	}
+	return nil
+}
+
+// Delete implements Store
+func (s *keychainStore) Delete(name string) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+
+	// Look the secret up first so a missing name is reported consistently across tools
+	if _, err := s.Get(name); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	if _, err := s.run(ctx, "", s.tool, s.del(name)...); err != nil {
+		return fmt.Errorf("failed to delete secret from %s: %w", s.backend, err)
+	}
+	return nil
+}
+
+// ValidateName checks that a secret name is usable as a keychain attribute and env var suffix
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("invalid secret name %q (use letters, digits, '.', '-' and '_')", name)
+	}
+	return nil
+}
+
+// IsReference reports whether a config value refers to a stored secret
+func IsReference(value string) bool {
+	return strings.HasPrefix(value, ReferencePrefix)
+}
+
+// Resolve returns value unchanged unless it is a secret reference, in which
+// case the named secret is returned. CLIO_SECRET_<NAME> takes precedence over
+// the keychain, with '.' and '-' in the name replaced by '_'.
+func Resolve(value string) (string, error) {
+	if !IsReference(value) {
+		return value, nil
+	}
+
+	name := strings.TrimPrefix(value, ReferencePrefix)
+	if err := ValidateName(name); err != nil {
+		return "", err
+	}
+	if envValue := os.Getenv(EnvVarName(name)); envValue != "" {
+		return envValue, nil
+	}
+
+	store, err := NewStore()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve secret %q: %w", name, err)
+	}
+	secret, err := store.Get(name)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve secret %q: %w", name, err)
+	}
+	return secret, nil
+}
+
+// EnvVarName returns the environment variable that overrides a secret
+func EnvVarName(name string) string {
+	return EnvPrefix + strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(name))
+}
+
+// runCommand runs a keychain tool, returning stdout and including stderr in errors
+func runCommand(ctx context.Context, stdin string, name string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	if stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s: %w: %s", name, err, msg)
+		}
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return out, nil
+}
+
+// isExitError reports whether err came from the tool exiting non-zero, as opposed to failing to run
+func isExitError(err error) bool {
+	var exitErr *exec.ExitError
+	return errors.As(err, &exitErr)
+}
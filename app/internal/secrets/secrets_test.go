@@ -0,0 +1,137 @@
+package secrets
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// fakeKeychain emulates secret-tool with an in-memory map
+type fakeKeychain struct {
+	items map[string]string
+	calls []string
+	stdin []string
+}
+
+func (f *fakeKeychain) run(ctx context.Context, stdin string, name string, args ...string) ([]byte, error) {
+	f.calls = append(f.calls, name+" "+strings.Join(args, " "))
+	f.stdin = append(f.stdin, stdin)
+	key := args[len(args)-1]
+
+	switch args[0] {
+	case "store":
+		f.items[key] = stdin
+	case "lookup":
+		if value, ok := f.items[key]; ok {
+			return []byte(value), nil
+		}
+	case "clear":
+		delete(f.items, key)
+	}
+	return nil, nil
+}
+
+func TestKeychainStore_SecretTool(t *testing.T) {
+	fake := &fakeKeychain{items: make(map[string]string)}
+	store, err := newStore("linux", fake.run)
+	if err != nil {
+		t.Fatalf("newStore() error = %v", err)
+	}
+
+	if err := store.Set("github-token", "ghp_abc"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	// The value is passed on stdin, never as an argument
+	if strings.Contains(fake.calls[0], "ghp_abc") || fake.stdin[0] != "ghp_abc" {
+		t.Errorf("store call = %q with stdin %q, want value on stdin only", fake.calls[0], fake.stdin[0])
+	}
+
+	value, err := store.Get("github-token")
+	if err != nil || value != "ghp_abc" {
+		t.Errorf("Get() = %q, %v, want ghp_abc", value, err)
+	}
+
+	if err := store.Delete("github-token"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := store.Get("github-token"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() after Delete error = %v, want ErrNotFound", err)
+	}
+	if err := store.Delete("github-token"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Delete() of missing secret error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestKeychainStore_SecurityMissingItem(t *testing.T) {
+	// security(1) exits non-zero when the item doesn't exist
+	run := func(ctx context.Context, stdin string, name string, args ...string) ([]byte, error) {
+		return nil, exec.Command("false").Run()
+	}
+	store, err := newStore("darwin", run)
+	if err != nil {
+		t.Fatalf("newStore() error = %v", err)
+	}
+	if _, err := store.Get("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestKeychainStore_SecuritySetKeepsValueOutOfArgs(t *testing.T) {
+	var args []string
+	var stdin string
+	run := func(ctx context.Context, in string, name string, a ...string) ([]byte, error) {
+		args = append([]string{name}, a...)
+		stdin = in
+		return nil, nil
+	}
+	store, err := newStore("darwin", run)
+	if err != nil {
+		t.Fatalf("newStore() error = %v", err)
+	}
+
+	if err := store.Set("github-token", "ghp_abc"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	// The command runs in security's interactive mode, with the value hex-encoded on stdin
+	if strings.Join(args, " ") != "security -i" {
+		t.Errorf("args = %q, want security -i", args)
+	}
+	for _, arg := range args {
+		if strings.Contains(arg, "ghp_abc") || strings.Contains(arg, hex.EncodeToString([]byte("ghp_abc"))) {
+			t.Errorf("args = %q, want the value kept out of the process list", args)
+		}
+	}
+	want := "add-generic-password -U -s clio -a github-token -X " + hex.EncodeToString([]byte("ghp_abc")) + "\n"
+	if stdin != want {
+		t.Errorf("stdin = %q, want %q", stdin, want)
+	}
+}
+
+func TestNewStore_Unsupported(t *testing.T) {
+	if _, err := newStore("plan9", runCommand); err == nil {
+		t.Error("newStore() on an unsupported platform should fail")
+	}
+}
+
+func TestResolve(t *testing.T) {
+	t.Setenv("CLIO_SECRET_WEBHOOK_KEY", "from-env")
+
+	if got, err := Resolve("https://example.com/hook"); err != nil || got != "https://example.com/hook" {
+		t.Errorf("Resolve(plain) = %q, %v, want unchanged", got, err)
+	}
+	if got, err := Resolve("secret:webhook-key"); err != nil || got != "from-env" {
+		t.Errorf("Resolve(reference) = %q, %v, want env override", got, err)
+	}
+	if _, err := Resolve("secret:bad name"); err == nil {
+		t.Error("Resolve() with an invalid name should fail")
+	}
+}
+
+func TestEnvVarName(t *testing.T) {
+	if got := EnvVarName("openai.api-key"); got != "CLIO_SECRET_OPENAI_API_KEY" {
+		t.Errorf("EnvVarName() = %q", got)
+	}
+}
@@ -0,0 +1,270 @@
+// Package sensitive classifies captured content into configured sensitive
+// categories, such as PII or client names, before it's stored. Categories are
+// found with regular expressions and, optionally, an external classifier
+// command such as a script asking a local model. Each category's action
+// decides what happens to content containing it: store it and flag it, redact
+// the sensitive parts, or drop it.
+package sensitive
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+const (
+	// ActionStore stores content as captured and flags it
+	ActionStore = "store"
+	// ActionRedact replaces the sensitive parts of content before it's stored
+	ActionRedact = "redact"
+	// ActionDrop keeps content out of the database
+	ActionDrop = "drop"
+
+	// maxClassifierOutput caps what the classifier command may print
+	maxClassifierOutput = 64 * 1024
+	// maxCachedVerdicts caps the classifier results kept, so content captured
+	// again, such as a conversation re-read whole, doesn't run the command again
+	maxCachedVerdicts = 1024
+)
+
+// severity orders actions from least to most strict
+var severity = map[string]int{
+	ActionStore:  1,
+	ActionRedact: 2,
+	ActionDrop:   3,
+}
+
+// Category is a configured sensitive category
+type Category struct {
+	Name     string
+	Action   string
+	patterns []*regexp.Regexp
+}
+
+// Decision is what the gate found in content and what to do with it
+type Decision struct {
+	Categories []Category // Categories found, in configuration order
+	Action     string     // The strictest action of the categories found; empty when none were
+}
+
+// Gate classifies content into sensitive categories
+type Gate struct {
+	categories []Category
+	command    string
+	timeout    time.Duration
+	logger     logging.Logger
+
+	mu       sync.Mutex
+	verdicts map[[sha256.Size]byte][]string // Classifier results by input
+}
+
+// NewGate creates a gate for the configured categories. It returns nil when no
+// categories are configured, in which case content is stored as captured.
+func NewGate(cfg config.SensitiveConfig, logger logging.Logger) (*Gate, error) {
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+	if len(cfg.Categories) == 0 {
+		return nil, nil
+	}
+
+	categories := make([]Category, 0, len(cfg.Categories))
+	for _, c := range cfg.Categories {
+		if severity[c.Action] == 0 {
+			return nil, fmt.Errorf("category %q: unknown action %q", c.Name, c.Action)
+		}
+		category := Category{Name: c.Name, Action: c.Action}
+		for _, pattern := range c.Patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("category %q: invalid pattern: %w", c.Name, err)
+			}
+			category.patterns = append(category.patterns, re)
+		}
+		categories = append(categories, category)
+	}
+
+	return &Gate{
+		categories: categories,
+		command:    cfg.ClassifierCommand,
+		timeout:    time.Duration(cfg.ClassifierTimeoutSeconds) * time.Second,
+		logger:     logger.With("component", "sensitive"),
+		verdicts:   make(map[[sha256.Size]byte][]string),
+	}, nil
+}
+
+// Check classifies the fields of one piece of content together, such as a
+// message's text and code blocks, and returns the decision with the fields to
+// store. Fields are returned unchanged unless the decision is ActionRedact, in
+// which case pattern matches of redacted categories are replaced with
+// [REDACTED:<category>]; a redacted category only the classifier command found
+// replaces each non-empty field whole, since it can't say where it is.
+func (g *Gate) Check(fields []string) (Decision, []string) {
+	found := make(map[string]bool)
+	located := make(map[string]bool) // Categories a pattern found
+	for _, category := range g.categories {
+		for _, re := range category.patterns {
+			for _, field := range fields {
+				if re.MatchString(field) {
+					found[category.Name], located[category.Name] = true, true
+				}
+			}
+		}
+	}
+	for _, name := range g.classify(fields) {
+		found[name] = true
+	}
+
+	var decision Decision
+	for _, category := range g.categories {
+		if !found[category.Name] {
+			continue
+		}
+		decision.Categories = append(decision.Categories, category)
+		if severity[category.Action] > severity[decision.Action] {
+			decision.Action = category.Action
+		}
+	}
+	if decision.Action != ActionRedact {
+		return decision, fields
+	}
+
+	redacted := append([]string(nil), fields...)
+	for _, category := range decision.Categories {
+		if category.Action != ActionRedact {
+			continue
+		}
+		placeholder := "[REDACTED:" + category.Name + "]"
+		for i := range redacted {
+			if !located[category.Name] {
+				if redacted[i] != "" {
+					redacted[i] = placeholder
+				}
+				continue
+			}
+			for _, re := range category.patterns {
+				redacted[i] = re.ReplaceAllLiteralString(redacted[i], placeholder)
+			}
+		}
+	}
+	return decision, redacted
+}
+
+// classify runs the classifier command on the fields and returns the known
+// categories it printed. Failures are logged and leave the patterns to decide.
+func (g *Gate) classify(fields []string) []string {
+	if g.command == "" {
+		return nil
+	}
+	input := strings.Join(fields, "\n\n")
+	if strings.TrimSpace(input) == "" {
+		return nil
+	}
+
+	key := sha256.Sum256([]byte(input))
+	g.mu.Lock()
+	names, cached := g.verdicts[key]
+	g.mu.Unlock()
+	if cached {
+		return names
+	}
+
+	names, err := g.runClassifier(input)
+	if err != nil {
+		g.logger.Warn("sensitive classifier failed, using patterns only", "command", g.command, "error", err)
+		return nil
+	}
+
+	g.mu.Lock()
+	if len(g.verdicts) >= maxCachedVerdicts {
+		g.verdicts = make(map[[sha256.Size]byte][]string)
+	}
+	g.verdicts[key] = names
+	g.mu.Unlock()
+	return names
+}
+
+// runClassifier runs the classifier command with the content on stdin. It
+// prints the categories it found, one per line; names that aren't configured
+// are ignored.
+func (g *Gate) runClassifier(input string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), g.timeout)
+	defer cancel()
+
+	names := make([]string, len(g.categories))
+	for i, category := range g.categories {
+		names[i] = category.Name
+	}
+
+	cmd := exec.CommandContext(ctx, g.command)
+	cmd.Stdin = strings.NewReader(input)
+	cmd.Env = append(os.Environ(), "CLIO_SENSITIVE_CATEGORIES="+strings.Join(names, ","))
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("classifier command failed: %w", err)
+	}
+	if stdout.Len() > maxClassifierOutput {
+		return nil, fmt.Errorf("classifier command printed more than %d bytes", maxClassifierOutput)
+	}
+
+	known := make(map[string]bool, len(names))
+	for _, name := range names {
+		known[name] = true
+	}
+	var found []string
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		if name := strings.TrimSpace(line); known[name] {
+			found = append(found, name)
+		} else if name != "" {
+			g.logger.Debug("ignoring unknown category from classifier", "category", name)
+		}
+	}
+	return found, nil
+}
+
+// CountFlags counts the messages the gate flagged, by the action taken
+func CountFlags(db *sql.DB) (map[string]int, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database cannot be nil")
+	}
+
+	// A message is counted once, under the strictest action taken on it
+	rows, err := db.Query("SELECT message_id, action FROM sensitive_flags")
+	if err != nil {
+		return nil, fmt.Errorf("failed to count sensitive flags: %w", err)
+	}
+	defer rows.Close()
+
+	actions := make(map[string]string)
+	for rows.Next() {
+		var messageID, action string
+		if err := rows.Scan(&messageID, &action); err != nil {
+			return nil, fmt.Errorf("failed to scan sensitive flag: %w", err)
+		}
+		if severity[action] > severity[actions[messageID]] {
+			actions[messageID] = action
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sensitive flags: %w", err)
+	}
+
+	counts := make(map[string]int)
+	for _, action := range actions {
+		counts[action]++
+	}
+	return counts, nil
+}
@@ -0,0 +1,94 @@
+package sensitive
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+func newTestGate(t *testing.T, cfg config.SensitiveConfig) *Gate {
+	t.Helper()
+	if cfg.ClassifierTimeoutSeconds == 0 {
+		cfg.ClassifierTimeoutSeconds = 5
+	}
+	gate, err := NewGate(cfg, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewGate() error = %v", err)
+	}
+	return gate
+}
+
+func TestNewGate_NoCategories(t *testing.T) {
+	gate, err := NewGate(config.SensitiveConfig{}, logging.NewNoopLogger())
+	if err != nil || gate != nil {
+		t.Errorf("NewGate() = %v, %v; want nil without categories", gate, err)
+	}
+}
+
+func TestGate_Check(t *testing.T) {
+	gate := newTestGate(t, config.SensitiveConfig{Categories: []config.SensitiveCategory{
+		{Name: "email", Patterns: []string{`[\w.+-]+@[\w-]+\.\w+`}, Action: ActionRedact},
+		{Name: "todo", Patterns: []string{`TODO`}, Action: ActionStore},
+		{Name: "client", Patterns: []string{`(?i)acme corp`}, Action: ActionDrop},
+	}})
+
+	decision, fields := gate.Check([]string{"nothing to see"})
+	if decision.Action != "" || len(decision.Categories) != 0 {
+		t.Errorf("Check() of plain text = %+v, want no categories", decision)
+	}
+
+	decision, fields = gate.Check([]string{"mail dev@example.com", "TODO: ask ops@example.com"})
+	if decision.Action != ActionRedact || len(decision.Categories) != 2 {
+		t.Errorf("Check() = %+v, want email and todo, redacted", decision)
+	}
+	if want := []string{"mail [REDACTED:email]", "TODO: ask [REDACTED:email]"}; !reflect.DeepEqual(fields, want) {
+		t.Errorf("redacted fields = %q, want %q", fields, want)
+	}
+
+	decision, fields = gate.Check([]string{"the ACME Corp rollout", "dev@example.com"})
+	if decision.Action != ActionDrop || fields[1] != "dev@example.com" {
+		t.Errorf("Check() = %+v, %q; want drop with fields untouched", decision, fields)
+	}
+}
+
+func TestGate_ClassifierCommand(t *testing.T) {
+	dir := t.TempDir()
+	command := filepath.Join(dir, "classify.sh")
+	count := filepath.Join(dir, "count")
+	script := "#!/bin/sh\necho run >> " + count + "\nif grep -q Jane; then echo pii; echo unknown; fi\n"
+	if err := os.WriteFile(command, []byte(script), 0755); err != nil {
+		t.Fatalf("failed to write classifier: %v", err)
+	}
+
+	gate := newTestGate(t, config.SensitiveConfig{
+		Categories:        []config.SensitiveCategory{{Name: "pii", Action: ActionRedact}},
+		ClassifierCommand: command,
+	})
+
+	decision, fields := gate.Check([]string{"Jane Doe called", "", "see notes"})
+	if decision.Action != ActionRedact {
+		t.Fatalf("Check() = %+v, want pii from the classifier", decision)
+	}
+	if want := []string{"[REDACTED:pii]", "", "[REDACTED:pii]"}; !reflect.DeepEqual(fields, want) {
+		t.Errorf("redacted fields = %q, want %q", fields, want)
+	}
+
+	// The same content isn't classified twice
+	gate.Check([]string{"Jane Doe called", "", "see notes"})
+	runs, err := os.ReadFile(count)
+	if err != nil || string(runs) != "run\n" {
+		t.Errorf("classifier runs = %q, %v; want one", runs, err)
+	}
+
+	// A failing classifier leaves the patterns to decide
+	if err := os.WriteFile(command, []byte("#!/bin/sh\nexit 1\n"), 0755); err != nil {
+		t.Fatalf("failed to write classifier: %v", err)
+	}
+	if decision, _ := gate.Check([]string{"Jane again"}); decision.Action != "" {
+		t.Errorf("Check() with a failing classifier = %+v, want nothing found", decision)
+	}
+}
@@ -0,0 +1,235 @@
+// Package share mints expiring, token-protected links to one session, so a
+// teammate on the LAN can read it in a browser without access to the rest of
+// the database. The daemon serves links on the share listener; the token in a
+// link's URL is its only credential.
+package share
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+const (
+	// PathPrefix is the URL path links are served under, followed by the token
+	PathPrefix = "/share/"
+	// tokenBytes is the random bytes in a token
+	tokenBytes = 24
+)
+
+// ErrInvalidLink is returned for tokens that don't name an active link. It
+// doesn't say whether a link expired, was revoked, or never existed.
+var ErrInvalidLink = errors.New("share link is invalid, expired, or revoked")
+
+// Link is a read-only link to one session
+type Link struct {
+	ID        int64
+	SessionID string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	RevokedAt *time.Time // Set once revoked
+	Token     string     // Only set on links returned by Create; stored hashed
+
+	Project      string    // The session's project
+	SessionStart time.Time // The session's start
+}
+
+// IsActive reports whether the link can be opened at now
+func (l Link) IsActive(now time.Time) bool {
+	return l.RevokedAt == nil && now.Before(l.ExpiresAt)
+}
+
+// Store defines the interface for minting and checking share links
+type Store interface {
+	// Create mints a link to the session that expires at expiresAt
+	Create(sessionID string, expiresAt, now time.Time) (*Link, error)
+	// Resolve returns the active link the token names, or ErrInvalidLink
+	Resolve(token string, now time.Time) (*Link, error)
+	// List returns active links, or all with all set, newest first
+	List(all bool, now time.Time) ([]Link, error)
+	// Revoke makes a link unusable before it expires
+	Revoke(id int64, at time.Time) error
+}
+
+// store implements Store on top of the clio database
+type store struct {
+	db     *sql.DB
+	logger logging.Logger
+}
+
+// NewStore creates a share link store backed by the database
+func NewStore(db *sql.DB, logger logging.Logger) (Store, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database cannot be nil")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	return &store{
+		db:     db,
+		logger: logger.With("component", "share"),
+	}, nil
+}
+
+// Create mints a link with a random token
+func (s *store) Create(sessionID string, expiresAt, now time.Time) (*Link, error) {
+	if !expiresAt.After(now) {
+		return nil, fmt.Errorf("share link must expire in the future")
+	}
+
+	link := Link{SessionID: sessionID, CreatedAt: now, ExpiresAt: expiresAt}
+	err := s.db.QueryRow("SELECT project, start_time FROM sessions WHERE id = ?", sessionID).Scan(&link.Project, &link.SessionStart)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("session %s not found", sessionID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up session: %w", err)
+	}
+
+	raw := make([]byte, tokenBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("failed to generate share token: %w", err)
+	}
+	link.Token = base64.RawURLEncoding.EncodeToString(raw)
+
+	result, err := s.db.Exec(`
+		INSERT INTO share_links (token_hash, session_id, created_at, expires_at)
+		VALUES (?, ?, ?, ?)
+	`, hashToken(link.Token), sessionID, now, expiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store share link: %w", err)
+	}
+	if link.ID, err = result.LastInsertId(); err != nil {
+		return nil, fmt.Errorf("failed to get share link ID: %w", err)
+	}
+
+	s.logger.Debug("created share link", "id", link.ID, "session_id", sessionID, "expires_at", expiresAt)
+	return &link, nil
+}
+
+// Resolve returns the active link the token names
+func (s *store) Resolve(token string, now time.Time) (*Link, error) {
+	if token == "" {
+		return nil, ErrInvalidLink
+	}
+	links, err := s.query(selectLinks+" WHERE l.token_hash = ?", hashToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if len(links) == 0 || !links[0].IsActive(now) {
+		return nil, ErrInvalidLink
+	}
+	return &links[0], nil
+}
+
+// List returns active links, or all of them
+func (s *store) List(all bool, now time.Time) ([]Link, error) {
+	links, err := s.query(selectLinks)
+	if err != nil {
+		return nil, err
+	}
+	if all {
+		return links, nil
+	}
+
+	var active []Link
+	for _, link := range links {
+		if link.IsActive(now) {
+			active = append(active, link)
+		}
+	}
+	return active, nil
+}
+
+// Revoke makes a link unusable
+func (s *store) Revoke(id int64, at time.Time) error {
+	result, err := s.db.Exec("UPDATE share_links SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL", at, id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke share link: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to revoke share link: %w", err)
+	} else if n == 0 {
+		return fmt.Errorf("no unrevoked share link %d", id)
+	}
+	return nil
+}
+
+// selectLinks selects links with their session's project and start
+const selectLinks = `
+	SELECT l.id, l.session_id, l.created_at, l.expires_at, l.revoked_at,
+		COALESCE(s.project, ''), s.start_time
+	FROM share_links l
+	LEFT JOIN sessions s ON s.id = l.session_id`
+
+// query runs a share links query and sorts the results newest first
+func (s *store) query(query string, args ...any) ([]Link, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query share links: %w", err)
+	}
+	defer rows.Close()
+
+	var links []Link
+	for rows.Next() {
+		var link Link
+		var revokedAt, sessionStart sql.NullTime
+		if err := rows.Scan(&link.ID, &link.SessionID, &link.CreatedAt, &link.ExpiresAt, &revokedAt,
+			&link.Project, &sessionStart); err != nil {
+			return nil, fmt.Errorf("failed to scan share link: %w", err)
+		}
+		if revokedAt.Valid {
+			link.RevokedAt = &revokedAt.Time
+		}
+		link.SessionStart = sessionStart.Time
+		links = append(links, link)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating share links: %w", err)
+	}
+
+	db.SortNewestFirst(links, func(l Link) time.Time { return l.CreatedAt })
+	return links, nil
+}
+
+// hashToken returns the hex SHA-256 of a token, as stored
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// URL returns the address a link's token is served at: share.url when set,
+// otherwise the listen address with the machine's hostname for an unspecified
+// host
+func URL(cfg config.ShareConfig, token string) (string, error) {
+	if cfg.URL != "" {
+		return strings.TrimSuffix(cfg.URL, "/") + PathPrefix + token, nil
+	}
+	if cfg.Listen == "" {
+		return "", fmt.Errorf("share.listen isn't set, so the daemon doesn't serve share links")
+	}
+
+	host, port, err := net.SplitHostPort(cfg.Listen)
+	if err != nil {
+		return "", fmt.Errorf("invalid share.listen %q: %w", cfg.Listen, err)
+	}
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		if host, err = os.Hostname(); err != nil {
+			return "", fmt.Errorf("failed to get hostname for share URL; set share.url: %w", err)
+		}
+	}
+	return "http://" + net.JoinHostPort(host, port) + PathPrefix + token, nil
+}
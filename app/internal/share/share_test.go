@@ -0,0 +1,117 @@
+package share
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/logging"
+	_ "modernc.org/sqlite"
+)
+
+func setupTestStore(t *testing.T) (*sql.DB, Store) {
+	database, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "clio.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if err := db.RunMigrations(database); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	store, err := NewStore(database, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	return database, store
+}
+
+func TestStore_CreateResolveRevoke(t *testing.T) {
+	database, store := setupTestStore(t)
+	now := time.Date(2024, 5, 1, 9, 0, 0, 0, time.Local)
+	if _, err := database.Exec(`
+		INSERT INTO sessions (id, project, start_time, last_activity, created_at, updated_at)
+		VALUES ('s1', 'clio', ?, ?, ?, ?)
+	`, now, now, now, now); err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+
+	link, err := store.Create("s1", now.Add(24*time.Hour), now)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if link.Token == "" || link.Project != "clio" {
+		t.Errorf("created = %+v, want a token and the session's project", link)
+	}
+	var stored string
+	if err := database.QueryRow("SELECT token_hash FROM share_links WHERE id = ?", link.ID).Scan(&stored); err != nil || stored == link.Token {
+		t.Errorf("stored token = %q, %v; want a hash of the token", stored, err)
+	}
+	if _, err := store.Create("missing", now.Add(time.Hour), now); err == nil {
+		t.Error("Create() with an unknown session should fail")
+	}
+	if _, err := store.Create("s1", now, now); err == nil {
+		t.Error("Create() expiring now should fail")
+	}
+
+	resolved, err := store.Resolve(link.Token, now.Add(time.Hour))
+	if err != nil || resolved.SessionID != "s1" || resolved.Token != "" {
+		t.Errorf("Resolve() = %+v, %v; want session s1 without the token", resolved, err)
+	}
+	for name, token := range map[string]string{"unknown": "nope", "empty": ""} {
+		if _, err := store.Resolve(token, now); !errors.Is(err, ErrInvalidLink) {
+			t.Errorf("Resolve(%s) error = %v, want ErrInvalidLink", name, err)
+		}
+	}
+	if _, err := store.Resolve(link.Token, now.Add(24*time.Hour)); !errors.Is(err, ErrInvalidLink) {
+		t.Errorf("Resolve() after expiry error = %v, want ErrInvalidLink", err)
+	}
+
+	if err := store.Revoke(link.ID, now.Add(time.Minute)); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+	if err := store.Revoke(link.ID, now.Add(time.Minute)); err == nil {
+		t.Error("Revoke() of a revoked link should fail")
+	}
+	if _, err := store.Resolve(link.Token, now.Add(time.Hour)); !errors.Is(err, ErrInvalidLink) {
+		t.Errorf("Resolve() after revoke error = %v, want ErrInvalidLink", err)
+	}
+
+	if active, err := store.List(false, now.Add(time.Hour)); err != nil || len(active) != 0 {
+		t.Errorf("List(false) = %+v, %v; want none", active, err)
+	}
+	if all, err := store.List(true, now.Add(time.Hour)); err != nil || len(all) != 1 || all[0].RevokedAt == nil {
+		t.Errorf("List(true) = %+v, %v; want the revoked link", all, err)
+	}
+}
+
+func TestURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.ShareConfig
+		want    string
+		wantErr bool
+	}{
+		{name: "base url", cfg: config.ShareConfig{Listen: ":7070", URL: "https://clio.lan/"}, want: "https://clio.lan/share/tok"},
+		{name: "listen host", cfg: config.ShareConfig{Listen: "192.168.1.5:7070"}, want: "http://192.168.1.5:7070/share/tok"},
+		{name: "unspecified host", cfg: config.ShareConfig{Listen: "0.0.0.0:7070"}, want: ":7070/share/tok"},
+		{name: "disabled", cfg: config.ShareConfig{}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := URL(tt.cfg, "tok")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("URL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !strings.HasSuffix(got, tt.want) || (strings.HasPrefix(tt.want, "http") && got != tt.want) {
+				t.Errorf("URL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
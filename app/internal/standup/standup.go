@@ -0,0 +1,273 @@
+// Package standup drafts yesterday/today/blockers standup messages from captured
+// sessions, commits, test runs, journal notes, and goals.
+package standup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/goals"
+	"github.com/stwalsh4118/clio/internal/pins"
+	"github.com/stwalsh4118/clio/internal/quality"
+	"github.com/stwalsh4118/clio/internal/recap"
+	"github.com/stwalsh4118/clio/pkg/export"
+)
+
+const (
+	// DefaultTemplate renders a standup as Slack mrkdwn
+	DefaultTemplate = `*Yesterday*
+{{- range .Yesterday}}
+• *{{.Project}}* ({{duration .Duration}})
+{{- range .Commits}}
+    ◦ {{.}}
+{{- end}}
+{{- range .Topics}}
+    ◦ {{.}}
+{{- end}}
+{{- else}}
+• Nothing captured
+{{- end}}
+{{- if .Pinned}}
+*Pinned*
+{{- range .Pinned}}
+• {{.}}
+{{- end}}
+{{- end}}
+*Today*
+{{- range .Today}}
+• {{.}}
+{{- else}}
+• Nothing planned yet
+{{- end}}
+*Blockers*
+{{- range .Blockers}}
+• {{.}}
+{{- else}}
+• None
+{{- end}}
+`
+
+	// goalHorizon is how soon an open goal must be due to be listed under today
+	goalHorizon = 7 * 24 * time.Hour
+	// maxFailedTestsListed bounds the failing test names listed in a blocker
+	maxFailedTestsListed = 3
+	// maxPhraseOutput bounds how much a phrase command may print
+	maxPhraseOutput = 64 * 1024
+)
+
+// blockerPattern matches journal notes describing a blocker
+var blockerPattern = regexp.MustCompile(`(?i)\b(blocked|blocker|blocking|stuck|waiting (on|for))\b`)
+
+// ProjectWork is the work captured in one project since the standup period began
+type ProjectWork struct {
+	Project  string
+	Duration time.Duration // Total duration of the project's sessions, less the time the machine slept
+	Commits  []string      // Commit subjects, oldest first
+	Topics   []string      // Conversation names, or opening prompts when unnamed, oldest first
+}
+
+// Standup is the content of a standup message
+type Standup struct {
+	Date      time.Time
+	Since     time.Time
+	Yesterday []ProjectWork // Most time first
+	Pinned    []string      // Sessions and conversations pinned since the period began, most recent first
+	Today     []string
+	Blockers  []string
+}
+
+// PreviousWorkday returns local midnight of the working day before now, so a
+// Monday standup covers Friday onwards
+func PreviousWorkday(now time.Time) time.Time {
+	now = now.Local()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local).AddDate(0, 0, -1)
+	for day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+		day = day.AddDate(0, 0, -1)
+	}
+	return day
+}
+
+// Build drafts a standup from the sessions captured since since, the open goals'
+// progress, and the pins. Yesterday lists each project's commits and conversation
+// topics; pinned lists what was pinned since the period began; today lists
+// conversations left unresolved and goals due within a week or worked on;
+// blockers are failing test runs, journal notes mentioning a blocker, and
+// conversations abandoned after an error.
+func Build(data *export.Data, openGoals []goals.Progress, pinned []pins.Pin, since, now time.Time) *Standup {
+	s := &Standup{Date: now, Since: since}
+	for _, pin := range pinned {
+		if !pin.PinnedAt.Before(since) && !pin.Missing {
+			s.Pinned = append(s.Pinned, pinnedItem(pin))
+		}
+	}
+	projects := make(map[string]*ProjectWork)
+	latestRuns := make(map[string]export.TestRun)
+	var unresolved []string
+
+	sessions := append([]export.Session(nil), data.Sessions...)
+	sort.SliceStable(sessions, func(i, j int) bool { return sessions[i].StartTime.Before(sessions[j].StartTime) })
+	for _, session := range sessions {
+		work := projects[session.Project]
+		if work == nil {
+			work = &ProjectWork{Project: session.Project}
+			projects[session.Project] = work
+		}
+		work.Duration += session.ActiveDuration(data.IdleGaps, now)
+
+		for _, commit := range session.Commits {
+			if commit.Timestamp.Before(since) {
+				continue
+			}
+			if subject := recap.CommitSubject(commit.Message); subject != "" && !slices.Contains(work.Commits, subject) {
+				work.Commits = append(work.Commits, subject)
+			}
+		}
+
+		for _, conversation := range session.Conversations {
+			topic := recap.ConversationTopic(conversation)
+			if topic == "" {
+				continue
+			}
+			if !slices.Contains(work.Topics, topic) {
+				work.Topics = append(work.Topics, topic)
+			}
+
+			metrics := quality.Analyze(recap.QualityMessages(conversation), now, quality.DefaultIdleTimeout)
+			switch {
+			case metrics.Status == quality.StatusAbandoned && metrics.ErrorMentions > 0:
+				s.Blockers = append(s.Blockers, fmt.Sprintf("Stuck on %s (%s)", topic, session.Project))
+			case metrics.Status != quality.StatusResolved:
+				unresolved = append(unresolved, fmt.Sprintf("Continue %s (%s)", topic, session.Project))
+			}
+		}
+
+		for _, run := range session.TestRuns {
+			if latest, ok := latestRuns[session.Project]; !ok || run.RunTime.After(latest.RunTime) {
+				latestRuns[session.Project] = run
+			}
+		}
+		for _, note := range session.Journal {
+			if blockerPattern.MatchString(note.Text) {
+				s.Blockers = append(s.Blockers, strings.Join(strings.Fields(note.Text), " "))
+			}
+		}
+	}
+
+	for _, work := range projects {
+		if len(work.Commits) == 0 && len(work.Topics) == 0 {
+			continue
+		}
+		s.Yesterday = append(s.Yesterday, *work)
+	}
+	sort.Slice(s.Yesterday, func(i, j int) bool {
+		if s.Yesterday[i].Duration != s.Yesterday[j].Duration {
+			return s.Yesterday[i].Duration > s.Yesterday[j].Duration
+		}
+		return s.Yesterday[i].Project < s.Yesterday[j].Project
+	})
+
+	s.Today = append(s.Today, unresolved...)
+	for _, goal := range openGoals {
+		dueSoon := !goal.Due.IsZero() && goal.Due.Sub(now) <= goalHorizon
+		if !dueSoon && goal.LastActivity.Before(since) {
+			continue
+		}
+		item := "Work towards " + goal.Title
+		if !goal.Due.IsZero() {
+			item += " (due " + goal.Due.Format("Mon Jan 2") + ")"
+		}
+		s.Today = append(s.Today, item)
+	}
+
+	runProjects := make([]string, 0, len(latestRuns))
+	for project := range latestRuns {
+		runProjects = append(runProjects, project)
+	}
+	sort.Strings(runProjects)
+	for _, project := range runProjects {
+		run := latestRuns[project]
+		if run.Failed == 0 {
+			continue
+		}
+		blocker := fmt.Sprintf("%d failing test(s) in %s", run.Failed, project)
+		if len(run.FailedTests) > 0 {
+			names := run.FailedTests
+			if len(names) > maxFailedTestsListed {
+				names = append(names[:maxFailedTestsListed:maxFailedTestsListed], "...")
+			}
+			blocker += ": " + strings.Join(names, ", ")
+		}
+		s.Blockers = append(s.Blockers, blocker)
+	}
+
+	return s
+}
+
+// Render formats a standup with a Go text/template; an empty template uses
+// DefaultTemplate. Templates can call duration to format a time.Duration.
+func Render(s *Standup, text string) (string, error) {
+	if strings.TrimSpace(text) == "" {
+		text = DefaultTemplate
+	}
+	tmpl, err := template.New("standup").Funcs(template.FuncMap{"duration": recap.FormatDuration}).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse standup template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, s); err != nil {
+		return "", fmt.Errorf("failed to render standup template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// Phrase rewrites a rendered standup with an external command, such as a script
+// that asks an LLM to smooth the wording. The standup is written to the command's
+// stdin and its stdout replaces it.
+func Phrase(ctx context.Context, command, text string) (string, error) {
+	cmd := exec.CommandContext(ctx, command)
+	cmd.Stdin = strings.NewReader(text)
+	cmd.Stderr = os.Stderr
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("phrase command failed: %w", err)
+	}
+	if stdout.Len() > maxPhraseOutput {
+		return "", fmt.Errorf("phrase command printed more than %d bytes", maxPhraseOutput)
+	}
+	phrased := strings.TrimSpace(stdout.String())
+	if phrased == "" {
+		return "", fmt.Errorf("phrase command printed nothing")
+	}
+	return phrased + "\n", nil
+}
+
+// pinnedItem describes a pin by its conversation's name, or when the session
+// started, with its project and note
+func pinnedItem(pin pins.Pin) string {
+	item := pin.Name
+	switch {
+	case pin.Kind == pins.KindSession:
+		item = "Session on " + pin.Start.Local().Format("Mon Jan 2 15:04")
+	case item == "":
+		item = "Conversation " + pin.TargetID
+	}
+	if pin.Project != "" {
+		item += " (" + pin.Project + ")"
+	}
+	if pin.Note != "" {
+		item += ": " + pin.Note
+	}
+	return item
+}
@@ -0,0 +1,139 @@
+package standup
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/goals"
+	"github.com/stwalsh4118/clio/internal/pins"
+	"github.com/stwalsh4118/clio/pkg/export"
+)
+
+func TestPreviousWorkday(t *testing.T) {
+	tests := []struct {
+		now  time.Time
+		want time.Time
+	}{
+		{time.Date(2024, 5, 15, 9, 30, 0, 0, time.Local), time.Date(2024, 5, 14, 0, 0, 0, 0, time.Local)}, // Wednesday
+		{time.Date(2024, 5, 13, 9, 30, 0, 0, time.Local), time.Date(2024, 5, 10, 0, 0, 0, 0, time.Local)}, // Monday covers Friday
+		{time.Date(2024, 5, 12, 9, 30, 0, 0, time.Local), time.Date(2024, 5, 10, 0, 0, 0, 0, time.Local)}, // Sunday
+	}
+	for _, tt := range tests {
+		if got := PreviousWorkday(tt.now); !got.Equal(tt.want) {
+			t.Errorf("PreviousWorkday(%v) = %v, want %v", tt.now, got, tt.want)
+		}
+	}
+}
+
+func TestBuildAndRender(t *testing.T) {
+	since := time.Date(2024, 5, 14, 0, 0, 0, 0, time.Local)
+	now := since.Add(36 * time.Hour) // Wednesday noon
+	start := since.Add(9 * time.Hour)
+	end := start.Add(2 * time.Hour)
+	message := func(role, text string, minutes int) export.Message {
+		return export.Message{Role: role, Text: text, CreatedAt: start.Add(time.Duration(minutes) * time.Minute)}
+	}
+
+	data := &export.Data{Sessions: []export.Session{{
+		Project:   "clio",
+		StartTime: start,
+		EndTime:   &end,
+		Conversations: []export.Conversation{
+			{Name: "Add standup", Messages: []export.Message{
+				message("user", "add a standup command", 0), message("agent", "added", 1), message("user", "thanks", 2),
+			}},
+			{Messages: []export.Message{
+				message("user", "why does   the importer hang?", 10), message("agent", "it waits on a lock", 11),
+				message("user", "still failing with error: deadlock", 12), message("agent", "try this", 13),
+			}},
+		},
+		Commits: []export.Commit{
+			{Message: "Old work", Timestamp: since.Add(-time.Hour)},
+			{Message: "Add standup command\n\nDetails", Timestamp: start.Add(30 * time.Minute)},
+		},
+		TestRuns: []export.TestRun{
+			{RunTime: start.Add(20 * time.Minute), Failed: 1, FailedTests: []string{"TestOld"}},
+			{RunTime: start.Add(40 * time.Minute), Failed: 4, FailedTests: []string{"TestA", "TestB", "TestC", "TestD"}},
+		},
+		Journal: []export.JournalEntry{
+			{Text: "Blocked on API keys from ops"},
+			{Text: "Chose a template approach"},
+		},
+	}}}
+	openGoals := []goals.Progress{
+		{Goal: goals.Goal{Title: "Ship v1 importer", Due: since.AddDate(0, 0, 5)}},
+		{Goal: goals.Goal{Title: "Someday", Due: since.AddDate(0, 2, 0)}},
+	}
+
+	pinned := []pins.Pin{
+		{Kind: pins.KindConversation, TargetID: "c1", Name: "Lexer fix", Project: "clio", Note: "great debugging", PinnedAt: start},
+		{Kind: pins.KindSession, TargetID: "s0", Project: "clio", PinnedAt: since.Add(-time.Hour)},
+	}
+
+	s := Build(data, openGoals, pinned, since, now)
+	if len(s.Yesterday) != 1 || s.Yesterday[0].Duration != 2*time.Hour {
+		t.Fatalf("yesterday = %+v, want clio with 2h", s.Yesterday)
+	}
+	work := s.Yesterday[0]
+	if len(work.Commits) != 1 || work.Commits[0] != "Add standup command" {
+		t.Errorf("commits = %v, want only the subject of the commit since the period began", work.Commits)
+	}
+	if len(work.Topics) != 2 || work.Topics[1] != "why does the importer hang?" {
+		t.Errorf("topics = %v, want the name and the unnamed conversation's opening prompt", work.Topics)
+	}
+	if len(s.Today) != 1 || !strings.HasPrefix(s.Today[0], "Work towards Ship v1 importer (due ") {
+		t.Errorf("today = %v, want only the goal due this week", s.Today)
+	}
+	wantBlockers := []string{
+		"Stuck on why does the importer hang? (clio)",
+		"Blocked on API keys from ops",
+		"4 failing test(s) in clio: TestA, TestB, TestC, ...",
+	}
+	if strings.Join(s.Blockers, "|") != strings.Join(wantBlockers, "|") {
+		t.Errorf("blockers = %q, want %q", s.Blockers, wantBlockers)
+	}
+
+	text, err := Render(s, "")
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	for _, want := range []string{"*Yesterday*\n• *clio* (2h00m)\n    ◦ Add standup command", "*Pinned*\n• Lexer fix (clio): great debugging\n*Today*", "*Blockers*\n• Stuck on"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("rendered standup missing %q:\n%s", want, text)
+		}
+	}
+
+	custom, err := Render(s, "{{len .Blockers}} blockers since {{.Since.Format \"Jan 2\"}}")
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if custom != "3 blockers since May 14" {
+		t.Errorf("custom template rendered %q", custom)
+	}
+	if _, err := Render(s, "{{.Missing}"); err == nil {
+		t.Error("Render() with an invalid template should fail")
+	}
+}
+
+func TestPhrase(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "phrase.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\ntr a-z A-Z\n"), 0755); err != nil {
+		t.Fatalf("failed to write script: %v", err)
+	}
+
+	phrased, err := Phrase(context.Background(), script, "yesterday: tests\n")
+	if err != nil {
+		t.Fatalf("Phrase() error = %v", err)
+	}
+	if phrased != "YESTERDAY: TESTS\n" {
+		t.Errorf("Phrase() = %q", phrased)
+	}
+
+	if _, err := Phrase(context.Background(), filepath.Join(t.TempDir(), "missing"), "text"); err == nil {
+		t.Error("Phrase() with a missing command should fail")
+	}
+}
@@ -0,0 +1,320 @@
+// Package subscriptions stores saved searches that the daemon runs against
+// newly captured messages, such as "panic:" in agent messages, so new matches
+// can be announced as they are captured. Each check picks up where the
+// subscription's previous one stopped, so a message is matched at most once.
+package subscriptions
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/blobs"
+	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/logging"
+	"github.com/stwalsh4118/clio/internal/search"
+)
+
+const (
+	// matchLimit caps the matches one subscription reports per check, so a
+	// broad query doesn't flood the notifiers; the rest are skipped
+	matchLimit = 20
+	// excerptLength caps the matching line included in a match, in runes
+	excerptLength = 200
+)
+
+// namePattern restricts subscription names to words that are easy to type
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Subscription is a saved search
+type Subscription struct {
+	ID            int64
+	Name          string
+	Query         string // In the search query language; see search.ParseQuery
+	CodeOnly      bool   // Bare terms match only code blocks
+	MatchCount    int    // Matches found since the subscription was added
+	LastMatchedAt *time.Time
+	CreatedAt     time.Time
+}
+
+// Match is a newly captured message matching a subscription
+type Match struct {
+	Subscription string
+	Query        string
+	Excerpt      string // The first line containing a query term, trimmed
+	Time         time.Time
+
+	MessageID        string
+	SessionID        string
+	Project          string
+	ConversationName string
+	ComposerID       string
+	Role             string
+}
+
+// Store defines the interface for saving and checking subscriptions
+type Store interface {
+	// Add saves a subscription. Only messages captured after it is added can
+	// match it.
+	Add(sub Subscription) (*Subscription, error)
+	// List returns the subscriptions by name
+	List() ([]Subscription, error)
+	// Remove deletes the subscription with a name
+	Remove(name string) error
+	// Check runs each subscription against the messages captured since its
+	// previous check and returns the matches, oldest first per subscription
+	Check() ([]Match, error)
+}
+
+// store implements Store on top of the clio database
+type store struct {
+	db     *sql.DB
+	blobs  blobs.Store // Loads messages moved to the blob store for indexing and excerpts; may be nil
+	logger logging.Logger
+}
+
+// NewStore creates a subscription store backed by the database. blobStore is
+// optional; checks index messages moved to it by their preview without it.
+func NewStore(db *sql.DB, blobStore blobs.Store, logger logging.Logger) (Store, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database cannot be nil")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	return &store{
+		db:     db,
+		blobs:  blobStore,
+		logger: logger.With("component", "subscriptions"),
+	}, nil
+}
+
+// Add saves a subscription, starting after the newest message
+func (s *store) Add(sub Subscription) (*Subscription, error) {
+	if !namePattern.MatchString(sub.Name) {
+		return nil, fmt.Errorf("invalid subscription name %q: use lowercase letters, digits, - and _", sub.Name)
+	}
+	sub.Query = strings.TrimSpace(sub.Query)
+	if _, err := search.ParseQuery(sub.Query, time.Now()); err != nil {
+		return nil, fmt.Errorf("invalid query: %w", err)
+	}
+	if sub.CreatedAt.IsZero() {
+		sub.CreatedAt = time.Now()
+	}
+
+	var exists bool
+	if err := s.db.QueryRow("SELECT EXISTS(SELECT 1 FROM search_subscriptions WHERE name = ?)", sub.Name).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to look up subscription: %w", err)
+	}
+	if exists {
+		return nil, fmt.Errorf("subscription %q already exists", sub.Name)
+	}
+
+	// Messages captured before the subscription was added don't match it
+	result, err := s.db.Exec(`
+		INSERT INTO search_subscriptions (name, query, code_only, last_rowid, created_at)
+		VALUES (?, ?, ?, (SELECT COALESCE(MAX(rowid), 0) FROM messages), ?)
+	`, sub.Name, sub.Query, sub.CodeOnly, sub.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store subscription: %w", err)
+	}
+	if sub.ID, err = result.LastInsertId(); err != nil {
+		return nil, fmt.Errorf("failed to get subscription ID: %w", err)
+	}
+	sub.MatchCount, sub.LastMatchedAt = 0, nil
+
+	s.logger.Debug("added subscription", "name", sub.Name, "query", sub.Query)
+	return &sub, nil
+}
+
+// List returns the subscriptions by name
+func (s *store) List() ([]Subscription, error) {
+	list, _, err := s.list()
+	return list, err
+}
+
+// Remove deletes a subscription
+func (s *store) Remove(name string) error {
+	result, err := s.db.Exec("DELETE FROM search_subscriptions WHERE name = ?", name)
+	if err != nil {
+		return fmt.Errorf("failed to remove subscription: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to remove subscription: %w", err)
+	} else if n == 0 {
+		return fmt.Errorf("no subscription named %q", name)
+	}
+	return nil
+}
+
+// Check runs the subscriptions against newly captured messages
+func (s *store) Check() ([]Match, error) {
+	list, cursors, err := s.list()
+	if err != nil || len(list) == 0 {
+		return nil, err
+	}
+
+	// The newest message is read before indexing, so everything up to it is in the index
+	var newest int64
+	if err := s.db.QueryRow("SELECT COALESCE(MAX(rowid), 0) FROM messages").Scan(&newest); err != nil {
+		return nil, fmt.Errorf("failed to find the newest message: %w", err)
+	}
+	index, err := search.NewIndex(s.db, s.blobs, s.logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create search index: %w", err)
+	}
+	if _, err := index.Sync(); err != nil {
+		return nil, fmt.Errorf("failed to update search index: %w", err)
+	}
+
+	var matches []Match
+	now := time.Now()
+	for i, sub := range list {
+		if cursors[i] >= newest {
+			continue
+		}
+		found, err := s.check(index, sub, cursors[i], newest, now)
+		if err != nil {
+			// A query the current search options can't run is skipped, not retried forever
+			s.logger.Warn("failed to check subscription, skipping new messages", "name", sub.Name, "error", err)
+		}
+		if err := s.advance(sub.ID, newest, found, now); err != nil {
+			return matches, err
+		}
+		matches = append(matches, found...)
+	}
+	return matches, nil
+}
+
+// check returns the matches of a subscription among messages after one rowid, up to another
+func (s *store) check(index search.Index, sub Subscription, after, upTo int64, now time.Time) ([]Match, error) {
+	query, err := search.ParseQuery(sub.Query, now)
+	if err != nil {
+		return nil, err
+	}
+	where, args, err := index.Compile(query, sub.CodeOnly)
+	if err != nil {
+		return nil, err
+	}
+	if where == "" {
+		where = "1"
+	}
+
+	rows, err := s.db.Query(`
+		SELECT m.id, s.id, COALESCE(s.project, ''), COALESCE(c.name, ''), c.composer_id, m.role,
+			COALESCE(m.content, ''), m.content_blob, d.code, m.created_at
+		FROM search_documents d
+		JOIN messages m ON m.id = d.message_id
+		JOIN conversations c ON c.id = m.conversation_id
+		JOIN sessions s ON s.id = c.session_id
+		WHERE m.rowid > ? AND m.rowid <= ? AND `+where+`
+		ORDER BY m.rowid
+	`, append([]any{after, upTo}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search messages: %w", err)
+	}
+	defer rows.Close()
+
+	terms := query.Terms()
+	var matches []Match
+	for rows.Next() && len(matches) < matchLimit {
+		match := Match{Subscription: sub.Name, Query: sub.Query}
+		var content, code string
+		var contentBlob sql.NullString
+		if err := rows.Scan(&match.MessageID, &match.SessionID, &match.Project, &match.ConversationName,
+			&match.ComposerID, &match.Role, &content, &contentBlob, &code, &match.Time); err != nil {
+			return nil, fmt.Errorf("failed to scan message: %w", err)
+		}
+		if !db.InRange(match.Time, query.Since, query.Until) {
+			continue
+		}
+		if sub.CodeOnly {
+			content = code
+		} else if content, err = blobs.Resolve(s.blobs, content, contentBlob); err != nil {
+			s.logger.Warn("failed to load message blob, excerpting the stored preview", "message_id", match.MessageID, "error", err)
+		}
+		match.Excerpt = excerpt(content, terms)
+		matches = append(matches, match)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating messages: %w", err)
+	}
+	return matches, nil
+}
+
+// advance records the last message checked for a subscription and its new matches
+func (s *store) advance(id, rowid int64, found []Match, now time.Time) error {
+	var err error
+	if len(found) > 0 {
+		_, err = s.db.Exec(`
+			UPDATE search_subscriptions
+			SET last_rowid = ?, match_count = match_count + ?, last_matched_at = ?
+			WHERE id = ?
+		`, rowid, len(found), now, id)
+	} else {
+		_, err = s.db.Exec("UPDATE search_subscriptions SET last_rowid = ? WHERE id = ?", rowid, id)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save subscription progress: %w", err)
+	}
+	return nil
+}
+
+// list returns the subscriptions by name with the last rowid each has checked
+func (s *store) list() ([]Subscription, []int64, error) {
+	rows, err := s.db.Query(`
+		SELECT id, name, query, code_only, match_count, last_matched_at, created_at, last_rowid
+		FROM search_subscriptions
+		ORDER BY name
+	`)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var list []Subscription
+	var cursors []int64
+	for rows.Next() {
+		var sub Subscription
+		var lastMatchedAt sql.NullTime
+		var cursor int64
+		if err := rows.Scan(&sub.ID, &sub.Name, &sub.Query, &sub.CodeOnly, &sub.MatchCount, &lastMatchedAt, &sub.CreatedAt, &cursor); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan subscription: %w", err)
+		}
+		if lastMatchedAt.Valid {
+			sub.LastMatchedAt = &lastMatchedAt.Time
+		}
+		list = append(list, sub)
+		cursors = append(cursors, cursor)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("error iterating subscriptions: %w", err)
+	}
+	return list, cursors, nil
+}
+
+// excerpt returns the first line of text containing a term, or the first
+// non-blank line when none does, trimmed and shortened to excerptLength
+func excerpt(text string, terms []string) string {
+	lines := strings.Split(text, "\n")
+	chosen := ""
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		if chosen == "" {
+			chosen = line
+		}
+		if search.ContainsTerm(line, terms) {
+			chosen = line
+			break
+		}
+	}
+	if runes := []rune(chosen); len(runes) > excerptLength {
+		chosen = string(runes[:excerptLength]) + "…"
+	}
+	return chosen
+}
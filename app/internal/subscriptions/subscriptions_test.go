@@ -0,0 +1,142 @@
+package subscriptions
+
+import (
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/blobs"
+	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/logging"
+	_ "modernc.org/sqlite"
+)
+
+func setupTestDB(t *testing.T) *sql.DB {
+	database, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "clio.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if err := db.RunMigrations(database); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	now := time.Date(2024, 5, 1, 9, 0, 0, 0, time.Local)
+	mustExec(t, database, `
+		INSERT INTO sessions (id, project, start_time, last_activity, created_at, updated_at)
+		VALUES ('s1', 'clio', ?, ?, ?, ?)
+	`, now, now, now, now)
+	mustExec(t, database, `
+		INSERT INTO conversations (id, session_id, composer_id, name, created_at, updated_at)
+		VALUES ('c1', 's1', 'composer-1', 'Crash', ?, ?)
+	`, now, now)
+	return database
+}
+
+func mustExec(t *testing.T, database *sql.DB, query string, args ...any) {
+	t.Helper()
+	if _, err := database.Exec(query, args...); err != nil {
+		t.Fatalf("failed to exec %q: %v", query, err)
+	}
+}
+
+func addMessage(t *testing.T, database *sql.DB, id, role, content string) {
+	mustExec(t, database, `
+		INSERT INTO messages (id, conversation_id, bubble_id, type, role, content, created_at)
+		VALUES (?, 'c1', ?, 1, ?, ?, ?)
+	`, id, id, role, content, time.Now())
+}
+
+func TestStore_Check(t *testing.T) {
+	database := setupTestDB(t)
+	store, err := NewStore(database, nil, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+
+	// Nothing to check without subscriptions
+	addMessage(t, database, "old", "agent", "panic: before the subscription")
+	if matches, err := store.Check(); err != nil || len(matches) != 0 {
+		t.Fatalf("Check() without subscriptions = %+v, %v", matches, err)
+	}
+
+	if _, err := store.Add(Subscription{Name: "panics", Query: "panic role:agent"}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if _, err := store.Add(Subscription{Name: "panics", Query: "panic"}); err == nil {
+		t.Error("Add() with a duplicate name should fail")
+	}
+	if _, err := store.Add(Subscription{Name: "Bad Name", Query: "panic"}); err == nil {
+		t.Error("Add() with an invalid name should fail")
+	}
+	if _, err := store.Add(Subscription{Name: "broken", Query: "(panic"}); err == nil {
+		t.Error("Add() with an invalid query should fail")
+	}
+
+	// Messages from before the subscription don't match; new ones match once
+	addMessage(t, database, "m1", "user", "why the panic?")
+	addMessage(t, database, "m2", "agent", "Found it.\n\nThe **panic**: index out of range")
+	matches, err := store.Check()
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if len(matches) != 1 {
+		t.Fatalf("Check() = %+v, want the agent message", matches)
+	}
+	got := matches[0]
+	if got.Subscription != "panics" || got.MessageID != "m2" || got.Excerpt != "The **panic**: index out of range" ||
+		got.Project != "clio" || got.ConversationName != "Crash" || got.Role != "agent" {
+		t.Errorf("match = %+v", got)
+	}
+	if matches, err := store.Check(); err != nil || len(matches) != 0 {
+		t.Errorf("Check() again = %+v, %v, want no new matches", matches, err)
+	}
+
+	list, err := store.List()
+	if err != nil || len(list) != 1 || list[0].MatchCount != 1 || list[0].LastMatchedAt == nil {
+		t.Errorf("List() = %+v, %v, want one match counted", list, err)
+	}
+
+	if err := store.Remove("panics"); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if err := store.Remove("panics"); err == nil {
+		t.Error("Remove() of a missing subscription should fail")
+	}
+}
+
+func TestStore_CheckResolvesBlobs(t *testing.T) {
+	database := setupTestDB(t)
+	blobStore, err := blobs.NewStore(filepath.Join(t.TempDir(), "blobs"), logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	store, err := NewStore(database, blobStore, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	if _, err := store.Add(Subscription{Name: "panics", Query: "panic"}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	// The matching line lies past the preview kept in the row
+	long := strings.Repeat("trace line\n", blobs.PreviewLength/11+1) + "panic: index out of range"
+	ref, err := blobStore.Put([]byte(long))
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	mustExec(t, database, `
+		INSERT INTO messages (id, conversation_id, bubble_id, type, role, content, content_blob, created_at)
+		VALUES ('long', 'c1', 'long', 2, 'agent', ?, ?, ?)
+	`, blobs.Preview(long), ref, time.Now())
+
+	matches, err := store.Check()
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if len(matches) != 1 || matches[0].Excerpt != "panic: index out of range" {
+		t.Errorf("Check() = %+v, want the matching line from the blob store", matches)
+	}
+}
@@ -0,0 +1,279 @@
+package summaries
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	// conversationSentences and sessionSentences are how many key sentences an
+	// extractive summary keeps
+	conversationSentences = 3
+	sessionSentences      = 5
+	// maxRolledUpCommits is how many commit subjects a commit rollup names
+	maxRolledUpCommits = 5
+	// maxSentenceLength skips sentences longer than this, usually pasted logs or code
+	maxSentenceLength = 300
+	// minSentenceTerms skips sentences with fewer terms, such as "Thanks!"
+	minSentenceTerms = 3
+	// maxTitleLength truncates titles taken from a key sentence
+	maxTitleLength = 80
+	// Position weights: the opening prompt states the task and the final answer
+	// states the outcome, so their sentences are favoured
+	openingPromptWeight = 1.5
+	finalAnswerWeight   = 1.2
+	userWeight          = 1.1
+)
+
+// stopWords are common English words that don't distinguish sentences
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "that": true, "this": true, "with": true, "you": true,
+	"are": true, "was": true, "but": true, "not": true, "have": true, "has": true, "can": true,
+	"will": true, "from": true, "what": true, "when": true, "where": true, "which": true,
+	"how": true, "why": true, "all": true, "any": true, "its": true, "it's": true, "into": true,
+	"then": true, "than": true, "there": true, "here": true, "they": true, "them": true,
+	"their": true, "your": true, "our": true, "out": true, "now": true, "also": true, "just": true,
+	"should": true, "would": true, "could": true, "let": true, "let's": true, "i'll": true,
+	"i'm": true, "use": true, "using": true, "need": true, "make": true, "some": true,
+	"does": true, "did": true, "been": true, "being": true, "were": true, "these": true,
+	"those": true, "about": true, "like": true, "one": true, "more": true, "only": true,
+	"get": true, "got": true, "sure": true, "okay": true, "yes": true, "please": true, "too": true,
+}
+
+// Extractive summarizes without a model: it keeps the sentences that score
+// highest by TF-IDF across the messages, weighted towards the opening prompt and
+// the final answer, in their original order, followed by a rollup of a
+// session's commits. The same input always gives the same summary.
+type Extractive struct{}
+
+// Name implements Summarizer; bump the version when the output changes
+func (Extractive) Name() string {
+	return "extractive-v1"
+}
+
+// Summarize implements Summarizer
+func (Extractive) Summarize(_ context.Context, input *Input) (string, error) {
+	limit := conversationSentences
+	if input.Subject == SubjectSession {
+		limit = sessionSentences
+	}
+
+	var lines []string
+	for _, s := range keySentences(input, limit) {
+		lines = append(lines, "- "+s.text)
+	}
+	if rollup := commitRollup(input.Commits); rollup != "" {
+		lines = append(lines, rollup)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// Title names the input: its title when it has one, otherwise its key sentence
+// or first commit subject, truncated
+func Title(input *Input) string {
+	if title := strings.TrimSpace(input.Title); title != "" && input.Subject != SubjectSession {
+		return title
+	}
+	if sentences := keySentences(input, 1); len(sentences) > 0 {
+		return truncate(sentences[0].text, maxTitleLength)
+	}
+	if len(input.Commits) > 0 {
+		return truncate(commitSubject(input.Commits[0]), maxTitleLength)
+	}
+	return strings.TrimSpace(input.Title)
+}
+
+// sentence is a candidate for an extractive summary
+type sentence struct {
+	text     string
+	terms    []string
+	weight   float64 // Position weight
+	position int     // Order in the input
+	score    float64
+}
+
+// keySentences returns the limit highest scoring sentences in input order
+func keySentences(input *Input, limit int) []sentence {
+	// Each message is a document for IDF, so terms every message repeats count little
+	var candidates []sentence
+	documentFrequency := make(map[string]int)
+	documents := 0
+	firstUser, lastAgent := -1, -1
+	for i, m := range input.Messages {
+		if m.Role == "user" && firstUser < 0 && strings.TrimSpace(m.Text) != "" {
+			firstUser = i
+		}
+		if m.Role != "user" && strings.TrimSpace(m.Text) != "" {
+			lastAgent = i
+		}
+	}
+
+	for i, m := range input.Messages {
+		seen := make(map[string]bool)
+		weight := 1.0
+		switch {
+		case i == firstUser:
+			weight = openingPromptWeight
+		case i == lastAgent:
+			weight = finalAnswerWeight
+		case m.Role == "user":
+			weight = userWeight
+		}
+		for _, text := range splitSentences(m.Text) {
+			terms := Terms(text)
+			for _, term := range terms {
+				if !seen[term] {
+					seen[term] = true
+					documentFrequency[term]++
+				}
+			}
+			if len(text) > maxSentenceLength || len(terms) < minSentenceTerms {
+				continue
+			}
+			candidates = append(candidates, sentence{text: text, terms: terms, weight: weight, position: len(candidates)})
+		}
+		if len(seen) > 0 {
+			documents++
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	for i := range candidates {
+		candidates[i].score = score(candidates[i], documentFrequency, documents)
+	}
+	ranked := make([]sentence, len(candidates))
+	copy(ranked, candidates)
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].score > ranked[j].score })
+
+	// Skip sentences repeating the terms of one already kept, as retries do
+	var kept []sentence
+	keptTerms := make(map[string]bool)
+	for _, s := range ranked {
+		if len(kept) == limit {
+			break
+		}
+		key := termKey(s.terms)
+		if keptTerms[key] {
+			continue
+		}
+		keptTerms[key] = true
+		kept = append(kept, s)
+	}
+	sort.Slice(kept, func(i, j int) bool { return kept[i].position < kept[j].position })
+	return kept
+}
+
+// score sums the TF-IDF of a sentence's terms, normalised by its length so long
+// sentences don't win on size alone, and applies its position weight
+func score(s sentence, documentFrequency map[string]int, documents int) float64 {
+	tf := make(map[string]int)
+	for _, term := range s.terms {
+		tf[term]++
+	}
+	total := 0.0
+	for term, count := range tf {
+		idf := math.Log(float64(documents+1)/float64(documentFrequency[term]+1)) + 1
+		total += float64(count) * idf
+	}
+	return s.weight * total / math.Sqrt(float64(len(s.terms)))
+}
+
+// splitSentences splits text into sentences, leaving out fenced code blocks
+func splitSentences(text string) []string {
+	var sentences []string
+	inFence := false
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence || trimmed == "" {
+			continue
+		}
+		trimmed = strings.TrimLeft(trimmed, "-*#> ")
+
+		start := 0
+		for i, r := range trimmed {
+			if r != '.' && r != '!' && r != '?' {
+				continue
+			}
+			// A sentence ends at punctuation followed by a space, not inside "v1.2" or "main.go"
+			if next := i + 1; next < len(trimmed) && trimmed[next] != ' ' {
+				continue
+			}
+			if s := strings.TrimSpace(trimmed[start : i+1]); s != "" {
+				sentences = append(sentences, s)
+			}
+			start = i + 1
+		}
+		if s := strings.TrimSpace(trimmed[start:]); s != "" {
+			sentences = append(sentences, s)
+		}
+	}
+	return sentences
+}
+
+// Terms returns the lowercase words of text with three or more characters,
+// without stop words, as the extractive summarizer scores them
+func Terms(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '\''
+	})
+	var terms []string
+	for _, field := range fields {
+		field = strings.Trim(field, "'")
+		if len(field) < 3 || stopWords[field] {
+			continue
+		}
+		terms = append(terms, field)
+	}
+	return terms
+}
+
+// termKey identifies a sentence by its distinct terms
+func termKey(terms []string) string {
+	distinct := make(map[string]bool, len(terms))
+	for _, term := range terms {
+		distinct[term] = true
+	}
+	keys := make([]string, 0, len(distinct))
+	for term := range distinct {
+		keys = append(keys, term)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, " ")
+}
+
+// commitRollup names a session's commits by their subjects
+func commitRollup(commits []string) string {
+	if len(commits) == 0 {
+		return ""
+	}
+	var subjects []string
+	for _, commit := range commits {
+		if subject := commitSubject(commit); subject != "" && len(subjects) < maxRolledUpCommits {
+			subjects = append(subjects, subject)
+		}
+	}
+	noun := "commits"
+	if len(commits) == 1 {
+		noun = "commit"
+	}
+	rollup := fmt.Sprintf("%d %s: %s", len(commits), noun, strings.Join(subjects, "; "))
+	if more := len(commits) - len(subjects); more > 0 {
+		rollup += fmt.Sprintf(" (+%d more)", more)
+	}
+	return rollup
+}
+
+// commitSubject returns the first line of a commit message
+func commitSubject(message string) string {
+	return strings.TrimSpace(strings.SplitN(message, "\n", 2)[0])
+}
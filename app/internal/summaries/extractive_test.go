@@ -0,0 +1,88 @@
+package summaries
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitSentences(t *testing.T) {
+	text := "Fix the lexer in main.go. It fails on v1.2 tokens!\n\n```go\nfunc main() {}\n```\n- Then add tests"
+	want := []string{"Fix the lexer in main.go.", "It fails on v1.2 tokens!", "Then add tests"}
+	if got := splitSentences(text); !reflect.DeepEqual(got, want) {
+		t.Errorf("splitSentences() = %q, want %q", got, want)
+	}
+}
+
+func TestTerms(t *testing.T) {
+	want := []string{"parser", "handles", "unicode", "identifiers"}
+	if got := Terms("The parser now handles Unicode identifiers, too."); !reflect.DeepEqual(got, want) {
+		t.Errorf("Terms() = %q, want %q", got, want)
+	}
+}
+
+func TestExtractive_Conversation(t *testing.T) {
+	input := &Input{
+		Subject: SubjectConversation,
+		Title:   "Lexer fix",
+		Messages: []Message{
+			{Role: "user", Text: "The lexer crashes on unicode identifiers in template strings. Can you fix it?"},
+			{Role: "agent", Text: "Let me look at the file. I'll read the code first."},
+			{Role: "agent", Text: "The scanner advanced by bytes instead of runes.\n```go\nr, size := utf8.DecodeRuneInString(s)\n```"},
+			{Role: "user", Text: "Thanks!"},
+			{Role: "agent", Text: "Fixed the scanner to decode runes and added regression tests for unicode identifiers."},
+		},
+	}
+
+	summary, err := Extractive{}.Summarize(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Summarize() error = %v", err)
+	}
+	lines := strings.Split(summary, "\n")
+	if len(lines) != conversationSentences {
+		t.Fatalf("Summarize() = %q, want %d key sentences", summary, conversationSentences)
+	}
+	if !strings.Contains(lines[0], "lexer crashes on unicode") {
+		t.Errorf("first key sentence = %q, want the opening prompt", lines[0])
+	}
+	if strings.Contains(summary, "utf8.DecodeRune") || strings.Contains(summary, "Thanks") {
+		t.Errorf("Summarize() = %q, kept code or a sentence without content", summary)
+	}
+
+	// Deterministic
+	again, _ := Extractive{}.Summarize(context.Background(), input)
+	if again != summary {
+		t.Errorf("Summarize() is not deterministic: %q then %q", summary, again)
+	}
+
+	if title := Title(input); title != "Lexer fix" {
+		t.Errorf("Title() = %q, want the conversation name", title)
+	}
+	input.Title = ""
+	if title := Title(input); !strings.Contains(title, "lexer crashes") {
+		t.Errorf("Title() without a name = %q, want the key sentence", title)
+	}
+}
+
+func TestExtractive_SessionCommitRollup(t *testing.T) {
+	input := &Input{Subject: SubjectSession}
+	for i := 0; i < maxRolledUpCommits+2; i++ {
+		input.Commits = append(input.Commits, "Commit subject\n\nbody")
+	}
+
+	summary, err := Extractive{}.Summarize(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Summarize() error = %v", err)
+	}
+	if !strings.HasPrefix(summary, "7 commits: Commit subject;") || !strings.HasSuffix(summary, "(+2 more)") {
+		t.Errorf("Summarize() = %q, want a rollup of 7 commits naming 5", summary)
+	}
+	if title := Title(input); title != "Commit subject" {
+		t.Errorf("Title() = %q, want the first commit subject", title)
+	}
+
+	if summary, _ := (Extractive{}).Summarize(context.Background(), &Input{}); summary != "" {
+		t.Errorf("Summarize() of nothing = %q, want empty", summary)
+	}
+}
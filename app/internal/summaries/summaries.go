@@ -0,0 +1,299 @@
+// Package summaries caches generated summaries of conversations and sessions.
+// Each summary is stored with a hash of the messages (and a session's commits)
+// it was generated from, so it is only regenerated after they change, and
+// repeated exports and digests don't pay for the summarizer again.
+package summaries
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/blobs"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+// Subjects that can be summarized
+const (
+	SubjectConversation = "conversation"
+	SubjectSession      = "session"
+)
+
+// Message is a message summarized, with its full content
+type Message struct {
+	Role      string // "user" or "agent"
+	Text      string
+	CreatedAt time.Time
+}
+
+// Input is what a summarizer summarizes
+type Input struct {
+	Subject  string    // SubjectConversation or SubjectSession
+	ID       string    // Conversation or session ID
+	Title    string    // Conversation name, or a session's project
+	Messages []Message // Oldest first; a session's span its conversations
+	Commits  []string  // Messages of a session's commits, oldest first; empty for conversations
+}
+
+// Summarizer generates a summary
+type Summarizer interface {
+	// Name identifies the summarizer's cached summaries; it must change when the
+	// same input would be summarized differently, e.g. with another command
+	Name() string
+	Summarize(ctx context.Context, input *Input) (string, error)
+}
+
+// Summary is a generated summary
+type Summary struct {
+	Text        string
+	Summarizer  string
+	ContentHash string
+	GeneratedAt time.Time
+	Cached      bool // Served from the cache rather than generated now
+}
+
+// Cache returns summaries, generating them only when the content changed
+type Cache interface {
+	Conversation(ctx context.Context, conversationID string, summarizer Summarizer) (*Summary, error)
+	Session(ctx context.Context, sessionID string, summarizer Summarizer) (*Summary, error)
+	// Forget removes a subject's cached summaries, so they're regenerated next time
+	Forget(subject, id string) error
+}
+
+// cache implements Cache
+type cache struct {
+	db     *sql.DB
+	blobs  blobs.Store
+	logger logging.Logger
+}
+
+// NewCache creates a summary cache. Message content in the blob store is loaded
+// from store when a summary is generated; with a nil store summarizers see previews.
+func NewCache(db *sql.DB, store blobs.Store, logger logging.Logger) (Cache, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database cannot be nil")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+	return &cache{
+		db:     db,
+		blobs:  store,
+		logger: logger.With("component", "summaries"),
+	}, nil
+}
+
+// storedMessage is a message row as read for hashing; content is a preview when blob is set
+type storedMessage struct {
+	id        string
+	role      string
+	content   string
+	blob      sql.NullString
+	createdAt time.Time
+}
+
+// Conversation returns the summary of a conversation
+func (c *cache) Conversation(ctx context.Context, conversationID string, summarizer Summarizer) (*Summary, error) {
+	var title sql.NullString
+	err := c.db.QueryRow("SELECT name FROM conversations WHERE id = ?", conversationID).Scan(&title)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("conversation not found: %s", conversationID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query conversation: %w", err)
+	}
+
+	messages, err := c.messages("WHERE conversation_id = ?", conversationID)
+	if err != nil {
+		return nil, err
+	}
+	input := &Input{Subject: SubjectConversation, ID: conversationID, Title: title.String}
+	return c.summary(ctx, input, messages, nil, summarizer)
+}
+
+// Session returns the summary of a session: its conversations and commits
+func (c *cache) Session(ctx context.Context, sessionID string, summarizer Summarizer) (*Summary, error) {
+	var project string
+	err := c.db.QueryRow("SELECT project FROM sessions WHERE id = ?", sessionID).Scan(&project)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("session not found: %s", sessionID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query session: %w", err)
+	}
+
+	messages, err := c.messages("WHERE conversation_id IN (SELECT id FROM conversations WHERE session_id = ?)", sessionID)
+	if err != nil {
+		return nil, err
+	}
+	commits, err := c.commits(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	input := &Input{Subject: SubjectSession, ID: sessionID, Title: project}
+	return c.summary(ctx, input, messages, commits, summarizer)
+}
+
+// Forget removes a subject's cached summaries
+func (c *cache) Forget(subject, id string) error {
+	if _, err := c.db.Exec("DELETE FROM summaries WHERE subject = ? AND subject_id = ?", subject, id); err != nil {
+		return fmt.Errorf("failed to forget summaries: %w", err)
+	}
+	return nil
+}
+
+// summary returns the cached summary when its hash matches the content, and
+// otherwise generates and stores a new one
+func (c *cache) summary(ctx context.Context, input *Input, messages []storedMessage, commits [][2]string, summarizer Summarizer) (*Summary, error) {
+	if summarizer == nil {
+		return nil, fmt.Errorf("summarizer cannot be nil")
+	}
+	hash := contentHash(input.Title, messages, commits)
+
+	cached := &Summary{Summarizer: summarizer.Name(), Cached: true}
+	err := c.db.QueryRow(`
+		SELECT summary, content_hash, generated_at
+		FROM summaries
+		WHERE subject = ? AND subject_id = ? AND summarizer = ?
+	`, input.Subject, input.ID, cached.Summarizer).Scan(&cached.Text, &cached.ContentHash, &cached.GeneratedAt)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to query cached summary: %w", err)
+	}
+	if err == nil && cached.ContentHash == hash {
+		return cached, nil
+	}
+
+	for _, m := range messages {
+		text, err := blobs.Resolve(c.blobs, m.content, m.blob)
+		if err != nil {
+			// A missing blob only shortens the message to its preview
+			c.logger.Warn("failed to load message blob", "message_id", m.id, "error", err)
+		}
+		input.Messages = append(input.Messages, Message{Role: m.role, Text: text, CreatedAt: m.createdAt})
+	}
+	for _, commit := range commits {
+		input.Commits = append(input.Commits, commit[1])
+	}
+
+	text, err := summarizer.Summarize(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize %s %s: %w", input.Subject, input.ID, err)
+	}
+	generated := &Summary{Text: text, Summarizer: summarizer.Name(), ContentHash: hash, GeneratedAt: time.Now()}
+
+	_, err = c.db.Exec(`
+		INSERT INTO summaries (subject, subject_id, summarizer, content_hash, summary, generated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(subject, subject_id, summarizer) DO UPDATE SET
+			content_hash = excluded.content_hash,
+			summary = excluded.summary,
+			generated_at = excluded.generated_at
+	`, input.Subject, input.ID, generated.Summarizer, generated.ContentHash, generated.Text, generated.GeneratedAt)
+	if err != nil {
+		// The summary is still good; it's generated again next time
+		c.logger.Warn("failed to cache summary", "subject", input.Subject, "id", input.ID, "error", err)
+	}
+	return generated, nil
+}
+
+// messages returns the message rows matching where, oldest first
+func (c *cache) messages(where string, arg string) ([]storedMessage, error) {
+	rows, err := c.db.Query(`
+		SELECT id, role, content, content_blob, created_at
+		FROM messages
+		`+where+`
+		ORDER BY created_at ASC, id ASC
+	`, arg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []storedMessage
+	for rows.Next() {
+		var m storedMessage
+		if err := rows.Scan(&m.id, &m.role, &m.content, &m.blob, &m.createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan message: %w", err)
+		}
+		messages = append(messages, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating messages: %w", err)
+	}
+	return messages, nil
+}
+
+// commits returns the hash and message of a session's commits, oldest first
+func (c *cache) commits(sessionID string) ([][2]string, error) {
+	rows, err := c.db.Query("SELECT hash, message FROM commits WHERE session_id = ? ORDER BY timestamp ASC, hash ASC", sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query commits: %w", err)
+	}
+	defer rows.Close()
+
+	var commits [][2]string
+	for rows.Next() {
+		var hash, message string
+		if err := rows.Scan(&hash, &message); err != nil {
+			return nil, fmt.Errorf("failed to scan commit: %w", err)
+		}
+		commits = append(commits, [2]string{hash, message})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating commits: %w", err)
+	}
+	return commits, nil
+}
+
+// contentHash hashes what a summary is generated from. Content in the blob store
+// is hashed by its reference, itself a hash of the content, so checking the cache
+// never reads blobs.
+func contentHash(title string, messages []storedMessage, commits [][2]string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "title\x00%d\x00%s\x00", len(title), title)
+	for _, m := range messages {
+		fmt.Fprintf(h, "message\x00%s\x00%s\x00", m.id, m.role)
+		if m.blob.Valid && m.blob.String != "" {
+			fmt.Fprintf(h, "blob\x00%s\x00", m.blob.String)
+		} else {
+			fmt.Fprintf(h, "text\x00%d\x00%s\x00", len(m.content), m.content)
+		}
+	}
+	for _, commit := range commits {
+		fmt.Fprintf(h, "commit\x00%s\x00%d\x00%s\x00", commit[0], len(commit[1]), commit[1])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// ConversationRef names a conversation
+type ConversationRef struct {
+	ID   string
+	Name string // Empty when Cursor didn't name it
+}
+
+// Conversations returns a session's conversations, oldest first
+func Conversations(db *sql.DB, sessionID string) ([]ConversationRef, error) {
+	rows, err := db.Query("SELECT id, name FROM conversations WHERE session_id = ? ORDER BY created_at ASC, id ASC", sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query conversations: %w", err)
+	}
+	defer rows.Close()
+
+	var conversations []ConversationRef
+	for rows.Next() {
+		var ref ConversationRef
+		var name sql.NullString
+		if err := rows.Scan(&ref.ID, &name); err != nil {
+			return nil, fmt.Errorf("failed to scan conversation: %w", err)
+		}
+		ref.Name = name.String
+		conversations = append(conversations, ref)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating conversations: %w", err)
+	}
+	return conversations, nil
+}
@@ -0,0 +1,170 @@
+package summaries
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/logging"
+	_ "modernc.org/sqlite"
+)
+
+func openTestDB(t *testing.T) *sql.DB {
+	database, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "clio.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	database.SetMaxOpenConns(1)
+	t.Cleanup(func() { database.Close() })
+	if err := db.RunMigrations(database); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	return database
+}
+
+func mustExec(t *testing.T, database *sql.DB, query string, args ...any) {
+	t.Helper()
+	if _, err := database.Exec(query, args...); err != nil {
+		t.Fatalf("failed to run %q: %v", query, err)
+	}
+}
+
+// countingSummarizer counts its calls and summarizes by message count
+type countingSummarizer struct {
+	calls int
+}
+
+func (s *countingSummarizer) Name() string { return "counting" }
+
+func (s *countingSummarizer) Summarize(_ context.Context, input *Input) (string, error) {
+	s.calls++
+	return strings.Repeat("m", len(input.Messages)) + strings.Repeat("c", len(input.Commits)), nil
+}
+
+func seedSession(t *testing.T, database *sql.DB) time.Time {
+	now := time.Now()
+	mustExec(t, database, `INSERT INTO sessions (id, project, start_time, last_activity, created_at, updated_at) VALUES ('s1', 'clio', ?, ?, ?, ?)`, now, now, now, now)
+	mustExec(t, database, `INSERT INTO conversations (id, session_id, composer_id, name, status, message_count, created_at, updated_at) VALUES ('c1', 's1', 'c1', 'Fix parser', 'completed', 2, ?, ?)`, now, now)
+	mustExec(t, database, `INSERT INTO messages (id, conversation_id, bubble_id, type, role, content, created_at) VALUES ('m1', 'c1', 'm1', 1, 'user', 'Fix the   lexer', ?)`, now)
+	mustExec(t, database, `INSERT INTO messages (id, conversation_id, bubble_id, type, role, content, created_at) VALUES ('m2', 'c1', 'm2', 2, 'agent', 'Done', ?)`, now.Add(time.Second))
+	return now
+}
+
+func TestNewCache(t *testing.T) {
+	if _, err := NewCache(nil, nil, logging.NewNoopLogger()); err == nil {
+		t.Error("NewCache(nil, ...) expected error, got nil")
+	}
+	if _, err := NewCache(openTestDB(t), nil, nil); err == nil {
+		t.Error("NewCache(..., nil) expected error, got nil")
+	}
+}
+
+func TestCache_RegeneratesOnlyWhenContentChanges(t *testing.T) {
+	database := openTestDB(t)
+	now := seedSession(t, database)
+	cache, err := NewCache(database, nil, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewCache() error = %v", err)
+	}
+	summarizer := &countingSummarizer{}
+	ctx := context.Background()
+
+	summary, err := cache.Conversation(ctx, "c1", summarizer)
+	if err != nil {
+		t.Fatalf("Conversation() error = %v", err)
+	}
+	if summary.Text != "mm" || summary.Cached {
+		t.Errorf("first Conversation() = %+v, want a generated summary of 2 messages", summary)
+	}
+
+	summary, err = cache.Conversation(ctx, "c1", summarizer)
+	if err != nil {
+		t.Fatalf("Conversation() error = %v", err)
+	}
+	if !summary.Cached || summarizer.calls != 1 {
+		t.Errorf("repeated Conversation() = %+v after %d calls, want the cached summary", summary, summarizer.calls)
+	}
+
+	// An edited message invalidates the summary
+	mustExec(t, database, "UPDATE messages SET content = 'Done, with tests' WHERE id = 'm2'")
+	if summary, err = cache.Conversation(ctx, "c1", summarizer); err != nil || summary.Cached || summarizer.calls != 2 {
+		t.Errorf("Conversation() after an edit = %+v, %v after %d calls; want a regenerated summary", summary, err, summarizer.calls)
+	}
+
+	// A session summary covers its commits, so a new commit invalidates it
+	if summary, err = cache.Session(ctx, "s1", summarizer); err != nil || summary.Text != "mm" {
+		t.Fatalf("Session() = %+v, %v", summary, err)
+	}
+	mustExec(t, database, `INSERT INTO commits (id, session_id, repository_path, repository_name, hash, message, author_name, author_email, timestamp, branch, created_at, updated_at)
+		VALUES ('k1', 's1', '/repo', 'repo', 'abc123', 'Fix lexer', 'a', 'a@example.com', ?, 'main', ?, ?)`, now, now, now)
+	if summary, err = cache.Session(ctx, "s1", summarizer); err != nil || summary.Text != "mmc" || summary.Cached {
+		t.Errorf("Session() after a commit = %+v, %v; want a regenerated summary", summary, err)
+	}
+
+	// Forgetting drops the cached summary
+	calls := summarizer.calls
+	if err := cache.Forget(SubjectSession, "s1"); err != nil {
+		t.Fatalf("Forget() error = %v", err)
+	}
+	if summary, err = cache.Session(ctx, "s1", summarizer); err != nil || summary.Cached || summarizer.calls != calls+1 {
+		t.Errorf("Session() after Forget() = %+v, %v; want a regenerated summary", summary, err)
+	}
+
+	if _, err := cache.Conversation(ctx, "missing", summarizer); err == nil {
+		t.Error("Conversation() for a missing conversation expected error, got nil")
+	}
+}
+
+func TestOpeningPrompt(t *testing.T) {
+	database := openTestDB(t)
+	seedSession(t, database)
+	cache, err := NewCache(database, nil, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewCache() error = %v", err)
+	}
+
+	summary, err := cache.Conversation(context.Background(), "c1", OpeningPrompt{})
+	if err != nil {
+		t.Fatalf("Conversation() error = %v", err)
+	}
+	if summary.Text != "Fix the lexer" {
+		t.Errorf("OpeningPrompt summary = %q, want the flattened first prompt", summary.Text)
+	}
+
+	commitsOnly := &Input{Commits: []string{"Add parser\n\nLong body"}}
+	if text, _ := (OpeningPrompt{}).Summarize(context.Background(), commitsOnly); text != "Add parser" {
+		t.Errorf("OpeningPrompt without prompts = %q, want the first commit subject", text)
+	}
+}
+
+func TestCommandSummarizer(t *testing.T) {
+	if _, err := NewCommandSummarizer(""); err == nil {
+		t.Error("NewCommandSummarizer(\"\") expected error, got nil")
+	}
+
+	script := filepath.Join(t.TempDir(), "summarize.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\necho \"$CLIO_SUMMARY_SUBJECT: $(grep -c '^user:')\"\n"), 0755); err != nil {
+		t.Fatalf("failed to write script: %v", err)
+	}
+	summarizer, err := NewCommandSummarizer(script)
+	if err != nil {
+		t.Fatalf("NewCommandSummarizer() error = %v", err)
+	}
+	if summarizer.Name() == (OpeningPrompt{}).Name() {
+		t.Error("command summarizer shares the built-in summarizer's cache name")
+	}
+
+	input := &Input{Subject: SubjectConversation, Messages: []Message{{Role: "user", Text: "hi"}, {Role: "agent", Text: "hello"}}}
+	text, err := summarizer.Summarize(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Summarize() error = %v", err)
+	}
+	if text != "conversation: 1" {
+		t.Errorf("Summarize() = %q, want the command's output", text)
+	}
+}
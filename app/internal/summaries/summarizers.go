@@ -0,0 +1,117 @@
+package summaries
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+const (
+	// maxOpeningPromptLength truncates the opening prompt used as a summary
+	maxOpeningPromptLength = 160
+	// maxCommandOutput bounds how much a summary command may print
+	maxCommandOutput = 64 * 1024
+)
+
+// OpeningPrompt summarizes by the first user message, flattened to one line and
+// truncated; a session without one falls back to its first commit message
+type OpeningPrompt struct{}
+
+// Name implements Summarizer
+func (OpeningPrompt) Name() string {
+	return "opening-prompt"
+}
+
+// Summarize implements Summarizer
+func (OpeningPrompt) Summarize(_ context.Context, input *Input) (string, error) {
+	for _, m := range input.Messages {
+		if m.Role != "user" {
+			continue
+		}
+		if prompt := strings.Join(strings.Fields(m.Text), " "); prompt != "" {
+			return truncate(prompt, maxOpeningPromptLength), nil
+		}
+	}
+	for _, commit := range input.Commits {
+		if subject := commitSubject(commit); subject != "" {
+			return truncate(subject, maxOpeningPromptLength), nil
+		}
+	}
+	return "", nil
+}
+
+// truncate shortens s to at most max runes, marking the cut with an ellipsis
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-1]) + "…"
+}
+
+// commandSummarizer summarizes with an external command, such as a script that
+// asks an LLM
+type commandSummarizer struct {
+	command string
+}
+
+// NewCommandSummarizer creates a summarizer running command with a transcript of
+// the input on stdin; what it prints is the summary
+func NewCommandSummarizer(command string) (Summarizer, error) {
+	if command == "" {
+		return nil, fmt.Errorf("summary command cannot be empty")
+	}
+	return &commandSummarizer{command: command}, nil
+}
+
+// Name implements Summarizer; summaries by another command are cached apart
+func (s *commandSummarizer) Name() string {
+	return "command:" + s.command
+}
+
+// Summarize implements Summarizer
+func (s *commandSummarizer) Summarize(ctx context.Context, input *Input) (string, error) {
+	cmd := exec.CommandContext(ctx, s.command)
+	cmd.Stdin = strings.NewReader(Transcript(input))
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), "CLIO_SUMMARY_SUBJECT="+input.Subject, "CLIO_SUMMARY_ID="+input.ID)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("summary command failed: %w", err)
+	}
+	if stdout.Len() > maxCommandOutput {
+		return "", fmt.Errorf("summary command printed more than %d bytes", maxCommandOutput)
+	}
+	summary := strings.TrimSpace(stdout.String())
+	if summary == "" {
+		return "", fmt.Errorf("summary command printed nothing")
+	}
+	return summary, nil
+}
+
+// Transcript renders the input as plain text, as given to summary commands
+func Transcript(input *Input) string {
+	var b strings.Builder
+	if input.Title != "" {
+		fmt.Fprintf(&b, "# %s\n\n", input.Title)
+	}
+	for _, m := range input.Messages {
+		text := strings.TrimSpace(m.Text)
+		if text == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "%s: %s\n\n", m.Role, text)
+	}
+	if len(input.Commits) > 0 {
+		b.WriteString("Commits:\n")
+		for _, commit := range input.Commits {
+			fmt.Fprintf(&b, "- %s\n", commitSubject(commit))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,200 @@
+// Package tagrules tags sessions with goal tags automatically when they end,
+// from rules in the tag_rules configuration block such as "if the project
+// matches api-* and a message mentions migration, tag db-work".
+package tagrules
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/blobs"
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/goals"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+// Rule is a compiled tag rule
+type Rule struct {
+	Tag      string
+	Project  string   // Lowercased glob; empty matches any project
+	Keywords []string // Lowercased; empty matches without looking at messages
+}
+
+// Compile compiles configured tag rules
+func Compile(rules []config.TagRuleConfig) ([]Rule, error) {
+	compiled := make([]Rule, 0, len(rules))
+	for i, rule := range rules {
+		r := Rule{Tag: strings.ToLower(rule.Tag), Project: strings.ToLower(rule.Project)}
+		if _, err := filepath.Match(r.Project, ""); err != nil {
+			return nil, fmt.Errorf("rule %d: invalid project pattern %q: %w", i+1, rule.Project, err)
+		}
+		for _, keyword := range rule.Contains {
+			if keyword = strings.ToLower(strings.TrimSpace(keyword)); keyword != "" {
+				r.Keywords = append(r.Keywords, keyword)
+			}
+		}
+		compiled = append(compiled, r)
+	}
+	return compiled, nil
+}
+
+// matchesProject reports whether the rule applies to a session in project
+func (r Rule) matchesProject(project string) bool {
+	if r.Project == "" {
+		return true
+	}
+	matched, _ := filepath.Match(r.Project, strings.ToLower(project))
+	return matched
+}
+
+// matchesText reports whether text mentions one of the rule's keywords; text is lowercased
+func (r Rule) matchesText(text string) bool {
+	for _, keyword := range r.Keywords {
+		if strings.Contains(text, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// Tagger applies tag rules to sessions
+type Tagger interface {
+	// Apply tags a session with the tags of every rule it matches and returns them
+	Apply(sessionID string, at time.Time) ([]string, error)
+}
+
+// tagger implements Tagger over the clio database
+type tagger struct {
+	db     *sql.DB
+	blobs  blobs.Store
+	goals  goals.Tracker
+	rules  []Rule
+	logger logging.Logger
+}
+
+// NewTagger creates a tagger for the rules. blobStore may be nil, in which case
+// content moved to the blob store is matched against its preview.
+func NewTagger(db *sql.DB, blobStore blobs.Store, rules []Rule, logger logging.Logger) (Tagger, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database cannot be nil")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	tracker, err := goals.NewTracker(db, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create goal tracker: %w", err)
+	}
+
+	return &tagger{
+		db:     db,
+		blobs:  blobStore,
+		goals:  tracker,
+		rules:  rules,
+		logger: logger.With("component", "tagrules"),
+	}, nil
+}
+
+// Apply tags a session with the tags of every rule it matches
+func (t *tagger) Apply(sessionID string, at time.Time) ([]string, error) {
+	var project sql.NullString
+	if err := t.db.QueryRow("SELECT project FROM sessions WHERE id = ?", sessionID).Scan(&project); err != nil {
+		return nil, fmt.Errorf("failed to look up session: %w", err)
+	}
+
+	// Rules without keywords match on the project alone; the rest wait for a message
+	var matched, pending []Rule
+	for _, rule := range t.rules {
+		switch {
+		case !rule.matchesProject(project.String):
+		case len(rule.Keywords) == 0:
+			matched = append(matched, rule)
+		default:
+			pending = append(pending, rule)
+		}
+	}
+	if len(pending) > 0 {
+		found, err := t.matchMessages(sessionID, pending)
+		if err != nil {
+			return nil, err
+		}
+		matched = append(matched, found...)
+	}
+
+	var tags []string
+	seen := make(map[string]bool)
+	for _, rule := range matched {
+		if seen[rule.Tag] {
+			continue
+		}
+		seen[rule.Tag] = true
+		if err := t.tag(rule.Tag, sessionID, at); err != nil {
+			return tags, err
+		}
+		tags = append(tags, rule.Tag)
+	}
+
+	if len(tags) > 0 {
+		t.logger.Info("tagged session by rule", "session_id", sessionID, "tags", strings.Join(tags, ","))
+	}
+	return tags, nil
+}
+
+// matchMessages returns the rules whose keywords one of the session's messages mentions
+func (t *tagger) matchMessages(sessionID string, rules []Rule) ([]Rule, error) {
+	rows, err := t.db.Query(`
+		SELECT m.content, m.content_blob
+		FROM messages m
+		JOIN conversations c ON c.id = m.conversation_id
+		WHERE c.session_id = ?
+	`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query messages: %w", err)
+	}
+	defer rows.Close()
+
+	var matched []Rule
+	for rows.Next() && len(rules) > 0 {
+		var text string
+		var ref sql.NullString
+		if err := rows.Scan(&text, &ref); err != nil {
+			return nil, fmt.Errorf("failed to scan message: %w", err)
+		}
+		if text, err = blobs.Resolve(t.blobs, text, ref); err != nil {
+			t.logger.Warn("failed to load blob, matching the stored preview", "session_id", sessionID, "error", err)
+		}
+		text = strings.ToLower(text)
+
+		var remaining []Rule
+		for _, rule := range rules {
+			if rule.matchesText(text) {
+				matched = append(matched, rule)
+			} else {
+				remaining = append(remaining, rule)
+			}
+		}
+		rules = remaining
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating messages: %w", err)
+	}
+	return matched, nil
+}
+
+// tag links the session to the goal, creating the goal when no goal has the tag
+func (t *tagger) tag(tag, sessionID string, at time.Time) error {
+	if _, err := t.goals.Get(tag); err != nil {
+		if _, err := t.goals.Add(goals.Goal{Tag: tag, Title: tag, CreatedAt: at}); err != nil {
+			return fmt.Errorf("failed to create goal %q: %w", tag, err)
+		}
+		t.logger.Info("created goal for tag rule", "tag", tag)
+	}
+	if err := t.goals.TagSession(tag, sessionID, at); err != nil {
+		return fmt.Errorf("failed to tag session with %q: %w", tag, err)
+	}
+	return nil
+}
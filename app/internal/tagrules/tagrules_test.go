@@ -0,0 +1,123 @@
+package tagrules
+
+import (
+	"database/sql"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/goals"
+	"github.com/stwalsh4118/clio/internal/logging"
+	_ "modernc.org/sqlite"
+)
+
+func setupTestDB(t *testing.T) *sql.DB {
+	database, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "clio.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if err := db.RunMigrations(database); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	now := time.Date(2024, 5, 1, 9, 0, 0, 0, time.Local)
+	for _, session := range []struct{ id, project string }{{"s1", "api-server"}, {"s2", "clio"}} {
+		mustExec(t, database, `
+			INSERT INTO sessions (id, project, start_time, last_activity, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, session.id, session.project, now, now, now, now)
+		mustExec(t, database, `
+			INSERT INTO conversations (id, session_id, composer_id, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?)
+		`, "c-"+session.id, session.id, "composer-"+session.id, now, now)
+	}
+	mustExec(t, database, `
+		INSERT INTO messages (id, conversation_id, bubble_id, type, role, content, created_at)
+		VALUES ('m1', 'c-s1', 'm1', 1, 'user', 'Write the MIGRATION for the users table', ?),
+			('m2', 'c-s2', 'm2', 1, 'user', 'Fix the lexer', ?)
+	`, now, now)
+	return database
+}
+
+func mustExec(t *testing.T, database *sql.DB, query string, args ...any) {
+	t.Helper()
+	if _, err := database.Exec(query, args...); err != nil {
+		t.Fatalf("failed to exec %q: %v", query, err)
+	}
+}
+
+func TestCompile(t *testing.T) {
+	rules, err := Compile([]config.TagRuleConfig{{Tag: "DB-Work", Project: "API-*", Contains: []string{" Migration ", ""}}})
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if r := rules[0]; r.Tag != "db-work" || r.Project != "api-*" || len(r.Keywords) != 1 || r.Keywords[0] != "migration" {
+		t.Errorf("Compile() = %+v, want lowercased and trimmed", rules)
+	}
+	if _, err := Compile([]config.TagRuleConfig{{Tag: "x", Project: "[api"}}); err == nil {
+		t.Error("Compile() with a bad project pattern should fail")
+	}
+}
+
+func TestTagger_Apply(t *testing.T) {
+	database := setupTestDB(t)
+	now := time.Date(2024, 5, 1, 18, 0, 0, 0, time.Local)
+
+	tracker, err := goals.NewTracker(database, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewTracker() error = %v", err)
+	}
+	if _, err := tracker.Add(goals.Goal{Tag: "api", Title: "API work"}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	rules, err := Compile([]config.TagRuleConfig{
+		{Tag: "db-work", Project: "api-*", Contains: []string{"migration", "schema"}},
+		{Tag: "api", Project: "api-*"},
+		{Tag: "parser", Contains: []string{"lexer"}},
+		{Tag: "docs", Contains: []string{"readme"}},
+	})
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	tagger, err := NewTagger(database, nil, rules, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewTagger() error = %v", err)
+	}
+
+	tags, err := tagger.Apply("s1", now)
+	if err != nil {
+		t.Fatalf("Apply(s1) error = %v", err)
+	}
+	sort.Strings(tags)
+	if len(tags) != 2 || tags[0] != "api" || tags[1] != "db-work" {
+		t.Errorf("Apply(s1) = %v, want api and db-work", tags)
+	}
+	if tags, err := tagger.Apply("s2", now); err != nil || len(tags) != 1 || tags[0] != "parser" {
+		t.Errorf("Apply(s2) = %v, %v, want parser", tags, err)
+	}
+
+	// The db-work goal was created for the rule, and tagging again is harmless
+	goal, err := tracker.Get("db-work")
+	if err != nil {
+		t.Fatalf("Get(db-work) error = %v", err)
+	}
+	if _, err := tagger.Apply("s1", now); err != nil {
+		t.Errorf("Apply(s1) again error = %v", err)
+	}
+	sessions, err := goals.Sessions(database, goal.Tag)
+	if err != nil || len(sessions) != 1 || !sessions["s1"] {
+		t.Errorf("Sessions(db-work) = %v, %v, want s1", sessions, err)
+	}
+	if _, err := tracker.Get("docs"); err == nil {
+		t.Error("a rule that didn't match shouldn't create its goal")
+	}
+
+	if _, err := tagger.Apply("missing", now); err == nil {
+		t.Error("Apply() of a missing session should fail")
+	}
+}
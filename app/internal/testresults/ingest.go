@@ -0,0 +1,236 @@
+package testresults
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+// Options controls how a results file is ingested
+type Options struct {
+	Format  string // FormatAuto, FormatJUnit, or FormatGoTest; empty means FormatAuto
+	Project string // Only link to sessions and commits of this project (case-insensitive); empty matches any
+}
+
+// Result describes a stored test run
+type Result struct {
+	RunID      string
+	Format     string
+	RunTime    time.Time
+	Total      int
+	Passed     int
+	Failed     int
+	Skipped    int
+	SessionID  string // Session active when the run finished; empty when none was
+	CommitHash string // Latest commit at or before the run; empty when none was
+	Duplicate  bool   // The file was ingested before and nothing was written
+}
+
+// Ingester defines the interface for storing test run outcomes
+type Ingester interface {
+	// Ingest parses a results file and stores the run, linked to the session active when
+	// it finished and the nearest preceding commit. Re-ingesting an unchanged file is a no-op.
+	Ingest(path string, opts Options) (*Result, error)
+}
+
+// ingester implements Ingester on top of the clio database
+type ingester struct {
+	db     *sql.DB
+	logger logging.Logger
+}
+
+// NewIngester creates an ingester writing to the given database
+func NewIngester(db *sql.DB, logger logging.Logger) (Ingester, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database cannot be nil")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	return &ingester{
+		db:     db,
+		logger: logger.With("component", "test_results"),
+	}, nil
+}
+
+// Ingest reads, links, and stores a results file
+func (i *ingester) Ingest(path string, opts Options) (*Result, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read test results: %w", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat test results: %w", err)
+	}
+
+	run, err := Parse(data, opts.Format, info.ModTime())
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(data)
+	passed, failed, skipped := run.Counts()
+	result := &Result{
+		RunID:   hex.EncodeToString(sum[:]),
+		Format:  run.Format,
+		RunTime: run.RunTime,
+		Total:   len(run.Cases),
+		Passed:  passed,
+		Failed:  failed,
+		Skipped: skipped,
+	}
+
+	var existing int
+	if err := i.db.QueryRow("SELECT COUNT(*) FROM test_runs WHERE id = ?", result.RunID).Scan(&existing); err != nil {
+		return nil, fmt.Errorf("failed to check for existing test run: %w", err)
+	}
+	if existing > 0 {
+		result.Duplicate = true
+		return result, nil
+	}
+
+	if result.SessionID, err = i.activeSession(run.RunTime, opts.Project); err != nil {
+		return nil, err
+	}
+	if result.CommitHash, err = i.nearestCommit(run.RunTime, result.SessionID, opts.Project); err != nil {
+		return nil, err
+	}
+
+	if err := i.store(run, result, path); err != nil {
+		return nil, err
+	}
+
+	i.logger.Info("ingested test run",
+		"path", path,
+		"format", result.Format,
+		"total", result.Total,
+		"failed", result.Failed,
+		"session_id", result.SessionID,
+		"commit", result.CommitHash)
+
+	return result, nil
+}
+
+// store writes the run and its cases in one transaction
+func (i *ingester) store(run *Run, result *Result, path string) error {
+	tx, err := i.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		INSERT INTO test_runs (id, session_id, commit_hash, format, source_path, run_time,
+			duration_ms, total, passed, failed, skipped, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, result.RunID, nullString(result.SessionID), nullString(result.CommitHash), run.Format, path, run.RunTime,
+		run.Duration.Milliseconds(), result.Total, result.Passed, result.Failed, result.Skipped, time.Now()); err != nil {
+		return fmt.Errorf("failed to insert test run: %w", err)
+	}
+
+	for _, c := range run.Cases {
+		if _, err := tx.Exec(`
+			INSERT INTO test_cases (run_id, suite, name, status, duration_ms, message)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, result.RunID, c.Suite, c.Name, c.Status, c.Duration.Milliseconds(), nullString(c.Message)); err != nil {
+			return fmt.Errorf("failed to insert test case: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit test run: %w", err)
+	}
+	return nil
+}
+
+// activeSession returns the most recently started session covering t, or "" when none does.
+// Sessions that haven't ended cover everything after their start.
+func (i *ingester) activeSession(t time.Time, project string) (string, error) {
+	rows, err := i.db.Query("SELECT id, project, start_time, end_time FROM sessions")
+	if err != nil {
+		return "", fmt.Errorf("failed to query sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var bestID string
+	var bestStart time.Time
+	for rows.Next() {
+		var id string
+		var sessionProject sql.NullString
+		var start time.Time
+		var end sql.NullTime
+		if err := rows.Scan(&id, &sessionProject, &start, &end); err != nil {
+			return "", fmt.Errorf("failed to scan session: %w", err)
+		}
+		if project != "" && !strings.EqualFold(project, sessionProject.String) {
+			continue
+		}
+		if start.After(t) || (end.Valid && end.Time.Before(t)) {
+			continue
+		}
+		if bestID == "" || start.After(bestStart) {
+			bestID, bestStart = id, start
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("error iterating sessions: %w", err)
+	}
+	return bestID, nil
+}
+
+// nearestCommit returns the hash of the latest commit at or before t, preferring commits
+// correlated with sessionID and otherwise considering the project's repositories
+func (i *ingester) nearestCommit(t time.Time, sessionID, project string) (string, error) {
+	if sessionID != "" {
+		hash, err := i.latestCommit(t, "SELECT hash, timestamp FROM commits WHERE session_id = ?", sessionID)
+		if err != nil || hash != "" {
+			return hash, err
+		}
+	}
+	if project != "" {
+		return i.latestCommit(t, "SELECT hash, timestamp FROM commits WHERE repository_name = ? COLLATE NOCASE", project)
+	}
+	return i.latestCommit(t, "SELECT hash, timestamp FROM commits")
+}
+
+// latestCommit returns the hash of the latest commit from query at or before t
+func (i *ingester) latestCommit(t time.Time, query string, args ...interface{}) (string, error) {
+	rows, err := i.db.Query(query, args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to query commits: %w", err)
+	}
+	defer rows.Close()
+
+	var bestHash string
+	var bestTime time.Time
+	for rows.Next() {
+		var hash string
+		var timestamp time.Time
+		if err := rows.Scan(&hash, &timestamp); err != nil {
+			return "", fmt.Errorf("failed to scan commit: %w", err)
+		}
+		if timestamp.After(t) {
+			continue
+		}
+		if bestHash == "" || timestamp.After(bestTime) {
+			bestHash, bestTime = hash, timestamp
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("error iterating commits: %w", err)
+	}
+	return bestHash, nil
+}
+
+// nullString stores empty strings as NULL
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
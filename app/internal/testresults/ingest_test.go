@@ -0,0 +1,204 @@
+package testresults
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/logging"
+	_ "modernc.org/sqlite"
+)
+
+const junitXML = `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="parser" timestamp="2024-01-10T12:30:00Z" time="2.5">
+    <testcase classname="parser.Lexer" name="TestTokens" time="0.5"/>
+    <testcase classname="parser.Lexer" name="TestStrings" time="1.0">
+      <failure message="expected quote">lexer_test.go:42: unterminated string</failure>
+    </testcase>
+    <testcase name="TestLegacy"><skipped/></testcase>
+    <testsuite name="nested">
+      <testcase name="TestNested"><error message="panic"/></testcase>
+    </testsuite>
+  </testsuite>
+</testsuites>`
+
+const goTestJSON = `{"Time":"2024-01-10T12:00:00Z","Action":"run","Package":"example/pkg","Test":"TestA"}
+{"Time":"2024-01-10T12:00:01Z","Action":"output","Package":"example/pkg","Test":"TestA","Output":"    a_test.go:10: boom\n"}
+{"Time":"2024-01-10T12:00:01Z","Action":"fail","Package":"example/pkg","Test":"TestA","Elapsed":1}
+# example/other [build output]
+{"Time":"2024-01-10T12:00:02Z","Action":"pass","Package":"example/pkg","Test":"TestB","Elapsed":0.2}
+{"Time":"2024-01-10T12:00:03Z","Action":"skip","Package":"example/pkg","Test":"TestC"}
+{"Time":"2024-01-10T12:00:04Z","Action":"fail","Package":"example/pkg","Elapsed":4}
+`
+
+func TestParse_JUnit(t *testing.T) {
+	run, err := Parse([]byte(junitXML), FormatAuto, time.Time{})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if run.Format != FormatJUnit {
+		t.Errorf("format = %q, want %q", run.Format, FormatJUnit)
+	}
+	if want := time.Date(2024, 1, 10, 12, 30, 2, 500_000_000, time.UTC); !run.RunTime.Equal(want) {
+		t.Errorf("run time = %v, want suite timestamp plus duration %v", run.RunTime, want)
+	}
+
+	passed, failed, skipped := run.Counts()
+	if len(run.Cases) != 4 || passed != 1 || failed != 2 || skipped != 1 {
+		t.Fatalf("cases = %+v, want 1 passed, 2 failed (failure and error), 1 skipped", run.Cases)
+	}
+	failure := run.Cases[1]
+	if failure.Suite != "parser.Lexer" || failure.Message != "expected quote\nlexer_test.go:42: unterminated string" {
+		t.Errorf("failed case = %+v", failure)
+	}
+	if run.Cases[2].Suite != "parser" {
+		t.Errorf("case without classname suite = %q, want the suite name", run.Cases[2].Suite)
+	}
+}
+
+func TestParse_GoTest(t *testing.T) {
+	run, err := Parse([]byte(goTestJSON), FormatAuto, time.Time{})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if run.Format != FormatGoTest {
+		t.Errorf("format = %q, want %q", run.Format, FormatGoTest)
+	}
+	if !run.RunTime.Equal(time.Date(2024, 1, 10, 12, 0, 4, 0, time.UTC)) {
+		t.Errorf("run time = %v, want the last event", run.RunTime)
+	}
+	if run.Duration != 4*time.Second {
+		t.Errorf("duration = %v, want the package elapsed time", run.Duration)
+	}
+
+	passed, failed, skipped := run.Counts()
+	if passed != 1 || failed != 1 || skipped != 1 {
+		t.Fatalf("cases = %+v, want one of each", run.Cases)
+	}
+	if run.Cases[0].Message != "a_test.go:10: boom" {
+		t.Errorf("failure message = %q", run.Cases[0].Message)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		format string
+	}{
+		{name: "empty", data: "  ", format: FormatAuto},
+		{name: "unknown content", data: "PASS", format: FormatAuto},
+		{name: "unknown format", data: junitXML, format: "tap"},
+		{name: "no cases", data: `<testsuite name="empty"/>`, format: FormatJUnit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse([]byte(tt.data), tt.format, time.Now()); err == nil {
+				t.Error("Parse() should fail")
+			}
+		})
+	}
+}
+
+func TestIngester_LinksSessionAndCommit(t *testing.T) {
+	dir := t.TempDir()
+	database, err := sql.Open("sqlite", filepath.Join(dir, "clio.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer database.Close()
+	if err := db.RunMigrations(database); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	base := time.Date(2024, 1, 10, 11, 0, 0, 0, time.UTC)
+	for _, s := range []struct {
+		id, project string
+		start       time.Time
+	}{
+		{"alpha-old", "alpha", base.Add(-2 * time.Hour)},
+		{"alpha-1", "alpha", base},
+		{"beta-1", "beta", base.Add(30 * time.Minute)},
+	} {
+		if _, err := database.Exec(`
+			INSERT INTO sessions (id, project, start_time, end_time, last_activity, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, s.id, s.project, s.start, s.start.Add(90*time.Minute), s.start, s.start, s.start); err != nil {
+			t.Fatalf("failed to create session: %v", err)
+		}
+	}
+	for _, c := range []struct {
+		hash      string
+		sessionID interface{}
+		at        time.Time
+	}{
+		{"before", "alpha-1", base.Add(10 * time.Minute)},
+		{"after", "alpha-1", base.Add(2 * time.Hour)},
+		{"other", nil, base.Add(55 * time.Minute)},
+	} {
+		if _, err := database.Exec(`
+			INSERT INTO commits (id, session_id, repository_path, repository_name, hash, message,
+				author_name, author_email, timestamp, branch, created_at, updated_at)
+			VALUES (?, ?, '/src/alpha', 'alpha', ?, 'msg', 'Dev', 'dev@example.com', ?, 'main', ?, ?)
+		`, c.hash, c.sessionID, c.hash, c.at, c.at, c.at); err != nil {
+			t.Fatalf("failed to create commit: %v", err)
+		}
+	}
+
+	path := filepath.Join(dir, "results.json")
+	if err := os.WriteFile(path, []byte(goTestJSON), 0644); err != nil {
+		t.Fatalf("failed to write results: %v", err)
+	}
+
+	ingester, err := NewIngester(database, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewIngester() error = %v", err)
+	}
+
+	// Run finished at 12:00, inside both alpha-1 and beta-1; the project picks alpha-1
+	result, err := ingester.Ingest(path, Options{Project: "Alpha"})
+	if err != nil {
+		t.Fatalf("Ingest() error = %v", err)
+	}
+	if result.SessionID != "alpha-1" || result.CommitHash != "before" {
+		t.Errorf("linked to session %q commit %q, want alpha-1 and the session's latest earlier commit", result.SessionID, result.CommitHash)
+	}
+	if result.Total != 3 || result.Failed != 1 || result.Duplicate {
+		t.Errorf("result = %+v", result)
+	}
+
+	var cases int
+	if err := database.QueryRow("SELECT COUNT(*) FROM test_cases WHERE run_id = ?", result.RunID).Scan(&cases); err != nil {
+		t.Fatalf("failed to count cases: %v", err)
+	}
+	if cases != 3 {
+		t.Errorf("stored %d cases, want 3", cases)
+	}
+
+	again, err := ingester.Ingest(path, Options{})
+	if err != nil {
+		t.Fatalf("second Ingest() error = %v", err)
+	}
+	if !again.Duplicate {
+		t.Error("re-ingesting an unchanged file should be a duplicate")
+	}
+
+	// Without a project the most recently started covering session wins
+	unlinked := filepath.Join(dir, "rerun.json")
+	if err := os.WriteFile(unlinked, []byte(strings.Replace(goTestJSON, "TestB", "TestD", -1)), 0644); err != nil {
+		t.Fatalf("failed to write results: %v", err)
+	}
+	result, err = ingester.Ingest(unlinked, Options{})
+	if err != nil {
+		t.Fatalf("Ingest() error = %v", err)
+	}
+	if result.SessionID != "beta-1" || result.CommitHash != "other" {
+		t.Errorf("linked to session %q commit %q, want beta-1 and the latest earlier commit overall", result.SessionID, result.CommitHash)
+	}
+}
@@ -0,0 +1,322 @@
+package testresults
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// FormatAuto detects the format from the file contents
+	FormatAuto = "auto"
+	// FormatJUnit is JUnit XML, written by most test runners and CI plugins
+	FormatJUnit = "junit"
+	// FormatGoTest is the event stream written by go test -json
+	FormatGoTest = "gotest"
+
+	// StatusPassed marks a test that passed
+	StatusPassed = "passed"
+	// StatusFailed marks a test that failed or errored
+	StatusFailed = "failed"
+	// StatusSkipped marks a test that was skipped
+	StatusSkipped = "skipped"
+
+	// maxMessageLength caps the failure output stored per test
+	maxMessageLength = 4096
+	// maxGoTestLineSize is the longest go test -json event line accepted
+	maxGoTestLineSize = 1024 * 1024
+)
+
+// Formats returns the supported result formats
+func Formats() []string {
+	return []string{FormatAuto, FormatJUnit, FormatGoTest}
+}
+
+// Run is a parsed test run
+type Run struct {
+	Format   string
+	RunTime  time.Time // When the run finished
+	Duration time.Duration
+	Cases    []Case
+}
+
+// Case is a single test outcome
+type Case struct {
+	Suite    string
+	Name     string
+	Status   string // StatusPassed, StatusFailed, or StatusSkipped
+	Duration time.Duration
+	Message  string // Failure or skip output, truncated to maxMessageLength
+}
+
+// Counts returns the number of passed, failed, and skipped cases
+func (r *Run) Counts() (passed, failed, skipped int) {
+	for _, c := range r.Cases {
+		switch c.Status {
+		case StatusPassed:
+			passed++
+		case StatusFailed:
+			failed++
+		case StatusSkipped:
+			skipped++
+		}
+	}
+	return passed, failed, skipped
+}
+
+// Parse reads test results in the given format. Runs that don't record when they
+// finished are given fallbackTime.
+func Parse(data []byte, format string, fallbackTime time.Time) (*Run, error) {
+	if format == "" || format == FormatAuto {
+		detected, err := detectFormat(data)
+		if err != nil {
+			return nil, err
+		}
+		format = detected
+	}
+
+	var run *Run
+	var err error
+	switch format {
+	case FormatJUnit:
+		run, err = parseJUnit(data)
+	case FormatGoTest:
+		run, err = parseGoTest(data)
+	default:
+		return nil, fmt.Errorf("unsupported test result format %q (supported: %s)", format, strings.Join(Formats(), ", "))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s results: %w", format, err)
+	}
+	if len(run.Cases) == 0 {
+		return nil, fmt.Errorf("no test cases found in %s results", format)
+	}
+
+	run.Format = format
+	if run.RunTime.IsZero() {
+		run.RunTime = fallbackTime
+	}
+	return run, nil
+}
+
+// detectFormat distinguishes JUnit XML from go test -json by the first non-space byte
+func detectFormat(data []byte) (string, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return "", fmt.Errorf("test results are empty")
+	}
+	switch trimmed[0] {
+	case '<':
+		return FormatJUnit, nil
+	case '{':
+		return FormatGoTest, nil
+	}
+	return "", fmt.Errorf("unrecognised test result format (supported: %s)", strings.Join(Formats()[1:], ", "))
+}
+
+// junitSuite is a <testsuite>, which may nest further suites
+type junitSuite struct {
+	Name      string       `xml:"name,attr"`
+	Timestamp string       `xml:"timestamp,attr"`
+	Time      string       `xml:"time,attr"`
+	Suites    []junitSuite `xml:"testsuite"`
+	Cases     []junitCase  `xml:"testcase"`
+}
+
+// junitCase is a <testcase> with its outcome elements
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitOutcome `xml:"failure"`
+	Error     *junitOutcome `xml:"error"`
+	Skipped   *junitOutcome `xml:"skipped"`
+}
+
+// junitOutcome is a <failure>, <error>, or <skipped> element
+type junitOutcome struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// text returns the outcome's message and body
+func (o *junitOutcome) text() string {
+	return truncate(strings.TrimSpace(strings.TrimSpace(o.Message) + "\n" + strings.TrimSpace(o.Body)))
+}
+
+// parseJUnit reads a <testsuites> or <testsuite> document
+func parseJUnit(data []byte) (*Run, error) {
+	var root struct {
+		XMLName xml.Name
+		junitSuite
+	}
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+
+	var suites []junitSuite
+	switch root.XMLName.Local {
+	case "testsuites":
+		suites = root.Suites
+	case "testsuite":
+		suites = []junitSuite{root.junitSuite}
+	default:
+		return nil, fmt.Errorf("unexpected root element <%s>", root.XMLName.Local)
+	}
+
+	run := &Run{}
+	for _, suite := range suites {
+		run.addJUnitSuite(suite)
+	}
+	return run, nil
+}
+
+// addJUnitSuite adds a suite's cases, and those of nested suites, to the run
+func (r *Run) addJUnitSuite(suite junitSuite) {
+	suiteDuration := parseSeconds(suite.Time)
+	r.Duration += suiteDuration
+	if started, ok := parseJUnitTimestamp(suite.Timestamp); ok {
+		if finished := started.Add(suiteDuration); finished.After(r.RunTime) {
+			r.RunTime = finished
+		}
+	}
+
+	for _, tc := range suite.Cases {
+		c := Case{
+			Suite:    tc.ClassName,
+			Name:     tc.Name,
+			Status:   StatusPassed,
+			Duration: parseSeconds(tc.Time),
+		}
+		if c.Suite == "" {
+			c.Suite = suite.Name
+		}
+		switch {
+		case tc.Failure != nil:
+			c.Status, c.Message = StatusFailed, tc.Failure.text()
+		case tc.Error != nil:
+			c.Status, c.Message = StatusFailed, tc.Error.text()
+		case tc.Skipped != nil:
+			c.Status, c.Message = StatusSkipped, tc.Skipped.text()
+		}
+		r.Cases = append(r.Cases, c)
+	}
+
+	for _, nested := range suite.Suites {
+		r.addJUnitSuite(nested)
+	}
+}
+
+// parseJUnitTimestamp reads a suite timestamp, which runners write with or without a zone
+func parseJUnitTimestamp(value string) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999"} {
+		if parsed, err := time.ParseInLocation(layout, strings.TrimSpace(value), time.Local); err == nil {
+			return parsed, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseSeconds reads a duration in (possibly fractional) seconds, returning zero when invalid
+func parseSeconds(value string) time.Duration {
+	var seconds float64
+	if _, err := fmt.Sscanf(strings.TrimSpace(value), "%g", &seconds); err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// goTestEvent is one line of go test -json output
+type goTestEvent struct {
+	Time    time.Time `json:"Time"`
+	Action  string    `json:"Action"`
+	Package string    `json:"Package"`
+	Test    string    `json:"Test"`
+	Elapsed float64   `json:"Elapsed"`
+	Output  string    `json:"Output"`
+}
+
+// parseGoTest reads go test -json events, recording the final action of each test
+func parseGoTest(data []byte) (*Run, error) {
+	type testKey struct{ pkg, test string }
+	outputs := make(map[testKey]*strings.Builder)
+	indexes := make(map[testKey]int)
+	run := &Run{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxGoTestLineSize)
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 || text[0] != '{' {
+			continue // go test interleaves non-JSON build output
+		}
+		var event goTestEvent
+		if err := json.Unmarshal(text, &event); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if event.Time.After(run.RunTime) {
+			run.RunTime = event.Time
+		}
+		if event.Test == "" {
+			if event.Action == "pass" || event.Action == "fail" {
+				run.Duration += time.Duration(event.Elapsed * float64(time.Second))
+			}
+			continue
+		}
+
+		key := testKey{event.Package, event.Test}
+		var status string
+		switch event.Action {
+		case "output":
+			if outputs[key] == nil {
+				outputs[key] = &strings.Builder{}
+			}
+			if outputs[key].Len() < maxMessageLength {
+				outputs[key].WriteString(event.Output)
+			}
+			continue
+		case "pass":
+			status = StatusPassed
+		case "fail":
+			status = StatusFailed
+		case "skip":
+			status = StatusSkipped
+		default:
+			continue
+		}
+
+		c := Case{
+			Suite:    event.Package,
+			Name:     event.Test,
+			Status:   status,
+			Duration: time.Duration(event.Elapsed * float64(time.Second)),
+		}
+		if status != StatusPassed && outputs[key] != nil {
+			c.Message = truncate(strings.TrimSpace(outputs[key].String()))
+		}
+		// A test run more than once (-count) keeps its last outcome
+		if i, ok := indexes[key]; ok {
+			run.Cases[i] = c
+		} else {
+			indexes[key] = len(run.Cases)
+			run.Cases = append(run.Cases, c)
+		}
+		delete(outputs, key)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// truncate caps s at maxMessageLength bytes
+func truncate(s string) string {
+	if len(s) <= maxMessageLength {
+		return s
+	}
+	return s[:maxMessageLength]
+}
@@ -0,0 +1,286 @@
+// Package timeline draws captured activity on a time axis in the terminal, to
+// show how a day or session unfolded.
+package timeline
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stwalsh4118/clio/pkg/export"
+)
+
+const (
+	// DefaultWidth is how many columns the time axis spans
+	DefaultWidth = 60
+	// MinWidth is the narrowest time axis that still fits its labels
+	MinWidth = 20
+	// labelWidth is the width of the lane labels before the axis
+	labelWidth = 12
+	// axisLabelEvery is the fewest columns between time axis labels
+	axisLabelEvery = 10
+	// axisTimeLayout labels the time axis
+	axisTimeLayout = "15:04"
+	// noProject labels activity in sessions without a project
+	noProject = "(no project)"
+)
+
+// densityLevels draws a column's share of its lane's busiest column, from a
+// little activity to the most
+const densityLevels = ".:-=+*#@"
+
+// bucketSizes are the column widths a timeline picks from, so axis labels land
+// on round times
+var bucketSizes = []time.Duration{
+	time.Minute, 2 * time.Minute, 5 * time.Minute, 10 * time.Minute, 15 * time.Minute,
+	20 * time.Minute, 30 * time.Minute, time.Hour, 2 * time.Hour, 3 * time.Hour,
+	6 * time.Hour, 12 * time.Hour, 24 * time.Hour,
+}
+
+// Lane counts one kind of activity per column
+type Lane struct {
+	Label  string // "messages", "tools", or "commits"
+	Counts []int
+	Total  int
+}
+
+// Project is one project's lanes
+type Project struct {
+	Name   string
+	Lanes  []Lane // messages, tools, commits
+	Active int    // Columns with any activity
+}
+
+// Timeline is activity bucketed into columns of equal duration
+type Timeline struct {
+	Start    time.Time // Start of the first column
+	Bucket   time.Duration
+	Columns  int
+	Projects []Project // Most activity first
+}
+
+// End returns the end of the last column
+func (t *Timeline) End() time.Time {
+	return t.Start.Add(time.Duration(t.Columns) * t.Bucket)
+}
+
+// Bounds returns the earliest and latest message or commit of sessions between
+// start and end, and false when there are none
+func Bounds(sessions []export.Session, start, end time.Time) (time.Time, time.Time, bool) {
+	var first, last time.Time
+	found := false
+	note := func(at time.Time) {
+		if at.Before(start) || !at.Before(end) {
+			return
+		}
+		if !found || at.Before(first) {
+			first = at
+		}
+		if !found || at.After(last) {
+			last = at
+		}
+		found = true
+	}
+	for _, session := range sessions {
+		for _, conversation := range session.Conversations {
+			for _, message := range conversation.Messages {
+				note(message.CreatedAt)
+			}
+		}
+		for _, commit := range session.Commits {
+			note(commit.Timestamp)
+		}
+	}
+	return first, last, found
+}
+
+// Build buckets the messages, tool calls, and commits of sessions between start
+// and end into at most width columns per project. The column width is rounded
+// up to a round duration, and start down to a multiple of it in local time.
+func Build(sessions []export.Session, start, end time.Time, width int) *Timeline {
+	if width < MinWidth {
+		width = MinWidth
+	}
+	span := end.Sub(start)
+	bucket := bucketSizes[len(bucketSizes)-1]
+	for _, size := range bucketSizes {
+		if time.Duration(width)*size >= span {
+			bucket = size
+			break
+		}
+	}
+
+	// Truncate in local time so hours and days line up with the clock
+	_, offset := start.Local().Zone()
+	shift := time.Duration(offset) * time.Second
+	axisStart := start.Add(shift).Truncate(bucket).Add(-shift)
+	columns := int((end.Sub(axisStart) + bucket - 1) / bucket)
+	if columns < 1 {
+		columns = 1
+	}
+	t := &Timeline{Start: axisStart, Bucket: bucket, Columns: columns}
+
+	projects := make(map[string]*Project)
+	add := func(project string, lane int, at time.Time, count int) {
+		if at.Before(start) || !at.Before(end) || count == 0 {
+			return
+		}
+		if project == "" {
+			project = noProject
+		}
+		p := projects[project]
+		if p == nil {
+			p = &Project{Name: project}
+			for _, label := range []string{"messages", "tools", "commits"} {
+				p.Lanes = append(p.Lanes, Lane{Label: label, Counts: make([]int, columns)})
+			}
+			projects[project] = p
+		}
+		column := int(at.Sub(axisStart) / bucket)
+		p.Lanes[lane].Counts[column] += count
+		p.Lanes[lane].Total += count
+	}
+
+	for _, session := range sessions {
+		for _, conversation := range session.Conversations {
+			for _, message := range conversation.Messages {
+				add(session.Project, 0, message.CreatedAt, 1)
+				add(session.Project, 1, message.CreatedAt, len(message.ToolCalls))
+			}
+		}
+		for _, commit := range session.Commits {
+			add(session.Project, 2, commit.Timestamp, 1)
+		}
+	}
+
+	for _, p := range projects {
+		for column := 0; column < columns; column++ {
+			for _, lane := range p.Lanes {
+				if lane.Counts[column] > 0 {
+					p.Active++
+					break
+				}
+			}
+		}
+		t.Projects = append(t.Projects, *p)
+	}
+	sort.Slice(t.Projects, func(i, j int) bool {
+		a, b := t.Projects[i], t.Projects[j]
+		if a.Active != b.Active {
+			return a.Active > b.Active
+		}
+		return a.Name < b.Name
+	})
+	return t
+}
+
+// Render draws the timeline: a time axis, then per project a density bar per
+// lane and a line of totals. Message and tool lanes scale to their busiest
+// column across projects; commit lanes show counts.
+func Render(w io.Writer, t *Timeline) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s - %s, 1 column = %s\n\n", t.Start.Local().Format("2006-01-02 15:04"), t.End().Local().Format("2006-01-02 15:04"), formatDuration(t.Bucket))
+	if len(t.Projects) == 0 {
+		b.WriteString("No activity captured\n")
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+
+	b.WriteString(axis(t))
+
+	// Lanes of the same kind share a scale so projects can be compared
+	peaks := make([]int, 3)
+	for _, p := range t.Projects {
+		for i, lane := range p.Lanes {
+			for _, count := range lane.Counts {
+				peaks[i] = max(peaks[i], count)
+			}
+		}
+	}
+
+	for _, p := range t.Projects {
+		fmt.Fprintf(&b, "\n%s\n", p.Name)
+		for i, lane := range p.Lanes {
+			fmt.Fprintf(&b, "  %-*s|", labelWidth-3, lane.Label)
+			for _, count := range lane.Counts {
+				if lane.Label == "commits" {
+					b.WriteByte(commitMark(count))
+				} else {
+					b.WriteByte(density(count, peaks[i]))
+				}
+			}
+			b.WriteString("|\n")
+		}
+		fmt.Fprintf(&b, "  %d message(s), %d tool call(s), %d commit(s), active %s\n",
+			p.Lanes[0].Total, p.Lanes[1].Total, p.Lanes[2].Total, formatDuration(time.Duration(p.Active)*t.Bucket))
+	}
+
+	b.WriteString("\nDensity: " + densityLevels + " (least to most); commits are counted, + for 10 or more\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// axis draws time labels above the columns, at least axisLabelEvery columns
+// apart and on round times where the column width allows
+func axis(t *Timeline) string {
+	step := time.Duration(axisLabelEvery) * t.Bucket
+	for _, size := range bucketSizes {
+		if size >= step {
+			step = size
+			break
+		}
+	}
+
+	line := []byte(strings.Repeat(" ", labelWidth+t.Columns+len(axisTimeLayout)))
+	ticks := []byte(strings.Repeat(" ", labelWidth+t.Columns))
+	for column := 0; column < t.Columns; column++ {
+		at := t.Start.Add(time.Duration(column) * t.Bucket)
+		// Each column uses its own offset, so ticks stay on round times across a DST change
+		_, offset := at.Local().Zone()
+		shift := time.Duration(offset) * time.Second
+		if at.Add(shift).Truncate(step) != at.Add(shift) {
+			continue
+		}
+		copy(line[labelWidth+column:], at.Local().Format(axisTimeLayout))
+		ticks[labelWidth+column] = '|'
+	}
+	return strings.TrimRight(string(line), " ") + "\n" + strings.TrimRight(string(ticks), " ") + "\n"
+}
+
+// density draws count as a share of peak
+func density(count, peak int) byte {
+	if count == 0 || peak == 0 {
+		return ' '
+	}
+	level := (count*len(densityLevels) - 1) / peak
+	return densityLevels[min(level, len(densityLevels)-1)]
+}
+
+// commitMark draws a column's commit count
+func commitMark(count int) byte {
+	switch {
+	case count == 0:
+		return ' '
+	case count > 9:
+		return '+'
+	default:
+		return byte('0' + count)
+	}
+}
+
+// formatDuration formats a duration as days, hours, and minutes, e.g. 1h30m or 15m
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	switch {
+	case d >= 24*time.Hour && d%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", int(d.Hours())/24)
+	case d >= time.Hour && d%time.Hour == 0:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	default:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+}
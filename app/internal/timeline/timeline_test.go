@@ -0,0 +1,168 @@
+package timeline
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/pkg/export"
+)
+
+func testSessions() []export.Session {
+	start := time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)
+	return []export.Session{
+		{
+			ID:        "session-1",
+			Project:   "clio",
+			StartTime: start,
+			Conversations: []export.Conversation{{ComposerID: "c1", Messages: []export.Message{
+				{Role: "user", Text: "Fix the lexer", CreatedAt: start.Add(5 * time.Minute)},
+				{Role: "agent", Text: "Done", CreatedAt: start.Add(7 * time.Minute),
+					ToolCalls: []export.ToolCall{{Name: "read_file"}, {Name: "edit_file"}}},
+				{Role: "user", Text: "Now the parser", CreatedAt: start.Add(65 * time.Minute)},
+			}}},
+			Commits: []export.Commit{
+				{Hash: "aaa", Timestamp: start.Add(8 * time.Minute)},
+				{Hash: "bbb", Timestamp: start.Add(9 * time.Minute)},
+			},
+		},
+		{
+			ID:        "session-2",
+			StartTime: start,
+			Conversations: []export.Conversation{{ComposerID: "c2", Messages: []export.Message{
+				{Role: "user", Text: "Question", CreatedAt: start.Add(30 * time.Minute)},
+				// Outside the window
+				{Role: "user", Text: "Tomorrow", CreatedAt: start.Add(24 * time.Hour)},
+			}}},
+		},
+	}
+}
+
+func TestBounds(t *testing.T) {
+	start := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	first, last, ok := Bounds(testSessions(), start, start.Add(24*time.Hour))
+	if !ok {
+		t.Fatal("Bounds() found no activity")
+	}
+	if want := start.Add(9*time.Hour + 5*time.Minute); !first.Equal(want) {
+		t.Errorf("first = %v, want %v", first, want)
+	}
+	if want := start.Add(10*time.Hour + 5*time.Minute); !last.Equal(want) {
+		t.Errorf("last = %v, want %v", last, want)
+	}
+
+	if _, _, ok := Bounds(testSessions(), start.Add(-24*time.Hour), start); ok {
+		t.Error("Bounds() found activity in an empty window")
+	}
+}
+
+func TestBuild(t *testing.T) {
+	start := time.Date(2024, 1, 10, 9, 3, 0, 0, time.UTC)
+	end := time.Date(2024, 1, 10, 11, 0, 0, 0, time.UTC)
+	tl := Build(testSessions(), start, end, 30)
+
+	// Two hours in 30 columns rounds up to 5 minute columns from 09:00
+	if tl.Bucket != 5*time.Minute {
+		t.Errorf("Bucket = %v, want 5m", tl.Bucket)
+	}
+	if want := time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC); !tl.Start.Equal(want) {
+		t.Errorf("Start = %v, want %v", tl.Start, want)
+	}
+	if tl.Columns != 24 {
+		t.Errorf("Columns = %d, want 24", tl.Columns)
+	}
+
+	if len(tl.Projects) != 2 || tl.Projects[0].Name != "clio" || tl.Projects[1].Name != noProject {
+		t.Fatalf("Projects = %+v, want clio then %s", tl.Projects, noProject)
+	}
+	clio := tl.Projects[0]
+	messages, tools, commits := clio.Lanes[0], clio.Lanes[1], clio.Lanes[2]
+	if messages.Total != 3 || messages.Counts[1] != 2 || messages.Counts[13] != 1 {
+		t.Errorf("messages = %+v, want 2 in column 1 and 1 in column 13", messages)
+	}
+	if tools.Total != 2 || tools.Counts[1] != 2 {
+		t.Errorf("tools = %+v, want 2 in column 1", tools)
+	}
+	// The commit stored twice is counted once
+	if commits.Total != 2 || commits.Counts[1] != 2 {
+		t.Errorf("commits = %+v, want 2 in column 1", commits)
+	}
+	if clio.Active != 2 {
+		t.Errorf("Active = %d, want 2", clio.Active)
+	}
+	if tl.Projects[1].Lanes[0].Total != 1 {
+		t.Errorf("%s messages = %d, want the one inside the window", noProject, tl.Projects[1].Lanes[0].Total)
+	}
+}
+
+func TestRender(t *testing.T) {
+	start := time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)
+	tl := Build(testSessions(), start, start.Add(2*time.Hour), 30)
+
+	var out bytes.Buffer
+	if err := Render(&out, tl); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	got := out.String()
+	for _, want := range []string{
+		"1 column = 5m",
+		"\n" + strings.Repeat(" ", labelWidth) + "|" + strings.Repeat(" ", 11) + "|\n",
+		"\nclio\n",
+		"  messages |" + " @" + strings.Repeat(" ", 11) + "=" + strings.Repeat(" ", 10) + "|",
+		"  tools    |" + " @" + strings.Repeat(" ", 22) + "|",
+		"  commits  |" + " 2" + strings.Repeat(" ", 22) + "|",
+		"  3 message(s), 2 tool call(s), 2 commit(s), active 10m",
+		"\n" + noProject + "\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+
+	var empty bytes.Buffer
+	if err := Render(&empty, Build(nil, start, start.Add(time.Hour), 30)); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if !strings.Contains(empty.String(), "No activity captured") {
+		t.Errorf("empty output = %q", empty.String())
+	}
+}
+
+func TestAxis_DSTChange(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %v", err)
+	}
+	local := time.Local
+	time.Local = newYork
+	defer func() { time.Local = local }()
+
+	// Clocks spring forward at 02:00, so the night is five hours long
+	start := time.Date(2026, 3, 8, 0, 0, 0, 0, newYork)
+	tl := Build(nil, start, time.Date(2026, 3, 8, 6, 0, 0, 0, newYork), 30)
+
+	// Labels stay on round local times after the change
+	axisLine := strings.SplitN(axis(tl), "\n", 2)[0]
+	if !strings.Contains(axisLine, "00:00") || !strings.Contains(axisLine, "04:00") || strings.Contains(axisLine, "03:00") {
+		t.Errorf("axis = %q, want 00:00 and 04:00", axisLine)
+	}
+}
+
+func TestDensity(t *testing.T) {
+	tests := []struct {
+		count, peak int
+		want        byte
+	}{
+		{0, 10, ' '},
+		{1, 10, '.'},
+		{5, 10, '='},
+		{10, 10, '@'},
+		{1, 1, '@'},
+	}
+	for _, tt := range tests {
+		if got := density(tt.count, tt.peak); got != tt.want {
+			t.Errorf("density(%d, %d) = %q, want %q", tt.count, tt.peak, got, tt.want)
+		}
+	}
+}
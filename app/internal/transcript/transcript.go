@@ -0,0 +1,174 @@
+// Package transcript renders a single conversation as a Markdown transcript for
+// sharing, independent of the session it was captured in.
+package transcript
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/stwalsh4118/clio/pkg/export"
+)
+
+// timeLayout is the timestamp format used in transcripts
+const timeLayout = "2006-01-02 15:04"
+
+// fenceOpen matches a code fence line, capturing its indent, backticks or
+// tildes, and info string
+var fenceOpen = regexp.MustCompile("^( {0,3})(```+|~~~+)\\s*(.*)$")
+
+// languageHints guess the language of an unlabelled code block from its first
+// non-blank line, checked in order
+var languageHints = []struct {
+	pattern  *regexp.Regexp
+	language string
+}{
+	{regexp.MustCompile(`^#!.*\b(ba|z)?sh\b|^\$ `), "bash"},
+	{regexp.MustCompile(`^package \w+$|^func (\(\w+ \*?\w+\) )?\w+\(|^import \($`), "go"},
+	{regexp.MustCompile(`^(def |class \w+(\(.*\))?:|from [\w.]+ import |import \w+$)`), "python"},
+	{regexp.MustCompile(`^(fn |use \w+::|let mut |impl )`), "rust"},
+	{regexp.MustCompile(`^(interface \w+|type \w+ = |export (interface|type) )`), "typescript"},
+	{regexp.MustCompile(`^(const |let |function |export |import .* from )`), "javascript"},
+	{regexp.MustCompile(`(?i)^(select|insert into|update \w+ set|create (table|index)|alter table|delete from) `), "sql"},
+	{regexp.MustCompile(`^<(!doctype|html|div|\w+[ >])`), "html"},
+}
+
+// Write renders a conversation as Markdown: a heading, then each message under
+// its role and time. Code blocks without a language get one guessed from their
+// content, and blocks left open are closed. Agent reasoning is collapsed in a
+// <details> block and the tools the agent ran are listed after its message.
+func Write(w io.Writer, conversation export.Conversation) error {
+	text, _ := render(conversation)
+	if _, err := io.WriteString(w, text); err != nil {
+		return fmt.Errorf("failed to write transcript: %w", err)
+	}
+	return nil
+}
+
+// MessageLines returns the 1-based line of each message's heading in the
+// transcript Write renders, for opening it at a message
+func MessageLines(conversation export.Conversation) []int {
+	_, lines := render(conversation)
+	return lines
+}
+
+// render renders the transcript, returning it with the line of each message heading
+func render(conversation export.Conversation) (string, []int) {
+	var b strings.Builder
+	lines := make([]int, 0, len(conversation.Messages))
+	newlines, counted := 0, 0 // Newlines in the first counted bytes of b
+
+	title := conversation.Name
+	if title == "" {
+		title = conversation.ComposerID
+	}
+	fmt.Fprintf(&b, "# %s\n\n", title)
+	fmt.Fprintf(&b, "Conversation `%s`, %d message(s)", conversation.ComposerID, len(conversation.Messages))
+	if n := len(conversation.Messages); n > 0 {
+		fmt.Fprintf(&b, ", %s - %s", conversation.Messages[0].CreatedAt.Local().Format(timeLayout),
+			conversation.Messages[n-1].CreatedAt.Local().Format(timeLayout))
+	}
+	b.WriteString("\n")
+
+	for _, message := range conversation.Messages {
+		// The heading follows a blank line
+		newlines += strings.Count(b.String()[counted:], "\n")
+		counted = b.Len()
+		lines = append(lines, newlines+2)
+		fmt.Fprintf(&b, "\n## %s (%s)\n\n", roleTitle(message.Role), message.CreatedAt.Local().Format(timeLayout))
+		if thinking := strings.TrimSpace(message.Thinking); thinking != "" {
+			fmt.Fprintf(&b, "<details>\n<summary>Thinking</summary>\n\n%s\n\n</details>\n\n", fenceCode(thinking))
+		}
+		if text := strings.TrimSpace(message.Text); text != "" {
+			b.WriteString(fenceCode(text) + "\n")
+		}
+		if len(message.ToolCalls) > 0 {
+			calls := make([]string, len(message.ToolCalls))
+			for i, call := range message.ToolCalls {
+				calls[i] = "`" + call.Name + "`"
+				if call.Status != "" && call.Status != "completed" {
+					calls[i] += " (" + call.Status + ")"
+				}
+			}
+			fmt.Fprintf(&b, "\n*Tools: %s*\n", strings.Join(calls, ", "))
+		}
+	}
+
+	return b.String(), lines
+}
+
+// roleTitle capitalizes a message role for a heading
+func roleTitle(role string) string {
+	switch role {
+	case "user":
+		return "User"
+	case "agent":
+		return "Agent"
+	case "":
+		return "Unknown"
+	default:
+		return strings.ToUpper(role[:1]) + role[1:]
+	}
+}
+
+// fenceCode labels the code blocks in Markdown text that have no language with
+// one guessed from their content, and closes a block left open at the end
+func fenceCode(text string) string {
+	lines := strings.Split(text, "\n")
+	var out []string
+	open := -1 // Index in out of the open fence line
+	var marker string
+	for _, line := range lines {
+		match := fenceOpen.FindStringSubmatch(line)
+		if open < 0 {
+			if match != nil {
+				open, marker = len(out), match[2]
+			}
+			out = append(out, line)
+			continue
+		}
+		// A fence closes on the same character, at least as long, with no info string
+		if match != nil && match[2][0] == marker[0] && len(match[2]) >= len(marker) && match[3] == "" {
+			labelFence(out, open)
+			open = -1
+		}
+		out = append(out, line)
+	}
+	if open >= 0 {
+		labelFence(out, open)
+		out = append(out, marker)
+	}
+	return strings.Join(out, "\n")
+}
+
+// labelFence sets the language of the fence at out[open] when it has none
+func labelFence(out []string, open int) {
+	match := fenceOpen.FindStringSubmatch(out[open])
+	if match[3] != "" {
+		return
+	}
+	if language := guessLanguage(out[open+1:]); language != "" {
+		out[open] = match[1] + match[2] + language
+	}
+}
+
+// guessLanguage guesses the language of a code block's lines, or returns ""
+func guessLanguage(lines []string) string {
+	code := strings.TrimSpace(strings.Join(lines, "\n"))
+	if code == "" {
+		return ""
+	}
+	if (code[0] == '{' || code[0] == '[') && json.Valid([]byte(code)) {
+		return "json"
+	}
+	first, _, _ := strings.Cut(code, "\n")
+	first = strings.TrimSpace(first)
+	for _, hint := range languageHints {
+		if hint.pattern.MatchString(first) {
+			return hint.language
+		}
+	}
+	return ""
+}
@@ -0,0 +1,73 @@
+package transcript
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/pkg/export"
+)
+
+func TestWrite(t *testing.T) {
+	start := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	conversation := export.Conversation{
+		ComposerID: "composer-1",
+		Name:       "Fix the lexer",
+		Messages: []export.Message{
+			{Role: "user", Text: "Why does this panic?\n```\npackage main\n```", CreatedAt: start},
+			{Role: "agent", Text: "The index is off by one.", Thinking: "Check the loop bounds", CreatedAt: start.Add(time.Minute),
+				ToolCalls: []export.ToolCall{{Name: "read_file", Status: "completed"}, {Name: "run_terminal_cmd", Status: "error"}}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, conversation); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"# Fix the lexer\n\nConversation `composer-1`, 2 message(s), ",
+		"\n## User (",
+		"Why does this panic?\n```go\npackage main\n```\n",
+		"\n## Agent (",
+		"<details>\n<summary>Thinking</summary>\n\nCheck the loop bounds\n\n</details>\n\nThe index is off by one.\n",
+		"*Tools: `read_file`, `run_terminal_cmd` (error)*",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("transcript missing %q:\n%s", want, out)
+		}
+	}
+
+	lines := strings.Split(out, "\n")
+	for i, line := range MessageLines(conversation) {
+		if !strings.HasPrefix(lines[line-1], "## "+roleTitle(conversation.Messages[i].Role)+" (") {
+			t.Errorf("MessageLines()[%d] = %d, which is %q", i, line, lines[line-1])
+		}
+	}
+}
+
+func TestFenceCode(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "labelled", text: "```ts\nconst x = 1\n```", want: "```ts\nconst x = 1\n```"},
+		{name: "python", text: "```\ndef main():\n    pass\n```", want: "```python\ndef main():\n    pass\n```"},
+		{name: "json", text: "~~~\n{\"a\": 1}\n~~~", want: "~~~json\n{\"a\": 1}\n~~~"},
+		{name: "shell", text: "```\n$ go test ./...\n```", want: "```bash\n$ go test ./...\n```"},
+		{name: "unknown", text: "```\nhello\n```", want: "```\nhello\n```"},
+		{name: "unclosed", text: "Run:\n```\nSELECT * FROM t", want: "Run:\n```sql\nSELECT * FROM t\n```"},
+		{name: "nested fence", text: "````\n```\nx\n```\n````", want: "````\n```\nx\n```\n````"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fenceCode(tt.text); got != tt.want {
+				t.Errorf("fenceCode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
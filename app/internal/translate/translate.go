@@ -0,0 +1,134 @@
+// Package translate translates exported messages written in another language
+// into a target language with an external command, such as a script that asks
+// an LLM, so a post can be written in English from chats held in German.
+// Messages are picked by their detected language (see the language package);
+// those whose language couldn't be told are left as they are.
+package translate
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stwalsh4118/clio/pkg/export"
+)
+
+// maxCommandOutput caps what a translation command may print
+const maxCommandOutput = 1 << 20
+
+// Translator translates text from one language into another
+type Translator interface {
+	// Translate returns text, written in source, translated into target; both
+	// are ISO 639-1 codes
+	Translate(ctx context.Context, text, source, target string) (string, error)
+}
+
+// commandTranslator translates with an external command
+type commandTranslator struct {
+	command string
+}
+
+// NewCommandTranslator creates a translator running command with the text on
+// stdin and the languages in CLIO_SOURCE_LANGUAGE and CLIO_TARGET_LANGUAGE;
+// what it prints is the translation
+func NewCommandTranslator(command string) (Translator, error) {
+	if command == "" {
+		return nil, fmt.Errorf("translation command cannot be empty")
+	}
+	return &commandTranslator{command: command}, nil
+}
+
+// Translate implements Translator
+func (t *commandTranslator) Translate(ctx context.Context, text, source, target string) (string, error) {
+	cmd := exec.CommandContext(ctx, t.command)
+	cmd.Stdin = strings.NewReader(text)
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), "CLIO_SOURCE_LANGUAGE="+source, "CLIO_TARGET_LANGUAGE="+target)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("translation command failed: %w", err)
+	}
+	if stdout.Len() > maxCommandOutput {
+		return "", fmt.Errorf("translation command printed more than %d bytes", maxCommandOutput)
+	}
+	translated := strings.TrimSpace(stdout.String())
+	if translated == "" {
+		return "", fmt.Errorf("translation command printed nothing")
+	}
+	return translated, nil
+}
+
+// cacheKey identifies a translation
+type cacheKey struct {
+	text, source, target string
+}
+
+// cachedTranslator remembers translations, so repeated texts such as "Please
+// continue" are translated once
+type cachedTranslator struct {
+	translator Translator
+	mu         sync.Mutex
+	cache      map[cacheKey]string
+}
+
+// NewCachedTranslator wraps translator so each text is only translated once
+// for the life of the returned translator
+func NewCachedTranslator(translator Translator) Translator {
+	return &cachedTranslator{translator: translator, cache: make(map[cacheKey]string)}
+}
+
+// Translate implements Translator
+func (t *cachedTranslator) Translate(ctx context.Context, text, source, target string) (string, error) {
+	key := cacheKey{text: text, source: source, target: target}
+	t.mu.Lock()
+	translated, ok := t.cache[key]
+	t.mu.Unlock()
+	if ok {
+		return translated, nil
+	}
+
+	translated, err := t.translator.Translate(ctx, text, source, target)
+	if err != nil {
+		return "", err
+	}
+	t.mu.Lock()
+	t.cache[key] = translated
+	t.mu.Unlock()
+	return translated, nil
+}
+
+// Sessions translates the text of messages in a known language other than
+// target into it, each within timeout, and returns how many it translated.
+// Translated messages have their Language set to target.
+func Sessions(ctx context.Context, translator Translator, sessions []export.Session, target string, timeout time.Duration) (int, error) {
+	translated := 0
+	for i := range sessions {
+		for j := range sessions[i].Conversations {
+			messages := sessions[i].Conversations[j].Messages
+			for k := range messages {
+				message := &messages[k]
+				if message.Language == "" || message.Language == target || strings.TrimSpace(message.Text) == "" {
+					continue
+				}
+
+				messageCtx, cancel := context.WithTimeout(ctx, timeout)
+				text, err := translator.Translate(messageCtx, message.Text, message.Language, target)
+				cancel()
+				if err != nil {
+					return translated, fmt.Errorf("failed to translate a message from %s: %w", message.Language, err)
+				}
+				message.Text = text
+				message.Language = target
+				translated++
+			}
+		}
+	}
+	return translated, nil
+}
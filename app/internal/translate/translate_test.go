@@ -0,0 +1,81 @@
+package translate
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/pkg/export"
+)
+
+func TestCommandTranslator(t *testing.T) {
+	if _, err := NewCommandTranslator(""); err == nil {
+		t.Error("NewCommandTranslator(\"\") expected error, got nil")
+	}
+
+	script := filepath.Join(t.TempDir(), "translate.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\necho \"$CLIO_SOURCE_LANGUAGE>$CLIO_TARGET_LANGUAGE: $(cat)\"\n"), 0755); err != nil {
+		t.Fatalf("failed to write script: %v", err)
+	}
+	translator, err := NewCommandTranslator(script)
+	if err != nil {
+		t.Fatalf("NewCommandTranslator() error = %v", err)
+	}
+	text, err := translator.Translate(context.Background(), "hallo", "de", "en")
+	if err != nil {
+		t.Fatalf("Translate() error = %v", err)
+	}
+	if text != "de>en: hallo" {
+		t.Errorf("Translate() = %q, want the command's output", text)
+	}
+}
+
+// countingTranslator prefixes text with its source language and counts its calls
+type countingTranslator struct {
+	calls int
+}
+
+func (t *countingTranslator) Translate(ctx context.Context, text, source, target string) (string, error) {
+	t.calls++
+	return source + ":" + text, nil
+}
+
+func TestSessions(t *testing.T) {
+	sessions := []export.Session{{
+		Conversations: []export.Conversation{{
+			Messages: []export.Message{
+				{Role: "user", Text: "Bitte weiter", Language: "de"},
+				{Role: "agent", Text: "Done.", Language: "en"},
+				{Role: "user", Text: "ok"},
+				{Role: "user", Text: "Bitte weiter", Language: "de"},
+			},
+		}},
+	}}
+
+	counting := &countingTranslator{}
+	n, err := Sessions(context.Background(), NewCachedTranslator(counting), sessions, "en", time.Minute)
+	if err != nil {
+		t.Fatalf("Sessions() error = %v", err)
+	}
+	if n != 2 {
+		t.Errorf("Sessions() translated %d messages, want 2", n)
+	}
+	if counting.calls != 1 {
+		t.Errorf("translator called %d times, want the repeated text translated once", counting.calls)
+	}
+
+	messages := sessions[0].Conversations[0].Messages
+	want := []export.Message{
+		{Role: "user", Text: "de:Bitte weiter", Language: "en"},
+		{Role: "agent", Text: "Done.", Language: "en"},
+		{Role: "user", Text: "ok"},
+		{Role: "user", Text: "de:Bitte weiter", Language: "en"},
+	}
+	for i := range want {
+		if messages[i].Text != want[i].Text || messages[i].Language != want[i].Language {
+			t.Errorf("message %d = %+v, want %+v", i, messages[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,206 @@
+// Package upgrade brings stored data up to date when a new clio version first opens
+// the database. Schema migrations run in db.Open; upgrade runs the derived-field
+// backfills the data needs and records the data version and the binaries using
+// it, so an older clio refuses the data instead of writing it in an outdated form
+// and the CLI can refuse to run alongside a daemon of another version.
+package upgrade
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/blobs"
+	"github.com/stwalsh4118/clio/internal/cursor"
+	"github.com/stwalsh4118/clio/internal/logging"
+)
+
+// DataVersion is the stored data format this clio writes. Bump it when a release
+// adds a backfill, so older binaries refuse data they would write in a form the
+// backfill already replaced.
+const DataVersion = 1
+
+// ErrNewerData is returned when the data was upgraded by a newer clio than this one
+var ErrNewerData = errors.New("database was upgraded by a newer clio")
+
+// State is the recorded data version and the clio versions using the data
+type State struct {
+	DataVersion   int    // Zero before the first upgrade
+	WrittenBy     string // clio version that last upgraded the data
+	DaemonVersion string // clio version of the daemon last started, empty once it stops
+	DaemonPID     int
+	UpdatedAt     time.Time
+}
+
+// Progress is called as a backfill works through the stored conversations
+type Progress func(backfill string, done, total int)
+
+// Result reports what an upgrade did
+type Result struct {
+	FromVersion int
+	ToVersion   int
+	Backfills   []cursor.BackfillResult
+}
+
+// Upgrader brings the stored data to DataVersion and records the daemon using it
+type Upgrader interface {
+	Needed() (bool, error)
+	Run(progress Progress) (*Result, error)
+	RecordDaemon(pid int) error
+	ClearDaemon(pid int) error
+}
+
+// upgrader implements Upgrader
+type upgrader struct {
+	db            *sql.DB
+	binaryVersion string
+	backfills     cursor.BackfillRunner
+	logger        logging.Logger
+}
+
+// NewUpgrader creates an upgrader for a clio binary of binaryVersion. blobStore
+// loads messages moved there so backfills rewrite them in full.
+func NewUpgrader(db *sql.DB, blobStore blobs.Store, binaryVersion string, logger logging.Logger) (Upgrader, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database cannot be nil")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	backfills, err := cursor.NewBackfillRunner(db, blobStore, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create backfill runner: %w", err)
+	}
+
+	return &upgrader{
+		db:            db,
+		binaryVersion: binaryVersion,
+		backfills:     backfills,
+		logger:        logger.With("component", "upgrader"),
+	}, nil
+}
+
+// Needed reports whether the data is behind DataVersion or has backfills pending
+func (u *upgrader) Needed() (bool, error) {
+	state, err := ReadState(u.db)
+	if err != nil {
+		return false, err
+	}
+	if err := Check(state, u.binaryVersion); err != nil {
+		return false, err
+	}
+	if state.DataVersion < DataVersion {
+		return true, nil
+	}
+
+	pending, err := u.backfills.Pending()
+	if err != nil {
+		return false, fmt.Errorf("failed to check pending backfills: %w", err)
+	}
+	return len(pending) > 0, nil
+}
+
+// Run applies pending backfills and records the data as DataVersion written by
+// this binary. It refuses data upgraded by a newer clio.
+func (u *upgrader) Run(progress Progress) (*Result, error) {
+	state, err := ReadState(u.db)
+	if err != nil {
+		return nil, err
+	}
+	if err := Check(state, u.binaryVersion); err != nil {
+		return nil, err
+	}
+
+	result := &Result{FromVersion: state.DataVersion, ToVersion: DataVersion}
+	if state.DataVersion < DataVersion {
+		u.logger.Info("upgrading data", "from_version", state.DataVersion, "to_version", DataVersion, "clio_version", u.binaryVersion)
+	}
+
+	backfills, err := u.backfills.RunWithProgress(cursor.BackfillProgress(progress))
+	result.Backfills = backfills
+	if err != nil {
+		// Completed backfills are recorded, so the next run resumes with the rest
+		return result, fmt.Errorf("failed to run backfills: %w", err)
+	}
+
+	if state.DataVersion == DataVersion && state.WrittenBy == u.binaryVersion && len(backfills) == 0 {
+		return result, nil
+	}
+	if _, err := u.db.Exec(`
+		INSERT INTO data_version (id, data_version, written_by, updated_at)
+		VALUES (1, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			data_version = excluded.data_version,
+			written_by = excluded.written_by,
+			updated_at = excluded.updated_at
+	`, DataVersion, u.binaryVersion, time.Now()); err != nil {
+		return result, fmt.Errorf("failed to record data version: %w", err)
+	}
+
+	u.logger.Info("data upgraded", "data_version", DataVersion, "clio_version", u.binaryVersion, "backfills", len(backfills))
+	return result, nil
+}
+
+// RecordDaemon records that a daemon of this binary's version runs as pid
+func (u *upgrader) RecordDaemon(pid int) error {
+	result, err := u.db.Exec(
+		"UPDATE data_version SET daemon_version = ?, daemon_pid = ?, updated_at = ? WHERE id = 1",
+		u.binaryVersion, pid, time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record daemon version: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return fmt.Errorf("failed to record daemon version: data version not recorded, run the upgrade first")
+	}
+	return nil
+}
+
+// ClearDaemon removes the daemon record if it is still pid's, when the daemon stops
+func (u *upgrader) ClearDaemon(pid int) error {
+	if _, err := u.db.Exec(
+		"UPDATE data_version SET daemon_version = '', daemon_pid = 0, updated_at = ? WHERE id = 1 AND daemon_pid = ?",
+		time.Now(), pid,
+	); err != nil {
+		return fmt.Errorf("failed to clear daemon version: %w", err)
+	}
+	return nil
+}
+
+// ReadState returns the recorded state. It works before migrations have run, so
+// callers can check versions before changing the database: a database without
+// the data_version table, or without a row in it, has a zero state.
+func ReadState(db *sql.DB) (*State, error) {
+	var exists bool
+	if err := db.QueryRow(
+		"SELECT EXISTS (SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = 'data_version')",
+	).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to check data version table: %w", err)
+	}
+	state := &State{}
+	if !exists {
+		return state, nil
+	}
+
+	err := db.QueryRow(
+		"SELECT data_version, written_by, daemon_version, daemon_pid, updated_at FROM data_version WHERE id = 1",
+	).Scan(&state.DataVersion, &state.WrittenBy, &state.DaemonVersion, &state.DaemonPID, &state.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data version: %w", err)
+	}
+	return state, nil
+}
+
+// Check refuses data upgraded by a newer clio than binaryVersion
+func Check(state *State, binaryVersion string) error {
+	if state.DataVersion > DataVersion {
+		return fmt.Errorf("%w: data version %d was written by clio %s, this clio %s supports up to %d; upgrade clio",
+			ErrNewerData, state.DataVersion, state.WrittenBy, binaryVersion, DataVersion)
+	}
+	return nil
+}
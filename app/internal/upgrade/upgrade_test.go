@@ -0,0 +1,175 @@
+package upgrade
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/cursor"
+	"github.com/stwalsh4118/clio/internal/db"
+	"github.com/stwalsh4118/clio/internal/logging"
+	_ "modernc.org/sqlite"
+)
+
+func openTestDB(t *testing.T) *sql.DB {
+	database, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "clio.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	database.SetMaxOpenConns(1)
+	t.Cleanup(func() { database.Close() })
+	return database
+}
+
+func newTestUpgrader(t *testing.T, database *sql.DB, binaryVersion string) Upgrader {
+	u, err := NewUpgrader(database, nil, binaryVersion, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewUpgrader() error = %v", err)
+	}
+	return u
+}
+
+func TestNewUpgrader(t *testing.T) {
+	database := openTestDB(t)
+	if _, err := NewUpgrader(nil, nil, "1.0.0", logging.NewNoopLogger()); err == nil {
+		t.Error("NewUpgrader(nil, ...) expected error, got nil")
+	}
+	if _, err := NewUpgrader(database, nil, "1.0.0", nil); err == nil {
+		t.Error("NewUpgrader(..., nil) expected error, got nil")
+	}
+}
+
+func TestReadState_BeforeMigrations(t *testing.T) {
+	database := openTestDB(t)
+
+	state, err := ReadState(database)
+	if err != nil {
+		t.Fatalf("ReadState() error = %v", err)
+	}
+	if state.DataVersion != 0 || state.WrittenBy != "" {
+		t.Errorf("ReadState() = %+v, want a zero state", state)
+	}
+}
+
+func TestUpgrader_Run(t *testing.T) {
+	database := openTestDB(t)
+	if err := db.RunMigrations(database); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	// A conversation captured before the backfills ran
+	if _, err := database.Exec(`
+		INSERT INTO sessions (id, project, start_time, last_activity, created_at, updated_at)
+		VALUES ('s1', 'project', ?, ?, ?, ?)
+	`, time.Now(), time.Now(), time.Now(), time.Now()); err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	storage, err := cursor.NewConversationStorage(database, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	conversation := &cursor.Conversation{
+		ComposerID: "c1",
+		CreatedAt:  time.Now(),
+		Messages:   []cursor.Message{{BubbleID: "b1", Type: 2, Role: "agent", ThinkingText: "considering", CreatedAt: time.Now()}},
+	}
+	if err := storage.StoreConversation(conversation, "s1"); err != nil {
+		t.Fatalf("failed to store conversation: %v", err)
+	}
+
+	u := newTestUpgrader(t, database, "1.0.0")
+	needed, err := u.Needed()
+	if err != nil || !needed {
+		t.Fatalf("Needed() = %v, %v, want true", needed, err)
+	}
+
+	var reported []string
+	result, err := u.Run(func(backfill string, done, total int) {
+		if done == total {
+			reported = append(reported, backfill)
+		}
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.FromVersion != 0 || result.ToVersion != DataVersion {
+		t.Errorf("Run() versions = %d -> %d, want 0 -> %d", result.FromVersion, result.ToVersion, DataVersion)
+	}
+	if len(reported) != len(result.Backfills) || len(reported) == 0 {
+		t.Errorf("progress completed %v, want every backfill run (%d)", reported, len(result.Backfills))
+	}
+
+	state, err := ReadState(database)
+	if err != nil {
+		t.Fatalf("ReadState() error = %v", err)
+	}
+	if state.DataVersion != DataVersion || state.WrittenBy != "1.0.0" {
+		t.Errorf("state = %+v, want data version %d written by 1.0.0", state, DataVersion)
+	}
+	if needed, err := u.Needed(); err != nil || needed {
+		t.Errorf("Needed() after Run = %v, %v, want false", needed, err)
+	}
+}
+
+func TestUpgrader_RefusesNewerData(t *testing.T) {
+	database := openTestDB(t)
+	if err := db.RunMigrations(database); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	if _, err := database.Exec(
+		"INSERT INTO data_version (id, data_version, written_by, updated_at) VALUES (1, ?, '9.0.0', ?)",
+		DataVersion+1, time.Now(),
+	); err != nil {
+		t.Fatalf("failed to record data version: %v", err)
+	}
+
+	u := newTestUpgrader(t, database, "1.0.0")
+	if _, err := u.Run(nil); !errors.Is(err, ErrNewerData) {
+		t.Errorf("Run() error = %v, want ErrNewerData", err)
+	}
+	if _, err := u.Needed(); !errors.Is(err, ErrNewerData) {
+		t.Errorf("Needed() error = %v, want ErrNewerData", err)
+	}
+}
+
+func TestUpgrader_RecordDaemon(t *testing.T) {
+	database := openTestDB(t)
+	if err := db.RunMigrations(database); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	u := newTestUpgrader(t, database, "1.0.0")
+	if err := u.RecordDaemon(100); err == nil {
+		t.Error("RecordDaemon() before an upgrade expected error, got nil")
+	}
+	if _, err := u.Run(nil); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if err := u.RecordDaemon(100); err != nil {
+		t.Fatalf("RecordDaemon() error = %v", err)
+	}
+
+	state, err := ReadState(database)
+	if err != nil {
+		t.Fatalf("ReadState() error = %v", err)
+	}
+	if state.DaemonVersion != "1.0.0" || state.DaemonPID != 100 {
+		t.Errorf("state = %+v, want daemon 1.0.0 as pid 100", state)
+	}
+
+	// A stopping daemon only clears its own record
+	if err := u.ClearDaemon(200); err != nil {
+		t.Fatalf("ClearDaemon() error = %v", err)
+	}
+	if state, _ := ReadState(database); state.DaemonPID != 100 {
+		t.Errorf("ClearDaemon(200) cleared pid %d's record", 100)
+	}
+	if err := u.ClearDaemon(100); err != nil {
+		t.Fatalf("ClearDaemon() error = %v", err)
+	}
+	if state, _ := ReadState(database); state.DaemonPID != 0 || state.DaemonVersion != "" {
+		t.Errorf("state after ClearDaemon = %+v, want no daemon", state)
+	}
+}
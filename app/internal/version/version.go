@@ -0,0 +1,6 @@
+// Package version holds the clio release shared by the CLI and the daemon
+package version
+
+// Version is the clio release, overridable at build time with
+// -ldflags "-X github.com/stwalsh4118/clio/internal/version.Version=<version>"
+var Version = "0.1.0"
@@ -0,0 +1,428 @@
+package zed
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/cursor"
+	"github.com/stwalsh4118/clio/internal/logging"
+	"github.com/stwalsh4118/clio/internal/sensitive"
+)
+
+const (
+	// SourceZed marks conversations captured from Zed
+	SourceZed = "zed"
+
+	// walSuffix is appended to the state database path for its write-ahead log
+	walSuffix = "-wal"
+	// codeFence opens the fenced blocks Zed's /file and /tab commands insert, followed by a worktree path
+	codeFence = "```"
+)
+
+// CaptureService defines the interface for the Zed assistant capture service
+type CaptureService interface {
+	Start() error
+	Stop() error
+	// OnSessionEnd registers a handler for sessions this service owns. Sessions shared with
+	// another capture source report their ends through that source.
+	OnSessionEnd(handler cursor.SessionEndHandler)
+}
+
+// captureService polls Zed's saved contexts and inline assist history
+type captureService struct {
+	config            *config.Config
+	db                *sql.DB
+	logger            logging.Logger
+	storage           cursor.ConversationStorage
+	recorder          cursor.ConversationRecorder
+	projects          cursor.ProjectNamer
+	sessionManager    cursor.SessionManager
+	ownsSessions      bool // sessionManager was created here rather than shared
+	conversationsPath string
+	databasePath      string
+	interval          time.Duration
+	modTimes          map[string]time.Time // Last captured modification time per file (poll goroutine only)
+	capturedPrompts   map[string]bool      // Inline assist prompt message IDs already stored (poll goroutine only)
+	ctx               context.Context
+	cancel            context.CancelFunc
+	wg                sync.WaitGroup
+	started           bool
+	mu                sync.Mutex
+}
+
+// NewCaptureService creates a Zed capture service. Conversations join sessions from
+// sessions when given, so Zed and Cursor work on a project share sessions; otherwise
+// the service manages its own.
+func NewCaptureService(cfg *config.Config, database *sql.DB, sessions cursor.SessionManager) (CaptureService, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+	if database == nil {
+		return nil, fmt.Errorf("database cannot be nil")
+	}
+
+	logger, err := logging.NewLogger(cfg)
+	if err != nil {
+		logger = logging.NewNoopLogger()
+	}
+	logger = logger.With("component", "zed_capture")
+
+	conversationsPath := cfg.Zed.ConversationsPath
+	if conversationsPath == "" {
+		if conversationsPath, err = DefaultConversationsPath(); err != nil {
+			return nil, err
+		}
+	}
+	databasePath := cfg.Zed.DatabasePath
+	if databasePath == "" {
+		if databasePath, err = DefaultDatabasePath(); err != nil {
+			return nil, err
+		}
+	}
+
+	storage, err := cursor.NewConversationStorage(database, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create conversation storage: %w", err)
+	}
+
+	ownsSessions := sessions == nil
+	if ownsSessions {
+		sessions, err = cursor.NewSessionManager(cfg, database)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create session manager: %w", err)
+		}
+		if err := sessions.LoadSessions(); err != nil {
+			logger.Warn("failed to load existing sessions", "error", err)
+		}
+	}
+
+	gate, err := sensitive.NewGate(cfg.Sensitive, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sensitive gate: %w", err)
+	}
+	recorder, err := cursor.NewConversationRecorderWithGate(database, sessions, gate, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create conversation recorder: %w", err)
+	}
+	projects, err := cursor.NewProjectNamer(database, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create project namer: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &captureService{
+		config:            cfg,
+		db:                database,
+		logger:            logger,
+		storage:           storage,
+		recorder:          recorder,
+		projects:          projects,
+		sessionManager:    sessions,
+		ownsSessions:      ownsSessions,
+		conversationsPath: conversationsPath,
+		databasePath:      databasePath,
+		interval:          time.Duration(cfg.Zed.PollIntervalSeconds) * time.Second,
+		modTimes:          make(map[string]time.Time),
+		capturedPrompts:   make(map[string]bool),
+		ctx:               ctx,
+		cancel:            cancel,
+	}, nil
+}
+
+// DefaultConversationsPath returns the directory Zed saves assistant contexts to
+func DefaultConversationsPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	// Zed keeps configuration and contexts under ~/.config/zed on macOS too
+	return filepath.Join(homeDir, ".config", "zed", "conversations"), nil
+}
+
+// DefaultDatabasePath returns the path of Zed's stable-channel state database
+func DefaultDatabasePath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	dataDir := filepath.Join(homeDir, ".local", "share", "zed")
+	if runtime.GOOS == "darwin" {
+		dataDir = filepath.Join(homeDir, "Library", "Application Support", "Zed")
+	} else if xdgData := os.Getenv("XDG_DATA_HOME"); xdgData != "" {
+		dataDir = filepath.Join(xdgData, "zed")
+	}
+	return filepath.Join(dataDir, "db", "0-stable", "db.sqlite"), nil
+}
+
+// Start begins polling
+func (cs *captureService) Start() error {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if cs.started {
+		return fmt.Errorf("zed capture service is already started")
+	}
+
+	if cs.ownsSessions {
+		if err := cs.sessionManager.StartInactivityMonitor(cs.ctx); err != nil {
+			return fmt.Errorf("failed to start inactivity monitor: %w", err)
+		}
+	}
+
+	if err := cs.loadCapturedPrompts(); err != nil {
+		// Not fatal - already captured prompts are upserted again
+		cs.logger.Warn("failed to load captured inline assist prompts", "error", err)
+	}
+
+	cs.wg.Add(1)
+	go cs.run()
+
+	cs.started = true
+	cs.logger.Info("zed capture started", "conversations_path", cs.conversationsPath, "database_path", cs.databasePath, "interval", cs.interval)
+	return nil
+}
+
+// Stop stops polling and waits for an in-progress poll to finish
+func (cs *captureService) Stop() error {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if !cs.started {
+		return nil
+	}
+
+	cs.cancel()
+	cs.wg.Wait()
+
+	if cs.ownsSessions {
+		if err := cs.sessionManager.Stop(); err != nil {
+			return fmt.Errorf("failed to stop session manager: %w", err)
+		}
+	}
+
+	cs.started = false
+	cs.logger.Info("zed capture stopped")
+	return nil
+}
+
+// OnSessionEnd registers a handler when this service owns its sessions
+func (cs *captureService) OnSessionEnd(handler cursor.SessionEndHandler) {
+	if cs.ownsSessions {
+		cs.sessionManager.OnSessionEnd(handler)
+	}
+}
+
+// run polls immediately, then on every interval until stopped
+func (cs *captureService) run() {
+	defer cs.wg.Done()
+
+	ticker := time.NewTicker(cs.interval)
+	defer ticker.Stop()
+
+	for {
+		cs.pollContexts()
+		cs.pollInlineAssists()
+
+		select {
+		case <-cs.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// pollContexts captures saved contexts modified since the last poll
+func (cs *captureService) pollContexts() {
+	entries, err := os.ReadDir(cs.conversationsPath)
+	if err != nil {
+		// Zed creates the directory when the first context is saved
+		cs.logger.Debug("failed to read zed conversations directory", "path", cs.conversationsPath, "error", err)
+		return
+	}
+
+	for _, entry := range entries {
+		if cs.ctx.Err() != nil {
+			return
+		}
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, contextFileSuffix) {
+			continue
+		}
+
+		path := filepath.Join(cs.conversationsPath, name)
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if modTime, ok := cs.modTimes[path]; ok && modTime.Equal(info.ModTime()) {
+			continue
+		}
+		// Record before parsing so a malformed file is retried only once it changes
+		cs.modTimes[path] = info.ModTime()
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			cs.logger.Warn("failed to read zed context", "path", path, "error", err)
+			continue
+		}
+		conversation, err := parseContext(data, strings.TrimSuffix(name, contextFileSuffix))
+		if err != nil {
+			cs.logger.Warn("failed to parse zed context", "path", path, "error", err)
+			continue
+		}
+		if len(conversation.Messages) == 0 {
+			continue
+		}
+
+		if err := cs.capture(conversation, string(data)); err != nil {
+			cs.logger.Error("failed to capture zed context", "path", path, "composer_id", conversation.ComposerID, "error", err)
+		}
+	}
+}
+
+// pollInlineAssists captures inline assist prompts added since the last poll into today's conversation
+func (cs *captureService) pollInlineAssists() {
+	modTime, ok := latestModTime(cs.databasePath, cs.databasePath+walSuffix)
+	if !ok {
+		return
+	}
+	if previous, seen := cs.modTimes[cs.databasePath]; seen && previous.Equal(modTime) {
+		return
+	}
+	cs.modTimes[cs.databasePath] = modTime
+
+	prompts, err := readInlineAssistHistory(cs.databasePath)
+	if err != nil {
+		cs.logger.Warn("failed to read zed inline assist history", "path", cs.databasePath, "error", err)
+		return
+	}
+	fresh := newPrompts(prompts, cs.capturedPrompts)
+	if len(fresh) == 0 {
+		return
+	}
+
+	now := time.Now()
+	conversation := newInlineAssistConversation(now, fresh, now)
+	if existing, err := cs.storage.GetConversationByComposerID(conversation.ComposerID); err == nil {
+		conversation.Messages = append(existing.Messages, conversation.Messages...)
+	}
+
+	if err := cs.capture(conversation, strings.Join(fresh, "\n")); err != nil {
+		cs.logger.Error("failed to capture zed inline assists", "composer_id", conversation.ComposerID, "error", err)
+	}
+}
+
+// capture stamps message times and records the conversation, attributing new ones to
+// the project detected from hint
+func (cs *captureService) capture(conversation *cursor.Conversation, hint string) error {
+	existing, err := cs.storage.GetConversationByComposerID(conversation.ComposerID)
+	if err != nil {
+		existing = nil
+	}
+	assignTimes(conversation, existing, time.Now())
+
+	project := ""
+	if existing == nil {
+		project = cs.projects.ProjectName(detectProject(hint, cs.config.WatchedDirectories))
+	}
+	_, err = cs.recorder.Record(conversation, project)
+	return err
+}
+
+// loadCapturedPrompts seeds the captured prompt set from stored inline assist conversations
+func (cs *captureService) loadCapturedPrompts() error {
+	rows, err := cs.db.Query("SELECT id FROM messages WHERE conversation_id LIKE ?", inlineAssistIDPrefix+"-%")
+	if err != nil {
+		return fmt.Errorf("failed to query inline assist prompts: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return fmt.Errorf("failed to scan inline assist prompt: %w", err)
+		}
+		cs.capturedPrompts[id] = true
+	}
+	return rows.Err()
+}
+
+// latestModTime returns the newest modification time among the paths that exist
+func latestModTime(paths ...string) (time.Time, bool) {
+	var latest time.Time
+	found := false
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		found = true
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, found
+}
+
+// detectProject returns the directory of the watched project text mentions most. Zed contexts
+// don't record a workspace, so absolute paths under watched directories and the worktree-relative
+// paths in /file and /tab output are counted. Returns an empty string when nothing matches.
+func detectProject(text string, watchedDirectories []string) string {
+	counts := make(map[string]int)
+	for _, dir := range watchedDirectories {
+		prefix := filepath.Clean(dir) + string(filepath.Separator)
+		for rest := text; ; {
+			idx := strings.Index(rest, prefix)
+			if idx < 0 {
+				break
+			}
+			rest = rest[idx+len(prefix):]
+			end := strings.IndexAny(rest, "/\\ \t\n\"'`)")
+			if end < 0 {
+				end = len(rest)
+			}
+			if name := rest[:end]; name != "" {
+				counts[filepath.Join(dir, name)]++
+			}
+		}
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+			if n := strings.Count(text, codeFence+entry.Name()+"/"); n > 0 {
+				counts[filepath.Join(dir, entry.Name())] += n
+			}
+		}
+	}
+
+	dirs := make([]string, 0, len(counts))
+	for dir := range counts {
+		dirs = append(dirs, dir)
+	}
+	sort.Slice(dirs, func(i, j int) bool {
+		if counts[dirs[i]] != counts[dirs[j]] {
+			return counts[dirs[i]] > counts[dirs[j]]
+		}
+		return dirs[i] < dirs[j]
+	})
+
+	if len(dirs) == 0 {
+		return ""
+	}
+	return dirs[0]
+}
@@ -0,0 +1,192 @@
+package zed
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/config"
+	"github.com/stwalsh4118/clio/internal/db"
+	_ "modernc.org/sqlite"
+)
+
+const contextV04 = `{
+	"id": "ctx-1",
+	"zed": "context",
+	"version": "0.4.0",
+	"text": "Why does this panic?\n` + "```" + `clio/app/main.go\nfunc main() {}\n` + "```" + `\nBecause the map is nil.\nsystem note",
+	"summary": "Nil map panic",
+	"messages": [
+		{"id": {"replica_id": 0, "value": 0}, "start": 0, "metadata": {"role": "user", "status": "Done"}},
+		{"id": {"replica_id": 0, "value": 3}, "start": 60, "metadata": {"role": "assistant", "status": "Done"}},
+		{"id": {"replica_id": 0, "value": 5}, "start": 84, "metadata": {"role": "system", "status": "Done"}}
+	]
+}`
+
+const contextV01 = `{
+	"zed": "context",
+	"version": "0.1.0",
+	"text": "hello\nhi there",
+	"messages": [{"id": 0, "start": 0}, {"id": 1, "start": 6}],
+	"message_metadata": {"0": {"role": "user"}, "1": {"role": "assistant"}}
+}`
+
+func TestParseContext_Versions(t *testing.T) {
+	conversation, err := parseContext([]byte(contextV04), "fallback")
+	if err != nil {
+		t.Fatalf("parseContext() error = %v", err)
+	}
+	if conversation.ComposerID != "zed-ctx-1" || conversation.Name != "Nil map panic" || conversation.Source != SourceZed {
+		t.Errorf("conversation = %q/%q/%q", conversation.ComposerID, conversation.Name, conversation.Source)
+	}
+	if len(conversation.Messages) != 2 {
+		t.Fatalf("got %d messages, want 2 (system message skipped)", len(conversation.Messages))
+	}
+	if got := conversation.Messages[1]; got.Role != "agent" || got.Text != "Because the map is nil." || got.BubbleID != "zed-ctx-1-0-3" {
+		t.Errorf("assistant message = %q %q (%s)", got.Role, got.Text, got.BubbleID)
+	}
+
+	legacy, err := parseContext([]byte(contextV01), "Greeting - 1")
+	if err != nil {
+		t.Fatalf("parseContext() legacy error = %v", err)
+	}
+	if legacy.ComposerID != "zed-Greeting - 1" || len(legacy.Messages) != 2 {
+		t.Fatalf("legacy conversation = %q with %d messages", legacy.ComposerID, len(legacy.Messages))
+	}
+	if legacy.Messages[0].Text != "hello" || legacy.Messages[1].Role != "agent" {
+		t.Errorf("legacy messages = %+v", legacy.Messages)
+	}
+
+	if _, err := parseContext([]byte(`{"zed": "prompt"}`), "x"); err == nil {
+		t.Error("expected an error for a non-context zed file")
+	}
+}
+
+func TestDetectProject(t *testing.T) {
+	watched := t.TempDir()
+	os.Mkdir(filepath.Join(watched, "clio"), 0755)
+	os.Mkdir(filepath.Join(watched, "other"), 0755)
+
+	text := "```clio/app/main.go\n```\nsee " + filepath.Join(watched, "other", "x.go") + " and ```clio/README.md"
+	if got, want := detectProject(text, []string{watched}), filepath.Join(watched, "clio"); got != want {
+		t.Errorf("detectProject() = %q, want %q", got, want)
+	}
+	if got := detectProject("no paths here", []string{watched}); got != "" {
+		t.Errorf("detectProject() without matches = %q, want none", got)
+	}
+}
+
+func TestCaptureService_CapturesContextsAndInlineAssists(t *testing.T) {
+	home := t.TempDir()
+	database, err := sql.Open("sqlite", filepath.Join(home, "clio.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer database.Close()
+	if err := db.RunMigrations(database); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	watched := filepath.Join(home, "code")
+	os.MkdirAll(filepath.Join(watched, "clio"), 0755)
+	conversationsPath := filepath.Join(home, "conversations")
+	os.MkdirAll(conversationsPath, 0755)
+	contextPath := filepath.Join(conversationsPath, "Nil map panic.zed.json")
+	if err := os.WriteFile(contextPath, []byte(contextV04), 0644); err != nil {
+		t.Fatalf("failed to write context: %v", err)
+	}
+
+	zedDBPath := filepath.Join(home, "zed.sqlite")
+	zedDB, err := sql.Open("sqlite", zedDBPath)
+	if err != nil {
+		t.Fatalf("failed to open zed database: %v", err)
+	}
+	if _, err := zedDB.Exec(`CREATE TABLE kv_store (key TEXT PRIMARY KEY, value TEXT NOT NULL)`); err != nil {
+		t.Fatalf("failed to create kv_store: %v", err)
+	}
+	if _, err := zedDB.Exec(`INSERT INTO kv_store VALUES (?, ?)`, inlineAssistHistoryKey, `["add error handling", "rename to parse"]`); err != nil {
+		t.Fatalf("failed to insert history: %v", err)
+	}
+	zedDB.Close()
+
+	cfg := &config.Config{
+		WatchedDirectories: []string{watched},
+		Session:            config.SessionConfig{InactivityTimeoutMinutes: 30},
+		Zed: config.ZedConfig{
+			Enabled:             true,
+			ConversationsPath:   conversationsPath,
+			DatabasePath:        zedDBPath,
+			PollIntervalSeconds: 1,
+		},
+	}
+	service, err := NewCaptureService(cfg, database, nil)
+	if err != nil {
+		t.Fatalf("NewCaptureService() error = %v", err)
+	}
+	cs := service.(*captureService)
+
+	cs.pollContexts()
+	cs.pollInlineAssists()
+
+	var project, source string
+	if err := database.QueryRow(`
+		SELECT s.project, c.source FROM conversations c JOIN sessions s ON s.id = c.session_id WHERE c.id = 'zed-ctx-1'
+	`).Scan(&project, &source); err != nil {
+		t.Fatalf("context not captured: %v", err)
+	}
+	if project != "clio" || source != SourceZed {
+		t.Errorf("project/source = %q/%q, want clio/zed", project, source)
+	}
+
+	var prompts int
+	database.QueryRow("SELECT COUNT(*) FROM messages WHERE conversation_id = ?", inlineAssistID(time.Now())).Scan(&prompts)
+	if prompts != 2 {
+		t.Errorf("inline assist prompts = %d, want 2", prompts)
+	}
+
+	// Appending a reply updates the conversation in place and keeps earlier message times
+	stored, err := cs.storage.GetConversationByComposerID("zed-ctx-1")
+	if err != nil {
+		t.Fatalf("failed to load stored conversation: %v", err)
+	}
+	firstSeen := stored.Messages[0].CreatedAt
+	updated := `{"id": "ctx-1", "zed": "context", "text": "Why does this panic?\nNil map.\nThanks", "summary": "Nil map panic", "messages": [
+		{"id": {"replica_id": 0, "value": 0}, "start": 0, "metadata": {"role": "user"}},
+		{"id": {"replica_id": 0, "value": 3}, "start": 21, "metadata": {"role": "assistant"}},
+		{"id": {"replica_id": 0, "value": 7}, "start": 30, "metadata": {"role": "user"}}
+	]}`
+	os.WriteFile(contextPath, []byte(updated), 0644)
+	os.Chtimes(contextPath, time.Now().Add(time.Minute), time.Now().Add(time.Minute))
+	cs.pollContexts()
+
+	stored, err = cs.storage.GetConversationByComposerID("zed-ctx-1")
+	if err != nil {
+		t.Fatalf("failed to load updated conversation: %v", err)
+	}
+	if len(stored.Messages) != 3 || stored.Messages[2].Text != "Thanks" {
+		t.Fatalf("updated messages = %+v", stored.Messages)
+	}
+	if !stored.Messages[0].CreatedAt.Equal(firstSeen) {
+		t.Errorf("first message time changed from %v to %v", firstSeen, stored.Messages[0].CreatedAt)
+	}
+	var conversations int
+	database.QueryRow("SELECT COUNT(*) FROM conversations WHERE source = ?", SourceZed).Scan(&conversations)
+	if conversations != 2 {
+		t.Errorf("zed conversations = %d, want 2", conversations)
+	}
+
+	// A restarted service doesn't capture already stored prompts again
+	restarted, err := NewCaptureService(cfg, database, nil)
+	if err != nil {
+		t.Fatalf("NewCaptureService() error = %v", err)
+	}
+	rcs := restarted.(*captureService)
+	if err := rcs.loadCapturedPrompts(); err != nil {
+		t.Fatalf("loadCapturedPrompts() error = %v", err)
+	}
+	if fresh := newPrompts([]string{"add error handling", "new prompt"}, rcs.capturedPrompts); len(fresh) != 1 || fresh[0] != "new prompt" {
+		t.Errorf("newPrompts() after restart = %v", fresh)
+	}
+}
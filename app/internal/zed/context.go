@@ -0,0 +1,163 @@
+package zed
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/cursor"
+)
+
+const (
+	// contextFileSuffix is the extension Zed gives saved assistant contexts
+	contextFileSuffix = ".zed.json"
+	// contextKind is the "zed" field value identifying a saved assistant context
+	contextKind = "context"
+)
+
+// savedContext is a saved Zed assistant context (format versions 0.1.0 through 0.4.0).
+// The whole conversation is one text buffer; each message starts at a byte offset
+// and runs until the next message starts.
+type savedContext struct {
+	ID              string                     `json:"id"`
+	Zed             string                     `json:"zed"`
+	Version         string                     `json:"version"`
+	Text            string                     `json:"text"`
+	Summary         string                     `json:"summary"`
+	Messages        []savedMessage             `json:"messages"`
+	MessageMetadata map[string]messageMetadata `json:"message_metadata"` // Version 0.1.0 kept metadata beside the messages
+}
+
+// savedMessage is a message anchor in a saved context
+type savedMessage struct {
+	ID       json.RawMessage  `json:"id"`
+	Start    int              `json:"start"`
+	Metadata *messageMetadata `json:"metadata"`
+}
+
+// messageMetadata holds a message's role. Zed only records Lamport timestamps, not wall-clock time.
+type messageMetadata struct {
+	Role string `json:"role"`
+}
+
+// lamportID is the replica-qualified message ID used from format version 0.2.0
+type lamportID struct {
+	ReplicaID int `json:"replica_id"`
+	Value     int `json:"value"`
+}
+
+// parseContext converts a saved context into a conversation. fallbackID names contexts
+// saved without an ID. Messages have no timestamps yet; the caller assigns them.
+func parseContext(data []byte, fallbackID string) (*cursor.Conversation, error) {
+	var saved savedContext
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("failed to decode context: %w", err)
+	}
+	if saved.Zed != "" && saved.Zed != contextKind {
+		return nil, fmt.Errorf("unsupported zed file kind %q", saved.Zed)
+	}
+
+	id := saved.ID
+	if id == "" {
+		id = fallbackID
+	}
+	composerID := fmt.Sprintf("%s-%s", SourceZed, id)
+
+	var messages []cursor.Message
+	for i, anchor := range saved.Messages {
+		role := messageRole(saved, anchor)
+		if role != "user" && role != "assistant" {
+			continue
+		}
+
+		end := len(saved.Text)
+		if i+1 < len(saved.Messages) {
+			end = saved.Messages[i+1].Start
+		}
+		start := max(0, min(anchor.Start, len(saved.Text)))
+		end = max(start, min(end, len(saved.Text)))
+		text := strings.TrimSpace(saved.Text[start:end])
+		if text == "" {
+			continue
+		}
+
+		messageType, messageRole := 1, "user"
+		if role == "assistant" {
+			messageType, messageRole = 2, "agent"
+		}
+		messages = append(messages, cursor.Message{
+			BubbleID:      fmt.Sprintf("%s-%s", composerID, messageKey(anchor.ID, i)),
+			Type:          messageType,
+			Role:          messageRole,
+			Text:          text,
+			ContentSource: "text",
+			ParserVersion: cursor.ParserVersion,
+		})
+	}
+
+	name := strings.TrimSpace(saved.Summary)
+	if name == "" {
+		name = "Untitled"
+	}
+
+	return &cursor.Conversation{
+		ComposerID: composerID,
+		Name:       name,
+		Status:     "completed",
+		Messages:   messages,
+		Source:     SourceZed,
+	}, nil
+}
+
+// messageRole returns a message's role from its inline metadata or the 0.1.0 metadata map
+func messageRole(saved savedContext, anchor savedMessage) string {
+	if anchor.Metadata != nil {
+		return anchor.Metadata.Role
+	}
+	if metadata, ok := saved.MessageMetadata[strings.Trim(string(anchor.ID), `"`)]; ok {
+		return metadata.Role
+	}
+	return ""
+}
+
+// messageKey renders a message ID stably, falling back to its position for unrecognised IDs
+func messageKey(raw json.RawMessage, index int) string {
+	var lamport lamportID
+	if err := json.Unmarshal(raw, &lamport); err == nil {
+		return fmt.Sprintf("%d-%d", lamport.ReplicaID, lamport.Value)
+	}
+	var number int
+	if err := json.Unmarshal(raw, &number); err == nil {
+		return strconv.Itoa(number)
+	}
+	return fmt.Sprintf("idx%d", index)
+}
+
+// assignTimes stamps messages with the times they were first captured. Zed doesn't record
+// wall-clock times, so messages already stored keep their time and new ones get seenAt.
+func assignTimes(conversation *cursor.Conversation, existing *cursor.Conversation, seenAt time.Time) {
+	known := make(map[string]time.Time)
+	if existing != nil {
+		for _, message := range existing.Messages {
+			known[message.BubbleID] = message.CreatedAt
+		}
+		conversation.CreatedAt = existing.CreatedAt
+	}
+
+	for i := range conversation.Messages {
+		if createdAt, ok := known[conversation.Messages[i].BubbleID]; ok {
+			conversation.Messages[i].CreatedAt = createdAt
+		} else {
+			conversation.Messages[i].CreatedAt = seenAt
+		}
+	}
+
+	if conversation.CreatedAt.IsZero() {
+		conversation.CreatedAt = seenAt
+		if len(conversation.Messages) > 0 {
+			conversation.CreatedAt = conversation.Messages[0].CreatedAt
+		}
+	}
+}
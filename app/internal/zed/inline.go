@@ -0,0 +1,104 @@
+package zed
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/stwalsh4118/clio/internal/cursor"
+	_ "modernc.org/sqlite"
+)
+
+const (
+	// inlineAssistHistoryKey is the key-value store entry holding recent inline assist prompts
+	inlineAssistHistoryKey = "inline_assistant_history"
+	// inlineAssistIDPrefix prefixes the daily inline assist conversation IDs
+	inlineAssistIDPrefix = SourceZed + "-inline-assist"
+	// inlineAssistDateLayout names one inline assist conversation per local day
+	inlineAssistDateLayout = "2006-01-02"
+	// promptHashLength is the number of hex characters of the prompt hash used in message IDs
+	promptHashLength = 16
+)
+
+// readInlineAssistHistory returns the inline assist prompts in Zed's state database, oldest first.
+// The database is opened read-only so Zed's own writes are never blocked.
+func readInlineAssistHistory(databasePath string) ([]string, error) {
+	dsn := (&url.URL{Scheme: "file", Path: databasePath, RawQuery: "mode=ro"}).String()
+	database, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open zed database: %w", err)
+	}
+	defer database.Close()
+
+	var value string
+	err = database.QueryRow("SELECT value FROM kv_store WHERE key = ?", inlineAssistHistoryKey).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read inline assist history: %w", err)
+	}
+
+	var prompts []string
+	if err := json.Unmarshal([]byte(value), &prompts); err != nil {
+		return nil, fmt.Errorf("failed to decode inline assist history: %w", err)
+	}
+	return prompts, nil
+}
+
+// inlineAssistID returns the conversation ID for inline assists made on day
+func inlineAssistID(day time.Time) string {
+	return fmt.Sprintf("%s-%s", inlineAssistIDPrefix, day.Format(inlineAssistDateLayout))
+}
+
+// promptMessageID identifies a prompt by content, since the history has no IDs or times
+func promptMessageID(prompt string) string {
+	sum := sha256.Sum256([]byte(prompt))
+	return fmt.Sprintf("%s-%s", inlineAssistIDPrefix, hex.EncodeToString(sum[:])[:promptHashLength])
+}
+
+// newInlineAssistConversation builds the conversation for prompts first seen on day.
+// Only prompts are recorded; Zed applies inline assist responses to buffers without saving them.
+func newInlineAssistConversation(day time.Time, prompts []string, seenAt time.Time) *cursor.Conversation {
+	messages := make([]cursor.Message, 0, len(prompts))
+	for _, prompt := range prompts {
+		messages = append(messages, cursor.Message{
+			BubbleID:      promptMessageID(prompt),
+			Type:          1,
+			Role:          "user",
+			Text:          prompt,
+			ContentSource: "text",
+			CreatedAt:     seenAt,
+			ParserVersion: cursor.ParserVersion,
+		})
+	}
+
+	return &cursor.Conversation{
+		ComposerID: inlineAssistID(day),
+		Name:       fmt.Sprintf("Inline assists %s", day.Format(inlineAssistDateLayout)),
+		Status:     "completed",
+		CreatedAt:  seenAt,
+		Messages:   messages,
+		Source:     SourceZed,
+	}
+}
+
+// newPrompts returns the prompts not yet captured, preserving history order
+func newPrompts(prompts []string, captured map[string]bool) []string {
+	var fresh []string
+	for _, prompt := range prompts {
+		prompt = strings.TrimSpace(prompt)
+		if prompt == "" || captured[promptMessageID(prompt)] {
+			continue
+		}
+		captured[promptMessageID(prompt)] = true
+		fresh = append(fresh, prompt)
+	}
+	return fresh
+}
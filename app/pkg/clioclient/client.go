@@ -0,0 +1,323 @@
+// Package clioclient is a Go client for the clio daemon's local API.
+//
+// The daemon serves the API over a unix socket (~/.clio/clio.sock by
+// default), so only the user running the daemon can reach it:
+//
+//	client := clioclient.New("")
+//	sessions, err := client.Sessions(ctx, clioclient.SessionFilter{Project: "clio"})
+//
+// Calls return ErrDaemonNotRunning when nothing is listening on the socket.
+package clioclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/stwalsh4118/clio/pkg/export"
+)
+
+const (
+	// SocketFileName is the daemon API socket inside the clio config directory
+	SocketFileName = "clio.sock"
+	// ProfileEnvVar selects a clio profile; each profile's daemon has its own socket
+	ProfileEnvVar = "CLIO_PROFILE"
+	// configDirName is the clio config directory under the user's home
+	configDirName = ".clio"
+	// apiHost is a placeholder host; requests are always dialled over the socket
+	apiHost = "clio"
+	// defaultRequestTimeout bounds a single API call
+	defaultRequestTimeout = 30 * time.Second
+)
+
+// API paths served by the daemon
+const (
+	PathStatus     = "/v1/status"
+	PathSessions   = "/v1/sessions"
+	PathSearch     = "/v1/search"
+	PathExport     = "/v1/export"
+	PathHeartbeats = "/v1/heartbeats"
+)
+
+// ErrDaemonNotRunning is returned when the daemon API socket can't be reached
+var ErrDaemonNotRunning = errors.New("clio daemon is not running")
+
+// Status describes the running daemon
+type Status struct {
+	PID              int       `json:"pid"`
+	Version          string    `json:"version"` // clio version of the daemon
+	StartedAt        time.Time `json:"started_at"`
+	CursorCapture    bool      `json:"cursor_capture"`    // Cursor conversation capture is running
+	ZedCapture       bool      `json:"zed_capture"`       // Zed assistant capture is running
+	JetBrainsCapture bool      `json:"jetbrains_capture"` // JetBrains AI Assistant capture is running
+	CommitCapture    bool      `json:"commit_capture"`    // Git commit capture is running
+	HeartbeatLog     bool      `json:"heartbeat_log"`     // The heartbeat log is being tailed
+}
+
+// SessionFilter narrows the sessions returned or exported
+type SessionFilter struct {
+	Project string    // Only include this project (case-insensitive)
+	Since   time.Time // Only include sessions starting at or after this time
+	Until   time.Time // Only include sessions starting before this time
+}
+
+// SearchResult is a message matching a search query
+type SearchResult struct {
+	SessionID        string    `json:"session_id"`
+	Project          string    `json:"project"`
+	ConversationName string    `json:"conversation_name"`
+	ComposerID       string    `json:"composer_id"`
+	Role             string    `json:"role"`
+	Snippet          string    `json:"snippet"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// Heartbeat is editor activity on a file, in WakaTime's heartbeat format. Editor
+// plugins can send the heartbeats they would send to WakaTime unchanged.
+type Heartbeat struct {
+	Entity   string  `json:"entity"`             // File path (or app name or domain)
+	Type     string  `json:"type,omitempty"`     // "file" (default), "app", or "domain"
+	Category string  `json:"category,omitempty"` // e.g. "coding", "debugging"
+	Time     float64 `json:"time"`               // Unix seconds with a fractional part
+	Project  string  `json:"project,omitempty"`  // Detected from the entity's path when empty
+	Branch   string  `json:"branch,omitempty"`
+	Language string  `json:"language,omitempty"`
+	IsWrite  bool    `json:"is_write,omitempty"` // The heartbeat was triggered by saving the file
+}
+
+// HeartbeatResult reports what the daemon did with a batch of heartbeats
+type HeartbeatResult struct {
+	Received int `json:"received"`
+	Stored   int `json:"stored"`  // New heartbeats; resent duplicates aren't counted
+	Invalid  int `json:"invalid"` // Heartbeats without an entity or time
+}
+
+// ErrorResponse is the body of a failed API call
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// APIError is a non-success response from the daemon
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+// Error implements error
+func (e *APIError) Error() string {
+	return fmt.Sprintf("clio daemon API error (%d): %s", e.StatusCode, e.Message)
+}
+
+// Client calls the clio daemon API
+type Client struct {
+	socketPath string
+	httpClient *http.Client
+}
+
+// DefaultSocketPath returns the socket the daemon for the profile in
+// CLIO_PROFILE listens on, ~/.clio/clio.sock without a profile
+func DefaultSocketPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(homeDir, configDirName, SocketFileNameForProfile(os.Getenv(ProfileEnvVar))), nil
+}
+
+// SocketFileNameForProfile returns the socket file name for a profile's daemon,
+// e.g. clio.work.sock; an empty profile returns SocketFileName
+func SocketFileNameForProfile(profile string) string {
+	profile = strings.ToLower(strings.TrimSpace(profile))
+	if profile == "" {
+		return SocketFileName
+	}
+	return strings.TrimSuffix(SocketFileName, ".sock") + "." + profile + ".sock"
+}
+
+// New creates a client for the daemon listening on socketPath. An empty path
+// uses DefaultSocketPath.
+func New(socketPath string) *Client {
+	if socketPath == "" {
+		// Fall back to a relative name if the home directory is unknown; the dial
+		// then fails with ErrDaemonNotRunning
+		socketPath = SocketFileName
+		if path, err := DefaultSocketPath(); err == nil {
+			socketPath = path
+		}
+	}
+
+	dialer := &net.Dialer{}
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", socketPath)
+		},
+	}
+
+	return &Client{
+		socketPath: socketPath,
+		httpClient: &http.Client{Transport: transport, Timeout: defaultRequestTimeout},
+	}
+}
+
+// SocketPath returns the socket the client connects to
+func (c *Client) SocketPath() string {
+	return c.socketPath
+}
+
+// Status returns the daemon's status
+func (c *Client) Status(ctx context.Context) (*Status, error) {
+	var status Status
+	if err := c.getJSON(ctx, PathStatus, nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// Sessions returns the sessions matching filter with their conversations and commits
+func (c *Client) Sessions(ctx context.Context, filter SessionFilter) ([]export.Session, error) {
+	var sessions []export.Session
+	if err := c.getJSON(ctx, PathSessions, filter.values(), &sessions); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// Search returns messages containing query, newest first. A limit of zero uses
+// the daemon's default.
+func (c *Client) Search(ctx context.Context, query string, filter SessionFilter, limit int) ([]SearchResult, error) {
+	params := filter.values()
+	params.Set("q", query)
+	if limit > 0 {
+		params.Set("limit", strconv.Itoa(limit))
+	}
+
+	var results []SearchResult
+	if err := c.getJSON(ctx, PathSearch, params, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// Export runs the named exporter in the daemon and writes its output to w
+func (c *Client) Export(ctx context.Context, format string, filter SessionFilter, w io.Writer) error {
+	params := filter.values()
+	params.Set("format", format)
+
+	resp, err := c.get(ctx, PathExport, params)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("failed to read export: %w", err)
+	}
+	return nil
+}
+
+// SendHeartbeats records editor activity in the daemon, extending the active
+// session of each heartbeat's project
+func (c *Client) SendHeartbeats(ctx context.Context, heartbeats []Heartbeat) (*HeartbeatResult, error) {
+	body, err := json.Marshal(heartbeats)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode heartbeats: %w", err)
+	}
+
+	resp, err := c.do(ctx, http.MethodPost, PathHeartbeats, nil, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result HeartbeatResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &result, nil
+}
+
+// getJSON performs a GET and decodes the JSON response into out
+func (c *Client) getJSON(ctx context.Context, path string, params url.Values, out any) error {
+	resp, err := c.get(ctx, path, params)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// get performs a GET, converting connection failures and error responses to errors
+func (c *Client) get(ctx context.Context, path string, params url.Values) (*http.Response, error) {
+	return c.do(ctx, http.MethodGet, path, params, nil)
+}
+
+// do performs a request, converting connection failures and error responses to errors
+func (c *Client) do(ctx context.Context, method, path string, params url.Values, body io.Reader) (*http.Response, error) {
+	target := url.URL{Scheme: "http", Host: apiHost, Path: path}
+	if len(params) > 0 {
+		target.RawQuery = params.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		if isNotRunning(err) {
+			return nil, ErrDaemonNotRunning
+		}
+		return nil, fmt.Errorf("failed to call clio daemon: %w", err)
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		defer resp.Body.Close()
+		var body ErrorResponse
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || body.Error == "" {
+			body.Error = http.StatusText(resp.StatusCode)
+		}
+		return nil, &APIError{StatusCode: resp.StatusCode, Message: body.Error}
+	}
+
+	return resp, nil
+}
+
+// values encodes the filter as query parameters
+func (f SessionFilter) values() url.Values {
+	params := url.Values{}
+	if f.Project != "" {
+		params.Set("project", f.Project)
+	}
+	if !f.Since.IsZero() {
+		params.Set("since", f.Since.Format(time.RFC3339))
+	}
+	if !f.Until.IsZero() {
+		params.Set("until", f.Until.Format(time.RFC3339))
+	}
+	return params
+}
+
+// isNotRunning reports whether err means nothing is listening on the socket
+func isNotRunning(err error) bool {
+	return errors.Is(err, os.ErrNotExist) || errors.Is(err, syscall.ENOENT) || errors.Is(err, syscall.ECONNREFUSED)
+}
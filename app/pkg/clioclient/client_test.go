@@ -0,0 +1,115 @@
+package clioclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/clio/pkg/export"
+)
+
+// serveTestAPI serves handler on a unix socket and returns its path
+func serveTestAPI(t *testing.T, handler http.Handler) string {
+	// Unix socket paths are length-limited, so avoid the long t.TempDir path
+	dir, err := os.MkdirTemp("", "clio")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	socketPath := filepath.Join(dir, SocketFileName)
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	server := &http.Server{Handler: handler}
+	go server.Serve(listener)
+	t.Cleanup(func() { server.Close() })
+
+	return socketPath
+}
+
+func TestClient(t *testing.T) {
+	since := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(PathStatus, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(Status{PID: 42, CommitCapture: true})
+	})
+	mux.HandleFunc(PathSessions, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("project") != "clio" || r.URL.Query().Get("since") != since.Format(time.RFC3339) {
+			t.Errorf("sessions query = %q, want project and since", r.URL.RawQuery)
+		}
+		json.NewEncoder(w).Encode([]export.Session{{ID: "session-1", Project: "clio"}})
+	})
+	mux.HandleFunc(PathSearch, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("q") != "retry" || r.URL.Query().Get("limit") != "5" {
+			t.Errorf("search query = %q, want q and limit", r.URL.RawQuery)
+		}
+		json.NewEncoder(w).Encode([]SearchResult{{SessionID: "session-1", Snippet: "add retry"}})
+	})
+	mux.HandleFunc(PathExport, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("format") != "markdown" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "unknown export format"})
+			return
+		}
+		w.Write([]byte("# Clio Export\n"))
+	})
+	mux.HandleFunc(PathHeartbeats, func(w http.ResponseWriter, r *http.Request) {
+		var heartbeats []Heartbeat
+		if r.Method != http.MethodPost || json.NewDecoder(r.Body).Decode(&heartbeats) != nil || len(heartbeats) != 2 {
+			t.Errorf("heartbeats request = %s with %+v, want a POST of two heartbeats", r.Method, heartbeats)
+		}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(HeartbeatResult{Received: 2, Stored: 1})
+	})
+
+	client := New(serveTestAPI(t, mux))
+	ctx := context.Background()
+
+	status, err := client.Status(ctx)
+	if err != nil || status.PID != 42 || !status.CommitCapture {
+		t.Errorf("Status() = %+v, %v", status, err)
+	}
+
+	sessions, err := client.Sessions(ctx, SessionFilter{Project: "clio", Since: since})
+	if err != nil || len(sessions) != 1 || sessions[0].ID != "session-1" {
+		t.Errorf("Sessions() = %+v, %v", sessions, err)
+	}
+
+	results, err := client.Search(ctx, "retry", SessionFilter{}, 5)
+	if err != nil || len(results) != 1 || results[0].Snippet != "add retry" {
+		t.Errorf("Search() = %+v, %v", results, err)
+	}
+
+	var buf bytes.Buffer
+	if err := client.Export(ctx, "markdown", SessionFilter{}, &buf); err != nil || buf.String() != "# Clio Export\n" {
+		t.Errorf("Export() wrote %q, error = %v", buf.String(), err)
+	}
+
+	sent, err := client.SendHeartbeats(ctx, []Heartbeat{{Entity: "/src/clio/main.go", Time: 1704880800}, {Entity: "/src/clio/go.mod", Time: 1704880860.5}})
+	if err != nil || sent.Received != 2 || sent.Stored != 1 {
+		t.Errorf("SendHeartbeats() = %+v, %v", sent, err)
+	}
+
+	var apiErr *APIError
+	if err := client.Export(ctx, "csv", SessionFilter{}, &buf); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest || apiErr.Message != "unknown export format" {
+		t.Errorf("Export() with unknown format error = %v, want APIError 400", err)
+	}
+}
+
+func TestClient_DaemonNotRunning(t *testing.T) {
+	client := New(filepath.Join(t.TempDir(), "missing.sock"))
+
+	if _, err := client.Status(context.Background()); !errors.Is(err, ErrDaemonNotRunning) {
+		t.Errorf("Status() error = %v, want ErrDaemonNotRunning", err)
+	}
+}
@@ -0,0 +1,189 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+const (
+	// markdownTimeLayout is the timestamp format used in markdown output
+	markdownTimeLayout = "2006-01-02 15:04"
+)
+
+func init() {
+	Register(jsonExporter{})
+	Register(markdownExporter{})
+	Register(mermaidExporter{})
+	Register(dotExporter{})
+}
+
+// jsonExporter writes the data as indented JSON
+type jsonExporter struct{}
+
+// Name implements Exporter
+func (jsonExporter) Name() string { return "json" }
+
+// Description implements Exporter
+func (jsonExporter) Description() string { return "Sessions, conversations, and commits as JSON" }
+
+// Export implements Exporter
+func (jsonExporter) Export(w io.Writer, data *Data) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(data); err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+	return nil
+}
+
+// markdownExporter writes one section per session with its conversations, journal notes, and commits
+type markdownExporter struct{}
+
+// Name implements Exporter
+func (markdownExporter) Name() string { return "markdown" }
+
+// Description implements Exporter
+func (markdownExporter) Description() string { return "Readable session log in Markdown" }
+
+// Export implements Exporter
+func (markdownExporter) Export(w io.Writer, data *Data) error {
+	var b strings.Builder
+
+	b.WriteString("# Clio Export\n\n")
+	fmt.Fprintf(&b, "Generated %s\n", data.GeneratedAt.Local().Format(markdownTimeLayout))
+
+	for _, session := range data.Sessions {
+		end := "active"
+		if session.EndTime != nil {
+			end = session.EndTime.Local().Format(markdownTimeLayout)
+		}
+		fmt.Fprintf(&b, "\n## %s: %s - %s\n", session.Project, session.StartTime.Local().Format(markdownTimeLayout), end)
+		if len(session.Metadata) > 0 {
+			fmt.Fprintf(&b, "\n%s\n", formatMetadata(session.Metadata))
+		}
+
+		// Journal notes are interleaved with conversations; other activity has its own section
+		for _, event := range session.Timeline() {
+			switch event.Kind {
+			case EventConversation:
+				fmt.Fprintf(&b, "\n### %s\n", conversationTitle(*event.Conversation))
+				for _, message := range event.Conversation.Messages {
+					fmt.Fprintf(&b, "\n**%s** (%s):\n\n%s\n", message.Role, message.CreatedAt.Local().Format(markdownTimeLayout), message.Text)
+				}
+			case EventJournal:
+				fmt.Fprintf(&b, "\n> **Note** (%s): %s\n", event.Time.Local().Format(markdownTimeLayout),
+					strings.ReplaceAll(event.Journal.Text, "\n", "\n> "))
+			}
+		}
+
+		if len(session.Commits) > 0 {
+			b.WriteString("\n### Commits\n\n")
+			for _, commit := range session.Commits {
+				subject, _, _ := strings.Cut(commit.Message, "\n")
+				fmt.Fprintf(&b, "- `%s` %s (%s, %s)", shortHash(commit.Hash), subject, commit.Repository, commit.Branch)
+				if len(commit.Metadata) > 0 {
+					fmt.Fprintf(&b, " [%s]", formatMetadata(commit.Metadata))
+				}
+				b.WriteString("\n")
+			}
+		}
+
+		if len(session.TestRuns) > 0 {
+			writeMarkdownTests(&b, session)
+		}
+
+		if len(session.Attachments) > 0 {
+			writeMarkdownAttachments(&b, session.Attachments)
+		}
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write markdown: %w", err)
+	}
+	return nil
+}
+
+// formatMetadata formats key=value pairs sorted by key
+func formatMetadata(metadata map[string]string) string {
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = key + "=" + metadata[key]
+	}
+	return strings.Join(pairs, ", ")
+}
+
+// writeMarkdownTests lists a session's test runs and narrates which conversations fixed failures
+func writeMarkdownTests(b *strings.Builder, session Session) {
+	b.WriteString("\n### Tests\n\n")
+	for _, run := range session.TestRuns {
+		status := "pass"
+		if run.Failed > 0 {
+			status = "FAIL"
+		}
+		fmt.Fprintf(b, "- %s %s: %d passed, %d failed, %d skipped", run.RunTime.Local().Format(markdownTimeLayout), status, run.Passed, run.Failed, run.Skipped)
+		if run.CommitHash != "" {
+			fmt.Fprintf(b, " at `%s`", shortHash(run.CommitHash))
+		}
+		if len(run.FailedTests) > 0 {
+			fmt.Fprintf(b, " (%s)", strings.Join(run.FailedTests, ", "))
+		}
+		b.WriteString("\n")
+	}
+
+	for _, fix := range session.TestFixes() {
+		fmt.Fprintf(b, "\nTests went red at %s and were green again at %s",
+			fix.Failed.RunTime.Local().Format(markdownTimeLayout), fix.Passed.RunTime.Local().Format(markdownTimeLayout))
+		if len(fix.Conversations) > 0 {
+			names := make([]string, len(fix.Conversations))
+			for i, conversation := range fix.Conversations {
+				names[i] = fmt.Sprintf("%q", conversationTitle(conversation))
+			}
+			fmt.Fprintf(b, " after %s", strings.Join(names, ", "))
+		}
+		b.WriteString(".\n")
+	}
+}
+
+// writeMarkdownAttachments embeds images and links other attached files
+func writeMarkdownAttachments(b *strings.Builder, attachments []Attachment) {
+	b.WriteString("\n### Attachments\n")
+	for _, attachment := range attachments {
+		label := attachment.Caption
+		if label == "" {
+			label = attachment.Name
+		}
+		if attachment.IsImage() {
+			fmt.Fprintf(b, "\n![%s](<%s>)\n", label, attachment.Path)
+			if attachment.Caption != "" {
+				fmt.Fprintf(b, "\n*%s*\n", attachment.Caption)
+			}
+			continue
+		}
+		fmt.Fprintf(b, "\n- [%s](<%s>) (%s)\n", label, attachment.Path, attachment.MediaType)
+	}
+}
+
+// conversationTitle returns a conversation's name, falling back to its ID
+func conversationTitle(conversation Conversation) string {
+	if conversation.Name != "" {
+		return conversation.Name
+	}
+	return conversation.ComposerID
+}
+
+// shortHash abbreviates a commit hash for display
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
@@ -0,0 +1,322 @@
+// Package export defines the extension point for clio exporters.
+//
+// An exporter turns captured sessions, conversations, and commits into an
+// output format. Exporters register themselves by name from an init function,
+// and `clio export --format <name>` selects them. Third-party exporters are
+// compiled into a custom build by blank-importing their package from
+// cmd/clio (see cmd/clio/plugins.go):
+//
+//	import _ "example.com/clio-exporters/csv"
+//
+// This package is the public API for exporters: it only depends on the
+// standard library and its types are stable across clio releases.
+package export
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Exporter writes captured data in a specific format
+type Exporter interface {
+	// Name is the value selected with --format; it must be unique
+	Name() string
+	// Description is a one-line summary shown by --list-formats
+	Description() string
+	// Export writes data to w
+	Export(w io.Writer, data *Data) error
+}
+
+// Data is the captured activity handed to an exporter
+type Data struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	Sessions    []Session `json:"sessions"`
+	IdleGaps    []IdleGap `json:"idle_gaps,omitempty"` // Periods the machine slept while the sessions ran
+}
+
+// IdleGap is a period the machine was asleep, recorded by the daemon
+type IdleGap struct {
+	Kind  string    `json:"kind"` // "sleep"
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Session is a development session with its conversations, correlated commits,
+// test runs, attached files, and journal notes
+type Session struct {
+	ID            string            `json:"id"`
+	Project       string            `json:"project"`
+	StartTime     time.Time         `json:"start_time"`
+	EndTime       *time.Time        `json:"end_time,omitempty"` // Nil while the session is active
+	Conversations []Conversation    `json:"conversations"`
+	Commits       []Commit          `json:"commits"`
+	TestRuns      []TestRun         `json:"test_runs"`
+	Attachments   []Attachment      `json:"attachments"`
+	Journal       []JournalEntry    `json:"journal"`
+	Metadata      map[string]string `json:"metadata,omitempty"` // Key=value context set with clio meta
+}
+
+// Conversation is a single Cursor composer conversation
+type Conversation struct {
+	ComposerID string    `json:"composer_id"`
+	Name       string    `json:"name"`
+	Messages   []Message `json:"messages"`
+}
+
+// Message is a single user or agent message
+type Message struct {
+	Role      string     `json:"role"` // "user" or "agent"
+	Text      string     `json:"text"`
+	Thinking  string     `json:"thinking,omitempty"` // Agent reasoning, when the editor exposes it
+	Language  string     `json:"language,omitempty"` // ISO 639-1 code of the text's language; empty when undetected
+	CreatedAt time.Time  `json:"created_at"`
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"` // Tools the agent ran for this message
+}
+
+// ToolCall is a tool the agent ran, such as reading or editing a file
+type ToolCall struct {
+	Name   string `json:"name"`
+	Status string `json:"status"` // e.g. "completed" or "error"
+}
+
+// Commit is a git commit correlated with a session
+type Commit struct {
+	Hash            string            `json:"hash"`
+	Message         string            `json:"message"`
+	Author          string            `json:"author"`
+	Repository      string            `json:"repository"`
+	Branch          string            `json:"branch"`
+	Timestamp       time.Time         `json:"timestamp"`
+	CorrelationType string            `json:"correlation_type"`
+	Confidence      *float64          `json:"confidence,omitempty"` // Nil when the correlation wasn't scored
+	Metadata        map[string]string `json:"metadata,omitempty"`   // Key=value context set with clio meta
+}
+
+// TestRun is a test run ingested while the session was active
+type TestRun struct {
+	RunTime     time.Time `json:"run_time"` // When the run finished
+	Format      string    `json:"format"`   // "junit" or "gotest"
+	Total       int       `json:"total"`
+	Passed      int       `json:"passed"`
+	Failed      int       `json:"failed"`
+	Skipped     int       `json:"skipped"`
+	CommitHash  string    `json:"commit_hash,omitempty"` // Latest commit before the run, when known
+	FailedTests []string  `json:"failed_tests,omitempty"`
+}
+
+// Attachment is a file attached to the session with clio attach, such as a
+// screenshot of the UI being built
+type Attachment struct {
+	Name       string    `json:"name"`       // Original file name
+	MediaType  string    `json:"media_type"` // e.g. "image/png"
+	Path       string    `json:"path"`       // Absolute path of the stored copy
+	SHA256     string    `json:"sha256"`
+	Size       int64     `json:"size"`
+	Caption    string    `json:"caption,omitempty"`
+	AttachedAt time.Time `json:"attached_at"`
+}
+
+// IsImage reports whether the attachment can be embedded as an image
+func (a Attachment) IsImage() bool {
+	return strings.HasPrefix(a.MediaType, "image/")
+}
+
+// JournalEntry is a freeform note written with clio journal during the session
+type JournalEntry struct {
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Timeline event kinds
+const (
+	EventConversation = "conversation"
+	EventJournal      = "journal"
+	EventCommit       = "commit"
+	EventTestRun      = "test_run"
+	EventAttachment   = "attachment"
+)
+
+// Event is one entry in a session's timeline. Exactly one of the pointers is set,
+// matching Kind.
+type Event struct {
+	Time         time.Time
+	Kind         string
+	Conversation *Conversation
+	Journal      *JournalEntry
+	Commit       *Commit
+	TestRun      *TestRun
+	Attachment   *Attachment
+}
+
+// Timeline interleaves the session's activity in time order. Conversations are
+// placed at their first message, or the session start when they have none; events
+// at the same time keep the order conversations, journal, commits, test runs,
+// attachments.
+func (s Session) Timeline() []Event {
+	var events []Event
+	for i := range s.Conversations {
+		at := s.StartTime
+		if len(s.Conversations[i].Messages) > 0 {
+			at = s.Conversations[i].Messages[0].CreatedAt
+		}
+		events = append(events, Event{Time: at, Kind: EventConversation, Conversation: &s.Conversations[i]})
+	}
+	for i := range s.Journal {
+		events = append(events, Event{Time: s.Journal[i].CreatedAt, Kind: EventJournal, Journal: &s.Journal[i]})
+	}
+	for i := range s.Commits {
+		events = append(events, Event{Time: s.Commits[i].Timestamp, Kind: EventCommit, Commit: &s.Commits[i]})
+	}
+	for i := range s.TestRuns {
+		events = append(events, Event{Time: s.TestRuns[i].RunTime, Kind: EventTestRun, TestRun: &s.TestRuns[i]})
+	}
+	for i := range s.Attachments {
+		events = append(events, Event{Time: s.Attachments[i].AttachedAt, Kind: EventAttachment, Attachment: &s.Attachments[i]})
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Time.Before(events[j].Time) })
+	return events
+}
+
+// ActiveDuration returns how long the session ran, up to now while it's active,
+// less the parts of gaps that fall within it
+func (s Session) ActiveDuration(gaps []IdleGap, now time.Time) time.Duration {
+	end := now
+	if s.EndTime != nil {
+		end = *s.EndTime
+	}
+	if !end.After(s.StartTime) {
+		return 0
+	}
+
+	d := end.Sub(s.StartTime)
+	for _, gap := range gaps {
+		from, to := gap.Start, gap.End
+		if from.Before(s.StartTime) {
+			from = s.StartTime
+		}
+		if to.After(end) {
+			to = end
+		}
+		if to.After(from) {
+			d -= to.Sub(from)
+		}
+	}
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// TestFix is a failing test run followed by the next passing one, with the
+// conversations that had messages between them
+type TestFix struct {
+	Failed        TestRun
+	Passed        TestRun
+	Conversations []Conversation
+}
+
+// TestFixes pairs each failing run in the session with the passing run that ended
+// it, so exporters can narrate "tests went red, conversation X fixed them".
+// Consecutive failing runs are reported once, from the first failure.
+func (s Session) TestFixes() []TestFix {
+	runs := make([]TestRun, len(s.TestRuns))
+	copy(runs, s.TestRuns)
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].RunTime.Before(runs[j].RunTime) })
+
+	var fixes []TestFix
+	var red *TestRun
+	for i := range runs {
+		run := runs[i]
+		if run.Failed > 0 {
+			if red == nil {
+				red = &runs[i]
+			}
+			continue
+		}
+		if red == nil {
+			continue
+		}
+
+		fix := TestFix{Failed: *red, Passed: run}
+		for _, conversation := range s.Conversations {
+			for _, message := range conversation.Messages {
+				if message.CreatedAt.After(red.RunTime) && !message.CreatedAt.After(run.RunTime) {
+					fix.Conversations = append(fix.Conversations, conversation)
+					break
+				}
+			}
+		}
+		fixes = append(fixes, fix)
+		red = nil
+	}
+	return fixes
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Exporter)
+)
+
+// Register makes an exporter available by name. It panics if the exporter is
+// nil, its name is empty, or the name is already registered, so conflicts
+// surface at startup rather than as a silently ignored format.
+func Register(e Exporter) {
+	if e == nil {
+		panic("export: Register exporter is nil")
+	}
+	name := e.Name()
+	if name == "" {
+		panic("export: Register exporter has an empty name")
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, exists := registry[name]; exists {
+		panic(fmt.Sprintf("export: Register called twice for exporter %q", name))
+	}
+	registry[name] = e
+}
+
+// Lookup returns the exporter registered under name
+func Lookup(name string) (Exporter, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	e, ok := registry[name]
+	return e, ok
+}
+
+// Names returns the registered exporter names in sorted order
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Exporters returns the registered exporters sorted by name
+func Exporters() []Exporter {
+	names := Names()
+
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	exporters := make([]Exporter, 0, len(names))
+	for _, name := range names {
+		if e, ok := registry[name]; ok {
+			exporters = append(exporters, e)
+		}
+	}
+	return exporters
+}
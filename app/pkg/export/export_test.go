@@ -0,0 +1,299 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stubExporter is a minimal exporter for registry tests
+type stubExporter struct{ name string }
+
+func (s stubExporter) Name() string                      { return s.name }
+func (s stubExporter) Description() string               { return "stub" }
+func (s stubExporter) Export(w io.Writer, _ *Data) error { return nil }
+
+func testData() *Data {
+	start := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	confidence := 0.9
+	return &Data{
+		GeneratedAt: start.Add(24 * time.Hour),
+		Sessions: []Session{{
+			ID:        "session-1",
+			Project:   "clio",
+			StartTime: start,
+			Conversations: []Conversation{{
+				ComposerID: "composer-1",
+				Name:       "Add exporters",
+				Messages: []Message{
+					{Role: "user", Text: "How should exporters register?", CreatedAt: start},
+					{Role: "agent", Text: "From an init function.", CreatedAt: start.Add(time.Minute)},
+				},
+			}},
+			Commits: []Commit{{
+				Hash:            "0123456789abcdef",
+				Message:         "Add export registry\n\nDetails",
+				Repository:      "clio",
+				Branch:          "main",
+				Timestamp:       start.Add(time.Hour),
+				CorrelationType: "active",
+				Confidence:      &confidence,
+				Metadata:        map[string]string{"ticket": "CLI-7"},
+			}},
+			Attachments: []Attachment{
+				{Name: "before.png", MediaType: "image/png", Path: "/assets/ab/ab12.png", Caption: "Settings page", AttachedAt: start},
+				{Name: "trace.txt", MediaType: "text/plain", Path: "/assets/cd/cd34.txt", AttachedAt: start},
+			},
+			Journal: []JournalEntry{
+				{Text: "Plugins need a registry\nbefore the CLI flag", CreatedAt: start.Add(-time.Minute)},
+			},
+			Metadata: map[string]string{"customer": "acme", "billable": "yes"},
+		}},
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	Register(stubExporter{name: "test-stub"})
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, "test-stub")
+		registryMu.Unlock()
+	})
+
+	if _, ok := Lookup("test-stub"); !ok {
+		t.Error("Lookup() should find a registered exporter")
+	}
+	if _, ok := Lookup("missing"); ok {
+		t.Error("Lookup() should not find an unregistered exporter")
+	}
+
+	names := Names()
+	for _, want := range []string{"json", "markdown", "test-stub"} {
+		found := false
+		for _, name := range names {
+			found = found || name == want
+		}
+		if !found {
+			t.Errorf("Names() = %v, missing %q", names, want)
+		}
+	}
+	for i := 1; i < len(names); i++ {
+		if names[i-1] > names[i] {
+			t.Errorf("Names() = %v, want sorted", names)
+		}
+	}
+}
+
+func TestRegister_Panics(t *testing.T) {
+	tests := []struct {
+		name     string
+		exporter Exporter
+	}{
+		{name: "nil exporter", exporter: nil},
+		{name: "empty name", exporter: stubExporter{}},
+		{name: "duplicate name", exporter: stubExporter{name: "json"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Register() should panic")
+				}
+			}()
+			Register(tt.exporter)
+		})
+	}
+}
+
+func TestJSONExporter(t *testing.T) {
+	exporter, _ := Lookup("json")
+
+	var buf bytes.Buffer
+	if err := exporter.Export(&buf, testData()); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	var decoded Data
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if len(decoded.Sessions) != 1 || len(decoded.Sessions[0].Conversations[0].Messages) != 2 {
+		t.Errorf("decoded data = %+v, want one session with two messages", decoded)
+	}
+	if c := decoded.Sessions[0].Commits[0]; c.Confidence == nil || *c.Confidence != 0.9 {
+		t.Errorf("commit confidence did not round-trip: %+v", c)
+	}
+	if decoded.Sessions[0].Metadata["customer"] != "acme" || decoded.Sessions[0].Commits[0].Metadata["ticket"] != "CLI-7" {
+		t.Errorf("metadata did not round-trip: %+v", decoded.Sessions[0])
+	}
+}
+
+func TestMarkdownExporter(t *testing.T) {
+	exporter, _ := Lookup("markdown")
+
+	var buf bytes.Buffer
+	if err := exporter.Export(&buf, testData()); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{"## clio:", "### Add exporters", "How should exporters register?", "`0123456` Add export registry (clio, main) [ticket=CLI-7]", "billable=yes, customer=acme",
+		"![Settings page](</assets/ab/ab12.png>)", "- [trace.txt](</assets/cd/cd34.txt>) (text/plain)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("markdown output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Details") {
+		t.Error("markdown output should only include commit subjects")
+	}
+	note := strings.Index(out, "): Plugins need a registry\n> before the CLI flag")
+	if note < 0 || note > strings.Index(out, "### Add exporters") {
+		t.Errorf("journal note should be quoted before the later conversation:\n%s", out)
+	}
+}
+
+func TestSession_Timeline(t *testing.T) {
+	session := testData().Sessions[0]
+	session.Conversations = append(session.Conversations, Conversation{ComposerID: "empty"})
+
+	var kinds []string
+	for _, event := range session.Timeline() {
+		kinds = append(kinds, event.Kind)
+	}
+	// The empty conversation sits at the session start with the first conversation,
+	// ahead of the attachments at the same time
+	want := []string{EventJournal, EventConversation, EventConversation, EventAttachment, EventAttachment, EventCommit}
+	if strings.Join(kinds, ",") != strings.Join(want, ",") {
+		t.Errorf("timeline = %v, want %v", kinds, want)
+	}
+}
+
+func TestSession_ActiveDuration(t *testing.T) {
+	start := time.Date(2024, 5, 1, 17, 0, 0, 0, time.UTC)
+	end := start.Add(16 * time.Hour)
+	session := Session{StartTime: start, EndTime: &end}
+	gaps := []IdleGap{
+		{Kind: "sleep", Start: start.Add(-2 * time.Hour), End: start.Add(-time.Hour)},    // Before the session
+		{Kind: "sleep", Start: start.Add(2 * time.Hour), End: start.Add(15 * time.Hour)}, // Overnight
+		{Kind: "sleep", Start: end.Add(-30 * time.Minute), End: end.Add(time.Hour)},      // Runs past the end
+	}
+
+	if got, want := session.ActiveDuration(gaps, end), 2*time.Hour+30*time.Minute; got != want {
+		t.Errorf("ActiveDuration() = %v, want %v", got, want)
+	}
+	if got := session.ActiveDuration(nil, end); got != 16*time.Hour {
+		t.Errorf("ActiveDuration() without gaps = %v, want 16h", got)
+	}
+
+	session.EndTime = nil
+	if got := session.ActiveDuration(nil, start.Add(time.Hour)); got != time.Hour {
+		t.Errorf("ActiveDuration() of an active session = %v, want 1h", got)
+	}
+}
+
+func TestSession_TestFixes(t *testing.T) {
+	session := testData().Sessions[0]
+	start := session.StartTime
+	session.TestRuns = []TestRun{
+		{RunTime: start.Add(-time.Minute), Passed: 3},
+		{RunTime: start.Add(-30 * time.Second), Passed: 2, Failed: 1, FailedTests: []string{"TestRegister"}},
+		{RunTime: start.Add(30 * time.Second), Passed: 2, Failed: 1},
+		{RunTime: start.Add(2 * time.Minute), Passed: 3},
+		{RunTime: start.Add(time.Hour), Passed: 2, Failed: 1}, // Still red at the end of the session
+	}
+
+	fixes := session.TestFixes()
+	if len(fixes) != 1 {
+		t.Fatalf("TestFixes() = %+v, want one fix", fixes)
+	}
+	fix := fixes[0]
+	if !fix.Failed.RunTime.Equal(start.Add(-30*time.Second)) || !fix.Passed.RunTime.Equal(start.Add(2*time.Minute)) {
+		t.Errorf("fix spans %v to %v, want the first failure to the next pass", fix.Failed.RunTime, fix.Passed.RunTime)
+	}
+	if len(fix.Conversations) != 1 || fix.Conversations[0].Name != "Add exporters" {
+		t.Errorf("fix conversations = %+v, want the conversation between the runs", fix.Conversations)
+	}
+
+	data := testData()
+	data.Sessions[0] = session
+	exporter, _ := Lookup("markdown")
+	var buf bytes.Buffer
+	if err := exporter.Export(&buf, data); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	for _, want := range []string{"### Tests", "(TestRegister)", `after "Add exporters"`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("markdown output missing %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func graphData() *Data {
+	data := testData()
+	session := &data.Sessions[0]
+	start := session.StartTime
+	session.Conversations = append(session.Conversations, Conversation{
+		ComposerID: "composer-2",
+		Name:       `Wire the "web" client`,
+		Messages:   []Message{{Role: "user", Text: "Call the API", CreatedAt: start.Add(2 * time.Hour)}},
+	})
+	session.Commits = append(session.Commits,
+		Commit{Hash: "fedcba9876543210", Message: "Call export API", Repository: "web", Timestamp: start.Add(3 * time.Hour)},
+		Commit{Hash: "1111111111111111", Message: "Scaffold", Repository: "clio", Timestamp: start.Add(-time.Hour)},
+	)
+	return data
+}
+
+func TestMermaidExporter(t *testing.T) {
+	exporter, _ := Lookup("mermaid")
+
+	var buf bytes.Buffer
+	if err := exporter.Export(&buf, graphData()); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"flowchart LR\n",
+		`s0c0["Add exporters"]`,
+		`s0c1["Wire the #quot;web#quot; client"]`,
+		"  subgraph s0r0[\"clio\"]\n    s0r0k0{{\"0123456 Add export registry\"}}\n    s0r0k1{{\"1111111 Scaffold\"}}\n  end\n",
+		"  subgraph s0r1[\"web\"]\n    s0r1k0{{\"fedcba9 Call export API\"}}\n  end\n",
+		"s0 --> s0c0\n", "s0c0 --> s0r0k0\n", "s0c1 --> s0r1k0\n",
+		// A commit before any conversation hangs off the session
+		"s0 --> s0r0k1\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("mermaid output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestDotExporter(t *testing.T) {
+	exporter, _ := Lookup("dot")
+
+	var buf bytes.Buffer
+	if err := exporter.Export(&buf, graphData()); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"digraph clio {\n",
+		`s0c1 [label="Wire the \"web\" client", shape=box];`,
+		"subgraph cluster_s0r1 {\n    label=\"web\";\n    s0r1k0 [label=\"fedcba9 Call export API\", shape=note];\n  }\n",
+		"s0c1 -> s0r1k0;\n",
+		"s0 -> s0r0k1;\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("DOT output missing %q:\n%s", want, out)
+		}
+	}
+	if !strings.HasSuffix(out, "}\n") {
+		t.Errorf("DOT output should close the graph:\n%s", out)
+	}
+}
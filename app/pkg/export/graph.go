@@ -0,0 +1,206 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	// maxGraphLabel caps the characters of a conversation or commit label
+	maxGraphLabel = 48
+)
+
+// graphSession is a session's nodes and edges, shared by the graph exporters
+type graphSession struct {
+	id            string
+	label         string
+	conversations []graphNode
+	repositories  []graphRepository
+	edges         []graphEdge
+}
+
+// graphRepository groups a session's commits in one repository
+type graphRepository struct {
+	id      string
+	label   string
+	commits []graphNode
+}
+
+// graphNode is a conversation or commit
+type graphNode struct {
+	id    string
+	label string
+}
+
+// graphEdge links a session to a conversation, or a conversation or session to a commit
+type graphEdge struct {
+	from, to string
+}
+
+// buildGraph lays out each session as a node linked to its conversations, and
+// each commit linked to the conversation with the latest message at or before
+// it, or to the session when no conversation had started. Commits are grouped
+// by repository so multi-repo sessions show each repository's work together.
+func buildGraph(data *Data) []graphSession {
+	graph := make([]graphSession, 0, len(data.Sessions))
+	for i, session := range data.Sessions {
+		g := graphSession{
+			id:    fmt.Sprintf("s%d", i),
+			label: fmt.Sprintf("%s\n%s", session.Project, session.StartTime.Local().Format(markdownTimeLayout)),
+		}
+		if session.Project == "" {
+			g.label = session.StartTime.Local().Format(markdownTimeLayout)
+		}
+
+		for j, conversation := range session.Conversations {
+			node := graphNode{id: fmt.Sprintf("%sc%d", g.id, j), label: truncateLabel(conversationTitle(conversation))}
+			g.conversations = append(g.conversations, node)
+			g.edges = append(g.edges, graphEdge{from: g.id, to: node.id})
+		}
+
+		repositories := make(map[string]int)
+		for _, commit := range session.Commits {
+			r, ok := repositories[commit.Repository]
+			if !ok {
+				r = len(g.repositories)
+				repositories[commit.Repository] = r
+				g.repositories = append(g.repositories, graphRepository{id: fmt.Sprintf("%sr%d", g.id, r), label: commit.Repository})
+			}
+			repository := &g.repositories[r]
+			subject, _, _ := strings.Cut(commit.Message, "\n")
+			node := graphNode{
+				id:    fmt.Sprintf("%sk%d", repository.id, len(repository.commits)),
+				label: truncateLabel(shortHash(commit.Hash) + " " + strings.TrimSpace(subject)),
+			}
+			repository.commits = append(repository.commits, node)
+
+			from := g.id
+			if c := precedingConversation(session, commit); c >= 0 {
+				from = g.conversations[c].id
+			}
+			g.edges = append(g.edges, graphEdge{from: from, to: node.id})
+		}
+		graph = append(graph, g)
+	}
+	return graph
+}
+
+// precedingConversation returns the index of the conversation with the latest
+// message at or before the commit, or -1 when there is none
+func precedingConversation(session Session, commit Commit) int {
+	best := -1
+	var bestTime time.Time
+	for i, conversation := range session.Conversations {
+		for _, message := range conversation.Messages {
+			if message.CreatedAt.After(commit.Timestamp) {
+				continue
+			}
+			if best < 0 || message.CreatedAt.After(bestTime) {
+				best, bestTime = i, message.CreatedAt
+			}
+		}
+	}
+	return best
+}
+
+// truncateLabel shortens a label to maxGraphLabel characters
+func truncateLabel(label string) string {
+	runes := []rune(label)
+	if len(runes) <= maxGraphLabel {
+		return label
+	}
+	return string(runes[:maxGraphLabel-3]) + "..."
+}
+
+// mermaidExporter writes sessions, conversations, and commits as a Mermaid flowchart
+type mermaidExporter struct{}
+
+// Name implements Exporter
+func (mermaidExporter) Name() string { return "mermaid" }
+
+// Description implements Exporter
+func (mermaidExporter) Description() string {
+	return "Session, conversation, and commit graph as a Mermaid flowchart"
+}
+
+// Export implements Exporter
+func (mermaidExporter) Export(w io.Writer, data *Data) error {
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for _, session := range buildGraph(data) {
+		fmt.Fprintf(&b, "  %s([%s])\n", session.id, mermaidLabel(session.label))
+		for _, conversation := range session.conversations {
+			fmt.Fprintf(&b, "  %s[%s]\n", conversation.id, mermaidLabel(conversation.label))
+		}
+		for _, repository := range session.repositories {
+			fmt.Fprintf(&b, "  subgraph %s[%s]\n", repository.id, mermaidLabel(repository.label))
+			for _, commit := range repository.commits {
+				fmt.Fprintf(&b, "    %s{{%s}}\n", commit.id, mermaidLabel(commit.label))
+			}
+			b.WriteString("  end\n")
+		}
+		for _, edge := range session.edges {
+			fmt.Fprintf(&b, "  %s --> %s\n", edge.from, edge.to)
+		}
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write mermaid: %w", err)
+	}
+	return nil
+}
+
+// mermaidLabel quotes a label, escaping quotes and breaking lines with <br/>
+func mermaidLabel(label string) string {
+	label = strings.ReplaceAll(label, `"`, "#quot;")
+	return `"` + strings.ReplaceAll(label, "\n", "<br/>") + `"`
+}
+
+// dotExporter writes sessions, conversations, and commits as a Graphviz DOT graph
+type dotExporter struct{}
+
+// Name implements Exporter
+func (dotExporter) Name() string { return "dot" }
+
+// Description implements Exporter
+func (dotExporter) Description() string {
+	return "Session, conversation, and commit graph in Graphviz DOT"
+}
+
+// Export implements Exporter
+func (dotExporter) Export(w io.Writer, data *Data) error {
+	var b strings.Builder
+	b.WriteString("digraph clio {\n  rankdir=LR;\n  node [fontname=\"Helvetica\"];\n")
+	for _, session := range buildGraph(data) {
+		fmt.Fprintf(&b, "  %s [label=%s, shape=ellipse, style=bold];\n", session.id, dotLabel(session.label))
+		for _, conversation := range session.conversations {
+			fmt.Fprintf(&b, "  %s [label=%s, shape=box];\n", conversation.id, dotLabel(conversation.label))
+		}
+		for _, repository := range session.repositories {
+			// Graphviz only draws subgraphs named cluster_* as boxes
+			fmt.Fprintf(&b, "  subgraph cluster_%s {\n    label=%s;\n", repository.id, dotLabel(repository.label))
+			for _, commit := range repository.commits {
+				fmt.Fprintf(&b, "    %s [label=%s, shape=note];\n", commit.id, dotLabel(commit.label))
+			}
+			b.WriteString("  }\n")
+		}
+		for _, edge := range session.edges {
+			fmt.Fprintf(&b, "  %s -> %s;\n", edge.from, edge.to)
+		}
+	}
+	b.WriteString("}\n")
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write DOT: %w", err)
+	}
+	return nil
+}
+
+// dotLabel quotes a label, escaping backslashes and quotes and keeping line breaks
+func dotLabel(label string) string {
+	label = strings.ReplaceAll(label, `\`, `\\`)
+	label = strings.ReplaceAll(label, `"`, `\"`)
+	return `"` + strings.ReplaceAll(label, "\n", `\n`) + `"`
+}
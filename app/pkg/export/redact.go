@@ -0,0 +1,107 @@
+package export
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Redaction selects what to remove from data before it is exported. The zero
+// value removes nothing.
+type Redaction struct {
+	StripThinking  bool // Drop agent reasoning text
+	StripToolCalls bool // Drop the tools agents ran
+	// StripPaths shortens absolute and home-relative paths in text to their last
+	// element, and attachment paths to the file name, so exports don't reveal
+	// directory layouts or user names
+	StripPaths bool
+	// AllowExtensions drops attachments whose file extension isn't listed
+	// (case-insensitive, with or without the dot); empty allows every file
+	AllowExtensions []string
+}
+
+// pathPattern matches Unix, home-relative, and Windows absolute paths with at least
+// one directory. The first group is the delimiter before the path, kept since Go
+// regexps can't look behind.
+var pathPattern = regexp.MustCompile(`(^|[\s(\[{"'` + "`" + `=:,])((?:/|~/|[A-Za-z]:\\)(?:[\w.@+-]+[/\\])+[\w.@+-]*)`)
+
+// IsZero reports whether the redaction removes nothing
+func (r Redaction) IsZero() bool {
+	return !r.StripThinking && !r.StripToolCalls && !r.StripPaths && len(r.AllowExtensions) == 0
+}
+
+// Apply redacts data in place. Exporters receive data after redaction, so every
+// format honors the same rules.
+func (r Redaction) Apply(data *Data) {
+	if data == nil || r.IsZero() {
+		return
+	}
+
+	for i := range data.Sessions {
+		session := &data.Sessions[i]
+		for j := range session.Conversations {
+			conversation := &session.Conversations[j]
+			conversation.Name = r.text(conversation.Name)
+			for k := range conversation.Messages {
+				message := &conversation.Messages[k]
+				message.Text = r.text(message.Text)
+				message.Thinking = r.text(message.Thinking)
+				if r.StripThinking {
+					message.Thinking = ""
+				}
+				if r.StripToolCalls {
+					message.ToolCalls = nil
+				}
+			}
+		}
+		for j := range session.Commits {
+			session.Commits[j].Message = r.text(session.Commits[j].Message)
+		}
+		for j := range session.Journal {
+			session.Journal[j].Text = r.text(session.Journal[j].Text)
+		}
+
+		attachments := session.Attachments[:0]
+		for _, attachment := range session.Attachments {
+			if !r.allows(attachment.Name) {
+				continue
+			}
+			attachment.Caption = r.text(attachment.Caption)
+			if r.StripPaths {
+				attachment.Path = path.Base(strings.ReplaceAll(attachment.Path, `\`, "/"))
+			}
+			attachments = append(attachments, attachment)
+		}
+		session.Attachments = attachments
+	}
+}
+
+// text shortens the paths in s when StripPaths is set
+func (r Redaction) text(s string) string {
+	if !r.StripPaths || s == "" {
+		return s
+	}
+	return pathPattern.ReplaceAllStringFunc(s, func(match string) string {
+		groups := pathPattern.FindStringSubmatch(match)
+		p := strings.ReplaceAll(groups[2], `\`, "/")
+		base := path.Base(strings.TrimSuffix(p, "/"))
+		if strings.HasSuffix(p, "/") {
+			base += "/"
+		}
+		return groups[1] + base
+	})
+}
+
+// allows reports whether a file name passes AllowExtensions
+func (r Redaction) allows(name string) bool {
+	if len(r.AllowExtensions) == 0 {
+		return true
+	}
+	ext := strings.TrimPrefix(path.Ext(name), ".")
+	for _, allowed := range r.AllowExtensions {
+		if strings.EqualFold(strings.TrimPrefix(allowed, "."), ext) && ext != "" {
+			return true
+		}
+	}
+	return false
+}
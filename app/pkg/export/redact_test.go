@@ -0,0 +1,35 @@
+package export
+
+import (
+	"testing"
+)
+
+func TestRedaction_Apply(t *testing.T) {
+	data := testData()
+	session := &data.Sessions[0]
+	session.Conversations[0].Messages[1].Thinking = "Look at /home/dev/src/clio/pkg/export/export.go first"
+	session.Conversations[0].Messages[1].ToolCalls = []ToolCall{{Name: "read_file", Status: "completed"}}
+	session.Conversations[0].Messages[0].Text = "Why does /home/dev/src/clio/main.go fail? See ~/notes/todo.md, (C:\\Users\\dev\\app.log) and /var/log/"
+	session.Journal[0].Text = "Files in /tmp/x/y and and/or https://example.com/a/b stay"
+
+	Redaction{}.Apply(data)
+	if session.Conversations[0].Messages[1].ToolCalls == nil || len(session.Attachments) != 2 {
+		t.Fatal("the zero redaction should change nothing")
+	}
+
+	Redaction{StripThinking: true, StripToolCalls: true, StripPaths: true, AllowExtensions: []string{".PNG"}}.Apply(data)
+
+	agent := session.Conversations[0].Messages[1]
+	if agent.Thinking != "" || agent.ToolCalls != nil {
+		t.Errorf("agent message = %+v, want thinking and tool calls stripped", agent)
+	}
+	if got, want := session.Conversations[0].Messages[0].Text, "Why does main.go fail? See todo.md, (app.log) and log/"; got != want {
+		t.Errorf("text = %q, want %q", got, want)
+	}
+	if got, want := session.Journal[0].Text, "Files in y and and/or https://example.com/a/b stay"; got != want {
+		t.Errorf("journal = %q, want %q", got, want)
+	}
+	if len(session.Attachments) != 1 || session.Attachments[0].Name != "before.png" || session.Attachments[0].Path != "ab12.png" {
+		t.Errorf("attachments = %+v, want only the PNG with its path shortened", session.Attachments)
+	}
+}